@@ -0,0 +1,27 @@
+package intf
+
+import "github.com/gin-gonic/gin"
+
+// 运维管理处理器接口
+type AdminHandler interface {
+	SlowQueries(c *gin.Context)
+	Retention(c *gin.Context)
+	UpdateRetention(c *gin.Context)
+	Settings(c *gin.Context)
+	UpdateSettings(c *gin.Context)
+	SettingsHistory(c *gin.Context)
+	Offboard(c *gin.Context)
+	GetOffboarding(c *gin.Context)
+	ExportSnapshot(c *gin.Context)
+	RestoreSnapshot(c *gin.Context)
+	QueryJobs(c *gin.Context)
+	RetryJob(c *gin.Context)
+	CancelJob(c *gin.Context)
+	RateLimitShadow(c *gin.Context)
+	PermissionCacheStatus(c *gin.Context)
+	RebuildPermissionCache(c *gin.Context)
+	SupportBundle(c *gin.Context)
+	Readiness(c *gin.Context)
+	RolePermissionMatrix(c *gin.Context)
+	RevokeTokens(c *gin.Context)
+}
@@ -0,0 +1,10 @@
+package intf
+
+import "github.com/gin-gonic/gin"
+
+// API密钥管理处理器接口
+type APIKeyHandler interface {
+	Create(c *gin.Context)
+	Revoke(c *gin.Context)
+	QueryList(c *gin.Context)
+}
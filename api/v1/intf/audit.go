@@ -0,0 +1,12 @@
+package intf
+
+import "github.com/gin-gonic/gin"
+
+// 审计日志处理器接口
+type AuditHandler interface {
+	List(c *gin.Context)
+	Export(c *gin.Context)
+	GetExportJob(c *gin.Context)
+	DownloadExport(c *gin.Context)
+	Revert(c *gin.Context)
+}
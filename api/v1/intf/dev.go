@@ -0,0 +1,9 @@
+package intf
+
+import "github.com/gin-gonic/gin"
+
+// 开发与测试环境处理器接口
+type DevHandler interface {
+	Factory(c *gin.Context)
+	MintToken(c *gin.Context)
+}
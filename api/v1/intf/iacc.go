@@ -2,42 +2,105 @@ package intf
 
 import "github.com/gin-gonic/gin"
 
+// 客户端管理处理器接口
+type ClientHandler interface {
+	Create(c *gin.Context)
+	GetByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	QueryList(c *gin.Context)
+}
+
 // 权限管理处理器接口
 type PermissionHandler interface {
 	Create(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
 	GetByID(c *gin.Context)
 	DeleteByID(c *gin.Context)
 	QueryList(c *gin.Context)
+	Count(c *gin.Context)
+	Exists(c *gin.Context)
+	Values(c *gin.Context)
+	Tree(c *gin.Context)
+	Move(c *gin.Context)
+	GetTranslations(c *gin.Context)
+	PutTranslation(c *gin.Context)
+	DeleteTranslation(c *gin.Context)
 }
 
 // 角色管理处理器接口
 type RoleHandler interface {
 	Create(c *gin.Context)
+	BatchCreate(c *gin.Context)
 	GetByID(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
 	DeleteByID(c *gin.Context)
+	BatchDelete(c *gin.Context)
 	QueryList(c *gin.Context)
+	Count(c *gin.Context)
+	Exists(c *gin.Context)
+	Values(c *gin.Context)
 	AssignPermission(c *gin.Context)
+	SyncPermission(c *gin.Context)
 	GetPermissions(c *gin.Context)
+	QueryChanges(c *gin.Context)
+	ApproveChange(c *gin.Context)
+	RejectChange(c *gin.Context)
+	GetTranslations(c *gin.Context)
+	PutTranslation(c *gin.Context)
+	DeleteTranslation(c *gin.Context)
 }
 
 // 用户管理处理器接口
 type UserHandler interface {
 	Create(c *gin.Context)
+	CreateFromBlueprint(c *gin.Context)
 	BatchCreate(c *gin.Context)
 	GetByID(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
+	PatchProfile(c *gin.Context)
 	DeleteByID(c *gin.Context)
 	BatchDelete(c *gin.Context)
 	QueryList(c *gin.Context)
+	Export(c *gin.Context)
+	Import(c *gin.Context)
+	GetImportJob(c *gin.Context)
+	Count(c *gin.Context)
+	Exists(c *gin.Context)
+	CheckAvailability(c *gin.Context)
 	AssignRole(c *gin.Context)
 	GetRoles(c *gin.Context)
+	ResetPassword(c *gin.Context)
 }
 
 // 认证处理器接口
 type AuthHandler interface {
 	Login(c *gin.Context)
 	RefreshToken(c *gin.Context)
+	Logout(c *gin.Context)
+	ChangePassword(c *gin.Context)
 	UserDetail(c *gin.Context)
+	QueryDevices(c *gin.Context)
+	DeleteDevice(c *gin.Context)
+	SetStrictDevice(c *gin.Context)
+}
+
+// 入职蓝图管理处理器接口
+type AttributeHandler interface {
+	Create(c *gin.Context)
+	GetByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	QueryList(c *gin.Context)
+}
+
+type BlueprintHandler interface {
+	Create(c *gin.Context)
+	GetByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	QueryList(c *gin.Context)
 }
@@ -0,0 +1,8 @@
+package intf
+
+import "github.com/gin-gonic/gin"
+
+// 接口调试沙箱处理器接口
+type SandboxHandler interface {
+	IssueToken(c *gin.Context)
+}
@@ -7,8 +7,17 @@ type ITemplateHandler interface {
 	Create(*gin.Context)
 	GetByID(*gin.Context)
 	UpdateByID(*gin.Context)
+	PatchByID(*gin.Context)
 	DeleteByID(*gin.Context)
 	BatchCreate(*gin.Context)
 	QueryList(*gin.Context)
+	Count(*gin.Context)
 	BatchDelete(*gin.Context)
+	Transfer(*gin.Context)
+	Use(*gin.Context)
+	Archive(*gin.Context)
+	BatchArchive(*gin.Context)
+	Restore(*gin.Context)
+	SyncPull(*gin.Context)
+	SyncPush(*gin.Context)
 }
@@ -0,0 +1,9 @@
+package intf
+
+import "github.com/gin-gonic/gin"
+
+// 租户管理处理器接口
+type TenantHandler interface {
+	Create(c *gin.Context)
+	QueryList(c *gin.Context)
+}
@@ -0,0 +1,11 @@
+package intf
+
+import "github.com/gin-gonic/gin"
+
+// 保存的列表视图处理器接口
+type ViewHandler interface {
+	Create(c *gin.Context)
+	QueryList(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	DeleteByID(c *gin.Context)
+}
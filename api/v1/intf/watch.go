@@ -0,0 +1,11 @@
+package intf
+
+import "github.com/gin-gonic/gin"
+
+// 关注处理器接口
+type WatchHandler interface {
+	Create(c *gin.Context)
+	QueryList(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	Unwatch(c *gin.Context)
+}
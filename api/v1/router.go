@@ -2,6 +2,8 @@ package v1
 
 import (
 	"go-pg-demo/api/v1/intf"
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/pkgs"
 
 	"github.com/gin-gonic/gin"
 )
@@ -9,39 +11,96 @@ import (
 // v1路由
 type Router struct {
 	Engine            *gin.Engine
+	Config            *pkgs.Config
 	RouterGroup       *gin.RouterGroup
 	TemplateHandler   intf.ITemplateHandler
 	UserHandler       intf.UserHandler
 	RoleHandler       intf.RoleHandler
 	AuthHandler       intf.AuthHandler
+	ClientHandler     intf.ClientHandler
+	BlueprintHandler  intf.BlueprintHandler
+	AttributeHandler  intf.AttributeHandler
 	PermissionHandler intf.PermissionHandler
+	TenantHandler     intf.TenantHandler
+	APIKeyHandler     intf.APIKeyHandler
+	AdminHandler      intf.AdminHandler
+	DevHandler        intf.DevHandler
+	SandboxHandler    intf.SandboxHandler
+	ViewHandler       intf.ViewHandler
+	AuditHandler      intf.AuditHandler
+	WatchHandler      intf.WatchHandler
+	// 公开接口（/public/v1）路由组使用的中间件
+	PublicMiddlewares middlewares.PublicAPIMiddlewares
 }
 
 func NewRouter(
 	engine *gin.Engine,
+	config *pkgs.Config,
 	templateHandler intf.ITemplateHandler,
 	userHandler intf.UserHandler,
 	roleHandler intf.RoleHandler,
 	authHandler intf.AuthHandler,
+	clientHandler intf.ClientHandler,
+	blueprintHandler intf.BlueprintHandler,
+	attributeHandler intf.AttributeHandler,
 	permissionHandler intf.PermissionHandler,
+	tenantHandler intf.TenantHandler,
+	apiKeyHandler intf.APIKeyHandler,
+	adminHandler intf.AdminHandler,
+	devHandler intf.DevHandler,
+	sandboxHandler intf.SandboxHandler,
+	viewHandler intf.ViewHandler,
+	auditHandler intf.AuditHandler,
+	watchHandler intf.WatchHandler,
+	publicMiddlewares middlewares.PublicAPIMiddlewares,
 ) *Router {
 	return &Router{
 		Engine:            engine,
+		Config:            config,
 		TemplateHandler:   templateHandler,
 		UserHandler:       userHandler,
 		RoleHandler:       roleHandler,
 		AuthHandler:       authHandler,
+		ClientHandler:     clientHandler,
+		BlueprintHandler:  blueprintHandler,
+		AttributeHandler:  attributeHandler,
 		PermissionHandler: permissionHandler,
+		TenantHandler:     tenantHandler,
+		APIKeyHandler:     apiKeyHandler,
+		AdminHandler:      adminHandler,
+		DevHandler:        devHandler,
+		SandboxHandler:    sandboxHandler,
+		ViewHandler:       viewHandler,
+		AuditHandler:      auditHandler,
+		WatchHandler:      watchHandler,
+		PublicMiddlewares: publicMiddlewares,
 	}
 }
 
+// Register 注册路由，配置中关闭的模块（modules.*.enabled）不注册，请求返回 404
 func (r *Router) Register() {
 	r.RouterGroup = r.Engine.Group("/v1")
-	r.RegisterTemplate()
-	r.RegisterIACCPermission()
+	if r.Config.Modules.Template.Enabled {
+		r.RegisterTemplate()
+	}
+	if r.Config.Modules.Permission.Enabled {
+		r.RegisterIACCPermission()
+	}
 	r.RegisterIACCUser()
 	r.RegisterIACCRole()
 	r.RegisterIACCAuth()
+	r.RegisterIACCClient()
+	r.RegisterIACCBlueprint()
+	r.RegisterIACCAttribute()
+	r.RegisterTenant()
+	r.RegisterAPIKey()
+	r.RegisterView()
+	r.RegisterWatch()
+	r.RegisterAudit()
+	r.RegisterAdmin()
+	r.RegisterDev()
+	r.RegisterSandbox()
+	r.RegisterPublic()
 }
 
 func (r *Router) RegisterTemplate() {
@@ -50,10 +109,22 @@ func (r *Router) RegisterTemplate() {
 		templates.POST("", r.TemplateHandler.Create)
 		templates.GET("/:id", r.TemplateHandler.GetByID)
 		templates.PUT("/:id", r.TemplateHandler.UpdateByID)
+		templates.PATCH("/:id", r.TemplateHandler.PatchByID)
 		templates.DELETE("/:id", r.TemplateHandler.DeleteByID)
 		templates.POST("/batch-create", r.TemplateHandler.BatchCreate)
 		templates.GET("/list", r.TemplateHandler.QueryList)
+		templates.GET("/count", r.TemplateHandler.Count)
 		templates.POST("/batch-delete", r.TemplateHandler.BatchDelete)
+		templates.POST("/batch-archive", r.TemplateHandler.BatchArchive)
+		templates.POST("/:id/transfer", r.TemplateHandler.Transfer)
+		templates.POST("/:id/use", r.TemplateHandler.Use)
+		templates.POST("/:id/archive", r.TemplateHandler.Archive)
+		templates.POST("/:id/restore", r.TemplateHandler.Restore)
+	}
+	sync := r.RouterGroup.Group("/sync")
+	{
+		sync.GET("/template", r.TemplateHandler.SyncPull)
+		sync.POST("/template", r.TemplateHandler.SyncPush)
 	}
 }
 
@@ -63,8 +134,17 @@ func (r *Router) RegisterIACCPermission() {
 		permissions.POST("", r.PermissionHandler.Create)
 		permissions.GET("/:id", r.PermissionHandler.GetByID)
 		permissions.PUT("/:id", r.PermissionHandler.UpdateByID)
+		permissions.PATCH("/:id", r.PermissionHandler.PatchByID)
 		permissions.DELETE("/:id", r.PermissionHandler.DeleteByID)
 		permissions.GET("/list", r.PermissionHandler.QueryList)
+		permissions.GET("/count", r.PermissionHandler.Count)
+		permissions.GET("/exists", r.PermissionHandler.Exists)
+		permissions.GET("/values", r.PermissionHandler.Values)
+		permissions.GET("/tree", r.PermissionHandler.Tree)
+		permissions.PUT("/:id/move", r.PermissionHandler.Move)
+		permissions.GET("/:id/translation", r.PermissionHandler.GetTranslations)
+		permissions.PUT("/:id/translation/:locale", r.PermissionHandler.PutTranslation)
+		permissions.DELETE("/:id/translation/:locale", r.PermissionHandler.DeleteTranslation)
 	}
 }
 
@@ -72,12 +152,25 @@ func (r *Router) RegisterIACCRole() {
 	roles := r.RouterGroup.Group("/role")
 	{
 		roles.POST("", r.RoleHandler.Create)
+		roles.POST("/batch-create", r.RoleHandler.BatchCreate)
 		roles.GET("/:id", r.RoleHandler.GetByID)
 		roles.PUT("/:id", r.RoleHandler.UpdateByID)
+		roles.PATCH("/:id", r.RoleHandler.PatchByID)
 		roles.DELETE("/:id", r.RoleHandler.DeleteByID)
+		roles.POST("/batch-delete", r.RoleHandler.BatchDelete)
 		roles.GET("/list", r.RoleHandler.QueryList)
+		roles.GET("/count", r.RoleHandler.Count)
+		roles.GET("/exists", r.RoleHandler.Exists)
+		roles.GET("/values", r.RoleHandler.Values)
 		roles.POST("/:id/permission", r.RoleHandler.AssignPermission)
+		roles.PUT("/:id/permission/sync", r.RoleHandler.SyncPermission)
 		roles.GET("/:id/permission", r.RoleHandler.GetPermissions)
+		roles.GET("/:id/translation", r.RoleHandler.GetTranslations)
+		roles.PUT("/:id/translation/:locale", r.RoleHandler.PutTranslation)
+		roles.DELETE("/:id/translation/:locale", r.RoleHandler.DeleteTranslation)
+		roles.GET("/change/list", r.RoleHandler.QueryChanges)
+		roles.POST("/change/:id/approve", r.RoleHandler.ApproveChange)
+		roles.POST("/change/:id/reject", r.RoleHandler.RejectChange)
 	}
 }
 
@@ -86,13 +179,23 @@ func (r *Router) RegisterIACCUser() {
 	{
 		users.POST("", r.UserHandler.Create)
 		users.POST("/batch-create", r.UserHandler.BatchCreate)
+		users.POST("/from-blueprint/:blueprintId", r.UserHandler.CreateFromBlueprint)
 		users.GET("/:id", r.UserHandler.GetByID)
 		users.PUT("/:id", r.UserHandler.UpdateByID)
+		users.PATCH("/:id", r.UserHandler.PatchByID)
+		users.PATCH("/:id/profile", r.UserHandler.PatchProfile)
 		users.DELETE("/:id", r.UserHandler.DeleteByID)
 		users.POST("/batch-delete", r.UserHandler.BatchDelete)
 		users.GET("/list", r.UserHandler.QueryList)
+		users.GET("/export", r.UserHandler.Export)
+		users.POST("/import", r.UserHandler.Import)
+		users.GET("/import/:id", r.UserHandler.GetImportJob)
+		users.GET("/count", r.UserHandler.Count)
+		users.GET("/exists", r.UserHandler.Exists)
+		users.POST("/check-availability", r.UserHandler.CheckAvailability)
 		users.POST("/:id/role", r.UserHandler.AssignRole)
 		users.GET("/:id/roles", r.UserHandler.GetRoles)
+		users.POST("/:id/reset-password", r.UserHandler.ResetPassword)
 	}
 }
 
@@ -101,6 +204,148 @@ func (r *Router) RegisterIACCAuth() {
 	{
 		auth.POST("/login", r.AuthHandler.Login)
 		auth.POST("/refresh-token", r.AuthHandler.RefreshToken)
+		auth.POST("/logout", r.AuthHandler.Logout)
+		auth.POST("/change-password", r.AuthHandler.ChangePassword)
 		auth.GET("/user-detail", r.AuthHandler.UserDetail)
+		auth.GET("/devices", r.AuthHandler.QueryDevices)
+		auth.PUT("/devices/strict-mode", r.AuthHandler.SetStrictDevice)
+		auth.DELETE("/devices/:id", r.AuthHandler.DeleteDevice)
+	}
+}
+
+func (r *Router) RegisterIACCClient() {
+	clients := r.RouterGroup.Group("/client")
+	{
+		clients.POST("", r.ClientHandler.Create)
+		clients.GET("/list", r.ClientHandler.QueryList)
+		clients.GET("/:id", r.ClientHandler.GetByID)
+		clients.PUT("/:id", r.ClientHandler.UpdateByID)
+		clients.DELETE("/:id", r.ClientHandler.DeleteByID)
+	}
+}
+
+func (r *Router) RegisterIACCBlueprint() {
+	blueprints := r.RouterGroup.Group("/blueprint")
+	{
+		blueprints.POST("", r.BlueprintHandler.Create)
+		blueprints.GET("/list", r.BlueprintHandler.QueryList)
+		blueprints.GET("/:id", r.BlueprintHandler.GetByID)
+		blueprints.PUT("/:id", r.BlueprintHandler.UpdateByID)
+		blueprints.DELETE("/:id", r.BlueprintHandler.DeleteByID)
+	}
+}
+
+func (r *Router) RegisterIACCAttribute() {
+	attributes := r.RouterGroup.Group("/attribute")
+	{
+		attributes.POST("", r.AttributeHandler.Create)
+		attributes.GET("/list", r.AttributeHandler.QueryList)
+		attributes.GET("/:id", r.AttributeHandler.GetByID)
+		attributes.PUT("/:id", r.AttributeHandler.UpdateByID)
+		attributes.DELETE("/:id", r.AttributeHandler.DeleteByID)
+	}
+}
+
+func (r *Router) RegisterTenant() {
+	tenants := r.RouterGroup.Group("/tenant")
+	{
+		tenants.POST("", r.TenantHandler.Create)
+		tenants.GET("/list", r.TenantHandler.QueryList)
+	}
+}
+
+func (r *Router) RegisterAPIKey() {
+	apiKeys := r.RouterGroup.Group("/api-key")
+	{
+		apiKeys.POST("", r.APIKeyHandler.Create)
+		apiKeys.GET("/list", r.APIKeyHandler.QueryList)
+		apiKeys.DELETE("/:id", r.APIKeyHandler.Revoke)
+	}
+}
+
+func (r *Router) RegisterView() {
+	views := r.RouterGroup.Group("/views")
+	{
+		views.POST("", r.ViewHandler.Create)
+		views.GET("", r.ViewHandler.QueryList)
+		views.PUT("/:id", r.ViewHandler.UpdateByID)
+		views.DELETE("/:id", r.ViewHandler.DeleteByID)
+	}
+}
+
+func (r *Router) RegisterWatch() {
+	watches := r.RouterGroup.Group("/watch")
+	{
+		watches.POST("", r.WatchHandler.Create)
+		watches.GET("/list", r.WatchHandler.QueryList)
+		watches.POST("/unwatch", r.WatchHandler.Unwatch)
+		watches.DELETE("/:id", r.WatchHandler.DeleteByID)
+	}
+}
+
+func (r *Router) RegisterAudit() {
+	audit := r.RouterGroup.Group("/audit")
+	{
+		audit.GET("/list", r.AuditHandler.List)
+		audit.GET("/export", r.AuditHandler.Export)
+		audit.GET("/export/:id", r.AuditHandler.GetExportJob)
+		audit.GET("/export/:id/download", r.AuditHandler.DownloadExport)
+		audit.POST("/:id/revert", r.AuditHandler.Revert)
+	}
+}
+
+func (r *Router) RegisterAdmin() {
+	admin := r.RouterGroup.Group("/admin")
+	{
+		admin.GET("/slow-queries", r.AdminHandler.SlowQueries)
+		admin.GET("/retention", r.AdminHandler.Retention)
+		admin.PUT("/retention/:category", r.AdminHandler.UpdateRetention)
+		admin.GET("/settings", r.AdminHandler.Settings)
+		admin.PUT("/settings", r.AdminHandler.UpdateSettings)
+		admin.GET("/settings/history", r.AdminHandler.SettingsHistory)
+		admin.POST("/offboard/:userId", r.AdminHandler.Offboard)
+		admin.GET("/offboard/:id", r.AdminHandler.GetOffboarding)
+		admin.GET("/snapshot", r.AdminHandler.ExportSnapshot)
+		admin.POST("/snapshot/restore", r.AdminHandler.RestoreSnapshot)
+		admin.GET("/jobs", r.AdminHandler.QueryJobs)
+		admin.POST("/jobs/:id/retry", r.AdminHandler.RetryJob)
+		admin.POST("/jobs/:id/cancel", r.AdminHandler.CancelJob)
+		admin.GET("/rate-limit/shadow", r.AdminHandler.RateLimitShadow)
+		admin.GET("/permission-cache/status", r.AdminHandler.PermissionCacheStatus)
+		admin.POST("/permission-cache/rebuild", r.AdminHandler.RebuildPermissionCache)
+		admin.GET("/support-bundle", r.AdminHandler.SupportBundle)
+		admin.GET("/readiness", r.AdminHandler.Readiness)
+		admin.GET("/role-permission-matrix", r.AdminHandler.RolePermissionMatrix)
+		admin.POST("/tokens/revoke", r.AdminHandler.RevokeTokens)
+	}
+}
+
+// RegisterDev 注册开发与测试环境使用的接口，每个接口只在配置开启时注册
+func (r *Router) RegisterDev() {
+	dev := r.RouterGroup.Group("/dev")
+	if r.Config.DevFactory.Enabled {
+		dev.POST("/factory", r.DevHandler.Factory)
+	}
+	if r.Config.DevToken.Enabled {
+		dev.POST("/token", r.DevHandler.MintToken)
+	}
+}
+
+// RegisterSandbox 注册接口调试沙箱，只在配置 sandbox.enabled 时注册
+func (r *Router) RegisterSandbox() {
+	if r.Config.Sandbox.Enabled {
+		r.RouterGroup.POST("/sandbox/token", r.SandboxHandler.IssueToken)
+	}
+}
+
+// RegisterPublic 注册对外合作方开放的只读接口，使用 API 密钥鉴权、按等级限流并缓存响应
+func (r *Router) RegisterPublic() {
+	public := r.Engine.Group(middlewares.PublicAPIPrefix, r.PublicMiddlewares...)
+	if r.Config.Modules.Template.Enabled {
+		templates := public.Group("/template")
+		{
+			templates.GET("/list", r.TemplateHandler.QueryList)
+			templates.GET("/:id", r.TemplateHandler.GetByID)
+		}
 	}
 }
@@ -0,0 +1,451 @@
+// admin 运维命令，复用仓储层完成事故处理中常见的操作，无需调用需要登录的接口或手写 SQL
+//
+// 读取与服务相同的配置文件；多租户模式下通过 -tenant 指定操作的租户，不指定时操作默认 schema。
+// 密码参数省略时从标准输入读取一行，避免留在 shell 历史中。
+//
+//	go run ./cmd/admin create-admin-user -username ops -phone 13800000000
+//	go run ./cmd/admin reset-password -username alice
+//	go run ./cmd/admin grant-role -username alice -role root
+//	go run ./cmd/admin revoke-sessions -username alice
+//	go run ./cmd/admin run-migrations
+//	go run ./cmd/admin seed
+//	go run ./cmd/admin verify-config
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/internal/modules/iacc/auth"
+	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+// command 子命令
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"create-admin-user", "创建用户并授予角色（默认 root）", createAdminUser},
+	{"reset-password", "重置用户密码", resetPassword},
+	{"grant-role", "为用户追加一个角色，保留已有角色", grantRole},
+	{"revoke-sessions", "删除用户的登录设备并使已签发的刷新令牌失效", revokeSessions},
+	{"run-migrations", "对默认 schema 与所有租户 schema 执行数据库迁移", runMigrations},
+	{"seed", "写入种子数据：全部接口权限、基础角色与超级管理员，可重复执行", seed},
+	{"verify-config", "校验配置文件，并执行与服务启动时相同的检查", verifyConfig},
+}
+
+func main() {
+	gin.SetMode(gin.ReleaseMode)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(os.Stderr, "\n使用 admin <command> -h 查看子命令的参数")
+}
+
+// env 子命令共用的依赖，与服务使用相同的构造函数
+type env struct {
+	conf    *pkgs.Config
+	db      *sqlx.DB
+	logger  *zap.Logger
+	tables  *pkgs.TableNames
+	pool    *pkgs.TenantPool
+	ids     *pkgs.IDGenerator
+	hasher  *pkgs.PasswordHasher
+	cleanup []func()
+}
+
+func newEnv() (*env, error) {
+	conf, err := pkgs.NewConfig()
+	if err != nil {
+		return nil, fmt.Errorf("读取配置失败: %w", err)
+	}
+	logger, err := pkgs.NewLogger(conf)
+	if err != nil {
+		return nil, fmt.Errorf("创建日志失败: %w", err)
+	}
+	// 手机号、邮箱加密存储，写入前需要注册字段加密器
+	if _, err := pkgs.NewFieldCipher(conf); err != nil {
+		return nil, fmt.Errorf("初始化字段加密失败: %w", err)
+	}
+	hasher, err := pkgs.NewPasswordHasher(conf)
+	if err != nil {
+		return nil, fmt.Errorf("初始化密码哈希失败: %w", err)
+	}
+	db, err := pkgs.NewConnection(conf)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	e := &env{conf: conf, db: db, logger: logger, tables: pkgs.NewTableNames(conf), ids: pkgs.NewIDGenerator(conf), hasher: hasher}
+	e.cleanup = append(e.cleanup, func() { db.Close() })
+	batch, closeBatch, err := pkgs.NewBatchConnection(conf, db)
+	if err != nil {
+		e.close()
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	e.cleanup = append(e.cleanup, closeBatch)
+	var closePool func()
+	e.pool, closePool = pkgs.NewTenantPool(conf, db, batch, logger)
+	e.cleanup = append(e.cleanup, closePool)
+	return e, nil
+}
+
+// close 按创建的相反顺序释放资源
+func (e *env) close() {
+	for i := len(e.cleanup) - 1; i >= 0; i-- {
+		e.cleanup[i]()
+	}
+	e.logger.Sync()
+}
+
+// context 返回操作指定租户的请求上下文，租户不存在时报错，避免误操作默认 schema
+func (e *env) context(tenant string) (*gin.Context, error) {
+	if tenant != "" {
+		if !e.pool.Enabled() {
+			return nil, errors.New("未启用多租户模式，不能指定 -tenant")
+		}
+		exists, err := e.pool.Exists(context.Background(), tenant)
+		if err != nil {
+			return nil, fmt.Errorf("查询租户失败: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("租户 %q 不存在", tenant)
+		}
+	}
+	return pkgs.NewCommandContext(context.Background(), tenant), nil
+}
+
+// permissionCache 连接与服务相同的权限缓存，角色变更后使其失效；未配置 Redis 时为空操作
+func (e *env) permissionCache() *pkgs.PermissionCache {
+	client, closeClient := pkgs.NewRedisClient(e.conf)
+	e.cleanup = append(e.cleanup, closeClient)
+	return pkgs.NewPermissionCache(e.conf, client, e.logger)
+}
+
+// securityEvents 连接与服务相同的安全事件推送，退出前会刷新队列中的事件
+func (e *env) securityEvents() (*pkgs.SecurityEvents, error) {
+	events, closeEvents, err := pkgs.NewSecurityEvents(e.conf, e.logger)
+	if err != nil {
+		return nil, fmt.Errorf("初始化安全事件推送失败: %w", err)
+	}
+	e.cleanup = append(e.cleanup, closeEvents)
+	return events, nil
+}
+
+// settings 读取服务使用的运行时设置，创建用户、重置密码时按在线调整的密码策略校验
+func (e *env) settings() (*pkgs.Settings, error) {
+	settings := pkgs.NewSettings(e.conf, e.db, e.tables, e.logger)
+	if err := settings.Load(context.Background()); err != nil {
+		return nil, fmt.Errorf("读取运行时设置失败: %w", err)
+	}
+	return settings, nil
+}
+
+// readPassword 未通过参数指定密码时从标准输入读取一行
+func readPassword(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("读取密码失败: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func createAdminUser(args []string) error {
+	fs := flag.NewFlagSet("create-admin-user", flag.ExitOnError)
+	tenant := fs.String("tenant", "", "租户标识")
+	username := fs.String("username", "", "用户名")
+	phone := fs.String("phone", "", "手机号")
+	password := fs.String("password", "", "密码（与登录接口提交的值一致），为空时从标准输入读取")
+	role := fs.String("role", "root", "授予的角色名称")
+	fs.Parse(args)
+
+	pwd, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+	e, err := newEnv()
+	if err != nil {
+		return err
+	}
+	defer e.close()
+	c, err := e.context(*tenant)
+	if err != nil {
+		return err
+	}
+	settings, err := e.settings()
+	if err != nil {
+		return err
+	}
+	users := user.NewRepository(e.db, e.logger, e.tables, e.pool, e.ids, e.hasher, settings)
+	validator := pkgs.NewRequestValidator()
+
+	id, err := result.Pipe2(
+		mo.Ok(&user.CreateReq{Username: *username, Phone: *phone, Password: pwd}),
+		result.FlatMap(pkgs.ValidateV2[user.CreateReq](validator)),
+		result.FlatMap(users.Create(c)),
+	).Get()
+	if err != nil {
+		return fmt.Errorf("创建用户失败: %w", err)
+	}
+	fmt.Printf("created user %s (%s)\n", *username, id)
+
+	if _, err := users.GrantRole(c)(&user.GrantRoleReq{Username: *username, Role: *role}).Get(); err != nil {
+		return fmt.Errorf("用户已创建，授予角色 %s 失败（可重新执行 grant-role）: %w", *role, err)
+	}
+	e.permissionCache().Invalidate(c)
+	fmt.Printf("granted role %s\n", *role)
+	return nil
+}
+
+func resetPassword(args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	tenant := fs.String("tenant", "", "租户标识")
+	username := fs.String("username", "", "用户名")
+	password := fs.String("password", "", "新密码（与登录接口提交的值一致），为空时从标准输入读取")
+	fs.Parse(args)
+
+	pwd, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+	e, err := newEnv()
+	if err != nil {
+		return err
+	}
+	defer e.close()
+	c, err := e.context(*tenant)
+	if err != nil {
+		return err
+	}
+	settings, err := e.settings()
+	if err != nil {
+		return err
+	}
+	users := user.NewRepository(e.db, e.logger, e.tables, e.pool, e.ids, e.hasher, settings)
+
+	_, err = result.Pipe1(
+		pkgs.ValidateV2[user.ResetPasswordReq](pkgs.NewRequestValidator())(&user.ResetPasswordReq{Username: *username, Password: pwd}),
+		result.FlatMap(users.ResetPassword(c)),
+	).Get()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("password of %s reset\n", *username)
+	return nil
+}
+
+func grantRole(args []string) error {
+	fs := flag.NewFlagSet("grant-role", flag.ExitOnError)
+	tenant := fs.String("tenant", "", "租户标识")
+	username := fs.String("username", "", "用户名")
+	role := fs.String("role", "", "角色名称")
+	fs.Parse(args)
+
+	e, err := newEnv()
+	if err != nil {
+		return err
+	}
+	defer e.close()
+	c, err := e.context(*tenant)
+	if err != nil {
+		return err
+	}
+	settings, err := e.settings()
+	if err != nil {
+		return err
+	}
+	users := user.NewRepository(e.db, e.logger, e.tables, e.pool, e.ids, e.hasher, settings)
+	events, err := e.securityEvents()
+	if err != nil {
+		return err
+	}
+
+	audit := pkgs.NewAuditLog(e.pool, e.tables, e.logger)
+
+	granted, err := result.Pipe4(
+		pkgs.ValidateV2[user.GrantRoleReq](pkgs.NewRequestValidator())(&user.GrantRoleReq{Username: *username, Role: *role}),
+		result.FlatMap(users.GrantRole(c)),
+		result.Map(pkgs.InvalidatePermissionCache[user.GrantRoleRes](c, e.permissionCache())),
+		result.Map(pkgs.RecordSecurityEvent[user.GrantRoleRes](c, events, pkgs.SecurityEventRoleChange, map[string]any{"action": "grant_user_role", "username": *username, "role": *role, "source": "cli"})),
+		result.Map(func(granted user.GrantRoleRes) user.GrantRoleRes {
+			audit.Record(c, "grant_role", pkgs.AuditEntityUser, "", map[string]any{"username": *username, "role": *role, "source": "cli", "result": granted})
+			return granted
+		}),
+	).Get()
+	if err != nil {
+		return err
+	}
+	if granted == 0 {
+		fmt.Printf("%s already has role %s\n", *username, *role)
+		return nil
+	}
+	fmt.Printf("granted role %s to %s\n", *role, *username)
+	return nil
+}
+
+func revokeSessions(args []string) error {
+	fs := flag.NewFlagSet("revoke-sessions", flag.ExitOnError)
+	tenant := fs.String("tenant", "", "租户标识")
+	username := fs.String("username", "", "用户名")
+	fs.Parse(args)
+
+	e, err := newEnv()
+	if err != nil {
+		return err
+	}
+	defer e.close()
+	c, err := e.context(*tenant)
+	if err != nil {
+		return err
+	}
+	events, err := e.securityEvents()
+	if err != nil {
+		return err
+	}
+	settings, err := e.settings()
+	if err != nil {
+		return err
+	}
+	sessions := auth.NewRepository(e.db, e.logger, e.conf, e.tables, e.pool, e.ids, events, pkgs.NewTokenBlacklist(e.pool, e.tables, e.logger), e.hasher, settings)
+
+	devices, err := result.Pipe1(
+		pkgs.ValidateV2[auth.RevokeSessionsReq](pkgs.NewRequestValidator())(&auth.RevokeSessionsReq{Username: *username}),
+		result.FlatMap(sessions.RevokeSessions(c)),
+	).Get()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("revoked sessions of %s (%d device(s) removed); access tokens already issued stay valid until they expire (jwt.access_token_expire)\n", *username, devices)
+	return nil
+}
+
+func runMigrations(args []string) error {
+	fs := flag.NewFlagSet("run-migrations", flag.ExitOnError)
+	tenant := fs.String("tenant", "", "只迁移指定租户的 schema")
+	fs.Parse(args)
+
+	e, err := newEnv()
+	if err != nil {
+		return err
+	}
+	defer e.close()
+	return app.MigrateSchemas(e.db, e.conf, e.tables, e.pool, *tenant, os.Stdout)
+}
+
+func seed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	tenant := fs.String("tenant", "", "租户标识")
+	fs.Parse(args)
+
+	e, err := newEnv()
+	if err != nil {
+		return err
+	}
+	defer e.close()
+	c, err := e.context(*tenant)
+	if err != nil {
+		return err
+	}
+
+	res, err := pkgs.Seed(e.pool.DB(c), e.logger, e.tables, e.hasher)
+	if err != nil {
+		return err
+	}
+	// 运行中的服务在下一次读取权限缓存时发现版本号变化，重新编译接口权限
+	e.permissionCache().Invalidate(c)
+	fmt.Printf("seeded %d permission(s)", res.PermissionsCreated)
+	if len(res.RolesCreated) > 0 {
+		fmt.Printf(", created role(s) %s", strings.Join(res.RolesCreated, ", "))
+	}
+	fmt.Println("; super-admin user administrator has role root")
+	return nil
+}
+
+func verifyConfig(args []string) error {
+	fs := flag.NewFlagSet("verify-config", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "只校验配置文件，不连接数据库")
+	fs.Parse(args)
+
+	conf, err := pkgs.NewConfig()
+	if err != nil {
+		return fmt.Errorf("读取配置失败: %w", err)
+	}
+	// 服务启动时由各构造函数完成的校验
+	if _, err := pkgs.NewFieldCipher(conf); err != nil {
+		return fmt.Errorf("encryption: %w", err)
+	}
+	if _, err := pkgs.NewPasswordHasher(conf); err != nil {
+		return fmt.Errorf("password_hash: %w", err)
+	}
+	if _, err := pkgs.NewIDObfuscator(conf); err != nil {
+		return fmt.Errorf("id_obfuscation: %w", err)
+	}
+	if _, err := pkgs.NewTimeFormatter(conf); err != nil {
+		return fmt.Errorf("time: %w", err)
+	}
+	if _, err := pkgs.NewLocalizer(conf); err != nil {
+		return fmt.Errorf("locale: %w", err)
+	}
+	if _, err := pkgs.NewStorage(conf); err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	if *offline {
+		fmt.Println("config ok")
+		return nil
+	}
+
+	logger, err := pkgs.NewLogger(conf)
+	if err != nil {
+		return fmt.Errorf("创建日志失败: %w", err)
+	}
+	defer logger.Sync()
+	db, err := pkgs.NewConnection(conf)
+	if err != nil {
+		return fmt.Errorf("连接数据库失败: %w", err)
+	}
+	defer db.Close()
+	if err := app.Preflight(db, conf, pkgs.NewTableNames(conf), logger); err != nil {
+		return err
+	}
+	fmt.Println("config ok")
+	return nil
+}
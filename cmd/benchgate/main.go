@@ -0,0 +1,125 @@
+// benchgate 对比两次基准测试的结果，指标退化超过阈值时以非零状态退出
+//
+// 输入为 go test -bench -benchmem 的输出，同一基准多次运行（-count）时取中位数；
+// 对比 ns/op、B/op、allocs/op 三项指标，任一指标超过基线的 (1 + threshold%) 即视为退化。
+//
+//	go run ./cmd/benchgate -base test/bench/base.txt -new test/bench/new.txt -threshold 10
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 对比的指标
+var metrics = []string{"ns/op", "B/op", "allocs/op"}
+
+// 基准名称末尾的 GOMAXPROCS 后缀，如 BenchmarkUserQueryList/default-8
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+func main() {
+	base := flag.String("base", "test/bench/base.txt", "基线结果")
+	next := flag.String("new", "test/bench/new.txt", "本次结果")
+	threshold := flag.Float64("threshold", 10, "允许的退化百分比")
+	flag.Parse()
+
+	baseline, err := parseFile(*base)
+	if err != nil {
+		log.Fatalf("读取基线失败: %v", err)
+	}
+	current, err := parseFile(*next)
+	if err != nil {
+		log.Fatalf("读取本次结果失败: %v", err)
+	}
+
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	regressions := 0
+	fmt.Printf("%-50s %-10s %14s %14s %9s\n", "benchmark", "metric", "base", "new", "delta")
+	for _, name := range names {
+		for _, metric := range metrics {
+			now, ok := median(current[name][metric])
+			if !ok {
+				continue
+			}
+			old, ok := median(baseline[name][metric])
+			if !ok {
+				fmt.Printf("%-50s %-10s %14s %14.1f %9s\n", name, metric, "-", now, "new")
+				continue
+			}
+			delta := 0.0
+			if old > 0 {
+				delta = (now - old) / old * 100
+			}
+			mark := ""
+			if now > old*(1+*threshold/100) {
+				mark = "  REGRESSION"
+				regressions++
+			}
+			fmt.Printf("%-50s %-10s %14.1f %14.1f %+8.1f%%%s\n", name, metric, old, now, delta, mark)
+		}
+	}
+
+	if regressions > 0 {
+		fmt.Printf("\n%d 项指标退化超过 %.0f%%\n", regressions, *threshold)
+		os.Exit(1)
+	}
+}
+
+// parseFile 解析基准测试输出，返回 基准名称 -> 指标 -> 每次运行的值
+func parseFile(path string) (map[string]map[string][]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	results := map[string]map[string][]float64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// 结果行：名称 迭代次数 值 单位 [值 单位]...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") || len(fields)%2 != 0 {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if results[name] == nil {
+			results[name] = map[string][]float64{}
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			results[name][fields[i+1]] = append(results[name][fields[i+1]], value)
+		}
+	}
+	return results, scanner.Err()
+}
+
+func median(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2, true
+	}
+	return sorted[mid], true
+}
@@ -0,0 +1,43 @@
+// schemacheck 核对实体的 db 标签与数据库中的表结构，发现偏差时以非零状态退出
+//
+// 读取与服务相同的配置文件连接数据库，不执行迁移；检查已开启模块的实体，
+// 报告缺失的列、无法扫描的列类型以及非指针字段对应的可空列。适合在部署前或迁移之后的流水线中运行。
+//
+//	go run ./cmd/schemacheck
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+func main() {
+	conf, err := pkgs.NewConfig()
+	if err != nil {
+		log.Fatalf("读取配置失败: %v", err)
+	}
+	db, err := pkgs.NewConnection(conf)
+	if err != nil {
+		log.Fatalf("连接数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	problems, err := app.SchemaProblems(db, conf, pkgs.NewTableNames(conf))
+	if err != nil {
+		log.Fatalf("查询表结构失败: %v", err)
+	}
+	if len(problems) == 0 {
+		fmt.Println("schema ok")
+		return
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	fmt.Printf("%d schema problem(s) found\n", len(problems))
+	db.Close()
+	os.Exit(1)
+}
@@ -3,6 +3,8 @@
 // @title           Go-PG Demo API
 // @version         1.0
 // @description     This is a sample server for go-pg-demo server.
+// @description     Routes guarded by the permission middleware carry an x-permission extension (method + path) that must be granted to one of the caller's roles.
+// @description     Response versions are negotiated with the Accept header (application/vnd.gopgdemo.v1+json); requests without it get version 1, unsupported versions get 406.
 // @termsOfService  https://swagger.io/terms/
 //
 // @contact.name   API Support
@@ -22,11 +24,23 @@
 package main
 
 import (
+	"flag"
 	"go-pg-demo/internal/app"
 	"log"
+	"os"
 )
 
 func main() {
+	// -migrate 只执行数据库迁移（默认 schema 与所有租户 schema）后退出，不启动服务
+	migrate := flag.Bool("migrate", false, "run database migrations for all schemas and exit")
+	flag.Parse()
+	if *migrate {
+		if err := app.Migrate(os.Stdout); err != nil {
+			log.Fatalf("failed to run migrations: %v", err)
+		}
+		return
+	}
+
 	// 初始化应用
 	application, cleanup, err := app.InitializeApp()
 	if err != nil {
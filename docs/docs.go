@@ -24,33 +24,61 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "用户登录，获取访问令牌和刷新令牌",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/jobs": {
+            "get": {
+                "description": "按任务类型返回当前租户的队列状态：等待执行与执行中的任务数、最早等待任务的等待时长，以及统计窗口内成功、失败、取消的任务数、失败率与重试次数；同时分页返回任务（按创建时间倒序）。\n所有租户汇总的队列深度、最早等待时长与执行结果计数同时通过 /metrics 暴露（async_job_queue_depth、async_job_oldest_pending_seconds、async_job_runs_total）",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "运维管理"
                 ],
-                "summary": "用户登录",
+                "summary": "查询异步任务",
                 "parameters": [
                     {
-                        "description": "登录请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/auth.LoginReq"
-                        }
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页大小",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "running",
+                            "succeeded",
+                            "failed",
+                            "canceled"
+                        ],
+                        "type": "string",
+                        "description": "任务状态",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 24,
+                        "description": "统计窗口（小时）",
+                        "name": "windowHours",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -60,7 +88,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/auth.LoginRes"
+                                            "$ref": "#/definitions/admin.QueryJobsRes"
                                         }
                                     }
                                 }
@@ -73,80 +101,46 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
-                    "401": {
-                        "description": "用户名或密码错误",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            }
-        },
-        "/auth/me": {
-            "get": {
-                "description": "返回当前登录用户的信息、角色和权限列表",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "当前用户详情",
-                "responses": {
-                    "200": {
-                        "description": "成功",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/pkgs.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/auth.UserDetailRes"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
+                },
+                "security": [
+                    {
+                        "JWT": []
                     }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/jobs"
                 }
             }
         },
-        "/auth/refresh-token": {
+        "/admin/jobs/{id}/cancel": {
             "post": {
-                "description": "通过刷新令牌获取新的访问令牌",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "取消等待执行（包括失败后等待重试）的任务；执行中的任务无法中断，不能取消。取消后可以通过重试接口重新排队",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "运维管理"
                 ],
-                "summary": "刷新访问令牌",
+                "summary": "取消异步任务",
                 "parameters": [
                     {
-                        "description": "刷新令牌请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/auth.RefreshTokenReq"
-                        }
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "刷新成功",
+                        "description": "已取消",
                         "schema": {
                             "allOf": [
                                 {
@@ -156,7 +150,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/auth.RefreshTokenRes"
+                                            "$ref": "#/definitions/admin.JobActionRes"
                                         }
                                     }
                                 }
@@ -169,8 +163,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
-                    "401": {
-                        "description": "刷新令牌无效",
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "任务不在等待执行状态",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
@@ -181,22 +181,40 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/admin/jobs/:id/cancel"
                 }
             }
         },
-        "/auth/user-detail": {
-            "get": {
-                "description": "返回用户基本信息、角色列表、权限列表",
+        "/admin/jobs/{id}/retry": {
+            "post": {
+                "description": "将已失败或已取消的任务重新排队，下一轮轮询（约 5 秒）执行；已用完执行次数的任务额外允许执行一次",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "运维管理"
+                ],
+                "summary": "重试异步任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "获取当前用户详情",
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "已重新排队",
                         "schema": {
                             "allOf": [
                                 {
@@ -206,21 +224,27 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/auth.UserDetailRes"
+                                            "$ref": "#/definitions/admin.JobActionRes"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "400": {
+                        "description": "请求参数错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "404": {
-                        "description": "用户不存在",
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "任务未失败或未取消",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
@@ -231,41 +255,40 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            }
-        },
-        "/permission": {
-            "post": {
+                },
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "创建权限",
-                "consumes": [
-                    "application/json"
-                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/admin/jobs/:id/retry"
+                }
+            }
+        },
+        "/admin/offboard/{id}": {
+            "get": {
+                "description": "返回离职交接的任务状态（pending、running、succeeded、failed、canceled）、失败原因，以及完成后的交接报告（撤销的角色、终止的会话数、转交的模板数、停用时间）",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permission"
+                    "运维管理"
                 ],
-                "summary": "创建权限",
+                "summary": "查询离职交接",
                 "parameters": [
                     {
-                        "description": "创建权限请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/permission.CreatePermissionReq"
-                        }
+                        "type": "string",
+                        "description": "交接记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功，返回权限ID",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -275,7 +298,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/admin.GetOffboardingRes"
                                         }
                                     }
                                 }
@@ -288,23 +311,33 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
+                    "404": {
+                        "description": "离职交接记录不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            }
-        },
-        "/permission/list": {
-            "get": {
+                },
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "获取权限列表",
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/offboard/:id"
+                }
+            }
+        },
+        "/admin/offboard/{userId}": {
+            "post": {
+                "description": "以异步任务执行离职交接：撤销用户的全部角色、删除登录设备（终止会话）、将用户拥有的模板转交给交接人、停用账号，并生成交接报告。返回交接记录ID，通过 GET /admin/offboard/{id} 查询执行状态与报告；同一用户同时只能有一个进行中的交接",
                 "consumes": [
                     "application/json"
                 ],
@@ -312,40 +345,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "permission"
+                    "运维管理"
                 ],
-                "summary": "获取权限列表",
+                "summary": "发起离职交接",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "权限名称",
-                        "name": "name",
-                        "in": "query"
+                        "description": "离职用户ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "权限类型",
-                        "name": "type",
-                        "in": "query"
+                        "description": "交接参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.OffboardReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回权限列表",
+                        "description": "已提交",
                         "schema": {
                             "allOf": [
                                 {
@@ -355,7 +378,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/permission.QueryListRes"
+                                            "$ref": "#/definitions/admin.OffboardRes"
                                         }
                                     }
                                 }
@@ -363,7 +386,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数错误",
+                        "description": "请求参数错误或交接人不存在、已停用",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "用户已停用或已有进行中的交接",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
@@ -374,39 +409,31 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            }
-        },
-        "/permission/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "根据ID获取权限",
-                "consumes": [
-                    "application/json"
-                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/admin/offboard/:userId"
+                }
+            }
+        },
+        "/admin/permission-cache/rebuild": {
+            "post": {
+                "description": "直接修改数据库中的角色、权限数据后强制刷新授权状态，无需重启实例：递增当前租户的权限版本号使所有用户的缓存失效，并立即重新编译本实例的接口权限记录；\n其他实例在下一次读取缓存时发现版本号变化，同样重新编译。未配置 Redis 时只重新编译本实例，其他实例最多在 permission_cache.ttl 后刷新",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permission"
-                ],
-                "summary": "根据ID获取权限",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "权限ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "运维管理"
                 ],
+                "summary": "重建权限缓存",
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回权限信息",
+                        "description": "重建成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -416,71 +443,44 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/permission.GetByIDRes"
+                                            "$ref": "#/definitions/admin.RebuildPermissionCacheRes"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
-                    "404": {
-                        "description": "权限不存在",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "根据ID更新权限",
-                "consumes": [
-                    "application/json"
-                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/admin/permission-cache/rebuild"
+                }
+            }
+        },
+        "/admin/permission-cache/status": {
+            "get": {
+                "description": "返回当前租户的权限缓存状态：Redis 熔断器状态、权限版本号与最近一次失效时间、当前版本号下缓存的用户数与已失效但尚未过期的用户数，\n以及处理本请求的实例启动以来的命中统计和本实例编译的接口权限记录（编译时间、记录数、是否已超过 permission_cache.ttl）。未配置 Redis 时只返回本实例的数据",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permission"
-                ],
-                "summary": "根据ID更新权限",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "权限ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "更新权限请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/permission.UpdatePermissionReq"
-                        }
-                    }
+                    "运维管理"
                 ],
+                "summary": "权限缓存状态",
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -490,57 +490,44 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/admin.PermissionCacheStatusRes"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "根据ID删除权限",
-                "consumes": [
-                    "application/json"
-                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/permission-cache/status"
+                }
+            }
+        },
+        "/admin/rate-limit/shadow": {
+            "get": {
+                "description": "返回当前租户在影子模式（限流等级配置 shadow: true）下超出限制的 API 密钥：会被拒绝的请求数、单个时间窗口内的最大请求数与首次、最近超出时间，用于在启用限流前校准各等级的限制。\n影子模式下超出限制的请求不会被拒绝，只在响应中附带 X-RateLimit-Warning 头。统计保存在各实例的内存中，重启后清空",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permission"
-                ],
-                "summary": "根据ID删除权限",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "权限ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "运维管理"
                 ],
+                "summary": "影子模式限流报告",
                 "responses": {
                     "200": {
-                        "description": "删除成功，返回影响行数",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -550,56 +537,44 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/admin.RateLimitShadowRes"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/rate-limit/shadow"
                 }
             }
         },
-        "/role": {
-            "post": {
-                "description": "创建角色",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/readiness": {
+            "get": {
+                "description": "分别检查主库（database）与分析库（reporting_database）连接，返回各项检查的耗时与结果；未配置分析库时该项为正常，详情说明报表查询使用主库。\n只有主库决定是否就绪：主库不可用时返回 503 业务码，data 中同样包含各项检查结果；分析库不可用只影响统计、导出等报表查询，不影响就绪状态。\n批处理连接池、Redis 与权限缓存的检查见支持包的 health.json",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
-                ],
-                "summary": "创建角色",
-                "parameters": [
-                    {
-                        "description": "创建角色请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/role.CreateReq"
-                        }
-                    }
+                    "运维管理"
                 ],
+                "summary": "就绪检查",
                 "responses": {
                     "200": {
-                        "description": "创建成功，返回角色ID",
+                        "description": "已就绪",
                         "schema": {
                             "allOf": [
                                 {
@@ -609,55 +584,65 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/admin.ReadinessRes"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
-                    "500": {
-                        "description": "服务器内部错误",
+                    "503": {
+                        "description": "主库不可用",
                         "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/admin.ReadinessRes"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/readiness"
                 }
             }
         },
-        "/role/batch-create": {
-            "post": {
-                "description": "批量创建角色",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/retention": {
+            "get": {
+                "description": "返回每个数据类别的保留策略、最近一次执行结果，以及下次执行（每天 03:00）将清理的行数和预告期内将陆续过期的行数",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "运维管理"
                 ],
-                "summary": "批量创建角色",
+                "summary": "数据保留策略",
                 "parameters": [
                     {
-                        "description": "批量创建角色请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/role.BatchCreateReq"
-                        }
+                        "type": "integer",
+                        "default": 7,
+                        "description": "预告天数",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功，返回角色ID列表",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -667,10 +652,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/admin.RetentionRes"
                                         }
                                     }
                                 }
@@ -689,12 +671,21 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/retention"
                 }
             }
         },
-        "/role/batch-delete": {
-            "post": {
-                "description": "批量删除角色",
+        "/admin/retention/{category}": {
+            "put": {
+                "description": "修改数据类别的保留天数与启用状态，停用后定时任务跳过该类别",
                 "consumes": [
                     "application/json"
                 ],
@@ -702,23 +693,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "运维管理"
                 ],
-                "summary": "批量删除角色",
+                "summary": "修改数据保留策略",
                 "parameters": [
                     {
-                        "description": "批量删除角色请求参数",
+                        "type": "string",
+                        "description": "数据类别",
+                        "name": "category",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "保留策略",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/role.DeleteRolesReq"
+                            "$ref": "#/definitions/admin.UpdateRetentionReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功，返回影响行数",
+                        "description": "修改成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -742,53 +740,71 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
+                    "404": {
+                        "description": "数据类别不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/admin/retention/:category"
                 }
             }
         },
-        "/role/list": {
+        "/admin/role-permission-matrix": {
             "get": {
-                "description": "获取角色列表",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "供定期权限审计使用：每行一个权限（按名称排序、分页），每列一个角色（全部角色），单元格为该角色对该权限的授予效果 allow、deny 或未授予。\nformat=json 返回矩阵与权限总数；format=csv 返回当前页的 CSV 文件（表头为权限字段与角色名称），权限总数在 X-Total-Count 响应头中，逐页请求可以拼接出完整矩阵",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "role"
+                    "运维管理"
                 ],
-                "summary": "获取角色列表",
+                "summary": "导出角色权限矩阵",
                 "parameters": [
                     {
                         "type": "integer",
                         "default": 1,
-                        "description": "页码",
+                        "description": "页码（按权限分页）",
                         "name": "page",
                         "in": "query"
                     },
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "每页数量",
+                        "description": "每页权限数",
                         "name": "pageSize",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
                         "type": "string",
-                        "description": "角色名称",
-                        "name": "name",
+                        "default": "json",
+                        "description": "导出格式",
+                        "name": "format",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回角色列表",
+                        "description": "获取成功（format=csv 时为 CSV 文件）",
                         "schema": {
                             "allOf": [
                                 {
@@ -798,7 +814,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/role.QueryListRes"
+                                            "$ref": "#/definitions/admin.RolePermissionMatrixRes"
                                         }
                                     }
                                 }
@@ -817,34 +833,31 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/role-permission-matrix"
                 }
             }
         },
-        "/role/{id}": {
+        "/admin/settings": {
             "get": {
-                "description": "根据ID获取角色",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "返回可在线调整的设置项（令牌有效期、密码策略、公开接口限流等级）当前生效的取值、配置文件中的取值与最近一次修改。\nsource 为 database 时取值来自在线修改，为 config 时来自配置文件；设置对整个部署生效，不区分租户",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
-                ],
-                "summary": "根据ID获取角色",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "运维管理"
                 ],
+                "summary": "运行时设置",
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回角色信息",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -854,35 +867,32 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/role.GetByIDRes"
+                                            "$ref": "#/definitions/admin.SettingsRes"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
-                    "404": {
-                        "description": "角色不存在",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/settings"
                 }
             },
             "put": {
-                "description": "根据ID更新角色",
+                "description": "在一个事务内修改一个或多个设置项并记录变更历史，取值的格式与 GET /admin/settings 返回的 value 相同，取值为 null 时恢复为配置文件中的取值。\n任一设置项不存在或取值无效时不修改任何设置项。本实例立即生效，其他实例在下次重新加载（settings.reload_schedule，默认每分钟）后生效；令牌有效期只影响之后签发的令牌",
                 "consumes": [
                     "application/json"
                 ],
@@ -890,30 +900,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "运维管理"
                 ],
-                "summary": "根据ID更新角色",
+                "summary": "修改运行时设置",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "更新角色请求参数",
+                        "description": "设置项",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/role.UpdateByIDReq"
+                            "$ref": "#/definitions/admin.UpdateSettingsReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "修改成功，返回取值发生变化的设置项",
                         "schema": {
                             "allOf": [
                                 {
@@ -923,8 +926,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/admin.UpdateSettingsRes"
                                         }
                                     }
                                 }
@@ -932,7 +934,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数错误",
+                        "description": "请求参数错误、设置项不存在或取值无效",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
@@ -943,32 +945,53 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            },
-            "delete": {
-                "description": "根据ID删除角色",
-                "consumes": [
-                    "application/json"
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
                 ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/admin/settings"
+                }
+            }
+        },
+        "/admin/settings/history": {
+            "get": {
+                "description": "分页返回运行时设置的变更记录，最近的修改排在前面；old_value、new_value 为空表示配置文件中的取值",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "运维管理"
                 ],
-                "summary": "根据ID删除角色",
+                "summary": "设置变更历史",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "设置项",
+                        "name": "key",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功，返回影响行数",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -978,8 +1001,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/admin.SettingsHistoryRes"
                                         }
                                     }
                                 }
@@ -998,34 +1020,47 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/settings/history"
                 }
             }
         },
-        "/role/{id}/permission": {
+        "/admin/slow-queries": {
             "get": {
-                "description": "获取指定角色的权限列表",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "汇总 pg_stat_statements 中平均耗时最高的语句，对其中的 SELECT 生成通用执行计划（EXPLAIN GENERIC_PLAN），为带过滤条件的顺序扫描给出建索引语句；同时返回各表的顺序扫描/索引扫描统计。需要数据库启用 pg_stat_statements 扩展",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "运维管理"
                 ],
-                "summary": "获取角色权限列表",
+                "summary": "慢查询与索引建议",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "default": 20,
+                        "description": "返回条数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "default": 0,
+                        "description": "最小平均耗时（毫秒）",
+                        "name": "minMeanMs",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回权限列表",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1035,7 +1070,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/role.GetRolePermissionsRes"
+                                            "$ref": "#/definitions/admin.SlowQueriesRes"
                                         }
                                     }
                                 }
@@ -1043,13 +1078,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
-                    "404": {
-                        "description": "角色不存在",
+                        "description": "请求参数错误或未启用 pg_stat_statements",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
@@ -1060,41 +1089,31 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            },
-            "post": {
-                "description": "清空角色现有权限，并重新关联新的权限列表",
-                "consumes": [
-                    "application/json"
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
                 ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/slow-queries"
+                }
+            }
+        },
+        "/admin/snapshot": {
+            "get": {
+                "description": "在一致性读事务中导出 iacc_*、保存的列表视图、关注与模板表（模板模块开启时）的全部数据，用于复制预发环境或灾备演练，通过 POST /admin/snapshot/restore 恢复。\n不包含密码、API 密钥、登录设备等凭据，也不包含异步任务、角色变更记录等运行数据；手机号等加密字段保持密文，只能恢复到字段加密密钥相同的环境",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
-                ],
-                "summary": "为角色分配权限",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "分配权限的请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/role.AssignPermissionsReq"
-                        }
-                    }
+                    "运维管理"
                 ],
+                "summary": "导出环境快照",
                 "responses": {
                     "200": {
-                        "description": "分配成功",
+                        "description": "导出成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1104,32 +1123,34 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/admin.Snapshot"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/snapshot"
                 }
             }
         },
-        "/template": {
+        "/admin/snapshot/restore": {
             "post": {
-                "description": "创建模板",
+                "description": "在一个事务内恢复 GET /admin/snapshot 导出的快照，任一表失败时全部回滚；快照中没有的表不做改动。\nmode=merge（默认）按主键新增或覆盖；mode=replace 额外删除快照中不存在的行。新增的用户没有密码，需要重置密码后登录；已存在的用户保留原密码",
                 "consumes": [
                     "application/json"
                 ],
@@ -1137,23 +1158,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "template"
+                    "运维管理"
                 ],
-                "summary": "创建模板",
+                "summary": "恢复环境快照",
                 "parameters": [
                     {
-                        "description": "创建模板请求参数",
+                        "description": "恢复快照请求参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/template.CreateReq"
+                            "$ref": "#/definitions/admin.RestoreSnapshotReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功，返回模板ID",
+                        "description": "恢复成功，返回各表新增或覆盖、删除的行数",
                         "schema": {
                             "allOf": [
                                 {
@@ -1163,7 +1184,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/admin.RestoreSnapshotRes"
                                         }
                                     }
                                 }
@@ -1171,7 +1192,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数错误",
+                        "description": "请求参数错误或快照格式无效",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "字段加密密钥不一致或与现有数据冲突",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
@@ -1182,12 +1209,56 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/admin/snapshot/restore"
                 }
             }
         },
-        "/template/batch-create": {
+        "/admin/support-bundle": {
+            "get": {
+                "description": "生成提交问题报告时附带的诊断信息 zip 包，包含：manifest.json（生成时间、请求ID、运行时信息）、config.json（脱敏后的配置，密码、密钥、令牌、地址等非空配置项替换为 [REDACTED]）、\nerrors.json（处理本请求的实例最近 200 条 error 级别日志，已脱敏）、migrations.json（内嵌迁移的最新版本与默认 schema、各租户 schema 记录的版本）、pools.json（本实例各连接池的统计）、\nhealth.json（数据库、批处理连接池、Redis、权限缓存熔断器的检查结果）。各项信息读取失败时记录在对应文件中，不影响下载",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "运维管理"
+                ],
+                "summary": "下载支持包",
+                "responses": {
+                    "200": {
+                        "description": "支持包",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/admin/support-bundle"
+                }
+            }
+        },
+        "/admin/tokens/revoke": {
             "post": {
-                "description": "批量创建模板",
+                "description": "事件响应时按用户、签发时间、客户端类型批量撤销令牌，条件同时满足，至少指定一个：签发时间不晚于 issued_before（默认当前时间）、属于 user_ids（为空表示全部用户）、\n由 client_type 类型客户端签发（为空表示全部客户端）的刷新令牌不能再换取新令牌；持有命中令牌的用户已签发的访问令牌立即失效，未命中条件的刷新令牌仍可换取新的访问令牌。\n返回写入的撤销条件、撤销的刷新令牌数（tokens，不含已撤销的令牌）与持有这些令牌的用户数（users）",
                 "consumes": [
                     "application/json"
                 ],
@@ -1195,23 +1266,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "template"
+                    "运维管理"
                 ],
-                "summary": "批量创建模板",
+                "summary": "按条件批量撤销令牌",
                 "parameters": [
                     {
-                        "description": "批量创建模板请求参数",
+                        "description": "批量撤销令牌请求参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/template.BatchCreateReq"
+                            "$ref": "#/definitions/admin.RevokeTokensReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功，返回模板ID列表",
+                        "description": "撤销成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1221,10 +1292,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "type": "string"
-                                            }
+                                            "$ref": "#/definitions/admin.RevokeTokensRes"
                                         }
                                     }
                                 }
@@ -1243,12 +1311,21 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/admin/tokens/revoke"
                 }
             }
         },
-        "/template/batch-delete": {
+        "/api-key": {
             "post": {
-                "description": "批量删除模板",
+                "description": "为外部合作方创建访问 /public/v1 接口的 API 密钥，完整密钥只在创建时返回一次；tier 为运行时设置 public_api.tiers 中的限流等级（见 GET /admin/settings）",
                 "consumes": [
                     "application/json"
                 ],
@@ -1256,23 +1333,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "template"
+                    "API密钥"
                 ],
-                "summary": "批量删除模板",
+                "summary": "创建API密钥",
                 "parameters": [
                     {
-                        "description": "批量删除模板请求参数",
+                        "description": "创建API密钥请求参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/template.DeleteTemplatesReq"
+                            "$ref": "#/definitions/apikey.CreateReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功，返回影响行数",
+                        "description": "创建成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1282,8 +1359,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/apikey.CreateRes"
                                         }
                                     }
                                 }
@@ -1302,22 +1378,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/api-key"
                 }
             }
         },
-        "/template/list": {
+        "/api-key/list": {
             "get": {
-                "description": "获取模板列表",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "获取API密钥列表，不返回密钥本身",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "template"
+                    "API密钥"
                 ],
-                "summary": "获取模板列表",
+                "summary": "获取API密钥列表",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1335,28 +1417,14 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "模板名称",
+                        "description": "名称",
                         "name": "name",
                         "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "id",
-                        "description": "排序字段",
-                        "name": "orderBy",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "desc",
-                        "description": "排序顺序",
-                        "name": "order",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回模板列表",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1366,7 +1434,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/template.QueryListRes"
+                                            "$ref": "#/definitions/apikey.QueryListRes"
                                         }
                                     }
                                 }
@@ -1385,26 +1453,32 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/api-key/list"
                 }
             }
         },
-        "/template/{id}": {
-            "get": {
-                "description": "根据ID获取模板",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api-key/{id}": {
+            "delete": {
+                "description": "吊销后该密钥立即无法访问公开接口",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "template"
+                    "API密钥"
                 ],
-                "summary": "根据ID获取模板",
+                "summary": "吊销API密钥",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "模板ID",
+                        "description": "API密钥ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1412,7 +1486,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功，返回模板信息",
+                        "description": "吊销成功，返回影响行数",
                         "schema": {
                             "allOf": [
                                 {
@@ -1422,7 +1496,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/template.GetByIDRes"
+                                            "type": "integer",
+                                            "format": "int64"
                                         }
                                     }
                                 }
@@ -1435,22 +1510,27 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
-                    "404": {
-                        "description": "模板不存在",
-                        "schema": {
-                            "$ref": "#/definitions/pkgs.Response"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/api-key/:id"
                 }
-            },
-            "put": {
-                "description": "根据ID更新模板",
+            }
+        },
+        "/attribute": {
+            "post": {
+                "description": "为角色或权限登记自定义属性；enum 类型必须设置可选值。已有实体时不能直接创建必填属性，可先创建为非必填、为实体设置后再改为必填",
                 "consumes": [
                     "application/json"
                 ],
@@ -1458,30 +1538,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "template"
+                    "attribute"
                 ],
-                "summary": "根据ID更新模板",
+                "summary": "创建属性定义",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "模板ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "更新模板请求参数",
+                        "description": "创建属性定义请求参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/template.UpdateByIDReq"
+                            "$ref": "#/definitions/attribute.CreateReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "创建成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1491,8 +1564,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -1505,38 +1577,69 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
+                    "409": {
+                        "description": "属性已存在，或已有实体时创建必填属性",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            },
-            "delete": {
-                "description": "根据ID删除模板",
-                "consumes": [
-                    "application/json"
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
                 ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/attribute"
+                }
+            }
+        },
+        "/attribute/list": {
+            "get": {
+                "description": "按实体类型、属性键排序返回属性定义",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "template"
+                    "attribute"
                 ],
-                "summary": "根据ID删除模板",
+                "summary": "获取属性定义列表",
                 "parameters": [
                     {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "role",
+                            "permission"
+                        ],
                         "type": "string",
-                        "description": "模板ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "实体类型",
+                        "name": "entity",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功，返回影响行数",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1546,8 +1649,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/attribute.QueryListRes"
                                         }
                                     }
                                 }
@@ -1566,36 +1668,40 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/attribute/list"
                 }
             }
         },
-        "/user": {
-            "post": {
-                "description": "通过提供用户名、手机号、密码等信息创建一个新的用户账户。成功后返回新创建用户的唯一标识符(UUID)。",
-                "consumes": [
-                    "application/json"
-                ],
+        "/attribute/{id}": {
+            "get": {
+                "description": "返回属性定义详情",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "attribute"
                 ],
-                "summary": "创建一个新的用户账户",
+                "summary": "根据ID获取属性定义",
                 "parameters": [
                     {
-                        "description": "创建用户所需的请求体参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.CreateReq"
-                        }
+                        "type": "string",
+                        "description": "属性ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功创建用户，返回用户ID",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1605,7 +1711,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/attribute.GetByIDRes"
                                         }
                                     }
                                 }
@@ -1613,23 +1719,36 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数验证失败或格式不正确",
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "属性不存在",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误，无法创建用户",
+                        "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/attribute/:id"
                 }
-            }
-        },
-        "/user/batch-create": {
-            "post": {
-                "description": "批量创建用户",
+            },
+            "put": {
+                "description": "修改可选值、是否必填或描述；实体类型、属性键与值类型不能修改。已有实体使用的可选值不能移除，所有已有实体都设置了该属性才能设为必填",
                 "consumes": [
                     "application/json"
                 ],
@@ -1637,23 +1756,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "attribute"
                 ],
-                "summary": "批量创建用户",
+                "summary": "根据ID更新属性定义",
                 "parameters": [
                     {
-                        "description": "批量创建用户请求参数",
+                        "type": "string",
+                        "description": "属性ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新属性定义请求参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.BatchCreateReq"
+                            "$ref": "#/definitions/attribute.UpdateByIDReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功，返回用户ID列表",
+                        "description": "更新成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1663,10 +1789,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "type": "string"
-                                            }
+                                            "type": "integer",
+                                            "format": "int64"
                                         }
                                     }
                                 }
@@ -1679,37 +1803,51 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
+                    "404": {
+                        "description": "属性不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "已有实体的属性值与新的定义不一致",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            }
-        },
-        "/user/batch-delete": {
-            "post": {
-                "description": "批量删除用户",
-                "consumes": [
-                    "application/json"
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
                 ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/attribute/:id"
+                }
+            },
+            "delete": {
+                "description": "删除属性定义，并从所有实体中移除该属性的值",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "attribute"
                 ],
-                "summary": "批量删除用户",
+                "summary": "根据ID删除属性定义",
                 "parameters": [
                     {
-                        "description": "批量删除用户请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.DeleteUsersReq"
-                        }
+                        "type": "string",
+                        "description": "属性ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1744,56 +1882,94 @@ const docTemplate = `{
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/attribute/:id"
                 }
             }
         },
-        "/user/list": {
+        "/audit/export": {
             "get": {
-                "description": "获取系统中的用户列表，支持按手机号和用户名模糊搜索，并提供分页功能。",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "按创建时间范围（createdFrom/createdTo 或最近 days 天，二者必选其一）、操作人、实体、实体ID与操作筛选审计日志，按时间顺序导出为 CSV，时间为 UTC 的 RFC 3339 格式。\nviewId 为保存的筛选预设（POST /views 保存 entity 为 audit 的视图，config.filters 的键与本接口的查询参数一致），请求中未传入的参数使用预设中的值，便于定期的合规导出。\n匹配的行数不超过 audit.export_sync_rows 时直接返回 CSV 文件；超过时转为异步任务，返回 202 业务码与任务ID，通过 GET /audit/export/{id} 查询进度，完成后通过 GET /audit/export/{id}/download 下载（需要配置对象存储）",
                 "produces": [
+                    "text/csv",
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "audit"
                 ],
-                "summary": "获取用户列表（支持分页和筛选）",
+                "summary": "导出审计日志",
                 "parameters": [
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码，从1开始计算",
-                        "name": "page",
+                        "type": "string",
+                        "description": "创建时间起（RFC 3339 时间或 YYYY-MM-DD 日期）",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止，默认为当前时间",
+                        "name": "createdTo",
                         "in": "query"
                     },
                     {
-                        "maximum": 100,
-                        "minimum": 1,
                         "type": "integer",
-                        "default": 10,
-                        "description": "每页条目数",
-                        "name": "pageSize",
+                        "description": "最近天数，与 createdFrom、createdTo 二选一",
+                        "name": "days",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "手机号模糊搜索关键字",
-                        "name": "phone",
+                        "description": "操作人ID",
+                        "name": "actorId",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "role",
+                            "role_change",
+                            "permission",
+                            "user"
+                        ],
                         "type": "string",
-                        "description": "用户名模糊搜索关键字",
-                        "name": "username",
+                        "description": "实体",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "实体ID",
+                        "name": "entityId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "筛选预设ID",
+                        "name": "viewId",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功获取用户列表",
+                        "description": "CSV 文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "202": {
+                        "description": "匹配的记录较多，已转为异步导出",
                         "schema": {
                             "allOf": [
                                 {
@@ -1803,7 +1979,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/user.QueryListRes"
+                                            "$ref": "#/definitions/audit.ExportJobRes"
                                         }
                                     }
                                 }
@@ -1811,38 +1987,49 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数验证失败或格式不正确",
+                        "description": "请求参数错误，或匹配的记录较多且未配置对象存储",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "筛选预设不存在",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误，无法获取用户列表",
+                        "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/audit/export"
                 }
             }
         },
-        "/user/{id}": {
+        "/audit/export/{id}": {
             "get": {
-                "description": "通过指定的用户唯一标识符(UUID)来检索特定用户的详细信息。",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "返回自己发起的审计日志异步导出的任务状态（pending、running、succeeded、failed、canceled）与失败原因，ready 为 true 时可以下载",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "audit"
                 ],
-                "summary": "根据用户ID获取用户详情",
+                "summary": "查询异步导出",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "UUID",
-                        "description": "用户唯一标识符(UUID)",
+                        "description": "任务ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1850,7 +2037,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功获取用户信息",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1860,7 +2047,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/user.GetByIDRes"
+                                            "$ref": "#/definitions/audit.GetExportJobRes"
                                         }
                                     }
                                 }
@@ -1868,59 +2055,207 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "提供的用户ID格式无效",
+                        "description": "请求参数错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "404": {
-                        "description": "未找到指定ID的用户",
+                        "description": "导出任务不存在",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误，无法获取用户信息",
+                        "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            },
-            "put": {
-                "description": "通过指定的用户唯一标识符(UUID)来更新特定用户的密码和个人信息。只会更新请求中包含的字段。",
-                "consumes": [
-                    "application/json"
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
                 ],
-                "produces": [
-                    "application/json"
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/audit/export/:id"
+                }
+            }
+        },
+        "/audit/export/{id}/download": {
+            "get": {
+                "description": "下载自己发起的、已完成的审计日志异步导出文件",
+                "produces": [
+                    "text/csv",
+                    "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "audit"
                 ],
-                "summary": "根据用户ID更新用户信息",
+                "summary": "下载异步导出的文件",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "UUID",
-                        "description": "用户唯一标识符(UUID)",
+                        "description": "任务ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV 文件",
+                        "schema": {
+                            "type": "file"
+                        }
                     },
-                    {
-                        "description": "需要更新的用户信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    "400": {
+                        "description": "请求参数错误",
                         "schema": {
-                            "$ref": "#/definitions/user.UpdateByIDReq"
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "导出任务或导出文件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "导出任务尚未完成",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/audit/export/:id/download"
+                }
+            }
+        },
+        "/audit/list": {
+            "get": {
+                "description": "分页查询用户、角色、权限的创建、修改、删除与分配等管理操作的记录，包含操作人、请求方法与路由、来源IP与请求ID。\ndetail.changes 为操作前后发生变化的字段（{\"字段\": {\"before\": 旧值, \"after\": 新值}}），删除时 detail.before 为删除前的状态，创建时 detail.after 为创建后的状态；密码、手机号与个人信息不记录原值。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "查询审计日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，不统计总数",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "游标分页的每页条目数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作人ID",
+                        "name": "actorId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "role",
+                            "role_change",
+                            "permission",
+                            "user"
+                        ],
+                        "type": "string",
+                        "description": "实体",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "实体ID",
+                        "name": "entityId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作，如 create、update、patch、delete、batch_delete、assign_roles、assign_permissions",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "POST",
+                            "PUT",
+                            "PATCH",
+                            "DELETE"
+                        ],
+                        "type": "string",
+                        "description": "请求方法",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "按创建时间排序的顺序",
+                        "name": "order",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功更新用户信息",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1930,8 +2265,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/audit.ListRes"
                                         }
                                     }
                                 }
@@ -1939,36 +2273,43 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数验证失败或格式不正确",
+                        "description": "请求参数错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误，无法更新用户信息",
+                        "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            },
-            "delete": {
-                "description": "通过指定的用户唯一标识符(UUID)来删除特定用户。这是一个永久性操作，请谨慎使用。",
-                "consumes": [
-                    "application/json"
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
                 ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/audit/list"
+                }
+            }
+        },
+        "/audit/{id}/revert": {
+            "post": {
+                "description": "在一个事务中把实体恢复为操作前的状态，并写入一条 action 为 revert 的审计记录（detail.reverted_id 为被撤销的记录，detail.changes 与原操作相反）。\n可撤销的操作：角色的 assign_permissions、sync_permissions（恢复权限与命名空间授予），用户的 assign_roles（恢复角色），以及这些操作的撤销记录（即重做）。\n每条记录只能撤销一次；实体在此之后又被修改过、关键角色（变更需要审批）、原有的权限或角色已被删除时不能撤销。",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "audit"
                 ],
-                "summary": "根据用户ID删除用户",
+                "summary": "撤销审计记录对应的操作",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "UUID",
-                        "description": "用户唯一标识符(UUID)",
+                        "description": "审计记录ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1976,7 +2317,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功删除用户，返回受影响的行数",
+                        "description": "撤销成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1986,8 +2327,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "integer",
-                                            "format": "int64"
+                                            "$ref": "#/definitions/audit.RevertRes"
                                         }
                                     }
                                 }
@@ -1995,23 +2335,44 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "提供的用户ID格式无效",
+                        "description": "请求参数错误，或该操作不支持撤销",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "审计记录或实体不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "已被撤销、实体在此之后已被修改、关键角色或原有的权限、角色已被删除",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误，无法删除用户",
+                        "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/audit/:id/revert"
                 }
             }
         },
-        "/user/{id}/role": {
+        "/auth/change-password": {
             "post": {
-                "description": "为指定用户分配一个或多个角色。该操作会完全替换用户当前的所有角色关系。",
+                "description": "校验当前密码后设置新密码，新密码须满足密码策略（运行时设置 password_policy）；此前签发的刷新令牌全部失效（包括其他设备），当前访问令牌在过期前仍可使用",
                 "consumes": [
                     "application/json"
                 ],
@@ -2019,31 +2380,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "auth"
                 ],
-                "summary": "为用户分配角色",
+                "summary": "修改密码",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "UUID",
-                        "description": "用户唯一标识符(UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "要分配给用户的角色ID列表",
+                        "description": "修改密码请求参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.AssignRolesReq"
+                            "$ref": "#/definitions/auth.ChangePasswordReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功为用户分配角色",
+                        "description": "修改成功，返回影响行数",
                         "schema": {
                             "allOf": [
                                 {
@@ -2062,46 +2415,58 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数验证失败或格式不正确",
+                        "description": "请求参数错误、当前密码错误或新密码不满足密码策略",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "密码已被并发修改",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误，无法为用户分配角色",
+                        "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
             }
         },
-        "/user/{id}/roles": {
+        "/auth/devices": {
             "get": {
-                "description": "通过用户ID获取该用户拥有的所有角色信息，包括角色的基本信息和创建时间。",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "返回当前用户登录过的设备（按最近使用时间倒序）以及是否开启严格设备模式；携带 X-Device-ID 时标记当前设备",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "auth"
                 ],
-                "summary": "获取指定用户的角色列表",
+                "summary": "查询当前用户的设备",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "UUID",
-                        "description": "用户唯一标识符(UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "设备标识",
+                        "name": "X-Device-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功获取用户角色列表",
+                        "description": "成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2111,175 +2476,10699 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/user.GetRolesRes"
+                                            "$ref": "#/definitions/auth.QueryDevicesRes"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "提供的用户ID格式无效",
+                    "401": {
+                        "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误，无法获取用户角色列表",
+                        "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/pkgs.Response"
                         }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "auth.LoginReq": {
-            "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
-            "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
-                }
-            }
-        },
-        "auth.LoginRes": {
-            "type": "object",
-            "properties": {
-                "access_token": {
-                    "type": "string"
                 },
-                "expires_in": {
-                    "type": "integer"
-                },
-                "refresh_token": {
-                    "type": "string"
-                }
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
             }
         },
-        "auth.RefreshTokenReq": {
-            "type": "object",
+        "/auth/devices/strict-mode": {
+            "put": {
+                "description": "开启后只允许已登记设备使用绑定该设备的刷新令牌，且刷新时必须携带 X-Device-ID；未绑定设备的刷新令牌需重新登录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "设置严格设备模式",
+                "parameters": [
+                    {
+                        "description": "严格设备模式开关",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.StrictDeviceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "设置成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
+            }
+        },
+        "/auth/devices/{id}": {
+            "delete": {
+                "description": "删除设备记录，绑定该设备的刷新令牌随之失效（已签发的访问令牌在过期前仍可使用）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "删除当前用户的设备",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "设备记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "设备不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "用户登录，获取访问令牌和刷新令牌；携带设备标识时登记设备，刷新令牌绑定到该设备。\n开启 Cookie 鉴权（jwt.cookie.enabled）时同时写入 HttpOnly 的 access_token、refresh_token cookie 与前端可读的 csrf_token cookie，之后的修改类请求需通过 X-CSRF-Token 请求头回传 csrf_token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "用户登录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "设备标识（客户端生成并持久保存）",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "设备名称",
+                        "name": "X-Device-Name",
+                        "in": "header"
+                    },
+                    {
+                        "description": "登录请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.LoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.LoginRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户名或密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "撤销当前访问令牌；请求体携带刷新令牌时一并撤销（必须属于当前用户）。撤销的令牌在过期前访问接口或刷新均返回 401。\n开启 Cookie 鉴权时请求体未携带刷新令牌则撤销 refresh_token cookie 中的刷新令牌，退出成功后删除令牌 cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "退出登录",
+                "parameters": [
+                    {
+                        "description": "退出登录请求参数，只撤销访问令牌时传 {}",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.LogoutReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "退出成功，返回撤销的令牌数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "刷新令牌无效",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
+            }
+        },
+        "/auth/me": {
+            "get": {
+                "description": "返回当前登录用户的信息、角色和权限列表",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "当前用户详情",
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.UserDetailRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh-token": {
+            "post": {
+                "description": "通过刷新令牌获取新的访问令牌；绑定设备的刷新令牌在设备被删除或设备标识不一致时无效，开启严格设备模式后只允许已登记的设备刷新。\n开启 Cookie 鉴权时请求体可以传 {}，使用 refresh_token cookie 中的刷新令牌，此时必须携带 X-CSRF-Token 请求头；刷新成功后更新 cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "刷新访问令牌",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "设备标识",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSRF 令牌，使用 cookie 中的刷新令牌时必填",
+                        "name": "X-CSRF-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "刷新令牌请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.RefreshTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "刷新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.RefreshTokenRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "刷新令牌无效",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "CSRF 令牌校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/user-detail": {
+            "get": {
+                "description": "返回用户基本信息、角色列表、权限列表",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "获取当前用户详情",
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.UserDetailRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/blueprint": {
+            "post": {
+                "description": "创建入职蓝图，包含角色与默认个人信息；角色必须存在，通过 POST /user/from-blueprint/{blueprintId} 按蓝图创建用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blueprint"
+                ],
+                "summary": "创建蓝图",
+                "parameters": [
+                    {
+                        "description": "创建蓝图请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/blueprint.CreateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "蓝图名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/blueprint"
+                }
+            }
+        },
+        "/blueprint/list": {
+            "get": {
+                "description": "获取入职蓝图列表",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blueprint"
+                ],
+                "summary": "获取蓝图列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "蓝图名称",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/blueprint.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/blueprint/list"
+                }
+            }
+        },
+        "/blueprint/{id}": {
+            "get": {
+                "description": "返回蓝图详情，roles 为蓝图中仍存在的角色",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blueprint"
+                ],
+                "summary": "根据ID获取蓝图",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "蓝图ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/blueprint.GetByIDRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "蓝图不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/blueprint/:id"
+                }
+            },
+            "put": {
+                "description": "修改名称、描述、角色或默认个人信息，role_ids 与 profile 传入时整体替换；只影响之后按蓝图创建的用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blueprint"
+                ],
+                "summary": "根据ID更新蓝图",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "蓝图ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新蓝图请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/blueprint.UpdateByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/blueprint/:id"
+                }
+            },
+            "delete": {
+                "description": "删除蓝图，已按蓝图创建的用户不受影响",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blueprint"
+                ],
+                "summary": "根据ID删除蓝图",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "蓝图ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/blueprint/:id"
+                }
+            }
+        },
+        "/client": {
+            "post": {
+                "description": "登记客户端并配置令牌有效期（秒），登录时通过 client_id 选择客户端；刷新令牌有效期不能短于访问令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "登记客户端",
+                "parameters": [
+                    {
+                        "description": "登记客户端请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/client.CreateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "客户端标识已存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/client"
+                }
+            }
+        },
+        "/client/list": {
+            "get": {
+                "description": "获取已登记的客户端列表",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "获取客户端列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "web",
+                            "mobile",
+                            "service"
+                        ],
+                        "type": "string",
+                        "description": "客户端类型",
+                        "name": "client_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/client.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/client/list"
+                }
+            }
+        },
+        "/client/{id}": {
+            "get": {
+                "description": "根据ID获取客户端",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "根据ID获取客户端",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "客户端记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/client.GetByIDRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "客户端不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/client/:id"
+                }
+            },
+            "put": {
+                "description": "修改名称、类型、令牌有效期或启用状态，新有效期对之后签发的令牌生效；停用后该客户端不能登录与刷新令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "根据ID更新客户端",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "客户端记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新客户端请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/client.UpdateByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/client/:id"
+                }
+            },
+            "delete": {
+                "description": "删除后使用该客户端签发的刷新令牌无法再刷新",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "根据ID删除客户端",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "客户端记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/client/:id"
+                }
+            }
+        },
+        "/dev/factory": {
+            "post": {
+                "description": "一次请求生成指定数量的用户、角色、模板（仿真的姓名、手机号、邮箱等），用于压测与演示环境；同时生成用户和角色时每个用户随机分配一个本批次的角色，模板随机指定本批次的用户为所有者。只有配置 dev_factory.enabled 时才注册该接口，生产环境（server.mode 为 release）不允许开启",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dev"
+                ],
+                "summary": "批量生成测试数据",
+                "parameters": [
+                    {
+                        "description": "生成测试数据请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dev.FactoryReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "生成成功，返回批次号、用户密码与生成数据的ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dev.FactoryRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/dev/factory"
+                }
+            }
+        },
+        "/dev/token": {
+            "post": {
+                "description": "不经过登录流程为指定用户（user_id 或 username）或一组接口权限签发访问令牌与刷新令牌，供压测脚本（k6、vegeta 等）获取凭证。按权限集合签发时使用该集合专属的测试用户（用户名为 ci_ 加集合摘要），首次签发时创建用户、同名角色以及数据库中没有的接口权限。只有配置 dev_token.enabled 时才注册该接口，生产环境（server.mode 为 release）不允许开启",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dev"
+                ],
+                "summary": "签发测试令牌",
+                "parameters": [
+                    {
+                        "description": "签发测试令牌请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dev.MintTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "签发成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dev.MintTokenRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/dev/token"
+                }
+            }
+        },
+        "/permission": {
+            "post": {
+                "description": "创建权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "创建权限",
+                "parameters": [
+                    {
+                        "description": "创建权限请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/permission.CreatePermissionReq"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "entity"
+                        ],
+                        "type": "string",
+                        "description": "返回内容，entity 时返回完整权限（data 为 GetByIDRes）",
+                        "name": "return",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，返回权限ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/permission"
+                }
+            }
+        },
+        "/permission/count": {
+            "get": {
+                "description": "按与权限列表相同的筛选条件统计权限数量，不返回权限数据",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "统计权限数量",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "权限类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "按自定义属性筛选，格式为 键:值，可重复传入，需同时满足",
+                        "name": "attr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、type、method、path、code、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "权限数量",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/permission/count"
+                }
+            }
+        },
+        "/permission/exists": {
+            "get": {
+                "description": "按名称精确匹配检查权限是否存在，用于创建、改名前的提示",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "检查权限名称是否已存在",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限名称",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "是否存在",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "boolean"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/permission/exists"
+                }
+            }
+        },
+        "/permission/list": {
+            "get": {
+                "description": "获取权限列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "获取权限列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，只支持按创建时间排序，不统计总数",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "游标分页的每页数量",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "权限名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "权限类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "按自定义属性筛选，格式为 键:值，可重复传入，需同时满足",
+                        "name": "attr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、type、method、path、code、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 ETag，列表未变化时返回 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 Last-Modified，列表未变化时返回 304",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回权限列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/permission.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/permission/list"
+                }
+            }
+        },
+        "/permission/tree": {
+            "get": {
+                "description": "返回按菜单结构组织的全部权限，用于构建管理后台的菜单与按钮：menus 为根级菜单及其子树（子菜单、按钮、接口权限），\nungrouped 为不属于任何菜单的根级权限，按权限类型分组。子节点按菜单、按钮、接口、其他类型排列，同类型按名称排序；名称按 Accept-Language 返回翻译",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "查询权限树",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/permission.TreeRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/permission/tree"
+                }
+            }
+        },
+        "/permission/values": {
+            "get": {
+                "description": "返回满足筛选条件的权限中指定字段的各取值及数量，按数量降序，最多 100 个，用于填充筛选下拉框",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "查询权限字段取值",
+                "parameters": [
+                    {
+                        "enum": [
+                            "type",
+                            "method"
+                        ],
+                        "type": "string",
+                        "description": "字段",
+                        "name": "field",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "权限名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "权限类型",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "字段取值及数量",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/pkgs.DistinctValue"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/permission/values"
+                }
+            }
+        },
+        "/permission/{id}": {
+            "get": {
+                "description": "根据ID获取权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "根据ID获取权限",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回权限信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/permission.GetByIDRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "权限不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/permission/:id"
+                }
+            },
+            "put": {
+                "description": "根据ID更新权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "根据ID更新权限",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新权限请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/permission.UpdatePermissionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "权限下还有子节点，不能改为非菜单类型",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/permission/:id"
+                }
+            },
+            "delete": {
+                "description": "根据ID删除权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "根据ID删除权限",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/permission/:id"
+                }
+            },
+            "patch": {
+                "description": "按 JSON Merge Patch（RFC 7386）部分更新权限：未出现的字段保持不变，metadata 按字段递归合并，其中字段为 null 表示删除该字段",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "根据ID部分更新权限",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "部分更新权限请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/permission.PatchPermissionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "权限不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "权限下还有子节点，不能改为非菜单类型",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "PATCH",
+                    "path": "/v1/permission/:id"
+                }
+            }
+        },
+        "/permission/{id}/move": {
+            "put": {
+                "description": "将权限连同其子孙节点移到另一个菜单权限下，parent_id 为 null 时移到根级；父节点不能是节点自身或其子孙节点",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "移动权限树节点",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "移动权限请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/permission.MoveReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "移动成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或父节点无效",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "权限不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/permission/:id/move"
+                }
+            }
+        },
+        "/permission/{id}/translation": {
+            "get": {
+                "description": "返回权限显示名称的全部翻译，键为语言标签；列表与详情接口按 Accept-Language 请求头返回对应翻译",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "查询权限翻译",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/permission.GetTranslationsRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "权限不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/permission/:id/translation"
+                }
+            }
+        },
+        "/permission/{id}/translation/{locale}": {
+            "put": {
+                "description": "设置权限在指定语言下的显示名称，已有的翻译整体替换；语言标签不区分大小写，保存时规范化（如 zh-hans-cn 保存为 zh-Hans-CN）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "设置权限翻译",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "语言标签，如 en、en-US",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "翻译内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/permission.PutTranslationReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "设置成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "权限不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/permission/:id/translation/:locale"
+                }
+            },
+            "delete": {
+                "description": "删除权限在指定语言下的翻译，之后该语言返回原始名称",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permission"
+                ],
+                "summary": "删除权限翻译",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "语言标签",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数（翻译不存在时为 0）",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/permission/:id/translation/:locale"
+                }
+            }
+        },
+        "/role": {
+            "post": {
+                "description": "创建角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "创建角色",
+                "parameters": [
+                    {
+                        "description": "创建角色请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.CreateReq"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "entity"
+                        ],
+                        "type": "string",
+                        "description": "返回内容，entity 时返回完整角色（data 为 GetByIDRes）",
+                        "name": "return",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，返回角色ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/role"
+                }
+            }
+        },
+        "/role/batch-create": {
+            "post": {
+                "description": "批量创建角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "批量创建角色",
+                "parameters": [
+                    {
+                        "description": "批量创建角色请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.BatchCreateReq"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "entity"
+                        ],
+                        "type": "string",
+                        "description": "返回内容，entity 时返回完整角色列表（data 为 BatchCreateEntityRes）",
+                        "name": "return",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，返回角色ID列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/role/batch-create"
+                }
+            }
+        },
+        "/role/batch-delete": {
+            "post": {
+                "description": "批量删除角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "批量删除角色",
+                "parameters": [
+                    {
+                        "description": "批量删除角色请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.DeleteRolesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/role/batch-delete"
+                }
+            }
+        },
+        "/role/change/list": {
+            "get": {
+                "description": "分页查询关键角色暂存的变更，可按审批状态与角色筛选",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "获取关键角色的变更列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "审批状态",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "role_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回变更列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.QueryChangesRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/role/change/list"
+                }
+            }
+        },
+        "/role/change/{id}/approve": {
+            "post": {
+                "description": "审批通过并执行暂存的变更，提交人不能审批自己的变更；执行失败时变更保持待审批",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "审批通过关键角色的变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "变更ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "审批成功，返回变更的执行结果",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.ApproveChangeRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "不能审批自己提交的变更",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "变更不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "变更已审批",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/role/change/:id/approve"
+                }
+            }
+        },
+        "/role/change/{id}/reject": {
+            "post": {
+                "description": "驳回暂存的变更，提交人可以撤回自己的变更",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "驳回关键角色的变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "变更ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "驳回成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "变更不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "变更已审批",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/role/change/:id/reject"
+                }
+            }
+        },
+        "/role/count": {
+            "get": {
+                "description": "按与角色列表相同的筛选条件统计角色数量，不返回角色数据",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "统计角色数量",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "按自定义属性筛选，格式为 键:值，可重复传入，需同时满足",
+                        "name": "attr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、description、critical、parent_role_id、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "角色数量",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/role/count"
+                }
+            }
+        },
+        "/role/exists": {
+            "get": {
+                "description": "按名称精确匹配检查角色是否存在，用于创建、改名前的提示",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "检查角色名称是否已存在",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色名称",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "是否存在",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "boolean"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/role/exists"
+                }
+            }
+        },
+        "/role/list": {
+            "get": {
+                "description": "获取角色列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "获取角色列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，只支持按创建时间排序，不统计总数",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "游标分页的每页数量",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "角色名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "按自定义属性筛选，格式为 键:值，可重复传入，需同时满足",
+                        "name": "attr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、description、critical、parent_role_id、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 ETag，列表未变化时返回 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 Last-Modified，列表未变化时返回 304",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回角色列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/role/list"
+                }
+            }
+        },
+        "/role/values": {
+            "get": {
+                "description": "返回满足筛选条件的角色中指定字段的各取值及数量，按数量降序，最多 100 个，用于填充筛选下拉框",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "查询角色字段取值",
+                "parameters": [
+                    {
+                        "enum": [
+                            "critical"
+                        ],
+                        "type": "string",
+                        "description": "字段",
+                        "name": "field",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "角色名称",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "字段取值及数量",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/pkgs.DistinctValue"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/role/values"
+                }
+            }
+        },
+        "/role/{id}": {
+            "get": {
+                "description": "根据ID获取角色；include 指定扩展内容时在同一条查询中返回权限列表（permissions）与拥有该角色的用户数（userCount）\n权限列表按继承关系展开：包含全部祖先角色的权限，继承来的权限带 inherited_from（来源角色ID）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "根据ID获取角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "扩展内容，多个以逗号分隔：permissions, userCount",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回角色信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.GetByIDRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/role/:id"
+                }
+            },
+            "put": {
+                "description": "根据ID更新角色，只会更新请求中包含的字段；description、access_conditions、parent_role_id 传 null 时清空\nparent_role_id 不能是角色自身或其子孙角色，继承关系变化后拥有该角色及其子孙角色的用户权限立即变化",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "根据ID更新角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新角色请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.UpdateByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "关键角色的变更已提交，等待审批",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.StagedChangeRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/role/:id"
+                }
+            },
+            "delete": {
+                "description": "根据ID删除角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "根据ID删除角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "关键角色的变更已提交，等待审批",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.StagedChangeRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/role/:id"
+                }
+            },
+            "patch": {
+                "description": "按 JSON Merge Patch（RFC 7386）部分更新角色：未出现的字段保持不变，显式 null 清空可空字段（description、access_conditions、parent_role_id）",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "根据ID部分更新角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "部分更新角色请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.PatchByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "关键角色的变更已提交，等待审批",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.StagedChangeRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "PATCH",
+                    "path": "/v1/role/:id"
+                }
+            }
+        },
+        "/role/{id}/permission": {
+            "get": {
+                "description": "获取指定角色逐个分配的权限列表与命名空间授予，不含继承的权限（见角色详情 include=permissions）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "获取角色权限列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回权限列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.GetRolePermissionsRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/role/:id/permission"
+                }
+            },
+            "post": {
+                "description": "清空角色现有权限，并重新关联新的权限列表；deny_permission_ids 中的权限以拒绝效果关联，拒绝优先于其他角色的授予。不修改命名空间授予",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "为角色分配权限",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "分配权限的请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.AssignPermissionsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "分配成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "关键角色的变更已提交，等待审批",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.StagedChangeRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/role/:id/permission"
+                }
+            }
+        },
+        "/role/{id}/permission/sync": {
+            "put": {
+                "description": "将角色权限同步为请求中的完整集合：只插入缺少的关联、删除多余的关联、更新授予/拒绝效果变化的关联，未变化的关联保持不动；namespaces、deny_namespaces 按命名空间授予、拒绝权限（如 report 包含 report:export 等子命名空间下的全部权限，求值时展开），同样按差异同步；两者都不传时不修改命名空间授予",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "同步角色权限",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "角色最终应拥有的权限ID列表",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.SyncPermissionsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "同步成功，返回新增和移除的关联数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.SyncPermissionsRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "关键角色的变更已提交，等待审批",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.StagedChangeRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或权限不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/role/:id/permission/sync"
+                }
+            }
+        },
+        "/role/{id}/translation": {
+            "get": {
+                "description": "返回角色显示名称与描述的全部翻译，键为语言标签；列表与详情接口按 Accept-Language 请求头返回对应翻译",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "查询角色翻译",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/role.GetTranslationsRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/role/:id/translation"
+                }
+            }
+        },
+        "/role/{id}/translation/{locale}": {
+            "put": {
+                "description": "设置角色在指定语言下的显示名称与描述，已有的翻译整体替换；语言标签不区分大小写，保存时规范化（如 zh-hans-cn 保存为 zh-Hans-CN）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "设置角色翻译",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "语言标签，如 en、en-US",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "翻译内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.PutTranslationReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "设置成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/role/:id/translation/:locale"
+                }
+            },
+            "delete": {
+                "description": "删除角色在指定语言下的翻译，之后该语言返回原始名称与描述",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "删除角色翻译",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "语言标签",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数（翻译不存在时为 0）",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/role/:id/translation/:locale"
+                }
+            }
+        },
+        "/sandbox/token": {
+            "post": {
+                "description": "为当前用户签发只能访问指定接口的短时访问令牌，供集成方在真实的表结构与数据上试用接口。使用沙箱令牌的请求在一个最终回滚的事务中执行：写操作在本次请求内生效并正常返回结果，请求结束后全部撤销，响应带 X-Sandbox: rolled-back 头。接口必须是已注册的路由模板（如 \"PUT /v1/user/:id\"），纳入权限体系的接口当前用户必须拥有其权限；创建租户、开发环境接口、恢复归档模板等副作用不在数据库事务内的接口不能加入。沙箱令牌不能刷新。只有配置 sandbox.enabled 时才注册该接口",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sandbox"
+                ],
+                "summary": "签发沙箱令牌",
+                "parameters": [
+                    {
+                        "description": "签发沙箱令牌请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sandbox.IssueTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "签发成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/sandbox.IssueTokenRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或接口不能在沙箱中调用",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "无接口访问权限",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/sandbox/token"
+                }
+            }
+        },
+        "/sync/template": {
+            "get": {
+                "description": "客户端同步：返回当前用户的模板自检查点以来的新增、修改（op=upsert，附带完整数据）与删除（op=delete，来自墓碑），按变更时间排序。\n首次同步不传 checkpoint，只返回现存模板；保存响应中的 checkpoint 用于下次拉取，has_more 为 true 时继续拉取。\n最近几秒内的变更可能在下次拉取时重复返回，客户端按 ID 与 version 去重；检查点早于墓碑保留期限时返回 410，需清空检查点全量同步。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "拉取模板变更",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "上次拉取返回的检查点，为空时全量同步",
+                        "name": "checkpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每次返回的最大变更数，默认 100，最大 500",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "拉取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/template.SyncPullRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "410": {
+                        "description": "检查点已过期，需全量同步",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/sync/template"
+                }
+            },
+            "post": {
+                "description": "客户端同步：在一个事务内按顺序应用客户端的新增、修改与删除。新增时 base_version 为 0，ID 由客户端生成；\n修改与删除的 base_version 为客户端最后看到的 version，与服务端不一致时不应用，在 conflicts 中返回原因与服务端当前数据，由客户端合并后重新推送。\n删除已被删除的模板视为成功。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "推送模板变更",
+                "parameters": [
+                    {
+                        "description": "推送模板变更请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/template.SyncPushReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "推送完成，返回已应用的变更与冲突",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/template.SyncPushRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/sync/template"
+                }
+            }
+        },
+        "/template": {
+            "post": {
+                "description": "创建模板；required_permission 为可选的权限编码，设置后只有拥有该权限的用户可以看到模板，只能要求自己拥有的权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "创建模板",
+                "parameters": [
+                    {
+                        "description": "创建模板请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/template.CreateReq"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "entity"
+                        ],
+                        "type": "string",
+                        "description": "返回内容，entity 时返回完整模板（data 为 GetByIDRes）",
+                        "name": "return",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，返回模板ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/template/batch-archive": {
+            "post": {
+                "description": "在一个事务内归档多个模板，不满足条件的模板被跳过并在 skipped 中返回原因：模板不存在或不可见（not_found）、不属于当前用户且没有 template:manage_all 权限（forbidden）、已归档（archived）、仍被其他数据引用（referenced，referenced_by 为引用方）。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "批量归档模板",
+                "parameters": [
+                    {
+                        "description": "批量归档模板请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/template.BatchArchiveReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "归档完成，返回已归档与跳过的模板",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/template.BatchArchiveRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
+            }
+        },
+        "/template/batch-create": {
+            "post": {
+                "description": "批量创建模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "批量创建模板",
+                "parameters": [
+                    {
+                        "description": "批量创建模板请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/template.BatchCreateReq"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "entity"
+                        ],
+                        "type": "string",
+                        "description": "返回内容，entity 时返回完整模板列表（data 为 BatchCreateEntityRes）",
+                        "name": "return",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，返回模板ID列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/template/batch-delete": {
+            "post": {
+                "description": "批量删除模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "批量删除模板",
+                "parameters": [
+                    {
+                        "description": "批量删除模板请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/template.DeleteTemplatesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/template/count": {
+            "get": {
+                "description": "按与模板列表相同的筛选条件统计模板数量，不返回模板数据",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "统计模板数量",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "mine",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "mine",
+                        "description": "查询范围",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "数量（精确匹配）",
+                        "name": "num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最小数量（含）",
+                        "name": "numMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最大数量（含）",
+                        "name": "numMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、num、owner_id、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "模板数量",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有查看全部模板的权限",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/template/list": {
+            "get": {
+                "description": "获取模板列表，默认只返回当前用户的模板（匿名请求返回无所有者的模板），scope=all 需要 template:manage_all 权限；不返回当前用户缺少 required_permission 权限的模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "获取模板列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，只支持按创建时间排序，不统计总数",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "游标分页的每页数量",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "模板名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "name",
+                            "num",
+                            "created_at",
+                            "updated_at",
+                            "usage_count"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "排序字段",
+                        "name": "orderBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "排序顺序",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "mine",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "mine",
+                        "description": "查询范围",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "数量（精确匹配）",
+                        "name": "num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最小数量（含）",
+                        "name": "numMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最大数量（含）",
+                        "name": "numMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、num、owner_id、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 ETag，列表未变化时返回 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 Last-Modified，列表未变化时返回 304",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回模板列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/template.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "没有查看全部模板的权限",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/template/{id}": {
+            "get": {
+                "description": "根据ID获取模板，模板设置了 required_permission 而当前用户没有该权限时按不存在处理",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "根据ID获取模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功，返回模板信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/template.GetByIDRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "模板不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "根据ID更新模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "根据ID更新模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新模板请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/template.UpdateByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "缺少模板要求的权限",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "根据ID删除模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "根据ID删除模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "缺少模板要求的权限",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "按 JSON Merge Patch（RFC 7386）部分更新模板：未出现的字段保持不变，显式 null 清空可空字段（num）",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "根据ID部分更新模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "部分更新模板请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/template.PatchByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "缺少模板要求的权限",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/template/{id}/archive": {
+            "post": {
+                "description": "归档的模板不再出现在列表与计数中，仍可通过详情接口查看；只有所有者本人或拥有 template:manage_all 权限的用户可以操作。\n配置了对象存储时，归档超过 archive.after 的模板由定时任务导出到对象存储并从数据库删除，可通过恢复接口取回。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "归档模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "归档成功，返回模板",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/template.ArchiveRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "无权归档该模板",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "模板不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "模板已归档或仍被其他数据引用",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
+            }
+        },
+        "/template/{id}/restore": {
+            "post": {
+                "description": "模板仍在数据库中时清除归档标记；已导出到对象存储时读取归档文件重新写入（包括使用次数等钩子保存的关联数据），并删除归档文件。\n只有所有者本人或拥有 template:manage_all 权限的用户可以操作。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "恢复归档的模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "恢复成功，返回模板",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/template.RestoreRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "无权恢复该模板",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "模板不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "模板未归档",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
+            }
+        },
+        "/template/{id}/transfer": {
+            "post": {
+                "description": "将模板转移给其他用户，只有所有者本人或拥有 template:manage_all 权限的用户可以操作",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "转移模板所有权",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "转移模板请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/template.TransferReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "转移成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "无权转移该模板",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "模板不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ]
+            }
+        },
+        "/template/{id}/use": {
+            "post": {
+                "description": "模板使用次数加一，返回累计使用次数；列表可按 orderBy=usage_count 排序查看热门模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "记录模板使用",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "记录成功，返回累计使用次数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "模板不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenant": {
+            "post": {
+                "description": "创建租户 schema 并执行数据库迁移，同时初始化租户的管理员用户与 root 角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "租户管理"
+                ],
+                "summary": "创建租户",
+                "parameters": [
+                    {
+                        "description": "创建租户请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/tenant.CreateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/tenant.CreateRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或未启用多租户模式",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "租户已存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/tenant"
+                }
+            }
+        },
+        "/tenant/list": {
+            "get": {
+                "description": "列出所有已创建的租户",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "租户管理"
+                ],
+                "summary": "获取租户列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/tenant.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "未启用多租户模式",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/tenant/list"
+                }
+            }
+        },
+        "/user": {
+            "post": {
+                "description": "通过提供用户名、手机号、密码等信息创建一个新的用户账户。成功后返回新创建用户的唯一标识符(UUID)。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "创建一个新的用户账户",
+                "parameters": [
+                    {
+                        "description": "创建用户所需的请求体参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.CreateReq"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "entity"
+                        ],
+                        "type": "string",
+                        "description": "返回内容，entity 时返回完整用户（data 为 GetByIDRes，手机号、邮箱按权限脱敏）",
+                        "name": "return",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功创建用户，返回用户ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败或格式不正确",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法创建用户",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/user"
+                }
+            }
+        },
+        "/user/batch-create": {
+            "post": {
+                "description": "批量创建用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "批量创建用户",
+                "parameters": [
+                    {
+                        "description": "批量创建用户请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.BatchCreateReq"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "entity"
+                        ],
+                        "type": "string",
+                        "description": "返回内容，entity 时返回完整用户列表（data 为 BatchCreateEntityRes，手机号、邮箱按权限脱敏）",
+                        "name": "return",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功，返回用户ID列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/user/batch-create"
+                }
+            }
+        },
+        "/user/batch-delete": {
+            "post": {
+                "description": "批量删除用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "批量删除用户",
+                "parameters": [
+                    {
+                        "description": "批量删除用户请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.DeleteUsersReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/user/batch-delete"
+                }
+            }
+        },
+        "/user/check-availability": {
+            "post": {
+                "description": "批量创建用户前检查一批候选用户名与手机号，按请求顺序返回每一项是否可用，只执行一次查询。已被已有用户使用的项原因为 taken，与请求中前面的项重复的项原因为 duplicate；各最多 1000 项。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "批量检查用户名、手机号是否可用",
+                "parameters": [
+                    {
+                        "description": "候选用户名与手机号",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.CheckAvailabilityReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "每一项的可用性",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.CheckAvailabilityRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "未传任何候选项或参数格式错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/user/check-availability"
+                }
+            }
+        },
+        "/user/count": {
+            "get": {
+                "description": "按与用户列表相同的筛选条件统计用户数量，不返回用户数据",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "统计用户数量",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "手机号搜索关键字（启用字段加密后为精确匹配）",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户名模糊搜索关键字",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "邮箱精确匹配",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、username、strict_device、disabled_at、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用户数量",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/user/count"
+                }
+            }
+        },
+        "/user/exists": {
+            "get": {
+                "description": "按手机号、用户名或邮箱精确匹配检查用户是否存在（如注册时判断手机号是否已被占用），同时传多个条件时需全部满足",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "检查用户是否存在",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "手机号",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户名",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "邮箱",
+                        "name": "email",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "是否存在",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "boolean"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "未传任何条件或参数格式错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/user/exists"
+                }
+            }
+        },
+        "/user/export": {
+            "get": {
+                "description": "按与用户列表相同的筛选条件与排序导出全部匹配的用户，不分页，边查询边写出文件。没有 user:view_pii 权限时手机号、邮箱脱敏导出；时间按请求时区格式化。",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "导出用户（CSV 或 XLSX）",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "导出格式",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "username",
+                            "phone",
+                            "created_at",
+                            "updated_at"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "排序字段",
+                        "name": "orderBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "排序顺序",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "手机号搜索关键字（启用字段加密后为精确匹配）",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户名模糊搜索关键字",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "邮箱精确匹配",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、username、strict_device、disabled_at、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导出文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/user/export"
+                }
+            }
+        },
+        "/user/from-blueprint/{blueprintId}": {
+            "post": {
+                "description": "在一个事务内创建用户、分配蓝图中的角色并填入蓝图的默认个人信息（请求中填写的字段优先）；蓝图中已删除的角色被跳过，返回实际分配的角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "按蓝图创建用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "蓝图ID",
+                        "name": "blueprintId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "创建用户请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.CreateFromBlueprintReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.CreateFromBlueprintRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "蓝图不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/user/from-blueprint/:blueprintId"
+                }
+            }
+        },
+        "/user/import": {
+            "post": {
+                "description": "上传 UTF-8 编码的 CSV 文件，首行为表头：username、phone、password 必填，email 可选，列的顺序不限；最多 1000 行、5 MB。\n每行按创建用户的规则校验，文件内用户名、手机号重复或与已有用户冲突的行被拒绝。atomic 方式在一个事务内导入，存在被拒绝的行时不导入任何用户；row 方式逐行导入，跳过被拒绝的行。\n返回导入成功的行与被拒绝的行及原因，行号从表头开始计数（第一个数据行为第 2 行）。\n通过校验的行数超过 user_import.sync_rows 时转为异步导入，返回业务码 202 与任务ID（data 为 ImportJobRes），通过 GET /user/import/{id} 查询导入报告。",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "导入用户（CSV）",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV 文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "row"
+                        ],
+                        "type": "string",
+                        "default": "atomic",
+                        "description": "导入方式",
+                        "name": "mode",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导入完成，返回导入报告",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.ImportRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "已转为异步导入，返回任务ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.ImportJobRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数或文件格式错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/user/import"
+                }
+            }
+        },
+        "/user/import/{id}": {
+            "get": {
+                "description": "返回自己发起的用户异步导入的任务状态（pending、running、succeeded、failed、canceled）与失败原因，任务成功后 report 为导入报告",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "查询异步导入",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.GetImportJobRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "导入任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/user/import/:id"
+                }
+            }
+        },
+        "/user/list": {
+            "get": {
+                "description": "获取系统中的用户列表，支持按手机号和用户名模糊搜索，并提供分页功能。没有 user:view_pii 权限时手机号、邮箱脱敏返回。开启 user_search 配置时查询定时刷新的物化视图，refreshed_at 为数据的刷新时间，之后新增、修改的用户在下次刷新后可见。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "获取用户列表（支持分页和筛选）",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码，从1开始计算",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页条目数",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，只支持按创建时间排序，不统计总数",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "游标分页的每页条目数",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "手机号搜索关键字（启用字段加密后为精确匹配）",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户名模糊搜索关键字",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "邮箱精确匹配",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、username、strict_device、disabled_at、created_at、updated_at",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止（含），传日期时包含当天全天",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间起（含）",
+                        "name": "updatedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "更新时间止（含），传日期时包含当天全天",
+                        "name": "updatedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据",
+                        "name": "changedSince",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 ETag，列表未变化时返回 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 Last-Modified，列表未变化时返回 304",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取用户列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败或格式不正确",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法获取用户列表",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/user/list"
+                }
+            }
+        },
+        "/user/{id}": {
+            "get": {
+                "description": "通过指定的用户唯一标识符(UUID)来检索特定用户的详细信息。没有 user:view_pii 权限时手机号、邮箱脱敏返回。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "根据用户ID获取用户详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "UUID",
+                        "description": "用户唯一标识符(UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "扩展内容，逗号分隔：roles（角色，最多100条）、permissions（经角色获得的权限，最多500条）、loginHistory（最近登录的设备，最多20条）",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取用户信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.GetByIDRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "提供的用户ID格式无效或扩展内容不支持",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "未找到指定ID的用户",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法获取用户信息",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/user/:id"
+                }
+            },
+            "put": {
+                "description": "通过指定的用户唯一标识符(UUID)来更新特定用户的密码和个人信息。只会更新请求中包含的字段；phone、profile 传 null 时清空。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "根据用户ID更新用户信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "UUID",
+                        "description": "用户唯一标识符(UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "需要更新的用户信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdateByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功更新用户信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败或格式不正确",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法更新用户信息",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/user/:id"
+                }
+            },
+            "delete": {
+                "description": "通过指定的用户唯一标识符(UUID)来删除特定用户。这是一个永久性操作，请谨慎使用。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "根据用户ID删除用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "UUID",
+                        "description": "用户唯一标识符(UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功删除用户，返回受影响的行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "提供的用户ID格式无效",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法删除用户",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/user/:id"
+                }
+            },
+            "patch": {
+                "description": "按 JSON Merge Patch（RFC 7386）部分更新用户：未出现的字段保持不变，显式 null 清空可空字段（phone、profile），profile 为对象时按字段递归合并，其中字段为 null 表示删除该字段。",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "根据用户ID部分更新用户信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "UUID",
+                        "description": "用户唯一标识符(UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "需要更新的用户字段",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.PatchByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功更新用户信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败或格式不正确",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "未找到指定ID的用户",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法更新用户信息",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "PATCH",
+                    "path": "/v1/user/:id"
+                }
+            }
+        },
+        "/user/{id}/profile": {
+            "patch": {
+                "description": "将 set 中的字段合并进已存储的个人信息，delete 中的字段从个人信息中删除，未提及的字段保持不变，客户端无需先读取整个 profile。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "合并更新用户个人信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "UUID",
+                        "description": "用户唯一标识符(UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "需要合并或删除的个人信息字段",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.PatchProfileReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功更新个人信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败或格式不正确",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "未找到指定ID的用户",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法更新个人信息",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "PATCH",
+                    "path": "/v1/user/:id/profile"
+                }
+            }
+        },
+        "/user/{id}/reset-password": {
+            "post": {
+                "description": "管理员为指定用户设置新密码，无需原密码；该用户此前签发的刷新令牌全部失效，需要使用新密码重新登录。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "重置用户密码",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "UUID",
+                        "description": "用户唯一标识符(UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "新密码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.ResetPasswordByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "重置成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败或格式不正确",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "未找到指定ID的用户",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法重置密码",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/user/:id/reset-password"
+                }
+            }
+        },
+        "/user/{id}/role": {
+            "post": {
+                "description": "为指定用户分配一个或多个角色。该操作会完全替换用户当前的所有角色关系。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "为用户分配角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "UUID",
+                        "description": "用户唯一标识符(UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "要分配给用户的角色ID列表",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.AssignRolesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功为用户分配角色",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数验证失败或格式不正确",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法为用户分配角色",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/user/:id/role"
+                }
+            }
+        },
+        "/user/{id}/roles": {
+            "get": {
+                "description": "通过用户ID获取该用户拥有的所有角色信息，包括角色的基本信息和创建时间。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "获取指定用户的角色列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "UUID",
+                        "description": "用户唯一标识符(UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取用户角色列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.GetRolesRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "提供的用户ID格式无效",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误，无法获取用户角色列表",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/user/:id/roles"
+                }
+            }
+        },
+        "/views": {
+            "get": {
+                "description": "返回当前用户在指定实体上可用的视图：自己保存的视图（owned 为 true，排在前面）以及共享给其所属角色的视图",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "view"
+                ],
+                "summary": "获取视图列表",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "role",
+                            "permission",
+                            "template",
+                            "blueprint",
+                            "client",
+                            "audit"
+                        ],
+                        "type": "string",
+                        "description": "实体",
+                        "name": "entity",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/view.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/views"
+                }
+            },
+            "post": {
+                "description": "为当前用户保存某类实体列表的视图（筛选条件、排序与显示列），同一实体下名称不能重复；shared_role_ids 中的角色的用户可以看到并使用该视图，但不能修改",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "view"
+                ],
+                "summary": "保存视图",
+                "parameters": [
+                    {
+                        "description": "保存视图请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/view.CreateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "保存成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "视图名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/views"
+                }
+            }
+        },
+        "/views/{id}": {
+            "put": {
+                "description": "修改自己保存的视图，config 与 shared_role_ids 传入时整体替换；共享得到的视图不能修改",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "view"
+                ],
+                "summary": "根据ID更新视图",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "视图ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新视图请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/view.UpdateByIDReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或角色不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "视图不存在或不是自己的视图",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "视图名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "PUT",
+                    "path": "/v1/views/:id"
+                }
+            },
+            "delete": {
+                "description": "删除自己保存的视图，共享得到的视图不能删除",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "view"
+                ],
+                "summary": "根据ID删除视图",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "视图ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/views/:id"
+                }
+            }
+        },
+        "/watch": {
+            "post": {
+                "description": "当前用户关注模板或用户，只能关注自己可以查看的实体（模板按所有者与 required_permission 判断，用户须拥有 GET /v1/user/:id 接口权限）；重复关注时返回原有的关注ID。\n其他人修改、归档、恢复、转移或删除该实体时发送通知（类型为 watch.{entity}.{action}），实体删除后关注自动取消；\n变更通知按发送时实体的可见范围筛选，之后不能再查看该实体的关注者不再收到通知",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watch"
+                ],
+                "summary": "关注实体",
+                "parameters": [
+                    {
+                        "description": "关注实体请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/watch.CreateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "关注成功，返回关注ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或不支持关注该实体",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "无权查看该用户",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "实体不存在",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/watch"
+                }
+            }
+        },
+        "/watch/list": {
+            "get": {
+                "description": "分页返回当前用户的关注，最近关注的排在前面；传 entity 与 entity_id 可查询是否关注了某个实体",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watch"
+                ],
+                "summary": "获取关注列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "template",
+                            "user"
+                        ],
+                        "type": "string",
+                        "description": "实体",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "实体ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/watch.QueryListRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "GET",
+                    "path": "/v1/watch/list"
+                }
+            }
+        },
+        "/watch/unwatch": {
+            "post": {
+                "description": "取消当前用户对某个实体的关注，用于在实体页面上直接取消而不需要先查询关注ID；没有关注时影响行数为 0",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watch"
+                ],
+                "summary": "按实体取消关注",
+                "parameters": [
+                    {
+                        "description": "取消关注请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/watch.UnwatchReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "取消成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "POST",
+                    "path": "/v1/watch/unwatch"
+                }
+            }
+        },
+        "/watch/{id}": {
+            "delete": {
+                "description": "取消自己的关注，不是自己的关注时影响行数为 0",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watch"
+                ],
+                "summary": "根据ID取消关注",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "关注ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "取消成功，返回影响行数",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pkgs.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/pkgs.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "x-permission": {
+                    "method": "DELETE",
+                    "path": "/v1/watch/:id"
+                }
+            }
+        }
+    },
+    "definitions": {
+        "admin.GetOffboardingRes": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "report": {
+                    "$ref": "#/definitions/admin.OffboardingReport"
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "successor_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.JobActionRes": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trace_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.JobItem": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trace_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.MatrixRole": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.MatrixRow": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "grants": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "method": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "permission_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.OffboardReq": {
+            "type": "object",
+            "required": [
+                "successor_id"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "successor_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.OffboardRes": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.OffboardingReport": {
+            "type": "object",
+            "properties": {
+                "disabled_at": {
+                    "type": "string"
+                },
+                "reassigned_templates": {
+                    "type": "integer"
+                },
+                "revoked_roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.RevokedRole"
+                    }
+                },
+                "terminated_sessions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.PermissionCacheStatusRes": {
+            "type": "object",
+            "properties": {
+                "circuit": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "generation": {
+                    "type": "integer"
+                },
+                "hit_rate": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "invalidated_at": {
+                    "type": "string"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "routes": {
+                    "$ref": "#/definitions/admin.PermissionRoutesItem"
+                },
+                "stale_entries": {
+                    "type": "integer"
+                },
+                "ttl_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.PermissionRoutesItem": {
+            "type": "object",
+            "properties": {
+                "age_seconds": {
+                    "type": "integer"
+                },
+                "compiled": {
+                    "type": "boolean"
+                },
+                "compiled_at": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "stale": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "admin.QueryJobsRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.JobItem"
+                    }
+                },
+                "stats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkgs.JobTypeStats"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.RateLimitShadowItem": {
+            "type": "object",
+            "properties": {
+                "api_key_id": {
+                    "type": "string"
+                },
+                "exceeded": {
+                    "type": "integer"
+                },
+                "first_exceeded_at": {
+                    "type": "string"
+                },
+                "key_prefix": {
+                    "type": "string"
+                },
+                "last_exceeded_at": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "peak": {
+                    "type": "integer"
+                },
+                "tier": {
+                    "type": "string"
+                },
+                "window_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.RateLimitShadowRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.RateLimitShadowItem"
+                    }
+                }
+            }
+        },
+        "admin.ReadinessRes": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.SupportBundleProbe"
+                    }
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "admin.RebuildPermissionCacheRes": {
+            "type": "object",
+            "properties": {
+                "generation": {
+                    "type": "integer"
+                },
+                "routes": {
+                    "$ref": "#/definitions/admin.PermissionRoutesItem"
+                }
+            }
+        },
+        "admin.RestoreSnapshotReq": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "merge",
+                        "replace"
+                    ]
+                },
+                "snapshot": {
+                    "$ref": "#/definitions/admin.Snapshot"
+                }
+            }
+        },
+        "admin.RestoreSnapshotRes": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string"
+                },
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.RestoreSnapshotTable"
+                    }
+                }
+            }
+        },
+        "admin.RestoreSnapshotTable": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "upserted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.RetentionRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkgs.RetentionSummary"
+                    }
+                }
+            }
+        },
+        "admin.RevokeTokensReq": {
+            "type": "object",
+            "properties": {
+                "client_type": {
+                    "description": "只撤销该类型客户端的刷新令牌，为空表示全部客户端",
+                    "type": "string",
+                    "enum": [
+                        "web",
+                        "mobile",
+                        "service"
+                    ]
+                },
+                "issued_before": {
+                    "description": "撤销在该时间及之前签发的刷新令牌，默认为当前时间",
+                    "type": "string"
+                },
+                "user_ids": {
+                    "description": "为空表示全部用户",
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "admin.RevokeTokensRes": {
+            "type": "object",
+            "properties": {
+                "client_type": {
+                    "type": "string"
+                },
+                "issued_before": {
+                    "type": "string"
+                },
+                "tokens": {
+                    "type": "integer"
+                },
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.RevokedRole": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.RolePermissionMatrixRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.MatrixRow"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.MatrixRole"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.SettingsHistoryItem": {
+            "type": "object",
+            "properties": {
+                "changed_by": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "new_value": {
+                    "type": "object"
+                },
+                "old_value": {
+                    "type": "object"
+                }
+            }
+        },
+        "admin.SettingsHistoryRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.SettingsHistoryItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.SettingsRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkgs.SettingItem"
+                    }
+                }
+            }
+        },
+        "admin.SlowQueriesRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.SlowQueryItem"
+                    }
+                },
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.TableScanItem"
+                    }
+                }
+            }
+        },
+        "admin.SlowQueryItem": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer"
+                },
+                "mean_ms": {
+                    "type": "number"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "query_id": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkgs.IndexSuggestion"
+                    }
+                },
+                "total_ms": {
+                    "type": "number"
+                }
+            }
+        },
+        "admin.Snapshot": {
+            "type": "object",
+            "required": [
+                "format",
+                "version"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "key_fingerprint": {
+                    "type": "string"
+                },
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.SnapshotTable"
+                    }
+                },
+                "version": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "admin.SnapshotTable": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "admin.SupportBundleProbe": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "admin.TableScanItem": {
+            "type": "object",
+            "properties": {
+                "idx_scan": {
+                    "type": "integer"
+                },
+                "live_tuples": {
+                    "type": "integer"
+                },
+                "needs_index": {
+                    "type": "boolean"
+                },
+                "seq_scan": {
+                    "type": "integer"
+                },
+                "table": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.UpdateRetentionReq": {
+            "type": "object",
+            "required": [
+                "enabled",
+                "retain_days"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "retain_days": {
+                    "type": "integer",
+                    "maximum": 3650,
+                    "minimum": 1
+                }
+            }
+        },
+        "admin.UpdateSettingsReq": {
+            "type": "object",
+            "required": [
+                "values"
+            ],
+            "properties": {
+                "values": {
+                    "type": "object"
+                }
+            }
+        },
+        "admin.UpdateSettingsRes": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "apikey.APIKeyItem": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key_prefix": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "tier": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "apikey.CreateReq": {
+            "type": "object",
+            "required": [
+                "name",
+                "tier"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "tier": {
+                    "type": "string"
+                }
+            }
+        },
+        "apikey.CreateRes": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "key_prefix": {
+                    "type": "string"
+                }
+            }
+        },
+        "apikey.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apikey.APIKeyItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "attribute.CreateReq": {
+            "type": "object",
+            "required": [
+                "entity",
+                "key",
+                "options",
+                "type"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "entity": {
+                    "type": "string",
+                    "enum": [
+                        "role",
+                        "permission"
+                    ]
+                },
+                "key": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "options": {
+                    "description": "enum 类型的可选值，其他类型不能设置",
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "required": {
+                    "description": "必填属性在创建、整体更新实体时必须提供；已有实体时不能直接创建必填属性",
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "string",
+                        "number",
+                        "boolean",
+                        "enum"
+                    ]
+                }
+            }
+        },
+        "attribute.GetByIDRes": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "attribute.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/attribute.GetByIDRes"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "attribute.UpdateByIDReq": {
+            "type": "object",
+            "required": [
+                "id",
+                "options"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "id": {
+                    "type": "string"
+                },
+                "options": {
+                    "description": "enum 类型的可选值，传入时整体替换；已有实体使用的值必须保留",
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "required": {
+                    "description": "设为必填时所有已有实体必须已经设置该属性",
+                    "type": "boolean"
+                }
+            }
+        },
+        "audit.AuditItem": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "actor_username": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "object"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "trace_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.ExportJobRes": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "audit.FieldChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                }
+            }
+        },
+        "audit.GetExportJobRes": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "ready": {
+                    "description": "任务成功后可通过 GET /audit/export/{id}/download 下载",
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.ListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audit.AuditItem"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "audit.RevertRes": {
+            "type": "object",
+            "properties": {
+                "audit_id": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/audit.FieldChange"
+                    }
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "reverted_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.ChangePasswordReq": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "maxLength": 72
+                }
+            }
+        },
+        "auth.DeviceItem": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LoginReq": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "client_id": {
+                    "description": "已登记的客户端标识，令牌有效期按客户端配置；未携带时使用配置文件中的默认有效期",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LoginRes": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LogoutReq": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.QueryDevicesRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.DeviceItem"
+                    }
+                },
+                "strict_device": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth.RefreshTokenReq": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.RefreshTokenRes": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.StrictDeviceReq": {
+            "type": "object",
+            "required": [
+                "enabled"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "auth.UserDetailRes": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.UserPermRes"
+                    }
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "profile": {
+                    "$ref": "#/definitions/user.Profile"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.UserRoleRes"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.UserPermRes": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.UserRoleRes": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "blueprint.CreateReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "profile": {
+                    "$ref": "#/definitions/user.Profile"
+                },
+                "role_ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "blueprint.GetByIDRes": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "profile": {
+                    "$ref": "#/definitions/user.Profile"
+                },
+                "role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/blueprint.RoleItem"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "blueprint.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/blueprint.GetByIDRes"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "blueprint.RoleItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "blueprint.UpdateByIDReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "profile": {
+                    "$ref": "#/definitions/user.Profile"
+                },
+                "role_ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "client.CreateReq": {
+            "type": "object",
+            "required": [
+                "access_token_ttl",
+                "client_id",
+                "client_type",
+                "name",
+                "refresh_token_ttl"
+            ],
+            "properties": {
+                "access_token_ttl": {
+                    "type": "integer",
+                    "minimum": 60
+                },
+                "client_id": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "client_type": {
+                    "type": "string",
+                    "enum": [
+                        "web",
+                        "mobile",
+                        "service"
+                    ]
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "refresh_token_ttl": {
+                    "type": "integer"
+                }
+            }
+        },
+        "client.GetByIDRes": {
+            "type": "object",
+            "properties": {
+                "access_token_ttl": {
+                    "type": "integer"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "client_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refresh_token_ttl": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "client.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.GetByIDRes"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "client.UpdateByIDReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "access_token_ttl": {
+                    "type": "integer",
+                    "minimum": 60
+                },
+                "client_type": {
+                    "type": "string",
+                    "enum": [
+                        "web",
+                        "mobile",
+                        "service"
+                    ]
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "refresh_token_ttl": {
+                    "type": "integer",
+                    "minimum": 60
+                }
+            }
+        },
+        "dev.FactoryReq": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "生成用户的登录密码，为空时使用 Passw0rd!",
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 6
+                },
+                "roles": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "templates": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "users": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "dev.FactoryRes": {
+            "type": "object",
+            "properties": {
+                "batch": {
+                    "description": "本次生成的批次号，出现在生成数据的名称中，便于识别和清理",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "生成用户的登录密码",
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dev.MintTokenReq": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "访问令牌有效期（秒），为空时使用 jwt.access_token_expire，不能超过 dev_token.max_expire",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "permissions": {
+                    "description": "接口权限，格式为 \"GET /v1/user/list\"；按权限集合签发时使用该集合专属的测试用户，同一集合重复签发复用同一用户",
+                    "type": "array",
+                    "maxItems": 200,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dev.MintTokenRes": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "访问令牌有效期（秒）",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.CreatePermissionReq": {
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "attributes": {
+                    "description": "自定义属性，只能使用已定义的属性，必须包含全部必填属性",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.Attributes"
+                        }
+                    ]
+                },
+                "metadata": {
+                    "$ref": "#/definitions/permission.Metadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "命名空间，如 user、report:export；不传时按编码或接口路径推断（report:export:csv 属于 report:export，/v1/user/list 属于 user）",
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "权限树中的父节点，必须是菜单权限；不传时位于根级",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.GetByIDRes": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "$ref": "#/definitions/pkgs.Attributes"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/permission.Metadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.GetTranslationsRes": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/pkgs.Translation"
+            }
+        },
+        "permission.Metadata": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.MoveReq": {
+            "type": "object",
+            "properties": {
+                "parent_id": {
+                    "description": "新的父节点，必须是菜单权限，且不能是节点自身或其子孙节点；为空时移到根级",
+                    "type": "string"
+                }
+            }
+        },
+        "permission.PatchPermissionReq": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "description": "自定义属性，与已有属性合并，属性值为 null 表示删除该属性",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.Attributes"
+                        }
+                    ]
+                },
+                "metadata": {
+                    "$ref": "#/definitions/permission.Metadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "命名空间，null 表示移出命名空间",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.PermissionItem": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "$ref": "#/definitions/pkgs.Attributes"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/permission.Metadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.PutTranslationReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/permission.PermissionItem"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "permission.TreeNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/permission.TreeNode"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/permission.Metadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.TreeRes": {
+            "type": "object",
+            "properties": {
+                "menus": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/permission.TreeNode"
+                    }
+                },
+                "ungrouped": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/permission.TreeNode"
+                        }
+                    }
+                }
+            }
+        },
+        "permission.UpdatePermissionReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "attributes": {
+                    "description": "自定义属性，传入时整体替换，必须包含全部必填属性",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.Attributes"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/permission.Metadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "命名空间，修改元数据时不会重新推断",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkgs.AccessConditions": {
+            "type": "object",
+            "properties": {
+                "cidrs": {
+                    "description": "允许访问的来源 IP 段（CIDR），为空表示不限 IP",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "time_windows": {
+                    "description": "允许访问的时间段，满足任一时间段即可；为空表示不限时间",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkgs.TimeWindow"
+                    }
+                }
+            }
+        },
+        "pkgs.Attributes": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "pkgs.DistinctValue": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "value": {}
+            }
+        },
+        "pkgs.IndexSuggestion": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ddl": {
+                    "type": "string"
+                },
+                "filter": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "table": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkgs.JobTypeStats": {
+            "type": "object",
+            "properties": {
+                "canceled": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "failure_rate": {
+                    "description": "窗口内结束的任务中失败的比例，没有结束的任务时为空",
+                    "type": "number"
+                },
+                "oldest_pending_seconds": {
+                    "type": "number"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "retries": {
+                    "type": "integer"
+                },
+                "running": {
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkgs.QueryPlan": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "description": "生成执行计划失败（如超时）时的原因，不影响请求本身",
+                    "type": "string"
+                },
+                "plan": {
+                    "type": "object"
+                },
+                "query": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkgs.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {},
+                "meta": {
+                    "description": "调试信息，只在请求了执行计划时返回（见 QueryPlanHeader）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.ResponseMeta"
+                        }
+                    ]
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkgs.ResponseMeta": {
+            "type": "object",
+            "properties": {
+                "query_plans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkgs.QueryPlan"
+                    }
+                }
+            }
+        },
+        "pkgs.RetentionPolicy": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_purged": {
+                    "type": "integer"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "retain_days": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkgs.RetentionSummary": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "cutoff": {
+                    "description": "早于该时间的数据在下次执行时清理",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expired": {
+                    "description": "下次执行时清理的行数",
+                    "type": "integer"
+                },
+                "policy": {
+                    "description": "未配置策略时为空，该类别不会被清理",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.RetentionPolicy"
+                        }
+                    ]
+                },
+                "upcoming": {
+                    "description": "预告期内将陆续过期的行数",
+                    "type": "integer"
+                }
+            }
+        },
+        "pkgs.SettingItem": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "配置文件中的取值",
+                    "type": "object"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "source": {
+                    "description": "取值来源，见 SettingSourceConfig/Database",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "在线修改的时间与修改人，来源为配置文件时为空",
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "当前生效的取值",
+                    "type": "object"
+                }
+            }
+        },
+        "pkgs.TimeWindow": {
+            "type": "object",
+            "required": [
+                "end",
+                "start"
+            ],
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "IANA 时区名称，为空时使用服务器本地时区",
+                    "type": "string"
+                },
+                "weekdays": {
+                    "description": "生效的星期，0 表示周日；为空表示每天",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "pkgs.Translation": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "role.ApproveChangeRes": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "result": {}
+            }
+        },
+        "role.AssignPermissionsReq": {
+            "type": "object",
+            "required": [
+                "permission_ids"
+            ],
+            "properties": {
+                "deny_permission_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "permission_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "role.BatchCreateReq": {
+            "type": "object",
+            "required": [
+                "roles"
+            ],
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/role.CreateReq"
+                    }
+                }
+            }
+        },
+        "role.CreateReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "access_conditions": {
+                    "description": "访问条件，请求不满足时该角色的权限不生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.AccessConditions"
+                        }
+                    ]
+                },
+                "attributes": {
+                    "description": "自定义属性，只能使用已定义的属性，必须包含全部必填属性",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.Attributes"
+                        }
+                    ]
+                },
+                "critical": {
+                    "description": "关键角色的修改、删除与权限变更需要另一位管理员审批",
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_role_id": {
+                    "description": "父角色，继承父角色及其祖先角色的全部权限",
+                    "type": "string"
+                }
+            }
+        },
+        "role.DeleteRolesReq": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "role.GetByIDRes": {
+            "type": "object",
+            "properties": {
+                "access_conditions": {
+                    "description": "访问条件，为空表示不限制",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.AccessConditions"
+                        }
+                    ]
+                },
+                "attributes": {
+                    "$ref": "#/definitions/pkgs.Attributes"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "critical": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_role_id": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "展开后的权限列表，include=permissions 时返回：角色自身与全部祖先角色的权限，\n同一权限只返回一次，任一角色拒绝即为拒绝，继承来的权限带上来源角色",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/role.PermissionItem"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_count": {
+                    "description": "拥有该角色的用户数，include=userCount 时返回",
+                    "type": "integer"
+                }
+            }
+        },
+        "role.GetRolePermissionsRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/role.PermissionItem"
+                    }
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/role.NamespaceGrantItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "role.GetTranslationsRes": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/pkgs.Translation"
+            }
+        },
+        "role.NamespaceGrantItem": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "effect": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "permission_count": {
+                    "description": "命名空间及其子命名空间下当前的权限数，之后新增到命名空间下的权限同样生效",
+                    "type": "integer"
+                }
+            }
+        },
+        "role.PatchByIDReq": {
+            "type": "object",
+            "properties": {
+                "access_conditions": {
+                    "description": "访问条件，显式 null 表示取消限制",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.AccessConditions"
+                        }
+                    ]
+                },
+                "attributes": {
+                    "description": "自定义属性，与已有属性合并，属性值为 null 表示删除该属性",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.Attributes"
+                        }
+                    ]
+                },
+                "critical": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_role_id": {
+                    "description": "父角色，显式 null 表示取消继承；不能是角色自身或其子孙角色",
+                    "type": "string"
+                }
+            }
+        },
+        "role.PermissionItem": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "effect": {
+                    "type": "string"
+                },
+                "granted_namespace": {
+                    "description": "按命名空间授予的权限所属的授予命名空间，逐个分配的权限为空；只在角色详情的展开权限中返回",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inherited_from": {
+                    "description": "继承来的权限所属的祖先角色，角色自身的权限为空；只在角色详情的展开权限中返回",
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "role.PutTranslationReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "role.QueryChangesRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/role.RoleChangeItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "role.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/role.RoleItem"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "role.RoleChangeItem": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "role.RoleItem": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "$ref": "#/definitions/pkgs.Attributes"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "critical": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_role_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "role.StagedChangeRes": {
+            "type": "object",
+            "properties": {
+                "change_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "role.SyncPermissionsReq": {
+            "type": "object",
+            "required": [
+                "permission_ids"
+            ],
+            "properties": {
+                "deny_namespaces": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny_permission_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "namespaces": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "permission_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "role.SyncPermissionsRes": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "role.UpdateByIDReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "access_conditions": {
+                    "description": "访问条件，传入时整体替换，传 null 时清空",
+                    "type": "object"
+                },
+                "attributes": {
+                    "description": "自定义属性，传入时整体替换，必须包含全部必填属性",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkgs.Attributes"
+                        }
+                    ]
+                },
+                "critical": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "description": "角色描述，传 null 时清空",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_role_id": {
+                    "description": "父角色，传 null 时取消继承；不能是角色自身或其子孙角色",
+                    "type": "string"
+                }
+            }
+        },
+        "sandbox.IssueTokenReq": {
+            "type": "object",
+            "required": [
+                "apis"
+            ],
+            "properties": {
+                "apis": {
+                    "description": "允许访问的接口，格式为 \"METHOD 路由模板\"，如 \"PUT /v1/user/:id\"",
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expires_in": {
+                    "description": "有效期（秒），为空时使用 sandbox.max_expire，不能超过 sandbox.max_expire",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "sandbox.IssueTokenRes": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "apis": {
+                    "description": "令牌允许访问的接口",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expires_in": {
+                    "description": "有效期（秒）",
+                    "type": "integer"
+                }
+            }
+        },
+        "template.ArchiveRes": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "num": {
+                    "type": "integer"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "required_permission": {
+                    "description": "查看模板需要的权限编码",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "usage_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "template.BatchArchiveReq": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "template.BatchArchiveRes": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "skipped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/template.BatchArchiveSkipped"
+                    }
+                }
+            }
+        },
+        "template.BatchArchiveSkipped": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "referenced_by": {
+                    "description": "原因为 referenced 时返回引用该模板的检查器名称",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "template.BatchCreateReq": {
+            "type": "object",
             "required": [
-                "refresh_token"
+                "templates"
             ],
             "properties": {
-                "refresh_token": {
-                    "type": "string"
+                "templates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/template.CreateReq"
+                    }
                 }
             }
         },
-        "auth.RefreshTokenRes": {
+        "template.CreateReq": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "access_token": {
+                "name": {
                     "type": "string"
                 },
-                "expires_in": {
-                    "type": "integer"
+                "num": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
                 },
-                "refresh_token": {
-                    "type": "string"
+                "required_permission": {
+                    "description": "查看模板需要的权限编码（如 template:finance），只有拥有该权限的用户能在列表与详情中看到模板",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
                 }
             }
         },
-        "auth.UserDetailRes": {
+        "template.DeleteTemplatesReq": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "template.GetByIDRes": {
             "type": "object",
             "properties": {
+                "archived_at": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "permissions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/auth.UserPermRes"
-                    }
+                "name": {
+                    "type": "string"
                 },
-                "phone": {
+                "num": {
+                    "type": "integer"
+                },
+                "owner_id": {
                     "type": "string"
                 },
-                "profile": {
-                    "$ref": "#/definitions/user.Profile"
+                "required_permission": {
+                    "description": "查看模板需要的权限编码",
+                    "type": "string"
                 },
-                "roles": {
+                "updated_at": {
+                    "type": "string"
+                },
+                "usage_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "template.PatchByIDReq": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "num": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
+                "required_permission": {
+                    "description": "显式 null 时取消访问限制",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "template.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/auth.UserRoleRes"
+                        "$ref": "#/definitions/template.TemplateItem"
                     }
                 },
-                "updated_at": {
+                "next_cursor": {
                     "type": "string"
                 },
-                "username": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "auth.UserPermRes": {
+        "template.RestoreRes": {
             "type": "object",
             "properties": {
-                "code": {
+                "archived_at": {
                     "type": "string"
                 },
-                "id": {
+                "created_at": {
                     "type": "string"
                 },
-                "method": {
+                "id": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "path": {
+                "num": {
+                    "type": "integer"
+                },
+                "owner_id": {
                     "type": "string"
                 },
-                "type": {
+                "required_permission": {
+                    "description": "查看模板需要的权限编码",
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
+                },
+                "usage_count": {
+                    "type": "integer"
                 }
             }
         },
-        "auth.UserRoleRes": {
+        "template.SyncApplied": {
             "type": "object",
             "properties": {
-                "description": {
+                "id": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "template.SyncChange": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/template.SyncTemplate"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "op": {
                     "type": "string"
                 },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "template.SyncConflict": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "reason": {
                     "type": "string"
+                },
+                "server": {
+                    "$ref": "#/definitions/template.SyncChange"
                 }
             }
         },
-        "permission.CreatePermissionReq": {
+        "template.SyncPullRes": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/template.SyncChange"
+                    }
+                },
+                "checkpoint": {
+                    "type": "string"
+                },
+                "has_more": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "template.SyncPushChange": {
             "type": "object",
             "required": [
-                "name",
-                "type"
+                "id",
+                "op"
             ],
             "properties": {
-                "metadata": {
-                    "$ref": "#/definitions/permission.Metadata"
+                "base_version": {
+                    "description": "客户端修改前看到的版本，0 表示客户端新建的模板",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "num": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "upsert",
+                        "delete"
+                    ]
+                }
+            }
+        },
+        "template.SyncPushReq": {
+            "type": "object",
+            "required": [
+                "changes"
+            ],
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/template.SyncPushChange"
+                    }
+                }
+            }
+        },
+        "template.SyncPushRes": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/template.SyncApplied"
+                    }
+                },
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/template.SyncConflict"
+                    }
+                }
+            }
+        },
+        "template.SyncTemplate": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "type": {
+                "num": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "permission.GetByIDRes": {
+        "template.TemplateItem": {
             "type": "object",
             "properties": {
                 "created_at": {
@@ -2288,64 +13177,92 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
-                "metadata": {
-                    "$ref": "#/definitions/permission.Metadata"
-                },
                 "name": {
                     "type": "string"
                 },
-                "type": {
+                "num": {
+                    "type": "integer"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "required_permission": {
+                    "description": "查看模板需要的权限编码",
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
+                "updated_at": {
+                    "type": "string"
+                },
+                "usage_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "template.TransferReq": {
+            "type": "object",
+            "required": [
+                "owner_id"
+            ],
+            "properties": {
+                "owner_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "template.UpdateByIDReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "num": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
+                "required_permission": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
                 }
             }
         },
-        "permission.Metadata": {
+        "tenant.CreateReq": {
             "type": "object",
+            "required": [
+                "tenant"
+            ],
             "properties": {
-                "code": {
-                    "type": "string"
-                },
-                "method": {
-                    "type": "string"
-                },
-                "path": {
-                    "type": "string"
+                "tenant": {
+                    "type": "string",
+                    "maxLength": 40
                 }
             }
         },
-        "permission.PermissionItem": {
+        "tenant.CreateRes": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "metadata": {
-                    "$ref": "#/definitions/permission.Metadata"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "type": {
+                "schema": {
                     "type": "string"
                 },
-                "updated_at": {
+                "tenant": {
                     "type": "string"
                 }
             }
         },
-        "permission.QueryListRes": {
+        "tenant.QueryListRes": {
             "type": "object",
             "properties": {
                 "list": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/permission.PermissionItem"
+                        "$ref": "#/definitions/tenant.TenantItem"
                     }
                 },
                 "total": {
@@ -2353,83 +13270,171 @@ const docTemplate = `{
                 }
             }
         },
-        "permission.UpdatePermissionReq": {
+        "tenant.TenantItem": {
+            "type": "object",
+            "properties": {
+                "schema": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.AssignRolesReq": {
             "type": "object",
             "required": [
-                "id"
+                "id",
+                "role_ids"
             ],
             "properties": {
                 "id": {
                     "type": "string"
                 },
-                "metadata": {
-                    "$ref": "#/definitions/permission.Metadata"
+                "role_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "user.AvailabilityItem": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean"
                 },
-                "name": {
+                "reason": {
+                    "description": "不可用的原因：taken 已被使用，duplicate 与请求中前面的项重复",
                     "type": "string"
                 },
-                "type": {
+                "value": {
                     "type": "string"
                 }
             }
         },
-        "pkgs.Response": {
+        "user.BatchCreateReq": {
             "type": "object",
+            "required": [
+                "users"
+            ],
             "properties": {
-                "code": {
-                    "type": "integer"
-                },
-                "data": {},
-                "msg": {
-                    "type": "string"
+                "users": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/user.CreateReq"
+                    }
                 }
             }
         },
-        "role.AssignPermissionsReq": {
+        "user.CheckAvailabilityReq": {
             "type": "object",
             "required": [
-                "permission_ids"
+                "phones",
+                "usernames"
             ],
             "properties": {
-                "permission_ids": {
+                "phones": {
                     "type": "array",
-                    "minItems": 1,
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "usernames": {
+                    "type": "array",
+                    "maxItems": 1000,
                     "items": {
                         "type": "string"
                     }
                 }
             }
         },
-        "role.BatchCreateReq": {
+        "user.CheckAvailabilityRes": {
+            "type": "object",
+            "properties": {
+                "phones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user.AvailabilityItem"
+                    }
+                },
+                "usernames": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user.AvailabilityItem"
+                    }
+                }
+            }
+        },
+        "user.CreateFromBlueprintReq": {
             "type": "object",
             "required": [
-                "roles"
+                "password",
+                "phone",
+                "username"
             ],
             "properties": {
-                "roles": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 11,
+                    "minLength": 11
+                },
+                "profile": {
+                    "$ref": "#/definitions/user.Profile"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.CreateFromBlueprintRes": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "role_ids": {
                     "type": "array",
-                    "minItems": 1,
                     "items": {
-                        "$ref": "#/definitions/role.CreateReq"
+                        "type": "string"
                     }
                 }
             }
         },
-        "role.CreateReq": {
+        "user.CreateReq": {
             "type": "object",
             "required": [
-                "name"
+                "password",
+                "phone",
+                "username"
             ],
             "properties": {
-                "description": {
-                    "type": "string"
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
                 },
-                "name": {
+                "phone": {
+                    "type": "string",
+                    "maxLength": 11,
+                    "minLength": 11
+                },
+                "profile": {
+                    "$ref": "#/definitions/user.Profile"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "role.DeleteRolesReq": {
+        "user.DeleteUsersReq": {
             "type": "object",
             "required": [
                 "ids"
@@ -2444,71 +13449,80 @@ const docTemplate = `{
                 }
             }
         },
-        "role.GetByIDRes": {
+        "user.GetByIDRes": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
+                "login_history": {
+                    "description": "最近登录的设备，include=loginHistory 时返回，按最近使用时间倒序，最多 20 条",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user.LoginItem"
+                    }
                 },
-                "updated_at": {
+                "permissions": {
+                    "description": "经角色获得的权限，include=permissions 时返回，最多 500 条；任一角色拒绝时 effect 为 deny",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user.PermissionItem"
+                    }
+                },
+                "phone": {
                     "type": "string"
-                }
-            }
-        },
-        "role.GetRolePermissionsRes": {
-            "type": "object",
-            "properties": {
-                "list": {
+                },
+                "profile": {
+                    "$ref": "#/definitions/user.Profile"
+                },
+                "roles": {
+                    "description": "角色列表，include=roles 时返回，最多 100 条",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/role.PermissionItem"
+                        "$ref": "#/definitions/user.RoleItem"
                     }
                 },
-                "total": {
-                    "type": "integer"
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "role.PermissionItem": {
+        "user.GetImportJobRes": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "id": {
+                "error": {
                     "type": "string"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": true
-                },
-                "name": {
+                "finished_at": {
                     "type": "string"
                 },
-                "type": {
+                "job_id": {
                     "type": "string"
                 },
-                "updated_at": {
+                "report": {
+                    "$ref": "#/definitions/user.ImportRes"
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "role.QueryListRes": {
+        "user.GetRolesRes": {
             "type": "object",
             "properties": {
                 "list": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/role.RoleItem"
+                        "$ref": "#/definitions/user.RoleItem"
                     }
                 },
                 "total": {
@@ -2516,236 +13530,236 @@ const docTemplate = `{
                 }
             }
         },
-        "role.RoleItem": {
+        "user.ImportAccepted": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
+                "line": {
+                    "type": "integer"
                 }
             }
         },
-        "role.UpdateByIDReq": {
+        "user.ImportJobRes": {
             "type": "object",
-            "required": [
-                "id"
-            ],
             "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "id": {
+                "job_id": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
-                }
-            }
-        },
-        "template.BatchCreateReq": {
-            "type": "object",
-            "required": [
-                "templates"
-            ],
-            "properties": {
-                "templates": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "$ref": "#/definitions/template.CreateReq"
-                    }
+                "rows": {
+                    "type": "integer"
                 }
             }
         },
-        "template.CreateReq": {
+        "user.ImportRejected": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "name": {
+                "line": {
+                    "type": "integer"
+                },
+                "reason": {
                     "type": "string"
                 },
-                "num": {
-                    "type": "integer",
-                    "maximum": 1000,
-                    "minimum": 1
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "template.DeleteTemplatesReq": {
+        "user.ImportRes": {
             "type": "object",
-            "required": [
-                "ids"
-            ],
             "properties": {
-                "ids": {
+                "accepted": {
                     "type": "array",
-                    "minItems": 1,
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/user.ImportAccepted"
+                    }
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "rejected": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user.ImportRejected"
                     }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "template.GetByIDRes": {
+        "user.LoginItem": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "device_id": {
                     "type": "string"
                 },
-                "id": {
+                "first_login_at": {
+                    "type": "string"
+                },
+                "last_ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "num": {
-                    "type": "integer"
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.PatchByIDReq": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
                 },
-                "updated_at": {
+                "phone": {
+                    "type": "string",
+                    "maxLength": 11,
+                    "minLength": 11
+                },
+                "profile": {
+                    "$ref": "#/definitions/user.Profile"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "template.QueryListRes": {
+        "user.PatchProfileReq": {
             "type": "object",
             "properties": {
-                "list": {
+                "delete": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/template.TemplateItem"
+                        "type": "string"
                     }
                 },
-                "total": {
-                    "type": "integer"
+                "set": {
+                    "$ref": "#/definitions/user.Profile"
                 }
             }
         },
-        "template.TemplateItem": {
+        "user.PermissionItem": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "effect": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
                 "name": {
                     "type": "string"
                 },
-                "num": {
-                    "type": "integer"
-                },
-                "updated_at": {
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "template.UpdateByIDReq": {
+        "user.Profile": {
             "type": "object",
-            "required": [
-                "id"
-            ],
             "properties": {
-                "id": {
+                "email": {
                     "type": "string"
+                }
+            }
+        },
+        "user.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user.UserItem"
+                    }
                 },
-                "name": {
+                "next_cursor": {
                     "type": "string"
                 },
-                "num": {
-                    "type": "integer",
-                    "maximum": 1000,
-                    "minimum": 1
+                "refreshed_at": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "user.AssignRolesReq": {
+        "user.ResetPasswordByIDReq": {
             "type": "object",
             "required": [
                 "id",
-                "role_ids"
+                "password"
             ],
             "properties": {
                 "id": {
                     "type": "string"
                 },
-                "role_ids": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    }
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
                 }
             }
         },
-        "user.BatchCreateReq": {
+        "user.RoleItem": {
             "type": "object",
-            "required": [
-                "users"
-            ],
             "properties": {
-                "users": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "$ref": "#/definitions/user.CreateReq"
-                    }
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "user.CreateReq": {
+        "user.UpdateByIDReq": {
             "type": "object",
             "required": [
-                "password",
-                "phone",
-                "username"
+                "id"
             ],
             "properties": {
-                "password": {
+                "id": {
                     "type": "string"
                 },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
                 "phone": {
+                    "description": "手机号，传 null 时清空",
                     "type": "string",
                     "maxLength": 11,
                     "minLength": 11
                 },
                 "profile": {
-                    "$ref": "#/definitions/user.Profile"
+                    "description": "个人信息，传入时整体替换，传 null 时清空",
+                    "type": "object"
                 },
                 "username": {
                     "type": "string"
                 }
             }
         },
-        "user.DeleteUsersReq": {
-            "type": "object",
-            "required": [
-                "ids"
-            ],
-            "properties": {
-                "ids": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    }
-                }
-            }
-        },
-        "user.GetByIDRes": {
+        "user.UserItem": {
             "type": "object",
             "properties": {
                 "created_at": {
@@ -2760,6 +13774,13 @@ const docTemplate = `{
                 "profile": {
                     "$ref": "#/definitions/user.Profile"
                 },
+                "role_names": {
+                    "description": "用户拥有的角色名称，按名称排序",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -2768,13 +13789,50 @@ const docTemplate = `{
                 }
             }
         },
-        "user.GetRolesRes": {
+        "view.CreateReq": {
+            "type": "object",
+            "required": [
+                "entity",
+                "name"
+            ],
+            "properties": {
+                "config": {
+                    "$ref": "#/definitions/view.ViewConfig"
+                },
+                "entity": {
+                    "description": "实体，与各模块的列表接口对应；audit 为审计日志导出（GET /v1/audit/export）的筛选预设",
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "role",
+                        "permission",
+                        "template",
+                        "blueprint",
+                        "client",
+                        "audit"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "shared_role_ids": {
+                    "description": "共享给这些角色的用户（只读）",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "view.QueryListRes": {
             "type": "object",
             "properties": {
                 "list": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/user.RoleItem"
+                        "$ref": "#/definitions/view.ViewItem"
                     }
                 },
                 "total": {
@@ -2782,35 +13840,68 @@ const docTemplate = `{
                 }
             }
         },
-        "user.Profile": {
+        "view.UpdateByIDReq": {
             "type": "object",
+            "required": [
+                "id"
+            ],
             "properties": {
-                "email": {
+                "config": {
+                    "$ref": "#/definitions/view.ViewConfig"
+                },
+                "id": {
                     "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "shared_role_ids": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "user.QueryListRes": {
+        "view.ViewConfig": {
             "type": "object",
             "properties": {
-                "list": {
+                "columns": {
+                    "description": "显示的列，按顺序排列",
                     "type": "array",
+                    "maxItems": 100,
                     "items": {
-                        "$ref": "#/definitions/user.UserItem"
+                        "type": "string"
                     }
                 },
-                "total": {
-                    "type": "integer"
+                "filters": {
+                    "description": "筛选条件，键为列表接口的查询参数，如 {\"username\": \"alice\"}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "order": {
+                    "type": "string"
+                },
+                "order_by": {
+                    "type": "string",
+                    "maxLength": 50
                 }
             }
         },
-        "user.RoleItem": {
+        "view.ViewItem": {
             "type": "object",
             "properties": {
+                "config": {
+                    "$ref": "#/definitions/view.ViewConfig"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
+                "entity": {
                     "type": "string"
                 },
                 "id": {
@@ -2819,55 +13910,89 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "owned": {
+                    "description": "是否为当前用户保存的视图，否则为通过角色共享的视图（只读）",
+                    "type": "boolean"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "shared_role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "user.UpdateByIDReq": {
+        "watch.CreateReq": {
             "type": "object",
             "required": [
-                "id"
+                "entity",
+                "entity_id"
             ],
             "properties": {
-                "id": {
-                    "type": "string"
+                "entity": {
+                    "type": "string",
+                    "enum": [
+                        "template",
+                        "user"
+                    ]
                 },
-                "password": {
+                "entity_id": {
                     "type": "string"
+                }
+            }
+        },
+        "watch.QueryListRes": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/watch.WatchItem"
+                    }
                 },
-                "phone": {
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "watch.UnwatchReq": {
+            "type": "object",
+            "required": [
+                "entity",
+                "entity_id"
+            ],
+            "properties": {
+                "entity": {
                     "type": "string",
-                    "maxLength": 11,
-                    "minLength": 11
-                },
-                "profile": {
-                    "$ref": "#/definitions/user.Profile"
+                    "enum": [
+                        "template",
+                        "user"
+                    ]
                 },
-                "username": {
+                "entity_id": {
                     "type": "string"
                 }
             }
         },
-        "user.UserItem": {
+        "watch.WatchItem": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "string"
-                },
-                "phone": {
+                "entity": {
                     "type": "string"
                 },
-                "profile": {
-                    "$ref": "#/definitions/user.Profile"
-                },
-                "updated_at": {
+                "entity_id": {
                     "type": "string"
                 },
-                "username": {
+                "id": {
                     "type": "string"
                 }
             }
@@ -2890,7 +14015,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/v1",
 	Schemes:          []string{},
 	Title:            "Go-PG Demo API",
-	Description:      "This is a sample server for go-pg-demo server.",
+	Description:      "This is a sample server for go-pg-demo server.\nRoutes guarded by the permission middleware carry an x-permission extension (method + path) that must be granted to one of the caller's roles.\nResponse versions are negotiated with the Accept header (application/vnd.gopgdemo.v1+json); requests without it get version 1, unsupported versions get 406.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 
 	v1 "go-pg-demo/api/v1"
@@ -15,8 +16,15 @@ import (
 	"go.uber.org/zap"
 )
 
-func NewGin() *gin.Engine {
-	return gin.New()
+// NewGin 创建 gin 引擎
+// 只信任 server.trusted_proxies 中的代理转发的 X-Forwarded-For / X-Real-IP，默认不信任任何代理，
+// 否则客户端可以伪造请求头绕过角色访问条件中的 IP 段限制。
+func NewGin(conf *pkgs.Config) (*gin.Engine, error) {
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(conf.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid server.trusted_proxies: %w", err)
+	}
+	return engine, nil
 }
 
 type App struct {
@@ -36,14 +44,33 @@ func NewApp(
 	middlewares []gin.HandlerFunc,
 	v1Router *v1.Router,
 	scheduler *pkgs.Scheduler,
+	tables *pkgs.TableNames,
+	settings *pkgs.Settings,
 ) (*App, error) {
 
-	// 数据库迁移
-	err := migration.RunMigrations(db, conf)
-	if err != nil {
+	// 数据库迁移，关闭 auto_migrate 时由 Preflight 检查迁移版本
+	if conf.Database.AutoMigrate {
+		if err := migration.RunMigrations(db, conf, tables); err != nil {
+			return nil, err
+		}
+	}
+
+	// 启动前检查
+	if err := Preflight(db, conf, tables, logger); err != nil {
+		return nil, err
+	}
+
+	// 核对实体与数据表结构
+	if err := CheckSchema(db, conf, tables, logger); err != nil {
 		return nil, err
 	}
 
+	// 加载运行时设置，之后定时重新加载其他实例修改的设置
+	if err := settings.Load(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	scheduler.Register("settings.reload", conf.Settings.ReloadSchedule, settings.Reload)
+
 	// 应用中间件
 	for _, middleware := range middlewares {
 		server.Use(middleware)
@@ -51,11 +78,19 @@ func NewApp(
 
 	v1Router.Register()
 
+	// Prometheus 指标
+	server.GET(pkgs.MetricsPath, pkgs.MetricsHandler())
+
 	// 添加Swagger路由，仅在非生产环境启用
 	if conf.Server.Mode != "release" {
 		server.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
+	// 检查重复路由与接口权限覆盖
+	if err := CheckRoutes(server, db, conf, tables, logger); err != nil {
+		return nil, err
+	}
+
 	return &App{
 		Server:    server,
 		Logger:    logger,
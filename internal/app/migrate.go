@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go-pg-demo/migration"
+	"go-pg-demo/pkgs"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Migrate 读取配置，对默认 schema 与所有租户 schema 执行数据库迁移后返回，不启动服务
+// 用于 server -migrate：部署流水线在滚动发布前单独执行迁移，服务实例关闭 database.auto_migrate 后启动时只检查迁移版本。
+func Migrate(out io.Writer) error {
+	conf, err := pkgs.NewConfig()
+	if err != nil {
+		return fmt.Errorf("读取配置失败: %w", err)
+	}
+	logger, err := pkgs.NewLogger(conf)
+	if err != nil {
+		return fmt.Errorf("创建日志失败: %w", err)
+	}
+	defer logger.Sync()
+	db, err := pkgs.NewConnection(conf)
+	if err != nil {
+		return fmt.Errorf("连接数据库失败: %w", err)
+	}
+	defer db.Close()
+	batch, closeBatch, err := pkgs.NewBatchConnection(conf, db)
+	if err != nil {
+		return fmt.Errorf("连接数据库失败: %w", err)
+	}
+	defer closeBatch()
+	pool, closePool := pkgs.NewTenantPool(conf, db, batch, logger)
+	defer closePool()
+	return MigrateSchemas(db, conf, pkgs.NewTableNames(conf), pool, "", out)
+}
+
+// MigrateSchemas 执行数据库迁移，tenant 为空时迁移默认 schema 与所有租户 schema，否则只迁移该租户的 schema
+// 租户逐个迁移，失败的租户汇总后返回，不影响其他租户；进度写入 out。
+func MigrateSchemas(db *sqlx.DB, conf *pkgs.Config, tables *pkgs.TableNames, pool *pkgs.TenantPool, tenant string, out io.Writer) error {
+	if tenant == "" {
+		if err := migration.RunMigrations(db, conf, tables); err != nil {
+			return fmt.Errorf("迁移默认 schema 失败: %w", err)
+		}
+		fmt.Fprintln(out, "migrated default schema")
+	}
+	if !pool.Enabled() {
+		if tenant != "" {
+			return errors.New("未启用多租户模式，不能指定 -tenant")
+		}
+		return nil
+	}
+
+	tenants := []string{tenant}
+	if tenant == "" {
+		var err error
+		if tenants, err = pool.List(context.Background()); err != nil {
+			return fmt.Errorf("查询租户列表失败: %w", err)
+		}
+	}
+	var failed []string
+	for _, t := range tenants {
+		schema := pool.SchemaName(t)
+		if err := migration.RunSchemaMigrations(db, conf, tables, schema); err != nil {
+			fmt.Fprintf(out, "migrate tenant %s: %v\n", t, err)
+			failed = append(failed, t)
+			continue
+		}
+		fmt.Fprintf(out, "migrated tenant %s (schema %s)\n", t, schema)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d tenant(s) failed: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
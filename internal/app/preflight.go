@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/migration"
+	"go-pg-demo/pkgs"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// JWT 密钥最小长度（HS256 建议不少于 32 字节）
+const minJWTSecretLength = 32
+
+// 常见的弱密钥，出现在生产环境中视为配置错误
+var weakJWTSecrets = []string{"my-secret-key", "secret", "changeme", "jwt-secret", "123456"}
+
+// 业务代码依赖的编码类权限，缺失时无法为任何角色授予对应能力
+var requiredPermissionCodes = []string{
+	pkgs.PermissionCodeViewPII,
+	middlewares.PermissionCodeViewDocs,
+}
+
+// 可关闭模块依赖的编码类权限，模块关闭时不检查
+var modulePermissionCodes = map[string][]string{
+	pkgs.ModuleTemplate: {template.PermissionCodeManageAll},
+}
+
+// Preflight 启动前检查，在数据库迁移之后、开始接收请求之前执行
+// 1. 预热数据库连接，确认数据库可用；
+// 2. 数据库迁移版本与内嵌迁移文件一致且不处于 dirty 状态（schema-per-tenant 模式下逐个检查租户 schema）；
+// 3. 预置的编码类权限记录存在；
+// 4. JWT 密钥强度满足要求（release 模式下不满足直接失败，其他模式仅告警）。
+// 所有失败项汇总为一个错误返回，每项都带有处理建议，避免上线后才在首个请求中暴露为 500。
+func Preflight(db *sqlx.DB, conf *pkgs.Config, tables *pkgs.TableNames, logger *zap.Logger) error {
+	var problems []string
+
+	if err := warmUp(db, conf); err != nil {
+		// 数据库不可用时后续检查都没有意义
+		return fmt.Errorf("preflight: database unreachable at %s:%d: %w (check database.host/port/username/password)",
+			conf.Database.Host, conf.Database.Port, err)
+	}
+
+	problems = append(problems, checkSchemaVersions(db, conf, tables)...)
+
+	if problem := checkPermissionSeeds(db, conf, tables); problem != "" {
+		problems = append(problems, problem)
+	}
+
+	if problem := CheckJWTSecret(conf.JWT.Secret); problem != "" {
+		if conf.Server.Mode == "release" {
+			problems = append(problems, problem)
+		} else {
+			logger.Warn("JWT 密钥强度不足，release 模式下将拒绝启动", zap.String("reason", problem))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("preflight checks failed:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	logger.Info("启动前检查通过")
+	return nil
+}
+
+// warmUp 建立并预热数据库连接池中的空闲连接
+func warmUp(db *sqlx.DB, conf *pkgs.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	idle := max(conf.Database.MaxIdleConns, 1)
+	conns := make([]*sqlx.Conn, 0, idle)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	for range idle {
+		conn, err := db.Connx(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+		if err := conn.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SchemaState 一个 schema 中记录的迁移状态
+type SchemaState struct {
+	// schema 名称，为空表示默认 schema
+	Schema  string
+	Version uint
+	Dirty   bool
+	// 读取迁移版本失败的原因
+	Err error
+}
+
+// checkSchemaVersions 读取默认 schema 与所有租户 schema 的迁移状态并检查
+func checkSchemaVersions(db *sqlx.DB, conf *pkgs.Config, tables *pkgs.TableNames) []string {
+	latest, err := migration.LatestVersion()
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read embedded migrations: %v", err)}
+	}
+	schemas := []string{tables.Schema}
+	if conf.Tenant.Mode == pkgs.TenantModeSchema {
+		var tenantSchemas []string
+		query := `SELECT schema_name FROM information_schema.schemata WHERE starts_with(schema_name, $1) ORDER BY schema_name`
+		if err := db.Select(&tenantSchemas, query, conf.Tenant.SchemaPrefix); err != nil {
+			return []string{fmt.Sprintf("cannot list tenant schemas: %v", err)}
+		}
+		schemas = append(schemas, tenantSchemas...)
+	}
+	states := make([]SchemaState, 0, len(schemas))
+	for _, schema := range schemas {
+		version, dirty, err := migration.SchemaVersion(db, tables, schema)
+		states = append(states, SchemaState{Schema: schema, Version: version, Dirty: dirty, Err: err})
+	}
+	return CheckSchemaVersions(states, latest, tables.MigrationsTable())
+}
+
+// CheckSchemaVersions 检查各 schema 的迁移版本与最新迁移一致且不处于 dirty 状态
+func CheckSchemaVersions(states []SchemaState, latest uint, migrationsTable string) []string {
+	var problems []string
+	for _, state := range states {
+		table := migrationsTable
+		if state.Schema != "" {
+			table = state.Schema + "." + migrationsTable
+		}
+		switch {
+		case state.Err != nil:
+			problems = append(problems, fmt.Sprintf("cannot read migration version from %q: %v (was the database migrated by this build?)", table, state.Err))
+		case state.Dirty:
+			problems = append(problems, fmt.Sprintf("database schema is dirty at version %d: a migration failed halfway, fix the schema manually and reset %q.dirty to false", state.Version, table))
+		case state.Version != latest:
+			problems = append(problems, fmt.Sprintf("database schema version %d in %q does not match latest migration %d: deploy the matching build or run the migrations (server -migrate or admin run-migrations)", state.Version, table, latest))
+		}
+	}
+	return problems
+}
+
+func checkPermissionSeeds(db *sqlx.DB, conf *pkgs.Config, tables *pkgs.TableNames) string {
+	var existing []string
+	query := `SELECT DISTINCT metadata->>'code' FROM ` + tables.Permission + ` WHERE metadata->>'code' IS NOT NULL`
+	if err := db.Select(&existing, query); err != nil {
+		return fmt.Sprintf("cannot query permission seeds: %v", err)
+	}
+	if missing := MissingPermissionCodes(existing, conf.Modules.Disabled()); len(missing) > 0 {
+		return fmt.Sprintf("required permission codes missing from %s: %s (re-run the seed migrations or insert them with metadata.code set)",
+			tables.Permission, strings.Join(missing, ", "))
+	}
+	return ""
+}
+
+// MissingPermissionCodes 返回缺失的预置编码类权限，已关闭模块依赖的权限不检查
+func MissingPermissionCodes(existing []string, disabledModules []string) []string {
+	found := map[string]bool{}
+	for _, code := range existing {
+		found[code] = true
+	}
+	required := slices.Clone(requiredPermissionCodes)
+	for module, codes := range modulePermissionCodes {
+		if !slices.Contains(disabledModules, module) {
+			required = append(required, codes...)
+		}
+	}
+	var missing []string
+	for _, code := range required {
+		if !found[code] {
+			missing = append(missing, code)
+		}
+	}
+	return missing
+}
+
+// CheckJWTSecret 检查 JWT 密钥强度，不满足时返回问题描述
+func CheckJWTSecret(secret string) string {
+	for _, weak := range weakJWTSecrets {
+		if strings.EqualFold(secret, weak) {
+			return "jwt.secret is a well-known default value: generate a random secret (e.g. openssl rand -base64 48)"
+		}
+	}
+	if len(secret) < minJWTSecretLength {
+		return fmt.Sprintf("jwt.secret is %d bytes, at least %d required: generate a random secret (e.g. openssl rand -base64 48)", len(secret), minJWTSecretLength)
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 会修改数据的请求方法，缺少权限保护时需要报告
+var mutatingMethods = []string{"POST", "PUT", "PATCH", "DELETE"}
+
+// CheckRoutes 启动时检查已注册的路由与接口权限记录，在路由注册之后执行
+// warn 模式下问题只记录告警日志，strict 模式下有问题拒绝启动。
+// schema-per-tenant 模式下只检查默认 schema 中的权限记录。
+func CheckRoutes(server *gin.Engine, db *sqlx.DB, conf *pkgs.Config, tables *pkgs.TableNames, logger *zap.Logger) error {
+	if conf.Server.RouteLint == pkgs.RouteLintOff {
+		return nil
+	}
+
+	var problems []string
+	perms, err := loadAPIPermissions(db, tables)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("cannot query permission records: %v", err))
+	}
+	problems = append(problems, LintRoutes(server.Routes(), perms)...)
+	if len(problems) == 0 {
+		logger.Info("路由检查通过")
+		return nil
+	}
+
+	if conf.Server.RouteLint == pkgs.RouteLintStrict {
+		return errors.New("route checks failed:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	for _, problem := range problems {
+		logger.Warn("路由检查发现问题", zap.String("problem", problem))
+	}
+	return nil
+}
+
+// LintRoutes 检查路由配置，返回发现的问题：
+// 1. 重复路由（仅路径参数名不同的同一路径也算重复）；
+// 2. 写操作接口跳过了权限中间件（登录接口、API 密钥鉴权的公开接口与当前用户自助接口除外），或没有对应的权限记录（权限中间件会直接放行）；
+// 3. 权限记录的 method + path 匹配不到任何已注册的路由。
+func LintRoutes(routes gin.RoutesInfo, perms []pkgs.APIPermission) []string {
+	var problems []string
+
+	seen := map[string]string{}
+	for _, route := range routes {
+		key := route.Method + " " + normalizeRoute(route.Path)
+		if prev, ok := seen[key]; ok {
+			problems = append(problems, fmt.Sprintf("duplicate route %s %s conflicts with %s", route.Method, route.Path, prev))
+			continue
+		}
+		seen[key] = route.Path
+	}
+
+	for _, route := range routes {
+		if !slices.Contains(mutatingMethods, route.Method) {
+			continue
+		}
+		if reason, ok := middlewares.PermissionExempt(route.Path); ok {
+			if reason != middlewares.ExemptAuth && reason != middlewares.ExemptAPIKey && reason != middlewares.ExemptSelf {
+				problems = append(problems, fmt.Sprintf("mutating route %s %s bypasses the permission middleware (%s)", route.Method, route.Path, reason))
+			}
+			continue
+		}
+		if !strings.HasPrefix(route.Path, "/v1/") {
+			problems = append(problems, fmt.Sprintf("mutating route %s %s is outside /v1 and not checked by the permission middleware", route.Method, route.Path))
+			continue
+		}
+		covered := slices.ContainsFunc(perms, func(p pkgs.APIPermission) bool {
+			return p.Method == route.Method && routeMatches(route.Path, p.Path)
+		})
+		if !covered {
+			problems = append(problems, fmt.Sprintf("mutating route %s %s has no permission record, every logged-in user can call it", route.Method, route.Path))
+		}
+	}
+
+	for _, p := range perms {
+		exists := slices.ContainsFunc(routes, func(route gin.RouteInfo) bool {
+			return route.Method == p.Method && routeMatches(route.Path, p.Path)
+		})
+		if !exists {
+			problems = append(problems, fmt.Sprintf("permission %s %s does not match any registered route", p.Method, p.Path))
+		}
+	}
+	return problems
+}
+
+// loadAPIPermissions 查询所有接口类权限记录（metadata 中带 method 与 path）
+func loadAPIPermissions(db *sqlx.DB, tables *pkgs.TableNames) ([]pkgs.APIPermission, error) {
+	var perms []pkgs.APIPermission
+	query := `SELECT DISTINCT metadata->>'method' AS method, metadata->>'path' AS path FROM ` + tables.Permission + `
+		WHERE metadata->>'method' IS NOT NULL AND metadata->>'path' IS NOT NULL`
+	if err := db.Select(&perms, query); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
+// normalizeRoute 去掉路径参数名，例如 /v1/user/:id 与 /v1/user/:uid 都变为 /v1/user/:
+func normalizeRoute(path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segs[i] = seg[:1]
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// routeMatches 路由与权限记录的路径按段比较，任一方为路径参数的段视为匹配
+func routeMatches(route, perm string) bool {
+	routeSegs := strings.Split(strings.Trim(route, "/"), "/")
+	permSegs := strings.Split(strings.Trim(perm, "/"), "/")
+	if len(routeSegs) != len(permSegs) {
+		return false
+	}
+	for i := range routeSegs {
+		if strings.HasPrefix(routeSegs[i], ":") || strings.HasPrefix(permSegs[i], ":") {
+			continue
+		}
+		if routeSegs[i] != permSegs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/internal/modules/apikey"
+	"go-pg-demo/internal/modules/audit"
+	"go-pg-demo/internal/modules/iacc/attribute"
+	"go-pg-demo/internal/modules/iacc/auth"
+	"go-pg-demo/internal/modules/iacc/blueprint"
+	"go-pg-demo/internal/modules/iacc/client"
+	"go-pg-demo/internal/modules/iacc/permission"
+	"go-pg-demo/internal/modules/iacc/role"
+	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/internal/modules/view"
+	"go-pg-demo/internal/modules/watch"
+	"go-pg-demo/pkgs"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 仓储从数据库扫描的实体，新增实体或给实体加字段时同步登记
+var schemaEntities = []pkgs.SchemaEntity{
+	{Table: "iacc_user", Entity: user.UserEntity{}},
+	{Table: "iacc_user", Entity: auth.UserEntity{}},
+	{Table: "iacc_user_device", Entity: auth.DeviceEntity{}},
+	{Table: "iacc_role", Entity: role.RoleEntity{}},
+	{Table: "iacc_role", Entity: auth.RoleEntity{}},
+	{Table: "iacc_role_change", Entity: role.RoleChangeEntity{}},
+	{Table: "iacc_permission", Entity: permission.PermissionEntity{}},
+	{Table: "iacc_permission", Entity: auth.PermissionEntity{}},
+	{Table: "iacc_blueprint", Entity: blueprint.BlueprintEntity{}},
+	{Table: "iacc_attribute", Entity: attribute.AttributeEntity{}},
+	{Table: "iacc_client", Entity: client.ClientEntity{}},
+	// 任务状态与错误信息关联 async_job 查询
+	{Table: "iacc_offboarding", Entity: admin.OffboardingEntity{}, Computed: []string{"job_status", "last_error"}},
+	{Table: "api_key", Entity: apikey.APIKeyEntity{}},
+	{Table: "saved_view", Entity: view.ViewEntity{}},
+	// 操作人用户名关联 iacc_user 查询
+	{Table: "audit_log", Entity: audit.AuditEntity{}, Computed: []string{"actor_username"}},
+	{Table: "watch", Entity: watch.WatchEntity{}},
+	{Table: "settings_history", Entity: admin.SettingsHistoryEntity{}},
+}
+
+// 可关闭模块的实体，模块关闭时不检查
+var moduleSchemaEntities = map[string][]pkgs.SchemaEntity{
+	pkgs.ModuleTemplate: {
+		// 使用次数关联 template_usage 查询
+		{Table: "template", Entity: template.TemplateEntity{}, Computed: []string{"usage_count"}},
+	},
+}
+
+// CheckSchema 启动时核对实体的 db 标签与数据表结构，在数据库迁移之后执行
+// 迁移遗漏、手工改表等造成的偏差会在这里暴露，而不是等到第一次查询时才以扫描错误的形式出现。
+// warn 模式下问题只记录告警日志，strict 模式下有问题拒绝启动。
+// schema-per-tenant 模式下只检查默认 schema。
+func CheckSchema(db *sqlx.DB, conf *pkgs.Config, tables *pkgs.TableNames, logger *zap.Logger) error {
+	if conf.Database.SchemaCheck == pkgs.SchemaCheckOff {
+		return nil
+	}
+
+	problems, err := SchemaProblems(db, conf, tables)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(problems) == 0 {
+		logger.Info("数据库结构检查通过")
+		return nil
+	}
+
+	if conf.Database.SchemaCheck == pkgs.SchemaCheckStrict {
+		return errors.New("schema checks failed:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	for _, problem := range problems {
+		logger.Warn("数据库结构检查发现问题", zap.String("problem", problem))
+	}
+	return nil
+}
+
+// SchemaProblems 对比已开启模块的实体与数据表结构，返回发现的问题
+func SchemaProblems(db *sqlx.DB, conf *pkgs.Config, tables *pkgs.TableNames) ([]string, error) {
+	entities := slices.Clone(schemaEntities)
+	for module, moduleEntities := range moduleSchemaEntities {
+		if !slices.Contains(conf.Modules.Disabled(), module) {
+			entities = append(entities, moduleEntities...)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	drifts, err := pkgs.CheckSchema(ctx, db, tables, entities)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]string, 0, len(drifts))
+	for _, drift := range drifts {
+		problems = append(problems, drift.String())
+	}
+	return problems, nil
+}
@@ -6,11 +6,22 @@ import (
 	v1 "go-pg-demo/api/v1"
 	"go-pg-demo/api/v1/intf"
 	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/internal/modules/apikey"
+	"go-pg-demo/internal/modules/audit"
+	"go-pg-demo/internal/modules/dev"
+	"go-pg-demo/internal/modules/iacc/attribute"
 	"go-pg-demo/internal/modules/iacc/auth"
+	"go-pg-demo/internal/modules/iacc/blueprint"
+	"go-pg-demo/internal/modules/iacc/client"
 	"go-pg-demo/internal/modules/iacc/permission"
 	"go-pg-demo/internal/modules/iacc/role"
 	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/internal/modules/sandbox"
 	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/internal/modules/tenant"
+	"go-pg-demo/internal/modules/view"
+	"go-pg-demo/internal/modules/watch"
 	"go-pg-demo/pkgs"
 
 	"github.com/google/wire"
@@ -26,6 +37,17 @@ func InitializeApp() (*App, func(), error) {
 		user.NewUserHandler,
 		role.NewRoleHandler,
 		auth.NewAuthHandler,
+		client.NewClientHandler,
+		blueprint.NewBlueprintHandler,
+		attribute.NewAttributeHandler,
+		tenant.NewTenantHandler,
+		apikey.NewAPIKeyHandler,
+		admin.NewAdminHandler,
+		dev.NewDevHandler,
+		sandbox.NewSandboxHandler,
+		view.NewViewHandler,
+		audit.NewAuditHandler,
+		watch.NewWatchHandler,
 		v1.NewRouter,
 		NewApp,
 		// 绑定接口实现
@@ -34,6 +56,17 @@ func InitializeApp() (*App, func(), error) {
 		wire.Bind(new(intf.UserHandler), new(*user.Handler)),
 		wire.Bind(new(intf.RoleHandler), new(*role.Handler)),
 		wire.Bind(new(intf.AuthHandler), new(*auth.Handler)),
+		wire.Bind(new(intf.ClientHandler), new(*client.Handler)),
+		wire.Bind(new(intf.BlueprintHandler), new(*blueprint.Handler)),
+		wire.Bind(new(intf.AttributeHandler), new(*attribute.Handler)),
+		wire.Bind(new(intf.TenantHandler), new(*tenant.Handler)),
+		wire.Bind(new(intf.APIKeyHandler), new(*apikey.Handler)),
+		wire.Bind(new(intf.AdminHandler), new(*admin.Handler)),
+		wire.Bind(new(intf.DevHandler), new(*dev.Handler)),
+		wire.Bind(new(intf.SandboxHandler), new(*sandbox.Handler)),
+		wire.Bind(new(intf.ViewHandler), new(*view.Handler)),
+		wire.Bind(new(intf.AuditHandler), new(*audit.Handler)),
+		wire.Bind(new(intf.WatchHandler), new(*watch.Handler)),
 	)
 	return nil, nil, nil
 }
@@ -9,11 +9,22 @@ package app
 import (
 	"go-pg-demo/api/v1"
 	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/internal/modules/apikey"
+	"go-pg-demo/internal/modules/audit"
+	"go-pg-demo/internal/modules/dev"
+	"go-pg-demo/internal/modules/iacc/attribute"
 	"go-pg-demo/internal/modules/iacc/auth"
+	"go-pg-demo/internal/modules/iacc/blueprint"
+	"go-pg-demo/internal/modules/iacc/client"
 	"go-pg-demo/internal/modules/iacc/permission"
 	"go-pg-demo/internal/modules/iacc/role"
 	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/internal/modules/sandbox"
 	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/internal/modules/tenant"
+	"go-pg-demo/internal/modules/view"
+	"go-pg-demo/internal/modules/watch"
 	"go-pg-demo/pkgs"
 )
 
@@ -24,11 +35,14 @@ import (
 // Injectors from wire.go:
 
 func InitializeApp() (*App, func(), error) {
-	engine := NewGin()
 	config, err := pkgs.NewConfig()
 	if err != nil {
 		return nil, nil, err
 	}
+	engine, err := NewGin(config)
+	if err != nil {
+		return nil, nil, err
+	}
 	logger, err := pkgs.NewLogger(config)
 	if err != nil {
 		return nil, nil, err
@@ -37,23 +51,127 @@ func InitializeApp() (*App, func(), error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	traceMiddleware := middlewares.NewTraceMiddleware(logger)
+	idObfuscator, err := pkgs.NewIDObfuscator(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	idObfuscationMiddleware := middlewares.NewIDObfuscationMiddleware(idObfuscator)
 	loggerMiddleware := middlewares.NewLoggerMiddleware(logger)
-	authMiddleware := middlewares.NewAuthMiddleware(config)
-	permissionMiddleware := middlewares.NewPermissionMiddleware(db, logger)
+	timeFormatter, err := pkgs.NewTimeFormatter(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	localizer, err := pkgs.NewLocalizer(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	localeMiddleware := middlewares.NewLocaleMiddleware(localizer, timeFormatter)
+	apiVersionMiddleware := middlewares.NewAPIVersionMiddleware()
+	batchDB, cleanup, err := pkgs.NewBatchConnection(config, db)
+	if err != nil {
+		return nil, nil, err
+	}
+	tenantPool, cleanup2 := pkgs.NewTenantPool(config, db, batchDB, logger)
+	tenantMiddleware := middlewares.NewTenantMiddleware(config, tenantPool, logger)
+	tableNames := pkgs.NewTableNames(config)
+	tokenBlacklist := pkgs.NewTokenBlacklist(tenantPool, tableNames, logger)
+	settings := pkgs.NewSettings(config, db, tableNames, logger)
+	authMiddleware := middlewares.NewAuthMiddleware(config, tokenBlacklist)
+	redisClient, cleanup3 := pkgs.NewRedisClient(config)
+	permissionCache := pkgs.NewPermissionCache(config, redisClient, logger)
+	permissionMatcher := pkgs.NewPermissionMatcher(config, tenantPool, tableNames, logger, permissionCache)
+	permissionChecker := pkgs.NewPermissionChecker(tenantPool, tableNames, logger, permissionCache)
+	securityEvents, cleanup4, err := pkgs.NewSecurityEvents(config, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	permissionMiddleware := middlewares.NewPermissionMiddleware(config, logger, permissionMatcher, permissionChecker, securityEvents)
+	queryPlanMiddleware := middlewares.NewQueryPlanMiddleware(config, permissionChecker, logger)
+	sandboxMiddleware := middlewares.NewSandboxMiddleware(config, tenantPool, logger)
+	docsMiddleware := middlewares.NewDocsMiddleware(config, permissionChecker, tokenBlacklist, settings)
 	recoveryMiddleware := middlewares.NewRecoveryMiddleware(logger)
-	v := middlewares.NewUseMiddlewares(loggerMiddleware, authMiddleware, permissionMiddleware, recoveryMiddleware)
+	v := middlewares.NewUseMiddlewares(traceMiddleware, idObfuscationMiddleware, loggerMiddleware, localeMiddleware, apiVersionMiddleware, tenantMiddleware, authMiddleware, permissionMiddleware, queryPlanMiddleware, sandboxMiddleware, docsMiddleware, recoveryMiddleware)
 	requestValidator := pkgs.NewRequestValidator()
-	handler := template.NewTemplateHandler(db, logger, requestValidator)
-	userHandler := user.NewUserHandler(db, logger, requestValidator)
-	roleHandler := role.NewRoleHandler(db, logger, requestValidator)
-	authHandler := auth.NewAuthHandler(db, logger, requestValidator, config)
-	permissionHandler := permission.NewPermissionHandler(db, logger, requestValidator)
-	router := v1.NewRouter(engine, handler, userHandler, roleHandler, authHandler, permissionHandler)
-	scheduler := pkgs.NewScheduler(logger, db)
-	app, err := NewApp(engine, logger, config, db, v, router, scheduler)
+	idGenerator := pkgs.NewIDGenerator(config)
+	storage, err := pkgs.NewStorage(config)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	fieldCipher, err := pkgs.NewFieldCipher(config)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	passwordHasher, err := pkgs.NewPasswordHasher(config)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	jobQueue := pkgs.NewJobQueue(tenantPool, tableNames, logger)
+	retention := pkgs.NewRetention(tenantPool, tableNames, logger)
+	scheduler := pkgs.NewScheduler(logger, batchDB, tableNames, fieldCipher, passwordHasher, jobQueue, retention)
+	reportingDB, cleanup5, err := pkgs.NewReportingDB(config, tenantPool, tableNames, logger)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	notifier := pkgs.NewNotifier(config, jobQueue, logger)
+	watchers := pkgs.NewWatchers(tenantPool, tableNames, notifier, logger)
+	handler := template.NewTemplateHandler(db, logger, requestValidator, tableNames, tenantPool, reportingDB, permissionChecker, idGenerator, config, storage, scheduler, watchers)
+	auditLog := pkgs.NewAuditLog(tenantPool, tableNames, logger)
+	userHandler := user.NewUserHandler(db, logger, requestValidator, tableNames, tenantPool, reportingDB, permissionChecker, idGenerator, permissionCache, securityEvents, auditLog, passwordHasher, config, scheduler, watchers, jobQueue, settings)
+	roleHandler := role.NewRoleHandler(db, logger, requestValidator, tableNames, tenantPool, reportingDB, idGenerator, permissionCache, securityEvents, notifier, auditLog)
+	authHandler := auth.NewAuthHandler(db, logger, requestValidator, config, tableNames, tenantPool, idGenerator, securityEvents, tokenBlacklist, passwordHasher, scheduler, settings)
+	clientHandler := client.NewClientHandler(db, logger, requestValidator, tableNames, tenantPool, idGenerator)
+	blueprintHandler := blueprint.NewBlueprintHandler(db, logger, requestValidator, tableNames, tenantPool, idGenerator)
+	attributeHandler := attribute.NewAttributeHandler(db, logger, requestValidator, tableNames, tenantPool, idGenerator)
+	permissionHandler := permission.NewPermissionHandler(db, logger, requestValidator, tableNames, tenantPool, reportingDB, idGenerator, permissionCache, auditLog)
+	tenantHandler := tenant.NewTenantHandler(db, logger, requestValidator, config, tableNames, tenantPool, passwordHasher)
+	apikeyHandler := apikey.NewAPIKeyHandler(db, logger, requestValidator, tableNames, tenantPool, idGenerator, settings)
+	rateLimitShadow := pkgs.NewRateLimitShadow()
+	adminHandler := admin.NewAdminHandler(db, logger, requestValidator, config, tenantPool, reportingDB, tableNames, retention, idGenerator, jobQueue, permissionCache, permissionMatcher, securityEvents, rateLimitShadow, redisClient, settings)
+	devHandler := dev.NewDevHandler(db, logger, requestValidator, config, tenantPool, tableNames, idGenerator, securityEvents, tokenBlacklist, permissionCache, passwordHasher, settings)
+	sandboxHandler := sandbox.NewSandboxHandler(logger, requestValidator, config, engine, tenantPool, tableNames, permissionChecker)
+	viewHandler := view.NewViewHandler(db, logger, requestValidator, tableNames, tenantPool, idGenerator)
+	watchHandler := watch.NewWatchHandler(db, logger, requestValidator, tableNames, tenantPool, idGenerator, watchers)
+	auditHandler := audit.NewAuditHandler(db, logger, requestValidator, config, tableNames, tenantPool, reportingDB, jobQueue, storage, auditLog, permissionCache, securityEvents)
+	apiKeyMiddleware := middlewares.NewAPIKeyMiddleware(config, tenantPool, logger, tableNames)
+	rateLimitMiddleware := middlewares.NewRateLimitMiddleware(settings, rateLimitShadow)
+	responseCodes := pkgs.NewResponseCodes(config)
+	responseCacheMiddleware := middlewares.NewResponseCacheMiddleware(config, responseCodes)
+	publicAPIMiddlewares := middlewares.NewPublicAPIMiddlewares(apiKeyMiddleware, rateLimitMiddleware, responseCacheMiddleware)
+	router := v1.NewRouter(engine, config, handler, userHandler, roleHandler, authHandler, clientHandler, blueprintHandler, attributeHandler, permissionHandler, tenantHandler, apikeyHandler, adminHandler, devHandler, sandboxHandler, viewHandler, auditHandler, watchHandler, publicAPIMiddlewares)
+	app, err := NewApp(engine, logger, config, db, v, router, scheduler, tableNames, settings)
 	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
 		return nil, nil, err
 	}
 	return app, func() {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
 	}, nil
 }
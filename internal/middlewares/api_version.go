@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-pg-demo/pkgs"
+)
+
+// 接口版本协商中间件
+// 根据 Accept 请求头中的 application/vnd.gopgdemo.v{N}+json 确定响应的版本，写入 context 供 pkgs.Success 选择表示；
+// 未指定版本时使用默认版本，指定的版本均不支持时返回 406。
+type APIVersionMiddleware gin.HandlerFunc
+
+func NewAPIVersionMiddleware() APIVersionMiddleware {
+	return func(c *gin.Context) {
+		// 同一地址的响应随 Accept 变化，缓存需要区分
+		c.Header("Vary", "Accept")
+		version, explicit, ok := pkgs.ParseAPIVersion(c.GetHeader("Accept"))
+		if !ok {
+			pkgs.Error(c, http.StatusNotAcceptable, "不支持的接口版本")
+			return
+		}
+		if explicit {
+			c.Set(pkgs.APIVersionContextKey, version)
+		}
+		c.Next()
+	}
+}
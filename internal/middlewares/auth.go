@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -10,63 +11,149 @@ import (
 	"go-pg-demo/pkgs"
 )
 
-// JWT验证中间件
+// JWT验证中间件，已加入黑名单（退出登录）的访问令牌返回 401
+// 开启 Cookie 鉴权时，没有 Authorization 请求头的请求从 access_token cookie 读取访问令牌，
+// 此时修改类请求必须携带与 csrf_token cookie 一致的 X-CSRF-Token 请求头，否则返回 403。
 type AuthMiddleware gin.HandlerFunc
 
-func NewAuthMiddleware(config *pkgs.Config) AuthMiddleware {
+func NewAuthMiddleware(config *pkgs.Config, blacklist *pkgs.TokenBlacklist) AuthMiddleware {
 	return func(c *gin.Context) {
+		// 公开接口使用 API 密钥鉴权（见 APIKeyMiddleware）
+		if strings.HasPrefix(c.Request.URL.Path, PublicAPIPrefix+"/") {
+			c.Next()
+			return
+		}
+
+		// 模板为公共接口：不强制登录，携带令牌时解析出用户信息（用于记录和筛选模板所有者）
+		if strings.Contains(c.Request.URL.Path, "/v1/template") {
+			tokenString := ""
+			if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+				tokenString = authHeader[7:]
+			} else if authHeader == "" {
+				var ok bool
+				if tokenString, ok = cookieAccessToken(c, config); !ok {
+					return
+				}
+			}
+			if tokenString != "" {
+				claims, ok := authenticate(c, config, blacklist, tokenString)
+				if !ok {
+					return
+				}
+				setClaims(c, claims)
+			}
+			c.Next()
+			return
+		}
+
 		// 白名单
-		if strings.Contains(c.Request.URL.Path, "/swagger") ||
-			strings.Contains(c.Request.URL.Path, "/v1/template") ||
+		if c.Request.URL.Path == pkgs.MetricsPath ||
+			strings.Contains(c.Request.URL.Path, "/swagger") ||
 			strings.Contains(c.Request.URL.Path, "/v1/auth/login") ||
 			strings.Contains(c.Request.URL.Path, "/v1/auth/refresh-token") {
 			c.Next()
 			return
 		}
 
-		// 从请求头获取Authorization字段
+		// 从请求头获取Authorization字段，没有时读取 cookie 中的访问令牌
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			pkgs.Error(c, 401, "请求头缺少 Authorization 字段")
-			return
-		}
-
-		// 检查Bearer前缀
 		tokenString := ""
-		if strings.HasPrefix(authHeader, "Bearer ") {
+		if authHeader == "" {
+			var ok bool
+			if tokenString, ok = cookieAccessToken(c, config); !ok {
+				return
+			}
+			if tokenString == "" {
+				pkgs.Error(c, 401, "请求头缺少 Authorization 字段")
+				return
+			}
+		} else if strings.HasPrefix(authHeader, "Bearer ") {
+			// 检查Bearer前缀
 			tokenString = authHeader[7:] // "Bearer " 长度为7
 		} else {
 			pkgs.Error(c, 401, "Authorization 字段必须以 'Bearer ' 开头")
 			return
 		}
 
-		// 解析和验证JWT token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// 验证签名方法
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(config.JWT.Secret), nil
-		})
-
-		if err != nil {
-			pkgs.Error(c, 401, "无效的令牌")
-			return
-		}
-
-		// 检查token是否有效
-		if !token.Valid {
-			pkgs.Error(c, 401, "无效的令牌")
+		// 解析和验证JWT token，并检查是否已撤销
+		claims, ok := authenticate(c, config, blacklist, tokenString)
+		if !ok {
 			return
 		}
 
 		// 将用户信息存储到上下文中
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			pkgs.Error(c, 500, "token类型出错")
-		}
-		c.Set("user_id", claims["user_id"])
+		setClaims(c, claims)
 
 		c.Next()
 	}
 }
+
+// cookieAccessToken 读取 cookie 中的访问令牌，修改类请求同时校验 CSRF 令牌
+// 未开启 Cookie 鉴权或没有 cookie 时返回空字符串；CSRF 校验失败时写入错误响应并返回 false。
+func cookieAccessToken(c *gin.Context, config *pkgs.Config) (string, bool) {
+	tokenString := pkgs.CookieToken(c, config, pkgs.AccessTokenCookie)
+	if tokenString != "" && !pkgs.ValidCSRF(c) {
+		pkgs.Error(c, http.StatusForbidden, "CSRF 令牌校验失败")
+		return "", false
+	}
+	return tokenString, true
+}
+
+// authenticate 解析访问令牌并检查令牌黑名单，失败时写入错误响应并返回 false
+// 查询黑名单失败时拒绝请求，不放行可能已撤销的令牌。
+func authenticate(c *gin.Context, config *pkgs.Config, blacklist *pkgs.TokenBlacklist, tokenString string) (jwt.MapClaims, bool) {
+	claims, err := parseAccessToken(config, tokenString)
+	if err != nil {
+		pkgs.Error(c, http.StatusUnauthorized, "无效的令牌")
+		return nil, false
+	}
+	revoked, err := blacklist.IsRevoked(c, claims)
+	if err != nil {
+		pkgs.Error(c, http.StatusInternalServerError, "令牌校验失败")
+		return nil, false
+	}
+	if revoked {
+		pkgs.Error(c, http.StatusUnauthorized, "令牌已撤销")
+		return nil, false
+	}
+	return claims, true
+}
+
+// setClaims 将令牌中的用户信息写入上下文，沙箱令牌同时写入允许访问的接口（由 SandboxMiddleware 使用）
+// 完整的声明同时写入上下文，退出登录时据此撤销当前访问令牌。
+func setClaims(c *gin.Context, claims jwt.MapClaims) {
+	c.Set("user_id", claims["user_id"])
+	c.Set(pkgs.TokenClaimsContextKey, claims)
+	if scopes, ok := pkgs.SandboxScopesFromClaims(claims); ok {
+		c.Set(pkgs.SandboxContextKey, scopes)
+	}
+}
+
+// parseAccessToken 解析并校验访问令牌，返回其中的声明
+func parseAccessToken(config *pkgs.Config, tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// 验证签名方法
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.JWT.Secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查token是否有效
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("unexpected claims type: %T", token.Claims)
+	}
+	// 刷新令牌与缺少类型的令牌不能用于接口鉴权
+	if typ := pkgs.TokenType(claims); typ != pkgs.TokenTypeAccess {
+		return nil, fmt.Errorf("unexpected token type: %q", typ)
+	}
+	return claims, nil
+}
@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-pg-demo/pkgs"
+)
+
+// ID混淆中间件
+// 启用 id_obfuscation 后，在进入后续中间件与处理器之前把路径参数、查询参数与 JSON 请求体中的对外ID解码为 UUID，
+// 处理器写出的 JSON 响应在返回前把其中的 UUID 编码为对外ID；请求中出现原始 UUID 时返回 400（allow_raw 除外）。
+// 未启用时不做任何处理。
+type IDObfuscationMiddleware gin.HandlerFunc
+
+func NewIDObfuscationMiddleware(obfuscator *pkgs.IDObfuscator) IDObfuscationMiddleware {
+	return func(c *gin.Context) {
+		if !obfuscator.Enabled() {
+			c.Next()
+			return
+		}
+
+		if err := decodeRequestIDs(c, obfuscator); err != nil {
+			pkgs.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writer := &obfuscatingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		writer.flush(obfuscator)
+	}
+}
+
+// decodeRequestIDs 解码路径参数、查询参数与 JSON 请求体中的对外ID
+func decodeRequestIDs(c *gin.Context, obfuscator *pkgs.IDObfuscator) error {
+	for i, param := range c.Params {
+		value, err := obfuscator.DecodeParam(param.Value)
+		if err != nil {
+			return err
+		}
+		c.Params[i].Value = value
+	}
+
+	if c.Request.URL.RawQuery != "" {
+		query := c.Request.URL.Query()
+		for key, values := range query {
+			for i, v := range values {
+				value, err := obfuscator.DecodeParam(v)
+				if err != nil {
+					return err
+				}
+				values[i] = value
+			}
+			query[key] = values
+		}
+		c.Request.URL.RawQuery = query.Encode()
+	}
+
+	if c.Request.Body != nil && isJSONContentType(c.ContentType()) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		// 无法解析的请求体原样交给后续绑定处理，由绑定返回格式错误
+		if decoded, err := obfuscator.DecodeJSON(body); err == nil {
+			body = decoded
+		} else if errors.Is(err, pkgs.ErrRawID) {
+			return err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+	}
+	return nil
+}
+
+// isJSONContentType 是否为 JSON 请求体（包括 application/merge-patch+json）
+func isJSONContentType(contentType string) bool {
+	return contentType == gin.MIMEJSON || strings.HasSuffix(contentType, "+json")
+}
+
+// obfuscatingWriter 缓存 JSON 响应，处理器执行完后编码其中的 UUID 再写出；其它类型的响应直接写出
+type obfuscatingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	buffering bool
+	decided   bool
+}
+
+func (w *obfuscatingWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), gin.MIMEJSON)
+	}
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *obfuscatingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// flush 写出缓存的 JSON 响应，编码失败时原样写出
+func (w *obfuscatingWriter) flush(obfuscator *pkgs.IDObfuscator) {
+	if !w.buffering {
+		return
+	}
+	body := w.body.Bytes()
+	if encoded, err := obfuscator.EncodeJSON(body); err == nil {
+		body = encoded
+	}
+	w.Header().Del("Content-Length")
+	_, _ = w.ResponseWriter.Write(body)
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"cmp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-pg-demo/pkgs"
+)
+
+// 语言与时区解析中间件
+// 根据 Accept-Language 请求头确定错误信息、参数校验信息使用的语言，写入 context 供 pkgs.Localize 使用，并通过 Content-Language 响应头返回；
+// 根据 ?tz= 查询参数或 X-Timezone 请求头（IANA 时区名，如 Asia/Shanghai），未指定时按 Accept-Language 确定本次请求返回时间使用的时区，
+// 写入 context 供 pkgs.FormatTime 使用；时区非法返回 400。
+type LocaleMiddleware gin.HandlerFunc
+
+func NewLocaleMiddleware(localizer *pkgs.Localizer, formatter *pkgs.TimeFormatter) LocaleMiddleware {
+	return func(c *gin.Context) {
+		acceptLanguage := c.GetHeader("Accept-Language")
+		locale := localizer.Resolve(acceptLanguage)
+		c.Set(pkgs.LocaleContextKey, locale)
+		c.Header("Content-Language", locale)
+
+		loc, err := formatter.Resolve(cmp.Or(c.Query("tz"), c.GetHeader("X-Timezone")), acceptLanguage)
+		if err != nil {
+			pkgs.Error(c, http.StatusBadRequest, "时区参数错误")
+			return
+		}
+		c.Set(pkgs.TimeLocationContextKey, loc)
+		c.Next()
+	}
+}
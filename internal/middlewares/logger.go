@@ -1,13 +1,17 @@
 package middlewares
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
 )
 
-// 日志中间件，记录每个请求的路径、方法、IP和耗时
+// 访问日志中间件，每个请求结束后记录一条结构化日志：方法、路径、路由模板、状态码、耗时、IP、用户ID 与请求ID
+// 5xx 或处理过程中记录了错误时按 Error 级别记录，其余按 Info 级别记录。
 type LoggerMiddleware gin.HandlerFunc
 
 func NewLoggerMiddleware(logger *zap.Logger) LoggerMiddleware {
@@ -20,38 +24,28 @@ func NewLoggerMiddleware(logger *zap.Logger) LoggerMiddleware {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		method := c.Request.Method
-		ip := c.ClientIP()
 
 		// 处理请求
 		c.Next()
 
-		// 计算耗时
-		latency := time.Since(start)
-
-		// 获取状态码
-		statusCode := c.Writer.Status()
-
-		// 记录日志
-		if len(c.Errors) > 0 {
-			// 如果有错误，记录错误日志
-			logger.Error("Request",
-				zap.Int("status", statusCode),
-				zap.String("method", method),
-				zap.String("path", path),
-				zap.String("ip", ip),
-				zap.Duration("latency", latency),
-				zap.String("error", c.Errors.ByType(gin.ErrorTypePrivate).String()),
-			)
+		// 用户ID 由鉴权中间件在处理过程中写入，请求结束后再读取
+		fields := []zap.Field{
+			zap.Int("status", c.Writer.Status()),
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.String("route", c.FullPath()),
+			zap.String("ip", c.ClientIP()),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("size", c.Writer.Size()),
+			zap.String("user_id", pkgs.CurrentUserID(c)),
+		}
+		// 请求日志器已带上 trace_id
+		requestLogger := pkgs.RequestLogger(c, logger)
+		if len(c.Errors) > 0 || c.Writer.Status() >= http.StatusInternalServerError {
+			fields = append(fields, zap.String("error", c.Errors.ByType(gin.ErrorTypePrivate).String()))
+			requestLogger.Error("Request", fields...)
 		} else {
-			// 记录普通日志
-			logger.Info("Request",
-				zap.Int("status", statusCode),
-				zap.String("method", method),
-				zap.String("path", path),
-				zap.String("ip", ip),
-				zap.Duration("latency", latency),
-			)
+			requestLogger.Info("Request", fields...)
 		}
 	}
 }
@@ -2,10 +2,10 @@ package middlewares
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 
 	"go-pg-demo/pkgs"
@@ -13,45 +13,69 @@ import (
 
 // PermissionMiddleware 接口权限校验
 // 规则（与实际实现保持同步）：
-// 1. 白名单直接放行：swagger 文档、/v1/auth/login、/v1/auth/refresh-token；以及公共接口前缀 /v1/template*（无需登录 / 权限）。
-// 2. 仅对 /v1/ 开头的接口做权限控制，其他路径直接放行。
+// 1. 白名单直接放行：swagger 文档（由 DocsMiddleware 单独校验 docs:view 权限）、/v1/auth/login、/v1/auth/refresh-token；公共接口前缀 /v1/template*（无需登录 / 权限）；使用 API 密钥鉴权的 /public/v1/*；以及只需登录的当前用户自助接口 /v1/auth/*。
+// 2. 仅对 /v1/ 开头的接口做权限控制，其他路径以及已关闭模块（modules.*.enabled）的接口直接放行（未注册的路由由 gin 返回 404）。
 // 3. 必须先通过 AuthMiddleware 将 user_id 写入 context；若不存在或为空 -> 返回 401 业务码 (HTTP 仍 200)。
-// 4. 先查询权限元数据表(iacc_permission) 是否存在(method+path) 精确记录：
+// 4. 先由 PermissionMatcher 判断权限元数据表(iacc_permission) 中是否有匹配该接口的记录（记录的 path 为路由模板，按下面第 6 条的规则匹配）：
 //   - 若不存在：说明该接口尚未纳入权限体系 -> 放行（便于灰度 / 临时接口 / 忘记录入时不中断功能）。
 //   - 若存在：进入用户权限校验。
+//   - 权限记录按租户编译为前缀树缓存在内存中，判断只依据编译结果；新增、修改、删除权限后通过 PermissionCache 的失效钩子重新编译。
 //
-// 5. 通过用户角色关联 (iacc_user_role -> iacc_role_permission -> iacc_permission) 拉取用户拥有的全部权限(method+path) 列表。
+// 5. 由 PermissionChecker 通过一条 CTE 查询 (iacc_user_role -> iacc_role_permission -> iacc_permission) 拉取用户拥有的全部权限(method+path) 列表；配置 Redis 后结果缓存在 PermissionCache 中，Redis 不可用时降级为直接查询数据库（带熔断）。
 // 6. 匹配策略：
 //   - 先按 method 精确一致；
 //   - path 完全相等直接通过；
-//   - 若权限表 path 含 :param 形式（例如 /v1/user/:id），按段数一致且静态段逐一相等视为匹配（仅做“占位符”精确匹配，不做通配 / 前缀模糊）。
+//   - 若权限表 path 含 :param 形式（例如 /v1/user/:id），按段数一致且静态段逐一相等视为匹配；
+//   - 末尾为 *name 形式（例如 /v1/files/*path）时，前面的段逐一匹配且剩余至少一段视为匹配，与 gin 的通配路由一致。
 //
-// 7. 未匹配 -> 返回 403 业务码；所有错误响应使用 HTTP 200 包装（统一前端处理）。
-// 8. 未来可优化点：
-//   - 缓存用户权限集合减少每次查询；
-//   - 预编译路径模板提升匹配效率；
+// 7. 拒绝优先（用户任一角色以 deny 关联该接口即不放行）；未匹配 -> 返回 403 业务码；所有错误响应使用 HTTP 200 包装（统一前端处理）。
+// 8. 角色配置了访问条件（access_conditions）时，请求时间或来源 IP 不满足条件的角色权限不生效；因此被拒绝时返回 40301（时间）/ 40302（IP）业务码。
+// 9. 被拒绝时记录 auth.permission.denied 安全事件（配置 siem.sink 后推送到 SIEM）。
+// 10. 未来可优化点：
 //   - 后台管理端自动同步/生成权限元数据，降低人工遗漏。
 type PermissionMiddleware gin.HandlerFunc
 
-func NewPermissionMiddleware(db *sqlx.DB, logger *zap.Logger) PermissionMiddleware {
+// 白名单（与鉴权一致，可根据需要补充），无需登录与接口权限
+var authWhitelist = []string{
+	"/v1/auth/login",
+	"/v1/auth/refresh-token",
+}
+
+// 跳过接口权限校验的原因，启动时的路由检查据此区分预期内的公共接口
+const (
+	ExemptSwagger  = "swagger 文档"
+	ExemptAuth     = "登录接口"
+	ExemptAPIKey   = "API 密钥鉴权的公开接口"
+	ExemptTemplate = "模板公共接口"
+	ExemptSelf     = "当前用户自助接口"
+)
+
+// PermissionExempt 返回路径是否跳过接口权限校验及原因
+func PermissionExempt(path string) (string, bool) {
+	if strings.Contains(path, "/swagger") {
+		return ExemptSwagger, true
+	}
+	if slices.Contains(authWhitelist, path) {
+		return ExemptAuth, true
+	}
+	// 公开接口使用 API 密钥鉴权，不纳入接口权限体系
+	if strings.HasPrefix(path, PublicAPIPrefix+"/") {
+		return ExemptAPIKey, true
+	}
+	// 兼容 AuthMiddleware 中未解析 token 的公共接口（如 /v1/template/list）
+	if strings.HasPrefix(path, "/v1/template") {
+		return ExemptTemplate, true
+	}
+	// 当前用户查看自己的信息、管理自己的设备，只需登录（由 AuthMiddleware 校验）
+	if strings.HasPrefix(path, "/v1/auth/") {
+		return ExemptSelf, true
+	}
+	return "", false
+}
+
+func NewPermissionMiddleware(config *pkgs.Config, logger *zap.Logger, matcher *pkgs.PermissionMatcher, permissions *pkgs.PermissionChecker, events *pkgs.SecurityEvents) PermissionMiddleware {
 	return func(c *gin.Context) {
-		// 白名单（与鉴权一致，可根据需要补充），无需权限校验
-		authWhitelist := []string{
-			"/v1/auth/login",
-			"/v1/auth/refresh-token",
-		}
-		if strings.Contains(c.Request.URL.Path, "/swagger") {
-			c.Next()
-			return
-		}
-		for _, w := range authWhitelist {
-			if c.Request.URL.Path == w {
-				c.Next()
-				return
-			}
-		}
-		// 兼容 AuthMiddleware 中未解析 token 的公共接口（如 /v1/template/list）
-		if strings.HasPrefix(c.Request.URL.Path, "/v1/template") {
+		if _, ok := PermissionExempt(c.Request.URL.Path); ok {
 			c.Next()
 			return
 		}
@@ -76,74 +100,46 @@ func NewPermissionMiddleware(db *sqlx.DB, logger *zap.Logger) PermissionMiddlewa
 			c.Next()
 			return
 		}
+		// 已关闭模块的接口未注册路由，不做权限校验，交给路由返回 404
+		if c.FullPath() == "" && config.Modules.DisabledPath(path) {
+			c.Next()
+			return
+		}
 
 		// 先查权限表是否有该接口
-		var permCount int
-		metaQuery := `SELECT COUNT(1) FROM iacc_permission WHERE metadata->>'method' = $1 AND metadata->>'path' = $2`
-		if err := db.GetContext(c.Request.Context(), &permCount, metaQuery, method, path); err != nil {
+		guarded, err := matcher.Guarded(c, method, path)
+		if err != nil {
 			logger.Error("查询权限表失败", zap.Error(err))
 			pkgs.Error(c, http.StatusInternalServerError, "权限校验失败")
 			return
 		}
 		// 权限表查不到该接口，直接放行
-		if permCount == 0 {
+		if !guarded {
 			c.Next()
 			return
 		}
 
-		// 权限表有记录，校验用户是否有权限
-		var perms []struct {
-			Method *string `db:"method"`
-			Path   *string `db:"path"`
-		}
-		query := `SELECT (p.metadata->>'method') AS method, (p.metadata->>'path') AS path
-			FROM iacc_permission p
-			INNER JOIN iacc_role_permission rp ON p.id = rp.permission_id
-			INNER JOIN iacc_user_role ur ON rp.role_id = ur.role_id
-			WHERE ur.user_id = $1`
-		if err := db.SelectContext(c.Request.Context(), &perms, query, userID); err != nil {
-			logger.Error("查询用户权限失败", zap.Error(err))
+		// 权限表有记录，校验用户是否有权限；用户权限集合优先从缓存读取，缓存不可用时直接查询数据库
+		perms, err := permissions.Permissions(c)
+		if err != nil {
 			pkgs.Error(c, http.StatusInternalServerError, "权限校验失败")
 			return
 		}
 
-		allowed := false
-		for _, p := range perms {
-			if p.Method == nil || p.Path == nil {
-				continue
-			}
-			if *p.Method != method {
-				continue
-			}
-			permPath := *p.Path
-			if permPath == path {
-				allowed = true
-				break
-			}
-			if strings.Contains(permPath, ":") {
-				permSegs := strings.Split(strings.Trim(permPath, "/"), "/")
-				pathSegs := strings.Split(strings.Trim(path, "/"), "/")
-				if len(permSegs) == len(pathSegs) {
-					matchAll := true
-					for i := range permSegs {
-						if strings.HasPrefix(permSegs[i], ":") {
-							continue
-						}
-						if permSegs[i] != pathSegs[i] {
-							matchAll = false
-							break
-						}
-					}
-					if matchAll {
-						allowed = true
-						break
-					}
-				}
-			}
+		// 拒绝优先：任一角色拒绝该接口时，即使其他角色授予也不放行
+		match := func(p pkgs.APIPermission) bool {
+			return p.Method == method && pkgs.MatchPermissionPath(p.Path, path)
 		}
+		allowed := pkgs.PermissionAllowed(perms, match)
 
 		if !allowed {
-			pkgs.Error(c, http.StatusForbidden, "无接口访问权限")
+			// 授予该接口的角色因访问条件（时间段、IP 段）不生效时，返回条件对应的业务码
+			denial := permissions.Denial(c, match)
+			if denial == nil {
+				denial = pkgs.NewApiError(http.StatusForbidden, "无接口访问权限")
+			}
+			events.Record(c, pkgs.SecurityEventPermissionDenied, map[string]any{"code": denial.Code, "reason": denial.Message})
+			pkgs.Error(c, denial.Code, denial.Message)
 			return
 		}
 
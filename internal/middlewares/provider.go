@@ -6,25 +6,53 @@ import (
 )
 
 // NewUseMiddlewares 创建并返回按正确顺序排列的中间件切片
-// 顺序：logger -> auth -> permission -> recovery
+// 顺序：trace -> id obfuscation -> logger -> locale -> api version -> tenant -> auth -> permission -> query plan -> sandbox -> docs -> recovery
 func NewUseMiddlewares(
+	traceMiddleware TraceMiddleware,
+	idObfuscationMiddleware IDObfuscationMiddleware,
 	loggerMiddleware LoggerMiddleware,
+	localeMiddleware LocaleMiddleware,
+	apiVersionMiddleware APIVersionMiddleware,
+	tenantMiddleware TenantMiddleware,
 	authMiddleware AuthMiddleware,
 	permissionMiddleware PermissionMiddleware,
+	queryPlanMiddleware QueryPlanMiddleware,
+	sandboxMiddleware SandboxMiddleware,
+	docsMiddleware DocsMiddleware,
 	recoveryMiddleware RecoveryMiddleware,
 ) []gin.HandlerFunc {
 	return []gin.HandlerFunc{
+		gin.HandlerFunc(traceMiddleware),
+		gin.HandlerFunc(idObfuscationMiddleware),
 		gin.HandlerFunc(loggerMiddleware),
+		gin.HandlerFunc(localeMiddleware),
+		gin.HandlerFunc(apiVersionMiddleware),
+		gin.HandlerFunc(tenantMiddleware),
 		gin.HandlerFunc(authMiddleware),
 		gin.HandlerFunc(permissionMiddleware),
+		gin.HandlerFunc(queryPlanMiddleware),
+		gin.HandlerFunc(sandboxMiddleware),
+		gin.HandlerFunc(docsMiddleware),
 		gin.HandlerFunc(recoveryMiddleware),
 	}
 }
 
 var ProviderSet = wire.NewSet(
+	NewTraceMiddleware,
+	NewIDObfuscationMiddleware,
 	NewLoggerMiddleware,
 	NewRecoveryMiddleware,
 	NewAuthMiddleware,
+	NewSandboxMiddleware,
 	NewPermissionMiddleware,
+	NewQueryPlanMiddleware,
+	NewTenantMiddleware,
+	NewDocsMiddleware,
+	NewLocaleMiddleware,
+	NewAPIVersionMiddleware,
 	NewUseMiddlewares,
+	NewAPIKeyMiddleware,
+	NewRateLimitMiddleware,
+	NewResponseCacheMiddleware,
+	NewPublicAPIMiddlewares,
 )
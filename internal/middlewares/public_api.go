@@ -0,0 +1,190 @@
+package middlewares
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// 公开接口路由前缀，使用 API 密钥鉴权，不经过 JWT 鉴权和接口权限校验
+const PublicAPIPrefix = "/public/v1"
+
+// 响应缓存最多保存的条目数，超出后写入前先清理过期条目
+const maxResponseCacheEntries = 1000
+
+// PublicAPIMiddlewares 公开接口路由组使用的中间件
+// 顺序：API 密钥 -> 限流 -> 响应缓存（命中缓存的请求同样计入限流）
+type PublicAPIMiddlewares []gin.HandlerFunc
+
+func NewPublicAPIMiddlewares(
+	apiKeyMiddleware APIKeyMiddleware,
+	rateLimitMiddleware RateLimitMiddleware,
+	responseCacheMiddleware ResponseCacheMiddleware,
+) PublicAPIMiddlewares {
+	return PublicAPIMiddlewares{
+		gin.HandlerFunc(apiKeyMiddleware),
+		gin.HandlerFunc(rateLimitMiddleware),
+		gin.HandlerFunc(responseCacheMiddleware),
+	}
+}
+
+// APIKeyMiddleware API 密钥鉴权
+// 从配置的请求头（默认 X-API-Key）读取密钥，按哈希查询未吊销且未过期的密钥，
+// 校验通过后将密钥 ID 与限流等级写入 context。
+type APIKeyMiddleware gin.HandlerFunc
+
+func NewAPIKeyMiddleware(config *pkgs.Config, pool *pkgs.TenantPool, logger *zap.Logger, tables *pkgs.TableNames) APIKeyMiddleware {
+	return func(c *gin.Context) {
+		key := c.GetHeader(config.PublicAPI.Header)
+		if key == "" {
+			pkgs.Error(c, http.StatusUnauthorized, "请求头缺少 "+config.PublicAPI.Header+" 字段")
+			return
+		}
+
+		var apiKey struct {
+			ID   string `db:"id"`
+			Tier string `db:"tier"`
+		}
+		query := `SELECT id, tier FROM ` + tables.APIKey + `
+			WHERE key_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`
+		err := pool.DB(c).GetContext(c.Request.Context(), &apiKey, query, pkgs.HashAPIKey(key))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				pkgs.Error(c, http.StatusUnauthorized, "无效的 API 密钥")
+				return
+			}
+			logger.Error("查询 API 密钥失败", zap.Error(err))
+			pkgs.Error(c, http.StatusInternalServerError, "API 密钥校验失败")
+			return
+		}
+
+		c.Set(pkgs.APIKeyIDContextKey, apiKey.ID)
+		c.Set(pkgs.APIKeyTierContextKey, apiKey.Tier)
+		c.Next()
+	}
+}
+
+// RateLimitMiddleware 按 API 密钥的限流等级限流，等级取自运行时设置 public_api.tiers，在线调整后立即生效
+// 响应头返回 X-RateLimit-Limit / X-RateLimit-Remaining / X-RateLimit-Reset，超出限制返回 429 并附带 Retry-After。
+// 影子模式的等级超出限制时不拒绝请求，改为返回 X-RateLimit-Warning 响应头并记录到 RateLimitShadow。
+type RateLimitMiddleware gin.HandlerFunc
+
+// 影子模式下超出限制时的警告，响应头只能使用 ASCII
+const rateLimitShadowWarning = "rate limit exceeded; this request will be rejected once the limit is enforced"
+
+func NewRateLimitMiddleware(settings *pkgs.Settings, shadow *pkgs.RateLimitShadow) RateLimitMiddleware {
+	limiter := pkgs.NewRateLimiter()
+	return func(c *gin.Context) {
+		tierName := c.GetString(pkgs.APIKeyTierContextKey)
+		tier, ok := settings.RateLimitTier(tierName)
+		if !ok {
+			pkgs.Error(c, http.StatusForbidden, "API 密钥的限流等级不存在")
+			return
+		}
+
+		tenant, apiKeyID := c.GetString(pkgs.TenantContextKey), c.GetString(pkgs.APIKeyIDContextKey)
+		res := limiter.Allow(tenant+":"+apiKeyID, tier.Limit, tier.Window)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(res.Reset.Unix(), 10))
+		if !res.Allowed {
+			if tier.Shadow {
+				shadow.Record(tenant, apiKeyID, tierName, tier.Window, res)
+				c.Header("X-RateLimit-Warning", rateLimitShadowWarning)
+				c.Next()
+				return
+			}
+			retryAfter := int(time.Until(res.Reset).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			pkgs.Error(c, http.StatusTooManyRequests, "请求过于频繁，请稍后再试")
+			return
+		}
+		c.Next()
+	}
+}
+
+// ResponseCacheMiddleware 公开接口 GET 响应缓存
+// 按租户 + 接口版本 + 请求时区 + 请求语言 + 请求 URI 缓存成功响应（成功业务码），缓存时间由 public_api.cache_ttl 配置，为 0 时不缓存。
+// 响应头 X-Cache 标记 HIT / MISS，命中时附带 Cache-Control。缓存保存在进程内存中，数据变更后最多延迟一个缓存周期生效。
+type ResponseCacheMiddleware gin.HandlerFunc
+
+type cachedResponse struct {
+	body        []byte
+	contentType string
+	expiresAt   time.Time
+}
+
+// cacheWriter 在写出响应的同时保留一份副本用于缓存
+type cacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func NewResponseCacheMiddleware(config *pkgs.Config, codes *pkgs.ResponseCodes) ResponseCacheMiddleware {
+	var mu sync.Mutex
+	entries := make(map[string]cachedResponse)
+	ttl := config.PublicAPI.CacheTTL
+
+	return func(c *gin.Context) {
+		if ttl <= 0 || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		// 不同接口版本的响应结构不同、不同时区（由 ?tz=、X-Timezone 或 Accept-Language 确定）的时间字段不同，
+		// 不同语言的显示名称不同，分别缓存
+		key := c.GetString(pkgs.TenantContextKey) + ":" + strconv.Itoa(pkgs.APIVersion(c)) + ":" +
+			pkgs.RequestLocation(c).String() + ":" + c.GetHeader("Accept-Language") + ":" + c.Request.URL.RequestURI()
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Writer.Header().Add("Vary", "X-Timezone")
+		now := time.Now()
+		mu.Lock()
+		entry, ok := entries[key]
+		mu.Unlock()
+		if ok && now.Before(entry.expiresAt) {
+			c.Header("X-Cache", "HIT")
+			c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(entry.expiresAt.Sub(now).Seconds())))
+			c.Data(http.StatusOK, entry.contentType, entry.body)
+			c.Abort()
+			return
+		}
+
+		c.Header("X-Cache", "MISS")
+		writer := &cacheWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// 只缓存成功的业务响应
+		var resp pkgs.Response
+		if err := json.Unmarshal(writer.body.Bytes(), &resp); err != nil || resp.Code != codes.Success() {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(entries) >= maxResponseCacheEntries {
+			for k, e := range entries {
+				if !now.Before(e.expiresAt) {
+					delete(entries, k)
+				}
+			}
+			if len(entries) >= maxResponseCacheEntries {
+				return
+			}
+		}
+		entries[key] = cachedResponse{body: writer.body.Bytes(), contentType: writer.Header().Get("Content-Type"), expiresAt: now.Add(ttl)}
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// 执行计划捕获中间件，用于排查慢的列表筛选组合（见 pkgs.QueryPlanConfig）
+// 同时满足以下条件时，请求中经 pkgs.QueryAll、pkgs.NamedQueryAll 执行的查询会以 EXPLAIN (ANALYZE, BUFFERS) 再执行一次，
+// 执行计划附在响应的 meta.query_plans 中：
+//  1. 开启了 query_plan.enabled（server.mode 为 release 时不允许开启）；
+//  2. GET 请求，且请求头 X-Debug-Query-Plan 为 true；
+//  3. 当前用户拥有编码为 debug:query_plan 的权限。
+//
+// 不满足条件的请求按普通请求处理，不返回错误。
+type QueryPlanMiddleware gin.HandlerFunc
+
+func NewQueryPlanMiddleware(config *pkgs.Config, permissions *pkgs.PermissionChecker, logger *zap.Logger) QueryPlanMiddleware {
+	return func(c *gin.Context) {
+		if !config.QueryPlan.Enabled || c.Request.Method != http.MethodGet || c.GetHeader(pkgs.QueryPlanHeader) != "true" || pkgs.CurrentUserID(c) == "" {
+			c.Next()
+			return
+		}
+		requestLogger := pkgs.RequestLogger(c, logger)
+		allowed, err := permissions.HasCode(c, pkgs.QueryPlanPermission)
+		if err != nil {
+			requestLogger.Warn("校验执行计划权限失败", zap.Error(err))
+		}
+		if !allowed {
+			c.Next()
+			return
+		}
+
+		request := c.Request
+		c.Request = request.WithContext(pkgs.WithQueryPlans(request.Context(), config.QueryPlan.Timeout, requestLogger))
+		defer func() { c.Request = request }()
+		c.Next()
+	}
+}
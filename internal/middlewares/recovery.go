@@ -20,7 +20,7 @@ func NewRecoveryMiddleware(logger *zap.Logger) RecoveryMiddleware {
 				stack := debug.Stack()
 
 				// 记录错误日志
-				logger.Error("Panic recovered",
+				pkgs.RequestLogger(c, logger).Error("Panic recovered",
 					zap.Any("error", err),
 					zap.String("stack", string(stack)),
 					zap.String("path", c.Request.URL.Path),
@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// 沙箱中间件
+// 使用沙箱令牌（由 AuthMiddleware 解析出允许访问的接口）的请求：
+//  1. 未开启 sandbox.enabled 时返回 401；
+//  2. 接口不在令牌允许的范围内，或属于不能在沙箱中调用的接口（见 pkgs.SandboxDenied）时返回 403；
+//  3. 其余请求占用一个数据库连接开启事务，后续中间件与处理器的全部数据库操作都在该事务中执行，请求结束后回滚，
+//     响应带 X-Sandbox: rolled-back 头；超过 sandbox.timeout 的请求被中止。
+//
+// 普通令牌的请求直接放行。接口权限已由之前的 PermissionMiddleware 按令牌所属用户校验。
+type SandboxMiddleware gin.HandlerFunc
+
+func NewSandboxMiddleware(config *pkgs.Config, pool *pkgs.TenantPool, logger *zap.Logger) SandboxMiddleware {
+	return func(c *gin.Context) {
+		scopes, ok := pkgs.SandboxScopesFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		if !config.Sandbox.Enabled {
+			pkgs.Error(c, http.StatusUnauthorized, "沙箱模式未开启")
+			return
+		}
+		// 未注册的路由交给 gin 返回 404
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+		if reason, denied := pkgs.SandboxDenied(c.Request.Method, route); denied {
+			pkgs.Error(c, http.StatusForbidden, "该接口不能在沙箱中调用："+reason)
+			return
+		}
+		if !scopes.Allows(c.Request.Method, route) {
+			pkgs.Error(c, http.StatusForbidden, "沙箱令牌无权访问该接口")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), config.Sandbox.Timeout)
+		defer cancel()
+		request := c.Request
+		c.Request = request.WithContext(ctx)
+		defer func() { c.Request = request }()
+
+		ran := false
+		err := pool.RunSandbox(c, func() {
+			ran = true
+			c.Header(pkgs.SandboxHeader, "rolled-back")
+			c.Next()
+		})
+		if err == nil {
+			return
+		}
+		// 请求执行后回滚失败，连接已被丢弃，事务不会提交
+		if ran {
+			logger.Error("回滚沙箱事务失败", zap.Error(err))
+			return
+		}
+		logger.Error("开启沙箱事务失败", zap.Error(err))
+		pkgs.Error(c, http.StatusInternalServerError, "开启沙箱失败")
+	}
+}
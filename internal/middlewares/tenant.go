@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// 租户解析中间件
+// 1. 未启用 schema-per-tenant 模式时直接放行；
+// 2. 从配置的请求头（默认 X-Tenant-ID）读取租户标识，未携带时使用默认 schema；
+// 3. 租户标识非法返回 400，租户 schema 不存在返回 404；
+// 4. 校验通过后预先建立租户连接池，并将租户标识写入 context，后续仓储通过 TenantPool.DB 获取租户连接。
+type TenantMiddleware gin.HandlerFunc
+
+func NewTenantMiddleware(config *pkgs.Config, pool *pkgs.TenantPool, logger *zap.Logger) TenantMiddleware {
+	return func(c *gin.Context) {
+		if !pool.Enabled() {
+			c.Next()
+			return
+		}
+
+		tenant := c.GetHeader(config.Tenant.Header)
+		if tenant == "" {
+			c.Next()
+			return
+		}
+
+		if !pool.ValidTenant(tenant) {
+			pkgs.Error(c, http.StatusBadRequest, "租户标识格式错误")
+			return
+		}
+
+		exists, err := pool.Exists(c.Request.Context(), tenant)
+		if err != nil {
+			logger.Error("查询租户失败", zap.String("tenant", tenant), zap.Error(err))
+			pkgs.Error(c, http.StatusInternalServerError, "查询租户失败")
+			return
+		}
+		if !exists {
+			pkgs.Error(c, http.StatusNotFound, "租户不存在")
+			return
+		}
+
+		if _, err := pool.Get(tenant); err != nil {
+			logger.Error("创建租户连接池失败", zap.String("tenant", tenant), zap.Error(err))
+			pkgs.Error(c, http.StatusInternalServerError, "连接租户数据库失败")
+			return
+		}
+
+		c.Set(pkgs.TenantContextKey, tenant)
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// TraceMiddleware 请求ID
+// 沿用请求头 X-Request-ID 中合法的请求ID，否则生成新的请求ID；写入 context 并在响应头中返回。
+// 请求日志与请求发起的异步任务都会记录该ID，便于跨同步、异步两段排查同一次用户操作。
+// 同时在 context 中保存带 trace_id 字段的日志器，仓储通过 pkgs.RequestLogger 取用，错误日志自动带上请求ID。
+type TraceMiddleware gin.HandlerFunc
+
+func NewTraceMiddleware(logger *zap.Logger) TraceMiddleware {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader(pkgs.TraceIDHeader)
+		if !pkgs.ValidTraceID(traceID) {
+			traceID = pkgs.NewTraceID()
+		}
+		c.Set(pkgs.TraceIDContextKey, traceID)
+		c.Header(pkgs.TraceIDHeader, traceID)
+		pkgs.SetRequestLogger(c, logger)
+		c.Next()
+	}
+}
@@ -0,0 +1,592 @@
+// Package admin API.
+//
+// 运维管理API接口。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-pg-demo/pkgs"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+	events     *pkgs.SecurityEvents
+	cache      *pkgs.PermissionCache
+}
+
+func NewAdminHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, config *pkgs.Config, pool *pkgs.TenantPool, reporting *pkgs.ReportingDB, tables *pkgs.TableNames, retention *pkgs.Retention, ids *pkgs.IDGenerator, jobs *pkgs.JobQueue, cache *pkgs.PermissionCache, matcher *pkgs.PermissionMatcher, events *pkgs.SecurityEvents, shadow *pkgs.RateLimitShadow, redisClient *redis.Client, settings *pkgs.Settings) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, offboardRule)
+	pkgs.RegisterRule(validator, restoreSnapshotRule)
+	pkgs.RegisterRule(validator, revokeTokensRule)
+
+	repository := &Repository{
+		db:        db,
+		logger:    logger,
+		config:    config,
+		pool:      pool,
+		reporting: reporting,
+		tables:    tables,
+		retention: retention,
+		ids:       ids,
+		jobs:      jobs,
+		cache:     cache,
+		matcher:   matcher,
+		shadow:    shadow,
+		redis:     redisClient,
+		settings:  settings,
+	}
+	// 注册离职交接任务
+	jobs.Register(JobTypeOffboarding, repository.runOffboarding)
+
+	return &Handler{
+		db:         db,
+		logger:     logger,
+		validator:  validator,
+		repository: repository,
+		events:     events,
+		cache:      cache,
+	}
+}
+
+// SlowQueries 慢查询与索引建议
+//
+//	@Summary  慢查询与索引建议
+//	@Description  汇总 pg_stat_statements 中平均耗时最高的语句，对其中的 SELECT 生成通用执行计划（EXPLAIN GENERIC_PLAN），为带过滤条件的顺序扫描给出建索引语句；同时返回各表的顺序扫描/索引扫描统计。需要数据库启用 pg_stat_statements 扩展
+//	@Tags   运维管理
+//	@Produce  json
+//	@Param    limit   query int   false "返回条数"  default(20)
+//	@Param    minMeanMs query number  false "最小平均耗时（毫秒）"  default(0)
+//	@Success  200 {object}  pkgs.Response{data=SlowQueriesRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误或未启用 pg_stat_statements"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/slow-queries"}
+//	@Router   /admin/slow-queries [get]
+func (h *Handler) SlowQueries(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[SlowQueriesReq](c),
+		result.FlatMap(pkgs.ValidateV2[SlowQueriesReq](h.validator)),
+		result.FlatMap(h.repository.SlowQueries(c)),
+	).Match(
+		pkgs.HandleSuccess[SlowQueriesRes](c),
+		pkgs.HandleError[SlowQueriesRes](c),
+	)
+}
+
+// Retention 数据保留策略
+//
+//	@Summary  数据保留策略
+//	@Description  返回每个数据类别的保留策略、最近一次执行结果，以及下次执行（每天 03:00）将清理的行数和预告期内将陆续过期的行数
+//	@Tags   运维管理
+//	@Produce  json
+//	@Param    days  query int false "预告天数"  default(7)
+//	@Success  200 {object}  pkgs.Response{data=RetentionRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/retention"}
+//	@Router   /admin/retention [get]
+func (h *Handler) Retention(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[RetentionReq](c),
+		result.FlatMap(pkgs.ValidateV2[RetentionReq](h.validator)),
+		result.FlatMap(h.repository.Retention(c)),
+	).Match(
+		pkgs.HandleSuccess[RetentionRes](c),
+		pkgs.HandleError[RetentionRes](c),
+	)
+}
+
+// UpdateRetention 修改数据保留策略
+//
+//	@Summary  修改数据保留策略
+//	@Description  修改数据类别的保留天数与启用状态，停用后定时任务跳过该类别
+//	@Tags   运维管理
+//	@Accept   json
+//	@Produce  json
+//	@Param    category  path  string        true  "数据类别"
+//	@Param    request   body  UpdateRetentionReq  true  "保留策略"
+//	@Success  200 {object}  pkgs.Response{data=UpdateRetentionRes}  "修改成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response         "数据类别不存在"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/admin/retention/:category"}
+//	@Router   /admin/retention/{category} [put]
+func (h *Handler) UpdateRetention(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUriAndJSON[UpdateRetentionReq](c),
+		result.FlatMap(pkgs.ValidateV2[UpdateRetentionReq](h.validator)),
+		result.FlatMap(h.repository.UpdateRetention(c)),
+	).Match(
+		pkgs.HandleSuccess[UpdateRetentionRes](c),
+		pkgs.HandleError[UpdateRetentionRes](c),
+	)
+}
+
+// Settings 运行时设置
+//
+//	@Summary  运行时设置
+//	@Description  返回可在线调整的设置项（令牌有效期、密码策略、公开接口限流等级）当前生效的取值、配置文件中的取值与最近一次修改。
+//	@Description  source 为 database 时取值来自在线修改，为 config 时来自配置文件；设置对整个部署生效，不区分租户
+//	@Tags   运维管理
+//	@Produce  json
+//	@Success  200 {object}  pkgs.Response{data=SettingsRes}  "获取成功"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/settings"}
+//	@Router   /admin/settings [get]
+func (h *Handler) Settings(c *gin.Context) {
+	h.repository.Settings(c)().Match(
+		pkgs.HandleSuccess[SettingsRes](c),
+		pkgs.HandleError[SettingsRes](c),
+	)
+}
+
+// UpdateSettings 修改运行时设置
+//
+//	@Summary  修改运行时设置
+//	@Description  在一个事务内修改一个或多个设置项并记录变更历史，取值的格式与 GET /admin/settings 返回的 value 相同，取值为 null 时恢复为配置文件中的取值。
+//	@Description  任一设置项不存在或取值无效时不修改任何设置项。本实例立即生效，其他实例在下次重新加载（settings.reload_schedule，默认每分钟）后生效；令牌有效期只影响之后签发的令牌
+//	@Tags   运维管理
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  UpdateSettingsReq true  "设置项"
+//	@Success  200 {object}  pkgs.Response{data=UpdateSettingsRes}  "修改成功，返回取值发生变化的设置项"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误、设置项不存在或取值无效"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/admin/settings"}
+//	@Router   /admin/settings [put]
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindJSON[UpdateSettingsReq](c),
+		result.FlatMap(pkgs.ValidateV2[UpdateSettingsReq](h.validator)),
+		result.FlatMap(h.repository.UpdateSettings(c)),
+		result.Map(pkgs.RecordSecurityEvent[UpdateSettingsRes](c, h.events, pkgs.SecurityEventSettingsUpdate, nil)),
+	).Match(
+		pkgs.HandleSuccess[UpdateSettingsRes](c),
+		pkgs.HandleError[UpdateSettingsRes](c),
+	)
+}
+
+// SettingsHistory 设置变更历史
+//
+//	@Summary  设置变更历史
+//	@Description  分页返回运行时设置的变更记录，最近的修改排在前面；old_value、new_value 为空表示配置文件中的取值
+//	@Tags   运维管理
+//	@Produce  json
+//	@Param    page      query int     false "页码"  default(1)
+//	@Param    pageSize  query int     false "每页数量"  default(10)
+//	@Param    key       query string  false "设置项"
+//	@Success  200 {object}  pkgs.Response{data=SettingsHistoryRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/settings/history"}
+//	@Router   /admin/settings/history [get]
+func (h *Handler) SettingsHistory(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[SettingsHistoryReq](c),
+		result.FlatMap(pkgs.ValidateV2[SettingsHistoryReq](h.validator)),
+		result.FlatMap(h.repository.SettingsHistory(c)),
+	).Match(
+		pkgs.HandleSuccess[SettingsHistoryRes](c),
+		pkgs.HandleError[SettingsHistoryRes](c),
+	)
+}
+
+// Offboard 发起离职交接
+//
+//	@Summary  发起离职交接
+//	@Description  以异步任务执行离职交接：撤销用户的全部角色、删除登录设备（终止会话）、将用户拥有的模板转交给交接人、停用账号，并生成交接报告。返回交接记录ID，通过 GET /admin/offboard/{id} 查询执行状态与报告；同一用户同时只能有一个进行中的交接
+//	@Tags   运维管理
+//	@Accept   json
+//	@Produce  json
+//	@Param    userId  path  string      true  "离职用户ID"
+//	@Param    request body  OffboardReq true  "交接参数"
+//	@Success  200 {object}  pkgs.Response{data=OffboardRes}  "已提交"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误或交接人不存在、已停用"
+//	@Failure  404 {object}  pkgs.Response         "用户不存在"
+//	@Failure  409 {object}  pkgs.Response         "用户已停用或已有进行中的交接"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/admin/offboard/:userId"}
+//	@Router   /admin/offboard/{userId} [post]
+func (h *Handler) Offboard(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindUriAndJSON[OffboardReq](c),
+		result.FlatMap(pkgs.ValidateV2[OffboardReq](h.validator)),
+		result.FlatMap(h.repository.Offboard(c)),
+		result.Map(pkgs.RecordSecurityEvent[OffboardRes](c, h.events, pkgs.SecurityEventUserOffboard, map[string]any{"user_id": c.Param("userId")})),
+	).Match(
+		pkgs.HandleSuccess[OffboardRes](c),
+		pkgs.HandleError[OffboardRes](c),
+	)
+}
+
+// GetOffboarding 查询离职交接
+//
+//	@Summary  查询离职交接
+//	@Description  返回离职交接的任务状态（pending、running、succeeded、failed、canceled）、失败原因，以及完成后的交接报告（撤销的角色、终止的会话数、转交的模板数、停用时间）
+//	@Tags   运维管理
+//	@Produce  json
+//	@Param    id  path  string  true  "交接记录ID"
+//	@Success  200 {object}  pkgs.Response{data=GetOffboardingRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response         "离职交接记录不存在"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/offboard/:id"}
+//	@Router   /admin/offboard/{id} [get]
+func (h *Handler) GetOffboarding(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[GetOffboardingReq](c),
+		result.FlatMap(pkgs.ValidateV2[GetOffboardingReq](h.validator)),
+		result.FlatMap(h.repository.GetOffboarding(c)),
+	).Match(
+		pkgs.HandleSuccess[GetOffboardingRes](c),
+		pkgs.HandleError[GetOffboardingRes](c),
+	)
+}
+
+// ExportSnapshot 导出环境快照
+//
+//	@Summary  导出环境快照
+//	@Description  在一致性读事务中导出 iacc_*、保存的列表视图、关注与模板表（模板模块开启时）的全部数据，用于复制预发环境或灾备演练，通过 POST /admin/snapshot/restore 恢复。
+//	@Description  不包含密码、API 密钥、登录设备等凭据，也不包含异步任务、角色变更记录等运行数据；手机号等加密字段保持密文，只能恢复到字段加密密钥相同的环境
+//	@Tags   运维管理
+//	@Produce  json
+//	@Success  200 {object}  pkgs.Response{data=Snapshot}  "导出成功"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/snapshot"}
+//	@Router   /admin/snapshot [get]
+func (h *Handler) ExportSnapshot(c *gin.Context) {
+	result.Pipe1(
+		h.repository.ExportSnapshot(c)(),
+		result.Map(h.recordSnapshotExport(c)),
+	).Match(
+		pkgs.HandleSuccess[Snapshot](c),
+		pkgs.HandleError[Snapshot](c),
+	)
+}
+
+// recordSnapshotExport 记录导出快照的安全事件，只记录各表行数
+func (h *Handler) recordSnapshotExport(c *gin.Context) func(Snapshot) Snapshot {
+	return func(snapshot Snapshot) Snapshot {
+		if h.events.Enabled() {
+			tables := make(map[string]int, len(snapshot.Tables))
+			for _, table := range snapshot.Tables {
+				var rows []json.RawMessage
+				_ = json.Unmarshal(table.Rows, &rows)
+				tables[table.Name] = len(rows)
+			}
+			h.events.Record(c, pkgs.SecurityEventSnapshotExport, map[string]any{"tables": tables})
+		}
+		return snapshot
+	}
+}
+
+// RestoreSnapshot 恢复环境快照
+//
+//	@Summary  恢复环境快照
+//	@Description  在一个事务内恢复 GET /admin/snapshot 导出的快照，任一表失败时全部回滚；快照中没有的表不做改动。
+//	@Description  mode=merge（默认）按主键新增或覆盖；mode=replace 额外删除快照中不存在的行。新增的用户没有密码，需要重置密码后登录；已存在的用户保留原密码
+//	@Tags   运维管理
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  RestoreSnapshotReq  true  "恢复快照请求参数"
+//	@Success  200 {object}  pkgs.Response{data=RestoreSnapshotRes}  "恢复成功，返回各表新增或覆盖、删除的行数"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误或快照格式无效"
+//	@Failure  409 {object}  pkgs.Response         "字段加密密钥不一致或与现有数据冲突"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/admin/snapshot/restore"}
+//	@Router   /admin/snapshot/restore [post]
+func (h *Handler) RestoreSnapshot(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindJSON[RestoreSnapshotReq](c),
+		result.FlatMap(pkgs.ValidateV2[RestoreSnapshotReq](h.validator)),
+		result.FlatMap(h.repository.RestoreSnapshot(c)),
+		result.Map(pkgs.InvalidatePermissionCache[RestoreSnapshotRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[RestoreSnapshotRes](c, h.events, pkgs.SecurityEventSnapshotRestore, nil)),
+	).Match(
+		pkgs.HandleSuccess[RestoreSnapshotRes](c),
+		pkgs.HandleError[RestoreSnapshotRes](c),
+	)
+}
+
+// QueryJobs 查询异步任务
+//
+//	@Summary  查询异步任务
+//	@Description  按任务类型返回当前租户的队列状态：等待执行与执行中的任务数、最早等待任务的等待时长，以及统计窗口内成功、失败、取消的任务数、失败率与重试次数；同时分页返回任务（按创建时间倒序）。
+//	@Description  所有租户汇总的队列深度、最早等待时长与执行结果计数同时通过 /metrics 暴露（async_job_queue_depth、async_job_oldest_pending_seconds、async_job_runs_total）
+//	@Tags   运维管理
+//	@Produce  json
+//	@Param    page  query int false "页码"  default(1)
+//	@Param    pageSize  query int false "每页大小"  default(10)
+//	@Param    status  query string  false "任务状态"  Enums(pending, running, succeeded, failed, canceled)
+//	@Param    type  query string  false "任务类型"
+//	@Param    windowHours query int false "统计窗口（小时）"  default(24)
+//	@Success  200 {object}  pkgs.Response{data=QueryJobsRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/jobs"}
+//	@Router   /admin/jobs [get]
+func (h *Handler) QueryJobs(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[QueryJobsReq](c),
+		result.FlatMap(pkgs.ValidateV2[QueryJobsReq](h.validator)),
+		result.FlatMap(h.repository.QueryJobs(c)),
+	).Match(
+		pkgs.HandleSuccess[QueryJobsRes](c),
+		pkgs.HandleError[QueryJobsRes](c),
+	)
+}
+
+// RetryJob 重试异步任务
+//
+//	@Summary  重试异步任务
+//	@Description  将已失败或已取消的任务重新排队，下一轮轮询（约 5 秒）执行；已用完执行次数的任务额外允许执行一次
+//	@Tags   运维管理
+//	@Produce  json
+//	@Param    id  path  string  true  "任务ID"
+//	@Success  200 {object}  pkgs.Response{data=JobActionRes}  "已重新排队"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response         "任务不存在"
+//	@Failure  409 {object}  pkgs.Response         "任务未失败或未取消"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/admin/jobs/:id/retry"}
+//	@Router   /admin/jobs/{id}/retry [post]
+func (h *Handler) RetryJob(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[JobIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[JobIDReq](h.validator)),
+		result.FlatMap(h.repository.RetryJob(c)),
+	).Match(
+		pkgs.HandleSuccess[JobActionRes](c),
+		pkgs.HandleError[JobActionRes](c),
+	)
+}
+
+// CancelJob 取消异步任务
+//
+//	@Summary  取消异步任务
+//	@Description  取消等待执行（包括失败后等待重试）的任务；执行中的任务无法中断，不能取消。取消后可以通过重试接口重新排队
+//	@Tags   运维管理
+//	@Produce  json
+//	@Param    id  path  string  true  "任务ID"
+//	@Success  200 {object}  pkgs.Response{data=JobActionRes}  "已取消"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response         "任务不存在"
+//	@Failure  409 {object}  pkgs.Response         "任务不在等待执行状态"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/admin/jobs/:id/cancel"}
+//	@Router   /admin/jobs/{id}/cancel [post]
+func (h *Handler) CancelJob(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[JobIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[JobIDReq](h.validator)),
+		result.FlatMap(h.repository.CancelJob(c)),
+	).Match(
+		pkgs.HandleSuccess[JobActionRes](c),
+		pkgs.HandleError[JobActionRes](c),
+	)
+}
+
+// RateLimitShadow 影子模式限流报告
+//
+//	@Summary  影子模式限流报告
+//	@Description  返回当前租户在影子模式（限流等级配置 shadow: true）下超出限制的 API 密钥：会被拒绝的请求数、单个时间窗口内的最大请求数与首次、最近超出时间，用于在启用限流前校准各等级的限制。
+//	@Description  影子模式下超出限制的请求不会被拒绝，只在响应中附带 X-RateLimit-Warning 头。统计保存在各实例的内存中，重启后清空
+//	@Tags   运维管理
+//	@Produce  json
+//	@Success  200 {object}  pkgs.Response{data=RateLimitShadowRes}  "获取成功"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/rate-limit/shadow"}
+//	@Router   /admin/rate-limit/shadow [get]
+func (h *Handler) RateLimitShadow(c *gin.Context) {
+	h.repository.RateLimitShadow(c)().Match(
+		pkgs.HandleSuccess[RateLimitShadowRes](c),
+		pkgs.HandleError[RateLimitShadowRes](c),
+	)
+}
+
+// PermissionCacheStatus 权限缓存状态
+//
+//	@Summary  权限缓存状态
+//	@Description  返回当前租户的权限缓存状态：Redis 熔断器状态、权限版本号与最近一次失效时间、当前版本号下缓存的用户数与已失效但尚未过期的用户数，
+//	@Description  以及处理本请求的实例启动以来的命中统计和本实例编译的接口权限记录（编译时间、记录数、是否已超过 permission_cache.ttl）。未配置 Redis 时只返回本实例的数据
+//	@Tags   运维管理
+//	@Produce  json
+//	@Success  200 {object}  pkgs.Response{data=PermissionCacheStatusRes}  "获取成功"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/permission-cache/status"}
+//	@Router   /admin/permission-cache/status [get]
+func (h *Handler) PermissionCacheStatus(c *gin.Context) {
+	h.repository.PermissionCacheStatus(c)().Match(
+		pkgs.HandleSuccess[PermissionCacheStatusRes](c),
+		pkgs.HandleError[PermissionCacheStatusRes](c),
+	)
+}
+
+// RebuildPermissionCache 重建权限缓存
+//
+//	@Summary  重建权限缓存
+//	@Description  直接修改数据库中的角色、权限数据后强制刷新授权状态，无需重启实例：递增当前租户的权限版本号使所有用户的缓存失效，并立即重新编译本实例的接口权限记录；
+//	@Description  其他实例在下一次读取缓存时发现版本号变化，同样重新编译。未配置 Redis 时只重新编译本实例，其他实例最多在 permission_cache.ttl 后刷新
+//	@Tags   运维管理
+//	@Produce  json
+//	@Success  200 {object}  pkgs.Response{data=RebuildPermissionCacheRes}  "重建成功"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/admin/permission-cache/rebuild"}
+//	@Router   /admin/permission-cache/rebuild [post]
+func (h *Handler) RebuildPermissionCache(c *gin.Context) {
+	h.repository.RebuildPermissionCache(c)().Match(
+		pkgs.HandleSuccess[RebuildPermissionCacheRes](c),
+		pkgs.HandleError[RebuildPermissionCacheRes](c),
+	)
+}
+
+// SupportBundle 下载支持包
+//
+//	@Summary  下载支持包
+//	@Description  生成提交问题报告时附带的诊断信息 zip 包，包含：manifest.json（生成时间、请求ID、运行时信息）、config.json（脱敏后的配置，密码、密钥、令牌、地址等非空配置项替换为 [REDACTED]）、
+//	@Description  errors.json（处理本请求的实例最近 200 条 error 级别日志，已脱敏）、migrations.json（内嵌迁移的最新版本与默认 schema、各租户 schema 记录的版本）、pools.json（本实例各连接池的统计）、
+//	@Description  health.json（数据库、批处理连接池、Redis、权限缓存熔断器的检查结果）。各项信息读取失败时记录在对应文件中，不影响下载
+//	@Tags   运维管理
+//	@Produce  application/zip
+//	@Success  200 {file}  file  "支持包"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/support-bundle"}
+//	@Router   /admin/support-bundle [get]
+func (h *Handler) SupportBundle(c *gin.Context) {
+	result.Pipe1(
+		h.repository.SupportBundle(c)(),
+		result.Map(pkgs.RecordSecurityEvent[SupportBundle](c, h.events, pkgs.SecurityEventSupportBundleExport, nil)),
+	).Match(
+		func(bundle SupportBundle) (SupportBundle, error) {
+			c.Header("Content-Disposition", `attachment; filename="`+bundle.FileName+`"`)
+			c.Data(http.StatusOK, "application/zip", bundle.Content)
+			return bundle, nil
+		},
+		pkgs.HandleError[SupportBundle](c),
+	)
+}
+
+// RolePermissionMatrix 导出角色权限矩阵
+//
+//	@Summary  导出角色权限矩阵
+//	@Description  供定期权限审计使用：每行一个权限（按名称排序、分页），每列一个角色（全部角色），单元格为该角色对该权限的授予效果 allow、deny 或未授予。
+//	@Description  format=json 返回矩阵与权限总数；format=csv 返回当前页的 CSV 文件（表头为权限字段与角色名称），权限总数在 X-Total-Count 响应头中，逐页请求可以拼接出完整矩阵
+//	@Tags   运维管理
+//	@Produce  json,text/csv
+//	@Param    page      query int     false "页码（按权限分页）"  default(1)
+//	@Param    pageSize  query int     false "每页权限数"  default(10)
+//	@Param    format    query string  false "导出格式"  Enums(json, csv) default(json)
+//	@Success  200 {object}  pkgs.Response{data=RolePermissionMatrixRes}  "获取成功（format=csv 时为 CSV 文件）"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/role-permission-matrix"}
+//	@Router   /admin/role-permission-matrix [get]
+func (h *Handler) RolePermissionMatrix(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[RolePermissionMatrixReq](c),
+		result.FlatMap(pkgs.ValidateV2[RolePermissionMatrixReq](h.validator)),
+		result.FlatMap(h.repository.RolePermissionMatrix(c)),
+	).Match(
+		func(matrix RolePermissionMatrixRes) (RolePermissionMatrixRes, error) {
+			if c.Query("format") != MatrixFormatCSV {
+				return pkgs.HandleSuccess[RolePermissionMatrixRes](c)(matrix)
+			}
+			var buf bytes.Buffer
+			if err := writeMatrixCSV(&buf, matrix); err != nil {
+				h.logger.Error("生成角色权限矩阵失败", zap.Error(err))
+				return pkgs.HandleError[RolePermissionMatrixRes](c)(pkgs.NewApiError(http.StatusInternalServerError, "查询角色权限矩阵失败"))
+			}
+			c.Header("Content-Disposition", `attachment; filename="role-permission-matrix-`+c.DefaultQuery("page", "1")+`.csv"`)
+			c.Header("X-Total-Count", strconv.FormatInt(matrix.Total, 10))
+			c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+			return matrix, nil
+		},
+		pkgs.HandleError[RolePermissionMatrixRes](c),
+	)
+}
+
+// Readiness 就绪检查
+//
+//	@Summary  就绪检查
+//	@Description  分别检查主库（database）与分析库（reporting_database）连接，返回各项检查的耗时与结果；未配置分析库时该项为正常，详情说明报表查询使用主库。
+//	@Description  只有主库决定是否就绪：主库不可用时返回 503 业务码，data 中同样包含各项检查结果；分析库不可用只影响统计、导出等报表查询，不影响就绪状态。
+//	@Description  批处理连接池、Redis 与权限缓存的检查见支持包的 health.json
+//	@Tags   运维管理
+//	@Produce  json
+//	@Success  200 {object}  pkgs.Response{data=ReadinessRes}  "已就绪"
+//	@Failure  503 {object}  pkgs.Response{data=ReadinessRes}  "主库不可用"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/admin/readiness"}
+//	@Router   /admin/readiness [get]
+func (h *Handler) Readiness(c *gin.Context) {
+	h.repository.Readiness(c)().Match(
+		pkgs.HandleSuccess[ReadinessRes](c),
+		pkgs.HandleError[ReadinessRes](c),
+	)
+}
+
+// RevokeTokens 按条件批量撤销令牌
+//
+//	@Summary  按条件批量撤销令牌
+//	@Description  事件响应时按用户、签发时间、客户端类型批量撤销令牌，条件同时满足，至少指定一个：签发时间不晚于 issued_before（默认当前时间）、属于 user_ids（为空表示全部用户）、
+//	@Description  由 client_type 类型客户端签发（为空表示全部客户端）的刷新令牌不能再换取新令牌；持有命中令牌的用户已签发的访问令牌立即失效，未命中条件的刷新令牌仍可换取新的访问令牌。
+//	@Description  返回写入的撤销条件、撤销的刷新令牌数（tokens，不含已撤销的令牌）与持有这些令牌的用户数（users）
+//	@Tags   运维管理
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  RevokeTokensReq true  "批量撤销令牌请求参数"
+//	@Success  200 {object}  pkgs.Response{data=RevokeTokensRes}  "撤销成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/admin/tokens/revoke"}
+//	@Router   /admin/tokens/revoke [post]
+func (h *Handler) RevokeTokens(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindJSON[RevokeTokensReq](c),
+		result.FlatMap(pkgs.ValidateV2[RevokeTokensReq](h.validator)),
+		result.FlatMap(h.repository.RevokeTokens(c)),
+		result.Map(pkgs.RecordSecurityEvent[RevokeTokensRes](c, h.events, pkgs.SecurityEventTokensRevoke, nil)),
+	).Match(
+		pkgs.HandleSuccess[RevokeTokensRes](c),
+		pkgs.HandleError[RevokeTokensRes](c),
+	)
+}
@@ -0,0 +1,1298 @@
+package admin
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-pg-demo/migration"
+	"go-pg-demo/pkgs"
+	"io"
+	"net/http"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+// 顺序扫描次数超过索引扫描且存活行数达到该值的表标记为建议加索引
+const needsIndexMinLiveTuples = 1000
+
+// 生成执行计划的超时时间，避免复杂语句的规划阻塞接口
+const explainTimeout = "2s"
+
+// 支持包中每项健康检查的超时时间
+const probeTimeout = 2 * time.Second
+
+type Repository struct {
+	db        *sqlx.DB
+	logger    *zap.Logger
+	config    *pkgs.Config
+	pool      *pkgs.TenantPool
+	reporting *pkgs.ReportingDB
+	tables    *pkgs.TableNames
+	retention *pkgs.Retention
+	ids       *pkgs.IDGenerator
+	jobs      *pkgs.JobQueue
+	cache     *pkgs.PermissionCache
+	matcher   *pkgs.PermissionMatcher
+	shadow    *pkgs.RateLimitShadow
+	redis     *redis.Client
+	// 运行时设置对整个部署生效，保存在主 schema 中，不使用租户连接
+	settings *pkgs.Settings
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// reportConn 返回报表查询应使用的连接与表名，配置了分析库时在分析库查询，不占用主库
+func (r *Repository) reportConn(c *gin.Context) (*sqlx.DB, *pkgs.TableNames) {
+	return r.reporting.Conn(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+func (r *Repository) SlowQueries(c *gin.Context) func(*SlowQueriesReq) mo.Result[SlowQueriesRes] {
+	return func(req *SlowQueriesReq) mo.Result[SlowQueriesRes] {
+		db := r.conn(c)
+		ctx := c.Request.Context()
+
+		// pg_stat_statements 需要在 shared_preload_libraries 中加载并创建扩展
+		var enabled bool
+		if err := db.GetContext(ctx, &enabled, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')`); err != nil {
+			r.log(c).Error("查询扩展失败", zap.Error(err))
+			return mo.Err[SlowQueriesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询慢查询失败"))
+		}
+		if !enabled {
+			return mo.Err[SlowQueriesRes](pkgs.NewApiError(http.StatusBadRequest, "数据库未启用 pg_stat_statements 扩展"))
+		}
+
+		// 查询当前数据库中平均耗时最高的语句
+		list := []SlowQueryItem{}
+		query := `SELECT queryid::text AS query_id, query, calls, total_exec_time AS total_ms, mean_exec_time AS mean_ms, rows
+			FROM pg_stat_statements
+			WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database()) AND mean_exec_time >= $1
+			ORDER BY mean_exec_time DESC LIMIT $2`
+		if err := db.SelectContext(ctx, &list, query, req.MinMeanMs, req.Limit); err != nil {
+			r.log(c).Error("查询 pg_stat_statements 失败", zap.Error(err))
+			return mo.Err[SlowQueriesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询慢查询失败"))
+		}
+		for i := range list {
+			list[i].Suggestions = r.suggest(c, list[i].Query)
+		}
+
+		// 表级扫描统计
+		tables := []TableScanItem{}
+		tableQuery := `SELECT relname AS table_name, seq_scan, COALESCE(idx_scan, 0) AS idx_scan, n_live_tup AS live_tuples
+			FROM pg_stat_user_tables WHERE schemaname = current_schema() ORDER BY seq_scan DESC`
+		if err := db.SelectContext(ctx, &tables, tableQuery); err != nil {
+			r.log(c).Error("查询表扫描统计失败", zap.Error(err))
+			return mo.Err[SlowQueriesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询慢查询失败"))
+		}
+		for i := range tables {
+			tables[i].NeedsIndex = tables[i].SeqScan > tables[i].IdxScan && tables[i].LiveTuples >= needsIndexMinLiveTuples
+		}
+
+		// 返回结果
+		return mo.Ok(SlowQueriesRes{List: list, Tables: tables})
+	}
+}
+
+// suggest 对查询语句生成通用执行计划并给出索引建议
+// 只分析单条 SELECT；在只读事务中执行 EXPLAIN 且不实际运行语句，失败时返回空建议
+func (r *Repository) suggest(c *gin.Context, query string) []pkgs.IndexSuggestion {
+	statement := strings.TrimSpace(query)
+	if !strings.HasPrefix(strings.ToUpper(statement), "SELECT") || strings.Contains(statement, ";") {
+		return []pkgs.IndexSuggestion{}
+	}
+
+	ctx := c.Request.Context()
+	tx, err := r.conn(c).BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(c).Warn("开启事务失败", zap.Error(err))
+		return []pkgs.IndexSuggestion{}
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SET TRANSACTION READ ONLY`); err != nil {
+		r.log(c).Warn("设置只读事务失败", zap.Error(err))
+		return []pkgs.IndexSuggestion{}
+	}
+	if _, err := tx.ExecContext(ctx, `SET LOCAL statement_timeout = '`+explainTimeout+`'`); err != nil {
+		r.log(c).Warn("设置语句超时失败", zap.Error(err))
+		return []pkgs.IndexSuggestion{}
+	}
+	// pg_stat_statements 中的语句已参数化（$1、$2），GENERIC_PLAN 允许在没有参数值时生成计划
+	var plan []byte
+	if err := tx.GetContext(ctx, &plan, `EXPLAIN (FORMAT JSON, GENERIC_PLAN) `+statement); err != nil {
+		r.log(c).Debug("生成执行计划失败", zap.String("query", statement), zap.Error(err))
+		return []pkgs.IndexSuggestion{}
+	}
+	suggestions, err := pkgs.SuggestIndexes(plan)
+	if err != nil {
+		r.log(c).Warn("解析执行计划失败", zap.Error(err))
+		return []pkgs.IndexSuggestion{}
+	}
+	return suggestions
+}
+
+// Retention 汇总数据保留策略与即将清理的数据量
+func (r *Repository) Retention(c *gin.Context) func(*RetentionReq) mo.Result[RetentionRes] {
+	return func(req *RetentionReq) mo.Result[RetentionRes] {
+		list, err := r.retention.Summary(c.Request.Context(), r.conn(c), time.Duration(req.Days)*24*time.Hour)
+		if err != nil {
+			return mo.Err[RetentionRes](pkgs.DBError(r.log(c), err, "查询数据保留策略失败"))
+		}
+		return mo.Ok(RetentionRes{List: list})
+	}
+}
+
+// UpdateRetention 修改数据类别的保留天数与启用状态
+func (r *Repository) UpdateRetention(c *gin.Context) func(*UpdateRetentionReq) mo.Result[UpdateRetentionRes] {
+	return func(req *UpdateRetentionReq) mo.Result[UpdateRetentionRes] {
+		if _, ok := pkgs.RetentionCategoryByName(req.Category); !ok {
+			return mo.Err[UpdateRetentionRes](pkgs.NewApiError(http.StatusNotFound, "数据类别不存在"))
+		}
+
+		query := `UPDATE ` + r.tables.Retention + ` SET retain_days = $1, enabled = $2 WHERE category = $3`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.RetainDays, *req.Enabled, req.Category)
+		if err != nil {
+			return mo.Err[UpdateRetentionRes](pkgs.DBError(r.log(c), err, "修改数据保留策略失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[UpdateRetentionRes](pkgs.NewApiError(http.StatusInternalServerError, "修改数据保留策略失败"))
+		}
+		if affectedRows == 0 {
+			return mo.Err[UpdateRetentionRes](pkgs.NewApiError(http.StatusNotFound, "该数据类别未配置保留策略"))
+		}
+		return mo.Ok(affectedRows)
+	}
+}
+
+// Settings 返回全部设置项当前生效的取值
+func (r *Repository) Settings(c *gin.Context) func() mo.Result[SettingsRes] {
+	return func() mo.Result[SettingsRes] {
+		list, err := r.settings.List(c.Request.Context())
+		if err != nil {
+			return mo.Err[SettingsRes](pkgs.DBError(r.log(c), err, "查询运行时设置失败"))
+		}
+		return mo.Ok(SettingsRes{List: list})
+	}
+}
+
+// UpdateSettings 修改运行时设置并记录变更历史
+func (r *Repository) UpdateSettings(c *gin.Context) func(*UpdateSettingsReq) mo.Result[UpdateSettingsRes] {
+	return func(req *UpdateSettingsReq) mo.Result[UpdateSettingsRes] {
+		changed, err := r.settings.Update(c.Request.Context(), pkgs.CurrentUserID(c), req.Values)
+		if err != nil {
+			var apiErr *pkgs.ApiError
+			if errors.As(err, &apiErr) {
+				return mo.Err[UpdateSettingsRes](apiErr)
+			}
+			return mo.Err[UpdateSettingsRes](pkgs.DBError(r.log(c), err, "修改运行时设置失败"))
+		}
+		if changed == nil {
+			changed = []string{}
+		}
+		return mo.Ok(UpdateSettingsRes{Changed: changed})
+	}
+}
+
+// SettingsHistory 分页查询设置变更历史，最近的修改排在前面
+func (r *Repository) SettingsHistory(c *gin.Context) func(*SettingsHistoryReq) mo.Result[SettingsHistoryRes] {
+	return func(req *SettingsHistoryReq) mo.Result[SettingsHistoryRes] {
+		ctx := c.Request.Context()
+
+		// 构建查询
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
+		}
+		whereCondition := ""
+		if req.Key != "" {
+			whereCondition = " WHERE key = :key"
+			params["key"] = req.Key
+		}
+
+		// 查询总数
+		var total int64
+		countQuery, countArgs, err := sqlx.Named("SELECT count(*) FROM "+r.tables.SettingsHistory+whereCondition, params)
+		if err != nil {
+			r.log(c).Error("构建计数查询失败", zap.Error(err))
+			return mo.Err[SettingsHistoryRes](pkgs.NewApiError(http.StatusInternalServerError, "查询设置变更历史失败"))
+		}
+		if err := r.db.GetContext(ctx, &total, r.db.Rebind(countQuery), countArgs...); err != nil {
+			return mo.Err[SettingsHistoryRes](pkgs.DBError(r.log(c), err, "查询设置变更历史失败"))
+		}
+		if total == 0 {
+			return mo.Ok(SettingsHistoryRes{List: []SettingsHistoryItem{}, Total: 0})
+		}
+
+		// 查询列表
+		listQuery := `SELECT id, created_at, key, old_value, new_value, changed_by FROM ` + r.tables.SettingsHistory +
+			whereCondition + ` ORDER BY seq DESC LIMIT :limit OFFSET :offset`
+		entities, err := pkgs.NamedQueryAll[SettingsHistoryEntity](ctx, r.db, listQuery, params)
+		if err != nil {
+			return mo.Err[SettingsHistoryRes](pkgs.DBError(r.log(c), err, "查询设置变更历史失败"))
+		}
+		list := make([]SettingsHistoryItem, 0, len(entities))
+		for i := range entities {
+			list = append(list, SettingsHistoryItem{
+				ID:        entities[i].ID,
+				Key:       entities[i].Key,
+				OldValue:  entities[i].OldValue,
+				NewValue:  entities[i].NewValue,
+				ChangedBy: entities[i].ChangedBy,
+				CreatedAt: pkgs.FormatTime(c, entities[i].CreatedAt),
+			})
+		}
+		return mo.Ok(SettingsHistoryRes{List: list, Total: total})
+	}
+}
+
+// Offboard 发起离职交接：校验用户与交接人后写入交接记录并提交异步任务，返回交接记录ID与任务ID
+func (r *Repository) Offboard(c *gin.Context) func(*OffboardReq) mo.Result[OffboardRes] {
+	return func(req *OffboardReq) mo.Result[OffboardRes] {
+		ctx := c.Request.Context()
+		requester := pkgs.CurrentUserID(c)
+		if requester == "" {
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusUnauthorized, "未授权"))
+		}
+
+		// 离职用户必须存在且未停用
+		var disabled bool
+		err := r.conn(c).GetContext(ctx, &disabled, `SELECT disabled_at IS NOT NULL FROM `+r.tables.User+` WHERE id = $1`, req.UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
+			}
+			r.log(c).Error("查询用户失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+		if disabled {
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusConflict, "用户已停用"))
+		}
+
+		// 交接人必须存在且未停用
+		var successorActive bool
+		query := `SELECT EXISTS (SELECT 1 FROM ` + r.tables.User + ` WHERE id = $1 AND disabled_at IS NULL)`
+		if err := r.conn(c).GetContext(ctx, &successorActive, query, req.SuccessorID); err != nil {
+			r.log(c).Error("查询交接人失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+		if !successorActive {
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusBadRequest, "交接人不存在或已停用"))
+		}
+
+		// 同一用户同时只能有一个未完成的交接（任务失败或已取消的交接可以重新发起）
+		var running bool
+		query = `SELECT EXISTS (
+			SELECT 1 FROM ` + r.tables.Offboarding + ` o LEFT JOIN ` + r.tables.AsyncJob + ` j ON j.id = o.job_id
+			WHERE o.user_id = $1 AND o.finished_at IS NULL AND COALESCE(j.status, '') NOT IN ('` + pkgs.JobStatusFailed + `', '` + pkgs.JobStatusCanceled + `'))`
+		if err := r.conn(c).GetContext(ctx, &running, query, req.UserID); err != nil {
+			r.log(c).Error("查询离职交接失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+		if running {
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusConflict, "该用户已有进行中的离职交接"))
+		}
+
+		// 写入交接记录与任务，任务ID回写到交接记录，写入失败时回滚交接记录
+		entity := &OffboardingEntity{UserID: req.UserID, SuccessorID: req.SuccessorID, RequestedBy: requester, Reason: req.Reason}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+		defer tx.Rollback()
+
+		columns, values := r.ids.Insert("user_id", "successor_id", "requested_by", "reason")
+		insert, args, err := tx.BindNamed(`INSERT INTO `+r.tables.Offboarding+` (`+columns+`) VALUES (`+values+`) RETURNING id`, entity)
+		if err == nil {
+			err = tx.GetContext(ctx, &entity.ID, insert, args...)
+		}
+		if err != nil {
+			r.log(c).Error("写入离职交接失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+		jobID, err := r.jobs.Enqueue(c, JobTypeOffboarding, offboardingPayload{OffboardingID: entity.ID})
+		if err != nil {
+			r.log(c).Error("提交离职交接任务失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE `+r.tables.Offboarding+` SET job_id = $1 WHERE id = $2`, jobID, entity.ID); err != nil {
+			r.log(c).Error("写入离职交接任务ID失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交事务失败", zap.Error(err))
+			return mo.Err[OffboardRes](pkgs.NewApiError(http.StatusInternalServerError, "发起离职交接失败"))
+		}
+
+		return mo.Ok(OffboardRes{ID: entity.ID, JobID: jobID})
+	}
+}
+
+// GetOffboarding 查询离职交接的执行状态与报告
+func (r *Repository) GetOffboarding(c *gin.Context) func(*GetOffboardingReq) mo.Result[GetOffboardingRes] {
+	return func(req *GetOffboardingReq) mo.Result[GetOffboardingRes] {
+		var entity OffboardingEntity
+		query := `SELECT o.id, o.created_at, o.updated_at, o.user_id, o.successor_id, o.requested_by, o.reason, o.job_id, o.report, o.finished_at,
+				j.status AS job_status, j.last_error
+			FROM ` + r.tables.Offboarding + ` o LEFT JOIN ` + r.tables.AsyncJob + ` j ON j.id = o.job_id
+			WHERE o.id = $1`
+		if err := r.conn(c).GetContext(c.Request.Context(), &entity, query, req.ID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[GetOffboardingRes](pkgs.NewApiError(http.StatusNotFound, "离职交接记录不存在"))
+			}
+			return mo.Err[GetOffboardingRes](pkgs.DBError(r.log(c), err, "查询离职交接失败"))
+		}
+
+		res := GetOffboardingRes{
+			ID:          entity.ID,
+			UserID:      entity.UserID,
+			SuccessorID: entity.SuccessorID,
+			RequestedBy: entity.RequestedBy,
+			Reason:      entity.Reason,
+			Status:      pkgs.JobStatusPending,
+			Error:       entity.LastError,
+			Report:      entity.Report,
+			CreatedAt:   pkgs.FormatTime(c, entity.CreatedAt),
+		}
+		if entity.JobID != nil {
+			res.JobID = *entity.JobID
+		}
+		if entity.JobStatus != nil {
+			res.Status = *entity.JobStatus
+		}
+		if entity.FinishedAt != nil {
+			finishedAt := pkgs.FormatTime(c, *entity.FinishedAt)
+			res.FinishedAt = &finishedAt
+		}
+		return mo.Ok(res)
+	}
+}
+
+// runOffboarding 执行离职交接任务：在一个事务内撤销全部角色、删除登录设备（使绑定设备的刷新令牌失效）、
+// 将拥有的模板转交给交接人、停用账号并写入报告；账号停用后不能登录或刷新令牌，已签发的访问令牌随角色撤销失去全部接口权限。
+// 已完成的交接再次执行时直接返回，任务重试不会重复执行。
+func (r *Repository) runOffboarding(ctx context.Context, db *sqlx.DB, job *pkgs.Job, logger *zap.Logger) error {
+	var payload offboardingPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	var entity OffboardingEntity
+	query := `SELECT id, user_id, successor_id, finished_at FROM ` + r.tables.Offboarding + ` WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &entity, query, payload.OffboardingID); err != nil {
+		return fmt.Errorf("查询离职交接记录失败: %w", err)
+	}
+	if entity.FinishedAt != nil {
+		logger.Info("离职交接已完成，跳过", zap.String("offboarding_id", entity.ID))
+		return nil
+	}
+
+	report := OffboardingReport{RevokedRoles: []RevokedRole{}}
+
+	// 撤销全部角色
+	query = `DELETE FROM ` + r.tables.UserRole + ` ur USING ` + r.tables.Role + ` ro
+		WHERE ur.role_id = ro.id AND ur.user_id = $1 RETURNING ro.id, ro.name`
+	if err := tx.SelectContext(ctx, &report.RevokedRoles, query, entity.UserID); err != nil {
+		return fmt.Errorf("撤销角色失败: %w", err)
+	}
+
+	// 删除登录设备，绑定设备的刷新令牌随之失效
+	res, err := tx.ExecContext(ctx, `DELETE FROM `+r.tables.UserDevice+` WHERE user_id = $1`, entity.UserID)
+	if err != nil {
+		return fmt.Errorf("删除登录设备失败: %w", err)
+	}
+	if report.TerminatedSessions, err = res.RowsAffected(); err != nil {
+		return fmt.Errorf("获取影响行数失败: %w", err)
+	}
+
+	// 模板模块关闭时没有模板表
+	if r.config.Modules.Template.Enabled {
+		res, err := tx.ExecContext(ctx, `UPDATE `+r.tables.Template+` SET owner_id = $1 WHERE owner_id = $2`, entity.SuccessorID, entity.UserID)
+		if err != nil {
+			return fmt.Errorf("转交模板失败: %w", err)
+		}
+		if report.ReassignedTemplates, err = res.RowsAffected(); err != nil {
+			return fmt.Errorf("获取影响行数失败: %w", err)
+		}
+	}
+
+	// 停用账号
+	query = `UPDATE ` + r.tables.User + ` SET disabled_at = COALESCE(disabled_at, CURRENT_TIMESTAMP) WHERE id = $1 RETURNING disabled_at`
+	if err := tx.GetContext(ctx, &report.DisabledAt, query, entity.UserID); err != nil {
+		return fmt.Errorf("停用账号失败: %w", err)
+	}
+
+	query = `UPDATE ` + r.tables.Offboarding + ` SET report = $1, finished_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, report, entity.ID); err != nil {
+		return fmt.Errorf("写入离职交接报告失败: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	// 角色已撤销，使该租户的权限缓存失效
+	r.cache.InvalidateTenant(ctx, job.Tenant)
+	logger.Info("离职交接完成",
+		zap.String("offboarding_id", entity.ID),
+		zap.Int("revoked_roles", len(report.RevokedRoles)),
+		zap.Int64("terminated_sessions", report.TerminatedSessions),
+		zap.Int64("reassigned_templates", report.ReassignedTemplates))
+	return nil
+}
+
+// snapshotTable 快照包含的数据表，按外键依赖顺序排列：恢复时按该顺序写入，按逆序删除
+type snapshotTable struct {
+	name string
+	// 所属的可关闭模块，模块关闭时没有该表
+	module string
+	// 主键列，恢复时按主键新增或覆盖
+	keys []string
+	// 不导出的列（凭据），恢复时新增的行该列为空，覆盖的行保留原值
+	exclude []string
+	order   string
+	table   func(*pkgs.TableNames) string
+}
+
+// 不包含的表：api_key、iacc_user_device（凭据与会话）、async_job、iacc_role_change、iacc_offboarding、template_tombstone、retention_policy、audit_log（运行数据）、settings、settings_history（部署级设置）
+var snapshotTables = []snapshotTable{
+	{name: "iacc_attribute", keys: []string{"id"}, order: "entity, key", table: func(t *pkgs.TableNames) string { return t.Attribute }},
+	{name: "iacc_permission", keys: []string{"id"}, order: "created_at, id", table: func(t *pkgs.TableNames) string { return t.Permission }},
+	{name: "iacc_role", keys: []string{"id"}, order: "created_at, id", table: func(t *pkgs.TableNames) string { return t.Role }},
+	{name: "iacc_role_permission", keys: []string{"role_id", "permission_id"}, order: "role_id, permission_id", table: func(t *pkgs.TableNames) string { return t.RolePermission }},
+	{name: "iacc_role_permission_namespace", keys: []string{"role_id", "namespace"}, order: "role_id, namespace", table: func(t *pkgs.TableNames) string { return t.RolePermissionNamespace }},
+	{name: "iacc_user", keys: []string{"id"}, exclude: []string{"password"}, order: "created_at, id", table: func(t *pkgs.TableNames) string { return t.User }},
+	{name: "iacc_user_role", keys: []string{"user_id", "role_id"}, order: "user_id, role_id", table: func(t *pkgs.TableNames) string { return t.UserRole }},
+	{name: "iacc_blueprint", keys: []string{"id"}, order: "created_at, id", table: func(t *pkgs.TableNames) string { return t.Blueprint }},
+	{name: "iacc_client", keys: []string{"id"}, order: "created_at, id", table: func(t *pkgs.TableNames) string { return t.Client }},
+	{name: "saved_view", keys: []string{"id"}, order: "created_at, id", table: func(t *pkgs.TableNames) string { return t.SavedView }},
+	{name: "watch", keys: []string{"id"}, order: "created_at, id", table: func(t *pkgs.TableNames) string { return t.Watch }},
+	{name: "template", module: pkgs.ModuleTemplate, keys: []string{"id"}, order: "created_at, id", table: func(t *pkgs.TableNames) string { return t.Template }},
+	{name: "template_usage", module: pkgs.ModuleTemplate, keys: []string{"template_id"}, order: "template_id", table: func(t *pkgs.TableNames) string { return t.TemplateUsage }},
+}
+
+func snapshotTableByName(name string) (snapshotTable, bool) {
+	for _, spec := range snapshotTables {
+		if spec.name == name {
+			return spec, true
+		}
+	}
+	return snapshotTable{}, false
+}
+
+// snapshotEnabled 表所属模块是否开启
+func (r *Repository) snapshotEnabled(spec snapshotTable) bool {
+	return spec.module == "" || !slices.Contains(r.config.Modules.Disabled(), spec.module)
+}
+
+// snapshotColumns 返回表中可以写入的列（排除 seq 等自增列与生成列）以及不导出的列
+func (r *Repository) snapshotColumns(ctx context.Context, q sqlx.QueryerContext, spec snapshotTable) (writable, skipped []string, err error) {
+	var columns []struct {
+		Name     string `db:"attname"`
+		Writable bool   `db:"writable"`
+	}
+	query := `SELECT attname, attidentity = '' AND attgenerated = '' AS writable
+		FROM pg_attribute WHERE attrelid = $1::regclass AND attnum > 0 AND NOT attisdropped ORDER BY attnum`
+	if err := sqlx.SelectContext(ctx, q, &columns, query, spec.table(r.tables)); err != nil {
+		return nil, nil, err
+	}
+	for _, column := range columns {
+		if column.Writable && !slices.Contains(spec.exclude, column.Name) {
+			writable = append(writable, column.Name)
+		} else {
+			skipped = append(skipped, column.Name)
+		}
+	}
+	return writable, skipped, nil
+}
+
+// ExportSnapshot 导出环境快照，在可重复读的只读事务中读取，各表数据一致
+func (r *Repository) ExportSnapshot(c *gin.Context) func() mo.Result[Snapshot] {
+	return func() mo.Result[Snapshot] {
+		ctx := c.Request.Context()
+		tx, err := r.conn(c).BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[Snapshot](pkgs.NewApiError(http.StatusInternalServerError, "导出快照失败"))
+		}
+		defer tx.Rollback()
+
+		snapshot := Snapshot{
+			Format:         SnapshotFormat,
+			Version:        SnapshotVersion,
+			KeyFingerprint: pkgs.FieldKeyFingerprint(),
+			Tables:         []SnapshotTable{},
+		}
+		var createdAt time.Time
+		if err := tx.GetContext(ctx, &createdAt, `SELECT CURRENT_TIMESTAMP`); err != nil {
+			r.log(c).Error("查询数据库时间失败", zap.Error(err))
+			return mo.Err[Snapshot](pkgs.NewApiError(http.StatusInternalServerError, "导出快照失败"))
+		}
+		snapshot.CreatedAt = createdAt.UTC().Format(time.RFC3339Nano)
+
+		for _, spec := range snapshotTables {
+			if !r.snapshotEnabled(spec) {
+				continue
+			}
+			_, skipped, err := r.snapshotColumns(ctx, tx, spec)
+			if err != nil {
+				r.log(c).Error("查询表结构失败", zap.String("table", spec.name), zap.Error(err))
+				return mo.Err[Snapshot](pkgs.NewApiError(http.StatusInternalServerError, "导出快照失败"))
+			}
+			var rows []byte
+			query := `SELECT COALESCE(jsonb_agg(to_jsonb(t) - $1::text[] ORDER BY ` + spec.order + `), '[]'::jsonb) FROM ` + spec.table(r.tables) + ` t`
+			if err := tx.GetContext(ctx, &rows, query, pkgs.PGArray(skipped)); err != nil {
+				r.log(c).Error("导出数据表失败", zap.String("table", spec.name), zap.Error(err))
+				return mo.Err[Snapshot](pkgs.NewApiError(http.StatusInternalServerError, "导出快照失败"))
+			}
+			snapshot.Tables = append(snapshot.Tables, SnapshotTable{Name: spec.name, Rows: rows})
+		}
+		return mo.Ok(snapshot)
+	}
+}
+
+// RestoreSnapshot 在一个事务内恢复环境快照，快照中没有的表不做改动
+// merge 按主键新增或覆盖；replace 先删除快照中不存在的行（逆序），再新增或覆盖。任一表失败时全部回滚。
+func (r *Repository) RestoreSnapshot(c *gin.Context) func(*RestoreSnapshotReq) mo.Result[RestoreSnapshotRes] {
+	return func(req *RestoreSnapshotReq) mo.Result[RestoreSnapshotRes] {
+		mode := req.Mode
+		if mode == "" {
+			mode = SnapshotModeMerge
+		}
+		// 密文与影子列只能在密钥相同的环境之间恢复
+		if req.Snapshot.KeyFingerprint != "" && req.Snapshot.KeyFingerprint != pkgs.FieldKeyFingerprint() {
+			return mo.Err[RestoreSnapshotRes](pkgs.NewApiError(http.StatusConflict, "快照的字段加密密钥与当前环境不一致"))
+		}
+
+		rows := map[string][]map[string]json.RawMessage{}
+		for _, table := range req.Snapshot.Tables {
+			spec, _ := snapshotTableByName(table.Name)
+			if !r.snapshotEnabled(spec) {
+				return mo.Err[RestoreSnapshotRes](pkgs.NewApiError(http.StatusBadRequest, "数据表所属模块已关闭："+table.Name))
+			}
+			var list []map[string]json.RawMessage
+			if err := json.Unmarshal(table.Rows, &list); err != nil {
+				return mo.Err[RestoreSnapshotRes](pkgs.NewApiError(http.StatusBadRequest, "数据表 "+table.Name+" 的数据格式无效"))
+			}
+			for _, row := range list {
+				for _, key := range spec.keys {
+					if value, ok := row[key]; !ok || string(value) == "null" {
+						return mo.Err[RestoreSnapshotRes](pkgs.NewApiError(http.StatusBadRequest, "数据表 "+table.Name+" 的数据缺少主键 "+key))
+					}
+				}
+			}
+			rows[table.Name] = list
+		}
+
+		ctx := c.Request.Context()
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[RestoreSnapshotRes](pkgs.NewApiError(http.StatusInternalServerError, "恢复快照失败"))
+		}
+		defer tx.Rollback()
+
+		results := map[string]*RestoreSnapshotTable{}
+		for _, spec := range snapshotTables {
+			if _, ok := rows[spec.name]; ok {
+				results[spec.name] = &RestoreSnapshotTable{Name: spec.name}
+			}
+		}
+
+		if mode == SnapshotModeReplace {
+			for i := len(snapshotTables) - 1; i >= 0; i-- {
+				spec := snapshotTables[i]
+				result, ok := results[spec.name]
+				if !ok {
+					continue
+				}
+				if result.Deleted, err = r.pruneSnapshotTable(ctx, tx, spec, rows[spec.name]); err != nil {
+					return mo.Err[RestoreSnapshotRes](r.restoreError(spec, err))
+				}
+			}
+		}
+		for _, spec := range snapshotTables {
+			result, ok := results[spec.name]
+			if !ok {
+				continue
+			}
+			if result.Upserted, err = r.upsertSnapshotTable(ctx, tx, spec, rows[spec.name]); err != nil {
+				return mo.Err[RestoreSnapshotRes](r.restoreError(spec, err))
+			}
+		}
+		// 恢复的模板不再是已删除状态，清除其墓碑（见模板同步接口）
+		if _, ok := results["template"]; ok {
+			query := `DELETE FROM ` + r.tables.TemplateTombstone + ` t USING ` + r.tables.Template + ` x WHERE t.id = x.id`
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				r.log(c).Error("清除模板墓碑失败", zap.Error(err))
+				return mo.Err[RestoreSnapshotRes](pkgs.NewApiError(http.StatusInternalServerError, "恢复快照失败"))
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交事务失败", zap.Error(err))
+			return mo.Err[RestoreSnapshotRes](pkgs.NewApiError(http.StatusInternalServerError, "恢复快照失败"))
+		}
+
+		res := RestoreSnapshotRes{Mode: mode, Tables: []RestoreSnapshotTable{}}
+		for _, spec := range snapshotTables {
+			if result, ok := results[spec.name]; ok {
+				res.Tables = append(res.Tables, *result)
+			}
+		}
+		return mo.Ok(res)
+	}
+}
+
+// pruneSnapshotTable 删除快照中不存在的行
+func (r *Repository) pruneSnapshotTable(ctx context.Context, tx *sqlx.Tx, spec snapshotTable, rows []map[string]json.RawMessage) (int64, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return 0, err
+	}
+	table := spec.table(r.tables)
+	match := make([]string, len(spec.keys))
+	for i, key := range spec.keys {
+		match[i] = "s." + key + " = x." + key
+	}
+	query := `DELETE FROM ` + table + ` x WHERE NOT EXISTS (
+		SELECT 1 FROM jsonb_populate_recordset(NULL::` + table + `, $1::jsonb) s WHERE ` + strings.Join(match, " AND ") + `)`
+	res, err := tx.ExecContext(ctx, query, data)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// upsertSnapshotTable 按主键新增或覆盖快照中的行，只写入快照中出现且当前表中存在的列
+func (r *Repository) upsertSnapshotTable(ctx context.Context, tx *sqlx.Tx, spec snapshotTable, rows []map[string]json.RawMessage) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	writable, _, err := r.snapshotColumns(ctx, tx, spec)
+	if err != nil {
+		return 0, err
+	}
+	var columns, updates []string
+	for _, column := range writable {
+		present := false
+		for _, row := range rows {
+			if _, ok := row[column]; ok {
+				present = true
+				break
+			}
+		}
+		if !present {
+			continue
+		}
+		columns = append(columns, column)
+		if !slices.Contains(spec.keys, column) {
+			updates = append(updates, column+" = EXCLUDED."+column)
+		}
+	}
+	conflict := `DO NOTHING`
+	if len(updates) > 0 {
+		conflict = `DO UPDATE SET ` + strings.Join(updates, ", ")
+	}
+
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return 0, err
+	}
+	table := spec.table(r.tables)
+	list := strings.Join(columns, ", ")
+	query := `INSERT INTO ` + table + ` (` + list + `)
+		SELECT ` + list + ` FROM jsonb_populate_recordset(NULL::` + table + `, $1::jsonb)
+		ON CONFLICT (` + strings.Join(spec.keys, ", ") + `) ` + conflict
+	res, err := tx.ExecContext(ctx, query, data)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// restoreError 违反约束（如用户名与现有数据重复、引用的角色不存在）时返回具体原因，其余返回 500
+func (r *Repository) restoreError(spec snapshotTable, err error) *pkgs.ApiError {
+	return pkgs.DBError(r.logger.With(zap.String("table", spec.name)), err, "恢复数据表 "+spec.name+" 失败")
+}
+
+// QueryJobs 按任务类型统计当前租户的异步任务队列，并分页返回任务
+func (r *Repository) QueryJobs(c *gin.Context) func(*QueryJobsReq) mo.Result[QueryJobsRes] {
+	return func(req *QueryJobsReq) mo.Result[QueryJobsRes] {
+		ctx := c.Request.Context()
+		db := r.conn(c)
+
+		stats, err := r.jobs.Stats(ctx, db, time.Now().Add(-time.Duration(req.WindowHours)*time.Hour))
+		if err != nil {
+			return mo.Err[QueryJobsRes](pkgs.DBError(r.log(c), err, "查询异步任务失败"))
+		}
+		if stats == nil {
+			stats = []pkgs.JobTypeStats{}
+		}
+
+		// 构建查询
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
+		}
+		var whereClauses []string
+		if req.Status != "" {
+			whereClauses = append(whereClauses, "status = :status")
+			params["status"] = req.Status
+		}
+		if req.Type != "" {
+			whereClauses = append(whereClauses, "type = :type")
+			params["type"] = req.Type
+		}
+		whereCondition := ""
+		if len(whereClauses) > 0 {
+			whereCondition = " WHERE " + strings.Join(whereClauses, " AND ")
+		}
+
+		// 查询总数
+		var total int64
+		countQuery, countArgs, err := sqlx.Named("SELECT count(*) FROM "+r.tables.AsyncJob+whereCondition, params)
+		if err != nil {
+			r.log(c).Error("构建计数查询失败", zap.Error(err))
+			return mo.Err[QueryJobsRes](pkgs.NewApiError(http.StatusInternalServerError, "查询异步任务失败"))
+		}
+		if err := db.GetContext(ctx, &total, db.Rebind(countQuery), countArgs...); err != nil {
+			return mo.Err[QueryJobsRes](pkgs.DBError(r.log(c), err, "查询异步任务失败"))
+		}
+		if total == 0 {
+			return mo.Ok(QueryJobsRes{Stats: stats, List: []JobItem{}, Total: 0})
+		}
+
+		// 查询列表
+		listQuery := `SELECT ` + pkgs.JobColumns + ` FROM ` + r.tables.AsyncJob + whereCondition + ` ORDER BY created_at DESC, seq DESC LIMIT :limit OFFSET :offset`
+		jobs, err := pkgs.NamedQueryAll[pkgs.Job](ctx, db, listQuery, params)
+		if err != nil {
+			return mo.Err[QueryJobsRes](pkgs.DBError(r.log(c), err, "查询异步任务失败"))
+		}
+		list := make([]JobItem, 0, len(jobs))
+		for i := range jobs {
+			list = append(list, newJobItem(c, &jobs[i]))
+		}
+		return mo.Ok(QueryJobsRes{Stats: stats, List: list, Total: total})
+	}
+}
+
+// RetryJob 将已失败或已取消的任务重新排队
+func (r *Repository) RetryJob(c *gin.Context) func(*JobIDReq) mo.Result[JobActionRes] {
+	return func(req *JobIDReq) mo.Result[JobActionRes] {
+		job, err := r.jobs.Retry(c.Request.Context(), r.conn(c), req.ID)
+		return r.jobActionResult(c, job, err, "重试任务失败")
+	}
+}
+
+// CancelJob 取消等待执行的任务
+func (r *Repository) CancelJob(c *gin.Context) func(*JobIDReq) mo.Result[JobActionRes] {
+	return func(req *JobIDReq) mo.Result[JobActionRes] {
+		job, err := r.jobs.Cancel(c.Request.Context(), r.conn(c), req.ID)
+		return r.jobActionResult(c, job, err, "取消任务失败")
+	}
+}
+
+// jobActionResult 任务不存在返回 404，状态不允许返回 409
+func (r *Repository) jobActionResult(c *gin.Context, job *pkgs.Job, err error, message string) mo.Result[JobActionRes] {
+	switch {
+	case errors.Is(err, pkgs.ErrJobNotFound):
+		return mo.Err[JobActionRes](pkgs.NewApiError(http.StatusNotFound, err.Error()))
+	case errors.Is(err, pkgs.ErrJobNotRetryable), errors.Is(err, pkgs.ErrJobNotCancelable):
+		return mo.Err[JobActionRes](pkgs.NewApiError(http.StatusConflict, err.Error()))
+	case err != nil:
+		return mo.Err[JobActionRes](pkgs.DBError(r.log(c), err, message))
+	}
+	return mo.Ok(newJobItem(c, job))
+}
+
+// newJobItem 将任务转换为响应，时间按本次请求的时区格式化
+func newJobItem(c *gin.Context, job *pkgs.Job) JobItem {
+	item := JobItem{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      job.Status,
+		Payload:     job.Payload,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		TraceID:     job.TraceID,
+		RunAt:       pkgs.FormatTime(c, job.RunAt),
+		CreatedAt:   pkgs.FormatTime(c, job.CreatedAt),
+		UpdatedAt:   pkgs.FormatTime(c, job.UpdatedAt),
+	}
+	if job.StartedAt != nil {
+		startedAt := pkgs.FormatTime(c, *job.StartedAt)
+		item.StartedAt = &startedAt
+	}
+	if job.FinishedAt != nil {
+		finishedAt := pkgs.FormatTime(c, *job.FinishedAt)
+		item.FinishedAt = &finishedAt
+	}
+	return item
+}
+
+// PermissionCacheStatus 返回权限缓存的状态与本实例编译的接口权限记录
+func (r *Repository) PermissionCacheStatus(c *gin.Context) func() mo.Result[PermissionCacheStatusRes] {
+	return func() mo.Result[PermissionCacheStatusRes] {
+		tenant := pkgs.TenantFromContext(c)
+		status, err := r.cache.Status(c.Request.Context(), tenant)
+		if err != nil {
+			r.log(c).Error("查询权限缓存状态失败", zap.Error(err))
+			return mo.Err[PermissionCacheStatusRes](pkgs.NewApiError(http.StatusInternalServerError, "查询权限缓存状态失败"))
+		}
+		var invalidatedAt *string
+		if !status.InvalidatedAt.IsZero() {
+			invalidatedAt = pkgs.FormatTimePtr(c, &status.InvalidatedAt)
+		}
+		return mo.Ok(PermissionCacheStatusRes{
+			Enabled:       status.Enabled,
+			Circuit:       status.Circuit,
+			TTLSeconds:    int64(status.TTL / time.Second),
+			Generation:    status.Generation,
+			InvalidatedAt: invalidatedAt,
+			Entries:       status.Entries,
+			StaleEntries:  status.StaleEntries,
+			Hits:          status.Hits,
+			Misses:        status.Misses,
+			Errors:        status.Errors,
+			HitRate:       status.HitRate(),
+			Routes:        permissionRoutesItem(c, r.matcher.Status(tenant)),
+		})
+	}
+}
+
+// RebuildPermissionCache 使当前租户的权限缓存失效并立即重新编译本实例的接口权限记录
+// 其他实例在下一次读取缓存时发现版本号变化，同样使本地的编译结果过期
+func (r *Repository) RebuildPermissionCache(c *gin.Context) func() mo.Result[RebuildPermissionCacheRes] {
+	return func() mo.Result[RebuildPermissionCacheRes] {
+		tenant := pkgs.TenantFromContext(c)
+		generation, err := r.cache.Rebuild(c.Request.Context(), tenant)
+		if err != nil {
+			r.log(c).Error("重建权限缓存失败", zap.Error(err))
+			return mo.Err[RebuildPermissionCacheRes](pkgs.NewApiError(http.StatusInternalServerError, "重建权限缓存失败"))
+		}
+		routes, err := r.matcher.Rebuild(c)
+		if err != nil {
+			return mo.Err[RebuildPermissionCacheRes](pkgs.DBError(r.log(c), err, "重建权限缓存失败"))
+		}
+		r.log(c).Info("已重建权限缓存", zap.String("tenant", tenant), zap.Int64("generation", generation), zap.Int("routes", routes.Count))
+		return mo.Ok(RebuildPermissionCacheRes{Generation: generation, Routes: permissionRoutesItem(c, routes)})
+	}
+}
+
+func permissionRoutesItem(c *gin.Context, status pkgs.PermissionRoutesStatus) PermissionRoutesItem {
+	if !status.Compiled {
+		return PermissionRoutesItem{}
+	}
+	return PermissionRoutesItem{
+		Compiled:   true,
+		CompiledAt: pkgs.FormatTimePtr(c, &status.CompiledAt),
+		AgeSeconds: int64(time.Since(status.CompiledAt) / time.Second),
+		Count:      status.Count,
+		Stale:      status.Stale,
+	}
+}
+
+// RateLimitShadow 返回当前租户在影子模式下超出限制的 API 密钥，统计保存在进程内存中，重启后清空
+func (r *Repository) RateLimitShadow(c *gin.Context) func() mo.Result[RateLimitShadowRes] {
+	return func() mo.Result[RateLimitShadowRes] {
+		clients := r.shadow.Clients(c.GetString(pkgs.TenantContextKey))
+		if len(clients) == 0 {
+			return mo.Ok(RateLimitShadowRes{List: []RateLimitShadowItem{}})
+		}
+
+		// 补充 API 密钥的名称与前缀
+		ids := make([]string, len(clients))
+		for i, client := range clients {
+			ids[i] = client.APIKeyID
+		}
+		var keys []struct {
+			ID        string `db:"id"`
+			Name      string `db:"name"`
+			KeyPrefix string `db:"key_prefix"`
+		}
+		query := `SELECT id, name, key_prefix FROM ` + r.tables.APIKey + ` WHERE id = ANY($1)`
+		if err := r.conn(c).SelectContext(c.Request.Context(), &keys, query, pkgs.PGArray(ids)); err != nil {
+			return mo.Err[RateLimitShadowRes](pkgs.DBError(r.log(c), err, "查询 API 密钥失败"))
+		}
+
+		list := make([]RateLimitShadowItem, len(clients))
+		for i, client := range clients {
+			list[i] = RateLimitShadowItem{
+				APIKeyID:        client.APIKeyID,
+				Tier:            client.Tier,
+				Limit:           client.Limit,
+				WindowSeconds:   int64(client.Window / time.Second),
+				Exceeded:        client.Exceeded,
+				Peak:            client.Peak,
+				FirstExceededAt: pkgs.FormatTime(c, client.FirstExceededAt),
+				LastExceededAt:  pkgs.FormatTime(c, client.LastExceededAt),
+			}
+			for _, key := range keys {
+				if key.ID == client.APIKeyID {
+					list[i].Name, list[i].KeyPrefix = key.Name, key.KeyPrefix
+				}
+			}
+		}
+		return mo.Ok(RateLimitShadowRes{List: list})
+	}
+}
+
+// RolePermissionMatrix 查询角色权限矩阵的一页
+// 当前页的权限与其在各角色上的授予情况由一条聚合查询得到（按权限分组，jsonb_object_agg 将角色转为列），
+// 不需要逐个角色、逐个权限查询；角色列表与权限总数各一条查询。
+// 授予情况包含按命名空间授予展开的权限，同一角色既授予又拒绝时为拒绝；不含继承自父角色的权限。
+// 矩阵按全部权限聚合，在报表连接上查询（配置了分析库时不占用主库）。
+func (r *Repository) RolePermissionMatrix(c *gin.Context) func(*RolePermissionMatrixReq) mo.Result[RolePermissionMatrixRes] {
+	return func(req *RolePermissionMatrixReq) mo.Result[RolePermissionMatrixRes] {
+		ctx := c.Request.Context()
+		db, tables := r.reportConn(c)
+
+		roles := []MatrixRole{}
+		if err := db.SelectContext(ctx, &roles, `SELECT id, name FROM `+tables.Role+` ORDER BY name, seq`); err != nil {
+			return mo.Err[RolePermissionMatrixRes](pkgs.DBError(r.log(c), err, "查询角色权限矩阵失败"))
+		}
+
+		var total int64
+		if err := db.GetContext(ctx, &total, `SELECT count(*) FROM `+tables.Permission); err != nil {
+			return mo.Err[RolePermissionMatrixRes](pkgs.DBError(r.log(c), err, "查询角色权限矩阵失败"))
+		}
+
+		rows := []MatrixRow{}
+		query := `WITH p AS (
+				SELECT id, name, type, metadata, seq FROM ` + tables.Permission + ` ORDER BY name, seq LIMIT $1 OFFSET $2
+			)
+			SELECT p.id, p.name, p.type,
+				COALESCE(p.metadata ->> 'code', '') AS code,
+				COALESCE(p.metadata ->> 'method', '') AS method,
+				COALESCE(p.metadata ->> 'path', '') AS path,
+				COALESCE(jsonb_object_agg(rp.role_id, rp.effect) FILTER (WHERE rp.role_id IS NOT NULL), '{}') AS grants
+			FROM p LEFT JOIN LATERAL (
+				SELECT x.role_id, CASE WHEN bool_or(x.effect = '` + pkgs.RolePermissionDeny + `') THEN '` + pkgs.RolePermissionDeny + `' ELSE '` + pkgs.RolePermissionAllow + `' END AS effect
+				FROM (` + pkgs.RolePermissionsSQL(tables) + `) x
+				WHERE x.permission_id = p.id
+				GROUP BY x.role_id
+			) rp ON true
+			GROUP BY p.id, p.name, p.type, p.metadata, p.seq
+			ORDER BY p.name, p.seq`
+		if err := db.SelectContext(ctx, &rows, query, req.PageSize, req.Offset()); err != nil {
+			return mo.Err[RolePermissionMatrixRes](pkgs.DBError(r.log(c), err, "查询角色权限矩阵失败"))
+		}
+		return mo.Ok(RolePermissionMatrixRes{Roles: roles, List: rows, Total: total})
+	}
+}
+
+// writeMatrixCSV 将角色权限矩阵写为 CSV：前六列为权限信息，之后每个角色一列，单元格为 allow、deny 或空
+func writeMatrixCSV(w io.Writer, matrix RolePermissionMatrixRes) error {
+	cw := csv.NewWriter(w)
+	header := []string{"permission_id", "permission_name", "type", "code", "method", "path"}
+	for _, role := range matrix.Roles {
+		header = append(header, role.Name)
+	}
+	for i := range header {
+		header[i] = pkgs.CSVCell(header[i])
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range matrix.List {
+		record := []string{row.PermissionID, row.Name, row.Type, row.Code, row.Method, row.Path}
+		for _, role := range matrix.Roles {
+			record = append(record, row.Grants[role.ID])
+		}
+		for i := range record {
+			record[i] = pkgs.CSVCell(record[i])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// RevokeTokens 按条件批量撤销令牌
+// 在一个事务内写入撤销规则并提升持有命中令牌的用户的令牌版本：规则使命中条件的刷新令牌不能再换取新令牌，
+// 版本提升使这些用户已签发的访问令牌立即失效（包括未命中客户端条件的会话，它们需要用刷新令牌换取新的访问令牌）。
+// 命中的令牌按签发登记（iacc_refresh_token）统计，不包括已加入黑名单或已被之前的规则撤销的令牌。
+func (r *Repository) RevokeTokens(c *gin.Context) func(*RevokeTokensReq) mo.Result[RevokeTokensRes] {
+	return func(req *RevokeTokensReq) mo.Result[RevokeTokensRes] {
+		ctx := c.Request.Context()
+		issuedBefore := time.Now()
+		if req.IssuedBefore != nil {
+			issuedBefore = *req.IssuedBefore
+		}
+		// user_ids 为空表示全部用户，写入 NULL 而不是空数组
+		var userIDs any
+		if len(req.UserIDs) > 0 {
+			userIDs = pkgs.PGArray(req.UserIDs)
+		}
+		var clientType *string
+		if req.ClientType != "" {
+			clientType = &req.ClientType
+		}
+		var createdBy *string
+		if id := pkgs.CurrentUserID(c); id != "" {
+			createdBy = &id
+		}
+		var id string
+		if err := r.ids.Assign(&id); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[RevokeTokensRes](pkgs.NewApiError(http.StatusInternalServerError, "批量撤销令牌失败"))
+		}
+		rule := map[string]any{"id": id, "user_ids": userIDs, "client_type": clientType, "issued_before": issuedBefore, "created_by": createdBy}
+
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[RevokeTokensRes](pkgs.NewApiError(http.StatusInternalServerError, "批量撤销令牌失败"))
+		}
+		defer tx.Rollback()
+
+		// 先统计命中条件且仍然有效的刷新令牌，并提升持有这些令牌的用户的令牌版本，再写入规则
+		var counts struct {
+			Tokens int64 `db:"tokens"`
+			Users  int64 `db:"users"`
+		}
+		query := `WITH revoked AS (
+				SELECT t.user_id FROM ` + r.tables.RefreshToken + ` t
+				LEFT JOIN ` + r.tables.Client + ` cl ON cl.client_id = t.client_id
+				WHERE ($1::uuid[] IS NULL OR t.user_id = ANY($1::uuid[]))
+				  AND ($2::varchar IS NULL OR cl.client_type = $2)
+				  AND t.issued_at <= $3 AND t.expires_at > CURRENT_TIMESTAMP
+				  AND NOT EXISTS (SELECT 1 FROM ` + r.tables.TokenRevocation + ` b WHERE b.jti = t.jti)
+				  AND NOT EXISTS (
+					SELECT 1 FROM ` + r.tables.TokenRevocationRule + ` rr
+					WHERE (rr.user_ids IS NULL OR rr.user_ids @> ARRAY[t.user_id])
+					  AND (rr.client_type IS NULL OR rr.client_type = cl.client_type)
+					  AND rr.issued_before >= t.issued_at
+				  )
+			), bumped AS (
+				UPDATE ` + r.tables.User + ` SET token_version = token_version + 1 WHERE id IN (SELECT user_id FROM revoked) RETURNING id
+			)
+			SELECT (SELECT COUNT(*) FROM revoked) AS tokens, (SELECT COUNT(*) FROM bumped) AS users`
+		if err := tx.GetContext(ctx, &counts, query, userIDs, clientType, issuedBefore); err != nil {
+			return mo.Err[RevokeTokensRes](pkgs.DBError(r.log(c), err, "批量撤销令牌失败"))
+		}
+
+		columns, values := r.ids.Insert("user_ids", "client_type", "issued_before", "created_by")
+		insert, args, err := tx.BindNamed(`INSERT INTO `+r.tables.TokenRevocationRule+` (`+columns+`) VALUES (`+values+`)`, rule)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, insert, args...)
+		}
+		if err != nil {
+			return mo.Err[RevokeTokensRes](pkgs.DBError(r.log(c), err, "批量撤销令牌失败"))
+		}
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交事务失败", zap.Error(err))
+			return mo.Err[RevokeTokensRes](pkgs.NewApiError(http.StatusInternalServerError, "批量撤销令牌失败"))
+		}
+
+		return mo.Ok(RevokeTokensRes{UserIDs: req.UserIDs, IssuedBefore: issuedBefore, ClientType: req.ClientType, Tokens: counts.Tokens, Users: counts.Users})
+	}
+}
+
+// SupportBundle 生成支持包：脱敏的配置、本实例最近的错误日志、迁移状态、连接池统计与健康检查结果
+// 各项信息读取失败时记录在对应文件中，不影响支持包的生成。
+func (r *Repository) SupportBundle(c *gin.Context) func() mo.Result[SupportBundle] {
+	return func() mo.Result[SupportBundle] {
+		now := time.Now()
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		files := []string{SupportBundleManifestFile, SupportBundleConfigFile, SupportBundleErrorsFile, SupportBundleMigrationsFile, SupportBundlePoolsFile, SupportBundleHealthFile}
+		contents := map[string]any{
+			SupportBundleManifestFile: SupportBundleManifest{
+				GeneratedAt:    now.UTC().Format(time.RFC3339),
+				RequestID:      pkgs.TraceIDFromContext(c),
+				Tenant:         pkgs.TenantFromContext(c),
+				ServerMode:     r.config.Server.Mode,
+				GoVersion:      runtime.Version(),
+				OS:             runtime.GOOS,
+				Arch:           runtime.GOARCH,
+				NumCPU:         runtime.NumCPU(),
+				Goroutines:     runtime.NumGoroutine(),
+				HeapAllocBytes: mem.HeapAlloc,
+				SysBytes:       mem.Sys,
+				NumGC:          mem.NumGC,
+				Files:          files,
+			},
+			SupportBundleConfigFile:     pkgs.RedactSettings(pkgs.ConfigSettings()),
+			SupportBundleErrorsFile:     append([]pkgs.ErrorLogEntry{}, pkgs.RecentErrorLogs()...),
+			SupportBundleMigrationsFile: r.migrationStatus(c),
+			SupportBundlePoolsFile:      r.poolStats(),
+			SupportBundleHealthFile:     r.probes(c),
+		}
+
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for _, name := range files {
+			data, err := json.MarshalIndent(contents[name], "", "  ")
+			if err == nil {
+				var w io.Writer
+				if w, err = zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now}); err == nil {
+					_, err = w.Write(data)
+				}
+			}
+			if err != nil {
+				r.log(c).Error("生成支持包失败", zap.String("file", name), zap.Error(err))
+				return mo.Err[SupportBundle](pkgs.NewApiError(http.StatusInternalServerError, "生成支持包失败"))
+			}
+		}
+		if err := zw.Close(); err != nil {
+			r.log(c).Error("生成支持包失败", zap.Error(err))
+			return mo.Err[SupportBundle](pkgs.NewApiError(http.StatusInternalServerError, "生成支持包失败"))
+		}
+		return mo.Ok(SupportBundle{FileName: "support-bundle-" + now.UTC().Format("20060102T150405Z") + ".zip", Content: buf.Bytes()})
+	}
+}
+
+// migrationStatus 读取默认 schema 与所有租户 schema 的迁移版本
+func (r *Repository) migrationStatus(c *gin.Context) SupportBundleMigrations {
+	status := SupportBundleMigrations{AutoMigrate: r.config.Database.AutoMigrate, Schemas: []SupportBundleSchema{}}
+	status.LatestVersion, _ = migration.LatestVersion()
+	schemas := []string{r.tables.Schema}
+	if r.pool.Enabled() {
+		tenants, err := r.pool.List(c.Request.Context())
+		if err != nil {
+			status.Schemas = append(status.Schemas, SupportBundleSchema{Error: "查询租户列表失败: " + err.Error()})
+		}
+		for _, tenant := range tenants {
+			schemas = append(schemas, r.pool.SchemaName(tenant))
+		}
+	}
+	for _, schema := range schemas {
+		item := SupportBundleSchema{Schema: schema}
+		version, dirty, err := migration.SchemaVersion(r.db, r.tables, schema)
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Version, item.Dirty = version, dirty
+		}
+		status.Schemas = append(status.Schemas, item)
+	}
+	return status
+}
+
+// poolStats 返回本实例各连接池的统计，配置了分析库时包含分析库连接池
+func (r *Repository) poolStats() []SupportBundlePool {
+	stats := r.pool.Stats()
+	if s, ok := r.reporting.Stats(); ok {
+		stats = append(stats, s)
+	}
+	pools := make([]SupportBundlePool, len(stats))
+	for i, s := range stats {
+		pools[i] = SupportBundlePool{
+			Name:               s.Name,
+			MaxOpenConnections: s.MaxOpenConnections,
+			OpenConnections:    s.OpenConnections,
+			InUse:              s.InUse,
+			Idle:               s.Idle,
+			WaitCount:          s.WaitCount,
+			WaitDurationMs:     s.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      s.MaxIdleClosed,
+			MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  s.MaxLifetimeClosed,
+		}
+	}
+	return pools
+}
+
+// probes 检查当前租户的数据库、批处理连接池、分析库、Redis 与权限缓存熔断器
+func (r *Repository) probes(c *gin.Context) []SupportBundleProbe {
+	return []SupportBundleProbe{
+		r.probe(c, "database", func(ctx context.Context) (string, error) {
+			return "", r.conn(c).PingContext(ctx)
+		}),
+		r.probe(c, "batch_database", func(ctx context.Context) (string, error) {
+			return "", r.pool.BatchDB(c).PingContext(ctx)
+		}),
+		r.probe(c, "reporting_database", r.reportingCheck),
+		r.probe(c, "redis", func(ctx context.Context) (string, error) {
+			if r.redis == nil {
+				return "未配置", nil
+			}
+			return "", r.redis.Ping(ctx).Err()
+		}),
+		r.probe(c, "permission_cache", func(context.Context) (string, error) {
+			if state := r.cache.BreakerState(); state != pkgs.CircuitClosed {
+				return state, errors.New("熔断中，权限校验降级为查询数据库")
+			}
+			return pkgs.CircuitClosed, nil
+		}),
+	}
+}
+
+// probe 在 probeTimeout 内执行一项健康检查并记录耗时
+func (r *Repository) probe(c *gin.Context, name string, check func(ctx context.Context) (string, error)) SupportBundleProbe {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), probeTimeout)
+	defer cancel()
+	started := time.Now()
+	detail, err := check(ctx)
+	item := SupportBundleProbe{Name: name, OK: err == nil, LatencyMs: float64(time.Since(started).Microseconds()) / 1000, Detail: detail}
+	if err != nil {
+		item.Error = err.Error()
+	}
+	return item
+}
+
+// reportingCheck 检查分析库连接，未配置分析库时报表查询使用主库，视为正常
+func (r *Repository) reportingCheck(ctx context.Context) (string, error) {
+	if !r.reporting.Enabled() {
+		return "未配置，报表查询使用主库", nil
+	}
+	lag, err := r.reporting.Ping(ctx)
+	if err != nil || lag == nil {
+		return "", err
+	}
+	return fmt.Sprintf("复制延迟 %.1fs", *lag), nil
+}
+
+// Readiness 分别检查主库与分析库连接
+// 只有主库决定是否就绪：分析库不可用时只有报表查询（统计、导出等）失败，其它接口不受影响，在 reporting_database 一项中单独报告
+func (r *Repository) Readiness(c *gin.Context) func() mo.Result[ReadinessRes] {
+	return func() mo.Result[ReadinessRes] {
+		res := ReadinessRes{Checks: []SupportBundleProbe{
+			r.probe(c, "database", func(ctx context.Context) (string, error) {
+				return "", r.conn(c).PingContext(ctx)
+			}),
+			r.probe(c, "reporting_database", r.reportingCheck),
+		}}
+		res.Ready = res.Checks[0].OK
+		if !res.Ready {
+			return mo.Err[ReadinessRes](&pkgs.ApiError{Code: http.StatusServiceUnavailable, Message: "主库不可用", Data: res})
+		}
+		return mo.Ok(res)
+	}
+}
@@ -0,0 +1,505 @@
+package admin
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"go-pg-demo/pkgs"
+	"time"
+)
+
+// 查询慢查询的请求参数
+type SlowQueriesReq struct {
+	Limit     int     `form:"limit,default=20" validate:"min=1,max=100" label:"返回条数"`
+	MinMeanMs float64 `form:"minMeanMs,default=0" validate:"min=0" label:"最小平均耗时（毫秒）"`
+}
+
+// 慢查询项，数据来自 pg_stat_statements
+type SlowQueryItem struct {
+	QueryID     string                 `db:"query_id" json:"query_id" label:"查询ID"`
+	Query       string                 `db:"query" json:"query" label:"查询语句"`
+	Calls       int64                  `db:"calls" json:"calls" label:"调用次数"`
+	TotalMs     float64                `db:"total_ms" json:"total_ms" label:"总耗时（毫秒）"`
+	MeanMs      float64                `db:"mean_ms" json:"mean_ms" label:"平均耗时（毫秒）"`
+	Rows        int64                  `db:"rows" json:"rows" label:"返回行数"`
+	Suggestions []pkgs.IndexSuggestion `db:"-" json:"suggestions" label:"索引建议"`
+}
+
+// 表扫描统计，数据来自 pg_stat_user_tables
+type TableScanItem struct {
+	Table      string `db:"table_name" json:"table" label:"表名"`
+	SeqScan    int64  `db:"seq_scan" json:"seq_scan" label:"顺序扫描次数"`
+	IdxScan    int64  `db:"idx_scan" json:"idx_scan" label:"索引扫描次数"`
+	LiveTuples int64  `db:"live_tuples" json:"live_tuples" label:"存活行数"`
+	NeedsIndex bool   `db:"-" json:"needs_index" label:"是否建议加索引"`
+}
+
+// 查询慢查询的响应体
+type SlowQueriesRes struct {
+	List   []SlowQueryItem `json:"list"`
+	Tables []TableScanItem `json:"tables"`
+}
+
+// 查询数据保留策略的请求参数
+type RetentionReq struct {
+	Days int `form:"days,default=7" validate:"min=1,max=365" label:"预告天数"`
+}
+
+// 查询数据保留策略的响应体
+type RetentionRes struct {
+	List []pkgs.RetentionSummary `json:"list"`
+}
+
+// 修改数据保留策略的请求体
+type UpdateRetentionReq struct {
+	Category   string `uri:"category" json:"-" validate:"required" label:"数据类别"`
+	RetainDays int    `json:"retain_days" validate:"required,min=1,max=3650" label:"保留天数"`
+	Enabled    *bool  `json:"enabled" validate:"required" label:"是否启用"`
+}
+
+// 修改数据保留策略的响应体
+type UpdateRetentionRes = int64
+
+// 查询运行时设置的响应体
+type SettingsRes struct {
+	List []pkgs.SettingItem `json:"list"`
+}
+
+// 修改运行时设置的请求体，键为设置项，取值为 null 时恢复为配置文件中的取值
+type UpdateSettingsReq struct {
+	Values map[string]json.RawMessage `json:"values" validate:"required,min=1" swaggertype:"object" label:"设置项"`
+}
+
+// 修改运行时设置的响应体，changed 为取值发生变化的设置项
+type UpdateSettingsRes struct {
+	Changed []string `json:"changed" label:"发生变化的设置项"`
+}
+
+// 查询设置变更历史的请求参数
+type SettingsHistoryReq struct {
+	pkgs.Pagination
+	Key string `form:"key,omitempty" validate:"omitempty,max=100" label:"设置项"`
+}
+
+// 数据库表 settings_history 的表结构
+type SettingsHistoryEntity struct {
+	ID        string          `db:"id"`
+	CreatedAt time.Time       `db:"created_at"`
+	Key       string          `db:"key"`
+	OldValue  json.RawMessage `db:"old_value"`
+	NewValue  json.RawMessage `db:"new_value"`
+	ChangedBy *string         `db:"changed_by"`
+}
+
+// 设置变更记录，old_value、new_value 为空表示配置文件中的取值
+type SettingsHistoryItem struct {
+	ID        string          `json:"id" label:"记录ID"`
+	Key       string          `json:"key" label:"设置项"`
+	OldValue  json.RawMessage `json:"old_value" swaggertype:"object" label:"修改前"`
+	NewValue  json.RawMessage `json:"new_value" swaggertype:"object" label:"修改后"`
+	ChangedBy *string         `json:"changed_by" label:"修改人ID"`
+	CreatedAt string          `json:"created_at" label:"修改时间"`
+}
+
+// 查询设置变更历史的响应体，按修改时间倒序
+type SettingsHistoryRes struct {
+	List  []SettingsHistoryItem `json:"list"`
+	Total int64                 `json:"total"`
+}
+
+// 离职交接的异步任务类型
+const JobTypeOffboarding = "iacc.user.offboard"
+
+// 发起离职交接的请求体
+type OffboardReq struct {
+	UserID      string  `uri:"userId" json:"-" validate:"required,uuid" label:"用户ID"`
+	SuccessorID string  `json:"successor_id" validate:"required,uuid" label:"交接人ID"`
+	Reason      *string `json:"reason,omitempty" validate:"omitempty,max=500" label:"原因"`
+}
+
+func offboardRule(req *OffboardReq) []pkgs.Violation {
+	if req.SuccessorID != "" && req.SuccessorID == req.UserID {
+		return []pkgs.Violation{{Field: "successor_id", Message: "交接人不能是离职用户本人"}}
+	}
+	return nil
+}
+
+// 发起离职交接的响应体
+type OffboardRes struct {
+	ID    string `json:"id" label:"交接记录ID"`
+	JobID string `json:"job_id" label:"任务ID"`
+}
+
+// 离职交接任务的参数
+type offboardingPayload struct {
+	OffboardingID string `json:"offboarding_id"`
+}
+
+// 离职交接中撤销的角色
+type RevokedRole struct {
+	ID   string `db:"id" json:"id" label:"角色ID"`
+	Name string `db:"name" json:"name" label:"角色名称"`
+}
+
+// 离职交接报告
+type OffboardingReport struct {
+	RevokedRoles        []RevokedRole `json:"revoked_roles" label:"撤销的角色"`
+	TerminatedSessions  int64         `json:"terminated_sessions" label:"终止的会话（登录设备）数"`
+	ReassignedTemplates int64         `json:"reassigned_templates" label:"转交的模板数"`
+	DisabledAt          time.Time     `json:"disabled_at" label:"停用时间"`
+}
+
+// Value 实现 driver.Valuer 接口，用于将OffboardingReport类型正确存储到数据库中
+func (r OffboardingReport) Value() (driver.Value, error) {
+	return pkgs.GenericJSONValue(r)
+}
+
+// Scan 实现 sql.Scanner 接口，用于从数据库中正确读取 OffboardingReport 类型
+func (r *OffboardingReport) Scan(value any) error {
+	return pkgs.GenericJSONScan(r, value)
+}
+
+// 数据库表iacc_offboarding的表结构，任务状态与错误信息来自 async_job
+type OffboardingEntity struct {
+	ID          string             `db:"id" label:"交接记录ID"`
+	CreatedAt   time.Time          `db:"created_at" label:"创建时间"`
+	UpdatedAt   time.Time          `db:"updated_at" label:"更新时间"`
+	UserID      string             `db:"user_id" label:"用户ID"`
+	SuccessorID string             `db:"successor_id" label:"交接人ID"`
+	RequestedBy string             `db:"requested_by" label:"发起人"`
+	Reason      *string            `db:"reason" label:"原因"`
+	JobID       *string            `db:"job_id" label:"任务ID"`
+	Report      *OffboardingReport `db:"report" label:"交接报告"`
+	FinishedAt  *time.Time         `db:"finished_at" label:"完成时间"`
+	JobStatus   *string            `db:"job_status" label:"任务状态"`
+	LastError   *string            `db:"last_error" label:"错误信息"`
+}
+
+// 查询离职交接的请求参数
+type GetOffboardingReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"交接记录ID"`
+}
+
+// 查询离职交接的响应体，status 为任务状态（pending、running、succeeded、failed、canceled）
+type GetOffboardingRes struct {
+	ID          string             `json:"id" label:"交接记录ID"`
+	UserID      string             `json:"user_id" label:"用户ID"`
+	SuccessorID string             `json:"successor_id" label:"交接人ID"`
+	RequestedBy string             `json:"requested_by" label:"发起人"`
+	Reason      *string            `json:"reason,omitempty" label:"原因"`
+	JobID       string             `json:"job_id" label:"任务ID"`
+	Status      string             `json:"status" label:"任务状态"`
+	Error       *string            `json:"error,omitempty" label:"错误信息"`
+	Report      *OffboardingReport `json:"report,omitempty" label:"交接报告"`
+	CreatedAt   string             `json:"created_at" label:"创建时间"`
+	FinishedAt  *string            `json:"finished_at,omitempty" label:"完成时间"`
+}
+
+// 环境快照的格式标识与版本，恢复时只接受相同格式、不高于当前版本的快照
+const (
+	SnapshotFormat  = "go-pg-demo.snapshot"
+	SnapshotVersion = 1
+)
+
+// 恢复快照的方式：merge 按主键新增或覆盖，replace 额外删除快照中不存在的数据
+const (
+	SnapshotModeMerge   = "merge"
+	SnapshotModeReplace = "replace"
+)
+
+// 环境快照，按依赖顺序包含 iacc_* 与模板表的数据
+// 不包含密码、API 密钥、登录设备等凭据，也不包含异步任务、角色变更记录等运行数据；
+// 手机号等加密字段保持密文，key_fingerprint 为导出环境的字段加密密钥指纹，只能恢复到密钥相同的环境。
+type Snapshot struct {
+	Format         string          `json:"format" validate:"required" label:"快照格式"`
+	Version        int             `json:"version" validate:"required,min=1" label:"快照版本"`
+	CreatedAt      string          `json:"created_at" label:"导出时间"`
+	KeyFingerprint string          `json:"key_fingerprint,omitempty" label:"字段加密密钥指纹"`
+	Tables         []SnapshotTable `json:"tables" validate:"dive" label:"数据表"`
+}
+
+// 快照中一张表的数据，rows 为按主键可重复导入的行（JSON 对象数组），name 为不带前缀的表名
+type SnapshotTable struct {
+	Name string          `json:"name" validate:"required" label:"表名"`
+	Rows json.RawMessage `json:"rows" swaggertype:"array,object" label:"数据行"`
+}
+
+// 恢复快照的请求体
+type RestoreSnapshotReq struct {
+	Mode     string   `json:"mode" validate:"omitempty,oneof=merge replace" label:"恢复方式"`
+	Snapshot Snapshot `json:"snapshot" label:"快照"`
+}
+
+func restoreSnapshotRule(req *RestoreSnapshotReq) []pkgs.Violation {
+	var violations []pkgs.Violation
+	if req.Snapshot.Format != SnapshotFormat || req.Snapshot.Version > SnapshotVersion {
+		violations = append(violations, pkgs.Violation{Field: "snapshot.format", Message: "不支持的快照格式或版本"})
+	}
+	names := make([]string, len(req.Snapshot.Tables))
+	for i, table := range req.Snapshot.Tables {
+		names[i] = table.Name
+		if _, ok := snapshotTableByName(table.Name); !ok {
+			violations = append(violations, pkgs.Violation{Field: "snapshot.tables.name", Message: "快照中包含不支持的数据表：" + table.Name})
+		}
+	}
+	return append(violations, pkgs.DuplicateViolations("snapshot.tables.name", "表名", names)...)
+}
+
+// 恢复快照时一张表的处理结果
+type RestoreSnapshotTable struct {
+	Name     string `json:"name" label:"表名"`
+	Upserted int64  `json:"upserted" label:"新增或覆盖的行数"`
+	Deleted  int64  `json:"deleted" label:"删除的行数"`
+}
+
+// 恢复快照的响应体
+type RestoreSnapshotRes struct {
+	Mode   string                 `json:"mode" label:"恢复方式"`
+	Tables []RestoreSnapshotTable `json:"tables" label:"数据表"`
+}
+
+// 查询异步任务的请求参数，windowHours 为成功、失败、重试统计的时间窗口
+type QueryJobsReq struct {
+	pkgs.Pagination
+	Status      string `form:"status,omitempty" validate:"omitempty,oneof=pending running succeeded failed canceled" label:"任务状态"`
+	Type        string `form:"type,omitempty" validate:"omitempty,max=50" label:"任务类型"`
+	WindowHours int    `form:"windowHours,default=24" validate:"min=1,max=720" label:"统计窗口（小时）"`
+}
+
+// 异步任务，status 为 pending、running、succeeded、failed、canceled
+type JobItem struct {
+	ID          string          `json:"id" label:"任务ID"`
+	Type        string          `json:"type" label:"任务类型"`
+	Status      string          `json:"status" label:"任务状态"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object" label:"任务参数"`
+	Attempts    int             `json:"attempts" label:"已执行次数"`
+	MaxAttempts int             `json:"max_attempts" label:"最大执行次数"`
+	LastError   *string         `json:"last_error,omitempty" label:"最近一次错误"`
+	TraceID     string          `json:"trace_id" label:"发起请求的请求ID"`
+	RunAt       string          `json:"run_at" label:"计划执行时间"`
+	StartedAt   *string         `json:"started_at,omitempty" label:"最近一次开始时间"`
+	FinishedAt  *string         `json:"finished_at,omitempty" label:"结束时间"`
+	CreatedAt   string          `json:"created_at" label:"创建时间"`
+	UpdatedAt   string          `json:"updated_at" label:"更新时间"`
+}
+
+// 查询异步任务的响应体，stats 为当前租户按任务类型的队列状态，list 为按创建时间倒序的任务
+type QueryJobsRes struct {
+	Stats []pkgs.JobTypeStats `json:"stats"`
+	List  []JobItem           `json:"list"`
+	Total int64               `json:"total"`
+}
+
+// 重试、取消异步任务的请求参数
+type JobIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"任务ID"`
+}
+
+// 重试、取消异步任务的响应体，为修改后的任务
+type JobActionRes = JobItem
+
+// 影子模式限流报告中的一个 API 密钥，exceeded 为启用限流后会被拒绝的请求数，peak 为单个时间窗口内的最大请求数
+// API 密钥已删除时 name、key_prefix 为空
+type RateLimitShadowItem struct {
+	APIKeyID        string `json:"api_key_id" label:"API密钥ID"`
+	Name            string `json:"name" label:"名称"`
+	KeyPrefix       string `json:"key_prefix" label:"密钥前缀"`
+	Tier            string `json:"tier" label:"限流等级"`
+	Limit           int    `json:"limit" label:"窗口内允许的请求数"`
+	WindowSeconds   int64  `json:"window_seconds" label:"时间窗口（秒）"`
+	Exceeded        int64  `json:"exceeded" label:"超出限制的请求数"`
+	Peak            int    `json:"peak" label:"窗口内最大请求数"`
+	FirstExceededAt string `json:"first_exceeded_at" label:"首次超出时间"`
+	LastExceededAt  string `json:"last_exceeded_at" label:"最近超出时间"`
+}
+
+// 影子模式限流报告的响应体，list 按超出限制的请求数倒序
+type RateLimitShadowRes struct {
+	List []RateLimitShadowItem `json:"list"`
+}
+
+// 本实例编译的接口权限记录（PermissionMiddleware 据此判断接口是否纳入权限体系）
+type PermissionRoutesItem struct {
+	Compiled   bool    `json:"compiled" label:"是否已编译"`
+	CompiledAt *string `json:"compiled_at" label:"编译时间"`
+	AgeSeconds int64   `json:"age_seconds" label:"编译至今（秒）"`
+	Count      int     `json:"count" label:"接口权限记录数"`
+	Stale      bool    `json:"stale" label:"是否已过期"`
+}
+
+// 权限缓存状态的响应体，hits、misses、errors、hit_rate 为处理本请求的实例启动以来的统计，
+// entries、stale_entries 为 Redis 中当前租户的缓存条目，未配置 Redis 或熔断时为 0
+type PermissionCacheStatusRes struct {
+	Enabled       bool                 `json:"enabled" label:"是否配置了Redis"`
+	Circuit       string               `json:"circuit" label:"熔断器状态"`
+	TTLSeconds    int64                `json:"ttl_seconds" label:"缓存有效期（秒）"`
+	Generation    int64                `json:"generation" label:"权限版本号"`
+	InvalidatedAt *string              `json:"invalidated_at" label:"最近失效时间"`
+	Entries       int64                `json:"entries" label:"缓存的用户数"`
+	StaleEntries  int64                `json:"stale_entries" label:"已失效未过期的用户数"`
+	Hits          int64                `json:"hits" label:"命中次数"`
+	Misses        int64                `json:"misses" label:"未命中次数"`
+	Errors        int64                `json:"errors" label:"出错次数"`
+	HitRate       float64              `json:"hit_rate" label:"命中率"`
+	Routes        PermissionRoutesItem `json:"routes" label:"接口权限记录"`
+}
+
+// 重建权限缓存的响应体，generation 为失效后的权限版本号，routes 为本实例重新编译的接口权限记录
+type RebuildPermissionCacheRes struct {
+	Generation int64                `json:"generation" label:"权限版本号"`
+	Routes     PermissionRoutesItem `json:"routes" label:"接口权限记录"`
+}
+
+// 角色权限矩阵的导出格式
+const (
+	MatrixFormatJSON = "json"
+	MatrixFormatCSV  = "csv"
+)
+
+// 角色权限矩阵的查询参数，按权限分页（矩阵的行），每页包含全部角色（矩阵的列）
+type RolePermissionMatrixReq struct {
+	pkgs.Pagination
+	Format string `form:"format,default=json" validate:"oneof=json csv" label:"导出格式"`
+}
+
+// 角色权限矩阵的列
+type MatrixRole struct {
+	ID   string `db:"id" json:"id" label:"角色ID"`
+	Name string `db:"name" json:"name" label:"角色名称"`
+}
+
+// 角色权限矩阵的行，grants 为角色ID到 allow、deny 的映射，未授予的角色不出现
+type MatrixRow struct {
+	PermissionID string       `db:"id" json:"permission_id" label:"权限ID"`
+	Name         string       `db:"name" json:"name" label:"权限名称"`
+	Type         string       `db:"type" json:"type" label:"权限类型"`
+	Code         string       `db:"code" json:"code,omitempty" label:"权限编码"`
+	Method       string       `db:"method" json:"method,omitempty" label:"请求方法"`
+	Path         string       `db:"path" json:"path,omitempty" label:"请求路径"`
+	Grants       MatrixGrants `db:"grants" json:"grants" swaggertype:"object,string" label:"授予情况"`
+}
+
+// MatrixGrants 角色ID到授予效果（allow、deny）的映射
+type MatrixGrants map[string]string
+
+// Scan 实现 sql.Scanner 接口
+func (g *MatrixGrants) Scan(value any) error {
+	return pkgs.GenericJSONScan(g, value)
+}
+
+// 角色权限矩阵的响应体，total 为权限总数
+type RolePermissionMatrixRes struct {
+	Roles []MatrixRole `json:"roles"`
+	List  []MatrixRow  `json:"list"`
+	Total int64        `json:"total"`
+}
+
+// 按条件批量撤销令牌的请求体，条件同时满足；至少指定一个条件
+type RevokeTokensReq struct {
+	// 为空表示全部用户
+	UserIDs []string `json:"user_ids,omitempty" validate:"omitempty,max=1000,dive,uuid" label:"用户ID列表"`
+	// 撤销在该时间及之前签发的刷新令牌，默认为当前时间
+	IssuedBefore *time.Time `json:"issued_before,omitempty" label:"签发时间早于"`
+	// 只撤销该类型客户端的刷新令牌，为空表示全部客户端
+	ClientType string `json:"client_type,omitempty" validate:"omitempty,oneof=web mobile service" label:"客户端类型"`
+}
+
+// revokeTokensRule 批量撤销令牌的跨字段校验：至少指定一个条件，签发时间不能晚于当前时间
+func revokeTokensRule(req *RevokeTokensReq) []pkgs.Violation {
+	var violations []pkgs.Violation
+	if len(req.UserIDs) == 0 && req.IssuedBefore == nil && req.ClientType == "" {
+		violations = append(violations, pkgs.Violation{Field: "user_ids", Message: "至少指定用户、签发时间、客户端类型中的一个条件"})
+	}
+	if req.IssuedBefore != nil && req.IssuedBefore.After(time.Now()) {
+		violations = append(violations, pkgs.Violation{Field: "issued_before", Message: "签发时间不能晚于当前时间"})
+	}
+	return violations
+}
+
+// 批量撤销令牌的响应体
+// 返回写入的撤销条件、撤销的刷新令牌数与持有这些令牌的用户数：这些用户的访问令牌立即失效，
+// 命中条件的刷新令牌不能再换取新令牌，未命中条件的刷新令牌仍可换取新的访问令牌。
+type RevokeTokensRes struct {
+	UserIDs      []string  `json:"user_ids,omitempty" label:"用户ID列表"`
+	IssuedBefore time.Time `json:"issued_before" label:"签发时间早于"`
+	ClientType   string    `json:"client_type,omitempty" label:"客户端类型"`
+	Tokens       int64     `json:"tokens" label:"撤销的刷新令牌数"`
+	Users        int64     `json:"users" label:"受影响的用户数"`
+}
+
+// 支持包（GET /admin/support-bundle）中的文件，均为 JSON
+const (
+	SupportBundleManifestFile   = "manifest.json"
+	SupportBundleConfigFile     = "config.json"
+	SupportBundleErrorsFile     = "errors.json"
+	SupportBundleMigrationsFile = "migrations.json"
+	SupportBundlePoolsFile      = "pools.json"
+	SupportBundleHealthFile     = "health.json"
+)
+
+// 生成的支持包，Content 为 zip 文件内容
+type SupportBundle struct {
+	FileName string
+	Content  []byte
+}
+
+// 支持包的说明文件：生成时间、处理请求的实例的运行时信息与包含的文件
+type SupportBundleManifest struct {
+	GeneratedAt    string   `json:"generated_at"`
+	RequestID      string   `json:"request_id"`
+	Tenant         string   `json:"tenant"`
+	ServerMode     string   `json:"server_mode"`
+	GoVersion      string   `json:"go_version"`
+	OS             string   `json:"os"`
+	Arch           string   `json:"arch"`
+	NumCPU         int      `json:"num_cpu"`
+	Goroutines     int      `json:"goroutines"`
+	HeapAllocBytes uint64   `json:"heap_alloc_bytes"`
+	SysBytes       uint64   `json:"sys_bytes"`
+	NumGC          uint32   `json:"num_gc"`
+	Files          []string `json:"files"`
+}
+
+// 支持包中一个 schema 的迁移状态，schema 为空表示默认 schema，读取失败时 error 为原因
+type SupportBundleSchema struct {
+	Schema  string `json:"schema"`
+	Version uint   `json:"version"`
+	Dirty   bool   `json:"dirty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// 支持包中的迁移状态：内嵌迁移文件的最新版本与各 schema 记录的版本
+type SupportBundleMigrations struct {
+	LatestVersion uint                  `json:"latest_version"`
+	AutoMigrate   bool                  `json:"auto_migrate"`
+	Schemas       []SupportBundleSchema `json:"schemas"`
+}
+
+// 支持包中一个连接池的统计（database/sql.DBStats）
+type SupportBundlePool struct {
+	Name               string `json:"name"`
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDurationMs     int64  `json:"wait_duration_ms"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+}
+
+// 就绪检查的响应体，主库与分析库连接分别报告
+// 主库不可用时 ready 为 false，返回 503 业务码，data 中同样包含各项检查结果
+type ReadinessRes struct {
+	Ready  bool                 `json:"ready" label:"是否就绪"`
+	Checks []SupportBundleProbe `json:"checks" label:"检查结果"`
+}
+
+// 支持包中一项健康检查的结果
+type SupportBundleProbe struct {
+	Name      string  `json:"name"`
+	OK        bool    `json:"ok"`
+	LatencyMs float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
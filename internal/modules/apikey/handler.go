@@ -0,0 +1,119 @@
+// Package apikey API.
+//
+// 公开接口 API 密钥管理。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package apikey
+
+import (
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+}
+
+func NewAPIKeyHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator, settings *pkgs.Settings) *Handler {
+	return &Handler{
+		db:        db,
+		logger:    logger,
+		validator: validator,
+		repository: &Repository{
+			db:       db,
+			logger:   logger,
+			tables:   tables,
+			pool:     pool,
+			ids:      ids,
+			settings: settings,
+		},
+	}
+}
+
+// Create 创建API密钥
+//
+//	@Summary  创建API密钥
+//	@Description  为外部合作方创建访问 /public/v1 接口的 API 密钥，完整密钥只在创建时返回一次；tier 为运行时设置 public_api.tiers 中的限流等级（见 GET /admin/settings）
+//	@Tags   API密钥
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  CreateReq true  "创建API密钥请求参数"
+//	@Success  200   {object}  pkgs.Response{data=CreateRes}  "创建成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/api-key"}
+//	@Router   /api-key [post]
+func (h *Handler) Create(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[CreateReq](c),
+		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+		result.FlatMap(h.repository.Create(c)),
+	).Match(
+		pkgs.HandleSuccess[CreateRes](c),
+		pkgs.HandleError[CreateRes](c),
+	)
+}
+
+// Revoke 吊销API密钥
+//
+//	@Summary  吊销API密钥
+//	@Description  吊销后该密钥立即无法访问公开接口
+//	@Tags   API密钥
+//	@Produce  json
+//	@Param    id  path  string  true  "API密钥ID"
+//	@Success  200 {object}  pkgs.Response{data=RevokeRes} "吊销成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"DELETE","path":"/v1/api-key/:id"}
+//	@Router   /api-key/{id} [delete]
+func (h *Handler) Revoke(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[RevokeReq](c),
+		result.FlatMap(pkgs.ValidateV2[RevokeReq](h.validator)),
+		result.FlatMap(h.repository.Revoke(c)),
+	).Match(
+		pkgs.HandleSuccess[RevokeRes](c),
+		pkgs.HandleError[RevokeRes](c),
+	)
+}
+
+// QueryList 获取API密钥列表
+//
+//	@Summary  获取API密钥列表
+//	@Description  获取API密钥列表，不返回密钥本身
+//	@Tags   API密钥
+//	@Produce  json
+//	@Param    page    query int   false "页码"  default(1)
+//	@Param    pageSize  query int   false "每页数量"  default(10)
+//	@Param    name    query string  false "名称"
+//	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/api-key/list"}
+//	@Router   /api-key/list [get]
+func (h *Handler) QueryList(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[QueryListReq](c),
+		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.QueryList(c)),
+	).Match(
+		pkgs.HandleSuccess[QueryListRes](c),
+		pkgs.HandleError[QueryListRes](c),
+	)
+}
@@ -0,0 +1,157 @@
+package apikey
+
+import (
+	"go-pg-demo/pkgs"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db       *sqlx.DB
+	logger   *zap.Logger
+	tables   *pkgs.TableNames
+	pool     *pkgs.TenantPool
+	ids      *pkgs.IDGenerator
+	settings *pkgs.Settings
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
+	return func(req *CreateReq) mo.Result[CreateRes] {
+		// 限流等级必须在运行时设置中存在
+		if _, ok := r.settings.RateLimitTier(req.Tier); !ok {
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusBadRequest, "限流等级不存在"))
+		}
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusBadRequest, "过期时间必须晚于当前时间"))
+		}
+
+		key, prefix, hash, err := pkgs.GenerateAPIKey()
+		if err != nil {
+			r.log(c).Error("生成API密钥失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建API密钥失败"))
+		}
+
+		// 数据库操作
+		entity := &APIKeyEntity{
+			Name:      req.Name,
+			KeyPrefix: prefix,
+			KeyHash:   hash,
+			Tier:      req.Tier,
+			ExpiresAt: req.ExpiresAt,
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建API密钥失败"))
+		}
+		columns, values := r.ids.Insert("name", "key_prefix", "key_hash", "tier", "expires_at")
+		query := `INSERT INTO ` + r.tables.APIKey + ` (` + columns + `) VALUES (` + values + `) RETURNING id`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
+		if err != nil {
+			r.log(c).Error("准备插入语句失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建API密钥失败"))
+		}
+		defer stmt.Close()
+		if err := stmt.GetContext(c.Request.Context(), &entity.ID, entity); err != nil {
+			return mo.Err[CreateRes](pkgs.DBError(r.log(c), err, "创建API密钥失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(CreateRes{ID: entity.ID, Key: key, KeyPrefix: prefix})
+	}
+}
+
+func (r *Repository) Revoke(c *gin.Context) func(*RevokeReq) mo.Result[RevokeRes] {
+	return func(req *RevokeReq) mo.Result[RevokeRes] {
+		// 数据库操作，已吊销的密钥保持原吊销时间
+		query := `UPDATE ` + r.tables.APIKey + ` SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID)
+		if err != nil {
+			return mo.Err[RevokeRes](pkgs.DBError(r.log(c), err, "吊销API密钥失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[RevokeRes](pkgs.NewApiError(http.StatusInternalServerError, "吊销API密钥失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
+	return func(req *QueryListReq) mo.Result[QueryListRes] {
+		// 构建查询
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
+		}
+		whereCondition := ""
+		if req.Name != "" {
+			whereCondition = " WHERE name ILIKE :name"
+			params["name"] = "%" + req.Name + "%"
+		}
+
+		// 查询总数
+		var total int64
+		countQuery := "SELECT count(*) FROM " + r.tables.APIKey + whereCondition
+		countQuery, countArgs, err := sqlx.Named(countQuery, params)
+		if err != nil {
+			r.log(c).Error("构建计数查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询API密钥列表失败"))
+		}
+		db := r.conn(c)
+		if err := db.GetContext(c.Request.Context(), &total, db.Rebind(countQuery), countArgs...); err != nil {
+			r.log(c).Error("统计API密钥数量失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询API密钥列表失败"))
+		}
+		if total == 0 {
+			return mo.Ok(QueryListRes{List: []APIKeyItem{}, Total: 0})
+		}
+
+		// 查询列表
+		var entities []APIKeyEntity
+		listQuery := `SELECT id, name, key_prefix, key_hash, tier, expires_at, revoked_at, created_at, updated_at FROM ` + r.tables.APIKey +
+			whereCondition + ` ORDER BY created_at DESC, seq DESC LIMIT :limit OFFSET :offset`
+		listQuery, listArgs, err := sqlx.Named(listQuery, params)
+		if err != nil {
+			r.log(c).Error("构建列表查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询API密钥列表失败"))
+		}
+		if err := db.SelectContext(c.Request.Context(), &entities, db.Rebind(listQuery), listArgs...); err != nil {
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询API密钥列表失败"))
+		}
+
+		list := make([]APIKeyItem, 0, len(entities))
+		for _, entity := range entities {
+			list = append(list, APIKeyItem{
+				ID:        entity.ID,
+				Name:      entity.Name,
+				KeyPrefix: entity.KeyPrefix,
+				Tier:      entity.Tier,
+				ExpiresAt: pkgs.FormatTimePtr(c, entity.ExpiresAt),
+				RevokedAt: pkgs.FormatTimePtr(c, entity.RevokedAt),
+				CreatedAt: pkgs.FormatTime(c, entity.CreatedAt),
+				UpdatedAt: pkgs.FormatTime(c, entity.UpdatedAt),
+			})
+		}
+
+		// 返回结果
+		return mo.Ok(QueryListRes{List: list, Total: total})
+	}
+}
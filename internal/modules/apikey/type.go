@@ -0,0 +1,65 @@
+package apikey
+
+import (
+	"go-pg-demo/pkgs"
+	"time"
+)
+
+// 数据库表 api_key 的表结构
+type APIKeyEntity struct {
+	ID        string     `db:"id" label:"API密钥ID"`
+	CreatedAt time.Time  `db:"created_at" label:"创建时间"`
+	UpdatedAt time.Time  `db:"updated_at" label:"更新时间"`
+	Name      string     `db:"name" label:"名称"`
+	KeyPrefix string     `db:"key_prefix" label:"密钥前缀"`
+	KeyHash   string     `db:"key_hash" label:"密钥哈希"`
+	Tier      string     `db:"tier" label:"限流等级"`
+	ExpiresAt *time.Time `db:"expires_at" label:"过期时间"`
+	RevokedAt *time.Time `db:"revoked_at" label:"吊销时间"`
+}
+
+// 创建 API 密钥的请求 DTO
+type CreateReq struct {
+	Name      string     `json:"name" validate:"required,max=100" label:"名称"`
+	Tier      string     `json:"tier" validate:"required" label:"限流等级"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" label:"过期时间"`
+}
+
+// 创建 API 密钥的响应 DTO，完整密钥只在创建时返回一次
+type CreateRes struct {
+	ID        string `json:"id" label:"API密钥ID"`
+	Key       string `json:"key" label:"API密钥"`
+	KeyPrefix string `json:"key_prefix" label:"密钥前缀"`
+}
+
+// 吊销 API 密钥的请求参数
+type RevokeReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"API密钥ID"`
+}
+
+// 吊销 API 密钥的响应
+type RevokeRes = int64
+
+// 查询 API 密钥列表的请求体
+type QueryListReq struct {
+	pkgs.Pagination
+	Name string `form:"name,omitempty" validate:"omitempty" label:"名称"`
+}
+
+// API 密钥列表项，不包含密钥本身
+type APIKeyItem struct {
+	ID        string  `json:"id" label:"API密钥ID"`
+	Name      string  `json:"name" label:"名称"`
+	KeyPrefix string  `json:"key_prefix" label:"密钥前缀"`
+	Tier      string  `json:"tier" label:"限流等级"`
+	ExpiresAt *string `json:"expires_at,omitempty" label:"过期时间"`
+	RevokedAt *string `json:"revoked_at,omitempty" label:"吊销时间"`
+	CreatedAt string  `json:"created_at" label:"创建时间"`
+	UpdatedAt string  `json:"updated_at" label:"更新时间"`
+}
+
+// 查询 API 密钥列表的响应体
+type QueryListRes struct {
+	List  []APIKeyItem `json:"list"`
+	Total int64        `json:"total"`
+}
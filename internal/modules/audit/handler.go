@@ -0,0 +1,237 @@
+// Package audit API.
+//
+// 审计日志查询与导出，按时间范围、操作人、实体与操作筛选用户、角色、权限的创建、修改、删除与分配等管理操作的记录，用于合规检查。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//	- text/csv
+//
+//	Schemes: http
+package audit
+
+import (
+	"net/http"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	cache      *pkgs.PermissionCache
+	events     *pkgs.SecurityEvents
+	repository *Repository
+}
+
+func NewAuditHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, config *pkgs.Config, tables *pkgs.TableNames, pool *pkgs.TenantPool, reporting *pkgs.ReportingDB, jobs *pkgs.JobQueue, storage *pkgs.Storage, audit *pkgs.AuditLog, cache *pkgs.PermissionCache, events *pkgs.SecurityEvents) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, exportRule)
+	pkgs.RegisterRule(validator, listRule)
+
+	repository := &Repository{
+		db:        db,
+		logger:    logger,
+		tables:    tables,
+		pool:      pool,
+		reporting: reporting,
+		jobs:      jobs,
+		storage:   storage,
+		audit:     audit,
+		syncRows:  config.Audit.ExportSyncRows,
+	}
+	// 注册异步导出任务
+	jobs.Register(JobTypeExport, repository.runExport)
+
+	return &Handler{
+		db:         db,
+		logger:     logger,
+		validator:  validator,
+		cache:      cache,
+		events:     events,
+		repository: repository,
+	}
+}
+
+// List 查询审计日志
+//
+//	@Summary  查询审计日志
+//	@Description  分页查询用户、角色、权限的创建、修改、删除与分配等管理操作的记录，包含操作人、请求方法与路由、来源IP与请求ID。
+//	@Description  detail.changes 为操作前后发生变化的字段（{"字段": {"before": 旧值, "after": 新值}}），删除时 detail.before 为删除前的状态，创建时 detail.after 为创建后的状态；密码、手机号与个人信息不记录原值。
+//	@Tags   audit
+//	@Produce  json
+//	@Param    page        query int     false "页码"  default(1)
+//	@Param    pageSize    query int     false "每页数量"  default(10)
+//	@Param    cursor      query string  false "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，不统计总数"
+//	@Param    limit       query int     false "游标分页的每页条目数"  minimum(1)  maximum(100)  default(10)
+//	@Param    createdFrom query string  false "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param    createdTo   query string  false "创建时间止（含），传日期时包含当天全天"
+//	@Param    actorId     query string  false "操作人ID"
+//	@Param    entity      query string  false "实体"  Enums(role, role_change, permission, user)
+//	@Param    entityId    query string  false "实体ID"
+//	@Param    action      query string  false "操作，如 create、update、patch、delete、batch_delete、assign_roles、assign_permissions"
+//	@Param    method      query string  false "请求方法"  Enums(POST, PUT, PATCH, DELETE)
+//	@Param    order       query string  false "按创建时间排序的顺序"  Enums(asc, desc)  default(desc)
+//	@Success  200 {object}  pkgs.Response{data=ListRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/audit/list"}
+//	@Router   /audit/list [get]
+func (h *Handler) List(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[ListReq](c),
+		result.FlatMap(pkgs.ValidateV2[ListReq](h.validator)),
+		result.FlatMap(h.repository.List(c)),
+	).Match(
+		pkgs.HandleSuccess[ListRes](c),
+		pkgs.HandleError[ListRes](c),
+	)
+}
+
+// Export 导出审计日志
+//
+//	@Summary  导出审计日志
+//	@Description  按创建时间范围（createdFrom/createdTo 或最近 days 天，二者必选其一）、操作人、实体、实体ID与操作筛选审计日志，按时间顺序导出为 CSV，时间为 UTC 的 RFC 3339 格式。
+//	@Description  viewId 为保存的筛选预设（POST /views 保存 entity 为 audit 的视图，config.filters 的键与本接口的查询参数一致），请求中未传入的参数使用预设中的值，便于定期的合规导出。
+//	@Description  匹配的行数不超过 audit.export_sync_rows 时直接返回 CSV 文件；超过时转为异步任务，返回 202 业务码与任务ID，通过 GET /audit/export/{id} 查询进度，完成后通过 GET /audit/export/{id}/download 下载（需要配置对象存储）
+//	@Tags   audit
+//	@Produce  text/csv,json
+//	@Param    createdFrom query string  false "创建时间起（RFC 3339 时间或 YYYY-MM-DD 日期）"
+//	@Param    createdTo   query string  false "创建时间止，默认为当前时间"
+//	@Param    days        query int     false "最近天数，与 createdFrom、createdTo 二选一"
+//	@Param    actorId     query string  false "操作人ID"
+//	@Param    entity      query string  false "实体"  Enums(role, role_change, permission, user)
+//	@Param    entityId    query string  false "实体ID"
+//	@Param    action      query string  false "操作"
+//	@Param    viewId      query string  false "筛选预设ID"
+//	@Success  200 {file}    file  "CSV 文件"
+//	@Failure  202 {object}  pkgs.Response{data=ExportJobRes}  "匹配的记录较多，已转为异步导出"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误，或匹配的记录较多且未配置对象存储"
+//	@Failure  404 {object}  pkgs.Response         "筛选预设不存在"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/audit/export"}
+//	@Router   /audit/export [get]
+func (h *Handler) Export(c *gin.Context) {
+	// 预设中的值与请求参数一样需要校验，补全后再校验一次
+	result.Pipe4(
+		pkgs.BindQuery[ExportReq](c),
+		result.FlatMap(pkgs.ValidateV2[ExportReq](h.validator)),
+		result.FlatMap(h.repository.ApplyPreset(c)),
+		result.FlatMap(pkgs.ValidateV2[ExportReq](h.validator)),
+		result.FlatMap(h.repository.Export(c)),
+	).Match(
+		h.writeCSV(c),
+		pkgs.HandleError[*ExportPlan](c),
+	)
+}
+
+// writeCSV 直接写出 CSV 文件，开始写出后出错时无法再返回错误信封，只能中断响应
+func (h *Handler) writeCSV(c *gin.Context) func(*ExportPlan) (*ExportPlan, error) {
+	return func(plan *ExportPlan) (*ExportPlan, error) {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+exportFilename(plan.Filter)+`"`)
+		c.Status(http.StatusOK)
+		db, tables := h.repository.reportConn(c)
+		if _, err := h.repository.writeCSV(c.Request.Context(), db, tables, plan.Filter, c.Writer); err != nil {
+			h.logger.Error("导出审计日志失败", zap.Int64("rows", plan.Rows), zap.Error(err))
+			c.Abort()
+			return plan, err
+		}
+		return plan, nil
+	}
+}
+
+// GetExportJob 查询异步导出
+//
+//	@Summary  查询异步导出
+//	@Description  返回自己发起的审计日志异步导出的任务状态（pending、running、succeeded、failed、canceled）与失败原因，ready 为 true 时可以下载
+//	@Tags   audit
+//	@Produce  json
+//	@Param    id  path  string  true  "任务ID"
+//	@Success  200 {object}  pkgs.Response{data=GetExportJobRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response         "导出任务不存在"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/audit/export/:id"}
+//	@Router   /audit/export/{id} [get]
+func (h *Handler) GetExportJob(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[ExportJobReq](c),
+		result.FlatMap(pkgs.ValidateV2[ExportJobReq](h.validator)),
+		result.FlatMap(h.repository.GetExportJob(c)),
+	).Match(
+		pkgs.HandleSuccess[GetExportJobRes](c),
+		pkgs.HandleError[GetExportJobRes](c),
+	)
+}
+
+// DownloadExport 下载异步导出的文件
+//
+//	@Summary  下载异步导出的文件
+//	@Description  下载自己发起的、已完成的审计日志异步导出文件
+//	@Tags   audit
+//	@Produce  text/csv,json
+//	@Param    id  path  string  true  "任务ID"
+//	@Success  200 {file}    file  "CSV 文件"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response         "导出任务或导出文件不存在"
+//	@Failure  409 {object}  pkgs.Response         "导出任务尚未完成"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/audit/export/:id/download"}
+//	@Router   /audit/export/{id}/download [get]
+func (h *Handler) DownloadExport(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[ExportJobReq](c),
+		result.FlatMap(pkgs.ValidateV2[ExportJobReq](h.validator)),
+		result.FlatMap(h.repository.DownloadExport(c)),
+	).Match(
+		func(res *DownloadExportRes) (*DownloadExportRes, error) {
+			c.Header("Content-Disposition", `attachment; filename="audit-`+res.JobID+`.csv"`)
+			c.Data(http.StatusOK, "text/csv; charset=utf-8", res.Data)
+			return res, nil
+		},
+		pkgs.HandleError[*DownloadExportRes](c),
+	)
+}
+
+// Revert 撤销审计记录对应的操作
+//
+//	@Summary  撤销审计记录对应的操作
+//	@Description  在一个事务中把实体恢复为操作前的状态，并写入一条 action 为 revert 的审计记录（detail.reverted_id 为被撤销的记录，detail.changes 与原操作相反）。
+//	@Description  可撤销的操作：角色的 assign_permissions、sync_permissions（恢复权限与命名空间授予），用户的 assign_roles（恢复角色），以及这些操作的撤销记录（即重做）。
+//	@Description  每条记录只能撤销一次；实体在此之后又被修改过、关键角色（变更需要审批）、原有的权限或角色已被删除时不能撤销。
+//	@Tags   audit
+//	@Produce  json
+//	@Param    id  path  string  true  "审计记录ID"
+//	@Success  200 {object}  pkgs.Response{data=RevertRes}  "撤销成功"
+//	@Failure  400 {object}  pkgs.Response         "请求参数错误，或该操作不支持撤销"
+//	@Failure  404 {object}  pkgs.Response         "审计记录或实体不存在"
+//	@Failure  409 {object}  pkgs.Response         "已被撤销、实体在此之后已被修改、关键角色或原有的权限、角色已被删除"
+//	@Failure  500 {object}  pkgs.Response         "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/audit/:id/revert"}
+//	@Router   /audit/{id}/revert [post]
+func (h *Handler) Revert(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindUri[RevertReq](c),
+		result.FlatMap(pkgs.ValidateV2[RevertReq](h.validator)),
+		result.FlatMap(h.repository.Revert(c)),
+		result.Map(pkgs.InvalidatePermissionCache[RevertRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[RevertRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "revert_audit", "audit_id": c.Param("id")})),
+	).Match(
+		pkgs.HandleSuccess[RevertRes](c),
+		pkgs.HandleError[RevertRes](c),
+	)
+}
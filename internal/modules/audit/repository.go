@@ -0,0 +1,655 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+// 写出多少行刷新一次响应，让客户端尽早开始接收
+const exportFlushRows = 1000
+
+// CSV 的列，与 writeCSV 中的取值顺序一致
+var exportColumns = []string{"id", "created_at", "actor_id", "actor_username", "action", "entity", "entity_id", "ip", "trace_id", "detail", "method", "path"}
+
+type Repository struct {
+	db        *sqlx.DB
+	logger    *zap.Logger
+	tables    *pkgs.TableNames
+	pool      *pkgs.TenantPool
+	reporting *pkgs.ReportingDB
+	jobs      *pkgs.JobQueue
+	storage   *pkgs.Storage
+	audit     *pkgs.AuditLog
+	// 直接导出的最大行数，见配置 audit.export_sync_rows
+	syncRows int
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// reportConn 返回报表查询应使用的连接与表名，配置了分析库时在分析库查询，不占用主库
+func (r *Repository) reportConn(c *gin.Context) (*sqlx.DB, *pkgs.TableNames) {
+	return r.reporting.Conn(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+// ApplyPreset 使用筛选预设补全请求中未传入的参数
+// 预设为当前用户保存或共享给其角色、entity 为 audit 的列表视图，筛选条件的键与导出接口的查询参数一致；
+// 请求中传入了任一时间参数（createdFrom、createdTo、days）时不使用预设中的时间范围。
+func (r *Repository) ApplyPreset(c *gin.Context) func(*ExportReq) mo.Result[*ExportReq] {
+	return func(req *ExportReq) mo.Result[*ExportReq] {
+		if req.ViewID == "" {
+			return mo.Ok(req)
+		}
+
+		var config json.RawMessage
+		query := `SELECT config FROM ` + r.tables.SavedView + `
+			WHERE id = $1 AND entity = $2 AND (owner_id = $3 OR shared_role_ids && ARRAY(SELECT role_id FROM ` + r.tables.UserRole + ` WHERE user_id = $3))`
+		if err := r.conn(c).GetContext(c.Request.Context(), &config, query, req.ViewID, presetEntity, pkgs.CurrentUserID(c)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[*ExportReq](pkgs.NewApiError(http.StatusNotFound, "筛选预设不存在"))
+			}
+			return mo.Err[*ExportReq](pkgs.DBError(r.log(c), err, "查询筛选预设失败"))
+		}
+		var preset struct {
+			Filters map[string]string `json:"filters"`
+		}
+		if err := json.Unmarshal(config, &preset); err != nil {
+			r.log(c).Error("解析筛选预设失败", zap.String("view_id", req.ViewID), zap.Error(err))
+			return mo.Err[*ExportReq](pkgs.NewApiError(http.StatusInternalServerError, "查询筛选预设失败"))
+		}
+
+		params := c.Request.URL.Query()
+		customRange := params.Has("createdFrom") || params.Has("createdTo") || params.Has("days")
+		for key, value := range preset.Filters {
+			if params.Has(key) {
+				continue
+			}
+			switch key {
+			case "createdFrom", "createdTo", "days":
+				if customRange {
+					continue
+				}
+			}
+			switch key {
+			case "createdFrom":
+				req.CreatedFrom = value
+			case "createdTo":
+				req.CreatedTo = value
+			case "days":
+				days, err := strconv.Atoi(value)
+				if err != nil {
+					return mo.Err[*ExportReq](pkgs.NewApiError(http.StatusBadRequest, "筛选预设中的最近天数无效"))
+				}
+				req.Days = days
+			case "actorId":
+				req.ActorID = value
+			case "entity":
+				req.Entity = value
+			case "entityId":
+				req.EntityID = value
+			case "action":
+				req.Action = value
+			}
+		}
+		return mo.Ok(req)
+	}
+}
+
+// Export 解析导出条件并统计匹配的行数
+// 行数不超过 audit.export_sync_rows 时返回导出条件，由处理器直接写出 CSV；
+// 超过时写入异步导出任务，以 202 业务码结束请求，任务完成后通过 GET /audit/export/{id} 查询并下载。
+func (r *Repository) Export(c *gin.Context) func(*ExportReq) mo.Result[*ExportPlan] {
+	return func(req *ExportReq) mo.Result[*ExportPlan] {
+		filter := resolveFilter(c, req)
+
+		var rows int64
+		params := map[string]any{}
+		db, tables := r.reportConn(c)
+		query, args, err := db.BindNamed(`SELECT COUNT(*) FROM `+tables.AuditLog+` a WHERE `+filter.where(params), params)
+		if err != nil {
+			r.log(c).Error("构建查询失败", zap.Error(err))
+			return mo.Err[*ExportPlan](pkgs.NewApiError(http.StatusInternalServerError, "导出审计日志失败"))
+		}
+		if err := db.GetContext(c.Request.Context(), &rows, query, args...); err != nil {
+			return mo.Err[*ExportPlan](pkgs.DBError(r.log(c), err, "导出审计日志失败"))
+		}
+		if rows <= int64(r.syncRows) {
+			return mo.Ok(&ExportPlan{Filter: filter, Rows: rows})
+		}
+
+		if !r.storage.Enabled() {
+			msg := fmt.Sprintf("匹配 %d 条记录，超过直接导出的上限 %d 条；未配置对象存储，无法异步导出，请缩小筛选范围", rows, r.syncRows)
+			return mo.Err[*ExportPlan](pkgs.NewApiError(http.StatusBadRequest, msg))
+		}
+		jobID, err := r.jobs.Enqueue(c, JobTypeExport, ExportJobPayload{Filter: filter, RequestedBy: pkgs.CurrentUserID(c)})
+		if err != nil {
+			r.log(c).Error("写入导出任务失败", zap.Error(err))
+			return mo.Err[*ExportPlan](pkgs.NewApiError(http.StatusInternalServerError, "导出审计日志失败"))
+		}
+		return mo.Err[*ExportPlan](&pkgs.ApiError{
+			Code:    http.StatusAccepted,
+			Message: "匹配的记录较多，已转为异步导出",
+			Data:    ExportJobRes{JobID: jobID, Rows: rows},
+		})
+	}
+}
+
+// List 分页查询审计日志，支持偏移分页与游标分页
+// 操作人用户名使用标量子查询，游标条件中的 created_at、id 不会与用户表的列混淆。
+func (r *Repository) List(c *gin.Context) func(*ListReq) mo.Result[ListRes] {
+	return func(req *ListReq) mo.Result[ListRes] {
+		upperOrder := pkgs.SortDirection(req.Order)
+		filter := ExportFilter{ActorID: req.ActorID, Entity: req.Entity, EntityID: req.EntityID, Action: req.Action, Method: req.Method}
+		filter.From, filter.To = pkgs.DateRange{CreatedFrom: req.CreatedFrom, CreatedTo: req.CreatedTo}.CreatedBounds(c)
+
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
+		}
+		whereCondition := " WHERE " + filter.where(params)
+		orderClause := ` ORDER BY a.created_at ` + upperOrder + `, a.seq ` + upperOrder + ` LIMIT :limit OFFSET :offset`
+		var total int64
+		if req.CursorPagination.Enabled() {
+			// 游标分页不统计总数
+			whereCondition, orderClause = req.CursorPagination.Keyset(whereCondition, upperOrder, params)
+		} else {
+			query, args, err := r.conn(c).BindNamed(`SELECT COUNT(*) FROM `+r.tables.AuditLog+` a`+whereCondition, params)
+			if err == nil {
+				err = r.conn(c).GetContext(c.Request.Context(), &total, query, args...)
+			}
+			if err != nil {
+				return mo.Err[ListRes](pkgs.DBError(r.log(c), err, "查询审计日志失败"))
+			}
+			if total == 0 {
+				return mo.Ok(ListRes{List: []AuditItem{}})
+			}
+		}
+
+		listQuery := `SELECT a.id, a.created_at, a.seq, a.actor_id, a.action, a.entity, a.entity_id, a.detail, a.ip, a.trace_id, a.method, a.path,
+			(SELECT u.username FROM ` + r.tables.User + ` u WHERE u.id = a.actor_id) AS actor_username
+			FROM ` + r.tables.AuditLog + ` a` + whereCondition + orderClause
+		entities, err := pkgs.NamedQueryAll[auditListEntity](c.Request.Context(), r.conn(c), listQuery, params)
+		if err != nil {
+			return mo.Err[ListRes](pkgs.DBError(r.log(c), err, "查询审计日志失败"))
+		}
+		entities, nextCursor := pkgs.CursorPage(req.CursorPagination, entities, func(e auditListEntity) (time.Time, int64) { return e.CreatedAt, e.Seq })
+
+		list := make([]AuditItem, 0, len(entities))
+		for _, entity := range entities {
+			list = append(list, AuditItem{
+				ID:            entity.ID,
+				CreatedAt:     pkgs.FormatTime(c, entity.CreatedAt),
+				ActorID:       entity.ActorID,
+				ActorUsername: entity.ActorUsername,
+				Action:        entity.Action,
+				Entity:        entity.Entity,
+				EntityID:      entity.EntityID,
+				Method:        entity.Method,
+				Path:          entity.Path,
+				IP:            entity.IP,
+				TraceID:       entity.TraceID,
+				Detail:        entity.Detail,
+			})
+		}
+		return mo.Ok(ListRes{List: list, Total: total, NextCursor: nextCursor})
+	}
+}
+
+// Revert 撤销一次角色授权或用户角色分配：在同一事务中恢复操作前的状态，并写入撤销的审计记录
+// 可撤销的条件：操作与实体见 revertFields，记录中有可撤销字段的变化，尚未被撤销，
+// 且实体的这些字段仍是操作后的状态（之后又被修改过时应先撤销最近的操作）；关键角色的授权变更需要审批，不能直接撤销。
+func (r *Repository) Revert(c *gin.Context) func(*RevertReq) mo.Result[RevertRes] {
+	return func(req *RevertReq) mo.Result[RevertRes] {
+		ctx := c.Request.Context()
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("为撤销操作开启事务失败", zap.Error(err))
+			return mo.Err[RevertRes](pkgs.NewApiError(http.StatusInternalServerError, "撤销操作失败"))
+		}
+		defer tx.Rollback()
+
+		// 锁定被撤销的审计记录，同一记录的并发撤销串行执行，后提交的请求能看到先提交的撤销记录
+		var entry struct {
+			Action   string          `db:"action"`
+			Entity   string          `db:"entity"`
+			EntityID *string         `db:"entity_id"`
+			Detail   json.RawMessage `db:"detail"`
+		}
+		query := `SELECT action, entity, entity_id, detail FROM ` + r.tables.AuditLog + ` WHERE id = $1 FOR UPDATE`
+		if err := tx.GetContext(ctx, &entry, query, req.ID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[RevertRes](pkgs.NewApiError(http.StatusNotFound, "审计记录不存在"))
+			}
+			return mo.Err[RevertRes](pkgs.DBError(r.log(c), err, "撤销操作失败"))
+		}
+		fields := revertFields[entry.Entity][entry.Action]
+		if len(fields) == 0 || entry.EntityID == nil {
+			return mo.Err[RevertRes](pkgs.NewApiError(http.StatusBadRequest, "该操作不支持撤销"))
+		}
+		var detail struct {
+			Changes map[string]FieldChange `json:"changes"`
+		}
+		if err := json.Unmarshal(entry.Detail, &detail); err != nil {
+			r.log(c).Error("解析审计记录失败", zap.String("audit_id", req.ID), zap.Error(err))
+			return mo.Err[RevertRes](pkgs.NewApiError(http.StatusInternalServerError, "撤销操作失败"))
+		}
+		changes := map[string]FieldChange{}
+		for _, field := range fields {
+			if change, ok := detail.Changes[field]; ok {
+				changes[field] = change
+			}
+		}
+		if len(changes) == 0 {
+			return mo.Err[RevertRes](pkgs.NewApiError(http.StatusBadRequest, "该操作没有可撤销的变化"))
+		}
+
+		var reverted bool
+		query = `SELECT EXISTS(SELECT 1 FROM ` + r.tables.AuditLog + ` WHERE action = $1 AND detail->>'reverted_id' = $2)`
+		if err := tx.GetContext(ctx, &reverted, query, ActionRevert, req.ID); err != nil {
+			return mo.Err[RevertRes](pkgs.DBError(r.log(c), err, "撤销操作失败"))
+		}
+		if reverted {
+			return mo.Err[RevertRes](pkgs.NewApiError(http.StatusConflict, "该操作已被撤销"))
+		}
+
+		entityID := *entry.EntityID
+		var apiErr *pkgs.ApiError
+		switch entry.Entity {
+		case pkgs.AuditEntityRole:
+			apiErr = r.revertRole(c, tx, entityID, changes)
+		case pkgs.AuditEntityUser:
+			apiErr = r.revertUser(c, tx, entityID, changes["role_ids"])
+		}
+		if apiErr != nil {
+			return mo.Err[RevertRes](apiErr)
+		}
+
+		// 撤销记录的变化与原操作相反、格式一致，撤销记录本身也可以再撤销
+		inverse := make(map[string]FieldChange, len(changes))
+		for field, change := range changes {
+			inverse[field] = FieldChange{Before: change.After, After: change.Before}
+		}
+		auditID, err := r.audit.RecordTx(c, tx, ActionRevert, entry.Entity, entityID, map[string]any{
+			"reverted_id":     req.ID,
+			"reverted_action": entry.Action,
+			"changes":         inverse,
+		})
+		if err != nil {
+			return mo.Err[RevertRes](pkgs.DBError(r.log(c), err, "撤销操作失败"))
+		}
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交撤销操作事务失败", zap.Error(err))
+			return mo.Err[RevertRes](pkgs.NewApiError(http.StatusInternalServerError, "撤销操作失败"))
+		}
+		return mo.Ok(RevertRes{AuditID: auditID, RevertedID: req.ID, Entity: entry.Entity, EntityID: entityID, Changes: inverse})
+	}
+}
+
+// revertRole 将角色的权限、命名空间授予恢复为操作前的状态，键为权限ID或命名空间，值为授权效果
+func (r *Repository) revertRole(c *gin.Context, tx *sqlx.Tx, roleID string, changes map[string]FieldChange) *pkgs.ApiError {
+	ctx := c.Request.Context()
+	var critical bool
+	if err := tx.GetContext(ctx, &critical, `SELECT critical FROM `+r.tables.Role+` WHERE id = $1 FOR UPDATE`, roleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkgs.NewApiError(http.StatusNotFound, "角色不存在")
+		}
+		return pkgs.DBError(r.log(c), err, "撤销操作失败")
+	}
+	if critical {
+		return pkgs.NewApiError(http.StatusConflict, "关键角色的授权变更需要审批，不能直接撤销")
+	}
+
+	grants := []struct {
+		field, table, column, cast string
+	}{
+		{"permissions", r.tables.RolePermission, "permission_id", "uuid"},
+		{"namespaces", r.tables.RolePermissionNamespace, "namespace", "text"},
+	}
+	for _, grant := range grants {
+		change, ok := changes[grant.field]
+		if !ok {
+			continue
+		}
+		before, after, err := decodeChange[map[string]string](change)
+		if err != nil {
+			r.log(c).Error("解析审计记录失败", zap.String("field", grant.field), zap.Error(err))
+			return pkgs.NewApiError(http.StatusInternalServerError, "撤销操作失败")
+		}
+
+		rows, err := pkgs.QueryAll[struct {
+			Key    string `db:"key"`
+			Effect string `db:"effect"`
+		}](ctx, tx, `SELECT `+grant.column+`::text AS key, effect FROM `+grant.table+` WHERE role_id = $1`, roleID)
+		if err != nil {
+			return pkgs.DBError(r.log(c), err, "撤销操作失败")
+		}
+		current := make(map[string]string, len(rows))
+		for _, row := range rows {
+			current[row.Key] = row.Effect
+		}
+		if !maps.Equal(current, after) {
+			return pkgs.NewApiError(http.StatusConflict, "角色的授权在此之后已被修改，不能撤销")
+		}
+
+		keys := slices.Sorted(maps.Keys(before))
+		effects := make([]string, 0, len(keys))
+		for _, key := range keys {
+			effects = append(effects, before[key])
+		}
+		if grant.field == "permissions" {
+			if missing, err := r.missing(ctx, tx, r.tables.Permission, keys); err != nil {
+				return pkgs.DBError(r.log(c), err, "撤销操作失败")
+			} else if len(missing) > 0 {
+				return pkgs.NewApiError(http.StatusConflict, "权限已被删除，不能撤销: "+strings.Join(missing, ", "))
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+grant.table+` WHERE role_id = $1`, roleID); err != nil {
+			return pkgs.DBError(r.log(c), err, "撤销操作失败")
+		}
+		insertQuery := `INSERT INTO ` + grant.table + ` (role_id, ` + grant.column + `, effect)
+			SELECT $1, u.key, u.effect FROM unnest($2::` + grant.cast + `[], $3::text[]) AS u(key, effect)`
+		if _, err := tx.ExecContext(ctx, insertQuery, roleID, pkgs.PGArray(keys), pkgs.PGArray(effects)); err != nil {
+			return pkgs.DBError(r.log(c), err, "撤销操作失败")
+		}
+	}
+	return nil
+}
+
+// revertUser 将用户的角色恢复为操作前的状态
+func (r *Repository) revertUser(c *gin.Context, tx *sqlx.Tx, userID string, change FieldChange) *pkgs.ApiError {
+	ctx := c.Request.Context()
+	var id string
+	if err := tx.GetContext(ctx, &id, `SELECT id FROM `+r.tables.User+` WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkgs.NewApiError(http.StatusNotFound, "用户不存在")
+		}
+		return pkgs.DBError(r.log(c), err, "撤销操作失败")
+	}
+	before, after, err := decodeChange[[]string](change)
+	if err != nil {
+		r.log(c).Error("解析审计记录失败", zap.String("field", "role_ids"), zap.Error(err))
+		return pkgs.NewApiError(http.StatusInternalServerError, "撤销操作失败")
+	}
+
+	var current []string
+	if err := tx.SelectContext(ctx, &current, `SELECT role_id FROM `+r.tables.UserRole+` WHERE user_id = $1`, userID); err != nil {
+		return pkgs.DBError(r.log(c), err, "撤销操作失败")
+	}
+	slices.Sort(current)
+	slices.Sort(after)
+	if !slices.Equal(current, after) {
+		return pkgs.NewApiError(http.StatusConflict, "用户的角色在此之后已被修改，不能撤销")
+	}
+
+	if missing, err := r.missing(ctx, tx, r.tables.Role, before); err != nil {
+		return pkgs.DBError(r.log(c), err, "撤销操作失败")
+	} else if len(missing) > 0 {
+		return pkgs.NewApiError(http.StatusConflict, "角色已被删除，不能撤销: "+strings.Join(missing, ", "))
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+r.tables.UserRole+` WHERE user_id = $1`, userID); err != nil {
+		return pkgs.DBError(r.log(c), err, "撤销操作失败")
+	}
+	insertQuery := `INSERT INTO ` + r.tables.UserRole + ` (user_id, role_id) SELECT $1, unnest($2::uuid[])`
+	if _, err := tx.ExecContext(ctx, insertQuery, userID, pkgs.PGArray(before)); err != nil {
+		return pkgs.DBError(r.log(c), err, "撤销操作失败")
+	}
+	return nil
+}
+
+// missing 返回 ids 中在表里已不存在的ID
+func (r *Repository) missing(ctx context.Context, tx *sqlx.Tx, table string, ids []string) ([]string, error) {
+	var missing []string
+	query := `SELECT u.id::text FROM unnest($1::uuid[]) AS u(id) WHERE NOT EXISTS (SELECT 1 FROM ` + table + ` t WHERE t.id = u.id)`
+	err := tx.SelectContext(ctx, &missing, query, pkgs.PGArray(ids))
+	return missing, err
+}
+
+// decodeChange 解码字段操作前后的值，一侧没有该字段（null）时为零值
+func decodeChange[T any](change FieldChange) (before, after T, err error) {
+	if len(change.Before) > 0 {
+		if err = json.Unmarshal(change.Before, &before); err != nil {
+			return
+		}
+	}
+	if len(change.After) > 0 {
+		err = json.Unmarshal(change.After, &after)
+	}
+	return
+}
+
+// GetExportJob 查询当前用户发起的异步导出
+func (r *Repository) GetExportJob(c *gin.Context) func(*ExportJobReq) mo.Result[GetExportJobRes] {
+	return func(req *ExportJobReq) mo.Result[GetExportJobRes] {
+		job, apiErr := r.exportJob(c, req.ID)
+		if apiErr != nil {
+			return mo.Err[GetExportJobRes](apiErr)
+		}
+		return mo.Ok(GetExportJobRes{
+			JobID:      job.ID,
+			Status:     job.Status,
+			Error:      job.LastError,
+			Ready:      job.Status == pkgs.JobStatusSucceeded,
+			CreatedAt:  pkgs.FormatTime(c, job.CreatedAt),
+			FinishedAt: pkgs.FormatTimePtr(c, job.FinishedAt),
+		})
+	}
+}
+
+// DownloadExport 读取当前用户发起的、已完成的异步导出文件
+func (r *Repository) DownloadExport(c *gin.Context) func(*ExportJobReq) mo.Result[*DownloadExportRes] {
+	return func(req *ExportJobReq) mo.Result[*DownloadExportRes] {
+		job, apiErr := r.exportJob(c, req.ID)
+		if apiErr != nil {
+			return mo.Err[*DownloadExportRes](apiErr)
+		}
+		if job.Status != pkgs.JobStatusSucceeded {
+			return mo.Err[*DownloadExportRes](pkgs.NewApiError(http.StatusConflict, "导出任务尚未完成"))
+		}
+		key := exportKey(pkgs.TenantFromContext(c), job.ID)
+		data, err := r.storage.Get(c.Request.Context(), key)
+		if errors.Is(err, pkgs.ErrObjectNotFound) {
+			return mo.Err[*DownloadExportRes](pkgs.NewApiError(http.StatusNotFound, "导出文件不存在"))
+		}
+		if err != nil {
+			r.log(c).Error("读取导出文件失败", zap.String("key", key), zap.Error(err))
+			return mo.Err[*DownloadExportRes](pkgs.NewApiError(http.StatusInternalServerError, "下载导出文件失败"))
+		}
+		return mo.Ok(&DownloadExportRes{JobID: job.ID, Data: data})
+	}
+}
+
+// exportJob 查询异步导出任务，只能查询自己发起的任务
+func (r *Repository) exportJob(c *gin.Context, id string) (*pkgs.Job, *pkgs.ApiError) {
+	var job pkgs.Job
+	query := `SELECT ` + pkgs.JobColumns + ` FROM ` + r.tables.AsyncJob + ` WHERE id = $1 AND type = $2`
+	if err := r.conn(c).GetContext(c.Request.Context(), &job, query, id, JobTypeExport); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkgs.NewApiError(http.StatusNotFound, "导出任务不存在")
+		}
+		return nil, pkgs.DBError(r.log(c), err, "查询导出任务失败")
+	}
+	var payload ExportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.RequestedBy != pkgs.CurrentUserID(c) {
+		return nil, pkgs.NewApiError(http.StatusNotFound, "导出任务不存在")
+	}
+	return &job, nil
+}
+
+// runExport 执行异步导出：按任务参数中的条件生成 CSV 写入对象存储
+func (r *Repository) runExport(ctx context.Context, db *sqlx.DB, job *pkgs.Job, logger *zap.Logger) error {
+	var payload ExportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+	var buf bytes.Buffer
+	db, tables := r.reporting.ForTenant(job.Tenant, db)
+	rows, err := r.writeCSV(ctx, db, tables, payload.Filter, &buf)
+	if err != nil {
+		return err
+	}
+	if err := r.storage.Put(ctx, exportKey(job.Tenant, job.ID), buf.Bytes(), "text/csv"); err != nil {
+		return fmt.Errorf("写入导出文件失败: %w", err)
+	}
+	logger.Info("审计日志已导出", zap.Int64("rows", rows), zap.Int("bytes", buf.Len()))
+	return nil
+}
+
+// writeCSV 按条件逐行写出审计日志，返回写出的行数
+// 时间统一为 UTC 的 RFC 3339 格式，同步与异步导出的文件一致；w 实现 http.Flusher 时定期刷新。
+// 同步与异步导出都在报表连接上查询，tables 为该连接上的表名。
+func (r *Repository) writeCSV(ctx context.Context, db *sqlx.DB, tables *pkgs.TableNames, filter ExportFilter, w io.Writer) (int64, error) {
+	params := map[string]any{}
+	query, args, err := db.BindNamed(`SELECT a.id, a.created_at, a.actor_id, a.action, a.entity, a.entity_id, a.detail, a.ip, a.trace_id, a.method, a.path, u.username AS actor_username
+		FROM `+tables.AuditLog+` a LEFT JOIN `+tables.User+` u ON u.id = a.actor_id
+		WHERE `+filter.where(params)+` ORDER BY a.created_at, a.seq`, params)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return 0, err
+	}
+	var count int64
+	for rows.Next() {
+		var entity AuditEntity
+		if err := rows.StructScan(&entity); err != nil {
+			return count, err
+		}
+		record := []string{
+			entity.ID,
+			entity.CreatedAt.UTC().Format(time.RFC3339Nano),
+			deref(entity.ActorID),
+			deref(entity.ActorUsername),
+			entity.Action,
+			entity.Entity,
+			deref(entity.EntityID),
+			deref(entity.IP),
+			deref(entity.TraceID),
+			string(entity.Detail),
+			deref(entity.Method),
+			deref(entity.Path),
+		}
+		for i := range record {
+			record[i] = pkgs.CSVCell(record[i])
+		}
+		if err := cw.Write(record); err != nil {
+			return count, err
+		}
+		count++
+		if count%exportFlushRows == 0 {
+			cw.Flush()
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	cw.Flush()
+	return count, cw.Error()
+}
+
+// resolveFilter 将请求参数解析为绝对的时间范围，未指定截止时间时截止到当前时刻
+func resolveFilter(c *gin.Context, req *ExportReq) ExportFilter {
+	now := time.Now()
+	filter := ExportFilter{ActorID: req.ActorID, Entity: req.Entity, EntityID: req.EntityID, Action: req.Action}
+	if req.Days > 0 {
+		filter.From, filter.To = now.AddDate(0, 0, -req.Days), now
+		return filter
+	}
+	filter.From, filter.To = pkgs.DateRange{CreatedFrom: req.CreatedFrom, CreatedTo: req.CreatedTo}.CreatedBounds(c)
+	if filter.To.IsZero() {
+		filter.To = now
+	}
+	return filter
+}
+
+// where 返回导出条件的查询条件（审计日志表别名为 a），参数写入 params
+func (f ExportFilter) where(params map[string]any) string {
+	var clauses []string
+	if !f.From.IsZero() {
+		clauses = append(clauses, "a.created_at >= :from")
+		params["from"] = f.From
+	}
+	if !f.To.IsZero() {
+		clauses = append(clauses, "a.created_at < :to")
+		params["to"] = f.To
+	}
+	if f.ActorID != "" {
+		clauses = append(clauses, "a.actor_id = :actor_id")
+		params["actor_id"] = f.ActorID
+	}
+	if f.Entity != "" {
+		clauses = append(clauses, "a.entity = :entity")
+		params["entity"] = f.Entity
+	}
+	if f.EntityID != "" {
+		clauses = append(clauses, "a.entity_id = :entity_id")
+		params["entity_id"] = f.EntityID
+	}
+	if f.Action != "" {
+		clauses = append(clauses, "a.action = :action")
+		params["action"] = f.Action
+	}
+	if f.Method != "" {
+		clauses = append(clauses, "a.method = :method")
+		params["method"] = f.Method
+	}
+	if len(clauses) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// exportKey 异步导出文件在对象存储中的 key，租户的导出按租户分目录
+func exportKey(tenant, id string) string {
+	if tenant == "" {
+		return "audit/exports/" + id + ".csv"
+	}
+	return "tenants/" + tenant + "/audit/exports/" + id + ".csv"
+}
+
+// exportFilename 下载时的文件名，包含导出的时间范围
+func exportFilename(filter ExportFilter) string {
+	return "audit-" + filter.From.UTC().Format("20060102") + "-" + filter.To.UTC().Format("20060102") + ".csv"
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"go-pg-demo/pkgs"
+)
+
+// JobTypeExport 审计日志异步导出任务
+const JobTypeExport = "audit.export"
+
+// 保存筛选预设使用的列表视图实体
+const presetEntity = "audit"
+
+// 数据库表 audit_log 的表结构
+type AuditEntity struct {
+	ID        string          `db:"id" label:"审计记录ID"`
+	CreatedAt time.Time       `db:"created_at" label:"创建时间"`
+	ActorID   *string         `db:"actor_id" label:"操作人ID"`
+	Action    string          `db:"action" label:"操作"`
+	Entity    string          `db:"entity" label:"实体"`
+	EntityID  *string         `db:"entity_id" label:"实体ID"`
+	Detail    json.RawMessage `db:"detail" label:"详情"`
+	IP        *string         `db:"ip" label:"来源IP"`
+	TraceID   *string         `db:"trace_id" label:"请求ID"`
+	Method    *string         `db:"method" label:"请求方法"`
+	Path      *string         `db:"path" label:"请求路由"`
+	// 关联 iacc_user 查询的操作人用户名，用户已删除时为空
+	ActorUsername *string `db:"actor_username" label:"操作人用户名"`
+}
+
+// auditListEntity 列表查询的一行，seq 用于游标分页
+type auditListEntity struct {
+	AuditEntity
+	Seq int64 `db:"seq"`
+}
+
+// 导出审计日志的请求参数
+type ExportReq struct {
+	CreatedFrom string `form:"createdFrom" label:"创建时间起"`
+	CreatedTo   string `form:"createdTo" label:"创建时间止"`
+	// 最近多少天，与 createdFrom、createdTo 二选一，便于保存为定期导出的预设
+	Days     int    `form:"days" validate:"omitempty,min=1,max=3660" label:"最近天数"`
+	ActorID  string `form:"actorId" validate:"omitempty,uuid" label:"操作人ID"`
+	Entity   string `form:"entity" validate:"omitempty,oneof=role role_change permission user" label:"实体"`
+	EntityID string `form:"entityId" validate:"omitempty,max=100" label:"实体ID"`
+	Action   string `form:"action" validate:"omitempty,max=50" label:"操作"`
+	// 筛选预设（entity 为 audit 的保存视图），请求中未传入的参数使用预设中的值
+	ViewID string `form:"viewId" validate:"omitempty,uuid" label:"筛选预设ID"`
+}
+
+func exportRule(req *ExportReq) []pkgs.Violation {
+	var violations []pkgs.Violation
+	if req.Days > 0 && (req.CreatedFrom != "" || req.CreatedTo != "") {
+		violations = append(violations, pkgs.Violation{Field: "days", Message: "最近天数不能与创建时间起止同时使用"})
+	}
+	if req.Days == 0 && req.CreatedFrom == "" {
+		violations = append(violations, pkgs.Violation{Field: "createdFrom", Message: "需要提供创建时间起或最近天数"})
+	}
+	return append(violations, pkgs.DateRange{CreatedFrom: req.CreatedFrom, CreatedTo: req.CreatedTo}.Violations()...)
+}
+
+// 查询审计日志的请求参数，按创建时间排序
+type ListReq struct {
+	pkgs.Pagination
+	pkgs.CursorPagination
+	CreatedFrom string `form:"createdFrom" label:"创建时间起"`
+	CreatedTo   string `form:"createdTo" label:"创建时间止"`
+	ActorID     string `form:"actorId" validate:"omitempty,uuid" label:"操作人ID"`
+	Entity      string `form:"entity" validate:"omitempty,oneof=role role_change permission user" label:"实体"`
+	EntityID    string `form:"entityId" validate:"omitempty,max=100" label:"实体ID"`
+	Action      string `form:"action" validate:"omitempty,max=50" label:"操作"`
+	Method      string `form:"method" validate:"omitempty,oneof=POST PUT PATCH DELETE" label:"请求方法"`
+	Order       string `form:"order,default=desc" validate:"sort_order" label:"排序顺序"`
+}
+
+func listRule(req *ListReq) []pkgs.Violation {
+	violations := pkgs.DateRange{CreatedFrom: req.CreatedFrom, CreatedTo: req.CreatedTo}.Violations()
+	return append(violations, req.CursorPagination.Violations("created_at")...)
+}
+
+// 审计日志列表项，detail 中的 changes 为操作前后发生变化的字段，删除时为 before，创建时为 after
+type AuditItem struct {
+	ID            string          `json:"id" label:"审计记录ID"`
+	CreatedAt     string          `json:"created_at" label:"创建时间"`
+	ActorID       *string         `json:"actor_id" label:"操作人ID"`
+	ActorUsername *string         `json:"actor_username" label:"操作人用户名"`
+	Action        string          `json:"action" label:"操作"`
+	Entity        string          `json:"entity" label:"实体"`
+	EntityID      *string         `json:"entity_id" label:"实体ID"`
+	Method        *string         `json:"method" label:"请求方法"`
+	Path          *string         `json:"path" label:"请求路由"`
+	IP            *string         `json:"ip" label:"来源IP"`
+	TraceID       *string         `json:"trace_id" label:"请求ID"`
+	Detail        json.RawMessage `json:"detail" swaggertype:"object" label:"详情"`
+}
+
+// 查询审计日志的响应体，游标分页时不统计总数（total 为 0）
+type ListRes struct {
+	List       []AuditItem `json:"list"`
+	Total      int64       `json:"total"`
+	NextCursor string      `json:"next_cursor,omitempty" label:"下一页游标"`
+}
+
+// ExportFilter 解析后的导出条件，时间范围为 [From, To)，异步导出时原样保存在任务参数中
+// 查询列表时也使用，From、To 为零值时不限制。
+type ExportFilter struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	ActorID  string    `json:"actor_id,omitempty"`
+	Entity   string    `json:"entity,omitempty"`
+	EntityID string    `json:"entity_id,omitempty"`
+	Action   string    `json:"action,omitempty"`
+	Method   string    `json:"method,omitempty"`
+}
+
+// ExportPlan 同步导出的条件与匹配的行数
+type ExportPlan struct {
+	Filter ExportFilter
+	Rows   int64
+}
+
+// 异步导出任务的参数
+type ExportJobPayload struct {
+	Filter      ExportFilter `json:"filter"`
+	RequestedBy string       `json:"requested_by"`
+}
+
+// 转为异步导出时的响应数据（业务码 202）
+type ExportJobRes struct {
+	JobID string `json:"job_id" label:"任务ID"`
+	Rows  int64  `json:"rows" label:"匹配的行数"`
+}
+
+// 查询异步导出的请求参数
+type ExportJobReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"任务ID"`
+}
+
+// 查询异步导出的响应
+type GetExportJobRes struct {
+	JobID  string  `json:"job_id" label:"任务ID"`
+	Status string  `json:"status" label:"任务状态"`
+	Error  *string `json:"error,omitempty" label:"失败原因"`
+	// 任务成功后可通过 GET /audit/export/{id}/download 下载
+	Ready      bool    `json:"ready" label:"是否可以下载"`
+	CreatedAt  string  `json:"created_at" label:"创建时间"`
+	FinishedAt *string `json:"finished_at,omitempty" label:"完成时间"`
+}
+
+// 下载异步导出的结果
+type DownloadExportRes struct {
+	JobID string
+	Data  []byte
+}
+
+// ActionRevert 撤销操作的审计记录的操作名，detail.reverted_id 为被撤销的审计记录ID
+const ActionRevert = "revert"
+
+// revertFields 可撤销的操作及其可撤销的字段（detail.changes 中的键），按实体类型划分
+// 撤销记录本身也可以再撤销，即重做被撤销的操作。
+var revertFields = map[string]map[string][]string{
+	pkgs.AuditEntityRole: {
+		"assign_permissions": {"permissions", "namespaces"},
+		"sync_permissions":   {"permissions", "namespaces"},
+		ActionRevert:         {"permissions", "namespaces"},
+	},
+	pkgs.AuditEntityUser: {
+		"assign_roles": {"role_ids"},
+		ActionRevert:   {"role_ids"},
+	},
+}
+
+// 撤销审计记录的请求参数
+type RevertReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"审计记录ID"`
+}
+
+// FieldChange 字段在操作前后的值，与审计记录 detail.changes 中的格式一致
+type FieldChange struct {
+	Before json.RawMessage `json:"before" swaggertype:"object" label:"操作前"`
+	After  json.RawMessage `json:"after" swaggertype:"object" label:"操作后"`
+}
+
+// 撤销审计记录的响应，changes 为撤销对实体做出的变化
+type RevertRes struct {
+	AuditID    string                 `json:"audit_id" label:"撤销记录ID"`
+	RevertedID string                 `json:"reverted_id" label:"被撤销的审计记录ID"`
+	Entity     string                 `json:"entity" label:"实体"`
+	EntityID   string                 `json:"entity_id" label:"实体ID"`
+	Changes    map[string]FieldChange `json:"changes" label:"变化"`
+}
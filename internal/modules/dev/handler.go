@@ -0,0 +1,107 @@
+// Package dev API.
+//
+// 开发与测试环境使用的API接口。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package dev
+
+import (
+	"go-pg-demo/internal/modules/iacc/auth"
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	cache      *pkgs.PermissionCache
+	repository *Repository
+}
+
+func NewDevHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, config *pkgs.Config, pool *pkgs.TenantPool, tables *pkgs.TableNames, ids *pkgs.IDGenerator, events *pkgs.SecurityEvents, blacklist *pkgs.TokenBlacklist, cache *pkgs.PermissionCache, hasher *pkgs.PasswordHasher, settings *pkgs.Settings) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, factoryRule(config.DevFactory.MaxCount))
+	pkgs.RegisterRule(validator, mintTokenRule(config.DevToken.MaxExpire))
+
+	return &Handler{
+		db:        db,
+		logger:    logger,
+		validator: validator,
+		cache:     cache,
+		repository: &Repository{
+			db:       db,
+			logger:   logger,
+			pool:     pool,
+			tables:   tables,
+			ids:      ids,
+			modules:  config.Modules,
+			settings: settings,
+			auth:     auth.NewRepository(db, logger, config, tables, pool, ids, events, blacklist, hasher, settings),
+			hasher:   hasher,
+		},
+	}
+}
+
+// Factory 批量生成测试数据
+//
+//	@Summary  批量生成测试数据
+//	@Description  一次请求生成指定数量的用户、角色、模板（仿真的姓名、手机号、邮箱等），用于压测与演示环境；同时生成用户和角色时每个用户随机分配一个本批次的角色，模板随机指定本批次的用户为所有者。只有配置 dev_factory.enabled 时才注册该接口，生产环境（server.mode 为 release）不允许开启
+//	@Tags   dev
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  FactoryReq  true  "生成测试数据请求参数"
+//	@Success  200 {object}  pkgs.Response{data=FactoryRes}  "生成成功，返回批次号、用户密码与生成数据的ID"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/dev/factory"}
+//	@Router   /dev/factory [post]
+func (h *Handler) Factory(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[FactoryReq](c),
+		result.FlatMap(pkgs.ValidateV2[FactoryReq](h.validator)),
+		result.FlatMap(h.repository.Generate(c)),
+	).Match(
+		pkgs.HandleSuccess[FactoryRes](c),
+		pkgs.HandleError[FactoryRes](c),
+	)
+}
+
+// MintToken 签发测试令牌
+//
+//	@Summary  签发测试令牌
+//	@Description  不经过登录流程为指定用户（user_id 或 username）或一组接口权限签发访问令牌与刷新令牌，供压测脚本（k6、vegeta 等）获取凭证。按权限集合签发时使用该集合专属的测试用户（用户名为 ci_ 加集合摘要），首次签发时创建用户、同名角色以及数据库中没有的接口权限。只有配置 dev_token.enabled 时才注册该接口，生产环境（server.mode 为 release）不允许开启
+//	@Tags   dev
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  MintTokenReq  true  "签发测试令牌请求参数"
+//	@Success  200 {object}  pkgs.Response{data=MintTokenRes}  "签发成功"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response           "用户不存在"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/dev/token"}
+//	@Router   /dev/token [post]
+func (h *Handler) MintToken(c *gin.Context) {
+	// 首次为权限集合创建用户时可能新增接口权限记录，需要使权限缓存失效
+	result.Pipe3(
+		pkgs.BindJSON[MintTokenReq](c),
+		result.FlatMap(pkgs.ValidateV2[MintTokenReq](h.validator)),
+		result.FlatMap(h.repository.Mint(c)),
+		result.Map(pkgs.InvalidatePermissionCache[MintTokenRes](c, h.cache)),
+	).Match(
+		pkgs.HandleSuccess[MintTokenRes](c),
+		pkgs.HandleError[MintTokenRes](c),
+	)
+}
@@ -0,0 +1,428 @@
+package dev
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-pg-demo/internal/modules/iacc/auth"
+	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db      *sqlx.DB
+	logger  *zap.Logger
+	pool    *pkgs.TenantPool
+	tables  *pkgs.TableNames
+	ids     *pkgs.IDGenerator
+	modules pkgs.ModulesConfig
+	// 未指定有效期时测试令牌使用运行时设置中的默认有效期
+	settings *pkgs.Settings
+	auth     *auth.Repository
+	hasher   *pkgs.PasswordHasher
+}
+
+// batchConn 返回批量写入使用的数据库连接
+func (r *Repository) batchConn(c *gin.Context) *sqlx.DB {
+	return r.pool.BatchDB(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+// Generate 在同一事务内生成用户、角色、模板，同时生成用户和角色时每个用户随机分配一个本批次的角色
+// 用户名或手机号与已有数据冲突的用户直接跳过，返回的 ID 只包含实际写入的数据
+func (r *Repository) Generate(c *gin.Context) func(*FactoryReq) mo.Result[FactoryRes] {
+	return func(req *FactoryReq) mo.Result[FactoryRes] {
+		if req.Templates > 0 && !r.modules.Template.Enabled {
+			return mo.Err[FactoryRes](pkgs.NewApiError(http.StatusBadRequest, "模板模块已关闭，不能生成模板"))
+		}
+		res := FactoryRes{
+			Batch:     uuid.NewString()[:6],
+			Password:  req.Password,
+			Users:     []string{},
+			Roles:     []string{},
+			Templates: []string{},
+		}
+		if res.Password == "" {
+			res.Password = defaultPassword
+		}
+
+		ctx := c.Request.Context()
+		tx, err := r.batchConn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[FactoryRes](pkgs.NewApiError(http.StatusInternalServerError, "生成测试数据失败"))
+		}
+		defer tx.Rollback()
+
+		if err = r.insertUsers(ctx, tx, req.Users, &res); err == nil {
+			if err = r.insertRoles(ctx, tx, req.Roles, &res); err == nil {
+				if err = r.assignRoles(ctx, tx, &res); err == nil {
+					err = r.insertTemplates(ctx, tx, req.Templates, &res)
+				}
+			}
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			r.log(c).Error("生成测试数据失败", zap.String("batch", res.Batch), zap.Error(err))
+			return mo.Err[FactoryRes](pkgs.NewApiError(http.StatusInternalServerError, "生成测试数据失败"))
+		}
+
+		r.log(c).Info("已生成测试数据", zap.String("batch", res.Batch),
+			zap.Int("users", len(res.Users)), zap.Int("roles", len(res.Roles)), zap.Int("templates", len(res.Templates)))
+		return mo.Ok(res)
+	}
+}
+
+func (r *Repository) insertUsers(ctx context.Context, tx *sqlx.Tx, count int, res *FactoryRes) error {
+	if count == 0 {
+		return nil
+	}
+	// 同一批次的用户使用相同的密码，只哈希一次
+	password, err := r.hasher.Hash(res.Password)
+	if err != nil {
+		return err
+	}
+	columns, values := r.ids.Insert("username", "phone", "phone_hash", "password", "profile", "email_hash")
+	query := `INSERT INTO ` + r.tables.User + ` (` + columns + `) VALUES (` + values + `) ON CONFLICT DO NOTHING RETURNING id`
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := range count {
+		row := fakeUser(res.Batch, i, password)
+		if err := r.ids.Assign(&row.ID); err != nil {
+			return err
+		}
+		if err := stmt.GetContext(ctx, &row.ID, row); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return err
+		}
+		res.Users = append(res.Users, row.ID)
+	}
+	return nil
+}
+
+func (r *Repository) insertRoles(ctx context.Context, tx *sqlx.Tx, count int, res *FactoryRes) error {
+	if count == 0 {
+		return nil
+	}
+	columns, values := r.ids.Insert("name", "description")
+	query := `INSERT INTO ` + r.tables.Role + ` (` + columns + `) VALUES (` + values + `) RETURNING id`
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := range count {
+		row := fakeRole(res.Batch, i)
+		if err := r.ids.Assign(&row.ID); err != nil {
+			return err
+		}
+		if err := stmt.GetContext(ctx, &row.ID, row); err != nil {
+			return err
+		}
+		res.Roles = append(res.Roles, row.ID)
+	}
+	return nil
+}
+
+// assignRoles 为本批次的每个用户随机分配一个本批次的角色
+func (r *Repository) assignRoles(ctx context.Context, tx *sqlx.Tx, res *FactoryRes) error {
+	if len(res.Users) == 0 || len(res.Roles) == 0 {
+		return nil
+	}
+	roleIDs := make([]string, len(res.Users))
+	for i := range res.Users {
+		roleIDs[i] = res.Roles[rand.IntN(len(res.Roles))]
+	}
+	query := `INSERT INTO ` + r.tables.UserRole + ` (user_id, role_id) SELECT * FROM UNNEST($1::uuid[], $2::uuid[])`
+	_, err := tx.ExecContext(ctx, query, pkgs.PGArray(res.Users), pkgs.PGArray(roleIDs))
+	return err
+}
+
+// insertTemplates 生成模板，同时生成了用户时随机指定本批次的用户为所有者
+func (r *Repository) insertTemplates(ctx context.Context, tx *sqlx.Tx, count int, res *FactoryRes) error {
+	if count == 0 {
+		return nil
+	}
+	columns, values := r.ids.Insert("name", "num", "owner_id")
+	query := `INSERT INTO ` + r.tables.Template + ` (` + columns + `) VALUES (` + values + `) RETURNING id`
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := range count {
+		row := fakeTemplate(res.Batch, i)
+		if len(res.Users) > 0 {
+			row.OwnerID = &res.Users[rand.IntN(len(res.Users))]
+		}
+		if err := r.ids.Assign(&row.ID); err != nil {
+			return err
+		}
+		if err := stmt.GetContext(ctx, &row.ID, row); err != nil {
+			return err
+		}
+		res.Templates = append(res.Templates, row.ID)
+	}
+	return nil
+}
+
+// Mint 不经过登录流程为指定用户或权限集合签发令牌
+func (r *Repository) Mint(c *gin.Context) func(*MintTokenReq) mo.Result[MintTokenRes] {
+	return func(req *MintTokenReq) mo.Result[MintTokenRes] {
+		var res MintTokenRes
+		var apiErr *pkgs.ApiError
+		switch {
+		case len(req.Permissions) > 0:
+			res.UserID, res.Username, apiErr = r.permissionUser(c, req.Permissions)
+		default:
+			res.UserID, res.Username, apiErr = r.findUser(c, req.UserID, req.Username)
+		}
+		if apiErr != nil {
+			return mo.Err[MintTokenRes](apiErr)
+		}
+
+		accessTTL := r.settings.AccessTokenExpire()
+		if req.ExpiresIn > 0 {
+			accessTTL = time.Duration(req.ExpiresIn) * time.Second
+		}
+		tokens, err := r.auth.IssueTokens(c, res.UserID, accessTTL, r.settings.RefreshTokenExpire())
+		if err != nil {
+			return mo.Err[MintTokenRes](pkgs.DBError(r.log(c), err, "签发测试令牌失败"))
+		}
+		res.AccessToken, res.RefreshToken, res.ExpiresIn = tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresIn
+
+		r.log(c).Info("已签发测试令牌", zap.String("user_id", res.UserID), zap.String("issuer", pkgs.CurrentUserID(c)))
+		return mo.Ok(res)
+	}
+}
+
+// findUser 按用户ID或用户名查询用户
+func (r *Repository) findUser(c *gin.Context, userID, username string) (string, string, *pkgs.ApiError) {
+	var entity struct {
+		ID       string `db:"id"`
+		Username string `db:"username"`
+	}
+	query := `SELECT id, username FROM ` + r.tables.User + ` WHERE id = $1`
+	arg := userID
+	if userID == "" {
+		query = `SELECT id, username FROM ` + r.tables.User + ` WHERE username = $1`
+		arg = username
+	}
+	if err := r.pool.DB(c).GetContext(c.Request.Context(), &entity, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", pkgs.NewApiError(http.StatusNotFound, "用户不存在")
+		}
+		r.log(c).Error("查询用户失败", zap.Error(err))
+		return "", "", pkgs.NewApiError(http.StatusInternalServerError, "签发测试令牌失败")
+	}
+	return entity.ID, entity.Username, nil
+}
+
+// permissionUser 返回权限集合专属的测试用户，不存在时创建
+// 用户名与角色名由权限集合的摘要生成（ci_ 加 12 位十六进制），用户只拥有同名角色，角色只拥有集合中的接口权限；
+// 数据库中没有的接口权限一并创建。同一集合的并发请求通过事务级咨询锁串行执行。
+func (r *Repository) permissionUser(c *gin.Context, permissions []string) (string, string, *pkgs.ApiError) {
+	failed := pkgs.NewApiError(http.StatusInternalServerError, "签发测试令牌失败")
+	set := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		method, path, _ := parsePermission(permission)
+		set = append(set, method+" "+path)
+	}
+	slices.Sort(set)
+	set = slices.Compact(set)
+	username := "ci_" + digest(strings.Join(set, "\n"))
+
+	ctx := c.Request.Context()
+	tx, err := r.pool.DB(c).BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(c).Error("开启事务失败", zap.Error(err))
+		return "", "", failed
+	}
+	defer tx.Rollback()
+
+	userID, err := r.ensurePermissionUser(ctx, tx, username, set)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		r.log(c).Error("创建测试用户失败", zap.String("username", username), zap.Error(err))
+		return "", "", failed
+	}
+	return userID, username, nil
+}
+
+func (r *Repository) ensurePermissionUser(ctx context.Context, tx *sqlx.Tx, username string, set []string) (string, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, r.tables.User+":"+username); err != nil {
+		return "", err
+	}
+	var userID string
+	err := tx.GetContext(ctx, &userID, `SELECT id FROM `+r.tables.User+` WHERE username = $1`, username)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return userID, err
+	}
+
+	// 查找或创建集合中的接口权限
+	permissionIDs := make([]string, 0, len(set))
+	for _, permission := range set {
+		method, path, _ := parsePermission(permission)
+		var id string
+		query := `SELECT id FROM ` + r.tables.Permission + ` WHERE type = 'api' AND metadata->>'method' = $1 AND metadata->>'path' = $2 ORDER BY created_at LIMIT 1`
+		err := tx.GetContext(ctx, &id, query, method, path)
+		if errors.Is(err, sql.ErrNoRows) {
+			id, err = r.insertPermission(ctx, tx, method, path)
+		}
+		if err != nil {
+			return "", err
+		}
+		permissionIDs = append(permissionIDs, id)
+	}
+
+	role := roleRow{Name: username, Description: "测试令牌使用的权限集合：" + strings.Join(set, ", ")}
+	if err := r.ids.Assign(&role.ID); err != nil {
+		return "", err
+	}
+	columns, values := r.ids.Insert("name", "description")
+	if err := namedGet(ctx, tx, &role.ID, `INSERT INTO `+r.tables.Role+` (`+columns+`) VALUES (`+values+`) RETURNING id`, role); err != nil {
+		return "", err
+	}
+	query := `INSERT INTO ` + r.tables.RolePermission + ` (role_id, permission_id) SELECT $1, UNNEST($2::uuid[])`
+	if _, err := tx.ExecContext(ctx, query, role.ID, pkgs.PGArray(permissionIDs)); err != nil {
+		return "", err
+	}
+
+	// 测试用户的密码随机生成且不返回，只能通过签发接口获取令牌
+	password, err := r.hasher.Hash(uuid.NewString())
+	if err != nil {
+		return "", err
+	}
+	row := userRow{Username: username, Password: password}
+	if err := r.ids.Assign(&row.ID); err != nil {
+		return "", err
+	}
+	columns, values = r.ids.Insert("username", "password")
+	if err := namedGet(ctx, tx, &row.ID, `INSERT INTO `+r.tables.User+` (`+columns+`) VALUES (`+values+`) RETURNING id`, row); err != nil {
+		return "", err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO `+r.tables.UserRole+` (user_id, role_id) VALUES ($1, $2)`, row.ID, role.ID)
+	return row.ID, err
+}
+
+func (r *Repository) insertPermission(ctx context.Context, tx *sqlx.Tx, method, path string) (string, error) {
+	metadata, err := json.Marshal(map[string]string{"method": method, "path": path})
+	if err != nil {
+		return "", err
+	}
+	row := permissionRow{Name: "ci_" + digest(method+" "+path), Metadata: string(metadata)}
+	if namespace := pkgs.DefaultPermissionNamespace("", path); namespace != "" {
+		row.Namespace = &namespace
+	}
+	if err := r.ids.Assign(&row.ID); err != nil {
+		return "", err
+	}
+	columns, values := r.ids.Insert("name", "metadata", "namespace")
+	query := `INSERT INTO ` + r.tables.Permission + ` (` + columns + `, type) VALUES (` + values + `, 'api') RETURNING id`
+	err = namedGet(ctx, tx, &row.ID, query, row)
+	return row.ID, err
+}
+
+// namedGet 执行带命名参数的语句并读取返回的一行
+func namedGet(ctx context.Context, tx *sqlx.Tx, dest any, query string, arg any) error {
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	return stmt.GetContext(ctx, dest, arg)
+}
+
+// digest 返回内容摘要的前 12 位十六进制
+func digest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// 生成姓名使用的姓氏与名字（拼音用于用户名和邮箱）
+var (
+	surnames   = []string{"wang", "li", "zhang", "liu", "chen", "yang", "huang", "zhao", "wu", "zhou", "xu", "sun", "ma", "zhu", "hu", "guo", "he", "lin", "luo", "gao"}
+	givenNames = []string{"wei", "fang", "na", "min", "jing", "li", "qiang", "lei", "jun", "yang", "yong", "yan", "jie", "tao", "ming", "chao", "xia", "ping", "gang", "hui"}
+	// 国内手机号号段
+	phonePrefixes = []string{"130", "131", "132", "135", "136", "137", "138", "139", "150", "151", "152", "158", "159", "176", "177", "180", "182", "186", "188", "199"}
+	emailDomains  = []string{"example.com", "example.net", "example.org"}
+
+	departments = []string{"财务部", "人力资源部", "市场部", "销售部", "研发部", "运维部", "客服部", "法务部", "采购部", "行政部"}
+	positions   = []string{"管理员", "审核员", "专员", "经理", "主管", "访客", "实习生", "数据分析员"}
+
+	templateSubjects = []string{"季度销售报表", "员工入职", "采购申请", "费用报销", "合同审批", "客户回访", "周报", "会议纪要", "项目立项", "库存盘点"}
+	templateKinds    = []string{"模板", "表单", "流程", "清单"}
+)
+
+// fakeUser 生成用户，用户名长度不超过 20（iacc_user.username 为 VARCHAR(20)），password 为已哈希的密码
+// 用户名由拼音姓名、批次号与序号组成，同一批次内不会重复
+func fakeUser(batch string, i int, password string) userRow {
+	name := pick(surnames) + pick(givenNames)
+	if len(name) > 9 {
+		name = name[:9]
+	}
+	username := name + "_" + batch + strconv.FormatInt(int64(i), 36)
+	phone := pick(phonePrefixes) + fmt.Sprintf("%08d", rand.IntN(100000000))
+	email := username + "@" + pick(emailDomains)
+	profile := user.Profile{Email: &email}
+	return userRow{
+		Username:  username,
+		Phone:     pkgs.EncryptedString(phone),
+		PhoneHash: pkgs.BlindIndex(phone),
+		Password:  password,
+		Profile:   profile,
+		EmailHash: profile.EmailHash(),
+	}
+}
+
+func fakeRole(batch string, i int) roleRow {
+	department, position := pick(departments), pick(positions)
+	return roleRow{
+		Name:        fmt.Sprintf("%s%s-%s-%d", department, position, batch, i),
+		Description: fmt.Sprintf("%s的%s，负责本部门日常业务（测试数据，批次 %s）", department, position, batch),
+	}
+}
+
+func fakeTemplate(batch string, i int) templateRow {
+	return templateRow{
+		Name: fmt.Sprintf("%s%s-%s-%d", pick(templateSubjects), pick(templateKinds), batch, i),
+		Num:  rand.IntN(1000) + 1,
+	}
+}
+
+func pick(values []string) string {
+	return values[rand.IntN(len(values))]
+}
@@ -0,0 +1,142 @@
+package dev
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/pkgs"
+)
+
+// 未指定密码时生成用户使用的密码
+const defaultPassword = "Passw0rd!"
+
+// 生成测试数据的请求体，每类数据的数量不能超过 dev_factory.max_count
+type FactoryReq struct {
+	Users     int `json:"users" validate:"min=0" label:"用户数量"`
+	Roles     int `json:"roles" validate:"min=0" label:"角色数量"`
+	Templates int `json:"templates" validate:"min=0" label:"模板数量"`
+	// 生成用户的登录密码，为空时使用 Passw0rd!
+	Password string `json:"password,omitempty" validate:"omitempty,min=6,max=64" label:"用户密码"`
+}
+
+// factoryRule 返回生成数量的校验规则
+func factoryRule(maxCount int) pkgs.Rule[FactoryReq] {
+	return func(req *FactoryReq) []pkgs.Violation {
+		var violations []pkgs.Violation
+		if req.Users+req.Roles+req.Templates == 0 {
+			violations = append(violations, pkgs.Violation{Field: "users", Message: "至少生成一条数据"})
+		}
+		counts := []struct {
+			field string
+			count int
+		}{{"users", req.Users}, {"roles", req.Roles}, {"templates", req.Templates}}
+		for _, item := range counts {
+			if item.count > maxCount {
+				violations = append(violations, pkgs.Violation{Field: item.field, Message: "单次最多生成 " + strconv.Itoa(maxCount) + " 条"})
+			}
+		}
+		return violations
+	}
+}
+
+// 生成测试数据的响应体
+type FactoryRes struct {
+	// 本次生成的批次号，出现在生成数据的名称中，便于识别和清理
+	Batch string `json:"batch"`
+	// 生成用户的登录密码
+	Password  string   `json:"password"`
+	Users     []string `json:"users"`
+	Roles     []string `json:"roles"`
+	Templates []string `json:"templates"`
+}
+
+// 生成的用户，写入 iacc_user
+type userRow struct {
+	ID        string               `db:"id"`
+	Username  string               `db:"username"`
+	Phone     pkgs.EncryptedString `db:"phone"`
+	PhoneHash string               `db:"phone_hash"`
+	Password  string               `db:"password"`
+	Profile   user.Profile         `db:"profile"`
+	EmailHash *string              `db:"email_hash"`
+}
+
+// 生成的角色，写入 iacc_role
+type roleRow struct {
+	ID          string `db:"id"`
+	Name        string `db:"name"`
+	Description string `db:"description"`
+}
+
+// 生成的模板，写入 template
+type templateRow struct {
+	ID      string  `db:"id"`
+	Name    string  `db:"name"`
+	Num     int     `db:"num"`
+	OwnerID *string `db:"owner_id"`
+}
+
+// 签发测试令牌的请求体，user_id、username、permissions 三选一
+type MintTokenReq struct {
+	UserID   string `json:"user_id" validate:"omitempty,uuid" label:"用户ID"`
+	Username string `json:"username" label:"用户名"`
+	// 接口权限，格式为 "GET /v1/user/list"；按权限集合签发时使用该集合专属的测试用户，同一集合重复签发复用同一用户
+	Permissions []string `json:"permissions" validate:"omitempty,max=200" label:"接口权限"`
+	// 访问令牌有效期（秒），为空时使用 jwt.access_token_expire，不能超过 dev_token.max_expire
+	ExpiresIn int64 `json:"expires_in" validate:"omitempty,min=1" label:"有效期"`
+}
+
+// mintTokenRule 返回签发测试令牌的校验规则
+func mintTokenRule(maxExpire time.Duration) pkgs.Rule[MintTokenReq] {
+	return func(req *MintTokenReq) []pkgs.Violation {
+		var violations []pkgs.Violation
+		selectors := 0
+		for _, set := range []bool{req.UserID != "", req.Username != "", len(req.Permissions) > 0} {
+			if set {
+				selectors++
+			}
+		}
+		if selectors != 1 {
+			violations = append(violations, pkgs.Violation{Field: "user_id", Message: "user_id、username、permissions 必须且只能指定一个"})
+		}
+		for i, permission := range req.Permissions {
+			if _, _, ok := parsePermission(permission); !ok {
+				violations = append(violations, pkgs.Violation{Field: "permissions", Message: "接口权限格式应为 \"GET /v1/user/list\"", Indexes: []int{i}})
+			}
+		}
+		if time.Duration(req.ExpiresIn)*time.Second > maxExpire {
+			violations = append(violations, pkgs.Violation{Field: "expires_in", Message: "有效期不能超过 " + maxExpire.String()})
+		}
+		return violations
+	}
+}
+
+// parsePermission 解析 "METHOD /path" 格式的接口权限
+func parsePermission(permission string) (string, string, bool) {
+	method, path, ok := strings.Cut(strings.TrimSpace(permission), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || method != strings.ToUpper(method) || !strings.HasPrefix(path, "/") || strings.ContainsAny(path, " \t") {
+		return "", "", false
+	}
+	return method, path, true
+}
+
+// 签发测试令牌的响应体
+type MintTokenRes struct {
+	UserID       string `json:"user_id"`
+	Username     string `json:"username"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	// 访问令牌有效期（秒）
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// 按权限集合签发时创建的接口权限，写入 iacc_permission
+type permissionRow struct {
+	ID        string  `db:"id"`
+	Name      string  `db:"name"`
+	Metadata  string  `db:"metadata"`
+	Namespace *string `db:"namespace"`
+}
@@ -0,0 +1,174 @@
+// Package attribute API.
+//
+// 自定义属性定义管理，登记角色、权限可以使用的属性键、值类型与可选值，实体的 attributes 按定义校验。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package attribute
+
+import (
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+}
+
+func NewAttributeHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator) *Handler {
+	pkgs.RegisterRule(validator, createRule)
+	pkgs.RegisterRule(validator, updateRule)
+	return &Handler{
+		db:        db,
+		logger:    logger,
+		validator: validator,
+		repository: &Repository{
+			db:     db,
+			logger: logger,
+			tables: tables,
+			pool:   pool,
+			ids:    ids,
+		},
+	}
+}
+
+// Create 创建属性定义
+//
+//	@Summary  创建属性定义
+//	@Description  为角色或权限登记自定义属性；enum 类型必须设置可选值。已有实体时不能直接创建必填属性，可先创建为非必填、为实体设置后再改为必填
+//	@Tags   attribute
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  CreateReq true  "创建属性定义请求参数"
+//	@Success  200   {object}  pkgs.Response{data=CreateRes}  "创建成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  409   {object}  pkgs.Response       "属性已存在，或已有实体时创建必填属性"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/attribute"}
+//	@Router   /attribute [post]
+func (h *Handler) Create(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[CreateReq](c),
+		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+		result.FlatMap(h.repository.Create(c)),
+	).Match(
+		pkgs.HandleSuccess[CreateRes](c),
+		pkgs.HandleError[CreateRes](c),
+	)
+}
+
+// GetByID 根据ID获取属性定义
+//
+//	@Summary  根据ID获取属性定义
+//	@Description  返回属性定义详情
+//	@Tags   attribute
+//	@Produce  json
+//	@Param    id  path  string  true  "属性ID"
+//	@Success  200 {object}  pkgs.Response{data=GetByIDRes} "获取成功"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response       "属性不存在"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/attribute/:id"}
+//	@Router   /attribute/{id} [get]
+func (h *Handler) GetByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[GetByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[GetByIDReq](h.validator)),
+		result.FlatMap(h.repository.GetByID(c)),
+	).Match(
+		pkgs.HandleSuccess[GetByIDRes](c),
+		pkgs.HandleError[GetByIDRes](c),
+	)
+}
+
+// UpdateByID 根据ID更新属性定义
+//
+//	@Summary  根据ID更新属性定义
+//	@Description  修改可选值、是否必填或描述；实体类型、属性键与值类型不能修改。已有实体使用的可选值不能移除，所有已有实体都设置了该属性才能设为必填
+//	@Tags   attribute
+//	@Accept   json
+//	@Produce  json
+//	@Param    id    path  string          true  "属性ID"
+//	@Param    request body  UpdateByIDReq true  "更新属性定义请求参数"
+//	@Success  200   {object}  pkgs.Response{data=UpdateByIDRes}       "更新成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404   {object}  pkgs.Response       "属性不存在"
+//	@Failure  409   {object}  pkgs.Response       "已有实体的属性值与新的定义不一致"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/attribute/:id"}
+//	@Router   /attribute/{id} [put]
+func (h *Handler) UpdateByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUriAndJSON[UpdateByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[UpdateByIDReq](h.validator)),
+		result.FlatMap(h.repository.UpdateByID(c)),
+	).Match(
+		pkgs.HandleSuccess[UpdateByIDRes](c),
+		pkgs.HandleError[UpdateByIDRes](c),
+	)
+}
+
+// DeleteByID 根据ID删除属性定义
+//
+//	@Summary  根据ID删除属性定义
+//	@Description  删除属性定义，并从所有实体中移除该属性的值
+//	@Tags   attribute
+//	@Produce  json
+//	@Param    id  path  string  true  "属性ID"
+//	@Success  200 {object}  pkgs.Response{data=DeleteByIDRes} "删除成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"DELETE","path":"/v1/attribute/:id"}
+//	@Router   /attribute/{id} [delete]
+func (h *Handler) DeleteByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[DeleteByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
+		result.FlatMap(h.repository.DeleteByID(c)),
+	).Match(
+		pkgs.HandleSuccess[DeleteByIDRes](c),
+		pkgs.HandleError[DeleteByIDRes](c),
+	)
+}
+
+// QueryList 获取属性定义列表
+//
+//	@Summary  获取属性定义列表
+//	@Description  按实体类型、属性键排序返回属性定义
+//	@Tags   attribute
+//	@Produce  json
+//	@Param    page    query int   false "页码"  default(1)
+//	@Param    pageSize  query int   false "每页数量"  default(10)
+//	@Param    entity    query string  false "实体类型"  Enums(role, permission)
+//	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/attribute/list"}
+//	@Router   /attribute/list [get]
+func (h *Handler) QueryList(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[QueryListReq](c),
+		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.QueryList(c)),
+	).Match(
+		pkgs.HandleSuccess[QueryListRes](c),
+		pkgs.HandleError[QueryListRes](c),
+	)
+}
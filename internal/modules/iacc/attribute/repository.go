@@ -0,0 +1,320 @@
+package attribute
+
+import (
+	"database/sql"
+	"errors"
+	"go-pg-demo/pkgs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+	tables *pkgs.TableNames
+	pool   *pkgs.TenantPool
+	ids    *pkgs.IDGenerator
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+// entityTable 返回实体类型对应的数据表
+func (r *Repository) entityTable(entity string) string {
+	if entity == pkgs.AttributeEntityPermission {
+		return r.tables.Permission
+	}
+	return r.tables.Role
+}
+
+const attributeColumns = `id, entity, key, type, options, required, description, created_at, updated_at`
+
+func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
+	return func(req *CreateReq) mo.Result[CreateRes] {
+		ctx := c.Request.Context()
+
+		// 已有实体都没有该属性，不能直接创建必填属性
+		if req.Required {
+			var exists bool
+			if err := r.conn(c).GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM `+r.entityTable(req.Entity)+`)`); err != nil {
+				return mo.Err[CreateRes](pkgs.DBError(r.log(c), err, "创建属性失败"))
+			}
+			if exists {
+				return mo.Err[CreateRes](pkgs.NewApiError(http.StatusConflict, "已有实体未设置该属性，不能创建必填属性"))
+			}
+		}
+
+		entity := &AttributeEntity{
+			Entity:      req.Entity,
+			Key:         req.Key,
+			Type:        req.Type,
+			Options:     pq.StringArray(req.Options),
+			Required:    req.Required,
+			Description: req.Description,
+		}
+		if entity.Options == nil {
+			entity.Options = pq.StringArray{}
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建属性失败"))
+		}
+
+		// 数据库操作，同一实体类型的属性键重复时不插入
+		columns, values := r.ids.Insert("entity", "key", "type", "options", "required", "description")
+		query := `INSERT INTO ` + r.tables.Attribute + ` (` + columns + `) VALUES (` + values + `) ON CONFLICT (entity, key) DO NOTHING RETURNING id`
+		stmt, err := r.conn(c).PrepareNamedContext(ctx, query)
+		if err != nil {
+			r.log(c).Error("准备插入语句失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建属性失败"))
+		}
+		defer stmt.Close()
+		if err := stmt.GetContext(ctx, &entity.ID, entity); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[CreateRes](pkgs.NewApiError(http.StatusConflict, "属性已存在"))
+			}
+			return mo.Err[CreateRes](pkgs.DBError(r.log(c), err, "创建属性失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(CreateRes(entity.ID))
+	}
+}
+
+func (r *Repository) GetByID(c *gin.Context) func(*GetByIDReq) mo.Result[GetByIDRes] {
+	return func(req *GetByIDReq) mo.Result[GetByIDRes] {
+		var entity AttributeEntity
+		err := r.conn(c).GetContext(c.Request.Context(), &entity, `SELECT `+attributeColumns+` FROM `+r.tables.Attribute+` WHERE id = $1`, req.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusNotFound, "属性不存在"))
+			}
+			return mo.Err[GetByIDRes](pkgs.DBError(r.log(c), err, "获取属性失败"))
+		}
+		return mo.Ok(toGetByIDRes(c, &entity))
+	}
+}
+
+// UpdateByID 更新属性定义，在事务内锁定定义后检查已有实体的属性值与新的约束是否一致
+func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[UpdateByIDRes] {
+	return func(req *UpdateByIDReq) mo.Result[UpdateByIDRes] {
+		ctx := c.Request.Context()
+
+		// 开启事务
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新属性失败"))
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+			if err != nil {
+				tx.Rollback()
+			} else {
+				err = tx.Commit()
+				if err != nil {
+					r.log(c).Error("Failed to commit transaction", zap.Error(err))
+					return
+				}
+			}
+		}()
+
+		var current AttributeEntity
+		err = tx.GetContext(ctx, &current, `SELECT `+attributeColumns+` FROM `+r.tables.Attribute+` WHERE id = $1 FOR UPDATE`, req.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusNotFound, "属性不存在"))
+			}
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新属性失败"))
+		}
+		table := r.entityTable(current.Entity)
+
+		// 动态构建更新语句
+		params := map[string]any{"id": req.ID}
+		var setClauses []string
+		if req.Options != nil {
+			if violations := optionsViolations(current.Type, req.Options); len(violations) > 0 {
+				return mo.Err[UpdateByIDRes](pkgs.NewViolationError(violations))
+			}
+			// 已有实体使用的取值不能移除
+			var inUse bool
+			query := `SELECT EXISTS (SELECT 1 FROM ` + table + ` WHERE jsonb_exists(attributes, $1) AND NOT (attributes ->> $1 = ANY($2)))`
+			if err = tx.GetContext(ctx, &inUse, query, current.Key, pkgs.PGArray(req.Options)); err != nil {
+				return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新属性失败"))
+			}
+			if inUse {
+				return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusConflict, "已有实体使用了被移除的可选值"))
+			}
+			params["options"] = pq.StringArray(req.Options)
+			setClauses = append(setClauses, "options = :options")
+		}
+		if req.Required != nil {
+			if *req.Required && !current.Required {
+				var missing bool
+				query := `SELECT EXISTS (SELECT 1 FROM ` + table + ` WHERE NOT jsonb_exists(attributes, $1))`
+				if err = tx.GetContext(ctx, &missing, query, current.Key); err != nil {
+					return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新属性失败"))
+				}
+				if missing {
+					return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusConflict, "已有实体未设置该属性，不能设为必填"))
+				}
+			}
+			params["required"] = *req.Required
+			setClauses = append(setClauses, "required = :required")
+		}
+		if req.Description != nil {
+			params["description"] = *req.Description
+			setClauses = append(setClauses, "description = :description")
+		}
+
+		// 如果没有需要更新的字段，直接返回成功
+		if len(setClauses) == 0 {
+			return mo.Ok(UpdateByIDRes(0))
+		}
+
+		query := "UPDATE " + r.tables.Attribute + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		res, err := tx.NamedExecContext(ctx, query, params)
+		if err != nil {
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新属性失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新属性失败"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// DeleteByID 删除属性定义，并在同一事务内从实体中移除该属性的值
+func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
+	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
+		ctx := c.Request.Context()
+
+		// 开启事务
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除属性失败"))
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+			if err != nil {
+				tx.Rollback()
+			} else {
+				err = tx.Commit()
+				if err != nil {
+					r.log(c).Error("Failed to commit transaction", zap.Error(err))
+					return
+				}
+			}
+		}()
+
+		var current AttributeEntity
+		err = tx.GetContext(ctx, &current, `DELETE FROM `+r.tables.Attribute+` WHERE id = $1 RETURNING entity, key`, req.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = nil
+				return mo.Ok(DeleteByIDRes(0))
+			}
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除属性失败"))
+		}
+		query := `UPDATE ` + r.entityTable(current.Entity) + ` SET attributes = attributes - $1::text WHERE jsonb_exists(attributes, $1)`
+		if _, err = tx.ExecContext(ctx, query, current.Key); err != nil {
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除属性失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(DeleteByIDRes(1))
+	}
+}
+
+func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
+	return func(req *QueryListReq) mo.Result[QueryListRes] {
+		// 构建查询
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
+		}
+		whereCondition := ""
+		if req.Entity != "" {
+			whereCondition = " WHERE entity = :entity"
+			params["entity"] = req.Entity
+		}
+
+		// 查询总数
+		db := r.conn(c)
+		var total int64
+		countQuery, countArgs, err := db.BindNamed("SELECT count(*) FROM "+r.tables.Attribute+whereCondition, params)
+		if err != nil {
+			r.log(c).Error("构建计数查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询属性列表失败"))
+		}
+		if err := db.GetContext(c.Request.Context(), &total, countQuery, countArgs...); err != nil {
+			r.log(c).Error("统计属性数量失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询属性列表失败"))
+		}
+		if total == 0 {
+			return mo.Ok(QueryListRes{List: []GetByIDRes{}, Total: 0})
+		}
+
+		// 查询列表
+		var entities []AttributeEntity
+		listQuery, listArgs, err := db.BindNamed(`SELECT `+attributeColumns+` FROM `+r.tables.Attribute+
+			whereCondition+` ORDER BY entity, key LIMIT :limit OFFSET :offset`, params)
+		if err != nil {
+			r.log(c).Error("构建列表查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询属性列表失败"))
+		}
+		if err := db.SelectContext(c.Request.Context(), &entities, listQuery, listArgs...); err != nil {
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询属性列表失败"))
+		}
+
+		list := make([]GetByIDRes, 0, len(entities))
+		for i := range entities {
+			list = append(list, toGetByIDRes(c, &entities[i]))
+		}
+
+		// 返回结果
+		return mo.Ok(QueryListRes{List: list, Total: total})
+	}
+}
+
+// toGetByIDRes 将数据库实体转换为属性定义详情
+func toGetByIDRes(c *gin.Context, entity *AttributeEntity) GetByIDRes {
+	options := []string(entity.Options)
+	if options == nil {
+		options = []string{}
+	}
+	return GetByIDRes{
+		ID:          entity.ID,
+		Entity:      entity.Entity,
+		Key:         entity.Key,
+		Type:        entity.Type,
+		Options:     options,
+		Required:    entity.Required,
+		Description: entity.Description,
+		CreatedAt:   pkgs.FormatTime(c, entity.CreatedAt),
+		UpdatedAt:   pkgs.FormatTime(c, entity.UpdatedAt),
+	}
+}
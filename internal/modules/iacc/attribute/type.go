@@ -0,0 +1,115 @@
+package attribute
+
+import (
+	"go-pg-demo/pkgs"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// 数据库表 iacc_attribute 的表结构
+type AttributeEntity struct {
+	ID          string         `db:"id" label:"属性ID"`
+	CreatedAt   time.Time      `db:"created_at" label:"创建时间"`
+	UpdatedAt   time.Time      `db:"updated_at" label:"更新时间"`
+	Entity      string         `db:"entity" label:"实体类型"`
+	Key         string         `db:"key" label:"属性键"`
+	Type        string         `db:"type" label:"值类型"`
+	Options     pq.StringArray `db:"options" label:"可选值"`
+	Required    bool           `db:"required" label:"是否必填"`
+	Description string         `db:"description" label:"属性描述"`
+}
+
+// 创建属性定义的请求 DTO
+type CreateReq struct {
+	Entity string `json:"entity" validate:"required,oneof=role permission" label:"实体类型"`
+	Key    string `json:"key" validate:"required,max=50" label:"属性键"`
+	Type   string `json:"type" validate:"required,oneof=string number boolean enum" label:"值类型"`
+	// enum 类型的可选值，其他类型不能设置
+	Options []string `json:"options,omitempty" validate:"omitempty,max=100,dive,required,max=100" label:"可选值"`
+	// 必填属性在创建、整体更新实体时必须提供；已有实体时不能直接创建必填属性
+	Required    bool   `json:"required" label:"是否必填"`
+	Description string `json:"description" validate:"max=500" label:"属性描述"`
+}
+
+// 属性键格式有效，enum 类型必须设置可选值且不能重复
+func createRule(req *CreateReq) []pkgs.Violation {
+	var violations []pkgs.Violation
+	if !pkgs.ValidAttributeKey(req.Key) {
+		violations = append(violations, pkgs.Violation{Field: "key", Message: "属性键只能包含小写字母、数字和下划线，且以小写字母开头"})
+	}
+	return append(violations, optionsViolations(req.Type, req.Options)...)
+}
+
+// optionsViolations 校验可选值与值类型是否匹配
+func optionsViolations(typ string, options []string) []pkgs.Violation {
+	if typ != pkgs.AttributeTypeEnum {
+		if len(options) > 0 {
+			return []pkgs.Violation{{Field: "options", Message: "只有 enum 类型的属性可以设置可选值"}}
+		}
+		return nil
+	}
+	if len(options) == 0 {
+		return []pkgs.Violation{{Field: "options", Message: "enum 类型的属性必须设置可选值"}}
+	}
+	return pkgs.DuplicateViolations("options", "可选值", options)
+}
+
+// 创建属性定义的响应 DTO
+type CreateRes string
+
+// 根据ID获取属性定义的参数
+type GetByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"属性ID"`
+}
+
+// 属性定义详情
+type GetByIDRes struct {
+	ID          string   `json:"id" label:"属性ID"`
+	Entity      string   `json:"entity" label:"实体类型"`
+	Key         string   `json:"key" label:"属性键"`
+	Type        string   `json:"type" label:"值类型"`
+	Options     []string `json:"options" label:"可选值"`
+	Required    bool     `json:"required" label:"是否必填"`
+	Description string   `json:"description" label:"属性描述"`
+	CreatedAt   string   `json:"created_at" label:"创建时间"`
+	UpdatedAt   string   `json:"updated_at" label:"更新时间"`
+}
+
+// 更新属性定义的请求体，实体类型、属性键与值类型创建后不能修改
+type UpdateByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"属性ID"`
+	// enum 类型的可选值，传入时整体替换；已有实体使用的值必须保留
+	Options []string `json:"options,omitempty" validate:"omitempty,max=100,dive,required,max=100" label:"可选值"`
+	// 设为必填时所有已有实体必须已经设置该属性
+	Required    *bool   `json:"required,omitempty" label:"是否必填"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500" label:"属性描述"`
+}
+
+// 可选值不能重复，是否为 enum 类型在仓储层按已有定义校验
+func updateRule(req *UpdateByIDReq) []pkgs.Violation {
+	return pkgs.DuplicateViolations("options", "可选值", req.Options)
+}
+
+// 更新属性定义的响应体
+type UpdateByIDRes = int64
+
+// 根据ID删除属性定义的请求参数
+type DeleteByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"属性ID"`
+}
+
+// 根据ID删除属性定义的响应
+type DeleteByIDRes = int64
+
+// 查询属性定义列表的请求体
+type QueryListReq struct {
+	pkgs.Pagination
+	Entity string `form:"entity,omitempty" validate:"omitempty,oneof=role permission" label:"实体类型"`
+}
+
+// 查询属性定义列表的响应体
+type QueryListRes struct {
+	List  []GetByIDRes `json:"list"`
+	Total int64        `json:"total"`
+}
@@ -13,9 +13,11 @@ package auth
 
 import (
 	"go-pg-demo/pkgs"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
 	"github.com/samber/mo/result"
 	"go.uber.org/zap"
 )
@@ -24,25 +26,33 @@ type Handler struct {
 	db         *sqlx.DB
 	logger     *zap.Logger
 	validator  *pkgs.RequestValidator
+	config     *pkgs.Config
 	repository *Repository
 }
 
-func NewAuthHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, config *pkgs.Config) *Handler {
+func NewAuthHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, config *pkgs.Config, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator, events *pkgs.SecurityEvents, blacklist *pkgs.TokenBlacklist, hasher *pkgs.PasswordHasher, scheduler *pkgs.Scheduler, settings *pkgs.Settings) *Handler {
+	repository := NewRepository(db, logger, config, tables, pool, ids, events, blacklist, hasher, settings)
+	// 统计各密码格式的用户数，用于跟踪旧系统密码的迁移进度
+	scheduler.Register("auth.password_hash_metrics", passwordHashMetricsSchedule, repository.RefreshPasswordHashMetrics)
 	return &Handler{
 		db:         db,
 		logger:     logger,
 		validator:  validator,
-		repository: NewRepository(db, logger, config),
+		config:     config,
+		repository: repository,
 	}
 }
 
 // Login 用户登录
 //
 //	@Summary  用户登录
-//	@Description  用户登录，获取访问令牌和刷新令牌
+//	@Description  用户登录，获取访问令牌和刷新令牌；携带设备标识时登记设备，刷新令牌绑定到该设备。
+//	@Description  开启 Cookie 鉴权（jwt.cookie.enabled）时同时写入 HttpOnly 的 access_token、refresh_token cookie 与前端可读的 csrf_token cookie，之后的修改类请求需通过 X-CSRF-Token 请求头回传 csrf_token
 //	@Tags   auth
 //	@Accept   json
 //	@Produce  json
+//	@Param    X-Device-ID   header  string  false "设备标识（客户端生成并持久保存）"
+//	@Param    X-Device-Name header  string  false "设备名称"
 //	@Param    request body  LoginReq true  "登录请求参数"
 //	@Success  200   {object}  pkgs.Response{data=LoginRes}  "登录成功"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
@@ -50,10 +60,11 @@ func NewAuthHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestVali
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
 //	@Router   /auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe3(
 		pkgs.BindJSON[LoginReq](c),
 		result.FlatMap(pkgs.ValidateV2[LoginReq](h.validator)),
 		result.FlatMap(h.repository.Login(c)),
+		result.FlatMap(h.setLoginCookies(c)),
 	).Match(
 		pkgs.HandleSuccess[LoginRes](c),
 		pkgs.HandleError[LoginRes](c),
@@ -63,27 +74,142 @@ func (h *Handler) Login(c *gin.Context) {
 // RefreshToken 刷新访问令牌
 //
 //	@Summary  刷新访问令牌
-//	@Description  通过刷新令牌获取新的访问令牌
+//	@Description  通过刷新令牌获取新的访问令牌；绑定设备的刷新令牌在设备被删除或设备标识不一致时无效，开启严格设备模式后只允许已登记的设备刷新。
+//	@Description  开启 Cookie 鉴权时请求体可以传 {}，使用 refresh_token cookie 中的刷新令牌，此时必须携带 X-CSRF-Token 请求头；刷新成功后更新 cookie
 //	@Tags   auth
 //	@Accept   json
 //	@Produce  json
+//	@Param    X-Device-ID header  string  false "设备标识"
+//	@Param    X-CSRF-Token  header  string  false "CSRF 令牌，使用 cookie 中的刷新令牌时必填"
 //	@Param    request body  RefreshTokenReq true  "刷新令牌请求参数"
 //	@Success  200   {object}  pkgs.Response{data=RefreshTokenRes}  "刷新成功"
 //	@Failure  400   {object}  pkgs.Response         "请求参数错误"
 //	@Failure  401   {object}  pkgs.Response         "刷新令牌无效"
+//	@Failure  403   {object}  pkgs.Response         "CSRF 令牌校验失败"
 //	@Failure  500   {object}  pkgs.Response         "服务器内部错误"
 //	@Router   /auth/refresh-token [post]
 func (h *Handler) RefreshToken(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe4(
 		pkgs.BindJSON[RefreshTokenReq](c),
+		result.FlatMap(h.refreshTokenFromCookie(c)),
 		result.FlatMap(pkgs.ValidateV2[RefreshTokenReq](h.validator)),
 		result.FlatMap(h.repository.RefreshToken(c)),
+		result.FlatMap(h.setRefreshCookies(c)),
 	).Match(
 		pkgs.HandleSuccess[RefreshTokenRes](c),
 		pkgs.HandleError[RefreshTokenRes](c),
 	)
 }
 
+// refreshTokenFromCookie 请求体没有刷新令牌时读取 cookie 中的刷新令牌
+// 刷新令牌接口不经过鉴权中间件，在这里校验 CSRF 令牌。
+func (h *Handler) refreshTokenFromCookie(c *gin.Context) func(*RefreshTokenReq) mo.Result[*RefreshTokenReq] {
+	return func(req *RefreshTokenReq) mo.Result[*RefreshTokenReq] {
+		if req.RefreshToken != "" {
+			return mo.Ok(req)
+		}
+		if req.RefreshToken = pkgs.CookieToken(c, h.config, pkgs.RefreshTokenCookie); req.RefreshToken != "" && !pkgs.ValidCSRF(c) {
+			return mo.Err[*RefreshTokenReq](pkgs.NewApiError(http.StatusForbidden, "CSRF 令牌校验失败"))
+		}
+		return mo.Ok(req)
+	}
+}
+
+// setLoginCookies 开启 Cookie 鉴权时把登录得到的令牌写入 cookie
+func (h *Handler) setLoginCookies(c *gin.Context) func(LoginRes) mo.Result[LoginRes] {
+	return func(res LoginRes) mo.Result[LoginRes] {
+		if err := pkgs.SetAuthCookies(c, h.config, res.AccessToken, res.RefreshToken); err != nil {
+			h.logger.Error("生成 CSRF 令牌失败", zap.Error(err))
+			return mo.Err[LoginRes](pkgs.NewApiError(http.StatusInternalServerError, "登录失败"))
+		}
+		return mo.Ok(res)
+	}
+}
+
+// setRefreshCookies 开启 Cookie 鉴权时把刷新得到的令牌写入 cookie
+func (h *Handler) setRefreshCookies(c *gin.Context) func(RefreshTokenRes) mo.Result[RefreshTokenRes] {
+	return func(res RefreshTokenRes) mo.Result[RefreshTokenRes] {
+		if err := pkgs.SetAuthCookies(c, h.config, res.AccessToken, res.RefreshToken); err != nil {
+			h.logger.Error("生成 CSRF 令牌失败", zap.Error(err))
+			return mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusInternalServerError, "刷新失败"))
+		}
+		return mo.Ok(res)
+	}
+}
+
+// Logout 退出登录
+//
+//	@Summary  退出登录
+//	@Description  撤销当前访问令牌；请求体携带刷新令牌时一并撤销（必须属于当前用户）。撤销的令牌在过期前访问接口或刷新均返回 401。
+//	@Description  开启 Cookie 鉴权时请求体未携带刷新令牌则撤销 refresh_token cookie 中的刷新令牌，退出成功后删除令牌 cookie
+//	@Tags   auth
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  LogoutReq true  "退出登录请求参数，只撤销访问令牌时传 {}"
+//	@Success  200 {object}  pkgs.Response{data=LogoutRes} "退出成功，返回撤销的令牌数"
+//	@Failure  400 {object}  pkgs.Response       "刷新令牌无效"
+//	@Failure  401 {object}  pkgs.Response       "未授权"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@Router   /auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindJSON[LogoutReq](c),
+		result.Map(h.logoutTokenFromCookie(c)),
+		result.FlatMap(pkgs.ValidateV2[LogoutReq](h.validator)),
+		result.FlatMap(h.repository.Logout(c)),
+		result.Map(h.clearCookies(c)),
+	).Match(
+		pkgs.HandleSuccess[LogoutRes](c),
+		pkgs.HandleError[LogoutRes](c),
+	)
+}
+
+// logoutTokenFromCookie 请求体没有刷新令牌时撤销 cookie 中的刷新令牌
+// 通过 cookie 鉴权的退出请求已在鉴权中间件校验 CSRF 令牌。
+func (h *Handler) logoutTokenFromCookie(c *gin.Context) func(*LogoutReq) *LogoutReq {
+	return func(req *LogoutReq) *LogoutReq {
+		if req.RefreshToken == "" {
+			req.RefreshToken = pkgs.CookieToken(c, h.config, pkgs.RefreshTokenCookie)
+		}
+		return req
+	}
+}
+
+// clearCookies 退出成功后删除令牌 cookie
+func (h *Handler) clearCookies(c *gin.Context) func(LogoutRes) LogoutRes {
+	return func(res LogoutRes) LogoutRes {
+		pkgs.ClearAuthCookies(c, h.config)
+		return res
+	}
+}
+
+// ChangePassword 修改当前用户的密码
+//
+//	@Summary  修改密码
+//	@Description  校验当前密码后设置新密码，新密码须满足密码策略（运行时设置 password_policy）；此前签发的刷新令牌全部失效（包括其他设备），当前访问令牌在过期前仍可使用
+//	@Tags   auth
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  ChangePasswordReq true  "修改密码请求参数"
+//	@Success  200 {object}  pkgs.Response{data=ChangePasswordRes} "修改成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误、当前密码错误或新密码不满足密码策略"
+//	@Failure  401 {object}  pkgs.Response       "未授权"
+//	@Failure  409 {object}  pkgs.Response       "密码已被并发修改"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@Router   /auth/change-password [post]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[ChangePasswordReq](c),
+		result.FlatMap(pkgs.ValidateV2[ChangePasswordReq](h.validator)),
+		result.FlatMap(h.repository.ChangePassword(c)),
+	).Match(
+		pkgs.HandleSuccess[ChangePasswordRes](c),
+		pkgs.HandleError[ChangePasswordRes](c),
+	)
+}
+
 // UserDetail 获取当前用户详情
 //
 //	@Summary  获取当前用户详情
@@ -124,3 +250,70 @@ func (h *Handler) UserDetail(c *gin.Context) {
 func (h *Handler) GetMe(c *gin.Context) {
 	h.UserDetail(c)
 }
+
+// QueryDevices 查询当前用户的设备
+//
+//	@Summary  查询当前用户的设备
+//	@Description  返回当前用户登录过的设备（按最近使用时间倒序）以及是否开启严格设备模式；携带 X-Device-ID 时标记当前设备
+//	@Tags   auth
+//	@Produce  json
+//	@Param    X-Device-ID header  string  false "设备标识"
+//	@Success  200 {object}  pkgs.Response{data=QueryDevicesRes}  "成功"
+//	@Failure  401 {object}  pkgs.Response           "未授权"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@Router   /auth/devices [get]
+func (h *Handler) QueryDevices(c *gin.Context) {
+	h.repository.QueryDevices(c)(pkgs.CurrentUserID(c)).Match(
+		pkgs.HandleSuccess[QueryDevicesRes](c),
+		pkgs.HandleError[QueryDevicesRes](c),
+	)
+}
+
+// DeleteDevice 删除当前用户的设备
+//
+//	@Summary  删除当前用户的设备
+//	@Description  删除设备记录，绑定该设备的刷新令牌随之失效（已签发的访问令牌在过期前仍可使用）
+//	@Tags   auth
+//	@Produce  json
+//	@Param    id  path  string  true  "设备记录ID"
+//	@Success  200 {object}  pkgs.Response{data=DeleteDeviceRes} "删除成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response       "设备不存在"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@Router   /auth/devices/{id} [delete]
+func (h *Handler) DeleteDevice(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[DeleteDeviceReq](c),
+		result.FlatMap(pkgs.ValidateV2[DeleteDeviceReq](h.validator)),
+		result.FlatMap(h.repository.DeleteDevice(c)),
+	).Match(
+		pkgs.HandleSuccess[DeleteDeviceRes](c),
+		pkgs.HandleError[DeleteDeviceRes](c),
+	)
+}
+
+// SetStrictDevice 设置当前用户的严格设备模式
+//
+//	@Summary  设置严格设备模式
+//	@Description  开启后只允许已登记设备使用绑定该设备的刷新令牌，且刷新时必须携带 X-Device-ID；未绑定设备的刷新令牌需重新登录
+//	@Tags   auth
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  StrictDeviceReq true  "严格设备模式开关"
+//	@Success  200 {object}  pkgs.Response{data=StrictDeviceRes} "设置成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@Router   /auth/devices/strict-mode [put]
+func (h *Handler) SetStrictDevice(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[StrictDeviceReq](c),
+		result.FlatMap(pkgs.ValidateV2[StrictDeviceReq](h.validator)),
+		result.FlatMap(h.repository.SetStrictDevice(c)),
+	).Match(
+		pkgs.HandleSuccess[StrictDeviceRes](c),
+		pkgs.HandleError[StrictDeviceRes](c),
+	)
+}
@@ -1,145 +1,403 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"go-pg-demo/pkgs"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/samber/mo"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 type Repository struct {
 	db     *sqlx.DB
 	logger *zap.Logger
 	config *pkgs.Config
+	tables *pkgs.TableNames
+	pool   *pkgs.TenantPool
+	ids    *pkgs.IDGenerator
+	events *pkgs.SecurityEvents
+	hasher *pkgs.PasswordHasher
+	// 运行时设置：未指定客户端时的令牌有效期、密码策略
+	settings *pkgs.Settings
+	// 退出登录撤销的令牌
+	blacklist *pkgs.TokenBlacklist
 }
 
-func NewRepository(db *sqlx.DB, logger *zap.Logger, config *pkgs.Config) *Repository {
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+func NewRepository(db *sqlx.DB, logger *zap.Logger, config *pkgs.Config, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator, events *pkgs.SecurityEvents, blacklist *pkgs.TokenBlacklist, hasher *pkgs.PasswordHasher, settings *pkgs.Settings) *Repository {
 	return &Repository{
 		db:     db,
 		logger: logger,
 		config: config,
+		tables: tables,
+		pool:   pool,
+		ids:    ids,
+		events: events,
+		hasher: hasher,
+
+		settings:  settings,
+		blacklist: blacklist,
 	}
 }
 
+// Login 登录，成功与失败都记录安全事件
 func (r *Repository) Login(c *gin.Context) func(*LoginReq) mo.Result[LoginRes] {
 	return func(req *LoginReq) mo.Result[LoginRes] {
-		// 查询用户（用户名唯一）
-		var user UserEntity
-		query := `SELECT id, username, password, phone, profile, created_at, updated_at FROM iacc_user WHERE username = $1`
-		err := r.db.GetContext(c.Request.Context(), &user, query, req.Username)
+		userID, res := r.login(c, req)
+		r.recordAuthEvent(c, userID, res.Error(), pkgs.SecurityEventLoginSuccess, pkgs.SecurityEventLoginFailure,
+			map[string]any{"username": req.Username, "client_id": req.ClientID, "device_id": c.GetHeader(DeviceIDHeader)})
+		return res
+	}
+}
+
+// rehashPassword 密码哈希的算法或参数与当前配置不同时，用本次登录的明文重新哈希
+// 只在校验通过后执行，失败只记录日志，不影响登录
+func (r *Repository) rehashPassword(c *gin.Context, userID, hash, password string) {
+	if !r.hasher.NeedsRehash(hash) {
+		return
+	}
+	newHash, err := r.hasher.Hash(password)
+	if err != nil {
+		r.log(c).Warn("重新哈希密码失败", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+	// 只在哈希未被并发修改时更新，避免覆盖同时修改的新密码
+	query := `UPDATE ` + r.tables.User + ` SET password = $1 WHERE id = $2 AND password = $3`
+	if _, err := r.conn(c).ExecContext(c.Request.Context(), query, newHash, userID, hash); err != nil {
+		r.log(c).Warn("更新密码哈希失败", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+	pkgs.RecordPasswordRehash(hash)
+}
+
+// RefreshPasswordHashMetrics 定时任务：按格式统计所有 schema 中用户的密码哈希，更新 user_password_hashes 指标
+// md5、plain 的数量即尚未在新系统登录过的旧系统用户
+func (r *Repository) RefreshPasswordHashMetrics(ctx context.Context) {
+	counts := map[string]int{}
+	for tenant, db := range r.pool.BatchDBs(ctx, r.logger) {
+		rows, err := pkgs.QueryAll[struct {
+			Scheme string `db:"scheme"`
+			Count  int    `db:"count"`
+		}](ctx, db, `SELECT `+pkgs.PasswordSchemeSQL("password")+` AS scheme, COUNT(*) AS count FROM `+r.tables.User+` GROUP BY 1`)
 		if err != nil {
-			if err == sql.ErrNoRows {
-				return mo.Err[LoginRes](pkgs.NewApiError(http.StatusUnauthorized, "用户名或密码错误"))
-			}
-			r.logger.Error("查询用户失败", zap.Error(err))
-			return mo.Err[LoginRes](pkgs.NewApiError(http.StatusInternalServerError, "登录失败"))
+			r.logger.Error("统计密码哈希格式失败", zap.String("tenant", tenant), zap.Error(err))
+			continue
 		}
-
-		// 简单密码校验（后续可引入加密）
-		if user.Password != req.Password {
-			return mo.Err[LoginRes](pkgs.NewApiError(http.StatusUnauthorized, "用户名或密码错误"))
+		for _, row := range rows {
+			counts[row.Scheme] += row.Count
 		}
+	}
+	pkgs.SetPasswordHashCounts(counts)
+}
 
-		// 生成访问令牌
-		accessToken, err := r.generateToken(user.ID, r.config.JWT.AccessTokenExpire)
-		if err != nil {
-			r.logger.Error("生成访问令牌失败", zap.Error(err))
-			return mo.Err[LoginRes](pkgs.NewApiError(http.StatusInternalServerError, "登录失败"))
+func (r *Repository) login(c *gin.Context, req *LoginReq) (string, mo.Result[LoginRes]) {
+	// 查询用户（用户名唯一）
+	var user UserEntity
+	query := `SELECT id, username, password, phone, profile, created_at, updated_at, disabled_at, token_version FROM ` + r.tables.User + ` WHERE username = $1`
+	err := r.conn(c).GetContext(c.Request.Context(), &user, query, req.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user.ID, mo.Err[LoginRes](pkgs.NewApiError(http.StatusUnauthorized, "用户名或密码错误"))
 		}
+		r.log(c).Error("查询用户失败", zap.Error(err))
+		return user.ID, mo.Err[LoginRes](pkgs.NewApiError(http.StatusInternalServerError, "登录失败"))
+	}
+
+	// 校验密码哈希，未设置密码的账号（如从快照恢复的用户）不能登录
+	if user.Password == nil || !r.hasher.Verify(*user.Password, req.Password) {
+		return user.ID, mo.Err[LoginRes](pkgs.NewApiError(http.StatusUnauthorized, "用户名或密码错误"))
+	}
+	r.rehashPassword(c, user.ID, *user.Password, req.Password)
 
-		// 生成刷新令牌
-		refreshToken, err := r.generateToken(user.ID, r.config.JWT.RefreshTokenExpire)
+	// 已停用的账号不能登录，密码校验通过后才提示，避免泄露账号状态
+	if user.DisabledAt != nil {
+		return user.ID, mo.Err[LoginRes](pkgs.NewApiError(http.StatusForbidden, "账号已停用"))
+	}
+
+	// 按客户端确定令牌有效期
+	accessTTL, refreshTTL, apiErr := r.tokenLifetimes(c, req.ClientID)
+	if apiErr != nil {
+		return user.ID, mo.Err[LoginRes](apiErr)
+	}
+
+	// 携带设备标识时登记设备，刷新令牌绑定到设备记录
+	deviceRecordID := ""
+	if deviceID := c.GetHeader(DeviceIDHeader); deviceID != "" {
+		if len(deviceID) > 128 {
+			return user.ID, mo.Err[LoginRes](pkgs.NewApiError(http.StatusBadRequest, "设备标识不能超过128个字符"))
+		}
+		device, err := r.registerDevice(c, user.ID, deviceID)
 		if err != nil {
-			r.logger.Error("生成刷新令牌失败", zap.Error(err))
-			return mo.Err[LoginRes](pkgs.NewApiError(http.StatusInternalServerError, "登录失败"))
+			r.log(c).Error("登记设备失败", zap.Error(err))
+			return user.ID, mo.Err[LoginRes](pkgs.NewApiError(http.StatusInternalServerError, "登录失败"))
 		}
+		deviceRecordID = device.ID
+	}
 
-		return mo.Ok(LoginRes{
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
-			ExpiresIn:    int64(r.config.JWT.AccessTokenExpire.Seconds()),
-		})
+	// 生成访问令牌
+	accessToken, err := r.generateToken(user.ID, pkgs.TokenTypeAccess, accessTTL, tokenBinding{}, user.TokenVersion)
+	if err != nil {
+		r.log(c).Error("生成访问令牌失败", zap.Error(err))
+		return user.ID, mo.Err[LoginRes](pkgs.NewApiError(http.StatusInternalServerError, "登录失败"))
+	}
+
+	// 生成刷新令牌
+	refreshToken, err := r.issueRefreshToken(c, user.ID, refreshTTL, tokenBinding{Device: deviceRecordID, Client: req.ClientID})
+	if err != nil {
+		r.log(c).Error("生成刷新令牌失败", zap.Error(err))
+		return user.ID, mo.Err[LoginRes](pkgs.NewApiError(http.StatusInternalServerError, "登录失败"))
 	}
+
+	return user.ID, mo.Ok(LoginRes{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTTL.Seconds()),
+	})
 }
 
+// RefreshToken 刷新令牌，成功与失败都记录安全事件
 func (r *Repository) RefreshToken(c *gin.Context) func(*RefreshTokenReq) mo.Result[RefreshTokenRes] {
 	return func(req *RefreshTokenReq) mo.Result[RefreshTokenRes] {
-		// 解析刷新 token
-		token, err := jwt.Parse(req.RefreshToken, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrTokenSignatureInvalid
-			}
-			return []byte(r.config.JWT.Secret), nil
-		})
-		if err != nil || !token.Valid {
-			return mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusUnauthorized, "刷新令牌无效"))
-		}
+		userID, res := r.refreshToken(c, req)
+		r.recordAuthEvent(c, userID, res.Error(), pkgs.SecurityEventTokenRefresh, pkgs.SecurityEventTokenRefreshFailure, nil)
+		return res
+	}
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusUnauthorized, "刷新令牌无效"))
+func (r *Repository) refreshToken(c *gin.Context, req *RefreshTokenReq) (string, mo.Result[RefreshTokenRes]) {
+	// 解析刷新 token
+	claims, userID, ok := r.parseRefreshToken(req.RefreshToken)
+	if !ok {
+		return userID, mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusUnauthorized, "刷新令牌无效"))
+	}
+	// 退出登录或批量撤销的刷新令牌不能再换取新令牌
+	revoked, err := r.blacklist.IsRevoked(c, claims)
+	if err != nil {
+		r.log(c).Error("查询令牌黑名单失败", zap.Error(err))
+		return userID, mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusInternalServerError, "刷新失败"))
+	}
+	if revoked {
+		return userID, mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusUnauthorized, "刷新令牌已撤销，请重新登录"))
+	}
+
+	// 校验刷新令牌绑定的设备与客户端，新令牌继续绑定同一设备与客户端
+	var binding tokenBinding
+	binding.Device, _ = claims[deviceClaim].(string)
+	binding.Client, _ = claims[clientClaim].(string)
+	issuedAt, _ := claims["iat"].(float64)
+	if apiErr := r.checkRefreshDevice(c, userID, binding.Device, int64(issuedAt)); apiErr != nil {
+		return userID, mo.Err[RefreshTokenRes](apiErr)
+	}
+	accessTTL, refreshTTL, apiErr := r.tokenLifetimes(c, binding.Client)
+	if apiErr != nil {
+		return userID, mo.Err[RefreshTokenRes](apiErr)
+	}
+
+	// 生成新的访问令牌，带上用户当前的令牌版本
+	version, err := r.blacklist.Version(c, userID)
+	if err != nil {
+		r.log(c).Error("查询令牌版本失败", zap.Error(err))
+		return userID, mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusInternalServerError, "刷新失败"))
+	}
+	accessToken, err := r.generateToken(userID, pkgs.TokenTypeAccess, accessTTL, tokenBinding{}, version)
+	if err != nil {
+		r.log(c).Error("生成访问令牌失败", zap.Error(err))
+		return userID, mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusInternalServerError, "刷新失败"))
+	}
+
+	// 生成新的刷新令牌
+	newRefreshToken, err := r.issueRefreshToken(c, userID, refreshTTL, binding)
+	if err != nil {
+		r.log(c).Error("生成刷新令牌失败", zap.Error(err))
+		return userID, mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusInternalServerError, "刷新失败"))
+	}
+
+	return userID, mo.Ok(RefreshTokenRes{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(accessTTL.Seconds()),
+	})
+}
+
+// parseRefreshToken 解析并校验刷新令牌，返回声明与用户ID；令牌无效时 ok 为 false，能解析出用户时仍返回用户ID
+func (r *Repository) parseRefreshToken(tokenString string) (claims jwt.MapClaims, userID string, ok bool) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrTokenSignatureInvalid
 		}
+		return []byte(r.config.JWT.Secret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, "", false
+	}
+
+	claims, ok = token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, "", false
+	}
 
-		userID, _ := claims["user_id"].(string)
-		if userID == "" {
-			return mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusUnauthorized, "刷新令牌无效"))
+	userID, _ = claims["user_id"].(string)
+	if userID == "" {
+		return nil, "", false
+	}
+	// 访问令牌不带设备绑定，不能用来换取新令牌
+	if pkgs.TokenType(claims) != pkgs.TokenTypeRefresh {
+		return nil, userID, false
+	}
+	// 沙箱令牌只能在有效期内访问签发时指定的接口，不能换取新令牌
+	if _, sandbox := pkgs.SandboxScopesFromClaims(claims); sandbox {
+		return nil, userID, false
+	}
+	return claims, userID, true
+}
+
+// Logout 退出登录：将当前访问令牌与请求中的刷新令牌加入黑名单，二者在过期前均不能再使用
+// 刷新令牌必须属于当前用户；返回撤销的令牌数，不带 jti 的旧令牌无法撤销、不计入。
+func (r *Repository) Logout(c *gin.Context) func(*LogoutReq) mo.Result[LogoutRes] {
+	return func(req *LogoutReq) mo.Result[LogoutRes] {
+		userID := pkgs.CurrentUserID(c)
+		tokens := make([]jwt.MapClaims, 0, 2)
+		if claims, ok := c.Value(pkgs.TokenClaimsContextKey).(jwt.MapClaims); ok {
+			tokens = append(tokens, claims)
+		}
+		// 先校验刷新令牌，无效时不撤销任何令牌，客户端可以修正后重试
+		if req.RefreshToken != "" {
+			claims, owner, ok := r.parseRefreshToken(req.RefreshToken)
+			if !ok || owner != userID {
+				return mo.Err[LogoutRes](pkgs.NewApiError(http.StatusBadRequest, "刷新令牌无效"))
+			}
+			tokens = append(tokens, claims)
 		}
 
-		// 生成新的访问令牌
-		accessToken, err := r.generateToken(userID, r.config.JWT.AccessTokenExpire)
-		if err != nil {
-			r.logger.Error("生成访问令牌失败", zap.Error(err))
-			return mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusInternalServerError, "刷新失败"))
+		var revoked int64
+		for _, claims := range tokens {
+			ok, err := r.blacklist.Revoke(c, claims)
+			if err != nil {
+				return mo.Err[LogoutRes](pkgs.DBError(r.log(c), err, "退出登录失败"))
+			}
+			if ok {
+				revoked++
+			}
 		}
+		r.events.RecordUser(c, userID, pkgs.SecurityEventLogout, map[string]any{
+			"revoked":       revoked,
+			"refresh_token": req.RefreshToken != "",
+		})
+		return mo.Ok(revoked)
+	}
+}
 
-		// 生成新的刷新令牌
-		newRefreshToken, err := r.generateToken(userID, r.config.JWT.RefreshTokenExpire)
-		if err != nil {
-			r.logger.Error("生成刷新令牌失败", zap.Error(err))
-			return mo.Err[RefreshTokenRes](pkgs.NewApiError(http.StatusInternalServerError, "刷新失败"))
+// ChangePassword 当前用户修改密码，成功与失败都记录安全事件
+// 修改后此前签发的刷新令牌全部失效（记录会话撤销时间），当前访问令牌在过期前仍可使用，客户端需要重新登录获取刷新令牌。
+func (r *Repository) ChangePassword(c *gin.Context) func(*ChangePasswordReq) mo.Result[ChangePasswordRes] {
+	return func(req *ChangePasswordReq) mo.Result[ChangePasswordRes] {
+		userID := pkgs.CurrentUserID(c)
+		res := r.changePassword(c, userID, req)
+		r.recordAuthEvent(c, userID, res.Error(), pkgs.SecurityEventPasswordChange, pkgs.SecurityEventPasswordChangeFailure, nil)
+		return res
+	}
+}
+
+func (r *Repository) changePassword(c *gin.Context, userID string, req *ChangePasswordReq) mo.Result[ChangePasswordRes] {
+	var hash *string
+	err := r.conn(c).GetContext(c.Request.Context(), &hash, `SELECT password FROM `+r.tables.User+` WHERE id = $1`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mo.Err[ChangePasswordRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
 		}
+		return mo.Err[ChangePasswordRes](pkgs.DBError(r.log(c), err, "修改密码失败"))
+	}
+	if hash == nil || !r.hasher.Verify(*hash, req.CurrentPassword) {
+		return mo.Err[ChangePasswordRes](pkgs.NewApiError(http.StatusBadRequest, "当前密码错误"))
+	}
 
-		return mo.Ok(RefreshTokenRes{
-			AccessToken:  accessToken,
-			RefreshToken: newRefreshToken,
-			ExpiresIn:    int64(r.config.JWT.AccessTokenExpire.Seconds()),
-		})
+	if apiErr := r.settings.PasswordPolicy().Check(req.NewPassword, r.hasher); apiErr != nil {
+		return mo.Err[ChangePasswordRes](apiErr)
+	}
+	newHash, err := r.hasher.Hash(req.NewPassword)
+	if err != nil {
+		r.log(c).Error("密码哈希失败", zap.Error(err))
+		return mo.Err[ChangePasswordRes](pkgs.NewApiError(http.StatusInternalServerError, "修改密码失败"))
+	}
+	// 只在哈希未被并发修改时更新，避免覆盖同时修改的新密码
+	query := `UPDATE ` + r.tables.User + ` SET password = $1, sessions_revoked_at = CURRENT_TIMESTAMP WHERE id = $2 AND password = $3`
+	res, err := r.conn(c).ExecContext(c.Request.Context(), query, newHash, userID, *hash)
+	if err != nil {
+		return mo.Err[ChangePasswordRes](pkgs.DBError(r.log(c), err, "修改密码失败"))
+	}
+	affectedRows, err := res.RowsAffected()
+	if err != nil {
+		r.log(c).Error("获取影响行数失败", zap.Error(err))
+		return mo.Err[ChangePasswordRes](pkgs.NewApiError(http.StatusInternalServerError, "修改密码失败"))
 	}
+	if affectedRows == 0 {
+		return mo.Err[ChangePasswordRes](pkgs.NewApiError(http.StatusConflict, "密码已被修改，请重试"))
+	}
+	return mo.Ok(affectedRows)
 }
 
 func (r *Repository) UserDetail(c *gin.Context) func(string) mo.Result[UserDetailRes] {
 	return func(userID string) mo.Result[UserDetailRes] {
-		// 查询用户基本信息
-		var user UserEntity
-		queryUser := `SELECT id, username, phone, profile, created_at, updated_at FROM iacc_user WHERE id = $1`
-		err := r.db.GetContext(c.Request.Context(), &user, queryUser, userID)
-		if err != nil {
-			if err == sql.ErrNoRows {
+		// 用户、角色、权限三个查询互不依赖，并发执行；任一查询失败时取消其余查询
+		var (
+			user  UserEntity
+			roles []RoleEntity
+			perms []PermissionEntity
+		)
+		db := r.conn(c)
+		g, ctx := errgroup.WithContext(c.Request.Context())
+		g.Go(func() error {
+			// 查询用户基本信息
+			queryUser := `SELECT id, username, phone, profile, created_at, updated_at FROM ` + r.tables.User + ` WHERE id = $1`
+			return db.GetContext(ctx, &user, queryUser, userID)
+		})
+		g.Go(func() error {
+			// 查询角色列表
+			queryRoles := `SELECT r.id, r.name, r.description FROM ` + r.tables.Role + ` r INNER JOIN ` + r.tables.UserRole + ` ur ON r.id = ur.role_id WHERE ur.user_id = $1`
+			if err := db.SelectContext(ctx, &roles, queryRoles, userID); err != nil {
+				return fmt.Errorf("查询角色失败: %w", err)
+			}
+			return nil
+		})
+		g.Go(func() error {
+			// 查询权限列表（含继承自父角色的权限与按命名空间授予的权限），被任一角色拒绝的权限不返回
+			queryPerms := `WITH RECURSIVE ` + pkgs.UserRolesCTE(r.tables, "ur.user_id = $1") + `
+				SELECT p.id, p.name, p.type, p.metadata FROM ` + r.tables.Permission + ` p
+				INNER JOIN (` + pkgs.RolePermissionsSQL(r.tables) + `) rp ON p.id = rp.permission_id
+				WHERE rp.role_id IN (SELECT role_id FROM user_roles)
+				GROUP BY p.id
+				HAVING bool_and(rp.effect = 'allow')`
+			if err := db.SelectContext(ctx, &perms, queryPerms, userID); err != nil {
+				return fmt.Errorf("查询权限失败: %w", err)
+			}
+			return nil
+		})
+		if err := g.Wait(); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
 				return mo.Err[UserDetailRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
 			}
-			r.logger.Error("查询用户失败", zap.Error(err))
-			return mo.Err[UserDetailRes](pkgs.NewApiError(http.StatusInternalServerError, "查询失败"))
-		}
-
-		// 查询角色列表
-		var roles []RoleEntity
-		queryRoles := `SELECT r.id, r.name, r.description FROM iacc_role r INNER JOIN iacc_user_role ur ON r.id = ur.role_id WHERE ur.user_id = $1`
-		if err = r.db.SelectContext(c.Request.Context(), &roles, queryRoles, userID); err != nil {
-			r.logger.Error("查询角色失败", zap.Error(err))
-			return mo.Err[UserDetailRes](pkgs.NewApiError(http.StatusInternalServerError, "查询失败"))
-		}
-
-		// 查询权限列表
-		var perms []PermissionEntity
-		queryPerms := `SELECT p.id, p.name, p.type, p.metadata FROM iacc_permission p INNER JOIN iacc_role_permission rp ON p.id = rp.permission_id INNER JOIN iacc_user_role ur ON rp.role_id = ur.role_id WHERE ur.user_id = $1`
-		if err = r.db.SelectContext(c.Request.Context(), &perms, queryPerms, userID); err != nil {
-			r.logger.Error("查询权限失败", zap.Error(err))
+			r.log(c).Error("查询用户详情失败", zap.Error(err))
 			return mo.Err[UserDetailRes](pkgs.NewApiError(http.StatusInternalServerError, "查询失败"))
 		}
 
@@ -198,7 +456,7 @@ func (r *Repository) UserDetail(c *gin.Context) func(string) mo.Result[UserDetai
 		// 处理手机号
 		phone := ""
 		if user.Phone != nil {
-			phone = *user.Phone
+			phone = string(*user.Phone)
 		}
 
 		return mo.Ok(UserDetailRes{
@@ -208,19 +466,325 @@ func (r *Repository) UserDetail(c *gin.Context) func(string) mo.Result[UserDetai
 			Profile:     user.Profile,
 			Roles:       userRoles,
 			Permissions: permList,
-			CreatedAt:   user.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
+			CreatedAt:   pkgs.FormatTime(c, user.CreatedAt),
+			UpdatedAt:   pkgs.FormatTime(c, user.UpdatedAt),
 		})
 	}
 }
 
-// generateToken 生成 JWT 令牌
-func (r *Repository) generateToken(userID string, expire time.Duration) (string, error) {
+// IssueTokens 不经过登录流程直接为用户签发访问令牌与刷新令牌，供测试环境的令牌签发接口使用
+func (r *Repository) IssueTokens(c *gin.Context, userID string, accessTTL, refreshTTL time.Duration) (LoginRes, error) {
+	version, err := r.blacklist.Version(c, userID)
+	if err != nil {
+		return LoginRes{}, err
+	}
+	accessToken, err := r.generateToken(userID, pkgs.TokenTypeAccess, accessTTL, tokenBinding{}, version)
+	if err != nil {
+		return LoginRes{}, err
+	}
+	refreshToken, err := r.issueRefreshToken(c, userID, refreshTTL, tokenBinding{})
+	if err != nil {
+		return LoginRes{}, err
+	}
+	return LoginRes{AccessToken: accessToken, RefreshToken: refreshToken, ExpiresIn: int64(accessTTL.Seconds())}, nil
+}
+
+// generateToken 生成 JWT 令牌，typ 为 pkgs.TokenTypeAccess 或 pkgs.TokenTypeRefresh，刷新令牌带上绑定的设备记录与客户端
+// 每个令牌带有唯一标识（jti），退出登录时据此加入黑名单；访问令牌带上用户当前的令牌版本，批量撤销提升版本后失效
+func (r *Repository) generateToken(userID, typ string, expire time.Duration, binding tokenBinding, version int) (string, error) {
+	return r.signToken(r.tokenClaims(userID, typ, expire, binding, version))
+}
+
+// issueRefreshToken 生成刷新令牌并登记签发记录，批量撤销令牌时据此统计命中条件的令牌与用户
+func (r *Repository) issueRefreshToken(c *gin.Context, userID string, expire time.Duration, binding tokenBinding) (string, error) {
+	claims := r.tokenClaims(userID, pkgs.TokenTypeRefresh, expire, binding, 0)
+	if err := r.blacklist.Register(c, claims); err != nil {
+		return "", err
+	}
+	return r.signToken(claims)
+}
+
+func (r *Repository) tokenClaims(userID, typ string, expire time.Duration, binding tokenBinding, version int) jwt.MapClaims {
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(expire).Unix(),
-		"iat":     time.Now().Unix(),
+		"user_id":           userID,
+		pkgs.TokenTypeClaim: typ,
+		pkgs.TokenIDClaim:   uuid.NewString(),
+		"exp":               now.Add(expire).Unix(),
+		"iat":               now.Unix(),
 	}
+	if typ == pkgs.TokenTypeAccess {
+		claims[pkgs.TokenVersionClaim] = version
+	}
+	if binding.Device != "" {
+		claims[deviceClaim] = binding.Device
+	}
+	if binding.Client != "" {
+		claims[clientClaim] = binding.Client
+	}
+	return claims
+}
+
+func (r *Repository) signToken(claims jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(r.config.JWT.Secret))
 }
+
+// recordAuthEvent 按结果记录登录或刷新令牌的安全事件，失败时带上业务码与原因
+func (r *Repository) recordAuthEvent(c *gin.Context, userID string, err error, success, failure string, detail map[string]any) {
+	if !r.events.Enabled() {
+		return
+	}
+	if detail == nil {
+		detail = map[string]any{}
+	}
+	eventType := success
+	if err != nil {
+		eventType = failure
+		var apiErr *pkgs.ApiError
+		if errors.As(err, &apiErr) {
+			detail["code"] = apiErr.Code
+			detail["reason"] = apiErr.Message
+		}
+	}
+	r.events.RecordUser(c, userID, eventType, detail)
+}
+
+// tokenLifetimes 返回客户端的访问令牌与刷新令牌有效期
+// 未指定客户端时使用运行时设置中的默认有效期（jwt.require_client 开启时拒绝）；客户端不存在或已停用时拒绝
+func (r *Repository) tokenLifetimes(c *gin.Context, clientID string) (time.Duration, time.Duration, *pkgs.ApiError) {
+	if clientID == "" {
+		if r.config.JWT.RequireClient {
+			return 0, 0, pkgs.NewApiError(http.StatusBadRequest, "缺少客户端标识")
+		}
+		return r.settings.AccessTokenExpire(), r.settings.RefreshTokenExpire(), nil
+	}
+
+	var ttl struct {
+		Access  int `db:"access_token_ttl"`
+		Refresh int `db:"refresh_token_ttl"`
+	}
+	query := `SELECT access_token_ttl, refresh_token_ttl FROM ` + r.tables.Client + ` WHERE client_id = $1 AND enabled`
+	if err := r.conn(c).GetContext(c.Request.Context(), &ttl, query, clientID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, pkgs.NewApiError(http.StatusUnauthorized, "客户端不存在或已停用")
+		}
+		r.log(c).Error("查询客户端失败", zap.Error(err))
+		return 0, 0, pkgs.NewApiError(http.StatusInternalServerError, "查询客户端失败")
+	}
+	return time.Duration(ttl.Access) * time.Second, time.Duration(ttl.Refresh) * time.Second, nil
+}
+
+// registerDevice 登记用户的登录设备，已登记的设备更新名称、User-Agent 与最近使用信息
+func (r *Repository) registerDevice(c *gin.Context, userID, deviceID string) (*DeviceEntity, error) {
+	device := &DeviceEntity{
+		UserID:    userID,
+		DeviceID:  deviceID,
+		Name:      headerValue(c, DeviceNameHeader, 100),
+		UserAgent: headerValue(c, "User-Agent", 500),
+		LastIP:    headerLimit(c.ClientIP(), 45),
+	}
+	if err := r.ids.Assign(&device.ID); err != nil {
+		return nil, err
+	}
+	columns, values := r.ids.Insert("user_id", "device_id", "name", "user_agent", "last_ip")
+	query := `INSERT INTO ` + r.tables.UserDevice + ` AS d (` + columns + `) VALUES (` + values + `)
+		ON CONFLICT (user_id, device_id) DO UPDATE SET
+			name = COALESCE(EXCLUDED.name, d.name), user_agent = EXCLUDED.user_agent, last_ip = EXCLUDED.last_ip, last_seen_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at, updated_at, last_seen_at`
+	stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	if err := stmt.GetContext(c.Request.Context(), device, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// checkRefreshDevice 校验刷新令牌绑定的设备：
+//  1. 账号已停用时拒绝；令牌签发于会话撤销之前时拒绝；
+//  2. 绑定的设备已被删除时拒绝；请求携带的设备标识与绑定的设备不一致时拒绝；
+//  3. 用户开启严格设备模式时，刷新令牌必须绑定设备且请求必须携带该设备的标识；
+//  4. 通过后更新设备的最近使用信息。
+func (r *Repository) checkRefreshDevice(c *gin.Context, userID, deviceRecordID string, issuedAt int64) *pkgs.ApiError {
+	var state struct {
+		Strict    bool       `db:"strict_device"`
+		Disabled  bool       `db:"disabled"`
+		RevokedAt *time.Time `db:"sessions_revoked_at"`
+	}
+	err := r.conn(c).GetContext(c.Request.Context(), &state, `SELECT strict_device, disabled_at IS NOT NULL AS disabled, sessions_revoked_at FROM `+r.tables.User+` WHERE id = $1`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkgs.NewApiError(http.StatusUnauthorized, "刷新令牌无效")
+		}
+		r.log(c).Error("查询用户失败", zap.Error(err))
+		return pkgs.NewApiError(http.StatusInternalServerError, "刷新失败")
+	}
+	if state.Disabled {
+		return pkgs.NewApiError(http.StatusUnauthorized, "账号已停用")
+	}
+	// iat 精确到秒，与撤销时间同一秒签发的令牌同样视为已撤销
+	if state.RevokedAt != nil && issuedAt <= state.RevokedAt.Unix() {
+		return pkgs.NewApiError(http.StatusUnauthorized, "会话已撤销，请重新登录")
+	}
+	strict := state.Strict
+
+	headerDeviceID := c.GetHeader(DeviceIDHeader)
+	if deviceRecordID == "" {
+		if strict {
+			return pkgs.NewApiError(http.StatusUnauthorized, "已开启严格设备模式，未登记的设备请重新登录")
+		}
+		return nil
+	}
+
+	var device DeviceEntity
+	query := `SELECT id, device_id FROM ` + r.tables.UserDevice + ` WHERE id = $1 AND user_id = $2`
+	err = r.conn(c).GetContext(c.Request.Context(), &device, query, deviceRecordID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkgs.NewApiError(http.StatusUnauthorized, "设备已移除，请重新登录")
+		}
+		r.log(c).Error("查询设备失败", zap.Error(err))
+		return pkgs.NewApiError(http.StatusInternalServerError, "刷新失败")
+	}
+	if (headerDeviceID != "" || strict) && headerDeviceID != device.DeviceID {
+		return pkgs.NewApiError(http.StatusUnauthorized, "刷新令牌与当前设备不匹配")
+	}
+
+	// 最近使用信息更新失败不影响刷新
+	query = `UPDATE ` + r.tables.UserDevice + ` SET last_ip = $1, last_seen_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := r.conn(c).ExecContext(c.Request.Context(), query, headerLimit(c.ClientIP(), 45), device.ID); err != nil {
+		r.log(c).Warn("更新设备使用信息失败", zap.Error(err))
+	}
+	return nil
+}
+
+// QueryDevices 查询当前用户登记的设备，按最近使用时间倒序
+func (r *Repository) QueryDevices(c *gin.Context) func(string) mo.Result[QueryDevicesRes] {
+	return func(userID string) mo.Result[QueryDevicesRes] {
+		var strict bool
+		err := r.conn(c).GetContext(c.Request.Context(), &strict, `SELECT strict_device FROM `+r.tables.User+` WHERE id = $1`, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[QueryDevicesRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
+			}
+			r.log(c).Error("查询用户失败", zap.Error(err))
+			return mo.Err[QueryDevicesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询设备失败"))
+		}
+
+		var entities []DeviceEntity
+		query := `SELECT id, created_at, updated_at, user_id, device_id, name, user_agent, last_ip, last_seen_at FROM ` + r.tables.UserDevice + `
+			WHERE user_id = $1 ORDER BY last_seen_at DESC`
+		if err := r.conn(c).SelectContext(c.Request.Context(), &entities, query, userID); err != nil {
+			return mo.Err[QueryDevicesRes](pkgs.DBError(r.log(c), err, "查询设备失败"))
+		}
+
+		current := c.GetHeader(DeviceIDHeader)
+		list := make([]DeviceItem, len(entities))
+		for i, entity := range entities {
+			list[i] = DeviceItem{
+				ID:         entity.ID,
+				DeviceID:   entity.DeviceID,
+				Name:       entity.Name,
+				UserAgent:  entity.UserAgent,
+				LastIP:     entity.LastIP,
+				Current:    current != "" && entity.DeviceID == current,
+				CreatedAt:  pkgs.FormatTime(c, entity.CreatedAt),
+				LastSeenAt: pkgs.FormatTime(c, entity.LastSeenAt),
+			}
+		}
+		return mo.Ok(QueryDevicesRes{List: list, Total: int64(len(list)), StrictDevice: strict})
+	}
+}
+
+// DeleteDevice 删除当前用户的设备，绑定该设备的刷新令牌随之失效
+func (r *Repository) DeleteDevice(c *gin.Context) func(*DeleteDeviceReq) mo.Result[DeleteDeviceRes] {
+	return func(req *DeleteDeviceReq) mo.Result[DeleteDeviceRes] {
+		query := `DELETE FROM ` + r.tables.UserDevice + ` WHERE id = $1 AND user_id = $2`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID, pkgs.CurrentUserID(c))
+		if err != nil {
+			return mo.Err[DeleteDeviceRes](pkgs.DBError(r.log(c), err, "删除设备失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[DeleteDeviceRes](pkgs.NewApiError(http.StatusInternalServerError, "删除设备失败"))
+		}
+		if affectedRows == 0 {
+			return mo.Err[DeleteDeviceRes](pkgs.NewApiError(http.StatusNotFound, "设备不存在"))
+		}
+		return mo.Ok(affectedRows)
+	}
+}
+
+// RevokeSessions 撤销用户的全部会话：删除登录设备，并记录撤销时间使此前签发的刷新令牌全部失效
+// 已签发的访问令牌在过期前仍然有效。供运维命令使用，返回删除的设备数。
+func (r *Repository) RevokeSessions(c *gin.Context) func(*RevokeSessionsReq) mo.Result[RevokeSessionsRes] {
+	return func(req *RevokeSessionsReq) mo.Result[RevokeSessionsRes] {
+		tx, err := r.conn(c).BeginTxx(c.Request.Context(), nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[RevokeSessionsRes](pkgs.NewApiError(http.StatusInternalServerError, "撤销会话失败"))
+		}
+		defer tx.Rollback()
+
+		var userID string
+		query := `UPDATE ` + r.tables.User + ` SET sessions_revoked_at = CURRENT_TIMESTAMP WHERE username = $1 RETURNING id`
+		if err := tx.GetContext(c.Request.Context(), &userID, query, req.Username); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[RevokeSessionsRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
+			}
+			return mo.Err[RevokeSessionsRes](pkgs.DBError(r.log(c), err, "撤销会话失败"))
+		}
+		res, err := tx.ExecContext(c.Request.Context(), `DELETE FROM `+r.tables.UserDevice+` WHERE user_id = $1`, userID)
+		if err != nil {
+			return mo.Err[RevokeSessionsRes](pkgs.DBError(r.log(c), err, "撤销会话失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[RevokeSessionsRes](pkgs.NewApiError(http.StatusInternalServerError, "撤销会话失败"))
+		}
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交事务失败", zap.Error(err))
+			return mo.Err[RevokeSessionsRes](pkgs.NewApiError(http.StatusInternalServerError, "撤销会话失败"))
+		}
+		r.events.RecordUser(c, userID, pkgs.SecurityEventSessionsRevoke, map[string]any{"devices": affectedRows})
+		return mo.Ok(affectedRows)
+	}
+}
+
+// SetStrictDevice 开启或关闭当前用户的严格设备模式
+func (r *Repository) SetStrictDevice(c *gin.Context) func(*StrictDeviceReq) mo.Result[StrictDeviceRes] {
+	return func(req *StrictDeviceReq) mo.Result[StrictDeviceRes] {
+		query := `UPDATE ` + r.tables.User + ` SET strict_device = $1 WHERE id = $2`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, *req.Enabled, pkgs.CurrentUserID(c))
+		if err != nil {
+			return mo.Err[StrictDeviceRes](pkgs.DBError(r.log(c), err, "设置严格设备模式失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[StrictDeviceRes](pkgs.NewApiError(http.StatusInternalServerError, "设置严格设备模式失败"))
+		}
+		return mo.Ok(affectedRows)
+	}
+}
+
+// headerValue 读取请求头，为空时返回 nil，超长时截断
+func headerValue(c *gin.Context, name string, limit int) *string {
+	return headerLimit(strings.TrimSpace(c.GetHeader(name)), limit)
+}
+
+// headerLimit 按字符数截断，为空时返回 nil
+func headerLimit(value string, limit int) *string {
+	if value == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(value) > limit {
+		value = string([]rune(value)[:limit])
+	}
+	return &value
+}
@@ -2,18 +2,23 @@ package auth
 
 import (
 	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/pkgs"
 	"time"
 )
 
 // 数据库表iacc_user的表结构
 type UserEntity struct {
-	ID        string       `db:"id" label:"用户ID"`
-	CreatedAt time.Time    `db:"created_at" label:"创建时间"`
-	UpdatedAt time.Time    `db:"updated_at" label:"更新时间"`
-	Username  string       `db:"username" label:"用户名"`
-	Password  string       `db:"password" label:"密码"`
-	Phone     *string      `db:"phone" label:"手机号"`
-	Profile   user.Profile `db:"profile" label:"个人信息"`
+	ID        string                `db:"id" label:"用户ID"`
+	CreatedAt time.Time             `db:"created_at" label:"创建时间"`
+	UpdatedAt time.Time             `db:"updated_at" label:"更新时间"`
+	Username  string                `db:"username" label:"用户名"`
+	Password  *string               `db:"password" label:"密码"`
+	Phone     *pkgs.EncryptedString `db:"phone" label:"手机号"`
+	Profile   user.Profile          `db:"profile" label:"个人信息"`
+	// 停用时间，非空表示账号已停用
+	DisabledAt *time.Time `db:"disabled_at" label:"停用时间"`
+	// 令牌版本，写入访问令牌，批量撤销时提升
+	TokenVersion int `db:"token_version" label:"令牌版本"`
 }
 
 // 数据库表iacc_role的表结构
@@ -39,6 +44,8 @@ type PermissionEntity struct {
 type LoginReq struct {
 	Username string `json:"username" validate:"required" label:"用户名"`
 	Password string `json:"password" validate:"required" label:"密码"`
+	// 已登记的客户端标识，令牌有效期按客户端配置；未携带时使用配置文件中的默认有效期
+	ClientID string `json:"client_id,omitempty" validate:"omitempty,max=64" label:"客户端标识"`
 }
 
 // 用户登录响应
@@ -48,7 +55,7 @@ type LoginRes struct {
 	ExpiresIn    int64  `json:"expires_in" label:"访问令牌过期秒数"`
 }
 
-// 刷新令牌请求
+// 刷新令牌请求，开启 Cookie 鉴权时可以不传刷新令牌，使用 refresh_token cookie
 type RefreshTokenReq struct {
 	RefreshToken string `json:"refresh_token" validate:"required" label:"刷新令牌"`
 }
@@ -60,6 +67,23 @@ type RefreshTokenRes struct {
 	ExpiresIn    int64  `json:"expires_in" label:"访问令牌过期秒数"`
 }
 
+// 退出登录的请求参数，携带刷新令牌时一并撤销
+type LogoutReq struct {
+	RefreshToken string `json:"refresh_token,omitempty" label:"刷新令牌"`
+}
+
+// 退出登录的响应，撤销的令牌数
+type LogoutRes = int64
+
+// 修改密码的请求参数，新密码不能与当前密码相同
+type ChangePasswordReq struct {
+	CurrentPassword string `json:"current_password" validate:"required" label:"当前密码"`
+	NewPassword     string `json:"new_password" validate:"required,max=72,nefield=CurrentPassword" label:"新密码"`
+}
+
+// 修改密码的响应
+type ChangePasswordRes = int64
+
 // 用户详情响应
 type UserDetailRes struct {
 	ID          string        `json:"id" label:"用户ID"`
@@ -88,3 +112,80 @@ type UserPermRes struct {
 	Path   string `json:"path,omitempty" label:"接口路径"`
 	Method string `json:"method,omitempty" label:"请求方法"`
 }
+
+// 客户端生成并持久保存的设备唯一标识，登录时携带则登记设备并将刷新令牌绑定到该设备
+const (
+	DeviceIDHeader   = "X-Device-ID"
+	DeviceNameHeader = "X-Device-Name"
+)
+
+// 刷新令牌中绑定的设备记录ID与客户端标识
+const (
+	deviceClaim = "device"
+	clientClaim = "client_id"
+)
+
+// 统计各密码格式用户数的定时任务周期，每 10 分钟一次
+const passwordHashMetricsSchedule = "*/10 * * * *"
+
+// 令牌绑定的设备与客户端，刷新时沿用
+type tokenBinding struct {
+	Device string
+	Client string
+}
+
+// 数据库表iacc_user_device的表结构
+type DeviceEntity struct {
+	ID         string    `db:"id" label:"设备记录ID"`
+	CreatedAt  time.Time `db:"created_at" label:"首次登录时间"`
+	UpdatedAt  time.Time `db:"updated_at" label:"更新时间"`
+	UserID     string    `db:"user_id" label:"用户ID"`
+	DeviceID   string    `db:"device_id" label:"设备标识"`
+	Name       *string   `db:"name" label:"设备名称"`
+	UserAgent  *string   `db:"user_agent" label:"User-Agent"`
+	LastIP     *string   `db:"last_ip" label:"最近IP"`
+	LastSeenAt time.Time `db:"last_seen_at" label:"最近使用时间"`
+}
+
+// 设备条目
+type DeviceItem struct {
+	ID         string  `json:"id" label:"设备记录ID"`
+	DeviceID   string  `json:"device_id" label:"设备标识"`
+	Name       *string `json:"name,omitempty" label:"设备名称"`
+	UserAgent  *string `json:"user_agent,omitempty" label:"User-Agent"`
+	LastIP     *string `json:"last_ip,omitempty" label:"最近IP"`
+	Current    bool    `json:"current" label:"是否为当前请求的设备"`
+	CreatedAt  string  `json:"created_at" label:"首次登录时间"`
+	LastSeenAt string  `json:"last_seen_at" label:"最近使用时间"`
+}
+
+// 查询当前用户设备列表的响应
+type QueryDevicesRes struct {
+	List         []DeviceItem `json:"list"`
+	Total        int64        `json:"total"`
+	StrictDevice bool         `json:"strict_device" label:"是否开启严格设备模式"`
+}
+
+// 删除设备的请求参数
+type DeleteDeviceReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"设备记录ID"`
+}
+
+// 删除设备的响应
+type DeleteDeviceRes = int64
+
+// 撤销用户全部会话的请求参数
+type RevokeSessionsReq struct {
+	Username string `validate:"required" label:"用户名"`
+}
+
+// 撤销会话的响应，删除的登录设备数
+type RevokeSessionsRes = int64
+
+// 设置严格设备模式的请求体，开启后只允许已登记的设备刷新令牌
+type StrictDeviceReq struct {
+	Enabled *bool `json:"enabled" validate:"required" label:"是否开启"`
+}
+
+// 设置严格设备模式的响应
+type StrictDeviceRes = int64
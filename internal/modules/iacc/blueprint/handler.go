@@ -0,0 +1,170 @@
+// Package blueprint API.
+//
+// 入职蓝图管理，按岗位预设角色与默认个人信息，通过蓝图创建用户时一并应用。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package blueprint
+
+import (
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+}
+
+func NewBlueprintHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator) *Handler {
+	return &Handler{
+		db:        db,
+		logger:    logger,
+		validator: validator,
+		repository: &Repository{
+			db:     db,
+			logger: logger,
+			tables: tables,
+			pool:   pool,
+			ids:    ids,
+		},
+	}
+}
+
+// Create 创建蓝图
+//
+//	@Summary  创建蓝图
+//	@Description  创建入职蓝图，包含角色与默认个人信息；角色必须存在，通过 POST /user/from-blueprint/{blueprintId} 按蓝图创建用户
+//	@Tags   blueprint
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  CreateReq true  "创建蓝图请求参数"
+//	@Success  200   {object}  pkgs.Response{data=CreateRes}  "创建成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误或角色不存在"
+//	@Failure  409   {object}  pkgs.Response       "蓝图名称已存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/blueprint"}
+//	@Router   /blueprint [post]
+func (h *Handler) Create(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[CreateReq](c),
+		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+		result.FlatMap(h.repository.Create(c)),
+	).Match(
+		pkgs.HandleSuccess[CreateRes](c),
+		pkgs.HandleError[CreateRes](c),
+	)
+}
+
+// GetByID 根据ID获取蓝图
+//
+//	@Summary  根据ID获取蓝图
+//	@Description  返回蓝图详情，roles 为蓝图中仍存在的角色
+//	@Tags   blueprint
+//	@Produce  json
+//	@Param    id  path  string  true  "蓝图ID"
+//	@Success  200 {object}  pkgs.Response{data=GetByIDRes} "获取成功"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response       "蓝图不存在"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/blueprint/:id"}
+//	@Router   /blueprint/{id} [get]
+func (h *Handler) GetByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[GetByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[GetByIDReq](h.validator)),
+		result.FlatMap(h.repository.GetByID(c)),
+	).Match(
+		pkgs.HandleSuccess[GetByIDRes](c),
+		pkgs.HandleError[GetByIDRes](c),
+	)
+}
+
+// UpdateByID 根据ID更新蓝图
+//
+//	@Summary  根据ID更新蓝图
+//	@Description  修改名称、描述、角色或默认个人信息，role_ids 与 profile 传入时整体替换；只影响之后按蓝图创建的用户
+//	@Tags   blueprint
+//	@Accept   json
+//	@Produce  json
+//	@Param    id    path  string          true  "蓝图ID"
+//	@Param    request body  UpdateByIDReq true  "更新蓝图请求参数"
+//	@Success  200   {object}  pkgs.Response{data=UpdateByIDRes}       "更新成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误或角色不存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/blueprint/:id"}
+//	@Router   /blueprint/{id} [put]
+func (h *Handler) UpdateByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUriAndJSON[UpdateByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[UpdateByIDReq](h.validator)),
+		result.FlatMap(h.repository.UpdateByID(c)),
+	).Match(
+		pkgs.HandleSuccess[UpdateByIDRes](c),
+		pkgs.HandleError[UpdateByIDRes](c),
+	)
+}
+
+// DeleteByID 根据ID删除蓝图
+//
+//	@Summary  根据ID删除蓝图
+//	@Description  删除蓝图，已按蓝图创建的用户不受影响
+//	@Tags   blueprint
+//	@Produce  json
+//	@Param    id  path  string  true  "蓝图ID"
+//	@Success  200 {object}  pkgs.Response{data=DeleteByIDRes} "删除成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"DELETE","path":"/v1/blueprint/:id"}
+//	@Router   /blueprint/{id} [delete]
+func (h *Handler) DeleteByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[DeleteByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
+		result.FlatMap(h.repository.DeleteByID(c)),
+	).Match(
+		pkgs.HandleSuccess[DeleteByIDRes](c),
+		pkgs.HandleError[DeleteByIDRes](c),
+	)
+}
+
+// QueryList 获取蓝图列表
+//
+//	@Summary  获取蓝图列表
+//	@Description  获取入职蓝图列表
+//	@Tags   blueprint
+//	@Produce  json
+//	@Param    page    query int   false "页码"  default(1)
+//	@Param    pageSize  query int   false "每页数量"  default(10)
+//	@Param    name    query string  false "蓝图名称"
+//	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/blueprint/list"}
+//	@Router   /blueprint/list [get]
+func (h *Handler) QueryList(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[QueryListReq](c),
+		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.QueryList(c)),
+	).Match(
+		pkgs.HandleSuccess[QueryListRes](c),
+		pkgs.HandleError[QueryListRes](c),
+	)
+}
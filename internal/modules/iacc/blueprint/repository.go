@@ -0,0 +1,263 @@
+package blueprint
+
+import (
+	"database/sql"
+	"errors"
+	"go-pg-demo/pkgs"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+	tables *pkgs.TableNames
+	pool   *pkgs.TenantPool
+	ids    *pkgs.IDGenerator
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+const blueprintColumns = `id, name, description, role_ids, profile, created_at, updated_at`
+
+func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
+	return func(req *CreateReq) mo.Result[CreateRes] {
+		if apiErr := r.checkRoles(c, req.RoleIDs); apiErr != nil {
+			return mo.Err[CreateRes](apiErr)
+		}
+
+		entity := &BlueprintEntity{
+			Name:        req.Name,
+			Description: req.Description,
+			RoleIDs:     pq.StringArray(dedupe(req.RoleIDs)),
+			Profile:     req.Profile,
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建蓝图失败"))
+		}
+
+		// 数据库操作，名称重复时不插入
+		columns, values := r.ids.Insert("name", "description", "role_ids", "profile")
+		query := `INSERT INTO ` + r.tables.Blueprint + ` (` + columns + `) VALUES (` + values + `) ON CONFLICT (name) DO NOTHING RETURNING id`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
+		if err != nil {
+			r.log(c).Error("准备插入语句失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建蓝图失败"))
+		}
+		defer stmt.Close()
+		if err := stmt.GetContext(c.Request.Context(), &entity.ID, entity); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[CreateRes](pkgs.NewApiError(http.StatusConflict, "蓝图名称已存在"))
+			}
+			return mo.Err[CreateRes](pkgs.DBError(r.log(c), err, "创建蓝图失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(CreateRes(entity.ID))
+	}
+}
+
+func (r *Repository) GetByID(c *gin.Context) func(*GetByIDReq) mo.Result[GetByIDRes] {
+	return func(req *GetByIDReq) mo.Result[GetByIDRes] {
+		var entity BlueprintEntity
+		err := r.conn(c).GetContext(c.Request.Context(), &entity, `SELECT `+blueprintColumns+` FROM `+r.tables.Blueprint+` WHERE id = $1`, req.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusNotFound, "蓝图不存在"))
+			}
+			return mo.Err[GetByIDRes](pkgs.DBError(r.log(c), err, "获取蓝图失败"))
+		}
+
+		// 查询蓝图中仍存在的角色
+		res := toGetByIDRes(c, &entity)
+		if len(entity.RoleIDs) > 0 {
+			query := `SELECT id, name FROM ` + r.tables.Role + ` WHERE id = ANY($1) ORDER BY name`
+			if err := r.conn(c).SelectContext(c.Request.Context(), &res.Roles, query, entity.RoleIDs); err != nil {
+				r.log(c).Error("查询蓝图角色失败", zap.Error(err))
+				return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "获取蓝图失败"))
+			}
+		}
+		return mo.Ok(res)
+	}
+}
+
+func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[UpdateByIDRes] {
+	return func(req *UpdateByIDReq) mo.Result[UpdateByIDRes] {
+		// 动态构建更新语句
+		params := map[string]any{"id": req.ID}
+		var setClauses []string
+		set := func(column string, value any) {
+			params[column] = value
+			setClauses = append(setClauses, column+" = :"+column)
+		}
+		if req.Name != nil {
+			set("name", *req.Name)
+		}
+		if req.Description != nil {
+			set("description", *req.Description)
+		}
+		if req.RoleIDs != nil {
+			if apiErr := r.checkRoles(c, req.RoleIDs); apiErr != nil {
+				return mo.Err[UpdateByIDRes](apiErr)
+			}
+			set("role_ids", pq.StringArray(dedupe(req.RoleIDs)))
+		}
+		if req.Profile != nil {
+			set("profile", *req.Profile)
+		}
+
+		// 如果没有需要更新的字段，直接返回成功
+		if len(setClauses) == 0 {
+			return mo.Ok(UpdateByIDRes(0))
+		}
+
+		query := "UPDATE " + r.tables.Blueprint + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
+		if err != nil {
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新蓝图失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新蓝图失败"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
+	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
+		res, err := r.conn(c).ExecContext(c.Request.Context(), `DELETE FROM `+r.tables.Blueprint+` WHERE id = $1`, req.ID)
+		if err != nil {
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除蓝图失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除蓝图失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
+	return func(req *QueryListReq) mo.Result[QueryListRes] {
+		// 构建查询
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
+		}
+		whereCondition := ""
+		if req.Name != "" {
+			whereCondition = " WHERE name ILIKE :name"
+			params["name"] = "%" + req.Name + "%"
+		}
+
+		// 查询总数
+		db := r.conn(c)
+		var total int64
+		countQuery, countArgs, err := db.BindNamed("SELECT count(*) FROM "+r.tables.Blueprint+whereCondition, params)
+		if err != nil {
+			r.log(c).Error("构建计数查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询蓝图列表失败"))
+		}
+		if err := db.GetContext(c.Request.Context(), &total, countQuery, countArgs...); err != nil {
+			r.log(c).Error("统计蓝图数量失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询蓝图列表失败"))
+		}
+		if total == 0 {
+			return mo.Ok(QueryListRes{List: []GetByIDRes{}, Total: 0})
+		}
+
+		// 查询列表
+		var entities []BlueprintEntity
+		listQuery, listArgs, err := db.BindNamed(`SELECT `+blueprintColumns+` FROM `+r.tables.Blueprint+
+			whereCondition+` ORDER BY created_at DESC, seq DESC LIMIT :limit OFFSET :offset`, params)
+		if err != nil {
+			r.log(c).Error("构建列表查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询蓝图列表失败"))
+		}
+		if err := db.SelectContext(c.Request.Context(), &entities, listQuery, listArgs...); err != nil {
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询蓝图列表失败"))
+		}
+
+		list := make([]GetByIDRes, 0, len(entities))
+		for i := range entities {
+			list = append(list, toGetByIDRes(c, &entities[i]))
+		}
+
+		// 返回结果
+		return mo.Ok(QueryListRes{List: list, Total: total})
+	}
+}
+
+// checkRoles 校验蓝图中的角色都存在，不存在时返回 400 并列出缺失的角色ID
+func (r *Repository) checkRoles(c *gin.Context, roleIDs []string) *pkgs.ApiError {
+	roleIDs = dedupe(roleIDs)
+	if len(roleIDs) == 0 {
+		return nil
+	}
+	var found []string
+	query := `SELECT id FROM ` + r.tables.Role + ` WHERE id = ANY($1)`
+	if err := r.conn(c).SelectContext(c.Request.Context(), &found, query, pkgs.PGArray(roleIDs)); err != nil {
+		r.log(c).Error("查询角色失败", zap.Error(err))
+		return pkgs.NewApiError(http.StatusInternalServerError, "查询角色失败")
+	}
+	var missing []string
+	for _, id := range roleIDs {
+		if !slices.Contains(found, id) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return pkgs.NewApiError(http.StatusBadRequest, "角色不存在: "+strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// dedupe 去掉重复的ID，保持原有顺序
+func dedupe(ids []string) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// toGetByIDRes 将数据库实体转换为蓝图详情
+func toGetByIDRes(c *gin.Context, entity *BlueprintEntity) GetByIDRes {
+	roleIDs := []string(entity.RoleIDs)
+	if roleIDs == nil {
+		roleIDs = []string{}
+	}
+	return GetByIDRes{
+		ID:          entity.ID,
+		Name:        entity.Name,
+		Description: entity.Description,
+		RoleIDs:     roleIDs,
+		Profile:     entity.Profile,
+		CreatedAt:   pkgs.FormatTime(c, entity.CreatedAt),
+		UpdatedAt:   pkgs.FormatTime(c, entity.UpdatedAt),
+	}
+}
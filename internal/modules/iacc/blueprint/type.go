@@ -0,0 +1,86 @@
+package blueprint
+
+import (
+	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/pkgs"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// 数据库表 iacc_blueprint 的表结构
+type BlueprintEntity struct {
+	ID          string         `db:"id" label:"蓝图ID"`
+	CreatedAt   time.Time      `db:"created_at" label:"创建时间"`
+	UpdatedAt   time.Time      `db:"updated_at" label:"更新时间"`
+	Name        string         `db:"name" label:"蓝图名称"`
+	Description *string        `db:"description" label:"蓝图描述"`
+	RoleIDs     pq.StringArray `db:"role_ids" label:"角色ID列表"`
+	Profile     user.Profile   `db:"profile" label:"默认个人信息"`
+}
+
+// 创建蓝图的请求 DTO
+type CreateReq struct {
+	Name        string       `json:"name" validate:"required,max=100" label:"蓝图名称"`
+	Description *string      `json:"description,omitempty" validate:"omitempty,max=500" label:"蓝图描述"`
+	RoleIDs     []string     `json:"role_ids" validate:"max=100,dive,uuid" label:"角色ID列表"`
+	Profile     user.Profile `json:"profile" label:"默认个人信息"`
+}
+
+// 创建蓝图的响应 DTO
+type CreateRes string
+
+// 根据ID获取蓝图的参数
+type GetByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"蓝图ID"`
+}
+
+// 蓝图中的角色
+type RoleItem struct {
+	ID   string `db:"id" json:"id" label:"角色ID"`
+	Name string `db:"name" json:"name" label:"角色名称"`
+}
+
+// 蓝图详情，roles 只包含仍存在的角色
+type GetByIDRes struct {
+	ID          string       `json:"id" label:"蓝图ID"`
+	Name        string       `json:"name" label:"蓝图名称"`
+	Description *string      `json:"description,omitempty" label:"蓝图描述"`
+	RoleIDs     []string     `json:"role_ids" label:"角色ID列表"`
+	Roles       []RoleItem   `json:"roles,omitempty" label:"角色列表"`
+	Profile     user.Profile `json:"profile" label:"默认个人信息"`
+	CreatedAt   string       `json:"created_at" label:"创建时间"`
+	UpdatedAt   string       `json:"updated_at" label:"更新时间"`
+}
+
+// 更新蓝图的请求体，role_ids、profile 传入时整体替换
+type UpdateByIDReq struct {
+	ID          string        `uri:"id" validate:"required,uuid" label:"蓝图ID"`
+	Name        *string       `json:"name,omitempty" validate:"omitempty,max=100" label:"蓝图名称"`
+	Description *string       `json:"description,omitempty" validate:"omitempty,max=500" label:"蓝图描述"`
+	RoleIDs     []string      `json:"role_ids,omitempty" validate:"omitempty,max=100,dive,uuid" label:"角色ID列表"`
+	Profile     *user.Profile `json:"profile,omitempty" label:"默认个人信息"`
+}
+
+// 更新蓝图的响应体
+type UpdateByIDRes = int64
+
+// 根据ID删除蓝图的请求参数
+type DeleteByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"蓝图ID"`
+}
+
+// 根据ID删除蓝图的响应
+type DeleteByIDRes = int64
+
+// 查询蓝图列表的请求体
+type QueryListReq struct {
+	pkgs.Pagination
+	Name string `form:"name,omitempty" validate:"omitempty" label:"蓝图名称"`
+}
+
+// 查询蓝图列表的响应体
+type QueryListRes struct {
+	List  []GetByIDRes `json:"list"`
+	Total int64        `json:"total"`
+}
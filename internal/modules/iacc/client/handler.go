@@ -0,0 +1,175 @@
+// Package client API.
+//
+// 已登记客户端管理，按客户端配置令牌有效期。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package client
+
+import (
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+}
+
+func NewClientHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, createRule)
+	pkgs.RegisterRule(validator, updateRule)
+
+	return &Handler{
+		db:        db,
+		logger:    logger,
+		validator: validator,
+		repository: &Repository{
+			db:     db,
+			logger: logger,
+			tables: tables,
+			pool:   pool,
+			ids:    ids,
+		},
+	}
+}
+
+// Create 登记客户端
+//
+//	@Summary  登记客户端
+//	@Description  登记客户端并配置令牌有效期（秒），登录时通过 client_id 选择客户端；刷新令牌有效期不能短于访问令牌
+//	@Tags   client
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  CreateReq true  "登记客户端请求参数"
+//	@Success  200   {object}  pkgs.Response{data=CreateRes}  "创建成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  409   {object}  pkgs.Response       "客户端标识已存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/client"}
+//	@Router   /client [post]
+func (h *Handler) Create(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[CreateReq](c),
+		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+		result.FlatMap(h.repository.Create(c)),
+	).Match(
+		pkgs.HandleSuccess[CreateRes](c),
+		pkgs.HandleError[CreateRes](c),
+	)
+}
+
+// GetByID 根据ID获取客户端
+//
+//	@Summary  根据ID获取客户端
+//	@Description  根据ID获取客户端
+//	@Tags   client
+//	@Produce  json
+//	@Param    id  path  string  true  "客户端记录ID"
+//	@Success  200 {object}  pkgs.Response{data=GetByIDRes} "获取成功"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response       "客户端不存在"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/client/:id"}
+//	@Router   /client/{id} [get]
+func (h *Handler) GetByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[GetByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[GetByIDReq](h.validator)),
+		result.FlatMap(h.repository.GetByID(c)),
+	).Match(
+		pkgs.HandleSuccess[GetByIDRes](c),
+		pkgs.HandleError[GetByIDRes](c),
+	)
+}
+
+// UpdateByID 根据ID更新客户端
+//
+//	@Summary  根据ID更新客户端
+//	@Description  修改名称、类型、令牌有效期或启用状态，新有效期对之后签发的令牌生效；停用后该客户端不能登录与刷新令牌
+//	@Tags   client
+//	@Accept   json
+//	@Produce  json
+//	@Param    id    path  string          true  "客户端记录ID"
+//	@Param    request body  UpdateByIDReq true  "更新客户端请求参数"
+//	@Success  200   {object}  pkgs.Response{data=UpdateByIDRes}       "更新成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/client/:id"}
+//	@Router   /client/{id} [put]
+func (h *Handler) UpdateByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUriAndJSON[UpdateByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[UpdateByIDReq](h.validator)),
+		result.FlatMap(h.repository.UpdateByID(c)),
+	).Match(
+		pkgs.HandleSuccess[UpdateByIDRes](c),
+		pkgs.HandleError[UpdateByIDRes](c),
+	)
+}
+
+// DeleteByID 根据ID删除客户端
+//
+//	@Summary  根据ID删除客户端
+//	@Description  删除后使用该客户端签发的刷新令牌无法再刷新
+//	@Tags   client
+//	@Produce  json
+//	@Param    id  path  string  true  "客户端记录ID"
+//	@Success  200 {object}  pkgs.Response{data=DeleteByIDRes} "删除成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"DELETE","path":"/v1/client/:id"}
+//	@Router   /client/{id} [delete]
+func (h *Handler) DeleteByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[DeleteByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
+		result.FlatMap(h.repository.DeleteByID(c)),
+	).Match(
+		pkgs.HandleSuccess[DeleteByIDRes](c),
+		pkgs.HandleError[DeleteByIDRes](c),
+	)
+}
+
+// QueryList 获取客户端列表
+//
+//	@Summary  获取客户端列表
+//	@Description  获取已登记的客户端列表
+//	@Tags   client
+//	@Produce  json
+//	@Param    page    query int   false "页码"  default(1)
+//	@Param    pageSize  query int   false "每页数量"  default(10)
+//	@Param    name    query string  false "名称"
+//	@Param    client_type query string  false "客户端类型"  Enums(web, mobile, service)
+//	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/client/list"}
+//	@Router   /client/list [get]
+func (h *Handler) QueryList(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[QueryListReq](c),
+		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.QueryList(c)),
+	).Match(
+		pkgs.HandleSuccess[QueryListRes](c),
+		pkgs.HandleError[QueryListRes](c),
+	)
+}
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"database/sql"
+	"errors"
+	"go-pg-demo/pkgs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+	tables *pkgs.TableNames
+	pool   *pkgs.TenantPool
+	ids    *pkgs.IDGenerator
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+const clientColumns = `id, client_id, name, client_type, access_token_ttl, refresh_token_ttl, enabled, created_at, updated_at`
+
+func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
+	return func(req *CreateReq) mo.Result[CreateRes] {
+		entity := &ClientEntity{
+			ClientID:        req.ClientID,
+			Name:            req.Name,
+			ClientType:      req.ClientType,
+			AccessTokenTTL:  req.AccessTokenTTL,
+			RefreshTokenTTL: req.RefreshTokenTTL,
+			Enabled:         req.Enabled == nil || *req.Enabled,
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建客户端失败"))
+		}
+
+		// 数据库操作，客户端标识重复时不插入
+		columns, values := r.ids.Insert("client_id", "name", "client_type", "access_token_ttl", "refresh_token_ttl", "enabled")
+		query := `INSERT INTO ` + r.tables.Client + ` (` + columns + `) VALUES (` + values + `) ON CONFLICT (client_id) DO NOTHING RETURNING id`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
+		if err != nil {
+			r.log(c).Error("准备插入语句失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建客户端失败"))
+		}
+		defer stmt.Close()
+		if err := stmt.GetContext(c.Request.Context(), &entity.ID, entity); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[CreateRes](pkgs.NewApiError(http.StatusConflict, "客户端标识已存在"))
+			}
+			return mo.Err[CreateRes](pkgs.DBError(r.log(c), err, "创建客户端失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(CreateRes(entity.ID))
+	}
+}
+
+func (r *Repository) GetByID(c *gin.Context) func(*GetByIDReq) mo.Result[GetByIDRes] {
+	return func(req *GetByIDReq) mo.Result[GetByIDRes] {
+		entity, apiErr := r.get(c, req.ID)
+		if apiErr != nil {
+			return mo.Err[GetByIDRes](apiErr)
+		}
+		return mo.Ok(toGetByIDRes(c, entity))
+	}
+}
+
+func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[UpdateByIDRes] {
+	return func(req *UpdateByIDReq) mo.Result[UpdateByIDRes] {
+		// 只修改其中一个有效期时，与另一个的现有值比较
+		if (req.AccessTokenTTL == nil) != (req.RefreshTokenTTL == nil) {
+			current, apiErr := r.get(c, req.ID)
+			if apiErr != nil {
+				return mo.Err[UpdateByIDRes](apiErr)
+			}
+			access, refresh := current.AccessTokenTTL, current.RefreshTokenTTL
+			if req.AccessTokenTTL != nil {
+				access = *req.AccessTokenTTL
+			}
+			if req.RefreshTokenTTL != nil {
+				refresh = *req.RefreshTokenTTL
+			}
+			if refresh < access {
+				return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusBadRequest, "刷新令牌有效期不能短于访问令牌有效期"))
+			}
+		}
+
+		// 动态构建更新语句
+		params := map[string]any{"id": req.ID}
+		var setClauses []string
+		set := func(column string, value any) {
+			params[column] = value
+			setClauses = append(setClauses, column+" = :"+column)
+		}
+		if req.Name != nil {
+			set("name", *req.Name)
+		}
+		if req.ClientType != nil {
+			set("client_type", *req.ClientType)
+		}
+		if req.AccessTokenTTL != nil {
+			set("access_token_ttl", *req.AccessTokenTTL)
+		}
+		if req.RefreshTokenTTL != nil {
+			set("refresh_token_ttl", *req.RefreshTokenTTL)
+		}
+		if req.Enabled != nil {
+			set("enabled", *req.Enabled)
+		}
+
+		// 如果没有需要更新的字段，直接返回成功
+		if len(setClauses) == 0 {
+			return mo.Ok(UpdateByIDRes(0))
+		}
+
+		query := "UPDATE " + r.tables.Client + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
+		if err != nil {
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新客户端失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新客户端失败"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
+	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
+		res, err := r.conn(c).ExecContext(c.Request.Context(), `DELETE FROM `+r.tables.Client+` WHERE id = $1`, req.ID)
+		if err != nil {
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除客户端失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除客户端失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
+	return func(req *QueryListReq) mo.Result[QueryListRes] {
+		// 构建查询
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
+		}
+		var whereClauses []string
+		if req.Name != "" {
+			whereClauses = append(whereClauses, "name ILIKE :name")
+			params["name"] = "%" + req.Name + "%"
+		}
+		if req.ClientType != "" {
+			whereClauses = append(whereClauses, "client_type = :client_type")
+			params["client_type"] = req.ClientType
+		}
+		whereCondition := ""
+		if len(whereClauses) > 0 {
+			whereCondition = " WHERE " + strings.Join(whereClauses, " AND ")
+		}
+
+		// 查询总数
+		var total int64
+		countQuery, countArgs, err := sqlx.Named("SELECT count(*) FROM "+r.tables.Client+whereCondition, params)
+		if err != nil {
+			r.log(c).Error("构建计数查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询客户端列表失败"))
+		}
+		db := r.conn(c)
+		if err := db.GetContext(c.Request.Context(), &total, db.Rebind(countQuery), countArgs...); err != nil {
+			r.log(c).Error("统计客户端数量失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询客户端列表失败"))
+		}
+		if total == 0 {
+			return mo.Ok(QueryListRes{List: []GetByIDRes{}, Total: 0})
+		}
+
+		// 查询列表
+		var entities []ClientEntity
+		listQuery, listArgs, err := sqlx.Named(`SELECT `+clientColumns+` FROM `+r.tables.Client+
+			whereCondition+` ORDER BY created_at DESC, seq DESC LIMIT :limit OFFSET :offset`, params)
+		if err != nil {
+			r.log(c).Error("构建列表查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询客户端列表失败"))
+		}
+		if err := db.SelectContext(c.Request.Context(), &entities, db.Rebind(listQuery), listArgs...); err != nil {
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询客户端列表失败"))
+		}
+
+		list := make([]GetByIDRes, 0, len(entities))
+		for i := range entities {
+			list = append(list, toGetByIDRes(c, &entities[i]))
+		}
+
+		// 返回结果
+		return mo.Ok(QueryListRes{List: list, Total: total})
+	}
+}
+
+// get 按ID查询客户端，不存在时返回 404
+func (r *Repository) get(c *gin.Context, id string) (*ClientEntity, *pkgs.ApiError) {
+	var entity ClientEntity
+	err := r.conn(c).GetContext(c.Request.Context(), &entity, `SELECT `+clientColumns+` FROM `+r.tables.Client+` WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkgs.NewApiError(http.StatusNotFound, "客户端不存在")
+		}
+		r.log(c).Error("获取客户端失败", zap.Error(err))
+		return nil, pkgs.NewApiError(http.StatusInternalServerError, "获取客户端失败")
+	}
+	return &entity, nil
+}
+
+// toGetByIDRes 将数据库实体转换为客户端详情
+func toGetByIDRes(c *gin.Context, entity *ClientEntity) GetByIDRes {
+	return GetByIDRes{
+		ID:              entity.ID,
+		ClientID:        entity.ClientID,
+		Name:            entity.Name,
+		ClientType:      entity.ClientType,
+		AccessTokenTTL:  entity.AccessTokenTTL,
+		RefreshTokenTTL: entity.RefreshTokenTTL,
+		Enabled:         entity.Enabled,
+		CreatedAt:       pkgs.FormatTime(c, entity.CreatedAt),
+		UpdatedAt:       pkgs.FormatTime(c, entity.UpdatedAt),
+	}
+}
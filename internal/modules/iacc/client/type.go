@@ -0,0 +1,110 @@
+package client
+
+import (
+	"go-pg-demo/pkgs"
+	"regexp"
+	"time"
+)
+
+// 客户端类型
+const (
+	ClientTypeWeb     = "web"
+	ClientTypeMobile  = "mobile"
+	ClientTypeService = "service"
+)
+
+// 数据库表 iacc_client 的表结构
+type ClientEntity struct {
+	ID              string    `db:"id" label:"客户端记录ID"`
+	CreatedAt       time.Time `db:"created_at" label:"创建时间"`
+	UpdatedAt       time.Time `db:"updated_at" label:"更新时间"`
+	ClientID        string    `db:"client_id" label:"客户端标识"`
+	Name            string    `db:"name" label:"名称"`
+	ClientType      string    `db:"client_type" label:"客户端类型"`
+	AccessTokenTTL  int       `db:"access_token_ttl" label:"访问令牌有效期（秒）"`
+	RefreshTokenTTL int       `db:"refresh_token_ttl" label:"刷新令牌有效期（秒）"`
+	Enabled         bool      `db:"enabled" label:"是否启用"`
+}
+
+// 创建客户端的请求 DTO
+type CreateReq struct {
+	ClientID        string `json:"client_id" validate:"required,max=64" label:"客户端标识"`
+	Name            string `json:"name" validate:"required,max=100" label:"名称"`
+	ClientType      string `json:"client_type" validate:"required,oneof=web mobile service" label:"客户端类型"`
+	AccessTokenTTL  int    `json:"access_token_ttl" validate:"required,min=60" label:"访问令牌有效期（秒）"`
+	RefreshTokenTTL int    `json:"refresh_token_ttl" validate:"required,gtefield=AccessTokenTTL" label:"刷新令牌有效期（秒）"`
+	Enabled         *bool  `json:"enabled,omitempty" label:"是否启用"`
+}
+
+// 客户端标识只允许字母、数字、下划线和中划线
+var clientIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func createRule(req *CreateReq) []pkgs.Violation {
+	if req.ClientID != "" && !clientIDPattern.MatchString(req.ClientID) {
+		return []pkgs.Violation{{Field: "client_id", Message: "客户端标识只能包含字母、数字、下划线和中划线"}}
+	}
+	return nil
+}
+
+// 创建客户端的响应 DTO
+type CreateRes string
+
+// 根据ID获取客户端的参数
+type GetByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"客户端记录ID"`
+}
+
+// 客户端详情
+type GetByIDRes struct {
+	ID              string `json:"id" label:"客户端记录ID"`
+	ClientID        string `json:"client_id" label:"客户端标识"`
+	Name            string `json:"name" label:"名称"`
+	ClientType      string `json:"client_type" label:"客户端类型"`
+	AccessTokenTTL  int    `json:"access_token_ttl" label:"访问令牌有效期（秒）"`
+	RefreshTokenTTL int    `json:"refresh_token_ttl" label:"刷新令牌有效期（秒）"`
+	Enabled         bool   `json:"enabled" label:"是否启用"`
+	CreatedAt       string `json:"created_at" label:"创建时间"`
+	UpdatedAt       string `json:"updated_at" label:"更新时间"`
+}
+
+// 更新客户端的请求体，客户端标识创建后不可修改
+type UpdateByIDReq struct {
+	ID              string  `uri:"id" validate:"required,uuid" label:"客户端记录ID"`
+	Name            *string `json:"name,omitempty" validate:"omitempty,max=100" label:"名称"`
+	ClientType      *string `json:"client_type,omitempty" validate:"omitempty,oneof=web mobile service" label:"客户端类型"`
+	AccessTokenTTL  *int    `json:"access_token_ttl,omitempty" validate:"omitempty,min=60" label:"访问令牌有效期（秒）"`
+	RefreshTokenTTL *int    `json:"refresh_token_ttl,omitempty" validate:"omitempty,min=60" label:"刷新令牌有效期（秒）"`
+	Enabled         *bool   `json:"enabled,omitempty" label:"是否启用"`
+}
+
+// 同时修改两个有效期时，刷新令牌有效期不能短于访问令牌（只修改其一时由数据库约束校验）
+func updateRule(req *UpdateByIDReq) []pkgs.Violation {
+	if req.AccessTokenTTL != nil && req.RefreshTokenTTL != nil && *req.RefreshTokenTTL < *req.AccessTokenTTL {
+		return []pkgs.Violation{{Field: "refresh_token_ttl", Message: "刷新令牌有效期不能短于访问令牌有效期"}}
+	}
+	return nil
+}
+
+// 更新客户端的响应体
+type UpdateByIDRes = int64
+
+// 根据ID删除客户端的请求参数
+type DeleteByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"客户端记录ID"`
+}
+
+// 根据ID删除客户端的响应
+type DeleteByIDRes = int64
+
+// 查询客户端列表的请求体
+type QueryListReq struct {
+	pkgs.Pagination
+	Name       string `form:"name,omitempty" validate:"omitempty" label:"名称"`
+	ClientType string `form:"client_type,omitempty" validate:"omitempty,oneof=web mobile service" label:"客户端类型"`
+}
+
+// 查询客户端列表的响应体
+type QueryListRes struct {
+	List  []GetByIDRes `json:"list"`
+	Total int64        `json:"total"`
+}
@@ -35,17 +35,36 @@ type Handler struct {
 	logger     *zap.Logger
 	validator  *pkgs.RequestValidator
 	repository *Repository
+	cache      *pkgs.PermissionCache
+	audit      *pkgs.AuditLog
 }
 
-func NewPermissionHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator) *Handler {
-	return &Handler{
+func NewPermissionHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, reporting *pkgs.ReportingDB, ids *pkgs.IDGenerator, cache *pkgs.PermissionCache, audit *pkgs.AuditLog) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, patchRule)
+	pkgs.RegisterRule(validator, queryListRule)
+	pkgs.RegisterRule(validator, listFilterRule)
+	pkgs.RegisterRule(validator, valuesRule)
+	pkgs.RegisterRule(validator, putTranslationRule)
+	pkgs.RegisterRule(validator, deleteTranslationRule)
+
+	repository := &Repository{
 		db:        db,
 		logger:    logger,
-		validator: validator,
-		repository: &Repository{
-			db:     db,
-			logger: logger,
-		},
+		tables:    tables,
+		pool:      pool,
+		reporting: reporting,
+		ids:       ids,
+	}
+	audit.RegisterSnapshot(pkgs.AuditEntityPermission, repository.Snapshot)
+
+	return &Handler{
+		db:         db,
+		logger:     logger,
+		validator:  validator,
+		cache:      cache,
+		audit:      audit,
+		repository: repository,
 	}
 }
 
@@ -57,16 +76,33 @@ func NewPermissionHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.Reque
 //	@Accept   json
 //	@Produce  json
 //	@Param    request body  CreatePermissionReq true  "创建权限请求参数"
+//	@Param    return  query string  false "返回内容，entity 时返回完整权限（data 为 GetByIDRes）"  Enums(id, entity)
 //	@Success  200   {object}  pkgs.Response{data=CreatePermissionRes}  "创建成功，返回权限ID"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
 //	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/permission"}
 //	@Router   /permission [post]
 func (h *Handler) Create(c *gin.Context) {
-	result.Pipe2(
+	if pkgs.WantEntity(c) {
+		result.Pipe3(
+			pkgs.BindJSON[CreatePermissionReq](c),
+			result.FlatMap(pkgs.ValidateV2[CreatePermissionReq](h.validator)),
+			result.FlatMap(h.repository.CreateEntity(c)),
+			result.Map(pkgs.RecordAuditEach[GetByIDRes](c, h.audit, "create", pkgs.AuditEntityPermission, func(res GetByIDRes) []string { return []string{res.ID} })),
+		).Match(
+			pkgs.HandleSuccess[GetByIDRes](c),
+			pkgs.HandleError[GetByIDRes](c),
+		)
+		return
+	}
+
+	result.Pipe4(
 		pkgs.BindJSON[CreatePermissionReq](c),
 		result.FlatMap(pkgs.ValidateV2[CreatePermissionReq](h.validator)),
 		result.FlatMap(h.repository.Create(c)),
+		result.Map(pkgs.InvalidatePermissionCache[CreatePermissionRes](c, h.cache)),
+		result.Map(pkgs.RecordAuditEach[CreatePermissionRes](c, h.audit, "create", pkgs.AuditEntityPermission, func(id CreatePermissionRes) []string { return []string{string(id)} })),
 	).Match(
 		pkgs.HandleSuccess[CreatePermissionRes](c),
 		pkgs.HandleError[CreatePermissionRes](c),
@@ -86,6 +122,7 @@ func (h *Handler) Create(c *gin.Context) {
 //	@Failure  404 {object}  pkgs.Response           "权限不存在"
 //	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
 //	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/permission/:id"}
 //	@Router   /permission/{id} [get]
 func (h *Handler) GetByID(c *gin.Context) {
 	result.Pipe2(
@@ -109,20 +146,56 @@ func (h *Handler) GetByID(c *gin.Context) {
 //	@Param    request body  UpdatePermissionReq true  "更新权限请求参数"
 //	@Success  200   {object}  pkgs.Response{data=UpdatePermissionRes}       "更新成功"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  409   {object}  pkgs.Response       "权限下还有子节点，不能改为非菜单类型"
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
 //	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/permission/:id"}
 //	@Router   /permission/{id} [put]
 func (h *Handler) UpdateByID(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe5(
 		pkgs.BindUriAndJSON[UpdatePermissionReq](c),
 		result.FlatMap(pkgs.ValidateV2[UpdatePermissionReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[UpdatePermissionReq](c, h.audit, pkgs.AuditEntityPermission, c.Param("id"))),
 		result.FlatMap(h.repository.UpdateByID(c)),
+		result.Map(pkgs.InvalidatePermissionCache[UpdatePermissionRes](c, h.cache)),
+		result.Map(pkgs.RecordAudit[UpdatePermissionRes](c, h.audit, "update", pkgs.AuditEntityPermission, c.Param("id"))),
 	).Match(
 		pkgs.HandleSuccess[UpdatePermissionRes](c),
 		pkgs.HandleError[UpdatePermissionRes](c),
 	)
 }
 
+// PatchByID 根据ID部分更新权限
+//
+//	@Summary  根据ID部分更新权限
+//	@Description  按 JSON Merge Patch（RFC 7386）部分更新权限：未出现的字段保持不变，metadata 按字段递归合并，其中字段为 null 表示删除该字段
+//	@Tags   permission
+//	@Accept   json,application/merge-patch+json
+//	@Produce  json
+//	@Param    id    path  string          true  "权限ID"
+//	@Param    request body  PatchPermissionReq true  "部分更新权限请求参数"
+//	@Success  200   {object}  pkgs.Response{data=PatchPermissionRes}       "更新成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404   {object}  pkgs.Response       "权限不存在"
+//	@Failure  409   {object}  pkgs.Response       "权限下还有子节点，不能改为非菜单类型"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PATCH","path":"/v1/permission/:id"}
+//	@Router   /permission/{id} [patch]
+func (h *Handler) PatchByID(c *gin.Context) {
+	result.Pipe5(
+		pkgs.BindUriAndMergePatch[PatchPermissionReq](c),
+		result.FlatMap(pkgs.ValidateV2[PatchPermissionReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[PatchPermissionReq](c, h.audit, pkgs.AuditEntityPermission, c.Param("id"))),
+		result.FlatMap(h.repository.PatchByID(c)),
+		result.Map(pkgs.InvalidatePermissionCache[PatchPermissionRes](c, h.cache)),
+		result.Map(pkgs.RecordAudit[PatchPermissionRes](c, h.audit, "patch", pkgs.AuditEntityPermission, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[PatchPermissionRes](c),
+		pkgs.HandleError[PatchPermissionRes](c),
+	)
+}
+
 // DeleteByID 根据ID删除权限
 //
 //	@Summary  根据ID删除权限
@@ -135,12 +208,16 @@ func (h *Handler) UpdateByID(c *gin.Context) {
 //	@Failure  400 {object}  pkgs.Response       "请求参数错误"
 //	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
 //	@Security JWT
+//	@x-permission {"method":"DELETE","path":"/v1/permission/:id"}
 //	@Router   /permission/{id} [delete]
 func (h *Handler) DeleteByID(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe5(
 		pkgs.BindUri[DeleteByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[DeleteByIDReq](c, h.audit, pkgs.AuditEntityPermission, c.Param("id"))),
 		result.FlatMap(h.repository.DeleteByID(c)),
+		result.Map(pkgs.InvalidatePermissionCache[DeleteByIDRes](c, h.cache)),
+		result.Map(pkgs.RecordAudit[DeleteByIDRes](c, h.audit, "delete", pkgs.AuditEntityPermission, c.Param("id"))),
 	).Match(
 		pkgs.HandleSuccess[DeleteByIDRes](c),
 		pkgs.HandleError[DeleteByIDRes](c),
@@ -156,20 +233,250 @@ func (h *Handler) DeleteByID(c *gin.Context) {
 //	@Produce  json
 //	@Param    page    query int   false "页码"  default(1)
 //	@Param    pageSize  query int   false "每页数量"  default(10)
+//	@Param    cursor  query string  false "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，只支持按创建时间排序，不统计总数"
+//	@Param    limit   query int   false "游标分页的每页数量"  default(10)
 //	@Param    name    query string  false "权限名称"
 //	@Param    type    query string  false "权限类型"
+//	@Param    attr    query []string  false "按自定义属性筛选，格式为 键:值，可重复传入，需同时满足"  collectionFormat(multi)
+//	@Param    filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、type、method、path、code、created_at、updated_at"
+//	@Param    createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param    createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param    updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param    updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param    changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Param    If-None-Match  header  string  false  "上次响应的 ETag，列表未变化时返回 304"
+//	@Param    If-Modified-Since  header  string  false  "上次响应的 Last-Modified，列表未变化时返回 304"
 //	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功，返回权限列表"
 //	@Failure  400     {object}  pkgs.Response               "请求参数错误"
 //	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
 //	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/permission/list"}
 //	@Router   /permission/list [get]
 func (h *Handler) QueryList(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe3(
 		pkgs.BindQuery[QueryListReq](c),
 		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.CheckModified(c)),
 		result.FlatMap(h.repository.QueryList(c)),
 	).Match(
 		pkgs.HandleSuccess[QueryListRes](c),
 		pkgs.HandleError[QueryListRes](c),
 	)
-}
\ No newline at end of file
+}
+
+// Count 统计权限数量
+//
+//	@Summary  统计权限数量
+//	@Description  按与权限列表相同的筛选条件统计权限数量，不返回权限数据
+//	@Tags   permission
+//	@Accept   json
+//	@Produce  json
+//	@Param    name    query string  false "权限名称"
+//	@Param    type    query string  false "权限类型"
+//	@Param    attr    query []string  false "按自定义属性筛选，格式为 键:值，可重复传入，需同时满足"  collectionFormat(multi)
+//	@Param    filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、type、method、path、code、created_at、updated_at"
+//	@Param    createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param    createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param    updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param    updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param    changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Success  200     {object}  pkgs.Response{data=CountRes}  "权限数量"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/permission/count"}
+//	@Router   /permission/count [get]
+func (h *Handler) Count(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[CountReq](c),
+		result.FlatMap(pkgs.ValidateV2[CountReq](h.validator)),
+		result.FlatMap(h.repository.Count(c)),
+	).Match(
+		pkgs.HandleSuccess[CountRes](c),
+		pkgs.HandleError[CountRes](c),
+	)
+}
+
+// Exists 检查权限名称是否已存在
+//
+//	@Summary  检查权限名称是否已存在
+//	@Description  按名称精确匹配检查权限是否存在，用于创建、改名前的提示
+//	@Tags   permission
+//	@Accept   json
+//	@Produce  json
+//	@Param    name    query string  true "权限名称"
+//	@Success  200     {object}  pkgs.Response{data=ExistsRes}  "是否存在"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/permission/exists"}
+//	@Router   /permission/exists [get]
+func (h *Handler) Exists(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[ExistsReq](c),
+		result.FlatMap(pkgs.ValidateV2[ExistsReq](h.validator)),
+		result.FlatMap(h.repository.Exists(c)),
+	).Match(
+		pkgs.HandleSuccess[ExistsRes](c),
+		pkgs.HandleError[ExistsRes](c),
+	)
+}
+
+// Values 查询权限字段的取值及数量
+//
+//	@Summary  查询权限字段取值
+//	@Description  返回满足筛选条件的权限中指定字段的各取值及数量，按数量降序，最多 100 个，用于填充筛选下拉框
+//	@Tags   permission
+//	@Accept   json
+//	@Produce  json
+//	@Param    field   query string  true  "字段"  Enums(type, method)
+//	@Param    name    query string  false "权限名称"
+//	@Param    type    query string  false "权限类型"
+//	@Success  200     {object}  pkgs.Response{data=ValuesRes}  "字段取值及数量"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/permission/values"}
+//	@Router   /permission/values [get]
+func (h *Handler) Values(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[ValuesReq](c),
+		result.FlatMap(pkgs.ValidateV2[ValuesReq](h.validator)),
+		result.FlatMap(h.repository.Values(c)),
+	).Match(
+		pkgs.HandleSuccess[ValuesRes](c),
+		pkgs.HandleError[ValuesRes](c),
+	)
+}
+
+// Tree 查询权限树
+//
+//	@Summary  查询权限树
+//	@Description  返回按菜单结构组织的全部权限，用于构建管理后台的菜单与按钮：menus 为根级菜单及其子树（子菜单、按钮、接口权限），
+//	@Description  ungrouped 为不属于任何菜单的根级权限，按权限类型分组。子节点按菜单、按钮、接口、其他类型排列，同类型按名称排序；名称按 Accept-Language 返回翻译
+//	@Tags   permission
+//	@Produce  json
+//	@Success  200 {object}  pkgs.Response{data=TreeRes}  "获取成功"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/permission/tree"}
+//	@Router   /permission/tree [get]
+func (h *Handler) Tree(c *gin.Context) {
+	h.repository.Tree(c)().Match(
+		pkgs.HandleSuccess[TreeRes](c),
+		pkgs.HandleError[TreeRes](c),
+	)
+}
+
+// Move 移动权限树节点
+//
+//	@Summary  移动权限树节点
+//	@Description  将权限连同其子孙节点移到另一个菜单权限下，parent_id 为 null 时移到根级；父节点不能是节点自身或其子孙节点
+//	@Tags   permission
+//	@Accept   json
+//	@Produce  json
+//	@Param    id    path  string  true  "权限ID"
+//	@Param    request body  MoveReq true  "移动权限请求参数"
+//	@Success  200   {object}  pkgs.Response{data=MoveRes}  "移动成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误或父节点无效"
+//	@Failure  404   {object}  pkgs.Response       "权限不存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/permission/:id/move"}
+//	@Router   /permission/{id}/move [put]
+func (h *Handler) Move(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindUriAndJSON[MoveReq](c),
+		result.FlatMap(pkgs.ValidateV2[MoveReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[MoveReq](c, h.audit, pkgs.AuditEntityPermission, c.Param("id"))),
+		result.FlatMap(h.repository.Move(c)),
+		result.Map(pkgs.RecordAudit[MoveRes](c, h.audit, "move", pkgs.AuditEntityPermission, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[MoveRes](c),
+		pkgs.HandleError[MoveRes](c),
+	)
+}
+
+// GetTranslations 查询权限翻译
+//
+//	@Summary  查询权限翻译
+//	@Description  返回权限显示名称的全部翻译，键为语言标签；列表与详情接口按 Accept-Language 请求头返回对应翻译
+//	@Tags   permission
+//	@Accept   json
+//	@Produce  json
+//	@Param    id  path  string  true  "权限ID"
+//	@Success  200 {object}  pkgs.Response{data=GetTranslationsRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response           "权限不存在"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/permission/:id/translation"}
+//	@Router   /permission/{id}/translation [get]
+func (h *Handler) GetTranslations(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[GetTranslationsReq](c),
+		result.FlatMap(pkgs.ValidateV2[GetTranslationsReq](h.validator)),
+		result.FlatMap(h.repository.GetTranslations(c)),
+	).Match(
+		pkgs.HandleSuccess[GetTranslationsRes](c),
+		pkgs.HandleError[GetTranslationsRes](c),
+	)
+}
+
+// PutTranslation 设置权限某一语言的翻译
+//
+//	@Summary  设置权限翻译
+//	@Description  设置权限在指定语言下的显示名称，已有的翻译整体替换；语言标签不区分大小写，保存时规范化（如 zh-hans-cn 保存为 zh-Hans-CN）
+//	@Tags   permission
+//	@Accept   json
+//	@Produce  json
+//	@Param    id      path  string              true  "权限ID"
+//	@Param    locale  path  string              true  "语言标签，如 en、en-US"
+//	@Param    request body  PutTranslationReq   true  "翻译内容"
+//	@Success  200 {object}  pkgs.Response{data=PutTranslationRes}  "设置成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response           "权限不存在"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/permission/:id/translation/:locale"}
+//	@Router   /permission/{id}/translation/{locale} [put]
+func (h *Handler) PutTranslation(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindUriAndJSON[PutTranslationReq](c),
+		result.FlatMap(pkgs.ValidateV2[PutTranslationReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[PutTranslationReq](c, h.audit, pkgs.AuditEntityPermission, c.Param("id"))),
+		result.FlatMap(h.repository.PutTranslation(c)),
+		result.Map(pkgs.RecordAudit[PutTranslationRes](c, h.audit, "put_translation", pkgs.AuditEntityPermission, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[PutTranslationRes](c),
+		pkgs.HandleError[PutTranslationRes](c),
+	)
+}
+
+// DeleteTranslation 删除权限某一语言的翻译
+//
+//	@Summary  删除权限翻译
+//	@Description  删除权限在指定语言下的翻译，之后该语言返回原始名称
+//	@Tags   permission
+//	@Accept   json
+//	@Produce  json
+//	@Param    id      path  string  true  "权限ID"
+//	@Param    locale  path  string  true  "语言标签"
+//	@Success  200 {object}  pkgs.Response{data=DeleteTranslationRes}  "删除成功，返回影响行数（翻译不存在时为 0）"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"DELETE","path":"/v1/permission/:id/translation/:locale"}
+//	@Router   /permission/{id}/translation/{locale} [delete]
+func (h *Handler) DeleteTranslation(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindUri[DeleteTranslationReq](c),
+		result.FlatMap(pkgs.ValidateV2[DeleteTranslationReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[DeleteTranslationReq](c, h.audit, pkgs.AuditEntityPermission, c.Param("id"))),
+		result.FlatMap(h.repository.DeleteTranslation(c)),
+		result.Map(pkgs.RecordAudit[DeleteTranslationRes](c, h.audit, "delete_translation", pkgs.AuditEntityPermission, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[DeleteTranslationRes](c),
+		pkgs.HandleError[DeleteTranslationRes](c),
+	)
+}
@@ -1,7 +1,9 @@
 package permission
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"go-pg-demo/pkgs"
 	"net/http"
 	"strings"
@@ -10,38 +12,103 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/samber/mo"
+	"github.com/samber/mo/result"
 	"go.uber.org/zap"
 )
 
 type Repository struct {
-	db     *sqlx.DB
-	logger *zap.Logger
+	db        *sqlx.DB
+	logger    *zap.Logger
+	tables    *pkgs.TableNames
+	pool      *pkgs.TenantPool
+	reporting *pkgs.ReportingDB
+	ids       *pkgs.IDGenerator
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// reportConn 返回报表查询应使用的连接与表名，配置了分析库时在分析库查询，不占用主库
+func (r *Repository) reportConn(c *gin.Context) (*sqlx.DB, *pkgs.TableNames) {
+	return r.reporting.Conn(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+// checkAttributes 按权限的属性定义校验请求中的自定义属性
+func (r *Repository) checkAttributes(c *gin.Context, complete bool, attrs pkgs.Attributes) *pkgs.ApiError {
+	return pkgs.CheckAttributes(c.Request.Context(), r.conn(c), r.log(c), r.tables, pkgs.AttributeEntityPermission, complete, []string{"attributes"}, []pkgs.Attributes{attrs})
 }
 
 func (r *Repository) Create(c *gin.Context) func(*CreatePermissionReq) mo.Result[CreatePermissionRes] {
 	return func(req *CreatePermissionReq) mo.Result[CreatePermissionRes] {
+		return result.Pipe1(r.insert(c)(req), result.Map(func(entity *PermissionEntity) CreatePermissionRes {
+			return CreatePermissionRes(entity.ID)
+		}))
+	}
+}
+
+// CreateEntity 创建权限并返回完整实体
+func (r *Repository) CreateEntity(c *gin.Context) func(*CreatePermissionReq) mo.Result[GetByIDRes] {
+	return func(req *CreatePermissionReq) mo.Result[GetByIDRes] {
+		return result.Pipe1(r.insert(c)(req), result.Map(func(entity *PermissionEntity) GetByIDRes {
+			return toGetByIDRes(c, entity)
+		}))
+	}
+}
+
+// insert 插入单个权限，服务端生成的 id/created_at/updated_at 通过 RETURNING 回填到实体
+func (r *Repository) insert(c *gin.Context) func(*CreatePermissionReq) mo.Result[*PermissionEntity] {
+	return func(req *CreatePermissionReq) mo.Result[*PermissionEntity] {
+		// 校验自定义属性
+		if apiErr := r.checkAttributes(c, true, req.Attributes); apiErr != nil {
+			return mo.Err[*PermissionEntity](apiErr)
+		}
+
+		// 校验父节点，新建的节点没有子孙节点，不会形成环
+		if req.ParentID != nil {
+			if apiErr := r.checkParent(c, r.conn(c), "", *req.ParentID, "创建权限失败"); apiErr != nil {
+				return mo.Err[*PermissionEntity](apiErr)
+			}
+		}
+
 		// 创建实体
 		entity := &PermissionEntity{
-			Name:     req.Name,
-			Type:     req.Type,
-			Metadata: req.Metadata,
+			Name:       req.Name,
+			Type:       req.Type,
+			Metadata:   req.Metadata,
+			Namespace:  req.Namespace,
+			Attributes: req.Attributes.OrEmpty(),
+			ParentID:   req.ParentID,
+		}
+		if entity.Namespace == nil {
+			entity.Namespace = req.Metadata.defaultNamespace()
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[*PermissionEntity](pkgs.NewApiError(http.StatusInternalServerError, "创建权限失败"))
 		}
 		// 数据库操作
-		query := `INSERT INTO iacc_permission (name, type, metadata) VALUES (:name, :type, :metadata) RETURNING id, created_at, updated_at`
-		stmt, err := r.db.PrepareNamedContext(c.Request.Context(), query)
+		columns, values := r.ids.Insert("name", "type", "metadata", "namespace", "attributes", "parent_id")
+		query := `INSERT INTO ` + r.tables.Permission + ` (` + columns + `) VALUES (` + values + `) RETURNING id, created_at, updated_at`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
 		if err != nil {
-			r.logger.Error("创建权限语句准备失败", zap.Error(err))
-			return mo.Err[CreatePermissionRes](pkgs.NewApiError(http.StatusInternalServerError, "创建权限失败"))
+			r.log(c).Error("创建权限语句准备失败", zap.Error(err))
+			return mo.Err[*PermissionEntity](pkgs.NewApiError(http.StatusInternalServerError, "创建权限失败"))
 		}
 		defer stmt.Close()
 
 		err = stmt.GetContext(c.Request.Context(), entity, entity)
 		if err != nil {
-			r.logger.Error("创建权限失败", zap.Error(err))
-			return mo.Err[CreatePermissionRes](pkgs.NewApiError(http.StatusInternalServerError, "创建权限失败"))
+			return mo.Err[*PermissionEntity](pkgs.DBError(r.log(c), err, "创建权限失败"))
 		}
 		// 返回结果
-		return mo.Ok(CreatePermissionRes(entity.ID))
+		return mo.Ok(entity)
 	}
 }
 
@@ -50,26 +117,17 @@ func (r *Repository) GetByID(c *gin.Context) func(*GetByIDReq) mo.Result[GetByID
 
 		// 数据库操作
 		var entity PermissionEntity
-		query := `SELECT id, name, type, metadata, created_at, updated_at FROM iacc_permission WHERE id = $1`
-		err := r.db.GetContext(c.Request.Context(), &entity, query, req.ID)
+		query := `SELECT id, name, type, metadata, namespace, translations, attributes, parent_id, created_at, updated_at FROM ` + r.tables.Permission + ` WHERE id = $1`
+		err := r.conn(c).GetContext(c.Request.Context(), &entity, query, req.ID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusNotFound, "权限不存在"))
 			}
-			r.logger.Error("获取权限失败", zap.Error(err))
-			return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "获取权限失败"))
+			return mo.Err[GetByIDRes](pkgs.DBError(r.log(c), err, "获取权限失败"))
 		}
 
 		// 返回结果
-		response := GetByIDRes{
-			ID:        entity.ID,
-			Name:      entity.Name,
-			Type:      entity.Type,
-			Metadata:  entity.Metadata,
-			CreatedAt: entity.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: entity.UpdatedAt.Format(time.RFC3339),
-		}
-		return mo.Ok(response)
+		return mo.Ok(toGetByIDRes(c, &entity))
 	}
 }
 
@@ -91,42 +149,183 @@ func (r *Repository) UpdateByID(c *gin.Context) func(*UpdatePermissionReq) mo.Re
 			params["metadata"] = *req.Metadata
 			setClauses = append(setClauses, "metadata = :metadata")
 		}
+		if req.Namespace != nil {
+			params["namespace"] = *req.Namespace
+			setClauses = append(setClauses, "namespace = :namespace")
+		}
+		if req.Attributes != nil {
+			if apiErr := r.checkAttributes(c, true, req.Attributes); apiErr != nil {
+				return mo.Err[UpdatePermissionRes](apiErr)
+			}
+			params["attributes"] = req.Attributes
+			setClauses = append(setClauses, "attributes = :attributes")
+		}
 
 		// 如果没有需要更新的字段，直接返回成功
 		if len(setClauses) == 0 {
 			return mo.Ok(UpdatePermissionRes(0))
 		}
 
-		query := "UPDATE iacc_permission SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		ctx := c.Request.Context()
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[UpdatePermissionRes](pkgs.NewApiError(http.StatusInternalServerError, "更新权限失败"))
+		}
+		defer tx.Rollback()
+
+		if req.Type != nil {
+			if apiErr := r.checkType(c, tx, req.ID, *req.Type, "更新权限失败"); apiErr != nil {
+				return mo.Err[UpdatePermissionRes](apiErr)
+			}
+		}
+
+		query := "UPDATE " + r.tables.Permission + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
 
 		// 执行数据库操作
-		res, err := r.db.NamedExecContext(c.Request.Context(), query, params)
+		res, err := tx.NamedExecContext(ctx, query, params)
 		if err != nil {
-			r.logger.Error("更新权限失败", zap.Error(err))
-			return mo.Err[UpdatePermissionRes](pkgs.NewApiError(http.StatusInternalServerError, "更新权限失败"))
+			return mo.Err[UpdatePermissionRes](pkgs.DBError(r.log(c), err, "更新权限失败"))
 		}
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
 			return mo.Err[UpdatePermissionRes](pkgs.NewApiError(http.StatusInternalServerError, "更新权限失败"))
 		}
+		if err := tx.Commit(); err != nil {
+			return mo.Err[UpdatePermissionRes](pkgs.DBError(r.log(c), err, "更新权限失败"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// PatchByID 按 JSON Merge Patch 部分更新权限，metadata 在事务内读取当前值后递归合并
+func (r *Repository) PatchByID(c *gin.Context) func(*PatchPermissionReq) mo.Result[PatchPermissionRes] {
+	return func(req *PatchPermissionReq) mo.Result[PatchPermissionRes] {
+		// 动态构建更新语句，只处理补丁中出现的字段
+		params := map[string]any{"id": req.ID}
+		var setClauses []string
+
+		if req.Has("name") {
+			params["name"] = *req.Name
+			setClauses = append(setClauses, "name = :name")
+		}
+		if req.Has("type") {
+			params["type"] = *req.Type
+			setClauses = append(setClauses, "type = :type")
+		}
+		if req.Has("namespace") {
+			params["namespace"] = req.Namespace
+			setClauses = append(setClauses, "namespace = :namespace")
+		}
+		if req.Has("attributes") {
+			if apiErr := r.checkAttributes(c, false, req.Attributes); apiErr != nil {
+				return mo.Err[PatchPermissionRes](apiErr)
+			}
+			set, removed := pkgs.SplitAttributePatch(req.Attributes)
+			params["attributes"] = set
+			params["attributes_removed"] = pkgs.PGArray(removed)
+			setClauses = append(setClauses, "attributes = (attributes || :attributes) - CAST(:attributes_removed AS text[])")
+		}
+
+		// 如果没有需要更新的字段，直接返回成功
+		if len(setClauses) == 0 && !req.Has("metadata") {
+			return mo.Ok(PatchPermissionRes(0))
+		}
+
+		// 开启事务
+		tx, err := r.conn(c).BeginTxx(c.Request.Context(), nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[PatchPermissionRes](pkgs.NewApiError(http.StatusInternalServerError, "更新权限失败"))
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+			if err != nil {
+				tx.Rollback()
+			} else {
+				err = tx.Commit()
+				if err != nil {
+					r.log(c).Error("Failed to commit transaction", zap.Error(err))
+					return
+				}
+			}
+		}()
+
+		if req.Has("type") {
+			if apiErr := r.checkType(c, tx, req.ID, *req.Type, "更新权限失败"); apiErr != nil {
+				return mo.Err[PatchPermissionRes](apiErr)
+			}
+		}
+
+		if req.Has("metadata") {
+			// 锁定并读取当前元数据，与补丁合并
+			var current Metadata
+			err = tx.GetContext(c.Request.Context(), &current, `SELECT metadata FROM `+r.tables.Permission+` WHERE id = $1 FOR UPDATE`, req.ID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return mo.Err[PatchPermissionRes](pkgs.NewApiError(http.StatusNotFound, "权限不存在"))
+				}
+				r.log(c).Error("读取权限元数据失败", zap.Error(err))
+				return mo.Err[PatchPermissionRes](pkgs.NewApiError(http.StatusInternalServerError, "更新权限失败"))
+			}
+			var merged Metadata
+			merged, err = mergeMetadata(current, req.Raw("metadata"))
+			if err != nil {
+				r.log(c).Error("合并权限元数据失败", zap.Error(err))
+				return mo.Err[PatchPermissionRes](pkgs.NewApiError(http.StatusBadRequest, "权限元数据格式错误"))
+			}
+			params["metadata"] = merged
+			setClauses = append(setClauses, "metadata = :metadata")
+		}
+
+		query := "UPDATE " + r.tables.Permission + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+
+		// 执行数据库操作
+		res, err := tx.NamedExecContext(c.Request.Context(), query, params)
+		if err != nil {
+			return mo.Err[PatchPermissionRes](pkgs.DBError(r.log(c), err, "更新权限失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[PatchPermissionRes](pkgs.NewApiError(http.StatusInternalServerError, "更新权限失败"))
+		}
 		// 返回结果
 		return mo.Ok(affectedRows)
 	}
 }
 
+// mergeMetadata 按 RFC 7386 将补丁合并到权限元数据
+func mergeMetadata(current Metadata, patch []byte) (Metadata, error) {
+	var merged Metadata
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return merged, err
+	}
+	raw, err = pkgs.ApplyMergePatch(raw, patch)
+	if err != nil {
+		return merged, err
+	}
+	err = json.Unmarshal(raw, &merged)
+	return merged, err
+}
+
 func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
 	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
 		// 数据库操作
-		query := `DELETE FROM iacc_permission WHERE id = $1`
-		res, err := r.db.ExecContext(c.Request.Context(), query, req.ID)
+		query := `DELETE FROM ` + r.tables.Permission + ` WHERE id = $1`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID)
 		if err != nil {
-			r.logger.Error("删除权限失败", zap.Error(err))
-			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除权限失败"))
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除权限失败"))
 		}
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
 			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除权限失败"))
 		}
 
@@ -135,114 +334,374 @@ func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[D
 	}
 }
 
-func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
-	return func(req *QueryListReq) mo.Result[QueryListRes] {
-		// 校验排序字段
-		validOrderBy := map[string]bool{
-			"id":         true,
-			"name":       true,
-			"type":       true,
-			"created_at": true,
-			"updated_at": true,
-		}
-		if !validOrderBy[req.OrderBy] {
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "排序字段不存在"))
+// Move 移动权限树节点：parent_id 为空时移到根级，否则移到该菜单权限下，子孙节点随之移动
+// 并发的移动通过事务级咨询锁依次执行，避免两个节点互相移到对方之下形成环。
+func (r *Repository) Move(c *gin.Context) func(*MoveReq) mo.Result[MoveRes] {
+	return func(req *MoveReq) mo.Result[MoveRes] {
+		ctx := c.Request.Context()
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("为移动权限开启事务失败", zap.Error(err))
+			return mo.Err[MoveRes](pkgs.NewApiError(http.StatusInternalServerError, "移动权限失败"))
 		}
+		defer tx.Rollback()
 
-		// 校验排序顺序
-		upperOrder := strings.ToUpper(req.Order)
-		if upperOrder != "ASC" && upperOrder != "DESC" {
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "排序顺序参数错误"))
+		if err := r.lockTree(ctx, tx); err != nil {
+			return mo.Err[MoveRes](pkgs.DBError(r.log(c), err, "移动权限失败"))
 		}
-
-		// 构建查询
-		params := map[string]any{
-			"limit":  req.PageSize,
-			"offset": (req.Page - 1) * req.PageSize,
+		if req.ParentID != nil {
+			if apiErr := r.checkParent(c, tx, req.ID, *req.ParentID, "移动权限失败"); apiErr != nil {
+				return mo.Err[MoveRes](apiErr)
+			}
 		}
-
-		var whereClauses []string
-		if req.Name != "" {
-			whereClauses = append(whereClauses, "name ILIKE :name")
-			params["name"] = "%" + req.Name + "%"
+		res, err := tx.ExecContext(ctx, `UPDATE `+r.tables.Permission+` SET parent_id = $2 WHERE id = $1`, req.ID, req.ParentID)
+		if err != nil {
+			return mo.Err[MoveRes](pkgs.DBError(r.log(c), err, "移动权限失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[MoveRes](pkgs.NewApiError(http.StatusInternalServerError, "移动权限失败"))
 		}
-		if req.Type != "" {
-			whereClauses = append(whereClauses, "type = :type")
-			params["type"] = req.Type
+		if affectedRows == 0 {
+			return mo.Err[MoveRes](pkgs.NewApiError(http.StatusNotFound, "权限不存在"))
 		}
-
-		whereCondition := ""
-		if len(whereClauses) > 0 {
-			whereCondition = " WHERE " + strings.Join(whereClauses, " AND ")
+		if err := tx.Commit(); err != nil {
+			return mo.Err[MoveRes](pkgs.DBError(r.log(c), err, "移动权限失败"))
 		}
+		return mo.Ok(affectedRows)
+	}
+}
 
-		// 查询总数
-		var total int64
-		countQuery := "SELECT count(*) FROM iacc_permission" + whereCondition
-		// 使用 NamedQuery 而不是 PrepareNamed
-		rows, err := r.db.NamedQueryContext(c.Request.Context(), countQuery, params)
+// lockTree 获取权限树的事务级咨询锁，移动节点与修改节点类型依次执行
+func (r *Repository) lockTree(ctx context.Context, tx *sqlx.Tx) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, r.tables.Permission+":tree")
+	return err
+}
+
+// checkType 在权限树锁内校验类型修改：还有子节点的权限只能是菜单权限，否则子节点会挂在非菜单节点下
+// msg 为查询失败时返回的错误信息
+func (r *Repository) checkType(c *gin.Context, tx *sqlx.Tx, id, typ, msg string) *pkgs.ApiError {
+	if err := r.lockTree(c.Request.Context(), tx); err != nil {
+		return pkgs.DBError(r.log(c), err, msg)
+	}
+	if typ == TypeMenu {
+		return nil
+	}
+	var hasChildren bool
+	query := `SELECT EXISTS(SELECT 1 FROM ` + r.tables.Permission + ` WHERE parent_id = $1)`
+	if err := tx.GetContext(c.Request.Context(), &hasChildren, query, id); err != nil {
+		return pkgs.DBError(r.log(c), err, msg)
+	}
+	if hasChildren {
+		return pkgs.NewApiError(http.StatusConflict, "权限下还有子节点，不能改为非菜单类型")
+	}
+	return nil
+}
+
+// checkParent 校验权限树的父节点：父节点必须存在且是菜单权限，不能是节点自身或其子孙节点（否则形成环）
+// id 为空表示新建的节点，msg 为查询失败时返回的错误信息
+func (r *Repository) checkParent(c *gin.Context, q sqlx.QueryerContext, id, parentID, msg string) *pkgs.ApiError {
+	var row struct {
+		Exists bool `db:"parent_exists"`
+		Menu   bool `db:"is_menu"`
+		Cycle  bool `db:"cycle"`
+	}
+	query := `WITH RECURSIVE chain AS (
+			SELECT id, parent_id FROM ` + r.tables.Permission + ` WHERE id = $1
+			UNION
+			SELECT p.id, p.parent_id FROM ` + r.tables.Permission + ` p JOIN chain ON p.id = chain.parent_id
+		)
+		SELECT EXISTS(SELECT 1 FROM chain) AS parent_exists,
+			EXISTS(SELECT 1 FROM ` + r.tables.Permission + ` WHERE id = $1 AND type = '` + TypeMenu + `') AS is_menu,
+			EXISTS(SELECT 1 FROM chain WHERE id::text = $2) AS cycle`
+	if err := sqlx.GetContext(c.Request.Context(), q, &row, query, parentID, id); err != nil {
+		return pkgs.DBError(r.log(c), err, msg)
+	}
+	if !row.Exists {
+		return pkgs.NewApiError(http.StatusBadRequest, "父节点不存在")
+	}
+	if !row.Menu {
+		return pkgs.NewApiError(http.StatusBadRequest, "父节点必须是菜单权限")
+	}
+	if row.Cycle {
+		return pkgs.NewApiError(http.StatusBadRequest, "父节点不能是节点自身或其子孙节点")
+	}
+	return nil
+}
+
+// Tree 查询权限树：一次读出全部权限，按 parent_id 组装
+// 根级的菜单权限及其子树放在 menus 中，其余根级权限按类型分组放在 ungrouped 中；名称按请求语言返回翻译。
+func (r *Repository) Tree(c *gin.Context) func() mo.Result[TreeRes] {
+	return func() mo.Result[TreeRes] {
+		query := `SELECT id, name, type, metadata, namespace, translations, attributes, parent_id, created_at, updated_at FROM ` + r.tables.Permission + `
+			ORDER BY CASE type WHEN '` + TypeMenu + `' THEN 0 WHEN '` + TypeButton + `' THEN 1 WHEN '` + TypeAPI + `' THEN 2 ELSE 3 END, type, name, seq`
+		entities, err := pkgs.QueryAll[PermissionEntity](c.Request.Context(), r.conn(c), query)
 		if err != nil {
-			r.logger.Error("准备命名计数查询失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询权限列表失败"))
+			return mo.Err[TreeRes](pkgs.DBError(r.log(c), err, "查询权限树失败"))
 		}
-		defer rows.Close()
 
-		if rows.Next() {
-			err = rows.Scan(&total)
+		// 子节点按查询顺序追加，保持类型与名称的排序
+		children := map[string][]*PermissionEntity{}
+		var roots []*PermissionEntity
+		for i := range entities {
+			entity := &entities[i]
+			if entity.ParentID == nil {
+				roots = append(roots, entity)
+			} else {
+				children[*entity.ParentID] = append(children[*entity.ParentID], entity)
+			}
 		}
-		if err != nil {
-			r.logger.Error("统计权限数量失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询权限列表失败"))
+		var build func(entity *PermissionEntity) TreeNode
+		build = func(entity *PermissionEntity) TreeNode {
+			name, _ := entity.Translations.Localize(c, entity.Name, nil)
+			node := TreeNode{ID: entity.ID, Name: name, Type: entity.Type, Metadata: entity.Metadata, Namespace: entity.Namespace, Children: []TreeNode{}}
+			for _, child := range children[entity.ID] {
+				node.Children = append(node.Children, build(child))
+			}
+			return node
 		}
 
-		if total == 0 {
-			return mo.Ok(QueryListRes{
-				List:  []PermissionItem{},
-				Total: 0,
-			})
+		res := TreeRes{Menus: []TreeNode{}, Ungrouped: map[string][]TreeNode{}}
+		for _, root := range roots {
+			if root.Type == TypeMenu {
+				res.Menus = append(res.Menus, build(root))
+			} else {
+				res.Ungrouped[root.Type] = append(res.Ungrouped[root.Type], build(root))
+			}
 		}
+		return mo.Ok(res)
+	}
+}
 
-		// 查询列表
-		var entities []PermissionEntity
-		listQuery := `SELECT id, name, type, metadata, created_at, updated_at FROM iacc_permission` + whereCondition + ` ORDER BY ` + req.OrderBy + ` ` + upperOrder + ` LIMIT :limit OFFSET :offset`
-		// 使用 NamedQuery 而不是 PrepareNamed
-		rows, err = r.db.NamedQueryContext(c.Request.Context(), listQuery, params)
-		if err != nil {
-			r.logger.Error("准备命名列表查询失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询权限列表失败"))
+func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
+	return func(req *QueryListReq) mo.Result[QueryListRes] {
+		// 排序字段与排序顺序已在请求参数中校验
+		upperOrder := pkgs.SortDirection(req.Order)
+
+		// 构建查询
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
 		}
-		defer rows.Close()
 
-		for rows.Next() {
-			var entity PermissionEntity
-			err = rows.StructScan(&entity)
+		whereCondition := r.listWhere(c, &req.ListFilter, params)
+
+		// 排序与分页
+		// seq 作为决胜字段，保证排序字段值相同时（如批量插入的 created_at）分页顺序稳定
+		orderClause := ` ORDER BY ` + req.OrderBy + ` ` + upperOrder + `, seq ` + upperOrder + ` LIMIT :limit OFFSET :offset`
+		var total int64
+		if req.CursorPagination.Enabled() {
+			// 游标分页不统计总数
+			whereCondition, orderClause = req.CursorPagination.Keyset(whereCondition, upperOrder, params)
+		} else {
+			// 查询总数
+			var err error
+			total, err = r.count(c, r.conn(c), r.tables.Permission, whereCondition, params)
 			if err != nil {
-				r.logger.Error("扫描行数据失败", zap.Error(err))
+				r.log(c).Error("统计权限数量失败", zap.Error(err))
 				return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询权限列表失败"))
 			}
-			entities = append(entities, entity)
+
+			if total == 0 {
+				return mo.Ok(QueryListRes{
+					List:  []PermissionItem{},
+					Total: 0,
+				})
+			}
 		}
+
+		// 查询列表
+		listQuery := `SELECT id, name, type, metadata, namespace, translations, attributes, parent_id, created_at, updated_at, seq FROM ` + r.tables.Permission + whereCondition + orderClause
+		// 逐行扫描前检查请求是否已取消，并检查遍历是否中途失败
+		entities, err := pkgs.NamedQueryAll[permissionListEntity](c.Request.Context(), r.conn(c), listQuery, params)
 		if err != nil {
-			r.logger.Error("查询权限列表失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询权限列表失败"))
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询权限列表失败"))
 		}
+		entities, nextCursor := pkgs.CursorPage(req.CursorPagination, entities, func(e permissionListEntity) (time.Time, int64) { return e.CreatedAt, e.Seq })
 
 		// 转换并返回结果
 		var responseEntities []PermissionItem
 		for _, entity := range entities {
+			name, _ := entity.Translations.Localize(c, entity.Name, nil)
 			responseEntities = append(responseEntities, PermissionItem{
-				ID:        entity.ID,
-				Name:      entity.Name,
-				Type:      entity.Type,
-				Metadata:  entity.Metadata,
-				CreatedAt: entity.CreatedAt.Format(time.RFC3339),
-				UpdatedAt: entity.UpdatedAt.Format(time.RFC3339),
+				ID:         entity.ID,
+				Name:       name,
+				Type:       entity.Type,
+				Metadata:   entity.Metadata,
+				Namespace:  entity.Namespace,
+				Attributes: entity.Attributes,
+				ParentID:   entity.ParentID,
+				CreatedAt:  pkgs.FormatTime(c, entity.CreatedAt),
+				UpdatedAt:  pkgs.FormatTime(c, entity.UpdatedAt),
 			})
 		}
 
 		return mo.Ok(QueryListRes{
-			List:  responseEntities,
-			Total: total,
+			List:       responseEntities,
+			Total:      total,
+			NextCursor: nextCursor,
 		})
 	}
 }
+
+// CheckModified 列表的条件请求：权限表自上次请求后没有变化时返回 304
+func (r *Repository) CheckModified(c *gin.Context) func(*QueryListReq) mo.Result[*QueryListReq] {
+	return pkgs.CheckModified[QueryListReq](c, r.conn(c), r.tables.Permission)
+}
+
+// Count 统计满足筛选条件的权限数量，筛选条件与列表接口相同，在报表连接上查询
+func (r *Repository) Count(c *gin.Context) func(*CountReq) mo.Result[CountRes] {
+	return func(req *CountReq) mo.Result[CountRes] {
+		params := map[string]any{}
+		db, tables := r.reportConn(c)
+		total, err := r.count(c, db, tables.Permission, r.listWhere(c, req, params), params)
+		if err != nil {
+			return mo.Err[CountRes](pkgs.DBError(r.log(c), err, "统计权限数量失败"))
+		}
+		return mo.Ok(total)
+	}
+}
+
+// Exists 检查是否存在指定名称的权限（精确匹配）
+func (r *Repository) Exists(c *gin.Context) func(*ExistsReq) mo.Result[ExistsRes] {
+	return func(req *ExistsReq) mo.Result[ExistsRes] {
+		var exists bool
+		query := `SELECT EXISTS (SELECT 1 FROM ` + r.tables.Permission + ` WHERE name = $1)`
+		if err := r.conn(c).GetContext(c.Request.Context(), &exists, query, req.Name); err != nil {
+			return mo.Err[ExistsRes](pkgs.DBError(r.log(c), err, "检查权限是否存在失败"))
+		}
+		return mo.Ok(exists)
+	}
+}
+
+// Values 统计满足筛选条件的权限中指定字段的各取值及数量，在报表连接上查询
+func (r *Repository) Values(c *gin.Context) func(*ValuesReq) mo.Result[ValuesRes] {
+	return func(req *ValuesReq) mo.Result[ValuesRes] {
+		params := map[string]any{}
+		db, tables := r.reportConn(c)
+		query, args, err := db.BindNamed(valueFields.Query(req.Field, tables.Permission, r.listWhere(c, &req.ListFilter, params)), params)
+		if err != nil {
+			r.log(c).Error("构建权限取值查询失败", zap.Error(err))
+			return mo.Err[ValuesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询权限字段取值失败"))
+		}
+		values := ValuesRes{}
+		if err := db.SelectContext(c.Request.Context(), &values, query, args...); err != nil {
+			return mo.Err[ValuesRes](pkgs.DBError(r.log(c), err, "查询权限字段取值失败"))
+		}
+		return mo.Ok(values)
+	}
+}
+
+// listWhere 构建列表、计数与取值接口共用的查询条件，返回 WHERE 子句，没有条件时为空
+func (r *Repository) listWhere(c *gin.Context, filter *ListFilter, params map[string]any) string {
+	var whereClauses []string
+	if filter.Name != "" {
+		whereClauses = append(whereClauses, "name ILIKE :name")
+		params["name"] = "%" + filter.Name + "%"
+	}
+	if filter.Type != "" {
+		whereClauses = append(whereClauses, "type = :type")
+		params["type"] = filter.Type
+	}
+	if filter.Namespace != "" {
+		whereClauses = append(whereClauses, pkgs.NamespaceMatchSQL(":namespace", "namespace"))
+		params["namespace"] = filter.Namespace
+	}
+	whereClauses = append(whereClauses, filter.Attr.Where("attributes", params)...)
+	whereClauses = append(whereClauses, filter.DateRange.Where(c, params)...)
+	whereClauses = append(whereClauses, filterColumns.Where(c, filter.Filter, params)...)
+
+	if len(whereClauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(whereClauses, " AND ")
+}
+
+// count 统计满足查询条件的权限数量
+func (r *Repository) count(c *gin.Context, db *sqlx.DB, table, whereCondition string, params map[string]any) (int64, error) {
+	query, args, err := db.BindNamed("SELECT count(*) FROM "+table+whereCondition, params)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	err = db.GetContext(c.Request.Context(), &total, query, args...)
+	return total, err
+}
+
+// Snapshot 读取权限的审计快照
+func (r *Repository) Snapshot(c *gin.Context, ids []string) (map[string]map[string]any, error) {
+	query := `SELECT p.id, to_jsonb(p) - 'updated_at' - 'seq' AS state FROM ` + r.tables.Permission + ` p WHERE p.id = ANY($1)`
+	return pkgs.QueryAuditStates(c.Request.Context(), r.conn(c), query, pkgs.PGArray(ids))
+}
+
+// toGetByIDRes 将数据库实体转换为权限详情
+// GetTranslations 查询权限名称的全部翻译
+func (r *Repository) GetTranslations(c *gin.Context) func(*GetTranslationsReq) mo.Result[GetTranslationsRes] {
+	return func(req *GetTranslationsReq) mo.Result[GetTranslationsRes] {
+		var translations pkgs.Translations
+		query := `SELECT translations FROM ` + r.tables.Permission + ` WHERE id = $1`
+		err := r.conn(c).GetContext(c.Request.Context(), &translations, query, req.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return mo.Err[GetTranslationsRes](pkgs.NewApiError(http.StatusNotFound, "权限不存在"))
+			}
+			return mo.Err[GetTranslationsRes](pkgs.DBError(r.log(c), err, "查询权限翻译失败"))
+		}
+		if translations == nil {
+			translations = pkgs.Translations{}
+		}
+		return mo.Ok(translations)
+	}
+}
+
+// PutTranslation 设置权限某一语言的翻译，语言标签规范化后作为键
+func (r *Repository) PutTranslation(c *gin.Context) func(*PutTranslationReq) mo.Result[PutTranslationRes] {
+	return func(req *PutTranslationReq) mo.Result[PutTranslationRes] {
+		locale, _ := pkgs.CanonicalLocale(req.Locale)
+		translation, err := json.Marshal(pkgs.Translation{Name: req.Name})
+		if err != nil {
+			r.log(c).Error("序列化权限翻译失败", zap.Error(err))
+			return mo.Err[PutTranslationRes](pkgs.NewApiError(http.StatusInternalServerError, "设置权限翻译失败"))
+		}
+		query := `UPDATE ` + r.tables.Permission + ` SET translations = translations || jsonb_build_object($2::text, $3::jsonb) WHERE id = $1`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID, locale, string(translation))
+		if err != nil {
+			return mo.Err[PutTranslationRes](pkgs.DBError(r.log(c), err, "设置权限翻译失败"))
+		}
+		rowsAffected, _ := res.RowsAffected()
+		if rowsAffected == 0 {
+			return mo.Err[PutTranslationRes](pkgs.NewApiError(http.StatusNotFound, "权限不存在"))
+		}
+		return mo.Ok(rowsAffected)
+	}
+}
+
+// DeleteTranslation 删除权限某一语言的翻译，翻译不存在时返回 0
+func (r *Repository) DeleteTranslation(c *gin.Context) func(*DeleteTranslationReq) mo.Result[DeleteTranslationRes] {
+	return func(req *DeleteTranslationReq) mo.Result[DeleteTranslationRes] {
+		locale, _ := pkgs.CanonicalLocale(req.Locale)
+		query := `UPDATE ` + r.tables.Permission + ` SET translations = translations - $2::text WHERE id = $1 AND jsonb_exists(translations, $2)`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID, locale)
+		if err != nil {
+			return mo.Err[DeleteTranslationRes](pkgs.DBError(r.log(c), err, "删除权限翻译失败"))
+		}
+		rowsAffected, _ := res.RowsAffected()
+		return mo.Ok(rowsAffected)
+	}
+}
+
+func toGetByIDRes(c *gin.Context, entity *PermissionEntity) GetByIDRes {
+	name, _ := entity.Translations.Localize(c, entity.Name, nil)
+	return GetByIDRes{
+		ID:         entity.ID,
+		Name:       name,
+		Type:       entity.Type,
+		Metadata:   entity.Metadata,
+		Namespace:  entity.Namespace,
+		Attributes: entity.Attributes,
+		ParentID:   entity.ParentID,
+		CreatedAt:  pkgs.FormatTime(c, entity.CreatedAt),
+		UpdatedAt:  pkgs.FormatTime(c, entity.UpdatedAt),
+	}
+}
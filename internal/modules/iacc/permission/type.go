@@ -6,6 +6,13 @@ import (
 	"time"
 )
 
+// 权限类型：菜单权限可以作为权限树的父节点，其下挂子菜单、按钮与接口权限
+const (
+	TypeMenu   = "menu"
+	TypeButton = "button"
+	TypeAPI    = "api"
+)
+
 // Permision元数据类型
 type Metadata struct {
 	Path   *string `json:"path,omitempty" label:"接口路径"`
@@ -23,6 +30,21 @@ func (p *Metadata) Scan(value any) error {
 	return pkgs.GenericJSONScan(p, value)
 }
 
+// defaultNamespace 按编码或接口路径推断命名空间，无法推断时返回 nil
+func (p Metadata) defaultNamespace() *string {
+	var code, path string
+	if p.Code != nil {
+		code = *p.Code
+	}
+	if p.Path != nil {
+		path = *p.Path
+	}
+	if namespace := pkgs.DefaultPermissionNamespace(code, path); namespace != "" {
+		return &namespace
+	}
+	return nil
+}
+
 // 数据库表Permission的表结构
 type PermissionEntity struct {
 	ID        string    `db:"id" label:"权限ID"`
@@ -31,6 +53,20 @@ type PermissionEntity struct {
 	Name      string    `db:"name" label:"权限名称"`
 	Type      string    `db:"type" label:"权限类型"`
 	Metadata  Metadata  `db:"metadata" label:"权限元数据"`
+	// 层级的命名空间，如 user、report:export，给角色授予命名空间即授予其下（含子命名空间）的全部权限
+	Namespace *string `db:"namespace" label:"命名空间"`
+	// 名称的多语言翻译，列表与详情接口按 Accept-Language 返回
+	Translations pkgs.Translations `db:"translations" label:"翻译"`
+	// 自定义属性，键与值类型由属性定义约束
+	Attributes pkgs.Attributes `db:"attributes" label:"自定义属性"`
+	// 权限树中的父节点（菜单权限），为空时位于根级
+	ParentID *string `db:"parent_id" label:"父节点ID"`
+}
+
+// permissionListEntity 列表查询的一行，seq 用于游标分页
+type permissionListEntity struct {
+	PermissionEntity
+	Seq int64 `db:"seq"`
 }
 
 // 创建权限的请求 DTO
@@ -38,6 +74,12 @@ type CreatePermissionReq struct {
 	Name     string   `json:"name" validate:"required" label:"权限名称"`
 	Type     string   `json:"type" validate:"required" label:"权限类型"`
 	Metadata Metadata `json:"metadata" label:"权限元数据"`
+	// 命名空间，如 user、report:export；不传时按编码或接口路径推断（report:export:csv 属于 report:export，/v1/user/list 属于 user）
+	Namespace *string `json:"namespace,omitempty" validate:"omitempty,permission_namespace" label:"命名空间"`
+	// 自定义属性，只能使用已定义的属性，必须包含全部必填属性
+	Attributes pkgs.Attributes `json:"attributes,omitempty" label:"自定义属性"`
+	// 权限树中的父节点，必须是菜单权限；不传时位于根级
+	ParentID *string `json:"parent_id,omitempty" validate:"omitempty,uuid" label:"父节点ID"`
 }
 
 // 创建权限的响应 DTO
@@ -50,12 +92,15 @@ type GetByIDReq struct {
 
 // 根据ID获取权限的响应
 type GetByIDRes struct {
-	ID        string   `json:"id" label:"权限ID"`
-	Name      string   `json:"name" label:"权限名称"`
-	Type      string   `json:"type" label:"权限类型"`
-	Metadata  Metadata `json:"metadata,omitempty" label:"权限元数据"`
-	CreatedAt string   `json:"created_at" label:"创建时间"`
-	UpdatedAt string   `json:"updated_at" label:"更新时间"`
+	ID         string          `json:"id" label:"权限ID"`
+	Name       string          `json:"name" label:"权限名称"`
+	Type       string          `json:"type" label:"权限类型"`
+	Metadata   Metadata        `json:"metadata,omitempty" label:"权限元数据"`
+	Namespace  *string         `json:"namespace" label:"命名空间"`
+	Attributes pkgs.Attributes `json:"attributes" label:"自定义属性"`
+	ParentID   *string         `json:"parent_id" label:"父节点ID"`
+	CreatedAt  string          `json:"created_at" label:"创建时间"`
+	UpdatedAt  string          `json:"updated_at" label:"更新时间"`
 }
 
 // 更新权限的请求体
@@ -64,14 +109,42 @@ type UpdatePermissionReq struct {
 	Name     *string   `json:"name,omitempty" label:"权限名称"`
 	Type     *string   `json:"type,omitempty" label:"权限类型"`
 	Metadata *Metadata `json:"metadata,omitempty" label:"权限元数据"`
+	// 命名空间，修改元数据时不会重新推断
+	Namespace *string `json:"namespace,omitempty" validate:"omitempty,permission_namespace" label:"命名空间"`
+	// 自定义属性，传入时整体替换，必须包含全部必填属性
+	Attributes pkgs.Attributes `json:"attributes,omitempty" label:"自定义属性"`
 }
 
 // 更新权限的响应体
 type UpdatePermissionRes = int64
 
+// 部分更新权限的请求体（JSON Merge Patch，metadata 按字段递归合并，字段为 null 表示删除）
+type PatchPermissionReq struct {
+	pkgs.MergePatch `json:"-" swaggerignore:"true"`
+	ID              string    `uri:"id" json:"-" validate:"required,uuid" label:"权限ID"`
+	Name            *string   `json:"name,omitempty" label:"权限名称"`
+	Type            *string   `json:"type,omitempty" label:"权限类型"`
+	Metadata        *Metadata `json:"metadata,omitempty" label:"权限元数据"`
+	// 命名空间，null 表示移出命名空间
+	Namespace *string `json:"namespace,omitempty" validate:"omitempty,permission_namespace" label:"命名空间"`
+	// 自定义属性，与已有属性合并，属性值为 null 表示删除该属性
+	Attributes pkgs.Attributes `json:"attributes,omitempty" label:"自定义属性"`
+}
+
+// 权限名称、类型、元数据、自定义属性均不可为空，不允许通过 null 清空
+func patchRule(req *PatchPermissionReq) []pkgs.Violation {
+	violations := req.NotNull("name", "权限名称")
+	violations = append(violations, req.NotNull("type", "权限类型")...)
+	violations = append(violations, req.NotNull("metadata", "权限元数据")...)
+	return append(violations, req.NotNull("attributes", "自定义属性")...)
+}
+
+// 部分更新权限的响应体
+type PatchPermissionRes = int64
+
 // 根据ID删除权限的请求参数
 type DeleteByIDReq struct {
-	ID string `uri:"id" binding:"required,uuid" label:"权限ID"`
+	ID string `uri:"id" validate:"required,uuid" label:"权限ID"`
 }
 
 // 根据ID删除权限的响应
@@ -79,26 +152,159 @@ type DeleteByIDRes = int64
 
 // 查询权限的请求体
 type QueryListReq struct {
-	Page     int    `form:"page,default=1" validate:"min=1" label:"页码"`
-	PageSize int    `form:"pageSize,default=10" validate:"min=1,max=100" label:"每页大小"`
-	Name     string `form:"name,omitempty" validate:"omitempty" label:"权限名称"`
-	Type     string `form:"type,omitempty" validate:"omitempty" label:"权限类型"`
-	OrderBy  string `form:"orderBy,default=id" validate:"omitempty" label:"排序字段"`
-	Order    string `form:"order,default=desc" validate:"omitempty" label:"排序顺序"`
+	pkgs.Pagination
+	pkgs.CursorPagination
+	ListFilter
+	OrderBy string `form:"orderBy,default=created_at" validate:"oneof=id name type namespace created_at updated_at" label:"排序字段"`
+	Order   string `form:"order,default=desc" validate:"sort_order" label:"排序顺序"`
+}
+
+func queryListRule(req *QueryListReq) []pkgs.Violation {
+	return append(listFilterRule(&req.ListFilter), req.CursorPagination.Violations(req.OrderBy)...)
+}
+
+// 权限列表的筛选条件，列表与计数接口共用
+type ListFilter struct {
+	Name string `form:"name,omitempty" validate:"omitempty" label:"权限名称"`
+	Type string `form:"type,omitempty" validate:"omitempty" label:"权限类型"`
+	// 按命名空间筛选，包含子命名空间（report 包含 report:export）
+	Namespace string `form:"namespace,omitempty" validate:"omitempty,permission_namespace" label:"命名空间"`
+	// 按自定义属性筛选，格式为 键:值，可重复传入，需同时满足
+	Attr pkgs.AttributeFilters `form:"attr" label:"自定义属性"`
+	// 通用过滤表达式，如 name:like:foo,created_at:gte:2024-01-01，可筛选的字段见 filterColumns
+	Filter string `form:"filter" validate:"omitempty,max=2048" label:"过滤表达式"`
+	pkgs.DateRange
+}
+
+// 过滤表达式可以筛选的字段
+var filterColumns = pkgs.FilterColumns{
+	"id":         {Expr: "id", Type: pkgs.FilterUUID},
+	"name":       {Expr: "name", Type: pkgs.FilterText},
+	"type":       {Expr: "type", Type: pkgs.FilterText},
+	"method":     {Expr: "metadata->>'method'", Type: pkgs.FilterText},
+	"path":       {Expr: "metadata->>'path'", Type: pkgs.FilterText},
+	"code":       {Expr: "metadata->>'code'", Type: pkgs.FilterText},
+	"namespace":  {Expr: "namespace", Type: pkgs.FilterText},
+	"created_at": {Expr: "created_at", Type: pkgs.FilterTime},
+	"updated_at": {Expr: "updated_at", Type: pkgs.FilterTime},
+}
+
+func listFilterRule(req *ListFilter) []pkgs.Violation {
+	violations := append(req.DateRange.Violations(), req.Attr.Violations("attr")...)
+	return append(violations, filterColumns.Violations("filter", req.Filter)...)
+}
+
+// 统计权限数量的请求参数
+type CountReq = ListFilter
+
+// 统计权限数量的响应
+type CountRes = int64
+
+// 检查权限名称是否已存在的请求参数
+type ExistsReq struct {
+	Name string `form:"name" validate:"required" label:"权限名称"`
+}
+
+// 检查权限名称是否已存在的响应
+type ExistsRes = bool
+
+// 查询权限字段取值的请求参数，筛选条件与列表接口相同
+type ValuesReq struct {
+	Field string `form:"field" validate:"required" label:"字段"`
+	ListFilter
 }
 
+// 允许查询取值的字段
+var valueFields = pkgs.DistinctFields{
+	"type":      "type",
+	"method":    "metadata->>'method'",
+	"namespace": "namespace",
+}
+
+func valuesRule(req *ValuesReq) []pkgs.Violation {
+	return append(valueFields.Violations(req.Field), listFilterRule(&req.ListFilter)...)
+}
+
+// 查询权限字段取值的响应
+type ValuesRes = []pkgs.DistinctValue
+
 // 权限响应项
 type PermissionItem struct {
-	ID        string   `json:"id" label:"权限ID"`
-	Name      string   `json:"name" label:"权限名称"`
-	Type      string   `json:"type" label:"权限类型"`
-	Metadata  Metadata `json:"metadata,omitempty" label:"权限元数据"`
-	CreatedAt string   `json:"created_at" label:"创建时间"`
-	UpdatedAt string   `json:"updated_at" label:"更新时间"`
+	ID         string          `json:"id" label:"权限ID"`
+	Name       string          `json:"name" label:"权限名称"`
+	Type       string          `json:"type" label:"权限类型"`
+	Metadata   Metadata        `json:"metadata,omitempty" label:"权限元数据"`
+	Namespace  *string         `json:"namespace" label:"命名空间"`
+	Attributes pkgs.Attributes `json:"attributes" label:"自定义属性"`
+	ParentID   *string         `json:"parent_id" label:"父节点ID"`
+	CreatedAt  string          `json:"created_at" label:"创建时间"`
+	UpdatedAt  string          `json:"updated_at" label:"更新时间"`
 }
 
-// 分页列表权限响应
+// 分页列表权限响应，游标分页时不统计总数（total 为 0）
 type QueryListRes struct {
-	List  []PermissionItem `json:"list"`
-	Total int64            `json:"total"`
+	List       []PermissionItem `json:"list"`
+	Total      int64            `json:"total"`
+	NextCursor string           `json:"next_cursor,omitempty" label:"下一页游标"`
+}
+
+// 查询权限翻译的请求参数
+type GetTranslationsReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"权限ID"`
+}
+
+// 查询权限翻译的响应，键为语言标签
+type GetTranslationsRes = pkgs.Translations
+
+// 设置权限某一语言翻译的请求体，已有的翻译整体替换
+type PutTranslationReq struct {
+	ID     string `uri:"id" json:"-" validate:"required,uuid" label:"权限ID"`
+	Locale string `uri:"locale" json:"-" validate:"required" label:"语言"`
+	Name   string `json:"name" validate:"required" label:"权限名称"`
+}
+
+func putTranslationRule(req *PutTranslationReq) []pkgs.Violation {
+	return pkgs.LocaleViolations("locale", req.Locale)
+}
+
+// 设置权限翻译的响应
+type PutTranslationRes = int64
+
+// 删除权限某一语言翻译的请求参数
+type DeleteTranslationReq struct {
+	ID     string `uri:"id" validate:"required,uuid" label:"权限ID"`
+	Locale string `uri:"locale" validate:"required" label:"语言"`
+}
+
+func deleteTranslationRule(req *DeleteTranslationReq) []pkgs.Violation {
+	return pkgs.LocaleViolations("locale", req.Locale)
+}
+
+// 删除权限翻译的响应
+type DeleteTranslationRes = int64
+
+// 移动权限树节点的请求体
+type MoveReq struct {
+	ID string `uri:"id" json:"-" validate:"required,uuid" label:"权限ID"`
+	// 新的父节点，必须是菜单权限，且不能是节点自身或其子孙节点；为空时移到根级
+	ParentID *string `json:"parent_id" validate:"omitempty,uuid" label:"父节点ID"`
+}
+
+// 移动权限树节点的响应
+type MoveRes = int64
+
+// 权限树的节点，子节点按菜单、按钮、接口、其他类型排列，同类型按名称排序
+type TreeNode struct {
+	ID        string     `json:"id" label:"权限ID"`
+	Name      string     `json:"name" label:"权限名称"`
+	Type      string     `json:"type" label:"权限类型"`
+	Metadata  Metadata   `json:"metadata,omitempty" label:"权限元数据"`
+	Namespace *string    `json:"namespace" label:"命名空间"`
+	Children  []TreeNode `json:"children" label:"子节点"`
+}
+
+// 权限树：menus 为根级菜单及其子树，ungrouped 为不属于任何菜单的根级权限，按权限类型分组
+type TreeRes struct {
+	Menus     []TreeNode            `json:"menus" label:"菜单"`
+	Ungrouped map[string][]TreeNode `json:"ungrouped" label:"未归属菜单的权限"`
 }
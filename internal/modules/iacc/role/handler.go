@@ -25,17 +25,45 @@ type Handler struct {
 	logger     *zap.Logger
 	validator  *pkgs.RequestValidator
 	repository *Repository
+	cache      *pkgs.PermissionCache
+	events     *pkgs.SecurityEvents
+	audit      *pkgs.AuditLog
 }
 
-func NewRoleHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator) *Handler {
-	return &Handler{
+func NewRoleHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, reporting *pkgs.ReportingDB, ids *pkgs.IDGenerator, cache *pkgs.PermissionCache, events *pkgs.SecurityEvents, notifier *pkgs.Notifier, audit *pkgs.AuditLog) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, batchCreateRule)
+	pkgs.RegisterRule(validator, getByIDRule)
+	pkgs.RegisterRule(validator, queryListRule)
+	pkgs.RegisterRule(validator, listFilterRule)
+	pkgs.RegisterRule(validator, valuesRule)
+	pkgs.RegisterRule(validator, batchDeleteRule)
+	pkgs.RegisterRule(validator, assignPermissionsRule)
+	pkgs.RegisterRule(validator, patchRule)
+	pkgs.RegisterRule(validator, syncPermissionsRule)
+	pkgs.RegisterRule(validator, putTranslationRule)
+	pkgs.RegisterRule(validator, deleteTranslationRule)
+
+	repository := &Repository{
 		db:        db,
 		logger:    logger,
-		validator: validator,
-		repository: &Repository{
-			db:     db,
-			logger: logger,
-		},
+		tables:    tables,
+		pool:      pool,
+		reporting: reporting,
+		ids:       ids,
+		// 关键角色的变更通知审批人
+		notifier: notifier,
+	}
+	audit.RegisterSnapshot(pkgs.AuditEntityRole, repository.Snapshot)
+
+	return &Handler{
+		db:         db,
+		logger:     logger,
+		validator:  validator,
+		cache:      cache,
+		events:     events,
+		audit:      audit,
+		repository: repository,
 	}
 }
 
@@ -47,15 +75,31 @@ func NewRoleHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestVali
 //	@Accept   json
 //	@Produce  json
 //	@Param    request body  CreateReq true  "创建角色请求参数"
+//	@Param    return  query string  false "返回内容，entity 时返回完整角色（data 为 GetByIDRes）"  Enums(id, entity)
 //	@Success  200   {object}  pkgs.Response{data=CreateRes}  "创建成功，返回角色ID"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/role"}
 //	@Router   /role [post]
 func (h *Handler) Create(c *gin.Context) {
-	result.Pipe2(
+	if pkgs.WantEntity(c) {
+		result.Pipe3(
+			pkgs.BindJSON[CreateReq](c),
+			result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+			result.FlatMap(h.repository.CreateEntity(c)),
+			result.Map(pkgs.RecordAuditEach[GetByIDRes](c, h.audit, "create", pkgs.AuditEntityRole, func(res GetByIDRes) []string { return []string{res.ID} })),
+		).Match(
+			pkgs.HandleSuccess[GetByIDRes](c),
+			pkgs.HandleError[GetByIDRes](c),
+		)
+		return
+	}
+
+	result.Pipe3(
 		pkgs.BindJSON[CreateReq](c),
 		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
 		result.FlatMap(h.repository.Create(c)),
+		result.Map(pkgs.RecordAuditEach[CreateRes](c, h.audit, "create", pkgs.AuditEntityRole, func(id CreateRes) []string { return []string{string(id)} })),
 	).Match(
 		pkgs.HandleSuccess[CreateRes](c),
 		pkgs.HandleError[CreateRes](c),
@@ -70,15 +114,31 @@ func (h *Handler) Create(c *gin.Context) {
 //	@Accept   json
 //	@Produce  json
 //	@Param    request body  BatchCreateReq  true  "批量创建角色请求参数"
+//	@Param    return  query string  false "返回内容，entity 时返回完整角色列表（data 为 BatchCreateEntityRes）"  Enums(id, entity)
 //	@Success  200   {object}  pkgs.Response{data=BatchCreateRes}  "创建成功，返回角色ID列表"
 //	@Failure  400   {object}  pkgs.Response         "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response         "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/role/batch-create"}
 //	@Router   /role/batch-create [post]
 func (h *Handler) BatchCreate(c *gin.Context) {
-	result.Pipe2(
+	if pkgs.WantEntity(c) {
+		result.Pipe3(
+			pkgs.BindJSON[BatchCreateReq](c),
+			result.FlatMap(pkgs.ValidateV2[BatchCreateReq](h.validator)),
+			result.FlatMap(h.repository.BatchCreateEntity(c)),
+			result.Map(pkgs.RecordAuditEach[BatchCreateEntityRes](c, h.audit, "batch_create", pkgs.AuditEntityRole, BatchCreateEntityRes.ids)),
+		).Match(
+			pkgs.HandleSuccess[BatchCreateEntityRes](c),
+			pkgs.HandleError[BatchCreateEntityRes](c),
+		)
+		return
+	}
+
+	result.Pipe3(
 		pkgs.BindJSON[BatchCreateReq](c),
 		result.FlatMap(pkgs.ValidateV2[BatchCreateReq](h.validator)),
 		result.FlatMap(h.repository.BatchCreate(c)),
+		result.Map(pkgs.RecordAuditEach[BatchCreateRes](c, h.audit, "batch_create", pkgs.AuditEntityRole, func(ids BatchCreateRes) []string { return ids })),
 	).Match(
 		pkgs.HandleSuccess[BatchCreateRes](c),
 		pkgs.HandleError[BatchCreateRes](c),
@@ -88,19 +148,22 @@ func (h *Handler) BatchCreate(c *gin.Context) {
 // GetByID 根据ID获取角色
 //
 //	@Summary  根据ID获取角色
-//	@Description  根据ID获取角色
+//	@Description  根据ID获取角色；include 指定扩展内容时在同一条查询中返回权限列表（permissions）与拥有该角色的用户数（userCount）
+//	@Description  权限列表按继承关系展开：包含全部祖先角色的权限，继承来的权限带 inherited_from（来源角色ID）
 //	@Tags   role
 //	@Accept   json
 //	@Produce  json
 //	@Param    id  path  string  true  "角色ID"
+//	@Param    include query string  false "扩展内容，多个以逗号分隔：permissions, userCount"
 //	@Success  200 {object}  pkgs.Response{data=GetByIDRes}  "获取成功，返回角色信息"
 //	@Failure  400 {object}  pkgs.Response           "请求参数错误"
 //	@Failure  404 {object}  pkgs.Response           "角色不存在"
 //	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/role/:id"}
 //	@Router   /role/{id} [get]
 func (h *Handler) GetByID(c *gin.Context) {
 	result.Pipe2(
-		pkgs.BindUri[GetByIDReq](c),
+		pkgs.BindUriAndQuery[GetByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[GetByIDReq](h.validator)),
 		result.FlatMap(h.repository.GetByID(c)),
 	).Match(
@@ -112,7 +175,8 @@ func (h *Handler) GetByID(c *gin.Context) {
 // UpdateByID 根据ID更新角色
 //
 //	@Summary  根据ID更新角色
-//	@Description  根据ID更新角色
+//	@Description  根据ID更新角色，只会更新请求中包含的字段；description、access_conditions、parent_role_id 传 null 时清空
+//	@Description  parent_role_id 不能是角色自身或其子孙角色，继承关系变化后拥有该角色及其子孙角色的用户权限立即变化
 //	@Tags   role
 //	@Accept   json
 //	@Produce  json
@@ -121,18 +185,56 @@ func (h *Handler) GetByID(c *gin.Context) {
 //	@Success  200   {object}  pkgs.Response{data=UpdateByIDRes}       "更新成功"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Failure  202 {object}  pkgs.Response{data=StagedChangeRes} "关键角色的变更已提交，等待审批"
+//	@x-permission {"method":"PUT","path":"/v1/role/:id"}
 //	@Router   /role/{id} [put]
 func (h *Handler) UpdateByID(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe7(
 		pkgs.BindUriAndJSON[UpdateByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[UpdateByIDReq](h.validator)),
+		result.FlatMap(stageCritical(h.repository, c, ChangeUpdate, func(req *UpdateByIDReq) string { return req.ID })),
+		result.FlatMap(pkgs.AuditBefore[UpdateByIDReq](c, h.audit, pkgs.AuditEntityRole, c.Param("id"))),
 		result.FlatMap(h.repository.UpdateByID(c)),
+		result.Map(pkgs.InvalidatePermissionCache[UpdateByIDRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[UpdateByIDRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "update", "role_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[UpdateByIDRes](c, h.audit, "update", pkgs.AuditEntityRole, c.Param("id"))),
 	).Match(
 		pkgs.HandleSuccess[UpdateByIDRes](c),
 		pkgs.HandleError[UpdateByIDRes](c),
 	)
 }
 
+// PatchByID 根据ID部分更新角色
+//
+//	@Summary  根据ID部分更新角色
+//	@Description  按 JSON Merge Patch（RFC 7386）部分更新角色：未出现的字段保持不变，显式 null 清空可空字段（description、access_conditions、parent_role_id）
+//	@Tags   role
+//	@Accept   json,application/merge-patch+json
+//	@Produce  json
+//	@Param    id    path  string          true  "角色ID"
+//	@Param    request body  PatchByIDReq true  "部分更新角色请求参数"
+//	@Success  200   {object}  pkgs.Response{data=PatchByIDRes}       "更新成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Failure  202 {object}  pkgs.Response{data=StagedChangeRes} "关键角色的变更已提交，等待审批"
+//	@x-permission {"method":"PATCH","path":"/v1/role/:id"}
+//	@Router   /role/{id} [patch]
+func (h *Handler) PatchByID(c *gin.Context) {
+	result.Pipe7(
+		pkgs.BindUriAndMergePatch[PatchByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[PatchByIDReq](h.validator)),
+		result.FlatMap(stageCritical(h.repository, c, ChangePatch, func(req *PatchByIDReq) string { return req.ID })),
+		result.FlatMap(pkgs.AuditBefore[PatchByIDReq](c, h.audit, pkgs.AuditEntityRole, c.Param("id"))),
+		result.FlatMap(h.repository.PatchByID(c)),
+		result.Map(pkgs.InvalidatePermissionCache[PatchByIDRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[PatchByIDRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "patch", "role_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[PatchByIDRes](c, h.audit, "patch", pkgs.AuditEntityRole, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[PatchByIDRes](c),
+		pkgs.HandleError[PatchByIDRes](c),
+	)
+}
+
 // DeleteByID 根据ID删除角色
 //
 //	@Summary  根据ID删除角色
@@ -144,12 +246,19 @@ func (h *Handler) UpdateByID(c *gin.Context) {
 //	@Success  200 {object}  pkgs.Response{data=DeleteByIDRes} "删除成功，返回影响行数"
 //	@Failure  400 {object}  pkgs.Response       "请求参数错误"
 //	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Failure  202 {object}  pkgs.Response{data=StagedChangeRes} "关键角色的变更已提交，等待审批"
+//	@x-permission {"method":"DELETE","path":"/v1/role/:id"}
 //	@Router   /role/{id} [delete]
 func (h *Handler) DeleteByID(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe7(
 		pkgs.BindUri[DeleteByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
+		result.FlatMap(stageCritical(h.repository, c, ChangeDelete, func(req *DeleteByIDReq) string { return req.ID })),
+		result.FlatMap(pkgs.AuditBefore[DeleteByIDReq](c, h.audit, pkgs.AuditEntityRole, c.Param("id"))),
 		result.FlatMap(h.repository.DeleteByID(c)),
+		result.Map(pkgs.InvalidatePermissionCache[DeleteByIDRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[DeleteByIDRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "delete", "role_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[DeleteByIDRes](c, h.audit, "delete", pkgs.AuditEntityRole, c.Param("id"))),
 	).Match(
 		pkgs.HandleSuccess[DeleteByIDRes](c),
 		pkgs.HandleError[DeleteByIDRes](c),
@@ -167,12 +276,17 @@ func (h *Handler) DeleteByID(c *gin.Context) {
 //	@Success  200   {object}  pkgs.Response{data=BatchDeleteRes}       "删除成功，返回影响行数"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/role/batch-delete"}
 //	@Router   /role/batch-delete [post]
 func (h *Handler) BatchDelete(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe6(
 		pkgs.BindJSON[DeleteRolesReq](c),
 		result.FlatMap(pkgs.ValidateV2[DeleteRolesReq](h.validator)),
+		result.FlatMap(pkgs.AuditBeforeEach[DeleteRolesReq](c, h.audit, pkgs.AuditEntityRole, func(req *DeleteRolesReq) []string { return req.IDs })),
 		result.FlatMap(h.repository.BatchDelete(c)),
+		result.Map(pkgs.InvalidatePermissionCache[BatchDeleteRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[BatchDeleteRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "batch_delete"})),
+		result.Map(pkgs.RecordAuditEach[BatchDeleteRes](c, h.audit, "batch_delete", pkgs.AuditEntityRole, nil)),
 	).Match(
 		pkgs.HandleSuccess[BatchDeleteRes](c),
 		pkgs.HandleError[BatchDeleteRes](c),
@@ -188,15 +302,28 @@ func (h *Handler) BatchDelete(c *gin.Context) {
 //	@Produce  json
 //	@Param    page    query int   false "页码"  default(1)
 //	@Param    pageSize  query int   false "每页数量"  default(10)
+//	@Param    cursor  query string  false "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，只支持按创建时间排序，不统计总数"
+//	@Param    limit   query int   false "游标分页的每页数量"  default(10)
 //	@Param    name    query string  false "角色名称"
+//	@Param    attr    query []string  false "按自定义属性筛选，格式为 键:值，可重复传入，需同时满足"  collectionFormat(multi)
+//	@Param    filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、description、critical、parent_role_id、created_at、updated_at"
+//	@Param    createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param    createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param    updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param    updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param    changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Param    If-None-Match  header  string  false  "上次响应的 ETag，列表未变化时返回 304"
+//	@Param    If-Modified-Since  header  string  false  "上次响应的 Last-Modified，列表未变化时返回 304"
 //	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功，返回角色列表"
 //	@Failure  400     {object}  pkgs.Response               "请求参数错误"
 //	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/role/list"}
 //	@Router   /role/list [get]
 func (h *Handler) QueryList(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe3(
 		pkgs.BindQuery[QueryListReq](c),
 		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.CheckModified(c)),
 		result.FlatMap(h.repository.QueryList(c)),
 	).Match(
 		pkgs.HandleSuccess[QueryListRes](c),
@@ -204,10 +331,90 @@ func (h *Handler) QueryList(c *gin.Context) {
 	)
 }
 
+// Count 统计角色数量
+//
+//	@Summary  统计角色数量
+//	@Description  按与角色列表相同的筛选条件统计角色数量，不返回角色数据
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    name    query string  false "角色名称"
+//	@Param    attr    query []string  false "按自定义属性筛选，格式为 键:值，可重复传入，需同时满足"  collectionFormat(multi)
+//	@Param    filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、description、critical、parent_role_id、created_at、updated_at"
+//	@Param    createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param    createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param    updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param    updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param    changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Success  200     {object}  pkgs.Response{data=CountRes}  "角色数量"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/role/count"}
+//	@Router   /role/count [get]
+func (h *Handler) Count(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[CountReq](c),
+		result.FlatMap(pkgs.ValidateV2[CountReq](h.validator)),
+		result.FlatMap(h.repository.Count(c)),
+	).Match(
+		pkgs.HandleSuccess[CountRes](c),
+		pkgs.HandleError[CountRes](c),
+	)
+}
+
+// Exists 检查角色名称是否已存在
+//
+//	@Summary  检查角色名称是否已存在
+//	@Description  按名称精确匹配检查角色是否存在，用于创建、改名前的提示
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    name    query string  true "角色名称"
+//	@Success  200     {object}  pkgs.Response{data=ExistsRes}  "是否存在"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/role/exists"}
+//	@Router   /role/exists [get]
+func (h *Handler) Exists(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[ExistsReq](c),
+		result.FlatMap(pkgs.ValidateV2[ExistsReq](h.validator)),
+		result.FlatMap(h.repository.Exists(c)),
+	).Match(
+		pkgs.HandleSuccess[ExistsRes](c),
+		pkgs.HandleError[ExistsRes](c),
+	)
+}
+
+// Values 查询角色字段的取值及数量
+//
+//	@Summary  查询角色字段取值
+//	@Description  返回满足筛选条件的角色中指定字段的各取值及数量，按数量降序，最多 100 个，用于填充筛选下拉框
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    field   query string  true  "字段"  Enums(critical)
+//	@Param    name    query string  false "角色名称"
+//	@Success  200     {object}  pkgs.Response{data=ValuesRes}  "字段取值及数量"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/role/values"}
+//	@Router   /role/values [get]
+func (h *Handler) Values(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[ValuesReq](c),
+		result.FlatMap(pkgs.ValidateV2[ValuesReq](h.validator)),
+		result.FlatMap(h.repository.Values(c)),
+	).Match(
+		pkgs.HandleSuccess[ValuesRes](c),
+		pkgs.HandleError[ValuesRes](c),
+	)
+}
+
 // AssignPermission 为角色分配权限
 //
 //	@Summary  为角色分配权限
-//	@Description  清空角色现有权限，并重新关联新的权限列表
+//	@Description  清空角色现有权限，并重新关联新的权限列表；deny_permission_ids 中的权限以拒绝效果关联，拒绝优先于其他角色的授予。不修改命名空间授予
 //	@Tags   role
 //	@Accept   json
 //	@Produce  json
@@ -216,22 +423,61 @@ func (h *Handler) QueryList(c *gin.Context) {
 //	@Success  200     {object}  pkgs.Response{data=AssignPermissionsRes} "分配成功"
 //	@Failure  400     {object}  pkgs.Response "请求参数错误"
 //	@Failure  500     {object}  pkgs.Response "服务器内部错误"
+//	@Failure  202 {object}  pkgs.Response{data=StagedChangeRes} "关键角色的变更已提交，等待审批"
+//	@x-permission {"method":"POST","path":"/v1/role/:id/permission"}
 //	@Router   /role/{id}/permission [post]
 func (h *Handler) AssignPermission(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe7(
 		pkgs.BindUriAndJSON[AssignPermissionsByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[AssignPermissionsByIDReq](h.validator)),
+		result.FlatMap(stageCritical(h.repository, c, ChangeAssignPermissions, func(req *AssignPermissionsByIDReq) string { return req.ID })),
+		result.FlatMap(pkgs.AuditBefore[AssignPermissionsByIDReq](c, h.audit, pkgs.AuditEntityRole, c.Param("id"))),
 		result.FlatMap(h.repository.AssignPermissions(c)),
+		result.Map(pkgs.InvalidatePermissionCache[AssignPermissionsRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[AssignPermissionsRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "assign_permissions", "role_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[AssignPermissionsRes](c, h.audit, "assign_permissions", pkgs.AuditEntityRole, c.Param("id"))),
 	).Match(
 		pkgs.HandleSuccess[AssignPermissionsRes](c),
 		pkgs.HandleError[AssignPermissionsRes](c),
 	)
 }
 
+// SyncPermission 同步角色权限
+//
+//	@Summary  同步角色权限
+//	@Description  将角色权限同步为请求中的完整集合：只插入缺少的关联、删除多余的关联、更新授予/拒绝效果变化的关联，未变化的关联保持不动；namespaces、deny_namespaces 按命名空间授予、拒绝权限（如 report 包含 report:export 等子命名空间下的全部权限，求值时展开），同样按差异同步；两者都不传时不修改命名空间授予
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    id      path      string  true  "角色ID"
+//	@Param    request body      SyncPermissionsReq true  "角色最终应拥有的权限ID列表"
+//	@Success  200     {object}  pkgs.Response{data=SyncPermissionsRes} "同步成功，返回新增和移除的关联数"
+//	@Failure  400     {object}  pkgs.Response "请求参数错误或权限不存在"
+//	@Failure  404     {object}  pkgs.Response "角色不存在"
+//	@Failure  500     {object}  pkgs.Response "服务器内部错误"
+//	@Failure  202 {object}  pkgs.Response{data=StagedChangeRes} "关键角色的变更已提交，等待审批"
+//	@x-permission {"method":"PUT","path":"/v1/role/:id/permission/sync"}
+//	@Router   /role/{id}/permission/sync [put]
+func (h *Handler) SyncPermission(c *gin.Context) {
+	result.Pipe7(
+		pkgs.BindUriAndJSON[SyncPermissionsByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[SyncPermissionsByIDReq](h.validator)),
+		result.FlatMap(stageCritical(h.repository, c, ChangeSyncPermissions, func(req *SyncPermissionsByIDReq) string { return req.ID })),
+		result.FlatMap(pkgs.AuditBefore[SyncPermissionsByIDReq](c, h.audit, pkgs.AuditEntityRole, c.Param("id"))),
+		result.FlatMap(h.repository.SyncPermissions(c)),
+		result.Map(pkgs.InvalidatePermissionCache[SyncPermissionsRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[SyncPermissionsRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "sync_permissions", "role_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[SyncPermissionsRes](c, h.audit, "sync_permissions", pkgs.AuditEntityRole, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[SyncPermissionsRes](c),
+		pkgs.HandleError[SyncPermissionsRes](c),
+	)
+}
+
 // GetPermissions 获取角色权限列表
 //
 //	@Summary  获取角色权限列表
-//	@Description  获取指定角色的权限列表
+//	@Description  获取指定角色逐个分配的权限列表与命名空间授予，不含继承的权限（见角色详情 include=permissions）
 //	@Tags   role
 //	@Accept   json
 //	@Produce  json
@@ -240,6 +486,7 @@ func (h *Handler) AssignPermission(c *gin.Context) {
 //	@Failure  400 {object}  pkgs.Response           "请求参数错误"
 //	@Failure  404 {object}  pkgs.Response           "角色不存在"
 //	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/role/:id/permission"}
 //	@Router   /role/{id}/permission [get]
 func (h *Handler) GetPermissions(c *gin.Context) {
 	result.Pipe2(
@@ -251,3 +498,169 @@ func (h *Handler) GetPermissions(c *gin.Context) {
 		pkgs.HandleError[GetRolePermissionsRes](c),
 	)
 }
+
+// GetTranslations 查询角色翻译
+//
+//	@Summary  查询角色翻译
+//	@Description  返回角色显示名称与描述的全部翻译，键为语言标签；列表与详情接口按 Accept-Language 请求头返回对应翻译
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    id  path  string  true  "角色ID"
+//	@Success  200 {object}  pkgs.Response{data=GetTranslationsRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response           "角色不存在"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/role/:id/translation"}
+//	@Router   /role/{id}/translation [get]
+func (h *Handler) GetTranslations(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[GetTranslationsReq](c),
+		result.FlatMap(pkgs.ValidateV2[GetTranslationsReq](h.validator)),
+		result.FlatMap(h.repository.GetTranslations(c)),
+	).Match(
+		pkgs.HandleSuccess[GetTranslationsRes](c),
+		pkgs.HandleError[GetTranslationsRes](c),
+	)
+}
+
+// PutTranslation 设置角色某一语言的翻译
+//
+//	@Summary  设置角色翻译
+//	@Description  设置角色在指定语言下的显示名称与描述，已有的翻译整体替换；语言标签不区分大小写，保存时规范化（如 zh-hans-cn 保存为 zh-Hans-CN）
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    id      path  string              true  "角色ID"
+//	@Param    locale  path  string              true  "语言标签，如 en、en-US"
+//	@Param    request body  PutTranslationReq   true  "翻译内容"
+//	@Success  200 {object}  pkgs.Response{data=PutTranslationRes}  "设置成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response           "角色不存在"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@x-permission {"method":"PUT","path":"/v1/role/:id/translation/:locale"}
+//	@Router   /role/{id}/translation/{locale} [put]
+func (h *Handler) PutTranslation(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindUriAndJSON[PutTranslationReq](c),
+		result.FlatMap(pkgs.ValidateV2[PutTranslationReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[PutTranslationReq](c, h.audit, pkgs.AuditEntityRole, c.Param("id"))),
+		result.FlatMap(h.repository.PutTranslation(c)),
+		result.Map(pkgs.RecordAudit[PutTranslationRes](c, h.audit, "put_translation", pkgs.AuditEntityRole, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[PutTranslationRes](c),
+		pkgs.HandleError[PutTranslationRes](c),
+	)
+}
+
+// DeleteTranslation 删除角色某一语言的翻译
+//
+//	@Summary  删除角色翻译
+//	@Description  删除角色在指定语言下的翻译，之后该语言返回原始名称与描述
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    id      path  string  true  "角色ID"
+//	@Param    locale  path  string  true  "语言标签"
+//	@Success  200 {object}  pkgs.Response{data=DeleteTranslationRes}  "删除成功，返回影响行数（翻译不存在时为 0）"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@x-permission {"method":"DELETE","path":"/v1/role/:id/translation/:locale"}
+//	@Router   /role/{id}/translation/{locale} [delete]
+func (h *Handler) DeleteTranslation(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindUri[DeleteTranslationReq](c),
+		result.FlatMap(pkgs.ValidateV2[DeleteTranslationReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[DeleteTranslationReq](c, h.audit, pkgs.AuditEntityRole, c.Param("id"))),
+		result.FlatMap(h.repository.DeleteTranslation(c)),
+		result.Map(pkgs.RecordAudit[DeleteTranslationRes](c, h.audit, "delete_translation", pkgs.AuditEntityRole, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[DeleteTranslationRes](c),
+		pkgs.HandleError[DeleteTranslationRes](c),
+	)
+}
+
+// QueryChanges 获取关键角色的变更列表
+//
+//	@Summary  获取关键角色的变更列表
+//	@Description  分页查询关键角色暂存的变更，可按审批状态与角色筛选
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    page    query int   false "页码"  default(1)
+//	@Param    pageSize  query int   false "每页数量"  default(10)
+//	@Param    status  query string  false "审批状态"  Enums(pending, approved, rejected)
+//	@Param    role_id query string  false "角色ID"
+//	@Success  200     {object}  pkgs.Response{data=QueryChangesRes}  "获取成功，返回变更列表"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/role/change/list"}
+//	@Router   /role/change/list [get]
+func (h *Handler) QueryChanges(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[QueryChangesReq](c),
+		result.FlatMap(pkgs.ValidateV2[QueryChangesReq](h.validator)),
+		result.FlatMap(h.repository.QueryChanges(c)),
+	).Match(
+		pkgs.HandleSuccess[QueryChangesRes](c),
+		pkgs.HandleError[QueryChangesRes](c),
+	)
+}
+
+// ApproveChange 审批通过关键角色的变更
+//
+//	@Summary  审批通过关键角色的变更
+//	@Description  审批通过并执行暂存的变更，提交人不能审批自己的变更；执行失败时变更保持待审批
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    id  path  string  true  "变更ID"
+//	@Success  200 {object}  pkgs.Response{data=ApproveChangeRes} "审批成功，返回变更的执行结果"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  403 {object}  pkgs.Response       "不能审批自己提交的变更"
+//	@Failure  404 {object}  pkgs.Response       "变更不存在"
+//	@Failure  409 {object}  pkgs.Response       "变更已审批"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/role/change/:id/approve"}
+//	@Router   /role/change/{id}/approve [post]
+func (h *Handler) ApproveChange(c *gin.Context) {
+	result.Pipe5(
+		pkgs.BindUri[ReviewChangeReq](c),
+		result.FlatMap(pkgs.ValidateV2[ReviewChangeReq](h.validator)),
+		result.FlatMap(h.repository.ApproveChange(c)),
+		result.Map(pkgs.InvalidatePermissionCache[ApproveChangeRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[ApproveChangeRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "approve_change", "change_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[ApproveChangeRes](c, h.audit, "approve_change", pkgs.AuditEntityRoleChange, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[ApproveChangeRes](c),
+		pkgs.HandleError[ApproveChangeRes](c),
+	)
+}
+
+// RejectChange 驳回关键角色的变更
+//
+//	@Summary  驳回关键角色的变更
+//	@Description  驳回暂存的变更，提交人可以撤回自己的变更
+//	@Tags   role
+//	@Accept   json
+//	@Produce  json
+//	@Param    id  path  string  true  "变更ID"
+//	@Success  200 {object}  pkgs.Response{data=RejectChangeRes} "驳回成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response       "变更不存在"
+//	@Failure  409 {object}  pkgs.Response       "变更已审批"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/role/change/:id/reject"}
+//	@Router   /role/change/{id}/reject [post]
+func (h *Handler) RejectChange(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindUri[ReviewChangeReq](c),
+		result.FlatMap(pkgs.ValidateV2[ReviewChangeReq](h.validator)),
+		result.FlatMap(h.repository.RejectChange(c)),
+		result.Map(pkgs.RecordSecurityEvent[RejectChangeRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "reject_change", "change_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[RejectChangeRes](c, h.audit, "reject_change", pkgs.AuditEntityRoleChange, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[RejectChangeRes](c),
+		pkgs.HandleError[RejectChangeRes](c),
+	)
+}
@@ -3,64 +3,181 @@ package role
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"go-pg-demo/pkgs"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/samber/mo"
+	"github.com/samber/mo/result"
 	"go.uber.org/zap"
 )
 
 type Repository struct {
-	db     *sqlx.DB
-	logger *zap.Logger
+	db        *sqlx.DB
+	logger    *zap.Logger
+	tables    *pkgs.TableNames
+	pool      *pkgs.TenantPool
+	reporting *pkgs.ReportingDB
+	ids       *pkgs.IDGenerator
+	// 关键角色的变更通知审批人
+	notifier *pkgs.Notifier
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// reportConn 返回报表查询应使用的连接与表名，配置了分析库时在分析库查询，不占用主库
+func (r *Repository) reportConn(c *gin.Context) (*sqlx.DB, *pkgs.TableNames) {
+	return r.reporting.Conn(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+// checkAttributes 按角色的属性定义校验请求中的自定义属性
+func (r *Repository) checkAttributes(c *gin.Context, complete bool, fields []string, attrs []pkgs.Attributes) *pkgs.ApiError {
+	return pkgs.CheckAttributes(c.Request.Context(), r.conn(c), r.log(c), r.tables, pkgs.AttributeEntityRole, complete, fields, attrs)
+}
+
+// batchConn 返回批量操作应使用的数据库连接，与交互请求的连接池隔离
+func (r *Repository) batchConn(c *gin.Context) *sqlx.DB {
+	return r.pool.BatchDB(c)
 }
 
 func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
 	return func(req *CreateReq) mo.Result[CreateRes] {
+		return result.Pipe1(r.insert(c)(req), result.Map(func(entity *RoleEntity) CreateRes {
+			return CreateRes(entity.ID)
+		}))
+	}
+}
+
+// CreateEntity 创建角色并返回完整实体
+func (r *Repository) CreateEntity(c *gin.Context) func(*CreateReq) mo.Result[GetByIDRes] {
+	return func(req *CreateReq) mo.Result[GetByIDRes] {
+		return result.Pipe1(r.insert(c)(req), result.Map(func(entity *RoleEntity) GetByIDRes {
+			return toGetByIDRes(c, entity)
+		}))
+	}
+}
+
+// insert 插入单个角色，服务端生成的 id/created_at/updated_at 通过 RETURNING 回填到实体
+func (r *Repository) insert(c *gin.Context) func(*CreateReq) mo.Result[*RoleEntity] {
+	return func(req *CreateReq) mo.Result[*RoleEntity] {
+		// 校验自定义属性
+		if apiErr := r.checkAttributes(c, true, []string{"attributes"}, []pkgs.Attributes{req.Attributes}); apiErr != nil {
+			return mo.Err[*RoleEntity](apiErr)
+		}
+		if apiErr := r.checkParent(c, "", req.ParentRoleID); apiErr != nil {
+			return mo.Err[*RoleEntity](apiErr)
+		}
+
 		// 创建实体
 		entity := &RoleEntity{
-			Name:        req.Name,
-			Description: req.Description,
+			Name:             req.Name,
+			Description:      req.Description,
+			AccessConditions: req.AccessConditions,
+			Critical:         req.Critical,
+			Attributes:       req.Attributes.OrEmpty(),
+			ParentRoleID:     req.ParentRoleID,
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[*RoleEntity](pkgs.NewApiError(http.StatusInternalServerError, "创建角色失败"))
 		}
 		// 数据库操作
-		query := `INSERT INTO iacc_role (name, description) VALUES (:name, :description) RETURNING id, created_at, updated_at`
-		stmt, err := r.db.PrepareNamedContext(c.Request.Context(), query)
+		columns, values := r.ids.Insert("name", "description", "access_conditions", "critical", "attributes", "parent_role_id")
+		query := `INSERT INTO ` + r.tables.Role + ` (` + columns + `) VALUES (` + values + `) RETURNING id, created_at, updated_at`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
 		if err != nil {
-			r.logger.Error("创建角色语句准备失败", zap.Error(err))
-			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建角色失败"))
+			r.log(c).Error("创建角色语句准备失败", zap.Error(err))
+			return mo.Err[*RoleEntity](pkgs.NewApiError(http.StatusInternalServerError, "创建角色失败"))
 		}
 		defer stmt.Close()
 
 		err = stmt.GetContext(c.Request.Context(), entity, entity)
 		if err != nil {
-			r.logger.Error("创建角色失败", zap.Error(err))
-			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建角色失败"))
+			return mo.Err[*RoleEntity](pkgs.DBError(r.log(c), err, "创建角色失败"))
 		}
 		// 返回结果
-		return mo.Ok(CreateRes(entity.ID))
+		return mo.Ok(entity)
 	}
 }
 
 func (r *Repository) BatchCreate(c *gin.Context) func(*BatchCreateReq) mo.Result[BatchCreateRes] {
 	return func(req *BatchCreateReq) mo.Result[BatchCreateRes] {
+		return result.Pipe1(r.batchInsert(c)(req), result.Map(func(entities []RoleEntity) BatchCreateRes {
+			createdIDs := make(BatchCreateRes, len(entities))
+			for i, entity := range entities {
+				createdIDs[i] = entity.ID
+			}
+			return createdIDs
+		}))
+	}
+}
+
+// BatchCreateEntity 批量创建角色并返回完整实体列表
+func (r *Repository) BatchCreateEntity(c *gin.Context) func(*BatchCreateReq) mo.Result[BatchCreateEntityRes] {
+	return func(req *BatchCreateReq) mo.Result[BatchCreateEntityRes] {
+		return result.Pipe1(r.batchInsert(c)(req), result.Map(func(entities []RoleEntity) BatchCreateEntityRes {
+			items := make(BatchCreateEntityRes, len(entities))
+			for i := range entities {
+				items[i] = toGetByIDRes(c, &entities[i])
+			}
+			return items
+		}))
+	}
+}
+
+// batchInsert 在同一事务内批量插入角色，服务端生成的字段通过 RETURNING 回填到实体
+func (r *Repository) batchInsert(c *gin.Context) func(*BatchCreateReq) mo.Result[[]RoleEntity] {
+	return func(req *BatchCreateReq) mo.Result[[]RoleEntity] {
+		// 校验自定义属性
+		fields := make([]string, len(req.Roles))
+		attrs := make([]pkgs.Attributes, len(req.Roles))
+		for i, t := range req.Roles {
+			fields[i] = fmt.Sprintf("roles[%d].attributes", i)
+			attrs[i] = t.Attributes
+		}
+		if apiErr := r.checkAttributes(c, true, fields, attrs); apiErr != nil {
+			return mo.Err[[]RoleEntity](apiErr)
+		}
+
+		for _, t := range req.Roles {
+			if apiErr := r.checkParent(c, "", t.ParentRoleID); apiErr != nil {
+				return mo.Err[[]RoleEntity](apiErr)
+			}
+		}
+
 		// 准备批量插入的实体
 		var entities []RoleEntity
 		for _, t := range req.Roles {
 			entities = append(entities, RoleEntity{
-				Name:        t.Name,
-				Description: t.Description,
+				Name:             t.Name,
+				Description:      t.Description,
+				AccessConditions: t.AccessConditions,
+				Critical:         t.Critical,
+				Attributes:       t.Attributes.OrEmpty(),
+				ParentRoleID:     t.ParentRoleID,
 			})
 		}
 
 		// 开启事务
-		tx, err := r.db.BeginTxx(c.Request.Context(), nil)
+		tx, err := r.batchConn(c).BeginTxx(c.Request.Context(), nil)
 		if err != nil {
-			r.logger.Error("开启事务失败", zap.Error(err))
-			return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建角色失败"))
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[[]RoleEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建角色失败"))
 		}
 		defer func() {
 			if p := recover(); p != nil {
@@ -72,60 +189,109 @@ func (r *Repository) BatchCreate(c *gin.Context) func(*BatchCreateReq) mo.Result
 			} else {
 				err = tx.Commit()
 				if err != nil {
-					r.logger.Error("Failed to commit transaction", zap.Error(err))
+					r.log(c).Error("Failed to commit transaction", zap.Error(err))
 					return
 				}
 			}
 		}()
 
 		// 数据库操作
-		query := `INSERT INTO iacc_role (name, description) VALUES (:name, :description) RETURNING id`
+		columns, values := r.ids.Insert("name", "description", "access_conditions", "critical", "attributes", "parent_role_id")
+		query := `INSERT INTO ` + r.tables.Role + ` (` + columns + `) VALUES (` + values + `) RETURNING id, created_at, updated_at`
 		stmt, err := tx.PrepareNamedContext(c.Request.Context(), query)
 		if err != nil {
-			r.logger.Error("准备命名语句失败", zap.Error(err))
-			return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建角色失败"))
+			r.log(c).Error("准备命名语句失败", zap.Error(err))
+			return mo.Err[[]RoleEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建角色失败"))
 		}
 		defer stmt.Close()
 
-		var createdIDs []string
-		for _, entity := range entities {
-			var id string
-			err = stmt.GetContext(c.Request.Context(), &id, entity)
+		for i := range entities {
+			if err = r.ids.Assign(&entities[i].ID); err != nil {
+				r.log(c).Error("生成主键失败", zap.Error(err))
+				return mo.Err[[]RoleEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建角色失败"))
+			}
+			err = stmt.GetContext(c.Request.Context(), &entities[i], entities[i])
 			if err != nil {
-				r.logger.Error("批量创建角色失败", zap.Error(err))
-				return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建角色失败"))
+				return mo.Err[[]RoleEntity](pkgs.DBError(r.log(c), err, "批量创建角色失败"))
 			}
-			createdIDs = append(createdIDs, id)
 		}
 
-		return mo.Ok(BatchCreateRes(createdIDs))
+		return mo.Ok(entities)
 	}
 }
 
 func (r *Repository) GetByID(c *gin.Context) func(*GetByIDReq) mo.Result[GetByIDRes] {
 	return func(req *GetByIDReq) mo.Result[GetByIDRes] {
 
-		// 数据库操作
-		var entity RoleEntity
-		query := `SELECT id, name, description, created_at, updated_at FROM iacc_role WHERE id = $1`
-		err := r.db.GetContext(c.Request.Context(), &entity, query, req.ID)
+		// 数据库操作，扩展内容以子查询的形式在同一条查询中返回
+		var row struct {
+			RoleEntity
+			Permissions []byte `db:"permissions"`
+			UserCount   *int64 `db:"user_count"`
+		}
+		columns := `r.id, r.name, r.description, r.access_conditions, r.critical, r.translations, r.attributes, r.parent_role_id, r.created_at, r.updated_at`
+		if req.Include.Has(IncludePermissions) {
+			// 展开角色自身与祖先角色的权限（含按命名空间授予的权限）：任一角色拒绝即为拒绝，来源取最近的角色（拒绝时取最近的拒绝角色），
+			// 同一角色既逐个分配又按命名空间授予时优先显示逐个分配
+			columns += `, COALESCE((
+				WITH RECURSIVE ` + pkgs.RoleChainCTE(r.tables, "r.id") + `
+				SELECT json_agg(json_build_object('id', x.id, 'name', x.name, 'type', x.type, 'metadata', x.metadata, 'effect', x.effect,
+					'inherited_from', NULLIF(x.role_id, r.id), 'granted_namespace', x.namespace, 'created_at', x.created_at, 'updated_at', x.updated_at) ORDER BY x.created_at DESC, x.seq DESC)
+				FROM (
+					SELECT DISTINCT ON (p.id) p.id, p.name, p.type, p.metadata, p.created_at, p.updated_at, p.seq, rp.effect, rp.role_id, rp.namespace
+					FROM role_chain INNER JOIN (` + pkgs.RolePermissionsSQL(r.tables) + `) rp ON rp.role_id = role_chain.role_id
+					INNER JOIN ` + r.tables.Permission + ` p ON p.id = rp.permission_id
+					ORDER BY p.id, rp.effect = '` + pkgs.RolePermissionDeny + `' DESC, role_chain.depth, rp.namespace NULLS FIRST
+				) x
+			), '[]') AS permissions`
+		}
+		if req.Include.Has(IncludeUserCount) {
+			columns += `, (SELECT COUNT(*) FROM ` + r.tables.UserRole + ` ur WHERE ur.role_id = r.id) AS user_count`
+		}
+		query := `SELECT ` + columns + ` FROM ` + r.tables.Role + ` r WHERE r.id = $1`
+		err := r.conn(c).GetContext(c.Request.Context(), &row, query, req.ID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusNotFound, "角色不存在"))
 			}
-			r.logger.Error("获取角色失败", zap.Error(err))
-			return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "获取角色失败"))
+			return mo.Err[GetByIDRes](pkgs.DBError(r.log(c), err, "获取角色失败"))
 		}
 
 		// 返回结果
-		response := GetByIDRes{
-			ID:          entity.ID,
-			Name:        entity.Name,
-			Description: entity.Description,
-			CreatedAt:   entity.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   entity.UpdatedAt.Format(time.RFC3339),
+		res := toGetByIDRes(c, &row.RoleEntity)
+		res.UserCount = row.UserCount
+		if row.Permissions != nil {
+			var permissions []struct {
+				ID            string         `json:"id"`
+				Name          string         `json:"name"`
+				Type          string         `json:"type"`
+				Metadata      map[string]any `json:"metadata"`
+				Effect        string         `json:"effect"`
+				InheritedFrom *string        `json:"inherited_from"`
+				Namespace     *string        `json:"granted_namespace"`
+				CreatedAt     time.Time      `json:"created_at"`
+				UpdatedAt     time.Time      `json:"updated_at"`
+			}
+			if err := json.Unmarshal(row.Permissions, &permissions); err != nil {
+				r.log(c).Error("解析角色权限失败", zap.Error(err))
+				return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "获取角色失败"))
+			}
+			res.Permissions = make([]PermissionItem, len(permissions))
+			for i, p := range permissions {
+				res.Permissions[i] = PermissionItem{
+					ID:            p.ID,
+					Name:          p.Name,
+					Type:          p.Type,
+					Metadata:      p.Metadata,
+					Effect:        p.Effect,
+					InheritedFrom: p.InheritedFrom,
+					Namespace:     p.Namespace,
+					CreatedAt:     pkgs.FormatTime(c, p.CreatedAt),
+					UpdatedAt:     pkgs.FormatTime(c, p.UpdatedAt),
+				}
+			}
 		}
-		return mo.Ok(response)
+		return mo.Ok(res)
 	}
 }
 
@@ -139,27 +305,48 @@ func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[U
 			params["name"] = *req.Name
 			setClauses = append(setClauses, "name = :name")
 		}
-		if req.Description != nil {
-			params["description"] = *req.Description
+		if req.Description.IsSet() {
+			params["description"] = req.Description.Ptr()
 			setClauses = append(setClauses, "description = :description")
 		}
+		if req.AccessConditions.IsSet() {
+			params["access_conditions"] = req.AccessConditions.Ptr()
+			setClauses = append(setClauses, "access_conditions = :access_conditions")
+		}
+		if req.Critical != nil {
+			params["critical"] = *req.Critical
+			setClauses = append(setClauses, "critical = :critical")
+		}
+		if req.Attributes != nil {
+			if apiErr := r.checkAttributes(c, true, []string{"attributes"}, []pkgs.Attributes{req.Attributes}); apiErr != nil {
+				return mo.Err[UpdateByIDRes](apiErr)
+			}
+			params["attributes"] = req.Attributes
+			setClauses = append(setClauses, "attributes = :attributes")
+		}
+		if req.ParentRoleID.IsSet() {
+			if apiErr := r.checkParent(c, req.ID, req.ParentRoleID.Ptr()); apiErr != nil {
+				return mo.Err[UpdateByIDRes](apiErr)
+			}
+			params["parent_role_id"] = req.ParentRoleID.Ptr()
+			setClauses = append(setClauses, "parent_role_id = :parent_role_id")
+		}
 
 		// 如果没有需要更新的字段，直接返回成功
 		if len(setClauses) == 0 {
 			return mo.Ok(UpdateByIDRes(0))
 		}
 
-		query := "UPDATE iacc_role SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		query := "UPDATE " + r.tables.Role + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
 
 		// 执行数据库操作
-		res, err := r.db.NamedExecContext(c.Request.Context(), query, params)
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
 		if err != nil {
-			r.logger.Error("更新角色失败", zap.Error(err))
-			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新角色失败"))
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新角色失败"))
 		}
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
 			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新角色失败"))
 		}
 		// 返回结果
@@ -167,18 +354,115 @@ func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[U
 	}
 }
 
+// PatchByID 按 JSON Merge Patch 部分更新角色，显式 null 的可空字段会被清空
+func (r *Repository) PatchByID(c *gin.Context) func(*PatchByIDReq) mo.Result[PatchByIDRes] {
+	return func(req *PatchByIDReq) mo.Result[PatchByIDRes] {
+		// 动态构建更新语句，只处理补丁中出现的字段
+		params := map[string]any{"id": req.ID}
+		var setClauses []string
+
+		if req.Has("name") {
+			params["name"] = *req.Name
+			setClauses = append(setClauses, "name = :name")
+		}
+		if req.Has("description") {
+			params["description"] = req.Description
+			setClauses = append(setClauses, "description = :description")
+		}
+		if req.Has("access_conditions") {
+			params["access_conditions"] = req.AccessConditions
+			setClauses = append(setClauses, "access_conditions = :access_conditions")
+		}
+		if req.Has("critical") {
+			params["critical"] = *req.Critical
+			setClauses = append(setClauses, "critical = :critical")
+		}
+		if req.Has("attributes") {
+			if apiErr := r.checkAttributes(c, false, []string{"attributes"}, []pkgs.Attributes{req.Attributes}); apiErr != nil {
+				return mo.Err[PatchByIDRes](apiErr)
+			}
+			set, removed := pkgs.SplitAttributePatch(req.Attributes)
+			params["attributes"] = set
+			params["attributes_removed"] = pkgs.PGArray(removed)
+			setClauses = append(setClauses, "attributes = (attributes || :attributes) - CAST(:attributes_removed AS text[])")
+		}
+		if req.Has("parent_role_id") {
+			if apiErr := r.checkParent(c, req.ID, req.ParentRoleID); apiErr != nil {
+				return mo.Err[PatchByIDRes](apiErr)
+			}
+			params["parent_role_id"] = req.ParentRoleID
+			setClauses = append(setClauses, "parent_role_id = :parent_role_id")
+		}
+
+		// 如果没有需要更新的字段，直接返回成功
+		if len(setClauses) == 0 {
+			return mo.Ok(PatchByIDRes(0))
+		}
+
+		query := "UPDATE " + r.tables.Role + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+
+		// 执行数据库操作
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
+		if err != nil {
+			return mo.Err[PatchByIDRes](pkgs.DBError(r.log(c), err, "更新角色失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[PatchByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新角色失败"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// checkParent 校验父角色：父角色必须存在，且不能是角色自身或其子孙角色（否则继承关系形成环）；
+// 父角色或其祖先角色是关键角色时，继承会绕过关键角色的变更审批，只有本身是关键角色（变更已经审批）的角色可以继承。
+// id 为空表示新建角色，parentID 为空表示取消继承，无需校验
+func (r *Repository) checkParent(c *gin.Context, id string, parentID *string) *pkgs.ApiError {
+	if parentID == nil {
+		return nil
+	}
+	var self any
+	if id != "" {
+		self = id
+	}
+	var row struct {
+		Exists       bool `db:"parent_exists"`
+		Cycle        bool `db:"cycle"`
+		Critical     bool `db:"critical_parent"`
+		SelfCritical bool `db:"self_critical"`
+	}
+	query := `WITH RECURSIVE ` + pkgs.RoleChainCTE(r.tables, "$1") + `
+		SELECT EXISTS(SELECT 1 FROM role_chain) AS parent_exists, EXISTS(SELECT 1 FROM role_chain WHERE role_id = $2) AS cycle,
+			EXISTS(SELECT 1 FROM role_chain rc INNER JOIN ` + r.tables.Role + ` ro ON ro.id = rc.role_id WHERE ro.critical) AS critical_parent,
+			COALESCE((SELECT critical FROM ` + r.tables.Role + ` WHERE id = $2), false) AS self_critical`
+	if err := r.conn(c).GetContext(c.Request.Context(), &row, query, *parentID, self); err != nil {
+		return pkgs.DBError(r.log(c), err, "校验父角色失败")
+	}
+	if !row.Exists {
+		return pkgs.NewApiError(http.StatusBadRequest, "父角色不存在")
+	}
+	if row.Cycle {
+		return pkgs.NewApiError(http.StatusBadRequest, "父角色不能是角色自身或其子孙角色")
+	}
+	if row.Critical && !row.SelfCritical {
+		return pkgs.NewApiError(http.StatusBadRequest, "父角色或其祖先角色是关键角色，不能直接继承")
+	}
+	return nil
+}
+
 func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
 	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
 		// 数据库操作
-		query := `DELETE FROM iacc_role WHERE id = $1`
-		res, err := r.db.ExecContext(c.Request.Context(), query, req.ID)
+		query := `DELETE FROM ` + r.tables.Role + ` WHERE id = $1`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID)
 		if err != nil {
-			r.logger.Error("删除角色失败", zap.Error(err))
-			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除角色失败"))
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除角色失败"))
 		}
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
 			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除角色失败"))
 		}
 
@@ -189,23 +473,26 @@ func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[D
 
 func (r *Repository) BatchDelete(c *gin.Context) func(*DeleteRolesReq) mo.Result[BatchDeleteRes] {
 	return func(req *DeleteRolesReq) mo.Result[BatchDeleteRes] {
-		query, args, err := sqlx.In(`DELETE FROM iacc_role WHERE id IN (?)`, req.IDs)
-		if err != nil {
-			r.logger.Error("构建批量删除查询失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "构建批量删除查询失败"))
+		// 关键角色的删除需要审批，不能通过批量删除绕过
+		var hasCritical bool
+		checkQuery := `SELECT EXISTS(SELECT 1 FROM ` + r.tables.Role + ` WHERE id = ANY($1::uuid[]) AND critical)`
+		if err := r.batchConn(c).GetContext(c.Request.Context(), &hasCritical, checkQuery, pkgs.PGArray(req.IDs)); err != nil {
+			r.log(c).Error("查询关键角色失败", zap.Error(err))
+			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "批量删除角色失败"))
+		}
+		if hasCritical {
+			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusBadRequest, "批量删除不能包含关键角色，请单独删除并等待审批"))
 		}
 
-		query = r.db.Rebind(query)
-		res, err := r.db.ExecContext(c.Request.Context(), query, args...)
+		query := `DELETE FROM ` + r.tables.Role + ` WHERE id = ANY($1)`
+		res, err := r.batchConn(c).ExecContext(c.Request.Context(), query, pkgs.PGArray(req.IDs))
 		if err != nil {
-			r.logger.Error("批量删除角色失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "批量删除角色失败"))
+			return mo.Err[BatchDeleteRes](pkgs.DBError(r.log(c), err, "批量删除角色失败"))
 		}
 
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "获取影响行数失败"))
+			return mo.Err[BatchDeleteRes](pkgs.DBError(r.log(c), err, "获取影响行数失败"))
 		}
 
 		return mo.Ok(affectedRows)
@@ -214,117 +501,156 @@ func (r *Repository) BatchDelete(c *gin.Context) func(*DeleteRolesReq) mo.Result
 
 func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
 	return func(req *QueryListReq) mo.Result[QueryListRes] {
-		// 校验排序字段
-		validOrderBy := map[string]bool{
-			"id":          true,
-			"name":        true,
-			"description": true,
-			"created_at":  true,
-			"updated_at":  true,
-		}
-		if !validOrderBy[req.OrderBy] {
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "排序字段不存在"))
-		}
-
-		// 校验排序顺序
-		upperOrder := strings.ToUpper(req.Order)
-		if upperOrder != "ASC" && upperOrder != "DESC" {
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "排序顺序参数错误"))
-		}
+		// 排序字段与排序顺序已在请求参数中校验
+		upperOrder := pkgs.SortDirection(req.Order)
 
 		// 构建查询
 		params := map[string]any{
 			"limit":  req.PageSize,
-			"offset": (req.Page - 1) * req.PageSize,
+			"offset": req.Offset(),
 		}
 
-		var whereClauses []string
-		if req.Name != "" {
-			whereClauses = append(whereClauses, "name ILIKE :name")
-			params["name"] = "%" + req.Name + "%"
-		}
-
-		whereCondition := ""
-		if len(whereClauses) > 0 {
-			whereCondition = " WHERE " + strings.Join(whereClauses, " AND ")
-		}
+		whereCondition := r.listWhere(c, &req.ListFilter, params)
 
-		// 查询总数
+		// 排序与分页
+		// seq 作为决胜字段，保证排序字段值相同时（如批量插入的 created_at）分页顺序稳定
+		orderClause := ` ORDER BY ` + req.OrderBy + ` ` + upperOrder + `, seq ` + upperOrder + ` LIMIT :limit OFFSET :offset`
 		var total int64
-		countQuery := "SELECT count(*) FROM iacc_role" + whereCondition
-		// 使用 NamedQuery 而不是 PrepareNamed
-		rows, err := r.db.NamedQueryContext(c.Request.Context(), countQuery, params)
-		if err != nil {
-			r.logger.Error("准备命名计数查询失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色列表失败"))
-		}
-		defer rows.Close()
-
-		if rows.Next() {
-			err = rows.Scan(&total)
-		}
-		if err != nil {
-			r.logger.Error("统计角色数量失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色列表失败"))
-		}
-
-		if total == 0 {
-			return mo.Ok(QueryListRes{
-				List:  []RoleItem{},
-				Total: 0,
-			})
-		}
-
-		// 查询列表
-		var entities []RoleEntity
-		listQuery := `SELECT id, name, description, created_at, updated_at FROM iacc_role` + whereCondition + ` ORDER BY ` + req.OrderBy + ` ` + upperOrder + ` LIMIT :limit OFFSET :offset`
-		// 使用 NamedQuery 而不是 PrepareNamed
-		rows, err = r.db.NamedQueryContext(c.Request.Context(), listQuery, params)
-		if err != nil {
-			r.logger.Error("准备命名列表查询失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色列表失败"))
-		}
-		defer rows.Close()
-
-		for rows.Next() {
-			var entity RoleEntity
-			err = rows.StructScan(&entity)
+		if req.CursorPagination.Enabled() {
+			// 游标分页不统计总数
+			whereCondition, orderClause = req.CursorPagination.Keyset(whereCondition, upperOrder, params)
+		} else {
+			// 查询总数
+			var err error
+			total, err = r.count(c, r.conn(c), r.tables.Role, whereCondition, params)
 			if err != nil {
-				r.logger.Error("扫描行数据失败", zap.Error(err))
+				r.log(c).Error("统计角色数量失败", zap.Error(err))
 				return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色列表失败"))
 			}
-			entities = append(entities, entity)
+
+			if total == 0 {
+				return mo.Ok(QueryListRes{
+					List:  []RoleItem{},
+					Total: 0,
+				})
+			}
 		}
+
+		// 查询列表
+		listQuery := `SELECT id, name, description, critical, translations, attributes, parent_role_id, created_at, updated_at, seq FROM ` + r.tables.Role + whereCondition + orderClause
+		// 逐行扫描前检查请求是否已取消，并检查遍历是否中途失败
+		entities, err := pkgs.NamedQueryAll[roleListEntity](c.Request.Context(), r.conn(c), listQuery, params)
 		if err != nil {
-			r.logger.Error("查询角色列表失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色列表失败"))
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询角色列表失败"))
 		}
+		entities, nextCursor := pkgs.CursorPage(req.CursorPagination, entities, func(e roleListEntity) (time.Time, int64) { return e.CreatedAt, e.Seq })
 
 		// 转换并返回结果
 		var responseEntities []RoleItem
 		for _, entity := range entities {
+			name, description := entity.Translations.Localize(c, entity.Name, entity.Description)
 			responseEntities = append(responseEntities, RoleItem{
-				ID:          entity.ID,
-				Name:        entity.Name,
-				Description: entity.Description,
-				CreatedAt:   entity.CreatedAt.Format(time.RFC3339),
-				UpdatedAt:   entity.UpdatedAt.Format(time.RFC3339),
+				ID:           entity.ID,
+				Name:         name,
+				Description:  description,
+				Critical:     entity.Critical,
+				Attributes:   entity.Attributes,
+				ParentRoleID: entity.ParentRoleID,
+				CreatedAt:    pkgs.FormatTime(c, entity.CreatedAt),
+				UpdatedAt:    pkgs.FormatTime(c, entity.UpdatedAt),
 			})
 		}
 
 		return mo.Ok(QueryListRes{
-			List:  responseEntities,
-			Total: total,
+			List:       responseEntities,
+			Total:      total,
+			NextCursor: nextCursor,
 		})
 	}
 }
 
+// CheckModified 列表的条件请求：角色表自上次请求后没有变化时返回 304
+func (r *Repository) CheckModified(c *gin.Context) func(*QueryListReq) mo.Result[*QueryListReq] {
+	return pkgs.CheckModified[QueryListReq](c, r.conn(c), r.tables.Role)
+}
+
+// Count 统计满足筛选条件的角色数量，筛选条件与列表接口相同，在报表连接上查询
+func (r *Repository) Count(c *gin.Context) func(*CountReq) mo.Result[CountRes] {
+	return func(req *CountReq) mo.Result[CountRes] {
+		params := map[string]any{}
+		db, tables := r.reportConn(c)
+		total, err := r.count(c, db, tables.Role, r.listWhere(c, req, params), params)
+		if err != nil {
+			return mo.Err[CountRes](pkgs.DBError(r.log(c), err, "统计角色数量失败"))
+		}
+		return mo.Ok(total)
+	}
+}
+
+// Exists 检查是否存在指定名称的角色（精确匹配）
+func (r *Repository) Exists(c *gin.Context) func(*ExistsReq) mo.Result[ExistsRes] {
+	return func(req *ExistsReq) mo.Result[ExistsRes] {
+		var exists bool
+		query := `SELECT EXISTS (SELECT 1 FROM ` + r.tables.Role + ` WHERE name = $1)`
+		if err := r.conn(c).GetContext(c.Request.Context(), &exists, query, req.Name); err != nil {
+			return mo.Err[ExistsRes](pkgs.DBError(r.log(c), err, "检查角色是否存在失败"))
+		}
+		return mo.Ok(exists)
+	}
+}
+
+// Values 统计满足筛选条件的角色中指定字段的各取值及数量，在报表连接上查询
+func (r *Repository) Values(c *gin.Context) func(*ValuesReq) mo.Result[ValuesRes] {
+	return func(req *ValuesReq) mo.Result[ValuesRes] {
+		params := map[string]any{}
+		db, tables := r.reportConn(c)
+		query, args, err := db.BindNamed(valueFields.Query(req.Field, tables.Role, r.listWhere(c, &req.ListFilter, params)), params)
+		if err != nil {
+			r.log(c).Error("构建角色取值查询失败", zap.Error(err))
+			return mo.Err[ValuesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色字段取值失败"))
+		}
+		values := ValuesRes{}
+		if err := db.SelectContext(c.Request.Context(), &values, query, args...); err != nil {
+			return mo.Err[ValuesRes](pkgs.DBError(r.log(c), err, "查询角色字段取值失败"))
+		}
+		return mo.Ok(values)
+	}
+}
+
+// listWhere 构建列表、计数与取值接口共用的查询条件，返回 WHERE 子句，没有条件时为空
+func (r *Repository) listWhere(c *gin.Context, filter *ListFilter, params map[string]any) string {
+	var whereClauses []string
+	if filter.Name != "" {
+		whereClauses = append(whereClauses, "name ILIKE :name")
+		params["name"] = "%" + filter.Name + "%"
+	}
+	whereClauses = append(whereClauses, filter.Attr.Where("attributes", params)...)
+	whereClauses = append(whereClauses, filter.DateRange.Where(c, params)...)
+	whereClauses = append(whereClauses, filterColumns.Where(c, filter.Filter, params)...)
+
+	if len(whereClauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(whereClauses, " AND ")
+}
+
+// count 统计满足查询条件的角色数量
+func (r *Repository) count(c *gin.Context, db *sqlx.DB, table, whereCondition string, params map[string]any) (int64, error) {
+	query, args, err := db.BindNamed("SELECT count(*) FROM "+table+whereCondition, params)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	err = db.GetContext(c.Request.Context(), &total, query, args...)
+	return total, err
+}
+
 func (r *Repository) AssignPermissions(c *gin.Context) func(*AssignPermissionsByIDReq) mo.Result[AssignPermissionsRes] {
 	return func(req *AssignPermissionsByIDReq) mo.Result[AssignPermissionsRes] {
 		// 开启事务
-		tx, err := r.db.BeginTxx(c.Request.Context(), nil)
+		tx, err := r.conn(c).BeginTxx(c.Request.Context(), nil)
 		if err != nil {
-			r.logger.Error("为分配权限开启事务失败", zap.Error(err))
+			r.log(c).Error("为分配权限开启事务失败", zap.Error(err))
 			return mo.Err[AssignPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "分配权限失败"))
 		}
 		defer func() {
@@ -337,19 +663,19 @@ func (r *Repository) AssignPermissions(c *gin.Context) func(*AssignPermissionsBy
 			} else {
 				err = tx.Commit()
 				if err != nil {
-					r.logger.Error("提交分配权限事务失败", zap.Error(err))
+					r.log(c).Error("提交分配权限事务失败", zap.Error(err))
 				}
 			}
 		}()
 
 		// 删除旧的关联
-		deleteQuery := `DELETE FROM iacc_role_permission WHERE role_id = $1`
+		deleteQuery := `DELETE FROM ` + r.tables.RolePermission + ` WHERE role_id = $1`
 		if _, err = tx.ExecContext(c.Request.Context(), deleteQuery, req.ID); err != nil {
-			r.logger.Error("删除角色旧权限失败", zap.String("roleID", req.ID), zap.Error(err))
+			r.log(c).Error("删除角色旧权限失败", zap.String("roleID", req.ID), zap.Error(err))
 			return mo.Err[AssignPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "分配权限失败"))
 		}
 
-		if len(req.PermissionIDs) == 0 {
+		if len(req.PermissionIDs) == 0 && len(req.DenyPermissionIDs) == 0 {
 			return mo.Ok(AssignPermissionsRes(0))
 		}
 
@@ -359,27 +685,177 @@ func (r *Repository) AssignPermissions(c *gin.Context) func(*AssignPermissionsBy
 			entities = append(entities, map[string]any{
 				"role_id":       req.ID,
 				"permission_id": permID,
+				"effect":        pkgs.RolePermissionAllow,
+			})
+		}
+		for _, permID := range req.DenyPermissionIDs {
+			entities = append(entities, map[string]any{
+				"role_id":       req.ID,
+				"permission_id": permID,
+				"effect":        pkgs.RolePermissionDeny,
 			})
 		}
 
-		insertQuery := `INSERT INTO iacc_role_permission (role_id, permission_id) VALUES (:role_id, :permission_id)`
+		insertQuery := `INSERT INTO ` + r.tables.RolePermission + ` (role_id, permission_id, effect) VALUES (:role_id, :permission_id, :effect)`
 		if _, err = tx.NamedExecContext(c.Request.Context(), insertQuery, entities); err != nil {
-			r.logger.Error("为角色插入新权限失败", zap.String("roleID", req.ID), zap.Error(err))
+			r.log(c).Error("为角色插入新权限失败", zap.String("roleID", req.ID), zap.Error(err))
 			return mo.Err[AssignPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "分配权限失败"))
 		}
 
-		return mo.Ok(AssignPermissionsRes(len(req.PermissionIDs)))
+		return mo.Ok(AssignPermissionsRes(len(entities)))
 	}
 }
 
+// SyncPermissions 将角色权限同步为给定集合，只插入缺少的关联、删除多余的关联、更新效果（授予/拒绝）变化的关联
+// 未变化的关联保持不动（保留 created_at），避免整表删除重建带来的锁竞争
+func (r *Repository) SyncPermissions(c *gin.Context) func(*SyncPermissionsByIDReq) mo.Result[SyncPermissionsRes] {
+	return func(req *SyncPermissionsByIDReq) mo.Result[SyncPermissionsRes] {
+		// 开启事务
+		tx, err := r.conn(c).BeginTxx(c.Request.Context(), nil)
+		if err != nil {
+			r.log(c).Error("为同步权限开启事务失败", zap.Error(err))
+			return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "同步权限失败"))
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+			if err != nil {
+				tx.Rollback()
+			} else {
+				err = tx.Commit()
+				if err != nil {
+					r.log(c).Error("提交同步权限事务失败", zap.Error(err))
+				}
+			}
+		}()
+
+		// 锁定角色行，同一角色的并发同步串行执行
+		var roleID string
+		err = tx.GetContext(c.Request.Context(), &roleID, `SELECT id FROM `+r.tables.Role+` WHERE id = $1 FOR UPDATE`, req.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusNotFound, "角色不存在"))
+			}
+			r.log(c).Error("查询角色失败", zap.String("roleID", req.ID), zap.Error(err))
+			return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "同步权限失败"))
+		}
+
+		// 授予与拒绝的权限及各自的效果，按下标一一对应；集合为空时须传空数组而不是 NULL，否则 <> ALL 不会删除任何关联
+		permissionIDs := append(pq.StringArray{}, slices.Concat(req.PermissionIDs, req.DenyPermissionIDs)...)
+		effects := make(pq.StringArray, 0, len(permissionIDs))
+		for range req.PermissionIDs {
+			effects = append(effects, pkgs.RolePermissionAllow)
+		}
+		for range req.DenyPermissionIDs {
+			effects = append(effects, pkgs.RolePermissionDeny)
+		}
+
+		// 校验权限是否都存在
+		var existing []string
+		err = tx.SelectContext(c.Request.Context(), &existing, `SELECT id FROM `+r.tables.Permission+` WHERE id = ANY($1::uuid[])`, permissionIDs)
+		if err != nil {
+			r.log(c).Error("查询权限失败", zap.Error(err))
+			return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "同步权限失败"))
+		}
+		if len(existing) != len(permissionIDs) {
+			var missing []string
+			for _, id := range permissionIDs {
+				if !slices.Contains(existing, strings.ToLower(id)) {
+					missing = append(missing, id)
+				}
+			}
+			return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusBadRequest, "权限不存在: "+strings.Join(missing, ", ")))
+		}
+
+		// 删除多余的关联
+		deleteQuery := `DELETE FROM ` + r.tables.RolePermission + ` WHERE role_id = $1 AND permission_id <> ALL($2::uuid[])`
+		deleted, err := tx.ExecContext(c.Request.Context(), deleteQuery, req.ID, permissionIDs)
+		if err != nil {
+			r.log(c).Error("删除角色多余权限失败", zap.String("roleID", req.ID), zap.Error(err))
+			return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "同步权限失败"))
+		}
+		removed, err := deleted.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "同步权限失败"))
+		}
+
+		// 插入缺少的关联、更新效果变化的关联，未变化的关联保持不变
+		insertQuery := `INSERT INTO ` + r.tables.RolePermission + ` AS rp (role_id, permission_id, effect)
+			SELECT $1, u.permission_id, u.effect FROM unnest($2::uuid[], $3::text[]) AS u(permission_id, effect)
+			ON CONFLICT (role_id, permission_id) DO UPDATE SET effect = EXCLUDED.effect
+			WHERE rp.effect <> EXCLUDED.effect`
+		inserted, err := tx.ExecContext(c.Request.Context(), insertQuery, req.ID, permissionIDs, effects)
+		if err != nil {
+			r.log(c).Error("为角色插入缺少的权限失败", zap.String("roleID", req.ID), zap.Error(err))
+			return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "同步权限失败"))
+		}
+		added, err := inserted.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "同步权限失败"))
+		}
+
+		// 同步命名空间授予，未传入时保持不变
+		if req.SyncNamespaces() {
+			var nsAdded, nsRemoved int64
+			nsAdded, nsRemoved, err = r.syncNamespaces(c, tx, req)
+			if err != nil {
+				r.log(c).Error("同步角色命名空间授予失败", zap.String("roleID", req.ID), zap.Error(err))
+				return mo.Err[SyncPermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "同步权限失败"))
+			}
+			added, removed = added+nsAdded, removed+nsRemoved
+		}
+
+		return mo.Ok(SyncPermissionsRes{Added: added, Removed: removed})
+	}
+}
+
+// syncNamespaces 在同步权限的事务内将角色的命名空间授予同步为请求中的集合，返回新增（含变更效果）与移除的行数
+func (r *Repository) syncNamespaces(c *gin.Context, tx *sqlx.Tx, req *SyncPermissionsByIDReq) (int64, int64, error) {
+	ctx := c.Request.Context()
+	namespaces := append(pq.StringArray{}, slices.Concat(req.Namespaces, req.DenyNamespaces)...)
+	effects := make(pq.StringArray, 0, len(namespaces))
+	for range req.Namespaces {
+		effects = append(effects, pkgs.RolePermissionAllow)
+	}
+	for range req.DenyNamespaces {
+		effects = append(effects, pkgs.RolePermissionDeny)
+	}
+
+	deleted, err := tx.ExecContext(ctx, `DELETE FROM `+r.tables.RolePermissionNamespace+` WHERE role_id = $1 AND namespace <> ALL($2::text[])`, req.ID, namespaces)
+	if err != nil {
+		return 0, 0, err
+	}
+	removed, err := deleted.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	inserted, err := tx.ExecContext(ctx, `INSERT INTO `+r.tables.RolePermissionNamespace+` AS rn (role_id, namespace, effect)
+		SELECT $1, u.namespace, u.effect FROM unnest($2::text[], $3::text[]) AS u(namespace, effect)
+		ON CONFLICT (role_id, namespace) DO UPDATE SET effect = EXCLUDED.effect
+		WHERE rn.effect <> EXCLUDED.effect`, req.ID, namespaces, effects)
+	if err != nil {
+		return 0, 0, err
+	}
+	added, err := inserted.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	return added, removed, nil
+}
+
 func (r *Repository) GetPermissions(c *gin.Context) func(*GetRolePermissionsReq) mo.Result[GetRolePermissionsRes] {
 	return func(req *GetRolePermissionsReq) mo.Result[GetRolePermissionsRes] {
 		// 首先检查角色是否存在
 		var roleExists bool
-		checkRoleQuery := `SELECT EXISTS(SELECT 1 FROM iacc_role WHERE id = $1)`
-		err := r.db.GetContext(c.Request.Context(), &roleExists, checkRoleQuery, req.ID)
+		checkRoleQuery := `SELECT EXISTS(SELECT 1 FROM ` + r.tables.Role + ` WHERE id = $1)`
+		err := r.conn(c).GetContext(c.Request.Context(), &roleExists, checkRoleQuery, req.ID)
 		if err != nil {
-			r.logger.Error("检查角色存在性失败", zap.Error(err))
+			r.log(c).Error("检查角色存在性失败", zap.Error(err))
 			return mo.Err[GetRolePermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色权限失败"))
 		}
 		if !roleExists {
@@ -388,41 +864,33 @@ func (r *Repository) GetPermissions(c *gin.Context) func(*GetRolePermissionsReq)
 
 		// 查询角色关联的权限
 		query := `
-			SELECT p.id, p.name, p.type, p.metadata, p.created_at, p.updated_at
-			FROM iacc_permission p
-			INNER JOIN iacc_role_permission rp ON p.id = rp.permission_id
+			SELECT p.id, p.name, p.type, p.metadata, rp.effect, p.created_at, p.updated_at
+			FROM ` + r.tables.Permission + ` p
+			INNER JOIN ` + r.tables.RolePermission + ` rp ON p.id = rp.permission_id
 			WHERE rp.role_id = $1
-			ORDER BY p.created_at DESC
+			ORDER BY p.created_at DESC, p.seq DESC
 		`
 
-		rows, err := r.db.QueryxContext(c.Request.Context(), query, req.ID)
+		rows, err := pkgs.QueryAll[struct {
+			ID        string    `db:"id"`
+			Name      string    `db:"name"`
+			Type      string    `db:"type"`
+			Metadata  []byte    `db:"metadata"`
+			Effect    string    `db:"effect"`
+			CreatedAt time.Time `db:"created_at"`
+			UpdatedAt time.Time `db:"updated_at"`
+		}](c.Request.Context(), r.conn(c), query, req.ID)
 		if err != nil {
-			r.logger.Error("查询角色权限失败", zap.Error(err))
-			return mo.Err[GetRolePermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色权限失败"))
+			return mo.Err[GetRolePermissionsRes](pkgs.DBError(r.log(c), err, "查询角色权限失败"))
 		}
-		defer rows.Close()
 
 		var permissions []PermissionItem
-		for rows.Next() {
-			var permission struct {
-				ID        string    `db:"id"`
-				Name      string    `db:"name"`
-				Type      string    `db:"type"`
-				Metadata  []byte    `db:"metadata"`
-				CreatedAt time.Time `db:"created_at"`
-				UpdatedAt time.Time `db:"updated_at"`
-			}
-
-			if err := rows.StructScan(&permission); err != nil {
-				r.logger.Error("扫描权限数据失败", zap.Error(err))
-				return mo.Err[GetRolePermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色权限失败"))
-			}
-
+		for _, permission := range rows {
 			// 解析 JSONB 元数据
 			var metadata map[string]interface{}
 			if len(permission.Metadata) > 0 {
 				if err := json.Unmarshal(permission.Metadata, &metadata); err != nil {
-					r.logger.Error("解析权限元数据失败", zap.Error(err))
+					r.log(c).Error("解析权限元数据失败", zap.Error(err))
 					return mo.Err[GetRolePermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色权限失败"))
 				}
 			}
@@ -432,25 +900,393 @@ func (r *Repository) GetPermissions(c *gin.Context) func(*GetRolePermissionsReq)
 				Name:      permission.Name,
 				Type:      permission.Type,
 				Metadata:  metadata,
-				CreatedAt: permission.CreatedAt.Format(time.RFC3339),
-				UpdatedAt: permission.UpdatedAt.Format(time.RFC3339),
+				Effect:    permission.Effect,
+				CreatedAt: pkgs.FormatTime(c, permission.CreatedAt),
+				UpdatedAt: pkgs.FormatTime(c, permission.UpdatedAt),
 			})
 		}
 
-		// 检查是否有错误
-		if err = rows.Err(); err != nil {
-			r.logger.Error("遍历权限结果失败", zap.Error(err))
-			return mo.Err[GetRolePermissionsRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色权限失败"))
-		}
-
 		// 确保 permissions 不为 nil，如果是 nil 则返回空数组
 		if permissions == nil {
 			permissions = []PermissionItem{}
 		}
 
+		// 查询角色的命名空间授予及各命名空间下当前的权限数
+		namespaces := []NamespaceGrantItem{}
+		namespaceQuery := `
+			SELECT rn.namespace, rn.effect, rn.created_at,
+				(SELECT COUNT(*) FROM ` + r.tables.Permission + ` p WHERE ` + pkgs.NamespaceMatchSQL("rn.namespace", "p.namespace") + `) AS permission_count
+			FROM ` + r.tables.RolePermissionNamespace + ` rn
+			WHERE rn.role_id = $1
+			ORDER BY rn.namespace
+		`
+		grants, err := pkgs.QueryAll[struct {
+			Namespace       string    `db:"namespace"`
+			Effect          string    `db:"effect"`
+			CreatedAt       time.Time `db:"created_at"`
+			PermissionCount int64     `db:"permission_count"`
+		}](c.Request.Context(), r.conn(c), namespaceQuery, req.ID)
+		if err != nil {
+			return mo.Err[GetRolePermissionsRes](pkgs.DBError(r.log(c), err, "查询角色权限失败"))
+		}
+		for _, grant := range grants {
+			namespaces = append(namespaces, NamespaceGrantItem{
+				Namespace:       grant.Namespace,
+				Effect:          grant.Effect,
+				PermissionCount: grant.PermissionCount,
+				CreatedAt:       pkgs.FormatTime(c, grant.CreatedAt),
+			})
+		}
+
 		return mo.Ok(GetRolePermissionsRes{
-			List:  permissions,
-			Total: int64(len(permissions)),
+			List:       permissions,
+			Total:      int64(len(permissions)),
+			Namespaces: namespaces,
 		})
 	}
 }
+
+// Snapshot 读取角色的审计快照，permissions 为分配的权限，键为权限ID，值为授权效果；namespaces 为命名空间授予，键为命名空间
+func (r *Repository) Snapshot(c *gin.Context, ids []string) (map[string]map[string]any, error) {
+	query := `
+		SELECT r.id, (to_jsonb(r) - 'updated_at' - 'seq')
+			|| jsonb_build_object('permissions', COALESCE((
+				SELECT jsonb_object_agg(rp.permission_id, rp.effect) FROM ` + r.tables.RolePermission + ` rp WHERE rp.role_id = r.id
+			), '{}'::jsonb), 'namespaces', COALESCE((
+				SELECT jsonb_object_agg(rn.namespace, rn.effect) FROM ` + r.tables.RolePermissionNamespace + ` rn WHERE rn.role_id = r.id
+			), '{}'::jsonb)) AS state
+		FROM ` + r.tables.Role + ` r
+		WHERE r.id = ANY($1)
+	`
+	return pkgs.QueryAuditStates(c.Request.Context(), r.conn(c), query, pkgs.PGArray(ids))
+}
+
+// toGetByIDRes 将数据库实体转换为角色详情
+// stageCritical 返回暂存关键角色变更的管道步骤，放在校验之后、执行变更之前
+// 角色为关键角色时把请求写入 iacc_role_change 并通知审批人，以 202 业务码结束请求，不执行变更；
+// 非关键角色原样放行，角色不存在时交给后续步骤处理
+func stageCritical[T any](r *Repository, c *gin.Context, action string, roleID func(*T) string) func(*T) mo.Result[*T] {
+	return func(req *T) mo.Result[*T] {
+		ctx := c.Request.Context()
+		var role RoleEntity
+		err := r.conn(c).GetContext(ctx, &role, `SELECT id, name, critical FROM `+r.tables.Role+` WHERE id = $1`, roleID(req))
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && !role.Critical) {
+			return mo.Ok(req)
+		}
+		if err != nil {
+			return mo.Err[*T](pkgs.DBError(r.log(c), err, "查询角色失败"))
+		}
+
+		requester := pkgs.CurrentUserID(c)
+		if requester == "" {
+			return mo.Err[*T](pkgs.NewApiError(http.StatusUnauthorized, "未授权"))
+		}
+		payload, err := changePayload(req)
+		if err != nil {
+			r.log(c).Error("序列化角色变更失败", zap.Error(err))
+			return mo.Err[*T](pkgs.NewApiError(http.StatusInternalServerError, "提交角色变更失败"))
+		}
+		change := &RoleChangeEntity{RoleID: role.ID, Action: action, Payload: payload, RequestedBy: requester}
+		if err := r.ids.Assign(&change.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[*T](pkgs.NewApiError(http.StatusInternalServerError, "提交角色变更失败"))
+		}
+
+		// 每个角色同时只能有一个待审批的变更
+		columns, values := r.ids.Insert("role_id", "action", "payload", "requested_by")
+		query := `INSERT INTO ` + r.tables.RoleChange + ` (` + columns + `) VALUES (` + values + `)
+			ON CONFLICT (role_id) WHERE status = '` + ChangePending + `' DO NOTHING RETURNING id`
+		stmt, err := r.conn(c).PrepareNamedContext(ctx, query)
+		if err != nil {
+			r.log(c).Error("提交角色变更语句准备失败", zap.Error(err))
+			return mo.Err[*T](pkgs.NewApiError(http.StatusInternalServerError, "提交角色变更失败"))
+		}
+		defer stmt.Close()
+		if err := stmt.GetContext(ctx, &change.ID, change); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[*T](pkgs.NewApiError(http.StatusConflict, "该角色已有待审批的变更"))
+			}
+			return mo.Err[*T](pkgs.DBError(r.log(c), err, "提交角色变更失败"))
+		}
+
+		r.notifyApprovers(c, &role, change)
+		return mo.Err[*T](&pkgs.ApiError{
+			Code:    http.StatusAccepted,
+			Message: "关键角色的变更已提交，等待其他管理员审批",
+			Data:    StagedChangeRes{ChangeID: change.ID},
+		})
+	}
+}
+
+// changePayload 返回暂存的变更内容，Merge Patch 请求保存补丁文档以保留显式 null
+func changePayload(req any) (json.RawMessage, error) {
+	if patch, ok := req.(interface {
+		Document() (json.RawMessage, error)
+	}); ok {
+		return patch.Document()
+	}
+	return json.Marshal(req)
+}
+
+// notifyApprovers 通知拥有审批权限的其他用户，通知失败只记录日志，不影响变更的提交
+func (r *Repository) notifyApprovers(c *gin.Context, role *RoleEntity, change *RoleChangeEntity) {
+	var approvers []string
+	query := `WITH RECURSIVE ` + pkgs.UserRolesCTE(r.tables, "ur.user_id <> $1") + `
+		SELECT DISTINCT ur.user_id FROM user_roles ur
+		JOIN (` + pkgs.RolePermissionsSQL(r.tables) + `) rp ON rp.role_id = ur.role_id AND rp.effect = '` + pkgs.RolePermissionAllow + `'
+		JOIN ` + r.tables.Permission + ` p ON p.id = rp.permission_id
+		WHERE p.metadata->>'method' = 'POST' AND p.metadata->>'path' = '/v1/role/change/:id/approve'`
+	if err := r.conn(c).SelectContext(c.Request.Context(), &approvers, query, change.RequestedBy); err != nil {
+		r.log(c).Warn("查询审批人失败", zap.Error(err))
+	}
+	err := r.notifier.Notify(c, pkgs.Notification{
+		Type:       "iacc.role.change.pending",
+		Recipients: approvers,
+		Title:      "关键角色变更待审批",
+		Body:       fmt.Sprintf("关键角色「%s」有一项待审批的变更（%s），需要由提交人以外的管理员审批", role.Name, change.Action),
+		Data:       map[string]any{"change_id": change.ID, "role_id": role.ID, "action": change.Action, "requested_by": change.RequestedBy},
+	})
+	if err != nil {
+		r.log(c).Warn("发送角色变更审批通知失败", zap.String("change_id", change.ID), zap.Error(err))
+	}
+}
+
+// ApproveChange 审批通过关键角色的变更并执行，提交人不能审批自己的变更
+// 执行失败时变更恢复为待审批状态
+func (r *Repository) ApproveChange(c *gin.Context) func(*ReviewChangeReq) mo.Result[ApproveChangeRes] {
+	return func(req *ReviewChangeReq) mo.Result[ApproveChangeRes] {
+		change, apiErr := r.review(c, req.ID, ChangeApproved)
+		if apiErr != nil {
+			return mo.Err[ApproveChangeRes](apiErr)
+		}
+
+		res := r.applyChange(c, change)
+		if res.IsError() {
+			query := `UPDATE ` + r.tables.RoleChange + ` SET status = $1, reviewed_by = NULL, reviewed_at = NULL WHERE id = $2`
+			if _, err := r.conn(c).ExecContext(c.Request.Context(), query, ChangePending, change.ID); err != nil {
+				r.log(c).Error("恢复角色变更状态失败", zap.String("change_id", change.ID), zap.Error(err))
+			}
+		}
+		return res
+	}
+}
+
+// RejectChange 驳回关键角色的变更，提交人可以撤回自己的变更
+func (r *Repository) RejectChange(c *gin.Context) func(*ReviewChangeReq) mo.Result[RejectChangeRes] {
+	return func(req *ReviewChangeReq) mo.Result[RejectChangeRes] {
+		if _, apiErr := r.review(c, req.ID, ChangeRejected); apiErr != nil {
+			return mo.Err[RejectChangeRes](apiErr)
+		}
+		return mo.Ok(RejectChangeRes(1))
+	}
+}
+
+// review 将待审批的变更标记为审批通过或驳回，条件更新保证同一变更只会被审批一次
+func (r *Repository) review(c *gin.Context, id string, status string) (*RoleChangeEntity, *pkgs.ApiError) {
+	ctx := c.Request.Context()
+	reviewer := pkgs.CurrentUserID(c)
+	if reviewer == "" {
+		return nil, pkgs.NewApiError(http.StatusUnauthorized, "未授权")
+	}
+
+	var change RoleChangeEntity
+	query := `SELECT id, created_at, updated_at, role_id, action, payload, status, requested_by, reviewed_by, reviewed_at FROM ` + r.tables.RoleChange + ` WHERE id = $1`
+	if err := r.conn(c).GetContext(ctx, &change, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkgs.NewApiError(http.StatusNotFound, "角色变更不存在")
+		}
+		r.log(c).Error("查询角色变更失败", zap.Error(err))
+		return nil, pkgs.NewApiError(http.StatusInternalServerError, "审批角色变更失败")
+	}
+	if change.Status != ChangePending {
+		return nil, pkgs.NewApiError(http.StatusConflict, "该变更已审批")
+	}
+	if status == ChangeApproved && change.RequestedBy == reviewer {
+		return nil, pkgs.NewApiError(http.StatusForbidden, "不能审批自己提交的变更")
+	}
+
+	update := `UPDATE ` + r.tables.RoleChange + ` SET status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = $4 RETURNING status, reviewed_by, reviewed_at`
+	if err := r.conn(c).GetContext(ctx, &change, update, status, reviewer, id, ChangePending); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pkgs.NewApiError(http.StatusConflict, "该变更已审批")
+		}
+		r.log(c).Error("更新角色变更状态失败", zap.Error(err))
+		return nil, pkgs.NewApiError(http.StatusInternalServerError, "审批角色变更失败")
+	}
+	return &change, nil
+}
+
+// applyChange 按操作类型还原请求并执行变更
+func (r *Repository) applyChange(c *gin.Context, change *RoleChangeEntity) mo.Result[ApproveChangeRes] {
+	invalid := func(err error) mo.Result[ApproveChangeRes] {
+		r.log(c).Error("解析角色变更失败", zap.String("change_id", change.ID), zap.Error(err))
+		return mo.Err[ApproveChangeRes](pkgs.NewApiError(http.StatusInternalServerError, "角色变更内容无效"))
+	}
+	switch change.Action {
+	case ChangeUpdate:
+		var req UpdateByIDReq
+		if err := json.Unmarshal(change.Payload, &req); err != nil {
+			return invalid(err)
+		}
+		req.ID = change.RoleID
+		return approvedResult(change.Action, r.UpdateByID(c)(&req))
+	case ChangePatch:
+		req, err := pkgs.DecodeMergePatch[PatchByIDReq](change.Payload)
+		if err != nil {
+			return invalid(err)
+		}
+		req.ID = change.RoleID
+		return approvedResult(change.Action, r.PatchByID(c)(req))
+	case ChangeDelete:
+		return approvedResult(change.Action, r.DeleteByID(c)(&DeleteByIDReq{ID: change.RoleID}))
+	case ChangeAssignPermissions:
+		req := AssignPermissionsByIDReq{ID: change.RoleID}
+		if err := json.Unmarshal(change.Payload, &req.AssignPermissionsReq); err != nil {
+			return invalid(err)
+		}
+		return approvedResult(change.Action, r.AssignPermissions(c)(&req))
+	case ChangeSyncPermissions:
+		req := SyncPermissionsByIDReq{ID: change.RoleID}
+		if err := json.Unmarshal(change.Payload, &req.SyncPermissionsReq); err != nil {
+			return invalid(err)
+		}
+		return approvedResult(change.Action, r.SyncPermissions(c)(&req))
+	default:
+		return invalid(fmt.Errorf("未知的操作类型: %s", change.Action))
+	}
+}
+
+func approvedResult[T any](action string, res mo.Result[T]) mo.Result[ApproveChangeRes] {
+	return result.Pipe1(res, result.Map(func(v T) ApproveChangeRes {
+		return ApproveChangeRes{Action: action, Result: v}
+	}))
+}
+
+// QueryChanges 分页查询关键角色的变更
+func (r *Repository) QueryChanges(c *gin.Context) func(*QueryChangesReq) mo.Result[QueryChangesRes] {
+	return func(req *QueryChangesReq) mo.Result[QueryChangesRes] {
+		ctx := c.Request.Context()
+		var conditions []string
+		var args []any
+		if req.Status != "" {
+			args = append(args, req.Status)
+			conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+		}
+		if req.RoleID != "" {
+			args = append(args, req.RoleID)
+			conditions = append(conditions, fmt.Sprintf("role_id = $%d", len(args)))
+		}
+		where := ""
+		if len(conditions) > 0 {
+			where = " WHERE " + strings.Join(conditions, " AND ")
+		}
+
+		var total int64
+		if err := r.conn(c).GetContext(ctx, &total, `SELECT COUNT(*) FROM `+r.tables.RoleChange+where, args...); err != nil {
+			r.log(c).Error("查询角色变更总数失败", zap.Error(err))
+			return mo.Err[QueryChangesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询角色变更失败"))
+		}
+		if total == 0 {
+			return mo.Ok(QueryChangesRes{List: []RoleChangeItem{}, Total: 0})
+		}
+
+		var entities []RoleChangeEntity
+		args = append(args, req.PageSize, req.Offset())
+		query := `SELECT id, created_at, updated_at, role_id, action, payload, status, requested_by, reviewed_by, reviewed_at FROM ` + r.tables.RoleChange + where +
+			fmt.Sprintf(` ORDER BY created_at DESC, seq DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+		if err := r.conn(c).SelectContext(ctx, &entities, query, args...); err != nil {
+			return mo.Err[QueryChangesRes](pkgs.DBError(r.log(c), err, "查询角色变更失败"))
+		}
+
+		list := make([]RoleChangeItem, 0, len(entities))
+		for _, entity := range entities {
+			item := RoleChangeItem{
+				ID:          entity.ID,
+				RoleID:      entity.RoleID,
+				Action:      entity.Action,
+				Payload:     entity.Payload,
+				Status:      entity.Status,
+				RequestedBy: entity.RequestedBy,
+				ReviewedBy:  entity.ReviewedBy,
+				CreatedAt:   pkgs.FormatTime(c, entity.CreatedAt),
+			}
+			if entity.ReviewedAt != nil {
+				reviewedAt := pkgs.FormatTime(c, *entity.ReviewedAt)
+				item.ReviewedAt = &reviewedAt
+			}
+			list = append(list, item)
+		}
+		return mo.Ok(QueryChangesRes{List: list, Total: total})
+	}
+}
+
+// GetTranslations 查询角色名称与描述的全部翻译
+func (r *Repository) GetTranslations(c *gin.Context) func(*GetTranslationsReq) mo.Result[GetTranslationsRes] {
+	return func(req *GetTranslationsReq) mo.Result[GetTranslationsRes] {
+		var translations pkgs.Translations
+		query := `SELECT translations FROM ` + r.tables.Role + ` WHERE id = $1`
+		err := r.conn(c).GetContext(c.Request.Context(), &translations, query, req.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return mo.Err[GetTranslationsRes](pkgs.NewApiError(http.StatusNotFound, "角色不存在"))
+			}
+			return mo.Err[GetTranslationsRes](pkgs.DBError(r.log(c), err, "查询角色翻译失败"))
+		}
+		if translations == nil {
+			translations = pkgs.Translations{}
+		}
+		return mo.Ok(translations)
+	}
+}
+
+// PutTranslation 设置角色某一语言的翻译，语言标签规范化后作为键
+func (r *Repository) PutTranslation(c *gin.Context) func(*PutTranslationReq) mo.Result[PutTranslationRes] {
+	return func(req *PutTranslationReq) mo.Result[PutTranslationRes] {
+		locale, _ := pkgs.CanonicalLocale(req.Locale)
+		translation, err := json.Marshal(pkgs.Translation{Name: req.Name, Description: req.Description})
+		if err != nil {
+			r.log(c).Error("序列化角色翻译失败", zap.Error(err))
+			return mo.Err[PutTranslationRes](pkgs.NewApiError(http.StatusInternalServerError, "设置角色翻译失败"))
+		}
+		query := `UPDATE ` + r.tables.Role + ` SET translations = translations || jsonb_build_object($2::text, $3::jsonb) WHERE id = $1`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID, locale, string(translation))
+		if err != nil {
+			return mo.Err[PutTranslationRes](pkgs.DBError(r.log(c), err, "设置角色翻译失败"))
+		}
+		rowsAffected, _ := res.RowsAffected()
+		if rowsAffected == 0 {
+			return mo.Err[PutTranslationRes](pkgs.NewApiError(http.StatusNotFound, "角色不存在"))
+		}
+		return mo.Ok(rowsAffected)
+	}
+}
+
+// DeleteTranslation 删除角色某一语言的翻译，翻译不存在时返回 0
+func (r *Repository) DeleteTranslation(c *gin.Context) func(*DeleteTranslationReq) mo.Result[DeleteTranslationRes] {
+	return func(req *DeleteTranslationReq) mo.Result[DeleteTranslationRes] {
+		locale, _ := pkgs.CanonicalLocale(req.Locale)
+		query := `UPDATE ` + r.tables.Role + ` SET translations = translations - $2::text WHERE id = $1 AND jsonb_exists(translations, $2)`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID, locale)
+		if err != nil {
+			return mo.Err[DeleteTranslationRes](pkgs.DBError(r.log(c), err, "删除角色翻译失败"))
+		}
+		rowsAffected, _ := res.RowsAffected()
+		return mo.Ok(rowsAffected)
+	}
+}
+
+func toGetByIDRes(c *gin.Context, entity *RoleEntity) GetByIDRes {
+	name, description := entity.Translations.Localize(c, entity.Name, entity.Description)
+	return GetByIDRes{
+		ID:               entity.ID,
+		Name:             name,
+		Description:      description,
+		AccessConditions: entity.AccessConditions,
+		Critical:         entity.Critical,
+		Attributes:       entity.Attributes,
+		ParentRoleID:     entity.ParentRoleID,
+		CreatedAt:        pkgs.FormatTime(c, entity.CreatedAt),
+		UpdatedAt:        pkgs.FormatTime(c, entity.UpdatedAt),
+	}
+}
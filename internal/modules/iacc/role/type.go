@@ -1,6 +1,13 @@
 package role
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"go-pg-demo/pkgs"
+	"slices"
+	"strings"
+	"time"
+)
 
 // 数据库表 iacc_role 的表结构
 type RoleEntity struct {
@@ -9,12 +16,36 @@ type RoleEntity struct {
 	UpdatedAt   time.Time `db:"updated_at" label:"更新时间"`
 	Name        string    `db:"name" label:"角色名称"`
 	Description *string   `db:"description" label:"角色描述"`
+	// 访问条件（时间段、IP 段），为空表示不限制
+	AccessConditions *pkgs.AccessConditions `db:"access_conditions" label:"访问条件"`
+	// 关键角色的变更需要另一位管理员审批
+	Critical bool `db:"critical" label:"是否关键角色"`
+	// 名称与描述的多语言翻译，列表与详情接口按 Accept-Language 返回
+	Translations pkgs.Translations `db:"translations" label:"翻译"`
+	// 自定义属性，键与值类型由属性定义约束
+	Attributes pkgs.Attributes `db:"attributes" label:"自定义属性"`
+	// 父角色，角色继承父角色及其祖先角色的全部权限
+	ParentRoleID *string `db:"parent_role_id" label:"父角色ID"`
+}
+
+// roleListEntity 列表查询的一行，seq 用于游标分页
+type roleListEntity struct {
+	RoleEntity
+	Seq int64 `db:"seq"`
 }
 
 // 创建角色的请求 DTO
 type CreateReq struct {
 	Name        string  `json:"name" validate:"required" label:"角色名称"`
 	Description *string `json:"description" label:"角色描述"`
+	// 访问条件，请求不满足时该角色的权限不生效
+	AccessConditions *pkgs.AccessConditions `json:"access_conditions,omitempty" validate:"omitempty" label:"访问条件"`
+	// 关键角色的修改、删除与权限变更需要另一位管理员审批
+	Critical bool `json:"critical" label:"是否关键角色"`
+	// 自定义属性，只能使用已定义的属性，必须包含全部必填属性
+	Attributes pkgs.Attributes `json:"attributes,omitempty" label:"自定义属性"`
+	// 父角色，继承父角色及其祖先角色的全部权限
+	ParentRoleID *string `json:"parent_role_id,omitempty" validate:"omitempty,uuid" label:"父角色ID"`
 }
 
 // 创建角色的响应 DTO
@@ -25,12 +56,45 @@ type BatchCreateReq struct {
 	Roles []CreateReq `json:"roles" validate:"required,min=1,dive" label:"角色列表"`
 }
 
+// 批量创建时同一批次内角色名称不能重复
+func batchCreateRule(req *BatchCreateReq) []pkgs.Violation {
+	names := make([]string, len(req.Roles))
+	for i, r := range req.Roles {
+		names[i] = r.Name
+	}
+	return pkgs.DuplicateViolations("roles.name", "角色名称", names)
+}
+
 // 批量创建角色的响应体
 type BatchCreateRes []string
 
+// 批量创建角色的响应体（return=entity 时返回完整实体）
+type BatchCreateEntityRes []GetByIDRes
+
+// ids 返回创建的角色ID，用于写入审计日志
+func (r BatchCreateEntityRes) ids() []string {
+	ids := make([]string, len(r))
+	for i, role := range r {
+		ids[i] = role.ID
+	}
+	return ids
+}
+
 // 根据ID获取角色的参数
 type GetByIDReq struct {
 	ID string `uri:"id" validate:"required,uuid" label:"角色ID"`
+	// 扩展内容：permissions 返回权限列表（含继承自祖先角色的权限），userCount 返回拥有该角色的用户数
+	Include pkgs.Includes `form:"include" label:"扩展内容"`
+}
+
+// 角色详情的扩展内容
+const (
+	IncludePermissions = "permissions"
+	IncludeUserCount   = "userCount"
+)
+
+func getByIDRule(req *GetByIDReq) []pkgs.Violation {
+	return req.Include.Violations(IncludePermissions, IncludeUserCount)
 }
 
 // 根据ID获取角色的响应体
@@ -38,23 +102,65 @@ type GetByIDRes struct {
 	ID          string  `json:"id" label:"角色ID"`
 	Name        string  `json:"name" label:"角色名称"`
 	Description *string `json:"description,omitempty" label:"角色描述"`
-	CreatedAt   string  `json:"created_at" label:"创建时间"`
-	UpdatedAt   string  `json:"updated_at" label:"更新时间"`
+	// 访问条件，为空表示不限制
+	AccessConditions *pkgs.AccessConditions `json:"access_conditions,omitempty" label:"访问条件"`
+	Critical         bool                   `json:"critical" label:"是否关键角色"`
+	Attributes       pkgs.Attributes        `json:"attributes" label:"自定义属性"`
+	ParentRoleID     *string                `json:"parent_role_id,omitempty" label:"父角色ID"`
+	CreatedAt        string                 `json:"created_at" label:"创建时间"`
+	UpdatedAt        string                 `json:"updated_at" label:"更新时间"`
+	// 展开后的权限列表，include=permissions 时返回：角色自身与全部祖先角色的权限，
+	// 同一权限只返回一次，任一角色拒绝即为拒绝，继承来的权限带上来源角色
+	Permissions []PermissionItem `json:"permissions,omitzero" label:"权限列表"`
+	// 拥有该角色的用户数，include=userCount 时返回
+	UserCount *int64 `json:"user_count,omitempty" label:"用户数"`
 }
 
 // 更新角色的请求体
 type UpdateByIDReq struct {
-	ID          string  `uri:"id" validate:"required,uuid" label:"角色ID"`
-	Name        *string `json:"name,omitempty" validate:"omitempty" label:"角色名称"`
-	Description *string `json:"description,omitempty" validate:"omitempty" label:"角色描述"`
+	ID   string  `uri:"id" validate:"required,uuid" label:"角色ID"`
+	Name *string `json:"name,omitempty" validate:"omitempty" label:"角色名称"`
+	// 角色描述，传 null 时清空
+	Description pkgs.Optional[string] `json:"description,omitzero" validate:"omitempty" label:"角色描述" swaggertype:"string"`
+	// 访问条件，传入时整体替换，传 null 时清空
+	AccessConditions pkgs.Optional[pkgs.AccessConditions] `json:"access_conditions,omitzero" validate:"omitempty" label:"访问条件" swaggertype:"object"`
+	Critical         *bool                                `json:"critical,omitempty" label:"是否关键角色"`
+	// 自定义属性，传入时整体替换，必须包含全部必填属性
+	Attributes pkgs.Attributes `json:"attributes,omitempty" label:"自定义属性"`
+	// 父角色，传 null 时取消继承；不能是角色自身或其子孙角色
+	ParentRoleID pkgs.Optional[string] `json:"parent_role_id,omitzero" validate:"omitempty,uuid" label:"父角色ID" swaggertype:"string"`
 }
 
 // 更新角色的响应体
 type UpdateByIDRes = int64
 
+// 部分更新角色的请求体（JSON Merge Patch，显式 null 表示清空）
+type PatchByIDReq struct {
+	pkgs.MergePatch `json:"-" swaggerignore:"true"`
+	ID              string  `uri:"id" json:"-" validate:"required,uuid" label:"角色ID"`
+	Name            *string `json:"name,omitempty" label:"角色名称"`
+	Description     *string `json:"description,omitempty" label:"角色描述"`
+	// 访问条件，显式 null 表示取消限制
+	AccessConditions *pkgs.AccessConditions `json:"access_conditions,omitempty" validate:"omitempty" label:"访问条件"`
+	Critical         *bool                  `json:"critical,omitempty" label:"是否关键角色"`
+	// 自定义属性，与已有属性合并，属性值为 null 表示删除该属性
+	Attributes pkgs.Attributes `json:"attributes,omitempty" label:"自定义属性"`
+	// 父角色，显式 null 表示取消继承；不能是角色自身或其子孙角色
+	ParentRoleID *string `json:"parent_role_id,omitempty" validate:"omitempty,uuid" label:"父角色ID"`
+}
+
+// 角色名称、是否关键角色与自定义属性不可为空，不允许通过 null 清空
+func patchRule(req *PatchByIDReq) []pkgs.Violation {
+	violations := append(req.NotNull("name", "角色名称"), req.NotNull("critical", "是否关键角色")...)
+	return append(violations, req.NotNull("attributes", "自定义属性")...)
+}
+
+// 部分更新角色的响应体
+type PatchByIDRes = int64
+
 // 根据ID删除角色的请求参数
 type DeleteByIDReq struct {
-	ID string `uri:"id" binding:"required,uuid" label:"角色ID"`
+	ID string `uri:"id" validate:"required,uuid" label:"角色ID"`
 }
 
 // 根据ID删除角色的响应
@@ -65,36 +171,145 @@ type DeleteRolesReq struct {
 	IDs []string `json:"ids" validate:"required,min=1,dive,uuid" label:"角色ID列表"`
 }
 
+// 批量删除的角色ID不能重复
+func batchDeleteRule(req *DeleteRolesReq) []pkgs.Violation {
+	return pkgs.DuplicateViolations("ids", "角色ID", req.IDs)
+}
+
 // 批量删除角色响应
 type BatchDeleteRes = int64
 
 // 查询角色的请求体
 type QueryListReq struct {
-	Page     int    `form:"page,default=1" validate:"min=1" label:"页码"`
-	PageSize int    `form:"pageSize,default=10" validate:"min=1,max=100" label:"每页大小"`
-	Name     string `form:"name,omitempty" validate:"omitempty" label:"角色名称"`
-	OrderBy  string `form:"orderBy,default=id" validate:"omitempty" label:"排序字段"`
-	Order    string `form:"order,default=desc" validate:"omitempty" label:"排序顺序"`
+	pkgs.Pagination
+	pkgs.CursorPagination
+	ListFilter
+	OrderBy string `form:"orderBy,default=created_at" validate:"oneof=id name description created_at updated_at" label:"排序字段"`
+	Order   string `form:"order,default=desc" validate:"sort_order" label:"排序顺序"`
+}
+
+func queryListRule(req *QueryListReq) []pkgs.Violation {
+	return append(listFilterRule(&req.ListFilter), req.CursorPagination.Violations(req.OrderBy)...)
+}
+
+// 角色列表的筛选条件，列表与计数接口共用
+type ListFilter struct {
+	Name string `form:"name,omitempty" validate:"omitempty" label:"角色名称"`
+	// 按自定义属性筛选，格式为 键:值，可重复传入，需同时满足
+	Attr pkgs.AttributeFilters `form:"attr" label:"自定义属性"`
+	// 通用过滤表达式，如 name:like:foo,created_at:gte:2024-01-01，可筛选的字段见 filterColumns
+	Filter string `form:"filter" validate:"omitempty,max=2048" label:"过滤表达式"`
+	pkgs.DateRange
+}
+
+// 过滤表达式可以筛选的字段
+var filterColumns = pkgs.FilterColumns{
+	"id":             {Expr: "id", Type: pkgs.FilterUUID},
+	"name":           {Expr: "name", Type: pkgs.FilterText},
+	"description":    {Expr: "description", Type: pkgs.FilterText},
+	"critical":       {Expr: "critical", Type: pkgs.FilterBool},
+	"parent_role_id": {Expr: "parent_role_id", Type: pkgs.FilterUUID},
+	"created_at":     {Expr: "created_at", Type: pkgs.FilterTime},
+	"updated_at":     {Expr: "updated_at", Type: pkgs.FilterTime},
+}
+
+func listFilterRule(req *ListFilter) []pkgs.Violation {
+	violations := append(req.DateRange.Violations(), req.Attr.Violations("attr")...)
+	return append(violations, filterColumns.Violations("filter", req.Filter)...)
+}
+
+// 统计角色数量的请求参数
+type CountReq = ListFilter
+
+// 统计角色数量的响应
+type CountRes = int64
+
+// 检查角色名称是否已存在的请求参数
+type ExistsReq struct {
+	Name string `form:"name" validate:"required" label:"角色名称"`
+}
+
+// 检查角色名称是否已存在的响应
+type ExistsRes = bool
+
+// 查询角色字段取值的请求参数，筛选条件与列表接口相同
+type ValuesReq struct {
+	Field string `form:"field" validate:"required" label:"字段"`
+	ListFilter
+}
+
+// 允许查询取值的字段
+var valueFields = pkgs.DistinctFields{
+	"critical": "critical",
 }
 
+func valuesRule(req *ValuesReq) []pkgs.Violation {
+	return append(valueFields.Violations(req.Field), listFilterRule(&req.ListFilter)...)
+}
+
+// 查询角色字段取值的响应
+type ValuesRes = []pkgs.DistinctValue
+
 // 角色响应
 type RoleItem struct {
-	ID          string  `json:"id" label:"角色ID"`
-	Name        string  `json:"name" label:"角色名称"`
-	Description *string `json:"description,omitempty" label:"角色描述"`
-	CreatedAt   string  `json:"created_at" label:"创建时间"`
-	UpdatedAt   string  `json:"updated_at" label:"更新时间"`
+	ID           string          `json:"id" label:"角色ID"`
+	Name         string          `json:"name" label:"角色名称"`
+	Description  *string         `json:"description,omitempty" label:"角色描述"`
+	Critical     bool            `json:"critical" label:"是否关键角色"`
+	Attributes   pkgs.Attributes `json:"attributes" label:"自定义属性"`
+	ParentRoleID *string         `json:"parent_role_id,omitempty" label:"父角色ID"`
+	CreatedAt    string          `json:"created_at" label:"创建时间"`
+	UpdatedAt    string          `json:"updated_at" label:"更新时间"`
 }
 
-// 查询角色的响应体
+// 查询角色的响应体，游标分页时不统计总数（total 为 0）
 type QueryListRes struct {
-	List  []RoleItem `json:"list"`
-	Total int64      `json:"total"`
+	List       []RoleItem `json:"list"`
+	Total      int64      `json:"total"`
+	NextCursor string     `json:"next_cursor,omitempty" label:"下一页游标"`
 }
 
+// 查询角色翻译的请求参数
+type GetTranslationsReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"角色ID"`
+}
+
+// 查询角色翻译的响应，键为语言标签
+type GetTranslationsRes = pkgs.Translations
+
+// 设置角色某一语言翻译的请求体，已有的翻译整体替换
+type PutTranslationReq struct {
+	ID          string  `uri:"id" json:"-" validate:"required,uuid" label:"角色ID"`
+	Locale      string  `uri:"locale" json:"-" validate:"required" label:"语言"`
+	Name        string  `json:"name" validate:"required" label:"角色名称"`
+	Description *string `json:"description,omitempty" label:"角色描述"`
+}
+
+func putTranslationRule(req *PutTranslationReq) []pkgs.Violation {
+	return pkgs.LocaleViolations("locale", req.Locale)
+}
+
+// 设置角色翻译的响应
+type PutTranslationRes = int64
+
+// 删除角色某一语言翻译的请求参数
+type DeleteTranslationReq struct {
+	ID     string `uri:"id" validate:"required,uuid" label:"角色ID"`
+	Locale string `uri:"locale" validate:"required" label:"语言"`
+}
+
+func deleteTranslationRule(req *DeleteTranslationReq) []pkgs.Violation {
+	return pkgs.LocaleViolations("locale", req.Locale)
+}
+
+// 删除角色翻译的响应
+type DeleteTranslationRes = int64
+
 // 给角色分配权限的请求体
+// deny_permission_ids 为拒绝的权限，用户任一角色拒绝某权限时，其他角色授予的同一权限不再生效
 type AssignPermissionsReq struct {
-	PermissionIDs []string `json:"permission_ids" validate:"required,min=1" label:"权限ID列表"`
+	PermissionIDs     []string `json:"permission_ids" validate:"required,min=1" label:"权限ID列表"`
+	DenyPermissionIDs []string `json:"deny_permission_ids" validate:"omitempty,dive,uuid" label:"拒绝的权限ID列表"`
 }
 
 // 给角色分配权限的请求参数（包含角色ID）
@@ -103,9 +318,68 @@ type AssignPermissionsByIDReq struct {
 	AssignPermissionsReq
 }
 
+// 分配的权限ID列表不能重复，同一权限不能同时授予和拒绝
+func assignPermissionsRule(req *AssignPermissionsByIDReq) []pkgs.Violation {
+	violations := pkgs.DuplicateViolations("permission_ids", "权限ID", req.PermissionIDs)
+	violations = append(violations, pkgs.DuplicateViolations("deny_permission_ids", "拒绝的权限ID", req.DenyPermissionIDs)...)
+	return append(violations, conflictingEffectViolations("deny_permission_ids", "权限", req.PermissionIDs, req.DenyPermissionIDs)...)
+}
+
 // 给角色分配权限的响应体
 type AssignPermissionsRes = int64
 
+// 同步角色权限的请求体：permission_ids 与 deny_permission_ids 为角色最终应授予、拒绝的完整权限集合，传空数组表示清空
+// namespaces 与 deny_namespaces 为按命名空间授予、拒绝的完整集合，一行授予命名空间及其子命名空间下的全部权限（相当于 user:*）；
+// 两者都不传时不修改角色的命名空间授予
+type SyncPermissionsReq struct {
+	PermissionIDs     []string `json:"permission_ids" validate:"required,dive,uuid" label:"权限ID列表"`
+	DenyPermissionIDs []string `json:"deny_permission_ids" validate:"omitempty,dive,uuid" label:"拒绝的权限ID列表"`
+	Namespaces        []string `json:"namespaces" validate:"omitempty,dive,permission_namespace" label:"命名空间列表"`
+	DenyNamespaces    []string `json:"deny_namespaces" validate:"omitempty,dive,permission_namespace" label:"拒绝的命名空间列表"`
+}
+
+// SyncNamespaces 请求中是否传入了命名空间授予
+func (req *SyncPermissionsReq) SyncNamespaces() bool {
+	return req.Namespaces != nil || req.DenyNamespaces != nil
+}
+
+// 同步角色权限的请求参数（包含角色ID）
+type SyncPermissionsByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"角色ID"`
+	SyncPermissionsReq
+}
+
+// 同步的权限ID列表不能重复，同一权限不能同时授予和拒绝
+func syncPermissionsRule(req *SyncPermissionsByIDReq) []pkgs.Violation {
+	violations := pkgs.DuplicateViolations("permission_ids", "权限ID", req.PermissionIDs)
+	violations = append(violations, pkgs.DuplicateViolations("deny_permission_ids", "拒绝的权限ID", req.DenyPermissionIDs)...)
+	violations = append(violations, conflictingEffectViolations("deny_permission_ids", "权限", req.PermissionIDs, req.DenyPermissionIDs)...)
+	violations = append(violations, pkgs.DuplicateViolations("namespaces", "命名空间", req.Namespaces)...)
+	violations = append(violations, pkgs.DuplicateViolations("deny_namespaces", "拒绝的命名空间", req.DenyNamespaces)...)
+	return append(violations, conflictingEffectViolations("deny_namespaces", "命名空间", req.Namespaces, req.DenyNamespaces)...)
+}
+
+// conflictingEffectViolations 返回同时出现在授予与拒绝列表中的值，field 为拒绝列表的字段名
+func conflictingEffectViolations(field, label string, allow, deny []string) []pkgs.Violation {
+	var violations []pkgs.Violation
+	for i, id := range deny {
+		if slices.ContainsFunc(allow, func(a string) bool { return strings.EqualFold(a, id) }) {
+			violations = append(violations, pkgs.Violation{
+				Field:   field,
+				Message: fmt.Sprintf("%s %s 不能同时授予和拒绝（下标 %d）", label, id, i),
+				Indexes: []int{i},
+			})
+		}
+	}
+	return violations
+}
+
+// 同步角色权限的响应体
+type SyncPermissionsRes struct {
+	Added   int64 `json:"added" label:"新增或变更效果的关联数（含命名空间授予）"`
+	Removed int64 `json:"removed" label:"移除关联数"`
+}
+
 // 查询角色权限列表的请求参数
 type GetRolePermissionsReq struct {
 	ID string `uri:"id" validate:"required,uuid" label:"角色ID"`
@@ -113,16 +387,106 @@ type GetRolePermissionsReq struct {
 
 // 权限项结构体
 type PermissionItem struct {
-	ID        string                 `json:"id" label:"权限ID"`
-	Name      string                 `json:"name" label:"权限名称"`
-	Type      string                 `json:"type" label:"权限类型"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty" label:"权限元数据"`
-	CreatedAt string                 `json:"created_at" label:"创建时间"`
-	UpdatedAt string                 `json:"updated_at" label:"更新时间"`
+	ID       string                 `json:"id" label:"权限ID"`
+	Name     string                 `json:"name" label:"权限名称"`
+	Type     string                 `json:"type" label:"权限类型"`
+	Metadata map[string]interface{} `json:"metadata,omitempty" label:"权限元数据"`
+	Effect   string                 `json:"effect" label:"效果（allow 授予，deny 拒绝）"`
+	// 继承来的权限所属的祖先角色，角色自身的权限为空；只在角色详情的展开权限中返回
+	InheritedFrom *string `json:"inherited_from,omitempty" label:"来源角色ID"`
+	// 按命名空间授予的权限所属的授予命名空间，逐个分配的权限为空；只在角色详情的展开权限中返回
+	Namespace *string `json:"granted_namespace,omitempty" label:"授予的命名空间"`
+	CreatedAt string  `json:"created_at" label:"创建时间"`
+	UpdatedAt string  `json:"updated_at" label:"更新时间"`
 }
 
-// 查询角色权限列表的响应体
+// 角色的命名空间授予
+type NamespaceGrantItem struct {
+	Namespace string `json:"namespace" label:"命名空间"`
+	Effect    string `json:"effect" label:"效果（allow 授予，deny 拒绝）"`
+	// 命名空间及其子命名空间下当前的权限数，之后新增到命名空间下的权限同样生效
+	PermissionCount int64  `json:"permission_count" label:"权限数"`
+	CreatedAt       string `json:"created_at" label:"创建时间"`
+}
+
+// 查询角色权限列表的响应体，list 为逐个分配的权限，namespaces 为按命名空间授予
 type GetRolePermissionsRes struct {
-	List  []PermissionItem `json:"list"`
+	List       []PermissionItem     `json:"list"`
+	Total      int64                `json:"total"`
+	Namespaces []NamespaceGrantItem `json:"namespaces" label:"命名空间授予"`
+}
+
+// 关键角色变更的操作类型，与 iacc_role_change.action 一致
+const (
+	ChangeUpdate            = "update"
+	ChangePatch             = "patch"
+	ChangeDelete            = "delete"
+	ChangeAssignPermissions = "assign_permissions"
+	ChangeSyncPermissions   = "sync_permissions"
+)
+
+// 关键角色变更的审批状态
+const (
+	ChangePending  = "pending"
+	ChangeApproved = "approved"
+	ChangeRejected = "rejected"
+)
+
+// 数据库表 iacc_role_change 的表结构
+type RoleChangeEntity struct {
+	ID          string          `db:"id" label:"变更ID"`
+	CreatedAt   time.Time       `db:"created_at" label:"创建时间"`
+	UpdatedAt   time.Time       `db:"updated_at" label:"更新时间"`
+	RoleID      string          `db:"role_id" label:"角色ID"`
+	Action      string          `db:"action" label:"操作类型"`
+	Payload     json.RawMessage `db:"payload" label:"变更内容"`
+	Status      string          `db:"status" label:"审批状态"`
+	RequestedBy string          `db:"requested_by" label:"提交人"`
+	ReviewedBy  *string         `db:"reviewed_by" label:"审批人"`
+	ReviewedAt  *time.Time      `db:"reviewed_at" label:"审批时间"`
+}
+
+// 关键角色的变更已暂存时随 202 业务码返回的数据
+type StagedChangeRes struct {
+	ChangeID string `json:"change_id" label:"变更ID"`
+}
+
+// 审批角色变更的请求参数
+type ReviewChangeReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"变更ID"`
+}
+
+// 审批通过角色变更的响应体，result 为执行变更的结果
+type ApproveChangeRes struct {
+	Action string `json:"action" label:"操作类型"`
+	Result any    `json:"result" label:"执行结果"`
+}
+
+// 驳回角色变更的响应体
+type RejectChangeRes = int64
+
+// 查询角色变更的请求参数
+type QueryChangesReq struct {
+	pkgs.Pagination
+	Status string `form:"status,omitempty" validate:"omitempty,oneof=pending approved rejected" label:"审批状态"`
+	RoleID string `form:"role_id,omitempty" validate:"omitempty,uuid" label:"角色ID"`
+}
+
+// 角色变更项
+type RoleChangeItem struct {
+	ID          string          `json:"id" label:"变更ID"`
+	RoleID      string          `json:"role_id" label:"角色ID"`
+	Action      string          `json:"action" label:"操作类型"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object" label:"变更内容"`
+	Status      string          `json:"status" label:"审批状态"`
+	RequestedBy string          `json:"requested_by" label:"提交人"`
+	ReviewedBy  *string         `json:"reviewed_by,omitempty" label:"审批人"`
+	ReviewedAt  *string         `json:"reviewed_at,omitempty" label:"审批时间"`
+	CreatedAt   string          `json:"created_at" label:"创建时间"`
+}
+
+// 查询角色变更的响应体
+type QueryChangesRes struct {
+	List  []RoleChangeItem `json:"list"`
 	Total int64            `json:"total"`
 }
@@ -23,6 +23,14 @@
 //	    post: BatchDelete
 //	  /user/list:
 //	    get: QueryList
+//	  /user/export:
+//	    get: Export
+//	  /user/import:
+//	    post: Import
+//	  /user/import/{id}:
+//	    get: GetImportJob
+//	  /user/check-availability:
+//	    post: CheckAvailability
 //	  /user/{id}/role:
 //	    post: AssignRoles
 //	  /user/{id}/roles:
@@ -30,10 +38,19 @@
 package user
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"go-pg-demo/pkgs"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
 	"github.com/samber/mo/result"
 	"go.uber.org/zap"
 )
@@ -43,17 +60,84 @@ type Handler struct {
 	logger     *zap.Logger
 	validator  *pkgs.RequestValidator
 	repository *Repository
+	// 敏感信息脱敏依赖 user:view_pii 权限判断
+	permissions *pkgs.PermissionChecker
+	// 分配角色后使权限缓存失效
+	cache *pkgs.PermissionCache
+	// 分配角色记录为角色变更安全事件
+	events *pkgs.SecurityEvents
+	// 创建、修改、删除、分配角色写入审计日志
+	audit *pkgs.AuditLog
+	// 修改、删除、分配角色通知关注该用户的人
+	watchers *pkgs.Watchers
 }
 
-func NewUserHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator) *Handler {
-	return &Handler{
-		db:        db,
-		logger:    logger,
-		validator: validator,
-		repository: &Repository{
-			db:     db,
-			logger: logger,
+func NewUserHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, reporting *pkgs.ReportingDB, permissions *pkgs.PermissionChecker, ids *pkgs.IDGenerator, cache *pkgs.PermissionCache, events *pkgs.SecurityEvents, audit *pkgs.AuditLog, hasher *pkgs.PasswordHasher, config *pkgs.Config, scheduler *pkgs.Scheduler, watchers *pkgs.Watchers, jobs *pkgs.JobQueue, settings *pkgs.Settings) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, getByIDRule)
+	pkgs.RegisterRule(validator, queryListRule)
+	pkgs.RegisterRule(validator, listFilterRule)
+	pkgs.RegisterRule(validator, exportRule)
+	pkgs.RegisterRule(validator, importRule)
+	pkgs.RegisterRule(validator, existsRule)
+	pkgs.RegisterRule(validator, checkAvailabilityRule)
+	pkgs.RegisterRule(validator, batchCreateRule)
+	pkgs.RegisterRule(validator, batchDeleteRule)
+	pkgs.RegisterRule(validator, assignRolesRule)
+	pkgs.RegisterRule(validator, patchRule)
+	pkgs.RegisterRule(validator, patchProfileRule)
+
+	repository := NewRepository(db, logger, tables, pool, ids, hasher, settings)
+	audit.RegisterSnapshot(pkgs.AuditEntityUser, repository.Snapshot)
+	repository.search = config.UserSearch
+	repository.reporting = reporting
+	repository.jobs = jobs
+	repository.audit = audit
+	repository.importSyncRows = config.UserImport.SyncRows
+	jobs.Register(JobTypeImport, repository.runImport)
+	// 开启物化视图时定时刷新
+	if config.UserSearch.Enabled {
+		scheduler.Register("user.search_refresh", config.UserSearch.Schedule, repository.RefreshSearch)
+	}
+	watchers.RegisterTarget(pkgs.WatchEntityUser, pkgs.WatchTarget{
+		Label: "用户",
+		Table: tables.User,
+		// 关注用户须拥有查看该用户的接口权限，关注之后权限被收回的关注者不再收到通知
+		Check: func(c *gin.Context, id string) error {
+			allowed, err := permissions.HasAPI(c, http.MethodGet, "/v1/user/"+id)
+			if err != nil {
+				return pkgs.NewApiError(http.StatusInternalServerError, "关注失败")
+			}
+			if !allowed {
+				return pkgs.NewApiError(http.StatusForbidden, "无权查看该用户")
+			}
+			return repository.GetByID(c)(&GetByIDReq{ID: id}).Error()
+		},
+		Visible: func(c *gin.Context, id string, userIDs []string) ([]string, error) {
+			var visible []string
+			for _, userID := range userIDs {
+				perms, err := permissions.UserPermissions(c, userID)
+				if err != nil {
+					return nil, err
+				}
+				if pkgs.APIAllowed(perms, http.MethodGet, "/v1/user/"+id) {
+					visible = append(visible, userID)
+				}
+			}
+			return visible, nil
 		},
+	})
+
+	return &Handler{
+		db:          db,
+		logger:      logger,
+		validator:   validator,
+		permissions: permissions,
+		cache:       cache,
+		events:      events,
+		audit:       audit,
+		watchers:    watchers,
+		repository:  repository,
 	}
 }
 
@@ -65,21 +149,74 @@ func NewUserHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestVali
 //	@Accept       json
 //	@Produce      json
 //	@Param        request  body      CreateReq              true  "创建用户所需的请求体参数"
+//	@Param        return   query     string                 false "返回内容，entity 时返回完整用户（data 为 GetByIDRes，手机号、邮箱按权限脱敏）"  Enums(id, entity)
 //	@Success      200      {object}  pkgs.Response{data=CreateRes} "成功创建用户，返回用户ID"
 //	@Failure      400      {object}  pkgs.Response              "请求参数验证失败或格式不正确"
 //	@Failure      500      {object}  pkgs.Response              "服务器内部错误，无法创建用户"
+//	@x-permission {"method":"POST","path":"/v1/user"}
 //	@Router       /user [post]
 func (h *Handler) Create(c *gin.Context) {
-	result.Pipe2(
+	if pkgs.WantEntity(c) {
+		result.Pipe4(
+			pkgs.BindJSON[CreateReq](c),
+			result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+			result.FlatMap(h.repository.CreateEntity(c)),
+			result.Map(pkgs.RecordAuditEach[GetByIDRes](c, h.audit, "create", pkgs.AuditEntityUser, func(res GetByIDRes) []string { return []string{res.ID} })),
+			result.FlatMap(pkgs.MaskPII[GetByIDRes](c, h.permissions)),
+		).Match(
+			pkgs.HandleSuccess[GetByIDRes](c),
+			pkgs.HandleError[GetByIDRes](c),
+		)
+		return
+	}
+
+	result.Pipe3(
 		pkgs.BindJSON[CreateReq](c),
 		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
 		result.FlatMap(h.repository.Create(c)),
+		result.Map(pkgs.RecordAuditEach[CreateRes](c, h.audit, "create", pkgs.AuditEntityUser, func(id CreateRes) []string { return []string{string(id)} })),
 	).Match(
 		pkgs.HandleSuccess[CreateRes](c),
 		pkgs.HandleError[CreateRes](c),
 	)
 }
 
+// CreateFromBlueprint 按蓝图创建用户
+//
+//	@Summary  按蓝图创建用户
+//	@Description  在一个事务内创建用户、分配蓝图中的角色并填入蓝图的默认个人信息（请求中填写的字段优先）；蓝图中已删除的角色被跳过，返回实际分配的角色
+//	@Tags   user
+//	@Accept   json
+//	@Produce  json
+//	@Param    blueprintId path  string                  true  "蓝图ID"
+//	@Param    request     body  CreateFromBlueprintReq  true  "创建用户请求参数"
+//	@Success  200   {object}  pkgs.Response{data=CreateFromBlueprintRes}  "创建成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404   {object}  pkgs.Response       "蓝图不存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/user/from-blueprint/:blueprintId"}
+//	@Router   /user/from-blueprint/{blueprintId} [post]
+func (h *Handler) CreateFromBlueprint(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindUriAndJSON[CreateFromBlueprintReq](c),
+		result.FlatMap(pkgs.ValidateV2[CreateFromBlueprintReq](h.validator)),
+		result.FlatMap(h.repository.CreateFromBlueprint(c)),
+		result.Map(pkgs.RecordSecurityEvent[CreateFromBlueprintRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "create_user_from_blueprint", "blueprint_id": c.Param("blueprintId")})),
+		result.Map(h.recordCreateFromBlueprint(c)),
+	).Match(
+		pkgs.HandleSuccess[CreateFromBlueprintRes](c),
+		pkgs.HandleError[CreateFromBlueprintRes](c),
+	)
+}
+
+// recordCreateFromBlueprint 将按蓝图创建用户写入审计日志，实体ID为新用户的ID
+func (h *Handler) recordCreateFromBlueprint(c *gin.Context) func(CreateFromBlueprintRes) CreateFromBlueprintRes {
+	return func(res CreateFromBlueprintRes) CreateFromBlueprintRes {
+		h.audit.Record(c, "create_from_blueprint", pkgs.AuditEntityUser, res.ID, map[string]any{"blueprint_id": c.Param("blueprintId"), "role_ids": res.RoleIDs})
+		return res
+	}
+}
+
 // BatchCreate 批量创建用户
 //
 //	@Summary  批量创建用户
@@ -88,15 +225,32 @@ func (h *Handler) Create(c *gin.Context) {
 //	@Accept   json
 //	@Produce  json
 //	@Param    request body  BatchCreateReq  true  "批量创建用户请求参数"
+//	@Param    return  query string  false "返回内容，entity 时返回完整用户列表（data 为 BatchCreateEntityRes，手机号、邮箱按权限脱敏）"  Enums(id, entity)
 //	@Success  200   {object}  pkgs.Response{data=BatchCreateRes}  "创建成功，返回用户ID列表"
 //	@Failure  400   {object}  pkgs.Response         "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response         "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/user/batch-create"}
 //	@Router   /user/batch-create [post]
 func (h *Handler) BatchCreate(c *gin.Context) {
-	result.Pipe2(
+	if pkgs.WantEntity(c) {
+		result.Pipe4(
+			pkgs.BindJSON[BatchCreateReq](c),
+			result.FlatMap(pkgs.ValidateV2[BatchCreateReq](h.validator)),
+			result.FlatMap(h.repository.BatchCreateEntity(c)),
+			result.Map(pkgs.RecordAuditEach[BatchCreateEntityRes](c, h.audit, "batch_create", pkgs.AuditEntityUser, BatchCreateEntityRes.ids)),
+			result.FlatMap(pkgs.MaskPII[BatchCreateEntityRes](c, h.permissions)),
+		).Match(
+			pkgs.HandleSuccess[BatchCreateEntityRes](c),
+			pkgs.HandleError[BatchCreateEntityRes](c),
+		)
+		return
+	}
+
+	result.Pipe3(
 		pkgs.BindJSON[BatchCreateReq](c),
 		result.FlatMap(pkgs.ValidateV2[BatchCreateReq](h.validator)),
 		result.FlatMap(h.repository.BatchCreate(c)),
+		result.Map(pkgs.RecordAuditEach[BatchCreateRes](c, h.audit, "batch_create", pkgs.AuditEntityUser, func(ids BatchCreateRes) []string { return ids })),
 	).Match(
 		pkgs.HandleSuccess[BatchCreateRes](c),
 		pkgs.HandleError[BatchCreateRes](c),
@@ -106,21 +260,24 @@ func (h *Handler) BatchCreate(c *gin.Context) {
 // GetByID 根据ID获取用户
 //
 //	@Summary      根据用户ID获取用户详情
-//	@Description  通过指定的用户唯一标识符(UUID)来检索特定用户的详细信息。
+//	@Description  通过指定的用户唯一标识符(UUID)来检索特定用户的详细信息。没有 user:view_pii 权限时手机号、邮箱脱敏返回。
 //	@Tags         用户管理
 //	@Accept       json
 //	@Produce      json
-//	@Param        id   path      string                     true  "用户唯一标识符(UUID)"  Format(UUID)
+//	@Param        id       path      string                     true  "用户唯一标识符(UUID)"  Format(UUID)
+//	@Param        include  query     string                     false "扩展内容，逗号分隔：roles（角色，最多100条）、permissions（经角色获得的权限，最多500条）、loginHistory（最近登录的设备，最多20条）"
 //	@Success      200  {object}  pkgs.Response{data=GetByIDRes} "成功获取用户信息"
-//	@Failure      400  {object}  pkgs.Response              "提供的用户ID格式无效"
+//	@Failure      400  {object}  pkgs.Response              "提供的用户ID格式无效或扩展内容不支持"
 //	@Failure      404  {object}  pkgs.Response              "未找到指定ID的用户"
 //	@Failure      500  {object}  pkgs.Response              "服务器内部错误，无法获取用户信息"
+//	@x-permission {"method":"GET","path":"/v1/user/:id"}
 //	@Router       /user/{id} [get]
 func (h *Handler) GetByID(c *gin.Context) {
-	result.Pipe2(
-		pkgs.BindUri[GetByIDReq](c),
+	result.Pipe3(
+		pkgs.BindUriAndQuery[GetByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[GetByIDReq](h.validator)),
 		result.FlatMap(h.repository.GetByID(c)),
+		result.FlatMap(pkgs.MaskPII[GetByIDRes](c, h.permissions)),
 	).Match(
 		pkgs.HandleSuccess[GetByIDRes](c),
 		pkgs.HandleError[GetByIDRes](c),
@@ -130,7 +287,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 // UpdateByID 根据ID更新用户
 //
 //	@Summary      根据用户ID更新用户信息
-//	@Description  通过指定的用户唯一标识符(UUID)来更新特定用户的密码和个人信息。只会更新请求中包含的字段。
+//	@Description  通过指定的用户唯一标识符(UUID)来更新特定用户的密码和个人信息。只会更新请求中包含的字段；phone、profile 传 null 时清空。
 //	@Tags         用户管理
 //	@Accept       json
 //	@Produce      json
@@ -139,18 +296,80 @@ func (h *Handler) GetByID(c *gin.Context) {
 //	@Success      200      {object}  pkgs.Response{data=UpdateByIDRes}   "成功更新用户信息"
 //	@Failure      400      {object}  pkgs.Response   "请求参数验证失败或格式不正确"
 //	@Failure      500      {object}  pkgs.Response   "服务器内部错误，无法更新用户信息"
+//	@x-permission {"method":"PUT","path":"/v1/user/:id"}
 //	@Router       /user/{id} [put]
 func (h *Handler) UpdateByID(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe5(
 		pkgs.BindUriAndJSON[UpdateByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[UpdateByIDReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[UpdateByIDReq](c, h.audit, pkgs.AuditEntityUser, c.Param("id"))),
 		result.FlatMap(h.repository.UpdateByID(c)),
+		result.Map(pkgs.RecordAudit[UpdateByIDRes](c, h.audit, "update", pkgs.AuditEntityUser, c.Param("id"))),
+		result.Map(pkgs.NotifyWatchers[UpdateByIDRes](c, h.watchers, "update", pkgs.WatchEntityUser, pkgs.WatchAffected(c.Param("id")))),
 	).Match(
 		pkgs.HandleSuccess[UpdateByIDRes](c),
 		pkgs.HandleError[UpdateByIDRes](c),
 	)
 }
 
+// PatchByID 根据ID部分更新用户
+//
+//	@Summary      根据用户ID部分更新用户信息
+//	@Description  按 JSON Merge Patch（RFC 7386）部分更新用户：未出现的字段保持不变，显式 null 清空可空字段（phone、profile），profile 为对象时按字段递归合并，其中字段为 null 表示删除该字段。
+//	@Tags         用户管理
+//	@Accept       json,application/merge-patch+json
+//	@Produce      json
+//	@Param        id       path      string          true  "用户唯一标识符(UUID)"  Format(UUID)
+//	@Param        request  body      PatchByIDReq    true  "需要更新的用户字段"
+//	@Success      200      {object}  pkgs.Response{data=PatchByIDRes}   "成功更新用户信息"
+//	@Failure      400      {object}  pkgs.Response   "请求参数验证失败或格式不正确"
+//	@Failure      404      {object}  pkgs.Response   "未找到指定ID的用户"
+//	@Failure      500      {object}  pkgs.Response   "服务器内部错误，无法更新用户信息"
+//	@x-permission {"method":"PATCH","path":"/v1/user/:id"}
+//	@Router       /user/{id} [patch]
+func (h *Handler) PatchByID(c *gin.Context) {
+	result.Pipe5(
+		pkgs.BindUriAndMergePatch[PatchByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[PatchByIDReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[PatchByIDReq](c, h.audit, pkgs.AuditEntityUser, c.Param("id"))),
+		result.FlatMap(h.repository.PatchByID(c)),
+		result.Map(pkgs.RecordAudit[PatchByIDRes](c, h.audit, "patch", pkgs.AuditEntityUser, c.Param("id"))),
+		result.Map(pkgs.NotifyWatchers[PatchByIDRes](c, h.watchers, "patch", pkgs.WatchEntityUser, pkgs.WatchAffected(c.Param("id")))),
+	).Match(
+		pkgs.HandleSuccess[PatchByIDRes](c),
+		pkgs.HandleError[PatchByIDRes](c),
+	)
+}
+
+// PatchProfile 合并更新用户个人信息
+//
+//	@Summary      合并更新用户个人信息
+//	@Description  将 set 中的字段合并进已存储的个人信息，delete 中的字段从个人信息中删除，未提及的字段保持不变，客户端无需先读取整个 profile。
+//	@Tags         用户管理
+//	@Accept       json
+//	@Produce      json
+//	@Param        id       path      string            true  "用户唯一标识符(UUID)"  Format(UUID)
+//	@Param        request  body      PatchProfileReq   true  "需要合并或删除的个人信息字段"
+//	@Success      200      {object}  pkgs.Response{data=PatchProfileRes}   "成功更新个人信息"
+//	@Failure      400      {object}  pkgs.Response     "请求参数验证失败或格式不正确"
+//	@Failure      404      {object}  pkgs.Response     "未找到指定ID的用户"
+//	@Failure      500      {object}  pkgs.Response     "服务器内部错误，无法更新个人信息"
+//	@x-permission {"method":"PATCH","path":"/v1/user/:id/profile"}
+//	@Router       /user/{id}/profile [patch]
+func (h *Handler) PatchProfile(c *gin.Context) {
+	result.Pipe5(
+		pkgs.BindUriAndJSON[PatchProfileReq](c),
+		result.FlatMap(pkgs.ValidateV2[PatchProfileReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[PatchProfileReq](c, h.audit, pkgs.AuditEntityUser, c.Param("id"))),
+		result.FlatMap(h.repository.PatchProfile(c)),
+		result.Map(pkgs.RecordAudit[PatchProfileRes](c, h.audit, "patch_profile", pkgs.AuditEntityUser, c.Param("id"))),
+		result.Map(pkgs.NotifyWatchers[PatchProfileRes](c, h.watchers, "patch_profile", pkgs.WatchEntityUser, pkgs.WatchAffected(c.Param("id")))),
+	).Match(
+		pkgs.HandleSuccess[PatchProfileRes](c),
+		pkgs.HandleError[PatchProfileRes](c),
+	)
+}
+
 // DeleteByID 根据ID删除用户
 //
 //	@Summary      根据用户ID删除用户
@@ -162,12 +381,16 @@ func (h *Handler) UpdateByID(c *gin.Context) {
 //	@Success      200  {object}  pkgs.Response{data=DeleteByIDRes} "成功删除用户，返回受影响的行数"
 //	@Failure      400  {object}  pkgs.Response             "提供的用户ID格式无效"
 //	@Failure      500  {object}  pkgs.Response             "服务器内部错误，无法删除用户"
+//	@x-permission {"method":"DELETE","path":"/v1/user/:id"}
 //	@Router       /user/{id} [delete]
 func (h *Handler) DeleteByID(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe5(
 		pkgs.BindUri[DeleteByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[DeleteByIDReq](c, h.audit, pkgs.AuditEntityUser, c.Param("id"))),
 		result.FlatMap(h.repository.DeleteByID(c)),
+		result.Map(pkgs.RecordAudit[DeleteByIDRes](c, h.audit, "delete", pkgs.AuditEntityUser, c.Param("id"))),
+		result.Map(pkgs.NotifyWatchersDeleted[DeleteByIDRes](c, h.watchers, "delete", pkgs.WatchEntityUser, pkgs.WatchAffected(c.Param("id")))),
 	).Match(
 		pkgs.HandleSuccess[DeleteByIDRes](c),
 		pkgs.HandleError[DeleteByIDRes](c),
@@ -185,12 +408,17 @@ func (h *Handler) DeleteByID(c *gin.Context) {
 //	@Success  200   {object}  pkgs.Response{data=BatchDeleteRes}       "删除成功，返回影响行数"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/user/batch-delete"}
 //	@Router   /user/batch-delete [post]
 func (h *Handler) BatchDelete(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe6(
 		pkgs.BindJSON[DeleteUsersReq](c),
 		result.FlatMap(pkgs.ValidateV2[DeleteUsersReq](h.validator)),
+		result.FlatMap(pkgs.AuditBeforeEach[DeleteUsersReq](c, h.audit, pkgs.AuditEntityUser, func(req *DeleteUsersReq) []string { return req.IDs })),
+		result.Map(pkgs.WatchTargets[DeleteUsersReq](c, pkgs.WatchEntityUser, func(req *DeleteUsersReq) []string { return req.IDs })),
 		result.FlatMap(h.repository.BatchDelete(c)),
+		result.Map(pkgs.RecordAuditEach[BatchDeleteRes](c, h.audit, "batch_delete", pkgs.AuditEntityUser, nil)),
+		result.Map(pkgs.NotifyWatchersDeleted[BatchDeleteRes](c, h.watchers, "batch_delete", pkgs.WatchEntityUser, nil)),
 	).Match(
 		pkgs.HandleSuccess[BatchDeleteRes](c),
 		pkgs.HandleError[BatchDeleteRes](c),
@@ -200,29 +428,352 @@ func (h *Handler) BatchDelete(c *gin.Context) {
 // QueryList 获取用户列表
 //
 //	@Summary      获取用户列表（支持分页和筛选）
-//	@Description  获取系统中的用户列表，支持按手机号和用户名模糊搜索，并提供分页功能。
+//	@Description  获取系统中的用户列表，支持按手机号和用户名模糊搜索，并提供分页功能。没有 user:view_pii 权限时手机号、邮箱脱敏返回。开启 user_search 配置时查询定时刷新的物化视图，refreshed_at 为数据的刷新时间，之后新增、修改的用户在下次刷新后可见。
 //	@Tags         用户管理
 //	@Accept       json
 //	@Produce      json
 //	@Param        page      query     int                        false  "页码，从1开始计算"  minimum(1)  default(1)
 //	@Param        pageSize  query     int                        false  "每页条目数"        minimum(1)  maximum(100)  default(10)
-//	@Param        phone     query     string                     false  "手机号模糊搜索关键字"
+//	@Param        cursor    query     string                     false  "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，只支持按创建时间排序，不统计总数"
+//	@Param        limit     query     int                        false  "游标分页的每页条目数"  minimum(1)  maximum(100)  default(10)
+//	@Param        phone     query     string                     false  "手机号搜索关键字（启用字段加密后为精确匹配）"
 //	@Param        username  query     string                     false  "用户名模糊搜索关键字"
+//	@Param        email     query     string                     false  "邮箱精确匹配"
+//	@Param        filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、username、strict_device、disabled_at、created_at、updated_at"
+//	@Param        createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param        createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param        updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param        updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param        changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Param        If-None-Match  header  string  false  "上次响应的 ETag，列表未变化时返回 304"
+//	@Param        If-Modified-Since  header  string  false  "上次响应的 Last-Modified，列表未变化时返回 304"
 //	@Success      200       {object}  pkgs.Response{data=QueryListRes}  "成功获取用户列表"
 //	@Failure      400       {object}  pkgs.Response                  "请求参数验证失败或格式不正确"
 //	@Failure      500       {object}  pkgs.Response                  "服务器内部错误，无法获取用户列表"
+//	@x-permission {"method":"GET","path":"/v1/user/list"}
 //	@Router       /user/list [get]
 func (h *Handler) QueryList(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe4(
 		pkgs.BindQuery[QueryListReq](c),
 		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.CheckModified(c)),
 		result.FlatMap(h.repository.QueryList(c)),
+		result.FlatMap(pkgs.MaskPII[QueryListRes](c, h.permissions)),
 	).Match(
 		pkgs.HandleSuccess[QueryListRes](c),
 		pkgs.HandleError[QueryListRes](c),
 	)
 }
 
+// Export 导出用户
+//
+//	@Summary      导出用户（CSV 或 XLSX）
+//	@Description  按与用户列表相同的筛选条件与排序导出全部匹配的用户，不分页，边查询边写出文件。没有 user:view_pii 权限时手机号、邮箱脱敏导出；时间按请求时区格式化。
+//	@Tags         用户管理
+//	@Produce      text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,json
+//	@Param        format       query  string  false  "导出格式"  Enums(csv, xlsx)  default(csv)
+//	@Param        orderBy      query  string  false  "排序字段"  Enums(id, username, phone, created_at, updated_at)  default(created_at)
+//	@Param        order        query  string  false  "排序顺序"  Enums(asc, desc)  default(desc)
+//	@Param        phone        query  string  false  "手机号搜索关键字（启用字段加密后为精确匹配）"
+//	@Param        username     query  string  false  "用户名模糊搜索关键字"
+//	@Param        email        query  string  false  "邮箱精确匹配"
+//	@Param        filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、username、strict_device、disabled_at、created_at、updated_at"
+//	@Param        createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param        createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param        updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param        updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param        changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Success      200  {file}    file           "导出文件"
+//	@Failure      400  {object}  pkgs.Response  "请求参数验证失败"
+//	@Failure      500  {object}  pkgs.Response  "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/user/export"}
+//	@Router       /user/export [get]
+func (h *Handler) Export(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindQuery[ExportReq](c),
+		result.FlatMap(pkgs.ValidateV2[ExportReq](h.validator)),
+		result.FlatMap(h.repository.Export(c)),
+		result.FlatMap(h.exportMask(c)),
+	).Match(
+		h.writeExport(c),
+		pkgs.HandleError[*ExportPlan](c),
+	)
+}
+
+// exportMask 当前用户没有 user:view_pii 权限时脱敏导出
+func (h *Handler) exportMask(c *gin.Context) func(*ExportPlan) mo.Result[*ExportPlan] {
+	return func(plan *ExportPlan) mo.Result[*ExportPlan] {
+		canView, err := h.permissions.HasCode(c, pkgs.PermissionCodeViewPII)
+		if err != nil {
+			return mo.Err[*ExportPlan](pkgs.NewApiError(http.StatusInternalServerError, "权限校验失败"))
+		}
+		plan.Mask = !canView
+		return mo.Ok(plan)
+	}
+}
+
+// writeExport 执行查询后写出文件；查询失败时返回错误信封，开始写出后出错时只能中断响应
+func (h *Handler) writeExport(c *gin.Context) func(*ExportPlan) (*ExportPlan, error) {
+	return func(plan *ExportPlan) (*ExportPlan, error) {
+		rows, err := h.repository.exportRows(c, plan)
+		if err != nil {
+			return pkgs.HandleError[*ExportPlan](c)(pkgs.DBError(h.logger, err, "导出用户失败"))
+		}
+		c.Header("Content-Type", pkgs.ExportContentType(plan.Format))
+		c.Header("Content-Disposition", `attachment; filename="users-`+time.Now().UTC().Format("20060102")+`.`+plan.Format+`"`)
+		c.Status(http.StatusOK)
+		count, err := h.repository.writeExport(c, rows, plan, c.Writer)
+		if err != nil {
+			h.logger.Error("导出用户失败", zap.Int64("rows", count), zap.Error(err))
+			c.Abort()
+			return plan, err
+		}
+		return plan, nil
+	}
+}
+
+// Import 导入用户
+//
+//	@Summary      导入用户（CSV）
+//	@Description  上传 UTF-8 编码的 CSV 文件，首行为表头：username、phone、password 必填，email 可选，列的顺序不限；最多 1000 行、5 MB。
+//	@Description  每行按创建用户的规则校验，文件内用户名、手机号重复或与已有用户冲突的行被拒绝。atomic 方式在一个事务内导入，存在被拒绝的行时不导入任何用户；row 方式逐行导入，跳过被拒绝的行。
+//	@Description  返回导入成功的行与被拒绝的行及原因，行号从表头开始计数（第一个数据行为第 2 行）。
+//	@Description  通过校验的行数超过 user_import.sync_rows 时转为异步导入，返回业务码 202 与任务ID（data 为 ImportJobRes），通过 GET /user/import/{id} 查询导入报告。
+//	@Tags         用户管理
+//	@Accept       multipart/form-data
+//	@Produce      json
+//	@Param        file  formData  file    true   "CSV 文件"
+//	@Param        mode  formData  string  false  "导入方式"  Enums(atomic, row)  default(atomic)
+//	@Success      200  {object}  pkgs.Response{data=ImportRes}  "导入完成，返回导入报告"
+//	@Success      202  {object}  pkgs.Response{data=ImportJobRes}  "已转为异步导入，返回任务ID"
+//	@Failure      400  {object}  pkgs.Response  "请求参数或文件格式错误"
+//	@Failure      500  {object}  pkgs.Response  "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/user/import"}
+//	@Router       /user/import [post]
+func (h *Handler) Import(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindForm[ImportReq](c),
+		result.FlatMap(pkgs.ValidateV2[ImportReq](h.validator)),
+		result.FlatMap(h.importPlan(c)),
+		result.FlatMap(h.repository.Import(c)),
+		result.Map(pkgs.RecordAuditEach[ImportRes](c, h.audit, "import", pkgs.AuditEntityUser, ImportRes.ids)),
+	).Match(
+		pkgs.HandleSuccess[ImportRes](c),
+		pkgs.HandleError[ImportRes](c),
+	)
+}
+
+// GetImportJob 查询异步导入
+//
+//	@Summary      查询异步导入
+//	@Description  返回自己发起的用户异步导入的任务状态（pending、running、succeeded、failed、canceled）与失败原因，任务成功后 report 为导入报告
+//	@Tags         用户管理
+//	@Produce      json
+//	@Param        id  path  string  true  "任务ID"
+//	@Success      200  {object}  pkgs.Response{data=GetImportJobRes}  "获取成功"
+//	@Failure      400  {object}  pkgs.Response  "请求参数错误"
+//	@Failure      404  {object}  pkgs.Response  "导入任务不存在"
+//	@Failure      500  {object}  pkgs.Response  "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/user/import/:id"}
+//	@Router       /user/import/{id} [get]
+func (h *Handler) GetImportJob(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[ImportJobReq](c),
+		result.FlatMap(pkgs.ValidateV2[ImportJobReq](h.validator)),
+		result.FlatMap(h.repository.GetImportJob(c)),
+	).Match(
+		pkgs.HandleSuccess[GetImportJobRes](c),
+		pkgs.HandleError[GetImportJobRes](c),
+	)
+}
+
+// importPlan 读取导入文件，逐行按创建用户的规则校验；用户名、手机号与文件中前面的行重复时拒绝该行
+func (h *Handler) importPlan(c *gin.Context) func(*ImportReq) mo.Result[*ImportPlan] {
+	return func(req *ImportReq) mo.Result[*ImportPlan] {
+		file, err := req.File.Open()
+		if err != nil {
+			h.logger.Error("打开导入文件失败", zap.Error(err))
+			return mo.Err[*ImportPlan](pkgs.NewApiError(http.StatusInternalServerError, "导入用户失败"))
+		}
+		defer file.Close()
+		records, apiErr := readImportFile(file)
+		if apiErr != nil {
+			return mo.Err[*ImportPlan](apiErr)
+		}
+
+		plan := &ImportPlan{Mode: req.Mode, Total: len(records), Rows: []ImportRow{}, Rejected: []ImportRejected{}}
+		validate := pkgs.ValidateV2[CreateReq](h.validator)
+		usernames := map[string]int{}
+		phones := map[string]int{}
+		for i, record := range records {
+			// 表头为第 1 行
+			row := ImportRow{Line: i + 2, CreateReq: record}
+			reason := ""
+			if err := validate(&row.CreateReq).Error(); err != nil {
+				apiErr, _ := err.(*pkgs.ApiError)
+				reason = pkgs.LocalizeError(pkgs.RequestLocale(c), apiErr)
+			} else if apiErr := h.repository.settings.PasswordPolicy().Check(row.Password, h.repository.hasher); apiErr != nil {
+				reason = pkgs.LocalizeError(pkgs.RequestLocale(c), apiErr)
+			} else if line, ok := usernames[row.Username]; ok {
+				reason = pkgs.Localize(c, fmt.Sprintf("用户名与第 %d 行重复", line))
+			} else if line, ok := phones[row.Phone]; ok {
+				reason = pkgs.Localize(c, fmt.Sprintf("手机号与第 %d 行重复", line))
+			}
+			if reason != "" {
+				plan.Rejected = append(plan.Rejected, ImportRejected{Line: row.Line, Username: row.Username, Reason: reason})
+				continue
+			}
+			usernames[row.Username] = row.Line
+			phones[row.Phone] = row.Line
+			plan.Rows = append(plan.Rows, row)
+		}
+		return mo.Ok(plan)
+	}
+}
+
+// 导入文件的列，必填列之外只支持 email
+var importColumns = []string{"username", "phone", "password", "email"}
+
+// readImportFile 读取导入文件的表头与数据行，文件格式错误时返回 400
+func readImportFile(r io.Reader) ([]CreateReq, *pkgs.ApiError) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, pkgs.NewApiError(http.StatusBadRequest, "导入文件没有数据行")
+	}
+	if err != nil {
+		return nil, pkgs.NewApiError(http.StatusBadRequest, "导入文件格式错误："+err.Error())
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			// 电子表格软件保存的 UTF-8 文件可能带有 BOM
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(importColumns, name) {
+			return nil, pkgs.NewApiError(http.StatusBadRequest, "导入文件包含不支持的列 "+name+"，可选值："+strings.Join(importColumns, ", "))
+		}
+		index[name] = i
+	}
+	for _, name := range importColumns[:3] {
+		if _, ok := index[name]; !ok {
+			return nil, pkgs.NewApiError(http.StatusBadRequest, "导入文件缺少列 "+name)
+		}
+	}
+
+	var records []CreateReq
+	for {
+		cells, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, pkgs.NewApiError(http.StatusBadRequest, "导入文件格式错误："+err.Error())
+		}
+		if len(records) == maxImportRows {
+			return nil, pkgs.NewApiError(http.StatusBadRequest, fmt.Sprintf("导入文件不能超过 %d 行", maxImportRows))
+		}
+		record := CreateReq{
+			Username: strings.TrimSpace(cells[index["username"]]),
+			Phone:    strings.TrimSpace(cells[index["phone"]]),
+			Password: cells[index["password"]],
+		}
+		if i, ok := index["email"]; ok {
+			if email := strings.TrimSpace(cells[i]); email != "" {
+				record.Profile.Email = &email
+			}
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, pkgs.NewApiError(http.StatusBadRequest, "导入文件没有数据行")
+	}
+	return records, nil
+}
+
+// Count 统计用户数量
+//
+//	@Summary      统计用户数量
+//	@Description  按与用户列表相同的筛选条件统计用户数量，不返回用户数据
+//	@Tags         用户管理
+//	@Accept       json
+//	@Produce      json
+//	@Param        phone        query  string  false  "手机号搜索关键字（启用字段加密后为精确匹配）"
+//	@Param        username     query  string  false  "用户名模糊搜索关键字"
+//	@Param        email        query  string  false  "邮箱精确匹配"
+//	@Param        filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、username、strict_device、disabled_at、created_at、updated_at"
+//	@Param        createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param        createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param        updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param        updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param        changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Success      200  {object}  pkgs.Response{data=CountRes}  "用户数量"
+//	@Failure      400  {object}  pkgs.Response                 "请求参数验证失败"
+//	@Failure      500  {object}  pkgs.Response                 "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/user/count"}
+//	@Router       /user/count [get]
+func (h *Handler) Count(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[CountReq](c),
+		result.FlatMap(pkgs.ValidateV2[CountReq](h.validator)),
+		result.FlatMap(h.repository.Count(c)),
+	).Match(
+		pkgs.HandleSuccess[CountRes](c),
+		pkgs.HandleError[CountRes](c),
+	)
+}
+
+// Exists 检查用户是否存在
+//
+//	@Summary      检查用户是否存在
+//	@Description  按手机号、用户名或邮箱精确匹配检查用户是否存在（如注册时判断手机号是否已被占用），同时传多个条件时需全部满足
+//	@Tags         用户管理
+//	@Accept       json
+//	@Produce      json
+//	@Param        phone     query  string  false  "手机号"
+//	@Param        username  query  string  false  "用户名"
+//	@Param        email     query  string  false  "邮箱"
+//	@Success      200  {object}  pkgs.Response{data=ExistsRes}  "是否存在"
+//	@Failure      400  {object}  pkgs.Response                  "未传任何条件或参数格式错误"
+//	@Failure      500  {object}  pkgs.Response                  "服务器内部错误"
+//	@x-permission {"method":"GET","path":"/v1/user/exists"}
+//	@Router       /user/exists [get]
+func (h *Handler) Exists(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[ExistsReq](c),
+		result.FlatMap(pkgs.ValidateV2[ExistsReq](h.validator)),
+		result.FlatMap(h.repository.Exists(c)),
+	).Match(
+		pkgs.HandleSuccess[ExistsRes](c),
+		pkgs.HandleError[ExistsRes](c),
+	)
+}
+
+// CheckAvailability 批量检查用户名、手机号是否可用
+//
+//	@Summary      批量检查用户名、手机号是否可用
+//	@Description  批量创建用户前检查一批候选用户名与手机号，按请求顺序返回每一项是否可用，只执行一次查询。已被已有用户使用的项原因为 taken，与请求中前面的项重复的项原因为 duplicate；各最多 1000 项。
+//	@Tags         用户管理
+//	@Accept       json
+//	@Produce      json
+//	@Param        request  body      CheckAvailabilityReq  true  "候选用户名与手机号"
+//	@Success      200  {object}  pkgs.Response{data=CheckAvailabilityRes}  "每一项的可用性"
+//	@Failure      400  {object}  pkgs.Response                  "未传任何候选项或参数格式错误"
+//	@Failure      500  {object}  pkgs.Response                  "服务器内部错误"
+//	@x-permission {"method":"POST","path":"/v1/user/check-availability"}
+//	@Router       /user/check-availability [post]
+func (h *Handler) CheckAvailability(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[CheckAvailabilityReq](c),
+		result.FlatMap(pkgs.ValidateV2[CheckAvailabilityReq](h.validator)),
+		result.FlatMap(h.repository.CheckAvailability(c)),
+	).Match(
+		pkgs.HandleSuccess[CheckAvailabilityRes](c),
+		pkgs.HandleError[CheckAvailabilityRes](c),
+	)
+}
+
 // AssignRole 为用户分配角色
 //
 //	@Summary      为用户分配角色
@@ -235,12 +786,18 @@ func (h *Handler) QueryList(c *gin.Context) {
 //	@Success      200      {object}  pkgs.Response{data=AssignRolesRes}          "成功为用户分配角色"
 //	@Failure      400      {object}  pkgs.Response          "请求参数验证失败或格式不正确"
 //	@Failure      500      {object}  pkgs.Response          "服务器内部错误，无法为用户分配角色"
+//	@x-permission {"method":"POST","path":"/v1/user/:id/role"}
 //	@Router       /user/{id}/role [post]
 func (h *Handler) AssignRole(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe7(
 		pkgs.BindUriAndJSON[AssignRolesReq](c),
 		result.FlatMap(pkgs.ValidateV2[AssignRolesReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[AssignRolesReq](c, h.audit, pkgs.AuditEntityUser, c.Param("id"))),
 		result.FlatMap(h.repository.AssignRoles(c)),
+		result.Map(pkgs.InvalidatePermissionCache[AssignRolesRes](c, h.cache)),
+		result.Map(pkgs.RecordSecurityEvent[AssignRolesRes](c, h.events, pkgs.SecurityEventRoleChange, map[string]any{"action": "assign_user_roles", "user_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[AssignRolesRes](c, h.audit, "assign_roles", pkgs.AuditEntityUser, c.Param("id"))),
+		result.Map(pkgs.NotifyWatchers[AssignRolesRes](c, h.watchers, "assign_roles", pkgs.WatchEntityUser, func(AssignRolesRes) []string { return []string{c.Param("id")} })),
 	).Match(
 		pkgs.HandleSuccess[AssignRolesRes](c),
 		pkgs.HandleError[AssignRolesRes](c),
@@ -258,6 +815,7 @@ func (h *Handler) AssignRole(c *gin.Context) {
 //	@Success      200  {object}  pkgs.Response{data=GetRolesRes} "成功获取用户角色列表"
 //	@Failure      400  {object}  pkgs.Response               "提供的用户ID格式无效"
 //	@Failure      500  {object}  pkgs.Response               "服务器内部错误，无法获取用户角色列表"
+//	@x-permission {"method":"GET","path":"/v1/user/:id/roles"}
 //	@Router       /user/{id}/roles [get]
 func (h *Handler) GetRoles(c *gin.Context) {
 	result.Pipe2(
@@ -269,3 +827,32 @@ func (h *Handler) GetRoles(c *gin.Context) {
 		pkgs.HandleError[GetRolesRes](c),
 	)
 }
+
+// ResetPassword 管理员重置用户密码
+//
+//	@Summary      重置用户密码
+//	@Description  管理员为指定用户设置新密码，无需原密码；该用户此前签发的刷新令牌全部失效，需要使用新密码重新登录。
+//	@Tags         用户管理
+//	@Accept       json
+//	@Produce      json
+//	@Param        id       path      string                true  "用户唯一标识符(UUID)"  Format(UUID)
+//	@Param        request  body      ResetPasswordByIDReq  true  "新密码"
+//	@Success      200      {object}  pkgs.Response{data=ResetPasswordRes}  "重置成功，返回影响行数"
+//	@Failure      400      {object}  pkgs.Response         "请求参数验证失败或格式不正确"
+//	@Failure      404      {object}  pkgs.Response         "未找到指定ID的用户"
+//	@Failure      500      {object}  pkgs.Response         "服务器内部错误，无法重置密码"
+//	@x-permission {"method":"POST","path":"/v1/user/:id/reset-password"}
+//	@Router       /user/{id}/reset-password [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	result.Pipe5(
+		pkgs.BindUriAndJSON[ResetPasswordByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[ResetPasswordByIDReq](h.validator)),
+		result.FlatMap(pkgs.AuditBefore[ResetPasswordByIDReq](c, h.audit, pkgs.AuditEntityUser, c.Param("id"))),
+		result.FlatMap(h.repository.ResetPasswordByID(c)),
+		result.Map(pkgs.RecordSecurityEvent[ResetPasswordRes](c, h.events, pkgs.SecurityEventPasswordReset, map[string]any{"user_id": c.Param("id")})),
+		result.Map(pkgs.RecordAudit[ResetPasswordRes](c, h.audit, "reset_password", pkgs.AuditEntityUser, c.Param("id"))),
+	).Match(
+		pkgs.HandleSuccess[ResetPasswordRes](c),
+		pkgs.HandleError[ResetPasswordRes](c),
+	)
+}
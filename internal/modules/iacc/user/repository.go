@@ -1,69 +1,258 @@
 package user
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"go-pg-demo/pkgs"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/samber/mo"
+	"github.com/samber/mo/result"
 	"go.uber.org/zap"
 )
 
+// 导出时每写出多少行刷新一次响应
+const exportFlushRows = 1000
+
+// 导出文件的列，与 writeExport 中的取值顺序一致
+var exportColumns = []string{"id", "username", "phone", "email", "role_names", "disabled_at", "created_at", "updated_at"}
+
 type Repository struct {
-	db     *sqlx.DB
-	logger *zap.Logger
+	db        *sqlx.DB
+	logger    *zap.Logger
+	tables    *pkgs.TableNames
+	pool      *pkgs.TenantPool
+	reporting *pkgs.ReportingDB
+	ids       *pkgs.IDGenerator
+	hasher    *pkgs.PasswordHasher
+	jobs      *pkgs.JobQueue
+	audit     *pkgs.AuditLog
+	// 运行时设置中的密码策略，设置新密码时校验
+	settings *pkgs.Settings
+	// 通过校验的行数超过该值时转为异步导入
+	importSyncRows int
+	// 开启后列表查询物化视图 iacc_user_search
+	search pkgs.UserSearchConfig
+}
+
+func NewRepository(db *sqlx.DB, logger *zap.Logger, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator, hasher *pkgs.PasswordHasher, settings *pkgs.Settings) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+		tables: tables,
+		pool:   pool,
+		ids:    ids,
+		hasher: hasher,
+
+		settings: settings,
+	}
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// reportConn 返回报表查询应使用的连接与表名，配置了分析库时在分析库查询，不占用主库
+func (r *Repository) reportConn(c *gin.Context) (*sqlx.DB, *pkgs.TableNames) {
+	return r.reporting.Conn(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+// batchConn 返回批量操作应使用的数据库连接，与交互请求的连接池隔离
+func (r *Repository) batchConn(c *gin.Context) *sqlx.DB {
+	return r.pool.BatchDB(c)
+}
+
+// hashPassword 按密码策略校验请求中的明文密码并哈希，数据库中只保存哈希值
+func (r *Repository) hashPassword(password, message string) (string, *pkgs.ApiError) {
+	if apiErr := r.settings.PasswordPolicy().Check(password, r.hasher); apiErr != nil {
+		return "", apiErr
+	}
+	hash, err := r.hasher.Hash(password)
+	if err != nil {
+		r.logger.Error("密码哈希失败", zap.Error(err))
+		return "", pkgs.NewApiError(http.StatusInternalServerError, message)
+	}
+	return hash, nil
 }
 
 func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
 	return func(req *CreateReq) mo.Result[CreateRes] {
+		return result.Pipe1(r.insert(c)(req), result.Map(func(entity *UserEntity) CreateRes {
+			return CreateRes(entity.ID)
+		}))
+	}
+}
+
+// CreateEntity 创建用户并返回完整实体
+func (r *Repository) CreateEntity(c *gin.Context) func(*CreateReq) mo.Result[GetByIDRes] {
+	return func(req *CreateReq) mo.Result[GetByIDRes] {
+		return result.Pipe1(r.insert(c)(req), result.Map(func(entity *UserEntity) GetByIDRes {
+			return toGetByIDRes(c, entity)
+		}))
+	}
+}
+
+// insert 插入单个用户，服务端生成的 id/created_at/updated_at 通过 RETURNING 回填到实体
+func (r *Repository) insert(c *gin.Context) func(*CreateReq) mo.Result[*UserEntity] {
+	return func(req *CreateReq) mo.Result[*UserEntity] {
 		// 创建实体
-		entity := &UserEntity{
-			Username: req.Username,
-			Phone:    &req.Phone,
-			Password: req.Password,
-			Profile:  req.Profile,
+		password, apiErr := r.hashPassword(req.Password, "创建用户失败")
+		if apiErr != nil {
+			return mo.Err[*UserEntity](apiErr)
+		}
+		entity := newUserEntity(req.Username, req.Phone, password, req.Profile)
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[*UserEntity](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
 		}
 		// 数据库操作
-		query := `INSERT INTO "iacc_user" (username, phone, password, profile) VALUES (:username, :phone, :password, :profile) RETURNING id, created_at, updated_at`
-		stmt, err := r.db.PrepareNamedContext(c.Request.Context(), query)
+		columns, values := r.ids.Insert("username", "phone", "phone_hash", "password", "profile", "email_hash")
+		query := `INSERT INTO ` + r.tables.User + ` (` + columns + `) VALUES (` + values + `) RETURNING id, created_at, updated_at`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
 		if err != nil {
-			r.logger.Error("创建用户语句准备失败", zap.Error(err))
-			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
+			r.log(c).Error("创建用户语句准备失败", zap.Error(err))
+			return mo.Err[*UserEntity](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
 		}
 		defer stmt.Close()
 
-		err = stmt.GetContext(c.Request.Context(), entity, entity)
+		err = stmt.GetContext(c.Request.Context(), &entity, entity)
 		if err != nil {
-			r.logger.Error("创建用户失败", zap.Error(err))
-			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
+			return mo.Err[*UserEntity](pkgs.DBError(r.log(c), err, "创建用户失败"))
 		}
 		// 返回结果
-		return mo.Ok(CreateRes(entity.ID))
+		return mo.Ok(&entity)
+	}
+}
+
+// CreateFromBlueprint 按蓝图创建用户：在一个事务内读取蓝图、合并默认个人信息、创建用户并分配蓝图中的角色
+func (r *Repository) CreateFromBlueprint(c *gin.Context) func(*CreateFromBlueprintReq) mo.Result[CreateFromBlueprintRes] {
+	return func(req *CreateFromBlueprintReq) mo.Result[CreateFromBlueprintRes] {
+		ctx := c.Request.Context()
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[CreateFromBlueprintRes](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
+		}
+		defer tx.Rollback()
+
+		// 读取蓝图，锁定到事务结束，避免创建过程中蓝图被修改
+		var blueprint struct {
+			RoleIDs pq.StringArray `db:"role_ids"`
+			Profile Profile        `db:"profile"`
+		}
+		err = tx.GetContext(ctx, &blueprint, `SELECT role_ids, profile FROM `+r.tables.Blueprint+` WHERE id = $1 FOR SHARE`, req.BlueprintID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return mo.Err[CreateFromBlueprintRes](pkgs.NewApiError(http.StatusNotFound, "蓝图不存在"))
+			}
+			r.log(c).Error("获取蓝图失败", zap.Error(err))
+			return mo.Err[CreateFromBlueprintRes](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
+		}
+
+		// 请求中的个人信息优先，未填写的字段使用蓝图的默认值
+		profile := blueprint.Profile
+		if req.Profile.Email != nil {
+			profile.Email = req.Profile.Email
+		}
+
+		// 创建用户
+		password, apiErr := r.hashPassword(req.Password, "创建用户失败")
+		if apiErr != nil {
+			return mo.Err[CreateFromBlueprintRes](apiErr)
+		}
+		entity := newUserEntity(req.Username, req.Phone, password, profile)
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[CreateFromBlueprintRes](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
+		}
+		columns, values := r.ids.Insert("username", "phone", "phone_hash", "password", "profile", "email_hash")
+		query, args, err := tx.BindNamed(`INSERT INTO `+r.tables.User+` (`+columns+`) VALUES (`+values+`) RETURNING id`, entity)
+		if err == nil {
+			err = tx.GetContext(ctx, &entity.ID, query, args...)
+		}
+		if err != nil {
+			return mo.Err[CreateFromBlueprintRes](pkgs.DBError(r.log(c), err, "创建用户失败"))
+		}
+
+		// 分配蓝图中仍存在的角色
+		res := CreateFromBlueprintRes{ID: entity.ID, RoleIDs: []string{}}
+		if len(blueprint.RoleIDs) > 0 {
+			query := `INSERT INTO ` + r.tables.UserRole + ` (user_id, role_id)
+				SELECT $1, id FROM ` + r.tables.Role + ` WHERE id = ANY($2) RETURNING role_id`
+			if err := tx.SelectContext(ctx, &res.RoleIDs, query, entity.ID, blueprint.RoleIDs); err != nil {
+				r.log(c).Error("分配蓝图角色失败", zap.Error(err))
+				return mo.Err[CreateFromBlueprintRes](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交事务失败", zap.Error(err))
+			return mo.Err[CreateFromBlueprintRes](pkgs.NewApiError(http.StatusInternalServerError, "创建用户失败"))
+		}
+		return mo.Ok(res)
 	}
 }
 
 func (r *Repository) BatchCreate(c *gin.Context) func(*BatchCreateReq) mo.Result[BatchCreateRes] {
 	return func(req *BatchCreateReq) mo.Result[BatchCreateRes] {
-		// 准备批量插入的实体
+		return result.Pipe1(r.batchInsert(c)(req), result.Map(func(entities []UserEntity) BatchCreateRes {
+			createdIDs := make(BatchCreateRes, len(entities))
+			for i, entity := range entities {
+				createdIDs[i] = entity.ID
+			}
+			return createdIDs
+		}))
+	}
+}
+
+// BatchCreateEntity 批量创建用户并返回完整实体列表
+func (r *Repository) BatchCreateEntity(c *gin.Context) func(*BatchCreateReq) mo.Result[BatchCreateEntityRes] {
+	return func(req *BatchCreateReq) mo.Result[BatchCreateEntityRes] {
+		return result.Pipe1(r.batchInsert(c)(req), result.Map(func(entities []UserEntity) BatchCreateEntityRes {
+			items := make(BatchCreateEntityRes, len(entities))
+			for i := range entities {
+				items[i] = toGetByIDRes(c, &entities[i])
+			}
+			return items
+		}))
+	}
+}
+
+// batchInsert 在同一事务内批量插入用户，服务端生成的字段通过 RETURNING 回填到实体
+func (r *Repository) batchInsert(c *gin.Context) func(*BatchCreateReq) mo.Result[[]UserEntity] {
+	return func(req *BatchCreateReq) mo.Result[[]UserEntity] {
+		// 准备批量插入的实体，密码哈希在开启事务前完成，避免长时间占用连接
 		var entities []UserEntity
 		for _, u := range req.Users {
-			entities = append(entities, UserEntity{
-				Username: u.Username,
-				Phone:    &u.Phone,
-				Password: u.Password,
-				Profile:  u.Profile,
-			})
+			password, apiErr := r.hashPassword(u.Password, "批量创建用户失败")
+			if apiErr != nil {
+				return mo.Err[[]UserEntity](apiErr)
+			}
+			entities = append(entities, newUserEntity(u.Username, u.Phone, password, u.Profile))
 		}
 
 		// 开启事务
-		tx, err := r.db.BeginTxx(c.Request.Context(), nil)
+		tx, err := r.batchConn(c).BeginTxx(c.Request.Context(), nil)
 		if err != nil {
-			r.logger.Error("开启事务失败", zap.Error(err))
-			return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建用户失败"))
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[[]UserEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建用户失败"))
 		}
 		defer func() {
 			if p := recover(); p != nil {
@@ -75,66 +264,158 @@ func (r *Repository) BatchCreate(c *gin.Context) func(*BatchCreateReq) mo.Result
 			} else {
 				err = tx.Commit()
 				if err != nil {
-					r.logger.Error("Failed to commit transaction", zap.Error(err))
+					r.log(c).Error("Failed to commit transaction", zap.Error(err))
 					return
 				}
 			}
 		}()
 
 		// 数据库操作
-		query := `INSERT INTO "iacc_user" (username, phone, password, profile) VALUES (:username, :phone, :password, :profile) RETURNING id`
+		columns, values := r.ids.Insert("username", "phone", "phone_hash", "password", "profile", "email_hash")
+		query := `INSERT INTO ` + r.tables.User + ` (` + columns + `) VALUES (` + values + `) RETURNING id, created_at, updated_at`
 		stmt, err := tx.PrepareNamedContext(c.Request.Context(), query)
 		if err != nil {
-			r.logger.Error("准备命名语句失败", zap.Error(err))
-			return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建用户失败"))
+			r.log(c).Error("准备命名语句失败", zap.Error(err))
+			return mo.Err[[]UserEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建用户失败"))
 		}
 		defer stmt.Close()
 
-		var createdIDs []string
-		for _, entity := range entities {
-			var id string
-			err = stmt.GetContext(c.Request.Context(), &id, entity)
+		for i := range entities {
+			if err = r.ids.Assign(&entities[i].ID); err != nil {
+				r.log(c).Error("生成主键失败", zap.Error(err))
+				return mo.Err[[]UserEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建用户失败"))
+			}
+			err = stmt.GetContext(c.Request.Context(), &entities[i], entities[i])
 			if err != nil {
-				r.logger.Error("批量创建用户失败", zap.Error(err))
-				return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建用户失败"))
+				return mo.Err[[]UserEntity](pkgs.DBError(r.log(c), err, "批量创建用户失败"))
 			}
-			createdIDs = append(createdIDs, id)
 		}
 
-		return mo.Ok(BatchCreateRes(createdIDs))
+		return mo.Ok(entities)
 	}
 }
 
 func (r *Repository) GetByID(c *gin.Context) func(*GetByIDReq) mo.Result[GetByIDRes] {
 	return func(req *GetByIDReq) mo.Result[GetByIDRes] {
 
-		// 数据库操作
-		var entity UserEntity
-		query := `SELECT id, username, phone, profile, created_at, updated_at FROM "iacc_user" WHERE id = $1`
-		err := r.db.GetContext(c.Request.Context(), &entity, query, req.ID)
+		// 数据库操作，扩展内容以子查询的形式在同一条查询中返回，每项按上限截断
+		var row struct {
+			UserEntity
+			Roles        []byte `db:"roles"`
+			Permissions  []byte `db:"permissions"`
+			LoginHistory []byte `db:"login_history"`
+		}
+		columns := `u.id, u.username, u.phone, u.profile, u.created_at, u.updated_at`
+		if req.Include.Has(IncludeRoles) {
+			columns += `, COALESCE((
+				SELECT json_agg(x ORDER BY x.created_at DESC, x.seq DESC) FROM (
+					SELECT r.id, r.name, r.description, r.created_at, r.updated_at, r.seq
+					FROM ` + r.tables.UserRole + ` ur INNER JOIN ` + r.tables.Role + ` r ON r.id = ur.role_id
+					WHERE ur.user_id = u.id
+					ORDER BY r.created_at DESC, r.seq DESC
+					LIMIT ` + strconv.Itoa(includeRolesLimit) + `
+				) x
+			), '[]') AS roles`
+		}
+		if req.Include.Has(IncludePermissions) {
+			columns += `, COALESCE((
+				SELECT json_agg(x ORDER BY x.name) FROM (
+					SELECT p.id, p.name, p.type, p.metadata,
+						CASE WHEN bool_or(rp.effect = '` + pkgs.RolePermissionDeny + `') THEN '` + pkgs.RolePermissionDeny + `' ELSE '` + pkgs.RolePermissionAllow + `' END AS effect
+					FROM (` + pkgs.RolePermissionsSQL(r.tables) + `) rp
+					INNER JOIN ` + r.tables.Permission + ` p ON p.id = rp.permission_id
+					WHERE rp.role_id IN (WITH RECURSIVE ` + pkgs.UserRolesCTE(r.tables, "ur.user_id = u.id") + ` SELECT role_id FROM user_roles)
+					GROUP BY p.id
+					ORDER BY p.name
+					LIMIT ` + strconv.Itoa(includePermissionsLimit) + `
+				) x
+			), '[]') AS permissions`
+		}
+		if req.Include.Has(IncludeLoginHistory) {
+			columns += `, COALESCE((
+				SELECT json_agg(x ORDER BY x.last_seen_at DESC) FROM (
+					SELECT d.device_id, d.name, d.user_agent, d.last_ip, d.created_at, d.last_seen_at
+					FROM ` + r.tables.UserDevice + ` d
+					WHERE d.user_id = u.id
+					ORDER BY d.last_seen_at DESC
+					LIMIT ` + strconv.Itoa(includeLoginHistoryLimit) + `
+				) x
+			), '[]') AS login_history`
+		}
+		query := `SELECT ` + columns + ` FROM ` + r.tables.User + ` u WHERE u.id = $1`
+		err := r.conn(c).GetContext(c.Request.Context(), &row, query, req.ID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
 			}
-			r.logger.Error("获取用户失败", zap.Error(err))
-			return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "获取用户失败"))
+			return mo.Err[GetByIDRes](pkgs.DBError(r.log(c), err, "获取用户失败"))
 		}
 
 		// 返回结果
-		phone := ""
-		if entity.Phone != nil {
-			phone = *entity.Phone
+		res := toGetByIDRes(c, &row.UserEntity)
+		if err := decodeIncludes(c, &res, row.Roles, row.Permissions, row.LoginHistory); err != nil {
+			r.log(c).Error("解析用户扩展内容失败", zap.Error(err))
+			return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "获取用户失败"))
+		}
+		return mo.Ok(res)
+	}
+}
+
+// decodeIncludes 解析 json_agg 返回的扩展内容，未请求的扩展内容为 nil，不返回
+func decodeIncludes(c *gin.Context, res *GetByIDRes, roles, permissions, loginHistory []byte) error {
+	if roles != nil {
+		var items []struct {
+			ID          string    `json:"id"`
+			Name        string    `json:"name"`
+			Description *string   `json:"description"`
+			CreatedAt   time.Time `json:"created_at"`
+			UpdatedAt   time.Time `json:"updated_at"`
+		}
+		if err := json.Unmarshal(roles, &items); err != nil {
+			return err
+		}
+		res.Roles = make([]RoleItem, len(items))
+		for i, item := range items {
+			res.Roles[i] = RoleItem{
+				ID:          item.ID,
+				Name:        item.Name,
+				Description: item.Description,
+				CreatedAt:   pkgs.FormatTime(c, item.CreatedAt),
+				UpdatedAt:   pkgs.FormatTime(c, item.UpdatedAt),
+			}
 		}
-		response := GetByIDRes{
-			ID:        entity.ID,
-			Username:  entity.Username,
-			Phone:     phone,
-			Profile:   entity.Profile,
-			CreatedAt: entity.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: entity.UpdatedAt.Format(time.RFC3339),
+	}
+	if permissions != nil {
+		res.Permissions = []PermissionItem{}
+		if err := json.Unmarshal(permissions, &res.Permissions); err != nil {
+			return err
 		}
-		return mo.Ok(response)
 	}
+	if loginHistory != nil {
+		var items []struct {
+			DeviceID   string    `json:"device_id"`
+			Name       *string   `json:"name"`
+			UserAgent  *string   `json:"user_agent"`
+			LastIP     *string   `json:"last_ip"`
+			CreatedAt  time.Time `json:"created_at"`
+			LastSeenAt time.Time `json:"last_seen_at"`
+		}
+		if err := json.Unmarshal(loginHistory, &items); err != nil {
+			return err
+		}
+		res.LoginHistory = make([]LoginItem, len(items))
+		for i, item := range items {
+			res.LoginHistory[i] = LoginItem{
+				DeviceID:     item.DeviceID,
+				Name:         item.Name,
+				UserAgent:    item.UserAgent,
+				LastIP:       item.LastIP,
+				FirstLoginAt: pkgs.FormatTime(c, item.CreatedAt),
+				LastSeenAt:   pkgs.FormatTime(c, item.LastSeenAt),
+			}
+		}
+	}
+	return nil
 }
 
 func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[UpdateByIDRes] {
@@ -147,17 +428,31 @@ func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[U
 			params["username"] = *req.Username
 			setClauses = append(setClauses, "username = :username")
 		}
-		if req.Phone != nil {
-			params["phone"] = *req.Phone
-			setClauses = append(setClauses, "phone = :phone")
+		if req.Phone.IsSet() {
+			if phone, ok := req.Phone.Get(); ok {
+				params["phone"] = pkgs.EncryptedString(phone)
+				params["phone_hash"] = pkgs.BlindIndex(phone)
+			} else {
+				params["phone"], params["phone_hash"] = nil, nil
+			}
+			setClauses = append(setClauses, "phone = :phone", "phone_hash = :phone_hash")
 		}
 		if req.Password != nil {
-			params["password"] = *req.Password
+			password, apiErr := r.hashPassword(*req.Password, "更新用户失败")
+			if apiErr != nil {
+				return mo.Err[UpdateByIDRes](apiErr)
+			}
+			params["password"] = password
 			setClauses = append(setClauses, "password = :password")
 		}
-		if req.Profile != nil {
-			params["profile"] = *req.Profile
-			setClauses = append(setClauses, "profile = :profile")
+		if req.Profile.IsSet() {
+			if profile, ok := req.Profile.Get(); ok {
+				params["profile"] = profile
+				params["email_hash"] = profile.EmailHash()
+			} else {
+				params["profile"], params["email_hash"] = nil, nil
+			}
+			setClauses = append(setClauses, "profile = :profile", "email_hash = :email_hash")
 		}
 
 		// 如果没有需要更新的字段，直接返回成功
@@ -166,17 +461,16 @@ func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[U
 		}
 
 		setClauses = append(setClauses, "updated_at = CURRENT_TIMESTAMP")
-		query := "UPDATE \"iacc_user\" SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		query := "UPDATE " + r.tables.User + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
 
 		// 执行数据库操作
-		res, err := r.db.NamedExecContext(c.Request.Context(), query, params)
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
 		if err != nil {
-			r.logger.Error("更新用户失败", zap.Error(err))
-			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新用户失败"))
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新用户失败"))
 		}
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
 			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新用户失败"))
 		}
 		// 返回结果
@@ -184,18 +478,176 @@ func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[U
 	}
 }
 
+// PatchByID 按 JSON Merge Patch 部分更新用户
+// 显式 null 的可空字段（phone、profile）会被清空；profile 为对象时与已存储的个人信息递归合并，
+// 由于邮箱加密存储，合并在事务内读取当前值后完成，同时重新计算影子列。
+func (r *Repository) PatchByID(c *gin.Context) func(*PatchByIDReq) mo.Result[PatchByIDRes] {
+	return func(req *PatchByIDReq) mo.Result[PatchByIDRes] {
+		// 动态构建更新语句，只处理补丁中出现的字段
+		params := map[string]any{"id": req.ID}
+		var setClauses []string
+
+		if req.Has("username") {
+			params["username"] = *req.Username
+			setClauses = append(setClauses, "username = :username")
+		}
+		if req.Has("phone") {
+			if req.Phone != nil {
+				params["phone"] = pkgs.EncryptedString(*req.Phone)
+				params["phone_hash"] = pkgs.BlindIndex(*req.Phone)
+			} else {
+				params["phone"], params["phone_hash"] = nil, nil
+			}
+			setClauses = append(setClauses, "phone = :phone", "phone_hash = :phone_hash")
+		}
+		if req.Has("password") {
+			password, apiErr := r.hashPassword(*req.Password, "更新用户失败")
+			if apiErr != nil {
+				return mo.Err[PatchByIDRes](apiErr)
+			}
+			params["password"] = password
+			setClauses = append(setClauses, "password = :password")
+		}
+		if req.IsNull("profile") {
+			params["profile"], params["email_hash"] = nil, nil
+			setClauses = append(setClauses, "profile = :profile", "email_hash = :email_hash")
+		}
+
+		// 如果没有需要更新的字段，直接返回成功
+		if len(setClauses) == 0 && !req.Has("profile") {
+			return mo.Ok(PatchByIDRes(0))
+		}
+
+		// 开启事务
+		tx, err := r.conn(c).BeginTxx(c.Request.Context(), nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[PatchByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新用户失败"))
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+			if err != nil {
+				tx.Rollback()
+			} else {
+				err = tx.Commit()
+				if err != nil {
+					r.log(c).Error("Failed to commit transaction", zap.Error(err))
+					return
+				}
+			}
+		}()
+
+		if req.Has("profile") && !req.IsNull("profile") {
+			// 锁定并读取当前个人信息，与补丁合并
+			var current Profile
+			err = tx.GetContext(c.Request.Context(), &current, `SELECT profile FROM `+r.tables.User+` WHERE id = $1 FOR UPDATE`, req.ID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return mo.Err[PatchByIDRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
+				}
+				r.log(c).Error("读取用户个人信息失败", zap.Error(err))
+				return mo.Err[PatchByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新用户失败"))
+			}
+			var merged Profile
+			merged, err = mergeProfile(current, req.Raw("profile"))
+			if err != nil {
+				r.log(c).Error("合并用户个人信息失败", zap.Error(err))
+				return mo.Err[PatchByIDRes](pkgs.NewApiError(http.StatusBadRequest, "个人信息格式错误"))
+			}
+			params["profile"] = merged
+			params["email_hash"] = merged.EmailHash()
+			setClauses = append(setClauses, "profile = :profile", "email_hash = :email_hash")
+		}
+
+		setClauses = append(setClauses, "updated_at = CURRENT_TIMESTAMP")
+		query := "UPDATE " + r.tables.User + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+
+		// 执行数据库操作
+		res, err := tx.NamedExecContext(c.Request.Context(), query, params)
+		if err != nil {
+			return mo.Err[PatchByIDRes](pkgs.DBError(r.log(c), err, "更新用户失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[PatchByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新用户失败"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// PatchProfile 在数据库内合并更新个人信息，无需先读取整个 profile
+// set 通过 || 合并进已存储的 JSONB，delete 中的字段通过 - 删除；邮箱在写入前加密并同步影子列
+func (r *Repository) PatchProfile(c *gin.Context) func(*PatchProfileReq) mo.Result[PatchProfileRes] {
+	return func(req *PatchProfileReq) mo.Result[PatchProfileRes] {
+		// 没有需要合并或删除的字段，直接返回成功
+		if req.Set == (Profile{}) && len(req.Delete) == 0 {
+			return mo.Ok(PatchProfileRes(0))
+		}
+
+		params := map[string]any{
+			"id":     req.ID,
+			"set":    req.Set,
+			"delete": pq.StringArray(req.Delete),
+		}
+		setClauses := []string{"profile = (COALESCE(profile, '{}'::jsonb) || CAST(:set AS jsonb)) - CAST(:delete AS text[])"}
+		if req.Set.Email != nil {
+			params["email_hash"] = req.Set.EmailHash()
+			setClauses = append(setClauses, "email_hash = :email_hash")
+		} else if slices.Contains(req.Delete, "email") {
+			setClauses = append(setClauses, "email_hash = NULL")
+		}
+		setClauses = append(setClauses, "updated_at = CURRENT_TIMESTAMP")
+		query := "UPDATE " + r.tables.User + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+
+		// 执行数据库操作
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
+		if err != nil {
+			return mo.Err[PatchProfileRes](pkgs.DBError(r.log(c), err, "更新用户个人信息失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[PatchProfileRes](pkgs.NewApiError(http.StatusInternalServerError, "更新用户个人信息失败"))
+		}
+		if affectedRows == 0 {
+			return mo.Err[PatchProfileRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// mergeProfile 按 RFC 7386 将补丁合并到个人信息
+func mergeProfile(current Profile, patch []byte) (Profile, error) {
+	var merged Profile
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return merged, err
+	}
+	raw, err = pkgs.ApplyMergePatch(raw, patch)
+	if err != nil {
+		return merged, err
+	}
+	err = json.Unmarshal(raw, &merged)
+	return merged, err
+}
+
 func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
 	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
 		// 数据库操作
-		query := `DELETE FROM "iacc_user" WHERE id = $1`
-		res, err := r.db.ExecContext(c.Request.Context(), query, req.ID)
+		query := `DELETE FROM ` + r.tables.User + ` WHERE id = $1`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID)
 		if err != nil {
-			r.logger.Error("删除用户失败", zap.Error(err))
-			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除用户失败"))
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除用户失败"))
 		}
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
 			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除用户失败"))
 		}
 
@@ -206,23 +658,15 @@ func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[D
 
 func (r *Repository) BatchDelete(c *gin.Context) func(*DeleteUsersReq) mo.Result[BatchDeleteRes] {
 	return func(req *DeleteUsersReq) mo.Result[BatchDeleteRes] {
-		query, args, err := sqlx.In(`DELETE FROM "iacc_user" WHERE id IN (?)`, req.IDs)
-		if err != nil {
-			r.logger.Error("构建批量删除查询失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "构建批量删除查询失败"))
-		}
-
-		query = r.db.Rebind(query)
-		res, err := r.db.ExecContext(c.Request.Context(), query, args...)
+		query := `DELETE FROM ` + r.tables.User + ` WHERE id = ANY($1)`
+		res, err := r.batchConn(c).ExecContext(c.Request.Context(), query, pkgs.PGArray(req.IDs))
 		if err != nil {
-			r.logger.Error("批量删除用户失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "批量删除用户失败"))
+			return mo.Err[BatchDeleteRes](pkgs.DBError(r.log(c), err, "批量删除用户失败"))
 		}
 
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "获取影响行数失败"))
+			return mo.Err[BatchDeleteRes](pkgs.DBError(r.log(c), err, "获取影响行数失败"))
 		}
 
 		return mo.Ok(affectedRows)
@@ -231,126 +675,564 @@ func (r *Repository) BatchDelete(c *gin.Context) func(*DeleteUsersReq) mo.Result
 
 func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
 	return func(req *QueryListReq) mo.Result[QueryListRes] {
-		// 校验排序字段
-		validOrderBy := map[string]bool{
-			"id":         true,
-			"username":   true,
-			"phone":      true,
-			"created_at": true,
-			"updated_at": true,
-		}
-		if !validOrderBy[req.OrderBy] {
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "排序字段不存在"))
-		}
-
-		// 校验排序顺序
-		upperOrder := strings.ToUpper(req.Order)
-		if upperOrder != "ASC" && upperOrder != "DESC" {
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "排序顺序参数错误"))
-		}
+		// 排序字段与排序顺序已在请求参数中校验
+		upperOrder := pkgs.SortDirection(req.Order)
 
 		// 构建查询
 		params := map[string]any{
 			"limit":  req.PageSize,
-			"offset": (req.Page - 1) * req.PageSize,
-		}
-
-		var whereClauses []string
-		if req.Phone != "" {
-			whereClauses = append(whereClauses, "phone ILIKE :phone")
-			params["phone"] = "%" + req.Phone + "%"
-		}
-		if req.Username != "" {
-			whereClauses = append(whereClauses, "username ILIKE :username")
-			params["username"] = "%" + req.Username + "%"
+			"offset": req.Offset(),
 		}
 
-		whereCondition := ""
-		if len(whereClauses) > 0 {
-			whereCondition = " WHERE " + strings.Join(whereClauses, " AND ")
-		}
-
-		// 查询总数
-		var total int64
-		countQuery := "SELECT count(*) FROM \"iacc_user\"" + whereCondition
-		// 使用 NamedQuery 而不是 PrepareNamed
-		rows, err := r.db.NamedQueryContext(c.Request.Context(), countQuery, params)
-		if err != nil {
-			r.logger.Error("准备命名计数查询失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户列表失败"))
-		}
-		defer rows.Close()
-
-		if rows.Next() {
-			err = rows.Scan(&total)
-		}
+		whereCondition := r.listWhere(c, &req.ListFilter, params)
+		source, roleNames := r.listSource()
+		refreshedAt, err := r.searchRefreshedAt(c)
 		if err != nil {
-			r.logger.Error("统计用户数量失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户列表失败"))
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询用户列表失败"))
 		}
 
-		if total == 0 {
-			return mo.Ok(QueryListRes{
-				List:  []UserItem{},
-				Total: 0,
-			})
-		}
-
-		// 查询列表
-		var entities []UserEntity
-		listQuery := `SELECT id, username, phone, profile, created_at, updated_at FROM "iacc_user"` + whereCondition + ` ORDER BY ` + req.OrderBy + ` ` + upperOrder + ` LIMIT :limit OFFSET :offset`
-		// 使用 NamedQuery 而不是 PrepareNamed
-		rows, err = r.db.NamedQueryContext(c.Request.Context(), listQuery, params)
-		if err != nil {
-			r.logger.Error("准备命名列表查询失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户列表失败"))
-		}
-		defer rows.Close()
-
-		for rows.Next() {
-			var entity UserEntity
-			err = rows.StructScan(&entity)
+		// 排序与分页
+		// seq 作为决胜字段，保证排序字段值相同时（如批量插入的 created_at）分页顺序稳定
+		orderClause := ` ORDER BY ` + req.OrderBy + ` ` + upperOrder + `, seq ` + upperOrder + ` LIMIT :limit OFFSET :offset`
+		var total int64
+		if req.CursorPagination.Enabled() {
+			// 游标分页不统计总数
+			whereCondition, orderClause = req.CursorPagination.Keyset(whereCondition, upperOrder, params)
+		} else {
+			// 查询总数
+			total, err = r.count(c, r.conn(c), source, whereCondition, params)
 			if err != nil {
-				r.logger.Error("扫描行数据失败", zap.Error(err))
+				r.log(c).Error("统计用户数量失败", zap.Error(err))
 				return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户列表失败"))
 			}
-			entities = append(entities, entity)
+
+			if total == 0 {
+				return mo.Ok(QueryListRes{
+					List:        []UserItem{},
+					Total:       0,
+					RefreshedAt: refreshedAt,
+				})
+			}
 		}
+
+		// 查询列表
+		listQuery := `SELECT id, username, phone, profile, created_at, updated_at, seq, ` + roleNames + ` AS role_names FROM ` + source + whereCondition + orderClause
+		// 逐行扫描前检查请求是否已取消，并检查遍历是否中途失败
+		entities, err := pkgs.NamedQueryAll[userListEntity](c.Request.Context(), r.conn(c), listQuery, params)
 		if err != nil {
-			r.logger.Error("查询用户列表失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户列表失败"))
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询用户列表失败"))
 		}
+		entities, nextCursor := pkgs.CursorPage(req.CursorPagination, entities, func(e userListEntity) (time.Time, int64) { return e.CreatedAt, e.Seq })
 
 		// 转换并返回结果
 		var responseEntities []UserItem
 		for _, entity := range entities {
 			phone := ""
 			if entity.Phone != nil {
-				phone = *entity.Phone
+				phone = string(*entity.Phone)
+			}
+			roleNames := []string(entity.RoleNames)
+			if roleNames == nil {
+				roleNames = []string{}
 			}
 			responseEntities = append(responseEntities, UserItem{
 				ID:        entity.ID,
 				Username:  entity.Username,
 				Phone:     phone,
 				Profile:   entity.Profile,
-				CreatedAt: entity.CreatedAt.Format(time.RFC3339),
-				UpdatedAt: entity.UpdatedAt.Format(time.RFC3339),
+				RoleNames: roleNames,
+				CreatedAt: pkgs.FormatTime(c, entity.CreatedAt),
+				UpdatedAt: pkgs.FormatTime(c, entity.UpdatedAt),
 			})
 		}
 
 		return mo.Ok(QueryListRes{
-			List:  responseEntities,
-			Total: total,
+			List:        responseEntities,
+			Total:       total,
+			NextCursor:  nextCursor,
+			RefreshedAt: refreshedAt,
 		})
 	}
 }
 
+// Export 按列表的筛选条件与排序生成导出查询，不分页
+func (r *Repository) Export(c *gin.Context) func(*ExportReq) mo.Result[*ExportPlan] {
+	return func(req *ExportReq) mo.Result[*ExportPlan] {
+		params := map[string]any{}
+		whereCondition := r.listWhere(c, &req.ListFilter, params)
+		source, roleNames := r.listSource()
+		upperOrder := pkgs.SortDirection(req.Order)
+		query, args, err := r.batchConn(c).BindNamed(`SELECT id, username, phone, profile, disabled_at, created_at, updated_at, `+roleNames+` AS role_names
+			FROM `+source+whereCondition+` ORDER BY `+req.OrderBy+` `+upperOrder+`, seq `+upperOrder, params)
+		if err != nil {
+			r.log(c).Error("构建用户导出查询失败", zap.Error(err))
+			return mo.Err[*ExportPlan](pkgs.NewApiError(http.StatusInternalServerError, "导出用户失败"))
+		}
+		return mo.Ok(&ExportPlan{Format: req.Format, Query: query, Args: args})
+	}
+}
+
+// exportRows 执行导出查询，导出耗时较长，使用批处理连接池
+func (r *Repository) exportRows(c *gin.Context, plan *ExportPlan) (*sqlx.Rows, error) {
+	return r.batchConn(c).QueryxContext(c.Request.Context(), plan.Query, plan.Args...)
+}
+
+// writeExport 逐行写出导出文件，返回写出的行数；时间按请求时区格式化，与列表接口一致
+// 每写出 exportFlushRows 行刷新一次，内存占用与导出的行数无关。
+func (r *Repository) writeExport(c *gin.Context, rows *sqlx.Rows, plan *ExportPlan, w io.Writer) (int64, error) {
+	defer rows.Close()
+	rw, err := pkgs.NewRowWriter(plan.Format, w)
+	if err != nil {
+		return 0, err
+	}
+	if err := rw.Write(exportColumns); err != nil {
+		return 0, err
+	}
+	var count int64
+	for rows.Next() {
+		var entity userExportEntity
+		if err := rows.StructScan(&entity); err != nil {
+			return count, err
+		}
+		phone, email, disabledAt := "", "", ""
+		if entity.Phone != nil {
+			phone = string(*entity.Phone)
+		}
+		if entity.Profile.Email != nil {
+			email = *entity.Profile.Email
+		}
+		if entity.DisabledAt != nil {
+			disabledAt = pkgs.FormatTime(c, *entity.DisabledAt)
+		}
+		if plan.Mask && phone != "" {
+			phone = pkgs.MaskPhone(phone)
+		}
+		if plan.Mask && email != "" {
+			email = pkgs.MaskEmail(email)
+		}
+		record := []string{
+			entity.ID,
+			entity.Username,
+			phone,
+			email,
+			strings.Join(entity.RoleNames, ", "),
+			disabledAt,
+			pkgs.FormatTime(c, entity.CreatedAt),
+			pkgs.FormatTime(c, entity.UpdatedAt),
+		}
+		if err := rw.Write(record); err != nil {
+			return count, err
+		}
+		count++
+		if count%exportFlushRows == 0 {
+			if err := rw.Flush(); err != nil {
+				return count, err
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, rw.Close()
+}
+
+// Import 导入通过校验的行
+// atomic 方式下校验阶段已有被拒绝的行时不再写入，直接返回报告；通过校验的行数超过 user_import.sync_rows 时
+// 写入异步导入任务，以 202 业务码结束请求，任务完成后通过 GET /user/import/{id} 查询导入报告。
+func (r *Repository) Import(c *gin.Context) func(*ImportPlan) mo.Result[ImportRes] {
+	return func(plan *ImportPlan) mo.Result[ImportRes] {
+		if plan.Mode == ImportModeAtomic && len(plan.Rejected) > 0 {
+			return mo.Ok(ImportRes{Mode: plan.Mode, Total: plan.Total, Accepted: []ImportAccepted{}, Rejected: plan.Rejected})
+		}
+		if len(plan.Rows) > r.importSyncRows {
+			return r.enqueueImport(c, plan)
+		}
+		res, apiErr := r.importRows(c.Request.Context(), r.batchConn(c), plan, pkgs.RequestLocale(c), r.log(c))
+		if apiErr != nil {
+			return mo.Err[ImportRes](apiErr)
+		}
+		return mo.Ok(res)
+	}
+}
+
+// enqueueImport 写入异步导入任务，启用字段加密时任务参数中的密码加密保存
+func (r *Repository) enqueueImport(c *gin.Context, plan *ImportPlan) mo.Result[ImportRes] {
+	payload := ImportJobPayload{
+		RequestedBy: pkgs.CurrentUserID(c),
+		Locale:      pkgs.RequestLocale(c),
+		Mode:        plan.Mode,
+		Total:       plan.Total,
+		Rows:        make([]ImportJobRow, len(plan.Rows)),
+		Rejected:    plan.Rejected,
+	}
+	for i, row := range plan.Rows {
+		password, err := pkgs.EncryptField(row.Password)
+		if err != nil {
+			r.log(c).Error("加密密码失败", zap.Error(err))
+			return mo.Err[ImportRes](pkgs.NewApiError(http.StatusInternalServerError, "导入用户失败"))
+		}
+		payload.Rows[i] = ImportJobRow{Line: row.Line, Username: row.Username, Phone: row.Phone, Password: password, Profile: row.Profile}
+	}
+	jobID, err := r.jobs.Enqueue(c, JobTypeImport, payload)
+	if err != nil {
+		r.log(c).Error("写入导入任务失败", zap.Error(err))
+		return mo.Err[ImportRes](pkgs.NewApiError(http.StatusInternalServerError, "导入用户失败"))
+	}
+	return mo.Err[ImportRes](&pkgs.ApiError{
+		Code:    http.StatusAccepted,
+		Message: "导入的行数较多，已转为异步导入",
+		Data:    ImportJobRes{JobID: jobID, Rows: len(plan.Rows)},
+	})
+}
+
+// runImport 执行异步导入：导入任务参数中的行，为导入成功的用户写入审计日志，并把任务参数替换为导入报告
+// 任务已有报告时（上次执行已完成但未能更新任务状态）直接结束；重新执行时已导入的行因用户名已存在被拒绝。
+func (r *Repository) runImport(ctx context.Context, db *sqlx.DB, job *pkgs.Job, logger *zap.Logger) error {
+	var payload ImportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+	if payload.Report != nil {
+		return nil
+	}
+	plan := &ImportPlan{Mode: payload.Mode, Total: payload.Total, Rows: make([]ImportRow, len(payload.Rows)), Rejected: payload.Rejected}
+	for i, row := range payload.Rows {
+		password, err := pkgs.DecryptField(row.Password)
+		if err != nil {
+			return fmt.Errorf("解密密码失败: %w", err)
+		}
+		plan.Rows[i] = ImportRow{Line: row.Line, CreateReq: CreateReq{Username: row.Username, Phone: row.Phone, Password: password, Profile: row.Profile}}
+	}
+	if plan.Rejected == nil {
+		plan.Rejected = []ImportRejected{}
+	}
+
+	res, apiErr := r.importRows(ctx, db, plan, payload.Locale, logger)
+	if apiErr != nil {
+		return apiErr
+	}
+	for _, id := range res.ids() {
+		if err := r.audit.RecordJob(ctx, db, job, payload.RequestedBy, "import", pkgs.AuditEntityUser, id); err != nil {
+			logger.Error("写入审计日志失败", zap.String("entity_id", id), zap.Error(err))
+		}
+	}
+	report, err := json.Marshal(ImportJobPayload{RequestedBy: payload.RequestedBy, Locale: payload.Locale, Mode: payload.Mode, Total: payload.Total, Report: &res})
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE `+r.tables.AsyncJob+` SET payload = $1 WHERE id = $2`, report, job.ID); err != nil {
+		return fmt.Errorf("写入导入报告失败: %w", err)
+	}
+	logger.Info("用户已导入", zap.Int("accepted", len(res.Accepted)), zap.Int("rejected", len(res.Rejected)))
+	return nil
+}
+
+// importRows 在一个事务内逐行写入通过校验的行，每行使用保存点，写入失败（如用户名已存在）的行被拒绝，原因按 locale 生成
+// atomic 方式下存在被拒绝的行时回滚事务，仍返回完整的报告；row 方式提交写入成功的行。
+func (r *Repository) importRows(ctx context.Context, db *sqlx.DB, plan *ImportPlan, locale string, logger *zap.Logger) (ImportRes, *pkgs.ApiError) {
+	res := ImportRes{Mode: plan.Mode, Total: plan.Total, Accepted: []ImportAccepted{}, Rejected: plan.Rejected}
+
+	// 密码哈希在开启事务前完成，避免长时间占用连接
+	entities := make([]UserEntity, len(plan.Rows))
+	for i, row := range plan.Rows {
+		password, apiErr := r.hashPassword(row.Password, "导入用户失败")
+		if apiErr != nil {
+			return res, apiErr
+		}
+		entities[i] = newUserEntity(row.Username, row.Phone, password, row.Profile)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Error("开启事务失败", zap.Error(err))
+		return res, pkgs.NewApiError(http.StatusInternalServerError, "导入用户失败")
+	}
+	defer tx.Rollback()
+
+	columns, values := r.ids.Insert("username", "phone", "phone_hash", "password", "profile", "email_hash")
+	query := `INSERT INTO ` + r.tables.User + ` (` + columns + `) VALUES (` + values + `) RETURNING id, created_at, updated_at`
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		logger.Error("准备命名语句失败", zap.Error(err))
+		return res, pkgs.NewApiError(http.StatusInternalServerError, "导入用户失败")
+	}
+	defer stmt.Close()
+
+	for i, row := range plan.Rows {
+		if err := r.ids.Assign(&entities[i].ID); err != nil {
+			logger.Error("生成主键失败", zap.Error(err))
+			return res, pkgs.NewApiError(http.StatusInternalServerError, "导入用户失败")
+		}
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT import_row"); err != nil {
+			return res, pkgs.DBError(logger, err, "导入用户失败")
+		}
+		if err := stmt.GetContext(ctx, &entities[i], entities[i]); err != nil {
+			// 只有请求数据引起的错误（唯一约束等）拒绝该行，其他错误中止导入
+			apiErr := pkgs.DBError(logger, err, "导入用户失败")
+			if apiErr.Code != http.StatusBadRequest && apiErr.Code != http.StatusConflict {
+				return res, apiErr
+			}
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_row"); err != nil {
+				return res, pkgs.DBError(logger, err, "导入用户失败")
+			}
+			res.Rejected = append(res.Rejected, ImportRejected{Line: row.Line, Username: row.Username, Reason: pkgs.LocalizeError(locale, apiErr)})
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_row"); err != nil {
+			return res, pkgs.DBError(logger, err, "导入用户失败")
+		}
+		res.Accepted = append(res.Accepted, ImportAccepted{Line: row.Line, ID: entities[i].ID})
+	}
+	slices.SortFunc(res.Rejected, func(a, b ImportRejected) int { return a.Line - b.Line })
+
+	// atomic 方式下存在被拒绝的行时不提交，事务在返回时回滚
+	if plan.Mode == ImportModeAtomic && len(res.Rejected) > 0 {
+		res.Accepted = []ImportAccepted{}
+		return res, nil
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Error("提交事务失败", zap.Error(err))
+		return res, pkgs.NewApiError(http.StatusInternalServerError, "导入用户失败")
+	}
+	return res, nil
+}
+
+// GetImportJob 查询当前用户发起的异步导入，只能查询自己发起的任务
+func (r *Repository) GetImportJob(c *gin.Context) func(*ImportJobReq) mo.Result[GetImportJobRes] {
+	return func(req *ImportJobReq) mo.Result[GetImportJobRes] {
+		var job pkgs.Job
+		query := `SELECT ` + pkgs.JobColumns + ` FROM ` + r.tables.AsyncJob + ` WHERE id = $1 AND type = $2`
+		if err := r.conn(c).GetContext(c.Request.Context(), &job, query, req.ID, JobTypeImport); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[GetImportJobRes](pkgs.NewApiError(http.StatusNotFound, "导入任务不存在"))
+			}
+			return mo.Err[GetImportJobRes](pkgs.DBError(r.log(c), err, "查询导入任务失败"))
+		}
+		var payload ImportJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.RequestedBy != pkgs.CurrentUserID(c) {
+			return mo.Err[GetImportJobRes](pkgs.NewApiError(http.StatusNotFound, "导入任务不存在"))
+		}
+		return mo.Ok(GetImportJobRes{
+			JobID:      job.ID,
+			Status:     job.Status,
+			Error:      job.LastError,
+			Report:     payload.Report,
+			CreatedAt:  pkgs.FormatTime(c, job.CreatedAt),
+			FinishedAt: pkgs.FormatTimePtr(c, job.FinishedAt),
+		})
+	}
+}
+
+// CheckModified 列表的条件请求：用户表（开启物化视图时为视图）自上次请求后没有变化时返回 304
+func (r *Repository) CheckModified(c *gin.Context) func(*QueryListReq) mo.Result[*QueryListReq] {
+	source, _ := r.listSource()
+	return pkgs.CheckModified[QueryListReq](c, r.conn(c), source)
+}
+
+// listSource 返回列表查询的数据源与角色名称的查询表达式
+// 开启物化视图时查询视图中预先聚合的角色名称，否则实时关联用户角色与角色表。
+func (r *Repository) listSource() (string, string) {
+	if r.search.Enabled {
+		return r.tables.UserSearch, "role_names"
+	}
+	roleNames := `COALESCE((SELECT array_agg(ro.name ORDER BY ro.name) FROM ` + r.tables.UserRole + ` ur
+		JOIN ` + r.tables.Role + ` ro ON ro.id = ur.role_id WHERE ur.user_id = u.id), '{}')`
+	return r.tables.User + " u", roleNames
+}
+
+// searchRefreshedAt 返回物化视图的刷新时间，未开启物化视图或视图为空时返回空字符串
+func (r *Repository) searchRefreshedAt(c *gin.Context) (string, error) {
+	if !r.search.Enabled {
+		return "", nil
+	}
+	var refreshedAt time.Time
+	err := r.conn(c).GetContext(c.Request.Context(), &refreshedAt, `SELECT refreshed_at FROM `+r.tables.UserSearch+` LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return pkgs.FormatTime(c, refreshedAt), nil
+}
+
+// RefreshSearch 定时任务：逐个 schema 并发刷新用户列表的物化视图，刷新期间列表仍可查询旧的快照
+func (r *Repository) RefreshSearch(ctx context.Context) {
+	for tenant, db := range r.pool.BatchDBs(ctx, r.logger) {
+		if _, err := db.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY `+r.tables.UserSearch); err != nil {
+			r.logger.Error("刷新用户列表物化视图失败", zap.String("tenant", tenant), zap.Error(err))
+		}
+	}
+}
+
+// Count 统计满足筛选条件的用户数量，筛选条件与列表接口相同，在报表连接上查询
+func (r *Repository) Count(c *gin.Context) func(*CountReq) mo.Result[CountRes] {
+	return func(req *CountReq) mo.Result[CountRes] {
+		params := map[string]any{}
+		db, tables := r.reportConn(c)
+		total, err := r.count(c, db, tables.User, r.listWhere(c, req, params), params)
+		if err != nil {
+			return mo.Err[CountRes](pkgs.DBError(r.log(c), err, "统计用户数量失败"))
+		}
+		return mo.Ok(total)
+	}
+}
+
+// Exists 检查是否存在同时满足全部条件的用户，条件均为精确匹配
+func (r *Repository) Exists(c *gin.Context) func(*ExistsReq) mo.Result[ExistsRes] {
+	return func(req *ExistsReq) mo.Result[ExistsRes] {
+		params := map[string]any{}
+		var whereClauses []string
+		encrypted := pkgs.FieldEncryptionEnabled()
+		if req.Phone != "" {
+			if encrypted {
+				whereClauses = append(whereClauses, "phone_hash = :phone_hash")
+				params["phone_hash"] = pkgs.BlindIndex(req.Phone)
+			} else {
+				whereClauses = append(whereClauses, "phone = :phone")
+				params["phone"] = req.Phone
+			}
+		}
+		if req.Email != "" {
+			if encrypted {
+				whereClauses = append(whereClauses, "email_hash = :email_hash")
+				params["email_hash"] = pkgs.EmailBlindIndex(req.Email)
+			} else {
+				whereClauses = append(whereClauses, "lower(profile->>'email') = lower(:email)")
+				params["email"] = req.Email
+			}
+		}
+		if req.Username != "" {
+			whereClauses = append(whereClauses, "username = :username")
+			params["username"] = req.Username
+		}
+
+		query, args, err := r.conn(c).BindNamed(`SELECT EXISTS (SELECT 1 FROM `+r.tables.User+` WHERE `+strings.Join(whereClauses, " AND ")+`)`, params)
+		if err != nil {
+			r.log(c).Error("构建用户存在性查询失败", zap.Error(err))
+			return mo.Err[ExistsRes](pkgs.NewApiError(http.StatusInternalServerError, "检查用户是否存在失败"))
+		}
+		var exists bool
+		if err := r.conn(c).GetContext(c.Request.Context(), &exists, query, args...); err != nil {
+			return mo.Err[ExistsRes](pkgs.DBError(r.log(c), err, "检查用户是否存在失败"))
+		}
+		return mo.Ok(exists)
+	}
+}
+
+// CheckAvailability 在一次查询中检查一批用户名与手机号是否已被使用，用户名与手机号（或其影子列）均有唯一索引
+func (r *Repository) CheckAvailability(c *gin.Context) func(*CheckAvailabilityReq) mo.Result[CheckAvailabilityRes] {
+	return func(req *CheckAvailabilityReq) mo.Result[CheckAvailabilityRes] {
+		// 启用字段加密后手机号为密文，按影子列匹配
+		phoneColumn, phoneKeys := "phone", req.Phones
+		if pkgs.FieldEncryptionEnabled() {
+			phoneColumn, phoneKeys = "phone_hash", make([]string, len(req.Phones))
+			for i, phone := range req.Phones {
+				phoneKeys[i] = pkgs.BlindIndex(phone)
+			}
+		}
+
+		query := `
+			SELECT 'username' AS kind, username AS value FROM ` + r.tables.User + ` WHERE username = ANY($1)
+			UNION ALL
+			SELECT 'phone' AS kind, ` + phoneColumn + ` AS value FROM ` + r.tables.User + ` WHERE ` + phoneColumn + ` = ANY($2)
+		`
+		rows, err := pkgs.QueryAll[struct {
+			Kind  string `db:"kind"`
+			Value string `db:"value"`
+		}](c.Request.Context(), r.conn(c), query, pkgs.PGArray(req.Usernames), pkgs.PGArray(phoneKeys))
+		if err != nil {
+			return mo.Err[CheckAvailabilityRes](pkgs.DBError(r.log(c), err, "检查可用性失败"))
+		}
+		takenUsernames, takenPhones := map[string]bool{}, map[string]bool{}
+		for _, row := range rows {
+			if row.Kind == "username" {
+				takenUsernames[row.Value] = true
+			} else {
+				takenPhones[row.Value] = true
+			}
+		}
+
+		return mo.Ok(CheckAvailabilityRes{
+			Usernames: availability(req.Usernames, req.Usernames, takenUsernames),
+			Phones:    availability(req.Phones, phoneKeys, takenPhones),
+		})
+	}
+}
+
+// availability 按请求顺序返回每一项的可用性，keys 为与 values 一一对应的查询值
+func availability(values, keys []string, taken map[string]bool) []AvailabilityItem {
+	items := make([]AvailabilityItem, len(values))
+	seen := map[string]bool{}
+	for i, value := range values {
+		items[i] = AvailabilityItem{Value: value, Available: true}
+		switch {
+		case taken[keys[i]]:
+			items[i].Available, items[i].Reason = false, AvailabilityTaken
+		case seen[value]:
+			items[i].Available, items[i].Reason = false, AvailabilityDuplicate
+		}
+		seen[value] = true
+	}
+	return items
+}
+
+// listWhere 构建列表与计数接口共用的查询条件，返回 WHERE 子句，没有条件时为空
+func (r *Repository) listWhere(c *gin.Context, filter *ListFilter, params map[string]any) string {
+	var whereClauses []string
+	// 启用加密后密文无法模糊匹配，手机号、邮箱改为通过影子列精确匹配
+	encrypted := pkgs.FieldEncryptionEnabled()
+	if filter.Phone != "" {
+		if encrypted {
+			whereClauses = append(whereClauses, "phone_hash = :phone_hash")
+			params["phone_hash"] = pkgs.BlindIndex(filter.Phone)
+		} else {
+			whereClauses = append(whereClauses, "phone ILIKE :phone")
+			params["phone"] = "%" + filter.Phone + "%"
+		}
+	}
+	if filter.Email != "" {
+		if encrypted {
+			whereClauses = append(whereClauses, "email_hash = :email_hash")
+			params["email_hash"] = pkgs.EmailBlindIndex(filter.Email)
+		} else {
+			whereClauses = append(whereClauses, "lower(profile->>'email') = lower(:email)")
+			params["email"] = filter.Email
+		}
+	}
+	if filter.Username != "" {
+		whereClauses = append(whereClauses, "username ILIKE :username")
+		params["username"] = "%" + filter.Username + "%"
+	}
+	whereClauses = append(whereClauses, filter.DateRange.Where(c, params)...)
+	whereClauses = append(whereClauses, filterColumns.Where(c, filter.Filter, params)...)
+
+	if len(whereClauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(whereClauses, " AND ")
+}
+
+// count 统计 source 中满足查询条件的用户数量
+func (r *Repository) count(c *gin.Context, db *sqlx.DB, source, whereCondition string, params map[string]any) (int64, error) {
+	query, args, err := db.BindNamed("SELECT count(*) FROM "+source+whereCondition, params)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	err = db.GetContext(c.Request.Context(), &total, query, args...)
+	return total, err
+}
+
 func (r *Repository) AssignRoles(c *gin.Context) func(*AssignRolesReq) mo.Result[AssignRolesRes] {
 	return func(req *AssignRolesReq) mo.Result[AssignRolesRes] {
 		// 开启事务
-		tx, err := r.db.BeginTxx(c.Request.Context(), nil)
+		tx, err := r.conn(c).BeginTxx(c.Request.Context(), nil)
 		if err != nil {
-			r.logger.Error("为分配角色开启事务失败", zap.Error(err))
+			r.log(c).Error("为分配角色开启事务失败", zap.Error(err))
 			return mo.Err[AssignRolesRes](pkgs.NewApiError(http.StatusInternalServerError, "分配角色失败"))
 		}
 		defer func() {
@@ -363,15 +1245,15 @@ func (r *Repository) AssignRoles(c *gin.Context) func(*AssignRolesReq) mo.Result
 			} else {
 				err = tx.Commit()
 				if err != nil {
-					r.logger.Error("提交分配角色事务失败", zap.Error(err))
+					r.log(c).Error("提交分配角色事务失败", zap.Error(err))
 				}
 			}
 		}()
 
 		// 删除用户已有角色
-		_, err = tx.ExecContext(c.Request.Context(), `DELETE FROM "iacc_user_role" WHERE user_id = $1`, req.ID)
+		_, err = tx.ExecContext(c.Request.Context(), `DELETE FROM `+r.tables.UserRole+` WHERE user_id = $1`, req.ID)
 		if err != nil {
-			r.logger.Error("删除用户已有角色失败", zap.String("userID", req.ID), zap.Error(err))
+			r.log(c).Error("删除用户已有角色失败", zap.String("userID", req.ID), zap.Error(err))
 			return mo.Err[AssignRolesRes](pkgs.NewApiError(http.StatusInternalServerError, "分配角色失败"))
 		}
 
@@ -389,9 +1271,9 @@ func (r *Repository) AssignRoles(c *gin.Context) func(*AssignRolesReq) mo.Result
 			})
 		}
 
-		_, err = tx.NamedExecContext(c.Request.Context(), `INSERT INTO "iacc_user_role" (user_id, role_id) VALUES (:user_id, :role_id)`, userRoles)
+		_, err = tx.NamedExecContext(c.Request.Context(), `INSERT INTO `+r.tables.UserRole+` (user_id, role_id) VALUES (:user_id, :role_id)`, userRoles)
 		if err != nil {
-			r.logger.Error("为用户插入新角色失败", zap.String("userID", req.ID), zap.Error(err))
+			r.log(c).Error("为用户插入新角色失败", zap.String("userID", req.ID), zap.Error(err))
 			return mo.Err[AssignRolesRes](pkgs.NewApiError(http.StatusInternalServerError, "分配角色失败"))
 		}
 
@@ -400,19 +1282,100 @@ func (r *Repository) AssignRoles(c *gin.Context) func(*AssignRolesReq) mo.Result
 	}
 }
 
+// GrantRole 按名称为用户追加一个角色，保留已有角色；用户已拥有该角色时返回 0
+func (r *Repository) GrantRole(c *gin.Context) func(*GrantRoleReq) mo.Result[GrantRoleRes] {
+	return func(req *GrantRoleReq) mo.Result[GrantRoleRes] {
+		var ids struct {
+			UserID *string `db:"user_id"`
+			RoleID *string `db:"role_id"`
+		}
+		query := `SELECT (SELECT id FROM ` + r.tables.User + ` WHERE username = $1) AS user_id,
+			(SELECT id FROM ` + r.tables.Role + ` WHERE name = $2) AS role_id`
+		if err := r.conn(c).GetContext(c.Request.Context(), &ids, query, req.Username, req.Role); err != nil {
+			return mo.Err[GrantRoleRes](pkgs.DBError(r.log(c), err, "分配角色失败"))
+		}
+		if ids.UserID == nil {
+			return mo.Err[GrantRoleRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
+		}
+		if ids.RoleID == nil {
+			return mo.Err[GrantRoleRes](pkgs.NewApiError(http.StatusNotFound, "角色不存在"))
+		}
+
+		query = `INSERT INTO ` + r.tables.UserRole + ` (user_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, *ids.UserID, *ids.RoleID)
+		if err != nil {
+			return mo.Err[GrantRoleRes](pkgs.DBError(r.log(c), err, "分配角色失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[GrantRoleRes](pkgs.NewApiError(http.StatusInternalServerError, "分配角色失败"))
+		}
+		return mo.Ok(affectedRows)
+	}
+}
+
+// ResetPassword 按用户名重置密码
+func (r *Repository) ResetPassword(c *gin.Context) func(*ResetPasswordReq) mo.Result[ResetPasswordRes] {
+	return func(req *ResetPasswordReq) mo.Result[ResetPasswordRes] {
+		password, apiErr := r.hashPassword(req.Password, "重置密码失败")
+		if apiErr != nil {
+			return mo.Err[ResetPasswordRes](apiErr)
+		}
+		query := `UPDATE ` + r.tables.User + ` SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE username = $2`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, password, req.Username)
+		if err != nil {
+			return mo.Err[ResetPasswordRes](pkgs.DBError(r.log(c), err, "重置密码失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[ResetPasswordRes](pkgs.NewApiError(http.StatusInternalServerError, "重置密码失败"))
+		}
+		if affectedRows == 0 {
+			return mo.Err[ResetPasswordRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
+		}
+		return mo.Ok(affectedRows)
+	}
+}
+
+// ResetPasswordByID 管理员重置用户密码，用户此前签发的刷新令牌全部失效（记录会话撤销时间）
+func (r *Repository) ResetPasswordByID(c *gin.Context) func(*ResetPasswordByIDReq) mo.Result[ResetPasswordRes] {
+	return func(req *ResetPasswordByIDReq) mo.Result[ResetPasswordRes] {
+		password, apiErr := r.hashPassword(req.Password, "重置密码失败")
+		if apiErr != nil {
+			return mo.Err[ResetPasswordRes](apiErr)
+		}
+		query := `UPDATE ` + r.tables.User + ` SET password = $1, sessions_revoked_at = CURRENT_TIMESTAMP WHERE id = $2`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, password, req.ID)
+		if err != nil {
+			return mo.Err[ResetPasswordRes](pkgs.DBError(r.log(c), err, "重置密码失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[ResetPasswordRes](pkgs.NewApiError(http.StatusInternalServerError, "重置密码失败"))
+		}
+		if affectedRows == 0 {
+			return mo.Err[ResetPasswordRes](pkgs.NewApiError(http.StatusNotFound, "用户不存在"))
+		}
+		return mo.Ok(affectedRows)
+	}
+}
+
 func (r *Repository) GetRoles(c *gin.Context) func(*GetRolesReq) mo.Result[GetRolesRes] {
 	return func(req *GetRolesReq) mo.Result[GetRolesRes] {
 		// 查询总数
 		var total int64
 		countQuery := `
 			SELECT COUNT(*)
-			FROM "iacc_user_role" ur
-			JOIN "iacc_role" r ON ur.role_id = r.id
+			FROM ` + r.tables.UserRole + ` ur
+			JOIN ` + r.tables.Role + ` r ON ur.role_id = r.id
 			WHERE ur.user_id = $1
 		`
-		err := r.db.GetContext(c.Request.Context(), &total, countQuery, req.ID)
+		err := r.conn(c).GetContext(c.Request.Context(), &total, countQuery, req.ID)
 		if err != nil {
-			r.logger.Error("统计用户角色数量失败", zap.Error(err))
+			r.log(c).Error("统计用户角色数量失败", zap.Error(err))
 			return mo.Err[GetRolesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户角色列表失败"))
 		}
 
@@ -425,48 +1388,35 @@ func (r *Repository) GetRoles(c *gin.Context) func(*GetRolesReq) mo.Result[GetRo
 		}
 
 		// 查询角色列表
-		var roles []RoleItem
 		listQuery := `
 			SELECT r.id, r.name, r.description, r.created_at, r.updated_at
-			FROM "iacc_user_role" ur
-			JOIN "iacc_role" r ON ur.role_id = r.id
+			FROM ` + r.tables.UserRole + ` ur
+			JOIN ` + r.tables.Role + ` r ON ur.role_id = r.id
 			WHERE ur.user_id = $1
-			ORDER BY r.created_at DESC
+			ORDER BY r.created_at DESC, r.seq DESC
 		`
-		rows, err := r.db.QueryxContext(c.Request.Context(), listQuery, req.ID)
+		rows, err := pkgs.QueryAll[struct {
+			ID          string    `db:"id"`
+			Name        string    `db:"name"`
+			Description *string   `db:"description"`
+			CreatedAt   time.Time `db:"created_at"`
+			UpdatedAt   time.Time `db:"updated_at"`
+		}](c.Request.Context(), r.conn(c), listQuery, req.ID)
 		if err != nil {
-			r.logger.Error("查询用户角色列表失败", zap.Error(err))
-			return mo.Err[GetRolesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户角色列表失败"))
+			return mo.Err[GetRolesRes](pkgs.DBError(r.log(c), err, "查询用户角色列表失败"))
 		}
-		defer rows.Close()
 
-		for rows.Next() {
-			var role struct {
-				ID          string    `db:"id"`
-				Name        string    `db:"name"`
-				Description *string   `db:"description"`
-				CreatedAt   time.Time `db:"created_at"`
-				UpdatedAt   time.Time `db:"updated_at"`
-			}
-			err = rows.StructScan(&role)
-			if err != nil {
-				r.logger.Error("扫描角色数据失败", zap.Error(err))
-				return mo.Err[GetRolesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户角色列表失败"))
-			}
+		var roles []RoleItem
+		for _, role := range rows {
 			roles = append(roles, RoleItem{
 				ID:          role.ID,
 				Name:        role.Name,
 				Description: role.Description,
-				CreatedAt:   role.CreatedAt.Format(time.RFC3339),
-				UpdatedAt:   role.UpdatedAt.Format(time.RFC3339),
+				CreatedAt:   pkgs.FormatTime(c, role.CreatedAt),
+				UpdatedAt:   pkgs.FormatTime(c, role.UpdatedAt),
 			})
 		}
 
-		if err = rows.Err(); err != nil {
-			r.logger.Error("查询用户角色列表失败", zap.Error(err))
-			return mo.Err[GetRolesRes](pkgs.NewApiError(http.StatusInternalServerError, "查询用户角色列表失败"))
-		}
-
 		// 返回结果
 		return mo.Ok(GetRolesRes{
 			List:  roles,
@@ -474,3 +1424,33 @@ func (r *Repository) GetRoles(c *gin.Context) func(*GetRolesReq) mo.Result[GetRo
 		})
 	}
 }
+
+// Snapshot 读取用户的审计快照，包含已分配的角色ID
+// 不包含密码、手机号与个人信息（密文或敏感信息），手机号、邮箱的变化体现为影子列的变化。
+func (r *Repository) Snapshot(c *gin.Context, ids []string) (map[string]map[string]any, error) {
+	query := `
+		SELECT u.id, (to_jsonb(u) - 'password' - 'phone' - 'profile' - 'updated_at' - 'seq')
+			|| jsonb_build_object('role_ids', COALESCE((
+				SELECT jsonb_agg(ur.role_id ORDER BY ur.role_id) FROM ` + r.tables.UserRole + ` ur WHERE ur.user_id = u.id
+			), '[]'::jsonb)) AS state
+		FROM ` + r.tables.User + ` u
+		WHERE u.id = ANY($1)
+	`
+	return pkgs.QueryAuditStates(c.Request.Context(), r.conn(c), query, pkgs.PGArray(ids))
+}
+
+// toGetByIDRes 将数据库实体转换为用户详情
+func toGetByIDRes(c *gin.Context, entity *UserEntity) GetByIDRes {
+	phone := ""
+	if entity.Phone != nil {
+		phone = string(*entity.Phone)
+	}
+	return GetByIDRes{
+		ID:        entity.ID,
+		Username:  entity.Username,
+		Phone:     phone,
+		Profile:   entity.Profile,
+		CreatedAt: pkgs.FormatTime(c, entity.CreatedAt),
+		UpdatedAt: pkgs.FormatTime(c, entity.UpdatedAt),
+	}
+}
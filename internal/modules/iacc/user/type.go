@@ -2,85 +2,263 @@ package user
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"go-pg-demo/pkgs"
+	"mime/multipart"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Profile 是一个自定义类型，用于处理 JSONB 数据
 type Profile struct {
-	Email *string `json:"email,omitempty" label:"邮箱"`
+	Email *string `json:"email,omitempty" label:"邮箱" mask:"email"`
 }
 
-// Value - 实现 driver.Valuer 接口
+// Value - 实现 driver.Valuer 接口，邮箱加密后写入
 func (p Profile) Value() (driver.Value, error) {
+	if p.Email != nil {
+		email, err := pkgs.EncryptField(*p.Email)
+		if err != nil {
+			return nil, err
+		}
+		p.Email = &email
+	}
 	return pkgs.GenericJSONValue(p)
 }
 
-// Scan 实现 sql.Scanner 接口，用于从数据库中正确读取 Profile 类型
+// Scan 实现 sql.Scanner 接口，用于从数据库中正确读取 Profile 类型，邮箱读取后解密
 func (p *Profile) Scan(value any) error {
-	return pkgs.GenericJSONScan(p, value)
+	if err := pkgs.GenericJSONScan(p, value); err != nil {
+		return err
+	}
+	if p.Email != nil {
+		email, err := pkgs.DecryptField(*p.Email)
+		if err != nil {
+			return err
+		}
+		p.Email = &email
+	}
+	return nil
+}
+
+// EmailHash 返回邮箱影子列的值，未填写邮箱时返回 nil
+func (p Profile) EmailHash() *string {
+	if p.Email == nil {
+		return nil
+	}
+	hash := pkgs.EmailBlindIndex(*p.Email)
+	return &hash
 }
 
 // 数据库表 iacc_user 的表结构
 type UserEntity struct {
-	ID        string    `db:"id" label:"用户ID"`
-	CreatedAt time.Time `db:"created_at" label:"创建时间"`
-	UpdatedAt time.Time `db:"updated_at" label:"更新时间"`
-	Username  string    `db:"username" label:"用户名"`
-	Phone     *string   `db:"phone" label:"手机号"`
-	Password  string    `db:"password" label:"密码"`
-	Profile   Profile   `db:"profile" label:"个人信息"`
+	ID        string                `db:"id" label:"用户ID"`
+	CreatedAt time.Time             `db:"created_at" label:"创建时间"`
+	UpdatedAt time.Time             `db:"updated_at" label:"更新时间"`
+	Username  string                `db:"username" label:"用户名"`
+	Phone     *pkgs.EncryptedString `db:"phone" label:"手机号"`
+	PhoneHash *string               `db:"phone_hash" label:"手机号影子列"`
+	Password  *string               `db:"password" label:"密码哈希"`
+	Profile   Profile               `db:"profile" label:"个人信息"`
+	EmailHash *string               `db:"email_hash" label:"邮箱影子列"`
+}
+
+// newUserEntity 根据明文字段创建实体，并计算敏感字段的影子列，password 为已哈希的密码
+func newUserEntity(username, phone, password string, profile Profile) UserEntity {
+	encryptedPhone := pkgs.EncryptedString(phone)
+	phoneHash := pkgs.BlindIndex(phone)
+	return UserEntity{
+		Username:  username,
+		Phone:     &encryptedPhone,
+		PhoneHash: &phoneHash,
+		Password:  &password,
+		Profile:   profile,
+		EmailHash: profile.EmailHash(),
+	}
 }
 
 // 创建用户的请求 DTO
 type CreateReq struct {
 	Username string  `json:"username" validate:"required" label:"用户名"`
 	Phone    string  `json:"phone" validate:"required,min=11,max=11" label:"手机号"`
-	Password string  `json:"password" validate:"required" label:"密码"`
+	Password string  `json:"password" validate:"required,max=72" label:"密码"`
 	Profile  Profile `json:"profile,omitempty" label:"个人信息"`
 }
 
 // 创建用户的响应 DTO
 type CreateRes string
 
+// 按蓝图创建用户的请求体，profile 中传入的字段覆盖蓝图的默认值
+type CreateFromBlueprintReq struct {
+	BlueprintID string  `uri:"blueprintId" json:"-" validate:"required,uuid" label:"蓝图ID"`
+	Username    string  `json:"username" validate:"required" label:"用户名"`
+	Phone       string  `json:"phone" validate:"required,min=11,max=11" label:"手机号"`
+	Password    string  `json:"password" validate:"required,max=72" label:"密码"`
+	Profile     Profile `json:"profile,omitempty" label:"个人信息"`
+}
+
+// 按蓝图创建用户的响应，role_ids 为实际分配的角色（蓝图中已删除的角色被跳过）
+type CreateFromBlueprintRes struct {
+	ID      string   `json:"id" label:"用户ID"`
+	RoleIDs []string `json:"role_ids" label:"分配的角色ID列表"`
+}
+
 // 批量创建用户的请求体
 type BatchCreateReq struct {
 	Users []CreateReq `json:"users" validate:"required,min=1,dive" label:"用户列表"`
 }
 
+// 批量创建时同一批次内用户名、手机号不能重复（否则后一条会在数据库唯一约束处失败）
+func batchCreateRule(req *BatchCreateReq) []pkgs.Violation {
+	usernames := make([]string, len(req.Users))
+	phones := make([]string, len(req.Users))
+	for i, u := range req.Users {
+		usernames[i] = u.Username
+		phones[i] = u.Phone
+	}
+	return append(
+		pkgs.DuplicateViolations("users.username", "用户名", usernames),
+		pkgs.DuplicateViolations("users.phone", "手机号", phones)...,
+	)
+}
+
 // 批量创建用户的响应体
 type BatchCreateRes []string
 
+// 批量创建用户的响应体（return=entity 时返回完整实体）
+type BatchCreateEntityRes []GetByIDRes
+
+// ids 返回创建的用户ID，用于写入审计日志
+func (r BatchCreateEntityRes) ids() []string {
+	ids := make([]string, len(r))
+	for i, user := range r {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
 // 根据ID获取用户的参数
 type GetByIDReq struct {
 	ID string `uri:"id" validate:"required,uuid" label:"用户ID"`
+	// 扩展内容：roles 返回角色列表，permissions 返回经角色获得的权限，loginHistory 返回最近登录的设备
+	Include pkgs.Includes `form:"include" label:"扩展内容"`
+}
+
+// 用户详情的扩展内容
+const (
+	IncludeRoles        = "roles"
+	IncludePermissions  = "permissions"
+	IncludeLoginHistory = "loginHistory"
+)
+
+// 用户详情扩展内容的返回条数上限，超出部分需调用对应的列表接口查询
+const (
+	includeRolesLimit        = 100
+	includePermissionsLimit  = 500
+	includeLoginHistoryLimit = 20
+)
+
+func getByIDRule(req *GetByIDReq) []pkgs.Violation {
+	return req.Include.Violations(IncludeRoles, IncludePermissions, IncludeLoginHistory)
 }
 
 // 根据ID获取用户的响应体
 type GetByIDRes struct {
 	ID        string  `json:"id" label:"用户ID"`
 	Username  string  `json:"username" label:"用户名"`
-	Phone     string  `json:"phone" label:"手机号"`
+	Phone     string  `json:"phone" label:"手机号" mask:"phone"`
 	Profile   Profile `json:"profile,omitempty" label:"个人信息"`
 	CreatedAt string  `json:"created_at" label:"创建时间"`
 	UpdatedAt string  `json:"updated_at" label:"更新时间"`
+	// 角色列表，include=roles 时返回，最多 100 条
+	Roles []RoleItem `json:"roles,omitzero" label:"角色列表"`
+	// 经角色获得的权限，include=permissions 时返回，最多 500 条；任一角色拒绝时 effect 为 deny
+	Permissions []PermissionItem `json:"permissions,omitzero" label:"权限列表"`
+	// 最近登录的设备，include=loginHistory 时返回，按最近使用时间倒序，最多 20 条
+	LoginHistory []LoginItem `json:"login_history,omitzero" label:"登录记录"`
+}
+
+// 用户经角色获得的权限
+type PermissionItem struct {
+	ID       string         `json:"id" label:"权限ID"`
+	Name     string         `json:"name" label:"权限名称"`
+	Type     string         `json:"type" label:"权限类型"`
+	Metadata map[string]any `json:"metadata" label:"权限元数据"`
+	Effect   string         `json:"effect" label:"效果"`
+}
+
+// 用户登录记录，来自登录时登记的设备
+type LoginItem struct {
+	DeviceID     string  `json:"device_id" label:"设备标识"`
+	Name         *string `json:"name,omitempty" label:"设备名称"`
+	UserAgent    *string `json:"user_agent,omitempty" label:"User-Agent"`
+	LastIP       *string `json:"last_ip,omitempty" label:"最近IP"`
+	FirstLoginAt string  `json:"first_login_at" label:"首次登录时间"`
+	LastSeenAt   string  `json:"last_seen_at" label:"最近使用时间"`
 }
 
 // 更新用户的请求体
 type UpdateByIDReq struct {
-	ID       string   `uri:"id" validate:"required,uuid" label:"用户ID"`
-	Username *string  `json:"username,omitempty" validate:"omitempty" label:"用户名"`
-	Phone    *string  `json:"phone,omitempty" validate:"omitempty,min=11,max=11" label:"手机号"`
-	Password *string  `json:"password,omitempty" validate:"omitempty" label:"密码"`
-	Profile  *Profile `json:"profile,omitempty" label:"个人信息"`
+	ID       string  `uri:"id" validate:"required,uuid" label:"用户ID"`
+	Username *string `json:"username,omitempty" validate:"omitempty" label:"用户名"`
+	// 手机号，传 null 时清空
+	Phone    pkgs.Optional[string] `json:"phone,omitzero" validate:"omitempty,min=11,max=11" label:"手机号" swaggertype:"string"`
+	Password *string               `json:"password,omitempty" validate:"omitempty,max=72" label:"密码"`
+	// 个人信息，传入时整体替换，传 null 时清空
+	Profile pkgs.Optional[Profile] `json:"profile,omitzero" label:"个人信息" swaggertype:"object"`
 }
 
 // 更新用户的响应体
 type UpdateByIDRes = int64
 
+// 部分更新用户的请求体（JSON Merge Patch，显式 null 表示清空，profile 按字段递归合并）
+type PatchByIDReq struct {
+	pkgs.MergePatch `json:"-" swaggerignore:"true"`
+	ID              string   `uri:"id" json:"-" validate:"required,uuid" label:"用户ID"`
+	Username        *string  `json:"username,omitempty" label:"用户名"`
+	Phone           *string  `json:"phone,omitempty" validate:"omitempty,min=11,max=11" label:"手机号"`
+	Password        *string  `json:"password,omitempty" validate:"omitempty,max=72" label:"密码"`
+	Profile         *Profile `json:"profile,omitempty" label:"个人信息"`
+}
+
+// 用户名、密码不可为空，不允许通过 null 清空
+func patchRule(req *PatchByIDReq) []pkgs.Violation {
+	violations := req.NotNull("username", "用户名")
+	return append(violations, req.NotNull("password", "密码")...)
+}
+
+// 部分更新用户的响应体
+type PatchByIDRes = int64
+
+// 合并更新个人信息的请求体：set 中的字段合并进已存储的 profile，delete 中的字段从 profile 删除
+type PatchProfileReq struct {
+	ID     string   `uri:"id" json:"-" validate:"required,uuid" label:"用户ID"`
+	Set    Profile  `json:"set" label:"合并的字段"`
+	Delete []string `json:"delete,omitempty" validate:"omitempty,dive,oneof=email" label:"删除的字段"`
+}
+
+// 同一个字段不能同时合并和删除
+func patchProfileRule(req *PatchProfileReq) []pkgs.Violation {
+	var violations []pkgs.Violation
+	for i, key := range req.Delete {
+		if key == "email" && req.Set.Email != nil {
+			violations = append(violations, pkgs.Violation{
+				Field:   "delete",
+				Message: "个人信息字段 email 不能同时设置和删除",
+				Indexes: []int{i},
+			})
+		}
+	}
+	return violations
+}
+
+// 合并更新个人信息的响应体
+type PatchProfileRes = int64
+
 // 根据ID删除用户的请求参数
 type DeleteByIDReq struct {
-	ID string `uri:"id" binding:"required,uuid" label:"用户ID"`
+	ID string `uri:"id" validate:"required,uuid" label:"用户ID"`
 }
 
 // 根据ID删除用户的响应
@@ -91,33 +269,280 @@ type DeleteUsersReq struct {
 	IDs []string `json:"ids" validate:"required,min=1,dive,uuid" label:"用户ID列表"`
 }
 
+// 批量删除的用户ID不能重复
+func batchDeleteRule(req *DeleteUsersReq) []pkgs.Violation {
+	return pkgs.DuplicateViolations("ids", "用户ID", req.IDs)
+}
+
 // 批量删除用户响应
 type BatchDeleteRes = int64
 
 // 查询用户的请求体
 type QueryListReq struct {
-	Page     int    `form:"page,default=1" validate:"min=1" label:"页码"`
-	PageSize int    `form:"pageSize,default=10" validate:"min=1,max=100" label:"每页大小"`
+	pkgs.Pagination
+	pkgs.CursorPagination
+	ListFilter
+	OrderBy string `form:"orderBy,default=created_at" validate:"oneof=id username phone created_at updated_at" label:"排序字段"`
+	Order   string `form:"order,default=desc" validate:"sort_order" label:"排序顺序"`
+}
+
+func queryListRule(req *QueryListReq) []pkgs.Violation {
+	return append(listFilterRule(&req.ListFilter), req.CursorPagination.Violations(req.OrderBy)...)
+}
+
+// 用户列表的筛选条件，列表与计数接口共用
+type ListFilter struct {
 	Phone    string `form:"phone,omitempty" validate:"omitempty" label:"手机号"`
 	Username string `form:"username,omitempty" validate:"omitempty" label:"用户名"`
-	OrderBy  string `form:"orderBy,default=id" validate:"omitempty" label:"排序字段"`
-	Order    string `form:"order,default=desc" validate:"omitempty" label:"排序顺序"`
+	Email    string `form:"email,omitempty" validate:"omitempty" label:"邮箱"`
+	// 通用过滤表达式，如 username:like:foo,created_at:gte:2024-01-01，可筛选的字段见 filterColumns
+	Filter string `form:"filter" validate:"omitempty,max=2048" label:"过滤表达式"`
+	pkgs.DateRange
+}
+
+// 过滤表达式可以筛选的字段；手机号、邮箱启用加密后无法比较，不在其中
+var filterColumns = pkgs.FilterColumns{
+	"id":            {Expr: "id", Type: pkgs.FilterUUID},
+	"username":      {Expr: "username", Type: pkgs.FilterText},
+	"strict_device": {Expr: "strict_device", Type: pkgs.FilterBool},
+	"disabled_at":   {Expr: "disabled_at", Type: pkgs.FilterTime},
+	"created_at":    {Expr: "created_at", Type: pkgs.FilterTime},
+	"updated_at":    {Expr: "updated_at", Type: pkgs.FilterTime},
+}
+
+func listFilterRule(req *ListFilter) []pkgs.Violation {
+	return append(req.DateRange.Violations(), filterColumns.Violations("filter", req.Filter)...)
+}
+
+// 导出用户的请求参数，筛选与排序与列表接口相同，不分页
+type ExportReq struct {
+	Format string `form:"format,default=csv" validate:"oneof=csv xlsx" label:"导出格式"`
+	ListFilter
+	OrderBy string `form:"orderBy,default=created_at" validate:"oneof=id username phone created_at updated_at" label:"排序字段"`
+	Order   string `form:"order,default=desc" validate:"sort_order" label:"排序顺序"`
+}
+
+func exportRule(req *ExportReq) []pkgs.Violation {
+	return listFilterRule(&req.ListFilter)
+}
+
+// 导出的查询，查询在开始写出文件前执行，失败时仍可返回错误信封
+type ExportPlan struct {
+	Format string
+	Query  string
+	Args   []any
+	// 当前用户没有 user:view_pii 权限时手机号、邮箱脱敏导出
+	Mask bool
+}
+
+// userExportEntity 导出的一行
+type userExportEntity struct {
+	userListEntity
+	DisabledAt *time.Time `db:"disabled_at"`
+}
+
+// 导入用户的方式
+const (
+	// 全部行通过校验并写入成功才提交，否则不导入任何用户
+	ImportModeAtomic = "atomic"
+	// 逐行导入，跳过被拒绝的行
+	ImportModeRow = "row"
+)
+
+// 导入文件的大小与数据行数上限
+const (
+	maxImportFileSize = 5 << 20
+	maxImportRows     = 1000
+)
+
+// 导入用户的请求参数，file 为 UTF-8 编码的 CSV 文件，首行为表头
+type ImportReq struct {
+	File *multipart.FileHeader `form:"file" validate:"required" label:"导入文件"`
+	Mode string                `form:"mode,default=atomic" validate:"oneof=atomic row" label:"导入方式"`
+}
+
+func importRule(req *ImportReq) []pkgs.Violation {
+	if req.File != nil && req.File.Size > maxImportFileSize {
+		return []pkgs.Violation{{Field: "file", Message: fmt.Sprintf("导入文件不能超过 %d MB", maxImportFileSize>>20)}}
+	}
+	return nil
+}
+
+// 导入计划：读取文件后通过校验的行与被拒绝的行
+type ImportPlan struct {
+	Mode string
+	// 文件中的数据行数
+	Total    int
+	Rows     []ImportRow
+	Rejected []ImportRejected
+}
+
+// ImportRow 通过校验的一行，Line 为文件中的行号（表头为第 1 行）
+type ImportRow struct {
+	Line int
+	CreateReq
+}
+
+// 导入成功的行
+type ImportAccepted struct {
+	Line int    `json:"line" label:"行号"`
+	ID   string `json:"id" label:"用户ID"`
+}
+
+// 导入被拒绝的行
+type ImportRejected struct {
+	Line     int    `json:"line" label:"行号"`
+	Username string `json:"username" label:"用户名"`
+	Reason   string `json:"reason" label:"原因"`
+}
+
+// 导入用户的响应，rejected 按行号排序；atomic 方式下存在被拒绝的行时不导入任何用户，accepted 为空
+type ImportRes struct {
+	Mode     string           `json:"mode" label:"导入方式"`
+	Total    int              `json:"total" label:"数据行数"`
+	Accepted []ImportAccepted `json:"accepted" label:"导入成功的行"`
+	Rejected []ImportRejected `json:"rejected" label:"被拒绝的行"`
+}
+
+// ids 返回导入成功的用户ID，用于写入审计日志
+func (r ImportRes) ids() []string {
+	ids := make([]string, len(r.Accepted))
+	for i, row := range r.Accepted {
+		ids[i] = row.ID
+	}
+	return ids
+}
+
+// JobTypeImport 用户异步导入任务
+const JobTypeImport = "iacc.user.import"
+
+// 异步导入任务的参数：通过校验的行（启用字段加密时密码加密保存）与校验阶段被拒绝的行
+// 任务完成后参数替换为只包含导入报告（Report）的版本，不再保存密码。
+type ImportJobPayload struct {
+	RequestedBy string `json:"requested_by"`
+	// 发起请求时的语言，被拒绝的原因按该语言生成
+	Locale   string           `json:"locale"`
+	Mode     string           `json:"mode"`
+	Total    int              `json:"total"`
+	Rows     []ImportJobRow   `json:"rows,omitempty"`
+	Rejected []ImportRejected `json:"rejected,omitempty"`
+	Report   *ImportRes       `json:"report,omitempty"`
+}
+
+// 异步导入任务中通过校验的一行
+type ImportJobRow struct {
+	Line     int     `json:"line"`
+	Username string  `json:"username"`
+	Phone    string  `json:"phone"`
+	Password string  `json:"password"`
+	Profile  Profile `json:"profile"`
+}
+
+// 转为异步导入时的响应数据（业务码 202）
+type ImportJobRes struct {
+	JobID string `json:"job_id" label:"任务ID"`
+	Rows  int    `json:"rows" label:"待导入的行数"`
+}
+
+// 查询异步导入的请求参数
+type ImportJobReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"任务ID"`
+}
+
+// 查询异步导入的响应，任务成功后 report 为导入报告
+type GetImportJobRes struct {
+	JobID      string     `json:"job_id" label:"任务ID"`
+	Status     string     `json:"status" label:"任务状态"`
+	Error      *string    `json:"error,omitempty" label:"失败原因"`
+	Report     *ImportRes `json:"report,omitempty" label:"导入报告"`
+	CreatedAt  string     `json:"created_at" label:"创建时间"`
+	FinishedAt *string    `json:"finished_at,omitempty" label:"完成时间"`
+}
+
+// 统计用户数量的请求参数
+type CountReq = ListFilter
+
+// 统计用户数量的响应
+type CountRes = int64
+
+// 检查用户是否存在的请求参数，同时传多个条件时需全部满足
+type ExistsReq struct {
+	Phone    string `form:"phone" validate:"omitempty" label:"手机号"`
+	Username string `form:"username" validate:"omitempty" label:"用户名"`
+	Email    string `form:"email" validate:"omitempty,email" label:"邮箱"`
+}
+
+func existsRule(req *ExistsReq) []pkgs.Violation {
+	if req.Phone == "" && req.Username == "" && req.Email == "" {
+		return []pkgs.Violation{{Field: "phone", Message: "手机号、用户名、邮箱至少传一个"}}
+	}
+	return nil
+}
+
+// 检查用户是否存在的响应
+type ExistsRes = bool
+
+// 可用性检查中不可用的原因
+const (
+	// 已被已有用户使用
+	AvailabilityTaken = "taken"
+	// 与请求中前面的项重复
+	AvailabilityDuplicate = "duplicate"
+)
+
+// 批量检查用户名、手机号是否可用的请求体，批量创建用户前由前端调用
+type CheckAvailabilityReq struct {
+	Usernames []string `json:"usernames" validate:"max=1000,dive,required,max=20" label:"用户名列表"`
+	Phones    []string `json:"phones" validate:"max=1000,dive,required,min=11,max=11" label:"手机号列表"`
+}
+
+func checkAvailabilityRule(req *CheckAvailabilityReq) []pkgs.Violation {
+	if len(req.Usernames) == 0 && len(req.Phones) == 0 {
+		return []pkgs.Violation{{Field: "usernames", Message: "用户名、手机号至少传一个"}}
+	}
+	return nil
+}
+
+// 一个用户名或手机号的可用性
+type AvailabilityItem struct {
+	Value     string `json:"value" label:"用户名或手机号"`
+	Available bool   `json:"available" label:"是否可用"`
+	// 不可用的原因：taken 已被使用，duplicate 与请求中前面的项重复
+	Reason string `json:"reason,omitempty" label:"不可用原因"`
+}
+
+// 批量检查可用性的响应，顺序与请求一致
+type CheckAvailabilityRes struct {
+	Usernames []AvailabilityItem `json:"usernames" label:"用户名可用性"`
+	Phones    []AvailabilityItem `json:"phones" label:"手机号可用性"`
 }
 
 // 用户响应
 type UserItem struct {
-	ID        string  `json:"id" label:"用户ID"`
-	Username  string  `json:"username" label:"用户名"`
-	Phone     string  `json:"phone" label:"手机号"`
-	Profile   Profile `json:"profile" label:"个人信息"`
-	CreatedAt string  `json:"created_at" label:"创建时间"`
-	UpdatedAt string  `json:"updated_at" label:"更新时间"`
+	ID       string  `json:"id" label:"用户ID"`
+	Username string  `json:"username" label:"用户名"`
+	Phone    string  `json:"phone" label:"手机号" mask:"phone"`
+	Profile  Profile `json:"profile" label:"个人信息"`
+	// 用户拥有的角色名称，按名称排序
+	RoleNames []string `json:"role_names" label:"角色名称"`
+	CreatedAt string   `json:"created_at" label:"创建时间"`
+	UpdatedAt string   `json:"updated_at" label:"更新时间"`
+}
+
+// userListEntity 列表查询的一行，附带聚合的角色名称与游标分页使用的 seq
+type userListEntity struct {
+	UserEntity
+	RoleNames pq.StringArray `db:"role_names"`
+	Seq       int64          `db:"seq"`
 }
 
-// 查询用户的响应体
+// 查询用户的响应体，游标分页时不统计总数（total 为 0）
+// 开启物化视图时 refreshed_at 为快照的刷新时间，之后新增、修改的用户要等下次刷新后才会出现在列表中。
 type QueryListRes struct {
-	List  []UserItem `json:"list"`
-	Total int64      `json:"total"`
+	List        []UserItem `json:"list"`
+	Total       int64      `json:"total"`
+	NextCursor  string     `json:"next_cursor,omitempty" label:"下一页游标"`
+	RefreshedAt string     `json:"refreshed_at,omitempty" label:"数据刷新时间"`
 }
 
 // 给用户分配角色的请求 DTO
@@ -126,9 +551,38 @@ type AssignRolesReq struct {
 	RoleIDs []string `json:"role_ids" validate:"required,min=1,dive,uuid" label:"角色ID列表"`
 }
 
+// 分配的角色ID列表不能重复
+func assignRolesRule(req *AssignRolesReq) []pkgs.Violation {
+	return pkgs.DuplicateViolations("role_ids", "角色ID", req.RoleIDs)
+}
+
 // 给用户分配角色的响应 DTO
 type AssignRolesRes = int64
 
+// 按名称为用户追加角色的请求参数，供运维命令使用
+type GrantRoleReq struct {
+	Username string `validate:"required" label:"用户名"`
+	Role     string `validate:"required" label:"角色名称"`
+}
+
+// 追加角色的响应，新增的用户角色数（已拥有时为 0）
+type GrantRoleRes = int64
+
+// 按用户名重置密码的请求参数，供运维命令使用
+type ResetPasswordReq struct {
+	Username string `validate:"required" label:"用户名"`
+	Password string `validate:"required,max=72" label:"密码"`
+}
+
+// 重置密码的响应
+type ResetPasswordRes = int64
+
+// 管理员按用户ID重置密码的请求参数
+type ResetPasswordByIDReq struct {
+	ID       string `uri:"id" validate:"required,uuid" label:"用户ID"`
+	Password string `json:"password" validate:"required,max=72" label:"新密码"`
+}
+
 // 获取用户角色列表的请求参数
 type GetRolesReq struct {
 	ID string `uri:"id" validate:"required,uuid" label:"用户ID"`
@@ -0,0 +1,71 @@
+// Package sandbox API.
+//
+// 接口调试沙箱：签发短时、限定接口范围的沙箱令牌，使用沙箱令牌的请求在回滚的事务中执行。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package sandbox
+
+import (
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+}
+
+func NewSandboxHandler(logger *zap.Logger, validator *pkgs.RequestValidator, config *pkgs.Config, engine *gin.Engine, pool *pkgs.TenantPool, tables *pkgs.TableNames, permissions *pkgs.PermissionChecker) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, issueTokenRule(config.Sandbox.MaxExpire))
+
+	return &Handler{
+		logger:    logger,
+		validator: validator,
+		repository: &Repository{
+			logger:      logger,
+			engine:      engine,
+			pool:        pool,
+			tables:      tables,
+			permissions: permissions,
+			jwt:         config.JWT,
+			sandbox:     config.Sandbox,
+		},
+	}
+}
+
+// IssueToken 签发沙箱令牌
+//
+//	@Summary  签发沙箱令牌
+//	@Description  为当前用户签发只能访问指定接口的短时访问令牌，供集成方在真实的表结构与数据上试用接口。使用沙箱令牌的请求在一个最终回滚的事务中执行：写操作在本次请求内生效并正常返回结果，请求结束后全部撤销，响应带 X-Sandbox: rolled-back 头。接口必须是已注册的路由模板（如 "PUT /v1/user/:id"），纳入权限体系的接口当前用户必须拥有其权限；创建租户、开发环境接口、恢复归档模板等副作用不在数据库事务内的接口不能加入。沙箱令牌不能刷新。只有配置 sandbox.enabled 时才注册该接口
+//	@Tags   sandbox
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  IssueTokenReq  true  "签发沙箱令牌请求参数"
+//	@Success  200 {object}  pkgs.Response{data=IssueTokenRes}  "签发成功"
+//	@Failure  400 {object}  pkgs.Response           "请求参数错误或接口不能在沙箱中调用"
+//	@Failure  403 {object}  pkgs.Response           "无接口访问权限"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/sandbox/token"}
+//	@Router   /sandbox/token [post]
+func (h *Handler) IssueToken(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[IssueTokenReq](c),
+		result.FlatMap(pkgs.ValidateV2[IssueTokenReq](h.validator)),
+		result.FlatMap(h.repository.Issue(c)),
+	).Match(
+		pkgs.HandleSuccess[IssueTokenRes](c),
+		pkgs.HandleError[IssueTokenRes](c),
+	)
+}
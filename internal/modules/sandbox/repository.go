@@ -0,0 +1,106 @@
+package sandbox
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	logger      *zap.Logger
+	engine      *gin.Engine
+	pool        *pkgs.TenantPool
+	tables      *pkgs.TableNames
+	permissions *pkgs.PermissionChecker
+	jwt         pkgs.JWTConfig
+	sandbox     pkgs.SandboxConfig
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+// Issue 为当前用户签发沙箱令牌
+// 每个接口必须是已注册的路由、不属于不能在沙箱中调用的接口，纳入权限体系的接口当前用户必须拥有其权限：
+// 沙箱令牌只能收窄、不能扩大令牌所属用户的访问范围。
+func (r *Repository) Issue(c *gin.Context) func(*IssueTokenReq) mo.Result[IssueTokenRes] {
+	return func(req *IssueTokenReq) mo.Result[IssueTokenRes] {
+		scopes := make(pkgs.SandboxScopes, 0, len(req.APIs))
+		routes := r.engine.Routes()
+		for _, api := range req.APIs {
+			method, route, _ := parseAPI(api)
+			if !slices.ContainsFunc(routes, func(info gin.RouteInfo) bool { return info.Method == method && info.Path == route }) {
+				return mo.Err[IssueTokenRes](pkgs.NewApiError(http.StatusBadRequest, "接口不存在："+api))
+			}
+			if reason, denied := pkgs.SandboxDenied(method, route); denied {
+				return mo.Err[IssueTokenRes](pkgs.NewApiError(http.StatusBadRequest, "该接口不能在沙箱中调用："+api+"，"+reason))
+			}
+			scopes = append(scopes, pkgs.SandboxScope(method, route))
+		}
+		if apiErr := r.checkPermissions(c, scopes); apiErr != nil {
+			return mo.Err[IssueTokenRes](apiErr)
+		}
+
+		expire := r.sandbox.MaxExpire
+		if req.ExpiresIn > 0 {
+			expire = time.Duration(req.ExpiresIn) * time.Second
+		}
+		userID := pkgs.CurrentUserID(c)
+		// 沙箱令牌沿用当前访问令牌的令牌版本，批量撤销后随之失效
+		current, _ := c.Value(pkgs.TokenClaimsContextKey).(jwt.MapClaims)
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id":              userID,
+			pkgs.TokenTypeClaim:    pkgs.TokenTypeAccess,
+			"exp":                  now.Add(expire).Unix(),
+			"iat":                  now.Unix(),
+			pkgs.TokenIDClaim:      uuid.NewString(),
+			pkgs.TokenVersionClaim: pkgs.TokenVersion(current),
+			pkgs.SandboxClaim:      []string(scopes),
+		})
+		accessToken, err := token.SignedString([]byte(r.jwt.Secret))
+		if err != nil {
+			r.log(c).Error("签发沙箱令牌失败", zap.Error(err))
+			return mo.Err[IssueTokenRes](pkgs.NewApiError(http.StatusInternalServerError, "签发沙箱令牌失败"))
+		}
+
+		r.log(c).Info("已签发沙箱令牌", zap.String("user_id", userID), zap.Strings("apis", scopes), zap.Duration("expire", expire))
+		return mo.Ok(IssueTokenRes{AccessToken: accessToken, ExpiresIn: int64(expire.Seconds()), APIs: scopes})
+	}
+}
+
+// checkPermissions 校验当前用户拥有沙箱令牌中纳入权限体系的接口的权限（与 PermissionMiddleware 一致，拒绝优先）
+func (r *Repository) checkPermissions(c *gin.Context, scopes pkgs.SandboxScopes) *pkgs.ApiError {
+	var guarded []struct {
+		Method string `db:"method"`
+		Path   string `db:"path"`
+	}
+	query := `SELECT DISTINCT metadata->>'method' AS method, metadata->>'path' AS path FROM ` + r.tables.Permission + `
+		WHERE (metadata->>'method') || ' ' || (metadata->>'path') = ANY($1)`
+	if err := r.pool.DB(c).SelectContext(c.Request.Context(), &guarded, query, pkgs.PGArray(scopes)); err != nil {
+		return pkgs.DBError(r.log(c), err, "签发沙箱令牌失败")
+	}
+	if len(guarded) == 0 {
+		return nil
+	}
+
+	perms, err := r.permissions.Permissions(c)
+	if err != nil {
+		return pkgs.NewApiError(http.StatusInternalServerError, "签发沙箱令牌失败")
+	}
+	for _, api := range guarded {
+		if !pkgs.PermissionAllowed(perms, func(p pkgs.APIPermission) bool { return p.Method == api.Method && p.Path == api.Path }) {
+			return pkgs.NewApiError(http.StatusForbidden, "无接口访问权限："+pkgs.SandboxScope(api.Method, api.Path))
+		}
+	}
+	return nil
+}
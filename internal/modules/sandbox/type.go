@@ -0,0 +1,60 @@
+package sandbox
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"go-pg-demo/pkgs"
+)
+
+// 签发沙箱令牌的请求体
+type IssueTokenReq struct {
+	// 允许访问的接口，格式为 "METHOD 路由模板"，如 "PUT /v1/user/:id"
+	APIs []string `json:"apis" validate:"required,min=1,max=50" label:"接口"`
+	// 有效期（秒），为空时使用 sandbox.max_expire，不能超过 sandbox.max_expire
+	ExpiresIn int64 `json:"expires_in" validate:"omitempty,min=1" label:"有效期"`
+}
+
+// issueTokenRule 返回签发沙箱令牌的校验规则
+func issueTokenRule(maxExpire time.Duration) pkgs.Rule[IssueTokenReq] {
+	return func(req *IssueTokenReq) []pkgs.Violation {
+		var violations []pkgs.Violation
+		seen := make([]string, 0, len(req.APIs))
+		for i, api := range req.APIs {
+			method, route, ok := parseAPI(api)
+			if !ok {
+				violations = append(violations, pkgs.Violation{Field: "apis", Message: "接口格式应为 \"PUT /v1/user/:id\"", Indexes: []int{i}})
+				continue
+			}
+			scope := pkgs.SandboxScope(method, route)
+			if slices.Contains(seen, scope) {
+				violations = append(violations, pkgs.Violation{Field: "apis", Message: "接口重复", Indexes: []int{i}})
+			}
+			seen = append(seen, scope)
+		}
+		if time.Duration(req.ExpiresIn)*time.Second > maxExpire {
+			violations = append(violations, pkgs.Violation{Field: "expires_in", Message: "有效期不能超过 " + maxExpire.String()})
+		}
+		return violations
+	}
+}
+
+// parseAPI 解析 "METHOD /v1/路由模板" 格式的接口
+func parseAPI(api string) (string, string, bool) {
+	method, route, ok := strings.Cut(strings.TrimSpace(api), " ")
+	route = strings.TrimSpace(route)
+	if !ok || method == "" || method != strings.ToUpper(method) || !strings.HasPrefix(route, "/v1/") || strings.ContainsAny(route, " \t") {
+		return "", "", false
+	}
+	return method, route, true
+}
+
+// 签发沙箱令牌的响应体
+type IssueTokenRes struct {
+	AccessToken string `json:"access_token"`
+	// 有效期（秒）
+	ExpiresIn int64 `json:"expires_in"`
+	// 令牌允许访问的接口
+	APIs []string `json:"apis"`
+}
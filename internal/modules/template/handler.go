@@ -24,34 +24,100 @@ type Handler struct {
 	db         *sqlx.DB
 	logger     *zap.Logger
 	validator  *pkgs.RequestValidator
+	watchers   *pkgs.Watchers
 	repository *Repository
 }
 
-func NewTemplateHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator) *Handler {
+func NewTemplateHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, reporting *pkgs.ReportingDB, permissions *pkgs.PermissionChecker, ids *pkgs.IDGenerator, config *pkgs.Config, storage *pkgs.Storage, scheduler *pkgs.Scheduler, watchers *pkgs.Watchers) *Handler {
+	// 注册跨字段校验规则
+	pkgs.RegisterRule(validator, queryListRule)
+	pkgs.RegisterRule(validator, listFilterRule)
+	pkgs.RegisterRule(validator, batchDeleteRule)
+	pkgs.RegisterRule(validator, batchArchiveRule)
+	pkgs.RegisterRule(validator, patchRule)
+	pkgs.RegisterRule(validator, syncPullRule)
+	pkgs.RegisterRule(validator, syncPushRule)
+
+	repository := &Repository{
+		db:          db,
+		logger:      logger,
+		tables:      tables,
+		pool:        pool,
+		reporting:   reporting,
+		permissions: permissions,
+		ids:         ids,
+		storage:     storage,
+		archive:     config.Archive,
+	}
+	repository.hooks = []ArchiveHook{repository.usageArchiveHook()}
+	repository.references = []ReferenceChecker{repository.watchReferenceChecker()}
+	if config.Archive.RecentUse > 0 {
+		repository.references = append(repository.references, repository.usageReferenceChecker(config.Archive.RecentUse))
+	}
+
+	// 配置了对象存储时定时导出归档的模板
+	if config.Modules.Template.Enabled && storage.Enabled() {
+		scheduler.Register("template.archive", config.Archive.Schedule, repository.ExportArchived)
+	}
+	// 只能关注自己可以查看的模板，不可见的模板与不存在的模板一样返回 404
+	if config.Modules.Template.Enabled {
+		watchers.RegisterTarget(pkgs.WatchEntityTemplate, pkgs.WatchTarget{
+			Label: "模板",
+			Table: tables.Template,
+			Check: func(c *gin.Context, id string) error {
+				return repository.GetByID(c)(&GetByIDReq{ID: id}).Error()
+			},
+			Visible: repository.watchVisible,
+		})
+	}
+
 	return &Handler{
-		db:        db,
-		logger:    logger,
-		validator: validator,
-		repository: &Repository{
-			db:     db,
-			logger: logger,
-		},
+		db:         db,
+		logger:     logger,
+		validator:  validator,
+		watchers:   watchers,
+		repository: repository,
 	}
 }
 
+// RegisterArchiveHook 注册归档的生命周期钩子，导出时保存模板的关联数据，恢复时写回
+// 须在定时任务启动前调用；钩子名称作为归档文件中 extensions 的键，注册后不应修改。
+func (h *Handler) RegisterArchiveHook(hook ArchiveHook) {
+	h.repository.hooks = append(h.repository.hooks, hook)
+}
+
+// RegisterReferenceChecker 注册模板引用检查器，归档时拒绝、批量归档时跳过仍被引用的模板
+// 须在处理请求前调用；检查器名称在跳过原因中返回给调用方。
+func (h *Handler) RegisterReferenceChecker(checker ReferenceChecker) {
+	h.repository.references = append(h.repository.references, checker)
+}
+
 // Create 创建模板
 //
 //	@Summary  创建模板
-//	@Description  创建模板
+//	@Description  创建模板；required_permission 为可选的权限编码，设置后只有拥有该权限的用户可以看到模板，只能要求自己拥有的权限
 //	@Tags   template
 //	@Accept   json
 //	@Produce  json
 //	@Param    request body  CreateReq true  "创建模板请求参数"
+//	@Param    return  query string  false "返回内容，entity 时返回完整模板（data 为 GetByIDRes）"  Enums(id, entity)
 //	@Success  200   {object}  pkgs.Response{data=CreateRes}  "创建成功，返回模板ID"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
 //	@Router   /template [post]
 func (h *Handler) Create(c *gin.Context) {
+	if pkgs.WantEntity(c) {
+		result.Pipe2(
+			pkgs.BindJSON[CreateReq](c),
+			result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+			result.FlatMap(h.repository.CreateEntity(c)),
+		).Match(
+			pkgs.HandleSuccess[GetByIDRes](c),
+			pkgs.HandleError[GetByIDRes](c),
+		)
+		return
+	}
+
 	result.Pipe2(
 		pkgs.BindJSON[CreateReq](c),
 		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
@@ -70,11 +136,24 @@ func (h *Handler) Create(c *gin.Context) {
 //	@Accept   json
 //	@Produce  json
 //	@Param    request body  BatchCreateReq  true  "批量创建模板请求参数"
+//	@Param    return  query string  false "返回内容，entity 时返回完整模板列表（data 为 BatchCreateEntityRes）"  Enums(id, entity)
 //	@Success  200   {object}  pkgs.Response{data=BatchCreateRes}  "创建成功，返回模板ID列表"
 //	@Failure  400   {object}  pkgs.Response         "请求参数错误"
 //	@Failure  500   {object}  pkgs.Response         "服务器内部错误"
 //	@Router   /template/batch-create [post]
 func (h *Handler) BatchCreate(c *gin.Context) {
+	if pkgs.WantEntity(c) {
+		result.Pipe2(
+			pkgs.BindJSON[BatchCreateReq](c),
+			result.FlatMap(pkgs.ValidateV2[BatchCreateReq](h.validator)),
+			result.FlatMap(h.repository.BatchCreateEntity(c)),
+		).Match(
+			pkgs.HandleSuccess[BatchCreateEntityRes](c),
+			pkgs.HandleError[BatchCreateEntityRes](c),
+		)
+		return
+	}
+
 	result.Pipe2(
 		pkgs.BindJSON[BatchCreateReq](c),
 		result.FlatMap(pkgs.ValidateV2[BatchCreateReq](h.validator)),
@@ -88,7 +167,7 @@ func (h *Handler) BatchCreate(c *gin.Context) {
 // GetByID 根据ID获取模板
 //
 //	@Summary  根据ID获取模板
-//	@Description  根据ID获取模板
+//	@Description  根据ID获取模板，模板设置了 required_permission 而当前用户没有该权限时按不存在处理
 //	@Tags   template
 //	@Accept   json
 //	@Produce  json
@@ -120,19 +199,47 @@ func (h *Handler) GetByID(c *gin.Context) {
 //	@Param    request body  UpdateByIDReq true  "更新模板请求参数"
 //	@Success  200   {object}  pkgs.Response{data=UpdateByIDRes}       "更新成功"
 //	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404   {object}  pkgs.Response       "缺少模板要求的权限"
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
 //	@Router   /template/{id} [put]
 func (h *Handler) UpdateByID(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe3(
 		pkgs.BindUriAndJSON[UpdateByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[UpdateByIDReq](h.validator)),
 		result.FlatMap(h.repository.UpdateByID(c)),
+		result.Map(pkgs.NotifyWatchers[UpdateByIDRes](c, h.watchers, "update", pkgs.WatchEntityTemplate, pkgs.WatchAffected(c.Param("id")))),
 	).Match(
 		pkgs.HandleSuccess[UpdateByIDRes](c),
 		pkgs.HandleError[UpdateByIDRes](c),
 	)
 }
 
+// PatchByID 根据ID部分更新模板
+//
+//	@Summary  根据ID部分更新模板
+//	@Description  按 JSON Merge Patch（RFC 7386）部分更新模板：未出现的字段保持不变，显式 null 清空可空字段（num）
+//	@Tags   template
+//	@Accept   json,application/merge-patch+json
+//	@Produce  json
+//	@Param    id    path  string          true  "模板ID"
+//	@Param    request body  PatchByIDReq true  "部分更新模板请求参数"
+//	@Success  200   {object}  pkgs.Response{data=PatchByIDRes}       "更新成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404   {object}  pkgs.Response       "缺少模板要求的权限"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Router   /template/{id} [patch]
+func (h *Handler) PatchByID(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindUriAndMergePatch[PatchByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[PatchByIDReq](h.validator)),
+		result.FlatMap(h.repository.PatchByID(c)),
+		result.Map(pkgs.NotifyWatchers[PatchByIDRes](c, h.watchers, "patch", pkgs.WatchEntityTemplate, pkgs.WatchAffected(c.Param("id")))),
+	).Match(
+		pkgs.HandleSuccess[PatchByIDRes](c),
+		pkgs.HandleError[PatchByIDRes](c),
+	)
+}
+
 // DeleteByID 根据ID删除模板
 //
 //	@Summary  根据ID删除模板
@@ -143,13 +250,15 @@ func (h *Handler) UpdateByID(c *gin.Context) {
 //	@Param    id  path  string  true  "模板ID"
 //	@Success  200 {object}  pkgs.Response{data=DeleteByIDRes} "删除成功，返回影响行数"
 //	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response       "缺少模板要求的权限"
 //	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
 //	@Router   /template/{id} [delete]
 func (h *Handler) DeleteByID(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe3(
 		pkgs.BindUri[DeleteByIDReq](c),
 		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
 		result.FlatMap(h.repository.DeleteByID(c)),
+		result.Map(pkgs.NotifyWatchersDeleted[DeleteByIDRes](c, h.watchers, "delete", pkgs.WatchEntityTemplate, pkgs.WatchAffected(c.Param("id")))),
 	).Match(
 		pkgs.HandleSuccess[DeleteByIDRes](c),
 		pkgs.HandleError[DeleteByIDRes](c),
@@ -169,10 +278,12 @@ func (h *Handler) DeleteByID(c *gin.Context) {
 //	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
 //	@Router   /template/batch-delete [post]
 func (h *Handler) BatchDelete(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe4(
 		pkgs.BindJSON[DeleteTemplatesReq](c),
 		result.FlatMap(pkgs.ValidateV2[DeleteTemplatesReq](h.validator)),
+		result.Map(pkgs.WatchTargets[DeleteTemplatesReq](c, pkgs.WatchEntityTemplate, func(req *DeleteTemplatesReq) []string { return req.IDs })),
 		result.FlatMap(h.repository.BatchDelete(c)),
+		result.Map(pkgs.NotifyWatchersDeleted[BatchDeleteRes](c, h.watchers, "batch_delete", pkgs.WatchEntityTemplate, nil)),
 	).Match(
 		pkgs.HandleSuccess[BatchDeleteRes](c),
 		pkgs.HandleError[BatchDeleteRes](c),
@@ -182,26 +293,272 @@ func (h *Handler) BatchDelete(c *gin.Context) {
 // QueryList 获取模板列表
 //
 //	@Summary  获取模板列表
-//	@Description  获取模板列表
+//	@Description  获取模板列表，默认只返回当前用户的模板（匿名请求返回无所有者的模板），scope=all 需要 template:manage_all 权限；不返回当前用户缺少 required_permission 权限的模板
 //	@Tags   template
 //	@Accept   json
 //	@Produce  json
 //	@Param    page    query int   false "页码"  default(1)
 //	@Param    pageSize  query int   false "每页数量"  default(10)
+//	@Param    cursor  query string  false "游标，传上一页响应的 next_cursor；传 cursor 或 limit 时按游标分页，只支持按创建时间排序，不统计总数"
+//	@Param    limit   query int   false "游标分页的每页数量"  default(10)
 //	@Param    name    query string  false "模板名称"
-//	@Param    orderBy query string  false "排序字段" default(id)
+//	@Param    orderBy query string  false "排序字段"  Enums(id, name, num, created_at, updated_at, usage_count) default(created_at)
 //	@Param    order   query string  false "排序顺序" default(desc)
+//	@Param    scope   query string  false "查询范围"  Enums(mine, all) default(mine)
+//	@Param    num     query int   false "数量（精确匹配）"
+//	@Param    numMin  query int   false "最小数量（含）"
+//	@Param    numMax  query int   false "最大数量（含）"
+//	@Param    filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、num、owner_id、created_at、updated_at"
+//	@Param    createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param    createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param    updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param    updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param    changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Param    If-None-Match  header  string  false  "上次响应的 ETag，列表未变化时返回 304"
+//	@Param    If-Modified-Since  header  string  false  "上次响应的 Last-Modified，列表未变化时返回 304"
 //	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功，返回模板列表"
 //	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  403     {object}  pkgs.Response               "没有查看全部模板的权限"
 //	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
 //	@Router   /template/list [get]
 func (h *Handler) QueryList(c *gin.Context) {
-	result.Pipe2(
+	result.Pipe3(
 		pkgs.BindQuery[QueryListReq](c),
 		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.CheckModified(c)),
 		result.FlatMap(h.repository.QueryList(c)),
 	).Match(
 		pkgs.HandleSuccess[QueryListRes](c),
 		pkgs.HandleError[QueryListRes](c),
 	)
 }
+
+// Count 统计模板数量
+//
+//	@Summary  统计模板数量
+//	@Description  按与模板列表相同的筛选条件统计模板数量，不返回模板数据
+//	@Tags   template
+//	@Accept   json
+//	@Produce  json
+//	@Param    name    query string  false "模板名称"
+//	@Param    scope   query string  false "查询范围"  Enums(mine, all) default(mine)
+//	@Param    num     query int   false "数量（精确匹配）"
+//	@Param    numMin  query int   false "最小数量（含）"
+//	@Param    numMax  query int   false "最大数量（含）"
+//	@Param    filter  query  string  false  "通用过滤表达式，逗号分隔的 字段:操作符:值，如 created_at:gte:2024-01-01；操作符 eq、ne、gt、gte、lt、lte、like、in（取值用 | 分隔）、null（true/false）；可筛选字段：id、name、num、owner_id、created_at、updated_at"
+//	@Param    createdFrom  query  string  false  "创建时间起（含），RFC 3339 时间或 YYYY-MM-DD 日期，日期按请求时区解析"
+//	@Param    createdTo    query  string  false  "创建时间止（含），传日期时包含当天全天"
+//	@Param    updatedFrom  query  string  false  "更新时间起（含）"
+//	@Param    updatedTo    query  string  false  "更新时间止（含），传日期时包含当天全天"
+//	@Param    changedSince query  string  false  "变更时间起（不含），RFC 3339 时间，增量同步时返回此后新增或修改的数据"
+//	@Success  200     {object}  pkgs.Response{data=CountRes}  "模板数量"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  403     {object}  pkgs.Response               "没有查看全部模板的权限"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Router   /template/count [get]
+func (h *Handler) Count(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[CountReq](c),
+		result.FlatMap(pkgs.ValidateV2[CountReq](h.validator)),
+		result.FlatMap(h.repository.Count(c)),
+	).Match(
+		pkgs.HandleSuccess[CountRes](c),
+		pkgs.HandleError[CountRes](c),
+	)
+}
+
+// Transfer 转移模板所有权
+//
+//	@Summary  转移模板所有权
+//	@Description  将模板转移给其他用户，只有所有者本人或拥有 template:manage_all 权限的用户可以操作
+//	@Tags   template
+//	@Accept   json
+//	@Produce  json
+//	@Security JWT
+//	@Param    id    path  string      true  "模板ID"
+//	@Param    request body  TransferReq true  "转移模板请求参数"
+//	@Success  200   {object}  pkgs.Response{data=TransferRes}       "转移成功，返回影响行数"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  401   {object}  pkgs.Response       "未授权"
+//	@Failure  403   {object}  pkgs.Response       "无权转移该模板"
+//	@Failure  404   {object}  pkgs.Response       "模板不存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Router   /template/{id}/transfer [post]
+func (h *Handler) Transfer(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindUriAndJSON[TransferReq](c),
+		result.FlatMap(pkgs.ValidateV2[TransferReq](h.validator)),
+		result.FlatMap(h.repository.Transfer(c)),
+		result.Map(pkgs.NotifyWatchers[TransferRes](c, h.watchers, "transfer", pkgs.WatchEntityTemplate, pkgs.WatchAffected(c.Param("id")))),
+	).Match(
+		pkgs.HandleSuccess[TransferRes](c),
+		pkgs.HandleError[TransferRes](c),
+	)
+}
+
+// Use 记录模板使用
+//
+//	@Summary  记录模板使用
+//	@Description  模板使用次数加一，返回累计使用次数；列表可按 orderBy=usage_count 排序查看热门模板
+//	@Tags   template
+//	@Accept   json
+//	@Produce  json
+//	@Param    id  path  string  true  "模板ID"
+//	@Success  200 {object}  pkgs.Response{data=UseRes} "记录成功，返回累计使用次数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  404 {object}  pkgs.Response       "模板不存在"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Router   /template/{id}/use [post]
+func (h *Handler) Use(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[UseReq](c),
+		result.FlatMap(pkgs.ValidateV2[UseReq](h.validator)),
+		result.FlatMap(h.repository.Use(c)),
+	).Match(
+		pkgs.HandleSuccess[UseRes](c),
+		pkgs.HandleError[UseRes](c),
+	)
+}
+
+// Archive 归档模板
+//
+//	@Summary  归档模板
+//	@Description  归档的模板不再出现在列表与计数中，仍可通过详情接口查看；只有所有者本人或拥有 template:manage_all 权限的用户可以操作。
+//	@Description  配置了对象存储时，归档超过 archive.after 的模板由定时任务导出到对象存储并从数据库删除，可通过恢复接口取回。
+//	@Tags   template
+//	@Produce  json
+//	@Security JWT
+//	@Param    id  path  string  true  "模板ID"
+//	@Success  200 {object}  pkgs.Response{data=ArchiveRes}  "归档成功，返回模板"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  401 {object}  pkgs.Response       "未授权"
+//	@Failure  403 {object}  pkgs.Response       "无权归档该模板"
+//	@Failure  404 {object}  pkgs.Response       "模板不存在"
+//	@Failure  409 {object}  pkgs.Response       "模板已归档或仍被其他数据引用"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Router   /template/{id}/archive [post]
+func (h *Handler) Archive(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindUri[ArchiveReq](c),
+		result.FlatMap(pkgs.ValidateV2[ArchiveReq](h.validator)),
+		result.FlatMap(h.repository.Archive(c)),
+		result.Map(pkgs.NotifyWatchers[ArchiveRes](c, h.watchers, "archive", pkgs.WatchEntityTemplate, func(res ArchiveRes) []string { return []string{res.ID} })),
+	).Match(
+		pkgs.HandleSuccess[ArchiveRes](c),
+		pkgs.HandleError[ArchiveRes](c),
+	)
+}
+
+// BatchArchive 批量归档模板
+//
+//	@Summary  批量归档模板
+//	@Description  在一个事务内归档多个模板，不满足条件的模板被跳过并在 skipped 中返回原因：模板不存在或不可见（not_found）、不属于当前用户且没有 template:manage_all 权限（forbidden）、已归档（archived）、仍被其他数据引用（referenced，referenced_by 为引用方）。
+//	@Tags   template
+//	@Accept   json
+//	@Produce  json
+//	@Security JWT
+//	@Param    request body  BatchArchiveReq true  "批量归档模板请求参数"
+//	@Success  200   {object}  pkgs.Response{data=BatchArchiveRes}  "归档完成，返回已归档与跳过的模板"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  401   {object}  pkgs.Response       "未授权"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Router   /template/batch-archive [post]
+func (h *Handler) BatchArchive(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindJSON[BatchArchiveReq](c),
+		result.FlatMap(pkgs.ValidateV2[BatchArchiveReq](h.validator)),
+		result.FlatMap(h.repository.BatchArchive(c)),
+		result.Map(pkgs.NotifyWatchers[BatchArchiveRes](c, h.watchers, "archive", pkgs.WatchEntityTemplate, func(res BatchArchiveRes) []string { return res.Archived })),
+	).Match(
+		pkgs.HandleSuccess[BatchArchiveRes](c),
+		pkgs.HandleError[BatchArchiveRes](c),
+	)
+}
+
+// Restore 恢复归档的模板
+//
+//	@Summary  恢复归档的模板
+//	@Description  模板仍在数据库中时清除归档标记；已导出到对象存储时读取归档文件重新写入（包括使用次数等钩子保存的关联数据），并删除归档文件。
+//	@Description  只有所有者本人或拥有 template:manage_all 权限的用户可以操作。
+//	@Tags   template
+//	@Produce  json
+//	@Security JWT
+//	@Param    id  path  string  true  "模板ID"
+//	@Success  200 {object}  pkgs.Response{data=RestoreRes}  "恢复成功，返回模板"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  401 {object}  pkgs.Response       "未授权"
+//	@Failure  403 {object}  pkgs.Response       "无权恢复该模板"
+//	@Failure  404 {object}  pkgs.Response       "模板不存在"
+//	@Failure  409 {object}  pkgs.Response       "模板未归档"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Router   /template/{id}/restore [post]
+func (h *Handler) Restore(c *gin.Context) {
+	result.Pipe3(
+		pkgs.BindUri[RestoreReq](c),
+		result.FlatMap(pkgs.ValidateV2[RestoreReq](h.validator)),
+		result.FlatMap(h.repository.Restore(c)),
+		result.Map(pkgs.NotifyWatchers[RestoreRes](c, h.watchers, "restore", pkgs.WatchEntityTemplate, func(res RestoreRes) []string { return []string{res.ID} })),
+	).Match(
+		pkgs.HandleSuccess[RestoreRes](c),
+		pkgs.HandleError[RestoreRes](c),
+	)
+}
+
+// SyncPull 拉取模板变更
+//
+//	@Summary  拉取模板变更
+//	@Description  客户端同步：返回当前用户的模板自检查点以来的新增、修改（op=upsert，附带完整数据）与删除（op=delete，来自墓碑），按变更时间排序。
+//	@Description  首次同步不传 checkpoint，只返回现存模板；保存响应中的 checkpoint 用于下次拉取，has_more 为 true 时继续拉取。
+//	@Description  最近几秒内的变更可能在下次拉取时重复返回，客户端按 ID 与 version 去重；检查点早于墓碑保留期限时返回 410，需清空检查点全量同步。
+//	@Tags   template
+//	@Produce  json
+//	@Param    checkpoint  query string  false "上次拉取返回的检查点，为空时全量同步"
+//	@Param    limit     query int     false "每次返回的最大变更数，默认 100，最大 500"
+//	@Success  200 {object}  pkgs.Response{data=SyncPullRes} "拉取成功"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  401 {object}  pkgs.Response       "未授权"
+//	@Failure  410 {object}  pkgs.Response       "检查点已过期，需全量同步"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/sync/template"}
+//	@Router   /sync/template [get]
+func (h *Handler) SyncPull(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[SyncPullReq](c),
+		result.FlatMap(pkgs.ValidateV2[SyncPullReq](h.validator)),
+		result.FlatMap(h.repository.SyncPull(c)),
+	).Match(
+		pkgs.HandleSuccess[SyncPullRes](c),
+		pkgs.HandleError[SyncPullRes](c),
+	)
+}
+
+// SyncPush 推送模板变更
+//
+//	@Summary  推送模板变更
+//	@Description  客户端同步：在一个事务内按顺序应用客户端的新增、修改与删除。新增时 base_version 为 0，ID 由客户端生成；
+//	@Description  修改与删除的 base_version 为客户端最后看到的 version，与服务端不一致时不应用，在 conflicts 中返回原因与服务端当前数据，由客户端合并后重新推送。
+//	@Description  删除已被删除的模板视为成功。
+//	@Tags   template
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  SyncPushReq true  "推送模板变更请求参数"
+//	@Success  200   {object}  pkgs.Response{data=SyncPushRes}  "推送完成，返回已应用的变更与冲突"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  401   {object}  pkgs.Response       "未授权"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/sync/template"}
+//	@Router   /sync/template [post]
+func (h *Handler) SyncPush(c *gin.Context) {
+	result.Pipe4(
+		pkgs.BindJSON[SyncPushReq](c),
+		result.FlatMap(pkgs.ValidateV2[SyncPushReq](h.validator)),
+		result.FlatMap(h.repository.SyncPush(c)),
+		result.Map(pkgs.NotifyWatchers[SyncPushRes](c, h.watchers, "sync_update", pkgs.WatchEntityTemplate, SyncPushRes.upserted)),
+		result.Map(pkgs.NotifyWatchersDeleted[SyncPushRes](c, h.watchers, "sync_delete", pkgs.WatchEntityTemplate, SyncPushRes.deleted)),
+	).Match(
+		pkgs.HandleSuccess[SyncPushRes](c),
+		pkgs.HandleError[SyncPushRes](c),
+	)
+}
@@ -1,65 +1,171 @@
 package template
 
 import (
+	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"go-pg-demo/pkgs"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/samber/mo"
+	"github.com/samber/mo/result"
 	"go.uber.org/zap"
 )
 
 type Repository struct {
-	db     *sqlx.DB
-	logger *zap.Logger
+	db          *sqlx.DB
+	logger      *zap.Logger
+	tables      *pkgs.TableNames
+	pool        *pkgs.TenantPool
+	reporting   *pkgs.ReportingDB
+	permissions *pkgs.PermissionChecker
+	ids         *pkgs.IDGenerator
+	storage     *pkgs.Storage
+	archive     pkgs.ArchiveConfig
+	// 归档的生命周期钩子，按注册顺序执行
+	hooks []ArchiveHook
+	// 模板引用检查器，归档时按注册顺序执行
+	references []ReferenceChecker
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// reportConn 返回报表查询应使用的连接与表名，配置了分析库时在分析库查询，不占用主库
+func (r *Repository) reportConn(c *gin.Context) (*sqlx.DB, *pkgs.TableNames) {
+	return r.reporting.Conn(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+// batchConn 返回批量操作应使用的数据库连接，与交互请求的连接池隔离
+func (r *Repository) batchConn(c *gin.Context) *sqlx.DB {
+	return r.pool.BatchDB(c)
+}
+
+// owner 返回当前登录用户作为模板所有者，匿名请求返回 nil
+func (r *Repository) owner(c *gin.Context) *string {
+	if uid := pkgs.CurrentUserID(c); uid != "" {
+		return &uid
+	}
+	return nil
 }
 
 func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
 	return func(req *CreateReq) mo.Result[CreateRes] {
+		return result.Pipe1(r.insert(c)(req), result.Map(func(entity *TemplateEntity) CreateRes {
+			return CreateRes(entity.ID)
+		}))
+	}
+}
+
+// CreateEntity 创建模板并返回完整实体
+func (r *Repository) CreateEntity(c *gin.Context) func(*CreateReq) mo.Result[GetByIDRes] {
+	return func(req *CreateReq) mo.Result[GetByIDRes] {
+		return result.Pipe1(r.insert(c)(req), result.Map(func(entity *TemplateEntity) GetByIDRes {
+			return toGetByIDRes(c, entity)
+		}))
+	}
+}
+
+// insert 插入单个模板，服务端生成的 id/created_at/updated_at 通过 RETURNING 回填到实体
+func (r *Repository) insert(c *gin.Context) func(*CreateReq) mo.Result[*TemplateEntity] {
+	return func(req *CreateReq) mo.Result[*TemplateEntity] {
 		// 创建实体
+		if apiErr := r.checkRequiredPermission(c, req.RequiredPermission, "创建模板失败"); apiErr != nil {
+			return mo.Err[*TemplateEntity](apiErr)
+		}
 		entity := &TemplateEntity{
-			Name: req.Name,
-			Num:  req.Num,
+			Name:               req.Name,
+			Num:                req.Num,
+			OwnerID:            r.owner(c),
+			RequiredPermission: req.RequiredPermission,
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[*TemplateEntity](pkgs.NewApiError(http.StatusInternalServerError, "创建模板失败"))
 		}
 		// 数据库操作
-		query := `INSERT INTO template (name, num) VALUES (:name, :num) RETURNING id, created_at, updated_at`
-		stmt, err := r.db.PrepareNamedContext(c.Request.Context(), query)
+		columns, values := r.ids.Insert("name", "num", "owner_id", "required_permission")
+		query := `INSERT INTO ` + r.tables.Template + ` (` + columns + `) VALUES (` + values + `) RETURNING id, created_at, updated_at`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
 		if err != nil {
-			r.logger.Error("创建模板语句准备失败", zap.Error(err))
-			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建模板失败"))
+			r.log(c).Error("创建模板语句准备失败", zap.Error(err))
+			return mo.Err[*TemplateEntity](pkgs.NewApiError(http.StatusInternalServerError, "创建模板失败"))
 		}
 		defer stmt.Close()
 
 		err = stmt.GetContext(c.Request.Context(), entity, entity)
 		if err != nil {
-			r.logger.Error("创建模板失败", zap.Error(err))
-			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建模板失败"))
+			return mo.Err[*TemplateEntity](pkgs.DBError(r.log(c), err, "创建模板失败"))
 		}
 		// 返回结果
-		return mo.Ok(CreateRes(entity.ID))
+		return mo.Ok(entity)
 	}
 }
 
 func (r *Repository) BatchCreate(c *gin.Context) func(*BatchCreateReq) mo.Result[BatchCreateRes] {
 	return func(req *BatchCreateReq) mo.Result[BatchCreateRes] {
+		return result.Pipe1(r.batchInsert(c)(req), result.Map(func(entities []TemplateEntity) BatchCreateRes {
+			createdIDs := make(BatchCreateRes, len(entities))
+			for i, entity := range entities {
+				createdIDs[i] = entity.ID
+			}
+			return createdIDs
+		}))
+	}
+}
+
+// BatchCreateEntity 批量创建模板并返回完整实体列表
+func (r *Repository) BatchCreateEntity(c *gin.Context) func(*BatchCreateReq) mo.Result[BatchCreateEntityRes] {
+	return func(req *BatchCreateReq) mo.Result[BatchCreateEntityRes] {
+		return result.Pipe1(r.batchInsert(c)(req), result.Map(func(entities []TemplateEntity) BatchCreateEntityRes {
+			items := make(BatchCreateEntityRes, len(entities))
+			for i := range entities {
+				items[i] = toGetByIDRes(c, &entities[i])
+			}
+			return items
+		}))
+	}
+}
+
+// batchInsert 在同一事务内批量插入模板，服务端生成的字段通过 RETURNING 回填到实体
+func (r *Repository) batchInsert(c *gin.Context) func(*BatchCreateReq) mo.Result[[]TemplateEntity] {
+	return func(req *BatchCreateReq) mo.Result[[]TemplateEntity] {
 		// 准备批量插入的实体
 		var entities []TemplateEntity
+		ownerID := r.owner(c)
 		for _, t := range req.Templates {
+			if apiErr := r.checkRequiredPermission(c, t.RequiredPermission, "批量创建模板失败"); apiErr != nil {
+				return mo.Err[[]TemplateEntity](apiErr)
+			}
 			entities = append(entities, TemplateEntity{
-				Name: t.Name,
-				Num:  t.Num,
+				Name:               t.Name,
+				Num:                t.Num,
+				OwnerID:            ownerID,
+				RequiredPermission: t.RequiredPermission,
 			})
 		}
 
 		// 开启事务
-		tx, err := r.db.BeginTxx(c.Request.Context(), nil)
+		tx, err := r.batchConn(c).BeginTxx(c.Request.Context(), nil)
 		if err != nil {
-			r.logger.Error("开启事务失败", zap.Error(err))
-			return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建模板失败"))
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[[]TemplateEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建模板失败"))
 		}
 		defer func() {
 			if p := recover(); p != nil {
@@ -71,33 +177,34 @@ func (r *Repository) BatchCreate(c *gin.Context) func(*BatchCreateReq) mo.Result
 			} else {
 				err = tx.Commit()
 				if err != nil {
-					r.logger.Error("Failed to commit transaction", zap.Error(err))
+					r.log(c).Error("Failed to commit transaction", zap.Error(err))
 					return
 				}
 			}
 		}()
 
 		// 数据库操作
-		query := `INSERT INTO template (name, num) VALUES (:name, :num) RETURNING id`
+		columns, values := r.ids.Insert("name", "num", "owner_id", "required_permission")
+		query := `INSERT INTO ` + r.tables.Template + ` (` + columns + `) VALUES (` + values + `) RETURNING id, created_at, updated_at`
 		stmt, err := tx.PrepareNamedContext(c.Request.Context(), query)
 		if err != nil {
-			r.logger.Error("准备命名语句失败", zap.Error(err))
-			return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建模板失败"))
+			r.log(c).Error("准备命名语句失败", zap.Error(err))
+			return mo.Err[[]TemplateEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建模板失败"))
 		}
 		defer stmt.Close()
 
-		var createdIDs []string
-		for _, entity := range entities {
-			var id string
-			err = stmt.GetContext(c.Request.Context(), &id, entity)
+		for i := range entities {
+			if err = r.ids.Assign(&entities[i].ID); err != nil {
+				r.log(c).Error("生成主键失败", zap.Error(err))
+				return mo.Err[[]TemplateEntity](pkgs.NewApiError(http.StatusInternalServerError, "批量创建模板失败"))
+			}
+			err = stmt.GetContext(c.Request.Context(), &entities[i], entities[i])
 			if err != nil {
-				r.logger.Error("批量创建模板失败", zap.Error(err))
-				return mo.Err[BatchCreateRes](pkgs.NewApiError(http.StatusInternalServerError, "批量创建模板失败"))
+				return mo.Err[[]TemplateEntity](pkgs.DBError(r.log(c), err, "批量创建模板失败"))
 			}
-			createdIDs = append(createdIDs, id)
 		}
 
-		return mo.Ok(BatchCreateRes(createdIDs))
+		return mo.Ok(entities)
 	}
 }
 
@@ -106,25 +213,37 @@ func (r *Repository) GetByID(c *gin.Context) func(*GetByIDReq) mo.Result[GetByID
 
 		// 数据库操作
 		var entity TemplateEntity
-		query := `SELECT id, name, num, created_at, updated_at FROM template WHERE id = $1`
-		err := r.db.GetContext(c.Request.Context(), &entity, query, req.ID)
+		query := `SELECT t.id, t.name, t.num, t.owner_id, COALESCE(u.usage_count, 0) AS usage_count, t.created_at, t.updated_at, t.required_permission
+			FROM ` + r.tables.Template + ` t LEFT JOIN ` + r.tables.TemplateUsage + ` u ON u.template_id = t.id
+			WHERE t.id = $1`
+		args := []any{req.ID}
+		// 不属于当前用户、或缺少模板要求的权限时按不存在处理，不暴露其他用户的模板
+		scope, err := r.ownerCondition(c, "t.owner_id", "$2")
+		if err != nil {
+			return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "获取模板失败"))
+		}
+		if scope != "" {
+			query += scope
+			args = append(args, r.owner(c))
+		}
+		visible, allowed, err := r.permissionCondition(c, "t.required_permission", "$"+strconv.Itoa(len(args)+1))
+		if err != nil {
+			return mo.Err[GetByIDRes](pkgs.DBError(r.log(c), err, "获取模板失败"))
+		}
+		if visible != "" {
+			query += " AND " + visible
+			args = append(args, pkgs.PGArray(allowed))
+		}
+		err = r.conn(c).GetContext(c.Request.Context(), &entity, query, args...)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusNotFound, "模板不存在"))
 			}
-			r.logger.Error("获取模板失败", zap.Error(err))
-			return mo.Err[GetByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "获取模板失败"))
+			return mo.Err[GetByIDRes](pkgs.DBError(r.log(c), err, "获取模板失败"))
 		}
 
 		// 返回结果
-		response := GetByIDRes{
-			ID:        entity.ID,
-			Name:      entity.Name,
-			Num:       entity.Num,
-			CreatedAt: entity.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: entity.UpdatedAt.Format(time.RFC3339),
-		}
-		return mo.Ok(response)
+		return mo.Ok(toGetByIDRes(c, &entity))
 	}
 }
 
@@ -142,25 +261,114 @@ func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[U
 			params["num"] = *req.Num
 			setClauses = append(setClauses, "num = :num")
 		}
+		if req.RequiredPermission != nil {
+			if apiErr := r.checkRequiredPermission(c, req.RequiredPermission, "更新模板失败"); apiErr != nil {
+				return mo.Err[UpdateByIDRes](apiErr)
+			}
+			params["required_permission"] = *req.RequiredPermission
+			setClauses = append(setClauses, "required_permission = :required_permission")
+		}
 
 		// 如果没有需要更新的字段，直接返回成功
 		if len(setClauses) == 0 {
 			return mo.Ok(UpdateByIDRes(0))
 		}
 
-		query := "UPDATE template SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		query := "UPDATE " + r.tables.Template + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		scope, err := r.ownerCondition(c, "owner_id", ":owner_id")
+		if err != nil {
+			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新模板失败"))
+		}
+		query += scope
+		params["owner_id"] = r.owner(c)
+		visible, allowed, err := r.permissionCondition(c, "required_permission", ":allowed_permissions")
+		if err != nil {
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新模板失败"))
+		}
+		if visible != "" {
+			query += " AND " + visible
+			params["allowed_permissions"] = pkgs.PGArray(allowed)
+		}
 
 		// 执行数据库操作
-		res, err := r.db.NamedExecContext(c.Request.Context(), query, params)
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
 		if err != nil {
-			r.logger.Error("更新模板失败", zap.Error(err))
-			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新模板失败"))
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新模板失败"))
 		}
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
 			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新模板失败"))
 		}
+		if affectedRows == 0 && scope != "" {
+			if apiErr := r.forbidIfExists(c, req.ID, "更新模板失败", "只能修改自己的模板"); apiErr != nil {
+				return mo.Err[UpdateByIDRes](apiErr)
+			}
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// PatchByID 按 JSON Merge Patch 部分更新模板，显式 null 的可空字段会被清空
+func (r *Repository) PatchByID(c *gin.Context) func(*PatchByIDReq) mo.Result[PatchByIDRes] {
+	return func(req *PatchByIDReq) mo.Result[PatchByIDRes] {
+		// 动态构建更新语句，只处理补丁中出现的字段
+		params := map[string]any{"id": req.ID}
+		var setClauses []string
+
+		if req.Has("name") {
+			params["name"] = *req.Name
+			setClauses = append(setClauses, "name = :name")
+		}
+		if req.Has("num") {
+			params["num"] = req.Num
+			setClauses = append(setClauses, "num = :num")
+		}
+		if req.Has("required_permission") {
+			if apiErr := r.checkRequiredPermission(c, req.RequiredPermission, "更新模板失败"); apiErr != nil {
+				return mo.Err[PatchByIDRes](apiErr)
+			}
+			params["required_permission"] = req.RequiredPermission
+			setClauses = append(setClauses, "required_permission = :required_permission")
+		}
+
+		// 如果没有需要更新的字段，直接返回成功
+		if len(setClauses) == 0 {
+			return mo.Ok(PatchByIDRes(0))
+		}
+
+		query := "UPDATE " + r.tables.Template + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id"
+		scope, err := r.ownerCondition(c, "owner_id", ":owner_id")
+		if err != nil {
+			return mo.Err[PatchByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新模板失败"))
+		}
+		query += scope
+		params["owner_id"] = r.owner(c)
+		visible, allowed, err := r.permissionCondition(c, "required_permission", ":allowed_permissions")
+		if err != nil {
+			return mo.Err[PatchByIDRes](pkgs.DBError(r.log(c), err, "更新模板失败"))
+		}
+		if visible != "" {
+			query += " AND " + visible
+			params["allowed_permissions"] = pkgs.PGArray(allowed)
+		}
+
+		// 执行数据库操作
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
+		if err != nil {
+			return mo.Err[PatchByIDRes](pkgs.DBError(r.log(c), err, "更新模板失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[PatchByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新模板失败"))
+		}
+		if affectedRows == 0 && scope != "" {
+			if apiErr := r.forbidIfExists(c, req.ID, "更新模板失败", "只能修改自己的模板"); apiErr != nil {
+				return mo.Err[PatchByIDRes](apiErr)
+			}
+		}
 		// 返回结果
 		return mo.Ok(affectedRows)
 	}
@@ -169,17 +377,38 @@ func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[U
 func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
 	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
 		// 数据库操作
-		query := `DELETE FROM template WHERE id = $1`
-		res, err := r.db.ExecContext(c.Request.Context(), query, req.ID)
+		query := `DELETE FROM ` + r.tables.Template + ` WHERE id = $1`
+		args := []any{req.ID}
+		scope, err := r.ownerCondition(c, "owner_id", "$2")
 		if err != nil {
-			r.logger.Error("删除模板失败", zap.Error(err))
 			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除模板失败"))
 		}
+		if scope != "" {
+			query += scope
+			args = append(args, r.owner(c))
+		}
+		visible, allowed, err := r.permissionCondition(c, "required_permission", "$"+strconv.Itoa(len(args)+1))
+		if err != nil {
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除模板失败"))
+		}
+		if visible != "" {
+			query += " AND " + visible
+			args = append(args, pkgs.PGArray(allowed))
+		}
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, args...)
+		if err != nil {
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除模板失败"))
+		}
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
 			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除模板失败"))
 		}
+		if affectedRows == 0 && scope != "" {
+			if apiErr := r.forbidIfExists(c, req.ID, "删除模板失败", "只能删除自己的模板"); apiErr != nil {
+				return mo.Err[DeleteByIDRes](apiErr)
+			}
+		}
 
 		// 返回结果
 		return mo.Ok(affectedRows)
@@ -188,23 +417,15 @@ func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[D
 
 func (r *Repository) BatchDelete(c *gin.Context) func(*DeleteTemplatesReq) mo.Result[BatchDeleteRes] {
 	return func(req *DeleteTemplatesReq) mo.Result[BatchDeleteRes] {
-		query, args, err := sqlx.In(`DELETE FROM template WHERE id IN (?)`, req.IDs)
+		query := `DELETE FROM ` + r.tables.Template + ` WHERE id = ANY($1)`
+		res, err := r.batchConn(c).ExecContext(c.Request.Context(), query, pkgs.PGArray(req.IDs))
 		if err != nil {
-			r.logger.Error("构建批量删除查询失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "构建批量删除查询失败"))
-		}
-
-		query = r.db.Rebind(query)
-		res, err := r.db.ExecContext(c.Request.Context(), query, args...)
-		if err != nil {
-			r.logger.Error("批量删除模板失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "批量删除模板失败"))
+			return mo.Err[BatchDeleteRes](pkgs.DBError(r.log(c), err, "批量删除模板失败"))
 		}
 
 		affectedRows, err := res.RowsAffected()
 		if err != nil {
-			r.logger.Error("获取影响行数失败", zap.Error(err))
-			return mo.Err[BatchDeleteRes](pkgs.NewApiError(http.StatusInternalServerError, "获取影响行数失败"))
+			return mo.Err[BatchDeleteRes](pkgs.DBError(r.log(c), err, "获取影响行数失败"))
 		}
 
 		return mo.Ok(affectedRows)
@@ -213,107 +434,1107 @@ func (r *Repository) BatchDelete(c *gin.Context) func(*DeleteTemplatesReq) mo.Re
 
 func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
 	return func(req *QueryListReq) mo.Result[QueryListRes] {
-		// 校验排序字段
-		validOrderBy := map[string]bool{
-			"id":         true,
-			"name":       true,
-			"num":        true,
-			"created_at": true,
-			"updated_at": true,
+		// 排序字段与排序顺序已在请求参数中校验
+		upperOrder := pkgs.SortDirection(req.Order)
+
+		// 构建查询
+		params := map[string]any{
+			"limit":  req.PageSize,
+			"offset": req.Offset(),
 		}
-		if !validOrderBy[req.OrderBy] {
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "排序字段不存在"))
+
+		whereCondition, apiErr := r.listWhere(c, &req.ListFilter, params)
+		if apiErr != nil {
+			return mo.Err[QueryListRes](apiErr)
 		}
 
-		// 校验排序顺序
-		upperOrder := strings.ToUpper(req.Order)
-		if upperOrder != "ASC" && upperOrder != "DESC" {
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "排序顺序参数错误"))
+		// 排序与分页
+		// seq 作为决胜字段，保证排序字段值相同时（如批量插入的 created_at、使用次数）分页顺序稳定
+		orderClause := ` ORDER BY ` + req.OrderBy + ` ` + upperOrder + `, seq ` + upperOrder + ` LIMIT :limit OFFSET :offset`
+		var total int64
+		if req.CursorPagination.Enabled() {
+			// 游标分页不统计总数
+			whereCondition, orderClause = req.CursorPagination.Keyset(whereCondition, upperOrder, params)
+		} else {
+			// 查询总数
+			var err error
+			total, err = r.count(c, r.conn(c), r.tables.Template, whereCondition, params)
+			if err != nil {
+				r.log(c).Error("统计模板数量失败", zap.Error(err))
+				return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询模板列表失败"))
+			}
+
+			if total == 0 {
+				return mo.Ok(QueryListRes{
+					List:  []TemplateItem{},
+					Total: 0,
+				})
+			}
 		}
 
-		// 构建查询
-		params := map[string]any{
-			"limit":  req.PageSize,
-			"offset": (req.Page - 1) * req.PageSize,
+		// 查询列表
+		listQuery := `SELECT id, name, num, owner_id, COALESCE(u.usage_count, 0) AS usage_count, created_at, updated_at, required_permission, seq FROM ` + r.tables.Template +
+			` t LEFT JOIN ` + r.tables.TemplateUsage + ` u ON u.template_id = t.id` + whereCondition + orderClause
+		// 逐行扫描前检查请求是否已取消，并检查遍历是否中途失败
+		entities, err := pkgs.NamedQueryAll[templateListEntity](c.Request.Context(), r.conn(c), listQuery, params)
+		if err != nil {
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询模板列表失败"))
 		}
+		entities, nextCursor := pkgs.CursorPage(req.CursorPagination, entities, func(e templateListEntity) (time.Time, int64) { return e.CreatedAt, e.Seq })
 
-		var whereClauses []string
-		if req.Name != "" {
-			whereClauses = append(whereClauses, "name ILIKE :name")
-			params["name"] = "%" + req.Name + "%"
+		// 转换并返回结果
+		var responseEntities []TemplateItem
+		for _, entity := range entities {
+			responseEntities = append(responseEntities, TemplateItem{
+				ID:         entity.ID,
+				Name:       entity.Name,
+				Num:        entity.Num,
+				OwnerID:    entity.OwnerID,
+				UsageCount: entity.UsageCount,
+				CreatedAt:  pkgs.FormatTime(c, entity.CreatedAt),
+				UpdatedAt:  pkgs.FormatTime(c, entity.UpdatedAt),
+
+				RequiredPermission: entity.RequiredPermission,
+			})
 		}
 
-		whereCondition := ""
-		if len(whereClauses) > 0 {
-			whereCondition = " WHERE " + strings.Join(whereClauses, " AND ")
+		return mo.Ok(QueryListRes{
+			List:       responseEntities,
+			Total:      total,
+			NextCursor: nextCursor,
+		})
+	}
+}
+
+// CheckModified 列表的条件请求：模板表自上次请求后没有变化时返回 304
+func (r *Repository) CheckModified(c *gin.Context) func(*QueryListReq) mo.Result[*QueryListReq] {
+	return pkgs.CheckModified[QueryListReq](c, r.conn(c), r.tables.Template)
+}
+
+// Count 统计满足筛选条件的模板数量，筛选条件（含查询范围）与列表接口相同，在报表连接上查询
+func (r *Repository) Count(c *gin.Context) func(*CountReq) mo.Result[CountRes] {
+	return func(req *CountReq) mo.Result[CountRes] {
+		params := map[string]any{}
+		whereCondition, apiErr := r.listWhere(c, req, params)
+		if apiErr != nil {
+			return mo.Err[CountRes](apiErr)
 		}
+		db, tables := r.reportConn(c)
+		total, err := r.count(c, db, tables.Template, whereCondition, params)
+		if err != nil {
+			return mo.Err[CountRes](pkgs.DBError(r.log(c), err, "统计模板数量失败"))
+		}
+		return mo.Ok(total)
+	}
+}
 
-		// 查询总数
-		var total int64
-		countQuery := "SELECT count(*) FROM template" + whereCondition
-		// 使用 NamedExec 而不是 PrepareNamed
-		rows, err := r.db.NamedQueryContext(c.Request.Context(), countQuery, params)
+// listWhere 构建列表与计数接口共用的查询条件，返回 WHERE 子句，没有条件时为空
+// 查询范围：默认只查自己的模板（匿名请求对应无所有者的模板），查询全部需要编码权限
+func (r *Repository) listWhere(c *gin.Context, filter *ListFilter, params map[string]any) (string, *pkgs.ApiError) {
+	var whereClauses []string
+	if filter.Name != "" {
+		whereClauses = append(whereClauses, "name ILIKE :name")
+		params["name"] = "%" + filter.Name + "%"
+	}
+	if filter.Num != nil {
+		whereClauses = append(whereClauses, "num = :num")
+		params["num"] = *filter.Num
+	}
+	if filter.NumMin != nil {
+		whereClauses = append(whereClauses, "num >= :num_min")
+		params["num_min"] = *filter.NumMin
+	}
+	if filter.NumMax != nil {
+		whereClauses = append(whereClauses, "num <= :num_max")
+		params["num_max"] = *filter.NumMax
+	}
+	whereClauses = append(whereClauses, filter.DateRange.Where(c, params)...)
+	whereClauses = append(whereClauses, filterColumns.Where(c, filter.Filter, params)...)
+	// 归档的模板不出现在列表中，可通过详情接口查看或恢复
+	whereClauses = append(whereClauses, "archived_at IS NULL")
+
+	if filter.Scope == ScopeAll {
+		allowed, err := r.permissions.HasCode(c, PermissionCodeManageAll)
 		if err != nil {
-			r.logger.Error("准备命名计数查询失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询模板列表失败"))
+			return "", pkgs.NewApiError(http.StatusInternalServerError, "查询模板列表失败")
+		}
+		if !allowed {
+			return "", pkgs.NewApiError(http.StatusForbidden, "没有查看全部模板的权限")
 		}
-		defer rows.Close()
+	} else {
+		whereClauses = append(whereClauses, "owner_id IS NOT DISTINCT FROM CAST(:owner_id AS uuid)")
+		params["owner_id"] = r.owner(c)
+	}
+	visible, allowed, err := r.permissionCondition(c, "required_permission", ":allowed_permissions")
+	if err != nil {
+		return "", pkgs.DBError(r.log(c), err, "查询模板列表失败")
+	}
+	if visible != "" {
+		whereClauses = append(whereClauses, visible)
+		params["allowed_permissions"] = pkgs.PGArray(allowed)
+	}
 
-		if rows.Next() {
-			err = rows.Scan(&total)
+	return " WHERE " + strings.Join(whereClauses, " AND "), nil
+}
+
+// count 统计满足查询条件的模板数量
+func (r *Repository) count(c *gin.Context, db *sqlx.DB, table, whereCondition string, params map[string]any) (int64, error) {
+	query, args, err := db.BindNamed("SELECT count(*) FROM "+table+whereCondition, params)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	err = db.GetContext(c.Request.Context(), &total, query, args...)
+	return total, err
+}
+
+// Transfer 转移模板所有权：所有者本人或拥有管理全部模板权限的用户可以操作
+func (r *Repository) Transfer(c *gin.Context) func(*TransferReq) mo.Result[TransferRes] {
+	return func(req *TransferReq) mo.Result[TransferRes] {
+		uid := pkgs.CurrentUserID(c)
+		if uid == "" {
+			return mo.Err[TransferRes](pkgs.NewApiError(http.StatusUnauthorized, "未授权"))
+		}
+
+		// 查询当前所有者，缺少模板要求的权限时按不存在处理
+		visible, allowed, err := r.permissionCondition(c, "required_permission", "$2")
+		if err != nil {
+			return mo.Err[TransferRes](pkgs.DBError(r.log(c), err, "转移模板失败"))
+		}
+		args := []any{req.ID}
+		scope := ""
+		if visible != "" {
+			scope = " AND " + visible
+			args = append(args, pkgs.PGArray(allowed))
 		}
+		var ownerID *string
+		err = r.conn(c).GetContext(c.Request.Context(), &ownerID, `SELECT owner_id FROM `+r.tables.Template+` WHERE id = $1`+scope, args...)
 		if err != nil {
-			r.logger.Error("统计模板数量失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询模板列表失败"))
+			if err == sql.ErrNoRows {
+				return mo.Err[TransferRes](pkgs.NewApiError(http.StatusNotFound, "模板不存在"))
+			}
+			r.log(c).Error("查询模板所有者失败", zap.Error(err))
+			return mo.Err[TransferRes](pkgs.NewApiError(http.StatusInternalServerError, "转移模板失败"))
+		}
+		if ownerID == nil || *ownerID != uid {
+			allowed, err := r.permissions.HasCode(c, PermissionCodeManageAll)
+			if err != nil {
+				return mo.Err[TransferRes](pkgs.NewApiError(http.StatusInternalServerError, "转移模板失败"))
+			}
+			if !allowed {
+				return mo.Err[TransferRes](pkgs.NewApiError(http.StatusForbidden, "只能转移自己的模板"))
+			}
 		}
 
-		if total == 0 {
-			return mo.Ok(QueryListRes{
-				List:  []TemplateItem{},
-				Total: 0,
-			})
+		// 校验新所有者存在
+		var exists bool
+		err = r.conn(c).GetContext(c.Request.Context(), &exists, `SELECT EXISTS(SELECT 1 FROM `+r.tables.User+` WHERE id = $1)`, req.OwnerID)
+		if err != nil {
+			r.log(c).Error("查询用户失败", zap.Error(err))
+			return mo.Err[TransferRes](pkgs.NewApiError(http.StatusInternalServerError, "转移模板失败"))
+		}
+		if !exists {
+			return mo.Err[TransferRes](pkgs.NewApiError(http.StatusBadRequest, "新所有者不存在"))
 		}
 
-		// 查询列表
-		var entities []TemplateEntity
-		listQuery := `SELECT id, name, num, created_at, updated_at FROM template` + whereCondition + ` ORDER BY ` + req.OrderBy + ` ` + upperOrder + ` LIMIT :limit OFFSET :offset`
-		// 使用 NamedQuery 而不是 PrepareNamed
-		rows, err = r.db.NamedQueryContext(c.Request.Context(), listQuery, params)
+		// 数据库操作
+		query := `UPDATE ` + r.tables.Template + ` SET owner_id = $` + strconv.Itoa(len(args)+1) + ` WHERE id = $1` + scope
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, append(args, req.OwnerID)...)
+		if err != nil {
+			return mo.Err[TransferRes](pkgs.DBError(r.log(c), err, "转移模板失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[TransferRes](pkgs.NewApiError(http.StatusInternalServerError, "转移模板失败"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// Use 记录一次模板使用，返回累计使用次数
+func (r *Repository) Use(c *gin.Context) func(*UseReq) mo.Result[UseRes] {
+	return func(req *UseReq) mo.Result[UseRes] {
+		// 数据库操作：模板不存在或缺少模板要求的权限时不插入任何行
+		visible, allowed, err := r.permissionCondition(c, "required_permission", "$2")
+		if err != nil {
+			return mo.Err[UseRes](pkgs.DBError(r.log(c), err, "记录模板使用失败"))
+		}
+		args := []any{req.ID}
+		scope := ""
+		if visible != "" {
+			scope = " AND " + visible
+			args = append(args, pkgs.PGArray(allowed))
+		}
+		var usageCount int64
+		query := `INSERT INTO ` + r.tables.TemplateUsage + ` AS u (template_id, usage_count)
+			SELECT id, 1 FROM ` + r.tables.Template + ` WHERE id = $1` + scope + `
+			ON CONFLICT (template_id) DO UPDATE SET usage_count = u.usage_count + 1, last_used_at = CURRENT_TIMESTAMP
+			RETURNING usage_count`
+		err = r.conn(c).GetContext(c.Request.Context(), &usageCount, query, args...)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return mo.Err[UseRes](pkgs.NewApiError(http.StatusNotFound, "模板不存在"))
+			}
+			return mo.Err[UseRes](pkgs.DBError(r.log(c), err, "记录模板使用失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(usageCount)
+	}
+}
+
+// ownerCondition 返回按所有者限定单个模板读写的查询条件，与列表接口的默认范围一致：
+// 拥有管理全部模板权限的用户不限定（返回空字符串）；其他用户只能访问自己的模板，匿名请求只能访问无所有者的模板。
+// 条件使用 param 作为所有者参数，调用方传入 r.owner(c)。
+func (r *Repository) ownerCondition(c *gin.Context, column, param string) (string, error) {
+	if pkgs.CurrentUserID(c) != "" {
+		allowed, err := r.permissions.HasCode(c, PermissionCodeManageAll)
+		if err != nil {
+			return "", err
+		}
+		if allowed {
+			return "", nil
+		}
+	}
+	return " AND " + column + " IS NOT DISTINCT FROM CAST(" + param + " AS uuid)", nil
+}
+
+// forbidIfExists 按所有者与模板要求的权限限定的写操作未影响任何行时，区分模板不存在（保持影响行数为 0）、
+// 缺少模板要求的权限（与获取一致按不存在处理，返回 404）与不属于当前用户（403）
+func (r *Repository) forbidIfExists(c *gin.Context, id, message, forbidden string) *pkgs.ApiError {
+	var state struct {
+		Exists  bool `db:"exists"`
+		Visible bool `db:"visible"`
+	}
+	query := `SELECT TRUE AS exists, TRUE AS visible FROM ` + r.tables.Template + ` WHERE id = $1`
+	args := []any{id}
+	visible, allowed, err := r.permissionCondition(c, "required_permission", "$2")
+	if err != nil {
+		return pkgs.DBError(r.log(c), err, message)
+	}
+	if visible != "" {
+		query = `SELECT TRUE AS exists, ` + visible + ` AS visible FROM ` + r.tables.Template + ` WHERE id = $1`
+		args = append(args, pkgs.PGArray(allowed))
+	}
+	err = r.conn(c).GetContext(c.Request.Context(), &state, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return pkgs.DBError(r.log(c), err, message)
+	}
+	switch {
+	case !state.Exists:
+		return nil
+	case !state.Visible:
+		return pkgs.NewApiError(http.StatusNotFound, "模板不存在")
+	default:
+		return pkgs.NewApiError(http.StatusForbidden, forbidden)
+	}
+}
+
+// permissionCondition 按模板要求的权限限定可见范围：设置了 required_permission 的模板只对拥有该权限的用户可见
+// 拥有管理全部模板权限的用户不限定（返回空字符串）。先查出模板要求的全部权限编码（通常只有少数几个），
+// 按当前用户的权限（含通配与拒绝规则）求出拥有的部分作为 param 参数的值（allowed），分页与计数仍在数据库中完成。
+func (r *Repository) permissionCondition(c *gin.Context, column, param string) (string, []string, error) {
+	if pkgs.CurrentUserID(c) != "" {
+		manageAll, err := r.permissions.HasCode(c, PermissionCodeManageAll)
+		if err != nil {
+			return "", nil, err
+		}
+		if manageAll {
+			return "", nil, nil
+		}
+	}
+	var required []string
+	query := `SELECT DISTINCT required_permission FROM ` + r.tables.Template + ` WHERE required_permission IS NOT NULL`
+	if err := r.conn(c).SelectContext(c.Request.Context(), &required, query); err != nil {
+		return "", nil, err
+	}
+	allowed, err := r.permissions.AllowedCodes(c, required)
+	if err != nil {
+		return "", nil, err
+	}
+	return "(" + column + " IS NULL OR " + column + " = ANY(" + param + "))", allowed, nil
+}
+
+// watchVisible 返回 userIDs 中仍可以查看模板的关注者：模板要求权限时只保留拥有该权限或管理全部模板权限的用户，
+// 可见规则与 permissionCondition 相同；模板已不存在时不保留任何人
+func (r *Repository) watchVisible(c *gin.Context, id string, userIDs []string) ([]string, error) {
+	var required *string
+	err := r.conn(c).GetContext(c.Request.Context(), &required, `SELECT required_permission FROM `+r.tables.Template+` WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil || required == nil {
+		return userIDs, err
+	}
+	var visible []string
+	for _, userID := range userIDs {
+		perms, err := r.permissions.UserPermissions(c, userID)
+		if err != nil {
+			return nil, err
+		}
+		if pkgs.CodeAllowed(perms, *required) || pkgs.CodeAllowed(perms, PermissionCodeManageAll) {
+			visible = append(visible, userID)
+		}
+	}
+	return visible, nil
+}
+
+// checkRequiredPermission 设置模板要求的权限时，当前用户须拥有该权限或管理全部模板的权限，避免设置后自己也看不到模板
+func (r *Repository) checkRequiredPermission(c *gin.Context, code *string, message string) *pkgs.ApiError {
+	if code == nil {
+		return nil
+	}
+	allowed, err := r.permissions.AllowedCodes(c, []string{*code, PermissionCodeManageAll})
+	if err != nil {
+		return pkgs.NewApiError(http.StatusInternalServerError, message)
+	}
+	if len(allowed) == 0 {
+		return pkgs.NewApiError(http.StatusForbidden, "只能要求自己拥有的权限")
+	}
+	return nil
+}
+
+// checkOwner 校验当前用户可以操作所有者为 ownerID 的模板：所有者本人或拥有管理全部模板权限的用户
+func (r *Repository) checkOwner(c *gin.Context, ownerID *string, message, forbidden string) *pkgs.ApiError {
+	uid := pkgs.CurrentUserID(c)
+	if uid == "" {
+		return pkgs.NewApiError(http.StatusUnauthorized, "未授权")
+	}
+	if ownerID != nil && *ownerID == uid {
+		return nil
+	}
+	allowed, err := r.permissions.HasCode(c, PermissionCodeManageAll)
+	if err != nil {
+		return pkgs.NewApiError(http.StatusInternalServerError, message)
+	}
+	if !allowed {
+		return pkgs.NewApiError(http.StatusForbidden, forbidden)
+	}
+	return nil
+}
+
+// archiveState 模板的所有者与归档状态
+type archiveState struct {
+	OwnerID    *string    `db:"owner_id"`
+	ArchivedAt *time.Time `db:"archived_at"`
+}
+
+// Archive 归档模板：所有者本人或拥有管理全部模板权限的用户可以操作
+// 归档的模板不再出现在列表中，归档超过 archive.after 后由定时任务导出到对象存储并从数据库删除
+func (r *Repository) Archive(c *gin.Context) func(*ArchiveReq) mo.Result[ArchiveRes] {
+	return func(req *ArchiveReq) mo.Result[ArchiveRes] {
+		// 缺少模板要求的权限时按不存在处理
+		visible, allowed, err := r.permissionCondition(c, "required_permission", "$2")
+		if err != nil {
+			return mo.Err[ArchiveRes](pkgs.DBError(r.log(c), err, "归档模板失败"))
+		}
+		args := []any{req.ID}
+		scope := ""
+		if visible != "" {
+			scope = " AND " + visible
+			args = append(args, pkgs.PGArray(allowed))
+		}
+
+		ctx := c.Request.Context()
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[ArchiveRes](pkgs.NewApiError(http.StatusInternalServerError, "归档模板失败"))
+		}
+		defer tx.Rollback()
+
+		// 锁定模板行，引用检查与归档在同一事务内完成，与批量归档一致
+		var state archiveState
+		query := `SELECT owner_id, archived_at FROM ` + r.tables.Template + ` WHERE id = $1` + scope + ` FOR UPDATE`
+		err = tx.GetContext(ctx, &state, query, args...)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return mo.Err[ArchiveRes](pkgs.NewApiError(http.StatusNotFound, "模板不存在"))
+			}
+			return mo.Err[ArchiveRes](pkgs.DBError(r.log(c), err, "归档模板失败"))
+		}
+		if apiErr := r.checkOwner(c, state.OwnerID, "归档模板失败", "只能归档自己的模板"); apiErr != nil {
+			return mo.Err[ArchiveRes](apiErr)
+		}
+
+		if state.ArchivedAt != nil {
+			return mo.Err[ArchiveRes](pkgs.NewApiError(http.StatusConflict, "模板已归档"))
+		}
+		referenced, err := r.referencedBy(ctx, tx, []string{req.ID})
+		if err != nil {
+			r.log(c).Error("检查模板引用失败", zap.Error(err))
+			return mo.Err[ArchiveRes](pkgs.NewApiError(http.StatusInternalServerError, "归档模板失败"))
+		}
+		if names := referenced[req.ID]; len(names) > 0 {
+			return mo.Err[ArchiveRes](pkgs.NewApiError(http.StatusConflict, "模板仍被引用："+strings.Join(names, "、")))
+		}
+
+		// 数据库操作
+		query = `UPDATE ` + r.tables.Template + ` SET archived_at = CURRENT_TIMESTAMP WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, req.ID); err != nil {
+			return mo.Err[ArchiveRes](pkgs.DBError(r.log(c), err, "归档模板失败"))
+		}
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交事务失败", zap.Error(err))
+			return mo.Err[ArchiveRes](pkgs.NewApiError(http.StatusInternalServerError, "归档模板失败"))
+		}
+
+		// 返回结果
+		return r.GetByID(c)(&GetByIDReq{ID: req.ID})
+	}
+}
+
+// batchArchiveRow 批量归档时锁定的模板
+type batchArchiveRow struct {
+	ID string `db:"id"`
+	archiveState
+}
+
+// BatchArchive 在一个事务内批量归档模板，跳过不存在、无权操作、已归档与仍被引用的模板并返回原因
+// 权限规则与单个归档相同；模板行按 ID 顺序加锁，引用检查与归档在同一事务内完成，检查后新增的引用需等待归档提交。
+func (r *Repository) BatchArchive(c *gin.Context) func(*BatchArchiveReq) mo.Result[BatchArchiveRes] {
+	return func(req *BatchArchiveReq) mo.Result[BatchArchiveRes] {
+		uid := pkgs.CurrentUserID(c)
+		if uid == "" {
+			return mo.Err[BatchArchiveRes](pkgs.NewApiError(http.StatusUnauthorized, "未授权"))
+		}
+		manageAll, err := r.permissions.HasCode(c, PermissionCodeManageAll)
+		if err != nil {
+			return mo.Err[BatchArchiveRes](pkgs.NewApiError(http.StatusInternalServerError, "批量归档模板失败"))
+		}
+
+		// 缺少模板要求的权限时按不存在处理
+		query := `SELECT id, owner_id, archived_at FROM ` + r.tables.Template + ` WHERE id = ANY($1)`
+		args := []any{pkgs.PGArray(req.IDs)}
+		visible, allowed, err := r.permissionCondition(c, "required_permission", "$2")
+		if err != nil {
+			return mo.Err[BatchArchiveRes](pkgs.DBError(r.log(c), err, "批量归档模板失败"))
+		}
+		if visible != "" {
+			query += " AND " + visible
+			args = append(args, pkgs.PGArray(allowed))
+		}
+		query += ` ORDER BY id FOR UPDATE`
+
+		ctx := c.Request.Context()
+		tx, err := r.batchConn(c).BeginTxx(ctx, nil)
+		if err != nil {
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[BatchArchiveRes](pkgs.NewApiError(http.StatusInternalServerError, "批量归档模板失败"))
+		}
+		defer tx.Rollback()
+
+		var rows []batchArchiveRow
+		if err := tx.SelectContext(ctx, &rows, query, args...); err != nil {
+			return mo.Err[BatchArchiveRes](pkgs.DBError(r.log(c), err, "批量归档模板失败"))
+		}
+		found := make(map[string]batchArchiveRow, len(rows))
+		for _, row := range rows {
+			found[row.ID] = row
+		}
+
+		// 按请求顺序检查每个模板
+		res := BatchArchiveRes{Archived: []string{}, Skipped: []BatchArchiveSkipped{}}
+		var candidates []string
+		for _, id := range req.IDs {
+			row, ok := found[id]
+			switch {
+			case !ok:
+				res.Skipped = append(res.Skipped, BatchArchiveSkipped{ID: id, Reason: ArchiveSkipNotFound})
+			case !manageAll && (row.OwnerID == nil || *row.OwnerID != uid):
+				res.Skipped = append(res.Skipped, BatchArchiveSkipped{ID: id, Reason: ArchiveSkipForbidden})
+			case row.ArchivedAt != nil:
+				res.Skipped = append(res.Skipped, BatchArchiveSkipped{ID: id, Reason: ArchiveSkipArchived})
+			default:
+				candidates = append(candidates, id)
+			}
+		}
+		referenced, err := r.referencedBy(ctx, tx, candidates)
+		if err != nil {
+			r.log(c).Error("检查模板引用失败", zap.Error(err))
+			return mo.Err[BatchArchiveRes](pkgs.NewApiError(http.StatusInternalServerError, "批量归档模板失败"))
+		}
+		for _, id := range candidates {
+			if names := referenced[id]; len(names) > 0 {
+				res.Skipped = append(res.Skipped, BatchArchiveSkipped{ID: id, Reason: ArchiveSkipReferenced, ReferencedBy: names})
+			} else {
+				res.Archived = append(res.Archived, id)
+			}
+		}
+
+		// 数据库操作
+		if len(res.Archived) > 0 {
+			query = `UPDATE ` + r.tables.Template + ` SET archived_at = CURRENT_TIMESTAMP WHERE id = ANY($1)`
+			if _, err := tx.ExecContext(ctx, query, pkgs.PGArray(res.Archived)); err != nil {
+				return mo.Err[BatchArchiveRes](pkgs.DBError(r.log(c), err, "批量归档模板失败"))
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交事务失败", zap.Error(err))
+			return mo.Err[BatchArchiveRes](pkgs.NewApiError(http.StatusInternalServerError, "批量归档模板失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(res)
+	}
+}
+
+// referencedBy 依次执行各引用检查器，返回仍被引用的模板ID及引用它的检查器名称
+func (r *Repository) referencedBy(ctx context.Context, tx *sqlx.Tx, ids []string) (map[string][]string, error) {
+	referenced := map[string][]string{}
+	if len(ids) == 0 {
+		return referenced, nil
+	}
+	for _, checker := range r.references {
+		found, err := checker.Referenced(ctx, tx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", checker.Name, err)
+		}
+		for _, id := range found {
+			referenced[id] = append(referenced[id], checker.Name)
+		}
+	}
+	return referenced, nil
+}
+
+// watchReferenceChecker 内置的引用检查器：模板所有者以外的用户关注的模板仍被引用
+func (r *Repository) watchReferenceChecker() ReferenceChecker {
+	return ReferenceChecker{
+		Name: "watch",
+		Referenced: func(ctx context.Context, tx *sqlx.Tx, ids []string) ([]string, error) {
+			var referenced []string
+			query := `SELECT DISTINCT w.entity_id FROM ` + r.tables.Watch + ` w
+				JOIN ` + r.tables.Template + ` t ON t.id = w.entity_id
+				WHERE w.entity = $1 AND w.entity_id = ANY($2) AND w.user_id IS DISTINCT FROM t.owner_id`
+			err := tx.SelectContext(ctx, &referenced, query, pkgs.WatchEntityTemplate, pkgs.PGArray(ids))
+			return referenced, err
+		},
+	}
+}
+
+// usageReferenceChecker 内置的引用检查器：最近 recent 内使用过的模板仍被引用
+func (r *Repository) usageReferenceChecker(recent time.Duration) ReferenceChecker {
+	return ReferenceChecker{
+		Name: "template_usage",
+		Referenced: func(ctx context.Context, tx *sqlx.Tx, ids []string) ([]string, error) {
+			var referenced []string
+			query := `SELECT template_id FROM ` + r.tables.TemplateUsage + `
+				WHERE template_id = ANY($1) AND last_used_at > CURRENT_TIMESTAMP - make_interval(secs => $2)`
+			err := tx.SelectContext(ctx, &referenced, query, pkgs.PGArray(ids), recent.Seconds())
+			return referenced, err
+		},
+	}
+}
+
+// Restore 恢复归档的模板
+// 模板仍在数据库中时清除归档标记；已导出到对象存储时读取归档文件重新写入数据库，并删除归档文件
+func (r *Repository) Restore(c *gin.Context) func(*RestoreReq) mo.Result[RestoreRes] {
+	return func(req *RestoreReq) mo.Result[RestoreRes] {
+		var state archiveState
+		query := `SELECT owner_id, archived_at FROM ` + r.tables.Template + ` WHERE id = $1`
+		err := r.conn(c).GetContext(c.Request.Context(), &state, query, req.ID)
+		if err == sql.ErrNoRows {
+			return r.rehydrate(c, req.ID)
+		}
+		if err != nil {
+			return mo.Err[RestoreRes](pkgs.DBError(r.log(c), err, "恢复模板失败"))
+		}
+		if apiErr := r.checkOwner(c, state.OwnerID, "恢复模板失败", "只能恢复自己的模板"); apiErr != nil {
+			return mo.Err[RestoreRes](apiErr)
+		}
+		if state.ArchivedAt == nil {
+			return mo.Err[RestoreRes](pkgs.NewApiError(http.StatusConflict, "模板未归档"))
+		}
+
+		// 数据库操作
+		query = `UPDATE ` + r.tables.Template + ` SET archived_at = NULL WHERE id = $1`
+		if _, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID); err != nil {
+			return mo.Err[RestoreRes](pkgs.DBError(r.log(c), err, "恢复模板失败"))
+		}
+
+		// 返回结果
+		return r.GetByID(c)(&GetByIDReq{ID: req.ID})
+	}
+}
+
+// rehydrate 从对象存储读取归档文件，在一个事务内重新写入模板并执行各钩子的恢复
+// 恢复后的模板 updated_at 为当前时间并删除同步墓碑，离线客户端下次拉取时重新获得该模板。
+// 所有者已被删除时恢复为无所有者的模板。
+func (r *Repository) rehydrate(c *gin.Context, id string) mo.Result[RestoreRes] {
+	ctx := c.Request.Context()
+	key := archiveKey(pkgs.TenantFromContext(c), id)
+	data, err := r.storage.Get(ctx, key)
+	if errors.Is(err, pkgs.ErrObjectNotFound) {
+		return mo.Err[RestoreRes](pkgs.NewApiError(http.StatusNotFound, "模板不存在"))
+	}
+	if err != nil {
+		r.log(c).Error("读取模板归档失败", zap.String("key", key), zap.Error(err))
+		return mo.Err[RestoreRes](pkgs.NewApiError(http.StatusInternalServerError, "恢复模板失败"))
+	}
+	doc, err := decodeArchive(data)
+	if err != nil {
+		r.log(c).Error("解析模板归档失败", zap.String("key", key), zap.Error(err))
+		return mo.Err[RestoreRes](pkgs.NewApiError(http.StatusInternalServerError, "恢复模板失败"))
+	}
+	if apiErr := r.checkOwner(c, doc.Template.OwnerID, "恢复模板失败", "只能恢复自己的模板"); apiErr != nil {
+		return mo.Err[RestoreRes](apiErr)
+	}
+
+	tx, err := r.conn(c).BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(c).Error("开启事务失败", zap.Error(err))
+		return mo.Err[RestoreRes](pkgs.NewApiError(http.StatusInternalServerError, "恢复模板失败"))
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO ` + r.tables.Template + ` (id, name, num, owner_id, created_at, required_permission)
+		SELECT $1, $2, $3, (SELECT id FROM ` + r.tables.User + ` WHERE id = $4), $5, $6`
+	template := doc.Template
+	if _, err := tx.ExecContext(ctx, query, template.ID, template.Name, template.Num, template.OwnerID, template.CreatedAt, template.RequiredPermission); err != nil {
+		return mo.Err[RestoreRes](pkgs.DBError(r.log(c), err, "恢复模板失败"))
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+r.tables.TemplateTombstone+` WHERE id = $1`, id); err != nil {
+		return mo.Err[RestoreRes](pkgs.DBError(r.log(c), err, "恢复模板失败"))
+	}
+	for _, hook := range r.hooks {
+		raw, ok := doc.Extensions[hook.Name]
+		if !ok || hook.Restore == nil {
+			continue
+		}
+		if err := hook.Restore(ctx, tx, id, raw); err != nil {
+			r.log(c).Error("执行归档恢复钩子失败", zap.String("hook", hook.Name), zap.String("id", id), zap.Error(err))
+			return mo.Err[RestoreRes](pkgs.NewApiError(http.StatusInternalServerError, "恢复模板失败"))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		r.log(c).Error("提交事务失败", zap.Error(err))
+		return mo.Err[RestoreRes](pkgs.NewApiError(http.StatusInternalServerError, "恢复模板失败"))
+	}
+
+	// 模板已写回数据库，归档文件删除失败只记录日志，再次导出时会覆盖
+	if err := r.storage.Delete(ctx, key); err != nil {
+		r.log(c).Warn("删除模板归档失败", zap.String("key", key), zap.Error(err))
+	}
+	return r.GetByID(c)(&GetByIDReq{ID: id})
+}
+
+// ExportArchived 将归档超过 archive.after 的模板导出到对象存储并从数据库删除，依次处理默认 schema 与所有租户 schema
+// 未配置对象存储时不执行
+func (r *Repository) ExportArchived(ctx context.Context) {
+	if !r.storage.Enabled() {
+		return
+	}
+	cutoff := time.Now().Add(-r.archive.After)
+	for tenant, db := range r.pool.BatchDBs(ctx, r.logger) {
+		exported, err := r.exportArchived(ctx, tenant, db, cutoff)
+		if err != nil {
+			r.logger.Error("导出归档模板失败", zap.String("tenant", tenant), zap.Int("exported", exported), zap.Error(err))
+			continue
+		}
+		if exported > 0 {
+			r.logger.Info("归档模板已导出", zap.String("tenant", tenant), zap.Int("exported", exported))
+		}
+	}
+}
+
+// exportArchived 分批导出一个 schema 中归档早于 cutoff 的模板，返回导出的数量
+func (r *Repository) exportArchived(ctx context.Context, tenant string, db *sqlx.DB, cutoff time.Time) (int, error) {
+	var total int
+	for {
+		exported, err := r.exportBatch(ctx, tenant, db, cutoff)
+		total += exported
+		if err != nil || exported < r.archive.BatchSize {
+			return total, err
+		}
+	}
+}
+
+// exportBatch 在一个事务内导出一批模板：先写入对象存储，全部写入成功后再删除
+// 写入后事务失败时模板仍保留在数据库中，下次导出覆盖同一对象；多个实例同时执行时通过 SKIP LOCKED 各自导出不同的模板
+func (r *Repository) exportBatch(ctx context.Context, tenant string, db *sqlx.DB, cutoff time.Time) (int, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var templates []ArchivedTemplate
+	query := `SELECT id, name, num, owner_id, created_at, updated_at, archived_at, required_permission FROM ` + r.tables.Template + `
+		WHERE archived_at < $1 ORDER BY archived_at LIMIT $2 FOR UPDATE SKIP LOCKED`
+	if err := tx.SelectContext(ctx, &templates, query, cutoff, r.archive.BatchSize); err != nil {
+		return 0, err
+	}
+	if len(templates) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(templates))
+	for i, template := range templates {
+		doc := ArchiveDocument{
+			Format:     ArchiveFormat,
+			Version:    ArchiveVersion,
+			Tenant:     tenant,
+			ExportedAt: time.Now().UTC(),
+			Template:   template,
+			Extensions: map[string]json.RawMessage{},
+		}
+		for _, hook := range r.hooks {
+			if hook.Export == nil {
+				continue
+			}
+			data, err := hook.Export(ctx, tx, template.ID)
+			if err != nil {
+				return 0, fmt.Errorf("archive hook %s: %w", hook.Name, err)
+			}
+			if data != nil {
+				doc.Extensions[hook.Name] = data
+			}
+		}
+		body, err := json.Marshal(doc)
 		if err != nil {
-			r.logger.Error("准备命名列表查询失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询模板列表失败"))
+			return 0, err
+		}
+		if err := r.storage.Put(ctx, archiveKey(tenant, template.ID), body, "application/json"); err != nil {
+			return 0, fmt.Errorf("put %s: %w", template.ID, err)
 		}
-		defer rows.Close()
+		ids[i] = template.ID
+	}
+
+	// 删除后由触发器写入同步墓碑，template_usage 随之级联删除
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+r.tables.Template+` WHERE id = ANY($1)`, pkgs.PGArray(ids)); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(templates), nil
+}
 
-		for rows.Next() {
-			var entity TemplateEntity
-			err = rows.StructScan(&entity)
+// archiveKey 模板归档文件在对象存储中的 key，租户的归档按租户分目录
+func archiveKey(tenant, id string) string {
+	if tenant == "" {
+		return "templates/" + id + ".json"
+	}
+	return "tenants/" + tenant + "/templates/" + id + ".json"
+}
+
+// decodeArchive 解析归档文件，拒绝未知格式与更高版本
+func decodeArchive(data []byte) (*ArchiveDocument, error) {
+	var doc ArchiveDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Format != ArchiveFormat || doc.Version < 1 || doc.Version > ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive format %q version %d", doc.Format, doc.Version)
+	}
+	return &doc, nil
+}
+
+// archivedUsage 归档文件中保存的使用次数
+type archivedUsage struct {
+	UsageCount int64     `json:"usage_count" db:"usage_count"`
+	LastUsedAt time.Time `json:"last_used_at" db:"last_used_at"`
+}
+
+// usageArchiveHook 内置的归档钩子：导出与恢复模板的使用次数（template_usage 随模板级联删除）
+func (r *Repository) usageArchiveHook() ArchiveHook {
+	return ArchiveHook{
+		Name: "usage",
+		Export: func(ctx context.Context, tx *sqlx.Tx, id string) (json.RawMessage, error) {
+			var usage archivedUsage
+			query := `SELECT usage_count, last_used_at FROM ` + r.tables.TemplateUsage + ` WHERE template_id = $1`
+			err := tx.GetContext(ctx, &usage, query, id)
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
 			if err != nil {
-				r.logger.Error("扫描行数据失败", zap.Error(err))
-				return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询模板列表失败"))
+				return nil, err
+			}
+			return json.Marshal(usage)
+		},
+		Restore: func(ctx context.Context, tx *sqlx.Tx, id string, data json.RawMessage) error {
+			var usage archivedUsage
+			if err := json.Unmarshal(data, &usage); err != nil {
+				return err
+			}
+			query := `INSERT INTO ` + r.tables.TemplateUsage + ` (template_id, usage_count, last_used_at) VALUES ($1, $2, $3)`
+			_, err := tx.ExecContext(ctx, query, id, usage.UsageCount, usage.LastUsedAt)
+			return err
+		},
+	}
+}
+
+// toGetByIDRes 将数据库实体转换为模板详情
+func toGetByIDRes(c *gin.Context, entity *TemplateEntity) GetByIDRes {
+	res := GetByIDRes{
+		ID:         entity.ID,
+		Name:       entity.Name,
+		Num:        entity.Num,
+		OwnerID:    entity.OwnerID,
+		UsageCount: entity.UsageCount,
+		CreatedAt:  pkgs.FormatTime(c, entity.CreatedAt),
+		UpdatedAt:  pkgs.FormatTime(c, entity.UpdatedAt),
+
+		RequiredPermission: entity.RequiredPermission,
+	}
+	if entity.ArchivedAt != nil {
+		archivedAt := pkgs.FormatTime(c, *entity.ArchivedAt)
+		res.ArchivedAt = &archivedAt
+	}
+	return res
+}
+
+// 拉取变更时，最近这段时间内的变更仍会在下次拉取时重复返回
+// updated_at 取事务开始时间，耗时较长的事务提交时其变更时间可能早于已返回的变更，检查点不越过该时间段以免漏掉这类变更
+const syncSettleWindow = 5 * time.Second
+
+// 全量同步的起始检查点
+var syncOrigin = syncCursor{ID: "00000000-0000-0000-0000-000000000000"}
+
+// syncCursor 同步检查点：已返回的最后一条变更的 (变更时间, ID)
+type syncCursor struct {
+	At time.Time
+	ID string
+}
+
+// encodeCheckpoint 将同步检查点编码为不透明字符串
+func encodeCheckpoint(cursor syncCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(cursor.At.UnixMicro(), 10) + ":" + cursor.ID))
+}
+
+// decodeCheckpoint 解析同步检查点，空字符串表示全量同步
+func decodeCheckpoint(checkpoint string) (syncCursor, bool) {
+	if checkpoint == "" {
+		return syncOrigin, true
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(checkpoint)
+	if err != nil {
+		return syncCursor{}, false
+	}
+	micro, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return syncCursor{}, false
+	}
+	at, err := strconv.ParseInt(micro, 10, 64)
+	if err != nil || uuid.Validate(id) != nil {
+		return syncCursor{}, false
+	}
+	return syncCursor{At: time.UnixMicro(at), ID: id}, true
+}
+
+// syncRow 模板或墓碑的一条变更
+type syncRow struct {
+	ID        string     `db:"id"`
+	Op        string     `db:"op"`
+	ChangedAt time.Time  `db:"changed_at"`
+	Name      *string    `db:"name"`
+	Num       *int       `db:"num"`
+	CreatedAt *time.Time `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+}
+
+func (row *syncRow) change(c *gin.Context) SyncChange {
+	change := SyncChange{ID: row.ID, Op: row.Op, Version: row.ChangedAt.UnixMicro()}
+	if row.Op == SyncOpUpsert {
+		change.Data = &SyncTemplate{Num: row.Num}
+		if row.Name != nil {
+			change.Data.Name = *row.Name
+		}
+		if row.CreatedAt != nil && row.UpdatedAt != nil {
+			change.Data.CreatedAt = pkgs.FormatTime(c, *row.CreatedAt)
+			change.Data.UpdatedAt = pkgs.FormatTime(c, *row.UpdatedAt)
+		}
+	}
+	return change
+}
+
+// SyncPull 按检查点拉取当前用户模板的新增、修改与删除，按变更时间排序
+func (r *Repository) SyncPull(c *gin.Context) func(*SyncPullReq) mo.Result[SyncPullRes] {
+	return func(req *SyncPullReq) mo.Result[SyncPullRes] {
+		uid := pkgs.CurrentUserID(c)
+		if uid == "" {
+			return mo.Err[SyncPullRes](pkgs.NewApiError(http.StatusUnauthorized, "未授权"))
+		}
+		cursor, _ := decodeCheckpoint(req.Checkpoint)
+		full := req.Checkpoint == ""
+		ctx := c.Request.Context()
+
+		var now time.Time
+		if err := r.conn(c).GetContext(ctx, &now, `SELECT CURRENT_TIMESTAMP`); err != nil {
+			r.log(c).Error("查询数据库时间失败", zap.Error(err))
+			return mo.Err[SyncPullRes](pkgs.NewApiError(http.StatusInternalServerError, "拉取模板变更失败"))
+		}
+
+		// 检查点早于墓碑保留期限时，期间的删除可能已被清理，只能全量同步
+		if !full {
+			var retainDays int
+			query := `SELECT retain_days FROM ` + r.tables.Retention + ` WHERE category = $1 AND enabled`
+			err := r.conn(c).GetContext(ctx, &retainDays, query, pkgs.RetentionTombstone)
+			if err != nil && err != sql.ErrNoRows {
+				r.log(c).Error("查询墓碑保留策略失败", zap.Error(err))
+				return mo.Err[SyncPullRes](pkgs.NewApiError(http.StatusInternalServerError, "拉取模板变更失败"))
+			}
+			if err == nil && cursor.At.Before(now.AddDate(0, 0, -retainDays)) {
+				return mo.Err[SyncPullRes](pkgs.NewApiError(http.StatusGone, "同步检查点已过期，请清空检查点全量同步"))
 			}
-			entities = append(entities, entity)
 		}
+
+		// 多查一条判断是否还有更多变更；全量同步不需要返回删除
+		query := `SELECT id, op, changed_at, name, num, created_at, updated_at FROM (
+				SELECT id, '` + SyncOpUpsert + `' AS op, updated_at AS changed_at, name, num, created_at, updated_at
+				FROM ` + r.tables.Template + ` WHERE owner_id = $1 AND (updated_at, id) > ($2, $3)
+				UNION ALL
+				SELECT id, '` + SyncOpDelete + `', deleted_at, NULL, NULL, NULL, NULL
+				FROM ` + r.tables.TemplateTombstone + ` WHERE owner_id = $1 AND (deleted_at, id) > ($2, $3) AND NOT $5
+			) changes ORDER BY changed_at, id LIMIT $4`
+		var rows []syncRow
+		if err := r.conn(c).SelectContext(ctx, &rows, query, uid, cursor.At, cursor.ID, req.Limit+1, full); err != nil {
+			return mo.Err[SyncPullRes](pkgs.DBError(r.log(c), err, "拉取模板变更失败"))
+		}
+
+		res := SyncPullRes{Changes: make([]SyncChange, 0, len(rows)), HasMore: len(rows) > req.Limit}
+		if res.HasMore {
+			rows = rows[:req.Limit]
+		}
+		for i := range rows {
+			res.Changes = append(res.Changes, rows[i].change(c))
+		}
+		if len(rows) > 0 {
+			last := rows[len(rows)-1]
+			cursor = syncCursor{At: last.ChangedAt, ID: last.ID}
+		}
+		// 最后一批的检查点不越过尚未稳定的时间段，其中的变更下次拉取时重复返回
+		if settled := now.Add(-syncSettleWindow); !res.HasMore && cursor.At.After(settled) {
+			cursor = syncCursor{At: settled, ID: syncOrigin.ID}
+		}
+		res.Checkpoint = encodeCheckpoint(cursor)
+		return mo.Ok(res)
+	}
+}
+
+// SyncPush 在一个事务内按顺序应用客户端推送的变更
+// 修改、删除仅在 base_version 与服务端当前版本一致时生效，否则作为冲突返回服务端数据，由客户端合并后重新推送
+func (r *Repository) SyncPush(c *gin.Context) func(*SyncPushReq) mo.Result[SyncPushRes] {
+	return func(req *SyncPushReq) mo.Result[SyncPushRes] {
+		uid := pkgs.CurrentUserID(c)
+		if uid == "" {
+			return mo.Err[SyncPushRes](pkgs.NewApiError(http.StatusUnauthorized, "未授权"))
+		}
+		ctx := c.Request.Context()
+		tx, err := r.conn(c).BeginTxx(ctx, nil)
 		if err != nil {
-			r.logger.Error("查询模板列表失败", zap.Error(err))
-			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询模板列表失败"))
+			r.log(c).Error("开启事务失败", zap.Error(err))
+			return mo.Err[SyncPushRes](pkgs.NewApiError(http.StatusInternalServerError, "推送模板变更失败"))
 		}
+		defer tx.Rollback()
 
-		// 转换并返回结果
-		var responseEntities []TemplateItem
-		for _, entity := range entities {
-			responseEntities = append(responseEntities, TemplateItem{
-				ID:        entity.ID,
-				Name:      entity.Name,
-				Num:       entity.Num,
-				CreatedAt: entity.CreatedAt.Format(time.RFC3339),
-				UpdatedAt: entity.UpdatedAt.Format(time.RFC3339),
-			})
+		res := SyncPushRes{Applied: []SyncApplied{}, Conflicts: []SyncConflict{}}
+		for _, change := range req.Changes {
+			version, err := r.applySyncChange(c, tx, uid, &change)
+			if err != nil {
+				r.log(c).Error("应用模板变更失败", zap.String("id", change.ID), zap.Error(err))
+				return mo.Err[SyncPushRes](pkgs.NewApiError(http.StatusInternalServerError, "推送模板变更失败"))
+			}
+			if version > 0 {
+				res.Applied = append(res.Applied, SyncApplied{ID: change.ID, Op: change.Op, Version: version})
+				continue
+			}
+			conflict, applied, err := r.syncConflict(c, tx, uid, &change)
+			if err != nil {
+				r.log(c).Error("查询模板冲突失败", zap.String("id", change.ID), zap.Error(err))
+				return mo.Err[SyncPushRes](pkgs.NewApiError(http.StatusInternalServerError, "推送模板变更失败"))
+			}
+			if applied != nil {
+				res.Applied = append(res.Applied, *applied)
+			} else {
+				res.Conflicts = append(res.Conflicts, conflict)
+			}
 		}
 
-		return mo.Ok(QueryListRes{
-			List:  responseEntities,
-			Total: total,
-		})
+		if err := tx.Commit(); err != nil {
+			r.log(c).Error("提交事务失败", zap.Error(err))
+			return mo.Err[SyncPushRes](pkgs.NewApiError(http.StatusInternalServerError, "推送模板变更失败"))
+		}
+		return mo.Ok(res)
+	}
+}
+
+// applySyncChange 应用一条变更，返回应用后的版本；版本不一致或模板不存在时返回 0
+func (r *Repository) applySyncChange(c *gin.Context, tx *sqlx.Tx, uid string, change *SyncPushChange) (int64, error) {
+	ctx := c.Request.Context()
+	var changedAt time.Time
+	var err error
+	switch {
+	case change.Op == SyncOpDelete:
+		// 删除后由触发器写入墓碑
+		query := `DELETE FROM ` + r.tables.Template + ` WHERE id = $1 AND owner_id = $2 AND updated_at = $3 RETURNING CURRENT_TIMESTAMP`
+		err = tx.GetContext(ctx, &changedAt, query, change.ID, uid, time.UnixMicro(change.BaseVersion))
+	case change.BaseVersion == 0:
+		// 客户端新建的模板使用客户端生成的ID，已删除的ID不能重新创建
+		query := `INSERT INTO ` + r.tables.Template + ` (id, name, num, owner_id)
+			SELECT $1, $2, $3, $4 WHERE NOT EXISTS (SELECT 1 FROM ` + r.tables.TemplateTombstone + ` WHERE id = $1)
+			ON CONFLICT (id) DO NOTHING RETURNING updated_at`
+		err = tx.GetContext(ctx, &changedAt, query, change.ID, change.Name, change.Num, uid)
+	default:
+		query := `UPDATE ` + r.tables.Template + ` SET name = $4, num = $5 WHERE id = $1 AND owner_id = $2 AND updated_at = $3 RETURNING updated_at`
+		err = tx.GetContext(ctx, &changedAt, query, change.ID, uid, time.UnixMicro(change.BaseVersion), change.Name, change.Num)
+	}
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return changedAt.UnixMicro(), nil
+}
+
+// syncConflict 查询未能应用的变更在服务端的状态
+// 删除已在服务端删除的模板视为已应用（幂等），返回 applied
+func (r *Repository) syncConflict(c *gin.Context, tx *sqlx.Tx, uid string, change *SyncPushChange) (SyncConflict, *SyncApplied, error) {
+	ctx := c.Request.Context()
+	conflict := SyncConflict{ID: change.ID}
+
+	var row syncRow
+	var ownerID *string
+	query := `SELECT id, '` + SyncOpUpsert + `' AS op, updated_at AS changed_at, name, num, created_at, updated_at, owner_id
+		FROM ` + r.tables.Template + ` WHERE id = $1`
+	err := tx.QueryRowxContext(ctx, query, change.ID).Scan(&row.ID, &row.Op, &row.ChangedAt, &row.Name, &row.Num, &row.CreatedAt, &row.UpdatedAt, &ownerID)
+	switch {
+	case err == nil && ownerID != nil && *ownerID == uid:
+		server := row.change(c)
+		conflict.Server = &server
+		conflict.Reason = SyncConflictVersion
+		if change.Op == SyncOpUpsert && change.BaseVersion == 0 {
+			conflict.Reason = SyncConflictExists
+		}
+		return conflict, nil, nil
+	case err == nil:
+		// 属于其他用户的模板不返回数据
+		conflict.Reason = SyncConflictNotFound
+		if change.Op == SyncOpUpsert && change.BaseVersion == 0 {
+			conflict.Reason = SyncConflictExists
+		}
+		return conflict, nil, nil
+	case err != sql.ErrNoRows:
+		return conflict, nil, err
+	}
+
+	var deletedAt time.Time
+	query = `SELECT deleted_at FROM ` + r.tables.TemplateTombstone + ` WHERE id = $1 AND owner_id = $2`
+	err = tx.GetContext(ctx, &deletedAt, query, change.ID, uid)
+	if err == sql.ErrNoRows {
+		conflict.Reason = SyncConflictNotFound
+		return conflict, nil, nil
+	}
+	if err != nil {
+		return conflict, nil, err
+	}
+	if change.Op == SyncOpDelete {
+		return conflict, &SyncApplied{ID: change.ID, Op: SyncOpDelete, Version: deletedAt.UnixMicro()}, nil
 	}
+	conflict.Reason = SyncConflictDeleted
+	conflict.Server = &SyncChange{ID: change.ID, Op: SyncOpDelete, Version: deletedAt.UnixMicro()}
+	return conflict, nil, nil
 }
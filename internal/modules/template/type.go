@@ -1,22 +1,51 @@
 package template
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-pg-demo/pkgs"
 	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// 查看、转移全部模板的编码权限；没有该权限时只能查看和转移自己的模板
+// 拥有该权限的用户同样不受模板要求的访问权限（required_permission）限制。
+const PermissionCodeManageAll = "template:manage_all"
+
+// 模板列表查询范围
+const (
+	ScopeMine = "mine"
+	ScopeAll  = "all"
 )
 
 // 数据库表Template的表结构
 type TemplateEntity struct {
-	ID        string    `db:"id" label:"模板ID"`
-	CreatedAt time.Time `db:"created_at" label:"创建时间"`
-	UpdatedAt time.Time `db:"updated_at" label:"更新时间"`
-	Name      string    `db:"name" label:"模板名称"`
-	Num       *int      `db:"num" label:"模板数量"`
+	ID         string     `db:"id" label:"模板ID"`
+	CreatedAt  time.Time  `db:"created_at" label:"创建时间"`
+	UpdatedAt  time.Time  `db:"updated_at" label:"更新时间"`
+	Name       string     `db:"name" label:"模板名称"`
+	Num        *int       `db:"num" label:"模板数量"`
+	OwnerID    *string    `db:"owner_id" label:"所有者ID"`
+	UsageCount int64      `db:"usage_count" label:"使用次数"`
+	ArchivedAt *time.Time `db:"archived_at" label:"归档时间"`
+	// 查看模板需要的权限编码，为空时不限制
+	RequiredPermission *string `db:"required_permission" label:"访问所需权限"`
+}
+
+// templateListEntity 列表查询的一行，seq 用于游标分页
+type templateListEntity struct {
+	TemplateEntity
+	Seq int64 `db:"seq"`
 }
 
 // 创建模板的请求 DTO
 type CreateReq struct {
 	Name string `json:"name" validate:"required" label:"模板名称"`
 	Num  *int   `json:"num,omitempty" validate:"omitempty,min=1,max=1000" label:"模板数量"`
+	// 查看模板需要的权限编码（如 template:finance），只有拥有该权限的用户能在列表与详情中看到模板
+	RequiredPermission *string `json:"required_permission,omitempty" validate:"omitempty,min=1,max=100" label:"访问所需权限"`
 }
 
 // 创建模板的响应 DTO
@@ -30,6 +59,9 @@ type BatchCreateReq struct {
 // 批量创建模板的响应体
 type BatchCreateRes []string
 
+// 批量创建模板的响应体（return=entity 时返回完整实体）
+type BatchCreateEntityRes []GetByIDRes
+
 // 根据ID获取模板的参数
 type GetByIDReq struct {
 	ID string `uri:"id" validate:"required,uuid" label:"模板ID"`
@@ -37,26 +69,50 @@ type GetByIDReq struct {
 
 // 根据ID获取模板的响应体
 type GetByIDRes struct {
-	ID        string `json:"id" label:"模板ID"`
-	Name      string `json:"name" label:"模板名称"`
-	Num       *int   `json:"num,omitempty" label:"模板数量"`
-	CreatedAt string `json:"created_at" label:"创建时间"`
-	UpdatedAt string `json:"updated_at" label:"更新时间"`
+	ID         string  `json:"id" label:"模板ID"`
+	Name       string  `json:"name" label:"模板名称"`
+	Num        *int    `json:"num,omitempty" label:"模板数量"`
+	OwnerID    *string `json:"owner_id,omitempty" label:"所有者ID"`
+	UsageCount int64   `json:"usage_count" label:"使用次数"`
+	CreatedAt  string  `json:"created_at" label:"创建时间"`
+	UpdatedAt  string  `json:"updated_at" label:"更新时间"`
+	ArchivedAt *string `json:"archived_at,omitempty" label:"归档时间"`
+	// 查看模板需要的权限编码
+	RequiredPermission *string `json:"required_permission,omitempty" label:"访问所需权限"`
 }
 
 // 更新模板的请求体
 type UpdateByIDReq struct {
-	ID   string  `uri:"id" validate:"required,uuid" label:"模板ID"`
-	Name *string `json:"name,omitempty" validate:"omitempty" label:"模板名称"`
-	Num  *int    `json:"num,omitempty" validate:"omitempty,min=1,max=1000" label:"模板数量"`
+	ID                 string  `uri:"id" validate:"required,uuid" label:"模板ID"`
+	Name               *string `json:"name,omitempty" validate:"omitempty" label:"模板名称"`
+	Num                *int    `json:"num,omitempty" validate:"omitempty,min=1,max=1000" label:"模板数量"`
+	RequiredPermission *string `json:"required_permission,omitempty" validate:"omitempty,min=1,max=100" label:"访问所需权限"`
 }
 
 // 更新模板的响应体
 type UpdateByIDRes = int64
 
+// 部分更新模板的请求体（JSON Merge Patch，显式 null 表示清空）
+type PatchByIDReq struct {
+	pkgs.MergePatch `json:"-" swaggerignore:"true"`
+	ID              string  `uri:"id" json:"-" validate:"required,uuid" label:"模板ID"`
+	Name            *string `json:"name,omitempty" label:"模板名称"`
+	Num             *int    `json:"num,omitempty" validate:"omitempty,min=1,max=1000" label:"模板数量"`
+	// 显式 null 时取消访问限制
+	RequiredPermission *string `json:"required_permission,omitempty" validate:"omitempty,min=1,max=100" label:"访问所需权限"`
+}
+
+// 模板名称不可为空，不允许通过 null 清空
+func patchRule(req *PatchByIDReq) []pkgs.Violation {
+	return req.NotNull("name", "模板名称")
+}
+
+// 部分更新模板的响应体
+type PatchByIDRes = int64
+
 // 根据ID删除模板的请求参数
 type DeleteByIDReq struct {
-	ID string `uri:"id" binding:"required,uuid" label:"模板ID"`
+	ID string `uri:"id" validate:"required,uuid" label:"模板ID"`
 }
 
 // 根据ID删除模板的响应
@@ -67,29 +123,323 @@ type DeleteTemplatesReq struct {
 	IDs []string `json:"ids" validate:"required,min=1,dive,uuid" label:"模板ID列表"`
 }
 
+// 批量删除的模板ID不能重复
+func batchDeleteRule(req *DeleteTemplatesReq) []pkgs.Violation {
+	return pkgs.DuplicateViolations("ids", "模板ID", req.IDs)
+}
+
 // 批量删除模板响应
 type BatchDeleteRes = int64
 
 // 查询模板的请求体
 type QueryListReq struct {
-	Page     int    `form:"page,default=1" validate:"min=1" label:"页码"`
-	PageSize int    `form:"pageSize,default=10" validate:"min=1,max=100" label:"每页大小"`
-	Name     string `form:"name,omitempty" validate:"omitempty" label:"模板名称"`
-	OrderBy  string `form:"orderBy,default=id" validate:"omitempty" label:"排序字段"`
-	Order    string `form:"order,default=desc" validate:"omitempty" label:"排序顺序"`
+	pkgs.Pagination
+	pkgs.CursorPagination
+	ListFilter
+	OrderBy string `form:"orderBy,default=created_at" validate:"oneof=id name num created_at updated_at usage_count" label:"排序字段"`
+	Order   string `form:"order,default=desc" validate:"sort_order" label:"排序顺序"`
+}
+
+func queryListRule(req *QueryListReq) []pkgs.Violation {
+	return append(listFilterRule(&req.ListFilter), req.CursorPagination.Violations(req.OrderBy)...)
+}
+
+// 模板列表的筛选条件，列表与计数接口共用
+type ListFilter struct {
+	Name  string `form:"name,omitempty" validate:"omitempty" label:"模板名称"`
+	Scope string `form:"scope,default=mine" validate:"omitempty,oneof=mine all" label:"查询范围"`
+	// 按数量筛选：num 精确匹配，numMin、numMax 为闭区间，可以只传一端
+	Num    *int `form:"num" validate:"omitempty,min=0" label:"模板数量"`
+	NumMin *int `form:"numMin" validate:"omitempty,min=0" label:"最小数量"`
+	NumMax *int `form:"numMax" validate:"omitempty,min=0" label:"最大数量"`
+	// 通用过滤表达式，如 name:like:foo,num:gte:10，可筛选的字段见 filterColumns
+	Filter string `form:"filter" validate:"omitempty,max=2048" label:"过滤表达式"`
+	pkgs.DateRange
+}
+
+// 过滤表达式可以筛选的字段
+var filterColumns = pkgs.FilterColumns{
+	"id":         {Expr: "id", Type: pkgs.FilterUUID},
+	"name":       {Expr: "name", Type: pkgs.FilterText},
+	"num":        {Expr: "num", Type: pkgs.FilterInt},
+	"owner_id":   {Expr: "owner_id", Type: pkgs.FilterUUID},
+	"created_at": {Expr: "created_at", Type: pkgs.FilterTime},
+	"updated_at": {Expr: "updated_at", Type: pkgs.FilterTime},
 }
 
+// 数量区间的下限不能大于上限
+func listFilterRule(req *ListFilter) []pkgs.Violation {
+	violations := append(req.DateRange.Violations(), filterColumns.Violations("filter", req.Filter)...)
+	if req.NumMin != nil && req.NumMax != nil && *req.NumMin > *req.NumMax {
+		violations = append(violations, pkgs.Violation{Field: "numMin", Message: "最小数量不能大于最大数量"})
+	}
+	return violations
+}
+
+// 统计模板数量的请求参数
+type CountReq = ListFilter
+
+// 统计模板数量的响应
+type CountRes = int64
+
 // 模板响应
 type TemplateItem struct {
-	ID        string `json:"id" label:"模板ID"`
+	ID         string  `json:"id" label:"模板ID"`
+	Name       string  `json:"name" label:"模板名称"`
+	Num        *int    `json:"num,omitempty" label:"模板数量"`
+	OwnerID    *string `json:"owner_id,omitempty" label:"所有者ID"`
+	UsageCount int64   `json:"usage_count" label:"使用次数"`
+	CreatedAt  string  `json:"created_at" label:"创建时间"`
+	UpdatedAt  string  `json:"updated_at" label:"更新时间"`
+	// 查看模板需要的权限编码
+	RequiredPermission *string `json:"required_permission,omitempty" label:"访问所需权限"`
+}
+
+// 查询模板的响应体，游标分页时不统计总数（total 为 0）
+type QueryListRes struct {
+	List       []TemplateItem `json:"list"`
+	Total      int64          `json:"total"`
+	NextCursor string         `json:"next_cursor,omitempty" label:"下一页游标"`
+}
+
+// 转移模板所有权的请求体
+type TransferReq struct {
+	ID      string `uri:"id" json:"-" validate:"required,uuid" label:"模板ID"`
+	OwnerID string `json:"owner_id" validate:"required,uuid" label:"新所有者ID"`
+}
+
+// 转移模板所有权的响应体
+type TransferRes = int64
+
+// 记录模板使用的请求参数
+type UseReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"模板ID"`
+}
+
+// 记录模板使用的响应体，返回累计使用次数
+type UseRes = int64
+
+// 归档模板的请求参数
+type ArchiveReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"模板ID"`
+}
+
+// 归档模板的响应体，返回归档后的模板
+type ArchiveRes = GetByIDRes
+
+// 批量归档模板的请求参数
+type BatchArchiveReq struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=100,dive,uuid" label:"模板ID列表"`
+}
+
+// 批量归档的模板ID不能重复
+func batchArchiveRule(req *BatchArchiveReq) []pkgs.Violation {
+	return pkgs.DuplicateViolations("ids", "模板ID", req.IDs)
+}
+
+// 批量归档时跳过模板的原因
+const (
+	// 模板不存在或当前用户不可见
+	ArchiveSkipNotFound = "not_found"
+	// 模板不属于当前用户，且没有管理全部模板的权限
+	ArchiveSkipForbidden = "forbidden"
+	// 模板已归档
+	ArchiveSkipArchived = "archived"
+	// 模板仍被其他数据引用
+	ArchiveSkipReferenced = "referenced"
+)
+
+// BatchArchiveSkipped 批量归档时跳过的模板
+type BatchArchiveSkipped struct {
+	ID     string `json:"id" label:"模板ID"`
+	Reason string `json:"reason" label:"跳过原因"`
+	// 原因为 referenced 时返回引用该模板的检查器名称
+	ReferencedBy []string `json:"referenced_by,omitempty" label:"引用方"`
+}
+
+// 批量归档模板的响应体
+type BatchArchiveRes struct {
+	Archived []string              `json:"archived" label:"已归档的模板ID"`
+	Skipped  []BatchArchiveSkipped `json:"skipped" label:"跳过的模板"`
+}
+
+// 恢复归档模板的请求参数
+type RestoreReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"模板ID"`
+}
+
+// 恢复归档模板的响应体，返回恢复后的模板
+type RestoreRes = GetByIDRes
+
+// 归档文件的格式标识与版本，格式不兼容地变化时增加版本号
+const (
+	ArchiveFormat  = "go-pg-demo.template"
+	ArchiveVersion = 1
+)
+
+// ArchiveDocument 导出到对象存储的模板归档文件
+// Extensions 保存各归档钩子导出的关联数据，键为钩子名称。
+type ArchiveDocument struct {
+	Format     string                     `json:"format"`
+	Version    int                        `json:"version"`
+	Tenant     string                     `json:"tenant,omitempty"`
+	ExportedAt time.Time                  `json:"exported_at"`
+	Template   ArchivedTemplate           `json:"template"`
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// ArchivedTemplate 归档文件中的模板数据
+type ArchivedTemplate struct {
+	ID         string    `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Num        *int      `json:"num,omitempty" db:"num"`
+	OwnerID    *string   `json:"owner_id,omitempty" db:"owner_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ArchivedAt time.Time `json:"archived_at" db:"archived_at"`
+	// 旧版本导出的归档中没有该字段，恢复后不限制访问
+	RequiredPermission *string `json:"required_permission,omitempty" db:"required_permission"`
+}
+
+// ArchiveHook 归档的生命周期钩子，用于导出与恢复模板的关联数据
+// Export 在删除模板前于同一事务内执行，返回 nil 表示没有需要保存的数据；
+// Restore 在恢复模板后于同一事务内执行，data 为导出时保存的数据。
+type ArchiveHook struct {
+	Name    string
+	Export  func(ctx context.Context, tx *sqlx.Tx, id string) (json.RawMessage, error)
+	Restore func(ctx context.Context, tx *sqlx.Tx, id string, data json.RawMessage) error
+}
+
+// ReferenceChecker 模板引用检查器，由引用模板的模块注册，归档前检查模板是否仍被引用
+// Referenced 在归档事务内执行（模板行已加锁），返回 ids 中仍被引用的模板ID。
+type ReferenceChecker struct {
+	Name       string
+	Referenced func(ctx context.Context, tx *sqlx.Tx, ids []string) ([]string, error)
+}
+
+// 增量同步的变更类型
+const (
+	SyncOpUpsert = "upsert"
+	SyncOpDelete = "delete"
+)
+
+// 推送变更冲突的原因
+const (
+	// 服务端版本与客户端的 base_version 不一致，客户端需以服务端数据为准合并后重新推送
+	SyncConflictVersion = "version_mismatch"
+	// 模板已在服务端删除
+	SyncConflictDeleted = "deleted"
+	// 新建的模板ID已被占用
+	SyncConflictExists = "exists"
+	// 模板不存在或不属于当前用户
+	SyncConflictNotFound = "not_found"
+)
+
+// 拉取模板变更的请求参数
+type SyncPullReq struct {
+	// 上次拉取返回的检查点，为空表示全量同步
+	Checkpoint string `form:"checkpoint" label:"同步检查点"`
+	Limit      int    `form:"limit,default=100" validate:"min=1,max=500" label:"每批数量"`
+}
+
+func syncPullRule(req *SyncPullReq) []pkgs.Violation {
+	if _, ok := decodeCheckpoint(req.Checkpoint); !ok {
+		return []pkgs.Violation{{Field: "checkpoint", Message: "同步检查点无效"}}
+	}
+	return nil
+}
+
+// 模板的同步数据
+type SyncTemplate struct {
 	Name      string `json:"name" label:"模板名称"`
 	Num       *int   `json:"num,omitempty" label:"模板数量"`
 	CreatedAt string `json:"created_at" label:"创建时间"`
 	UpdatedAt string `json:"updated_at" label:"更新时间"`
 }
 
-// 查询模板的响应体
-type QueryListRes struct {
-	List  []TemplateItem `json:"list"`
-	Total int64          `json:"total"`
+// 一条模板变更，version 为变更时间（微秒时间戳），推送修改、删除时作为 base_version 传回
+type SyncChange struct {
+	ID      string        `json:"id" label:"模板ID"`
+	Op      string        `json:"op" label:"变更类型"`
+	Version int64         `json:"version" label:"版本"`
+	Data    *SyncTemplate `json:"data,omitempty" label:"模板数据"`
+}
+
+// 拉取模板变更的响应，has_more 为 true 时应使用新的检查点继续拉取
+type SyncPullRes struct {
+	Changes    []SyncChange `json:"changes" label:"变更列表"`
+	Checkpoint string       `json:"checkpoint" label:"同步检查点"`
+	HasMore    bool         `json:"has_more" label:"是否还有更多变更"`
+}
+
+// 客户端推送的一条变更
+type SyncPushChange struct {
+	ID string `json:"id" validate:"required,uuid" label:"模板ID"`
+	Op string `json:"op" validate:"required,oneof=upsert delete" label:"变更类型"`
+	// 客户端修改前看到的版本，0 表示客户端新建的模板
+	BaseVersion int64  `json:"base_version" validate:"min=0" label:"基准版本"`
+	Name        string `json:"name" validate:"omitempty,max=50" label:"模板名称"`
+	Num         *int   `json:"num,omitempty" validate:"omitempty,min=1,max=1000" label:"模板数量"`
+}
+
+// 推送模板变更的请求体，按顺序逐条处理
+type SyncPushReq struct {
+	Changes []SyncPushChange `json:"changes" validate:"required,min=1,max=100,dive" label:"变更列表"`
+}
+
+// 同一批次内模板ID不能重复，upsert 必须提供模板名称，删除必须提供基准版本
+func syncPushRule(req *SyncPushReq) []pkgs.Violation {
+	ids := make([]string, len(req.Changes))
+	var violations []pkgs.Violation
+	for i, change := range req.Changes {
+		ids[i] = change.ID
+		switch {
+		case change.Op == SyncOpUpsert && change.Name == "":
+			violations = append(violations, pkgs.Violation{Field: fmt.Sprintf("changes[%d].name", i), Message: "模板名称为必填字段"})
+		case change.Op == SyncOpDelete && change.BaseVersion == 0:
+			violations = append(violations, pkgs.Violation{Field: fmt.Sprintf("changes[%d].base_version", i), Message: "删除时必须提供基准版本"})
+		}
+	}
+	return append(pkgs.DuplicateViolations("changes.id", "模板ID", ids), violations...)
+}
+
+// 已应用的变更及应用后的版本
+type SyncApplied struct {
+	ID      string `json:"id" label:"模板ID"`
+	Op      string `json:"op" label:"变更类型"`
+	Version int64  `json:"version" label:"版本"`
+}
+
+// 未应用的冲突变更，server 为服务端当前数据（已删除时为删除记录，不可见时为空）
+type SyncConflict struct {
+	ID     string      `json:"id" label:"模板ID"`
+	Reason string      `json:"reason" label:"冲突原因"`
+	Server *SyncChange `json:"server,omitempty" label:"服务端数据"`
+}
+
+// 推送模板变更的响应
+type SyncPushRes struct {
+	Applied   []SyncApplied  `json:"applied" label:"已应用的变更"`
+	Conflicts []SyncConflict `json:"conflicts" label:"冲突的变更"`
+}
+
+// upserted 返回已应用的新增与修改的模板ID
+func (r SyncPushRes) upserted() []string {
+	return r.appliedIDs(SyncOpUpsert)
+}
+
+// deleted 返回已应用的删除的模板ID
+func (r SyncPushRes) deleted() []string {
+	return r.appliedIDs(SyncOpDelete)
+}
+
+// appliedIDs 返回指定变更类型的已应用变更的模板ID
+func (r SyncPushRes) appliedIDs(op string) []string {
+	ids := make([]string, 0, len(r.Applied))
+	for _, applied := range r.Applied {
+		if applied.Op == op {
+			ids = append(ids, applied.ID)
+		}
+	}
+	return ids
 }
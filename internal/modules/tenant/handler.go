@@ -0,0 +1,89 @@
+// Package tenant API.
+//
+// 租户管理API接口（schema-per-tenant 模式）。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package tenant
+
+import (
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+}
+
+func NewTenantHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, config *pkgs.Config, tables *pkgs.TableNames, pool *pkgs.TenantPool, hasher *pkgs.PasswordHasher) *Handler {
+	return &Handler{
+		db:        db,
+		logger:    logger,
+		validator: validator,
+		repository: &Repository{
+			db:     db,
+			logger: logger,
+			config: config,
+			tables: tables,
+			pool:   pool,
+			hasher: hasher,
+		},
+	}
+}
+
+// Create 创建租户
+//
+//	@Summary  创建租户
+//	@Description  创建租户 schema 并执行数据库迁移，同时初始化租户的管理员用户与 root 角色
+//	@Tags   租户管理
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  CreateReq true  "创建租户请求参数"
+//	@Success  200   {object}  pkgs.Response{data=CreateRes}  "创建成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误或未启用多租户模式"
+//	@Failure  409   {object}  pkgs.Response       "租户已存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/tenant"}
+//	@Router   /tenant [post]
+func (h *Handler) Create(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[CreateReq](c),
+		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+		result.FlatMap(h.repository.Create(c)),
+	).Match(
+		pkgs.HandleSuccess[CreateRes](c),
+		pkgs.HandleError[CreateRes](c),
+	)
+}
+
+// QueryList 获取租户列表
+//
+//	@Summary  获取租户列表
+//	@Description  列出所有已创建的租户
+//	@Tags   租户管理
+//	@Produce  json
+//	@Success  200 {object}  pkgs.Response{data=QueryListRes}  "获取成功"
+//	@Failure  400 {object}  pkgs.Response           "未启用多租户模式"
+//	@Failure  500 {object}  pkgs.Response           "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/tenant/list"}
+//	@Router   /tenant/list [get]
+func (h *Handler) QueryList(c *gin.Context) {
+	h.repository.QueryList(c).Match(
+		pkgs.HandleSuccess[QueryListRes](c),
+		pkgs.HandleError[QueryListRes](c),
+	)
+}
@@ -0,0 +1,88 @@
+package tenant
+
+import (
+	"go-pg-demo/migration"
+	"go-pg-demo/pkgs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+	config *pkgs.Config
+	tables *pkgs.TableNames
+	pool   *pkgs.TenantPool
+	hasher *pkgs.PasswordHasher
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
+	return func(req *CreateReq) mo.Result[CreateRes] {
+		if !r.pool.Enabled() {
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusBadRequest, "未启用多租户模式"))
+		}
+		if !r.pool.ValidTenant(req.Tenant) {
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusBadRequest, "租户标识只能包含小写字母、数字和下划线，且以字母开头"))
+		}
+
+		exists, err := r.pool.Exists(c.Request.Context(), req.Tenant)
+		if err != nil {
+			r.log(c).Error("查询租户失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建租户失败"))
+		}
+		if exists {
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusConflict, "租户已存在"))
+		}
+
+		// 创建 schema 并执行迁移
+		schema := r.pool.SchemaName(req.Tenant)
+		if err := migration.RunSchemaMigrations(r.db, r.config, r.tables, schema); err != nil {
+			r.log(c).Error("执行租户迁移失败", zap.String("schema", schema), zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建租户失败"))
+		}
+
+		// 初始化租户的管理员与 root 角色
+		tenantDB, err := r.pool.Get(req.Tenant)
+		if err != nil {
+			r.log(c).Error("连接租户数据库失败", zap.String("schema", schema), zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建租户失败"))
+		}
+		if err := pkgs.InitAdminRoot(tenantDB, r.log(c), r.tables, r.hasher); err != nil {
+			r.log(c).Error("初始化租户管理员失败", zap.String("schema", schema), zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "创建租户失败"))
+		}
+
+		return mo.Ok(CreateRes{Tenant: req.Tenant, Schema: schema})
+	}
+}
+
+func (r *Repository) QueryList(c *gin.Context) mo.Result[QueryListRes] {
+	if !r.pool.Enabled() {
+		return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusBadRequest, "未启用多租户模式"))
+	}
+
+	var schemas []string
+	query := `SELECT schema_name FROM information_schema.schemata WHERE starts_with(schema_name, $1) ORDER BY schema_name`
+	if err := r.db.SelectContext(c.Request.Context(), &schemas, query, r.config.Tenant.SchemaPrefix); err != nil {
+		return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询租户列表失败"))
+	}
+
+	list := []TenantItem{}
+	for _, schema := range schemas {
+		list = append(list, TenantItem{
+			Tenant: strings.TrimPrefix(schema, r.config.Tenant.SchemaPrefix),
+			Schema: schema,
+		})
+	}
+	return mo.Ok(QueryListRes{List: list, Total: int64(len(list))})
+}
@@ -0,0 +1,24 @@
+package tenant
+
+// 创建租户的请求 DTO
+type CreateReq struct {
+	Tenant string `json:"tenant" validate:"required,max=40" label:"租户标识"`
+}
+
+// 创建租户的响应 DTO
+type CreateRes struct {
+	Tenant string `json:"tenant" label:"租户标识"`
+	Schema string `json:"schema" label:"租户schema"`
+}
+
+// 租户项
+type TenantItem struct {
+	Tenant string `json:"tenant" label:"租户标识"`
+	Schema string `json:"schema" label:"租户schema"`
+}
+
+// 查询租户列表的响应体
+type QueryListRes struct {
+	List  []TenantItem `json:"list"`
+	Total int64        `json:"total"`
+}
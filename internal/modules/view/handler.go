@@ -0,0 +1,145 @@
+// Package view API.
+//
+// 保存的列表视图，用户为各类实体的列表保存常用的筛选条件、排序与显示列，可共享给角色。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package view
+
+import (
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+}
+
+func NewViewHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator) *Handler {
+	return &Handler{
+		db:        db,
+		logger:    logger,
+		validator: validator,
+		repository: &Repository{
+			db:     db,
+			logger: logger,
+			tables: tables,
+			pool:   pool,
+			ids:    ids,
+		},
+	}
+}
+
+// Create 保存视图
+//
+//	@Summary  保存视图
+//	@Description  为当前用户保存某类实体列表的视图（筛选条件、排序与显示列），同一实体下名称不能重复；shared_role_ids 中的角色的用户可以看到并使用该视图，但不能修改
+//	@Tags   view
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  CreateReq true  "保存视图请求参数"
+//	@Success  200   {object}  pkgs.Response{data=CreateRes}  "保存成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误或角色不存在"
+//	@Failure  409   {object}  pkgs.Response       "视图名称已存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/views"}
+//	@Router   /views [post]
+func (h *Handler) Create(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[CreateReq](c),
+		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+		result.FlatMap(h.repository.Create(c)),
+	).Match(
+		pkgs.HandleSuccess[CreateRes](c),
+		pkgs.HandleError[CreateRes](c),
+	)
+}
+
+// QueryList 获取视图列表
+//
+//	@Summary  获取视图列表
+//	@Description  返回当前用户在指定实体上可用的视图：自己保存的视图（owned 为 true，排在前面）以及共享给其所属角色的视图
+//	@Tags   view
+//	@Produce  json
+//	@Param    entity  query string  true  "实体"  Enums(user, role, permission, template, blueprint, client, audit)
+//	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/views"}
+//	@Router   /views [get]
+func (h *Handler) QueryList(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[QueryListReq](c),
+		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.QueryList(c)),
+	).Match(
+		pkgs.HandleSuccess[QueryListRes](c),
+		pkgs.HandleError[QueryListRes](c),
+	)
+}
+
+// UpdateByID 根据ID更新视图
+//
+//	@Summary  根据ID更新视图
+//	@Description  修改自己保存的视图，config 与 shared_role_ids 传入时整体替换；共享得到的视图不能修改
+//	@Tags   view
+//	@Accept   json
+//	@Produce  json
+//	@Param    id    path  string          true  "视图ID"
+//	@Param    request body  UpdateByIDReq true  "更新视图请求参数"
+//	@Success  200   {object}  pkgs.Response{data=UpdateByIDRes}       "更新成功"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误或角色不存在"
+//	@Failure  404   {object}  pkgs.Response       "视图不存在或不是自己的视图"
+//	@Failure  409   {object}  pkgs.Response       "视图名称已存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"PUT","path":"/v1/views/:id"}
+//	@Router   /views/{id} [put]
+func (h *Handler) UpdateByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUriAndJSON[UpdateByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[UpdateByIDReq](h.validator)),
+		result.FlatMap(h.repository.UpdateByID(c)),
+	).Match(
+		pkgs.HandleSuccess[UpdateByIDRes](c),
+		pkgs.HandleError[UpdateByIDRes](c),
+	)
+}
+
+// DeleteByID 根据ID删除视图
+//
+//	@Summary  根据ID删除视图
+//	@Description  删除自己保存的视图，共享得到的视图不能删除
+//	@Tags   view
+//	@Produce  json
+//	@Param    id  path  string  true  "视图ID"
+//	@Success  200 {object}  pkgs.Response{data=DeleteByIDRes} "删除成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"DELETE","path":"/v1/views/:id"}
+//	@Router   /views/{id} [delete]
+func (h *Handler) DeleteByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[DeleteByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
+		result.FlatMap(h.repository.DeleteByID(c)),
+	).Match(
+		pkgs.HandleSuccess[DeleteByIDRes](c),
+		pkgs.HandleError[DeleteByIDRes](c),
+	)
+}
@@ -0,0 +1,219 @@
+package view
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+	tables *pkgs.TableNames
+	pool   *pkgs.TenantPool
+	ids    *pkgs.IDGenerator
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+const viewColumns = `id, owner_id, entity, name, config, shared_role_ids, created_at, updated_at`
+
+// Create 为当前用户保存视图，同一实体下视图名称不能重复
+func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
+	return func(req *CreateReq) mo.Result[CreateRes] {
+		if apiErr := r.checkRoles(c, req.SharedRoleIDs); apiErr != nil {
+			return mo.Err[CreateRes](apiErr)
+		}
+
+		entity := &ViewEntity{
+			OwnerID:       pkgs.CurrentUserID(c),
+			Entity:        req.Entity,
+			Name:          req.Name,
+			Config:        req.Config,
+			SharedRoleIDs: pq.StringArray(dedupe(req.SharedRoleIDs)),
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "保存视图失败"))
+		}
+
+		// 数据库操作，名称重复时不插入
+		columns, values := r.ids.Insert("owner_id", "entity", "name", "config", "shared_role_ids")
+		query := `INSERT INTO ` + r.tables.SavedView + ` (` + columns + `) VALUES (` + values + `) ON CONFLICT (owner_id, entity, name) DO NOTHING RETURNING id`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
+		if err != nil {
+			r.log(c).Error("准备插入语句失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "保存视图失败"))
+		}
+		defer stmt.Close()
+		if err := stmt.GetContext(c.Request.Context(), &entity.ID, entity); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mo.Err[CreateRes](pkgs.NewApiError(http.StatusConflict, "视图名称已存在"))
+			}
+			return mo.Err[CreateRes](pkgs.DBError(r.log(c), err, "保存视图失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(CreateRes(entity.ID))
+	}
+}
+
+// UpdateByID 更新当前用户自己的视图，共享得到的视图不能修改
+func (r *Repository) UpdateByID(c *gin.Context) func(*UpdateByIDReq) mo.Result[UpdateByIDRes] {
+	return func(req *UpdateByIDReq) mo.Result[UpdateByIDRes] {
+		// 动态构建更新语句
+		params := map[string]any{"id": req.ID, "owner_id": pkgs.CurrentUserID(c)}
+		var setClauses []string
+		set := func(column string, value any) {
+			params[column] = value
+			setClauses = append(setClauses, column+" = :"+column)
+		}
+		if req.Name != nil {
+			set("name", *req.Name)
+		}
+		if req.Config != nil {
+			set("config", *req.Config)
+		}
+		if req.SharedRoleIDs != nil {
+			if apiErr := r.checkRoles(c, req.SharedRoleIDs); apiErr != nil {
+				return mo.Err[UpdateByIDRes](apiErr)
+			}
+			set("shared_role_ids", pq.StringArray(dedupe(req.SharedRoleIDs)))
+		}
+
+		// 如果没有需要更新的字段，直接返回成功
+		if len(setClauses) == 0 {
+			return mo.Ok(UpdateByIDRes(0))
+		}
+
+		query := "UPDATE " + r.tables.SavedView + " SET " + strings.Join(setClauses, ", ") + " WHERE id = :id AND owner_id = :owner_id"
+		res, err := r.conn(c).NamedExecContext(c.Request.Context(), query, params)
+		if err != nil {
+			return mo.Err[UpdateByIDRes](pkgs.DBError(r.log(c), err, "更新视图失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "更新视图失败"))
+		}
+		if affectedRows == 0 {
+			return mo.Err[UpdateByIDRes](pkgs.NewApiError(http.StatusNotFound, "视图不存在"))
+		}
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// DeleteByID 删除当前用户自己的视图
+func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
+	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
+		query := `DELETE FROM ` + r.tables.SavedView + ` WHERE id = $1 AND owner_id = $2`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID, pkgs.CurrentUserID(c))
+		if err != nil {
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "删除视图失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "删除视图失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// QueryList 返回当前用户在某个实体上可用的视图：自己保存的视图，以及共享给其所属角色的视图
+// 自己的视图排在前面，同类按名称排序
+func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
+	return func(req *QueryListReq) mo.Result[QueryListRes] {
+		userID := pkgs.CurrentUserID(c)
+		query := `SELECT ` + viewColumns + ` FROM ` + r.tables.SavedView + `
+			WHERE entity = $1 AND (owner_id = $2 OR shared_role_ids && ARRAY(SELECT role_id FROM ` + r.tables.UserRole + ` WHERE user_id = $2))
+			ORDER BY owner_id = $2 DESC, name, seq`
+		var entities []ViewEntity
+		if err := r.conn(c).SelectContext(c.Request.Context(), &entities, query, req.Entity, userID); err != nil {
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询视图列表失败"))
+		}
+
+		list := make([]ViewItem, 0, len(entities))
+		for i := range entities {
+			list = append(list, toViewItem(c, &entities[i], userID))
+		}
+
+		// 返回结果
+		return mo.Ok(QueryListRes{List: list, Total: int64(len(list))})
+	}
+}
+
+// checkRoles 校验共享的角色都存在，不存在时返回 400 并列出缺失的角色ID
+func (r *Repository) checkRoles(c *gin.Context, roleIDs []string) *pkgs.ApiError {
+	roleIDs = dedupe(roleIDs)
+	if len(roleIDs) == 0 {
+		return nil
+	}
+	var found []string
+	query := `SELECT id FROM ` + r.tables.Role + ` WHERE id = ANY($1)`
+	if err := r.conn(c).SelectContext(c.Request.Context(), &found, query, pkgs.PGArray(roleIDs)); err != nil {
+		r.log(c).Error("查询角色失败", zap.Error(err))
+		return pkgs.NewApiError(http.StatusInternalServerError, "查询角色失败")
+	}
+	var missing []string
+	for _, id := range roleIDs {
+		if !slices.Contains(found, id) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return pkgs.NewApiError(http.StatusBadRequest, "角色不存在: "+strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// dedupe 去掉重复的ID，保持原有顺序
+func dedupe(ids []string) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// toViewItem 将数据库实体转换为视图详情
+func toViewItem(c *gin.Context, entity *ViewEntity, userID string) ViewItem {
+	sharedRoleIDs := []string(entity.SharedRoleIDs)
+	if sharedRoleIDs == nil {
+		sharedRoleIDs = []string{}
+	}
+	return ViewItem{
+		ID:            entity.ID,
+		Entity:        entity.Entity,
+		Name:          entity.Name,
+		Config:        entity.Config,
+		SharedRoleIDs: sharedRoleIDs,
+		OwnerID:       entity.OwnerID,
+		Owned:         entity.OwnerID == userID,
+		CreatedAt:     pkgs.FormatTime(c, entity.CreatedAt),
+		UpdatedAt:     pkgs.FormatTime(c, entity.UpdatedAt),
+	}
+}
@@ -0,0 +1,99 @@
+package view
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/lib/pq"
+)
+
+// ViewConfig 列表视图的配置，前端按原样回放到列表接口
+type ViewConfig struct {
+	// 筛选条件，键为列表接口的查询参数，如 {"username": "alice"}
+	Filters map[string]string `json:"filters,omitempty" validate:"max=50" label:"筛选条件"`
+	OrderBy string            `json:"order_by,omitempty" validate:"omitempty,max=50" label:"排序字段"`
+	Order   string            `json:"order,omitempty" validate:"omitempty,sort_order" label:"排序顺序"`
+	// 显示的列，按顺序排列
+	Columns []string `json:"columns,omitempty" validate:"max=100,dive,max=50" label:"显示列"`
+}
+
+// Value 实现 driver.Valuer 接口
+func (v ViewConfig) Value() (driver.Value, error) {
+	return pkgs.GenericJSONValue(v)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (v *ViewConfig) Scan(value any) error {
+	return pkgs.GenericJSONScan(v, value)
+}
+
+// 数据库表 saved_view 的表结构
+type ViewEntity struct {
+	ID            string         `db:"id" label:"视图ID"`
+	CreatedAt     time.Time      `db:"created_at" label:"创建时间"`
+	UpdatedAt     time.Time      `db:"updated_at" label:"更新时间"`
+	OwnerID       string         `db:"owner_id" label:"所有者ID"`
+	Entity        string         `db:"entity" label:"实体"`
+	Name          string         `db:"name" label:"视图名称"`
+	Config        ViewConfig     `db:"config" label:"视图配置"`
+	SharedRoleIDs pq.StringArray `db:"shared_role_ids" label:"共享角色ID列表"`
+}
+
+// 保存视图的请求 DTO
+type CreateReq struct {
+	// 实体，与各模块的列表接口对应；audit 为审计日志导出（GET /v1/audit/export）的筛选预设
+	Entity string     `json:"entity" validate:"required,oneof=user role permission template blueprint client audit" label:"实体"`
+	Name   string     `json:"name" validate:"required,max=100" label:"视图名称"`
+	Config ViewConfig `json:"config" label:"视图配置"`
+	// 共享给这些角色的用户（只读）
+	SharedRoleIDs []string `json:"shared_role_ids" validate:"max=50,dive,uuid" label:"共享角色ID列表"`
+}
+
+// 保存视图的响应 DTO
+type CreateRes string
+
+// 更新视图的请求体，config、shared_role_ids 传入时整体替换
+type UpdateByIDReq struct {
+	ID            string      `uri:"id" validate:"required,uuid" label:"视图ID"`
+	Name          *string     `json:"name,omitempty" validate:"omitempty,max=100" label:"视图名称"`
+	Config        *ViewConfig `json:"config,omitempty" label:"视图配置"`
+	SharedRoleIDs []string    `json:"shared_role_ids,omitempty" validate:"omitempty,max=50,dive,uuid" label:"共享角色ID列表"`
+}
+
+// 更新视图的响应体
+type UpdateByIDRes = int64
+
+// 根据ID删除视图的请求参数
+type DeleteByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"视图ID"`
+}
+
+// 根据ID删除视图的响应
+type DeleteByIDRes = int64
+
+// 查询视图列表的请求参数
+type QueryListReq struct {
+	Entity string `form:"entity" validate:"required,oneof=user role permission template blueprint client audit" label:"实体"`
+}
+
+// 视图详情
+type ViewItem struct {
+	ID            string     `json:"id" label:"视图ID"`
+	Entity        string     `json:"entity" label:"实体"`
+	Name          string     `json:"name" label:"视图名称"`
+	Config        ViewConfig `json:"config" label:"视图配置"`
+	SharedRoleIDs []string   `json:"shared_role_ids" label:"共享角色ID列表"`
+	OwnerID       string     `json:"owner_id" label:"所有者ID"`
+	// 是否为当前用户保存的视图，否则为通过角色共享的视图（只读）
+	Owned     bool   `json:"owned" label:"是否为自己的视图"`
+	CreatedAt string `json:"created_at" label:"创建时间"`
+	UpdatedAt string `json:"updated_at" label:"更新时间"`
+}
+
+// 查询视图列表的响应体
+type QueryListRes struct {
+	List  []ViewItem `json:"list"`
+	Total int64      `json:"total"`
+}
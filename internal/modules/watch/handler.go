@@ -0,0 +1,149 @@
+// Package watch API.
+//
+// 关注，用户关注模板、用户等实体，其他人修改、删除该实体时通过通知发送给关注者。
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http
+package watch
+
+import (
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo/result"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	db         *sqlx.DB
+	logger     *zap.Logger
+	validator  *pkgs.RequestValidator
+	repository *Repository
+}
+
+func NewWatchHandler(db *sqlx.DB, logger *zap.Logger, validator *pkgs.RequestValidator, tables *pkgs.TableNames, pool *pkgs.TenantPool, ids *pkgs.IDGenerator, watchers *pkgs.Watchers) *Handler {
+	return &Handler{
+		db:        db,
+		logger:    logger,
+		validator: validator,
+		repository: &Repository{
+			db:       db,
+			logger:   logger,
+			tables:   tables,
+			pool:     pool,
+			ids:      ids,
+			watchers: watchers,
+		},
+	}
+}
+
+// Create 关注实体
+//
+//	@Summary  关注实体
+//	@Description  当前用户关注模板或用户，只能关注自己可以查看的实体（模板按所有者与 required_permission 判断，用户须拥有 GET /v1/user/:id 接口权限）；重复关注时返回原有的关注ID。
+//	@Description  其他人修改、归档、恢复、转移或删除该实体时发送通知（类型为 watch.{entity}.{action}），实体删除后关注自动取消；
+//	@Description  变更通知按发送时实体的可见范围筛选，之后不能再查看该实体的关注者不再收到通知
+//	@Tags   watch
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  CreateReq true  "关注实体请求参数"
+//	@Success  200   {object}  pkgs.Response{data=CreateRes}  "关注成功，返回关注ID"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误或不支持关注该实体"
+//	@Failure  403   {object}  pkgs.Response       "无权查看该用户"
+//	@Failure  404   {object}  pkgs.Response       "实体不存在"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/watch"}
+//	@Router   /watch [post]
+func (h *Handler) Create(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[CreateReq](c),
+		result.FlatMap(pkgs.ValidateV2[CreateReq](h.validator)),
+		result.FlatMap(h.repository.Create(c)),
+	).Match(
+		pkgs.HandleSuccess[CreateRes](c),
+		pkgs.HandleError[CreateRes](c),
+	)
+}
+
+// QueryList 获取关注列表
+//
+//	@Summary  获取关注列表
+//	@Description  分页返回当前用户的关注，最近关注的排在前面；传 entity 与 entity_id 可查询是否关注了某个实体
+//	@Tags   watch
+//	@Produce  json
+//	@Param    page      query int     false "页码"  default(1)
+//	@Param    pageSize  query int     false "每页数量"  default(10)
+//	@Param    entity    query string  false "实体"  Enums(template, user)
+//	@Param    entity_id query string  false "实体ID"
+//	@Success  200     {object}  pkgs.Response{data=QueryListRes}  "获取成功"
+//	@Failure  400     {object}  pkgs.Response               "请求参数错误"
+//	@Failure  500     {object}  pkgs.Response               "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"GET","path":"/v1/watch/list"}
+//	@Router   /watch/list [get]
+func (h *Handler) QueryList(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindQuery[QueryListReq](c),
+		result.FlatMap(pkgs.ValidateV2[QueryListReq](h.validator)),
+		result.FlatMap(h.repository.QueryList(c)),
+	).Match(
+		pkgs.HandleSuccess[QueryListRes](c),
+		pkgs.HandleError[QueryListRes](c),
+	)
+}
+
+// DeleteByID 根据ID取消关注
+//
+//	@Summary  根据ID取消关注
+//	@Description  取消自己的关注，不是自己的关注时影响行数为 0
+//	@Tags   watch
+//	@Produce  json
+//	@Param    id  path  string  true  "关注ID"
+//	@Success  200 {object}  pkgs.Response{data=DeleteByIDRes} "取消成功，返回影响行数"
+//	@Failure  400 {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500 {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"DELETE","path":"/v1/watch/:id"}
+//	@Router   /watch/{id} [delete]
+func (h *Handler) DeleteByID(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindUri[DeleteByIDReq](c),
+		result.FlatMap(pkgs.ValidateV2[DeleteByIDReq](h.validator)),
+		result.FlatMap(h.repository.DeleteByID(c)),
+	).Match(
+		pkgs.HandleSuccess[DeleteByIDRes](c),
+		pkgs.HandleError[DeleteByIDRes](c),
+	)
+}
+
+// Unwatch 按实体取消关注
+//
+//	@Summary  按实体取消关注
+//	@Description  取消当前用户对某个实体的关注，用于在实体页面上直接取消而不需要先查询关注ID；没有关注时影响行数为 0
+//	@Tags   watch
+//	@Accept   json
+//	@Produce  json
+//	@Param    request body  UnwatchReq true  "取消关注请求参数"
+//	@Success  200   {object}  pkgs.Response{data=UnwatchRes}  "取消成功，返回影响行数"
+//	@Failure  400   {object}  pkgs.Response       "请求参数错误"
+//	@Failure  500   {object}  pkgs.Response       "服务器内部错误"
+//	@Security JWT
+//	@x-permission {"method":"POST","path":"/v1/watch/unwatch"}
+//	@Router   /watch/unwatch [post]
+func (h *Handler) Unwatch(c *gin.Context) {
+	result.Pipe2(
+		pkgs.BindJSON[UnwatchReq](c),
+		result.FlatMap(pkgs.ValidateV2[UnwatchReq](h.validator)),
+		result.FlatMap(h.repository.Unwatch(c)),
+	).Match(
+		pkgs.HandleSuccess[UnwatchRes](c),
+		pkgs.HandleError[UnwatchRes](c),
+	)
+}
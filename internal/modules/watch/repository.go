@@ -0,0 +1,182 @@
+package watch
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db       *sqlx.DB
+	logger   *zap.Logger
+	tables   *pkgs.TableNames
+	pool     *pkgs.TenantPool
+	ids      *pkgs.IDGenerator
+	watchers *pkgs.Watchers
+}
+
+// conn 返回当前请求应使用的数据库连接（schema-per-tenant 模式下为租户连接池）
+func (r *Repository) conn(c *gin.Context) *sqlx.DB {
+	return r.pool.DB(c)
+}
+
+// log 返回当前请求的日志器，写出的日志自动带上请求ID
+func (r *Repository) log(c *gin.Context) *zap.Logger {
+	return pkgs.RequestLogger(c, r.logger)
+}
+
+const watchColumns = `id, user_id, entity, entity_id, created_at, updated_at`
+
+// Create 当前用户关注实体，只能关注自己可以查看的实体；重复关注时返回原有的关注ID
+func (r *Repository) Create(c *gin.Context) func(*CreateReq) mo.Result[CreateRes] {
+	return func(req *CreateReq) mo.Result[CreateRes] {
+		if err := r.watchers.Check(c, req.Entity, req.EntityID); err != nil {
+			var apiErr *pkgs.ApiError
+			if errors.As(err, &apiErr) {
+				return mo.Err[CreateRes](apiErr)
+			}
+			r.log(c).Error("校验关注的实体失败", zap.String("entity", req.Entity), zap.String("entity_id", req.EntityID), zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "关注失败"))
+		}
+
+		entity := &WatchEntity{
+			UserID:   pkgs.CurrentUserID(c),
+			Entity:   req.Entity,
+			EntityID: req.EntityID,
+		}
+		if err := r.ids.Assign(&entity.ID); err != nil {
+			r.log(c).Error("生成主键失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "关注失败"))
+		}
+
+		// 数据库操作，已经关注时不插入，返回原有的关注ID
+		columns, values := r.ids.Insert("user_id", "entity", "entity_id")
+		query := `WITH inserted AS (
+				INSERT INTO ` + r.tables.Watch + ` (` + columns + `) VALUES (` + values + `)
+				ON CONFLICT (user_id, entity, entity_id) DO NOTHING RETURNING id
+			)
+			SELECT id FROM inserted
+			UNION ALL
+			SELECT id FROM ` + r.tables.Watch + ` WHERE user_id = :user_id AND entity = :entity AND entity_id = :entity_id
+			LIMIT 1`
+		stmt, err := r.conn(c).PrepareNamedContext(c.Request.Context(), query)
+		if err != nil {
+			r.log(c).Error("准备插入语句失败", zap.Error(err))
+			return mo.Err[CreateRes](pkgs.NewApiError(http.StatusInternalServerError, "关注失败"))
+		}
+		defer stmt.Close()
+		if err := stmt.GetContext(c.Request.Context(), &entity.ID, entity); err != nil {
+			return mo.Err[CreateRes](pkgs.DBError(r.log(c), err, "关注失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(CreateRes(entity.ID))
+	}
+}
+
+// QueryList 分页查询当前用户的关注，最近关注的排在前面
+func (r *Repository) QueryList(c *gin.Context) func(*QueryListReq) mo.Result[QueryListRes] {
+	return func(req *QueryListReq) mo.Result[QueryListRes] {
+		// 构建查询
+		params := map[string]any{
+			"user_id": pkgs.CurrentUserID(c),
+			"limit":   req.PageSize,
+			"offset":  req.Offset(),
+		}
+		whereClauses := []string{"user_id = :user_id"}
+		if req.Entity != "" {
+			whereClauses = append(whereClauses, "entity = :entity")
+			params["entity"] = req.Entity
+		}
+		if req.EntityID != "" {
+			whereClauses = append(whereClauses, "entity_id = :entity_id")
+			params["entity_id"] = req.EntityID
+		}
+		whereCondition := " WHERE " + strings.Join(whereClauses, " AND ")
+
+		// 查询总数
+		var total int64
+		countQuery, countArgs, err := sqlx.Named("SELECT count(*) FROM "+r.tables.Watch+whereCondition, params)
+		if err != nil {
+			r.log(c).Error("构建计数查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询关注列表失败"))
+		}
+		db := r.conn(c)
+		if err := db.GetContext(c.Request.Context(), &total, db.Rebind(countQuery), countArgs...); err != nil {
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询关注列表失败"))
+		}
+		if total == 0 {
+			return mo.Ok(QueryListRes{List: []WatchItem{}, Total: 0})
+		}
+
+		// 查询列表
+		var entities []WatchEntity
+		listQuery, listArgs, err := sqlx.Named(`SELECT `+watchColumns+` FROM `+r.tables.Watch+
+			whereCondition+` ORDER BY created_at DESC, seq DESC LIMIT :limit OFFSET :offset`, params)
+		if err != nil {
+			r.log(c).Error("构建列表查询失败", zap.Error(err))
+			return mo.Err[QueryListRes](pkgs.NewApiError(http.StatusInternalServerError, "查询关注列表失败"))
+		}
+		if err := db.SelectContext(c.Request.Context(), &entities, db.Rebind(listQuery), listArgs...); err != nil {
+			return mo.Err[QueryListRes](pkgs.DBError(r.log(c), err, "查询关注列表失败"))
+		}
+
+		list := make([]WatchItem, 0, len(entities))
+		for i := range entities {
+			list = append(list, WatchItem{
+				ID:        entities[i].ID,
+				Entity:    entities[i].Entity,
+				EntityID:  entities[i].EntityID,
+				CreatedAt: pkgs.FormatTime(c, entities[i].CreatedAt),
+			})
+		}
+
+		// 返回结果
+		return mo.Ok(QueryListRes{List: list, Total: total})
+	}
+}
+
+// DeleteByID 取消当前用户自己的关注
+func (r *Repository) DeleteByID(c *gin.Context) func(*DeleteByIDReq) mo.Result[DeleteByIDRes] {
+	return func(req *DeleteByIDReq) mo.Result[DeleteByIDRes] {
+		query := `DELETE FROM ` + r.tables.Watch + ` WHERE id = $1 AND user_id = $2`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, req.ID, pkgs.CurrentUserID(c))
+		if err != nil {
+			return mo.Err[DeleteByIDRes](pkgs.DBError(r.log(c), err, "取消关注失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[DeleteByIDRes](pkgs.NewApiError(http.StatusInternalServerError, "取消关注失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
+
+// Unwatch 按实体取消当前用户的关注，没有关注时影响行数为 0
+func (r *Repository) Unwatch(c *gin.Context) func(*UnwatchReq) mo.Result[UnwatchRes] {
+	return func(req *UnwatchReq) mo.Result[UnwatchRes] {
+		query := `DELETE FROM ` + r.tables.Watch + ` WHERE user_id = $1 AND entity = $2 AND entity_id = $3`
+		res, err := r.conn(c).ExecContext(c.Request.Context(), query, pkgs.CurrentUserID(c), req.Entity, req.EntityID)
+		if err != nil {
+			return mo.Err[UnwatchRes](pkgs.DBError(r.log(c), err, "取消关注失败"))
+		}
+		affectedRows, err := res.RowsAffected()
+		if err != nil {
+			r.log(c).Error("获取影响行数失败", zap.Error(err))
+			return mo.Err[UnwatchRes](pkgs.NewApiError(http.StatusInternalServerError, "取消关注失败"))
+		}
+
+		// 返回结果
+		return mo.Ok(affectedRows)
+	}
+}
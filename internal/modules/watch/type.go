@@ -0,0 +1,64 @@
+package watch
+
+import (
+	"time"
+
+	"go-pg-demo/pkgs"
+)
+
+// 数据库表 watch 的表结构
+type WatchEntity struct {
+	ID        string    `db:"id" label:"关注ID"`
+	CreatedAt time.Time `db:"created_at" label:"创建时间"`
+	UpdatedAt time.Time `db:"updated_at" label:"更新时间"`
+	UserID    string    `db:"user_id" label:"用户ID"`
+	Entity    string    `db:"entity" label:"实体"`
+	EntityID  string    `db:"entity_id" label:"实体ID"`
+}
+
+// 关注实体的请求 DTO
+type CreateReq struct {
+	Entity   string `json:"entity" validate:"required,oneof=template user" label:"实体"`
+	EntityID string `json:"entity_id" validate:"required,uuid" label:"实体ID"`
+}
+
+// 关注实体的响应 DTO，已经关注时返回原有的关注ID
+type CreateRes string
+
+// 查询关注列表的请求参数
+type QueryListReq struct {
+	pkgs.Pagination
+	Entity   string `form:"entity" validate:"omitempty,oneof=template user" label:"实体"`
+	EntityID string `form:"entity_id" validate:"omitempty,uuid" label:"实体ID"`
+}
+
+// 关注详情
+type WatchItem struct {
+	ID        string `json:"id" label:"关注ID"`
+	Entity    string `json:"entity" label:"实体"`
+	EntityID  string `json:"entity_id" label:"实体ID"`
+	CreatedAt string `json:"created_at" label:"创建时间"`
+}
+
+// 查询关注列表的响应体
+type QueryListRes struct {
+	List  []WatchItem `json:"list"`
+	Total int64       `json:"total"`
+}
+
+// 根据ID取消关注的请求参数
+type DeleteByIDReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"关注ID"`
+}
+
+// 根据ID取消关注的响应，返回影响行数
+type DeleteByIDRes = int64
+
+// 按实体取消关注的请求 DTO
+type UnwatchReq struct {
+	Entity   string `json:"entity" validate:"required,oneof=template user" label:"实体"`
+	EntityID string `json:"entity_id" validate:"required,uuid" label:"实体ID"`
+}
+
+// 按实体取消关注的响应，返回影响行数
+type UnwatchRes = int64
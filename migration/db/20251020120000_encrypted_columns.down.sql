@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_iacc_user_email_hash;
+
+ALTER TABLE "iacc_user" DROP COLUMN IF EXISTS email_hash;
+ALTER TABLE "iacc_user" DROP COLUMN IF EXISTS phone_hash;
+
+-- 回滚前需先关闭加密并还原明文，否则密文长度超出限制
+ALTER TABLE "iacc_user" ALTER COLUMN phone TYPE VARCHAR(20);
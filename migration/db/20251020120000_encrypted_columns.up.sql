@@ -0,0 +1,8 @@
+-- 手机号加密存储后密文长度超过 20，改为 TEXT
+ALTER TABLE "iacc_user" ALTER COLUMN phone TYPE TEXT;
+
+-- 影子列：敏感字段明文的 HMAC-SHA256，用于精确匹配查询及唯一性约束（密文每次加密结果不同）
+ALTER TABLE "iacc_user" ADD COLUMN IF NOT EXISTS phone_hash CHAR(64) UNIQUE;
+ALTER TABLE "iacc_user" ADD COLUMN IF NOT EXISTS email_hash CHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_iacc_user_email_hash ON "iacc_user" (email_hash);
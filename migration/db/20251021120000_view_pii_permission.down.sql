@@ -0,0 +1 @@
+DELETE FROM "iacc_permission" WHERE metadata->>'code' = 'user:view_pii';
@@ -0,0 +1,6 @@
+-- 预置查看用户敏感信息的编码权限，root 角色会由 InitAdminRoot 自动获得
+INSERT INTO "iacc_permission" (name, type, metadata)
+SELECT '查看用户敏感信息', 'data', '{"code": "user:view_pii"}'
+WHERE NOT EXISTS (
+    SELECT 1 FROM "iacc_permission" WHERE metadata->>'code' = 'user:view_pii'
+);
@@ -0,0 +1,6 @@
+-- 预置查看 Swagger 文档的编码权限，root 角色会由 InitAdminRoot 自动获得
+INSERT INTO "iacc_permission" (name, type, metadata)
+SELECT '查看接口文档', 'data', '{"code": "docs:view"}'
+WHERE NOT EXISTS (
+    SELECT 1 FROM "iacc_permission" WHERE metadata->>'code' = 'docs:view'
+);
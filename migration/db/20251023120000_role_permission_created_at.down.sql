@@ -0,0 +1 @@
+ALTER TABLE "iacc_role_permission" DROP COLUMN IF EXISTS created_at;
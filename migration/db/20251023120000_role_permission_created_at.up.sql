@@ -0,0 +1,2 @@
+-- 记录角色与权限关联的建立时间，同步权限时未变化的关联保留原值
+ALTER TABLE "iacc_role_permission" ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
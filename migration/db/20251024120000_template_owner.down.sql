@@ -0,0 +1,3 @@
+DELETE FROM "iacc_permission" WHERE metadata->>'code' = 'template:manage_all';
+DROP INDEX IF EXISTS idx_template_owner_id;
+ALTER TABLE "template" DROP COLUMN IF EXISTS owner_id;
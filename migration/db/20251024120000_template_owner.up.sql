@@ -0,0 +1,10 @@
+-- 模板所有者，创建时取自登录用户；匿名创建的模板为 NULL，所有者被删除时置空
+ALTER TABLE "template" ADD COLUMN IF NOT EXISTS owner_id UUID REFERENCES "iacc_user"(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_template_owner_id ON "template" (owner_id);
+
+-- 预置查看、转移全部模板的编码权限，root 角色会由 InitAdminRoot 自动获得
+INSERT INTO "iacc_permission" (name, type, metadata)
+SELECT '管理全部模板', 'data', '{"code": "template:manage_all"}'
+WHERE NOT EXISTS (
+    SELECT 1 FROM "iacc_permission" WHERE metadata->>'code' = 'template:manage_all'
+);
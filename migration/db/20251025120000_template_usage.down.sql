@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_template_usage_count;
+DROP TABLE IF EXISTS "template_usage";
@@ -0,0 +1,9 @@
+-- 模板使用次数，单独建表避免计数时触发模板的 updated_at 更新
+CREATE TABLE IF NOT EXISTS "template_usage" (
+    template_id UUID PRIMARY KEY REFERENCES "template"(id) ON DELETE CASCADE,
+    usage_count BIGINT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 按使用次数排序（热门模板）
+CREATE INDEX IF NOT EXISTS idx_template_usage_count ON "template_usage" (usage_count DESC);
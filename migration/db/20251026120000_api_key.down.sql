@@ -0,0 +1,5 @@
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_api_key ON "api_key";
+
+-- 删除表
+DROP TABLE IF EXISTS "api_key";
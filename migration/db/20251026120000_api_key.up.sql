@@ -0,0 +1,28 @@
+-- 公开接口（/public/v1）使用的 API 密钥，只保存密钥的 SHA-256 哈希
+CREATE TABLE IF NOT EXISTS "api_key" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    tier VARCHAR(50) NOT NULL,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_api_key'
+          AND tgrelid = 'api_key'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_api_key
+            BEFORE UPDATE ON "api_key"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS idx_api_key_created_at_seq;
+DROP INDEX IF EXISTS idx_api_key_seq;
+ALTER TABLE "api_key" DROP COLUMN IF EXISTS seq;
+
+DROP INDEX IF EXISTS idx_iacc_permission_created_at_seq;
+DROP INDEX IF EXISTS idx_iacc_permission_seq;
+ALTER TABLE "iacc_permission" DROP COLUMN IF EXISTS seq;
+
+DROP INDEX IF EXISTS idx_iacc_role_created_at_seq;
+DROP INDEX IF EXISTS idx_iacc_role_seq;
+ALTER TABLE "iacc_role" DROP COLUMN IF EXISTS seq;
+
+DROP INDEX IF EXISTS idx_iacc_user_created_at_seq;
+DROP INDEX IF EXISTS idx_iacc_user_seq;
+ALTER TABLE "iacc_user" DROP COLUMN IF EXISTS seq;
+
+-- module: template
+DROP INDEX IF EXISTS idx_template_created_at_seq;
+DROP INDEX IF EXISTS idx_template_seq;
+ALTER TABLE "template" DROP COLUMN IF EXISTS seq;
+-- end module
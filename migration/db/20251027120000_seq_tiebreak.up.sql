@@ -0,0 +1,27 @@
+-- 时间戳显式使用微秒精度；新增单调递增的 seq 列，创建时间相同时（如批量插入）作为排序的决胜字段
+-- module: template
+ALTER TABLE "template" ALTER COLUMN created_at TYPE TIMESTAMPTZ(6), ALTER COLUMN updated_at TYPE TIMESTAMPTZ(6);
+ALTER TABLE "template" ADD COLUMN IF NOT EXISTS seq BIGINT GENERATED ALWAYS AS IDENTITY;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_template_seq ON "template" (seq);
+CREATE INDEX IF NOT EXISTS idx_template_created_at_seq ON "template" (created_at, seq);
+-- end module
+
+ALTER TABLE "iacc_user" ALTER COLUMN created_at TYPE TIMESTAMPTZ(6), ALTER COLUMN updated_at TYPE TIMESTAMPTZ(6);
+ALTER TABLE "iacc_user" ADD COLUMN IF NOT EXISTS seq BIGINT GENERATED ALWAYS AS IDENTITY;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_user_seq ON "iacc_user" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_created_at_seq ON "iacc_user" (created_at, seq);
+
+ALTER TABLE "iacc_role" ALTER COLUMN created_at TYPE TIMESTAMPTZ(6), ALTER COLUMN updated_at TYPE TIMESTAMPTZ(6);
+ALTER TABLE "iacc_role" ADD COLUMN IF NOT EXISTS seq BIGINT GENERATED ALWAYS AS IDENTITY;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_role_seq ON "iacc_role" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_role_created_at_seq ON "iacc_role" (created_at, seq);
+
+ALTER TABLE "iacc_permission" ALTER COLUMN created_at TYPE TIMESTAMPTZ(6), ALTER COLUMN updated_at TYPE TIMESTAMPTZ(6);
+ALTER TABLE "iacc_permission" ADD COLUMN IF NOT EXISTS seq BIGINT GENERATED ALWAYS AS IDENTITY;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_permission_seq ON "iacc_permission" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_permission_created_at_seq ON "iacc_permission" (created_at, seq);
+
+ALTER TABLE "api_key" ALTER COLUMN created_at TYPE TIMESTAMPTZ(6), ALTER COLUMN updated_at TYPE TIMESTAMPTZ(6);
+ALTER TABLE "api_key" ADD COLUMN IF NOT EXISTS seq BIGINT GENERATED ALWAYS AS IDENTITY;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_key_seq ON "api_key" (seq);
+CREATE INDEX IF NOT EXISTS idx_api_key_created_at_seq ON "api_key" (created_at, seq);
@@ -0,0 +1,10 @@
+-- module: template
+DROP INDEX IF EXISTS idx_template_name_trgm;
+-- end module
+DROP INDEX IF EXISTS idx_iacc_permission_type_created_at_seq;
+DROP INDEX IF EXISTS idx_iacc_permission_name_trgm;
+DROP INDEX IF EXISTS idx_iacc_role_name_trgm;
+DROP INDEX IF EXISTS idx_iacc_user_email;
+DROP INDEX IF EXISTS idx_iacc_user_phone_trgm;
+DROP INDEX IF EXISTS idx_iacc_user_username_trgm;
+-- pg_trgm 扩展可能被其他 schema 使用，不在此处删除
@@ -0,0 +1,17 @@
+-- 列表接口的模糊查询（ILIKE '%关键字%'）无法使用 B-tree，改用 pg_trgm 的 GIN 索引
+-- 扩展固定安装在 public，schema-per-tenant 模式下各租户 schema 共用同一份操作符类
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+CREATE INDEX IF NOT EXISTS idx_iacc_user_username_trgm ON "iacc_user" USING gin (username public.gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_phone_trgm ON "iacc_user" USING gin (phone public.gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_email ON "iacc_user" (lower(profile->>'email'));
+
+CREATE INDEX IF NOT EXISTS idx_iacc_role_name_trgm ON "iacc_role" USING gin (name public.gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_iacc_permission_name_trgm ON "iacc_permission" USING gin (name public.gin_trgm_ops);
+-- 按类型筛选后按创建时间分页
+CREATE INDEX IF NOT EXISTS idx_iacc_permission_type_created_at_seq ON "iacc_permission" (type, created_at, seq);
+
+-- module: template
+CREATE INDEX IF NOT EXISTS idx_template_name_trgm ON "template" USING gin (name public.gin_trgm_ops);
+-- end module
@@ -0,0 +1,5 @@
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_async_job ON "async_job";
+
+-- 删除表
+DROP TABLE IF EXISTS "async_job";
@@ -0,0 +1,39 @@
+-- 异步任务（导入、导出、webhook 等），trace_id 记录发起请求的请求ID，用于串联同步请求与异步执行的日志
+CREATE TABLE IF NOT EXISTS "async_job" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 3,
+    last_error TEXT,
+    trace_id VARCHAR(64) NOT NULL DEFAULT '',
+    run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_async_job_seq ON "async_job" (seq);
+CREATE INDEX IF NOT EXISTS idx_async_job_created_at_seq ON "async_job" (created_at, seq);
+-- 工作协程按 run_at 领取待执行任务
+CREATE INDEX IF NOT EXISTS idx_async_job_pending ON "async_job" (run_at, seq) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_async_job_trace_id ON "async_job" (trace_id);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_async_job'
+          AND tgrelid = 'async_job'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_async_job
+            BEFORE UPDATE ON "async_job"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
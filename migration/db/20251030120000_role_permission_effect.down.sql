@@ -0,0 +1,2 @@
+ALTER TABLE "iacc_role_permission" DROP CONSTRAINT IF EXISTS chk_iacc_role_permission_effect;
+ALTER TABLE "iacc_role_permission" DROP COLUMN IF EXISTS effect;
@@ -0,0 +1,4 @@
+-- 角色与权限关联的效果：allow 授予，deny 拒绝；用户的任一角色拒绝某权限时，其他角色的授予不再生效
+ALTER TABLE "iacc_role_permission" ADD COLUMN IF NOT EXISTS effect VARCHAR(10) NOT NULL DEFAULT 'allow';
+ALTER TABLE "iacc_role_permission" DROP CONSTRAINT IF EXISTS chk_iacc_role_permission_effect;
+ALTER TABLE "iacc_role_permission" ADD CONSTRAINT chk_iacc_role_permission_effect CHECK (effect IN ('allow', 'deny'));
@@ -0,0 +1 @@
+ALTER TABLE "iacc_role" DROP COLUMN IF EXISTS access_conditions;
@@ -0,0 +1,2 @@
+-- 角色的访问条件（允许访问的时间段、来源 IP 段），为空表示不限制；请求不满足条件时该角色的权限不生效
+ALTER TABLE "iacc_role" ADD COLUMN IF NOT EXISTS access_conditions JSONB;
@@ -0,0 +1,7 @@
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_iacc_user_device ON "iacc_user_device";
+
+-- 删除表
+DROP TABLE IF EXISTS "iacc_user_device";
+
+ALTER TABLE "iacc_user" DROP COLUMN IF EXISTS strict_device;
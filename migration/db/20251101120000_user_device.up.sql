@@ -0,0 +1,32 @@
+-- 用户登录过的设备（登录时通过 X-Device-ID 请求头登记），刷新令牌绑定到设备记录，删除设备后其刷新令牌失效
+CREATE TABLE IF NOT EXISTS "iacc_user_device" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id UUID NOT NULL REFERENCES "iacc_user"(id) ON DELETE CASCADE,
+    device_id VARCHAR(128) NOT NULL,
+    name VARCHAR(100),
+    user_agent VARCHAR(500),
+    last_ip VARCHAR(45),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, device_id)
+);
+
+-- 严格设备模式：开启后只允许已登记设备使用绑定该设备的刷新令牌
+ALTER TABLE "iacc_user" ADD COLUMN IF NOT EXISTS strict_device BOOLEAN NOT NULL DEFAULT false;
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_iacc_user_device'
+          AND tgrelid = 'iacc_user_device'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_iacc_user_device
+            BEFORE UPDATE ON "iacc_user_device"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
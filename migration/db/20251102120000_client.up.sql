@@ -0,0 +1,34 @@
+-- 已登记的客户端（web / mobile / service），登录时通过 client_id 选择，按客户端类型配置访问令牌与刷新令牌的有效期（秒）
+CREATE TABLE IF NOT EXISTS "iacc_client" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    client_id VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    client_type VARCHAR(20) NOT NULL,
+    access_token_ttl INT NOT NULL,
+    refresh_token_ttl INT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    CONSTRAINT chk_iacc_client_type CHECK (client_type IN ('web', 'mobile', 'service')),
+    CONSTRAINT chk_iacc_client_ttl CHECK (access_token_ttl > 0 AND refresh_token_ttl >= access_token_ttl)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_client_seq ON "iacc_client" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_client_created_at_seq ON "iacc_client" (created_at, seq);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_iacc_client'
+          AND tgrelid = 'iacc_client'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_iacc_client
+            BEFORE UPDATE ON "iacc_client"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
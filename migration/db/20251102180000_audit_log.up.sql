@@ -0,0 +1,19 @@
+-- 审计日志：角色、权限、用户角色分配等管理操作的记录，只追加不修改
+-- actor_id、entity_id 不设外键：用户或实体删除后审计记录保留
+CREATE TABLE IF NOT EXISTS "audit_log" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    actor_id UUID,
+    action VARCHAR(50) NOT NULL,
+    entity VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100),
+    detail JSONB NOT NULL DEFAULT '{}',
+    ip VARCHAR(64),
+    trace_id VARCHAR(64)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_log_seq ON "audit_log" (seq);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at_seq ON "audit_log" (created_at, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id_created_at ON "audit_log" (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity_entity_id ON "audit_log" (entity, entity_id);
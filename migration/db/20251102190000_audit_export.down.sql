@@ -0,0 +1,6 @@
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_audit_export_preset ON "audit_export_preset";
+
+-- 删除表
+DROP TABLE IF EXISTS "audit_export_file";
+DROP TABLE IF EXISTS "audit_export_preset";
@@ -0,0 +1,36 @@
+-- 审计日志导出的筛选预设，每个用户保存自己的筛选条件，用于定期的合规导出
+CREATE TABLE IF NOT EXISTS "audit_export_preset" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    owner_id UUID NOT NULL REFERENCES "iacc_user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_export_preset_seq ON "audit_export_preset" (seq);
+CREATE INDEX IF NOT EXISTS idx_audit_export_preset_owner_id_created_at ON "audit_export_preset" (owner_id, created_at);
+
+-- 审计日志异步导出生成的 CSV 文件，按任务ID下载
+CREATE TABLE IF NOT EXISTS "audit_export_file" (
+    job_id UUID PRIMARY KEY REFERENCES "async_job"(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    data BYTEA NOT NULL
+);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_audit_export_preset'
+          AND tgrelid = 'audit_export_preset'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_audit_export_preset
+            BEFORE UPDATE ON "audit_export_preset"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
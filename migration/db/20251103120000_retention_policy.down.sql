@@ -0,0 +1,9 @@
+-- 删除索引
+DROP INDEX IF EXISTS idx_iacc_user_device_last_seen_at;
+DROP INDEX IF EXISTS idx_async_job_finished_at;
+
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_retention_policy ON "retention_policy";
+
+-- 删除表
+DROP TABLE IF EXISTS "retention_policy";
@@ -0,0 +1,37 @@
+-- 数据保留策略，每个数据类别一行，定时任务按 retain_days 清理过期数据
+CREATE TABLE IF NOT EXISTS "retention_policy" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    category VARCHAR(50) NOT NULL UNIQUE,
+    retain_days INT NOT NULL CHECK (retain_days > 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_run_at TIMESTAMPTZ,
+    last_purged BIGINT NOT NULL DEFAULT 0
+);
+
+-- 默认策略：已结束的异步任务保留 30 天，超过 90 天未登录的设备记录清理
+INSERT INTO "retention_policy" (category, retain_days) VALUES
+    ('async_job', 30),
+    ('user_device', 90)
+ON CONFLICT (category) DO NOTHING;
+
+-- 异步任务按结束时间清理
+CREATE INDEX IF NOT EXISTS idx_async_job_finished_at ON "async_job" (finished_at) WHERE finished_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_iacc_user_device_last_seen_at ON "iacc_user_device" (last_seen_at);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_retention_policy'
+          AND tgrelid = 'retention_policy'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_retention_policy
+            BEFORE UPDATE ON "retention_policy"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
@@ -0,0 +1,7 @@
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_iacc_role_change ON "iacc_role_change";
+
+-- 删除表
+DROP TABLE IF EXISTS "iacc_role_change";
+
+ALTER TABLE "iacc_role" DROP COLUMN IF EXISTS critical;
@@ -0,0 +1,39 @@
+-- 关键角色的变更需要另一位管理员审批后才生效
+ALTER TABLE "iacc_role" ADD COLUMN IF NOT EXISTS critical BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 关键角色的待审批变更，payload 为变更请求体，审批通过时按 action 重新执行
+-- role_id 不设外键：删除角色的变更通过后仍保留审批记录
+CREATE TABLE IF NOT EXISTS "iacc_role_change" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    role_id UUID NOT NULL,
+    action VARCHAR(30) NOT NULL CHECK (action IN ('update', 'patch', 'delete', 'assign_permissions', 'sync_permissions')),
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    requested_by UUID NOT NULL,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_role_change_seq ON "iacc_role_change" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_role_change_created_at_seq ON "iacc_role_change" (created_at, seq);
+-- 每个角色同时只能有一个待审批的变更
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_role_change_pending ON "iacc_role_change" (role_id) WHERE status = 'pending';
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_iacc_role_change'
+          AND tgrelid = 'iacc_role_change'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_iacc_role_change
+            BEFORE UPDATE ON "iacc_role_change"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_template_owner_id_num;
+DROP INDEX IF EXISTS idx_template_num;
@@ -0,0 +1,3 @@
+-- 列表接口按数量筛选（num、numMin、numMax）：查询全部模板时走 num 索引，默认只查自己的模板时走 (owner_id, num) 索引
+CREATE INDEX IF NOT EXISTS idx_template_num ON "template" (num);
+CREATE INDEX IF NOT EXISTS idx_template_owner_id_num ON "template" (owner_id, num);
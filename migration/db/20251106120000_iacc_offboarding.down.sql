@@ -0,0 +1,7 @@
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_iacc_offboarding ON "iacc_offboarding";
+
+-- 删除表
+DROP TABLE IF EXISTS "iacc_offboarding";
+
+ALTER TABLE "iacc_user" DROP COLUMN IF EXISTS disabled_at;
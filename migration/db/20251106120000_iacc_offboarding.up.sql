@@ -0,0 +1,38 @@
+-- 账号停用时间，非空表示账号已停用，停用后不能登录或刷新令牌
+ALTER TABLE "iacc_user" ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
+
+-- 离职交接记录，交接由 job_id 对应的异步任务执行，report 为执行结果
+-- user_id、successor_id 不设外键：删除用户后仍保留交接记录
+CREATE TABLE IF NOT EXISTS "iacc_offboarding" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    user_id UUID NOT NULL,
+    successor_id UUID NOT NULL,
+    requested_by UUID NOT NULL,
+    reason VARCHAR(500),
+    job_id UUID,
+    report JSONB,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_offboarding_seq ON "iacc_offboarding" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_offboarding_created_at_seq ON "iacc_offboarding" (created_at, seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_offboarding_user_id ON "iacc_offboarding" (user_id);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_iacc_offboarding'
+          AND tgrelid = 'iacc_offboarding'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_iacc_offboarding
+            BEFORE UPDATE ON "iacc_offboarding"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
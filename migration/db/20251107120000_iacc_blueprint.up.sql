@@ -0,0 +1,31 @@
+-- 入职蓝图：按岗位预设的角色与默认个人信息，通过蓝图创建用户时一并应用
+-- role_ids 不设外键：角色删除后蓝图保留，应用时只分配仍存在的角色
+CREATE TABLE IF NOT EXISTS "iacc_blueprint" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(500),
+    role_ids UUID[] NOT NULL DEFAULT '{}',
+    profile JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_blueprint_seq ON "iacc_blueprint" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_blueprint_created_at_seq ON "iacc_blueprint" (created_at, seq);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_iacc_blueprint'
+          AND tgrelid = 'iacc_blueprint'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_iacc_blueprint
+            BEFORE UPDATE ON "iacc_blueprint"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
@@ -0,0 +1,2 @@
+ALTER TABLE "iacc_permission" DROP COLUMN IF EXISTS translations;
+ALTER TABLE "iacc_role" DROP COLUMN IF EXISTS translations;
@@ -0,0 +1,3 @@
+-- 角色、权限显示名称与描述的多语言翻译，键为语言标签（如 en-US），值为 {"name": ..., "description": ...}
+ALTER TABLE "iacc_role" ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE "iacc_permission" ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}';
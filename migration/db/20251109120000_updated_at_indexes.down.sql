@@ -0,0 +1,6 @@
+-- module: template
+DROP INDEX IF EXISTS idx_template_updated_at_seq;
+-- end module
+DROP INDEX IF EXISTS idx_iacc_permission_updated_at_seq;
+DROP INDEX IF EXISTS idx_iacc_role_updated_at_seq;
+DROP INDEX IF EXISTS idx_iacc_user_updated_at_seq;
@@ -0,0 +1,8 @@
+-- 列表接口的条件请求按 MAX(updated_at) 计算 Last-Modified，增量同步按 updated_at > changedSince 查询
+CREATE INDEX IF NOT EXISTS idx_iacc_user_updated_at_seq ON "iacc_user" (updated_at, seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_role_updated_at_seq ON "iacc_role" (updated_at, seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_permission_updated_at_seq ON "iacc_permission" (updated_at, seq);
+
+-- module: template
+CREATE INDEX IF NOT EXISTS idx_template_updated_at_seq ON "template" (updated_at, seq);
+-- end module
@@ -0,0 +1,10 @@
+DELETE FROM "retention_policy" WHERE category = 'sync_tombstone';
+
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_record_tombstone_template ON "template";
+DROP FUNCTION IF EXISTS record_template_tombstone();
+
+DROP INDEX IF EXISTS idx_template_owner_updated_at;
+
+-- 删除表
+DROP TABLE IF EXISTS "template_tombstone";
@@ -0,0 +1,40 @@
+-- 已删除模板的墓碑记录，供离线客户端增量同步（/v1/sync/template）拉取删除操作
+-- 模板被删除时由触发器写入，同一ID重复删除（删除后以同一ID重新创建再删除）时更新删除时间
+CREATE TABLE IF NOT EXISTS "template_tombstone" (
+    id UUID PRIMARY KEY,
+    owner_id UUID,
+    deleted_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_template_tombstone_owner_deleted_at ON "template_tombstone" (owner_id, deleted_at, id);
+-- 增量同步按所有者拉取 (updated_at, id) 之后的变更
+CREATE INDEX IF NOT EXISTS idx_template_owner_updated_at ON "template" (owner_id, updated_at, id);
+
+CREATE OR REPLACE FUNCTION record_template_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO "template_tombstone" (id, owner_id) VALUES (OLD.id, OLD.owner_id)
+    ON CONFLICT (id) DO UPDATE SET owner_id = EXCLUDED.owner_id, deleted_at = EXCLUDED.deleted_at;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_record_tombstone_template'
+          AND tgrelid = 'template'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_record_tombstone_template
+            AFTER DELETE ON "template"
+            FOR EACH ROW
+            EXECUTE FUNCTION record_template_tombstone();
+    END IF;
+END $$;
+
+-- 墓碑默认保留 90 天，离线超过保留期限的客户端需要全量同步
+INSERT INTO "retention_policy" (category, retain_days) VALUES
+    ('sync_tombstone', 90)
+ON CONFLICT (category) DO NOTHING;
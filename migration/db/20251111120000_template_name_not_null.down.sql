@@ -0,0 +1 @@
+ALTER TABLE "template" ALTER COLUMN name DROP NOT NULL;
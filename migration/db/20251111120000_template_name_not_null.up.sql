@@ -0,0 +1,3 @@
+-- 所有写入接口都要求模板名称，实体按非空字符串扫描；补齐历史空值后加上非空约束
+UPDATE "template" SET name = '' WHERE name IS NULL;
+ALTER TABLE "template" ALTER COLUMN name SET NOT NULL;
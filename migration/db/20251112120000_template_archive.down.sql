@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_template_archived_at;
+
+ALTER TABLE "template" DROP COLUMN IF EXISTS archived_at;
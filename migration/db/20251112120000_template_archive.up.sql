@@ -0,0 +1,5 @@
+-- 模板归档：归档的模板不出现在列表中，归档超过 archive.after 后由定时任务导出到对象存储并从数据库删除
+ALTER TABLE "template" ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- 导出任务按归档时间查找待导出的模板
+CREATE INDEX IF NOT EXISTS idx_template_archived_at ON "template" (archived_at) WHERE archived_at IS NOT NULL;
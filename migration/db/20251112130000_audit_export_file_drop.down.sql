@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS "audit_export_file" (
+    job_id UUID PRIMARY KEY REFERENCES "async_job"(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    data BYTEA NOT NULL
+);
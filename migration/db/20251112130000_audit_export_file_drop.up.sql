@@ -0,0 +1,3 @@
+-- 审计日志异步导出的文件改为写入对象存储（storage），不再保存在数据库中
+-- 已完成的导出文件随表删除，需要时重新发起导出
+DROP TABLE IF EXISTS "audit_export_file";
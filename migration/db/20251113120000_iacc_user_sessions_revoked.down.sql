@@ -0,0 +1 @@
+ALTER TABLE "iacc_user" DROP COLUMN IF EXISTS sessions_revoked_at;
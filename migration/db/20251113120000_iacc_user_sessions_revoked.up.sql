@@ -0,0 +1,2 @@
+-- 会话撤销时间，早于该时间签发的刷新令牌不能再换取新令牌，由运维命令 revoke-sessions 设置
+ALTER TABLE "iacc_user" ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_saved_view ON "saved_view";
+
+-- 删除表
+DROP TABLE IF EXISTS "saved_view";
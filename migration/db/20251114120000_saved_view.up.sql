@@ -0,0 +1,34 @@
+-- 保存的列表视图：用户为某类实体的列表保存的筛选条件、排序与显示列，可共享给角色
+-- shared_role_ids 不设外键：角色删除后视图保留，只是不再对该角色可见
+CREATE TABLE IF NOT EXISTS "saved_view" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    owner_id UUID NOT NULL REFERENCES "iacc_user"(id) ON DELETE CASCADE,
+    entity VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    config JSONB NOT NULL DEFAULT '{}',
+    shared_role_ids UUID[] NOT NULL DEFAULT '{}',
+    UNIQUE (owner_id, entity, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_view_seq ON "saved_view" (seq);
+CREATE INDEX IF NOT EXISTS idx_saved_view_entity ON "saved_view" (entity);
+CREATE INDEX IF NOT EXISTS idx_saved_view_shared_role_ids ON "saved_view" USING GIN (shared_role_ids);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_saved_view'
+          AND tgrelid = 'saved_view'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_saved_view
+            BEFORE UPDATE ON "saved_view"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
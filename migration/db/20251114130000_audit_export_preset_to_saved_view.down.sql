@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS "audit_export_preset" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    owner_id UUID NOT NULL REFERENCES "iacc_user"(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_export_preset_seq ON "audit_export_preset" (seq);
+CREATE INDEX IF NOT EXISTS idx_audit_export_preset_owner_id_created_at ON "audit_export_preset" (owner_id, created_at);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_audit_export_preset'
+          AND tgrelid = 'audit_export_preset'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_audit_export_preset
+            BEFORE UPDATE ON "audit_export_preset"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
+
+INSERT INTO "audit_export_preset" (created_at, updated_at, owner_id, name, filters)
+SELECT created_at, updated_at, owner_id, name, COALESCE(config -> 'filters', '{}')
+FROM "saved_view"
+WHERE entity = 'audit'
+ORDER BY seq;
+
+DELETE FROM "saved_view" WHERE entity = 'audit';
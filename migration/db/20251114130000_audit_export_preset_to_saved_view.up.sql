@@ -0,0 +1,9 @@
+-- 审计日志导出的筛选预设改为保存的列表视图（entity = 'audit'），筛选条件写入 config.filters
+-- 同一用户下与已有视图重名的预设不再迁移
+INSERT INTO "saved_view" (created_at, updated_at, owner_id, entity, name, config)
+SELECT created_at, updated_at, owner_id, 'audit', name, jsonb_build_object('filters', filters)
+FROM "audit_export_preset"
+ORDER BY seq
+ON CONFLICT (owner_id, entity, name) DO NOTHING;
+
+DROP TABLE IF EXISTS "audit_export_preset";
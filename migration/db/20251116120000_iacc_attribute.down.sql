@@ -0,0 +1,9 @@
+-- 删除角色、权限的自定义属性值
+ALTER TABLE "iacc_permission" DROP COLUMN IF EXISTS attributes;
+ALTER TABLE "iacc_role" DROP COLUMN IF EXISTS attributes;
+
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_iacc_attribute ON "iacc_attribute";
+
+-- 删除表
+DROP TABLE IF EXISTS "iacc_attribute";
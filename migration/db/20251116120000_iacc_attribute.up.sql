@@ -0,0 +1,38 @@
+-- 自定义属性定义：管理员为角色、权限登记可用的属性键及其类型，实体的 attributes 列只能使用已登记的键
+-- enum 类型的取值限定在 options 中；required 为 true 时创建、整体更新实体必须提供该属性
+CREATE TABLE IF NOT EXISTS "iacc_attribute" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    entity VARCHAR(20) NOT NULL CHECK (entity IN ('role', 'permission')),
+    key VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT false,
+    description TEXT NOT NULL DEFAULT '',
+    UNIQUE (entity, key)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_attribute_seq ON "iacc_attribute" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_attribute_created_at ON "iacc_attribute" (created_at);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_iacc_attribute'
+          AND tgrelid = 'iacc_attribute'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_iacc_attribute
+            BEFORE UPDATE ON "iacc_attribute"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
+
+-- 角色、权限的自定义属性值
+ALTER TABLE "iacc_role" ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE "iacc_permission" ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
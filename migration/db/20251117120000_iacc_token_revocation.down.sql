@@ -0,0 +1,7 @@
+DELETE FROM "retention_policy" WHERE category = 'token_revocation';
+
+DROP INDEX IF EXISTS idx_iacc_token_revocation_user_id;
+DROP INDEX IF EXISTS idx_iacc_token_revocation_expires_at;
+
+-- 删除表
+DROP TABLE IF EXISTS "iacc_token_revocation";
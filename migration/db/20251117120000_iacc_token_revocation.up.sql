@@ -0,0 +1,17 @@
+-- 令牌黑名单：退出登录等场景撤销的访问令牌与刷新令牌，按令牌标识（jti）记录
+-- 记录只需保留到令牌过期，过期后由数据保留策略清理
+CREATE TABLE IF NOT EXISTS "iacc_token_revocation" (
+    jti UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id UUID REFERENCES "iacc_user"(id) ON DELETE CASCADE,
+    token_type VARCHAR(20) NOT NULL CHECK (token_type IN ('access', 'refresh')),
+    expires_at TIMESTAMPTZ(6) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_iacc_token_revocation_expires_at ON "iacc_token_revocation" (expires_at);
+CREATE INDEX IF NOT EXISTS idx_iacc_token_revocation_user_id ON "iacc_token_revocation" (user_id);
+
+-- 令牌过期后黑名单记录不再需要，默认保留 1 天
+INSERT INTO "retention_policy" (category, retain_days) VALUES
+    ('token_revocation', 1)
+ON CONFLICT (category) DO NOTHING;
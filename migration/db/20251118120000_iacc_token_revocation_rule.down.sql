@@ -0,0 +1,6 @@
+DELETE FROM "retention_policy" WHERE category = 'token_revocation_rule';
+
+-- 删除表
+DROP TABLE IF EXISTS "iacc_token_revocation_rule";
+
+ALTER TABLE "iacc_user" DROP COLUMN IF EXISTS token_version;
@@ -0,0 +1,24 @@
+-- 令牌版本：访问令牌签发时记录用户当前的令牌版本，批量撤销时提升版本，旧版本的访问令牌随即失效
+ALTER TABLE "iacc_user" ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
+
+-- 批量撤销规则：签发时间不晚于 issued_before、且满足用户与客户端类型条件（为空表示不限）的刷新令牌失效
+-- 一次撤销写入一条规则，user_ids 为空表示全部用户
+-- 刷新令牌不落库，按规则在刷新时判断；规则需要保留到被撤销的刷新令牌全部过期之后
+CREATE TABLE IF NOT EXISTS "iacc_token_revocation_rule" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    user_ids UUID[],
+    client_type VARCHAR(20) CHECK (client_type IN ('web', 'mobile', 'service')),
+    issued_before TIMESTAMPTZ(6) NOT NULL,
+    created_by UUID
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_token_revocation_rule_seq ON "iacc_token_revocation_rule" (seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_token_revocation_rule_created_at ON "iacc_token_revocation_rule" (created_at);
+CREATE INDEX IF NOT EXISTS idx_iacc_token_revocation_rule_user_ids ON "iacc_token_revocation_rule" USING GIN (user_ids);
+
+-- 规则默认保留 90 天，应大于各客户端刷新令牌的最长有效期
+INSERT INTO "retention_policy" (category, retain_days) VALUES
+    ('token_revocation_rule', 90)
+ON CONFLICT (category) DO NOTHING;
@@ -0,0 +1 @@
+DROP MATERIALIZED VIEW IF EXISTS "iacc_user_search";
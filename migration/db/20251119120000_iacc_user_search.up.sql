@@ -0,0 +1,34 @@
+-- 用户列表的物化视图：预先聚合用户的角色名称，开启 user_search 后 /v1/user/list 从这里查询，不再实时关联角色表
+-- 由定时任务 REFRESH MATERIALIZED VIEW CONCURRENTLY 刷新，刷新期间不阻塞查询；refreshed_at 为本次刷新的时间
+CREATE MATERIALIZED VIEW IF NOT EXISTS "iacc_user_search" AS
+SELECT
+    u.id,
+    u.username,
+    u.phone,
+    u.phone_hash,
+    u.profile,
+    u.email_hash,
+    u.strict_device,
+    u.disabled_at,
+    u.created_at,
+    u.updated_at,
+    u.seq,
+    COALESCE(roles.names, '{}') AS role_names,
+    now() AS refreshed_at
+FROM "iacc_user" u
+LEFT JOIN LATERAL (
+    SELECT array_agg(r.name ORDER BY r.name) AS names
+    FROM "iacc_user_role" ur
+    JOIN "iacc_role" r ON r.id = ur.role_id
+    WHERE ur.user_id = u.id
+) roles ON true;
+
+-- 并发刷新要求唯一索引
+CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_user_search_id ON "iacc_user_search" (id);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_search_created_at_seq ON "iacc_user_search" (created_at, seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_search_updated_at_seq ON "iacc_user_search" (updated_at, seq);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_search_username_trgm ON "iacc_user_search" USING gin (username public.gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_search_phone_trgm ON "iacc_user_search" USING gin (phone public.gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_search_phone_hash ON "iacc_user_search" (phone_hash);
+CREATE INDEX IF NOT EXISTS idx_iacc_user_search_email ON "iacc_user_search" (lower(profile->>'email'));
+CREATE INDEX IF NOT EXISTS idx_iacc_user_search_email_hash ON "iacc_user_search" (email_hash);
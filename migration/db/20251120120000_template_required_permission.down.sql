@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_template_required_permission;
+
+ALTER TABLE "template" DROP COLUMN IF EXISTS required_permission;
@@ -0,0 +1,5 @@
+-- 模板要求的权限：设置后只有拥有该权限编码的用户（或拥有管理全部模板权限的用户）可以看到模板
+ALTER TABLE "template" ADD COLUMN IF NOT EXISTS required_permission VARCHAR(100);
+
+-- 查询模板要求的全部权限编码
+CREATE INDEX IF NOT EXISTS idx_template_required_permission ON "template" (required_permission) WHERE required_permission IS NOT NULL;
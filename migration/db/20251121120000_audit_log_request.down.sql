@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_audit_log_entity_action_created_at;
+
+ALTER TABLE "audit_log" DROP COLUMN IF EXISTS path;
+ALTER TABLE "audit_log" DROP COLUMN IF EXISTS method;
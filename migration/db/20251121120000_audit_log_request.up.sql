@@ -0,0 +1,6 @@
+-- 审计日志记录请求方法与路由模板（如 /v1/user/:id），便于按接口筛选
+ALTER TABLE "audit_log" ADD COLUMN IF NOT EXISTS method VARCHAR(10);
+ALTER TABLE "audit_log" ADD COLUMN IF NOT EXISTS path VARCHAR(255);
+
+-- 按实体类型、操作筛选审计日志
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity_action_created_at ON "audit_log" (entity, action, created_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_iacc_role_parent_role_id;
+
+ALTER TABLE "iacc_role" DROP CONSTRAINT IF EXISTS chk_iacc_role_parent_not_self;
+ALTER TABLE "iacc_role" DROP COLUMN IF EXISTS parent_role_id;
@@ -0,0 +1,7 @@
+-- 角色继承：角色继承父角色（及其祖先角色）的全部权限，父角色删除后子角色不再继承
+ALTER TABLE "iacc_role" ADD COLUMN IF NOT EXISTS parent_role_id UUID REFERENCES "iacc_role" (id) ON DELETE SET NULL;
+ALTER TABLE "iacc_role" DROP CONSTRAINT IF EXISTS chk_iacc_role_parent_not_self;
+ALTER TABLE "iacc_role" ADD CONSTRAINT chk_iacc_role_parent_not_self CHECK (parent_role_id <> id);
+
+-- 查询子角色
+CREATE INDEX IF NOT EXISTS idx_iacc_role_parent_role_id ON "iacc_role" (parent_role_id) WHERE parent_role_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "iacc_role_permission_namespace";
+
+DROP INDEX IF EXISTS idx_iacc_permission_namespace;
+ALTER TABLE "iacc_permission" DROP CONSTRAINT IF EXISTS chk_iacc_permission_namespace;
+ALTER TABLE "iacc_permission" DROP COLUMN IF EXISTS namespace;
@@ -0,0 +1,38 @@
+-- 权限命名空间：层级的权限分组键，如 user、report:export，由冒号分隔的若干段组成
+ALTER TABLE "iacc_permission" ADD COLUMN IF NOT EXISTS namespace VARCHAR(255);
+
+-- 已有权限按默认规则填充：编码权限取最后一段之前的部分，接口权限取版本号之后的第一段路径
+UPDATE "iacc_permission" p SET namespace = d.namespace
+FROM (
+    SELECT id, CASE
+        WHEN metadata->>'code' LIKE '%:%' THEN regexp_replace(metadata->>'code', ':[^:]*$', '')
+        ELSE substring(metadata->>'path' FROM '^/v[0-9]+/([A-Za-z0-9_-]+)')
+    END AS namespace
+    FROM "iacc_permission"
+) d
+WHERE p.id = d.id AND p.namespace IS NULL AND d.namespace ~ '^[A-Za-z0-9_-]+(:[A-Za-z0-9_-]+)*$';
+
+ALTER TABLE "iacc_permission" DROP CONSTRAINT IF EXISTS chk_iacc_permission_namespace;
+ALTER TABLE "iacc_permission" ADD CONSTRAINT chk_iacc_permission_namespace CHECK (namespace ~ '^[A-Za-z0-9_-]+(:[A-Za-z0-9_-]+)*$');
+
+-- 按命名空间展开授予时查询其下的权限
+CREATE INDEX IF NOT EXISTS idx_iacc_permission_namespace ON "iacc_permission" (namespace) WHERE namespace IS NOT NULL;
+
+-- 按命名空间给角色授予权限：一行授予（或拒绝）该命名空间及其子命名空间下的全部权限，求值时展开，
+-- 之后新增到命名空间下的权限自动生效
+CREATE TABLE IF NOT EXISTS "iacc_role_permission_namespace" (
+    role_id UUID NOT NULL,
+    namespace VARCHAR(255) NOT NULL,
+    effect VARCHAR(10) NOT NULL DEFAULT 'allow',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role_id, namespace)
+);
+
+ALTER TABLE "iacc_role_permission_namespace" DROP CONSTRAINT IF EXISTS fk_role_permission_namespace_role;
+ALTER TABLE "iacc_role_permission_namespace"
+    ADD CONSTRAINT fk_role_permission_namespace_role
+    FOREIGN KEY (role_id) REFERENCES "iacc_role"(id) ON DELETE CASCADE;
+ALTER TABLE "iacc_role_permission_namespace" DROP CONSTRAINT IF EXISTS chk_iacc_role_permission_namespace_namespace;
+ALTER TABLE "iacc_role_permission_namespace" ADD CONSTRAINT chk_iacc_role_permission_namespace_namespace CHECK (namespace ~ '^[A-Za-z0-9_-]+(:[A-Za-z0-9_-]+)*$');
+ALTER TABLE "iacc_role_permission_namespace" DROP CONSTRAINT IF EXISTS chk_iacc_role_permission_namespace_effect;
+ALTER TABLE "iacc_role_permission_namespace" ADD CONSTRAINT chk_iacc_role_permission_namespace_effect CHECK (effect IN ('allow', 'deny'));
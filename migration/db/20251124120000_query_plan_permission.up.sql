@@ -0,0 +1,6 @@
+-- 预置查看列表查询执行计划的编码权限，root 角色会由 InitAdminRoot 自动获得
+INSERT INTO "iacc_permission" (name, type, metadata, namespace)
+SELECT '查看查询执行计划', 'data', '{"code": "debug:query_plan"}', 'debug'
+WHERE NOT EXISTS (
+    SELECT 1 FROM "iacc_permission" WHERE metadata->>'code' = 'debug:query_plan'
+);
@@ -0,0 +1,3 @@
+-- 撤销操作的审计记录（action = 'revert'）在 detail.reverted_id 中记录被撤销的审计记录ID
+-- 唯一索引保证同一条审计记录只能被撤销一次，并发的撤销请求只有一个能提交
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_log_reverted_id ON "audit_log" ((detail->>'reverted_id')) WHERE action = 'revert';
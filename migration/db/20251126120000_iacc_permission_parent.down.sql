@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_iacc_permission_parent_id;
+
+ALTER TABLE "iacc_permission" DROP CONSTRAINT IF EXISTS chk_iacc_permission_parent_not_self;
+ALTER TABLE "iacc_permission" DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,8 @@
+-- 权限树：菜单权限下挂子菜单、按钮与接口权限，父节点删除后子节点回到根级
+-- 外键延迟到提交时检查，环境快照恢复时同一表内的父子行可以按任意顺序写入
+ALTER TABLE "iacc_permission" ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES "iacc_permission" (id) ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED;
+ALTER TABLE "iacc_permission" DROP CONSTRAINT IF EXISTS chk_iacc_permission_parent_not_self;
+ALTER TABLE "iacc_permission" ADD CONSTRAINT chk_iacc_permission_parent_not_self CHECK (parent_id <> id);
+
+-- 查询子节点
+CREATE INDEX IF NOT EXISTS idx_iacc_permission_parent_id ON "iacc_permission" (parent_id) WHERE parent_id IS NOT NULL;
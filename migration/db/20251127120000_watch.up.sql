@@ -0,0 +1,31 @@
+-- 关注：用户关注某个实体（模板、用户），实体变更时通过通知发送给关注者
+-- entity_id 不设外键（实体分布在不同的表）：实体删除时由应用在通知关注者后删除对应的关注
+CREATE TABLE IF NOT EXISTS "watch" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    user_id UUID NOT NULL REFERENCES "iacc_user"(id) ON DELETE CASCADE,
+    entity VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    UNIQUE (user_id, entity, entity_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_watch_seq ON "watch" (seq);
+CREATE INDEX IF NOT EXISTS idx_watch_entity ON "watch" (entity, entity_id);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_watch'
+          AND tgrelid = 'watch'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_watch
+            BEFORE UPDATE ON "watch"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
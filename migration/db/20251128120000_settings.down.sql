@@ -0,0 +1,6 @@
+-- 删除触发器
+DROP TRIGGER IF EXISTS trigger_update_updated_at_settings ON "settings";
+
+-- 删除表
+DROP TABLE IF EXISTS "settings_history";
+DROP TABLE IF EXISTS "settings";
@@ -0,0 +1,44 @@
+-- 运行时设置：可在线调整的设置项（令牌有效期、密码策略、限流等级），每个设置项一行
+-- 没有行的设置项使用配置文件中的取值；value 的格式见 pkgs/settings.go 中的设置项定义
+-- updated_by、changed_by 不设外键：schema-per-tenant 模式下修改人是租户 schema 中的用户，用户删除后历史记录保留
+CREATE TABLE IF NOT EXISTS "settings" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    key VARCHAR(100) NOT NULL UNIQUE,
+    value JSONB NOT NULL,
+    updated_by UUID
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_settings_seq ON "settings" (seq);
+
+-- 设置变更历史，每次修改一个设置项写入一行；new_value 为空表示恢复为配置文件中的取值
+CREATE TABLE IF NOT EXISTS "settings_history" (
+    id UUID DEFAULT uuidv7() PRIMARY KEY,
+    created_at TIMESTAMPTZ(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seq BIGINT GENERATED ALWAYS AS IDENTITY,
+    key VARCHAR(100) NOT NULL,
+    old_value JSONB,
+    new_value JSONB,
+    changed_by UUID
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_settings_history_seq ON "settings_history" (seq);
+CREATE INDEX IF NOT EXISTS idx_settings_history_key ON "settings_history" (key, seq);
+
+-- 创建触发器
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_trigger
+        WHERE tgname = 'trigger_update_updated_at_settings'
+          AND tgrelid = 'settings'::regclass
+    ) THEN
+        CREATE TRIGGER trigger_update_updated_at_settings
+            BEFORE UPDATE ON "settings"
+            FOR EACH ROW
+            EXECUTE FUNCTION update_updated_at_column();
+    END IF;
+END $$;
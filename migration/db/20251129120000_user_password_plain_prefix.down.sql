@@ -0,0 +1 @@
+UPDATE "iacc_user" SET password = substr(password, length('$plain$') + 1) WHERE password LIKE '$plain$%';
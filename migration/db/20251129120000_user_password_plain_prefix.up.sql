@@ -0,0 +1,4 @@
+-- 改为保存密码哈希之前，iacc_user.password 中是明文；加上 $plain$ 前缀，
+-- password_hash.legacy_formats 默认包含 plain，这些用户可以继续登录，并在登录成功后按当前配置重新哈希；
+-- 运维从配置中移除 plain 前须确认没有 $plain$ 前缀的密码：SELECT COUNT(*) FROM "iacc_user" WHERE password LIKE '$plain$%';
+UPDATE "iacc_user" SET password = '$plain$' || password WHERE password IS NOT NULL AND password NOT LIKE '$%';
@@ -0,0 +1,3 @@
+DELETE FROM "retention_policy" WHERE category = 'refresh_token';
+
+DROP TABLE IF EXISTS "iacc_refresh_token";
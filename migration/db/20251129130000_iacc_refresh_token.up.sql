@@ -0,0 +1,17 @@
+-- 已签发的刷新令牌登记：批量撤销令牌时据此统计命中条件的令牌，只提升持有这些令牌的用户的令牌版本
+-- 令牌是否有效仍由签名、黑名单与撤销规则判断；记录只需保留到令牌过期，过期后由数据保留策略清理
+CREATE TABLE IF NOT EXISTS "iacc_refresh_token" (
+    jti UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES "iacc_user"(id) ON DELETE CASCADE,
+    client_id VARCHAR(64),
+    issued_at TIMESTAMPTZ(6) NOT NULL,
+    expires_at TIMESTAMPTZ(6) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_iacc_refresh_token_user_id_issued_at ON "iacc_refresh_token" (user_id, issued_at);
+CREATE INDEX IF NOT EXISTS idx_iacc_refresh_token_expires_at ON "iacc_refresh_token" (expires_at);
+
+-- 令牌过期后登记不再需要，默认保留 1 天
+INSERT INTO "retention_policy" (category, retain_days) VALUES
+    ('refresh_token', 1)
+ON CONFLICT (category) DO NOTHING;
@@ -0,0 +1,157 @@
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// 可关闭模块的迁移约定：
+//  1. 文件名中版本号后的描述以模块名开头的迁移整体属于该模块，例如 20251013153018_template.up.sql、20251025120000_template_usage.up.sql；
+//  2. 同时修改多个模块的迁移中，属于某个模块的语句用注释标记包裹：
+//     -- module: template
+//     ...
+//     -- end module
+//
+// 模块关闭时上述内容替换为空操作，迁移版本照常推进，保持与其他部署一致的版本号。
+const (
+	moduleBeginMarker = "-- module: "
+	moduleEndMarker   = "-- end module"
+)
+
+// 关闭模块时整体跳过的迁移文件保留的内容，空文件会被迁移工具视为错误
+const skippedMigration = "-- 模块已关闭，跳过该迁移\nSELECT 1;\n"
+
+// 模块的主表，模块重新开启时据此判断是否需要补执行该模块的迁移
+var moduleTables = map[string]func(t *pkgs.TableNames) string{
+	pkgs.ModuleTemplate: func(t *pkgs.TableNames) string { return t.Template },
+}
+
+// migrationModule 返回整体属于某个模块的迁移文件对应的模块名
+func migrationModule(name string) string {
+	_, desc, ok := strings.Cut(path.Base(name), "_")
+	if !ok {
+		return ""
+	}
+	for module := range moduleTables {
+		if desc == module || strings.HasPrefix(desc, module+"_") || strings.HasPrefix(desc, module+".") {
+			return module
+		}
+	}
+	return ""
+}
+
+// FilterModules 去掉迁移内容中属于已关闭模块的部分，name 为迁移文件名
+func FilterModules(name string, content string, disabled []string) string {
+	if module := migrationModule(name); module != "" && slices.Contains(disabled, module) {
+		return skippedMigration
+	}
+	return moduleSections(content, func(module string) bool { return !slices.Contains(disabled, module) }, true)
+}
+
+// moduleSections 按模块标记筛选迁移内容
+// keep 决定是否保留某个模块的标记块，keepUnmarked 决定是否保留标记块之外的语句
+func moduleSections(content string, keep func(module string) bool, keepUnmarked bool) string {
+	if !strings.Contains(content, moduleBeginMarker) {
+		if keepUnmarked {
+			return content
+		}
+		return ""
+	}
+	var b strings.Builder
+	current := ""
+	for line := range strings.Lines(content) {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, moduleBeginMarker):
+			current = strings.TrimSpace(strings.TrimPrefix(trimmed, moduleBeginMarker))
+			continue
+		case trimmed == moduleEndMarker:
+			current = ""
+			continue
+		}
+		if (current == "" && keepUnmarked) || (current != "" && keep(current)) {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// moduleUpSQL 按版本顺序汇总某个模块在所有 up 迁移中的语句
+func moduleUpSQL(module string, tables *pkgs.TableNames) (string, error) {
+	entries, err := fs.ReadDir(migrationsFS, "db")
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	// ReadDir 按文件名排序，即按版本号排序
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		content, err := fs.ReadFile(migrationsFS, path.Join("db", entry.Name()))
+		if err != nil {
+			return "", err
+		}
+		sql := string(content)
+		if migrationModule(entry.Name()) != module {
+			sql = moduleSections(sql, func(m string) bool { return m == module }, false)
+		}
+		if strings.TrimSpace(sql) != "" {
+			b.WriteString(sql)
+			b.WriteString("\n")
+		}
+	}
+	return tables.Rename(b.String()), nil
+}
+
+// applyEnabledModules 为已开启但主表不存在的模块补执行迁移
+// 模块关闭期间部署的迁移版本已记录为完成，重新开启后不会再由迁移工具执行；模块迁移均可重复执行。
+func applyEnabledModules(db *sqlx.DB, config *pkgs.Config, tables *pkgs.TableNames, schema string) error {
+	disabled := config.Modules.Disabled()
+	for module, table := range moduleTables {
+		if slices.Contains(disabled, module) {
+			continue
+		}
+		if err := applyModule(db, tables, schema, module, table(tables)); err != nil {
+			return fmt.Errorf("failed to apply migrations of module %s: %w", module, err)
+		}
+	}
+	return nil
+}
+
+func applyModule(db *sqlx.DB, tables *pkgs.TableNames, schema string, module string, table string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if schema != "" {
+		if _, err := tx.Exec(`SET LOCAL search_path TO "` + schema + `"`); err != nil {
+			return err
+		}
+	}
+	var exists bool
+	if err := tx.Get(&exists, `SELECT to_regclass($1) IS NOT NULL`, table); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	sql, err := moduleUpSQL(module, tables)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(sql); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,102 @@
+package pkgs
+
+import (
+	"database/sql/driver"
+	"net"
+	"slices"
+	"time"
+)
+
+// 角色访问条件不满足时返回的业务码，与 403 无接口权限区分，便于前端提示具体原因
+const (
+	CodeAccessTimeDenied = 40301
+	CodeAccessIPDenied   = 40302
+)
+
+// AccessConditions 角色的访问条件（iacc_role.access_conditions），为空表示不限制
+// 请求不满足角色的条件时，该角色的授予规则在本次请求中不生效；拒绝规则始终生效。
+type AccessConditions struct {
+	// 允许访问的时间段，满足任一时间段即可；为空表示不限时间
+	TimeWindows []TimeWindow `json:"time_windows,omitempty" validate:"omitempty,dive" label:"允许访问的时间段"`
+	// 允许访问的来源 IP 段（CIDR），为空表示不限 IP
+	CIDRs []string `json:"cidrs,omitempty" validate:"omitempty,dive,cidr" label:"允许访问的IP段"`
+}
+
+// TimeWindow 每天允许访问的时间段，End 早于 Start 时表示跨越午夜（如 22:00 - 06:00）
+type TimeWindow struct {
+	// 生效的星期，0 表示周日；为空表示每天
+	Weekdays []int  `json:"weekdays,omitempty" validate:"omitempty,dive,min=0,max=6" label:"星期"`
+	Start    string `json:"start" validate:"required,datetime=15:04" label:"开始时间"`
+	End      string `json:"end" validate:"required,datetime=15:04" label:"结束时间"`
+	// IANA 时区名称，为空时使用服务器本地时区
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,timezone" label:"时区"`
+}
+
+func (a *AccessConditions) Scan(value any) error {
+	return GenericJSONScan(a, value)
+}
+
+func (a AccessConditions) Value() (driver.Value, error) {
+	return GenericJSONValue(a)
+}
+
+// Check 判断请求时间与来源 IP 是否满足条件，不满足时返回带业务码的错误
+func (a *AccessConditions) Check(now time.Time, clientIP string) *ApiError {
+	if a == nil {
+		return nil
+	}
+	if len(a.TimeWindows) > 0 && !slices.ContainsFunc(a.TimeWindows, func(w TimeWindow) bool { return w.contains(now) }) {
+		return NewApiError(CodeAccessTimeDenied, "当前时间不在角色允许的访问时间段内")
+	}
+	if len(a.CIDRs) > 0 && !ipInCIDRs(clientIP, a.CIDRs) {
+		return NewApiError(CodeAccessIPDenied, "当前IP不在角色允许的访问范围内")
+	}
+	return nil
+}
+
+// contains 判断时间是否落在时间段内，跨午夜的时间段按开始时间所在的星期判断
+func (w TimeWindow) contains(now time.Time) bool {
+	loc := time.Local
+	if w.Timezone != "" {
+		if l, err := time.LoadLocation(w.Timezone); err == nil {
+			loc = l
+		}
+	}
+	start, err1 := time.Parse("15:04", w.Start)
+	end, err2 := time.Parse("15:04", w.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	weekday := local.Weekday()
+	var inWindow bool
+	switch {
+	case from <= to:
+		inWindow = minute >= from && minute < to
+	case minute >= from:
+		inWindow = true
+	case minute < to:
+		// 跨午夜时间段的后半段属于前一天开始的时间段
+		inWindow = true
+		weekday = (weekday + 6) % 7
+	}
+	return inWindow && (len(w.Weekdays) == 0 || slices.Contains(w.Weekdays, int(weekday)))
+}
+
+func ipInCIDRs(clientIP string, cidrs []string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package pkgs
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// API 密钥前缀，便于在日志、代码仓库中识别泄露的密钥
+const apiKeyPrefix = "pk_"
+
+// API 密钥在 gin.Context 中的键
+const (
+	APIKeyIDContextKey   = "api_key_id"
+	APIKeyTierContextKey = "api_key_tier"
+)
+
+// GenerateAPIKey 生成新的 API 密钥
+// 返回完整密钥（只在创建时返回给调用方一次）、用于展示和识别的前缀、以及入库保存的哈希值
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 24)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	prefix = key[:len(apiKeyPrefix)+8]
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// HashAPIKey 计算 API 密钥的哈希值（SHA-256 十六进制），数据库只保存哈希
+// 密钥本身是高熵随机值，无需加盐或慢哈希
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,118 @@
+package pkgs
+
+import (
+	"mime"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 接口版本协商
+// 客户端通过 Accept: application/vnd.gopgdemo.v{N}+json 指定响应的版本，未指定时使用 DefaultAPIVersion，
+// 现有客户端不受之后版本中字段改名、拆分的影响。响应的 Content-Type 回显协商的版本。
+const (
+	// DefaultAPIVersion 未指定版本的请求使用的版本，新版本发布后保持不变
+	DefaultAPIVersion = 1
+	// LatestAPIVersion 当前最新的版本，响应结构体即该版本的表示
+	LatestAPIVersion = 1
+
+	APIVersionContextKey = "api_version"
+)
+
+var vendorMediaType = regexp.MustCompile(`^application/vnd\.gopgdemo\.v(\d+)\+json$`)
+
+// ParseAPIVersion 从 Accept 请求头解析接口版本
+// 返回值：协商的版本、是否明确指定了版本、是否支持。
+// 没有本项目的媒体类型（如 application/json、*/*、为空）时使用默认版本；
+// 指定了多个版本时取第一个支持的版本，全部不支持时返回不支持。
+func ParseAPIVersion(accept string) (version int, explicit bool, ok bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		match := vendorMediaType.FindStringSubmatch(mediaType)
+		if match == nil {
+			continue
+		}
+		explicit = true
+		v, err := strconv.Atoi(match[1])
+		if err == nil && v >= 1 && v <= LatestAPIVersion {
+			return v, true, true
+		}
+	}
+	if explicit {
+		return 0, true, false
+	}
+	return DefaultAPIVersion, false, true
+}
+
+// APIVersion 返回本次请求协商的接口版本，未经过版本协商中间件时为默认版本
+func APIVersion(c *gin.Context) int {
+	if v := c.GetInt(APIVersionContextKey); v > 0 {
+		return v
+	}
+	return DefaultAPIVersion
+}
+
+// APIMediaType 返回接口版本对应的媒体类型
+func APIMediaType(version int) string {
+	return "application/vnd.gopgdemo.v" + strconv.Itoa(version) + "+json"
+}
+
+// representation 某个类型在某个版本及之前版本中的表示
+type representation struct {
+	version int
+	render  func(c *gin.Context, data any) any
+}
+
+var (
+	representationsMu sync.RWMutex
+	representations   = map[reflect.Type][]representation{}
+)
+
+// RegisterRepresentation 注册类型 T 面向版本 version 及更早版本客户端的表示
+// 响应结构体始终是最新版本的表示；改名、拆分字段时修改结构体，并为旧版本注册转换函数，返回旧版本的结构。
+// 请求的版本为 N 时，选用注册版本不小于 N 中最小的一个，没有时直接返回结构体。应在 init 或 Handler 构造函数中注册。
+//
+//	// v2 将 profile 拆分为 contact 与 preferences，v1 客户端仍看到 profile
+//	pkgs.RegisterRepresentation(1, func(c *gin.Context, res GetByIDRes) any { return toV1(res) })
+func RegisterRepresentation[T any](version int, render func(c *gin.Context, data T) any) {
+	t := reflect.TypeFor[T]()
+	representationsMu.Lock()
+	defer representationsMu.Unlock()
+	list := append(representations[t], representation{version: version, render: func(c *gin.Context, data any) any {
+		return render(c, data.(T))
+	}})
+	sort.Slice(list, func(i, j int) bool { return list[i].version < list[j].version })
+	representations[t] = list
+}
+
+// Represent 按本次请求的接口版本选择数据的表示，Success 在写出响应前调用
+func Represent(c *gin.Context, data any) any {
+	if data == nil {
+		return nil
+	}
+	representationsMu.RLock()
+	list := representations[reflect.TypeOf(data)]
+	representationsMu.RUnlock()
+	version := APIVersion(c)
+	for _, r := range list {
+		if r.version >= version {
+			return r.render(c, data)
+		}
+	}
+	return data
+}
+
+// writeAPIVersion 明确指定了版本的请求，响应的 Content-Type 回显该版本
+func writeAPIVersion(c *gin.Context) {
+	if _, explicit := c.Get(APIVersionContextKey); explicit {
+		c.Header("Content-Type", APIMediaType(APIVersion(c))+"; charset=utf-8")
+	}
+}
@@ -0,0 +1,212 @@
+package pkgs
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// 可以定义自定义属性的实体类型
+const (
+	AttributeEntityRole       = "role"
+	AttributeEntityPermission = "permission"
+)
+
+// 自定义属性的值类型，enum 的取值限定在 Options 中
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+)
+
+// 属性键格式：小写字母开头，由小写字母、数字、下划线组成
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ValidAttributeKey 判断属性键格式是否有效
+func ValidAttributeKey(key string) bool {
+	return attributeKeyPattern.MatchString(key)
+}
+
+// Attributes 实体的自定义属性，存储在 attributes JSONB 列中，键与值类型由属性定义（iacc_attribute）约束
+// 部署方可以为角色、权限打标签（如成本中心、负责团队）而不需要修改表结构。
+type Attributes map[string]any
+
+// Value 实现 driver.Valuer 接口
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("{}"), nil
+	}
+	return GenericJSONValue(a)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (a *Attributes) Scan(value any) error {
+	return GenericJSONScan(a, value)
+}
+
+// OrEmpty 未传入属性时返回空集合，保证响应中的 attributes 为 {} 而不是 null
+func (a Attributes) OrEmpty() Attributes {
+	if a == nil {
+		return Attributes{}
+	}
+	return a
+}
+
+// SplitAttributePatch 将合并更新的属性拆分为需要写入的属性与需要删除的键（值为 null），
+// 配合 attributes = (attributes || :set) - CAST(:removed AS text[]) 使用
+func SplitAttributePatch(patch Attributes) (set Attributes, removed []string) {
+	set = Attributes{}
+	removed = []string{}
+	for key, value := range patch {
+		if value == nil {
+			removed = append(removed, key)
+		} else {
+			set[key] = value
+		}
+	}
+	sort.Strings(removed)
+	return set, removed
+}
+
+// AttributeDefinition 属性定义中校验属性值需要的字段
+type AttributeDefinition struct {
+	Key      string         `db:"key"`
+	Type     string         `db:"type"`
+	Options  pq.StringArray `db:"options"`
+	Required bool           `db:"required"`
+}
+
+// LoadAttributeDefinitions 查询实体类型的全部属性定义
+func LoadAttributeDefinitions(ctx context.Context, db sqlx.QueryerContext, tables *TableNames, entity string) ([]AttributeDefinition, error) {
+	var defs []AttributeDefinition
+	query := `SELECT key, type, options, required FROM ` + tables.Attribute + ` WHERE entity = $1 ORDER BY key`
+	if err := sqlx.SelectContext(ctx, db, &defs, query, entity); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+// CheckAttributes 按实体类型的属性定义校验请求中的属性值，fields[i] 为 attrs[i] 在请求中的字段名
+// 存在违规时返回带违规明细的 400 错误；complete 的含义与 AttributeViolations 相同。
+func CheckAttributes(ctx context.Context, db sqlx.QueryerContext, logger *zap.Logger, tables *TableNames, entity string, complete bool, fields []string, attrs []Attributes) *ApiError {
+	if !complete && !slices.ContainsFunc(attrs, func(a Attributes) bool { return len(a) > 0 }) {
+		return nil
+	}
+	defs, err := LoadAttributeDefinitions(ctx, db, tables, entity)
+	if err != nil {
+		return DBError(logger, err, "查询属性定义失败")
+	}
+	var violations []Violation
+	for i, a := range attrs {
+		violations = append(violations, AttributeViolations(fields[i], defs, a, complete)...)
+	}
+	if len(violations) > 0 {
+		return NewViolationError(violations)
+	}
+	return nil
+}
+
+// AttributeViolations 按属性定义校验属性值，field 为请求中属性所在的字段名（如 attributes、roles[0].attributes）
+// 未定义的属性、类型不符、enum 取值不在选项中均为违规；complete 为 true 时（创建、整体替换）缺少必填属性也是违规，
+// 为 false 时（合并更新）值为 null 表示删除该属性，必填属性不能删除。
+func AttributeViolations(field string, defs []AttributeDefinition, attrs Attributes, complete bool) []Violation {
+	var violations []Violation
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := attrs[key]
+		i := slices.IndexFunc(defs, func(d AttributeDefinition) bool { return d.Key == key })
+		if i < 0 {
+			violations = append(violations, Violation{Field: field + "." + key, Message: fmt.Sprintf("属性 %s 未定义", key)})
+			continue
+		}
+		def := defs[i]
+		if value == nil {
+			if complete || def.Required {
+				violations = append(violations, Violation{Field: field + "." + key, Message: fmt.Sprintf("属性 %s 为必填属性，不能为空", key)})
+			}
+			continue
+		}
+		if message := def.check(value); message != "" {
+			violations = append(violations, Violation{Field: field + "." + key, Message: message})
+		}
+	}
+	if complete {
+		for _, def := range defs {
+			if _, ok := attrs[def.Key]; def.Required && !ok {
+				violations = append(violations, Violation{Field: field + "." + def.Key, Message: fmt.Sprintf("缺少必填属性 %s", def.Key)})
+			}
+		}
+	}
+	return violations
+}
+
+// check 校验单个属性值的类型，符合时返回空字符串
+func (d AttributeDefinition) check(value any) string {
+	switch d.Type {
+	case AttributeTypeNumber:
+		if _, ok := value.(float64); !ok {
+			return fmt.Sprintf("属性 %s 必须是数字", d.Key)
+		}
+	case AttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("属性 %s 必须是布尔值", d.Key)
+		}
+	case AttributeTypeEnum:
+		s, ok := value.(string)
+		if !ok || !slices.Contains(d.Options, s) {
+			return fmt.Sprintf("属性 %s 必须是[%s]中的一个", d.Key, strings.Join(d.Options, " "))
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("属性 %s 必须是字符串", d.Key)
+		}
+	}
+	return ""
+}
+
+// AttributeFilters 列表接口按自定义属性筛选的条件，每项为 键:值（如 attr=cost_center:CC100），多项同时满足
+// 值按文本比较，数字与布尔属性分别写作 12、true。
+type AttributeFilters []string
+
+// Violations 校验筛选条件的格式
+func (f AttributeFilters) Violations(field string) []Violation {
+	var violations []Violation
+	for i, filter := range f {
+		key, _, ok := strings.Cut(filter, ":")
+		if !ok || !ValidAttributeKey(key) {
+			violations = append(violations, Violation{
+				Field:   field,
+				Message: fmt.Sprintf("属性筛选条件 %q 格式错误，应为 键:值（下标 %d）", filter, i),
+				Indexes: []int{i},
+			})
+		}
+	}
+	return violations
+}
+
+// Where 返回筛选条件的 WHERE 子句，参数写入 params；column 为属性所在的列（如 attributes、r.attributes）
+func (f AttributeFilters) Where(column string, params map[string]any) []string {
+	clauses := make([]string, 0, len(f))
+	for i, filter := range f {
+		key, value, _ := strings.Cut(filter, ":")
+		name := "attr_" + strconv.Itoa(i)
+		params[name+"_key"] = key
+		params[name+"_value"] = value
+		clauses = append(clauses, column+" ->> :"+name+"_key = :"+name+"_value")
+	}
+	return clauses
+}
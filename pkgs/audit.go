@@ -0,0 +1,221 @@
+package pkgs
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+	"go.uber.org/zap"
+)
+
+// 审计日志的实体类型，与 audit_log.entity 一致
+const (
+	AuditEntityRole       = "role"
+	AuditEntityRoleChange = "role_change"
+	AuditEntityPermission = "permission"
+	AuditEntityUser       = "user"
+)
+
+// 操作前的实体快照与批量操作的实体ID在请求上下文中的键前缀
+const (
+	auditBeforeContextKey = "audit_before:"
+	auditIDsContextKey    = "audit_ids:"
+)
+
+// AuditSnapshot 读取实体的当前状态用于对比操作前后的变化，返回以实体ID为键的快照，不存在的实体不出现在结果中
+// 快照不应包含密码、密文等敏感字段，也不应包含每次写入都会变化的 updated_at。
+type AuditSnapshot func(c *gin.Context, ids []string) (map[string]map[string]any, error)
+
+// QueryAuditStates 执行返回 id、state（JSONB）两列的查询，解码为 AuditSnapshot 的结果
+func QueryAuditStates(ctx context.Context, q sqlx.QueryerContext, query string, args ...any) (map[string]map[string]any, error) {
+	rows, err := QueryAll[struct {
+		ID    string `db:"id"`
+		State []byte `db:"state"`
+	}](ctx, q, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]map[string]any, len(rows))
+	for _, row := range rows {
+		var state map[string]any
+		if err := json.Unmarshal(row.State, &state); err != nil {
+			return nil, err
+		}
+		states[row.ID] = state
+	}
+	return states, nil
+}
+
+// AuditLog 审计日志，将用户、角色、权限的创建、修改、删除与分配等管理操作写入当前租户的 audit_log 表
+// 与 SecurityEvents 不同，审计日志保存在数据库中，供合规导出与追溯使用，不依赖 SIEM 配置。
+// 写入在操作完成后同步执行，写入失败只记录日志，不影响已经完成的操作。
+type AuditLog struct {
+	pool   *TenantPool
+	tables *TableNames
+	logger *zap.Logger
+	// 按实体类型注册的快照，见 RegisterSnapshot
+	snapshots map[string]AuditSnapshot
+}
+
+func NewAuditLog(pool *TenantPool, tables *TableNames, logger *zap.Logger) *AuditLog {
+	return &AuditLog{pool: pool, tables: tables, logger: logger, snapshots: map[string]AuditSnapshot{}}
+}
+
+// RegisterSnapshot 注册实体的快照，注册后该实体的审计记录带有操作前后的变化，须在处理请求前调用
+func (a *AuditLog) RegisterSnapshot(entity string, snapshot AuditSnapshot) {
+	a.snapshots[entity] = snapshot
+}
+
+// Record 记录当前用户对实体的一次操作，自动带上请求方法、路由、来源 IP 与请求ID
+func (a *AuditLog) Record(c *gin.Context, action, entity, entityID string, detail map[string]any) {
+	if _, err := a.RecordTx(c, a.pool.DB(c), action, entity, entityID, detail); err != nil {
+		RequestLogger(c, a.logger).Error("写入审计日志失败", zap.String("action", action), zap.String("entity", entity), zap.String("entity_id", entityID), zap.Error(err))
+	}
+}
+
+// RecordTx 与 Record 相同，在调用方的事务中写入并返回审计记录ID，写入失败时返回错误
+// 用于审计记录须与操作一起提交的场景，如撤销操作以自身的审计记录防止重复撤销。
+func (a *AuditLog) RecordTx(c *gin.Context, q sqlx.QueryerContext, action, entity, entityID string, detail map[string]any) (string, error) {
+	data := []byte("{}")
+	if detail != nil {
+		var err error
+		if data, err = json.Marshal(detail); err != nil {
+			return "", err
+		}
+	}
+	query := `INSERT INTO ` + a.tables.AuditLog + ` (actor_id, action, entity, entity_id, detail, ip, trace_id, method, path)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''))
+		RETURNING id`
+	var id string
+	err := sqlx.GetContext(c.Request.Context(), q, &id, query,
+		CurrentUserID(c), action, entity, entityID, data, c.ClientIP(), TraceIDFromContext(c), c.Request.Method, c.FullPath())
+	return id, err
+}
+
+// RecordJob 记录异步任务代替发起人完成的操作，操作人为 actorID，请求ID为发起任务的请求ID（job.TraceID）
+// 任务中没有请求上下文，不记录来源 IP、请求方法与路由；detail 中带上任务ID。
+func (a *AuditLog) RecordJob(ctx context.Context, q sqlx.ExecerContext, job *Job, actorID, action, entity, entityID string) error {
+	data, err := json.Marshal(map[string]any{"job_id": job.ID})
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO ` + a.tables.AuditLog + ` (actor_id, action, entity, entity_id, detail, trace_id)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''))`
+	_, err = q.ExecContext(ctx, query, actorID, action, entity, entityID, data, job.TraceID)
+	return err
+}
+
+// snapshot 读取实体快照，没有注册快照或读取失败时 ok 为 false，读取失败只记录日志
+func (a *AuditLog) snapshot(c *gin.Context, entity string, ids []string) (map[string]map[string]any, bool) {
+	snapshot, registered := a.snapshots[entity]
+	// 批量操作的汇总记录没有实体ID
+	ids = slices.DeleteFunc(slices.Clone(ids), func(id string) bool { return id == "" })
+	if !registered || len(ids) == 0 {
+		return nil, false
+	}
+	states, err := snapshot(c, ids)
+	if err != nil {
+		RequestLogger(c, a.logger).Error("读取审计快照失败", zap.String("entity", entity), zap.Strings("entity_ids", ids), zap.Error(err))
+		return nil, false
+	}
+	return states, true
+}
+
+// record 写入一条带有操作前后变化的审计记录，detail 为附加的信息
+// changes 为发生变化的字段（修改、分配），只有操作前的快照时为 before（删除），只有操作后的快照时为 after（创建）。
+func (a *AuditLog) record(c *gin.Context, action, entity, entityID string, before, after map[string]any, detail map[string]any) {
+	switch {
+	case before != nil && after != nil:
+		detail["changes"] = AuditChanges(before, after)
+	case before != nil:
+		detail["before"] = before
+	case after != nil:
+		detail["after"] = after
+	}
+	a.Record(c, action, entity, entityID, detail)
+}
+
+// AuditBefore 返回在仓储操作前读取实体快照的管道步骤，由同一请求中的 RecordAudit 与操作后的快照对比
+// 用于修改、删除、分配等作用于已有实体的接口，放在参数校验之后、仓储操作之前。
+func AuditBefore[T any](c *gin.Context, audit *AuditLog, entity, entityID string) func(*T) mo.Result[*T] {
+	return AuditBeforeEach[T](c, audit, entity, func(*T) []string { return []string{entityID} })
+}
+
+// AuditBeforeEach 与 AuditBefore 相同，用于批量操作，实体ID从请求中取得，并记录下来供 RecordAuditEach 使用
+func AuditBeforeEach[T any](c *gin.Context, audit *AuditLog, entity string, ids func(*T) []string) func(*T) mo.Result[*T] {
+	return func(req *T) mo.Result[*T] {
+		entityIDs := ids(req)
+		c.Set(auditIDsContextKey+entity, entityIDs)
+		if states, ok := audit.snapshot(c, entity, entityIDs); ok {
+			c.Set(auditBeforeContextKey+entity, states)
+		}
+		return mo.Ok(req)
+	}
+}
+
+// auditBefore 返回同一请求中 AuditBefore 读取的快照
+func auditBefore(c *gin.Context, entity string) (map[string]map[string]any, bool) {
+	value, ok := c.Get(auditBeforeContextKey + entity)
+	if !ok {
+		return nil, false
+	}
+	states, ok := value.(map[string]map[string]any)
+	return states, ok
+}
+
+// RecordAudit 返回在结果成功后写入审计日志的管道步骤，结果值记录在 detail.result 中
+// 用于角色变更、权限变更等需要审计的接口，放在仓储操作（事务已提交）之后。
+// 实体注册了快照时，detail 中还记录操作前后的变化：changes 为发生变化的字段（修改、分配），
+// 只有操作前的快照时为 before（删除），只有操作后的快照时为 after（创建）。
+func RecordAudit[T any](c *gin.Context, audit *AuditLog, action, entity, entityID string) func(T) T {
+	return func(v T) T {
+		before, _ := auditBefore(c, entity)
+		after, _ := audit.snapshot(c, entity, []string{entityID})
+		audit.record(c, action, entity, entityID, before[entityID], after[entityID], map[string]any{"result": v})
+		return v
+	}
+}
+
+// RecordAuditEach 返回在操作成功后为每个实体写入一条审计日志的管道步骤，用于创建与批量操作
+// ids 从结果中取得实体ID（创建、批量创建、导入）；为 nil 时使用 AuditBeforeEach 记录的实体ID（批量删除），
+// 此时操作前不存在的实体没有被操作，不写入审计日志。
+func RecordAuditEach[T any](c *gin.Context, audit *AuditLog, action, entity string, ids func(T) []string) func(T) T {
+	return func(v T) T {
+		before, hasBefore := auditBefore(c, entity)
+		var entityIDs []string
+		if ids != nil {
+			entityIDs = ids(v)
+		} else if value, ok := c.Get(auditIDsContextKey + entity); ok {
+			entityIDs, _ = value.([]string)
+		}
+		after, _ := audit.snapshot(c, entity, entityIDs)
+		for _, id := range entityIDs {
+			if ids == nil && hasBefore && before[id] == nil {
+				continue
+			}
+			audit.record(c, action, entity, id, before[id], after[id], map[string]any{})
+		}
+		return v
+	}
+}
+
+// AuditChanges 对比操作前后的快照，返回发生变化的字段：{"字段": {"before": 旧值, "after": 新值}}
+// 只在一侧出现的字段，另一侧的值为 nil。
+func AuditChanges(before, after map[string]any) map[string]any {
+	changes := map[string]any{}
+	for key, old := range before {
+		if value, ok := after[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = map[string]any{"before": old, "after": after[key]}
+		}
+	}
+	for key, value := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = map[string]any{"before": nil, "after": value}
+		}
+	}
+	return changes
+}
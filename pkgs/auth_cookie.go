@@ -0,0 +1,108 @@
+package pkgs
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Cookie 鉴权使用的 cookie 与请求头
+const (
+	// 访问令牌，HttpOnly，所有接口都会携带
+	AccessTokenCookie = "access_token"
+	// 刷新令牌，HttpOnly，只在刷新令牌、退出登录接口携带
+	RefreshTokenCookie = "refresh_token"
+	// CSRF 令牌，前端脚本可读，修改类请求通过 CSRFHeader 回传
+	CSRFTokenCookie = "csrf_token"
+	CSRFHeader      = "X-CSRF-Token"
+)
+
+// 刷新令牌 cookie 的路径，覆盖 /v1/auth/refresh-token 与 /v1/auth/logout
+const refreshTokenCookiePath = "/v1/auth"
+
+// SetAuthCookies 把登录、刷新得到的令牌写入 cookie，并下发新的 CSRF 令牌；未开启 Cookie 鉴权时不做任何事
+// cookie 的有效期取令牌自身的过期时间，刷新令牌的有效期可能因客户端配置而不同。
+func SetAuthCookies(c *gin.Context, config *Config, accessToken, refreshToken string) error {
+	if !config.JWT.Cookie.Enabled {
+		return nil
+	}
+	csrf := make([]byte, 32)
+	if _, err := rand.Read(csrf); err != nil {
+		return err
+	}
+	cookie := config.JWT.Cookie
+	http.SetCookie(c.Writer, authCookie(cookie, AccessTokenCookie, accessToken, "/", tokenMaxAge(accessToken), true))
+	http.SetCookie(c.Writer, authCookie(cookie, RefreshTokenCookie, refreshToken, refreshTokenCookiePath, tokenMaxAge(refreshToken), true))
+	http.SetCookie(c.Writer, authCookie(cookie, CSRFTokenCookie, base64.RawURLEncoding.EncodeToString(csrf), "/", tokenMaxAge(refreshToken), false))
+	return nil
+}
+
+// ClearAuthCookies 退出登录时删除令牌与 CSRF 令牌 cookie
+func ClearAuthCookies(c *gin.Context, config *Config) {
+	if !config.JWT.Cookie.Enabled {
+		return
+	}
+	cookie := config.JWT.Cookie
+	http.SetCookie(c.Writer, authCookie(cookie, AccessTokenCookie, "", "/", -1, true))
+	http.SetCookie(c.Writer, authCookie(cookie, RefreshTokenCookie, "", refreshTokenCookiePath, -1, true))
+	http.SetCookie(c.Writer, authCookie(cookie, CSRFTokenCookie, "", "/", -1, false))
+}
+
+// CookieToken 读取 cookie 中的令牌，未开启 Cookie 鉴权或没有该 cookie 时返回空字符串
+func CookieToken(c *gin.Context, config *Config, name string) string {
+	if !config.JWT.Cookie.Enabled {
+		return ""
+	}
+	token, _ := c.Cookie(name)
+	return token
+}
+
+// ValidCSRF 双重提交校验：GET、HEAD、OPTIONS 请求直接通过，其他请求的 X-CSRF-Token 请求头必须与 csrf_token cookie 一致
+// 其他站点的页面可以让浏览器携带 cookie 发起请求，但读不到 cookie 的值，无法设置请求头。
+func ValidCSRF(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	cookie, _ := c.Cookie(CSRFTokenCookie)
+	header := c.GetHeader(CSRFHeader)
+	return cookie != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+func authCookie(config AuthCookieConfig, name, value, path string, maxAge int, httpOnly bool) *http.Cookie {
+	sameSite := http.SameSiteLaxMode
+	switch config.SameSite {
+	case SameSiteStrict:
+		sameSite = http.SameSiteStrictMode
+	case SameSiteNone:
+		sameSite = http.SameSiteNoneMode
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   config.Domain,
+		MaxAge:   maxAge,
+		Secure:   config.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sameSite,
+	}
+}
+
+// tokenMaxAge 返回令牌距离过期的秒数，令牌由本服务刚刚签发，不再校验签名
+func tokenMaxAge(token string) int {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return 0
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return 0
+	}
+	return max(int(time.Until(exp.Time).Seconds()), 1)
+}
@@ -2,16 +2,22 @@ package pkgs
 
 import (
 	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
 	"github.com/samber/mo"
 )
 
 // 绑定并返回路径参数。
 func BindUri[T any](c *gin.Context) mo.Result[*T] {
 	var req T
-	if err := c.ShouldBindUri(&req); err != nil {
-		return mo.Err[*T](NewApiError(http.StatusBadRequest, err.Error()))
+	if err := bindUri(c, &req); err != nil {
+		return mo.Err[*T](err)
 	}
 	return mo.Ok(&req)
 }
@@ -33,11 +39,20 @@ func BindJSON[T any](c *gin.Context) mo.Result[*T] {
 	return mo.Ok(&req)
 }
 
+// 绑定并返回 multipart/form-data 或 x-www-form-urlencoded 表单，文件字段使用 *multipart.FileHeader。
+func BindForm[T any](c *gin.Context) mo.Result[*T] {
+	var req T
+	if err := c.ShouldBindWith(&req, binding.FormMultipart); err != nil {
+		return mo.Err[*T](NewApiError(http.StatusBadRequest, err.Error()))
+	}
+	return mo.Ok(&req)
+}
+
 // 同时绑定路径参数和请求体的JSON数据。
 func BindUriAndJSON[T any](c *gin.Context) mo.Result[*T] {
 	var req T
-	if err := c.ShouldBindUri(&req); err != nil {
-		return mo.Err[*T](NewApiError(http.StatusBadRequest, err.Error()))
+	if err := bindUri(c, &req); err != nil {
+		return mo.Err[*T](err)
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -46,3 +61,108 @@ func BindUriAndJSON[T any](c *gin.Context) mo.Result[*T] {
 
 	return mo.Ok(&req)
 }
+
+// 同时绑定路径参数和查询参数。
+func BindUriAndQuery[T any](c *gin.Context) mo.Result[*T] {
+	var req T
+	if err := bindUri(c, &req); err != nil {
+		return mo.Err[*T](err)
+	}
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return mo.Err[*T](NewApiError(http.StatusBadRequest, err.Error()))
+	}
+
+	return mo.Ok(&req)
+}
+
+// 创建接口 return 查询参数的取值：返回完整实体
+const ReturnEntity = "entity"
+
+// WantEntity 创建接口是否要求返回完整实体（?return=entity），默认只返回 ID
+func WantEntity(c *gin.Context) bool {
+	return c.Query("return") == ReturnEntity
+}
+
+// uuidParam 声明为 UUID 的路径参数
+type uuidParam struct {
+	index int
+	name  string
+	label string
+}
+
+// 按请求类型缓存的 UUID 路径参数
+var uuidParams sync.Map
+
+// bindUri 绑定路径参数，先检查声明为 UUID（validate 或 binding 标签含 uuid）的路径参数，
+// 格式无效时统一返回 400，不再进入 binding 标签的校验、后续的校验与数据库查询
+func bindUri(c *gin.Context, req any) *ApiError {
+	params := make(map[string][]string, len(c.Params))
+	for _, param := range c.Params {
+		params[param.Key] = []string{param.Value}
+	}
+	if err := binding.MapFormWithTag(req, params, "uri"); err != nil {
+		return NewApiError(http.StatusBadRequest, err.Error())
+	}
+	if err := uuidViolations(req); err != nil {
+		return err
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return NewApiError(http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// uuidViolations 检查声明为 UUID 的路径参数
+func uuidViolations(req any) *ApiError {
+	value := reflect.ValueOf(req).Elem()
+	var violations []Violation
+	for _, param := range uuidParamsOf(value.Type()) {
+		if id := value.Field(param.index).String(); !isUUID(id) {
+			violations = append(violations, Violation{Field: param.name, Message: param.label + "必须是一个有效的UUID"})
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.Message
+	}
+	return &ApiError{Code: http.StatusBadRequest, Message: strings.Join(messages, "；"), Data: violations}
+}
+
+// uuidParamsOf 返回请求类型中声明为 UUID 的路径参数
+func uuidParamsOf(t reflect.Type) []uuidParam {
+	if cached, ok := uuidParams.Load(t); ok {
+		return cached.([]uuidParam)
+	}
+	var params []uuidParam
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup("uri")
+		if !ok || field.Type.Kind() != reflect.String {
+			continue
+		}
+		rules := strings.Split(field.Tag.Get("validate")+","+field.Tag.Get("binding"), ",")
+		if !slices.Contains(rules, "uuid") {
+			continue
+		}
+		label := field.Tag.Get("label")
+		if label == "" {
+			label = name
+		}
+		params = append(params, uuidParam{index: i, name: name, label: label})
+	}
+	uuidParams.Store(t, params)
+	return params
+}
+
+// isUUID 是否为 8-4-4-4-12 格式的 UUID
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
@@ -0,0 +1,20 @@
+package pkgs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewCommandContext 为命令行工具构造请求上下文，使其可以直接调用以 *gin.Context 为参数的仓储方法
+// tenant 非空时按该租户选择数据库连接；上下文中没有当前用户，也不经过任何中间件。
+func NewCommandContext(ctx context.Context, tenant string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if tenant != "" {
+		c.Set(TenantContextKey, tenant)
+	}
+	return c
+}
@@ -2,34 +2,99 @@ package pkgs
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Log      LogConfig      `mapstructure:"log"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	App      AppConfig      `mapstructure:"app"`
+	Server          ServerConfig          `mapstructure:"server"`
+	Database        DatabaseConfig        `mapstructure:"database"`
+	Log             LogConfig             `mapstructure:"log"`
+	JWT             JWTConfig             `mapstructure:"jwt"`
+	App             AppConfig             `mapstructure:"app"`
+	Tenant          TenantConfig          `mapstructure:"tenant"`
+	Encryption      EncryptionConfig      `mapstructure:"encryption"`
+	PasswordHash    PasswordHashConfig    `mapstructure:"password_hash"`
+	PasswordPolicy  PasswordPolicy        `mapstructure:"password_policy"`
+	IDObfuscation   IDObfuscationConfig   `mapstructure:"id_obfuscation"`
+	PublicAPI       PublicAPIConfig       `mapstructure:"public_api"`
+	Time            TimeConfig            `mapstructure:"time"`
+	Locale          LocaleConfig          `mapstructure:"locale"`
+	Response        ResponseConfig        `mapstructure:"response"`
+	Redis           RedisConfig           `mapstructure:"redis"`
+	PermissionCache PermissionCacheConfig `mapstructure:"permission_cache"`
+	Modules         ModulesConfig         `mapstructure:"modules"`
+	SIEM            SIEMConfig            `mapstructure:"siem"`
+	Notification    NotificationConfig    `mapstructure:"notification"`
+	DevFactory      DevFactoryConfig      `mapstructure:"dev_factory"`
+	DevToken        DevTokenConfig        `mapstructure:"dev_token"`
+	QueryPlan       QueryPlanConfig       `mapstructure:"query_plan"`
+	Storage         StorageConfig         `mapstructure:"storage"`
+	Archive         ArchiveConfig         `mapstructure:"archive"`
+	Audit           AuditConfig           `mapstructure:"audit"`
+	Sandbox         SandboxConfig         `mapstructure:"sandbox"`
+	UserSearch      UserSearchConfig      `mapstructure:"user_search"`
+	UserImport      UserImportConfig      `mapstructure:"user_import"`
+	Settings        SettingsConfig        `mapstructure:"settings"`
 }
 
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port      int    `mapstructure:"port"`
+	Mode      string `mapstructure:"mode"`
+	RouteLint string `mapstructure:"route_lint"`
+	// 信任的反向代理（IP 或 CIDR），只有来自这些地址的请求才按 X-Forwarded-For 确定客户端 IP；为空时使用连接的来源地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
-	Host            string        `mapstructure:"host"`
-	Port            int           `mapstructure:"port"`
-	Username        string        `mapstructure:"username"`
-	Password        string        `mapstructure:"password"`
-	DBName          string        `mapstructure:"dbname"`
-	SSLMode         string        `mapstructure:"sslmode"`
-	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
-	MaxOpenConns    int           `mapstructure:"max_open_conns"`
-	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	Host            string          `mapstructure:"host"`
+	Port            int             `mapstructure:"port"`
+	Username        string          `mapstructure:"username"`
+	Password        string          `mapstructure:"password"`
+	DBName          string          `mapstructure:"dbname"`
+	SSLMode         string          `mapstructure:"sslmode"`
+	MaxIdleConns    int             `mapstructure:"max_idle_conns"`
+	MaxOpenConns    int             `mapstructure:"max_open_conns"`
+	ConnMaxLifetime time.Duration   `mapstructure:"conn_max_lifetime"`
+	Schema          string          `mapstructure:"schema"`
+	TablePrefix     string          `mapstructure:"table_prefix"`
+	IDGeneration    string          `mapstructure:"id_generation"`
+	Batch           BatchPoolConfig `mapstructure:"batch"`
+	// 报表查询（统计、取值分布、矩阵与导出）使用的分析库，见 ReportingDB
+	Reporting ReportingConfig `mapstructure:"reporting"`
+	// 启动时核对实体与数据表结构，取值见 SchemaCheckOff/Warn/Strict
+	SchemaCheck string `mapstructure:"schema_check"`
+	// 启动时执行内嵌的数据库迁移；关闭后迁移由 server -migrate 或 admin run-migrations 在发布前单独执行，
+	// 启动时只由 Preflight 检查迁移版本，版本不一致时拒绝启动
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+}
+
+// BatchPoolConfig 批处理连接池配置，max_open_conns 为 0 时与交互请求共用连接池
+type BatchPoolConfig struct {
+	MaxIdleConns int `mapstructure:"max_idle_conns"`
+	MaxOpenConns int `mapstructure:"max_open_conns"`
+}
+
+// ReportingConfig 分析库（报表副本）连接配置，host 为空时报表查询使用主库
+// port、username、password、dbname、sslmode 未配置时沿用主库的配置；schema 与 table_prefix 独立配置，
+// 分析库中的表可以位于与主库不同的 schema 或使用不同的前缀（例如逻辑复制到 analytics schema）。
+type ReportingConfig struct {
+	Host         string `mapstructure:"host"`
+	Port         int    `mapstructure:"port"`
+	Username     string `mapstructure:"username"`
+	Password     string `mapstructure:"password"`
+	DBName       string `mapstructure:"dbname"`
+	SSLMode      string `mapstructure:"sslmode"`
+	MaxIdleConns int    `mapstructure:"max_idle_conns"`
+	MaxOpenConns int    `mapstructure:"max_open_conns"`
+	Schema       string `mapstructure:"schema"`
+	TablePrefix  string `mapstructure:"table_prefix"`
 }
 
 type LogConfig struct {
@@ -42,12 +107,380 @@ type JWTConfig struct {
 	Secret             string        `mapstructure:"secret"`
 	AccessTokenExpire  time.Duration `mapstructure:"access_token_expire"`
 	RefreshTokenExpire time.Duration `mapstructure:"refresh_token_expire"`
+	// 登录是否必须携带已登记的 client_id；关闭时未携带的登录使用上面的默认有效期
+	RequireClient bool `mapstructure:"require_client"`
+	// 浏览器端通过 HttpOnly cookie 携带令牌
+	Cookie AuthCookieConfig `mapstructure:"cookie"`
+}
+
+// Cookie 的 SameSite 取值，对应配置 jwt.cookie.same_site
+const (
+	SameSiteLax    = "lax"
+	SameSiteStrict = "strict"
+	SameSiteNone   = "none"
+)
+
+// AuthCookieConfig Cookie 鉴权配置
+// 开启后登录、刷新令牌时同时把令牌写入 HttpOnly cookie，并下发双重提交用的 CSRF 令牌，见 pkgs/auth_cookie.go。
+type AuthCookieConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 为空表示只对当前域名生效
+	Domain string `mapstructure:"domain"`
+	// 只通过 HTTPS 发送，只有本地 HTTP 调试时关闭
+	Secure   bool   `mapstructure:"secure"`
+	SameSite string `mapstructure:"same_site"`
+}
+
+func (a AuthCookieConfig) Validate() error {
+	switch a.SameSite {
+	case SameSiteLax, SameSiteStrict:
+	case SameSiteNone:
+		// 浏览器拒绝不带 Secure 的 SameSite=None cookie
+		if !a.Secure {
+			return fmt.Errorf("jwt.cookie.secure must be true when jwt.cookie.same_site is %q", SameSiteNone)
+		}
+	default:
+		return fmt.Errorf("invalid jwt.cookie.same_site: %q", a.SameSite)
+	}
+	return nil
 }
 
 type AppConfig struct {
 	Name string `mapstructure:"name"`
 }
 
+type TenantConfig struct {
+	Mode         string `mapstructure:"mode"`
+	Header       string `mapstructure:"header"`
+	SchemaPrefix string `mapstructure:"schema_prefix"`
+	MaxIdleConns int    `mapstructure:"max_idle_conns"`
+	MaxOpenConns int    `mapstructure:"max_open_conns"`
+	// 每个租户批处理连接池的连接数，batch_max_open_conns 为 0 时与该租户的交互连接池共用
+	BatchMaxIdleConns int `mapstructure:"batch_max_idle_conns"`
+	BatchMaxOpenConns int `mapstructure:"batch_max_open_conns"`
+}
+
+type EncryptionConfig struct {
+	Key          string `mapstructure:"key"`
+	HashKey      string `mapstructure:"hash_key"`
+	PseudonymKey string `mapstructure:"pseudonym_key"`
+}
+
+// 影子列 HMAC 密钥的最小长度
+const minHashKeyLength = 32
+
+// Validate 校验密钥组合
+// 启用加密后手机号、邮箱只能通过影子列查询，影子列的输入空间很小，HMAC 密钥为空或过短时可以被穷举还原
+func (e EncryptionConfig) Validate() error {
+	if e.Key != "" {
+		if e.HashKey == "" {
+			return fmt.Errorf("encryption.hash_key is required when encryption.key is set")
+		}
+		if len(e.HashKey) < minHashKeyLength {
+			return fmt.Errorf("encryption.hash_key must be at least %d bytes, got %d", minHashKeyLength, len(e.HashKey))
+		}
+		if e.HashKey == e.Key {
+			return fmt.Errorf("encryption.hash_key must differ from encryption.key")
+		}
+	}
+	// 假名化密钥与影子列密钥相同时，导出数据集中的假名可以直接匹配数据库中的影子列
+	if e.PseudonymKey != "" && e.PseudonymKey == e.HashKey {
+		return fmt.Errorf("encryption.pseudonym_key must differ from encryption.hash_key")
+	}
+	return nil
+}
+
+// PasswordHashConfig 用户密码哈希配置
+// 校验时按哈希值的格式识别算法，更换算法或调整参数后，已有密码仍可登录，并在下次登录成功时按新配置重新哈希。
+type PasswordHashConfig struct {
+	// 新密码使用的算法，取值见 PasswordAlgorithmBcrypt/Argon2id
+	Algorithm  string `mapstructure:"algorithm"`
+	BcryptCost int    `mapstructure:"bcrypt_cost"`
+	// argon2id 的迭代次数、内存（KiB）与并行度
+	Argon2Time    uint32 `mapstructure:"argon2_time"`
+	Argon2Memory  uint32 `mapstructure:"argon2_memory"`
+	Argon2Threads uint8  `mapstructure:"argon2_threads"`
+	// 允许校验的旧系统密码格式，取值见 PasswordLegacyPlain/MD5，迁移完成后应清空
+	LegacyFormats []string `mapstructure:"legacy_formats"`
+}
+
+// Validate 校验算法与参数范围
+func (p PasswordHashConfig) Validate() error {
+	switch p.Algorithm {
+	case PasswordAlgorithmBcrypt:
+		if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("password_hash.bcrypt_cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, p.BcryptCost)
+		}
+	case PasswordAlgorithmArgon2id:
+		if p.Argon2Time == 0 || p.Argon2Threads == 0 || p.Argon2Memory < 8*uint32(p.Argon2Threads) {
+			return fmt.Errorf("password_hash.argon2_time and argon2_threads must be positive, argon2_memory must be at least 8 KiB per thread")
+		}
+	default:
+		return fmt.Errorf("invalid password_hash.algorithm: %q", p.Algorithm)
+	}
+	for _, format := range p.LegacyFormats {
+		if format != PasswordLegacyPlain && format != PasswordLegacyMD5 {
+			return fmt.Errorf("invalid password_hash.legacy_formats entry: %q", format)
+		}
+	}
+	return nil
+}
+
+// IDObfuscationConfig 对外ID混淆，启用后接口只返回和接受混淆后的ID
+// AllowRaw 为 true 时请求中仍可使用原始 UUID，便于调用方逐步迁移
+type IDObfuscationConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Key      string `mapstructure:"key"`
+	AllowRaw bool   `mapstructure:"allow_raw"`
+}
+
+type TimeConfig struct {
+	Timezone          string            `mapstructure:"timezone"`
+	Format            string            `mapstructure:"format"`
+	LanguageTimezones map[string]string `mapstructure:"language_timezones"`
+}
+
+// LocaleConfig 接口消息的语言，Default 为请求未指定支持的语言（Accept-Language）时使用的语言
+type LocaleConfig struct {
+	Default string `mapstructure:"default"`
+}
+
+type PublicAPIConfig struct {
+	Header   string                   `mapstructure:"header"`
+	CacheTTL time.Duration            `mapstructure:"cache_ttl"`
+	Tiers    map[string]RateLimitTier `mapstructure:"tiers"`
+}
+
+// RateLimitTier 公开接口的限流等级：每个 API 密钥在 Window 时间窗口内最多请求 Limit 次
+type RateLimitTier struct {
+	Limit  int           `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+	// 影子模式：超出限制的请求不拒绝，只返回限流响应头并记录，用于启用前校准限制
+	Shadow bool `mapstructure:"shadow"`
+}
+
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+// PermissionCacheConfig 用户接口权限缓存，需要配置 redis.addr 才会启用
+// Redis 连续失败 FailureThreshold 次后熔断 Cooldown 时长，期间直接查询数据库
+type PermissionCacheConfig struct {
+	TTL              time.Duration `mapstructure:"ttl"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	Cooldown         time.Duration `mapstructure:"cooldown"`
+}
+
+// SIEMConfig 安全事件推送配置，sink 为空时不推送
+type SIEMConfig struct {
+	Sink           string           `mapstructure:"sink"`
+	BufferSize     int              `mapstructure:"buffer_size"`
+	EnqueueTimeout time.Duration    `mapstructure:"enqueue_timeout"`
+	BatchSize      int              `mapstructure:"batch_size"`
+	FlushInterval  time.Duration    `mapstructure:"flush_interval"`
+	MaxRetries     int              `mapstructure:"max_retries"`
+	Timeout        time.Duration    `mapstructure:"timeout"`
+	HTTP           SIEMHTTPConfig   `mapstructure:"http"`
+	Syslog         SIEMSyslogConfig `mapstructure:"syslog"`
+	Kafka          SIEMKafkaConfig  `mapstructure:"kafka"`
+}
+
+// http 推送的请求体格式
+const (
+	SIEMFormatJSON      = "json"
+	SIEMFormatSplunkHEC = "splunk_hec"
+)
+
+type SIEMHTTPConfig struct {
+	URL     string            `mapstructure:"url"`
+	Format  string            `mapstructure:"format"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// SIEMSyslogConfig network 与 address 为空时写入本机 syslog
+type SIEMSyslogConfig struct {
+	Network string `mapstructure:"network"`
+	Address string `mapstructure:"address"`
+	Tag     string `mapstructure:"tag"`
+}
+
+// SIEMKafkaConfig 通过 Kafka REST Proxy 写入主题
+type SIEMKafkaConfig struct {
+	RESTURL string `mapstructure:"rest_url"`
+	Topic   string `mapstructure:"topic"`
+}
+
+// NotificationConfig 通知投递配置，webhook_url 为空时只记录日志
+type NotificationConfig struct {
+	WebhookURL string            `mapstructure:"webhook_url"`
+	Headers    map[string]string `mapstructure:"headers"`
+	Timeout    time.Duration     `mapstructure:"timeout"`
+}
+
+// StorageConfig 对象存储，driver 为空时不启用（依赖存储的功能如模板归档导出随之关闭）
+type StorageConfig struct {
+	Driver string `mapstructure:"driver"`
+	// 所有对象 key 的前缀，多个环境共用一个 bucket 时用于区分
+	Prefix  string            `mapstructure:"prefix"`
+	Timeout time.Duration     `mapstructure:"timeout"`
+	File    StorageFileConfig `mapstructure:"file"`
+	S3      StorageS3Config   `mapstructure:"s3"`
+}
+
+type StorageFileConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// StorageS3Config S3 兼容存储，path_style 为 true 时使用 endpoint/bucket/key 形式的地址（MinIO 等）
+type StorageS3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	PathStyle bool   `mapstructure:"path_style"`
+}
+
+// ArchiveConfig 模板归档导出：归档超过 After 的模板写入对象存储后从数据库删除，需要配置 storage
+type ArchiveConfig struct {
+	After time.Duration `mapstructure:"after"`
+	// 导出任务的执行时间（cron 表达式）
+	Schedule  string `mapstructure:"schedule"`
+	BatchSize int    `mapstructure:"batch_size"`
+	// 最近 RecentUse 内使用过的模板视为仍被引用，不能归档；为 0 时不检查使用记录
+	RecentUse time.Duration `mapstructure:"recent_use"`
+}
+
+// AuditConfig 审计日志导出（GET /v1/audit/export）
+type AuditConfig struct {
+	// 匹配行数不超过该值时直接返回 CSV，超过时转为异步任务写入对象存储（需要配置 storage）
+	ExportSyncRows int `mapstructure:"export_sync_rows"`
+}
+
+// UserSearchConfig 用户列表的物化视图（iacc_user_search），开启后 /v1/user/list 查询定时刷新的快照，响应中的 refreshed_at 为快照时间
+type UserSearchConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 刷新物化视图的时间（cron 表达式）
+	Schedule string `mapstructure:"schedule"`
+}
+
+// UserImportConfig 用户导入（POST /v1/user/import）
+type UserImportConfig struct {
+	// 通过校验的行数不超过该值时在请求内导入，超过时转为异步任务，通过 GET /v1/user/import/{id} 查询导入报告
+	SyncRows int `mapstructure:"sync_rows"`
+}
+
+// SettingsConfig 运行时设置（settings 表），其他实例通过 /v1/admin/settings 修改后，本实例在下次重新加载时生效
+type SettingsConfig struct {
+	// 重新加载设置的时间（cron 表达式）
+	ReloadSchedule string `mapstructure:"reload_schedule"`
+}
+
+var identPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// 启动时路由检查模式，对应配置 server.route_lint
+const (
+	RouteLintOff    = "off"
+	RouteLintWarn   = "warn"
+	RouteLintStrict = "strict"
+)
+
+// DevFactoryConfig 测试数据工厂（/v1/dev/factory），只用于压测与演示环境
+// 开启后才注册路由；server.mode 为 release 时不允许开启。
+type DevFactoryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 单次请求每类数据最多创建的数量
+	MaxCount int `mapstructure:"max_count"`
+}
+
+// DevTokenConfig 测试令牌签发（/v1/dev/token），压测脚本不经过登录直接获取令牌
+// 开启后才注册路由；server.mode 为 release 时不允许开启。
+type DevTokenConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 签发的访问令牌最长有效期
+	MaxExpire time.Duration `mapstructure:"max_expire"`
+}
+
+// QueryPlanConfig 列表查询的执行计划捕获，用于排查慢的筛选组合
+// 开启后，请求头 X-Debug-Query-Plan 为 true 且拥有 debug:query_plan 编码权限的 GET 请求，
+// 列表查询会以 EXPLAIN (ANALYZE, BUFFERS) 再执行一次，执行计划附在响应的 meta.query_plans 中；
+// EXPLAIN ANALYZE 会真实执行查询，server.mode 为 release 时不允许开启。
+type QueryPlanConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 单条 EXPLAIN ANALYZE 的超时时间
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// ResponseConfig 响应信封中的业务码约定，默认与 HTTP 状态码一致
+type ResponseConfig struct {
+	// 成功响应的业务码
+	SuccessCode int `mapstructure:"success_code"`
+	// 错误业务码映射，键为内部业务码（HTTP 状态码或 40301 等细分业务码），值为对外返回的业务码
+	ErrorCodes map[int]int `mapstructure:"error_codes"`
+	// 不在 error_codes 中的错误对外返回的业务码，为 0 时返回内部业务码
+	DefaultErrorCode int `mapstructure:"default_error_code"`
+}
+
+// SandboxConfig 沙箱模式：POST /v1/sandbox/token 签发只能访问指定接口的短期令牌，
+// 使用该令牌的请求在回滚的事务中执行，集成方可以对真实数据结构试用接口而不修改数据。开启后才注册路由。
+type SandboxConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// 沙箱令牌最长有效期
+	MaxExpire time.Duration `mapstructure:"max_expire"`
+	// 单个沙箱请求的超时时间，沙箱请求独占一个数据库连接，超时后中止并回滚
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// 可关闭的业务模块名称，与迁移文件名中的模块前缀一致（如 20251013153018_template.up.sql）
+const (
+	ModuleTemplate   = "template"
+	ModulePermission = "permission"
+)
+
+// ModulesConfig 业务模块开关，未配置时默认开启
+// 关闭的模块不注册路由（请求返回 404），其数据库迁移也会跳过。
+type ModulesConfig struct {
+	Template   ModuleConfig `mapstructure:"template"`
+	Permission ModuleConfig `mapstructure:"permission"`
+}
+
+type ModuleConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// Disabled 返回已关闭的模块名称
+func (m ModulesConfig) Disabled() []string {
+	var disabled []string
+	if !m.Template.Enabled {
+		disabled = append(disabled, ModuleTemplate)
+	}
+	if !m.Permission.Enabled {
+		disabled = append(disabled, ModulePermission)
+	}
+	return disabled
+}
+
+// 模块的路由前缀
+var modulePathPrefixes = map[string][]string{
+	ModuleTemplate:   {"/v1/template", "/v1/sync/template", "/public/v1/template"},
+	ModulePermission: {"/v1/permission"},
+}
+
+// DisabledPath 判断请求路径是否属于已关闭的模块
+func (m ModulesConfig) DisabledPath(path string) bool {
+	for _, module := range m.Disabled() {
+		for _, prefix := range modulePathPrefixes[module] {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func NewConfig() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -71,10 +504,237 @@ func NewConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// 旧配置文件中没有 auto_migrate 时保持启动时迁移
+	viper.SetDefault("database.auto_migrate", true)
+	// 模块默认开启，旧配置文件中没有 modules 配置时保持原有行为
+	viper.SetDefault("modules.template.enabled", true)
+	viper.SetDefault("modules.permission.enabled", true)
+
+	viper.SetDefault("siem.buffer_size", 10000)
+	viper.SetDefault("siem.batch_size", 100)
+	viper.SetDefault("siem.flush_interval", 5*time.Second)
+	viper.SetDefault("siem.max_retries", 3)
+	viper.SetDefault("siem.timeout", 5*time.Second)
+	viper.SetDefault("siem.http.format", SIEMFormatJSON)
+	viper.SetDefault("siem.syslog.tag", "go-pg-demo")
+	viper.SetDefault("notification.timeout", 10*time.Second)
+	viper.SetDefault("dev_factory.max_count", 1000)
+	viper.SetDefault("dev_token.max_expire", 24*time.Hour)
+	viper.SetDefault("query_plan.timeout", 5*time.Second)
+	viper.SetDefault("storage.timeout", 30*time.Second)
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("archive.after", 90*24*time.Hour)
+	viper.SetDefault("archive.schedule", "30 3 * * *")
+	viper.SetDefault("archive.batch_size", 100)
+	viper.SetDefault("archive.recent_use", 30*24*time.Hour)
+	viper.SetDefault("audit.export_sync_rows", 10000)
+	viper.SetDefault("sandbox.max_expire", 15*time.Minute)
+	viper.SetDefault("sandbox.timeout", 10*time.Second)
+	viper.SetDefault("user_search.schedule", "*/5 * * * *")
+	viper.SetDefault("user_import.sync_rows", 200)
+	viper.SetDefault("settings.reload_schedule", "* * * * *")
+	viper.SetDefault("password_policy.min_length", 6)
+	viper.SetDefault("response.success_code", 200)
+	viper.SetDefault("locale.default", LocaleZh)
+	viper.SetDefault("password_hash.algorithm", PasswordAlgorithmBcrypt)
+	viper.SetDefault("password_hash.bcrypt_cost", bcrypt.DefaultCost)
+	viper.SetDefault("password_hash.argon2_time", 3)
+	viper.SetDefault("password_hash.argon2_memory", 64*1024)
+	viper.SetDefault("password_hash.argon2_threads", 4)
+	// 迁移 20251129120000 为改用哈希前保存的明文密码加上了 $plain$ 前缀，默认允许这些用户登录并重新哈希
+	viper.SetDefault("password_hash.legacy_formats", []string{PasswordLegacyPlain})
+	viper.SetDefault("jwt.cookie.secure", true)
+	viper.SetDefault("jwt.cookie.same_site", SameSiteLax)
+
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// 启动时的路由检查，未配置时只记录告警
+	switch config.Server.RouteLint {
+	case "":
+		config.Server.RouteLint = RouteLintWarn
+	case RouteLintOff, RouteLintWarn, RouteLintStrict:
+	default:
+		return nil, fmt.Errorf("invalid server.route_lint: %q", config.Server.RouteLint)
+	}
+
+	// 启动时的数据库结构检查，未配置时只记录告警
+	switch config.Database.SchemaCheck {
+	case "":
+		config.Database.SchemaCheck = SchemaCheckWarn
+	case SchemaCheckOff, SchemaCheckWarn, SchemaCheckStrict:
+	default:
+		return nil, fmt.Errorf("invalid database.schema_check: %q", config.Database.SchemaCheck)
+	}
+
+	// 表名前缀与 schema 会拼接进 SQL 及迁移文件，只允许小写字母、数字和下划线
+	if config.Database.TablePrefix != "" && !identPattern.MatchString(config.Database.TablePrefix) {
+		return nil, fmt.Errorf("invalid database.table_prefix: %q", config.Database.TablePrefix)
+	}
+	if config.Database.Schema != "" && !identPattern.MatchString(config.Database.Schema) {
+		return nil, fmt.Errorf("invalid database.schema: %q", config.Database.Schema)
+	}
+	if reporting := config.Database.Reporting; reporting.Host != "" {
+		if reporting.TablePrefix != "" && !identPattern.MatchString(reporting.TablePrefix) {
+			return nil, fmt.Errorf("invalid database.reporting.table_prefix: %q", reporting.TablePrefix)
+		}
+		if reporting.Schema != "" && !identPattern.MatchString(reporting.Schema) {
+			return nil, fmt.Errorf("invalid database.reporting.schema: %q", reporting.Schema)
+		}
+		if reporting.MaxOpenConns <= 0 {
+			return nil, fmt.Errorf("invalid database.reporting.max_open_conns: %d", reporting.MaxOpenConns)
+		}
+	}
+
+	// 主键生成方式，未配置时由数据库生成
+	switch config.Database.IDGeneration {
+	case "":
+		config.Database.IDGeneration = IDGenerationDatabase
+	case IDGenerationDatabase, IDGenerationApplication:
+	default:
+		return nil, fmt.Errorf("invalid database.id_generation: %q", config.Database.IDGeneration)
+	}
+
+	// schema-per-tenant 模式依赖 search_path 定位租户表，表名不能再带 schema 限定
+	if config.Tenant.Mode != "" {
+		if config.Tenant.Mode != TenantModeSchema {
+			return nil, fmt.Errorf("invalid tenant.mode: %q", config.Tenant.Mode)
+		}
+		if config.Database.Schema != "" {
+			return nil, fmt.Errorf("database.schema must be empty when tenant.mode is %q", TenantModeSchema)
+		}
+		if !identPattern.MatchString(config.Tenant.SchemaPrefix) {
+			return nil, fmt.Errorf("invalid tenant.schema_prefix: %q", config.Tenant.SchemaPrefix)
+		}
+	}
+	if err := config.Encryption.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.PasswordHash.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.PasswordPolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid password_policy: %w", err)
+	}
+	// 未指定客户端登录时的令牌有效期，可通过 /v1/admin/settings 在线调整
+	if config.JWT.AccessTokenExpire <= 0 || config.JWT.RefreshTokenExpire <= 0 {
+		return nil, fmt.Errorf("invalid jwt: access_token_expire and refresh_token_expire must be positive")
+	}
+	if err := config.JWT.Cookie.Validate(); err != nil {
+		return nil, err
+	}
+
+	if config.IDObfuscation.Enabled && config.IDObfuscation.Key == "" {
+		return nil, fmt.Errorf("id_obfuscation.key is required when id_obfuscation.enabled is true")
+	}
+
+	if config.Tenant.Header == "" {
+		config.Tenant.Header = "X-Tenant-ID"
+	}
+
+	if config.PublicAPI.Header == "" {
+		config.PublicAPI.Header = "X-API-Key"
+	}
+	for name, tier := range config.PublicAPI.Tiers {
+		if tier.Limit <= 0 || tier.Window <= 0 {
+			return nil, fmt.Errorf("invalid public_api.tiers.%s: limit and window must be positive", name)
+		}
+	}
+
+	// 测试数据工厂会绕过业务接口批量写入数据，生产环境不允许开启
+	if config.DevFactory.Enabled {
+		if config.Server.Mode == gin.ReleaseMode {
+			return nil, fmt.Errorf("dev_factory.enabled must be false when server.mode is %q", gin.ReleaseMode)
+		}
+		if config.DevFactory.MaxCount <= 0 {
+			return nil, fmt.Errorf("invalid dev_factory.max_count: %d", config.DevFactory.MaxCount)
+		}
+	}
+
+	// 测试令牌可以冒充任意用户，生产环境不允许开启
+	if config.DevToken.Enabled {
+		if config.Server.Mode == gin.ReleaseMode {
+			return nil, fmt.Errorf("dev_token.enabled must be false when server.mode is %q", gin.ReleaseMode)
+		}
+		if config.DevToken.MaxExpire <= 0 {
+			return nil, fmt.Errorf("invalid dev_token.max_expire: %s", config.DevToken.MaxExpire)
+		}
+	}
+
+	// 执行计划会再执行一次查询并暴露 SQL，生产环境不允许开启
+	if config.QueryPlan.Enabled {
+		if config.Server.Mode == gin.ReleaseMode {
+			return nil, fmt.Errorf("query_plan.enabled must be false when server.mode is %q", gin.ReleaseMode)
+		}
+		if config.QueryPlan.Timeout <= 0 {
+			return nil, fmt.Errorf("invalid query_plan.timeout: %s", config.QueryPlan.Timeout)
+		}
+	}
+
+	switch config.SIEM.Sink {
+	case "", SIEMSinkSyslog:
+	case SIEMSinkHTTP:
+		if config.SIEM.HTTP.URL == "" {
+			return nil, fmt.Errorf("siem.http.url is required when siem.sink is %q", SIEMSinkHTTP)
+		}
+		if config.SIEM.HTTP.Format != SIEMFormatJSON && config.SIEM.HTTP.Format != SIEMFormatSplunkHEC {
+			return nil, fmt.Errorf("invalid siem.http.format: %q", config.SIEM.HTTP.Format)
+		}
+	case SIEMSinkKafka:
+		if config.SIEM.Kafka.RESTURL == "" || config.SIEM.Kafka.Topic == "" {
+			return nil, fmt.Errorf("siem.kafka.rest_url and siem.kafka.topic are required when siem.sink is %q", SIEMSinkKafka)
+		}
+	default:
+		return nil, fmt.Errorf("invalid siem.sink: %q", config.SIEM.Sink)
+	}
+	if config.SIEM.Sink != "" && (config.SIEM.BufferSize <= 0 || config.SIEM.BatchSize <= 0 || config.SIEM.FlushInterval <= 0 || config.SIEM.Timeout <= 0) {
+		return nil, fmt.Errorf("invalid siem: buffer_size, batch_size, flush_interval and timeout must be positive")
+	}
+
+	switch config.Storage.Driver {
+	case "":
+	case StorageDriverFile:
+		if config.Storage.File.Dir == "" {
+			return nil, fmt.Errorf("storage.file.dir is required when storage.driver is %q", StorageDriverFile)
+		}
+	case StorageDriverS3:
+		if config.Storage.S3.Bucket == "" || config.Storage.S3.Region == "" {
+			return nil, fmt.Errorf("storage.s3.bucket and storage.s3.region are required when storage.driver is %q", StorageDriverS3)
+		}
+		if endpoint, err := url.Parse(config.Storage.S3.Endpoint); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid storage.s3.endpoint: %q", config.Storage.S3.Endpoint)
+		}
+	default:
+		return nil, fmt.Errorf("invalid storage.driver: %q", config.Storage.Driver)
+	}
+	if config.Archive.After <= 0 || config.Archive.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid archive: after and batch_size must be positive")
+	}
+	if config.UserImport.SyncRows <= 0 {
+		return nil, fmt.Errorf("invalid user_import: sync_rows must be positive")
+	}
+	if config.Archive.RecentUse < 0 {
+		return nil, fmt.Errorf("invalid archive: recent_use must not be negative")
+	}
+	if config.Sandbox.Enabled && (config.Sandbox.MaxExpire <= 0 || config.Sandbox.Timeout <= 0) {
+		return nil, fmt.Errorf("invalid sandbox: max_expire and timeout must be positive")
+	}
+	// 错误不能映射为成功的业务码，否则客户端会把失败当成成功处理
+	for from, to := range config.Response.ErrorCodes {
+		if to == config.Response.SuccessCode {
+			return nil, fmt.Errorf("invalid response.error_codes.%d: must not equal success_code %d", from, to)
+		}
+	}
+	if config.Response.DefaultErrorCode != 0 && config.Response.DefaultErrorCode == config.Response.SuccessCode {
+		return nil, fmt.Errorf("invalid response.default_error_code: must not equal success_code %d", config.Response.SuccessCode)
+	}
+
 	return &config, nil
 }
+
+// ConfigSettings 返回 NewConfig 加载的全部配置项（含默认值），键为配置文件中的名称；导出前须经 RedactSettings 脱敏
+func ConfigSettings() map[string]any {
+	return viper.AllSettings()
+}
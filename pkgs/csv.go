@@ -0,0 +1,12 @@
+package pkgs
+
+import "strings"
+
+// CSVCell 以 =、+、-、@ 等开头的值在电子表格中会被当作公式执行，加上单引号前缀
+// 导出给人工在电子表格中打开的 CSV 文件，每个单元格都经过这里。
+func CSVCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,112 @@
+package pkgs
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 游标分页未传 limit 时的每页大小，与 Pagination 的默认值一致
+const defaultCursorLimit = 10
+
+// CursorPagination 列表接口的游标（keyset）分页参数，与 Pagination 一起嵌入查询请求结构体
+// 传了 cursor 或 limit 时按游标分页：按 (created_at, seq) 定位上一页最后一行，可以使用各表的 (created_at, seq) 索引，
+// 查询耗时与翻页深度无关；
+// 都不传时仍按 page/pageSize 偏移分页。游标分页固定按创建时间排序，不统计总数，
+// 响应中的 next_cursor 为空表示已经是最后一页。
+type CursorPagination struct {
+	Cursor string `form:"cursor" validate:"omitempty,max=128" label:"游标"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100" label:"每页大小"`
+}
+
+// Enabled 是否按游标分页
+func (p CursorPagination) Enabled() bool {
+	return p.Cursor != "" || p.Limit > 0
+}
+
+// Violations 校验游标格式，游标分页时排序字段只能是 created_at
+func (p CursorPagination) Violations(orderBy string) []Violation {
+	if !p.Enabled() {
+		return nil
+	}
+	var violations []Violation
+	if _, _, ok := decodeCursor(p.Cursor); !ok {
+		violations = append(violations, Violation{Field: "cursor", Message: "游标无效"})
+	}
+	if orderBy != "created_at" {
+		violations = append(violations, Violation{Field: "orderBy", Message: "游标分页只支持按创建时间排序"})
+	}
+	return violations
+}
+
+// Keyset 返回追加游标条件后的 WHERE 子句与 ORDER BY ... LIMIT 子句，参数写入 params
+// where 为 listWhere 生成的子句（可以为空），order 为已校验的排序方向；多查询一行用于判断是否还有下一页。
+func (p CursorPagination) Keyset(where, order string, params map[string]any) (string, string) {
+	params["limit"] = p.limit() + 1
+	orderClause := ` ORDER BY created_at ` + order + `, seq ` + order + ` LIMIT :limit`
+	at, seq, _ := decodeCursor(p.Cursor)
+	if seq == 0 {
+		return where, orderClause
+	}
+
+	params["cursor_at"] = at
+	params["cursor_seq"] = seq
+	comparison := "<"
+	if order == "ASC" {
+		comparison = ">"
+	}
+	condition := "(created_at, seq) " + comparison + " (:cursor_at, :cursor_seq)"
+	if where == "" {
+		return " WHERE " + condition, orderClause
+	}
+	return where + " AND " + condition, orderClause
+}
+
+// CursorPage 截掉游标分页多查询的一行并返回下一页游标，没有下一页或按偏移分页时游标为空
+// key 返回一行的创建时间与 seq，列表查询需要同时查出 seq 列。
+func CursorPage[T any](p CursorPagination, rows []T, key func(T) (time.Time, int64)) ([]T, string) {
+	if !p.Enabled() || len(rows) <= p.limit() {
+		return rows, ""
+	}
+	rows = rows[:p.limit()]
+	at, seq := key(rows[len(rows)-1])
+	return rows, encodeCursor(at, seq)
+}
+
+func (p CursorPagination) limit() int {
+	if p.Limit == 0 {
+		return defaultCursorLimit
+	}
+	return p.Limit
+}
+
+// encodeCursor 将 (创建时间, seq) 编码为不透明字符串，创建时间精确到微秒与数据库一致
+func encodeCursor(at time.Time, seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(at.UnixMicro(), 10) + ":" + strconv.FormatInt(seq, 10)))
+}
+
+// decodeCursor 解析游标，空字符串表示第一页（返回的 seq 为 0）
+func decodeCursor(cursor string) (time.Time, int64, bool) {
+	if cursor == "" {
+		return time.Time{}, 0, true
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	micro, rest, found := strings.Cut(string(raw), ":")
+	if !found {
+		return time.Time{}, 0, false
+	}
+	at, err := strconv.ParseInt(micro, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	// seq 由数据库的 IDENTITY 列生成，从 1 开始
+	seq, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil || seq < 1 {
+		return time.Time{}, 0, false
+	}
+	return time.UnixMicro(at), seq, true
+}
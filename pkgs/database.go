@@ -7,11 +7,12 @@ import (
 	_ "github.com/lib/pq"
 )
 
-// NewConnection creates a new database connection using the provided configuration
-func NewConnection(config *Config) (*sqlx.DB, error) {
-	// Build PostgreSQL connection string
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+// ConnString builds the PostgreSQL connection string from the configuration
+// The session time zone is pinned to UTC so timestamps are stored and read back in UTC
+// regardless of the database server's default; display time zones are applied by TimeFormatter.
+func ConnString(config *Config) string {
+	return fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s timezone=UTC",
 		config.Database.Host,
 		config.Database.Port,
 		config.Database.Username,
@@ -19,9 +20,12 @@ func NewConnection(config *Config) (*sqlx.DB, error) {
 		config.Database.DBName,
 		config.Database.SSLMode,
 	)
+}
 
+// NewConnection creates a new database connection using the provided configuration
+func NewConnection(config *Config) (*sqlx.DB, error) {
 	// Connect to the database
-	db, err := sqlx.Connect("postgres", connStr)
+	db, err := sqlx.Connect("postgres", ConnString(config))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -38,3 +42,28 @@ func NewConnection(config *Config) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// BatchDB is the connection pool for imports, exports, batch operations and background jobs.
+// It is limited separately from the interactive pool so a large batch cannot starve
+// login and list requests of connections.
+type BatchDB struct {
+	*sqlx.DB
+}
+
+// NewBatchConnection creates the batch connection pool from database.batch.
+// When database.batch.max_open_conns is 0 the interactive pool is shared.
+func NewBatchConnection(config *Config, db *sqlx.DB) (*BatchDB, func(), error) {
+	if config.Database.Batch.MaxOpenConns <= 0 {
+		return &BatchDB{DB: db}, func() {}, nil
+	}
+
+	batch, err := sqlx.Connect("postgres", ConnString(config))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect batch database: %w", err)
+	}
+	batch.SetMaxIdleConns(config.Database.Batch.MaxIdleConns)
+	batch.SetMaxOpenConns(config.Database.Batch.MaxOpenConns)
+	batch.SetConnMaxLifetime(config.Database.ConnMaxLifetime)
+
+	return &BatchDB{DB: batch}, func() { batch.Close() }, nil
+}
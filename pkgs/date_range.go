@@ -0,0 +1,129 @@
+package pkgs
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DateRange 列表接口按创建时间、更新时间筛选的查询参数，嵌入到 QueryListReq 中使用
+// 取值可以是 RFC 3339 时间（2025-01-02T15:04:05+08:00）或日期（2025-01-02，按本次请求的时区解析）；
+// From 包含该时刻，To 为时间时包含该时刻、为日期时包含当天全天。
+// ChangedSince 供增量同步使用，只接受 RFC 3339 时间，返回更新时间晚于该时刻的数据（不含该时刻）。
+// 在跨字段校验规则中调用 Violations，仓储构建查询条件时调用 Where。
+type DateRange struct {
+	CreatedFrom  string `form:"createdFrom" label:"创建时间起"`
+	CreatedTo    string `form:"createdTo" label:"创建时间止"`
+	UpdatedFrom  string `form:"updatedFrom" label:"更新时间起"`
+	UpdatedTo    string `form:"updatedTo" label:"更新时间止"`
+	ChangedSince string `form:"changedSince" label:"变更时间起"`
+}
+
+// dateRangeBound 一个时间筛选参数
+type dateRangeBound struct {
+	field  string
+	label  string
+	value  string
+	column string
+	param  string
+	upper  bool
+}
+
+func (d DateRange) bounds() []dateRangeBound {
+	return []dateRangeBound{
+		{"createdFrom", "创建时间起", d.CreatedFrom, "created_at", "created_from", false},
+		{"createdTo", "创建时间止", d.CreatedTo, "created_at", "created_to", true},
+		{"updatedFrom", "更新时间起", d.UpdatedFrom, "updated_at", "updated_from", false},
+		{"updatedTo", "更新时间止", d.UpdatedTo, "updated_at", "updated_to", true},
+	}
+}
+
+// Violations 返回格式无效或起始晚于截止的参数
+func (d DateRange) Violations() []Violation {
+	var violations []Violation
+	parsed := map[string]time.Time{}
+	for _, b := range d.bounds() {
+		if b.value == "" {
+			continue
+		}
+		t, _, ok := parseDateRangeValue(b.value, time.UTC)
+		if !ok {
+			violations = append(violations, Violation{Field: b.field, Message: b.label + "格式无效，应为 RFC 3339 时间或 YYYY-MM-DD 日期"})
+			continue
+		}
+		parsed[b.field] = t
+	}
+	if d.ChangedSince != "" {
+		if _, err := time.Parse(time.RFC3339Nano, d.ChangedSince); err != nil {
+			violations = append(violations, Violation{Field: "changedSince", Message: "变更时间起格式无效，应为 RFC 3339 时间"})
+		}
+	}
+	for _, pair := range [][2]string{{"createdFrom", "createdTo"}, {"updatedFrom", "updatedTo"}} {
+		from, hasFrom := parsed[pair[0]]
+		to, hasTo := parsed[pair[1]]
+		if hasFrom && hasTo && from.After(to) {
+			violations = append(violations, Violation{Field: pair[0], Message: "起始时间不能晚于截止时间"})
+		}
+	}
+	return violations
+}
+
+// Where 返回时间筛选的查询条件，参数写入 params（命名参数 created_from、created_to、updated_from、updated_to、changed_since）
+// 需先经过 Violations 校验，无法解析的参数会被忽略
+func (d DateRange) Where(c *gin.Context, params map[string]any) []string {
+	loc := RequestLocation(c)
+	var clauses []string
+	for _, b := range d.bounds() {
+		if b.value == "" {
+			continue
+		}
+		t, dateOnly, ok := parseDateRangeValue(b.value, loc)
+		if !ok {
+			continue
+		}
+		switch {
+		case !b.upper:
+			clauses = append(clauses, b.column+" >= :"+b.param)
+		case dateOnly:
+			// 截止日期包含当天全天
+			t = t.AddDate(0, 0, 1)
+			clauses = append(clauses, b.column+" < :"+b.param)
+		default:
+			clauses = append(clauses, b.column+" <= :"+b.param)
+		}
+		params[b.param] = t
+	}
+	if t, err := time.Parse(time.RFC3339Nano, d.ChangedSince); d.ChangedSince != "" && err == nil {
+		clauses = append(clauses, "updated_at > :changed_since")
+		params["changed_since"] = t
+	}
+	return clauses
+}
+
+// CreatedBounds 返回创建时间筛选的范围 [from, to)，未传入的一端为零值；需先经过 Violations 校验
+// 用于筛选条件需要保存下来稍后执行的场景（如异步导出）：截止为日期时取次日零点，为时间时取该时刻之后的一微秒（数据库时间精度）
+func (d DateRange) CreatedBounds(c *gin.Context) (from, to time.Time) {
+	loc := RequestLocation(c)
+	if t, _, ok := parseDateRangeValue(d.CreatedFrom, loc); ok {
+		from = t
+	}
+	if t, dateOnly, ok := parseDateRangeValue(d.CreatedTo, loc); ok {
+		if dateOnly {
+			to = t.AddDate(0, 0, 1)
+		} else {
+			to = t.Truncate(time.Microsecond).Add(time.Microsecond)
+		}
+	}
+	return from, to
+}
+
+// parseDateRangeValue 解析 RFC 3339 时间或日期，日期按 loc 解析为当天零点
+func parseDateRangeValue(value string, loc *time.Location) (t time.Time, dateOnly bool, ok bool) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, false, true
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		return t, true, true
+	}
+	return time.Time{}, false, false
+}
@@ -0,0 +1,46 @@
+package pkgs
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 取值接口最多返回的取值个数
+const DistinctValuesLimit = 100
+
+// DistinctValue 字段的一个取值及具有该取值的记录数，供前端填充筛选下拉框
+type DistinctValue struct {
+	Value any   `json:"value" label:"取值"`
+	Count int64 `json:"count" label:"数量"`
+}
+
+// DistinctFields 取值接口（GET /v1/xxx/values?field=）允许查询的字段，键为 field 参数的取值，值为对应的 SQL 表达式
+// 只有白名单中的字段可以查询，表达式直接拼接到查询语句中，不能来自请求参数。
+type DistinctFields map[string]string
+
+// Names 返回允许查询的字段，按名称排序
+func (f DistinctFields) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Violations 返回 field 不在白名单中的错误
+func (f DistinctFields) Violations(field string) []Violation {
+	if _, ok := f[field]; ok || field == "" {
+		return nil
+	}
+	return []Violation{{Field: "field", Message: "不支持查询取值的字段 " + field + "，可选值：" + strings.Join(f.Names(), ", ")}}
+}
+
+// Query 返回统计 field 各取值数量的查询语句，按数量降序、取值升序，最多返回 DistinctValuesLimit 个
+// whereCondition 为列表接口构建的 WHERE 子句（可为空），field 需先经过 Violations 校验
+func (f DistinctFields) Query(field, table, whereCondition string) string {
+	expr := f[field]
+	return `SELECT ` + expr + ` AS value, count(*) AS count FROM ` + table + whereCondition +
+		` GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT ` + strconv.Itoa(DistinctValuesLimit)
+}
@@ -3,6 +3,10 @@ package pkgs
 type ApiError struct {
 	Code    int
 	Message string
+	// 随错误响应返回的附加数据（如校验失败明细），为空时响应 data 为 null
+	Data any
+	// 按语言重新生成消息与附加数据（如参数校验信息），为空时消息按消息目录翻译
+	localize func(locale string) (string, any)
 }
 
 func NewApiError(code int, message string) *ApiError {
@@ -15,3 +19,11 @@ func NewApiError(code int, message string) *ApiError {
 func (e *ApiError) Error() string {
 	return e.Message
 }
+
+// Localized 返回指定语言下的消息与附加数据
+func (e *ApiError) Localized(locale string) (string, any) {
+	if e.localize == nil {
+		return e.Message, e.Data
+	}
+	return e.localize(locale)
+}
@@ -0,0 +1,91 @@
+package pkgs
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 保留的最近错误日志条数，随支持包（GET /v1/admin/support-bundle）导出
+const recentErrorLogSize = 200
+
+// ErrorLogEntry 一条 error 及以上级别的日志，消息与字段已经过脱敏
+type ErrorLogEntry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Caller  string         `json:"caller,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// recentErrorLogs 本进程最近的错误日志，由 NewLogger 创建的日志器写入
+var recentErrorLogs = &errorLogBuffer{}
+
+// RecentErrorLogs 返回本进程最近的错误日志，按时间先后排列
+func RecentErrorLogs() []ErrorLogEntry {
+	return recentErrorLogs.list()
+}
+
+// errorLogBuffer 环形缓冲区，写满后覆盖最早的日志
+type errorLogBuffer struct {
+	mu      sync.Mutex
+	entries []ErrorLogEntry
+	next    int
+}
+
+func (b *errorLogBuffer) add(entry ErrorLogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) < recentErrorLogSize {
+		b.entries = append(b.entries, entry)
+		return
+	}
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % recentErrorLogSize
+}
+
+func (b *errorLogBuffer) list() []ErrorLogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Concat(b.entries[b.next:], b.entries[:b.next])
+}
+
+// errorLogCore 把 error 及以上级别的日志写入 errorLogBuffer 的 zapcore.Core
+type errorLogCore struct {
+	buffer *errorLogBuffer
+	fields []zapcore.Field
+}
+
+func (c *errorLogCore) Enabled(level zapcore.Level) bool {
+	return level >= zapcore.ErrorLevel
+}
+
+func (c *errorLogCore) With(fields []zapcore.Field) zapcore.Core {
+	return &errorLogCore{buffer: c.buffer, fields: slices.Concat(c.fields, fields)}
+}
+
+func (c *errorLogCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *errorLogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range slices.Concat(c.fields, fields) {
+		field.AddTo(enc)
+	}
+	item := ErrorLogEntry{Time: entry.Time, Level: entry.Level.String(), Message: entry.Message, Fields: enc.Fields}
+	if entry.Caller.Defined {
+		item.Caller = entry.Caller.TrimmedPath()
+	}
+	c.buffer.add(item)
+	return nil
+}
+
+func (c *errorLogCore) Sync() error {
+	return nil
+}
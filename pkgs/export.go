@@ -0,0 +1,61 @@
+package pkgs
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// 导出文件格式
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// 导出文件的 Content-Type
+var exportContentTypes = map[string]string{
+	ExportFormatCSV:  "text/csv; charset=utf-8",
+	ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// RowWriter 逐行写出导出文件，调用方定期 Flush 把数据推送给客户端，写完后 Close
+type RowWriter interface {
+	Write(record []string) error
+	Flush() error
+	Close() error
+}
+
+// NewRowWriter 按格式创建导出文件的写入器，format 须已校验为 csv 或 xlsx
+// CSV 的单元格经过 CSVCell 防止公式注入，xlsx 的单元格本身就是文本。
+func NewRowWriter(format string, w io.Writer) (RowWriter, error) {
+	if format == ExportFormatXLSX {
+		return NewXLSXWriter(w)
+	}
+	return &csvRowWriter{w: csv.NewWriter(w)}, nil
+}
+
+// ExportContentType 返回导出文件的 Content-Type
+func ExportContentType(format string) string {
+	return exportContentTypes[format]
+}
+
+// csvRowWriter CSV 格式的导出写入器
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) Write(record []string) error {
+	cells := make([]string, len(record))
+	for i, value := range record {
+		cells[i] = CSVCell(value)
+	}
+	return c.w.Write(cells)
+}
+
+func (c *csvRowWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvRowWriter) Close() error {
+	return c.Flush()
+}
@@ -0,0 +1,301 @@
+package pkgs
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 密文前缀，用于区分加密数据与启用加密前写入的明文数据
+const encryptedPrefix = "enc:v1:"
+
+// KeyProvider 字段加密密钥来源
+// 默认实现从配置文件读取，接入 KMS 时实现该接口并替换 NewFieldCipher 中的 provider 即可
+type KeyProvider interface {
+	// DataKey 返回 AES-256 数据密钥（32 字节），返回 nil 表示不启用加密
+	DataKey() ([]byte, error)
+	// IndexKey 返回计算影子列（HMAC-SHA256）使用的密钥
+	IndexKey() ([]byte, error)
+}
+
+// ConfigKeyProvider 从配置文件 encryption 节点读取密钥
+type ConfigKeyProvider struct {
+	config *EncryptionConfig
+}
+
+func NewConfigKeyProvider(config *Config) *ConfigKeyProvider {
+	return &ConfigKeyProvider{config: &config.Encryption}
+}
+
+func (p *ConfigKeyProvider) DataKey() ([]byte, error) {
+	if p.config.Key == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(p.config.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption.key: %w", err)
+	}
+	return key, nil
+}
+
+func (p *ConfigKeyProvider) IndexKey() ([]byte, error) {
+	return []byte(p.config.HashKey), nil
+}
+
+// FieldCipher 敏感字段加解密器
+// 使用 AES-GCM 加密手机号、邮箱等字段，同时提供 HMAC 影子值用于精确匹配查询和唯一性约束。
+// driver.Valuer/sql.Scanner 无法注入依赖，因此创建后会注册为包级默认实例，供 EncryptedString 等类型使用。
+type FieldCipher struct {
+	aead        cipher.AEAD
+	indexKey    []byte
+	fingerprint string
+}
+
+var defaultFieldCipher = &FieldCipher{}
+
+// NewFieldCipher 根据配置创建字段加解密器，并注册为默认实例
+func NewFieldCipher(config *Config) (*FieldCipher, error) {
+	f, err := newFieldCipher(NewConfigKeyProvider(config))
+	if err != nil {
+		return nil, err
+	}
+	defaultFieldCipher = f
+	return f, nil
+}
+
+func newFieldCipher(provider KeyProvider) (*FieldCipher, error) {
+	f := &FieldCipher{}
+	indexKey, err := provider.IndexKey()
+	if err != nil {
+		return nil, err
+	}
+	f.indexKey = indexKey
+
+	key, err := provider.DataKey()
+	if err != nil {
+		return nil, err
+	}
+	f.fingerprint = keyFingerprint(key, indexKey)
+	if key == nil {
+		return f, nil
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	f.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// keyFingerprint 密钥指纹，用于判断两个环境的加密数据能否互通，未配置任何密钥时为空
+func keyFingerprint(key, indexKey []byte) string {
+	if len(key) == 0 && len(indexKey) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, append(append([]byte{}, key...), indexKey...))
+	mac.Write([]byte("field-cipher-fingerprint"))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Enabled 是否配置了加密密钥
+func (f *FieldCipher) Enabled() bool {
+	return f.aead != nil
+}
+
+// Encrypt 加密字段值；未启用加密时原样返回
+func (f *FieldCipher) Encrypt(plain string) (string, error) {
+	if !f.Enabled() || plain == "" {
+		return plain, nil
+	}
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := f.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密字段值；不带密文前缀的值视为历史明文直接返回
+func (f *FieldCipher) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	if !f.Enabled() {
+		return "", errors.New("字段已加密但未配置加密密钥")
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("解析密文失败: %w", err)
+	}
+	nonceSize := f.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("密文长度错误")
+	}
+	plain, err := f.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("解密失败: %w", err)
+	}
+	return string(plain), nil
+}
+
+// BlindIndex 计算影子列的值（HMAC-SHA256 十六进制），相同明文得到相同结果
+func (f *FieldCipher) BlindIndex(plain string) string {
+	mac := hmac.New(sha256.New, f.indexKey)
+	mac.Write([]byte(plain))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// FieldEncryptionEnabled 默认加解密器是否启用了加密
+func FieldEncryptionEnabled() bool {
+	return defaultFieldCipher.Enabled()
+}
+
+// FieldKeyFingerprint 默认加解密器的密钥指纹，密文与影子列只能在指纹相同的环境之间迁移
+func FieldKeyFingerprint() string {
+	return defaultFieldCipher.fingerprint
+}
+
+// EncryptField 使用默认加解密器加密
+func EncryptField(plain string) (string, error) {
+	return defaultFieldCipher.Encrypt(plain)
+}
+
+// DecryptField 使用默认加解密器解密
+func DecryptField(value string) (string, error) {
+	return defaultFieldCipher.Decrypt(value)
+}
+
+// BlindIndex 使用默认加解密器计算影子列的值
+func BlindIndex(plain string) string {
+	return defaultFieldCipher.BlindIndex(plain)
+}
+
+// EmailBlindIndex 计算邮箱影子列的值，邮箱不区分大小写
+func EmailBlindIndex(email string) string {
+	return defaultFieldCipher.BlindIndex(normalizeEmail(email))
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// EncryptedString 加密存储的字符串字段
+// 写入数据库时自动加密，读取时自动解密，业务代码按普通字符串使用
+type EncryptedString string
+
+// Value 实现 driver.Valuer 接口
+func (s EncryptedString) Value() (driver.Value, error) {
+	return EncryptField(string(s))
+}
+
+// Scan 实现 sql.Scanner 接口
+func (s *EncryptedString) Scan(value any) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("无法将类型 %T 转换为 EncryptedString", value)
+	}
+	plain, err := DecryptField(raw)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plain)
+	return nil
+}
+
+// 每批补齐的用户数量
+const backfillBatchSize = 500
+
+// BackfillSensitiveColumns 为历史数据补齐加密与影子列
+// 启用加密前写入的明文会被加密，缺失的 phone_hash / email_hash 会按明文重新计算，可重复执行。
+func (f *FieldCipher) BackfillSensitiveColumns(db *sqlx.DB, logger *zap.Logger, tables *TableNames) error {
+	ctx := context.Background()
+	selectQuery := `SELECT id, phone, profile->>'email' AS email FROM ` + tables.User + `
+		WHERE (phone IS NOT NULL AND phone_hash IS NULL)
+		   OR (profile->>'email' IS NOT NULL AND email_hash IS NULL)
+		LIMIT $1`
+	updateQuery := `UPDATE ` + tables.User + ` SET
+		phone = $2,
+		phone_hash = $3,
+		profile = CASE WHEN $4::text IS NULL THEN profile ELSE jsonb_set(profile, '{email}', to_jsonb($4::text)) END,
+		email_hash = $5
+		WHERE id = $1`
+
+	total := 0
+	for {
+		var rows []struct {
+			ID    string  `db:"id"`
+			Phone *string `db:"phone"`
+			Email *string `db:"email"`
+		}
+		if err := db.SelectContext(ctx, &rows, selectQuery, backfillBatchSize); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			var phone, phoneHash, email, emailHash *string
+			if row.Phone != nil {
+				plain, err := f.Decrypt(*row.Phone)
+				if err != nil {
+					return fmt.Errorf("user %s: %w", row.ID, err)
+				}
+				encrypted, err := f.Encrypt(plain)
+				if err != nil {
+					return err
+				}
+				hash := f.BlindIndex(plain)
+				phone, phoneHash = &encrypted, &hash
+			}
+			if row.Email != nil {
+				plain, err := f.Decrypt(*row.Email)
+				if err != nil {
+					return fmt.Errorf("user %s: %w", row.ID, err)
+				}
+				encrypted, err := f.Encrypt(plain)
+				if err != nil {
+					return err
+				}
+				hash := f.BlindIndex(normalizeEmail(plain))
+				email, emailHash = &encrypted, &hash
+			}
+			if _, err := db.ExecContext(ctx, updateQuery, row.ID, phone, phoneHash, email, emailHash); err != nil {
+				return fmt.Errorf("user %s: %w", row.ID, err)
+			}
+		}
+
+		total += len(rows)
+		if len(rows) < backfillBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Info("敏感字段加密补齐完成", zap.Int("count", total))
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package pkgs
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// 过滤表达式字段的取值类型
+const (
+	FilterText = "text"
+	FilterInt  = "int"
+	FilterTime = "time"
+	FilterBool = "bool"
+	FilterUUID = "uuid"
+)
+
+// 过滤表达式最多包含的条件数与 in 操作符最多包含的取值数
+const (
+	maxFilterConditions = 10
+	maxFilterValues     = 100
+)
+
+// 各取值类型支持的操作符
+var filterOperators = map[string][]string{
+	FilterText: {"eq", "ne", "like", "in", "null"},
+	FilterInt:  {"eq", "ne", "gt", "gte", "lt", "lte", "in", "null"},
+	FilterTime: {"eq", "ne", "gt", "gte", "lt", "lte", "null"},
+	FilterBool: {"eq", "ne", "null"},
+	FilterUUID: {"eq", "ne", "in", "null"},
+}
+
+// FilterColumn 过滤表达式可以筛选的一个字段
+type FilterColumn struct {
+	// SQL 表达式，直接拼接到查询语句中，不能来自请求参数
+	Expr string
+	// 取值类型，决定可用的操作符与取值的解析方式
+	Type string
+}
+
+// FilterColumns 列表接口 filter 参数允许筛选的字段，键为表达式中的字段名，只有白名单中的字段可以筛选
+//
+// 过滤表达式由逗号分隔的条件组成，条件同时满足，每个条件为 字段:操作符:值，例如：
+//
+//	filter=username:like:foo,created_at:gte:2024-01-01
+//
+// 操作符：eq、ne、gt、gte、lt、lte、like（不区分大小写的包含匹配，% 与 _ 按字面匹配）、in（多个取值用 | 分隔）、
+// null（值为 true 时匹配空值，false 时匹配非空值）。取值不能包含逗号；时间取值与 createdFrom 等参数相同，
+// 可以是 RFC 3339 时间或日期，日期按请求时区解析，比较时代表当天全天（lte:2024-01-31 包含 31 日全天）。
+// 在跨字段校验规则中调用 Violations，仓储构建查询条件时调用 Where。
+type FilterColumns map[string]FilterColumn
+
+// filterCondition 过滤表达式中的一个条件
+type filterCondition struct {
+	field  string
+	op     string
+	values []string
+}
+
+// Names 返回允许筛选的字段，按名称排序
+func (f FilterColumns) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Violations 校验过滤表达式的格式、字段、操作符与取值，field 为请求参数名
+func (f FilterColumns) Violations(field, expr string) []Violation {
+	conditions, message := parseFilter(expr)
+	if message != "" {
+		return []Violation{{Field: field, Message: message}}
+	}
+	var violations []Violation
+	for i, cond := range conditions {
+		if message := f.check(cond); message != "" {
+			violations = append(violations, Violation{Field: field, Message: message, Indexes: []int{i}})
+		}
+	}
+	return violations
+}
+
+// Where 返回过滤表达式的 WHERE 子句，参数写入 params；表达式需先经过 Violations 校验，无效的条件被忽略
+func (f FilterColumns) Where(c *gin.Context, expr string, params map[string]any) []string {
+	conditions, message := parseFilter(expr)
+	if message != "" {
+		return nil
+	}
+	loc := RequestLocation(c)
+	var clauses []string
+	for i, cond := range conditions {
+		if f.check(cond) != "" {
+			continue
+		}
+		column := f[cond.field]
+		name := "filter_" + strconv.Itoa(i)
+		if clause := filterClause(column, cond, name, loc, params); clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
+// check 校验一个条件，返回错误信息，合法时返回空字符串
+func (f FilterColumns) check(cond filterCondition) string {
+	column, ok := f[cond.field]
+	if !ok {
+		return "不支持筛选的字段 " + cond.field + "，可选值：" + strings.Join(f.Names(), ", ")
+	}
+	if !slices.Contains(filterOperators[column.Type], cond.op) {
+		return fmt.Sprintf("字段 %s 不支持操作符 %s，可选值：%s", cond.field, cond.op, strings.Join(filterOperators[column.Type], ", "))
+	}
+	if cond.op == "null" {
+		if _, err := strconv.ParseBool(cond.values[0]); err != nil {
+			return fmt.Sprintf("字段 %s 的 null 操作符取值必须是 true 或 false", cond.field)
+		}
+		return ""
+	}
+	if len(cond.values) > maxFilterValues {
+		return fmt.Sprintf("字段 %s 的取值不能超过 %d 个", cond.field, maxFilterValues)
+	}
+	for _, value := range cond.values {
+		if _, ok := parseFilterValue(column.Type, value, time.UTC); !ok {
+			return fmt.Sprintf("字段 %s 的取值 %q 格式无效", cond.field, value)
+		}
+	}
+	return ""
+}
+
+// parseFilter 拆分过滤表达式，格式错误时返回错误信息
+func parseFilter(expr string) ([]filterCondition, string) {
+	if expr == "" {
+		return nil, ""
+	}
+	items := strings.Split(expr, ",")
+	if len(items) > maxFilterConditions {
+		return nil, fmt.Sprintf("过滤条件不能超过 %d 个", maxFilterConditions)
+	}
+	conditions := make([]filterCondition, 0, len(items))
+	for _, item := range items {
+		// 时间取值中包含冒号，只拆分前两个
+		parts := strings.SplitN(item, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Sprintf("过滤条件 %q 格式错误，应为 字段:操作符:值", item)
+		}
+		values := []string{parts[2]}
+		if parts[1] == "in" {
+			values = strings.Split(parts[2], "|")
+		}
+		conditions = append(conditions, filterCondition{field: parts[0], op: parts[1], values: values})
+	}
+	return conditions, ""
+}
+
+// likeEscaper 转义 like 取值中的通配符，取值中的 %、_ 按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterClause 生成一个条件的 SQL 子句，name 为参数名前缀
+func filterClause(column FilterColumn, cond filterCondition, name string, loc *time.Location, params map[string]any) string {
+	switch cond.op {
+	case "null":
+		if isNull, _ := strconv.ParseBool(cond.values[0]); isNull {
+			return column.Expr + " IS NULL"
+		}
+		return column.Expr + " IS NOT NULL"
+	case "like":
+		params[name] = "%" + likeEscaper.Replace(cond.values[0]) + "%"
+		return column.Expr + " ILIKE :" + name + ` ESCAPE '\'`
+	case "in":
+		placeholders := make([]string, len(cond.values))
+		for i, value := range cond.values {
+			param := name + "_" + strconv.Itoa(i)
+			params[param], _ = parseFilterValue(column.Type, value, loc)
+			placeholders[i] = ":" + param
+		}
+		return column.Expr + " IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	value, _ := parseFilterValue(column.Type, cond.values[0], loc)
+	if column.Type == FilterTime && isDateOnly(cond.values[0]) {
+		return dateClause(column.Expr, cond.op, name, value.(time.Time), params)
+	}
+	params[name] = value
+	operators := map[string]string{"eq": "=", "ne": "<>", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+	return column.Expr + " " + operators[cond.op] + " :" + name
+}
+
+// dateClause 日期代表当天全天 [day, day+1)
+func dateClause(expr, op, name string, day time.Time, params map[string]any) string {
+	params[name] = day
+	params[name+"_end"] = day.AddDate(0, 0, 1)
+	switch op {
+	case "eq":
+		return "(" + expr + " >= :" + name + " AND " + expr + " < :" + name + "_end)"
+	case "ne":
+		return "(" + expr + " < :" + name + " OR " + expr + " >= :" + name + "_end)"
+	case "gt":
+		return expr + " >= :" + name + "_end"
+	case "gte":
+		return expr + " >= :" + name
+	case "lt":
+		return expr + " < :" + name
+	default:
+		return expr + " < :" + name + "_end"
+	}
+}
+
+// parseFilterValue 按取值类型解析条件的取值
+func parseFilterValue(typ, value string, loc *time.Location) (any, bool) {
+	switch typ {
+	case FilterInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		return n, err == nil
+	case FilterTime:
+		t, _, ok := parseDateRangeValue(value, loc)
+		return t, ok
+	case FilterBool:
+		b, err := strconv.ParseBool(value)
+		return b, err == nil
+	case FilterUUID:
+		return value, uuid.Validate(value) == nil
+	default:
+		return value, true
+	}
+}
+
+func isDateOnly(value string) bool {
+	_, dateOnly, _ := parseDateRangeValue(value, time.UTC)
+	return dateOnly
+}
@@ -0,0 +1,62 @@
+package pkgs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// 主键生成方式，对应配置 database.id_generation
+const (
+	// 由数据库列默认值 uuidv7() 生成（默认）
+	IDGenerationDatabase = "database"
+	// 由应用生成 UUIDv7 后随 INSERT 写入，适用于列默认值仍为随机 v4 的旧库或需要提前得知主键的场景
+	IDGenerationApplication = "application"
+)
+
+// IDGenerator 新增行的主键生成器
+// 两种方式生成的都是 UUIDv7，按时间递增以保持 B-tree 索引的写入局部性；
+// 已存在的 v4 主键不受影响，校验规则 uuid 对版本不做限制，列表排序依赖 created_at + seq 而不是主键。
+type IDGenerator struct {
+	application bool
+}
+
+func NewIDGenerator(config *Config) *IDGenerator {
+	return &IDGenerator{application: config.Database.IDGeneration == IDGenerationApplication}
+}
+
+// NewID 生成一个 UUIDv7
+func NewID() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("生成 UUIDv7 失败: %w", err)
+	}
+	return id.String(), nil
+}
+
+// Assign 应用侧生成时为主键赋值，数据库生成时保持为空由列默认值填充
+func (g *IDGenerator) Assign(id *string) error {
+	if !g.application {
+		return nil
+	}
+	v, err := NewID()
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
+
+// Insert 返回 INSERT 语句的列名列表与命名参数列表，应用侧生成时在最前面加上 id 列
+// 例如 Insert("name", "num") 返回 "id, name, num" 与 ":id, :name, :num"
+func (g *IDGenerator) Insert(columns ...string) (string, string) {
+	if g.application {
+		columns = append([]string{"id"}, columns...)
+	}
+	params := make([]string, len(columns))
+	for i, column := range columns {
+		params[i] = ":" + column
+	}
+	return strings.Join(columns, ", "), strings.Join(params, ", ")
+}
@@ -0,0 +1,185 @@
+package pkgs
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// 对外ID的长度：16 字节 AES 密文 + 4 字节校验值，base64url 编码后为 27 个字符
+const (
+	obfuscatedIDTagSize = 4
+	obfuscatedIDLength  = 27
+)
+
+// ErrRawID 未允许原始 UUID 时，请求中出现原始 UUID
+var ErrRawID = errors.New("不接受原始ID，请使用接口返回的ID")
+
+// 响应中需要编码的 UUID
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// IDObfuscator 对外暴露ID的混淆器
+// 启用后接口边界上的 UUID 与对外ID互相转换：响应 JSON 中出现的 UUID（包括消息文本中的）编码为对外ID，
+// 请求的路径参数、查询参数与 JSON 请求体中的对外ID解码为 UUID，仓储与数据库始终使用原始 UUID。
+// 对外ID为 UUID 经 AES 加密后附加 HMAC 校验值，同一部署内稳定，无法由外部推测或与数据库主键关联。
+type IDObfuscator struct {
+	block    cipher.Block
+	tagKey   []byte
+	allowRaw bool
+}
+
+// NewIDObfuscator 根据配置创建ID混淆器，未启用时返回的实例不做任何转换
+func NewIDObfuscator(config *Config) (*IDObfuscator, error) {
+	conf := config.IDObfuscation
+	if !conf.Enabled {
+		return &IDObfuscator{}, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(conf.Key)
+	if err != nil || len(key) < 32 {
+		return nil, fmt.Errorf("invalid id_obfuscation.key: must be base64 of at least 32 bytes")
+	}
+	// 加密密钥与校验密钥由配置的密钥派生，互不相同
+	block, err := aes.NewCipher(deriveKey(key, "id-obfuscation-cipher"))
+	if err != nil {
+		return nil, err
+	}
+	return &IDObfuscator{block: block, tagKey: deriveKey(key, "id-obfuscation-tag"), allowRaw: conf.AllowRaw}, nil
+}
+
+func deriveKey(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// Enabled 是否启用ID混淆
+func (o *IDObfuscator) Enabled() bool {
+	return o != nil && o.block != nil
+}
+
+// Encode 将 UUID 编码为对外ID，不是 UUID 时原样返回
+func (o *IDObfuscator) Encode(id string) string {
+	parsed, err := uuid.Parse(id)
+	if !o.Enabled() || err != nil {
+		return id
+	}
+	out := make([]byte, aes.BlockSize+obfuscatedIDTagSize)
+	o.block.Encrypt(out, parsed[:])
+	copy(out[aes.BlockSize:], o.tag(out[:aes.BlockSize]))
+	return base64.RawURLEncoding.EncodeToString(out)
+}
+
+// Decode 将对外ID解码为 UUID，不是本部署签发的对外ID时 ok 为 false
+func (o *IDObfuscator) Decode(token string) (id string, ok bool) {
+	if !o.Enabled() || len(token) != obfuscatedIDLength {
+		return "", false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != aes.BlockSize+obfuscatedIDTagSize {
+		return "", false
+	}
+	if !hmac.Equal(raw[aes.BlockSize:], o.tag(raw[:aes.BlockSize])) {
+		return "", false
+	}
+	var parsed uuid.UUID
+	o.block.Decrypt(parsed[:], raw[:aes.BlockSize])
+	return parsed.String(), true
+}
+
+func (o *IDObfuscator) tag(ciphertext []byte) []byte {
+	mac := hmac.New(sha256.New, o.tagKey)
+	mac.Write(ciphertext)
+	return mac.Sum(nil)[:obfuscatedIDTagSize]
+}
+
+// DecodeParam 解码请求参数中的对外ID，逗号分隔的多个值逐个解码
+// 未允许原始 UUID 时，参数中出现原始 UUID 返回错误
+func (o *IDObfuscator) DecodeParam(value string) (string, error) {
+	if !o.Enabled() || value == "" {
+		return value, nil
+	}
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		decoded, err := o.decodeValue(part)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = decoded
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// decodeValue 解码一个字符串值：对外ID解码为 UUID，其余字符串原样返回
+func (o *IDObfuscator) decodeValue(value string) (string, error) {
+	if id, ok := o.Decode(value); ok {
+		return id, nil
+	}
+	if !o.allowRaw && isUUID(value) {
+		return "", ErrRawID
+	}
+	return value, nil
+}
+
+// DecodeJSON 解码 JSON 请求体中所有字符串值里的对外ID
+func (o *IDObfuscator) DecodeJSON(data []byte) ([]byte, error) {
+	return o.rewriteJSON(data, o.decodeValue)
+}
+
+// EncodeJSON 将 JSON 响应体中所有字符串值里的 UUID 编码为对外ID
+func (o *IDObfuscator) EncodeJSON(data []byte) ([]byte, error) {
+	return o.rewriteJSON(data, func(value string) (string, error) {
+		return uuidPattern.ReplaceAllStringFunc(value, o.Encode), nil
+	})
+}
+
+// rewriteJSON 按 rewrite 替换 JSON 中的字符串值，对象的键与数字保持不变
+func (o *IDObfuscator) rewriteJSON(data []byte, rewrite func(string) (string, error)) ([]byte, error) {
+	if !o.Enabled() || len(bytes.TrimSpace(data)) == 0 {
+		return data, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	value, err := rewriteJSONValue(value, rewrite)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func rewriteJSONValue(value any, rewrite func(string) (string, error)) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return rewrite(v)
+	case []any:
+		for i := range v {
+			item, err := rewriteJSONValue(v[i], rewrite)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = item
+		}
+	case map[string]any:
+		for key := range v {
+			item, err := rewriteJSONValue(v[key], rewrite)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = item
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,37 @@
+package pkgs
+
+import (
+	"slices"
+	"strings"
+)
+
+// Includes 详情接口 ?include= 查询参数，按需返回的扩展内容，多个值以逗号分隔（如 include=permissions,userCount）
+// 在请求结构体中声明为 Include Includes `form:"include"`，并在跨字段校验规则中调用 Violations 拒绝不支持的值。
+type Includes string
+
+// Names 返回请求的扩展内容，去掉空白与空值
+func (i Includes) Names() []string {
+	var names []string
+	for name := range strings.SplitSeq(string(i), ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Has 是否请求了指定的扩展内容
+func (i Includes) Has(name string) bool {
+	return slices.Contains(i.Names(), name)
+}
+
+// Violations 返回不在 allowed 中的扩展内容
+func (i Includes) Violations(allowed ...string) []Violation {
+	var violations []Violation
+	for _, name := range i.Names() {
+		if !slices.Contains(allowed, name) {
+			violations = append(violations, Violation{Field: "include", Message: "不支持的扩展内容 " + name + "，可选值：" + strings.Join(allowed, ", ")})
+		}
+	}
+	return violations
+}
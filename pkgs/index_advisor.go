@@ -0,0 +1,99 @@
+package pkgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IndexSuggestion 根据执行计划得出的索引建议
+type IndexSuggestion struct {
+	Table   string   `json:"table" label:"表名"`
+	Columns []string `json:"columns" label:"过滤列"`
+	Filter  string   `json:"filter" label:"过滤条件"`
+	Reason  string   `json:"reason" label:"原因"`
+	DDL     string   `json:"ddl" label:"建议的建索引语句"`
+}
+
+// planNode EXPLAIN (FORMAT JSON) 输出中的计划节点，只解析建议索引用到的字段
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Schema       string     `json:"Schema"`
+	Filter       string     `json:"Filter"`
+	PlanRows     float64    `json:"Plan Rows"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// 过滤条件中的 "列 操作符" 片段，例如 ((username)::text ~~* $1)、(type = $2)
+var filterColumnPattern = regexp.MustCompile(`\(*([a-z_][a-z0-9_]*)\)?(?:::[a-z ]+?)?\s+(=|<>|<=|>=|<|>|~~\*|~~)\s`)
+
+// SuggestIndexes 解析 EXPLAIN (FORMAT JSON) 的输出，为带过滤条件的顺序扫描给出索引建议
+// 模糊匹配（LIKE/ILIKE）建议 pg_trgm 的 GIN 索引，其余比较建议 B-tree 索引。
+// 建议只基于执行计划的形状，是否采纳需要结合数据量与写入开销判断。
+func SuggestIndexes(plan []byte) ([]IndexSuggestion, error) {
+	var explained []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explained); err != nil {
+		return nil, fmt.Errorf("解析执行计划失败: %w", err)
+	}
+
+	suggestions := []IndexSuggestion{}
+	var walk func(node planNode)
+	walk = func(node planNode) {
+		if node.NodeType == "Seq Scan" && node.Filter != "" {
+			if s, ok := suggestForFilter(node); ok {
+				suggestions = append(suggestions, s)
+			}
+		}
+		for _, child := range node.Plans {
+			walk(child)
+		}
+	}
+	for _, e := range explained {
+		walk(e.Plan)
+	}
+	return suggestions, nil
+}
+
+// suggestForFilter 从顺序扫描的过滤条件中提取列并生成建索引语句
+func suggestForFilter(node planNode) (IndexSuggestion, bool) {
+	var btree, trgm []string
+	seen := map[string]bool{}
+	for _, m := range filterColumnPattern.FindAllStringSubmatch(node.Filter, -1) {
+		column, op := m[1], m[2]
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+		if strings.HasPrefix(op, "~~") {
+			trgm = append(trgm, column)
+		} else {
+			btree = append(btree, column)
+		}
+	}
+	if len(btree) == 0 && len(trgm) == 0 {
+		return IndexSuggestion{}, false
+	}
+
+	table := node.RelationName
+	if node.Schema != "" {
+		table = node.Schema + "." + table
+	}
+	var ddl []string
+	if len(btree) > 0 {
+		ddl = append(ddl, fmt.Sprintf("CREATE INDEX ON %s (%s);", table, strings.Join(btree, ", ")))
+	}
+	for _, column := range trgm {
+		ddl = append(ddl, fmt.Sprintf("CREATE INDEX ON %s USING gin (%s public.gin_trgm_ops);", table, column))
+	}
+	return IndexSuggestion{
+		Table:   table,
+		Columns: append(btree, trgm...),
+		Filter:  node.Filter,
+		Reason:  fmt.Sprintf("顺序扫描 %s 并按条件过滤（预估 %.0f 行）", table, node.PlanRows),
+		DDL:     strings.Join(ddl, " "),
+	}, true
+}
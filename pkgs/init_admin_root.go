@@ -13,7 +13,7 @@ import (
 // 2. 检索是否存在name为root的角色，如果没有则创建
 // 3. 确保admin用户拥有root角色
 // 4. 确保root角色拥有所有的权限
-func InitAdminRoot(db *sqlx.DB, logger *zap.Logger) error {
+func InitAdminRoot(db *sqlx.DB, logger *zap.Logger, tables *TableNames, hasher *PasswordHasher) error {
 	tx, err := db.Beginx()
 	if err != nil {
 		return fmt.Errorf("开启事务失败: %w", err)
@@ -33,12 +33,16 @@ func InitAdminRoot(db *sqlx.DB, logger *zap.Logger) error {
 		}
 	}()
 
-	// 1. 检索或创建 administrator 用户，密码是md5(123456)
+	// 1. 检索或创建 administrator 用户，密码是md5(123456)，保存其哈希
 	var adminID string
-	err = tx.Get(&adminID, `SELECT id FROM iacc_user WHERE username = 'administrator'`)
+	err = tx.Get(&adminID, `SELECT id FROM `+tables.User+` WHERE username = 'administrator'`)
 	if err == sql.ErrNoRows {
 		logger.Info("admin 用户不存在，正在创建...")
-		err = tx.Get(&adminID, `INSERT INTO iacc_user (username, password) VALUES ('administrator', 'e10adc3949ba59abbe56e057f20f883e') RETURNING id`)
+		var password string
+		if password, err = hasher.Hash("e10adc3949ba59abbe56e057f20f883e"); err != nil {
+			return fmt.Errorf("哈希 admin 用户密码失败: %w", err)
+		}
+		err = tx.Get(&adminID, `INSERT INTO `+tables.User+` (username, password) VALUES ('administrator', $1) RETURNING id`, password)
 		if err != nil {
 			return fmt.Errorf("创建 admin 用户失败: %w", err)
 		}
@@ -51,10 +55,10 @@ func InitAdminRoot(db *sqlx.DB, logger *zap.Logger) error {
 
 	// 2. 检索或创建 root 角色
 	var rootID string
-	err = tx.Get(&rootID, `SELECT id FROM iacc_role WHERE name = 'root'`)
+	err = tx.Get(&rootID, `SELECT id FROM `+tables.Role+` WHERE name = 'root'`)
 	if err == sql.ErrNoRows {
 		logger.Info("root 角色不存在，正在创建...")
-		err = tx.Get(&rootID, `INSERT INTO iacc_role (name) VALUES ('root') RETURNING id`)
+		err = tx.Get(&rootID, `INSERT INTO `+tables.Role+` (name) VALUES ('root') RETURNING id`)
 		if err != nil {
 			return fmt.Errorf("创建 root 角色失败: %w", err)
 		}
@@ -67,13 +71,13 @@ func InitAdminRoot(db *sqlx.DB, logger *zap.Logger) error {
 
 	// 3. 确保 admin 用户拥有 root 角色
 	var count int
-	err = tx.Get(&count, `SELECT count(*) FROM iacc_user_role WHERE user_id = $1 AND role_id = $2`, adminID, rootID)
+	err = tx.Get(&count, `SELECT count(*) FROM `+tables.UserRole+` WHERE user_id = $1 AND role_id = $2`, adminID, rootID)
 	if err != nil {
 		return fmt.Errorf("检查 admin 用户的 root 角色失败: %w", err)
 	}
 	if count == 0 {
 		logger.Info("为 admin 用户分配 root 角色...")
-		_, err = tx.Exec(`INSERT INTO iacc_user_role (user_id, role_id) VALUES ($1, $2)`, adminID, rootID)
+		_, err = tx.Exec(`INSERT INTO `+tables.UserRole+` (user_id, role_id) VALUES ($1, $2)`, adminID, rootID)
 		if err != nil {
 			return fmt.Errorf("为 admin 用户分配 root 角色失败: %w", err)
 		}
@@ -84,14 +88,14 @@ func InitAdminRoot(db *sqlx.DB, logger *zap.Logger) error {
 
 	// 4. 确保 root 角色拥有所有权限
 	var permissionIDs []string
-	err = tx.Select(&permissionIDs, `SELECT id FROM iacc_permission`)
+	err = tx.Select(&permissionIDs, `SELECT id FROM `+tables.Permission)
 	if err != nil {
 		return fmt.Errorf("获取所有权限失败: %w", err)
 	}
 
 	if len(permissionIDs) > 0 {
 		logger.Info(fmt.Sprintf("为 root 角色分配 %d 个权限...", len(permissionIDs)))
-		query := `INSERT INTO iacc_role_permission (role_id, permission_id) VALUES `
+		query := `INSERT INTO ` + tables.RolePermission + ` (role_id, permission_id) VALUES `
 		args := []interface{}{}
 		for _, pid := range permissionIDs {
 			query += fmt.Sprintf("($%d, $%d),", len(args)+1, len(args)+2)
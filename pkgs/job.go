@@ -0,0 +1,334 @@
+package pkgs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// 异步任务状态
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCanceled  = "canceled"
+)
+
+// 手动重试与取消任务的错误
+var (
+	ErrJobNotFound      = errors.New("任务不存在")
+	ErrJobNotRetryable  = errors.New("只能重试已失败或已取消的任务")
+	ErrJobNotCancelable = errors.New("只能取消等待执行的任务")
+)
+
+var (
+	asyncJobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "async_job_runs_total",
+		Help: "Async job attempts by job type and result (succeeded, retried when requeued after a failure, failed when no attempts are left).",
+	}, []string{"type", "result"})
+	asyncJobQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "async_job_queue_depth",
+		Help: "Async jobs waiting or executing across all tenant schemas, by job type and status (pending, running).",
+	}, []string{"type", "status"})
+	asyncJobOldestPending = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "async_job_oldest_pending_seconds",
+		Help: "Age of the oldest pending async job across all tenant schemas, by job type.",
+	}, []string{"type"})
+)
+
+// 每轮每个 schema 最多领取的任务数
+const jobBatchSize = 10
+
+// 执行中超过该时长仍未结束的任务视为实例已退出，重新领取执行
+const jobStaleAfter = 10 * time.Minute
+
+// 失败重试的基础间隔，第 n 次失败后间隔 n 倍
+const jobRetryBackoff = 30 * time.Second
+
+// JobColumns 查询任务时返回的列，与 Job 的字段对应
+const JobColumns = `id, created_at, updated_at, type, payload, status, attempts, max_attempts, last_error, trace_id, run_at, started_at, finished_at`
+
+// Job 数据库表 async_job 的表结构
+type Job struct {
+	ID          string          `db:"id"`
+	CreatedAt   time.Time       `db:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at"`
+	Type        string          `db:"type"`
+	Payload     json.RawMessage `db:"payload"`
+	Status      string          `db:"status"`
+	Attempts    int             `db:"attempts"`
+	MaxAttempts int             `db:"max_attempts"`
+	LastError   *string         `db:"last_error"`
+	TraceID     string          `db:"trace_id"`
+	RunAt       time.Time       `db:"run_at"`
+	StartedAt   *time.Time      `db:"started_at"`
+	FinishedAt  *time.Time      `db:"finished_at"`
+	// 任务所在的租户，默认 schema 中的任务为空
+	Tenant string `db:"-"`
+}
+
+// JobHandler 异步任务处理函数，db 为任务所在 schema 的批处理连接，logger 已带上 trace_id、job_id、job_type 字段
+type JobHandler func(ctx context.Context, db *sqlx.DB, job *Job, logger *zap.Logger) error
+
+// JobQueue 基于数据库表的异步任务队列
+// 请求中通过 Enqueue 写入任务，并记录发起请求的请求ID（trace_id）；
+// Scheduler 定时调用 RunPending 领取任务执行，执行日志带上同一个 trace_id，
+// 运维可以按请求ID串联同步请求与异步执行两段日志。
+type JobQueue struct {
+	pool   *TenantPool
+	tables *TableNames
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+func NewJobQueue(pool *TenantPool, tables *TableNames, logger *zap.Logger) *JobQueue {
+	return &JobQueue{
+		pool:     pool,
+		tables:   tables,
+		logger:   logger,
+		handlers: map[string]JobHandler{},
+	}
+}
+
+// Register 注册任务类型的处理函数
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Enqueue 在当前请求的租户下写入一个待执行任务，返回任务ID
+func (q *JobQueue) Enqueue(c *gin.Context, jobType string, payload any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("序列化任务参数失败: %w", err)
+	}
+	traceID := TraceIDFromContext(c)
+
+	var id string
+	query := `INSERT INTO ` + q.tables.AsyncJob + ` (type, payload, trace_id) VALUES ($1, $2, $3) RETURNING id`
+	if err := q.pool.DB(c).GetContext(c.Request.Context(), &id, query, jobType, data, traceID); err != nil {
+		return "", fmt.Errorf("写入任务失败: %w", err)
+	}
+	q.logger.Info("任务已入队", zap.String("trace_id", traceID), zap.String("job_id", id), zap.String("job_type", jobType))
+	return id, nil
+}
+
+// RunAll 依次处理默认 schema 与所有租户 schema 中到期的任务，处理完后刷新队列指标
+func (q *JobQueue) RunAll(ctx context.Context) {
+	dbs := q.pool.BatchDBs(ctx, q.logger)
+	for tenant, db := range dbs {
+		if _, err := q.RunPending(ctx, tenant, db); err != nil {
+			q.logger.Error("执行异步任务失败", zap.String("tenant", tenant), zap.Error(err))
+		}
+	}
+	q.refreshMetrics(ctx, dbs)
+}
+
+// JobTypeStats 一种任务类型的队列状态
+// Pending、Running、OldestPendingSeconds 为当前值；Succeeded、Failed、Canceled、Retries 统计窗口内结束的任务
+// （Retries 另外包括仍在排队或执行的任务已发生的重试）。
+type JobTypeStats struct {
+	Type                 string   `db:"type" json:"type" label:"任务类型"`
+	Pending              int64    `db:"pending" json:"pending" label:"等待执行"`
+	Running              int64    `db:"running" json:"running" label:"执行中"`
+	Succeeded            int64    `db:"succeeded" json:"succeeded" label:"成功"`
+	Failed               int64    `db:"failed" json:"failed" label:"失败"`
+	Canceled             int64    `db:"canceled" json:"canceled" label:"已取消"`
+	Retries              int64    `db:"retries" json:"retries" label:"重试次数"`
+	OldestPendingSeconds *float64 `db:"oldest_pending_seconds" json:"oldest_pending_seconds,omitempty" label:"最早等待任务的等待时长（秒）"`
+	// 窗口内结束的任务中失败的比例，没有结束的任务时为空
+	FailureRate *float64 `db:"-" json:"failure_rate,omitempty" label:"失败率"`
+}
+
+// Stats 按任务类型统计 db 所在 schema 的队列状态，since 为统计窗口的起点
+func (q *JobQueue) Stats(ctx context.Context, db *sqlx.DB, since time.Time) ([]JobTypeStats, error) {
+	query := `SELECT type,
+			COUNT(*) FILTER (WHERE status = $2) AS pending,
+			COUNT(*) FILTER (WHERE status = $3) AS running,
+			COUNT(*) FILTER (WHERE status = $4 AND finished_at >= $1) AS succeeded,
+			COUNT(*) FILTER (WHERE status = $5 AND finished_at >= $1) AS failed,
+			COUNT(*) FILTER (WHERE status = $6 AND finished_at >= $1) AS canceled,
+			COALESCE(SUM(attempts - 1) FILTER (WHERE attempts > 1), 0) AS retries,
+			EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - MIN(created_at) FILTER (WHERE status = $2))::float8 AS oldest_pending_seconds
+		FROM ` + q.tables.AsyncJob + `
+		WHERE status IN ($2, $3) OR finished_at >= $1
+		GROUP BY type ORDER BY type`
+	stats, err := QueryAll[JobTypeStats](ctx, db, query, since, JobStatusPending, JobStatusRunning, JobStatusSucceeded, JobStatusFailed, JobStatusCanceled)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		if finished := stats[i].Succeeded + stats[i].Failed; finished > 0 {
+			rate := float64(stats[i].Failed) / float64(finished)
+			stats[i].FailureRate = &rate
+		}
+	}
+	return stats, nil
+}
+
+// refreshMetrics 汇总所有 schema 的队列深度与最早等待时长写入指标，查询失败的 schema 跳过
+func (q *JobQueue) refreshMetrics(ctx context.Context, dbs map[string]*sqlx.DB) {
+	depth := map[[2]string]int64{}
+	oldest := map[string]float64{}
+	for tenant, db := range dbs {
+		stats, err := q.Stats(ctx, db, time.Now())
+		if err != nil {
+			q.logger.Warn("统计异步任务队列失败", zap.String("tenant", tenant), zap.Error(err))
+			continue
+		}
+		for _, s := range stats {
+			depth[[2]string{s.Type, JobStatusPending}] += s.Pending
+			depth[[2]string{s.Type, JobStatusRunning}] += s.Running
+			if s.OldestPendingSeconds != nil {
+				oldest[s.Type] = max(oldest[s.Type], *s.OldestPendingSeconds)
+			}
+		}
+	}
+	// 先清空再写入，已清空的任务类型不再保留旧值
+	asyncJobQueueDepth.Reset()
+	asyncJobOldestPending.Reset()
+	for key, n := range depth {
+		asyncJobQueueDepth.WithLabelValues(key[0], key[1]).Set(float64(n))
+	}
+	for jobType, seconds := range oldest {
+		asyncJobOldestPending.WithLabelValues(jobType).Set(seconds)
+	}
+}
+
+// Retry 将已失败或已取消的任务重新排队立即执行，并允许再执行一次
+func (q *JobQueue) Retry(ctx context.Context, db *sqlx.DB, id string) (*Job, error) {
+	query := `UPDATE ` + q.tables.AsyncJob + ` SET status = $1, run_at = CURRENT_TIMESTAMP, started_at = NULL, finished_at = NULL,
+			max_attempts = GREATEST(max_attempts, attempts + 1)
+		WHERE id = $2 AND status IN ($3, $4)
+		RETURNING ` + JobColumns
+	return q.transition(ctx, db, id, ErrJobNotRetryable, "任务已手动重试", query, JobStatusPending, id, JobStatusFailed, JobStatusCanceled)
+}
+
+// Cancel 取消等待执行的任务；执行中的任务无法中断，不能取消
+func (q *JobQueue) Cancel(ctx context.Context, db *sqlx.DB, id string) (*Job, error) {
+	query := `UPDATE ` + q.tables.AsyncJob + ` SET status = $1, finished_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+		RETURNING ` + JobColumns
+	return q.transition(ctx, db, id, ErrJobNotCancelable, "任务已取消", query, JobStatusCanceled, id, JobStatusPending)
+}
+
+// transition 按条件修改任务状态，没有修改时区分任务不存在与状态不允许
+// 与 RunPending 的领取语句竞争同一行时，后执行的一方按最新状态重新判断条件
+func (q *JobQueue) transition(ctx context.Context, db *sqlx.DB, id string, stateErr error, message string, query string, args ...any) (*Job, error) {
+	var job Job
+	err := db.GetContext(ctx, &job, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM `+q.tables.AsyncJob+` WHERE id = $1)`, id); err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrJobNotFound
+		}
+		return nil, stateErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	q.logger.Info(message, zap.String("trace_id", job.TraceID), zap.String("job_id", job.ID), zap.String("job_type", job.Type))
+	return &job, nil
+}
+
+// RunPending 领取并执行 tenant 下一批到期的任务，返回执行的任务数
+// 使用 FOR UPDATE SKIP LOCKED 领取，多个实例同时运行时同一任务只会被一个实例执行；
+// 执行中超时的任务（实例中途退出）会被重新领取
+func (q *JobQueue) RunPending(ctx context.Context, tenant string, db *sqlx.DB) (int, error) {
+	var jobs []Job
+	claim := `UPDATE ` + q.tables.AsyncJob + ` SET status = $1, attempts = attempts + 1, started_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM ` + q.tables.AsyncJob + `
+			WHERE (status = $2 AND run_at <= CURRENT_TIMESTAMP) OR (status = $1 AND started_at < $4)
+			ORDER BY run_at, seq
+			FOR UPDATE SKIP LOCKED
+			LIMIT $3
+		)
+		RETURNING ` + JobColumns
+	if err := db.SelectContext(ctx, &jobs, claim, JobStatusRunning, JobStatusPending, jobBatchSize, time.Now().Add(-jobStaleAfter)); err != nil {
+		return 0, fmt.Errorf("领取任务失败: %w", err)
+	}
+
+	for i := range jobs {
+		jobs[i].Tenant = tenant
+		q.run(ctx, db, &jobs[i])
+	}
+	return len(jobs), nil
+}
+
+// run 执行单个任务并记录结果，失败且未达到最大次数时按退避间隔重新排队
+func (q *JobQueue) run(ctx context.Context, db *sqlx.DB, job *Job) {
+	logger := q.logger.With(
+		zap.String("trace_id", job.TraceID),
+		zap.String("job_id", job.ID),
+		zap.String("job_type", job.Type),
+		zap.Int("attempt", job.Attempts),
+	)
+
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("未注册的任务类型: %s", job.Type)
+	} else {
+		logger.Info("开始执行任务")
+		err = runJobHandler(ctx, handler, db, job, logger)
+	}
+
+	if err == nil {
+		asyncJobRuns.WithLabelValues(job.Type, "succeeded").Inc()
+		logger.Info("任务执行成功")
+		query := `UPDATE ` + q.tables.AsyncJob + ` SET status = $1, last_error = NULL, finished_at = CURRENT_TIMESTAMP WHERE id = $2`
+		if _, err := db.ExecContext(ctx, query, JobStatusSucceeded, job.ID); err != nil {
+			logger.Error("更新任务状态失败", zap.Error(err))
+		}
+		return
+	}
+
+	if ok && job.Attempts < job.MaxAttempts {
+		asyncJobRuns.WithLabelValues(job.Type, "retried").Inc()
+		retryAt := time.Now().Add(time.Duration(job.Attempts) * jobRetryBackoff)
+		logger.Warn("任务执行失败，稍后重试", zap.Time("retry_at", retryAt), zap.Error(err))
+		query := `UPDATE ` + q.tables.AsyncJob + ` SET status = $1, last_error = $2, run_at = $3 WHERE id = $4`
+		if _, err := db.ExecContext(ctx, query, JobStatusPending, err.Error(), retryAt, job.ID); err != nil {
+			logger.Error("更新任务状态失败", zap.Error(err))
+		}
+		return
+	}
+
+	asyncJobRuns.WithLabelValues(job.Type, "failed").Inc()
+	logger.Error("任务执行失败", zap.Error(err))
+	query := `UPDATE ` + q.tables.AsyncJob + ` SET status = $1, last_error = $2, finished_at = CURRENT_TIMESTAMP WHERE id = $3`
+	if _, err := db.ExecContext(ctx, query, JobStatusFailed, err.Error(), job.ID); err != nil {
+		logger.Error("更新任务状态失败", zap.Error(err))
+	}
+}
+
+// runJobHandler 执行处理函数，panic 按失败处理，避免一个任务拖垮整个调度协程
+func runJobHandler(ctx context.Context, handler JobHandler, db *sqlx.DB, job *Job, logger *zap.Logger) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("任务 panic: %v", p)
+		}
+	}()
+	return handler(ctx, db, job, logger)
+}
@@ -0,0 +1,71 @@
+package pkgs
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/mo"
+)
+
+// MaxUpdatedAt 返回表中最近的更新时间，表为空时返回零值
+func MaxUpdatedAt(ctx context.Context, db *sqlx.DB, table string) (time.Time, error) {
+	var lastModified sql.NullTime
+	if err := db.GetContext(ctx, &lastModified, `SELECT MAX(updated_at) FROM `+table); err != nil {
+		return time.Time{}, err
+	}
+	return lastModified.Time, nil
+}
+
+// CheckModified 列表接口的条件请求，放在校验之后、查询列表之前
+// 按整张表的 MAX(updated_at) 设置 Last-Modified（秒级）与 ETag（微秒级）响应头；
+// 请求携带的 If-None-Match 与 ETag 相同，或未携带 If-None-Match 且 If-Modified-Since 不早于最近更新时间时，返回 304 不再查询列表。
+// 查询最近更新时间失败时跳过条件请求，照常返回列表。
+func CheckModified[T any](c *gin.Context, db *sqlx.DB, table string) func(*T) mo.Result[*T] {
+	return func(req *T) mo.Result[*T] {
+		lastModified, err := MaxUpdatedAt(c.Request.Context(), db, table)
+		if err != nil {
+			return mo.Ok(req)
+		}
+
+		etag := `W/"` + strconv.FormatInt(lastModified.UnixMicro(), 36) + `"`
+		if !lastModified.IsZero() {
+			c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+		c.Header("ETag", etag)
+		// 响应随权限（脱敏）、语言（翻译）与时区变化，只允许客户端私有缓存，使用前必须重新验证
+		c.Header("Cache-Control", "private, no-cache")
+		c.Header("Vary", "Authorization, Accept-Language, X-Timezone")
+
+		if notModified(c, etag, lastModified) {
+			return mo.Err[*T](NewApiError(http.StatusNotModified, ""))
+		}
+		return mo.Ok(req)
+	}
+}
+
+// notModified 按 RFC 9110 判断条件请求：If-None-Match 优先于 If-Modified-Since
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if ifModifiedSince := c.GetHeader("If-Modified-Since"); ifModifiedSince != "" {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		return !lastModified.IsZero() && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package pkgs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 本次请求使用的语言在 gin.Context 中的键
+const LocaleContextKey = "locale"
+
+// 支持的语言，错误信息与参数校验信息按请求的语言返回
+const (
+	LocaleZh = "zh"
+	LocaleEn = "en"
+)
+
+// SupportedLocales 支持的语言，第一个为消息原文使用的语言
+var SupportedLocales = []string{LocaleZh, LocaleEn}
+
+// Localizer 按请求语言本地化接口返回的消息
+// 语言由 Accept-Language 请求头确定，没有支持的语言时使用配置的默认语言。
+// 消息目录以中文原文为键，"创建用户失败：数据已存在" 这样以全角冒号拼接的消息逐段翻译，没有译文的消息或片段返回原文。
+// 与 TimeFormatter 相同，创建后注册为包级默认实例，Error、HandleError 通过 Localize 使用。
+type Localizer struct {
+	locale   string
+	messages map[string]map[string]string
+}
+
+var defaultLocalizer = &Localizer{locale: LocaleZh, messages: map[string]map[string]string{LocaleEn: englishMessages}}
+
+// NewLocalizer 根据配置创建本地化器，并注册为默认实例
+func NewLocalizer(config *Config) (*Localizer, error) {
+	locale := config.Locale.Default
+	if locale == "" {
+		locale = LocaleZh
+	}
+	if !slices.Contains(SupportedLocales, locale) {
+		return nil, fmt.Errorf("invalid locale.default: %q, supported: %s", locale, strings.Join(SupportedLocales, ", "))
+	}
+	l := &Localizer{locale: locale, messages: map[string]map[string]string{LocaleEn: englishMessages}}
+	defaultLocalizer = l
+	return l, nil
+}
+
+// Resolve 按 Accept-Language 请求头中出现的顺序选择支持的语言，先匹配完整语言标签再匹配主语言（en-US → en）
+func (l *Localizer) Resolve(acceptLanguage string) string {
+	for _, lang := range AcceptLanguageTags(acceptLanguage) {
+		primary, _, _ := strings.Cut(lang, "-")
+		if slices.Contains(SupportedLocales, primary) {
+			return primary
+		}
+	}
+	return l.locale
+}
+
+// Localize 返回消息在指定语言下的译文
+func (l *Localizer) Localize(locale, message string) string {
+	catalog, ok := l.messages[locale]
+	if !ok || message == "" {
+		return message
+	}
+	if translated, ok := catalog[message]; ok {
+		return translated
+	}
+	parts := strings.Split(message, "：")
+	if len(parts) == 1 {
+		return message
+	}
+	for i, part := range parts {
+		if translated, ok := catalog[part]; ok {
+			parts[i] = translated
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+// Join 按语言的习惯拼接多条消息
+func (l *Localizer) Join(locale string, messages []string) string {
+	if locale == LocaleZh {
+		return strings.Join(messages, "；")
+	}
+	return strings.Join(messages, "; ")
+}
+
+// RequestLocale 返回本次请求的语言，未确定时返回默认语言
+func RequestLocale(c *gin.Context) string {
+	if c != nil {
+		if locale := c.GetString(LocaleContextKey); locale != "" {
+			return locale
+		}
+	}
+	return defaultLocalizer.locale
+}
+
+// Localize 使用默认本地化器按本次请求的语言翻译消息
+func Localize(c *gin.Context, message string) string {
+	return defaultLocalizer.Localize(RequestLocale(c), message)
+}
+
+// LocalizeError 返回错误在 locale 下的消息：按语言生成消息的错误（如参数校验）使用生成的消息，其他错误按消息目录翻译
+// 用于把错误写入响应数据（如导入报告中被拒绝的原因），与 HandleError 写出的消息一致；异步任务中传入发起请求时的语言。
+func LocalizeError(locale string, err *ApiError) string {
+	if err.localize != nil {
+		message, _ := err.localize(locale)
+		return message
+	}
+	return defaultLocalizer.Localize(locale, err.Message)
+}
+
+// AcceptLanguageTags 按出现顺序返回 Accept-Language 请求头中的语言标签（小写，忽略权重与 *）
+func AcceptLanguageTags(acceptLanguage string) []string {
+	var tags []string
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		lang := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		if lang != "" && lang != "*" {
+			tags = append(tags, lang)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,125 @@
+package pkgs
+
+// englishMessages 英文消息目录，键为中文原文
+// 包含中间件、数据库错误原因与各模块常见的错误信息，未收录的消息按原文返回。
+var englishMessages = map[string]string{
+	// 通用
+	"服务器内部错误":        "Internal server error",
+	"请求参数错误":         "Invalid request parameters",
+	"请求已取消":          "request canceled",
+	"请求超时":           "request timed out",
+	"数据已存在":          "already exists",
+	"数据仍被引用":         "still referenced",
+	"引用的数据不存在":       "referenced data does not exist",
+	"数据不满足约束":        "constraint violated",
+	"参数格式无效":         "invalid parameter format",
+	"该值已存在":          "The value already exists",
+	"获取影响行数失败":       "Failed to get affected rows",
+	"未启用多租户模式":       "Multi-tenant mode is not enabled",
+	"不支持的接口版本":       "Unsupported API version",
+	"时区参数错误":         "Invalid timezone",
+	"请求过于频繁，请稍后再试":   "Too many requests, please try again later",
+	"游标无效":           "Invalid cursor",
+	"游标分页只支持按创建时间排序": "Cursor pagination only supports ordering by created_at",
+
+	// 认证与权限
+	"未授权":                              "Unauthorized",
+	"无效的令牌":                            "Invalid token",
+	"权限校验失败":                           "Permission denied",
+	"请求头缺少 Authorization 字段":           "Missing Authorization header",
+	"Authorization 字段必须以 'Bearer ' 开头": "Authorization header must start with 'Bearer '",
+	"用户名或密码错误":                         "Incorrect username or password",
+	"账号已停用":                            "Account is disabled",
+	"登录失败":                             "Login failed",
+	"刷新令牌无效":                           "Invalid refresh token",
+	"刷新失败":                             "Failed to refresh token",
+	"令牌已撤销":                            "Token has been revoked",
+	"令牌校验失败":                           "Failed to verify token",
+	"CSRF 令牌校验失败":                      "CSRF token verification failed",
+	"刷新令牌已撤销，请重新登录":                    "Refresh token has been revoked, please log in again",
+	"退出登录失败":                           "Failed to log out",
+	"当前密码错误":                           "Current password is incorrect",
+	"修改密码失败":                           "Failed to change password",
+	"密码已被修改，请重试":                       "Password was changed concurrently, please try again",
+	"查询角色权限矩阵失败":                       "Failed to query the role-permission matrix",
+	"查询权限缓存状态失败":                       "Failed to query the permission cache status",
+	"主库不可用":                            "The primary database is unavailable",
+	"重建权限缓存失败":                         "Failed to rebuild the permission cache",
+	"生成支持包失败":                          "Failed to generate the support bundle",
+	"批量撤销令牌失败":                         "Failed to revoke tokens",
+	"至少指定用户、签发时间、客户端类型中的一个条件": "Specify at least one of users, issue time and client type",
+	"签发时间不能晚于当前时间":            "The issue time cannot be later than now",
+	"重置密码失败":                  "Failed to reset password",
+	"访问文档需要登录":                "Login is required to access the documentation",
+	"无文档访问权限":                 "No permission to access the documentation",
+	"无效的 API 密钥":              "Invalid API key",
+	"API 密钥校验失败":              "Failed to verify API key",
+	"API 密钥的限流等级不存在":          "The rate limit tier of the API key does not exist",
+	"沙箱模式未开启":                 "Sandbox mode is not enabled",
+	"沙箱令牌无权访问该接口":             "The sandbox token cannot access this endpoint",
+	"开启沙箱失败":                  "Failed to start sandbox",
+	"租户标识格式错误":                "Invalid tenant identifier",
+	"租户不存在":                   "Tenant does not exist",
+	"查询租户失败":                  "Failed to query tenant",
+	"连接租户数据库失败":               "Failed to connect to the tenant database",
+
+	// 业务模块
+	"用户不存在":           "User does not exist",
+	"角色不存在":           "Role does not exist",
+	"权限不存在":           "Permission does not exist",
+	"模板不存在":           "Template does not exist",
+	"创建用户失败":          "Failed to create user",
+	"更新用户失败":          "Failed to update user",
+	"创建角色失败":          "Failed to create role",
+	"更新角色失败":          "Failed to update role",
+	"查询角色失败":          "Failed to query roles",
+	"更新权限失败":          "Failed to update permission",
+	"同步权限失败":          "Failed to sync permissions",
+	"分配角色失败":          "Failed to assign roles",
+	"分配权限失败":          "Failed to assign permissions",
+	"撤销会话失败":          "Failed to revoke sessions",
+	"更新模板失败":          "Failed to update template",
+	"删除模板失败":          "Failed to delete template",
+	"只能要求自己拥有的权限":     "You can only require a permission you have",
+	"批量归档模板失败":        "Failed to archive templates",
+	"导出用户失败":          "Failed to export users",
+	"导入用户失败":          "Failed to import users",
+	"导入的行数较多，已转为异步导入": "Too many rows to import at once; the import continues as a background job",
+	"导入任务不存在":         "Import job does not exist",
+	"查询导入任务失败":        "Failed to query the import job",
+	"检查可用性失败":         "Failed to check availability",
+	"用户名、手机号至少传一个":    "Provide at least one username or phone",
+	"查询审计日志失败":        "Failed to query audit logs",
+	"审计记录不存在":         "Audit entry does not exist",
+	"撤销操作失败":          "Failed to revert the operation",
+	"该操作不支持撤销":        "This operation cannot be reverted",
+	"该操作没有可撤销的变化":     "This operation has no changes to revert",
+	"该操作已被撤销":         "This operation has already been reverted",
+	"关键角色的授权变更需要审批，不能直接撤销": "Grant changes on a critical role require approval and cannot be reverted directly",
+	"角色的授权在此之后已被修改，不能撤销":   "The role's grants have changed since, the operation cannot be reverted",
+	"用户的角色在此之后已被修改，不能撤销":   "The user's roles have changed since, the operation cannot be reverted",
+	"父节点不存在":                "Parent node does not exist",
+	"父节点必须是菜单权限":            "The parent node must be a menu permission",
+	"权限下还有子节点，不能改为非菜单类型":    "The permission still has children and cannot change to a non-menu type",
+	"父节点不能是节点自身或其子孙节点":      "The parent node cannot be the node itself or one of its descendants",
+	"移动权限失败":                "Failed to move permission",
+	"查询权限树失败":               "Failed to query the permission tree",
+	"父角色不存在":                "Parent role does not exist",
+	"父角色不能是角色自身或其子孙角色":      "The parent role cannot be the role itself or one of its descendants",
+	"父角色或其祖先角色是关键角色，不能直接继承": "The parent role or one of its ancestors is critical and cannot be inherited directly",
+	"校验父角色失败":               "Failed to validate the parent role",
+	"属性不存在":                 "Attribute does not exist",
+	"属性已存在":                 "Attribute already exists",
+	"不支持关注该实体":              "This entity cannot be watched",
+	"关注失败":                  "Failed to watch the entity",
+	"无权查看该用户":               "You are not allowed to view this user",
+	"查询关注列表失败":              "Failed to query watches",
+	"取消关注失败":                "Failed to unwatch the entity",
+	"密码必须包含字母":              "The password must contain a letter",
+	"密码必须包含数字":              "The password must contain a digit",
+	"设置项不存在":                "Setting does not exist",
+	"设置项取值无效":               "Invalid setting value",
+	"查询运行时设置失败":             "Failed to query settings",
+	"修改运行时设置失败":             "Failed to update settings",
+	"查询设置变更历史失败":            "Failed to query settings history",
+}
@@ -8,20 +8,24 @@ import (
 // NewLogger creates a new zap logger based on the server mode configuration.
 // In debug mode, it uses a human-friendly console encoder.
 // In release mode, it uses a JSON encoder for production environments.
+// All entries pass through the redaction core so passwords and tokens never reach the output.
+// Error entries are also kept in memory (see RecentErrorLogs) for the support bundle.
 func NewLogger(config *Config) (*zap.Logger, error) {
 	var loggerConfig zap.Config
-	
+
 	if config.Server.Mode == "debug" {
 		loggerConfig = zap.NewDevelopmentConfig()
 		loggerConfig.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	} else {
 		loggerConfig = zap.NewProductionConfig()
 	}
-	
-	logger, err := loggerConfig.Build()
+
+	logger, err := loggerConfig.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(NewRedactCore(core), NewRedactCore(&errorLogCore{buffer: recentErrorLogs}))
+	}))
 	if err != nil {
 		return nil, err
 	}
-	
+
 	return logger, nil
-}
\ No newline at end of file
+}
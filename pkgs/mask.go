@@ -0,0 +1,92 @@
+package pkgs
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/mo"
+)
+
+// 查看用户敏感信息（手机号、邮箱明文）的权限编码
+const PermissionCodeViewPII = "user:view_pii"
+
+// 脱敏规则，响应结构体字段通过 mask 标签引用，例如 `mask:"phone"`
+var maskRules = map[string]func(string) string{
+	"phone": MaskPhone,
+	"email": MaskEmail,
+}
+
+// MaskPhone 手机号脱敏：保留前 3 位和后 4 位，例如 13812345678 -> 138****5678
+func MaskPhone(phone string) string {
+	runes := []rune(phone)
+	if len(runes) < 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:3]) + "****" + string(runes[len(runes)-4:])
+}
+
+// MaskEmail 邮箱脱敏：用户名只保留首字符，域名保留，例如 alice@example.com -> a***@example.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return MaskPhone(email)
+	}
+	local := []rune(email[:at])
+	return string(local[0]) + "***" + email[at:]
+}
+
+// MaskFields 按 mask 标签对结构体（含嵌套结构体、指针、切片）中的字符串字段就地脱敏
+func MaskFields(v any) {
+	maskValue(reflect.ValueOf(v))
+}
+
+func maskValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			maskValue(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			maskValue(v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			rule, ok := maskRules[t.Field(i).Tag.Get("mask")]
+			if !ok {
+				maskValue(field)
+				continue
+			}
+			switch {
+			case field.Kind() == reflect.String && field.String() != "":
+				field.SetString(rule(field.String()))
+			case field.Kind() == reflect.Pointer && !field.IsNil() && field.Elem().Kind() == reflect.String:
+				masked := rule(field.Elem().String())
+				ptr := reflect.New(field.Elem().Type())
+				ptr.Elem().SetString(masked)
+				field.Set(ptr)
+			}
+		}
+	}
+}
+
+// MaskPII 响应脱敏步骤：当前用户没有 user:view_pii 权限时，对响应中标记了 mask 标签的字段脱敏
+func MaskPII[T any](c *gin.Context, checker *PermissionChecker) func(res T) mo.Result[T] {
+	return func(res T) mo.Result[T] {
+		canView, err := checker.HasCode(c, PermissionCodeViewPII)
+		if err != nil {
+			return mo.Err[T](NewApiError(http.StatusInternalServerError, "权限校验失败"))
+		}
+		if !canView {
+			MaskFields(&res)
+		}
+		return mo.Ok(res)
+	}
+}
@@ -0,0 +1,140 @@
+package pkgs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/mo"
+)
+
+// JSON Merge Patch（RFC 7386）的媒体类型
+const MergePatchContentType = "application/merge-patch+json"
+
+// MergePatch 记录 JSON Merge Patch 文档中出现的顶层字段
+// 嵌入到 PATCH 请求体中使用：字段缺省表示不修改，显式 null 表示清空，其它值表示替换（对象按 RFC 7386 递归合并）。
+type MergePatch struct {
+	fields map[string]json.RawMessage
+}
+
+func (p *MergePatch) setMergePatchFields(fields map[string]json.RawMessage) {
+	p.fields = fields
+}
+
+// Has 字段是否出现在补丁文档中
+func (p *MergePatch) Has(key string) bool {
+	_, ok := p.fields[key]
+	return ok
+}
+
+// IsNull 字段是否显式传了 null
+func (p *MergePatch) IsNull(key string) bool {
+	raw, ok := p.fields[key]
+	return ok && isJSONNull(raw)
+}
+
+// Raw 返回字段在补丁文档中的原始 JSON
+func (p *MergePatch) Raw(key string) json.RawMessage {
+	return p.fields[key]
+}
+
+// Empty 补丁文档是否没有任何字段
+func (p *MergePatch) Empty() bool {
+	return len(p.fields) == 0
+}
+
+// Document 返回补丁文档，保留显式 null，可由 DecodeMergePatch 还原
+func (p *MergePatch) Document() (json.RawMessage, error) {
+	if p.fields == nil {
+		return json.RawMessage("{}"), nil
+	}
+	return json.Marshal(p.fields)
+}
+
+// NotNull 不可为空的字段显式传 null 时返回违规信息，供 RegisterRule 注册的校验规则使用
+func (p *MergePatch) NotNull(field, label string) []Violation {
+	if !p.IsNull(field) {
+		return nil
+	}
+	return []Violation{{Field: field, Message: label + "不能为空"}}
+}
+
+type mergePatchCarrier interface {
+	setMergePatchFields(map[string]json.RawMessage)
+}
+
+// BindUriAndMergePatch 绑定路径参数和 JSON Merge Patch 请求体
+// 请求体必须是 JSON 对象；T 需要嵌入 MergePatch 以记录出现的字段
+func BindUriAndMergePatch[T any](c *gin.Context) mo.Result[*T] {
+	body, err := c.GetRawData()
+	if err != nil {
+		return mo.Err[*T](NewApiError(http.StatusBadRequest, err.Error()))
+	}
+	req, err := DecodeMergePatch[T](body)
+	if err != nil {
+		return mo.Err[*T](NewApiError(http.StatusBadRequest, err.Error()))
+	}
+	// 路径参数最后绑定，避免被请求体中的同名字段覆盖
+	if err := bindUri(c, req); err != nil {
+		return mo.Err[*T](err)
+	}
+	return mo.Ok(req)
+}
+
+// DecodeMergePatch 解析 JSON Merge Patch 文档，用于暂存后重新执行的补丁（见 MergePatch.Document）
+func DecodeMergePatch[T any](doc []byte) (*T, error) {
+	var req T
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil || fields == nil {
+		return nil, errors.New("请求体必须是 JSON 对象")
+	}
+	if err := json.Unmarshal(doc, &req); err != nil {
+		return nil, err
+	}
+	if carrier, ok := any(&req).(mergePatchCarrier); ok {
+		carrier.setMergePatchFields(fields)
+	}
+	return &req, nil
+}
+
+// ApplyMergePatch 按 RFC 7386 将补丁合并到目标 JSON 文档并返回结果
+// 补丁为对象时逐字段递归合并（null 删除字段），否则整体替换目标
+func ApplyMergePatch(target, patch []byte) ([]byte, error) {
+	var patchValue any
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("解析补丁失败: %w", err)
+	}
+	var targetValue any
+	if len(bytes.TrimSpace(target)) > 0 {
+		if err := json.Unmarshal(target, &targetValue); err != nil {
+			return nil, fmt.Errorf("解析目标文档失败: %w", err)
+		}
+	}
+	return json.Marshal(mergePatchValue(targetValue, patchValue))
+}
+
+func mergePatchValue(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatchValue(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
@@ -0,0 +1,15 @@
+package pkgs
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus 指标采集路径，由采集端直接访问，不经过 JWT 鉴权
+// 各组件的指标通过 promauto 注册到默认注册表
+const MetricsPath = "/metrics"
+
+// MetricsHandler 返回 Prometheus 指标接口
+func MetricsHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
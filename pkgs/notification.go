@@ -0,0 +1,77 @@
+package pkgs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 发送通知的异步任务类型
+const JobTypeNotification = "notification.send"
+
+// Notification 发送给用户的通知
+type Notification struct {
+	Type string `json:"type"`
+	// 接收通知的用户ID
+	Recipients []string       `json:"recipients"`
+	Title      string         `json:"title"`
+	Body       string         `json:"body"`
+	Data       map[string]any `json:"data,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// Notifier 通知发送
+// Notify 只把通知写入异步任务队列，由任务轮询投递，投递失败时按任务队列的规则重试；
+// 配置 notification.webhook_url 时以 JSON POST 到该地址（由外部服务转发为邮件、IM 消息等），否则只记录日志。
+type Notifier struct {
+	jobs    *JobQueue
+	client  *http.Client
+	url     string
+	headers map[string]string
+	logger  *zap.Logger
+}
+
+func NewNotifier(config *Config, jobs *JobQueue, logger *zap.Logger) *Notifier {
+	n := &Notifier{
+		jobs:    jobs,
+		client:  &http.Client{Timeout: config.Notification.Timeout},
+		url:     config.Notification.WebhookURL,
+		headers: config.Notification.Headers,
+		logger:  logger,
+	}
+	jobs.Register(JobTypeNotification, n.deliver)
+	return n
+}
+
+// Notify 在当前请求的租户下写入通知投递任务
+func (n *Notifier) Notify(c *gin.Context, notification Notification) error {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now().UTC()
+	}
+	_, err := n.jobs.Enqueue(c, JobTypeNotification, notification)
+	return err
+}
+
+// deliver 投递通知任务
+func (n *Notifier) deliver(ctx context.Context, _ *sqlx.DB, job *Job, logger *zap.Logger) error {
+	var notification Notification
+	if err := json.Unmarshal(job.Payload, &notification); err != nil {
+		return fmt.Errorf("解析通知失败: %w", err)
+	}
+	if n.url == "" {
+		logger.Info("通知", zap.String("type", notification.Type), zap.Strings("recipients", notification.Recipients), zap.String("title", notification.Title))
+		return nil
+	}
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, n.url, "application/json", n.headers, bytes.NewReader(body))
+}
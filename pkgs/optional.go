@@ -0,0 +1,94 @@
+package pkgs
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Optional 更新请求中可清空的字段，区分三种状态：缺省表示不修改，显式 null 表示清空，其它值表示替换
+// 在请求结构体中声明为 Description Optional[string] `json:"description,omitzero"`；
+// 仓储构建 SET 子句时用 IsSet 判断是否更新该列，用 Ptr 取得写入的值（清空时为 nil）。
+// 常用类型（见 NewRequestValidator）的 validate 标签作用于传入的值，缺省和 null 视为空值；其它类型需调用 RegisterOptional。
+type Optional[T any] struct {
+	value T
+	set   bool
+	null  bool
+}
+
+// Some 返回传值的 Optional
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// Null 返回显式 null 的 Optional
+func Null[T any]() Optional[T] {
+	return Optional[T]{set: true, null: true}
+}
+
+// IsSet 字段是否出现在请求体中（包括显式 null）
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsNull 字段是否显式传了 null
+func (o Optional[T]) IsNull() bool {
+	return o.set && o.null
+}
+
+// Get 返回传入的值，缺省或 null 时 ok 为 false
+func (o Optional[T]) Get() (value T, ok bool) {
+	return o.value, o.set && !o.null
+}
+
+// Ptr 返回传入值的指针，缺省或 null 时为 nil，可直接作为可空列的参数
+func (o Optional[T]) Ptr() *T {
+	if !o.set || o.null {
+		return nil
+	}
+	value := o.value
+	return &value
+}
+
+// IsZero 缺省时为零值，配合 omitzero 在序列化时省略
+func (o Optional[T]) IsZero() bool {
+	return !o.set
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	*o = Optional[T]{set: true}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set || o.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// validationValue 返回参与 validate 标签校验的值，缺省和 null 为 nil 指针（omitempty 跳过校验），结构体按字段标签校验
+func (o Optional[T]) validationValue() any {
+	return o.Ptr()
+}
+
+type optionalValue interface {
+	validationValue() any
+}
+
+// optionalValidationValue 供校验器的 CustomTypeFunc 使用
+func optionalValidationValue(field reflect.Value) any {
+	if o, ok := field.Interface().(optionalValue); ok {
+		return o.validationValue()
+	}
+	return nil
+}
+
+// RegisterOptional 让 Optional[T] 字段的 validate 标签作用于传入的值
+func RegisterOptional[T any](v *RequestValidator) {
+	v.validate.RegisterCustomTypeFunc(optionalValidationValue, Optional[T]{})
+}
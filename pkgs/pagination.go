@@ -0,0 +1,52 @@
+package pkgs
+
+import (
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Pagination 列表接口的分页参数，嵌入查询请求结构体使用
+// 页码与每页大小的默认值和上限只在这里声明，各模块不再各自定义。
+type Pagination struct {
+	Page     int `form:"page,default=1" validate:"min=1" label:"页码"`
+	PageSize int `form:"pageSize,default=10" validate:"min=1,max=100" label:"每页大小"`
+}
+
+// Offset 返回当前页的偏移量
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// 排序方向的校验标签，不区分大小写的 asc 或 desc
+// 可排序字段因接口而异，在请求结构体的 orderBy 字段上用 oneof 声明，例如：
+//
+//	OrderBy string `form:"orderBy,default=created_at" validate:"oneof=id name created_at updated_at" label:"排序字段"`
+//	Order   string `form:"order,default=desc" validate:"sort_order" label:"排序顺序"`
+const sortOrderTag = "sort_order"
+
+// SortDirection 返回可直接拼接到 SQL 中的排序方向（ASC 或 DESC），参数须已通过 sort_order 校验
+func SortDirection(order string) string {
+	if strings.EqualFold(order, "asc") {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// registerSortOrder 注册 sort_order 校验标签及其各语言的提示
+func registerSortOrder(validate *validator.Validate, translators map[string]ut.Translator) {
+	_ = validate.RegisterValidation(sortOrderTag, func(fl validator.FieldLevel) bool {
+		order := fl.Field().String()
+		return strings.EqualFold(order, "asc") || strings.EqualFold(order, "desc")
+	})
+	messages := map[string]string{LocaleZh: "{0}必须是asc或desc", LocaleEn: "{0} must be asc or desc"}
+	for locale, trans := range translators {
+		_ = validate.RegisterTranslation(sortOrderTag, trans, func(ut ut.Translator) error {
+			return ut.Add(sortOrderTag, messages[locale], true)
+		}, func(ut ut.Translator, fe validator.FieldError) string {
+			message, _ := ut.T(sortOrderTag, fe.Field())
+			return message
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package pkgs
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 密码哈希算法
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+// 从旧系统迁移来的密码格式，只用于校验，登录成功后按当前配置重新哈希
+// 导入时需加上前缀：明文为 $plain$<密码>，MD5 为 $md5$<32 位十六进制>，加盐的 MD5 为 $md5$<盐>$<md5(盐+密码)>
+const (
+	PasswordLegacyPlain = "plain"
+	PasswordLegacyMD5   = "md5"
+)
+
+// 哈希值无法识别或账号未设置密码时的格式
+const (
+	PasswordSchemeUnknown = "unknown"
+	PasswordSchemeNone    = "none"
+)
+
+var (
+	passwordHashes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "user_password_hashes",
+		Help: "Users by password hash scheme (bcrypt, argon2id, md5, plain, unknown, none) across all tenant schemas; md5 and plain are legacy hashes waiting to be rehashed on login.",
+	}, []string{"scheme"})
+	passwordRehashes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "user_password_rehashes_total",
+		Help: "Password hashes upgraded to the configured scheme after a successful login, by previous scheme.",
+	}, []string{"from"})
+)
+
+// argon2id 的盐与哈希长度（字节）
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// bcrypt 只使用密码的前 72 字节，超出部分会被 bcrypt.GenerateFromPassword 拒绝
+const bcryptMaxPasswordLength = 72
+
+// ErrPasswordTooLong 密码超过当前算法支持的长度
+var ErrPasswordTooLong = errors.New("密码过长")
+
+// PasswordHasher 用户密码哈希与校验
+// 新密码按配置的算法哈希；校验时按哈希值的格式（$2a$、$argon2id$ 等）识别算法，
+// 因此更换算法或调整参数后已有密码仍可校验，NeedsRehash 用于在登录成功时升级旧哈希。
+// 配置 legacy_formats 后还可校验从旧系统迁移来的明文与 MD5 密码，这些密码总是需要重新哈希。
+// 创建后注册为包级默认实例，供初始化数据、测试工具等无法注入依赖的地方使用。
+type PasswordHasher struct {
+	config PasswordHashConfig
+}
+
+var defaultPasswordHasher atomic.Pointer[PasswordHasher]
+
+// NewPasswordHasher 根据配置创建密码哈希器，并注册为默认实例
+func NewPasswordHasher(config *Config) (*PasswordHasher, error) {
+	if err := config.PasswordHash.Validate(); err != nil {
+		return nil, err
+	}
+	h := &PasswordHasher{config: config.PasswordHash}
+	defaultPasswordHasher.Store(h)
+	return h, nil
+}
+
+// HashPassword 使用默认实例哈希密码，未创建实例时使用 bcrypt 默认成本
+func HashPassword(password string) (string, error) {
+	h := defaultPasswordHasher.Load()
+	if h == nil {
+		h = &PasswordHasher{config: PasswordHashConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.DefaultCost}}
+	}
+	return h.Hash(password)
+}
+
+// Hash 按配置的算法哈希密码，结果包含算法、参数与盐，可以直接保存
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	switch h.config.Algorithm {
+	case PasswordAlgorithmArgon2id:
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		c := h.config
+		key := argon2.IDKey([]byte(password), salt, c.Argon2Time, c.Argon2Memory, c.Argon2Threads, argon2KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, c.Argon2Memory, c.Argon2Time, c.Argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	default:
+		if len(password) > bcryptMaxPasswordLength {
+			return "", ErrPasswordTooLong
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+}
+
+// MaxPasswordBytes 返回配置的算法支持的最大密码字节数，0 表示不限制；为 nil 时不限制
+func (h *PasswordHasher) MaxPasswordBytes() int {
+	if h == nil || h.config.Algorithm == PasswordAlgorithmArgon2id {
+		return 0
+	}
+	return bcryptMaxPasswordLength
+}
+
+// Verify 校验密码是否与哈希值匹配，无法识别的哈希格式视为不匹配
+func (h *PasswordHasher) Verify(hash, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, ok := parseArgon2Hash(hash)
+		if !ok {
+			return false
+		}
+		actual := argon2.IDKey([]byte(password), salt, params.Argon2Time, params.Argon2Memory, params.Argon2Threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(actual, key) == 1
+	}
+	if isBcryptHash(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	scheme := PasswordHashScheme(hash)
+	if !slices.Contains(h.config.LegacyFormats, scheme) {
+		return false
+	}
+	switch scheme {
+	case PasswordLegacyPlain:
+		stored := strings.TrimPrefix(hash, "$plain$")
+		return stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+	case PasswordLegacyMD5:
+		salt, digest := "", strings.TrimPrefix(hash, "$md5$")
+		if i := strings.LastIndex(digest, "$"); i >= 0 {
+			salt, digest = digest[:i], digest[i+1:]
+		}
+		expected, err := hex.DecodeString(digest)
+		if err != nil || len(expected) != md5.Size {
+			return false
+		}
+		actual := md5.Sum([]byte(salt + password))
+		return subtle.ConstantTimeCompare(actual[:], expected) == 1
+	}
+	return false
+}
+
+// NeedsRehash 判断哈希值的算法或参数是否与当前配置不同，需要在下次登录成功时重新哈希
+func (h *PasswordHasher) NeedsRehash(hash string) bool {
+	switch h.config.Algorithm {
+	case PasswordAlgorithmArgon2id:
+		params, _, _, ok := parseArgon2Hash(hash)
+		return !ok || params.Argon2Time != h.config.Argon2Time || params.Argon2Memory != h.config.Argon2Memory || params.Argon2Threads != h.config.Argon2Threads
+	default:
+		if !isBcryptHash(hash) {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.config.BcryptCost
+	}
+}
+
+// PasswordHashScheme 按哈希值的前缀识别格式：bcrypt、argon2id、md5、plain，其余为 unknown
+func PasswordHashScheme(hash string) string {
+	switch {
+	case isBcryptHash(hash):
+		return PasswordAlgorithmBcrypt
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return PasswordAlgorithmArgon2id
+	case strings.HasPrefix(hash, "$md5$"):
+		return PasswordLegacyMD5
+	case strings.HasPrefix(hash, "$plain$"):
+		return PasswordLegacyPlain
+	default:
+		return PasswordSchemeUnknown
+	}
+}
+
+// PasswordSchemeSQL 返回按前缀识别密码格式的 SQL 表达式，与 PasswordHashScheme 一致，未设置密码时为 none
+func PasswordSchemeSQL(column string) string {
+	return `CASE WHEN ` + column + ` IS NULL THEN '` + PasswordSchemeNone + `'` +
+		` WHEN ` + column + ` LIKE '$2a$%' OR ` + column + ` LIKE '$2b$%' OR ` + column + ` LIKE '$2y$%' THEN '` + PasswordAlgorithmBcrypt + `'` +
+		` WHEN ` + column + ` LIKE '$argon2id$%' THEN '` + PasswordAlgorithmArgon2id + `'` +
+		` WHEN ` + column + ` LIKE '$md5$%' THEN '` + PasswordLegacyMD5 + `'` +
+		` WHEN ` + column + ` LIKE '$plain$%' THEN '` + PasswordLegacyPlain + `'` +
+		` ELSE '` + PasswordSchemeUnknown + `' END`
+}
+
+// SetPasswordHashCounts 更新各密码格式的用户数指标，counts 为所有租户 schema 的合计
+func SetPasswordHashCounts(counts map[string]int) {
+	passwordHashes.Reset()
+	for scheme, n := range counts {
+		passwordHashes.WithLabelValues(scheme).Set(float64(n))
+	}
+}
+
+// RecordPasswordRehash 记录一次登录后的密码重新哈希，hash 为旧的哈希值
+func RecordPasswordRehash(hash string) {
+	passwordRehashes.WithLabelValues(PasswordHashScheme(hash)).Inc()
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// parseArgon2Hash 解析 $argon2id$v=19$m=65536,t=3,p=4$<盐>$<哈希> 格式的哈希值
+func parseArgon2Hash(hash string) (params PasswordHashConfig, salt, key []byte, ok bool) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return params, nil, nil, false
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Time, &params.Argon2Threads); err != nil {
+		return params, nil, nil, false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, false
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 || params.Argon2Threads == 0 || params.Argon2Time == 0 {
+		return params, nil, nil, false
+	}
+	return params, salt, key, true
+}
+
+// PasswordPolicy 新密码的安全策略，创建用户、修改与重置密码时校验，已有密码不受影响
+// 默认取值来自配置文件 password_policy，可通过 /v1/admin/settings 在线调整（见 Settings）。
+type PasswordPolicy struct {
+	// 最短长度（字符数）
+	MinLength     int  `mapstructure:"min_length" json:"min_length"`
+	RequireLetter bool `mapstructure:"require_letter" json:"require_letter"`
+	RequireDigit  bool `mapstructure:"require_digit" json:"require_digit"`
+}
+
+// Validate 校验最短长度：与 Check 一样按字符计数，不能超过 bcrypt 支持的 72 字节，
+// 否则使用 bcrypt 时满足最短长度的密码（全部为单字节字符时也）会超过上限，无法设置任何密码
+func (p PasswordPolicy) Validate() error {
+	if p.MinLength < 1 || p.MinLength > bcryptMaxPasswordLength {
+		return fmt.Errorf("min_length must be between 1 and %d, got %d", bcryptMaxPasswordLength, p.MinLength)
+	}
+	return nil
+}
+
+// Check 校验新密码是否满足策略，不满足时返回 400
+// 最短长度按字符计数；hasher 使用 bcrypt 时密码还不能超过 72 字节（多字节字符按字节计），否则哈希时才失败
+func (p PasswordPolicy) Check(password string, hasher *PasswordHasher) *ApiError {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return NewApiError(http.StatusBadRequest, fmt.Sprintf("密码长度不能少于 %d 位", p.MinLength))
+	}
+	if limit := hasher.MaxPasswordBytes(); limit > 0 && len(password) > limit {
+		return NewApiError(http.StatusBadRequest, fmt.Sprintf("密码不能超过 %d 字节", limit))
+	}
+	if p.RequireLetter && !strings.ContainsFunc(password, unicode.IsLetter) {
+		return NewApiError(http.StatusBadRequest, "密码必须包含字母")
+	}
+	if p.RequireDigit && !strings.ContainsFunc(password, unicode.IsDigit) {
+		return NewApiError(http.StatusBadRequest, "密码必须包含数字")
+	}
+	return nil
+}
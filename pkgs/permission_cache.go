@@ -0,0 +1,335 @@
+package pkgs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// APIPermission 用户拥有的权限：接口权限为 method + path，编码类权限为 code；Deny 为拒绝规则
+// Conditions 为授予该权限的角色的访问条件，由 PermissionChecker 在每次请求时判断是否生效
+type APIPermission struct {
+	Method     string            `json:"method,omitempty"`
+	Path       string            `json:"path,omitempty"`
+	Code       string            `json:"code,omitempty"`
+	Deny       bool              `json:"deny,omitempty"`
+	Conditions *AccessConditions `json:"conditions,omitempty"`
+}
+
+var (
+	permissionCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "permission_cache_lookups_total",
+		Help: "Permission cache lookups by result (hit, miss, error).",
+	}, []string{"result"})
+	permissionCacheFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "permission_cache_fallbacks_total",
+		Help: "Permission checks served directly from the database because the cache was unavailable, by reason (error, circuit_open).",
+	}, []string{"reason"})
+	permissionCacheCircuitOpen = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "permission_cache_circuit_open",
+		Help: "1 when the permission cache circuit breaker is open or half-open, 0 when closed.",
+	})
+)
+
+// PermissionCache 用户接口权限缓存（Redis）
+// 缓存键包含租户的权限版本号，角色授权、用户分配角色等修改权限关系的操作通过 Invalidate 递增版本号，
+// 使该租户下所有用户的缓存同时失效。
+// Redis 不可用时降级为直接查询数据库而不是让请求失败：连续失败达到阈值后熔断，冷却期内不再访问 Redis。
+// 版本号递增失败时旧缓存最多保留 TTL 时长。
+// 其他实例递增的版本号在读取缓存时发现，同样调用失效钩子，使各实例的本地缓存（如 PermissionMatcher）随之过期。
+type PermissionCache struct {
+	client  *redis.Client
+	ttl     time.Duration
+	timeout time.Duration
+	breaker *CircuitBreaker
+	logger  *zap.Logger
+	// 失效时同步调用的钩子，用于同样依赖权限数据的本地缓存
+	hooks []func(tenant string)
+
+	// 本实例启动以来的查询统计，见 Status
+	hits, misses, errors atomic.Int64
+
+	mu sync.Mutex
+	// 本实例最近看到的各租户版本号
+	generations map[string]int64
+}
+
+// PermissionCacheStatus 权限缓存的状态，命中统计为本实例启动以来的数据
+type PermissionCacheStatus struct {
+	Enabled bool
+	Circuit string
+	TTL     time.Duration
+	// 租户当前的版本号与最近一次失效的时间，从未失效时 InvalidatedAt 为零值
+	Generation    int64
+	InvalidatedAt time.Time
+	// 当前版本号下缓存的用户数，以及旧版本号下尚未过期的用户数（已失效、不会再被读取）
+	Entries      int64
+	StaleEntries int64
+	Hits         int64
+	Misses       int64
+	Errors       int64
+}
+
+// HitRate 命中率，没有查询时为 0
+func (s PermissionCacheStatus) HitRate() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+func NewPermissionCache(config *Config, client *redis.Client, logger *zap.Logger) *PermissionCache {
+	ttl := config.PermissionCache.TTL
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	timeout := config.PermissionCache.Timeout
+	if timeout <= 0 {
+		timeout = 100 * time.Millisecond
+	}
+	cooldown := config.PermissionCache.Cooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &PermissionCache{
+		client:  client,
+		ttl:     ttl,
+		timeout: timeout,
+		breaker: NewCircuitBreaker(config.PermissionCache.FailureThreshold, cooldown),
+		logger:  logger,
+
+		generations: map[string]int64{},
+	}
+}
+
+// Enabled 是否配置了 Redis
+func (p *PermissionCache) Enabled() bool {
+	return p.client != nil
+}
+
+// BreakerState 返回熔断器状态
+func (p *PermissionCache) BreakerState() string {
+	return p.breaker.State()
+}
+
+// Load 返回用户的接口权限，缓存未命中时调用 load 查询数据库并写入缓存
+// 缓存不可用（未配置、熔断、Redis 出错）时直接返回 load 的结果，只有 load 出错才返回错误
+func (p *PermissionCache) Load(c *gin.Context, userID string, load func() ([]APIPermission, error)) ([]APIPermission, error) {
+	if !p.Enabled() {
+		return load()
+	}
+	if !p.breaker.Allow() {
+		permissionCacheFallbacks.WithLabelValues("circuit_open").Inc()
+		return load()
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), p.timeout)
+	defer cancel()
+	tenant := TenantFromContext(c)
+
+	generation, err := p.generation(ctx, tenant)
+	if err != nil {
+		p.fail(err)
+		return load()
+	}
+	p.observe(tenant, generation)
+	key := p.userKey(tenant, generation, userID)
+
+	data, err := p.client.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		var perms []APIPermission
+		if err := json.Unmarshal(data, &perms); err == nil {
+			p.succeed()
+			p.hits.Add(1)
+			permissionCacheLookups.WithLabelValues("hit").Inc()
+			return perms, nil
+		}
+		// 缓存内容损坏时按未命中处理，重新写入
+	case errors.Is(err, redis.Nil):
+	default:
+		p.fail(err)
+		return load()
+	}
+
+	p.succeed()
+	p.misses.Add(1)
+	permissionCacheLookups.WithLabelValues("miss").Inc()
+	perms, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(perms); err == nil {
+		// 写入失败不影响本次结果
+		if err := p.client.Set(ctx, key, data, p.ttl).Err(); err != nil {
+			p.markFailure(err)
+		}
+	}
+	return perms, nil
+}
+
+// Invalidate 使当前租户下所有用户的权限缓存失效
+func (p *PermissionCache) Invalidate(c *gin.Context) {
+	p.InvalidateTenant(c.Request.Context(), TenantFromContext(c))
+}
+
+// InvalidateTenant 使指定租户下所有用户的权限缓存失效，用于异步任务等没有请求上下文的场景
+func (p *PermissionCache) InvalidateTenant(ctx context.Context, tenant string) {
+	if _, err := p.Rebuild(ctx, tenant); err != nil {
+		p.logger.Warn("权限缓存失效失败，旧缓存将在过期后刷新", zap.Duration("ttl", p.ttl), zap.Error(err))
+		p.markFailure(err)
+	}
+}
+
+// Rebuild 使指定租户下所有用户的权限缓存失效并返回新的版本号，失败时返回错误而不是只记录日志
+// 用于运维在直接修改数据库后强制刷新；未配置 Redis 时只调用失效钩子，版本号为 0。
+func (p *PermissionCache) Rebuild(ctx context.Context, tenant string) (int64, error) {
+	for _, hook := range p.hooks {
+		hook(tenant)
+	}
+	if !p.Enabled() {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	var generation *redis.IntCmd
+	_, err := p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		generation = pipe.Incr(ctx, p.generationKey(tenant))
+		pipe.Set(ctx, p.invalidatedAtKey(tenant), time.Now().UnixMilli(), 0)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	// 本实例已调用过钩子，读取缓存时不再重复调用
+	p.observe(tenant, generation.Val())
+	return generation.Val(), nil
+}
+
+// Status 返回权限缓存与指定租户的状态，统计缓存条目需要遍历该租户的键，只用于运维接口
+// 未配置 Redis 或熔断时只返回本实例的统计
+func (p *PermissionCache) Status(ctx context.Context, tenant string) (PermissionCacheStatus, error) {
+	status := PermissionCacheStatus{
+		Enabled: p.Enabled(),
+		Circuit: p.breaker.State(),
+		TTL:     p.ttl,
+		Hits:    p.hits.Load(),
+		Misses:  p.misses.Load(),
+		Errors:  p.errors.Load(),
+	}
+	if !p.Enabled() || status.Circuit != CircuitClosed {
+		return status, nil
+	}
+
+	generation, err := p.generation(ctx, tenant)
+	if err != nil {
+		return status, err
+	}
+	status.Generation = generation
+	invalidatedAt, err := p.client.Get(ctx, p.invalidatedAtKey(tenant)).Int64()
+	switch {
+	case err == nil:
+		status.InvalidatedAt = time.UnixMilli(invalidatedAt)
+	case !errors.Is(err, redis.Nil):
+		return status, err
+	}
+
+	current := p.userKey(tenant, generation, "")
+	iter := p.client.Scan(ctx, 0, "permission:"+tenant+":*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		switch {
+		case key == p.generationKey(tenant) || key == p.invalidatedAtKey(tenant):
+		case strings.HasPrefix(key, current):
+			status.Entries++
+		default:
+			status.StaleEntries++
+		}
+	}
+	return status, iter.Err()
+}
+
+// OnInvalidate 注册失效钩子，应在构造函数中注册（不支持并发注册），未配置 Redis 时同样调用
+func (p *PermissionCache) OnInvalidate(hook func(tenant string)) {
+	p.hooks = append(p.hooks, hook)
+}
+
+// InvalidatePermissionCache 返回在结果成功后使权限缓存失效的管道步骤
+// 用于角色授权、用户分配角色、修改接口权限等会改变用户权限的接口，放在仓储操作（事务已提交）之后
+func InvalidatePermissionCache[T any](c *gin.Context, cache *PermissionCache) func(T) T {
+	return func(v T) T {
+		cache.Invalidate(c)
+		return v
+	}
+}
+
+func (p *PermissionCache) generation(ctx context.Context, tenant string) (int64, error) {
+	generation, err := p.client.Get(ctx, p.generationKey(tenant)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return generation, err
+}
+
+func (p *PermissionCache) generationKey(tenant string) string {
+	return "permission:" + tenant + ":generation"
+}
+
+func (p *PermissionCache) invalidatedAtKey(tenant string) string {
+	return "permission:" + tenant + ":invalidated_at"
+}
+
+func (p *PermissionCache) userKey(tenant string, generation int64, userID string) string {
+	return "permission:" + tenant + ":" + strconv.FormatInt(generation, 10) + ":" + userID
+}
+
+func (p *PermissionCache) succeed() {
+	p.breaker.Success()
+	permissionCacheCircuitOpen.Set(0)
+}
+
+// observe 记录读取到的版本号，版本号被其他实例修改时调用失效钩子
+func (p *PermissionCache) observe(tenant string, generation int64) {
+	p.mu.Lock()
+	seen, ok := p.generations[tenant]
+	if ok && seen == generation {
+		p.mu.Unlock()
+		return
+	}
+	p.generations[tenant] = generation
+	p.mu.Unlock()
+	// 首次读取时本地缓存同样是新建的，无需失效
+	if !ok {
+		return
+	}
+	for _, hook := range p.hooks {
+		hook(tenant)
+	}
+}
+
+// fail 记录一次读取失败，本次请求降级为查询数据库
+func (p *PermissionCache) fail(err error) {
+	p.errors.Add(1)
+	permissionCacheLookups.WithLabelValues("error").Inc()
+	permissionCacheFallbacks.WithLabelValues("error").Inc()
+	p.markFailure(err)
+}
+
+func (p *PermissionCache) markFailure(err error) {
+	p.breaker.Failure()
+	if p.breaker.State() != CircuitClosed {
+		permissionCacheCircuitOpen.Set(1)
+	}
+	p.logger.Warn("权限缓存不可用，降级为查询数据库", zap.String("circuit", p.breaker.State()), zap.Error(err))
+}
@@ -0,0 +1,300 @@
+package pkgs
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 角色与权限关联的效果，对应 iacc_role_permission.effect
+const (
+	RolePermissionAllow = "allow"
+	RolePermissionDeny  = "deny"
+)
+
+// context 中缓存当前用户权限集合的键，同一请求内只解析一次
+const (
+	permissionsContextKey         = "user_permissions"
+	inactivePermissionsContextKey = "user_inactive_permissions"
+)
+
+// inactivePermission 因角色访问条件不满足而在本次请求中不生效的权限
+type inactivePermission struct {
+	APIPermission
+	reason *ApiError
+}
+
+// PermissionChecker 用户权限解析与编码类权限校验
+// 接口权限由 PermissionMiddleware 按 method+path 校验；数据/字段级权限（如查看敏感信息）
+// 通过权限元数据中的 code 标识，由业务代码调用 HasCode 判断当前用户是否拥有。
+// 两者共用 Permissions 解析出的同一份权限集合：一条 CTE 查询得到去重后的全部权限，结果缓存在 PermissionCache 中。
+type PermissionChecker struct {
+	pool   *TenantPool
+	tables *TableNames
+	logger *zap.Logger
+	cache  *PermissionCache
+
+	// 每个连接池（默认库与各租户 schema）各自预编译一次解析语句
+	mu    sync.Mutex
+	stmts map[*sqlx.DB]*sqlx.Stmt
+}
+
+func NewPermissionChecker(pool *TenantPool, tables *TableNames, logger *zap.Logger, cache *PermissionCache) *PermissionChecker {
+	return &PermissionChecker{
+		pool:   pool,
+		tables: tables,
+		logger: logger,
+		cache:  cache,
+		stmts:  map[*sqlx.DB]*sqlx.Stmt{},
+	}
+}
+
+// Permissions 返回当前登录用户在本次请求中生效的全部权限，未登录时返回空
+// 所属角色的访问条件（时间段、IP 段）不满足的授予规则不返回，可通过 Denial 查询原因；拒绝规则不受访问条件影响。
+// 同一请求内结果缓存在 context 中，跨请求由 PermissionCache 缓存（缓存的是未经条件筛选的权限）
+func (p *PermissionChecker) Permissions(c *gin.Context) ([]APIPermission, error) {
+	if v, ok := c.Get(permissionsContextKey); ok {
+		return v.([]APIPermission), nil
+	}
+
+	uid := CurrentUserID(c)
+	if uid == "" {
+		return nil, nil
+	}
+
+	perms, err := p.cache.Load(c, uid, func() ([]APIPermission, error) {
+		return p.Resolve(c.Request.Context(), p.pool.DB(c), uid)
+	})
+	if err != nil {
+		RequestLogger(c, p.logger).Error("查询用户权限失败", zap.Error(err))
+		return nil, err
+	}
+
+	now, ip := time.Now(), c.ClientIP()
+	active := make([]APIPermission, 0, len(perms))
+	var inactive []inactivePermission
+	for _, perm := range perms {
+		// 访问条件只限制授予规则，拒绝规则始终生效，否则在条件之外反而不再拒绝
+		if !perm.Deny {
+			if reason := perm.Conditions.Check(now, ip); reason != nil {
+				inactive = append(inactive, inactivePermission{APIPermission: perm, reason: reason})
+				continue
+			}
+		}
+		active = append(active, perm)
+	}
+	c.Set(permissionsContextKey, active)
+	c.Set(inactivePermissionsContextKey, inactive)
+	return active, nil
+}
+
+// Denial 在权限校验未通过时调用：用户的某个角色授予了该权限、只是角色的访问条件不满足时，
+// 返回访问条件对应的业务码错误，否则返回 nil（按无权限处理）
+func (p *PermissionChecker) Denial(c *gin.Context, match func(APIPermission) bool) *ApiError {
+	v, _ := c.Get(inactivePermissionsContextKey)
+	inactive, _ := v.([]inactivePermission)
+	for _, perm := range inactive {
+		if !perm.Deny && match(perm.APIPermission) {
+			return perm.reason
+		}
+	}
+	return nil
+}
+
+// HasCode 判断当前登录用户是否拥有指定编码的权限，支持 user:* 形式的通配授予与拒绝
+func (p *PermissionChecker) HasCode(c *gin.Context, code string) (bool, error) {
+	perms, err := p.Permissions(c)
+	if err != nil {
+		return false, err
+	}
+	return CodeAllowed(perms, code), nil
+}
+
+// AllowedCodes 返回 codes 中当前登录用户拥有的权限编码，判断规则与 HasCode 相同，未登录时返回空
+func (p *PermissionChecker) AllowedCodes(c *gin.Context, codes []string) ([]string, error) {
+	perms, err := p.Permissions(c)
+	if err != nil {
+		return nil, err
+	}
+	allowed := []string{}
+	for _, code := range codes {
+		if CodeAllowed(perms, code) {
+			allowed = append(allowed, code)
+		}
+	}
+	return allowed, nil
+}
+
+// HasAPI 判断当前登录用户是否拥有接口权限，path 为具体的请求路径（如 /v1/user/<id>），匹配规则与 PermissionMiddleware 相同
+// 用于在接口之外代替接口校验，如关注实体前确认用户可以查看该实体
+func (p *PermissionChecker) HasAPI(c *gin.Context, method, path string) (bool, error) {
+	perms, err := p.Permissions(c)
+	if err != nil {
+		return false, err
+	}
+	return APIAllowed(perms, method, path), nil
+}
+
+// UserPermissions 返回指定用户的全部权限，用于判断其他用户的权限（如发送通知前筛选接收人）
+// 与 Permissions 不同，角色的访问条件（时间段、IP 段）依赖该用户自己的请求，这里不筛选。
+func (p *PermissionChecker) UserPermissions(c *gin.Context, userID string) ([]APIPermission, error) {
+	return p.cache.Load(c, userID, func() ([]APIPermission, error) {
+		return p.Resolve(c.Request.Context(), p.pool.DB(c), userID)
+	})
+}
+
+// CodeAllowed 判断权限集合是否允许指定编码，按拒绝优先求值，支持通配
+func CodeAllowed(perms []APIPermission, code string) bool {
+	return PermissionAllowed(perms, func(perm APIPermission) bool {
+		return perm.Code != "" && MatchPermissionCode(perm.Code, code)
+	})
+}
+
+// APIAllowed 判断权限集合是否允许请求具体的接口，按拒绝优先求值
+func APIAllowed(perms []APIPermission, method, path string) bool {
+	return PermissionAllowed(perms, func(perm APIPermission) bool {
+		return perm.Method == method && MatchPermissionPath(perm.Path, path)
+	})
+}
+
+// PermissionAllowed 按拒绝优先判断权限：匹配到任一拒绝规则即不允许，否则匹配到授予规则才允许
+func PermissionAllowed(perms []APIPermission, match func(APIPermission) bool) bool {
+	allowed := false
+	for _, perm := range perms {
+		if !match(perm) {
+			continue
+		}
+		if perm.Deny {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
+
+// MatchPermissionCode 判断权限编码是否匹配，pattern 以 :* 结尾时匹配该前缀下的所有编码，* 匹配全部
+// 例如 user:* 匹配 user:delete、user:view_pii
+func MatchPermissionCode(pattern, code string) bool {
+	if pattern == code || pattern == "*" {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "*")
+	return ok && strings.HasSuffix(prefix, ":") && strings.HasPrefix(code, prefix)
+}
+
+// Resolve 用一条查询解析用户的全部权限（用户 -> 角色 -> 祖先角色 -> 权限），多个角色拥有的同一权限只返回一次
+// 按命名空间授予的权限在这里展开（见 RolePermissionsSQL）。
+// 拒绝规则（effect = deny）同样返回，由 PermissionAllowed 按拒绝优先求值。
+// 每条权限带上所属角色的访问条件，条件不同的角色授予的同一权限分别返回；继承来的权限沿用直接分配的角色的访问条件。
+// user_roles 汇总用户获得的角色（见 UserRolesCTE），新增授权来源（如用户组）时在其中 UNION 即可，其余部分不变。
+func (p *PermissionChecker) Resolve(ctx context.Context, db *sqlx.DB, userID string) ([]APIPermission, error) {
+	stmt, err := p.stmt(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Method     *string           `db:"method"`
+		Path       *string           `db:"path"`
+		Code       *string           `db:"code"`
+		Effect     string            `db:"effect"`
+		Conditions *AccessConditions `db:"access_conditions"`
+	}
+	if err := stmt.SelectContext(ctx, &rows, userID); err != nil {
+		return nil, err
+	}
+
+	perms := make([]APIPermission, 0, len(rows))
+	for _, row := range rows {
+		var perm APIPermission
+		if row.Method != nil && row.Path != nil {
+			perm.Method, perm.Path = *row.Method, *row.Path
+		}
+		if row.Code != nil {
+			perm.Code = *row.Code
+		}
+		if perm == (APIPermission{}) {
+			continue
+		}
+		perm.Deny = row.Effect == RolePermissionDeny
+		perm.Conditions = row.Conditions
+		perms = append(perms, perm)
+	}
+	return perms, nil
+}
+
+// UserRolesCTE 返回名为 user_roles 的递归 CTE，列为 user_id、role_id、access_conditions：
+// 直接分配给用户的角色，以及沿 parent_role_id 继承的全部祖先角色，祖先角色沿用直接分配的角色的访问条件。
+// filter 为筛选直接分配关系（别名 ur）的条件，如 ur.user_id = $1；语句须以 WITH RECURSIVE 开头。
+// 写入时已拒绝形成环的继承关系，path 记录经过的角色，即使并发修改形成了环也能终止。
+func UserRolesCTE(tables *TableNames, filter string) string {
+	return `user_roles AS (
+			SELECT ur.user_id, ur.role_id, r.access_conditions, r.parent_role_id, ARRAY[ur.role_id] AS path FROM ` + tables.UserRole + ` ur
+			INNER JOIN ` + tables.Role + ` r ON r.id = ur.role_id
+			WHERE ` + filter + `
+			UNION ALL
+			SELECT user_roles.user_id, r.id, user_roles.access_conditions, r.parent_role_id, user_roles.path || r.id FROM ` + tables.Role + ` r
+			INNER JOIN user_roles ON r.id = user_roles.parent_role_id
+			WHERE NOT r.id = ANY(user_roles.path)
+		)`
+}
+
+// RoleChainCTE 返回名为 role_chain 的递归 CTE，列为 role_id、depth：角色自身（depth 为 0）及其沿 parent_role_id 的全部祖先角色
+// start 为起始角色ID的 SQL 表达式，如 $1 或外层查询的 r.id；语句须以 WITH RECURSIVE 开头。
+func RoleChainCTE(tables *TableNames, start string) string {
+	return `role_chain AS (
+			SELECT r.id AS role_id, r.parent_role_id, 0 AS depth, ARRAY[r.id] AS path FROM ` + tables.Role + ` r WHERE r.id = ` + start + `
+			UNION ALL
+			SELECT r.id, r.parent_role_id, role_chain.depth + 1, role_chain.path || r.id FROM ` + tables.Role + ` r
+			INNER JOIN role_chain ON r.id = role_chain.parent_role_id
+			WHERE NOT r.id = ANY(role_chain.path)
+		)`
+}
+
+// RolePermissionsSQL 返回角色与权限的全部关联，列为 role_id、permission_id、effect、namespace，作为派生表代替 iacc_role_permission 参与求值：
+// 逐个分配的权限（namespace 为空），以及按命名空间授予展开得到的权限（namespace 为授予的命名空间）。
+// 同一角色的同一权限可能出现多次，按拒绝优先求值；UNION ALL 使外层的筛选条件可以下推到两部分。
+func RolePermissionsSQL(tables *TableNames) string {
+	return `SELECT rp.role_id, rp.permission_id, rp.effect, NULL::varchar AS namespace FROM ` + tables.RolePermission + ` rp
+			UNION ALL
+			SELECT rn.role_id, p.id, rn.effect, rn.namespace FROM ` + tables.RolePermissionNamespace + ` rn
+			INNER JOIN ` + tables.Permission + ` p ON ` + NamespaceMatchSQL("rn.namespace", "p.namespace")
+}
+
+// stmt 返回连接池对应的预编译解析语句，首次使用时预编译
+func (p *PermissionChecker) stmt(ctx context.Context, db *sqlx.DB) (*sqlx.Stmt, error) {
+	query := `WITH RECURSIVE ` + UserRolesCTE(p.tables, "ur.user_id = $1") + `, granted AS (
+			SELECT DISTINCT rp.permission_id, rp.effect, user_roles.access_conditions FROM (` + RolePermissionsSQL(p.tables) + `) rp
+			INNER JOIN user_roles USING (role_id)
+		)
+		SELECT DISTINCT p.metadata->>'method' AS method, p.metadata->>'path' AS path, p.metadata->>'code' AS code, g.effect, g.access_conditions
+		FROM ` + p.tables.Permission + ` p
+		INNER JOIN granted g ON g.permission_id = p.id`
+	// 沙箱请求的连接池只在本次请求内有效，不缓存预编译语句
+	if _, ok := db.Driver().(sandboxDriver); ok {
+		return db.PreparexContext(ctx, query)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if stmt, ok := p.stmts[db]; ok {
+		return stmt, nil
+	}
+	stmt, err := db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	p.stmts[db] = stmt
+	return stmt, nil
+}
+
+// CurrentUserID 返回 AuthMiddleware 写入的当前登录用户ID，未登录时返回空字符串
+func CurrentUserID(c *gin.Context) string {
+	userID, _ := c.Get("user_id")
+	uid, _ := userID.(string)
+	return uid
+}
@@ -0,0 +1,273 @@
+package pkgs
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PermissionRoutes 编译后的接口权限记录（method + path 模板），用于判断请求的接口是否纳入了权限体系
+// 每个 method 一棵按路径段组织的前缀树：静态段精确匹配，:param 匹配任意一段，*name 匹配剩余全部路径；
+// 匹配耗时只与路径段数有关，与权限记录的数量无关。
+type PermissionRoutes struct {
+	trees map[string]*routeNode
+	count int
+}
+
+type routeNode struct {
+	static map[string]*routeNode
+	param  *routeNode
+	// 以 *name 结尾的记录
+	catchAll string
+	// 在该节点结束的记录的路径模板
+	template string
+}
+
+// CompilePermissionRoutes 编译接口权限记录，忽略编码类权限与拒绝标记
+func CompilePermissionRoutes(perms []APIPermission) *PermissionRoutes {
+	routes := &PermissionRoutes{trees: map[string]*routeNode{}}
+	for _, perm := range perms {
+		if perm.Method == "" || perm.Path == "" {
+			continue
+		}
+		routes.count++
+		node := routes.trees[perm.Method]
+		if node == nil {
+			node = &routeNode{}
+			routes.trees[perm.Method] = node
+		}
+		for _, seg := range splitPath(perm.Path) {
+			if strings.HasPrefix(seg, "*") {
+				node.catchAll = perm.Path
+				node = nil
+				break
+			}
+			node = node.child(seg)
+		}
+		if node != nil {
+			node.template = perm.Path
+		}
+	}
+	return routes
+}
+
+func (n *routeNode) child(seg string) *routeNode {
+	if strings.HasPrefix(seg, ":") {
+		if n.param == nil {
+			n.param = &routeNode{}
+		}
+		return n.param
+	}
+	if n.static == nil {
+		n.static = map[string]*routeNode{}
+	}
+	next := n.static[seg]
+	if next == nil {
+		next = &routeNode{}
+		n.static[seg] = next
+	}
+	return next
+}
+
+// Count 返回编译的接口权限记录数
+func (r *PermissionRoutes) Count() int {
+	return r.count
+}
+
+// Match 返回匹配请求路径的权限记录的路径模板，静态段优先于 :param，:param 优先于 *name
+func (r *PermissionRoutes) Match(method, path string) (string, bool) {
+	node := r.trees[method]
+	if node == nil {
+		return "", false
+	}
+	return node.match(splitPath(path))
+}
+
+func (n *routeNode) match(segs []string) (string, bool) {
+	if len(segs) == 0 {
+		if n.template != "" {
+			return n.template, true
+		}
+		return "", false
+	}
+	if next := n.static[segs[0]]; next != nil {
+		if template, ok := next.match(segs[1:]); ok {
+			return template, true
+		}
+	}
+	if n.param != nil {
+		if template, ok := n.param.match(segs[1:]); ok {
+			return template, true
+		}
+	}
+	if n.catchAll != "" {
+		return n.catchAll, true
+	}
+	return "", false
+}
+
+// MatchPermissionPath 判断权限记录的 path 是否匹配请求路径，规则与 PermissionRoutes 一致：
+// 静态段逐一相等，:param 匹配任意一段，末尾的 *name 匹配剩余的一段或多段
+func MatchPermissionPath(permPath, path string) bool {
+	if permPath == path {
+		return true
+	}
+	// 逐段比较，不分配切片：用户的权限集合在每个请求中都要逐条匹配
+	permPath, path = strings.Trim(permPath, "/"), strings.Trim(path, "/")
+	for permPath != "" {
+		var seg, pathSeg string
+		seg, permPath, _ = strings.Cut(permPath, "/")
+		if strings.HasPrefix(seg, "*") {
+			return path != ""
+		}
+		if path == "" {
+			return false
+		}
+		pathSeg, path, _ = strings.Cut(path, "/")
+		if !strings.HasPrefix(seg, ":") && seg != pathSeg {
+			return false
+		}
+	}
+	return path == ""
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// PermissionMatcher 按租户缓存编译后的接口权限记录，供 PermissionMiddleware 判断接口是否需要校验权限
+// 编译结果是判断的唯一依据，不再回查数据库：权限记录的写入接口通过 PermissionCache 的失效钩子使编译结果过期，
+// 下一次请求重新编译；其他实例上的变更最多延迟 permission_cache.ttl 生效。
+type PermissionMatcher struct {
+	pool   *TenantPool
+	tables *TableNames
+	logger *zap.Logger
+	maxAge time.Duration
+
+	mu       sync.Mutex
+	compiled map[string]*compiledPermissionRoutes
+	// 每次失效递增，编译期间发生失效时不保存编译结果
+	generation uint64
+}
+
+// defaultPermissionMatcher 最近创建的实例，测试工具直接写入权限表后通过它使编译结果过期
+var defaultPermissionMatcher atomic.Pointer[PermissionMatcher]
+
+type compiledPermissionRoutes struct {
+	routes   *PermissionRoutes
+	compiled time.Time
+}
+
+func NewPermissionMatcher(config *Config, pool *TenantPool, tables *TableNames, logger *zap.Logger, cache *PermissionCache) *PermissionMatcher {
+	maxAge := config.PermissionCache.TTL
+	if maxAge <= 0 {
+		maxAge = time.Minute
+	}
+	m := &PermissionMatcher{
+		pool:     pool,
+		tables:   tables,
+		logger:   logger,
+		maxAge:   maxAge,
+		compiled: map[string]*compiledPermissionRoutes{},
+	}
+	cache.OnInvalidate(m.Invalidate)
+	defaultPermissionMatcher.Store(m)
+	return m
+}
+
+// Guarded 返回接口是否纳入了权限体系
+func (m *PermissionMatcher) Guarded(c *gin.Context, method, path string) (bool, error) {
+	routes, err := m.routes(c)
+	if err != nil {
+		return false, err
+	}
+	_, ok := routes.Match(method, path)
+	return ok, nil
+}
+
+// Invalidate 使租户的编译结果过期，下一次请求重新编译
+func (m *PermissionMatcher) Invalidate(tenant string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.compiled, tenant)
+	m.generation++
+}
+
+// PermissionRoutesStatus 租户编译结果的状态，Compiled 为 false 时尚未编译或已过期失效
+type PermissionRoutesStatus struct {
+	Compiled   bool
+	CompiledAt time.Time
+	Count      int
+	// 编译结果超过 permission_cache.ttl，下一次请求重新编译
+	Stale bool
+}
+
+// Status 返回租户在本实例中编译结果的状态
+func (m *PermissionMatcher) Status(tenant string) PermissionRoutesStatus {
+	m.mu.Lock()
+	entry := m.compiled[tenant]
+	m.mu.Unlock()
+	if entry == nil {
+		return PermissionRoutesStatus{}
+	}
+	return PermissionRoutesStatus{
+		Compiled:   true,
+		CompiledAt: entry.compiled,
+		Count:      entry.routes.Count(),
+		Stale:      time.Since(entry.compiled) >= m.maxAge,
+	}
+}
+
+// Rebuild 立即重新编译当前租户的接口权限记录，加载失败时返回错误
+func (m *PermissionMatcher) Rebuild(c *gin.Context) (PermissionRoutesStatus, error) {
+	tenant := TenantFromContext(c)
+	m.Invalidate(tenant)
+	if _, err := m.routes(c); err != nil {
+		return PermissionRoutesStatus{}, err
+	}
+	return m.Status(tenant), nil
+}
+
+// invalidateAll 使所有租户的编译结果过期
+func (m *PermissionMatcher) invalidateAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.compiled)
+	m.generation++
+}
+
+// routes 返回当前租户的编译结果，过期或不存在时从数据库加载并编译
+func (m *PermissionMatcher) routes(c *gin.Context) (*PermissionRoutes, error) {
+	tenant := TenantFromContext(c)
+	m.mu.Lock()
+	entry, generation := m.compiled[tenant], m.generation
+	m.mu.Unlock()
+	if entry != nil && time.Since(entry.compiled) < m.maxAge {
+		return entry.routes, nil
+	}
+
+	// 并发请求可能重复编译，结果相同，不影响正确性
+	var perms []APIPermission
+	query := `SELECT DISTINCT metadata->>'method' AS method, metadata->>'path' AS path FROM ` + m.tables.Permission + `
+		WHERE metadata->>'method' IS NOT NULL AND metadata->>'path' IS NOT NULL`
+	started := time.Now()
+	if err := m.pool.DB(c).SelectContext(c.Request.Context(), &perms, query); err != nil {
+		return nil, err
+	}
+	entry = &compiledPermissionRoutes{routes: CompilePermissionRoutes(perms), compiled: started}
+	m.mu.Lock()
+	if m.generation == generation {
+		m.compiled[tenant] = entry
+	}
+	m.mu.Unlock()
+	m.logger.Debug("已编译接口权限记录", zap.String("tenant", tenant), zap.Int("count", len(perms)), zap.Duration("elapsed", time.Since(started)))
+	return entry.routes, nil
+}
@@ -0,0 +1,63 @@
+package pkgs
+
+import (
+	"regexp"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// 权限命名空间的校验标签
+// 命名空间是层级的权限分组键，由冒号分隔的若干段组成，如 user、report:export；
+// 给角色授予命名空间相当于授予 user:* 形式的全部权限，包含子命名空间（report 包含 report:export）。
+const permissionNamespaceTag = "permission_namespace"
+
+// 命名空间每段只能包含字母、数字、下划线与连字符，与迁移中的 CHECK 约束一致
+var permissionNamespacePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(:[A-Za-z0-9_-]+)*$`)
+
+// 接口路径中版本号之后的第一段，如 /v1/user/list 中的 user
+var apiNamespacePattern = regexp.MustCompile(`^/v[0-9]+/([A-Za-z0-9_-]+)`)
+
+// ValidPermissionNamespace 判断命名空间格式是否合法
+func ValidPermissionNamespace(namespace string) bool {
+	return len(namespace) <= 255 && permissionNamespacePattern.MatchString(namespace)
+}
+
+// DefaultPermissionNamespace 返回创建权限时未指定命名空间的默认值，无法推断时返回空字符串
+// 编码权限取最后一段之前的部分（report:export:csv 属于 report:export，通配编码 user:* 属于 user），
+// 接口权限取版本号之后的第一段路径（/v1/user/list 属于 user）。
+func DefaultPermissionNamespace(code, path string) string {
+	if i := strings.LastIndex(code, ":"); i > 0 && ValidPermissionNamespace(code[:i]) {
+		return code[:i]
+	}
+	if m := apiNamespacePattern.FindStringSubmatch(path); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// NamespaceMatchSQL 返回判断权限命名空间 namespace 是否属于授予的命名空间 grant（自身或子命名空间）的 SQL 条件
+// 两个参数均为 SQL 表达式；不用 LIKE，命名空间中的下划线在 LIKE 中是通配符。
+func NamespaceMatchSQL(grant, namespace string) string {
+	return `(` + namespace + ` = ` + grant + ` OR starts_with(` + namespace + `, ` + grant + ` || ':'))`
+}
+
+// registerPermissionNamespace 注册 permission_namespace 校验标签及其各语言的提示
+func registerPermissionNamespace(validate *validator.Validate, translators map[string]ut.Translator) {
+	_ = validate.RegisterValidation(permissionNamespaceTag, func(fl validator.FieldLevel) bool {
+		return ValidPermissionNamespace(fl.Field().String())
+	})
+	messages := map[string]string{
+		LocaleZh: "{0}必须由冒号分隔的若干段组成，每段只能包含字母、数字、下划线与连字符",
+		LocaleEn: "{0} must be colon-separated segments of letters, digits, underscores and hyphens",
+	}
+	for locale, trans := range translators {
+		_ = validate.RegisterTranslation(permissionNamespaceTag, trans, func(ut ut.Translator) error {
+			return ut.Add(permissionNamespaceTag, messages[locale], true)
+		}, func(ut ut.Translator, fe validator.FieldError) string {
+			message, _ := ut.T(permissionNamespaceTag, fe.Field())
+			return message
+		})
+	}
+}
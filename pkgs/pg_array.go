@@ -0,0 +1,48 @@
+package pkgs
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/lib/pq"
+)
+
+// PGArrayElement 可以绑定为 PostgreSQL 数组参数的切片元素类型，包括以它们为底层类型的自定义类型
+type PGArrayElement interface {
+	~string | ~int | ~int32 | ~int64 | ~float64 | ~bool
+}
+
+// PGArray 将切片绑定为单个 PostgreSQL 数组参数，配合 `= ANY($1)`、`UNNEST($1::uuid[])` 使用，
+// 也可以作为 sqlx.Named 的命名参数（`= ANY(:ids)`）。
+//
+// 与 sqlx.In 展开为 IN (?, ?, ...) 相比，语句文本不随列表长度变化，解析开销固定、可以复用执行计划，
+// 也不受单条语句 65535 个参数的限制。空切片绑定为空数组 '{}'，不匹配任何行，不会变成 NULL。
+func PGArray[T PGArrayElement](values []T) driver.Valuer {
+	rv := reflect.ValueOf(values)
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.String:
+		array := make(pq.StringArray, len(values))
+		for i := range array {
+			array[i] = rv.Index(i).String()
+		}
+		return array
+	case reflect.Bool:
+		array := make(pq.BoolArray, len(values))
+		for i := range array {
+			array[i] = rv.Index(i).Bool()
+		}
+		return array
+	case reflect.Float64:
+		array := make(pq.Float64Array, len(values))
+		for i := range array {
+			array[i] = rv.Index(i).Float()
+		}
+		return array
+	default:
+		array := make(pq.Int64Array, len(values))
+		for i := range array {
+			array[i] = rv.Index(i).Int()
+		}
+		return array
+	}
+}
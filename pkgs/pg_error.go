@@ -0,0 +1,99 @@
+package pkgs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// 需要转换为业务错误的 SQLSTATE
+const (
+	PgUniqueViolation     = "23505"
+	PgForeignKeyViolation = "23503"
+	PgCheckViolation      = "23514"
+	PgInvalidTextRepr     = "22P02"
+	// 语句因超时（statement_timeout）或取消请求被中止
+	PgQueryCanceled = "57014"
+)
+
+// 约束错误详情中的列名，如 Key (user_id, role_id)=(...) already exists.
+var pgDetailKeyPattern = regexp.MustCompile(`^Key \((.+?)\)=\(`)
+
+// DBError 将数据库错误转换为接口错误，仓储执行语句失败时使用
+// 由请求数据引起的错误返回具体的业务码与原因，Data 为违反约束的字段（与参数校验失败的格式相同）：
+//   - 23505 唯一约束：409，数据已存在
+//   - 23503 外键：写入时引用的数据不存在返回 400，删除时数据仍被引用返回 409
+//   - 23514 检查约束：400，数据不满足约束
+//   - 22P02 格式无效：400，参数格式无效
+//
+// 客户端断开导致请求取消时返回 499，请求或语句超时（57014）返回 504，均不按错误记录日志。
+// 其余错误按 message 记录日志并返回 500。返回的消息不包含字段取值，避免泄露加密字段的影子值等数据。
+func DBError(logger *zap.Logger, err error, message string) *ApiError {
+	switch {
+	case errors.Is(err, context.Canceled):
+		logger.Info(message+"：请求已取消", zap.Error(err))
+		return NewApiError(StatusClientClosedRequest, message+"：请求已取消")
+	case errors.Is(err, context.DeadlineExceeded) || pgErrorCode(err) == PgQueryCanceled:
+		logger.Warn(message+"：请求超时", zap.Error(err))
+		return NewApiError(http.StatusGatewayTimeout, message+"：请求超时")
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		logger.Error(message, zap.Error(err))
+		return NewApiError(http.StatusInternalServerError, message)
+	}
+
+	var apiErr *ApiError
+	switch pqErr.Code {
+	case PgUniqueViolation:
+		apiErr = pgViolation(http.StatusConflict, message+"：数据已存在", pqErr, "该值已存在")
+	case PgForeignKeyViolation:
+		if strings.Contains(pqErr.Detail, "is still referenced") {
+			apiErr = pgViolation(http.StatusConflict, message+"：数据仍被引用", pqErr, "数据仍被引用")
+		} else {
+			apiErr = pgViolation(http.StatusBadRequest, message+"：引用的数据不存在", pqErr, "引用的数据不存在")
+		}
+	case PgCheckViolation:
+		apiErr = NewApiError(http.StatusBadRequest, message+"：数据不满足约束")
+		apiErr.Data = []Violation{{Field: pqErr.Constraint, Message: "数据不满足约束 " + pqErr.Constraint}}
+	case PgInvalidTextRepr:
+		apiErr = NewApiError(http.StatusBadRequest, message+"：参数格式无效")
+	default:
+		logger.Error(message, zap.Error(err))
+		return NewApiError(http.StatusInternalServerError, message)
+	}
+	logger.Info(message, zap.String("sqlstate", string(pqErr.Code)), zap.String("constraint", pqErr.Constraint))
+	return apiErr
+}
+
+// pgViolation 从约束错误详情中取出列名作为违反约束的字段
+func pgViolation(code int, message string, pqErr *pq.Error, reason string) *ApiError {
+	apiErr := NewApiError(code, message)
+	match := pgDetailKeyPattern.FindStringSubmatch(pqErr.Detail)
+	if match == nil {
+		if pqErr.Constraint != "" {
+			apiErr.Data = []Violation{{Field: pqErr.Constraint, Message: reason}}
+		}
+		return apiErr
+	}
+	var violations []Violation
+	for _, column := range strings.Split(match[1], ",") {
+		violations = append(violations, Violation{Field: strings.TrimSpace(column), Message: reason})
+	}
+	apiErr.Data = violations
+	return apiErr
+}
+
+// pgErrorCode 返回数据库错误的 SQLSTATE，不是数据库错误时为空
+func pgErrorCode(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
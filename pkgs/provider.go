@@ -8,7 +8,33 @@ import (
 var ProviderSet = wire.NewSet(
 	NewConfig,
 	NewConnection,
+	NewBatchConnection,
 	NewLogger,
 	NewRequestValidator,
 	NewScheduler,
+	NewTableNames,
+	NewTenantPool,
+	NewReportingDB,
+	NewFieldCipher,
+	NewPasswordHasher,
+	NewIDObfuscator,
+	NewPermissionChecker,
+	NewTimeFormatter,
+	NewLocalizer,
+	NewResponseCodes,
+	NewIDGenerator,
+	NewRedisClient,
+	NewPermissionCache,
+	NewPermissionMatcher,
+	NewJobQueue,
+	NewSecurityEvents,
+	NewRetention,
+	NewTokenBlacklist,
+	NewPseudonymizer,
+	NewNotifier,
+	NewStorage,
+	NewAuditLog,
+	NewWatchers,
+	NewSettings,
+	NewRateLimitShadow,
 )
@@ -0,0 +1,105 @@
+package pkgs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PIIAction 导出数据集中 PII 字段的处理方式
+type PIIAction int
+
+const (
+	// PIIHash 替换为稳定的假名，同一个值在不同导出中得到相同结果，可用于关联分析
+	PIIHash PIIAction = iota + 1
+	// PIIHashEmail 同 PIIHash，邮箱不区分大小写
+	PIIHashEmail
+	// PIIDrop 删除字段（如 profile 等自由格式数据）
+	PIIDrop
+)
+
+// 假名的长度（十六进制字符数），128 位足以避免碰撞
+const pseudonymLength = 32
+
+// ErrPseudonymizerDisabled 未配置 encryption.pseudonym_key 时无法导出假名化数据集
+var ErrPseudonymizerDisabled = errors.New("未配置假名化密钥")
+
+// Pseudonymizer 导出数据集的假名化处理，使分析人员拿到的数据集不含原始个人信息
+// 使用独立的 encryption.pseudonym_key 计算 HMAC，不能与影子列的 hash_key 相同，
+// 否则拿到数据集的人可以用假名直接匹配数据库中的 phone_hash、email_hash。
+type Pseudonymizer struct {
+	key []byte
+}
+
+func NewPseudonymizer(config *Config) *Pseudonymizer {
+	return &Pseudonymizer{key: []byte(config.Encryption.PseudonymKey)}
+}
+
+// Enabled 是否配置了假名化密钥
+func (p *Pseudonymizer) Enabled() bool {
+	return p != nil && len(p.key) > 0
+}
+
+// Pseudonym 计算值的假名，空值保持为空
+func (p *Pseudonymizer) Pseudonym(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+}
+
+// Stage 返回导出管道中的假名化阶段，按 fields 处理每条记录中的 PII 字段，未列出的字段原样保留
+// 记录以列名为键，阶段返回新的记录，不修改传入的记录；
+// 例如用户数据集使用 {"phone": PIIHash, "email": PIIHashEmail, "profile": PIIDrop}
+func (p *Pseudonymizer) Stage(fields map[string]PIIAction) (func(map[string]any) map[string]any, error) {
+	if !p.Enabled() {
+		return nil, ErrPseudonymizerDisabled
+	}
+	return func(record map[string]any) map[string]any {
+		out := make(map[string]any, len(record))
+		for name, value := range record {
+			action, ok := fields[name]
+			if !ok {
+				out[name] = value
+				continue
+			}
+			switch action {
+			case PIIDrop:
+			case PIIHashEmail:
+				out[name] = p.Pseudonym(normalizeEmail(piiString(value)))
+			default:
+				out[name] = p.Pseudonym(piiString(value))
+			}
+		}
+		return out
+	}, nil
+}
+
+// piiString 取出字段的字符串值，nil 视为空值
+func piiString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return strings.TrimSpace(*v)
+	case EncryptedString:
+		return strings.TrimSpace(string(v))
+	case *EncryptedString:
+		if v == nil {
+			return ""
+		}
+		return strings.TrimSpace(string(*v))
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,58 @@
+package pkgs
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StatusClientClosedRequest 客户端在响应前断开连接（沿用 nginx 的 499），只用于日志与指标，客户端收不到该响应
+const StatusClientClosedRequest = 499
+
+// ScanAll 扫描查询结果的全部行，返回前关闭 rows
+// 每行扫描前检查 ctx，客户端断开或请求超时后立即停止并返回 ctx 的错误；
+// 遍历结束后检查 rows.Err()，不会把中途失败的部分结果当作完整结果返回。
+func ScanAll[T any](ctx context.Context, rows *sqlx.Rows) ([]T, error) {
+	defer rows.Close()
+	var list []T
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var item T
+		if err := rows.StructScan(&item); err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// 遍历期间被取消时 rows.Next 可能直接返回 false
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// QueryAll 执行查询并扫描全部行，请求了执行计划时一并捕获（见 WithQueryPlans）
+func QueryAll[T any](ctx context.Context, q sqlx.QueryerContext, query string, args ...any) ([]T, error) {
+	captureQueryPlan(ctx, q, query, args)
+	rows, err := q.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ScanAll[T](ctx, rows)
+}
+
+// NamedQueryAll 执行命名参数查询并扫描全部行，请求了执行计划时一并捕获（见 WithQueryPlans）
+func NamedQueryAll[T any](ctx context.Context, db *sqlx.DB, query string, arg any) ([]T, error) {
+	if bound, args, err := db.BindNamed(query, arg); err == nil {
+		captureQueryPlan(ctx, db, bound, args)
+	}
+	rows, err := db.NamedQueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return ScanAll[T](ctx, rows)
+}
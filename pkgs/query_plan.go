@@ -0,0 +1,145 @@
+package pkgs
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 请求执行计划的请求头，值为 true 时生效（见 QueryPlanConfig）
+const QueryPlanHeader = "X-Debug-Query-Plan"
+
+// 查看执行计划所需的编码权限
+const QueryPlanPermission = "debug:query_plan"
+
+// 单个请求最多捕获的执行计划数，避免带扩展内容的列表响应过大
+const maxQueryPlans = 10
+
+// QueryPlan 一条查询的执行计划，Plan 为 EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) 的输出
+type QueryPlan struct {
+	Query string          `json:"query" label:"查询语句"`
+	Args  []string        `json:"args" label:"参数"`
+	Plan  json.RawMessage `json:"plan,omitempty" swaggertype:"object" label:"执行计划"`
+	// 生成执行计划失败（如超时）时的原因，不影响请求本身
+	Error string `json:"error,omitempty" label:"错误"`
+}
+
+// ResponseMeta 响应信封中的调试信息，只在请求了执行计划时返回
+type ResponseMeta struct {
+	QueryPlans []QueryPlan `json:"query_plans,omitempty" label:"执行计划"`
+}
+
+type queryPlanKey struct{}
+
+// queryPlanCollector 收集一个请求中的执行计划
+type queryPlanCollector struct {
+	timeout time.Duration
+	logger  *zap.Logger
+
+	mu    sync.Mutex
+	plans []QueryPlan
+}
+
+// WithQueryPlans 返回捕获执行计划的 context，之后经 QueryAll、NamedQueryAll 执行的查询都会附带执行计划
+// timeout 为单条 EXPLAIN ANALYZE 的超时时间，生成失败时写入 logger 与执行计划的 error 字段
+func WithQueryPlans(ctx context.Context, timeout time.Duration, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, queryPlanKey{}, &queryPlanCollector{timeout: timeout, logger: logger})
+}
+
+// QueryPlans 返回 context 中已捕获的执行计划，未请求执行计划时返回 nil
+func QueryPlans(ctx context.Context) []QueryPlan {
+	collector, ok := ctx.Value(queryPlanKey{}).(*queryPlanCollector)
+	if !ok {
+		return nil
+	}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	return append([]QueryPlan{}, collector.plans...)
+}
+
+// responseMeta 返回本次请求的调试信息，没有时返回 nil
+func responseMeta(ctx context.Context) *ResponseMeta {
+	if plans := QueryPlans(ctx); len(plans) > 0 {
+		return &ResponseMeta{QueryPlans: plans}
+	}
+	return nil
+}
+
+// captureQueryPlan 请求了执行计划时，在只读事务中以 EXPLAIN ANALYZE 再执行一次查询并记录执行计划
+// 只处理连接池上的 SELECT 查询：在调用方的事务中执行失败的 EXPLAIN 会使事务中止；沙箱请求同样跳过。
+func captureQueryPlan(ctx context.Context, q any, query string, args []any) {
+	collector, ok := ctx.Value(queryPlanKey{}).(*queryPlanCollector)
+	if !ok || !readOnlyQuery(query) {
+		return
+	}
+	db, ok := q.(*sqlx.DB)
+	if !ok {
+		return
+	}
+	if _, sandbox := db.Driver().(sandboxDriver); sandbox {
+		return
+	}
+	collector.mu.Lock()
+	full := len(collector.plans) >= maxQueryPlans
+	collector.mu.Unlock()
+	if full {
+		return
+	}
+
+	plan := QueryPlan{Query: query, Args: queryPlanArgs(args)}
+	if raw, err := explainAnalyze(ctx, db, collector.timeout, query, args); err != nil {
+		collector.logger.Warn("生成执行计划失败", zap.String("query", query), zap.Error(err))
+		plan.Error = err.Error()
+	} else {
+		plan.Plan = raw
+	}
+	collector.mu.Lock()
+	collector.plans = append(collector.plans, plan)
+	collector.mu.Unlock()
+}
+
+// readOnlyQuery 判断查询是否以 SELECT 或 WITH 开头，EXPLAIN ANALYZE 会真实执行修改语句
+func readOnlyQuery(query string) bool {
+	head := strings.ToUpper(strings.TrimSpace(query))
+	return strings.HasPrefix(head, "SELECT") || strings.HasPrefix(head, "WITH")
+}
+
+// explainAnalyze 在只读事务中执行 EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON)，事务最后回滚
+func explainAnalyze(ctx context.Context, db *sqlx.DB, timeout time.Duration, query string, args []any) (json.RawMessage, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `SET TRANSACTION READ ONLY`); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `SET LOCAL statement_timeout = `+strconv.FormatInt(timeout.Milliseconds(), 10)); err != nil {
+		return nil, err
+	}
+	var plan []byte
+	if err := tx.GetContext(ctx, &plan, `EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) `+query, args...); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// queryPlanArgs 把查询参数转为字符串，便于在本地重放查询
+func queryPlanArgs(args []any) []string {
+	list := make([]string, len(args))
+	for i, arg := range args {
+		raw, err := json.Marshal(arg)
+		if err != nil {
+			list[i] = "?"
+			continue
+		}
+		list[i] = string(raw)
+	}
+	return list
+}
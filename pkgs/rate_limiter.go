@@ -0,0 +1,143 @@
+package pkgs
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RateLimiter 固定窗口限流器
+// 计数保存在进程内存中，多实例部署时每个实例独立计数。
+type RateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+	now     func() time.Time
+}
+
+type rateWindow struct {
+	end   time.Time
+	count int
+}
+
+// RateLimitResult 单次限流判断的结果
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Count 当前时间窗口内的请求数（包含本次与超出限制的请求）
+	Count int
+	// Reset 当前时间窗口结束的时间
+	Reset time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		windows: make(map[string]*rateWindow),
+		now:     time.Now,
+	}
+}
+
+// NewRateLimiterWithClock 使用指定时钟创建限流器，便于测试
+func NewRateLimiterWithClock(now func() time.Time) *RateLimiter {
+	l := NewRateLimiter()
+	l.now = now
+	return l
+}
+
+// Allow 记录一次请求并判断是否超出 limit（每 window 时间内）
+func (l *RateLimiter) Allow(key string, limit int, window time.Duration) RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.end) {
+		// 新 key 出现时顺带清理已过期的窗口，避免不再出现的 key 常驻内存
+		if !ok {
+			l.prune(now)
+		}
+		w = &rateWindow{end: now.Add(window)}
+		l.windows[key] = w
+	}
+
+	// 超出限制的请求同样计数，影子模式据此统计窗口内的峰值
+	w.count++
+	res := RateLimitResult{Limit: limit, Count: w.count, Reset: w.end}
+	if w.count > limit {
+		return res
+	}
+	res.Allowed = true
+	res.Remaining = limit - w.count
+	return res
+}
+
+func (l *RateLimiter) prune(now time.Time) {
+	for key, w := range l.windows {
+		if !now.Before(w.end) {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// RateLimitShadow 影子模式限流的统计
+// 影子模式下超出限制的请求不会被拒绝，只在这里按租户与 API 密钥记录，用于在启用限流前校准限制，
+// 找出启用后会被限流的调用方。与 RateLimiter 一样保存在进程内存中，进程重启（修改配置）后清空。
+type RateLimitShadow struct {
+	mu      sync.Mutex
+	clients map[[2]string]*RateLimitShadowClient
+}
+
+// RateLimitShadowClient 一个 API 密钥在影子模式下超出限制的情况
+type RateLimitShadowClient struct {
+	APIKeyID string
+	Tier     string
+	Limit    int
+	Window   time.Duration
+	// Exceeded 启用限流后会被拒绝的请求数
+	Exceeded int64
+	// Peak 单个时间窗口内的最大请求数，可作为调整限制的参考
+	Peak            int
+	FirstExceededAt time.Time
+	LastExceededAt  time.Time
+}
+
+func NewRateLimitShadow() *RateLimitShadow {
+	return &RateLimitShadow{clients: make(map[[2]string]*RateLimitShadowClient)}
+}
+
+// Record 记录一次超出限制但未被拒绝的请求
+func (s *RateLimitShadow) Record(tenant, apiKeyID, tier string, window time.Duration, res RateLimitResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key := [2]string{tenant, apiKeyID}
+	client, ok := s.clients[key]
+	if !ok {
+		client = &RateLimitShadowClient{APIKeyID: apiKeyID, FirstExceededAt: now}
+		s.clients[key] = client
+	}
+	// 等级或限制调整后以最新的配置为准
+	client.Tier, client.Limit, client.Window = tier, res.Limit, window
+	client.Exceeded++
+	client.Peak = max(client.Peak, res.Count)
+	client.LastExceededAt = now
+}
+
+// Clients 返回租户下超出过限制的 API 密钥，按超出的请求数从多到少排序
+func (s *RateLimitShadow) Clients(tenant string) []RateLimitShadowClient {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []RateLimitShadowClient{}
+	for key, client := range s.clients {
+		if key[0] == tenant {
+			list = append(list, *client)
+		}
+	}
+	slices.SortFunc(list, func(a, b RateLimitShadowClient) int {
+		return cmp.Or(cmp.Compare(b.Exceeded, a.Exceeded), cmp.Compare(a.APIKeyID, b.APIKeyID))
+	})
+	return list
+}
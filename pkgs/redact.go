@@ -0,0 +1,193 @@
+package pkgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 脱敏后的占位符
+const Redacted = "[REDACTED]"
+
+// 按字段名脱敏：字段名（忽略大小写，- 视为 _）包含以下任一片段即整体替换
+var sensitiveKeyParts = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"cookie",
+	"api_key",
+	"apikey",
+	"hash_key",
+}
+
+// 按内容脱敏：字符串中出现以下模式时替换匹配部分
+var sensitivePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// Authorization: Bearer xxx
+	{regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`), "Bearer " + Redacted},
+	// JWT
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Redacted},
+	// password=xxx、"token": "xxx"、refresh_token: xxx 等键值对
+	{regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api_key|apikey)"?\s*[:=]\s*"?)[^"&\s,}]+`), "${1}" + Redacted},
+}
+
+// IsSensitiveKey 判断字段名是否属于敏感字段
+func IsSensitiveKey(key string) bool {
+	k := strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString 按内容模式脱敏字符串
+func RedactString(s string) string {
+	for _, p := range sensitivePatterns {
+		s = p.pattern.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// RedactValue 递归脱敏任意 JSON 兼容的值（map、切片、字符串）
+func RedactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			if IsSensitiveKey(k) {
+				out[k] = Redacted
+			} else {
+				out[k] = RedactValue(item)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = RedactValue(item)
+		}
+		return out
+	case string:
+		return RedactString(val)
+	default:
+		return v
+	}
+}
+
+// RedactSettings 脱敏配置项（如 viper.AllSettings()），用于导出配置
+// 除敏感字段外，还脱敏名为 key、以 _key 结尾的密钥，以 url 结尾的地址（可能带有凭据）与自定义请求头；
+// 未设置（空字符串）的配置项保持为空，便于区分未配置与已配置。
+func RedactSettings(settings map[string]any) map[string]any {
+	out := make(map[string]any, len(settings))
+	for k, v := range settings {
+		switch val := v.(type) {
+		case map[string]any:
+			if isSecretSetting(k) && len(val) > 0 {
+				out[k] = Redacted
+			} else {
+				out[k] = RedactSettings(val)
+			}
+		default:
+			if isSecretSetting(k) && v != nil && v != "" {
+				out[k] = Redacted
+			} else {
+				out[k] = RedactValue(v)
+			}
+		}
+	}
+	return out
+}
+
+func isSecretSetting(key string) bool {
+	k := strings.ToLower(key)
+	return IsSensitiveKey(k) || k == "key" || strings.HasSuffix(k, "_key") || strings.HasSuffix(k, "url") || k == "headers"
+}
+
+// RedactJSON 脱敏 JSON 文本（请求/响应体记录使用），非 JSON 内容按字符串模式脱敏
+func RedactJSON(payload []byte) []byte {
+	var v any
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return []byte(RedactString(string(payload)))
+	}
+	out, err := json.Marshal(RedactValue(v))
+	if err != nil {
+		return []byte(Redacted)
+	}
+	return out
+}
+
+// redactCore 在日志写出前对消息和所有结构化字段脱敏
+type redactCore struct {
+	zapcore.Core
+}
+
+// NewRedactCore 包装 zapcore.Core，使所有日志字段经过脱敏处理
+func NewRedactCore(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = RedactString(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = redactField(f)
+	}
+	return out
+}
+
+func redactField(f zapcore.Field) zapcore.Field {
+	if IsSensitiveKey(f.Key) {
+		return zap.String(f.Key, Redacted)
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		return zap.String(f.Key, RedactString(f.String))
+	case zapcore.ByteStringType:
+		return zap.String(f.Key, RedactString(string(f.Interface.([]byte))))
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return zap.String(f.Key, RedactString(err.Error()))
+		}
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok {
+			return zap.String(f.Key, RedactString(s.String()))
+		}
+	case zapcore.ReflectType:
+		// 结构体、map 等先转为通用 JSON 结构再按字段名递归脱敏
+		raw, err := json.Marshal(f.Interface)
+		if err != nil {
+			return zap.String(f.Key, RedactString(fmt.Sprint(f.Interface)))
+		}
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return zap.String(f.Key, RedactString(string(raw)))
+		}
+		return zap.Any(f.Key, RedactValue(v))
+	}
+	return f
+}
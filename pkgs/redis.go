@@ -0,0 +1,19 @@
+package pkgs
+
+import (
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedisClient 根据配置创建 Redis 客户端，未配置 redis.addr 时返回 nil
+// 客户端按需建立连接，Redis 暂时不可用不会阻止服务启动
+func NewRedisClient(config *Config) (*redis.Client, func()) {
+	if config.Redis.Addr == "" {
+		return nil, func() {}
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Redis.Addr,
+		Password: config.Redis.Password,
+		DB:       config.Redis.DB,
+	})
+	return client, func() { client.Close() }
+}
@@ -0,0 +1,124 @@
+package pkgs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// ReportingDB 报表查询（统计、取值分布、矩阵与导出）使用的连接
+// 配置了 database.reporting.host 时连接独立的分析库（只读副本或逻辑复制的分析库），耗时的聚合查询不占用主库的连接与 IO；
+// 分析库的表可以位于不同的 schema 或使用不同的前缀，因此连接与表名总是成对返回，见 Conn。
+// 以下请求仍在主库查询：未配置分析库；沙箱请求（数据只存在于未提交的事务中）；
+// schema-per-tenant 模式下的租户请求（分析库连接没有按租户设置 search_path）。
+// 分析库只用于读取，迁移与写入始终在主库执行；副本存在复制延迟，报表结果可能略滞后于主库。
+type ReportingDB struct {
+	// 未配置分析库时为 nil
+	db     *sqlx.DB
+	tables *TableNames
+
+	pool          *TenantPool
+	primaryTables *TableNames
+}
+
+// NewReportingDB 创建分析库连接，未配置 database.reporting.host 时报表查询使用主库
+// 分析库不可用不影响服务启动：连接按需建立，启动时 ping 失败只记录告警，健康检查见 Ping。
+func NewReportingDB(config *Config, pool *TenantPool, tables *TableNames, logger *zap.Logger) (*ReportingDB, func(), error) {
+	r := &ReportingDB{pool: pool, primaryTables: tables}
+	if config.Database.Reporting.Host == "" {
+		return r, func() {}, nil
+	}
+
+	db, err := sqlx.Open("postgres", ReportingConnString(config))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open reporting database: %w", err)
+	}
+	db.SetMaxIdleConns(config.Database.Reporting.MaxIdleConns)
+	db.SetMaxOpenConns(config.Database.Reporting.MaxOpenConns)
+	db.SetConnMaxLifetime(config.Database.ConnMaxLifetime)
+	if err := db.Ping(); err != nil {
+		logger.Warn("分析库暂不可用，报表查询将返回错误直到连接恢复", zap.String("host", config.Database.Reporting.Host), zap.Error(err))
+	}
+
+	r.db = db
+	r.tables = NewTableNames(&Config{Database: DatabaseConfig{
+		Schema:      config.Database.Reporting.Schema,
+		TablePrefix: config.Database.Reporting.TablePrefix,
+	}})
+	return r, func() { db.Close() }, nil
+}
+
+// ReportingConnString 构造分析库的连接字符串，未配置的连接参数沿用主库
+func ReportingConnString(config *Config) string {
+	reporting, primary := config.Database.Reporting, config.Database
+	port := reporting.Port
+	if port == 0 {
+		port = primary.Port
+	}
+	return fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s timezone=UTC",
+		reporting.Host,
+		port,
+		orDefault(reporting.Username, primary.Username),
+		orDefault(reporting.Password, primary.Password),
+		orDefault(reporting.DBName, primary.DBName),
+		orDefault(reporting.SSLMode, primary.SSLMode),
+	)
+}
+
+// Enabled 是否配置了独立的分析库
+func (r *ReportingDB) Enabled() bool {
+	return r.db != nil
+}
+
+// Conn 返回当前请求的报表查询应使用的连接与表名
+func (r *ReportingDB) Conn(c *gin.Context) (*sqlx.DB, *TableNames) {
+	if r.db == nil || sandboxFromContext(c) != nil || (r.pool.Enabled() && TenantFromContext(c) != "") {
+		return r.pool.DB(c), r.primaryTables
+	}
+	return r.db, r.tables
+}
+
+// ForTenant 返回后台任务的报表查询应使用的连接与表名，db 为任务所属租户在主库的连接
+func (r *ReportingDB) ForTenant(tenant string, db *sqlx.DB) (*sqlx.DB, *TableNames) {
+	if r.db == nil || (r.pool.Enabled() && tenant != "") {
+		return db, r.primaryTables
+	}
+	return r.db, r.tables
+}
+
+// Stats 返回分析库连接池的统计，未配置分析库时返回 false
+func (r *ReportingDB) Stats() (PoolStats, bool) {
+	if r.db == nil {
+		return PoolStats{}, false
+	}
+	return PoolStats{Name: "reporting", DBStats: r.db.Stats()}, true
+}
+
+// Ping 检查分析库是否可用，返回复制延迟（秒）；分析库不是处于恢复状态的副本（例如逻辑复制的目标库）时延迟为 nil
+func (r *ReportingDB) Ping(ctx context.Context) (*float64, error) {
+	if r.db == nil {
+		return nil, nil
+	}
+	var lag sql.NullFloat64
+	query := `SELECT CASE WHEN pg_is_in_recovery() THEN EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8 END`
+	if err := r.db.GetContext(ctx, &lag, query); err != nil {
+		return nil, err
+	}
+	if !lag.Valid {
+		return nil, nil
+	}
+	return &lag.Float64, nil
+}
+
+// orDefault 返回 value，为空时返回 fallback
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -6,31 +6,46 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Response 标准响应结构体
+// Response 标准响应结构体，Code 为按 response 配置转换后的业务码（见 ResponseCodes）
 type Response struct {
 	Code int         `json:"code"`
 	Msg  string      `json:"msg"`
 	Data interface{} `json:"data"`
+	// 调试信息，只在请求了执行计划时返回（见 QueryPlanHeader）
+	Meta *ResponseMeta `json:"meta,omitempty"`
 }
 
-// Success 成功响应
+// Success 成功响应，data 按请求协商的接口版本选择表示（见 RegisterRepresentation）
 func Success(c *gin.Context, data interface{}) {
+	writeAPIVersion(c)
 	c.JSON(http.StatusOK, Response{
-		Code: 200,
+		Code: currentResponseCodes().Success(),
 		Msg:  "success",
-		Data: data,
+		Data: Represent(c, data),
+		Meta: responseMeta(c.Request.Context()),
 	})
 }
 
-// Error 错误响应
+// Error 错误响应，msg 按本次请求的语言翻译（见 Localize）
 func Error(c *gin.Context, code int, msg string) {
+	writeAPIVersion(c)
 	c.AbortWithStatusJSON(http.StatusOK, Response{
-		Code: code,
-		Msg:  msg,
+		Code: currentResponseCodes().Error(code),
+		Msg:  Localize(c, msg),
 		Data: nil,
 	})
 }
 
+// ErrorWithData 带附加数据的错误响应，msg 按本次请求的语言翻译
+func ErrorWithData(c *gin.Context, code int, msg string, data interface{}) {
+	writeAPIVersion(c)
+	c.AbortWithStatusJSON(http.StatusOK, Response{
+		Code: currentResponseCodes().Error(code),
+		Msg:  Localize(c, msg),
+		Data: data,
+	})
+}
+
 func HandleSuccess[T any](c *gin.Context) func(req T) (T, error) {
 	return func(req T) (T, error) {
 		Success(c, req)
@@ -40,8 +55,12 @@ func HandleSuccess[T any](c *gin.Context) func(req T) (T, error) {
 
 func HandleError[T any](c *gin.Context) func(err error) (T, error) {
 	return func(err error) (T, error) {
-		if apiErr, ok := err.(*ApiError); ok {
-			Error(c, apiErr.Code, apiErr.Message)
+		if apiErr, ok := err.(*ApiError); ok && apiErr.Code == http.StatusNotModified {
+			// 条件请求命中（见 CheckModified），只返回状态码
+			c.AbortWithStatus(http.StatusNotModified)
+		} else if ok {
+			message, data := apiErr.Localized(RequestLocale(c))
+			ErrorWithData(c, apiErr.Code, message, data)
 		} else {
 			Error(c, http.StatusInternalServerError, "服务器内部错误")
 		}
@@ -0,0 +1,56 @@
+package pkgs
+
+import (
+	"maps"
+	"net/http"
+	"sync/atomic"
+)
+
+// ResponseCodes 响应信封中业务码的映射
+// 处理器与仓储始终使用内部业务码（HTTP 状态码或 40301 等细分业务码），写出响应时按 response 配置转换，
+// 同一套处理器既可以返回与 HTTP 状态码一致的业务码，也可以按部署方的约定返回（如成功为 0、错误为 1xxx）。
+// 映射表在 NewResponseCodes 中一次性构建，之后只读；创建后注册为包级默认实例，Success、Error 通过它转换业务码。
+type ResponseCodes struct {
+	success      int
+	errors       map[int]int
+	defaultError int
+}
+
+var defaultResponseCodes atomic.Pointer[ResponseCodes]
+
+func init() {
+	defaultResponseCodes.Store(&ResponseCodes{success: http.StatusOK})
+}
+
+// NewResponseCodes 根据配置创建业务码映射，并注册为默认实例
+// 映射表复制自配置，之后修改配置不影响已创建的实例
+func NewResponseCodes(config *Config) *ResponseCodes {
+	r := &ResponseCodes{
+		success:      config.Response.SuccessCode,
+		errors:       maps.Clone(config.Response.ErrorCodes),
+		defaultError: config.Response.DefaultErrorCode,
+	}
+	defaultResponseCodes.Store(r)
+	return r
+}
+
+// currentResponseCodes 返回当前注册的业务码映射
+func currentResponseCodes() *ResponseCodes {
+	return defaultResponseCodes.Load()
+}
+
+// Success 成功响应的业务码
+func (r *ResponseCodes) Success() int {
+	return r.success
+}
+
+// Error 返回内部错误业务码对外的业务码
+func (r *ResponseCodes) Error(code int) int {
+	if mapped, ok := r.errors[code]; ok {
+		return mapped
+	}
+	if r.defaultError != 0 {
+		return r.defaultError
+	}
+	return code
+}
@@ -0,0 +1,250 @@
+package pkgs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 数据保留类别，与 retention_policy.category 一致
+const (
+	RetentionAsyncJob            = "async_job"
+	RetentionUserDevice          = "user_device"
+	RetentionTombstone           = "sync_tombstone"
+	RetentionTokenRevocation     = "token_revocation"
+	RetentionTokenRevocationRule = "token_revocation_rule"
+	RetentionRefreshToken        = "refresh_token"
+)
+
+// 每次删除的行数，分批删除避免长事务与大量锁
+const retentionBatchSize = 1000
+
+// RetentionCategory 可按保留期限清理的数据类别
+type RetentionCategory struct {
+	Name        string
+	Description string
+	// 数据所在的表
+	Table func(t *TableNames) string
+	// 判断是否过期的时间列
+	TimeColumn string
+	// 额外的清理条件，为空表示不限制
+	Where string
+}
+
+// retentionCategories 已注册的数据类别，新增类别时同时在迁移中写入默认策略
+var retentionCategories = []RetentionCategory{
+	{
+		Name:        RetentionAsyncJob,
+		Description: "已结束的异步任务",
+		Table:       func(t *TableNames) string { return t.AsyncJob },
+		TimeColumn:  "finished_at",
+		Where:       "status IN ('" + JobStatusSucceeded + "', '" + JobStatusFailed + "', '" + JobStatusCanceled + "')",
+	},
+	{
+		// 删除设备记录后绑定该设备的刷新令牌失效，保留期限应大于刷新令牌有效期
+		Name:        RetentionUserDevice,
+		Description: "长期未登录的设备记录",
+		Table:       func(t *TableNames) string { return t.UserDevice },
+		TimeColumn:  "last_seen_at",
+	},
+	{
+		// 清理后离线时间超过保留期限的客户端无法拉取这段时间的删除操作，需要全量同步
+		Name:        RetentionTombstone,
+		Description: "已删除模板的同步墓碑",
+		Table:       func(t *TableNames) string { return t.TemplateTombstone },
+		TimeColumn:  "deleted_at",
+	},
+	{
+		// 按令牌过期时间清理，过期的令牌本身已无法通过校验
+		Name:        RetentionTokenRevocation,
+		Description: "已过期令牌的黑名单记录",
+		Table:       func(t *TableNames) string { return t.TokenRevocation },
+		TimeColumn:  "expires_at",
+	},
+	{
+		// 保留期限应大于刷新令牌的最长有效期，否则被撤销的刷新令牌在规则清理后重新生效
+		Name:        RetentionTokenRevocationRule,
+		Description: "批量撤销令牌的规则",
+		Table:       func(t *TableNames) string { return t.TokenRevocationRule },
+		TimeColumn:  "created_at",
+	},
+	{
+		// 按令牌过期时间清理，过期的刷新令牌不会再被批量撤销命中
+		Name:        RetentionRefreshToken,
+		Description: "已过期刷新令牌的签发登记",
+		Table:       func(t *TableNames) string { return t.RefreshToken },
+		TimeColumn:  "expires_at",
+	},
+}
+
+// RetentionCategoryByName 按名称查找数据类别
+func RetentionCategoryByName(name string) (RetentionCategory, bool) {
+	for _, category := range retentionCategories {
+		if category.Name == name {
+			return category, true
+		}
+	}
+	return RetentionCategory{}, false
+}
+
+// RetentionPolicy 数据库表 retention_policy 的表结构
+type RetentionPolicy struct {
+	ID         string     `db:"id" json:"id"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+	Category   string     `db:"category" json:"category"`
+	RetainDays int        `db:"retain_days" json:"retain_days"`
+	Enabled    bool       `db:"enabled" json:"enabled"`
+	LastRunAt  *time.Time `db:"last_run_at" json:"last_run_at"`
+	LastPurged int64      `db:"last_purged" json:"last_purged"`
+}
+
+// RetentionSummary 数据类别的保留策略与即将清理的数据量
+type RetentionSummary struct {
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	// 未配置策略时为空，该类别不会被清理
+	Policy *RetentionPolicy `json:"policy"`
+	// 早于该时间的数据在下次执行时清理
+	Cutoff *time.Time `json:"cutoff"`
+	// 下次执行时清理的行数
+	Expired int64 `json:"expired"`
+	// 预告期内将陆续过期的行数
+	Upcoming int64 `json:"upcoming"`
+}
+
+// Retention 数据保留策略的执行
+// 策略保存在每个 schema 的 retention_policy 表中，定时任务逐个 schema 按策略分批删除过期数据，
+// 并记录最近一次执行时间与清理行数。
+type Retention struct {
+	pool   *TenantPool
+	tables *TableNames
+	logger *zap.Logger
+}
+
+func NewRetention(pool *TenantPool, tables *TableNames, logger *zap.Logger) *Retention {
+	return &Retention{pool: pool, tables: tables, logger: logger}
+}
+
+// RunAll 依次对默认 schema 与所有租户 schema 执行清理
+func (r *Retention) RunAll(ctx context.Context) {
+	for tenant, db := range r.pool.BatchDBs(ctx, r.logger) {
+		if err := r.Enforce(ctx, db); err != nil {
+			r.logger.Error("执行数据保留策略失败", zap.String("tenant", tenant), zap.Error(err))
+		}
+	}
+}
+
+// Enforce 按已启用的策略清理过期数据，单个类别失败不影响其他类别
+func (r *Retention) Enforce(ctx context.Context, db *sqlx.DB) error {
+	policies, err := r.Policies(ctx, db)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, policy := range policies {
+		category, ok := RetentionCategoryByName(policy.Category)
+		if !ok || !policy.Enabled {
+			continue
+		}
+		purged, err := r.purge(ctx, db, category, retentionCutoff(policy.RetainDays))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", category.Name, err))
+		}
+		query := `UPDATE ` + r.tables.Retention + ` SET last_run_at = CURRENT_TIMESTAMP, last_purged = $1 WHERE id = $2`
+		if _, err := db.ExecContext(ctx, query, purged, policy.ID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: 更新执行记录失败: %w", category.Name, err))
+		}
+		r.logger.Info("数据保留策略已执行", zap.String("category", category.Name), zap.Int("retain_days", policy.RetainDays), zap.Int64("purged", purged))
+	}
+	return errors.Join(errs...)
+}
+
+// purge 分批删除早于 cutoff 的数据，返回删除的行数
+// 多个实例同时执行时通过 SKIP LOCKED 各自删除不同的行
+func (r *Retention) purge(ctx context.Context, db *sqlx.DB, category RetentionCategory, cutoff time.Time) (int64, error) {
+	table := category.Table(r.tables)
+	query := `DELETE FROM ` + table + ` WHERE id IN (
+		SELECT id FROM ` + table + ` WHERE ` + category.TimeColumn + ` < $1` + category.and() + `
+		LIMIT $2 FOR UPDATE SKIP LOCKED
+	)`
+	var total int64
+	for {
+		res, err := db.ExecContext(ctx, query, cutoff, retentionBatchSize)
+		if err != nil {
+			return total, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+		if affected < retentionBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// Policies 查询当前 schema 中的保留策略
+func (r *Retention) Policies(ctx context.Context, db *sqlx.DB) ([]RetentionPolicy, error) {
+	var policies []RetentionPolicy
+	query := `SELECT id, created_at, updated_at, category, retain_days, enabled, last_run_at, last_purged FROM ` + r.tables.Retention + ` ORDER BY category`
+	if err := db.SelectContext(ctx, &policies, query); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Summary 汇总每个数据类别的策略、下次执行将清理的行数与预告期内将过期的行数
+func (r *Retention) Summary(ctx context.Context, db *sqlx.DB, horizon time.Duration) ([]RetentionSummary, error) {
+	policies, err := r.Policies(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	byCategory := make(map[string]RetentionPolicy, len(policies))
+	for _, policy := range policies {
+		byCategory[policy.Category] = policy
+	}
+
+	list := make([]RetentionSummary, 0, len(retentionCategories))
+	for _, category := range retentionCategories {
+		item := RetentionSummary{Category: category.Name, Description: category.Description}
+		if policy, ok := byCategory[category.Name]; ok {
+			cutoff := retentionCutoff(policy.RetainDays)
+			item.Policy = &policy
+			item.Cutoff = &cutoff
+
+			table := category.Table(r.tables)
+			query := `SELECT COUNT(*) FILTER (WHERE ` + category.TimeColumn + ` < $1) AS expired,
+					COUNT(*) FILTER (WHERE ` + category.TimeColumn + ` >= $1 AND ` + category.TimeColumn + ` < $2) AS upcoming
+				FROM ` + table + ` WHERE ` + category.TimeColumn + ` < $2` + category.and()
+			var counts struct {
+				Expired  int64 `db:"expired"`
+				Upcoming int64 `db:"upcoming"`
+			}
+			if err := db.GetContext(ctx, &counts, query, cutoff, cutoff.Add(horizon)); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%s: %w", category.Name, err)
+			}
+			item.Expired, item.Upcoming = counts.Expired, counts.Upcoming
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// and 返回拼接在 WHERE 之后的额外条件
+func (c RetentionCategory) and() string {
+	if c.Where == "" {
+		return ""
+	}
+	return ` AND (` + c.Where + `)`
+}
+
+func retentionCutoff(retainDays int) time.Time {
+	return time.Now().AddDate(0, 0, -retainDays)
+}
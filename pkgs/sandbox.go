@@ -0,0 +1,226 @@
+package pkgs
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// 沙箱模式
+// 集成方使用沙箱令牌调用接口时，整个请求在一个最终回滚的事务中执行：写操作在本次请求内可见，请求结束后全部撤销。
+// 沙箱令牌由 POST /v1/sandbox/token 签发，有效期短，并且只能访问签发时指定的接口。
+const (
+	// SandboxContextKey 沙箱令牌允许访问的接口（SandboxScopes），存在即表示本次请求在沙箱中执行
+	SandboxContextKey = "sandbox_scopes"
+	// SandboxClaim 令牌中记录允许访问的接口的声明，只有沙箱令牌带有该声明
+	SandboxClaim = "sandbox"
+	// SandboxHeader 沙箱请求的响应头，提示本次请求的数据变更已回滚
+	SandboxHeader = "X-Sandbox"
+)
+
+// SandboxScopes 沙箱令牌允许访问的接口，每项为 "METHOD 路由模板"，如 "PUT /v1/user/:id"
+type SandboxScopes []string
+
+// SandboxScope 返回接口对应的范围项
+func SandboxScope(method, route string) string {
+	return method + " " + route
+}
+
+// Allows 是否允许访问该接口，route 为匹配到的路由模板（c.FullPath()）
+func (s SandboxScopes) Allows(method, route string) bool {
+	return slices.Contains(s, SandboxScope(method, route))
+}
+
+// SandboxScopesFromClaims 从令牌声明中读取沙箱范围，不是沙箱令牌时返回 false
+func SandboxScopesFromClaims(claims map[string]any) (SandboxScopes, bool) {
+	raw, ok := claims[SandboxClaim].([]any)
+	if !ok {
+		return nil, false
+	}
+	scopes := make(SandboxScopes, 0, len(raw))
+	for _, v := range raw {
+		if scope, ok := v.(string); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, true
+}
+
+// SandboxScopesFromContext 返回本次请求的沙箱范围，不是沙箱请求时返回 false
+func SandboxScopesFromContext(c *gin.Context) (SandboxScopes, bool) {
+	v, ok := c.Get(SandboxContextKey)
+	if !ok {
+		return nil, false
+	}
+	scopes, ok := v.(SandboxScopes)
+	return scopes, ok
+}
+
+// sandboxDenied 沙箱中不允许调用的接口：它们的副作用不在请求的数据库事务内，回滚无法撤销
+var sandboxDenied = []struct {
+	method string
+	prefix string
+	reason string
+}{
+	{"", "/v1/sandbox/", "沙箱令牌不能再签发沙箱令牌"},
+	{"", "/v1/tenant", "创建租户使用独立连接执行 DDL 与迁移"},
+	{"", "/v1/dev/", "开发环境接口签发真实令牌、创建测试数据"},
+	{"POST", "/v1/template/:id/restore", "恢复后会删除对象存储中的归档"},
+	{"POST", "/v1/admin/permission-cache/rebuild", "重建的权限缓存由所有请求共用"},
+}
+
+// SandboxDenied 返回接口是否不允许在沙箱中调用及原因
+func SandboxDenied(method, route string) (string, bool) {
+	for _, d := range sandboxDenied {
+		if (d.method == "" || d.method == method) && strings.HasPrefix(route, d.prefix) {
+			return d.reason, true
+		}
+	}
+	return "", false
+}
+
+type sandboxKey struct{}
+
+// sandboxFromContext 返回请求所在的沙箱连接
+func sandboxFromContext(c *gin.Context) *sqlx.DB {
+	if c.Request == nil {
+		return nil
+	}
+	db, _ := c.Request.Context().Value(sandboxKey{}).(*sqlx.DB)
+	return db
+}
+
+// RunSandbox 占用当前请求租户连接池中的一个连接开启事务，在其中执行 run，结束后回滚
+// run 执行期间 DB、BatchDB 返回只包含这个连接的连接池，仓储无需任何改动：
+// 仓储开启的事务转换为保存点，提交即释放保存点、回滚即回滚到保存点，外层事务始终不提交。
+// 同一请求中的并发查询在这个连接上依次执行；回滚失败时连接被丢弃，不会带着未结束的事务回到连接池。
+func (p *TenantPool) RunSandbox(c *gin.Context, run func()) error {
+	ctx := c.Request.Context()
+	conn, err := p.DB(c).Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) (err error) {
+		dc, ok := driverConn.(sandboxDriverConn)
+		if !ok {
+			return fmt.Errorf("driver connection %T does not support sandbox", driverConn)
+		}
+		sandbox := &sandboxConn{conn: dc}
+		if err := sandbox.exec(ctx, "BEGIN"); err != nil {
+			return err
+		}
+		db := sql.OpenDB(sandboxConnector{conn: sandbox})
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		defer func() {
+			db.Close()
+			if rollbackErr := sandbox.exec(context.Background(), "ROLLBACK"); rollbackErr != nil {
+				err = errors.Join(driver.ErrBadConn, rollbackErr)
+			}
+		}()
+
+		request := c.Request
+		c.Request = request.WithContext(context.WithValue(ctx, sandboxKey{}, sqlx.NewDb(db, "postgres")))
+		defer func() { c.Request = request }()
+		run()
+		return nil
+	})
+}
+
+// sandboxDriverConn 沙箱需要的驱动连接能力，lib/pq 的连接均已实现
+type sandboxDriverConn interface {
+	driver.Conn
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+}
+
+// sandboxConnector 始终返回同一个沙箱连接
+type sandboxConnector struct {
+	conn *sandboxConn
+}
+
+func (s sandboxConnector) Connect(context.Context) (driver.Conn, error) {
+	return s.conn, nil
+}
+
+func (s sandboxConnector) Driver() driver.Driver {
+	return sandboxDriver{}
+}
+
+// sandboxDriver 仅用于满足 driver.Connector 接口，沙箱连接池不会通过它建立连接
+type sandboxDriver struct{}
+
+func (sandboxDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("sandbox connections cannot be opened by name")
+}
+
+// sandboxConn 包装已开启事务的驱动连接，把事务转换为保存点
+type sandboxConn struct {
+	conn       sandboxDriverConn
+	savepoints int
+}
+
+func (c *sandboxConn) exec(ctx context.Context, query string) error {
+	_, err := c.conn.ExecContext(ctx, query, nil)
+	return err
+}
+
+func (c *sandboxConn) Prepare(query string) (driver.Stmt, error) {
+	return c.conn.Prepare(query)
+}
+
+func (c *sandboxConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.conn.PrepareContext(ctx, query)
+}
+
+func (c *sandboxConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.conn.ExecContext(ctx, query, args)
+}
+
+func (c *sandboxConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.conn.QueryContext(ctx, query, args)
+}
+
+// Close 底层连接由 RunSandbox 回滚后归还连接池
+func (c *sandboxConn) Close() error {
+	return nil
+}
+
+func (c *sandboxConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx 开启保存点，隔离级别与只读选项沿用外层事务
+func (c *sandboxConn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	c.savepoints++
+	name := "sandbox_" + strconv.Itoa(c.savepoints)
+	if err := c.exec(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &sandboxTx{conn: c, name: name}, nil
+}
+
+// sandboxTx 以保存点模拟的事务
+type sandboxTx struct {
+	conn *sandboxConn
+	name string
+}
+
+func (t *sandboxTx) Commit() error {
+	return t.conn.exec(context.Background(), "RELEASE SAVEPOINT "+t.name)
+}
+
+func (t *sandboxTx) Rollback() error {
+	return t.conn.exec(context.Background(), "ROLLBACK TO SAVEPOINT "+t.name)
+}
@@ -1,36 +1,72 @@
 package pkgs
 
 import (
+	"context"
+	"time"
+
 	"github.com/go-co-op/gocron/v2"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
 // AppScheduler 定时任务调度器
-// 依赖注入：Logger、DB
+// 依赖注入：Logger、BatchDB、TableNames、FieldCipher、PasswordHasher、JobQueue、Retention，后台任务使用批处理连接池，不占用交互请求的连接
 // Start 方法启动定时任务
 
 type Scheduler struct {
 	Logger *zap.Logger
 	DB     *sqlx.DB
+	Tables *TableNames
+	Cipher *FieldCipher
+	Hasher *PasswordHasher
+	Jobs   *JobQueue
+	// 数据保留策略，每天凌晨清理过期数据
+	Retention *Retention
+
+	// 业务模块注册的定时任务
+	tasks []scheduledTask
+}
+
+// scheduledTask 业务模块注册的定时任务
+type scheduledTask struct {
+	name string
+	cron string
+	run  func(ctx context.Context)
 }
 
-func NewScheduler(logger *zap.Logger, db *sqlx.DB) *Scheduler {
+func NewScheduler(logger *zap.Logger, batch *BatchDB, tables *TableNames, cipher *FieldCipher, hasher *PasswordHasher, jobs *JobQueue, retention *Retention) *Scheduler {
 	return &Scheduler{
 		Logger: logger,
-		DB:     db,
+		DB:     batch.DB,
+		Tables: tables,
+		Cipher: cipher,
+		Hasher: hasher,
+		Jobs:   jobs,
+
+		Retention: retention,
 	}
 }
 
+// Register 注册定时任务，须在 Start 之前调用（通常在模块的 Handler 构造函数中）
+// 上一轮未结束时跳过本轮。
+func (s *Scheduler) Register(name, cron string, run func(ctx context.Context)) {
+	s.tasks = append(s.tasks, scheduledTask{name: name, cron: cron, run: run})
+}
+
 func (s *Scheduler) Start() {
 	scheduler, err := gocron.NewScheduler()
 	if err != nil {
 		s.Logger.Error("创建调度器失败", zap.Error(err))
 		return
 	}
+	// 启动时补齐历史数据的敏感字段加密与影子列
+	if err := s.Cipher.BackfillSensitiveColumns(s.DB, s.Logger, s.Tables); err != nil {
+		s.Logger.Error("敏感字段加密补齐失败", zap.Error(err))
+	}
+
 	// 定时任务函数
 	task := func() {
-		if err := InitAdminRoot(s.DB, s.Logger); err != nil {
+		if err := InitAdminRoot(s.DB, s.Logger, s.Tables, s.Hasher); err != nil {
 			s.Logger.Error("定时任务 InitAdminRoot 执行失败", zap.Error(err))
 		} else {
 			s.Logger.Info("定时任务 InitAdminRoot 执行成功")
@@ -48,6 +84,39 @@ func (s *Scheduler) Start() {
 		s.Logger.Error("注册定时任务失败", zap.Error(jobErr))
 		return
 	}
+
+	// 异步任务轮询，上一轮未结束时跳过本轮
+	_, jobErr = scheduler.NewJob(
+		gocron.DurationJob(5*time.Second),
+		gocron.NewTask(func() { s.Jobs.RunAll(context.Background()) }),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if jobErr != nil {
+		s.Logger.Error("注册异步任务轮询失败", zap.Error(jobErr))
+		return
+	}
+
+	// 数据保留策略，每天 03:00 执行
+	_, jobErr = scheduler.NewJob(
+		gocron.CronJob("0 3 * * *", false),
+		gocron.NewTask(func() { s.Retention.RunAll(context.Background()) }),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if jobErr != nil {
+		s.Logger.Error("注册数据保留任务失败", zap.Error(jobErr))
+		return
+	}
+
+	for _, task := range s.tasks {
+		_, jobErr = scheduler.NewJob(
+			gocron.CronJob(task.cron, false),
+			gocron.NewTask(func() { task.run(context.Background()) }),
+			gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		)
+		if jobErr != nil {
+			s.Logger.Error("注册定时任务失败", zap.String("task", task.name), zap.String("cron", task.cron), zap.Error(jobErr))
+		}
+	}
 	scheduler.Start()
 	s.Logger.Info("定时任务 InitAdminRoot 已启动", zap.String("cron", "*/5 * * * *"))
 }
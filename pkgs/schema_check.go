@@ -0,0 +1,232 @@
+package pkgs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// 启动时数据库结构检查模式，对应配置 database.schema_check
+const (
+	SchemaCheckOff    = "off"
+	SchemaCheckWarn   = "warn"
+	SchemaCheckStrict = "strict"
+)
+
+// SchemaColumn information_schema.columns 中的一列
+type SchemaColumn struct {
+	Name     string `db:"column_name"`
+	DataType string `db:"data_type"`
+	UDTName  string `db:"udt_name"`
+	Nullable bool   `db:"nullable"`
+}
+
+// SchemaEntity 需要与数据表结构核对的实体
+// Table 为基础表名（不带前缀与 schema），Entity 为实体零值，
+// Computed 列出实体中由关联查询或表达式得到、不属于该表的列。
+type SchemaEntity struct {
+	Table    string
+	Entity   any
+	Computed []string
+}
+
+// SchemaDrift 实体与数据表结构不一致的一项，Field 为带包名的实体字段（如 user.UserEntity.Password）
+type SchemaDrift struct {
+	Table   string
+	Column  string
+	Field   string
+	Problem string
+}
+
+func (d SchemaDrift) String() string {
+	if d.Column == "" {
+		return d.Table + ": " + d.Problem
+	}
+	return fmt.Sprintf("%s.%s (%s): %s", d.Table, d.Column, d.Field, d.Problem)
+}
+
+// LoadSchemaColumns 查询数据表的列信息，表不存在时返回空 map
+// 配置了 schema 时查询该 schema，否则查询当前 search_path 下的第一个 schema
+func LoadSchemaColumns(ctx context.Context, db *sqlx.DB, tables *TableNames, table string) (map[string]SchemaColumn, error) {
+	query := `SELECT column_name, data_type, udt_name, is_nullable = 'YES' AS nullable
+		FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2`
+	var columns []SchemaColumn
+	if err := db.SelectContext(ctx, &columns, query, tables.Schema, tables.Prefix+table); err != nil {
+		return nil, err
+	}
+	byName := make(map[string]SchemaColumn, len(columns))
+	for _, column := range columns {
+		byName[column.Name] = column
+	}
+	return byName, nil
+}
+
+// CompareSchema 对比实体的 db 标签与数据表的列，返回不一致的项：
+// 1. 实体字段对应的列不存在；
+// 2. 字段的 Go 类型无法扫描该列的数据库类型；
+// 3. 列允许 NULL，字段却不是指针且没有实现 sql.Scanner（扫描到 NULL 时报错）。
+// columns 为空表示数据表不存在。
+func CompareSchema(entity SchemaEntity, columns map[string]SchemaColumn) []SchemaDrift {
+	if len(columns) == 0 {
+		return []SchemaDrift{{Table: entity.Table, Problem: "table does not exist"}}
+	}
+	entityType := reflect.TypeOf(entity.Entity)
+	var drifts []SchemaDrift
+	for _, field := range entityFields(entityType) {
+		if slices.Contains(entity.Computed, field.column) {
+			continue
+		}
+		drift := SchemaDrift{Table: entity.Table, Column: field.column, Field: entityType.String() + "." + field.name}
+		column, ok := columns[field.column]
+		if !ok {
+			drift.Problem = "column does not exist"
+			drifts = append(drifts, drift)
+			continue
+		}
+		if !typeCompatible(field.typ, column) {
+			drift.Problem = fmt.Sprintf("Go type %s cannot scan column type %s", field.typ, columnType(column))
+			drifts = append(drifts, drift)
+			continue
+		}
+		if column.Nullable && !nullSafe(field.typ) {
+			drift.Problem = fmt.Sprintf("column is nullable but field type %s cannot hold NULL (use a pointer or make the column NOT NULL)", field.typ)
+			drifts = append(drifts, drift)
+		}
+	}
+	return drifts
+}
+
+// CheckSchema 逐个查询实体对应数据表的列并对比，返回所有不一致的项
+func CheckSchema(ctx context.Context, db *sqlx.DB, tables *TableNames, entities []SchemaEntity) ([]SchemaDrift, error) {
+	var drifts []SchemaDrift
+	for _, entity := range entities {
+		columns, err := LoadSchemaColumns(ctx, db, tables, entity.Table)
+		if err != nil {
+			return nil, fmt.Errorf("cannot query columns of %s: %w", entity.Table, err)
+		}
+		drifts = append(drifts, CompareSchema(entity, columns)...)
+	}
+	return drifts, nil
+}
+
+// entityField 实体中映射到列的字段
+type entityField struct {
+	name   string
+	column string
+	typ    reflect.Type
+}
+
+// entityFields 按 sqlx 的映射规则列出结构体字段：db 标签为列名，无标签时为小写字段名，
+// "-" 与未导出字段跳过，无标签的嵌入结构体（包括未导出的）展开
+func entityFields(t reflect.Type) []entityField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var fields []entityField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		tag, _, _ = strings.Cut(tag, ",")
+		if f.Anonymous && !hasTag {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, entityFields(embedded)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if tag == "" {
+			tag = strings.ToLower(f.Name)
+		}
+		fields = append(fields, entityField{name: f.Name, column: tag, typ: f.Type})
+	}
+	return fields
+}
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+	rawJSONType = reflect.TypeFor[json.RawMessage]()
+	bytesType   = reflect.TypeFor[[]byte]()
+)
+
+// 各类 Go 值可以扫描的列类型（information_schema.columns.data_type）
+var (
+	textColumnTypes   = []string{"text", "character varying", "character", "uuid", "inet", "cidr", "citext", "USER-DEFINED"}
+	intColumnTypes    = []string{"smallint", "integer", "bigint"}
+	floatColumnTypes  = []string{"real", "double precision", "numeric"}
+	timeColumnTypes   = []string{"timestamp with time zone", "timestamp without time zone", "date"}
+	jsonColumnTypes   = []string{"json", "jsonb"}
+	binaryColumnTypes = []string{"json", "jsonb", "bytea"}
+)
+
+// typeCompatible 判断字段类型能否扫描该列
+// 实现了 sql.Scanner 的类型按底层类型判断：字符串对应文本列，切片对应数组列，结构体与 map 对应 JSON 列，其余不检查
+func typeCompatible(t reflect.Type, column SchemaColumn) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return slices.Contains(timeColumnTypes, column.DataType)
+	case t == rawJSONType || t == bytesType:
+		return slices.Contains(binaryColumnTypes, column.DataType)
+	case reflect.PointerTo(t).Implements(scannerType):
+		switch t.Kind() {
+		case reflect.String:
+			return slices.Contains(textColumnTypes, column.DataType)
+		case reflect.Slice:
+			return column.DataType == "ARRAY"
+		case reflect.Struct, reflect.Map:
+			return slices.Contains(jsonColumnTypes, column.DataType)
+		}
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.String:
+		return slices.Contains(textColumnTypes, column.DataType)
+	case reflect.Bool:
+		return column.DataType == "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return slices.Contains(intColumnTypes, column.DataType)
+	case reflect.Float32, reflect.Float64:
+		return slices.Contains(floatColumnTypes, column.DataType) || slices.Contains(intColumnTypes, column.DataType)
+	}
+	return false
+}
+
+// nullSafe 判断字段类型扫描到 NULL 时是否不会报错
+func nullSafe(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return reflect.PointerTo(t).Implements(scannerType)
+}
+
+// columnType 列类型的显示名称，数组与自定义类型显示 udt_name
+func columnType(column SchemaColumn) string {
+	if column.DataType == "ARRAY" || column.DataType == "USER-DEFINED" {
+		return column.UDTName
+	}
+	return column.DataType
+}
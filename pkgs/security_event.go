@@ -0,0 +1,267 @@
+package pkgs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// 安全事件类型
+const (
+	SecurityEventLoginSuccess          = "auth.login.success"
+	SecurityEventLoginFailure          = "auth.login.failure"
+	SecurityEventTokenRefresh          = "auth.token.refresh"
+	SecurityEventTokenRefreshFailure   = "auth.token.refresh.failure"
+	SecurityEventPermissionDenied      = "auth.permission.denied"
+	SecurityEventSessionsRevoke        = "auth.sessions.revoke"
+	SecurityEventLogout                = "auth.logout"
+	SecurityEventPasswordChange        = "auth.password.change"
+	SecurityEventPasswordChangeFailure = "auth.password.change.failure"
+	SecurityEventPasswordReset         = "iacc.user.password.reset"
+	SecurityEventRoleChange            = "iacc.role.change"
+	SecurityEventUserOffboard          = "iacc.user.offboard"
+	SecurityEventSnapshotExport        = "admin.snapshot.export"
+	SecurityEventSnapshotRestore       = "admin.snapshot.restore"
+	SecurityEventTokensRevoke          = "admin.tokens.revoke"
+	SecurityEventSupportBundleExport   = "admin.support_bundle.export"
+	SecurityEventSettingsUpdate        = "admin.settings.update"
+)
+
+// SIEM 推送方式，对应配置 siem.sink
+const (
+	SIEMSinkSyslog = "syslog"
+	SIEMSinkHTTP   = "http"
+	SIEMSinkKafka  = "kafka"
+)
+
+// SecurityEvent 推送到 SIEM 的结构化安全事件
+type SecurityEvent struct {
+	Type      string         `json:"type"`
+	Time      time.Time      `json:"time"`
+	App       string         `json:"app,omitempty"`
+	UserID    string         `json:"user_id,omitempty"`
+	IP        string         `json:"ip,omitempty"`
+	UserAgent string         `json:"user_agent,omitempty"`
+	Method    string         `json:"method,omitempty"`
+	Path      string         `json:"path,omitempty"`
+	Tenant    string         `json:"tenant,omitempty"`
+	TraceID   string         `json:"trace_id,omitempty"`
+	Detail    map[string]any `json:"detail,omitempty"`
+}
+
+// SecuritySink 安全事件的推送目标，Send 返回错误时整批重试
+type SecuritySink interface {
+	Send(ctx context.Context, events []SecurityEvent) error
+	Close() error
+}
+
+var securityEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "security_events_total",
+	Help: "Security events by result (sent, dropped when the queue is full, failed after retries).",
+}, []string{"result"})
+
+// SecurityEvents 安全事件的异步批量推送
+// 事件先写入内存队列，后台协程按 batch_size 或 flush_interval 批量推送，失败时按指数退避重试 max_retries 次。
+// 推送变慢时队列逐渐积压，队列满后请求最多等待 enqueue_timeout，仍无法写入则丢弃事件并计入指标，不会拖垮业务请求。
+// 未配置 siem.sink 时所有方法都是空操作。
+type SecurityEvents struct {
+	sink           SecuritySink
+	app            string
+	queue          chan SecurityEvent
+	batchSize      int
+	flushInterval  time.Duration
+	maxRetries     int
+	timeout        time.Duration
+	enqueueTimeout time.Duration
+	logger         *zap.Logger
+
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func NewSecurityEvents(config *Config, logger *zap.Logger) (*SecurityEvents, func(), error) {
+	s := &SecurityEvents{app: config.App.Name, logger: logger}
+	if config.SIEM.Sink == "" {
+		return s, func() {}, nil
+	}
+	sink, err := NewSecuritySink(config.SIEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create SIEM sink: %w", err)
+	}
+	return NewSecurityEventsWithSink(config, sink, logger)
+}
+
+// NewSecurityEventsWithSink 使用指定的推送目标创建，用于自定义适配器与测试
+func NewSecurityEventsWithSink(config *Config, sink SecuritySink, logger *zap.Logger) (*SecurityEvents, func(), error) {
+	siem := config.SIEM
+	s := &SecurityEvents{
+		sink:           sink,
+		app:            config.App.Name,
+		queue:          make(chan SecurityEvent, siem.BufferSize),
+		batchSize:      siem.BatchSize,
+		flushInterval:  siem.FlushInterval,
+		maxRetries:     siem.MaxRetries,
+		timeout:        siem.Timeout,
+		enqueueTimeout: siem.EnqueueTimeout,
+		logger:         logger,
+		done:           make(chan struct{}),
+		stopped:        make(chan struct{}),
+	}
+	go s.run()
+	return s, s.Close, nil
+}
+
+// Enabled 是否配置了推送目标
+func (s *SecurityEvents) Enabled() bool {
+	return s != nil && s.sink != nil
+}
+
+// Record 记录当前请求的安全事件，自动带上用户、来源 IP、请求路径、租户与请求ID
+func (s *SecurityEvents) Record(c *gin.Context, eventType string, detail map[string]any) {
+	s.RecordUser(c, CurrentUserID(c), eventType, detail)
+}
+
+// RecordUser 同 Record，用于登录等请求上下文中还没有当前用户的场景
+func (s *SecurityEvents) RecordUser(c *gin.Context, userID string, eventType string, detail map[string]any) {
+	if !s.Enabled() {
+		return
+	}
+	s.Emit(SecurityEvent{
+		Type:      eventType,
+		UserID:    userID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Tenant:    TenantFromContext(c),
+		TraceID:   TraceIDFromContext(c),
+		Detail:    detail,
+	})
+}
+
+// Emit 写入事件队列，队列满时最多等待 enqueue_timeout，超时丢弃
+func (s *SecurityEvents) Emit(event SecurityEvent) {
+	if !s.Enabled() {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if event.App == "" {
+		event.App = s.app
+	}
+
+	select {
+	case s.queue <- event:
+		return
+	default:
+	}
+	if s.enqueueTimeout > 0 {
+		timer := time.NewTimer(s.enqueueTimeout)
+		defer timer.Stop()
+		select {
+		case s.queue <- event:
+			return
+		case <-timer.C:
+		}
+	}
+	securityEventsTotal.WithLabelValues("dropped").Inc()
+}
+
+// Close 推送队列中剩余的事件后关闭推送目标
+func (s *SecurityEvents) Close() {
+	if !s.Enabled() {
+		return
+	}
+	s.once.Do(func() {
+		close(s.done)
+		<-s.stopped
+		if err := s.sink.Close(); err != nil {
+			s.logger.Warn("关闭 SIEM 推送失败", zap.Error(err))
+		}
+	})
+}
+
+func (s *SecurityEvents) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SecurityEvent, 0, s.batchSize)
+	add := func(event SecurityEvent) {
+		batch = append(batch, event)
+		if len(batch) >= s.batchSize {
+			s.flush(batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case event := <-s.queue:
+			add(event)
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-s.done:
+			// 退出前推送队列中剩余的事件
+			for {
+				select {
+				case event := <-s.queue:
+					add(event)
+				default:
+					if len(batch) > 0 {
+						s.flush(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush 推送一批事件，失败时按指数退避重试，重试期间队列继续积压
+func (s *SecurityEvents) flush(batch []SecurityEvent) {
+	backoff := 200 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		err := s.sink.Send(ctx, batch)
+		cancel()
+		if err == nil {
+			securityEventsTotal.WithLabelValues("sent").Add(float64(len(batch)))
+			return
+		}
+		if attempt >= s.maxRetries {
+			securityEventsTotal.WithLabelValues("failed").Add(float64(len(batch)))
+			s.logger.Error("推送安全事件失败，已丢弃", zap.Int("events", len(batch)), zap.Error(err))
+			return
+		}
+		s.logger.Warn("推送安全事件失败，稍后重试", zap.Int("attempt", attempt+1), zap.Error(err))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// RecordSecurityEvent 返回在结果成功后记录安全事件的管道步骤，结果值记录在 detail.result 中
+// 用于角色变更等需要审计的接口，放在仓储操作之后
+func RecordSecurityEvent[T any](c *gin.Context, events *SecurityEvents, eventType string, detail map[string]any) func(T) T {
+	return func(v T) T {
+		if events.Enabled() {
+			d := make(map[string]any, len(detail)+1)
+			for k, val := range detail {
+				d[k] = val
+			}
+			d["result"] = v
+			events.Record(c, eventType, d)
+		}
+		return v
+	}
+}
@@ -0,0 +1,145 @@
+package pkgs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/http"
+	"strings"
+)
+
+// NewSecuritySink 按配置创建安全事件的推送目标
+func NewSecuritySink(config SIEMConfig) (SecuritySink, error) {
+	switch config.Sink {
+	case SIEMSinkSyslog:
+		return newSyslogSink(config.Syslog)
+	case SIEMSinkHTTP:
+		return &httpSink{client: &http.Client{}, url: config.HTTP.URL, headers: config.HTTP.Headers, format: config.HTTP.Format}, nil
+	case SIEMSinkKafka:
+		return &kafkaRESTSink{client: &http.Client{}, url: strings.TrimRight(config.Kafka.RESTURL, "/") + "/topics/" + config.Kafka.Topic}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink %q", config.Sink)
+	}
+}
+
+// syslogSink 每个事件以一行 JSON 写入 syslog，失败与拒绝类事件使用 warning 级别
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func newSyslogSink(config SIEMSyslogConfig) (*syslogSink, error) {
+	writer, err := syslog.Dial(config.Network, config.Address, syslog.LOG_INFO|syslog.LOG_AUTH, config.Tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) Send(_ context.Context, events []SecurityEvent) error {
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(event.Type, ".failure") || strings.HasSuffix(event.Type, ".denied") {
+			err = s.writer.Warning(string(line))
+		} else {
+			err = s.writer.Info(string(line))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
+
+// httpSink 以 POST 推送一批事件
+// format 为 json 时请求体为 JSON 数组；为 splunk_hec 时按 Splunk HTTP Event Collector 格式逐行拼接
+type httpSink struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+	format  string
+}
+
+func (s *httpSink) Send(ctx context.Context, events []SecurityEvent) error {
+	var body bytes.Buffer
+	contentType := "application/json"
+	if s.format == SIEMFormatSplunkHEC {
+		encoder := json.NewEncoder(&body)
+		for _, event := range events {
+			hec := map[string]any{
+				"time":       float64(event.Time.UnixMilli()) / 1000,
+				"sourcetype": "_json",
+				"source":     event.App,
+				"event":      event,
+			}
+			if err := encoder.Encode(hec); err != nil {
+				return err
+			}
+		}
+	} else if err := json.NewEncoder(&body).Encode(events); err != nil {
+		return err
+	}
+	return postJSON(ctx, s.client, s.url, contentType, s.headers, &body)
+}
+
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// kafkaRESTSink 通过 Kafka REST Proxy（v2 API）写入主题，以用户ID为消息键保证同一用户的事件有序
+type kafkaRESTSink struct {
+	client *http.Client
+	url    string
+}
+
+func (s *kafkaRESTSink) Send(ctx context.Context, events []SecurityEvent) error {
+	type record struct {
+		Key   string        `json:"key,omitempty"`
+		Value SecurityEvent `json:"value"`
+	}
+	records := make([]record, len(events))
+	for i, event := range events {
+		records[i] = record{Key: event.UserID, Value: event}
+	}
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(map[string]any{"records": records}); err != nil {
+		return err
+	}
+	return postJSON(ctx, s.client, s.url, "application/vnd.kafka.json.v2+json", nil, &body)
+}
+
+func (s *kafkaRESTSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url, contentType string, headers map[string]string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
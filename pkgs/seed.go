@@ -0,0 +1,206 @@
+package pkgs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/swaggo/swag"
+	"go.uber.org/zap"
+)
+
+// 权限名称的最大长度，与 iacc_permission.name 一致
+const permissionNameMaxLen = 50
+
+// PermissionDef 服务定义的一个接口权限，取自接口文档中的 x-permission 扩展
+type PermissionDef struct {
+	// 接口摘要，创建权限时作为权限名称
+	Name   string
+	Method string
+	Path   string
+}
+
+// Permissions 返回服务定义的全部接口权限，按 path、method 排序
+// 接口权限以处理器注释中的 @x-permission 为准，随 swagger 文档生成；编码权限（如 docs:view）由迁移预置，不在其中。
+// 调用方所在的程序须导入 go-pg-demo/docs 注册接口文档。
+func Permissions() ([]PermissionDef, error) {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		return nil, fmt.Errorf("读取接口文档失败: %w", err)
+	}
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Summary    string `json:"summary"`
+			Permission *struct {
+				Method string `json:"method"`
+				Path   string `json:"path"`
+			} `json:"x-permission"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(doc), &spec); err != nil {
+		return nil, fmt.Errorf("解析接口文档失败: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var defs []PermissionDef
+	for _, operations := range spec.Paths {
+		for _, op := range operations {
+			if op.Permission == nil || seen[op.Permission.Method+" "+op.Permission.Path] {
+				continue
+			}
+			seen[op.Permission.Method+" "+op.Permission.Path] = true
+			defs = append(defs, PermissionDef{Name: strings.TrimSpace(op.Summary), Method: op.Permission.Method, Path: op.Permission.Path})
+		}
+	}
+	slices.SortFunc(defs, func(a, b PermissionDef) int {
+		return strings.Compare(a.Path+" "+a.Method, b.Path+" "+b.Method)
+	})
+	return defs, nil
+}
+
+// seedRoles 种子数据中的基础角色，grant 筛选授予的接口权限；拥有全部权限的 root 角色由 InitAdminRoot 创建
+var seedRoles = []struct {
+	name        string
+	description string
+	grant       func(PermissionDef) bool
+}{
+	{"viewer", "只读：除管理与审计接口外的全部查询接口", func(p PermissionDef) bool {
+		return p.Method == http.MethodGet && !strings.HasPrefix(p.Path, "/v1/admin/") && !strings.HasPrefix(p.Path, "/v1/audit/")
+	}},
+	{"auditor", "审计：查询与导出审计日志", func(p PermissionDef) bool {
+		return p.Method == http.MethodGet && strings.HasPrefix(p.Path, "/v1/audit/")
+	}},
+}
+
+// SeedResult 写入种子数据的结果
+type SeedResult struct {
+	// 新建的接口权限数与基础角色
+	PermissionsCreated int
+	RolesCreated       []string
+}
+
+// Seed 幂等地写入种子数据，新数据库执行迁移后无需手写 SQL 即可使用：
+// 1. 为 Permissions 中还没有记录的接口权限（按 method + path 判断）创建权限，名称为接口摘要，与已有名称重复时改用 "METHOD path"；
+// 2. 创建缺少的基础角色（见 seedRoles），新建的角色授予全部匹配的权限，已有的角色只追加本次新建的权限，不恢复管理员移除的授权；
+// 3. 执行 InitAdminRoot，创建超级管理员 administrator 与拥有全部权限的 root 角色。
+func Seed(db *sqlx.DB, logger *zap.Logger, tables *TableNames, hasher *PasswordHasher) (SeedResult, error) {
+	var result SeedResult
+	defs, err := Permissions()
+	if err != nil {
+		return result, err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return result, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing []struct {
+		Name   string  `db:"name"`
+		Method *string `db:"method"`
+		Path   *string `db:"path"`
+	}
+	if err := tx.Select(&existing, `SELECT name, metadata->>'method' AS method, metadata->>'path' AS path FROM `+tables.Permission); err != nil {
+		return result, fmt.Errorf("查询权限失败: %w", err)
+	}
+	names := map[string]bool{}
+	routes := map[string]bool{}
+	for _, p := range existing {
+		names[p.Name] = true
+		if p.Method != nil && p.Path != nil {
+			routes[*p.Method+" "+*p.Path] = true
+		}
+	}
+
+	// 本次新建的权限ID，按 defs 的下标记录
+	created := map[int]string{}
+	for i, def := range defs {
+		route := def.Method + " " + def.Path
+		if routes[route] {
+			continue
+		}
+		name := def.Name
+		if name == "" || names[name] || utf8.RuneCountInString(name) > permissionNameMaxLen {
+			name = truncateRunes(route, permissionNameMaxLen)
+		}
+		if names[name] {
+			logger.Warn("权限名称已被占用，跳过", zap.String("route", route), zap.String("name", name))
+			continue
+		}
+		metadata, _ := json.Marshal(map[string]string{"method": def.Method, "path": def.Path})
+		var id string
+		query := `INSERT INTO ` + tables.Permission + ` (name, type, metadata, namespace) VALUES ($1, 'api', $2, NULLIF($3, '')) RETURNING id`
+		if err := tx.Get(&id, query, name, metadata, DefaultPermissionNamespace("", def.Path)); err != nil {
+			return result, fmt.Errorf("创建权限 %s 失败: %w", route, err)
+		}
+		names[name] = true
+		created[i] = id
+	}
+	result.PermissionsCreated = len(created)
+	logger.Info("接口权限已同步", zap.Int("total", len(defs)), zap.Int("created", len(created)))
+
+	for _, role := range seedRoles {
+		var roleID string
+		err := tx.Get(&roleID, `INSERT INTO `+tables.Role+` (name, description) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING RETURNING id`, role.name, role.description)
+		isNew := err == nil
+		if errors.Is(err, sql.ErrNoRows) {
+			err = tx.Get(&roleID, `SELECT id FROM `+tables.Role+` WHERE name = $1`, role.name)
+		}
+		if err != nil {
+			return result, fmt.Errorf("创建角色 %s 失败: %w", role.name, err)
+		}
+
+		var grants []string
+		if isNew {
+			// 新建的角色授予全部匹配的权限，包括已有的权限，按 method + path 查询其ID
+			var methods, paths []string
+			for _, def := range defs {
+				if role.grant(def) {
+					methods, paths = append(methods, def.Method), append(paths, def.Path)
+				}
+			}
+			query := `SELECT p.id FROM ` + tables.Permission + ` p JOIN unnest($1::text[], $2::text[]) AS u(method, path)
+				ON p.metadata->>'method' = u.method AND p.metadata->>'path' = u.path`
+			var ids []string
+			if err := tx.Select(&ids, query, PGArray(methods), PGArray(paths)); err != nil {
+				return result, fmt.Errorf("查询角色 %s 的权限失败: %w", role.name, err)
+			}
+			grants = append(grants, ids...)
+			result.RolesCreated = append(result.RolesCreated, role.name)
+		} else {
+			for i, def := range defs {
+				if id, ok := created[i]; ok && role.grant(def) {
+					grants = append(grants, id)
+				}
+			}
+		}
+		query := `INSERT INTO ` + tables.RolePermission + ` (role_id, permission_id) SELECT $1, unnest($2::uuid[]) ON CONFLICT DO NOTHING`
+		if _, err := tx.Exec(query, roleID, PGArray(grants)); err != nil {
+			return result, fmt.Errorf("为角色 %s 授予权限失败: %w", role.name, err)
+		}
+		logger.Info("基础角色已同步", zap.String("role", role.name), zap.Bool("created", isNew), zap.Int("granted", len(grants)))
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	if err := InitAdminRoot(db, logger, tables, hasher); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// truncateRunes 截断到最多 n 个字符
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,390 @@
+package pkgs
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 可在线调整的设置项的键
+const (
+	SettingAccessTokenExpire  = "jwt.access_token_expire"
+	SettingRefreshTokenExpire = "jwt.refresh_token_expire"
+	SettingPasswordPolicy     = "password_policy"
+	SettingRateLimitTiers     = "public_api.tiers"
+)
+
+// 设置项取值的来源
+const (
+	SettingSourceConfig   = "config"
+	SettingSourceDatabase = "database"
+)
+
+// SettingDefinition 可在线调整的设置项
+type SettingDefinition struct {
+	Key         string
+	Description string
+	// 配置文件中的取值，数据库中没有该设置项时使用；按 JSON 序列化后即为设置项的格式
+	Default func(config *Config) any
+	// 解析并校验设置项的 JSON 值，返回读取方法使用的取值
+	Parse func(raw json.RawMessage) (any, error)
+}
+
+// settingDefinitions 已注册的设置项，键与配置文件中的路径一致
+var settingDefinitions = []SettingDefinition{
+	{
+		Key:         SettingAccessTokenExpire,
+		Description: "未指定客户端登录时访问令牌的有效期（如 15m），已签发的令牌不受影响",
+		Default:     func(config *Config) any { return config.JWT.AccessTokenExpire.String() },
+		Parse:       parseSettingDuration,
+	},
+	{
+		Key:         SettingRefreshTokenExpire,
+		Description: "未指定客户端登录时刷新令牌的有效期（如 24h），已签发的令牌不受影响",
+		Default:     func(config *Config) any { return config.JWT.RefreshTokenExpire.String() },
+		Parse:       parseSettingDuration,
+	},
+	{
+		Key:         SettingPasswordPolicy,
+		Description: "新密码的安全策略：最短长度、是否必须包含字母与数字，只在设置新密码时校验，已有密码仍可登录",
+		Default:     func(config *Config) any { return config.PasswordPolicy },
+		Parse: func(raw json.RawMessage) (any, error) {
+			var policy PasswordPolicy
+			if err := decodeSetting(raw, &policy); err != nil {
+				return nil, err
+			}
+			return policy, policy.Validate()
+		},
+	},
+	{
+		Key:         SettingRateLimitTiers,
+		Description: "公开接口的限流等级，键为等级名称；删除仍被 API 密钥使用的等级后，这些密钥的请求被拒绝",
+		Default:     func(config *Config) any { return config.PublicAPI.Tiers },
+		Parse: func(raw json.RawMessage) (any, error) {
+			var tiers map[string]RateLimitTier
+			if err := decodeSetting(raw, &tiers); err != nil {
+				return nil, err
+			}
+			if tiers == nil {
+				tiers = map[string]RateLimitTier{}
+			}
+			for name, tier := range tiers {
+				if tier.Limit <= 0 || tier.Window <= 0 {
+					return nil, fmt.Errorf("tier %s: limit and window must be positive", name)
+				}
+			}
+			return tiers, nil
+		},
+	},
+}
+
+// SettingDefinitionByKey 按键查找设置项
+func SettingDefinitionByKey(key string) (SettingDefinition, bool) {
+	for _, definition := range settingDefinitions {
+		if definition.Key == key {
+			return definition, true
+		}
+	}
+	return SettingDefinition{}, false
+}
+
+// decodeSetting 解析设置项的 JSON 值，不允许未知字段，避免拼错的字段被静默忽略
+func decodeSetting(raw json.RawMessage, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// parseSettingDuration 解析 Go 时长字符串（如 15m、24h），必须大于 0
+func parseSettingDuration(raw json.RawMessage) (any, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("must be a duration string such as 15m")
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, err
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("must be positive, got %s", s)
+	}
+	return d, nil
+}
+
+// rateLimitTierJSON RateLimitTier 在设置项中的格式，时间窗口为 Go 时长字符串
+type rateLimitTierJSON struct {
+	Limit  int    `json:"limit"`
+	Window string `json:"window"`
+	Shadow bool   `json:"shadow"`
+}
+
+func (t RateLimitTier) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rateLimitTierJSON{Limit: t.Limit, Window: t.Window.String(), Shadow: t.Shadow})
+}
+
+func (t *RateLimitTier) UnmarshalJSON(data []byte) error {
+	var v rateLimitTierJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&v); err != nil {
+		return err
+	}
+	window, err := time.ParseDuration(v.Window)
+	if err != nil {
+		return fmt.Errorf("window: %w", err)
+	}
+	*t = RateLimitTier{Limit: v.Limit, Window: window, Shadow: v.Shadow}
+	return nil
+}
+
+// SettingItem 设置项的当前取值
+type SettingItem struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	// 当前生效的取值
+	Value json.RawMessage `json:"value" swaggertype:"object"`
+	// 配置文件中的取值
+	Default json.RawMessage `json:"default" swaggertype:"object"`
+	// 取值来源，见 SettingSourceConfig/Database
+	Source string `json:"source"`
+	// 在线修改的时间与修改人，来源为配置文件时为空
+	UpdatedAt *time.Time `json:"updated_at"`
+	UpdatedBy *string    `json:"updated_by"`
+}
+
+// settingRow 数据库表 settings 中的设置项
+type settingRow struct {
+	Key       string          `db:"key"`
+	Value     json.RawMessage `db:"value"`
+	UpdatedAt time.Time       `db:"updated_at"`
+	UpdatedBy *string         `db:"updated_by"`
+}
+
+// Settings 运行时设置
+// 令牌有效期、密码策略、限流等级等可以通过 /v1/admin/settings 在线调整，保存在 settings 表中，没有保存的设置项使用配置文件中的取值。
+// 读取方法只读内存中的缓存，不访问数据库：启动时加载一次，之后按 settings.reload_schedule 定时重新加载；
+// 本实例修改后立即生效，其他实例在下次加载后生效。设置对整个部署生效，schema-per-tenant 模式下同样只保存在主 schema 中。
+type Settings struct {
+	db     *sqlx.DB
+	tables *TableNames
+	config *Config
+	logger *zap.Logger
+
+	mu sync.RWMutex
+	// 当前生效的取值，键为设置项的键
+	values map[string]any
+}
+
+func NewSettings(config *Config, db *sqlx.DB, tables *TableNames, logger *zap.Logger) *Settings {
+	s := &Settings{db: db, tables: tables, config: config, logger: logger}
+	s.values = s.defaults()
+	return s
+}
+
+// defaults 返回配置文件中的取值，配置文件中的取值无效时跳过（读取方法返回零值）
+func (s *Settings) defaults() map[string]any {
+	values := make(map[string]any, len(settingDefinitions))
+	for _, definition := range settingDefinitions {
+		raw, err := json.Marshal(definition.Default(s.config))
+		if err == nil {
+			var value any
+			if value, err = definition.Parse(raw); err == nil {
+				values[definition.Key] = value
+				continue
+			}
+		}
+		s.logger.Error("配置文件中的设置项无效", zap.String("key", definition.Key), zap.Error(err))
+	}
+	return values
+}
+
+// Load 从数据库加载设置项，替换内存中的缓存；数据库中的取值无效（如升级后格式变化）时使用配置文件中的取值
+func (s *Settings) Load(ctx context.Context) error {
+	var rows []settingRow
+	query := `SELECT key, value, updated_at, updated_by FROM ` + s.tables.Settings
+	if err := s.db.SelectContext(ctx, &rows, query); err != nil {
+		return err
+	}
+	values := s.defaults()
+	for _, row := range rows {
+		definition, ok := SettingDefinitionByKey(row.Key)
+		if !ok {
+			continue
+		}
+		value, err := definition.Parse(row.Value)
+		if err != nil {
+			s.logger.Warn("数据库中的设置项无效，使用配置文件中的取值", zap.String("key", row.Key), zap.Error(err))
+			continue
+		}
+		values[row.Key] = value
+	}
+
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+	return nil
+}
+
+// Reload 定时重新加载设置项，失败时保留当前的取值
+func (s *Settings) Reload(ctx context.Context) {
+	if err := s.Load(ctx); err != nil {
+		s.logger.Error("加载运行时设置失败", zap.Error(err))
+	}
+}
+
+// get 返回设置项当前生效的取值
+func (s *Settings) get(key string) any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[key]
+}
+
+// AccessTokenExpire 未指定客户端时访问令牌的有效期
+func (s *Settings) AccessTokenExpire() time.Duration {
+	d, _ := s.get(SettingAccessTokenExpire).(time.Duration)
+	return d
+}
+
+// RefreshTokenExpire 未指定客户端时刷新令牌的有效期
+func (s *Settings) RefreshTokenExpire() time.Duration {
+	d, _ := s.get(SettingRefreshTokenExpire).(time.Duration)
+	return d
+}
+
+// PasswordPolicy 新密码的安全策略
+func (s *Settings) PasswordPolicy() PasswordPolicy {
+	policy, _ := s.get(SettingPasswordPolicy).(PasswordPolicy)
+	return policy
+}
+
+// RateLimitTier 按名称查找公开接口的限流等级
+func (s *Settings) RateLimitTier(name string) (RateLimitTier, bool) {
+	tiers, _ := s.get(SettingRateLimitTiers).(map[string]RateLimitTier)
+	tier, ok := tiers[name]
+	return tier, ok
+}
+
+// List 返回全部设置项当前生效的取值、配置文件中的取值与最近一次修改
+func (s *Settings) List(ctx context.Context) ([]SettingItem, error) {
+	var rows []settingRow
+	query := `SELECT key, value, updated_at, updated_by FROM ` + s.tables.Settings
+	if err := s.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]settingRow, len(rows))
+	for _, row := range rows {
+		byKey[row.Key] = row
+	}
+
+	list := make([]SettingItem, 0, len(settingDefinitions))
+	for _, definition := range settingDefinitions {
+		raw, err := json.Marshal(definition.Default(s.config))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", definition.Key, err)
+		}
+		item := SettingItem{Key: definition.Key, Description: definition.Description, Value: raw, Default: raw, Source: SettingSourceConfig}
+		if row, ok := byKey[definition.Key]; ok {
+			if _, err := definition.Parse(row.Value); err == nil {
+				item.Value, item.Source = row.Value, SettingSourceDatabase
+				item.UpdatedAt, item.UpdatedBy = &row.UpdatedAt, row.UpdatedBy
+			}
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// Update 在一个事务内修改设置项并写入变更历史，返回实际发生变化的设置项
+// values 中取值为 null 的设置项恢复为配置文件中的取值；设置项不存在或取值无效时返回 400，不修改任何设置项。
+// 提交后立即重新加载本实例的缓存。
+func (s *Settings) Update(ctx context.Context, userID string, values map[string]json.RawMessage) ([]string, error) {
+	keys := slices.Sorted(maps.Keys(values))
+	for _, key := range keys {
+		definition, ok := SettingDefinitionByKey(key)
+		if !ok {
+			return nil, NewApiError(http.StatusBadRequest, "设置项不存在："+key)
+		}
+		if isJSONNull(values[key]) {
+			continue
+		}
+		if _, err := definition.Parse(values[key]); err != nil {
+			return nil, NewApiError(http.StatusBadRequest, "设置项取值无效："+key+"："+err.Error())
+		}
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var changed []string
+	for _, key := range keys {
+		// 锁定原有的行，并发修改同一设置项时按顺序写入历史
+		var old json.RawMessage
+		err := tx.GetContext(ctx, &old, `SELECT value FROM `+s.tables.Settings+` WHERE key = $1 FOR UPDATE`, key)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		if isJSONNull(values[key]) {
+			if old == nil {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+s.tables.Settings+` WHERE key = $1`, key); err != nil {
+				return nil, err
+			}
+		} else {
+			value = values[key]
+			query := `INSERT INTO ` + s.tables.Settings + ` AS s (key, value, updated_by) VALUES ($1, $2, NULLIF($3, '')::uuid)
+				ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by
+				WHERE s.value IS DISTINCT FROM EXCLUDED.value`
+			res, err := tx.ExecContext(ctx, query, key, []byte(value), userID)
+			if err != nil {
+				return nil, err
+			}
+			if affected, err := res.RowsAffected(); err != nil {
+				return nil, err
+			} else if affected == 0 {
+				continue
+			}
+		}
+
+		query := `INSERT INTO ` + s.tables.SettingsHistory + ` (key, old_value, new_value, changed_by) VALUES ($1, $2, $3, NULLIF($4, '')::uuid)`
+		if _, err := tx.ExecContext(ctx, query, key, nullableJSON(old), nullableJSON(value), userID); err != nil {
+			return nil, err
+		}
+		changed = append(changed, key)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if len(changed) > 0 {
+		s.logger.Info("运行时设置已修改", zap.Strings("keys", changed), zap.String("user_id", userID))
+		if err := s.Load(ctx); err != nil {
+			s.logger.Error("加载运行时设置失败", zap.Error(err))
+		}
+	}
+	return changed, nil
+}
+
+// nullableJSON 空值写入数据库时为 NULL
+func nullableJSON(raw json.RawMessage) any {
+	if raw == nil {
+		return nil
+	}
+	return []byte(raw)
+}
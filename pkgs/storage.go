@@ -0,0 +1,142 @@
+package pkgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 对象存储驱动，对应配置 storage.driver
+const (
+	StorageDriverFile = "file"
+	StorageDriverS3   = "s3"
+)
+
+// ErrObjectNotFound 对象不存在
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectStore 对象存储后端，key 为以 / 分隔的对象路径
+type ObjectStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get 对象不存在时返回 ErrObjectNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete 对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+// Storage 对象存储，用于归档等不适合留在数据库中的数据
+// 未配置 storage.driver 时不可用，依赖存储的功能应先检查 Enabled。
+// 所有 key 都会加上配置的 storage.prefix，多个环境可以共用一个 bucket。
+type Storage struct {
+	store  ObjectStore
+	prefix string
+}
+
+func NewStorage(config *Config) (*Storage, error) {
+	s := &Storage{prefix: config.Storage.Prefix}
+	switch config.Storage.Driver {
+	case "":
+	case StorageDriverFile:
+		s.store = &fileStore{dir: config.Storage.File.Dir}
+	case StorageDriverS3:
+		s.store = newS3Store(config.Storage.S3, config.Storage.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.Storage.Driver)
+	}
+	return s, nil
+}
+
+// NewStorageWith 使用指定的后端创建对象存储，便于测试或接入其他存储服务
+func NewStorageWith(store ObjectStore, prefix string) *Storage {
+	return &Storage{store: store, prefix: prefix}
+}
+
+// Enabled 是否配置了对象存储
+func (s *Storage) Enabled() bool {
+	return s.store != nil
+}
+
+func (s *Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !s.Enabled() {
+		return errors.New("storage is not configured")
+	}
+	return s.store.Put(ctx, s.prefix+key, data, contentType)
+}
+
+func (s *Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	if !s.Enabled() {
+		return nil, ErrObjectNotFound
+	}
+	return s.store.Get(ctx, s.prefix+key)
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if !s.Enabled() {
+		return nil
+	}
+	return s.store.Delete(ctx, s.prefix+key)
+}
+
+// fileStore 以本地目录保存对象，适合开发环境或挂载的网络存储
+type fileStore struct {
+	dir string
+}
+
+// path 返回对象的文件路径，拒绝跳出存储目录的 key
+func (s *fileStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+// Put 先写临时文件再重命名，读取方不会看到写了一半的对象
+func (s *fileStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *fileStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return data, err
+}
+
+func (s *fileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
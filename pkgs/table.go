@@ -0,0 +1,158 @@
+package pkgs
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 项目内所有数据表的基础名称（与 migration/db 下的建表语句保持一致）
+var baseTableNames = []string{
+	"iacc_user_device",
+	"iacc_user_search",
+	"iacc_token_revocation_rule",
+	"iacc_token_revocation",
+	"iacc_refresh_token",
+	"iacc_user_role",
+	"iacc_role_permission_namespace",
+	"iacc_role_permission",
+	"iacc_role_change",
+	"iacc_offboarding",
+	"iacc_blueprint",
+	"iacc_attribute",
+	"iacc_user",
+	"iacc_role",
+	"iacc_permission",
+	"iacc_client",
+	"template_usage",
+	"template_tombstone",
+	"api_key",
+	"async_job",
+	"retention_policy",
+	"settings_history",
+	"settings",
+	"saved_view",
+	"audit_log",
+	"template",
+	"watch",
+}
+
+// 已被后续迁移删除的表，仍出现在历史迁移文件中，迁移时同样需要加前缀
+var droppedTableNames = []string{
+	"audit_export_file",
+	"audit_export_preset",
+}
+
+// TableNames 数据表名称注册表
+// 根据配置中的 schema 和 table_prefix 生成实际使用的表名，所有仓储层统一从这里获取表名，
+// 便于将应用部署到与其他系统共享的数据库中而不产生表名冲突。
+type TableNames struct {
+	Schema string
+	Prefix string
+
+	User                    string
+	Role                    string
+	Permission              string
+	UserRole                string
+	UserDevice              string
+	UserSearch              string
+	TokenRevocation         string
+	TokenRevocationRule     string
+	RefreshToken            string
+	RolePermission          string
+	RolePermissionNamespace string
+	RoleChange              string
+	Offboarding             string
+	Blueprint               string
+	Attribute               string
+	Client                  string
+	Template                string
+	TemplateUsage           string
+	TemplateTombstone       string
+	APIKey                  string
+	AsyncJob                string
+	Retention               string
+	SavedView               string
+	AuditLog                string
+	Watch                   string
+	Settings                string
+	SettingsHistory         string
+}
+
+// NewTableNames 根据配置创建表名注册表
+func NewTableNames(config *Config) *TableNames {
+	t := &TableNames{
+		Schema: config.Database.Schema,
+		Prefix: config.Database.TablePrefix,
+	}
+	t.User = t.Name("iacc_user")
+	t.Role = t.Name("iacc_role")
+	t.Permission = t.Name("iacc_permission")
+	t.UserRole = t.Name("iacc_user_role")
+	t.UserDevice = t.Name("iacc_user_device")
+	t.UserSearch = t.Name("iacc_user_search")
+	t.TokenRevocation = t.Name("iacc_token_revocation")
+	t.TokenRevocationRule = t.Name("iacc_token_revocation_rule")
+	t.RefreshToken = t.Name("iacc_refresh_token")
+	t.RolePermission = t.Name("iacc_role_permission")
+	t.RolePermissionNamespace = t.Name("iacc_role_permission_namespace")
+	t.RoleChange = t.Name("iacc_role_change")
+	t.Offboarding = t.Name("iacc_offboarding")
+	t.Blueprint = t.Name("iacc_blueprint")
+	t.Attribute = t.Name("iacc_attribute")
+	t.Client = t.Name("iacc_client")
+	t.Template = t.Name("template")
+	t.TemplateUsage = t.Name("template_usage")
+	t.TemplateTombstone = t.Name("template_tombstone")
+	t.APIKey = t.Name("api_key")
+	t.AsyncJob = t.Name("async_job")
+	t.Retention = t.Name("retention_policy")
+	t.SavedView = t.Name("saved_view")
+	t.AuditLog = t.Name("audit_log")
+	t.Watch = t.Name("watch")
+	t.Settings = t.Name("settings")
+	t.SettingsHistory = t.Name("settings_history")
+	return t
+}
+
+// Name 返回可直接拼接到 SQL 中的表名（带前缀、带 schema 限定、带引号）
+func (t *TableNames) Name(base string) string {
+	name := quoteIdent(t.Prefix + base)
+	if t.Schema != "" {
+		return quoteIdent(t.Schema) + "." + name
+	}
+	return name
+}
+
+// Rename 将 SQL 文本中的基础表名替换为带前缀的表名，用于数据库迁移文件
+// 索引名与函数名在 schema 内全局唯一，同样加前缀，否则共享 schema 时第二个前缀的 CREATE INDEX IF NOT EXISTS
+// 会被静默跳过、CREATE OR REPLACE FUNCTION 会覆盖前一个前缀的函数；约束（uk_、fk_、chk_ 开头）一并加前缀保持一致。
+// 只替换完整的标识符（如 iacc_user 不会影响 iacc_user_role 或 trigger_update_updated_at_iacc_user；
+// 触发器名称只在所属表内唯一，不需要前缀）
+func (t *TableNames) Rename(sql string) string {
+	if t.Prefix == "" {
+		return sql
+	}
+	return schemaIdentPattern.ReplaceAllStringFunc(sql, func(name string) string {
+		return t.Prefix + name
+	})
+}
+
+// MigrationsTable 返回迁移版本记录表名，带前缀以避免与共享数据库中的其他系统冲突
+func (t *TableNames) MigrationsTable() string {
+	return t.Prefix + "schema_migrations"
+}
+
+// 迁移中创建的函数名称
+var baseFunctionNames = []string{
+	"update_updated_at_column",
+	"record_template_tombstone",
+}
+
+// schemaIdentPattern 匹配迁移中在 schema 内全局唯一的标识符：表、索引、约束、函数
+var schemaIdentPattern = regexp.MustCompile(`\b(` + strings.Join(baseTableNames, "|") + `|` +
+	strings.Join(droppedTableNames, "|") + `|` + strings.Join(baseFunctionNames, "|") + `|(?:idx|uk|uq|fk|chk)_[a-z0-9_]+)\b`)
+
+// quoteIdent 使用双引号包裹标识符
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,234 @@
+package pkgs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// 多租户模式：每个租户使用独立的 PostgreSQL schema
+const TenantModeSchema = "schema"
+
+// gin 上下文中保存当前租户标识的键
+const TenantContextKey = "tenant"
+
+// 租户标识只允许小写字母、数字和下划线，会拼接为 schema 名称
+var tenantPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// TenantPool 多租户数据库连接池
+// 单租户模式下始终返回默认连接；schema-per-tenant 模式下为每个租户按需创建独立的连接池，
+// 连接池在连接参数中设置 search_path，保证同一请求内所有语句都落在租户自己的 schema 中。
+// 交互请求与批处理（导入导出、批量操作）各自使用独立的连接池，见 DB 与 BatchDB。
+// 每个租户拥有全套数据表，表上的唯一约束（如角色名称、权限名称）只在租户内生效，不需要 tenant_id 列参与组合唯一约束；
+// 仓储必须通过 DB / BatchDB 取得连接，直接使用默认连接会越过租户隔离。
+type TenantPool struct {
+	config    *Config
+	base      *sqlx.DB
+	batchBase *sqlx.DB
+	logger    *zap.Logger
+
+	mu         sync.RWMutex
+	pools      map[string]*sqlx.DB
+	batchPools map[string]*sqlx.DB
+}
+
+// NewTenantPool 创建多租户连接池，返回的清理函数会关闭所有租户连接池
+func NewTenantPool(config *Config, db *sqlx.DB, batch *BatchDB, logger *zap.Logger) (*TenantPool, func()) {
+	p := &TenantPool{
+		config:     config,
+		base:       db,
+		batchBase:  batch.DB,
+		logger:     logger,
+		pools:      map[string]*sqlx.DB{},
+		batchPools: map[string]*sqlx.DB{},
+	}
+	return p, p.Close
+}
+
+// Enabled 是否启用了 schema-per-tenant 模式
+func (p *TenantPool) Enabled() bool {
+	return p.config.Tenant.Mode == TenantModeSchema
+}
+
+// ValidTenant 校验租户标识是否合法
+func (p *TenantPool) ValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+// SchemaName 返回租户对应的 schema 名称
+func (p *TenantPool) SchemaName(tenant string) string {
+	return p.config.Tenant.SchemaPrefix + tenant
+}
+
+// DB 返回当前请求应使用的数据库连接，沙箱请求返回沙箱连接
+func (p *TenantPool) DB(c *gin.Context) *sqlx.DB {
+	if db := sandboxFromContext(c); db != nil {
+		return db
+	}
+	tenant := TenantFromContext(c)
+	if tenant == "" || !p.Enabled() {
+		return p.base
+	}
+	db, err := p.Get(tenant)
+	if err != nil {
+		// 租户中间件已预先建立连接池，这里只可能是连接被关闭后重建失败；退回默认连接会导致数据串租，因此直接中止请求
+		RequestLogger(c, p.logger).Error("获取租户连接池失败", zap.String("tenant", tenant), zap.Error(err))
+		panic(err)
+	}
+	return db
+}
+
+// BatchDB 返回当前请求批处理操作应使用的数据库连接，沙箱请求返回沙箱连接
+func (p *TenantPool) BatchDB(c *gin.Context) *sqlx.DB {
+	if db := sandboxFromContext(c); db != nil {
+		return db
+	}
+	tenant := TenantFromContext(c)
+	if tenant == "" || !p.Enabled() {
+		return p.batchBase
+	}
+	db, err := p.GetBatch(tenant)
+	if err != nil {
+		// 租户中间件已预先建立连接池，这里只可能是连接被关闭后重建失败；退回默认连接会导致数据串租，因此直接中止请求
+		RequestLogger(c, p.logger).Error("获取租户连接池失败", zap.String("tenant", tenant), zap.Error(err))
+		panic(err)
+	}
+	return db
+}
+
+// Get 获取（必要时创建）租户连接池
+func (p *TenantPool) Get(tenant string) (*sqlx.DB, error) {
+	return p.get(p.pools, tenant, p.config.Tenant.MaxIdleConns, p.config.Tenant.MaxOpenConns)
+}
+
+// GetBatch 获取（必要时创建）租户批处理连接池，未配置 tenant.batch_max_open_conns 时与交互连接池共用
+func (p *TenantPool) GetBatch(tenant string) (*sqlx.DB, error) {
+	if p.config.Tenant.BatchMaxOpenConns <= 0 {
+		return p.Get(tenant)
+	}
+	return p.get(p.batchPools, tenant, p.config.Tenant.BatchMaxIdleConns, p.config.Tenant.BatchMaxOpenConns)
+}
+
+func (p *TenantPool) get(pools map[string]*sqlx.DB, tenant string, maxIdle, maxOpen int) (*sqlx.DB, error) {
+	p.mu.RLock()
+	db, ok := pools[tenant]
+	p.mu.RUnlock()
+	if ok {
+		return db, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if db, ok := pools[tenant]; ok {
+		return db, nil
+	}
+
+	db, err := sqlx.Connect("postgres", ConnString(p.config)+" search_path="+p.SchemaName(tenant))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect tenant database: %w", err)
+	}
+	db.SetMaxIdleConns(maxIdle)
+	db.SetMaxOpenConns(maxOpen)
+	db.SetConnMaxLifetime(p.config.Database.ConnMaxLifetime)
+	pools[tenant] = db
+	return db, nil
+}
+
+// Exists 判断租户 schema 是否已经创建
+func (p *TenantPool) Exists(ctx context.Context, tenant string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM information_schema.schemata WHERE schema_name = $1)`
+	if err := p.base.GetContext(ctx, &exists, query, p.SchemaName(tenant)); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// List 返回所有已创建的租户标识
+func (p *TenantPool) List(ctx context.Context) ([]string, error) {
+	var schemas []string
+	query := `SELECT schema_name FROM information_schema.schemata WHERE starts_with(schema_name, $1) ORDER BY schema_name`
+	if err := p.base.SelectContext(ctx, &schemas, query, p.config.Tenant.SchemaPrefix); err != nil {
+		return nil, err
+	}
+	tenants := make([]string, 0, len(schemas))
+	for _, schema := range schemas {
+		tenants = append(tenants, strings.TrimPrefix(schema, p.config.Tenant.SchemaPrefix))
+	}
+	return tenants, nil
+}
+
+// BatchDBs 返回默认 schema 与所有租户 schema 的批处理连接池，键为租户标识（默认 schema 为空字符串）
+// 用于后台任务逐个 schema 执行，查询租户或连接失败的租户记录日志后跳过
+func (p *TenantPool) BatchDBs(ctx context.Context, logger *zap.Logger) map[string]*sqlx.DB {
+	dbs := map[string]*sqlx.DB{"": p.batchBase}
+	if !p.Enabled() {
+		return dbs
+	}
+	tenants, err := p.List(ctx)
+	if err != nil {
+		logger.Error("查询租户列表失败", zap.Error(err))
+	}
+	for _, tenant := range tenants {
+		db, err := p.GetBatch(tenant)
+		if err != nil {
+			logger.Error("获取租户连接池失败", zap.String("tenant", tenant), zap.Error(err))
+			continue
+		}
+		dbs[tenant] = db
+	}
+	return dbs
+}
+
+// PoolStats 一个连接池的统计
+type PoolStats struct {
+	// 连接池名称：default、batch，租户连接池为 tenant:<租户标识> 与 tenant:<租户标识>:batch
+	Name string
+	sql.DBStats
+}
+
+// Stats 返回默认、批处理与已创建的租户连接池的统计，批处理与交互请求共用的连接池只返回一次
+func (p *TenantPool) Stats() []PoolStats {
+	stats := []PoolStats{{Name: "default", DBStats: p.base.Stats()}}
+	if p.batchBase != p.base {
+		stats = append(stats, PoolStats{Name: "batch", DBStats: p.batchBase.Stats()})
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, tenant := range slices.Sorted(maps.Keys(p.pools)) {
+		stats = append(stats, PoolStats{Name: "tenant:" + tenant, DBStats: p.pools[tenant].Stats()})
+	}
+	for _, tenant := range slices.Sorted(maps.Keys(p.batchPools)) {
+		stats = append(stats, PoolStats{Name: "tenant:" + tenant + ":batch", DBStats: p.batchPools[tenant].Stats()})
+	}
+	return stats
+}
+
+// Close 关闭所有租户连接池
+func (p *TenantPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pools := range []map[string]*sqlx.DB{p.pools, p.batchPools} {
+		for tenant, db := range pools {
+			if err := db.Close(); err != nil {
+				p.logger.Error("关闭租户连接池失败", zap.String("tenant", tenant), zap.Error(err))
+			}
+		}
+	}
+	p.pools = map[string]*sqlx.DB{}
+	p.batchPools = map[string]*sqlx.DB{}
+}
+
+// TenantFromContext 从 gin 上下文中获取当前租户标识，单租户请求返回空字符串
+func TenantFromContext(c *gin.Context) string {
+	return c.GetString(TenantContextKey)
+}
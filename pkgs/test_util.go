@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -46,10 +47,11 @@ type loginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// TestUtil 测试工具，T 可以是 *testing.T 或 *testing.B（基准测试）
 type TestUtil struct {
 	Engine *gin.Engine
 	DB     *sqlx.DB
-	T      *testing.T
+	T      testing.TB
 }
 
 // SetupTestPermission 创建一个权限（methodPath: "GET /v1/template/list"）
@@ -75,14 +77,23 @@ func (testUtil *TestUtil) SetupTestPermission(methodPath string) permission {
 	var createdAt, updatedAt time.Time
 	err := testUtil.DB.QueryRow(query, p.Name, p.Type, string(metaBytes)).Scan(&p.ID, &createdAt, &updatedAt)
 	require.NoError(testUtil.T, err, "创建测试权限失败")
+	invalidatePermissionRoutes()
 
 	testUtil.T.Cleanup(func() {
 		_, err := testUtil.DB.Exec(`DELETE FROM iacc_permission WHERE id = $1`, p.ID)
 		assert.NoError(testUtil.T, err, "清理测试权限失败")
+		invalidatePermissionRoutes()
 	})
 	return p
 }
 
+// invalidatePermissionRoutes 直接写入权限表后使已编译的接口权限记录过期，与权限接口写入后的失效钩子一致
+func invalidatePermissionRoutes() {
+	if m := defaultPermissionMatcher.Load(); m != nil {
+		m.invalidateAll()
+	}
+}
+
 // SetupTestRole 创建一个测试角色
 func (testUtil *TestUtil) SetupTestRole() role {
 	testUtil.T.Helper()
@@ -97,6 +108,11 @@ func (testUtil *TestUtil) SetupTestRole() role {
 	return r
 }
 
+// testPasswordHash 测试用户共用同一个密码，只哈希一次
+var testPasswordHash = sync.OnceValues(func() (string, error) {
+	return HashPassword("strongpassword")
+})
+
 // 创建一个用于测试的用户
 func (testUtil *TestUtil) SetupTestUser() user {
 	testUtil.T.Helper()
@@ -110,8 +126,10 @@ func (testUtil *TestUtil) SetupTestUser() user {
 		Phone:    phone,
 	}
 
+	hash, err := testPasswordHash()
+	require.NoError(testUtil.T, err, "哈希测试用户密码失败")
 	query := `INSERT INTO "iacc_user" (username, password, phone) VALUES ($1, $2, $3) RETURNING id`
-	err := testUtil.DB.QueryRow(query, u.Username, u.Password, u.Phone).Scan(&u.ID)
+	err = testUtil.DB.QueryRow(query, u.Username, hash, u.Phone).Scan(&u.ID)
 	require.NoError(testUtil.T, err, "创建测试用户失败")
 
 	testUtil.T.Cleanup(func() {
@@ -144,6 +162,17 @@ func (testUtil *TestUtil) AssignPermissionToRole(roleID, permissionID string) {
 	})
 }
 
+// 以拒绝效果关联权限到角色
+func (testUtil *TestUtil) DenyPermissionForRole(roleID, permissionID string) {
+	testUtil.T.Helper()
+	_, err := testUtil.DB.Exec(`INSERT INTO iacc_role_permission (role_id, permission_id, effect) VALUES ($1, $2, 'deny')`, roleID, permissionID)
+	require.NoError(testUtil.T, err, "关联拒绝权限到角色失败")
+	testUtil.T.Cleanup(func() {
+		_, err := testUtil.DB.Exec(`DELETE FROM iacc_role_permission WHERE role_id = $1 AND permission_id = $2`, roleID, permissionID)
+		assert.NoError(testUtil.T, err, "清理角色权限关联失败")
+	})
+}
+
 // 创建用户并分配权限，返回用户与token
 func (testUtil *TestUtil) SetupUserWithPermissions(methodPaths []string) (user, string) {
 	u := testUtil.SetupTestUser()
@@ -190,6 +219,39 @@ func (testUtil *TestUtil) GetAccessUserToken(permissions []string) string {
 	return token
 }
 
+// SetupTestCodePermission 创建一个编码类权限（metadata.code），如 user:view_pii
+func (testUtil *TestUtil) SetupTestCodePermission(code string) permission {
+	testUtil.T.Helper()
+	p := permission{
+		Name: uuid.NewString()[:8],
+		Type: "data",
+	}
+	p.Metadata.Code = &code
+
+	metaBytes, _ := json.Marshal(p.Metadata)
+	query := `INSERT INTO iacc_permission (name, type, metadata) VALUES ($1, $2, $3) RETURNING id`
+	err := testUtil.DB.QueryRow(query, p.Name, p.Type, string(metaBytes)).Scan(&p.ID)
+	require.NoError(testUtil.T, err, "创建测试编码权限失败")
+
+	testUtil.T.Cleanup(func() {
+		_, err := testUtil.DB.Exec(`DELETE FROM iacc_permission WHERE id = $1`, p.ID)
+		assert.NoError(testUtil.T, err, "清理测试编码权限失败")
+	})
+	return p
+}
+
+// 获取拥有指定编码权限的用户 token
+func (testUtil *TestUtil) GetAccessUserTokenWithCodes(codes []string) string {
+	u := testUtil.SetupTestUser()
+	r := testUtil.SetupTestRole()
+	testUtil.AssignRoleToUser(u.ID, r.ID)
+	for _, code := range codes {
+		perm := testUtil.SetupTestCodePermission(code)
+		testUtil.AssignPermissionToRole(r.ID, perm.ID)
+	}
+	return testUtil.GetAccessTokenByUser(u)
+}
+
 // 创建一个没有任何权限的用户 token
 func (testUtil *TestUtil) GetNoPermissionUserToken() string {
 	return testUtil.GetAccessTokenByUser(testUtil.SetupTestUser())
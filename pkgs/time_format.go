@@ -0,0 +1,113 @@
+package pkgs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 本次请求序列化时间使用的时区在 gin.Context 中的键
+const TimeLocationContextKey = "time_location"
+
+// 配置中可使用的时间格式名称，其他值按 Go 时间布局（如 2006-01-02 15:04:05）处理
+var namedTimeLayouts = map[string]string{
+	"":            time.RFC3339,
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"datetime":    time.DateTime,
+}
+
+// TimeFormatter 接口响应中时间字段的统一格式化器
+// 时区优先级：请求参数 ?tz= > X-Timezone 请求头 > Accept-Language 映射的时区 > 配置的默认时区。
+// 与 FieldCipher 相同，创建后注册为包级默认实例，仓储层通过 FormatTime 使用。
+type TimeFormatter struct {
+	location  *time.Location
+	layout    string
+	languages map[string]*time.Location
+}
+
+var defaultTimeFormatter = &TimeFormatter{location: time.Local, layout: time.RFC3339}
+
+// NewTimeFormatter 根据配置创建时间格式化器，并注册为默认实例
+func NewTimeFormatter(config *Config) (*TimeFormatter, error) {
+	f := &TimeFormatter{
+		location:  time.Local,
+		layout:    config.Time.Format,
+		languages: make(map[string]*time.Location),
+	}
+	if layout, ok := namedTimeLayouts[strings.ToLower(config.Time.Format)]; ok {
+		f.layout = layout
+	}
+	if config.Time.Timezone != "" {
+		loc, err := time.LoadLocation(config.Time.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time.timezone: %w", err)
+		}
+		f.location = loc
+	}
+	for lang, tz := range config.Time.LanguageTimezones {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time.language_timezones.%s: %w", lang, err)
+		}
+		f.languages[strings.ToLower(lang)] = loc
+	}
+	defaultTimeFormatter = f
+	return f, nil
+}
+
+// Resolve 根据请求参数 tz（或 X-Timezone 请求头）与 Accept-Language 请求头确定时区
+// tz 非法时返回错误；Accept-Language 没有匹配的映射时使用默认时区
+func (f *TimeFormatter) Resolve(tz, acceptLanguage string) (*time.Location, error) {
+	if tz != "" {
+		return time.LoadLocation(tz)
+	}
+	// 按请求头中出现的顺序匹配，先匹配完整语言标签（zh-CN），再匹配主语言（zh）
+	for _, lang := range AcceptLanguageTags(acceptLanguage) {
+		if loc, ok := f.languages[lang]; ok {
+			return loc, nil
+		}
+		if primary, _, found := strings.Cut(lang, "-"); found {
+			if loc, ok := f.languages[primary]; ok {
+				return loc, nil
+			}
+		}
+	}
+	return f.location, nil
+}
+
+// Format 按指定时区和配置的格式序列化时间
+func (f *TimeFormatter) Format(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = f.location
+	}
+	return t.In(loc).Format(f.layout)
+}
+
+// FormatTime 使用默认格式化器和本次请求的时区序列化时间
+func FormatTime(c *gin.Context, t time.Time) string {
+	return defaultTimeFormatter.Format(t, RequestLocation(c))
+}
+
+// RequestLocation 返回本次请求的时区，未确定时返回默认时区
+func RequestLocation(c *gin.Context) *time.Location {
+	if c != nil {
+		if v, ok := c.Get(TimeLocationContextKey); ok {
+			if loc, ok := v.(*time.Location); ok && loc != nil {
+				return loc
+			}
+		}
+	}
+	return defaultTimeFormatter.location
+}
+
+// FormatTimePtr 同 FormatTime，nil 时返回 nil
+func FormatTimePtr(c *gin.Context, t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := FormatTime(c, *t)
+	return &s
+}
@@ -0,0 +1,36 @@
+package pkgs
+
+// 令牌类型：访问令牌只能用于接口鉴权，刷新令牌只能用于换取新令牌，二者不能互换
+const (
+	// TokenTypeClaim 令牌中记录令牌类型的声明
+	TokenTypeClaim   = "typ"
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// TokenIDClaim 令牌中记录令牌唯一标识的声明，用于将单个令牌加入黑名单
+const TokenIDClaim = "jti"
+
+// TokenVersionClaim 访问令牌中记录签发时用户令牌版本的声明，低于用户当前版本的访问令牌无效
+const TokenVersionClaim = "ver"
+
+// TokenClaimsContextKey 鉴权中间件解析出的访问令牌声明在 gin.Context 中的键
+const TokenClaimsContextKey = "token_claims"
+
+// TokenType 返回令牌声明中的令牌类型，缺失时返回空字符串
+func TokenType(claims map[string]any) string {
+	typ, _ := claims[TokenTypeClaim].(string)
+	return typ
+}
+
+// TokenID 返回令牌声明中的唯一标识，缺失时返回空字符串
+func TokenID(claims map[string]any) string {
+	jti, _ := claims[TokenIDClaim].(string)
+	return jti
+}
+
+// TokenVersion 返回令牌声明中的令牌版本，缺失时（升级前签发的令牌）返回 0
+func TokenVersion(claims map[string]any) int {
+	ver, _ := claims[TokenVersionClaim].(float64)
+	return int(ver)
+}
@@ -0,0 +1,122 @@
+package pkgs
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TokenBlacklist 令牌黑名单
+// 签发的令牌带有唯一标识（jti），退出登录时将访问令牌与刷新令牌的标识写入当前 schema 的 iacc_token_revocation 表，
+// 鉴权中间件拒绝黑名单中的访问令牌，刷新令牌接口拒绝黑名单中的刷新令牌。
+// 记录保留到令牌过期之后，由数据保留策略（token_revocation）清理；不带 jti 的令牌（升级前签发的）无法单独撤销，只能等待过期。
+// 事件响应时按条件批量撤销（POST /admin/tokens/revoke）不逐个记录令牌：提升用户的令牌版本使访问令牌失效，
+// 并写入撤销规则（iacc_token_revocation_rule）使命中条件的刷新令牌失效，二者同样在这里判断。
+// 签发的刷新令牌登记在 iacc_refresh_token 表中，批量撤销据此确定需要提升令牌版本的用户并统计撤销的令牌数。
+type TokenBlacklist struct {
+	pool   *TenantPool
+	tables *TableNames
+	logger *zap.Logger
+}
+
+func NewTokenBlacklist(pool *TenantPool, tables *TableNames, logger *zap.Logger) *TokenBlacklist {
+	return &TokenBlacklist{pool: pool, tables: tables, logger: logger}
+}
+
+// Register 登记签发的刷新令牌，令牌不带 jti 时不登记
+func (b *TokenBlacklist) Register(c *gin.Context, claims map[string]any) error {
+	jti := revocableTokenID(claims)
+	if jti == "" {
+		return nil
+	}
+	issuedAt, _ := claims["iat"].(float64)
+	expiresAt, _ := claims["exp"].(float64)
+	var client *string
+	if id, _ := claims["client_id"].(string); id != "" {
+		client = &id
+	}
+	query := `INSERT INTO ` + b.tables.RefreshToken + ` (jti, user_id, client_id, issued_at, expires_at) VALUES ($1, $2, $3, to_timestamp($4), to_timestamp($5)) ON CONFLICT (jti) DO NOTHING`
+	_, err := b.pool.DB(c).ExecContext(c.Request.Context(), query, jti, claims["user_id"], client, int64(issuedAt), int64(expiresAt))
+	return err
+}
+
+// Revoke 将令牌加入黑名单，重复撤销不报错；令牌不带 jti 时返回 false
+func (b *TokenBlacklist) Revoke(c *gin.Context, claims map[string]any) (bool, error) {
+	jti := revocableTokenID(claims)
+	if jti == "" {
+		return false, nil
+	}
+	var userID *string
+	if id, _ := claims["user_id"].(string); id != "" {
+		userID = &id
+	}
+	expiresAt := time.Now()
+	if exp, ok := claims["exp"].(float64); ok {
+		expiresAt = time.Unix(int64(exp), 0)
+	}
+	query := `INSERT INTO ` + b.tables.TokenRevocation + ` (jti, user_id, token_type, expires_at) VALUES ($1, $2, $3, $4) ON CONFLICT (jti) DO NOTHING`
+	if _, err := b.pool.DB(c).ExecContext(c.Request.Context(), query, jti, userID, TokenType(claims), expiresAt); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IsRevoked 判断令牌是否已撤销，每次只执行一条按主键、索引查找的查询：
+//   - 在黑名单中（退出登录）的令牌已撤销，不带 jti 的令牌跳过这一项；
+//   - 访问令牌的令牌版本低于用户当前版本时已撤销（批量撤销时提升版本）；
+//   - 刷新令牌的签发时间与客户端类型命中批量撤销规则时已撤销。
+func (b *TokenBlacklist) IsRevoked(c *gin.Context, claims map[string]any) (bool, error) {
+	var jti *string
+	if id := revocableTokenID(claims); id != "" {
+		jti = &id
+	}
+	var userID *string
+	if id, _ := claims["user_id"].(string); uuid.Validate(id) == nil {
+		userID = &id
+	}
+
+	var revoked bool
+	var err error
+	if TokenType(claims) == TokenTypeRefresh {
+		issuedAt, _ := claims["iat"].(float64)
+		client, _ := claims["client_id"].(string)
+		// iat 精确到秒，与撤销时间同一秒签发的令牌同样视为已撤销
+		query := `SELECT EXISTS (SELECT 1 FROM ` + b.tables.TokenRevocation + ` WHERE jti = $1)
+			OR EXISTS (
+				SELECT 1 FROM ` + b.tables.TokenRevocationRule + ` r
+				WHERE (r.user_ids IS NULL OR r.user_ids @> ARRAY[$2::uuid])
+				  AND (r.client_type IS NULL OR r.client_type = (SELECT client_type FROM ` + b.tables.Client + ` WHERE client_id = $3))
+				  AND r.issued_before >= to_timestamp($4)
+			)`
+		err = b.pool.DB(c).GetContext(c.Request.Context(), &revoked, query, jti, userID, client, int64(issuedAt))
+	} else {
+		query := `SELECT EXISTS (SELECT 1 FROM ` + b.tables.TokenRevocation + ` WHERE jti = $1)
+			OR COALESCE((SELECT token_version FROM ` + b.tables.User + ` WHERE id = $2), 0) > $3`
+		err = b.pool.DB(c).GetContext(c.Request.Context(), &revoked, query, jti, userID, TokenVersion(claims))
+	}
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+// Version 返回用户当前的令牌版本，签发访问令牌时写入 ver 声明；用户不存在时返回 0
+func (b *TokenBlacklist) Version(c *gin.Context, userID string) (int, error) {
+	var version int
+	query := `SELECT COALESCE((SELECT token_version FROM ` + b.tables.User + ` WHERE id = $1), 0)`
+	if err := b.pool.DB(c).GetContext(c.Request.Context(), &version, query, userID); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// revocableTokenID 返回可以加入黑名单的令牌标识，令牌不带 jti 或格式不是 UUID 时返回空字符串
+func revocableTokenID(claims map[string]any) string {
+	jti := TokenID(claims)
+	if _, err := uuid.Parse(jti); err != nil {
+		return ""
+	}
+	return jti
+}
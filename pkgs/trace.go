@@ -0,0 +1,49 @@
+package pkgs
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 请求ID请求头，客户端或网关传入时沿用，否则由服务端生成；响应中原样返回
+const TraceIDHeader = "X-Request-ID"
+
+// gin 上下文中保存请求ID的键
+const TraceIDContextKey = "trace_id"
+
+// gin 上下文中保存带请求ID字段的日志器的键
+const loggerContextKey = "request_logger"
+
+// 外部传入的请求ID只接受常见的 ID 字符，避免写入日志和任务记录时夹带换行等控制字符
+var traceIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// ValidTraceID 判断外部传入的请求ID是否可以沿用
+func ValidTraceID(id string) bool {
+	return traceIDPattern.MatchString(id)
+}
+
+// NewTraceID 生成新的请求ID
+func NewTraceID() string {
+	return uuid.NewString()
+}
+
+// TraceIDFromContext 返回当前请求的请求ID，未经过 TraceMiddleware 时返回空字符串
+func TraceIDFromContext(c *gin.Context) string {
+	return c.GetString(TraceIDContextKey)
+}
+
+// SetRequestLogger 在 context 中保存带 trace_id 字段的日志器，由 TraceMiddleware 调用
+func SetRequestLogger(c *gin.Context, logger *zap.Logger) {
+	c.Set(loggerContextKey, logger.With(zap.String("trace_id", TraceIDFromContext(c))))
+}
+
+// RequestLogger 返回当前请求的日志器，写出的日志自动带上 trace_id；未经过 TraceMiddleware 时返回 fallback
+func RequestLogger(c *gin.Context, fallback *zap.Logger) *zap.Logger {
+	if v, ok := c.Get(loggerContextKey); ok {
+		return v.(*zap.Logger)
+	}
+	return fallback
+}
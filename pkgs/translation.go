@@ -0,0 +1,102 @@
+package pkgs
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Translation 一种语言下的显示名称与描述，未翻译的字段为空
+type Translation struct {
+	Name        string  `json:"name,omitempty" label:"名称"`
+	Description *string `json:"description,omitempty" label:"描述"`
+}
+
+// Translations 实体显示名称的多语言翻译，键为语言标签（如 en-US、ja），存储在 translations JSONB 列中
+// 列表与详情接口按 Accept-Language 请求头调用 Localize 选择翻译，没有匹配的翻译时使用原始名称与描述。
+type Translations map[string]Translation
+
+// Value 实现 driver.Valuer 接口
+func (t Translations) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("{}"), nil
+	}
+	return GenericJSONValue(t)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (t *Translations) Scan(value any) error {
+	return GenericJSONScan(t, value)
+}
+
+// Localize 按本次请求的 Accept-Language 返回显示名称与描述
+// 按请求头中出现的顺序匹配，先匹配完整语言标签（en-US），再匹配主语言（en）；翻译中未填写的字段使用原始值
+func (t Translations) Localize(c *gin.Context, name string, description *string) (string, *string) {
+	if len(t) == 0 || c == nil || c.Request == nil {
+		return name, description
+	}
+	translation, ok := t.match(c.GetHeader("Accept-Language"))
+	if !ok {
+		return name, description
+	}
+	if translation.Name != "" {
+		name = translation.Name
+	}
+	if translation.Description != nil {
+		description = translation.Description
+	}
+	return name, description
+}
+
+// match 按 Accept-Language 选择翻译，语言标签不区分大小写
+func (t Translations) match(acceptLanguage string) (Translation, bool) {
+	lookup := make(map[string]Translation, len(t))
+	for locale, translation := range t {
+		lookup[strings.ToLower(locale)] = translation
+	}
+	for _, lang := range AcceptLanguageTags(acceptLanguage) {
+		if translation, ok := lookup[lang]; ok {
+			return translation, true
+		}
+		if primary, _, found := strings.Cut(lang, "-"); found {
+			if translation, ok := lookup[primary]; ok {
+				return translation, true
+			}
+		}
+	}
+	return Translation{}, false
+}
+
+// 语言标签格式：2～3 位主语言，后接若干 2～8 位子标签（如 zh-Hans-CN）
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// CanonicalLocale 规范化语言标签：主语言小写，2 位地区大写，4 位文字首字母大写（zh-hans-cn → zh-Hans-CN）
+// 格式无效时 ok 为 false
+func CanonicalLocale(locale string) (canonical string, ok bool) {
+	if !localePattern.MatchString(locale) {
+		return "", false
+	}
+	parts := strings.Split(locale, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// LocaleViolations 校验路径或请求中的语言标签
+func LocaleViolations(field, locale string) []Violation {
+	if _, ok := CanonicalLocale(locale); !ok {
+		return []Violation{{Field: field, Message: "语言标签格式无效，应为 en、en-US、zh-Hans-CN 等形式"}}
+	}
+	return nil
+}
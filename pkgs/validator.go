@@ -1,23 +1,44 @@
 package pkgs
 
 import (
+	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/locales/en"
 	"github.com/go-playground/locales/zh"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
 	zh_translations "github.com/go-playground/validator/v10/translations/zh"
 	"github.com/samber/mo"
 )
 
 // 提供可重用的请求验证逻辑。
+// 校验信息按请求的语言（见 RequestLocale）翻译，字段名使用 label 标签。
 type RequestValidator struct {
-	validate *validator.Validate
-	trans    ut.Translator
+	validate    *validator.Validate
+	translators map[string]ut.Translator
+
+	// 按请求类型注册的跨字段/跨实体校验规则
+	mu    sync.RWMutex
+	rules map[reflect.Type][]func(req any) []Violation
+}
+
+// Violation 单条校验失败信息
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	// 涉及的列表下标（如批量请求中重复的项）
+	Indexes []int `json:"indexes,omitempty"`
 }
 
+// Rule 跨字段/跨实体校验规则，返回全部违规项，没有违规返回空切片
+type Rule[T any] func(req *T) []Violation
+
 // 创建一个新的 RequestValidator。
 func NewRequestValidator() *RequestValidator {
 	validate := validator.New()
@@ -28,16 +49,34 @@ func NewRequestValidator() *RequestValidator {
 		}
 		return name
 	})
+	// Optional 字段按传入的值校验，缺省和 null 视为空值
+	validate.RegisterCustomTypeFunc(optionalValidationValue,
+		Optional[string]{}, Optional[int]{}, Optional[int64]{}, Optional[float64]{}, Optional[bool]{}, Optional[AccessConditions]{})
 	chinese := zh.New()
-	uni := ut.New(chinese, chinese)
-	trans, _ := uni.GetTranslator("zh")
-	zh_translations.RegisterDefaultTranslations(validate, trans)
+	uni := ut.New(chinese, chinese, en.New())
+	translators := map[string]ut.Translator{}
+	for _, locale := range SupportedLocales {
+		translators[locale], _ = uni.GetTranslator(locale)
+	}
+	_ = zh_translations.RegisterDefaultTranslations(validate, translators[LocaleZh])
+	_ = en_translations.RegisterDefaultTranslations(validate, translators[LocaleEn])
+	registerSortOrder(validate, translators)
+	registerPermissionNamespace(validate, translators)
 	return &RequestValidator{
-		validate: validate,
-		trans:    trans,
+		validate:    validate,
+		translators: translators,
+		rules:       map[reflect.Type][]func(req any) []Violation{},
 	}
 }
 
+// translator 返回语言对应的翻译器，不支持的语言使用中文
+func (v *RequestValidator) translator(locale string) ut.Translator {
+	if trans, ok := v.translators[locale]; ok {
+		return trans
+	}
+	return v.translators[LocaleZh]
+}
+
 // 验证给定的请求结构体。
 func (v *RequestValidator) Validate(c *gin.Context, req interface{}) error {
 	if err := v.validate.Struct(req); err != nil {
@@ -48,7 +87,7 @@ func (v *RequestValidator) Validate(c *gin.Context, req interface{}) error {
 			message := field.Tag.Get("message")
 			if message == "" {
 				// 如果没有自定义消息，则使用翻译后的错误
-				Error(c, http.StatusBadRequest, validationErrors[0].Translate(v.trans))
+				Error(c, http.StatusBadRequest, validationErrors[0].Translate(v.translator(RequestLocale(c))))
 			} else {
 				// 使用自定义错误消息
 				Error(c, http.StatusBadRequest, message)
@@ -61,18 +100,124 @@ func (v *RequestValidator) Validate(c *gin.Context, req interface{}) error {
 	return nil
 }
 
+// RegisterRule 为请求类型 T 注册跨字段/跨实体校验规则，ValidateV2 在字段校验之后执行
+func RegisterRule[T any](v *RequestValidator, rule Rule[T]) {
+	t := reflect.TypeFor[T]()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[t] = append(v.rules[t], func(req any) []Violation {
+		return rule(req.(*T))
+	})
+}
+
 // 验证给定的请求结构体。
+// 先执行字段标签校验，再执行通过 RegisterRule 注册的规则，所有违规项合并后一次性返回；
+// 存在规则违规时，违规明细（含列表下标）放在响应 data 中。
+// 校验信息在 HandleError 中按请求的语言生成，规则违规的信息按消息目录翻译。
 func ValidateV2[T any](v *RequestValidator) func(req *T) mo.Result[*T] {
 	return func(req *T) mo.Result[*T] {
+		var fieldErrors validator.ValidationErrors
 		if err := v.validate.Struct(req); err != nil {
-			errMsg := err.Error()
-			// 获取第一个验证错误
-			if validationErrors, ok := err.(validator.ValidationErrors); ok && len(validationErrors) > 0 {
-				// 使用翻译后的错误
-				errMsg = validationErrors[0].Translate(v.trans)
+			validationErrors, ok := err.(validator.ValidationErrors)
+			if !ok {
+				return mo.Err[*T](NewApiError(http.StatusBadRequest, err.Error()))
+			}
+			fieldErrors = validationErrors
+		}
+
+		v.mu.RLock()
+		rules := v.rules[reflect.TypeFor[T]()]
+		v.mu.RUnlock()
+		var ruleViolations []Violation
+		for _, rule := range rules {
+			ruleViolations = append(ruleViolations, rule(req)...)
+		}
+
+		if len(fieldErrors) == 0 && len(ruleViolations) == 0 {
+			return mo.Ok(req)
+		}
+		localize := func(locale string) (string, any) {
+			trans := v.translator(locale)
+			violations := make([]Violation, 0, len(fieldErrors)+len(ruleViolations))
+			for _, fe := range fieldErrors {
+				// 去掉根结构体名，例如 BatchCreateReq.用户列表[0].手机号 -> 用户列表[0].手机号
+				_, field, _ := strings.Cut(fe.Namespace(), ".")
+				violations = append(violations, Violation{
+					Field:   field,
+					Message: fe.Translate(trans),
+				})
 			}
-			return mo.Err[*T](NewApiError(http.StatusBadRequest, errMsg))
+			for _, violation := range ruleViolations {
+				violation.Message = defaultLocalizer.Localize(locale, violation.Message)
+				violations = append(violations, violation)
+			}
+			messages := make([]string, 0, len(violations))
+			for _, violation := range violations {
+				messages = append(messages, violation.Message)
+			}
+			var data any
+			if len(ruleViolations) > 0 {
+				data = violations
+			}
+			return defaultLocalizer.Join(locale, messages), data
+		}
+		message, data := localize(defaultLocalizer.locale)
+		apiErr := NewApiError(http.StatusBadRequest, message)
+		apiErr.Data = data
+		apiErr.localize = localize
+		return mo.Err[*T](apiErr)
+	}
+}
+
+// DuplicateViolations 检查列表中的重复值，每个重复值返回一条违规信息，Indexes 为该值出现的全部下标
+func DuplicateViolations[V comparable](field, label string, values []V) []Violation {
+	positions := map[V][]int{}
+	var order []V
+	for i, value := range values {
+		if _, seen := positions[value]; !seen {
+			order = append(order, value)
+		}
+		positions[value] = append(positions[value], i)
+	}
+
+	var violations []Violation
+	for _, value := range order {
+		indexes := positions[value]
+		if len(indexes) < 2 {
+			continue
+		}
+		violations = append(violations, Violation{
+			Field:   field,
+			Message: fmt.Sprintf("%s存在重复值 %v（下标 %s）", label, value, joinInts(indexes)),
+			Indexes: indexes,
+		})
+	}
+	return violations
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NewViolationError 将需要查询数据库才能发现的违规项（如自定义属性校验）包装为 400 错误，格式与 ValidateV2 的规则违规相同
+func NewViolationError(violations []Violation) *ApiError {
+	localize := func(locale string) (string, any) {
+		localized := make([]Violation, 0, len(violations))
+		messages := make([]string, 0, len(violations))
+		for _, violation := range violations {
+			violation.Message = defaultLocalizer.Localize(locale, violation.Message)
+			localized = append(localized, violation)
+			messages = append(messages, violation.Message)
 		}
-		return mo.Ok(req)
+		return defaultLocalizer.Join(locale, messages), localized
 	}
+	message, data := localize(defaultLocalizer.locale)
+	apiErr := NewApiError(http.StatusBadRequest, message)
+	apiErr.Data = data
+	apiErr.localize = localize
+	return apiErr
 }
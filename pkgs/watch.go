@@ -0,0 +1,204 @@
+package pkgs
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 可以关注的实体类型，与 watch.entity 一致
+const (
+	WatchEntityTemplate = "template"
+	WatchEntityUser     = "user"
+)
+
+// 批量操作的实体ID在请求上下文中的键前缀
+const watchIDsContextKey = "watch_ids:"
+
+// WatchTarget 可以关注的实体，由实体所在的模块注册
+type WatchTarget struct {
+	// 实体名称，用于通知标题，如 "模板"
+	Label string
+	// 实体所在的表，删除后据此确认实体已不存在
+	Table string
+	// 校验当前用户可以关注该实体，实体不存在或不可见时返回 404 等业务错误
+	Check func(c *gin.Context, id string) error
+	// 返回 userIDs 中当前仍可以查看该实体的用户，为空表示不筛选
+	// 关注之后实体的可见范围可能收窄（如模板改为要求某个权限），变更通知只发给仍然可见的关注者
+	Visible func(c *gin.Context, id string, userIDs []string) ([]string, error)
+}
+
+// Watchers 实体关注
+// 用户关注模板、用户等实体后，其他人修改、删除该实体时通过 Notifier 通知关注者（操作者本人不通知）；
+// 实体删除后通知关注者并删除对应的关注。通知在操作完成后发送，发送失败只记录日志，不影响已经完成的操作。
+type Watchers struct {
+	pool     *TenantPool
+	tables   *TableNames
+	notifier *Notifier
+	logger   *zap.Logger
+	// 按实体类型注册的关注目标，见 RegisterTarget
+	targets map[string]WatchTarget
+}
+
+func NewWatchers(pool *TenantPool, tables *TableNames, notifier *Notifier, logger *zap.Logger) *Watchers {
+	return &Watchers{pool: pool, tables: tables, notifier: notifier, logger: logger, targets: map[string]WatchTarget{}}
+}
+
+// RegisterTarget 注册可以关注的实体，须在处理请求前调用；模块关闭时不注册，该实体不能被关注
+func (w *Watchers) RegisterTarget(entity string, target WatchTarget) {
+	w.targets[entity] = target
+}
+
+// Check 校验当前用户可以关注该实体，实体没有注册时返回 400
+func (w *Watchers) Check(c *gin.Context, entity, id string) error {
+	target, ok := w.targets[entity]
+	if !ok {
+		return NewApiError(http.StatusBadRequest, "不支持关注该实体")
+	}
+	return target.Check(c, id)
+}
+
+// Notify 通知实体的关注者发生了变更，操作者本人与已不能查看该实体的关注者不通知
+func (w *Watchers) Notify(c *gin.Context, action, entity string, ids []string) {
+	target, ok := w.targets[entity]
+	ids = slices.DeleteFunc(slices.Clone(ids), func(id string) bool { return id == "" })
+	if !ok || len(ids) == 0 {
+		return
+	}
+	query := `SELECT entity_id, user_id FROM ` + w.tables.Watch + `
+		WHERE entity = $1 AND entity_id = ANY($2) AND user_id IS DISTINCT FROM NULLIF($3, '')::uuid
+		ORDER BY entity_id, seq`
+	rows, err := QueryAll[watcherRow](c.Request.Context(), w.pool.DB(c), query, entity, PGArray(ids), CurrentUserID(c))
+	if err != nil {
+		RequestLogger(c, w.logger).Error("查询关注者失败", zap.String("entity", entity), zap.Strings("entity_ids", ids), zap.Error(err))
+		return
+	}
+	if target.Visible != nil {
+		rows = w.visible(c, entity, target, rows)
+	}
+	w.send(c, action, entity, rows, func(id string) (string, string) {
+		return fmt.Sprintf("关注的%s有新的变更", target.Label), fmt.Sprintf("你关注的%s（%s）有新的变更：%s", target.Label, id, action)
+	})
+}
+
+// NotifyDeleted 通知已删除实体的关注者并删除对应的关注；ids 中仍然存在的实体（没有被删除）被忽略
+func (w *Watchers) NotifyDeleted(c *gin.Context, action, entity string, ids []string) {
+	target, ok := w.targets[entity]
+	ids = slices.DeleteFunc(slices.Clone(ids), func(id string) bool { return id == "" })
+	if !ok || len(ids) == 0 {
+		return
+	}
+	query := `DELETE FROM ` + w.tables.Watch + ` w
+		WHERE w.entity = $1 AND w.entity_id = ANY($2) AND NOT EXISTS (SELECT 1 FROM ` + target.Table + ` t WHERE t.id = w.entity_id)
+		RETURNING w.entity_id, w.user_id`
+	rows, err := QueryAll[watcherRow](c.Request.Context(), w.pool.DB(c), query, entity, PGArray(ids))
+	if err != nil {
+		RequestLogger(c, w.logger).Error("删除关注失败", zap.String("entity", entity), zap.Strings("entity_ids", ids), zap.Error(err))
+		return
+	}
+	actor := CurrentUserID(c)
+	rows = slices.DeleteFunc(rows, func(row watcherRow) bool { return row.UserID == actor })
+	w.send(c, action, entity, rows, func(id string) (string, string) {
+		return fmt.Sprintf("关注的%s已删除", target.Label), fmt.Sprintf("你关注的%s（%s）已被删除，关注已自动取消", target.Label, id)
+	})
+}
+
+// watcherRow 关注者查询的结果行
+type watcherRow struct {
+	EntityID string `db:"entity_id"`
+	UserID   string `db:"user_id"`
+}
+
+// visible 按实体当前的可见范围筛选关注者，rows 须按 entity_id 排序；筛选失败时不通知该实体的关注者
+func (w *Watchers) visible(c *gin.Context, entity string, target WatchTarget, rows []watcherRow) []watcherRow {
+	var kept []watcherRow
+	for start := 0; start < len(rows); {
+		id := rows[start].EntityID
+		var users []string
+		end := start
+		for ; end < len(rows) && rows[end].EntityID == id; end++ {
+			users = append(users, rows[end].UserID)
+		}
+		start = end
+
+		allowed, err := target.Visible(c, id, users)
+		if err != nil {
+			RequestLogger(c, w.logger).Error("筛选关注者失败", zap.String("entity", entity), zap.String("entity_id", id), zap.Error(err))
+			continue
+		}
+		for _, userID := range allowed {
+			kept = append(kept, watcherRow{EntityID: id, UserID: userID})
+		}
+	}
+	return kept
+}
+
+// send 按实体分组发送通知，rows 须按 entity_id 排序
+func (w *Watchers) send(c *gin.Context, action, entity string, rows []watcherRow, text func(id string) (string, string)) {
+	for start := 0; start < len(rows); {
+		id := rows[start].EntityID
+		var recipients []string
+		end := start
+		for ; end < len(rows) && rows[end].EntityID == id; end++ {
+			recipients = append(recipients, rows[end].UserID)
+		}
+		start = end
+
+		title, body := text(id)
+		err := w.notifier.Notify(c, Notification{
+			Type:       "watch." + entity + "." + action,
+			Recipients: recipients,
+			Title:      title,
+			Body:       body,
+			Data:       map[string]any{"entity": entity, "entity_id": id, "action": action, "actor_id": CurrentUserID(c)},
+		})
+		if err != nil {
+			RequestLogger(c, w.logger).Warn("发送关注通知失败", zap.String("entity", entity), zap.String("entity_id", id), zap.Error(err))
+		}
+	}
+}
+
+// NotifyWatchers 返回在操作成功后通知实体关注者的管道步骤，放在仓储操作（事务已提交）之后
+// ids 从结果中取得发生变更的实体ID，返回影响行数的接口使用 WatchAffected。
+func NotifyWatchers[T any](c *gin.Context, w *Watchers, action, entity string, ids func(T) []string) func(T) T {
+	return func(v T) T {
+		w.Notify(c, action, entity, ids(v))
+		return v
+	}
+}
+
+// NotifyWatchersDeleted 返回在删除成功后通知关注者并删除关注的管道步骤
+// ids 从结果中取得删除的实体ID；为 nil 时使用 WatchTargets 记录的实体ID（批量删除），其中仍然存在的实体不通知。
+func NotifyWatchersDeleted[T any](c *gin.Context, w *Watchers, action, entity string, ids func(T) []string) func(T) T {
+	return func(v T) T {
+		var entityIDs []string
+		if ids != nil {
+			entityIDs = ids(v)
+		} else if value, ok := c.Get(watchIDsContextKey + entity); ok {
+			entityIDs, _ = value.([]string)
+		}
+		w.NotifyDeleted(c, action, entity, entityIDs)
+		return v
+	}
+}
+
+// WatchTargets 返回记录批量操作的实体ID的管道步骤，供 NotifyWatchersDeleted 使用，放在仓储操作之前
+func WatchTargets[T any](c *gin.Context, entity string, ids func(*T) []string) func(*T) *T {
+	return func(req *T) *T {
+		c.Set(watchIDsContextKey+entity, ids(req))
+		return req
+	}
+}
+
+// WatchAffected 返回影响行数大于 0 时的实体ID，用于 NotifyWatchers 的 ids
+func WatchAffected(id string) func(int64) []string {
+	return func(affected int64) []string {
+		if affected == 0 {
+			return nil
+		}
+		return []string{id}
+	}
+}
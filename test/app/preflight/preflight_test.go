@@ -0,0 +1,94 @@
+package preflight_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/pkgs"
+)
+
+// TestCheckJWTSecret 测试 JWT 密钥强度检查
+func TestCheckJWTSecret(t *testing.T) {
+	cases := []struct {
+		name    string
+		secret  string
+		problem string
+	}{
+		{name: "常见弱密钥", secret: "my-secret-key", problem: "well-known default"},
+		{name: "弱密钥不区分大小写", secret: "CHANGEME", problem: "well-known default"},
+		{name: "长度不足", secret: "short-but-random-9f8e7d", problem: "at least 32 required"},
+		{name: "空密钥", secret: "", problem: "is 0 bytes"},
+		{name: "强度足够", secret: strings.Repeat("k", 32)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			problem := app.CheckJWTSecret(tc.secret)
+			if tc.problem == "" {
+				assert.Empty(t, problem)
+				return
+			}
+			assert.Contains(t, problem, tc.problem)
+		})
+	}
+}
+
+// TestCheckSchemaVersions 测试迁移版本检查
+func TestCheckSchemaVersions(t *testing.T) {
+	const latest = 20251114120000
+	cases := []struct {
+		name     string
+		states   []app.SchemaState
+		problems []string
+	}{
+		{name: "版本一致", states: []app.SchemaState{{Version: latest}}},
+		{name: "版本落后", states: []app.SchemaState{{Version: 20251012153018}}, problems: []string{"version 20251012153018 in \"schema_migrations\" does not match latest migration 20251114120000"}},
+		{name: "dirty 状态", states: []app.SchemaState{{Version: latest, Dirty: true}}, problems: []string{"dirty at version 20251114120000"}},
+		{name: "读取失败", states: []app.SchemaState{{Err: errors.New("relation does not exist")}}, problems: []string{"cannot read migration version from \"schema_migrations\": relation does not exist"}},
+		{
+			name: "逐个检查租户 schema",
+			states: []app.SchemaState{
+				{Version: latest},
+				{Schema: "tenant_a", Version: latest},
+				{Schema: "tenant_b", Version: 20251012153018},
+			},
+			problems: []string{"in \"tenant_b.schema_migrations\" does not match"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := app.CheckSchemaVersions(tc.states, latest, "schema_migrations")
+			if assert.Len(t, problems, len(tc.problems)) {
+				for i, problem := range tc.problems {
+					assert.Contains(t, problems[i], problem)
+				}
+			}
+		})
+	}
+}
+
+// TestMissingPermissionCodes 测试预置编码类权限检查
+func TestMissingPermissionCodes(t *testing.T) {
+	all := []string{pkgs.PermissionCodeViewPII, middlewares.PermissionCodeViewDocs, template.PermissionCodeManageAll}
+	cases := []struct {
+		name     string
+		existing []string
+		disabled []string
+		missing  []string
+	}{
+		{name: "全部存在", existing: all},
+		{name: "缺少预置权限", existing: []string{pkgs.PermissionCodeViewPII}, missing: []string{middlewares.PermissionCodeViewDocs, template.PermissionCodeManageAll}},
+		{name: "已关闭模块的权限不检查", existing: all[:2], disabled: []string{pkgs.ModuleTemplate}},
+		{name: "模块开启时检查模块权限", existing: all[:2], missing: []string{template.PermissionCodeManageAll}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.missing, app.MissingPermissionCodes(tc.existing, tc.disabled))
+		})
+	}
+}
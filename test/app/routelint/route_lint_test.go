@@ -0,0 +1,59 @@
+package routelint_test
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+// TestLintRoutes 测试启动时的路由检查
+// 包含四个子测试：重复路由、写操作接口缺少权限、权限记录指向不存在的接口、配置正确时无问题
+func TestLintRoutes(t *testing.T) {
+	t.Run("重复路由", func(t *testing.T) {
+		routes := gin.RoutesInfo{
+			{Method: "GET", Path: "/v1/user/:id"},
+			{Method: "GET", Path: "/v1/user/:uid"},
+			{Method: "DELETE", Path: "/v1/user/:id"},
+		}
+		perms := []pkgs.APIPermission{{Method: "DELETE", Path: "/v1/user/:id"}}
+		problems := app.LintRoutes(routes, perms)
+		assert.Len(t, problems, 1)
+		assert.Contains(t, problems[0], "duplicate route GET /v1/user/:uid")
+	})
+
+	t.Run("写操作接口缺少权限", func(t *testing.T) {
+		routes := gin.RoutesInfo{
+			{Method: "POST", Path: "/v1/role"},
+			{Method: "POST", Path: "/v1/template"},
+			{Method: "POST", Path: "/v1/auth/login"},
+			{Method: "GET", Path: "/v1/role/list"},
+		}
+		problems := app.LintRoutes(routes, nil)
+		assert.Len(t, problems, 2, "登录接口与只读接口不报告")
+		assert.Contains(t, problems[0], "POST /v1/role has no permission record")
+		assert.Contains(t, problems[1], "POST /v1/template bypasses the permission middleware")
+	})
+
+	t.Run("权限记录指向不存在的接口", func(t *testing.T) {
+		routes := gin.RoutesInfo{{Method: "GET", Path: "/v1/role/:id"}}
+		perms := []pkgs.APIPermission{
+			{Method: "GET", Path: "/v1/role/123"},
+			{Method: "GET", Path: "/v1/role/:id/members"},
+		}
+		problems := app.LintRoutes(routes, perms)
+		assert.Equal(t, []string{"permission GET /v1/role/:id/members does not match any registered route"}, problems)
+	})
+
+	t.Run("配置正确时无问题", func(t *testing.T) {
+		routes := gin.RoutesInfo{
+			{Method: "PUT", Path: "/v1/role/:id"},
+			{Method: "GET", Path: "/public/v1/template/list"},
+		}
+		perms := []pkgs.APIPermission{{Method: "PUT", Path: "/v1/role/:id"}}
+		assert.Empty(t, app.LintRoutes(routes, perms))
+	})
+}
@@ -0,0 +1,121 @@
+// Package bench_test 仓储热点路径的性能基准
+//
+// 基准直接访问 configs/config.yaml 中的数据库，运行前需要完成迁移；基准数据在首次使用时通过测试数据工厂生成，
+// 全部基准结束后删除。数据量由环境变量 BENCH_USERS 控制（默认 5000）。
+// 运行与对比见 Makefile 中的 bench、bench-baseline、bench-gate。
+package bench_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+	testConf   *pkgs.Config
+
+	// 基准数据，首次使用时生成
+	seedOnce sync.Once
+	seeded   struct {
+		Users []string
+		Roles []string
+	}
+)
+
+// TestMain 初始化一次应用，开启测试数据工厂用于生成基准数据，结束后删除基准数据
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	testConf = a.Conf
+	a.Conf.DevFactory.Enabled = true
+	a.V1Router.RegisterDev()
+
+	code := m.Run()
+	if len(seeded.Users) > 0 {
+		testDB.Exec(`DELETE FROM iacc_user WHERE id = ANY($1::uuid[])`, pq.Array(seeded.Users))
+	}
+	if len(seeded.Roles) > 0 {
+		testDB.Exec(`DELETE FROM iacc_role WHERE id = ANY($1::uuid[])`, pq.Array(seeded.Roles))
+	}
+	os.Exit(code)
+}
+
+// seed 生成基准数据：BENCH_USERS 个用户，每批用户随机分配本批次 20 个角色中的一个
+func seed(b *testing.B) {
+	b.Helper()
+	seedOnce.Do(func() {
+		total := 5000
+		if v, err := strconv.Atoi(os.Getenv("BENCH_USERS")); err == nil && v > 0 {
+			total = v
+		}
+		util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: b}
+		_, token := util.SetupUserWithPermissions([]string{"POST /v1/dev/factory"})
+
+		const roles = 20
+		for created := 0; created < total; {
+			count := min(total-created, testConf.DevFactory.MaxCount)
+			var data struct {
+				Users []string `json:"users"`
+				Roles []string `json:"roles"`
+			}
+			resp := doRequest(b, http.MethodPost, "/v1/dev/factory", token, map[string]any{"users": count, "roles": roles})
+			if resp.Code != http.StatusOK {
+				b.Fatalf("生成基准数据失败: %d %s", resp.Code, resp.Msg)
+			}
+			raw, _ := json.Marshal(resp.Data)
+			if err := json.Unmarshal(raw, &data); err != nil {
+				b.Fatalf("解析基准数据失败: %v", err)
+			}
+			seeded.Users = append(seeded.Users, data.Users...)
+			seeded.Roles = append(seeded.Roles, data.Roles...)
+			created += count
+		}
+		// 生成数据后更新统计信息，避免执行计划受数据分布变化影响
+		if _, err := testDB.Exec(`ANALYZE iacc_user`); err != nil {
+			b.Fatalf("更新统计信息失败: %v", err)
+		}
+	})
+	if len(seeded.Users) == 0 {
+		b.Fatal("缺少基准数据")
+	}
+}
+
+// doRequest 发送 JSON 请求并解析标准响应
+func doRequest(b testing.TB, method, path, token string, body any) pkgs.Response {
+	b.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		b.Fatalf("解析响应体失败: %v", err)
+	}
+	return resp
+}
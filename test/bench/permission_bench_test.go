@@ -0,0 +1,44 @@
+package bench_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// BenchmarkPermissionResolve 测试解析用户权限（用户 -> 角色 -> 权限）的耗时，按用户拥有的角色数分组
+// 直接调用 PermissionChecker.Resolve，不经过 Redis 缓存，反映缓存未命中时每个请求的开销
+func BenchmarkPermissionResolve(b *testing.B) {
+	checker := pkgs.NewPermissionChecker(nil, pkgs.NewTableNames(testConf), zap.NewNop(), nil)
+	ctx := context.Background()
+
+	for _, roleCount := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("roles=%d", roleCount), func(b *testing.B) {
+			util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: b}
+			u := util.SetupTestUser()
+			for i := range roleCount {
+				r := util.SetupTestRole()
+				util.AssignRoleToUser(u.ID, r.ID)
+				// 每个角色 5 个接口权限
+				for j := range 5 {
+					p := util.SetupTestPermission(fmt.Sprintf("GET /v1/bench/%s/%d/%d", u.ID, i, j))
+					util.AssignPermissionToRole(r.ID, p.ID)
+				}
+			}
+
+			for b.Loop() {
+				perms, err := checker.Resolve(ctx, testDB, u.ID)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(perms) != roleCount*5 {
+					b.Fatalf("unexpected permission count %d", len(perms))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package bench_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"go-pg-demo/pkgs"
+)
+
+// BenchmarkUserBatchCreate 测试批量创建用户的耗时，按每批数量分组，每次操作创建一批
+func BenchmarkUserBatchCreate(b *testing.B) {
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: b}
+	_, token := util.SetupUserWithPermissions([]string{"POST /v1/user/batch-create"})
+
+	for _, size := range []int{10, 100} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			var created []string
+			b.Cleanup(func() {
+				testDB.Exec(`DELETE FROM iacc_user WHERE id = ANY($1::uuid[])`, pq.Array(created))
+			})
+
+			// 用户名与手机号按批次随机起点递增，避免与已有数据冲突
+			prefix := "bench_" + uuid.NewString()[:6]
+			seq, phoneBase := 0, rand.IntN(800000000)
+			for b.Loop() {
+				users := make([]map[string]any, size)
+				for i := range users {
+					seq++
+					users[i] = map[string]any{
+						"username": prefix + strconv.FormatInt(int64(seq), 36),
+						"phone":    fmt.Sprintf("19%09d", phoneBase+seq),
+						"password": "Passw0rd!",
+					}
+				}
+				resp := doRequest(b, http.MethodPost, "/v1/user/batch-create", token, map[string]any{"users": users})
+				if resp.Code != http.StatusOK {
+					b.Fatalf("unexpected code %d: %s", resp.Code, resp.Msg)
+				}
+				var ids []string
+				raw, _ := json.Marshal(resp.Data)
+				if err := json.Unmarshal(raw, &ids); err != nil {
+					b.Fatal(err)
+				}
+				created = append(created, ids...)
+			}
+		})
+	}
+}
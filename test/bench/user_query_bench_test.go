@@ -0,0 +1,41 @@
+package bench_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+)
+
+// BenchmarkUserQueryList 测试用户列表查询的耗时，覆盖常用的筛选、排序与深分页组合
+func BenchmarkUserQueryList(b *testing.B) {
+	seed(b)
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: b}
+	_, token := util.SetupUserWithPermissions([]string{"GET /v1/user/list"})
+
+	cases := []struct {
+		name  string
+		query string
+	}{
+		{"default", ""},
+		{"username", "username=wang"},
+		{"sort=username", "orderBy=username&order=asc"},
+		{"username+sort", "username=li&orderBy=updated_at&order=asc"},
+		{"pageSize=100", "pageSize=100"},
+		{"deep-page", "page=40&pageSize=100"},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			for b.Loop() {
+				req, _ := http.NewRequest(http.MethodGet, "/v1/user/list?"+tc.query, nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				w := httptest.NewRecorder()
+				testRouter.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					b.Fatalf("unexpected status %d", w.Code)
+				}
+			}
+		})
+	}
+}
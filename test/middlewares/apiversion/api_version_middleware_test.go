@@ -0,0 +1,82 @@
+package apiversion_middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/pkgs"
+)
+
+// widget 最新版本的响应结构
+type widget struct {
+	DisplayName string `json:"display_name"`
+}
+
+// gadget 没有注册旧版本表示的响应结构
+type gadget struct {
+	Name string `json:"name"`
+}
+
+func init() {
+	// 旧版本客户端看到改名前的字段
+	pkgs.RegisterRepresentation(pkgs.LatestAPIVersion, func(c *gin.Context, w widget) any {
+		return map[string]any{"name": w.DisplayName, "version": pkgs.APIVersion(c)}
+	})
+}
+
+// newRouter 只挂载版本协商中间件
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.HandlerFunc(middlewares.NewAPIVersionMiddleware()))
+	r.GET("/widget", func(c *gin.Context) { pkgs.Success(c, widget{DisplayName: "w"}) })
+	r.GET("/gadget", func(c *gin.Context) { pkgs.Success(c, gadget{Name: "g"}) })
+	return r
+}
+
+func get(r *gin.Engine, path, accept string) (*httptest.ResponseRecorder, pkgs.Response) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp pkgs.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+// TestAPIVersionMiddleware 测试按 Accept 请求头协商接口版本
+// 包含四个子测试：未指定版本、指定版本、多个候选版本、不支持的版本
+func TestAPIVersionMiddleware(t *testing.T) {
+	r := newRouter()
+
+	t.Run("未指定版本", func(t *testing.T) {
+		w, resp := get(r, "/widget", "application/json")
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		assert.Equal(t, map[string]any{"name": "w", "version": float64(pkgs.DefaultAPIVersion)}, resp.Data, "使用默认版本的表示")
+	})
+
+	t.Run("指定版本", func(t *testing.T) {
+		w, resp := get(r, "/gadget", "application/vnd.gopgdemo.v1+json")
+		assert.Equal(t, "application/vnd.gopgdemo.v1+json; charset=utf-8", w.Header().Get("Content-Type"), "响应回显协商的版本")
+		assert.Equal(t, map[string]any{"name": "g"}, resp.Data, "没有注册表示的类型原样返回")
+	})
+
+	t.Run("多个候选版本", func(t *testing.T) {
+		w, _ := get(r, "/widget", "application/vnd.gopgdemo.v99+json, application/vnd.gopgdemo.v1+json;q=0.5")
+		assert.Equal(t, "application/vnd.gopgdemo.v1+json; charset=utf-8", w.Header().Get("Content-Type"), "取第一个支持的版本")
+	})
+
+	t.Run("不支持的版本", func(t *testing.T) {
+		_, resp := get(r, "/widget", "application/vnd.gopgdemo.v99+json")
+		assert.Equal(t, http.StatusNotAcceptable, resp.Code)
+	})
+}
@@ -0,0 +1,129 @@
+package docs_middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/pkgs"
+)
+
+// 复用应用实例
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	code := m.Run()
+	os.Exit(code)
+}
+
+// 辅助函数：解析标准响应
+func parseResponse(t *testing.T, w *httptest.ResponseRecorder) pkgs.Response {
+	t.Helper()
+	var resp pkgs.Response
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err, "解析响应体不应出错")
+	return resp
+}
+
+// 场景1：未登录访问文档返回 401
+func TestDocsMiddleware_Unauthorized(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	resp := parseResponse(t, w)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, "业务码应为401 未授权")
+}
+
+// 场景2：已登录但没有 docs:view 权限返回 403
+func TestDocsMiddleware_Forbidden(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := tu.GetNoPermissionUserToken()
+
+	req, _ := http.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	resp := parseResponse(t, w)
+	assert.Equal(t, http.StatusForbidden, resp.Code, "业务码应为403 无权限")
+	assert.Equal(t, "无文档访问权限", resp.Msg)
+}
+
+// 场景3：拥有 docs:view 权限可获取文档，且文档中标注了接口所需权限
+func TestDocsMiddleware_Success(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := tu.GetAccessUserTokenWithCodes([]string{middlewares.PermissionCodeViewDocs})
+
+	req, _ := http.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var spec map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &spec)
+	assert.NoError(t, err, "文档应为合法 JSON")
+
+	paths := spec["paths"].(map[string]any)
+	op := paths["/user/{id}"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, map[string]any{"method": "GET", "path": "/v1/user/:id"}, op["x-permission"], "接口应标注所需权限")
+}
+
+// 场景4：不接受查询参数中的令牌，避免令牌写入访问日志与 Referer
+func TestDocsMiddleware_QueryTokenRejected(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := tu.GetAccessUserTokenWithCodes([]string{middlewares.PermissionCodeViewDocs})
+
+	req, _ := http.NewRequest(http.MethodGet, "/swagger/doc.json?token="+token, nil)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	resp := parseResponse(t, w)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, "查询参数中的令牌应被忽略")
+	assert.Empty(t, w.Result().Cookies(), "不应写入 cookie")
+}
+
+// 场景5：通过请求头携带令牌时写入 cookie，后续请求可凭 cookie 访问
+func TestDocsMiddleware_HeaderTokenSetsCookie(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := tu.GetAccessUserTokenWithCodes([]string{middlewares.PermissionCodeViewDocs})
+
+	req, _ := http.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1, "应写入文档令牌 cookie") {
+		assert.True(t, cookies[0].Secure, "cookie 应为 Secure")
+		assert.True(t, cookies[0].HttpOnly, "cookie 应为 HttpOnly")
+		assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+
+		req2, _ := http.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+		req2.AddCookie(cookies[0])
+		w2 := httptest.NewRecorder()
+		testRouter.ServeHTTP(w2, req2)
+		assert.Equal(t, http.StatusOK, w2.Code)
+		assert.Contains(t, w2.Body.String(), "x-permission", "凭 cookie 应能获取文档")
+	}
+}
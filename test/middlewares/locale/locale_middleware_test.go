@@ -0,0 +1,102 @@
+package locale_middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/pkgs"
+)
+
+type createReq struct {
+	Name string `json:"name" validate:"required" label:"名称"`
+}
+
+// newRouter 只挂载语言与时区中间件
+func newRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	config := &pkgs.Config{
+		Time:   pkgs.TimeConfig{Timezone: "UTC", Format: "datetime", LanguageTimezones: map[string]string{"ja": "Asia/Tokyo"}},
+		Locale: pkgs.LocaleConfig{Default: pkgs.LocaleZh},
+	}
+	formatter, err := pkgs.NewTimeFormatter(config)
+	require.NoError(t, err)
+	localizer, err := pkgs.NewLocalizer(config)
+	require.NoError(t, err)
+	validator := pkgs.NewRequestValidator()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.HandlerFunc(middlewares.NewLocaleMiddleware(localizer, formatter)))
+	r.GET("/time", func(c *gin.Context) {
+		pkgs.Success(c, pkgs.FormatTime(c, time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)))
+	})
+	r.GET("/missing", func(c *gin.Context) { pkgs.Error(c, http.StatusNotFound, "用户不存在") })
+	r.GET("/invalid", func(c *gin.Context) {
+		pkgs.ValidateV2[createReq](validator)(&createReq{}).Match(
+			pkgs.HandleSuccess[*createReq](c),
+			pkgs.HandleError[*createReq](c),
+		)
+	})
+	return r
+}
+
+func get(r *gin.Engine, path string, headers map[string]string) (*httptest.ResponseRecorder, pkgs.Response) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp pkgs.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+// TestLocaleMiddleware 测试按请求头确定语言与时区
+// 包含四个子测试：默认语言、按 Accept-Language 返回英文、X-Timezone 请求头、非法时区
+func TestLocaleMiddleware(t *testing.T) {
+	r := newRouter(t)
+
+	t.Run("默认语言", func(t *testing.T) {
+		w, resp := get(r, "/missing", nil)
+		assert.Equal(t, "用户不存在", resp.Msg)
+		assert.Equal(t, pkgs.LocaleZh, w.Header().Get("Content-Language"))
+
+		_, resp = get(r, "/invalid", map[string]string{"Accept-Language": "fr-FR"})
+		assert.Equal(t, "名称为必填字段", resp.Msg, "不支持的语言使用默认语言")
+	})
+
+	t.Run("按 Accept-Language 返回英文", func(t *testing.T) {
+		w, resp := get(r, "/missing", map[string]string{"Accept-Language": "fr-FR, en-US;q=0.8, zh;q=0.5"})
+		assert.Equal(t, "User does not exist", resp.Msg)
+		assert.Equal(t, pkgs.LocaleEn, w.Header().Get("Content-Language"))
+
+		_, resp = get(r, "/invalid", map[string]string{"Accept-Language": "en"})
+		assert.Equal(t, "名称 is a required field", resp.Msg, "校验信息按请求语言翻译，字段名使用 label")
+	})
+
+	t.Run("X-Timezone 请求头", func(t *testing.T) {
+		_, resp := get(r, "/time", nil)
+		assert.Equal(t, "2025-10-01 08:30:00", resp.Data)
+
+		_, resp = get(r, "/time", map[string]string{"X-Timezone": "Asia/Shanghai", "Accept-Language": "ja"})
+		assert.Equal(t, "2025-10-01 16:30:00", resp.Data, "X-Timezone 优先于 Accept-Language 映射的时区")
+
+		_, resp = get(r, "/time?tz=America/New_York", map[string]string{"X-Timezone": "Asia/Shanghai"})
+		assert.Equal(t, "2025-10-01 04:30:00", resp.Data, "?tz= 优先于 X-Timezone")
+	})
+
+	t.Run("非法时区", func(t *testing.T) {
+		_, resp := get(r, "/time", map[string]string{"X-Timezone": "Not/AZone", "Accept-Language": "en"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "Invalid timezone", resp.Msg)
+	})
+}
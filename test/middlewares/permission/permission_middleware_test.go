@@ -2,10 +2,12 @@ package permission_middleware_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -138,3 +140,116 @@ func TestPermissionMiddleware_Whitelist_TemplateList_NoToken(t *testing.T) {
 	assert.True(t, ok)
 	assert.Contains(t, data, "list")
 }
+
+// 场景7：多个角色拥有同一权限 - 权限集合去重后仍正常放行
+func TestPermissionMiddleware_Success_SharedPermissionAcrossRoles(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	u := tu.SetupTestUser()
+	perm := tu.SetupTestPermission("GET /v1/permission/list")
+	for range 2 {
+		r := tu.SetupTestRole()
+		tu.AssignRoleToUser(u.ID, r.ID)
+		tu.AssignPermissionToRole(r.ID, perm.ID)
+	}
+	token := tu.GetAccessTokenByUser(u)
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/permission/list", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	resp := parseResponse(t, w)
+	assert.Equal(t, http.StatusOK, resp.Code, "两个角色拥有同一权限时应放行")
+}
+
+// 场景8：拒绝优先 - 一个角色授予、另一个角色拒绝同一接口时应403
+func TestPermissionMiddleware_Forbidden_DenyOverridesAllow(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	u := tu.SetupTestUser()
+	perm := tu.SetupTestPermission("GET /v1/permission/list")
+	allowRole := tu.SetupTestRole()
+	tu.AssignRoleToUser(u.ID, allowRole.ID)
+	tu.AssignPermissionToRole(allowRole.ID, perm.ID)
+	denyRole := tu.SetupTestRole()
+	tu.AssignRoleToUser(u.ID, denyRole.ID)
+	tu.DenyPermissionForRole(denyRole.ID, perm.ID)
+	token := tu.GetAccessTokenByUser(u)
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/permission/list", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	resp := parseResponse(t, w)
+	assert.Equal(t, http.StatusForbidden, resp.Code, "被任一角色拒绝的接口应返回403")
+	assert.Equal(t, "无接口访问权限", resp.Msg)
+}
+
+// 场景9：角色访问条件 - 唯一授予接口的角色限制了来源 IP，请求 IP 不在范围内时返回 IP 受限业务码
+func TestPermissionMiddleware_Forbidden_AccessConditionCIDR(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	u := tu.SetupTestUser()
+	perm := tu.SetupTestPermission("GET /v1/permission/list")
+	r := tu.SetupTestRole()
+	_, err := testDB.Exec(`UPDATE iacc_role SET access_conditions = $1 WHERE id = $2`, `{"cidrs":["203.0.113.0/24"]}`, r.ID)
+	assert.NoError(t, err)
+	tu.AssignRoleToUser(u.ID, r.ID)
+	tu.AssignPermissionToRole(r.ID, perm.ID)
+	token := tu.GetAccessTokenByUser(u)
+
+	request := func(remoteAddr, forwardedFor string) pkgs.Response {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/permission/list", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return parseResponse(t, w)
+	}
+	assert.Equal(t, pkgs.CodeAccessIPDenied, request("192.0.2.1:1234", "").Code, "IP 不在角色允许范围内时应返回 IP 受限业务码")
+	assert.Equal(t, http.StatusOK, request("203.0.113.7:1234", "").Code, "IP 在角色允许范围内时应放行")
+	// 默认不信任任何代理，客户端伪造的 X-Forwarded-For 不能绕过 IP 段限制
+	assert.Equal(t, pkgs.CodeAccessIPDenied, request("192.0.2.1:1234", "203.0.113.7").Code, "伪造 X-Forwarded-For 时仍应返回 IP 受限业务码")
+}
+
+// 场景10：角色访问条件只限制授予规则 - 拒绝角色不在其访问时间段内时，拒绝规则仍然生效
+func TestPermissionMiddleware_Forbidden_DenyIgnoresAccessCondition(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	u := tu.SetupTestUser()
+	perm := tu.SetupTestPermission("GET /v1/permission/list")
+	allowRole := tu.SetupTestRole()
+	tu.AssignRoleToUser(u.ID, allowRole.ID)
+	tu.AssignPermissionToRole(allowRole.ID, perm.ID)
+
+	// 拒绝角色只在 12 小时后的一分钟内"生效"，当前时间不在其时间段内
+	start := time.Now().UTC().Add(12 * time.Hour)
+	conditions := fmt.Sprintf(`{"time_windows":[{"start":%q,"end":%q,"timezone":"UTC"}]}`, start.Format("15:04"), start.Add(time.Minute).Format("15:04"))
+	denyRole := tu.SetupTestRole()
+	_, err := testDB.Exec(`UPDATE iacc_role SET access_conditions = $1 WHERE id = $2`, conditions, denyRole.ID)
+	assert.NoError(t, err)
+	tu.AssignRoleToUser(u.ID, denyRole.ID)
+	tu.DenyPermissionForRole(denyRole.ID, perm.ID)
+	token := tu.GetAccessTokenByUser(u)
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/permission/list", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	resp := parseResponse(t, w)
+	assert.Equal(t, http.StatusForbidden, resp.Code, "拒绝规则不受访问条件影响，应返回403")
+	assert.Equal(t, "无接口访问权限", resp.Msg)
+}
+
+// 场景11：路径参数接口纳入权限体系 - 存在 GET /v1/role/:id 记录时，无该权限的用户访问具体ID应403
+func TestPermissionMiddleware_Forbidden_PathParamGuarded(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_ = tu.SetupTestPermission("GET /v1/role/:id")
+	token := tu.GetNoPermissionUserToken()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/role/550e8400-e29b-41d4-a716-446655440000", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	resp := parseResponse(t, w)
+	assert.Equal(t, http.StatusForbidden, resp.Code, "按路由模板匹配到权限记录，应校验用户权限")
+	assert.Equal(t, "无接口访问权限", resp.Msg)
+}
@@ -0,0 +1,165 @@
+package publicapi_middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/pkgs"
+)
+
+// 复用应用实例
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	code := m.Run()
+	os.Exit(code)
+}
+
+// 辅助函数：直接在数据库中创建 API 密钥，返回完整密钥
+func createAPIKey(t *testing.T, revoked bool) string {
+	t.Helper()
+	key, prefix, hash, err := pkgs.GenerateAPIKey()
+	assert.NoError(t, err, "生成 API 密钥不应出错")
+
+	var id string
+	query := `INSERT INTO api_key (name, key_prefix, key_hash, tier, revoked_at)
+		VALUES ($1, $2, $3, 'basic', CASE WHEN $4 THEN CURRENT_TIMESTAMP END) RETURNING id`
+	err = testDB.GetContext(context.Background(), &id, query, "test_"+prefix, prefix, hash, revoked)
+	assert.NoError(t, err, "创建 API 密钥不应出错")
+	t.Cleanup(func() {
+		_, err := testDB.ExecContext(context.Background(), `DELETE FROM api_key WHERE id = $1`, id)
+		assert.NoError(t, err, "清理 API 密钥不应出错")
+	})
+	return key
+}
+
+// 辅助函数：请求公开接口
+func get(t *testing.T, url, key string) (*httptest.ResponseRecorder, pkgs.Response) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	var resp pkgs.Response
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err, "解析响应体不应出错")
+	return w, resp
+}
+
+// 场景1：未携带 API 密钥返回 401
+func TestPublicAPI_MissingKey(t *testing.T) {
+	_, resp := get(t, "/public/v1/template/list", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, "业务码应为401 未授权")
+}
+
+// 场景2：已吊销的 API 密钥返回 401
+func TestPublicAPI_RevokedKey(t *testing.T) {
+	key := createAPIKey(t, true)
+	_, resp := get(t, "/public/v1/template/list", key)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, "业务码应为401 未授权")
+}
+
+// 场景3：有效密钥可访问模板列表，返回限流响应头，重复请求命中缓存
+func TestPublicAPI_Success(t *testing.T) {
+	key := createAPIKey(t, false)
+
+	w, resp := get(t, "/public/v1/template/list?pageSize=5", key)
+	assert.Equal(t, http.StatusOK, resp.Code, "业务码应为200")
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Limit"), "应返回限流上限")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"), "首次请求不应命中缓存")
+
+	w, resp = get(t, "/public/v1/template/list?pageSize=5", key)
+	assert.Equal(t, http.StatusOK, resp.Code, "业务码应为200")
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"), "重复请求应命中缓存")
+	assert.Contains(t, w.Header().Get("Cache-Control"), "max-age", "命中缓存时应返回 Cache-Control")
+}
+
+// 场景4：不同 Accept-Language 对应的时区不同，响应分别缓存
+func TestPublicAPI_CacheVariesByLanguage(t *testing.T) {
+	key := createAPIKey(t, false)
+	getWithLanguage := func(language string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/public/v1/template/list?pageSize=3", nil)
+		req.Header.Set("X-API-Key", key)
+		req.Header.Set("Accept-Language", language)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	w := getWithLanguage("zh-CN")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"), "首次请求不应命中缓存")
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Language", "响应应随 Accept-Language 变化")
+
+	w = getWithLanguage("ja")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"), "其他语言的请求不应命中 zh-CN 的缓存")
+
+	w = getWithLanguage("zh-CN")
+	assert.Equal(t, "HIT", w.Header().Get("X-Cache"), "相同语言的重复请求应命中缓存")
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Language", "命中缓存时同样返回 Vary")
+}
+
+// 场景5：影子模式的等级超出限制时不拒绝请求，返回警告响应头并记录到影子模式统计
+func TestPublicAPI_RateLimitShadow(t *testing.T) {
+	config := &pkgs.Config{}
+	config.PublicAPI.Tiers = map[string]pkgs.RateLimitTier{
+		"shadow":  {Limit: 1, Window: time.Minute, Shadow: true},
+		"enforce": {Limit: 1, Window: time.Minute},
+	}
+	shadow := pkgs.NewRateLimitShadow()
+	limit := middlewares.NewRateLimitMiddleware(pkgs.NewSettings(config, nil, pkgs.NewTableNames(config), zap.NewNop()), shadow)
+	do := func(tier string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.GET("/", func(c *gin.Context) {
+			c.Set(pkgs.TenantContextKey, "public")
+			c.Set(pkgs.APIKeyIDContextKey, "key-"+tier)
+			c.Set(pkgs.APIKeyTierContextKey, tier)
+		}, gin.HandlerFunc(limit), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusNoContent, do("shadow").Code)
+	w := do("shadow")
+	assert.Equal(t, http.StatusNoContent, w.Code, "影子模式不拒绝请求")
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Warning"), "超出限制时应返回警告")
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	assert.Equal(t, http.StatusNoContent, do("enforce").Code)
+	w = do("enforce")
+	var resp pkgs.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), "解析响应体不应出错")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code, "未开启影子模式时拒绝请求")
+	assert.Empty(t, w.Header().Get("X-RateLimit-Warning"))
+
+	clients := shadow.Clients("public")
+	if assert.Len(t, clients, 1, "只记录影子模式的等级") {
+		assert.Equal(t, "key-shadow", clients[0].APIKeyID)
+		assert.Equal(t, int64(1), clients[0].Exceeded)
+	}
+}
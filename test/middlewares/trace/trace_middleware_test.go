@@ -0,0 +1,80 @@
+package trace_middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go-pg-demo/internal/middlewares"
+	"go-pg-demo/pkgs"
+)
+
+// newRouter 只挂载请求ID中间件，处理函数返回 context 中的请求ID，并用请求日志器写一条日志
+func newRouter(logger *zap.Logger) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.HandlerFunc(middlewares.NewTraceMiddleware(logger)))
+	r.GET("/ping", func(c *gin.Context) {
+		pkgs.RequestLogger(c, nil).Error("ping")
+		c.String(http.StatusOK, pkgs.TraceIDFromContext(c))
+	})
+	return r
+}
+
+// TestTraceMiddleware 测试请求ID中间件
+// 包含四个子测试：沿用合法的请求头、缺失时生成、非法时重新生成、请求日志器带上请求ID
+func TestTraceMiddleware(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	r := newRouter(zap.New(core))
+
+	t.Run("沿用合法的请求头", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(pkgs.TraceIDHeader, "req-123.abc")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, "req-123.abc", w.Header().Get(pkgs.TraceIDHeader))
+		assert.Equal(t, "req-123.abc", w.Body.String())
+	})
+
+	t.Run("缺失时生成", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		traceID := w.Header().Get(pkgs.TraceIDHeader)
+		assert.True(t, pkgs.ValidTraceID(traceID))
+		assert.Equal(t, traceID, w.Body.String())
+	})
+
+	t.Run("非法时重新生成", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(pkgs.TraceIDHeader, "bad id; drop")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		traceID := w.Header().Get(pkgs.TraceIDHeader)
+		assert.NotEqual(t, "bad id; drop", traceID)
+		assert.True(t, pkgs.ValidTraceID(traceID))
+	})
+
+	t.Run("请求日志器带上请求ID", func(t *testing.T) {
+		logs.TakeAll()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(pkgs.TraceIDHeader, "req-log-1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		entries := logs.TakeAll()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "req-log-1", entries[0].ContextMap()["trace_id"])
+
+		fallback := zap.NewNop()
+		assert.Same(t, fallback, pkgs.RequestLogger(&gin.Context{}, fallback), "未经过中间件时返回 fallback")
+	})
+}
@@ -0,0 +1,47 @@
+package module_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/migration"
+	"go-pg-demo/pkgs"
+)
+
+const mixed = `ALTER TABLE "iacc_user" ADD COLUMN seq BIGINT;
+-- module: template
+ALTER TABLE "template" ADD COLUMN seq BIGINT;
+-- end module
+ALTER TABLE "iacc_role" ADD COLUMN seq BIGINT;
+`
+
+// TestFilterModules 测试关闭模块时迁移内容的筛选
+// 包含三个子测试：模块迁移整体跳过、混合迁移去掉模块标记块、模块开启时内容不变
+func TestFilterModules(t *testing.T) {
+	disabled := []string{pkgs.ModuleTemplate}
+
+	t.Run("模块迁移整体跳过", func(t *testing.T) {
+		for _, name := range []string{"db/20251013153018_template.up.sql", "db/20251025120000_template_usage.down.sql"} {
+			out := migration.FilterModules(name, `CREATE TABLE "template" (id UUID);`, disabled)
+			assert.NotContains(t, out, "template", name)
+			assert.Contains(t, out, "SELECT 1;", "跳过的迁移不能为空")
+		}
+		// 名称只是包含模块名的迁移不属于该模块
+		out := migration.FilterModules("db/20251021120000_view_pii_permission.up.sql", "SELECT 2;", disabled)
+		assert.Equal(t, "SELECT 2;", out)
+	})
+
+	t.Run("混合迁移去掉模块标记块", func(t *testing.T) {
+		out := migration.FilterModules("db/20251027120000_seq_tiebreak.up.sql", mixed, disabled)
+		assert.Equal(t, "ALTER TABLE \"iacc_user\" ADD COLUMN seq BIGINT;\nALTER TABLE \"iacc_role\" ADD COLUMN seq BIGINT;\n", out)
+	})
+
+	t.Run("模块开启时内容不变", func(t *testing.T) {
+		out := migration.FilterModules("db/20251013153018_template.up.sql", "SELECT 3;", nil)
+		assert.Equal(t, "SELECT 3;", out)
+		out = migration.FilterModules("db/20251027120000_seq_tiebreak.up.sql", mixed, nil)
+		assert.Contains(t, out, `ALTER TABLE "template" ADD COLUMN seq BIGINT;`)
+		assert.NotContains(t, out, "-- module:")
+	})
+}
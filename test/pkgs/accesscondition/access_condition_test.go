@@ -0,0 +1,86 @@
+package accesscondition_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestAccessConditionsTimeWindows 测试访问时间段
+// 包含四个子测试：未配置条件不限制、工作时间内外、按星期限制、跨午夜时间段
+func TestAccessConditionsTimeWindows(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("缺少时区数据")
+	}
+	// 2025-10-29 为周三
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 10, day, hour, minute, 0, 0, shanghai)
+	}
+
+	t.Run("未配置条件不限制", func(t *testing.T) {
+		var conditions *pkgs.AccessConditions
+		assert.Nil(t, conditions.Check(at(29, 3, 0), "10.0.0.1"))
+		assert.Nil(t, (&pkgs.AccessConditions{}).Check(at(29, 3, 0), "10.0.0.1"))
+	})
+
+	workHours := &pkgs.AccessConditions{TimeWindows: []pkgs.TimeWindow{
+		{Weekdays: []int{1, 2, 3, 4, 5}, Start: "09:00", End: "18:00", Timezone: "Asia/Shanghai"},
+	}}
+
+	t.Run("工作时间内外", func(t *testing.T) {
+		assert.Nil(t, workHours.Check(at(29, 9, 0), ""))
+		assert.Nil(t, workHours.Check(at(29, 17, 59), ""))
+		denial := workHours.Check(at(29, 18, 0), "")
+		if assert.NotNil(t, denial) {
+			assert.Equal(t, pkgs.CodeAccessTimeDenied, denial.Code)
+		}
+		// 按时间段的时区判断，UTC 01:00 即上海 09:00
+		assert.Nil(t, workHours.Check(time.Date(2025, 10, 29, 1, 0, 0, 0, time.UTC), ""))
+	})
+
+	t.Run("按星期限制", func(t *testing.T) {
+		// 2025-11-01 为周六
+		assert.NotNil(t, workHours.Check(time.Date(2025, 11, 1, 10, 0, 0, 0, shanghai), ""))
+	})
+
+	t.Run("跨午夜时间段", func(t *testing.T) {
+		// 周五 22:00 开始的夜班持续到周六 06:00
+		night := &pkgs.AccessConditions{TimeWindows: []pkgs.TimeWindow{
+			{Weekdays: []int{5}, Start: "22:00", End: "06:00", Timezone: "Asia/Shanghai"},
+		}}
+		assert.Nil(t, night.Check(at(31, 23, 0), ""))
+		assert.Nil(t, night.Check(time.Date(2025, 11, 1, 5, 59, 0, 0, shanghai), ""))
+		assert.NotNil(t, night.Check(time.Date(2025, 11, 1, 6, 0, 0, 0, shanghai), ""))
+		// 周五凌晨属于周四开始的时间段
+		assert.NotNil(t, night.Check(at(31, 2, 0), ""))
+	})
+}
+
+// TestAccessConditionsCIDRs 测试来源 IP 段，同时配置时间段时两者都需满足
+func TestAccessConditionsCIDRs(t *testing.T) {
+	office := &pkgs.AccessConditions{CIDRs: []string{"10.0.0.0/8", "2001:db8::/32"}}
+	now := time.Now()
+
+	assert.Nil(t, office.Check(now, "10.1.2.3"))
+	assert.Nil(t, office.Check(now, "2001:db8::1"))
+	for _, ip := range []string{"192.168.1.1", "", "not-an-ip"} {
+		denial := office.Check(now, ip)
+		if assert.NotNil(t, denial, ip) {
+			assert.Equal(t, pkgs.CodeAccessIPDenied, denial.Code)
+		}
+	}
+
+	both := &pkgs.AccessConditions{
+		// 起止相同的时间段不包含任何时刻
+		TimeWindows: []pkgs.TimeWindow{{Start: "00:00", End: "00:00"}},
+		CIDRs:       office.CIDRs,
+	}
+	denial := both.Check(now, "10.1.2.3")
+	if assert.NotNil(t, denial) {
+		assert.Equal(t, pkgs.CodeAccessTimeDenied, denial.Code)
+	}
+}
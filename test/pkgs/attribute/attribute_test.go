@@ -0,0 +1,79 @@
+package attribute_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+var defs = []pkgs.AttributeDefinition{
+	{Key: "cost_center", Type: pkgs.AttributeTypeString, Required: true},
+	{Key: "level", Type: pkgs.AttributeTypeNumber},
+	{Key: "sensitive", Type: pkgs.AttributeTypeBoolean},
+	{Key: "team", Type: pkgs.AttributeTypeEnum, Options: []string{"infra", "sales"}},
+}
+
+// fields 返回违规项的字段名
+func fields(violations []pkgs.Violation) []string {
+	result := make([]string, len(violations))
+	for i, v := range violations {
+		result[i] = v.Field
+	}
+	return result
+}
+
+// TestAttributeViolations 测试按属性定义校验属性值
+// 包含三个子测试：完整校验、合并更新、拆分合并更新
+func TestAttributeViolations(t *testing.T) {
+	t.Run("完整校验", func(t *testing.T) {
+		assert.Empty(t, pkgs.AttributeViolations("attributes", defs, pkgs.Attributes{
+			"cost_center": "CC100", "level": float64(3), "sensitive": true, "team": "infra",
+		}, true))
+
+		violations := pkgs.AttributeViolations("attributes", defs, pkgs.Attributes{
+			"level": "3", "sensitive": "yes", "team": "finance", "owner": "x",
+		}, true)
+		assert.Equal(t, []string{
+			"attributes.level", "attributes.owner", "attributes.sensitive", "attributes.team", "attributes.cost_center",
+		}, fields(violations), "类型不符、未定义、取值不在选项中、缺少必填属性")
+	})
+
+	t.Run("合并更新", func(t *testing.T) {
+		assert.Empty(t, pkgs.AttributeViolations("attributes", defs, pkgs.Attributes{"level": nil}, false), "合并更新不要求必填属性，null 删除非必填属性")
+		violations := pkgs.AttributeViolations("roles[1].attributes", defs, pkgs.Attributes{"cost_center": nil}, false)
+		assert.Equal(t, []string{"roles[1].attributes.cost_center"}, fields(violations), "必填属性不能删除")
+	})
+
+	t.Run("拆分合并更新", func(t *testing.T) {
+		set, removed := pkgs.SplitAttributePatch(pkgs.Attributes{"team": "sales", "level": nil, "sensitive": nil})
+		assert.Equal(t, pkgs.Attributes{"team": "sales"}, set)
+		assert.Equal(t, []string{"level", "sensitive"}, removed)
+	})
+}
+
+// TestAttributeFilters 测试列表接口的属性筛选条件
+// 包含两个子测试：格式校验、生成查询条件
+func TestAttributeFilters(t *testing.T) {
+	t.Run("格式校验", func(t *testing.T) {
+		assert.Empty(t, pkgs.AttributeFilters{"team:infra", "note:a:b", "level:"}.Violations("attr"))
+		violations := pkgs.AttributeFilters{"team", "Team:infra", "team:infra"}.Violations("attr")
+		assert.Len(t, violations, 2)
+		assert.Equal(t, []int{0}, violations[0].Indexes)
+		assert.Equal(t, []int{1}, violations[1].Indexes)
+	})
+
+	t.Run("生成查询条件", func(t *testing.T) {
+		params := map[string]any{}
+		clauses := pkgs.AttributeFilters{"team:infra", "note:a:b"}.Where("r.attributes", params)
+		assert.Equal(t, []string{
+			"r.attributes ->> :attr_0_key = :attr_0_value",
+			"r.attributes ->> :attr_1_key = :attr_1_value",
+		}, clauses)
+		assert.Equal(t, map[string]any{
+			"attr_0_key": "team", "attr_0_value": "infra",
+			"attr_1_key": "note", "attr_1_value": "a:b",
+		}, params)
+	})
+}
@@ -0,0 +1,35 @@
+package audit_test
+
+import (
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAuditChanges 测试操作前后快照的对比
+// 包含三个子测试：只返回发生变化的字段、只在一侧出现的字段、没有变化
+func TestAuditChanges(t *testing.T) {
+	t.Run("只返回发生变化的字段", func(t *testing.T) {
+		before := map[string]any{"name": "a", "critical": false, "permissions": map[string]any{"p1": "allow"}}
+		after := map[string]any{"name": "b", "critical": false, "permissions": map[string]any{"p1": "deny"}}
+		assert.Equal(t, map[string]any{
+			"name":        map[string]any{"before": "a", "after": "b"},
+			"permissions": map[string]any{"before": map[string]any{"p1": "allow"}, "after": map[string]any{"p1": "deny"}},
+		}, pkgs.AuditChanges(before, after))
+	})
+
+	t.Run("只在一侧出现的字段", func(t *testing.T) {
+		changes := pkgs.AuditChanges(map[string]any{"old": 1.0}, map[string]any{"new": 2.0})
+		assert.Equal(t, map[string]any{
+			"old": map[string]any{"before": 1.0, "after": nil},
+			"new": map[string]any{"before": nil, "after": 2.0},
+		}, changes)
+	})
+
+	t.Run("没有变化", func(t *testing.T) {
+		state := map[string]any{"role_ids": []any{"r1", "r2"}}
+		assert.Empty(t, pkgs.AuditChanges(state, map[string]any{"role_ids": []any{"r1", "r2"}}))
+	})
+}
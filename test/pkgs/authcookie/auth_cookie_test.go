@@ -0,0 +1,130 @@
+package authcookie_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// signToken 签发测试用的令牌，有效期为 ttl
+func signToken(t *testing.T, ttl time.Duration) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": time.Now().Add(ttl).Unix()}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func newContext(method string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(method, "/v1/auth/login", nil)
+	return c, w
+}
+
+// cookiesByName 解析响应中写入的 cookie
+func cookiesByName(w *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+// TestAuthCookies 测试 Cookie 鉴权的 cookie 写入与删除
+// 包含三个子测试：未开启时不写入、写入令牌与 CSRF 令牌、退出时删除
+func TestAuthCookies(t *testing.T) {
+	enabled := &pkgs.Config{JWT: pkgs.JWTConfig{Cookie: pkgs.AuthCookieConfig{Enabled: true, Secure: true, SameSite: pkgs.SameSiteStrict}}}
+
+	t.Run("未开启时不写入", func(t *testing.T) {
+		c, w := newContext(http.MethodPost)
+		require.NoError(t, pkgs.SetAuthCookies(c, &pkgs.Config{}, signToken(t, time.Minute), signToken(t, time.Hour)))
+		assert.Empty(t, w.Result().Cookies())
+		assert.Empty(t, pkgs.CookieToken(c, &pkgs.Config{}, pkgs.AccessTokenCookie))
+	})
+
+	t.Run("写入令牌与 CSRF 令牌", func(t *testing.T) {
+		access, refresh := signToken(t, time.Minute), signToken(t, time.Hour)
+		c, w := newContext(http.MethodPost)
+		require.NoError(t, pkgs.SetAuthCookies(c, enabled, access, refresh))
+		cookies := cookiesByName(w)
+
+		require.Contains(t, cookies, pkgs.AccessTokenCookie)
+		assert.Equal(t, access, cookies[pkgs.AccessTokenCookie].Value)
+		assert.True(t, cookies[pkgs.AccessTokenCookie].HttpOnly)
+		assert.True(t, cookies[pkgs.AccessTokenCookie].Secure)
+		assert.Equal(t, http.SameSiteStrictMode, cookies[pkgs.AccessTokenCookie].SameSite)
+		assert.InDelta(t, 60, cookies[pkgs.AccessTokenCookie].MaxAge, 2, "有效期取令牌的过期时间")
+
+		require.Contains(t, cookies, pkgs.RefreshTokenCookie)
+		assert.Equal(t, refresh, cookies[pkgs.RefreshTokenCookie].Value)
+		assert.Equal(t, "/v1/auth", cookies[pkgs.RefreshTokenCookie].Path, "刷新令牌只发送给认证接口")
+		assert.InDelta(t, 3600, cookies[pkgs.RefreshTokenCookie].MaxAge, 2)
+
+		require.Contains(t, cookies, pkgs.CSRFTokenCookie)
+		assert.NotEmpty(t, cookies[pkgs.CSRFTokenCookie].Value)
+		assert.False(t, cookies[pkgs.CSRFTokenCookie].HttpOnly, "前端脚本需要读取 CSRF 令牌")
+	})
+
+	t.Run("退出时删除", func(t *testing.T) {
+		c, w := newContext(http.MethodPost)
+		pkgs.ClearAuthCookies(c, enabled)
+		cookies := cookiesByName(w)
+		for _, name := range []string{pkgs.AccessTokenCookie, pkgs.RefreshTokenCookie, pkgs.CSRFTokenCookie} {
+			require.Contains(t, cookies, name)
+			assert.Empty(t, cookies[name].Value)
+			assert.Negative(t, cookies[name].MaxAge)
+		}
+	})
+}
+
+// TestValidCSRF 测试双重提交的 CSRF 校验
+// 包含四个子测试：只读请求不校验、请求头与 cookie 一致、请求头不一致、缺少 cookie
+func TestValidCSRF(t *testing.T) {
+	request := func(method, cookie, header string) *gin.Context {
+		c, _ := newContext(method)
+		if cookie != "" {
+			c.Request.AddCookie(&http.Cookie{Name: pkgs.CSRFTokenCookie, Value: cookie})
+		}
+		if header != "" {
+			c.Request.Header.Set(pkgs.CSRFHeader, header)
+		}
+		return c
+	}
+
+	t.Run("只读请求不校验", func(t *testing.T) {
+		assert.True(t, pkgs.ValidCSRF(request(http.MethodGet, "", "")))
+		assert.True(t, pkgs.ValidCSRF(request(http.MethodHead, "abc", "")))
+	})
+
+	t.Run("请求头与 cookie 一致", func(t *testing.T) {
+		assert.True(t, pkgs.ValidCSRF(request(http.MethodPost, "abc", "abc")))
+		assert.True(t, pkgs.ValidCSRF(request(http.MethodDelete, "abc", "abc")))
+	})
+
+	t.Run("请求头不一致", func(t *testing.T) {
+		assert.False(t, pkgs.ValidCSRF(request(http.MethodPost, "abc", "")))
+		assert.False(t, pkgs.ValidCSRF(request(http.MethodPut, "abc", "abd")))
+	})
+
+	t.Run("缺少 cookie", func(t *testing.T) {
+		assert.False(t, pkgs.ValidCSRF(request(http.MethodPost, "", "")))
+		assert.False(t, pkgs.ValidCSRF(request(http.MethodPatch, "", "abc")))
+	})
+}
+
+// TestAuthCookieConfigValidate 测试 Cookie 鉴权配置的校验
+func TestAuthCookieConfigValidate(t *testing.T) {
+	assert.NoError(t, pkgs.AuthCookieConfig{SameSite: pkgs.SameSiteLax}.Validate())
+	assert.NoError(t, pkgs.AuthCookieConfig{SameSite: pkgs.SameSiteNone, Secure: true}.Validate())
+	assert.ErrorContains(t, pkgs.AuthCookieConfig{SameSite: pkgs.SameSiteNone}.Validate(), "secure must be true")
+	assert.ErrorContains(t, pkgs.AuthCookieConfig{SameSite: "relaxed"}.Validate(), "invalid jwt.cookie.same_site")
+}
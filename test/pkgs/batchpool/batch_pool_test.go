@@ -0,0 +1,38 @@
+package batchpool_test
+
+import (
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// TestBatchPool 测试批处理连接池的选择
+// 包含两个子测试：未配置时共用交互连接池、单租户请求使用批处理连接池
+func TestBatchPool(t *testing.T) {
+	interactive := &sqlx.DB{}
+
+	t.Run("未配置时共用交互连接池", func(t *testing.T) {
+		config := &pkgs.Config{}
+		batch, cleanup, err := pkgs.NewBatchConnection(config, interactive)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer cleanup()
+		assert.Same(t, interactive, batch.DB, "max_open_conns 为 0 时应共用交互连接池")
+	})
+
+	t.Run("单租户请求使用批处理连接池", func(t *testing.T) {
+		batch := &pkgs.BatchDB{DB: &sqlx.DB{}}
+		pool, cleanup := pkgs.NewTenantPool(&pkgs.Config{}, interactive, batch, zap.NewNop())
+		defer cleanup()
+
+		c, _ := gin.CreateTestContext(nil)
+		assert.Same(t, interactive, pool.DB(c), "交互请求应使用交互连接池")
+		assert.Same(t, batch.DB, pool.BatchDB(c), "批量操作应使用批处理连接池")
+	})
+}
@@ -0,0 +1,80 @@
+package bind_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type getReq struct {
+	ID string `uri:"id" validate:"required,uuid" label:"模板ID"`
+}
+
+type changeReq struct {
+	ID     string `uri:"id" binding:"required,uuid" label:"角色ID"`
+	Name   string `json:"name"`
+	Detail string `uri:"detail"`
+}
+
+// bind 在路由中执行绑定，返回绑定结果
+func bind[T any](pattern, path string, binder func(*gin.Context) (*T, error)) (*T, error) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	var req *T
+	var err error
+	engine.Handle(http.MethodPost, pattern, func(c *gin.Context) {
+		req, err = binder(c)
+	})
+	r, _ := http.NewRequest(http.MethodPost, path, nil)
+	engine.ServeHTTP(httptest.NewRecorder(), r)
+	return req, err
+}
+
+func bindUri(c *gin.Context) (*getReq, error) {
+	return pkgs.BindUri[getReq](c).Get()
+}
+
+// TestBindUriUUID 测试路径参数的 UUID 校验
+// 包含三个子测试：有效 UUID、无效 UUID 统一返回 400、未声明 uuid 的路径参数不校验
+func TestBindUriUUID(t *testing.T) {
+	t.Run("有效 UUID", func(t *testing.T) {
+		req, err := bind("/t/:id", "/t/0199c5a0-0000-7000-8000-000000000001", bindUri)
+		assert.NoError(t, err)
+		assert.Equal(t, "0199c5a0-0000-7000-8000-000000000001", req.ID)
+	})
+
+	t.Run("无效 UUID 统一返回 400", func(t *testing.T) {
+		for _, id := range []string{"invalid-id", "a-b-c-d-e", "0199c5a000007000800000000000000001", "{0199c5a0-0000-7000-8000-000000000001}"} {
+			_, err := bind("/t/:id", "/t/"+id, bindUri)
+			apiErr, ok := err.(*pkgs.ApiError)
+			if assert.True(t, ok, "应返回 ApiError: %s", id) {
+				assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+				assert.Equal(t, "模板ID必须是一个有效的UUID", apiErr.Message)
+				assert.Equal(t, []pkgs.Violation{{Field: "id", Message: "模板ID必须是一个有效的UUID"}}, apiErr.Data)
+			}
+		}
+
+		// binding 标签与 validate 标签的路径参数返回相同格式的错误
+		_, err := bind("/t/:id/:detail", "/t/invalid-id/x", func(c *gin.Context) (*changeReq, error) {
+			return pkgs.BindUri[changeReq](c).Get()
+		})
+		apiErr, ok := err.(*pkgs.ApiError)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+			assert.Equal(t, "角色ID必须是一个有效的UUID", apiErr.Message)
+		}
+	})
+
+	t.Run("未声明 uuid 的路径参数不校验", func(t *testing.T) {
+		req, err := bind("/t/:id/:detail", "/t/0199c5a0-0000-7000-8000-000000000001/not-a-uuid", func(c *gin.Context) (*changeReq, error) {
+			return pkgs.BindUri[changeReq](c).Get()
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "not-a-uuid", req.Detail)
+	})
+}
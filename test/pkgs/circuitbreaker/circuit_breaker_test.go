@@ -0,0 +1,56 @@
+package circuitbreaker_test
+
+import (
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCircuitBreaker 测试熔断器状态切换
+// 包含三个子测试：连续失败后打开、冷却后半开只放行一次、探测失败重新打开
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("连续失败后打开", func(t *testing.T) {
+		b := pkgs.NewCircuitBreakerWithClock(3, time.Second, clock)
+		b.Failure()
+		b.Failure()
+		b.Success()
+		b.Failure()
+		b.Failure()
+		assert.Equal(t, pkgs.CircuitClosed, b.State(), "成功后应重新计数")
+		b.Failure()
+		assert.Equal(t, pkgs.CircuitOpen, b.State())
+		assert.False(t, b.Allow(), "冷却期内不应放行")
+	})
+
+	t.Run("冷却后半开只放行一次", func(t *testing.T) {
+		start := now
+		b := pkgs.NewCircuitBreakerWithClock(1, time.Second, func() time.Time { return start })
+		b.Failure()
+		start = start.Add(time.Second)
+		assert.True(t, b.Allow(), "冷却结束应放行探测")
+		assert.Equal(t, pkgs.CircuitHalfOpen, b.State())
+		assert.False(t, b.Allow(), "探测结束前不应再放行")
+		b.Success()
+		assert.Equal(t, pkgs.CircuitClosed, b.State())
+		assert.True(t, b.Allow())
+	})
+
+	t.Run("探测失败重新打开", func(t *testing.T) {
+		start := now
+		b := pkgs.NewCircuitBreakerWithClock(5, time.Second, func() time.Time { return start })
+		for i := 0; i < 5; i++ {
+			b.Failure()
+		}
+		start = start.Add(time.Second)
+		assert.True(t, b.Allow())
+		b.Failure()
+		assert.Equal(t, pkgs.CircuitOpen, b.State(), "半开状态下一次失败即重新打开")
+		assert.False(t, b.Allow())
+	})
+}
@@ -0,0 +1,41 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestEncryptionConfigValidate 测试加密密钥组合的校验
+func TestEncryptionConfigValidate(t *testing.T) {
+	key := "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+	hashKey := strings.Repeat("h", 32)
+
+	cases := []struct {
+		name    string
+		config  pkgs.EncryptionConfig
+		wantErr string
+	}{
+		{name: "未启用加密", config: pkgs.EncryptionConfig{}},
+		{name: "启用加密且影子列密钥合法", config: pkgs.EncryptionConfig{Key: key, HashKey: hashKey}},
+		{name: "启用加密但影子列密钥为空", config: pkgs.EncryptionConfig{Key: key}, wantErr: "hash_key is required"},
+		{name: "影子列密钥过短", config: pkgs.EncryptionConfig{Key: key, HashKey: "short"}, wantErr: "at least 32 bytes"},
+		{name: "影子列密钥与加密密钥相同", config: pkgs.EncryptionConfig{Key: key, HashKey: key}, wantErr: "must differ from encryption.key"},
+		{name: "假名化密钥与影子列密钥相同", config: pkgs.EncryptionConfig{Key: key, HashKey: hashKey, PseudonymKey: hashKey}, wantErr: "pseudonym_key must differ"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
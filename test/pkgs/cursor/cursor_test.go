@@ -0,0 +1,91 @@
+package cursor_test
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type row struct {
+	Seq       int64
+	CreatedAt time.Time
+}
+
+func rowKey(r row) (time.Time, int64) {
+	return r.CreatedAt, r.Seq
+}
+
+// TestCursorPagination 测试游标分页
+// 包含五个子测试：未传参数时按偏移分页、第一页、下一页游标定位上一页最后一行、最后一页没有游标、无效参数
+func TestCursorPagination(t *testing.T) {
+	base := time.Date(2025, 1, 1, 8, 0, 0, 123456000, time.UTC)
+	rows := make([]row, 3)
+	for i := range rows {
+		rows[i] = row{Seq: int64(i + 1), CreatedAt: base.Add(time.Duration(i) * time.Second)}
+	}
+
+	t.Run("未传参数时按偏移分页", func(t *testing.T) {
+		p := pkgs.CursorPagination{}
+		assert.False(t, p.Enabled())
+		assert.Empty(t, p.Violations("username"))
+		page, next := pkgs.CursorPage(p, rows, rowKey)
+		assert.Len(t, page, 3)
+		assert.Empty(t, next)
+	})
+
+	t.Run("第一页", func(t *testing.T) {
+		p := pkgs.CursorPagination{Limit: 2}
+		require.Empty(t, p.Violations("created_at"))
+		params := map[string]any{}
+		where, order := p.Keyset(" WHERE name ILIKE :name", "DESC", params)
+		assert.Equal(t, " WHERE name ILIKE :name", where)
+		assert.Equal(t, " ORDER BY created_at DESC, seq DESC LIMIT :limit", order)
+		assert.Equal(t, 3, params["limit"], "多查询一行用于判断是否还有下一页")
+	})
+
+	t.Run("下一页游标定位上一页最后一行", func(t *testing.T) {
+		page, next := pkgs.CursorPage(pkgs.CursorPagination{Limit: 2}, rows, rowKey)
+		require.Len(t, page, 2)
+		require.NotEmpty(t, next)
+
+		p := pkgs.CursorPagination{Cursor: next, Limit: 2}
+		require.Empty(t, p.Violations("created_at"))
+		params := map[string]any{}
+		where, order := p.Keyset("", "ASC", params)
+		assert.Equal(t, " WHERE (created_at, seq) > (:cursor_at, :cursor_seq)", where)
+		assert.Equal(t, " ORDER BY created_at ASC, seq ASC LIMIT :limit", order)
+		assert.True(t, rows[1].CreatedAt.Equal(params["cursor_at"].(time.Time)))
+		assert.Equal(t, rows[1].Seq, params["cursor_seq"])
+
+		where, _ = p.Keyset(" WHERE a = :a", "DESC", params)
+		assert.Equal(t, " WHERE a = :a AND (created_at, seq) < (:cursor_at, :cursor_seq)", where)
+	})
+
+	t.Run("最后一页没有游标", func(t *testing.T) {
+		page, next := pkgs.CursorPage(pkgs.CursorPagination{Cursor: "", Limit: 3}, rows, rowKey)
+		assert.Len(t, page, 3)
+		assert.Empty(t, next)
+	})
+
+	t.Run("无效参数", func(t *testing.T) {
+		violations := pkgs.CursorPagination{Cursor: "not-a-cursor"}.Violations("created_at")
+		require.Len(t, violations, 1)
+		assert.Equal(t, "cursor", violations[0].Field)
+
+		// 按 (创建时间, 主键) 编码的旧游标不再有效
+		legacy := base64.RawURLEncoding.EncodeToString([]byte("1735718400123456:" + uuid.NewString()))
+		violations = pkgs.CursorPagination{Cursor: legacy}.Violations("created_at")
+		require.Len(t, violations, 1)
+		assert.Equal(t, "cursor", violations[0].Field)
+
+		violations = pkgs.CursorPagination{Limit: 10}.Violations("username")
+		require.Len(t, violations, 1)
+		assert.Equal(t, "orderBy", violations[0].Field)
+	})
+}
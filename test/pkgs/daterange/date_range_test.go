@@ -0,0 +1,99 @@
+package daterange_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listReq struct {
+	Name string `form:"name"`
+	pkgs.DateRange
+}
+
+// bind 绑定查询参数，返回请求体与请求上下文（时区为 loc）
+func bind(t *testing.T, query string, loc *time.Location) (*listReq, *gin.Context) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/list?"+query, nil)
+	c.Set(pkgs.TimeLocationContextKey, loc)
+	req, err := pkgs.BindQuery[listReq](c).Get()
+	require.NoError(t, err)
+	return req, c
+}
+
+// TestDateRange 测试列表接口的创建、更新时间筛选
+// 包含五个子测试：日期按请求时区解析且截止日期包含全天、时间按原值比较、增量同步、无效参数、半开区间
+func TestDateRange(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	t.Run("日期按请求时区解析且截止日期包含全天", func(t *testing.T) {
+		req, c := bind(t, "createdFrom=2025-01-01&createdTo=2025-01-31", shanghai)
+		assert.Empty(t, req.Violations())
+
+		params := map[string]any{}
+		clauses := req.Where(c, params)
+		assert.Equal(t, []string{"created_at >= :created_from", "created_at < :created_to"}, clauses)
+		assert.True(t, time.Date(2025, 1, 1, 0, 0, 0, 0, shanghai).Equal(params["created_from"].(time.Time)))
+		assert.True(t, time.Date(2025, 2, 1, 0, 0, 0, 0, shanghai).Equal(params["created_to"].(time.Time)))
+	})
+
+	t.Run("时间按原值比较", func(t *testing.T) {
+		req, c := bind(t, "updatedFrom=2025-01-01T08:00:00Z&updatedTo=2025-01-01T19:30:00%2B08:00", shanghai)
+		assert.Empty(t, req.Violations())
+
+		params := map[string]any{}
+		clauses := req.Where(c, params)
+		assert.Equal(t, []string{"updated_at >= :updated_from", "updated_at <= :updated_to"}, clauses)
+		assert.True(t, time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC).Equal(params["updated_from"].(time.Time)))
+		assert.True(t, time.Date(2025, 1, 1, 11, 30, 0, 0, time.UTC).Equal(params["updated_to"].(time.Time)))
+	})
+
+	t.Run("增量同步", func(t *testing.T) {
+		req, c := bind(t, "changedSince=2025-01-01T08:00:00.123456Z", shanghai)
+		assert.Empty(t, req.Violations())
+
+		params := map[string]any{}
+		assert.Equal(t, []string{"updated_at > :changed_since"}, req.Where(c, params))
+		assert.True(t, time.Date(2025, 1, 1, 8, 0, 0, 123456000, time.UTC).Equal(params["changed_since"].(time.Time)))
+
+		req, _ = bind(t, "changedSince=2025-01-01", shanghai)
+		if violations := req.Violations(); assert.Len(t, violations, 1) {
+			assert.Equal(t, "changedSince", violations[0].Field)
+		}
+	})
+
+	t.Run("无效参数", func(t *testing.T) {
+		req, _ := bind(t, "createdFrom=yesterday&updatedFrom=2025-02-01&updatedTo=2025-01-01", shanghai)
+		violations := req.Violations()
+		if assert.Len(t, violations, 2) {
+			assert.Equal(t, "createdFrom", violations[0].Field)
+			assert.Equal(t, "updatedFrom", violations[1].Field)
+			assert.Equal(t, "起始时间不能晚于截止时间", violations[1].Message)
+		}
+
+		req, c := bind(t, "", shanghai)
+		assert.Empty(t, req.Where(c, map[string]any{}), "未传参数时没有查询条件")
+	})
+
+	t.Run("半开区间", func(t *testing.T) {
+		req, c := bind(t, "createdFrom=2025-01-01&createdTo=2025-01-31", shanghai)
+		from, to := req.CreatedBounds(c)
+		assert.True(t, time.Date(2025, 1, 1, 0, 0, 0, 0, shanghai).Equal(from))
+		assert.True(t, time.Date(2025, 2, 1, 0, 0, 0, 0, shanghai).Equal(to), "截止日期包含全天")
+
+		req, c = bind(t, "createdTo=2025-01-01T08:00:00.1234567Z", shanghai)
+		from, to = req.CreatedBounds(c)
+		assert.True(t, from.IsZero(), "未传入时为零值")
+		assert.True(t, time.Date(2025, 1, 1, 8, 0, 0, 123457000, time.UTC).Equal(to), "截止时间包含到微秒")
+	})
+}
@@ -0,0 +1,27 @@
+package distinct_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestDistinctFields 测试取值接口的字段白名单与查询语句
+func TestDistinctFields(t *testing.T) {
+	fields := pkgs.DistinctFields{"type": "type", "method": "metadata->>'method'"}
+	assert.Equal(t, []string{"method", "type"}, fields.Names())
+	assert.Empty(t, fields.Violations("type"))
+	assert.Empty(t, fields.Violations(""), "缺少字段由 required 标签校验")
+
+	violations := fields.Violations("name")
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, "field", violations[0].Field)
+		assert.Contains(t, violations[0].Message, "method, type")
+	}
+
+	assert.Equal(t,
+		"SELECT metadata->>'method' AS value, count(*) AS count FROM iacc_permission WHERE type = :type GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT 100",
+		fields.Query("method", "iacc_permission", " WHERE type = :type"))
+}
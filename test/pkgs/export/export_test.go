@@ -0,0 +1,104 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sheetRows 解析 xlsx 中工作表的单元格文本
+func sheetRows(t *testing.T, data []byte) [][]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	names := map[string]*zip.File{}
+	for _, f := range zr.File {
+		names[f.Name] = f
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		require.Contains(t, names, name)
+	}
+	require.Contains(t, names, "xl/worksheets/sheet1.xml")
+	f, err := names["xl/worksheets/sheet1.xml"].Open()
+	require.NoError(t, err)
+	defer f.Close()
+	raw, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Type string `xml:"t,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal(raw, &sheet))
+	var rows [][]string
+	for i, row := range sheet.Rows {
+		assert.Equal(t, i+1, row.R, "行号从 1 开始连续")
+		var cells []string
+		for _, cell := range row.Cells {
+			assert.Equal(t, "inlineStr", cell.Type)
+			cells = append(cells, cell.Text)
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// TestRowWriter 测试导出文件的逐行写入
+// 包含三个子测试：CSV 转义公式前缀、XLSX 为合法的工作簿、XLSX 转义特殊字符
+func TestRowWriter(t *testing.T) {
+	t.Run("CSV 转义公式前缀", func(t *testing.T) {
+		var buf bytes.Buffer
+		rw, err := pkgs.NewRowWriter(pkgs.ExportFormatCSV, &buf)
+		require.NoError(t, err)
+		require.NoError(t, rw.Write([]string{"id", "name"}))
+		require.NoError(t, rw.Write([]string{"1", "=SUM(A1)"}))
+		require.NoError(t, rw.Close())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"id", "name"}, {"1", "'=SUM(A1)"}}, records)
+		assert.Equal(t, "text/csv; charset=utf-8", pkgs.ExportContentType(pkgs.ExportFormatCSV))
+	})
+
+	t.Run("XLSX 为合法的工作簿", func(t *testing.T) {
+		var buf bytes.Buffer
+		rw, err := pkgs.NewRowWriter(pkgs.ExportFormatXLSX, &buf)
+		require.NoError(t, err)
+		require.NoError(t, rw.Write([]string{"id", "name"}))
+		for range 3 {
+			require.NoError(t, rw.Write([]string{"1", "张三"}))
+			require.NoError(t, rw.Flush())
+		}
+		require.NoError(t, rw.Close())
+
+		rows := sheetRows(t, buf.Bytes())
+		require.Len(t, rows, 4)
+		assert.Equal(t, []string{"id", "name"}, rows[0])
+		assert.Equal(t, []string{"1", "张三"}, rows[3])
+	})
+
+	t.Run("XLSX 转义特殊字符", func(t *testing.T) {
+		var buf bytes.Buffer
+		rw, err := pkgs.NewRowWriter(pkgs.ExportFormatXLSX, &buf)
+		require.NoError(t, err)
+		require.NoError(t, rw.Write([]string{`<a & "b">`, "  前后空格  ", "=1+1", ""}))
+		require.NoError(t, rw.Close())
+
+		rows := sheetRows(t, buf.Bytes())
+		require.Len(t, rows, 1)
+		assert.Equal(t, []string{`<a & "b">`, "  前后空格  ", "=1+1", ""}, rows[0], "单元格为文本，公式不需要转义")
+	})
+}
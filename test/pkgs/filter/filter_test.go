@@ -0,0 +1,114 @@
+package filter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var columns = pkgs.FilterColumns{
+	"id":         {Expr: "id", Type: pkgs.FilterUUID},
+	"username":   {Expr: "username", Type: pkgs.FilterText},
+	"method":     {Expr: "metadata->>'method'", Type: pkgs.FilterText},
+	"num":        {Expr: "num", Type: pkgs.FilterInt},
+	"critical":   {Expr: "critical", Type: pkgs.FilterBool},
+	"created_at": {Expr: "created_at", Type: pkgs.FilterTime},
+}
+
+// newContext 返回时区为 loc 的请求上下文
+func newContext(loc *time.Location) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/list", nil)
+	c.Set(pkgs.TimeLocationContextKey, loc)
+	return c
+}
+
+// TestFilterColumns 测试列表接口的通用过滤表达式
+// 包含七个子测试：比较与模糊匹配、模糊匹配转义通配符、日期代表当天全天、in 与 null、字段使用白名单中的表达式、格式错误、字段与操作符校验
+func TestFilterColumns(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	t.Run("比较与模糊匹配", func(t *testing.T) {
+		expr := "username:like:foo,num:gte:10,critical:eq:true,created_at:lt:2025-01-01T08:00:00Z"
+		require.Empty(t, columns.Violations("filter", expr))
+
+		params := map[string]any{}
+		clauses := columns.Where(newContext(time.UTC), expr, params)
+		assert.Equal(t, []string{"username ILIKE :filter_0 ESCAPE '\\'", "num >= :filter_1", "critical = :filter_2", "created_at < :filter_3"}, clauses)
+		assert.Equal(t, "%foo%", params["filter_0"])
+		assert.Equal(t, int64(10), params["filter_1"])
+		assert.Equal(t, true, params["filter_2"])
+		assert.True(t, time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC).Equal(params["filter_3"].(time.Time)))
+	})
+
+	t.Run("模糊匹配转义通配符", func(t *testing.T) {
+		params := map[string]any{}
+		clauses := columns.Where(newContext(time.UTC), `username:like:50%_a\b`, params)
+		assert.Equal(t, []string{"username ILIKE :filter_0 ESCAPE '\\'"}, clauses)
+		assert.Equal(t, `%50\%\_a\\b%`, params["filter_0"])
+	})
+
+	t.Run("日期代表当天全天", func(t *testing.T) {
+		expr := "created_at:lte:2025-01-31,created_at:gt:2025-01-01,created_at:eq:2025-01-15"
+		require.Empty(t, columns.Violations("filter", expr))
+
+		params := map[string]any{}
+		clauses := columns.Where(newContext(shanghai), expr, params)
+		assert.Equal(t, []string{
+			"created_at < :filter_0_end",
+			"created_at >= :filter_1_end",
+			"(created_at >= :filter_2 AND created_at < :filter_2_end)",
+		}, clauses)
+		assert.True(t, time.Date(2025, 2, 1, 0, 0, 0, 0, shanghai).Equal(params["filter_0_end"].(time.Time)))
+		assert.True(t, time.Date(2025, 1, 2, 0, 0, 0, 0, shanghai).Equal(params["filter_1_end"].(time.Time)))
+	})
+
+	t.Run("in 与 null", func(t *testing.T) {
+		expr := "num:in:1|2|3,username:null:false,created_at:null:true"
+		require.Empty(t, columns.Violations("filter", expr))
+
+		params := map[string]any{}
+		clauses := columns.Where(newContext(time.UTC), expr, params)
+		assert.Equal(t, []string{"num IN (:filter_0_0, :filter_0_1, :filter_0_2)", "username IS NOT NULL", "created_at IS NULL"}, clauses)
+		assert.Equal(t, int64(3), params["filter_0_2"])
+	})
+
+	t.Run("字段使用白名单中的表达式", func(t *testing.T) {
+		params := map[string]any{}
+		clauses := columns.Where(newContext(time.UTC), "method:eq:GET", params)
+		assert.Equal(t, []string{"metadata->>'method' = :filter_0"}, clauses)
+		assert.Equal(t, "GET", params["filter_0"])
+	})
+
+	t.Run("格式错误", func(t *testing.T) {
+		for _, expr := range []string{"username", "username:like", "username:like:", ":eq:1", "a:eq:1,b:eq:2,c:eq:3,d:eq:4,e:eq:5,f:eq:6,g:eq:7,h:eq:8,i:eq:9,j:eq:10,k:eq:11"} {
+			violations := columns.Violations("filter", expr)
+			require.Len(t, violations, 1, expr)
+			assert.Equal(t, "filter", violations[0].Field)
+			assert.Empty(t, columns.Where(newContext(time.UTC), expr, map[string]any{}), "无效的表达式不生成条件")
+		}
+	})
+
+	t.Run("字段与操作符校验", func(t *testing.T) {
+		violations := columns.Violations("filter", "password:eq:x,num:like:1,critical:gt:true,num:eq:abc,id:eq:1,created_at:gte:yesterday,username:null:maybe,username:eq:ok")
+		require.Len(t, violations, 7)
+		for i, v := range violations {
+			assert.Equal(t, []int{i}, v.Indexes)
+		}
+		assert.Contains(t, violations[0].Message, "不支持筛选的字段 password")
+		assert.Contains(t, violations[1].Message, "不支持操作符 like")
+
+		// Where 忽略无效的条件
+		params := map[string]any{}
+		assert.Equal(t, []string{"username = :filter_7"}, columns.Where(newContext(time.UTC), "password:eq:x,num:like:1,critical:gt:true,num:eq:abc,id:eq:1,created_at:gte:yesterday,username:null:maybe,username:eq:ok", params))
+	})
+}
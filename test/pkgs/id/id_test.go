@@ -0,0 +1,53 @@
+package id_test
+
+import (
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIDGenerator 测试主键生成
+// 包含三个子测试：生成 UUIDv7 且按时间递增、数据库生成时不赋值、INSERT 列名与参数
+func TestIDGenerator(t *testing.T) {
+	t.Run("生成UUIDv7且按时间递增", func(t *testing.T) {
+		prev := ""
+		for i := 0; i < 100; i++ {
+			id, err := pkgs.NewID()
+			if !assert.NoError(t, err) {
+				return
+			}
+			parsed, err := uuid.Parse(id)
+			assert.NoError(t, err)
+			assert.Equal(t, uuid.Version(7), parsed.Version(), "应为 UUIDv7")
+			assert.Greater(t, id, prev, "后生成的主键应大于先生成的主键")
+			prev = id
+		}
+	})
+
+	t.Run("数据库生成时不赋值", func(t *testing.T) {
+		config := &pkgs.Config{Database: pkgs.DatabaseConfig{IDGeneration: pkgs.IDGenerationDatabase}}
+		g := pkgs.NewIDGenerator(config)
+		id := ""
+		assert.NoError(t, g.Assign(&id))
+		assert.Empty(t, id, "由列默认值生成，不应赋值")
+		columns, values := g.Insert("name", "num")
+		assert.Equal(t, "name, num", columns)
+		assert.Equal(t, ":name, :num", values)
+	})
+
+	t.Run("应用生成时包含id列", func(t *testing.T) {
+		config := &pkgs.Config{Database: pkgs.DatabaseConfig{IDGeneration: pkgs.IDGenerationApplication}}
+		g := pkgs.NewIDGenerator(config)
+		id := ""
+		assert.NoError(t, g.Assign(&id))
+		parsed, err := uuid.Parse(id)
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), parsed.Version())
+		columns, values := g.Insert("name", "num")
+		assert.Equal(t, "id, name, num", columns)
+		assert.Equal(t, ":id, :name, :num", values)
+	})
+}
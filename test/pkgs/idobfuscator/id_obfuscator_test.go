@@ -0,0 +1,86 @@
+package idobfuscator_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+func newObfuscator(t *testing.T, allowRaw bool) *pkgs.IDObfuscator {
+	t.Helper()
+	config := &pkgs.Config{}
+	config.IDObfuscation.Enabled = true
+	config.IDObfuscation.Key = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	config.IDObfuscation.AllowRaw = allowRaw
+	o, err := pkgs.NewIDObfuscator(config)
+	require.NoError(t, err)
+	return o
+}
+
+// TestIDObfuscator 测试对外ID的编码与解码
+// 包含五个子测试：往返转换、篡改的ID、原始 UUID、JSON 响应编码、未启用
+func TestIDObfuscator(t *testing.T) {
+	o := newObfuscator(t, false)
+	id := uuid.NewString()
+
+	t.Run("往返转换", func(t *testing.T) {
+		token := o.Encode(id)
+		assert.Len(t, token, 27)
+		assert.NotContains(t, token, "-"+id[:8])
+		assert.Equal(t, token, o.Encode(id), "同一ID的对外ID稳定")
+
+		decoded, ok := o.Decode(token)
+		assert.True(t, ok)
+		assert.Equal(t, id, decoded)
+	})
+
+	t.Run("篡改的ID", func(t *testing.T) {
+		token := []byte(o.Encode(id))
+		token[0] ^= 1
+		_, ok := o.Decode(string(token))
+		assert.False(t, ok)
+
+		_, ok = newObfuscator(t, false).Decode(o.Encode(id))
+		assert.True(t, ok, "相同密钥的实例可以解码")
+	})
+
+	t.Run("原始 UUID", func(t *testing.T) {
+		_, err := o.DecodeParam(id)
+		assert.ErrorIs(t, err, pkgs.ErrRawID)
+
+		value, err := newObfuscator(t, true).DecodeParam(id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, value)
+
+		value, err = o.DecodeParam(o.Encode(id) + "," + o.Encode(id))
+		assert.NoError(t, err)
+		assert.Equal(t, id+","+id, value)
+
+		body, err := o.DecodeJSON([]byte(`{"role_ids":["` + o.Encode(id) + `"],"name":"admin","num":1}`))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"role_ids":["`+id+`"],"name":"admin","num":1}`, string(body))
+	})
+
+	t.Run("JSON 响应编码", func(t *testing.T) {
+		body, err := o.EncodeJSON([]byte(`{"code":200,"msg":"角色不存在: ` + id + `","data":{"id":"` + id + `","total":12345678901234567890}}`))
+		assert.NoError(t, err)
+		token := o.Encode(id)
+		assert.JSONEq(t, `{"code":200,"msg":"角色不存在: `+token+`","data":{"id":"`+token+`","total":12345678901234567890}}`, string(body))
+	})
+
+	t.Run("未启用", func(t *testing.T) {
+		disabled, err := pkgs.NewIDObfuscator(&pkgs.Config{})
+		require.NoError(t, err)
+		assert.False(t, disabled.Enabled())
+		assert.Equal(t, id, disabled.Encode(id))
+		value, err := disabled.DecodeParam(id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, value)
+	})
+}
@@ -0,0 +1,25 @@
+package include_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestIncludes 测试 ?include= 扩展内容的解析与校验
+func TestIncludes(t *testing.T) {
+	include := pkgs.Includes(" permissions, ,userCount,permissions")
+	assert.Equal(t, []string{"permissions", "userCount"}, include.Names(), "去掉空白、空值与重复值")
+	assert.True(t, include.Has("userCount"))
+	assert.False(t, include.Has("roles"))
+	assert.Empty(t, include.Violations("permissions", "userCount"))
+
+	violations := pkgs.Includes("permissions,users").Violations("permissions", "userCount")
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, "include", violations[0].Field)
+		assert.Contains(t, violations[0].Message, "users")
+	}
+	assert.Empty(t, pkgs.Includes("").Names())
+}
@@ -0,0 +1,50 @@
+package indexadvisor_test
+
+import (
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSuggestIndexes 测试根据执行计划给出索引建议
+// 包含三个子测试：等值过滤建议 B-tree、模糊匹配建议 trigram、已走索引的计划不给建议
+func TestSuggestIndexes(t *testing.T) {
+	t.Run("等值过滤建议B-tree", func(t *testing.T) {
+		plan := `[{"Plan": {"Node Type": "Limit", "Plans": [
+			{"Node Type": "Sort", "Plans": [
+				{"Node Type": "Seq Scan", "Relation Name": "iacc_permission", "Filter": "((type)::text = $1)", "Plan Rows": 120}
+			]}
+		]}}]`
+		suggestions, err := pkgs.SuggestIndexes([]byte(plan))
+		assert.NoError(t, err)
+		if assert.Len(t, suggestions, 1) {
+			assert.Equal(t, "iacc_permission", suggestions[0].Table)
+			assert.Equal(t, []string{"type"}, suggestions[0].Columns)
+			assert.Equal(t, "CREATE INDEX ON iacc_permission (type);", suggestions[0].DDL)
+		}
+	})
+
+	t.Run("模糊匹配建议trigram", func(t *testing.T) {
+		plan := `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "iacc_user",
+			"Filter": "(((username)::text ~~* $1) AND (status = $2))", "Plan Rows": 5}}]`
+		suggestions, err := pkgs.SuggestIndexes([]byte(plan))
+		assert.NoError(t, err)
+		if assert.Len(t, suggestions, 1) {
+			assert.Equal(t, []string{"status", "username"}, suggestions[0].Columns)
+			assert.Contains(t, suggestions[0].DDL, "CREATE INDEX ON iacc_user (status);")
+			assert.Contains(t, suggestions[0].DDL, "CREATE INDEX ON iacc_user USING gin (username public.gin_trgm_ops);")
+		}
+	})
+
+	t.Run("已走索引不给建议", func(t *testing.T) {
+		plan := `[{"Plan": {"Node Type": "Index Scan", "Relation Name": "template", "Index Cond": "(id = $1)"}}]`
+		suggestions, err := pkgs.SuggestIndexes([]byte(plan))
+		assert.NoError(t, err)
+		assert.Empty(t, suggestions)
+
+		_, err = pkgs.SuggestIndexes([]byte("not json"))
+		assert.Error(t, err, "非法执行计划应返回错误")
+	})
+}
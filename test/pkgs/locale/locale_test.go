@@ -0,0 +1,42 @@
+package locale_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestLocalizer 测试请求语言的选择与消息翻译
+// 包含四个子测试：按 Accept-Language 选择语言、整条消息翻译、拼接的消息逐段翻译、配置校验
+func TestLocalizer(t *testing.T) {
+	localizer, err := pkgs.NewLocalizer(&pkgs.Config{Locale: pkgs.LocaleConfig{Default: pkgs.LocaleEn}})
+	require.NoError(t, err)
+
+	t.Run("按 Accept-Language 选择语言", func(t *testing.T) {
+		assert.Equal(t, pkgs.LocaleEn, localizer.Resolve(""), "未指定时使用默认语言")
+		assert.Equal(t, pkgs.LocaleEn, localizer.Resolve("fr-FR, *"))
+		assert.Equal(t, pkgs.LocaleZh, localizer.Resolve("ja;q=0.9, zh-Hans-CN, en"), "按出现顺序匹配主语言")
+		assert.Equal(t, pkgs.LocaleEn, pkgs.RequestLocale(nil), "没有请求时使用默认语言")
+	})
+
+	t.Run("整条消息翻译", func(t *testing.T) {
+		assert.Equal(t, "Role does not exist", localizer.Localize(pkgs.LocaleEn, "角色不存在"))
+		assert.Equal(t, "角色不存在", localizer.Localize(pkgs.LocaleZh, "角色不存在"), "中文为原文")
+		assert.Equal(t, "没有译文的消息", localizer.Localize(pkgs.LocaleEn, "没有译文的消息"))
+	})
+
+	t.Run("拼接的消息逐段翻译", func(t *testing.T) {
+		assert.Equal(t, "Failed to create user: already exists", localizer.Localize(pkgs.LocaleEn, "创建用户失败：数据已存在"))
+		assert.Equal(t, "导出快照失败: request timed out", localizer.Localize(pkgs.LocaleEn, "导出快照失败：请求超时"), "没有译文的片段保留原文")
+		assert.Equal(t, "a; b", localizer.Join(pkgs.LocaleEn, []string{"a", "b"}))
+		assert.Equal(t, "a；b", localizer.Join(pkgs.LocaleZh, []string{"a", "b"}))
+	})
+
+	t.Run("配置校验", func(t *testing.T) {
+		_, err := pkgs.NewLocalizer(&pkgs.Config{Locale: pkgs.LocaleConfig{Default: "fr"}})
+		assert.Error(t, err)
+	})
+}
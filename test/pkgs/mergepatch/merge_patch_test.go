@@ -0,0 +1,39 @@
+package mergepatch_test
+
+import (
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestApplyMergePatch 使用 RFC 7386 附录中的示例测试合并规则
+func TestApplyMergePatch(t *testing.T) {
+	cases := []struct {
+		target, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{``, `{"a":"b"}`, `{"a":"b"}`},
+	}
+	for _, tc := range cases {
+		got, err := pkgs.ApplyMergePatch([]byte(tc.target), []byte(tc.patch))
+		if assert.NoError(t, err, "合并不应出错: %s + %s", tc.target, tc.patch) {
+			assert.JSONEq(t, tc.expected, string(got), "合并结果不符合 RFC 7386: %s + %s", tc.target, tc.patch)
+		}
+	}
+}
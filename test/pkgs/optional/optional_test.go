@@ -0,0 +1,66 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type updateReq struct {
+	Description pkgs.Optional[string]                `json:"description,omitzero" validate:"omitempty,max=5" label:"描述"`
+	Conditions  pkgs.Optional[pkgs.AccessConditions] `json:"conditions,omitzero" validate:"omitempty" label:"访问条件"`
+}
+
+// TestOptional 测试可清空字段的三种状态
+// 包含三个子测试：区分缺省、null 与传值，序列化往返，按传入的值校验
+func TestOptional(t *testing.T) {
+	t.Run("区分缺省、null 与传值", func(t *testing.T) {
+		var absent, null, value updateReq
+		require.NoError(t, json.Unmarshal([]byte(`{}`), &absent))
+		require.NoError(t, json.Unmarshal([]byte(`{"description":null}`), &null))
+		require.NoError(t, json.Unmarshal([]byte(`{"description":"abc"}`), &value))
+
+		assert.False(t, absent.Description.IsSet())
+		assert.Nil(t, absent.Description.Ptr())
+
+		assert.True(t, null.Description.IsSet())
+		assert.True(t, null.Description.IsNull())
+		assert.Nil(t, null.Description.Ptr())
+
+		assert.True(t, value.Description.IsSet())
+		assert.False(t, value.Description.IsNull())
+		got, ok := value.Description.Get()
+		assert.True(t, ok)
+		assert.Equal(t, "abc", got)
+	})
+
+	t.Run("序列化往返", func(t *testing.T) {
+		for _, doc := range []string{`{}`, `{"description":null}`, `{"description":"abc"}`} {
+			var req updateReq
+			require.NoError(t, json.Unmarshal([]byte(doc), &req))
+			out, err := json.Marshal(req)
+			require.NoError(t, err)
+			assert.JSONEq(t, doc, string(out))
+		}
+	})
+
+	t.Run("按传入的值校验", func(t *testing.T) {
+		v := pkgs.NewRequestValidator()
+		validate := func(req updateReq) bool {
+			return pkgs.ValidateV2[updateReq](v)(&req).IsOk()
+		}
+
+		assert.True(t, validate(updateReq{}), "缺省不校验")
+		assert.True(t, validate(updateReq{Description: pkgs.Null[string]()}), "null 不校验")
+		assert.True(t, validate(updateReq{Description: pkgs.Some("abc")}))
+		assert.False(t, validate(updateReq{Description: pkgs.Some("abcdef")}), "超过最大长度")
+
+		invalid := pkgs.AccessConditions{CIDRs: []string{"not-a-cidr"}}
+		assert.False(t, validate(updateReq{Conditions: pkgs.Some(invalid)}), "结构体按字段标签校验")
+		assert.True(t, validate(updateReq{Conditions: pkgs.Null[pkgs.AccessConditions]()}))
+	})
+}
@@ -0,0 +1,139 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// newHasher 按算法创建参数最低的密码哈希器，加快测试
+func newHasher(t *testing.T, algorithm string) *pkgs.PasswordHasher {
+	t.Helper()
+	h, err := pkgs.NewPasswordHasher(&pkgs.Config{PasswordHash: pkgs.PasswordHashConfig{
+		Algorithm:     algorithm,
+		BcryptCost:    4,
+		Argon2Time:    1,
+		Argon2Memory:  64,
+		Argon2Threads: 1,
+	}})
+	require.NoError(t, err)
+	return h
+}
+
+// TestPasswordHasher 测试密码哈希与校验
+// 包含六个子测试：bcrypt、argon2id、更换算法后旧哈希仍可校验、无法识别的哈希、旧系统密码格式、配置校验
+func TestPasswordHasher(t *testing.T) {
+	t.Run("bcrypt", func(t *testing.T) {
+		h := newHasher(t, pkgs.PasswordAlgorithmBcrypt)
+		hash, err := h.Hash("secret")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$2a$04$"), hash)
+		assert.True(t, h.Verify(hash, "secret"))
+		assert.False(t, h.Verify(hash, "Secret"))
+		assert.False(t, h.NeedsRehash(hash))
+
+		_, err = h.Hash(strings.Repeat("x", 73))
+		assert.ErrorIs(t, err, pkgs.ErrPasswordTooLong)
+	})
+
+	t.Run("argon2id", func(t *testing.T) {
+		h := newHasher(t, pkgs.PasswordAlgorithmArgon2id)
+		hash, err := h.Hash("secret")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"), hash)
+		assert.True(t, h.Verify(hash, "secret"))
+		assert.False(t, h.Verify(hash, "secret "))
+		assert.False(t, h.NeedsRehash(hash))
+
+		other, err := h.Hash("secret")
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, other, "每次使用不同的盐")
+	})
+
+	t.Run("更换算法后旧哈希仍可校验", func(t *testing.T) {
+		bcryptHash, err := newHasher(t, pkgs.PasswordAlgorithmBcrypt).Hash("secret")
+		require.NoError(t, err)
+		argonHash, err := newHasher(t, pkgs.PasswordAlgorithmArgon2id).Hash("secret")
+		require.NoError(t, err)
+
+		h := newHasher(t, pkgs.PasswordAlgorithmArgon2id)
+		assert.True(t, h.Verify(bcryptHash, "secret"))
+		assert.True(t, h.NeedsRehash(bcryptHash), "算法不同时需要重新哈希")
+
+		h = newHasher(t, pkgs.PasswordAlgorithmBcrypt)
+		assert.True(t, h.Verify(argonHash, "secret"))
+		assert.True(t, h.NeedsRehash(argonHash))
+
+		stronger, err := pkgs.NewPasswordHasher(&pkgs.Config{PasswordHash: pkgs.PasswordHashConfig{Algorithm: pkgs.PasswordAlgorithmBcrypt, BcryptCost: 5}})
+		require.NoError(t, err)
+		assert.True(t, stronger.NeedsRehash(bcryptHash), "成本不同时需要重新哈希")
+	})
+
+	t.Run("无法识别的哈希", func(t *testing.T) {
+		h := newHasher(t, pkgs.PasswordAlgorithmBcrypt)
+		assert.False(t, h.Verify("secret", "secret"), "明文不视为哈希")
+		assert.False(t, h.Verify("", ""))
+		assert.False(t, h.Verify("$argon2id$v=19$m=64,t=1,p=1$bad", "secret"))
+		assert.True(t, h.NeedsRehash("secret"))
+	})
+
+	t.Run("旧系统密码格式", func(t *testing.T) {
+		plain := "$plain$secret"
+		md5 := "$md5$5ebe2294ecd0e0f08eab7690d2a6ee69"
+		salted := "$md5$x9$c4e0116b9f257a61c92fa4751ce45077"
+
+		h := newHasher(t, pkgs.PasswordAlgorithmBcrypt)
+		assert.False(t, h.Verify(plain, "secret"), "未配置时不接受旧格式")
+		assert.False(t, h.Verify(md5, "secret"))
+
+		h, err := pkgs.NewPasswordHasher(&pkgs.Config{PasswordHash: pkgs.PasswordHashConfig{
+			Algorithm:     pkgs.PasswordAlgorithmBcrypt,
+			BcryptCost:    4,
+			LegacyFormats: []string{pkgs.PasswordLegacyPlain, pkgs.PasswordLegacyMD5},
+		}})
+		require.NoError(t, err)
+		assert.True(t, h.Verify(plain, "secret"))
+		assert.False(t, h.Verify(plain, "secret2"))
+		assert.False(t, h.Verify("$plain$", ""), "空明文不能登录")
+		assert.True(t, h.Verify(md5, "secret"))
+		assert.False(t, h.Verify(md5, "Secret"))
+		assert.True(t, h.Verify(salted, "secret"), "加盐的 MD5")
+		assert.False(t, h.Verify("$md5$x9$5ebe2294ecd0e0f08eab7690d2a6ee69", "secret"), "盐不参与计算时不匹配")
+		assert.False(t, h.Verify("$md5$not-hex", "secret"))
+		assert.True(t, h.NeedsRehash(plain), "旧格式总是需要重新哈希")
+		assert.True(t, h.NeedsRehash(salted))
+
+		assert.Equal(t, pkgs.PasswordLegacyMD5, pkgs.PasswordHashScheme(salted))
+		assert.Equal(t, pkgs.PasswordLegacyPlain, pkgs.PasswordHashScheme(plain))
+		assert.Equal(t, pkgs.PasswordSchemeUnknown, pkgs.PasswordHashScheme("secret"))
+	})
+
+	t.Run("配置校验", func(t *testing.T) {
+		for _, c := range []pkgs.PasswordHashConfig{
+			{Algorithm: "md5"},
+			{Algorithm: pkgs.PasswordAlgorithmBcrypt, BcryptCost: 4, LegacyFormats: []string{"sha1"}},
+			{Algorithm: pkgs.PasswordAlgorithmBcrypt, BcryptCost: 3},
+			{Algorithm: pkgs.PasswordAlgorithmArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 0},
+			{Algorithm: pkgs.PasswordAlgorithmArgon2id, Argon2Time: 1, Argon2Memory: 4, Argon2Threads: 1},
+		} {
+			assert.Error(t, c.Validate(), "%+v", c)
+		}
+	})
+}
+
+// TestPasswordPolicyMaxBytes 测试使用 bcrypt 时密码策略拒绝超过 72 字节的密码，多字节字符按字节计算
+func TestPasswordPolicyMaxBytes(t *testing.T) {
+	policy := pkgs.PasswordPolicy{MinLength: 4}
+	// 25 个汉字为 75 字节
+	long := strings.Repeat("密", 25)
+
+	apiErr := policy.Check(long, newHasher(t, pkgs.PasswordAlgorithmBcrypt))
+	require.NotNil(t, apiErr)
+	assert.Equal(t, 400, apiErr.Code)
+	assert.Nil(t, policy.Check(strings.Repeat("x", 72), newHasher(t, pkgs.PasswordAlgorithmBcrypt)))
+	assert.Nil(t, policy.Check(long, newHasher(t, pkgs.PasswordAlgorithmArgon2id)), "argon2id 不限制长度")
+}
@@ -0,0 +1,189 @@
+package permissioncache_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newCache 创建连接到 miniredis 的权限缓存，熔断阈值为 2、冷却时间为 50ms
+func newCache(t *testing.T, mr *miniredis.Miniredis) *pkgs.PermissionCache {
+	config := &pkgs.Config{PermissionCache: pkgs.PermissionCacheConfig{
+		TTL:              time.Minute,
+		Timeout:          50 * time.Millisecond,
+		FailureThreshold: 2,
+		Cooldown:         50 * time.Millisecond,
+	}}
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return pkgs.NewPermissionCache(config, client, zap.NewNop())
+}
+
+func newContext() *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/user/list", nil)
+	return c
+}
+
+// TestPermissionCache 测试权限缓存与 Redis 不可用时的降级
+// 包含五个子测试：命中缓存、失效后重新加载、Redis 宕机降级查库、熔断后不再访问 Redis、恢复后关闭熔断
+func TestPermissionCache(t *testing.T) {
+	perms := []pkgs.APIPermission{{Method: "GET", Path: "/v1/user/list"}}
+
+	t.Run("命中缓存", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		cache := newCache(t, mr)
+		c := newContext()
+
+		loads := 0
+		load := func() ([]pkgs.APIPermission, error) { loads++; return perms, nil }
+		for i := 0; i < 3; i++ {
+			got, err := cache.Load(c, "user-1", load)
+			assert.NoError(t, err)
+			assert.Equal(t, perms, got)
+		}
+		assert.Equal(t, 1, loads, "只有第一次应查询数据库")
+	})
+
+	t.Run("失效后重新加载", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		cache := newCache(t, mr)
+		c := newContext()
+
+		loads := 0
+		load := func() ([]pkgs.APIPermission, error) { loads++; return perms, nil }
+		_, _ = cache.Load(c, "user-1", load)
+		cache.Invalidate(c)
+		_, _ = cache.Load(c, "user-1", load)
+		assert.Equal(t, 2, loads, "失效后应重新查询数据库")
+	})
+
+	t.Run("Redis宕机降级查库", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		cache := newCache(t, mr)
+		c := newContext()
+		mr.Close()
+
+		loads := 0
+		got, err := cache.Load(c, "user-1", func() ([]pkgs.APIPermission, error) { loads++; return perms, nil })
+		assert.NoError(t, err, "Redis 不可用时请求不应失败")
+		assert.Equal(t, perms, got)
+		assert.Equal(t, 1, loads)
+
+		// 数据库出错时才返回错误
+		_, err = cache.Load(c, "user-1", func() ([]pkgs.APIPermission, error) { return nil, errors.New("db down") })
+		assert.Error(t, err)
+	})
+
+	t.Run("熔断后不再访问Redis", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		cache := newCache(t, mr)
+		c := newContext()
+		load := func() ([]pkgs.APIPermission, error) { return perms, nil }
+
+		mr.SetError("connection lost")
+		for i := 0; i < 2; i++ {
+			_, err := cache.Load(c, "user-1", load)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, pkgs.CircuitOpen, cache.BreakerState(), "连续失败达到阈值后应熔断")
+
+		commands := mr.CommandCount()
+		_, err := cache.Load(c, "user-1", load)
+		assert.NoError(t, err)
+		assert.Equal(t, commands, mr.CommandCount(), "熔断期间不应访问 Redis")
+	})
+
+	t.Run("恢复后关闭熔断", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		cache := newCache(t, mr)
+		c := newContext()
+		load := func() ([]pkgs.APIPermission, error) { return perms, nil }
+
+		mr.SetError("connection lost")
+		for i := 0; i < 2; i++ {
+			_, _ = cache.Load(c, "user-1", load)
+		}
+		assert.Equal(t, pkgs.CircuitOpen, cache.BreakerState())
+
+		mr.SetError("")
+		time.Sleep(60 * time.Millisecond)
+		_, err := cache.Load(c, "user-1", load)
+		assert.NoError(t, err)
+		assert.Equal(t, pkgs.CircuitClosed, cache.BreakerState(), "冷却后探测成功应关闭熔断")
+	})
+}
+
+// TestPermissionCacheStatus 测试权限缓存的状态与重建
+// 包含三个子测试：命中统计与缓存条目、重建后旧条目计为已失效、其他实例重建后调用失效钩子
+func TestPermissionCacheStatus(t *testing.T) {
+	perms := []pkgs.APIPermission{{Method: "GET", Path: "/v1/user/list"}}
+	load := func() ([]pkgs.APIPermission, error) { return perms, nil }
+
+	t.Run("命中统计与缓存条目", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		cache := newCache(t, mr)
+		c := newContext()
+
+		for _, userID := range []string{"user-1", "user-1", "user-1", "user-2"} {
+			_, err := cache.Load(c, userID, load)
+			assert.NoError(t, err)
+		}
+		status, err := cache.Status(c.Request.Context(), "")
+		assert.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, pkgs.CircuitClosed, status.Circuit)
+		assert.Equal(t, int64(2), status.Hits)
+		assert.Equal(t, int64(2), status.Misses)
+		assert.InDelta(t, 0.5, status.HitRate(), 1e-9)
+		assert.Equal(t, int64(2), status.Entries)
+		assert.Zero(t, status.StaleEntries)
+		assert.True(t, status.InvalidatedAt.IsZero(), "从未失效")
+	})
+
+	t.Run("重建后旧条目计为已失效", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		cache := newCache(t, mr)
+		c := newContext()
+
+		_, _ = cache.Load(c, "user-1", load)
+		generation, err := cache.Rebuild(c.Request.Context(), "")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), generation)
+		_, _ = cache.Load(c, "user-2", load)
+
+		status, err := cache.Status(c.Request.Context(), "")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), status.Generation)
+		assert.False(t, status.InvalidatedAt.IsZero(), "记录失效时间")
+		assert.Equal(t, int64(1), status.Entries)
+		assert.Equal(t, int64(1), status.StaleEntries)
+	})
+
+	t.Run("其他实例重建后调用失效钩子", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		local, other := newCache(t, mr), newCache(t, mr)
+		c := newContext()
+		invalidated := 0
+		local.OnInvalidate(func(string) { invalidated++ })
+
+		_, _ = local.Load(c, "user-1", load)
+		assert.Zero(t, invalidated, "首次读取不调用钩子")
+
+		_, err := other.Rebuild(c.Request.Context(), "")
+		assert.NoError(t, err)
+		_, _ = local.Load(c, "user-1", load)
+		_, _ = local.Load(c, "user-1", load)
+		assert.Equal(t, 1, invalidated, "发现版本号变化时调用一次钩子")
+	})
+}
@@ -0,0 +1,69 @@
+package permissioneval_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestMatchPermissionCode 测试权限编码的通配匹配
+func TestMatchPermissionCode(t *testing.T) {
+	assert.True(t, pkgs.MatchPermissionCode("user:delete", "user:delete"))
+	assert.True(t, pkgs.MatchPermissionCode("user:*", "user:delete"))
+	assert.True(t, pkgs.MatchPermissionCode("*", "template:manage_all"))
+	assert.False(t, pkgs.MatchPermissionCode("user:*", "users:delete"), "通配只匹配完整的前缀段")
+	assert.False(t, pkgs.MatchPermissionCode("user*", "user:delete"), "通配必须跟在冒号之后")
+	assert.False(t, pkgs.MatchPermissionCode("user:view_pii", "user:delete"))
+}
+
+// TestPermissionAllowed 测试拒绝优先的求值
+// 包含三个子测试：通配授予下拒绝单个编码、未授予时拒绝、拒绝规则顺序不影响结果
+func TestPermissionAllowed(t *testing.T) {
+	byCode := func(code string) func(pkgs.APIPermission) bool {
+		return func(p pkgs.APIPermission) bool { return p.Code != "" && pkgs.MatchPermissionCode(p.Code, code) }
+	}
+	perms := []pkgs.APIPermission{
+		{Code: "user:*"},
+		{Code: "user:delete", Deny: true},
+		{Method: "GET", Path: "/v1/user/list"},
+	}
+
+	t.Run("通配授予下拒绝单个编码", func(t *testing.T) {
+		assert.True(t, pkgs.PermissionAllowed(perms, byCode("user:view_pii")))
+		assert.False(t, pkgs.PermissionAllowed(perms, byCode("user:delete")))
+	})
+
+	t.Run("未授予时拒绝", func(t *testing.T) {
+		assert.False(t, pkgs.PermissionAllowed(perms, byCode("role:delete")))
+		assert.False(t, pkgs.PermissionAllowed(nil, byCode("user:view_pii")))
+	})
+
+	t.Run("拒绝规则顺序不影响结果", func(t *testing.T) {
+		reversed := []pkgs.APIPermission{{Code: "user:delete", Deny: true}, {Code: "user:*"}}
+		assert.False(t, pkgs.PermissionAllowed(reversed, byCode("user:delete")))
+	})
+}
+
+// TestPermissionNamespace 测试权限命名空间的格式校验与默认值推断
+// 包含两个子测试：格式校验、默认值推断
+func TestPermissionNamespace(t *testing.T) {
+	t.Run("格式校验", func(t *testing.T) {
+		assert.True(t, pkgs.ValidPermissionNamespace("user"))
+		assert.True(t, pkgs.ValidPermissionNamespace("report:export"))
+		assert.True(t, pkgs.ValidPermissionNamespace("view_pii-2"))
+		assert.False(t, pkgs.ValidPermissionNamespace(""))
+		assert.False(t, pkgs.ValidPermissionNamespace("report:"), "不能有空段")
+		assert.False(t, pkgs.ValidPermissionNamespace("user:*"), "命名空间本身不含通配")
+		assert.False(t, pkgs.ValidPermissionNamespace("report export"))
+	})
+
+	t.Run("默认值推断", func(t *testing.T) {
+		assert.Equal(t, "report:export", pkgs.DefaultPermissionNamespace("report:export:csv", ""))
+		assert.Equal(t, "user", pkgs.DefaultPermissionNamespace("user:*", ""), "通配编码属于其前缀")
+		assert.Equal(t, "user", pkgs.DefaultPermissionNamespace("", "/v1/user/:id/role"))
+		assert.Equal(t, "template", pkgs.DefaultPermissionNamespace("manage", "/v2/template/list"), "编码没有分段时按路径推断")
+		assert.Empty(t, pkgs.DefaultPermissionNamespace("manage", "/health"))
+	})
+}
@@ -0,0 +1,153 @@
+package permissionmatcher_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestPermissionRoutesMatch 测试编译后的接口权限记录匹配
+// 包含四个子测试：静态路径、路径参数、末尾斜杠、method 与段数不同
+func TestPermissionRoutesMatch(t *testing.T) {
+	routes := pkgs.CompilePermissionRoutes([]pkgs.APIPermission{
+		{Method: "GET", Path: "/v1/user/list"},
+		{Method: "GET", Path: "/v1/user/:id"},
+		{Method: "POST", Path: "/v1/template/:id/archive"},
+		{Method: "GET", Path: "/v1/files/*path"},
+		{Code: "user:view_pii"},
+	})
+
+	t.Run("静态路径", func(t *testing.T) {
+		template, ok := routes.Match("GET", "/v1/user/list")
+		assert.True(t, ok)
+		assert.Equal(t, "/v1/user/list", template)
+	})
+
+	t.Run("路径参数", func(t *testing.T) {
+		template, ok := routes.Match("GET", "/v1/user/550e8400-e29b-41d4-a716-446655440000")
+		assert.True(t, ok)
+		assert.Equal(t, "/v1/user/:id", template)
+
+		template, ok = routes.Match("POST", "/v1/template/abc/archive")
+		assert.True(t, ok)
+		assert.Equal(t, "/v1/template/:id/archive", template)
+
+		template, ok = routes.Match("GET", "/v1/files/a/b/c.txt")
+		assert.True(t, ok)
+		assert.Equal(t, "/v1/files/*path", template)
+	})
+
+	t.Run("末尾斜杠", func(t *testing.T) {
+		template, _ := routes.Match("GET", "/v1/user/list/")
+		assert.Equal(t, "/v1/user/list", template, "末尾的斜杠不影响匹配")
+	})
+
+	t.Run("method 与段数不同", func(t *testing.T) {
+		_, ok := routes.Match("DELETE", "/v1/user/list")
+		assert.False(t, ok)
+		_, ok = routes.Match("GET", "/v1/user/1/roles")
+		assert.False(t, ok)
+		_, ok = routes.Match("POST", "/v1/template/abc/restore")
+		assert.False(t, ok)
+		_, ok = routes.Match("GET", "/v1")
+		assert.False(t, ok)
+	})
+}
+
+// BenchmarkPermissionRoutesMatch 测试 1 万条权限记录下判断接口是否纳入权限体系的耗时
+// 目标：单次匹配远低于 100µs，且不随记录数增长
+func BenchmarkPermissionRoutesMatch(b *testing.B) {
+	for _, count := range []int{100, 10000} {
+		perms := make([]pkgs.APIPermission, 0, count)
+		for i := range count / 4 {
+			perms = append(perms,
+				pkgs.APIPermission{Method: "GET", Path: fmt.Sprintf("/v1/module%d/list", i)},
+				pkgs.APIPermission{Method: "GET", Path: fmt.Sprintf("/v1/module%d/:id", i)},
+				pkgs.APIPermission{Method: "PUT", Path: fmt.Sprintf("/v1/module%d/:id", i)},
+				pkgs.APIPermission{Method: "POST", Path: fmt.Sprintf("/v1/module%d/:id/items/:itemId", i)},
+			)
+		}
+		routes := pkgs.CompilePermissionRoutes(perms)
+		path := fmt.Sprintf("/v1/module%d/550e8400-e29b-41d4-a716-446655440000/items/42", count/4-1)
+
+		b.Run(fmt.Sprintf("rows=%d", count), func(b *testing.B) {
+			for b.Loop() {
+				if _, ok := routes.Match("POST", path); !ok {
+					b.Fatal("expected match")
+				}
+			}
+		})
+	}
+}
+
+// TestMatchPermissionPath 测试用户权限记录与请求路径的匹配
+// 包含三个子测试：路径参数、通配段、与编译结果一致
+func TestMatchPermissionPath(t *testing.T) {
+	t.Run("路径参数", func(t *testing.T) {
+		assert.True(t, pkgs.MatchPermissionPath("/v1/user/:id", "/v1/user/42"))
+		assert.True(t, pkgs.MatchPermissionPath("/v1/user/list", "/v1/user/list/"))
+		assert.False(t, pkgs.MatchPermissionPath("/v1/user/:id", "/v1/user/42/roles"))
+		assert.False(t, pkgs.MatchPermissionPath("/v1/user/:id", "/v1/role/42"))
+	})
+
+	t.Run("通配段", func(t *testing.T) {
+		assert.True(t, pkgs.MatchPermissionPath("/v1/files/*path", "/v1/files/a.txt"))
+		assert.True(t, pkgs.MatchPermissionPath("/v1/files/*path", "/v1/files/a/b/c.txt"))
+		assert.True(t, pkgs.MatchPermissionPath("/v1/:bucket/*path", "/v1/docs/a/b"))
+		assert.False(t, pkgs.MatchPermissionPath("/v1/files/*path", "/v1/files"), "通配段至少匹配一段")
+		assert.False(t, pkgs.MatchPermissionPath("/v1/files/*path", "/v1/images/a.txt"))
+	})
+
+	t.Run("与编译结果一致", func(t *testing.T) {
+		perms := []pkgs.APIPermission{
+			{Method: "GET", Path: "/v1/user/:id"},
+			{Method: "GET", Path: "/v1/files/*path"},
+			{Method: "GET", Path: "/v1/:bucket/*path"},
+		}
+		paths := []string{"/v1/user/42", "/v1/user/42/roles", "/v1/files", "/v1/files/a/b", "/v1/docs/a", "/v1/docs", "/v1"}
+		for _, perm := range perms {
+			routes := pkgs.CompilePermissionRoutes([]pkgs.APIPermission{perm})
+			for _, path := range paths {
+				_, guarded := routes.Match("GET", path)
+				assert.Equal(t, guarded, pkgs.MatchPermissionPath(perm.Path, path), "%s 与 %s", perm.Path, path)
+			}
+		}
+	})
+}
+
+// BenchmarkPermissionMiddlewarePath 测试 PermissionMiddleware 在内存中的判断耗时（不含读取用户权限集合）：
+// 先用编译结果判断接口是否纳入权限体系，再按拒绝优先规则在用户的权限集合中匹配
+func BenchmarkPermissionMiddlewarePath(b *testing.B) {
+	for _, count := range []int{100, 10000} {
+		perms := make([]pkgs.APIPermission, 0, count)
+		for i := range count / 4 {
+			perms = append(perms,
+				pkgs.APIPermission{Method: "GET", Path: fmt.Sprintf("/v1/module%d/list", i)},
+				pkgs.APIPermission{Method: "GET", Path: fmt.Sprintf("/v1/module%d/:id", i)},
+				pkgs.APIPermission{Method: "PUT", Path: fmt.Sprintf("/v1/module%d/:id", i)},
+				pkgs.APIPermission{Method: "GET", Path: fmt.Sprintf("/v1/module%d/files/*path", i)},
+			)
+		}
+		routes := pkgs.CompilePermissionRoutes(perms)
+		// 用户通过角色获得的权限集合通常远小于权限表
+		granted := perms[len(perms)-min(len(perms), 200):]
+		method, path := "GET", fmt.Sprintf("/v1/module%d/files/a/b.txt", count/4-1)
+
+		b.Run(fmt.Sprintf("rows=%d", count), func(b *testing.B) {
+			for b.Loop() {
+				if _, guarded := routes.Match(method, path); !guarded {
+					b.Fatal("expected guarded")
+				}
+				allowed := pkgs.PermissionAllowed(granted, func(p pkgs.APIPermission) bool {
+					return p.Method == method && pkgs.MatchPermissionPath(p.Path, path)
+				})
+				if !allowed {
+					b.Fatal("expected allowed")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package pgarray_test
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+type scope string
+
+type level int32
+
+// TestPGArray 测试切片绑定为 PostgreSQL 数组参数
+// 包含四个子测试：字符串与转义、数值与布尔、自定义类型、空切片与命名参数
+func TestPGArray(t *testing.T) {
+	t.Run("字符串与转义", func(t *testing.T) {
+		v, err := pkgs.PGArray([]string{"a", `b,"c"`, ""}).Value()
+		require.NoError(t, err)
+		assert.Equal(t, `{"a","b,\"c\"",""}`, v)
+	})
+
+	t.Run("数值与布尔", func(t *testing.T) {
+		v, err := pkgs.PGArray([]int{1, -2, 3}).Value()
+		require.NoError(t, err)
+		assert.Equal(t, "{1,-2,3}", v)
+
+		v, err = pkgs.PGArray([]float64{1.5, 2}).Value()
+		require.NoError(t, err)
+		assert.Equal(t, "{1.5,2}", v)
+
+		v, err = pkgs.PGArray([]bool{true, false}).Value()
+		require.NoError(t, err)
+		assert.Equal(t, "{t,f}", v)
+	})
+
+	t.Run("自定义类型", func(t *testing.T) {
+		v, err := pkgs.PGArray([]scope{"GET /v1/user", "POST /v1/user"}).Value()
+		require.NoError(t, err)
+		assert.Equal(t, `{"GET /v1/user","POST /v1/user"}`, v)
+
+		v, err = pkgs.PGArray([]level{3, 4}).Value()
+		require.NoError(t, err)
+		assert.Equal(t, "{3,4}", v)
+	})
+
+	t.Run("空切片与命名参数", func(t *testing.T) {
+		v, err := pkgs.PGArray([]string(nil)).Value()
+		require.NoError(t, err)
+		assert.Equal(t, "{}", v, "空切片绑定为空数组而不是 NULL")
+
+		// 长列表仍然只有一个参数
+		ids := make([]string, 70000)
+		query, args, err := sqlx.Named(`DELETE FROM t WHERE id = ANY(:ids)`, map[string]any{"ids": pkgs.PGArray(ids)})
+		require.NoError(t, err)
+		assert.Equal(t, `DELETE FROM t WHERE id = ANY(?)`, query)
+		assert.Len(t, args, 1)
+	})
+}
@@ -0,0 +1,77 @@
+package pgerror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestDBError 测试数据库错误转换
+// 包含七个子测试：唯一约束、外键引用不存在、外键仍被引用、检查约束、格式无效、请求取消与超时、其他错误
+func TestDBError(t *testing.T) {
+	logger := zap.NewNop()
+
+	t.Run("唯一约束", func(t *testing.T) {
+		err := &pq.Error{Code: pkgs.PgUniqueViolation, Constraint: "iacc_user_role_pkey", Detail: "Key (user_id, role_id)=(a, b) already exists."}
+		apiErr := pkgs.DBError(logger, fmt.Errorf("包装: %w", err), "分配角色失败")
+		assert.Equal(t, http.StatusConflict, apiErr.Code)
+		assert.Equal(t, "分配角色失败：数据已存在", apiErr.Message)
+		assert.Equal(t, []pkgs.Violation{{Field: "user_id", Message: "该值已存在"}, {Field: "role_id", Message: "该值已存在"}}, apiErr.Data)
+		assert.NotContains(t, apiErr.Message, "(a, b)", "消息不应包含字段取值")
+	})
+
+	t.Run("外键引用不存在", func(t *testing.T) {
+		err := &pq.Error{Code: pkgs.PgForeignKeyViolation, Detail: `Key (role_id)=(x) is not present in table "iacc_role".`}
+		apiErr := pkgs.DBError(logger, err, "分配角色失败")
+		assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+		assert.Equal(t, []pkgs.Violation{{Field: "role_id", Message: "引用的数据不存在"}}, apiErr.Data)
+	})
+
+	t.Run("外键仍被引用", func(t *testing.T) {
+		err := &pq.Error{Code: pkgs.PgForeignKeyViolation, Detail: `Key (id)=(x) is still referenced from table "iacc_user_role".`}
+		apiErr := pkgs.DBError(logger, err, "删除角色失败")
+		assert.Equal(t, http.StatusConflict, apiErr.Code)
+	})
+
+	t.Run("检查约束", func(t *testing.T) {
+		err := &pq.Error{Code: pkgs.PgCheckViolation, Constraint: "chk_iacc_client_ttl"}
+		apiErr := pkgs.DBError(logger, err, "创建客户端失败")
+		assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+		assert.Equal(t, []pkgs.Violation{{Field: "chk_iacc_client_ttl", Message: "数据不满足约束 chk_iacc_client_ttl"}}, apiErr.Data)
+	})
+
+	t.Run("格式无效", func(t *testing.T) {
+		err := &pq.Error{Code: pkgs.PgInvalidTextRepr, Message: `invalid input syntax for type uuid: "x"`}
+		apiErr := pkgs.DBError(logger, err, "获取角色失败")
+		assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+		assert.Equal(t, "获取角色失败：参数格式无效", apiErr.Message)
+	})
+
+	t.Run("请求取消与超时", func(t *testing.T) {
+		apiErr := pkgs.DBError(logger, fmt.Errorf("查询: %w", context.Canceled), "查询角色列表失败")
+		assert.Equal(t, pkgs.StatusClientClosedRequest, apiErr.Code, "客户端断开应返回 499")
+
+		apiErr = pkgs.DBError(logger, context.DeadlineExceeded, "查询角色列表失败")
+		assert.Equal(t, http.StatusGatewayTimeout, apiErr.Code, "请求超时应返回 504")
+
+		apiErr = pkgs.DBError(logger, &pq.Error{Code: pkgs.PgQueryCanceled}, "查询角色列表失败")
+		assert.Equal(t, http.StatusGatewayTimeout, apiErr.Code, "语句超时应返回 504")
+	})
+
+	t.Run("其他错误", func(t *testing.T) {
+		apiErr := pkgs.DBError(logger, errors.New("connection refused"), "获取角色失败")
+		assert.Equal(t, http.StatusInternalServerError, apiErr.Code)
+		assert.Equal(t, "获取角色失败", apiErr.Message)
+
+		apiErr = pkgs.DBError(logger, &pq.Error{Code: "40001"}, "获取角色失败")
+		assert.Equal(t, http.StatusInternalServerError, apiErr.Code)
+	})
+}
@@ -0,0 +1,76 @@
+package pseudonymize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+func pseudonymizer(key string) *pkgs.Pseudonymizer {
+	return pkgs.NewPseudonymizer(&pkgs.Config{Encryption: pkgs.EncryptionConfig{PseudonymKey: key}})
+}
+
+var userFields = map[string]pkgs.PIIAction{
+	"phone":   pkgs.PIIHash,
+	"email":   pkgs.PIIHashEmail,
+	"profile": pkgs.PIIDrop,
+}
+
+// TestPseudonymizerStage 测试导出数据集的假名化阶段
+// 包含四个子测试：替换与删除 PII 字段、假名稳定且与密钥相关、空值保持为空、未配置密钥时拒绝
+func TestPseudonymizerStage(t *testing.T) {
+	t.Run("替换与删除 PII 字段", func(t *testing.T) {
+		stage, err := pseudonymizer("export-key").Stage(userFields)
+		require.NoError(t, err)
+
+		record := map[string]any{
+			"id":       "0199c5a0-0000-7000-8000-000000000001",
+			"username": "alice",
+			"phone":    "13800000000",
+			"email":    "Alice@Example.com",
+			"profile":  map[string]any{"age": 30},
+		}
+		out := stage(record)
+
+		assert.Equal(t, record["id"], out["id"])
+		assert.Equal(t, "alice", out["username"])
+		assert.NotContains(t, out, "profile")
+		assert.Len(t, out["phone"], 32)
+		assert.NotEqual(t, "13800000000", out["phone"])
+		// 不修改传入的记录
+		assert.Equal(t, "13800000000", record["phone"])
+		assert.Contains(t, record, "profile")
+	})
+
+	t.Run("假名稳定且与密钥相关", func(t *testing.T) {
+		stage, err := pseudonymizer("export-key").Stage(userFields)
+		require.NoError(t, err)
+		other, err := pseudonymizer("another-key").Stage(userFields)
+		require.NoError(t, err)
+
+		a := stage(map[string]any{"email": "Alice@Example.com", "phone": "13800000000"})
+		b := stage(map[string]any{"email": " alice@example.com", "phone": "13800000000"})
+		c := other(map[string]any{"email": "alice@example.com", "phone": "13800000000"})
+
+		assert.Equal(t, a["email"], b["email"], "邮箱不区分大小写")
+		assert.Equal(t, a["phone"], b["phone"])
+		assert.NotEqual(t, a["phone"], c["phone"], "不同密钥得到不同假名")
+	})
+
+	t.Run("空值保持为空", func(t *testing.T) {
+		stage, err := pseudonymizer("export-key").Stage(userFields)
+		require.NoError(t, err)
+		var phone *string
+		out := stage(map[string]any{"phone": phone, "email": nil})
+		assert.Equal(t, "", out["phone"])
+		assert.Equal(t, "", out["email"])
+	})
+
+	t.Run("未配置密钥时拒绝", func(t *testing.T) {
+		_, err := pseudonymizer("").Stage(userFields)
+		assert.ErrorIs(t, err, pkgs.ErrPseudonymizerDisabled)
+	})
+}
@@ -0,0 +1,172 @@
+package query_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// 测试用驱动：查询返回 rows 行（列 id），第 failAt 行返回错误（0 表示不出错），closed 记录结果集是否已关闭
+type fakeDriver struct {
+	rows   int
+	failAt int
+	closed atomic.Int32
+	// 每返回一行后调用，用于在遍历中途取消请求
+	onRow func(int)
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return &fakeStmt{d: c.d}, nil }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type fakeStmt struct{ d *fakeDriver }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) { return &fakeRows{d: s.d}, nil }
+
+type fakeRows struct {
+	d *fakeDriver
+	n int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error {
+	r.d.closed.Add(1)
+	return nil
+}
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n >= r.d.rows {
+		return io.EOF
+	}
+	r.n++
+	if r.n == r.d.failAt {
+		return errors.New("connection reset")
+	}
+	dest[0] = strconv.Itoa(r.n)
+	if r.d.onRow != nil {
+		r.d.onRow(r.n)
+	}
+	return nil
+}
+
+type item struct {
+	ID string `db:"id"`
+}
+
+var driverSeq atomic.Int32
+
+func openFake(t *testing.T, d *fakeDriver) *sqlx.DB {
+	name := "fake" + strconv.Itoa(int(driverSeq.Add(1)))
+	sql.Register(name, d)
+	db, err := sqlx.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// TestScanAll 测试扫描全部行
+// 包含四个子测试：正常扫描、遍历中途取消、遍历中途出错、已取消的请求
+func TestScanAll(t *testing.T) {
+	t.Run("正常扫描", func(t *testing.T) {
+		d := &fakeDriver{rows: 3}
+		db := openFake(t, d)
+		list, err := pkgs.QueryAll[item](context.Background(), db, "SELECT id")
+		require.NoError(t, err)
+		assert.Equal(t, []item{{"1"}, {"2"}, {"3"}}, list)
+		assert.EqualValues(t, 1, d.closed.Load(), "结果集应已关闭")
+	})
+
+	t.Run("遍历中途取消", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		scanned := 0
+		d := &fakeDriver{rows: 1000, onRow: func(n int) {
+			scanned = n
+			if n == 2 {
+				cancel()
+			}
+		}}
+		db := openFake(t, d)
+		list, err := pkgs.QueryAll[item](ctx, db, "SELECT id")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, list, "取消后不应返回部分结果")
+		assert.Less(t, scanned, 1000, "取消后应停止扫描")
+		assert.EqualValues(t, 1, d.closed.Load(), "结果集应已关闭")
+	})
+
+	t.Run("遍历中途出错", func(t *testing.T) {
+		d := &fakeDriver{rows: 5, failAt: 3}
+		db := openFake(t, d)
+		list, err := pkgs.QueryAll[item](context.Background(), db, "SELECT id")
+		assert.Error(t, err, "遍历中途出错时应返回错误")
+		assert.Nil(t, list, "出错后不应返回部分结果")
+	})
+
+	t.Run("已取消的请求", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		db := openFake(t, &fakeDriver{rows: 3})
+		_, err := pkgs.QueryAll[item](ctx, db, "SELECT id")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// TestQueryPlanCapture 测试执行计划捕获
+// 包含三个子测试：未请求执行计划、生成执行计划失败不影响查询、命名参数查询
+func TestQueryPlanCapture(t *testing.T) {
+	t.Run("未请求执行计划", func(t *testing.T) {
+		db := openFake(t, &fakeDriver{rows: 1})
+		_, err := pkgs.QueryAll[item](context.Background(), db, "SELECT id")
+		require.NoError(t, err)
+		assert.Nil(t, pkgs.QueryPlans(context.Background()))
+	})
+
+	t.Run("生成执行计划失败不影响查询", func(t *testing.T) {
+		// 测试驱动不支持事务，EXPLAIN 无法执行
+		ctx := pkgs.WithQueryPlans(context.Background(), time.Second, zap.NewNop())
+		db := openFake(t, &fakeDriver{rows: 2})
+		list, err := pkgs.QueryAll[item](ctx, db, "SELECT id WHERE id > $1", 0)
+		require.NoError(t, err)
+		assert.Len(t, list, 2)
+
+		plans := pkgs.QueryPlans(ctx)
+		require.Len(t, plans, 1)
+		assert.Equal(t, "SELECT id WHERE id > $1", plans[0].Query)
+		assert.Equal(t, []string{"0"}, plans[0].Args)
+		assert.Nil(t, plans[0].Plan)
+		assert.Contains(t, plans[0].Error, "not supported")
+	})
+
+	t.Run("命名参数查询", func(t *testing.T) {
+		ctx := pkgs.WithQueryPlans(context.Background(), time.Second, zap.NewNop())
+		db := sqlx.NewDb(openFake(t, &fakeDriver{rows: 1}).DB, "postgres")
+		_, err := pkgs.NamedQueryAll[item](ctx, db, "SELECT id WHERE name = :name", map[string]any{"name": "a"})
+		require.NoError(t, err)
+
+		plans := pkgs.QueryPlans(ctx)
+		require.Len(t, plans, 1)
+		assert.Equal(t, "SELECT id WHERE name = $1", plans[0].Query, "记录绑定参数后的查询")
+		assert.Equal(t, []string{`"a"`}, plans[0].Args)
+	})
+}
@@ -0,0 +1,73 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRateLimiter 测试固定窗口限流
+// 包含四个子测试：窗口内超出限制被拒绝、窗口结束后重新计数、不同 key 独立计数、影子模式统计超出限制的请求
+func TestRateLimiter(t *testing.T) {
+	t.Run("窗口内超出限制被拒绝", func(t *testing.T) {
+		now := time.Unix(1000, 0)
+		limiter := pkgs.NewRateLimiterWithClock(func() time.Time { return now })
+
+		for i := 0; i < 3; i++ {
+			res := limiter.Allow("key", 3, time.Minute)
+			assert.True(t, res.Allowed, "限制内的请求应被允许")
+			assert.Equal(t, 2-i, res.Remaining, "剩余次数应递减")
+		}
+		res := limiter.Allow("key", 3, time.Minute)
+		assert.False(t, res.Allowed, "超出限制的请求应被拒绝")
+		assert.Equal(t, 0, res.Remaining)
+		assert.Equal(t, now.Add(time.Minute), res.Reset, "重置时间应为窗口结束时间")
+	})
+
+	t.Run("窗口结束后重新计数", func(t *testing.T) {
+		now := time.Unix(1000, 0)
+		limiter := pkgs.NewRateLimiterWithClock(func() time.Time { return now })
+
+		assert.True(t, limiter.Allow("key", 1, time.Minute).Allowed)
+		assert.False(t, limiter.Allow("key", 1, time.Minute).Allowed)
+
+		now = now.Add(time.Minute)
+		assert.True(t, limiter.Allow("key", 1, time.Minute).Allowed, "新窗口应重新计数")
+	})
+
+	t.Run("不同 key 独立计数", func(t *testing.T) {
+		limiter := pkgs.NewRateLimiter()
+
+		assert.True(t, limiter.Allow("a", 1, time.Minute).Allowed)
+		assert.False(t, limiter.Allow("a", 1, time.Minute).Allowed)
+		assert.True(t, limiter.Allow("b", 1, time.Minute).Allowed, "其他 key 不受影响")
+	})
+
+	t.Run("影子模式统计超出限制的请求", func(t *testing.T) {
+		limiter := pkgs.NewRateLimiter()
+		shadow := pkgs.NewRateLimitShadow()
+
+		for i := 0; i < 5; i++ {
+			if res := limiter.Allow("a", 2, time.Minute); !res.Allowed {
+				shadow.Record("tenant", "a", "basic", time.Minute, res)
+			}
+		}
+		if res := limiter.Allow("b", 0, time.Minute); !res.Allowed {
+			shadow.Record("tenant", "b", "basic", time.Minute, res)
+		}
+		shadow.Record("other", "c", "basic", time.Minute, pkgs.RateLimitResult{Limit: 1, Count: 2})
+
+		clients := shadow.Clients("tenant")
+		assert.Len(t, clients, 2, "只返回当前租户")
+		assert.Equal(t, "a", clients[0].APIKeyID, "按超出的请求数倒序")
+		assert.Equal(t, int64(3), clients[0].Exceeded)
+		assert.Equal(t, 5, clients[0].Peak, "峰值包含超出限制的请求")
+		assert.Equal(t, 2, clients[0].Limit)
+		assert.Equal(t, time.Minute, clients[0].Window)
+		assert.Equal(t, int64(1), clients[1].Exceeded)
+		assert.Empty(t, shadow.Clients("none"))
+	})
+}
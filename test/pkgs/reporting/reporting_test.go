@@ -0,0 +1,83 @@
+package reporting_test
+
+import (
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestReportingDB 测试报表查询连接的选择
+// 包含四个子测试：未配置时使用主库、配置后使用分析库与其表名、租户请求使用主库、连接参数沿用主库
+func TestReportingDB(t *testing.T) {
+	primary := &sqlx.DB{}
+	tables := pkgs.NewTableNames(&pkgs.Config{})
+
+	t.Run("未配置时使用主库", func(t *testing.T) {
+		pool, cleanupPool := pkgs.NewTenantPool(&pkgs.Config{}, primary, &pkgs.BatchDB{DB: primary}, zap.NewNop())
+		defer cleanupPool()
+		reporting, cleanup, err := pkgs.NewReportingDB(&pkgs.Config{}, pool, tables, zap.NewNop())
+		require.NoError(t, err)
+		defer cleanup()
+
+		assert.False(t, reporting.Enabled())
+		c, _ := gin.CreateTestContext(nil)
+		db, names := reporting.Conn(c)
+		assert.Same(t, primary, db)
+		assert.Same(t, tables, names)
+		_, ok := reporting.Stats()
+		assert.False(t, ok, "未配置分析库时没有连接池统计")
+	})
+
+	// 分析库连接按需建立，启动时不可达只记录告警，这里不需要真实的数据库
+	config := &pkgs.Config{}
+	config.Database.Port = 5432
+	config.Database.Username = "app"
+	config.Database.Password = "secret"
+	config.Database.DBName = "demo"
+	config.Database.SSLMode = "disable"
+	config.Database.Reporting = pkgs.ReportingConfig{Host: "127.0.0.1", Port: 1, MaxOpenConns: 1, Schema: "analytics", TablePrefix: "rpt_"}
+
+	t.Run("配置后使用分析库与其表名", func(t *testing.T) {
+		pool, cleanupPool := pkgs.NewTenantPool(config, primary, &pkgs.BatchDB{DB: primary}, zap.NewNop())
+		defer cleanupPool()
+		reporting, cleanup, err := pkgs.NewReportingDB(config, pool, tables, zap.NewNop())
+		require.NoError(t, err)
+		defer cleanup()
+
+		assert.True(t, reporting.Enabled())
+		c, _ := gin.CreateTestContext(nil)
+		db, names := reporting.Conn(c)
+		assert.NotSame(t, primary, db)
+		assert.Equal(t, `"analytics"."rpt_audit_log"`, names.AuditLog)
+		stats, ok := reporting.Stats()
+		assert.True(t, ok)
+		assert.Equal(t, "reporting", stats.Name)
+	})
+
+	t.Run("租户请求使用主库", func(t *testing.T) {
+		tenantConfig := *config
+		tenantConfig.Tenant.Mode = pkgs.TenantModeSchema
+		pool, cleanupPool := pkgs.NewTenantPool(&tenantConfig, primary, &pkgs.BatchDB{DB: primary}, zap.NewNop())
+		defer cleanupPool()
+		reporting, cleanup, err := pkgs.NewReportingDB(&tenantConfig, pool, tables, zap.NewNop())
+		require.NoError(t, err)
+		defer cleanup()
+
+		tenantDB := &sqlx.DB{}
+		db, names := reporting.ForTenant("acme", tenantDB)
+		assert.Same(t, tenantDB, db, "分析库连接没有按租户设置 search_path")
+		assert.Same(t, tables, names)
+		_, names = reporting.ForTenant("", tenantDB)
+		assert.Equal(t, `"analytics"."rpt_audit_log"`, names.AuditLog, "默认 schema 的任务使用分析库")
+	})
+
+	t.Run("连接参数沿用主库", func(t *testing.T) {
+		assert.Equal(t, "host=127.0.0.1 port=1 user=app password=secret dbname=demo sslmode=disable timezone=UTC", pkgs.ReportingConnString(config))
+	})
+}
@@ -0,0 +1,76 @@
+package responsecode_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// respond 在测试上下文中写出响应并解析
+func respond(t *testing.T, write func(c *gin.Context)) pkgs.Response {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	write(c)
+	assert.Equal(t, http.StatusOK, w.Code, "HTTP状态码统一为200")
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// TestResponseCodes 测试响应信封中业务码的映射
+// 包含四个子测试：默认与 HTTP 状态码一致、按配置映射、未映射的错误使用默认业务码、创建后修改配置不影响映射
+func TestResponseCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { pkgs.NewResponseCodes(&pkgs.Config{Response: pkgs.ResponseConfig{SuccessCode: http.StatusOK}}) })
+
+	t.Run("默认与 HTTP 状态码一致", func(t *testing.T) {
+		codes := pkgs.NewResponseCodes(&pkgs.Config{Response: pkgs.ResponseConfig{SuccessCode: http.StatusOK}})
+		assert.Equal(t, http.StatusOK, codes.Success())
+		assert.Equal(t, http.StatusOK, respond(t, func(c *gin.Context) { pkgs.Success(c, "ok") }).Code)
+		assert.Equal(t, http.StatusNotFound, respond(t, func(c *gin.Context) { pkgs.Error(c, http.StatusNotFound, "不存在") }).Code)
+	})
+
+	t.Run("按配置映射", func(t *testing.T) {
+		codes := pkgs.NewResponseCodes(&pkgs.Config{Response: pkgs.ResponseConfig{
+			SuccessCode: 0,
+			ErrorCodes:  map[int]int{http.StatusBadRequest: 1400, pkgs.CodeAccessIPDenied: 1432},
+		}})
+		assert.Equal(t, 0, respond(t, func(c *gin.Context) { pkgs.Success(c, "ok") }).Code)
+		assert.Equal(t, 0, codes.Success())
+
+		resp := respond(t, func(c *gin.Context) {
+			pkgs.HandleError[string](c)(pkgs.NewApiError(http.StatusBadRequest, "参数错误"))
+		})
+		assert.Equal(t, 1400, resp.Code)
+		assert.Equal(t, "参数错误", resp.Msg, "消息不受映射影响")
+		assert.Equal(t, 1432, respond(t, func(c *gin.Context) { pkgs.Error(c, pkgs.CodeAccessIPDenied, "IP 受限") }).Code)
+		assert.Equal(t, http.StatusConflict, respond(t, func(c *gin.Context) { pkgs.Error(c, http.StatusConflict, "已存在") }).Code, "未映射时返回内部业务码")
+	})
+
+	t.Run("未映射的错误使用默认业务码", func(t *testing.T) {
+		pkgs.NewResponseCodes(&pkgs.Config{Response: pkgs.ResponseConfig{
+			SuccessCode:      0,
+			ErrorCodes:       map[int]int{http.StatusUnauthorized: 1401},
+			DefaultErrorCode: 1000,
+		}})
+		assert.Equal(t, 1401, respond(t, func(c *gin.Context) { pkgs.Error(c, http.StatusUnauthorized, "未授权") }).Code)
+		assert.Equal(t, 1000, respond(t, func(c *gin.Context) { pkgs.Error(c, http.StatusInternalServerError, "服务器内部错误") }).Code)
+	})
+
+	t.Run("创建后修改配置不影响映射", func(t *testing.T) {
+		errorCodes := map[int]int{http.StatusNotFound: 1404}
+		codes := pkgs.NewResponseCodes(&pkgs.Config{Response: pkgs.ResponseConfig{SuccessCode: 0, ErrorCodes: errorCodes}})
+		errorCodes[http.StatusNotFound] = 0
+		assert.Equal(t, 1404, codes.Error(http.StatusNotFound))
+		assert.Equal(t, 1404, respond(t, func(c *gin.Context) { pkgs.Error(c, http.StatusNotFound, "不存在") }).Code)
+	})
+}
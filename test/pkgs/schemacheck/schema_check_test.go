@@ -0,0 +1,98 @@
+package schemacheck_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+type auditFields struct {
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type widgetEntity struct {
+	ID string `db:"id"`
+	auditFields
+	Name       string            `db:"name"`
+	Num        *int              `db:"num"`
+	Tags       pq.StringArray    `db:"tags"`
+	Extra      json.RawMessage   `db:"extra"`
+	Labels     pkgs.Translations `db:"translations"`
+	UsageCount int64             `db:"usage_count"`
+	Ignored    string            `db:"-"`
+}
+
+// 与 widgetEntity 一致的表结构
+func widgetColumns() map[string]pkgs.SchemaColumn {
+	columns := []pkgs.SchemaColumn{
+		{Name: "id", DataType: "uuid"},
+		{Name: "created_at", DataType: "timestamp with time zone"},
+		{Name: "updated_at", DataType: "timestamp with time zone"},
+		{Name: "name", DataType: "character varying"},
+		{Name: "num", DataType: "integer", Nullable: true},
+		{Name: "tags", DataType: "ARRAY", UDTName: "_uuid"},
+		{Name: "extra", DataType: "jsonb", Nullable: true},
+		{Name: "translations", DataType: "jsonb", Nullable: true},
+		{Name: "seq", DataType: "bigint"},
+	}
+	byName := map[string]pkgs.SchemaColumn{}
+	for _, column := range columns {
+		byName[column.Name] = column
+	}
+	return byName
+}
+
+// TestCompareSchema 测试实体与表结构的对比
+// 包含五个子测试：结构一致、缺少列、类型不符、可空列对应非指针字段、表不存在
+func TestCompareSchema(t *testing.T) {
+	entity := pkgs.SchemaEntity{Table: "widget", Entity: widgetEntity{}, Computed: []string{"usage_count"}}
+
+	t.Run("结构一致", func(t *testing.T) {
+		assert.Empty(t, pkgs.CompareSchema(entity, widgetColumns()), "嵌入结构体展开、计算列与 db:\"-\" 跳过、表中多出的列不报告")
+	})
+
+	t.Run("缺少列", func(t *testing.T) {
+		columns := widgetColumns()
+		delete(columns, "updated_at")
+		drifts := pkgs.CompareSchema(entity, columns)
+		assert.Equal(t, []pkgs.SchemaDrift{{Table: "widget", Column: "updated_at", Field: "schemacheck_test.widgetEntity.UpdatedAt", Problem: "column does not exist"}}, drifts)
+	})
+
+	t.Run("类型不符", func(t *testing.T) {
+		columns := widgetColumns()
+		columns["num"] = pkgs.SchemaColumn{Name: "num", DataType: "text", Nullable: true}
+		columns["tags"] = pkgs.SchemaColumn{Name: "tags", DataType: "jsonb"}
+		columns["translations"] = pkgs.SchemaColumn{Name: "translations", DataType: "text"}
+		drifts := pkgs.CompareSchema(entity, columns)
+		if assert.Len(t, drifts, 3) {
+			assert.Equal(t, "num", drifts[0].Column)
+			assert.Contains(t, drifts[0].Problem, "*int cannot scan column type text")
+			assert.Equal(t, "tags", drifts[1].Column)
+			assert.Equal(t, "translations", drifts[2].Column)
+		}
+	})
+
+	t.Run("可空列对应非指针字段", func(t *testing.T) {
+		columns := widgetColumns()
+		name := columns["name"]
+		name.Nullable = true
+		columns["name"] = name
+		drifts := pkgs.CompareSchema(entity, columns)
+		if assert.Len(t, drifts, 1) {
+			assert.Equal(t, "name", drifts[0].Column)
+			assert.Contains(t, drifts[0].String(), "widget.name (schemacheck_test.widgetEntity.Name): column is nullable")
+		}
+	})
+
+	t.Run("表不存在", func(t *testing.T) {
+		drifts := pkgs.CompareSchema(entity, nil)
+		assert.Equal(t, []pkgs.SchemaDrift{{Table: "widget", Problem: "table does not exist"}}, drifts)
+		assert.Equal(t, "widget: table does not exist", drifts[0].String())
+	})
+}
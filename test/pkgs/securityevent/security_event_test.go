@@ -0,0 +1,205 @@
+package securityevent_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+func siemConfig(sink string) *pkgs.Config {
+	return &pkgs.Config{
+		App: pkgs.AppConfig{Name: "go-pg-demo"},
+		SIEM: pkgs.SIEMConfig{
+			Sink:          sink,
+			BufferSize:    100,
+			BatchSize:     2,
+			FlushInterval: time.Hour,
+			MaxRetries:    0,
+			Timeout:       time.Second,
+			HTTP:          pkgs.SIEMHTTPConfig{Format: pkgs.SIEMFormatJSON},
+		},
+	}
+}
+
+// collector 记录 SIEM 收到的每个请求
+type collector struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (c *collector) server(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			body = append(body, scanner.Bytes()...)
+			body = append(body, '\n')
+		}
+		c.mu.Lock()
+		c.requests = append(c.requests, r)
+		c.bodies = append(c.bodies, body)
+		c.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestSecurityEventsHTTP 测试 HTTP 推送
+// 包含三个子测试：按批次推送且关闭时推送剩余事件、Splunk HEC 格式、Kafka REST Proxy 格式
+func TestSecurityEventsHTTP(t *testing.T) {
+	t.Run("按批次推送且关闭时推送剩余事件", func(t *testing.T) {
+		var c collector
+		server := c.server(t)
+		config := siemConfig(pkgs.SIEMSinkHTTP)
+		config.SIEM.HTTP.URL = server.URL
+		config.SIEM.HTTP.Headers = map[string]string{"Authorization": "Bearer test"}
+
+		events, cleanup, err := pkgs.NewSecurityEvents(config, zap.NewNop())
+		require.NoError(t, err)
+		for _, user := range []string{"u1", "u2", "u3", "u4", "u5"} {
+			events.Emit(pkgs.SecurityEvent{Type: pkgs.SecurityEventLoginFailure, UserID: user})
+		}
+		cleanup()
+
+		require.Len(t, c.requests, 3)
+		assert.Equal(t, "Bearer test", c.requests[0].Header.Get("Authorization"))
+		var total int
+		for _, body := range c.bodies {
+			var batch []pkgs.SecurityEvent
+			require.NoError(t, json.Unmarshal(body, &batch))
+			assert.LessOrEqual(t, len(batch), 2)
+			for _, event := range batch {
+				assert.Equal(t, pkgs.SecurityEventLoginFailure, event.Type)
+				assert.Equal(t, "go-pg-demo", event.App)
+				assert.False(t, event.Time.IsZero())
+			}
+			total += len(batch)
+		}
+		assert.Equal(t, 5, total)
+	})
+
+	t.Run("Splunk HEC 格式", func(t *testing.T) {
+		var c collector
+		server := c.server(t)
+		config := siemConfig(pkgs.SIEMSinkHTTP)
+		config.SIEM.HTTP.URL = server.URL
+		config.SIEM.HTTP.Format = pkgs.SIEMFormatSplunkHEC
+
+		events, cleanup, err := pkgs.NewSecurityEvents(config, zap.NewNop())
+		require.NoError(t, err)
+		events.Emit(pkgs.SecurityEvent{Type: pkgs.SecurityEventPermissionDenied, UserID: "u1"})
+		events.Emit(pkgs.SecurityEvent{Type: pkgs.SecurityEventRoleChange, UserID: "u2"})
+		cleanup()
+
+		require.Len(t, c.bodies, 1)
+		scanner := bufio.NewScanner(bytes.NewReader(c.bodies[0]))
+		var lines int
+		for scanner.Scan() {
+			var hec struct {
+				SourceType string             `json:"sourcetype"`
+				Event      pkgs.SecurityEvent `json:"event"`
+			}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &hec))
+			assert.Equal(t, "_json", hec.SourceType)
+			assert.NotEmpty(t, hec.Event.Type)
+			lines++
+		}
+		assert.Equal(t, 2, lines)
+	})
+
+	t.Run("Kafka REST Proxy 格式", func(t *testing.T) {
+		var c collector
+		server := c.server(t)
+		config := siemConfig(pkgs.SIEMSinkKafka)
+		config.SIEM.Kafka = pkgs.SIEMKafkaConfig{RESTURL: server.URL + "/", Topic: "security-events"}
+
+		events, cleanup, err := pkgs.NewSecurityEvents(config, zap.NewNop())
+		require.NoError(t, err)
+		events.Emit(pkgs.SecurityEvent{Type: pkgs.SecurityEventTokenRefresh, UserID: "u1"})
+		cleanup()
+
+		require.Len(t, c.requests, 1)
+		assert.Equal(t, "/topics/security-events", c.requests[0].URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", c.requests[0].Header.Get("Content-Type"))
+		var body struct {
+			Records []struct {
+				Key   string             `json:"key"`
+				Value pkgs.SecurityEvent `json:"value"`
+			} `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(c.bodies[0], &body))
+		require.Len(t, body.Records, 1)
+		assert.Equal(t, "u1", body.Records[0].Key)
+		assert.Equal(t, pkgs.SecurityEventTokenRefresh, body.Records[0].Value.Type)
+	})
+}
+
+// blockingSink 推送时阻塞，模拟 SIEM 变慢
+type blockingSink struct {
+	sending chan struct{}
+	release chan struct{}
+	mu      sync.Mutex
+	got     int
+}
+
+func (s *blockingSink) Send(_ context.Context, events []pkgs.SecurityEvent) error {
+	s.sending <- struct{}{}
+	<-s.release
+	s.mu.Lock()
+	s.got += len(events)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *blockingSink) Close() error { return nil }
+
+// TestSecurityEventsBackpressure 测试 SIEM 变慢时队列满后丢弃事件，不阻塞调用方
+func TestSecurityEventsBackpressure(t *testing.T) {
+	config := siemConfig("")
+	config.SIEM.BufferSize = 1
+	config.SIEM.BatchSize = 1
+	sink := &blockingSink{sending: make(chan struct{}, 10), release: make(chan struct{})}
+
+	events, cleanup, err := pkgs.NewSecurityEventsWithSink(config, sink, zap.NewNop())
+	require.NoError(t, err)
+
+	// 第一个事件被后台协程取出并阻塞在推送中
+	events.Emit(pkgs.SecurityEvent{Type: pkgs.SecurityEventLoginFailure})
+	<-sink.sending
+
+	// 第二个事件占满队列，之后的事件立即丢弃
+	start := time.Now()
+	for range 5 {
+		events.Emit(pkgs.SecurityEvent{Type: pkgs.SecurityEventLoginFailure})
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	close(sink.release)
+	cleanup()
+	assert.Equal(t, 2, sink.got)
+}
+
+// TestSecurityEventsDisabled 测试未配置推送目标时为空操作
+func TestSecurityEventsDisabled(t *testing.T) {
+	events, cleanup, err := pkgs.NewSecurityEvents(siemConfig(""), zap.NewNop())
+	require.NoError(t, err)
+	assert.False(t, events.Enabled())
+	events.Emit(pkgs.SecurityEvent{Type: pkgs.SecurityEventLoginSuccess})
+	cleanup()
+
+	var nilEvents *pkgs.SecurityEvents
+	assert.False(t, nilEvents.Enabled())
+}
@@ -0,0 +1,38 @@
+package seed_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "go-pg-demo/docs"
+	"go-pg-demo/pkgs"
+)
+
+// TestPermissions 测试从接口文档读取服务定义的接口权限
+// 包含两个子测试：包含带 x-permission 的接口、不重复且有序
+func TestPermissions(t *testing.T) {
+	defs, err := pkgs.Permissions()
+	require.NoError(t, err)
+	require.NotEmpty(t, defs)
+
+	t.Run("包含带 x-permission 的接口", func(t *testing.T) {
+		assert.Contains(t, defs, pkgs.PermissionDef{Name: "撤销审计记录对应的操作", Method: "POST", Path: "/v1/audit/:id/revert"})
+		for _, def := range defs {
+			assert.NotEqual(t, "/v1/auth/login", def.Path, "登录接口不在权限体系中")
+		}
+	})
+
+	t.Run("不重复且有序", func(t *testing.T) {
+		seen := map[string]bool{}
+		for i, def := range defs {
+			route := def.Method + " " + def.Path
+			assert.False(t, seen[route], "重复的接口权限 %s", route)
+			seen[route] = true
+			if i > 0 {
+				assert.LessOrEqual(t, defs[i-1].Path, def.Path)
+			}
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package settings_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go-pg-demo/pkgs"
+)
+
+// newSettings 创建不连接数据库的运行时设置，读取方法返回配置文件中的取值
+func newSettings() *pkgs.Settings {
+	config := &pkgs.Config{
+		JWT:            pkgs.JWTConfig{AccessTokenExpire: 15 * time.Minute, RefreshTokenExpire: 24 * time.Hour},
+		PasswordPolicy: pkgs.PasswordPolicy{MinLength: 8, RequireDigit: true},
+		PublicAPI: pkgs.PublicAPIConfig{Tiers: map[string]pkgs.RateLimitTier{
+			"basic": {Limit: 60, Window: time.Minute},
+		}},
+	}
+	return pkgs.NewSettings(config, nil, pkgs.NewTableNames(config), zap.NewNop())
+}
+
+// parse 按键解析设置项的 JSON 值
+func parse(t *testing.T, key, raw string) (any, error) {
+	t.Helper()
+	definition, ok := pkgs.SettingDefinitionByKey(key)
+	require.True(t, ok, key)
+	return definition.Parse(json.RawMessage(raw))
+}
+
+// TestSettings 测试运行时设置
+// 包含四个子测试：未加载数据库时使用配置文件的取值、时长校验、密码策略校验、限流等级校验
+func TestSettings(t *testing.T) {
+	t.Run("未加载数据库时使用配置文件的取值", func(t *testing.T) {
+		s := newSettings()
+		assert.Equal(t, 15*time.Minute, s.AccessTokenExpire())
+		assert.Equal(t, 24*time.Hour, s.RefreshTokenExpire())
+		assert.Equal(t, pkgs.PasswordPolicy{MinLength: 8, RequireDigit: true}, s.PasswordPolicy())
+
+		tier, ok := s.RateLimitTier("basic")
+		require.True(t, ok)
+		assert.Equal(t, 60, tier.Limit)
+		assert.Equal(t, time.Minute, tier.Window)
+		_, ok = s.RateLimitTier("missing")
+		assert.False(t, ok)
+
+		_, ok = pkgs.SettingDefinitionByKey("missing")
+		assert.False(t, ok, "未注册的设置项")
+	})
+
+	t.Run("时长校验", func(t *testing.T) {
+		value, err := parse(t, pkgs.SettingAccessTokenExpire, `"30m"`)
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, value)
+
+		for _, raw := range []string{`"0s"`, `"-1m"`, `"abc"`, `900`} {
+			_, err := parse(t, pkgs.SettingRefreshTokenExpire, raw)
+			assert.Error(t, err, raw)
+		}
+	})
+
+	t.Run("密码策略校验", func(t *testing.T) {
+		value, err := parse(t, pkgs.SettingPasswordPolicy, `{"min_length":10,"require_letter":true,"require_digit":true}`)
+		require.NoError(t, err)
+		assert.Equal(t, pkgs.PasswordPolicy{MinLength: 10, RequireLetter: true, RequireDigit: true}, value)
+
+		for _, raw := range []string{`{"min_length":0}`, `{"min_length":73}`, `{"min_length":8,"require_upper":true}`} {
+			_, err := parse(t, pkgs.SettingPasswordPolicy, raw)
+			assert.Error(t, err, raw)
+		}
+
+		policy := value.(pkgs.PasswordPolicy)
+		assert.NotNil(t, policy.Check("short1", nil), "长度不足")
+		assert.NotNil(t, policy.Check(strings.Repeat("a", 10), nil), "缺少数字")
+		assert.NotNil(t, policy.Check(strings.Repeat("1", 10), nil), "缺少字母")
+		assert.Nil(t, policy.Check("password123", nil))
+		assert.Nil(t, policy.Check("密码密码密码密码密码a1", nil), "按字符计算长度")
+	})
+
+	t.Run("限流等级校验", func(t *testing.T) {
+		value, err := parse(t, pkgs.SettingRateLimitTiers, `{"pro":{"limit":600,"window":"1m","shadow":true}}`)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]pkgs.RateLimitTier{"pro": {Limit: 600, Window: time.Minute, Shadow: true}}, value)
+
+		for _, raw := range []string{`{"pro":{"limit":0,"window":"1m"}}`, `{"pro":{"limit":10,"window":"0s"}}`, `{"pro":{"limit":10,"window":60}}`} {
+			_, err := parse(t, pkgs.SettingRateLimitTiers, raw)
+			assert.Error(t, err, raw)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// fakeS3 内存中的 S3 服务，记录每个请求
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	requests []*http.Request
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r)
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func storageConfig(storage pkgs.StorageConfig) *pkgs.Config {
+	storage.Timeout = time.Second
+	return &pkgs.Config{Storage: storage}
+}
+
+// TestStorage 测试对象存储
+// 包含四个子测试：未配置时不可用、本地目录、S3 路径风格请求与签名、S3 错误响应
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("未配置时不可用", func(t *testing.T) {
+		storage, err := pkgs.NewStorage(storageConfig(pkgs.StorageConfig{}))
+		require.NoError(t, err)
+		assert.False(t, storage.Enabled())
+		_, err = storage.Get(ctx, "a.json")
+		assert.ErrorIs(t, err, pkgs.ErrObjectNotFound)
+		assert.Error(t, storage.Put(ctx, "a.json", []byte("{}"), "application/json"))
+	})
+
+	t.Run("本地目录", func(t *testing.T) {
+		storage, err := pkgs.NewStorage(storageConfig(pkgs.StorageConfig{
+			Driver: pkgs.StorageDriverFile,
+			Prefix: "test/",
+			File:   pkgs.StorageFileConfig{Dir: t.TempDir()},
+		}))
+		require.NoError(t, err)
+		require.True(t, storage.Enabled())
+
+		require.NoError(t, storage.Put(ctx, "templates/a.json", []byte(`{"a":1}`), "application/json"))
+		data, err := storage.Get(ctx, "templates/a.json")
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+
+		require.NoError(t, storage.Delete(ctx, "templates/a.json"))
+		_, err = storage.Get(ctx, "templates/a.json")
+		assert.ErrorIs(t, err, pkgs.ErrObjectNotFound)
+		assert.NoError(t, storage.Delete(ctx, "templates/a.json"), "删除不存在的对象不报错")
+
+		assert.Error(t, storage.Put(ctx, "../escape.json", []byte("{}"), "application/json"), "key 不能跳出存储目录")
+	})
+
+	t.Run("S3 路径风格请求与签名", func(t *testing.T) {
+		fake := &fakeS3{objects: map[string][]byte{}}
+		server := httptest.NewServer(fake)
+		t.Cleanup(server.Close)
+		storage, err := pkgs.NewStorage(storageConfig(pkgs.StorageConfig{
+			Driver: pkgs.StorageDriverS3,
+			Prefix: "prod/",
+			S3: pkgs.StorageS3Config{
+				Endpoint:  server.URL,
+				Region:    "eu-west-1",
+				Bucket:    "archive",
+				AccessKey: "AKIDEXAMPLE",
+				SecretKey: "secret",
+				PathStyle: true,
+			},
+		}))
+		require.NoError(t, err)
+
+		body := []byte(`{"id":"1"}`)
+		require.NoError(t, storage.Put(ctx, "templates/1.json", body, "application/json"))
+		data, err := storage.Get(ctx, "templates/1.json")
+		require.NoError(t, err)
+		assert.Equal(t, body, data)
+		require.NoError(t, storage.Delete(ctx, "templates/1.json"))
+		_, err = storage.Get(ctx, "templates/1.json")
+		assert.ErrorIs(t, err, pkgs.ErrObjectNotFound)
+
+		put := fake.requests[0]
+		assert.Equal(t, "/archive/prod/templates/1.json", put.URL.Path, "路径风格地址为 /bucket/prefix+key")
+		sum := sha256.Sum256(body)
+		assert.Equal(t, hex.EncodeToString(sum[:]), put.Header.Get("X-Amz-Content-Sha256"))
+		auth := put.Header.Get("Authorization")
+		date := put.Header.Get("X-Amz-Date")
+		require.Len(t, date, len("20060102T150405Z"))
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"+date[:8]+"/eu-west-1/s3/aws4_request, "), auth)
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=")
+	})
+
+	t.Run("S3 错误响应", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		}))
+		t.Cleanup(server.Close)
+		storage, err := pkgs.NewStorage(storageConfig(pkgs.StorageConfig{
+			Driver: pkgs.StorageDriverS3,
+			S3:     pkgs.StorageS3Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "archive", PathStyle: true},
+		}))
+		require.NoError(t, err)
+		err = storage.Put(ctx, "a.json", []byte("{}"), "application/json")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "AccessDenied")
+	})
+}
@@ -0,0 +1,54 @@
+package table_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestTableNamesRename 测试迁移文件中标识符的前缀替换
+// 包含五个子测试：表名、索引与约束、函数、包含表名的其他标识符、无前缀时不变
+func TestTableNamesRename(t *testing.T) {
+	tables := pkgs.NewTableNames(&pkgs.Config{Database: pkgs.DatabaseConfig{TablePrefix: "demo_"}})
+
+	t.Run("表名", func(t *testing.T) {
+		assert.Equal(t,
+			`ALTER TABLE "demo_iacc_user" ADD COLUMN seq BIGINT; SELECT * FROM demo_iacc_user_role;`,
+			tables.Rename(`ALTER TABLE "iacc_user" ADD COLUMN seq BIGINT; SELECT * FROM iacc_user_role;`))
+	})
+
+	t.Run("索引与约束", func(t *testing.T) {
+		assert.Equal(t,
+			`CREATE UNIQUE INDEX IF NOT EXISTS demo_idx_iacc_user_seq ON "demo_iacc_user" (seq);`,
+			tables.Rename(`CREATE UNIQUE INDEX IF NOT EXISTS idx_iacc_user_seq ON "iacc_user" (seq);`))
+		assert.Equal(t,
+			`DROP INDEX IF EXISTS demo_idx_template_seq;`,
+			tables.Rename(`DROP INDEX IF EXISTS idx_template_seq;`))
+		assert.Equal(t,
+			`CONSTRAINT demo_fk_user_role_user FOREIGN KEY (user_id) REFERENCES "demo_iacc_user"(id), CONSTRAINT demo_chk_iacc_client_ttl CHECK (ttl > 0)`,
+			tables.Rename(`CONSTRAINT fk_user_role_user FOREIGN KEY (user_id) REFERENCES "iacc_user"(id), CONSTRAINT chk_iacc_client_ttl CHECK (ttl > 0)`))
+	})
+
+	t.Run("函数", func(t *testing.T) {
+		assert.Equal(t,
+			`CREATE OR REPLACE FUNCTION demo_record_template_tombstone() RETURNS TRIGGER AS $$ BEGIN INSERT INTO "demo_template_tombstone" (id) VALUES (OLD.id); RETURN OLD; END; $$`,
+			tables.Rename(`CREATE OR REPLACE FUNCTION record_template_tombstone() RETURNS TRIGGER AS $$ BEGIN INSERT INTO "template_tombstone" (id) VALUES (OLD.id); RETURN OLD; END; $$`))
+		assert.Equal(t,
+			`EXECUTE FUNCTION demo_update_updated_at_column();`,
+			tables.Rename(`EXECUTE FUNCTION update_updated_at_column();`))
+	})
+
+	t.Run("包含表名的其他标识符", func(t *testing.T) {
+		// 触发器名称只在所属表内唯一，列名、索引名中间出现的表名都不替换
+		sql := `CREATE TRIGGER trigger_update_updated_at_iacc_user BEFORE UPDATE ON "demo_x" FOR EACH ROW; SELECT template_id, my_template, iacc_users FROM t;`
+		assert.Equal(t, sql, tables.Rename(sql))
+		assert.Equal(t, "demo_idx_iacc_user_email", tables.Rename("idx_iacc_user_email"), "索引名整体加一次前缀")
+	})
+
+	t.Run("无前缀时不变", func(t *testing.T) {
+		sql := `CREATE INDEX idx_template_seq ON "template" (seq);`
+		assert.Equal(t, sql, pkgs.NewTableNames(&pkgs.Config{}).Rename(sql))
+	})
+}
@@ -0,0 +1,56 @@
+package timeformat_test
+
+import (
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTimeFormatter 测试接口时间字段的时区与格式
+// 包含四个子测试：默认时区与格式、?tz= 优先、Accept-Language 映射、非法时区
+func TestTimeFormatter(t *testing.T) {
+	config := &pkgs.Config{Time: pkgs.TimeConfig{
+		Timezone:          "UTC",
+		Format:            "datetime",
+		LanguageTimezones: map[string]string{"zh-CN": "Asia/Shanghai", "ja": "Asia/Tokyo"},
+	}}
+	formatter, err := pkgs.NewTimeFormatter(config)
+	if !assert.NoError(t, err, "创建格式化器不应出错") {
+		return
+	}
+	ts := time.Date(2025, 10, 1, 8, 30, 0, 0, time.UTC)
+
+	t.Run("默认时区与格式", func(t *testing.T) {
+		loc, err := formatter.Resolve("", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-10-01 08:30:00", formatter.Format(ts, loc))
+	})
+
+	t.Run("tz 参数优先", func(t *testing.T) {
+		loc, err := formatter.Resolve("America/New_York", "zh-CN")
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-10-01 04:30:00", formatter.Format(ts, loc))
+	})
+
+	t.Run("Accept-Language 映射", func(t *testing.T) {
+		loc, err := formatter.Resolve("", "zh-CN,zh;q=0.9,en;q=0.8")
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-10-01 16:30:00", formatter.Format(ts, loc), "zh-CN 应使用 Asia/Shanghai")
+
+		loc, err = formatter.Resolve("", "fr-FR, ja-JP;q=0.5")
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-10-01 17:30:00", formatter.Format(ts, loc), "ja-JP 应按主语言匹配 Asia/Tokyo")
+
+		loc, err = formatter.Resolve("", "fr-FR")
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-10-01 08:30:00", formatter.Format(ts, loc), "未配置的语言使用默认时区")
+	})
+
+	t.Run("非法时区", func(t *testing.T) {
+		_, err := formatter.Resolve("Not/AZone", "")
+		assert.Error(t, err, "非法时区应返回错误")
+	})
+}
@@ -0,0 +1,69 @@
+package translation_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestTranslationsLocalize 测试按 Accept-Language 选择翻译
+// 包含四个子测试：完整语言标签、主语言回退、未填写的字段使用原始值、没有匹配的翻译
+func TestTranslationsLocalize(t *testing.T) {
+	english := "Administrator role"
+	translations := pkgs.Translations{
+		"en":    {Name: "Administrator", Description: &english},
+		"zh-TW": {Name: "管理員"},
+	}
+	original := "超级管理员"
+	localize := func(acceptLanguage string) (string, *string) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Accept-Language", acceptLanguage)
+		return translations.Localize(c, "管理员", &original)
+	}
+
+	t.Run("完整语言标签", func(t *testing.T) {
+		name, _ := localize("zh-tw, en;q=0.8")
+		assert.Equal(t, "管理員", name)
+	})
+
+	t.Run("主语言回退", func(t *testing.T) {
+		name, description := localize("en-GB")
+		assert.Equal(t, "Administrator", name)
+		assert.Equal(t, &english, description)
+	})
+
+	t.Run("未填写的字段使用原始值", func(t *testing.T) {
+		_, description := localize("zh-TW")
+		assert.Equal(t, &original, description)
+	})
+
+	t.Run("没有匹配的翻译", func(t *testing.T) {
+		name, description := localize("ja, *")
+		assert.Equal(t, "管理员", name)
+		assert.Equal(t, &original, description)
+	})
+}
+
+// TestCanonicalLocale 测试语言标签的规范化与校验
+func TestCanonicalLocale(t *testing.T) {
+	for input, expected := range map[string]string{
+		"EN":         "en",
+		"en-us":      "en-US",
+		"zh-hans-cn": "zh-Hans-CN",
+	} {
+		canonical, ok := pkgs.CanonicalLocale(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, canonical)
+	}
+
+	for _, input := range []string{"", "e", "en_US", "en-", "中文"} {
+		_, ok := pkgs.CanonicalLocale(input)
+		assert.False(t, ok, input)
+		assert.Len(t, pkgs.LocaleViolations("locale", input), 1)
+	}
+}
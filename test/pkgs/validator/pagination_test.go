@@ -0,0 +1,58 @@
+package validator_test
+
+import (
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type listReq struct {
+	pkgs.Pagination
+	OrderBy string `form:"orderBy,default=created_at" validate:"oneof=id created_at" label:"排序字段"`
+	Order   string `form:"order,default=desc" validate:"sort_order" label:"排序顺序"`
+}
+
+// TestPaginationAndSort 测试共用的分页参数与排序参数校验
+func TestPaginationAndSort(t *testing.T) {
+	v := pkgs.NewRequestValidator()
+	validate := func(req listReq) error {
+		return pkgs.ValidateV2[listReq](v)(&req).Error()
+	}
+	valid := listReq{Pagination: pkgs.Pagination{Page: 2, PageSize: 100}, OrderBy: "id", Order: "ASC"}
+
+	t.Run("通过", func(t *testing.T) {
+		assert.NoError(t, validate(valid))
+		assert.Equal(t, 100, valid.Offset())
+		assert.Equal(t, "ASC", pkgs.SortDirection("asc"))
+		assert.Equal(t, "DESC", pkgs.SortDirection("Desc"))
+	})
+
+	t.Run("每页大小超过上限", func(t *testing.T) {
+		req := valid
+		req.PageSize = 101
+		err := validate(req)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "每页大小")
+		}
+	})
+
+	t.Run("排序字段不在声明的范围内", func(t *testing.T) {
+		req := valid
+		req.OrderBy = "password"
+		err := validate(req)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "排序字段必须是[id created_at]中的一个")
+		}
+	})
+
+	t.Run("排序顺序无效", func(t *testing.T) {
+		req := valid
+		req.Order = "sideways"
+		err := validate(req)
+		if assert.Error(t, err) {
+			assert.Equal(t, "排序顺序必须是asc或desc", err.(*pkgs.ApiError).Message)
+		}
+	})
+}
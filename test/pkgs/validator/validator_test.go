@@ -0,0 +1,81 @@
+package validator_test
+
+import (
+	"net/http"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	Code string `json:"code" validate:"required" label:"编码"`
+}
+
+type batchReq struct {
+	Name  string `json:"name" validate:"required" label:"名称"`
+	Items []item `json:"items" validate:"required,min=1,dive" label:"列表"`
+}
+
+// TestValidateV2Rules 测试跨字段规则与多条违规合并返回，以及按语言生成校验信息
+func TestValidateV2Rules(t *testing.T) {
+	v := pkgs.NewRequestValidator()
+	pkgs.RegisterRule(v, func(req *batchReq) []pkgs.Violation {
+		codes := make([]string, len(req.Items))
+		for i, it := range req.Items {
+			codes[i] = it.Code
+		}
+		return pkgs.DuplicateViolations("items.code", "编码", codes)
+	})
+
+	t.Run("通过", func(t *testing.T) {
+		req := &batchReq{Name: "ok", Items: []item{{Code: "a"}, {Code: "b"}}}
+		res := pkgs.ValidateV2[batchReq](v)(req)
+		assert.True(t, res.IsOk(), "无违规时应通过")
+	})
+
+	t.Run("字段错误与规则错误一次返回", func(t *testing.T) {
+		req := &batchReq{Items: []item{{Code: "a"}, {Code: "b"}, {Code: "a"}, {Code: "b"}}}
+		res := pkgs.ValidateV2[batchReq](v)(req)
+		assert.True(t, res.IsError(), "存在违规时应返回错误")
+
+		apiErr, ok := res.Error().(*pkgs.ApiError)
+		if !assert.True(t, ok, "错误类型应为 ApiError") {
+			return
+		}
+		assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+		assert.Contains(t, apiErr.Message, "名称为必填字段")
+		assert.Contains(t, apiErr.Message, "编码存在重复值 a")
+		assert.Contains(t, apiErr.Message, "编码存在重复值 b")
+
+		violations, ok := apiErr.Data.([]pkgs.Violation)
+		if assert.True(t, ok, "规则违规时应附带明细") && assert.Len(t, violations, 3) {
+			assert.Equal(t, "名称", violations[0].Field)
+			assert.Equal(t, []int{0, 2}, violations[1].Indexes)
+			assert.Equal(t, []int{1, 3}, violations[2].Indexes)
+		}
+	})
+
+	t.Run("仅字段错误时不附带明细", func(t *testing.T) {
+		req := &batchReq{Items: []item{{Code: "a"}}}
+		res := pkgs.ValidateV2[batchReq](v)(req)
+		apiErr := res.Error().(*pkgs.ApiError)
+		assert.Equal(t, "名称为必填字段", apiErr.Message)
+		assert.Nil(t, apiErr.Data)
+	})
+
+	t.Run("按语言生成校验信息", func(t *testing.T) {
+		req := &batchReq{Name: "ok", Items: []item{{}, {Code: "a"}, {Code: "a"}}}
+		apiErr := pkgs.ValidateV2[batchReq](v)(req).Error().(*pkgs.ApiError)
+		assert.Equal(t, "编码为必填字段；编码存在重复值 a（下标 1, 2）", apiErr.Message, "默认使用中文")
+
+		message, data := apiErr.Localized(pkgs.LocaleEn)
+		assert.Equal(t, "编码 is a required field; 编码存在重复值 a（下标 1, 2）", message)
+		violations, ok := data.([]pkgs.Violation)
+		if assert.True(t, ok) && assert.Len(t, violations, 2) {
+			assert.Equal(t, "列表[0].编码", violations[0].Field)
+			assert.Equal(t, "编码 is a required field", violations[0].Message)
+		}
+	})
+}
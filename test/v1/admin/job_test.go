@@ -0,0 +1,101 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/pkgs"
+)
+
+// TestJobs 测试异步任务的查询、取消与重试
+// 包含四个子测试：取消等待执行的任务、重试已取消的任务、查询队列状态与任务列表、任务不存在
+func TestJobs(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"GET /v1/admin/jobs", "POST /v1/admin/jobs/:id/retry", "POST /v1/admin/jobs/:id/cancel"})
+
+	// 未注册的任务类型，执行时直接失败
+	jobType := "test.unregistered." + uuid.NewString()[:8]
+	var jobID string
+	require.NoError(t, testDB.Get(&jobID, `INSERT INTO async_job (type) VALUES ($1) RETURNING id`, jobType))
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM async_job WHERE type = $1`, jobType)
+		assert.NoError(t, err, "清理测试任务失败")
+	})
+
+	decodeJob := func(t *testing.T, resp pkgs.Response) admin.JobItem {
+		var job admin.JobItem
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &job))
+		return job
+	}
+
+	t.Run("取消等待执行的任务", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/admin/jobs/"+jobID+"/retry", token, nil)
+		assert.Equal(t, http.StatusConflict, resp.Code, "等待执行的任务不能重试")
+
+		resp = doRequest(t, http.MethodPost, "/v1/admin/jobs/"+jobID+"/cancel", token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		job := decodeJob(t, resp)
+		assert.Equal(t, pkgs.JobStatusCanceled, job.Status)
+		assert.NotNil(t, job.FinishedAt)
+
+		resp = doRequest(t, http.MethodPost, "/v1/admin/jobs/"+jobID+"/cancel", token, nil)
+		assert.Equal(t, http.StatusConflict, resp.Code, "已取消的任务不能再次取消")
+	})
+
+	t.Run("重试已取消的任务", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/admin/jobs/"+jobID+"/retry", token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		job := decodeJob(t, resp)
+		assert.Equal(t, pkgs.JobStatusPending, job.Status)
+		assert.Nil(t, job.FinishedAt)
+
+		_, err := testJobs.RunPending(context.Background(), "", testDB)
+		require.NoError(t, err)
+		var status string
+		require.NoError(t, testDB.Get(&status, `SELECT status FROM async_job WHERE id = $1`, jobID))
+		assert.Equal(t, pkgs.JobStatusFailed, status, "未注册的任务类型执行失败")
+
+		resp = doRequest(t, http.MethodPost, "/v1/admin/jobs/"+jobID+"/retry", token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		job = decodeJob(t, resp)
+		assert.Equal(t, pkgs.JobStatusPending, job.Status)
+		assert.Equal(t, job.Attempts+1, job.MaxAttempts, "用完执行次数的任务重试时额外允许执行一次")
+	})
+
+	t.Run("查询队列状态与任务列表", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/v1/admin/jobs?type="+jobType, token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		var res admin.QueryJobsRes
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &res))
+		assert.Equal(t, int64(1), res.Total)
+		require.Len(t, res.List, 1)
+		assert.Equal(t, jobID, res.List[0].ID)
+
+		var stats *pkgs.JobTypeStats
+		for i := range res.Stats {
+			if res.Stats[i].Type == jobType {
+				stats = &res.Stats[i]
+			}
+		}
+		require.NotNil(t, stats, "统计中应包含测试任务类型")
+		assert.Equal(t, int64(1), stats.Pending)
+		assert.NotNil(t, stats.OldestPendingSeconds)
+
+		resp = doRequest(t, http.MethodGet, "/v1/admin/jobs?status=unknown", token, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("任务不存在", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/admin/jobs/"+uuid.NewString()+"/cancel", token, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
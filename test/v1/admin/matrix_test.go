@@ -0,0 +1,82 @@
+package admin_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/pkgs"
+)
+
+// TestRolePermissionMatrix 测试角色权限矩阵导出
+// 包含三个子测试：JSON 格式、CSV 格式、格式参数错误
+func TestRolePermissionMatrix(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"GET /v1/admin/role-permission-matrix"})
+
+	r := tu.SetupTestRole()
+	allowed := tu.SetupTestPermission("GET /v1/matrix-test/allowed")
+	denied := tu.SetupTestPermission("GET /v1/matrix-test/denied")
+	tu.AssignPermissionToRole(r.ID, allowed.ID)
+	tu.DenyPermissionForRole(r.ID, denied.ID)
+
+	t.Run("JSON 格式", func(t *testing.T) {
+		// 逐页查询直到找到测试权限，页数由权限总数确定
+		rows := map[string]admin.MatrixRow{}
+		for page := 1; ; page++ {
+			resp := doRequest(t, http.MethodGet, fmt.Sprintf("/v1/admin/role-permission-matrix?page=%d&pageSize=100", page), token, nil)
+			require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+			var matrix admin.RolePermissionMatrixRes
+			raw, _ := json.Marshal(resp.Data)
+			require.NoError(t, json.Unmarshal(raw, &matrix))
+			if page == 1 {
+				assert.True(t, slices.ContainsFunc(matrix.Roles, func(role admin.MatrixRole) bool { return role.ID == r.ID }), "每页包含全部角色")
+			}
+			for _, row := range matrix.List {
+				rows[row.PermissionID] = row
+			}
+			if int64(page*100) >= matrix.Total {
+				break
+			}
+		}
+
+		require.Contains(t, rows, allowed.ID)
+		assert.Equal(t, "allow", rows[allowed.ID].Grants[r.ID])
+		assert.Equal(t, "/v1/matrix-test/allowed", rows[allowed.ID].Path)
+		require.Contains(t, rows, denied.ID)
+		assert.Equal(t, "deny", rows[denied.ID].Grants[r.ID])
+	})
+
+	t.Run("CSV 格式", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/admin/role-permission-matrix?format=csv&pageSize=100", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv"))
+		assert.NotEmpty(t, w.Header().Get("X-Total-Count"))
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.NotEmpty(t, records)
+		assert.Equal(t, []string{"permission_id", "permission_name", "type", "code", "method", "path"}, records[0][:6])
+		assert.Contains(t, records[0][6:], r.Name)
+		for _, record := range records[1:] {
+			assert.Len(t, record, len(records[0]))
+		}
+	})
+
+	t.Run("格式参数错误", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/v1/admin/role-permission-matrix?format=xml", token, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,82 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/pkgs"
+)
+
+// TestOffboard 测试离职交接
+// 包含三个子测试：交接人不能是本人、执行交接并生成报告、已停用的用户不能再次交接
+func TestOffboard(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"POST /v1/admin/offboard/:userId", "GET /v1/admin/offboard/:id"})
+
+	leaver := tu.SetupTestUser()
+	successor := tu.SetupTestUser()
+	role := tu.SetupTestRole()
+	tu.AssignRoleToUser(leaver.ID, role.ID)
+
+	var templateID string
+	require.NoError(t, testDB.Get(&templateID, `INSERT INTO template (name, owner_id) VALUES ('离职交接测试模板', $1) RETURNING id`, leaver.ID))
+	_, err := testDB.Exec(`INSERT INTO iacc_user_device (user_id, device_id) VALUES ($1, 'offboard-test-device')`, leaver.ID)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM template WHERE id = $1`, templateID)
+		assert.NoError(t, err, "清理测试模板失败")
+		_, err = testDB.Exec(`DELETE FROM iacc_offboarding WHERE user_id = $1`, leaver.ID)
+		assert.NoError(t, err, "清理离职交接记录失败")
+	})
+
+	t.Run("交接人不能是本人", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/admin/offboard/"+leaver.ID, token, map[string]any{"successor_id": leaver.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("执行交接并生成报告", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/admin/offboard/"+leaver.ID, token, map[string]any{"successor_id": successor.ID, "reason": "离职"})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		var submitted admin.OffboardRes
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &submitted))
+		require.NotEmpty(t, submitted.JobID)
+
+		// 任务执行前不能重复发起
+		resp = doRequest(t, http.MethodPost, "/v1/admin/offboard/"+leaver.ID, token, map[string]any{"successor_id": successor.ID})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		_, err := testJobs.RunPending(context.Background(), "", testDB)
+		require.NoError(t, err)
+
+		resp = doRequest(t, http.MethodGet, "/v1/admin/offboard/"+submitted.ID, token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		var record admin.GetOffboardingRes
+		raw, _ = json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &record))
+		assert.Equal(t, pkgs.JobStatusSucceeded, record.Status)
+		require.NotNil(t, record.Report)
+		assert.Equal(t, []admin.RevokedRole{{ID: role.ID, Name: role.Name}}, record.Report.RevokedRoles)
+		assert.Equal(t, int64(1), record.Report.TerminatedSessions)
+		assert.Equal(t, int64(1), record.Report.ReassignedTemplates)
+
+		var ownerID string
+		require.NoError(t, testDB.Get(&ownerID, `SELECT owner_id FROM template WHERE id = $1`, templateID))
+		assert.Equal(t, successor.ID, ownerID, "模板应转交给交接人")
+
+		// 停用后不能登录
+		resp = doRequest(t, http.MethodPost, "/v1/auth/login", "", map[string]any{"username": leaver.Username, "password": leaver.Password})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("已停用的用户不能再次交接", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/admin/offboard/"+leaver.ID, token, map[string]any{"successor_id": successor.ID})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+}
@@ -0,0 +1,43 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/pkgs"
+)
+
+// TestPermissionCache 测试权限缓存的状态与重建
+// 包含两个子测试：重建后立即重新编译接口权限记录、状态包含编译结果
+func TestPermissionCache(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"GET /v1/admin/permission-cache/status", "POST /v1/admin/permission-cache/rebuild"})
+
+	t.Run("重建后立即重新编译接口权限记录", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/admin/permission-cache/rebuild", token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		var res admin.RebuildPermissionCacheRes
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &res))
+		assert.True(t, res.Routes.Compiled)
+		assert.NotNil(t, res.Routes.CompiledAt)
+		assert.Positive(t, res.Routes.Count, "至少包含本测试授予的接口权限")
+		assert.False(t, res.Routes.Stale)
+	})
+
+	t.Run("状态包含编译结果", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/v1/admin/permission-cache/status", token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		var res admin.PermissionCacheStatusRes
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &res))
+		assert.True(t, res.Routes.Compiled)
+		assert.Positive(t, res.TTLSeconds)
+		assert.NotEmpty(t, res.Circuit)
+	})
+}
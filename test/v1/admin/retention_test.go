@@ -0,0 +1,150 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+	testJobs   *pkgs.JobQueue
+)
+
+// TestMain 初始化一次应用，复用数据库和路由
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	testJobs = a.Scheduler.Jobs
+	os.Exit(m.Run())
+}
+
+// doRequest 发送 JSON 请求并解析标准响应
+func doRequest(t *testing.T, method, path, token string, body any) pkgs.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// insertFinishedJob 写入一个已结束的异步任务，测试结束后删除
+func insertFinishedJob(t *testing.T, finishedAt time.Time) string {
+	t.Helper()
+	var id string
+	err := testDB.Get(&id, `INSERT INTO async_job (type, status, finished_at) VALUES ('retention_test', 'succeeded', $1) RETURNING id`, finishedAt)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM async_job WHERE id = $1`, id)
+		assert.NoError(t, err, "清理测试任务失败")
+	})
+	return id
+}
+
+// TestRetention 测试数据保留策略
+// 包含三个子测试：汇总即将清理的数据、修改策略、按策略清理过期数据
+func TestRetention(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"GET /v1/admin/retention", "PUT /v1/admin/retention/:category"})
+
+	// 测试结束后恢复默认策略
+	var original pkgs.RetentionPolicy
+	require.NoError(t, testDB.Get(&original, `SELECT id, created_at, updated_at, category, retain_days, enabled, last_run_at, last_purged FROM retention_policy WHERE category = 'async_job'`))
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`UPDATE retention_policy SET retain_days = $1, enabled = $2 WHERE id = $3`, original.RetainDays, original.Enabled, original.ID)
+		assert.NoError(t, err, "恢复保留策略失败")
+	})
+	_, err := testDB.Exec(`UPDATE retention_policy SET retain_days = 30, enabled = TRUE WHERE id = $1`, original.ID)
+	require.NoError(t, err)
+
+	expired := insertFinishedJob(t, time.Now().AddDate(0, 0, -31))
+	upcoming := insertFinishedJob(t, time.Now().AddDate(0, 0, -27))
+
+	t.Run("汇总即将清理的数据", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/v1/admin/retention?days=7", token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		var data struct {
+			List []pkgs.RetentionSummary `json:"list"`
+		}
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &data))
+		var found bool
+		for _, item := range data.List {
+			if item.Category != pkgs.RetentionAsyncJob {
+				continue
+			}
+			found = true
+			require.NotNil(t, item.Policy)
+			assert.Equal(t, 30, item.Policy.RetainDays)
+			assert.GreaterOrEqual(t, item.Expired, int64(1))
+			assert.GreaterOrEqual(t, item.Upcoming, int64(1))
+		}
+		assert.True(t, found, "应包含 async_job 类别")
+	})
+
+	t.Run("修改策略", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPut, "/v1/admin/retention/async_job", token, map[string]any{"retain_days": 60, "enabled": true})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		var days int
+		require.NoError(t, testDB.Get(&days, `SELECT retain_days FROM retention_policy WHERE id = $1`, original.ID))
+		assert.Equal(t, 60, days)
+
+		resp = doRequest(t, http.MethodPut, "/v1/admin/retention/unknown", token, map[string]any{"retain_days": 60, "enabled": true})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		resp = doRequest(t, http.MethodPut, "/v1/admin/retention/async_job", token, map[string]any{"retain_days": 0, "enabled": true})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("按策略清理过期数据", func(t *testing.T) {
+		_, err := testDB.Exec(`UPDATE retention_policy SET retain_days = 30 WHERE id = $1`, original.ID)
+		require.NoError(t, err)
+
+		config, err := pkgs.NewConfig()
+		require.NoError(t, err)
+		retention := pkgs.NewRetention(nil, pkgs.NewTableNames(config), zap.NewNop())
+		require.NoError(t, retention.Enforce(context.Background(), testDB))
+
+		var count int
+		require.NoError(t, testDB.Get(&count, `SELECT COUNT(*) FROM async_job WHERE id = $1`, expired))
+		assert.Equal(t, 0, count, "过期任务应被清理")
+		require.NoError(t, testDB.Get(&count, `SELECT COUNT(*) FROM async_job WHERE id = $1`, upcoming))
+		assert.Equal(t, 1, count, "未过期任务应保留")
+
+		var policy pkgs.RetentionPolicy
+		require.NoError(t, testDB.Get(&policy, `SELECT id, created_at, updated_at, category, retain_days, enabled, last_run_at, last_purged FROM retention_policy WHERE id = $1`, original.ID))
+		assert.NotNil(t, policy.LastRunAt)
+		assert.GreaterOrEqual(t, policy.LastPurged, int64(1))
+	})
+}
@@ -0,0 +1,107 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/pkgs"
+)
+
+// TestSnapshot 测试环境快照
+// 包含四个子测试：导出不含凭据、合并恢复、格式校验、密钥指纹不一致
+func TestSnapshot(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"GET /v1/admin/snapshot", "POST /v1/admin/snapshot/restore"})
+
+	// 创建一个角色，测试结束后删除
+	roleName := "snapshot_" + uuid.NewString()[:8]
+	var roleID string
+	require.NoError(t, testDB.Get(&roleID, `INSERT INTO iacc_role (name, description) VALUES ($1, 'before') RETURNING id`, roleName))
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM iacc_role WHERE id = $1`, roleID)
+		assert.NoError(t, err, "清理测试角色失败")
+	})
+
+	var snapshot admin.Snapshot
+	tables := map[string][]map[string]any{}
+
+	t.Run("导出不含凭据", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, "/v1/admin/snapshot", token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		data, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(data, &snapshot))
+		assert.Equal(t, admin.SnapshotFormat, snapshot.Format)
+
+		for _, table := range snapshot.Tables {
+			var rows []map[string]any
+			require.NoError(t, json.Unmarshal(table.Rows, &rows))
+			tables[table.Name] = rows
+		}
+		assert.NotContains(t, tables, "api_key", "快照不应包含 API 密钥")
+		assert.NotContains(t, tables, "iacc_user_device", "快照不应包含登录设备")
+		for _, user := range tables["iacc_user"] {
+			assert.NotContains(t, user, "password", "快照不应包含密码")
+			assert.NotContains(t, user, "seq", "快照不应包含自增列")
+		}
+	})
+
+	t.Run("合并恢复", func(t *testing.T) {
+		var role map[string]any
+		for _, row := range tables["iacc_role"] {
+			if row["id"] == roleID {
+				role = row
+			}
+		}
+		require.NotNil(t, role, "快照应包含测试角色")
+
+		_, err := testDB.Exec(`UPDATE iacc_role SET description = 'after' WHERE id = $1`, roleID)
+		require.NoError(t, err)
+
+		// 只恢复测试角色，避免影响其他数据
+		rows, _ := json.Marshal([]map[string]any{role})
+		req := admin.RestoreSnapshotReq{Snapshot: admin.Snapshot{
+			Format:         snapshot.Format,
+			Version:        snapshot.Version,
+			KeyFingerprint: snapshot.KeyFingerprint,
+			Tables:         []admin.SnapshotTable{{Name: "iacc_role", Rows: rows}},
+		}}
+		resp := doRequest(t, http.MethodPost, "/v1/admin/snapshot/restore", token, req)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		var description string
+		require.NoError(t, testDB.Get(&description, `SELECT description FROM iacc_role WHERE id = $1`, roleID))
+		assert.Equal(t, "before", description, "恢复后应为快照中的数据")
+	})
+
+	t.Run("格式校验", func(t *testing.T) {
+		req := admin.RestoreSnapshotReq{Snapshot: admin.Snapshot{
+			Format:  snapshot.Format,
+			Version: snapshot.Version,
+			Tables:  []admin.SnapshotTable{{Name: "api_key", Rows: json.RawMessage(`[]`)}},
+		}}
+		resp := doRequest(t, http.MethodPost, "/v1/admin/snapshot/restore", token, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "不支持的数据表应返回 400")
+
+		req.Snapshot.Tables = nil
+		req.Snapshot.Format = "other"
+		resp = doRequest(t, http.MethodPost, "/v1/admin/snapshot/restore", token, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "不支持的格式应返回 400")
+	})
+
+	t.Run("密钥指纹不一致", func(t *testing.T) {
+		req := admin.RestoreSnapshotReq{Snapshot: admin.Snapshot{
+			Format:         snapshot.Format,
+			Version:        snapshot.Version,
+			KeyFingerprint: "0000000000000000",
+			Tables:         []admin.SnapshotTable{},
+		}}
+		resp := doRequest(t, http.MethodPost, "/v1/admin/snapshot/restore", token, req)
+		assert.Equal(t, http.StatusConflict, resp.Code, "密钥指纹不一致应返回 409")
+	})
+}
@@ -0,0 +1,101 @@
+package admin_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/admin"
+	"go-pg-demo/pkgs"
+)
+
+// TestSupportBundle 测试下载支持包
+// 检查 zip 包含约定的文件、配置已脱敏、迁移版本与健康检查结果
+func TestSupportBundle(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"GET /v1/admin/support-bundle"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/admin/support-bundle", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "support-bundle-")
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+	}
+
+	var manifest admin.SupportBundleManifest
+	require.NoError(t, json.Unmarshal(files[admin.SupportBundleManifestFile], &manifest))
+	for _, name := range manifest.Files {
+		assert.Contains(t, files, name, "manifest 列出的文件都在包中")
+	}
+
+	var config map[string]map[string]any
+	require.NoError(t, json.Unmarshal(files[admin.SupportBundleConfigFile], &config))
+	assert.Equal(t, pkgs.Redacted, config["database"]["password"], "数据库密码已脱敏")
+	assert.Equal(t, pkgs.Redacted, config["jwt"]["secret"], "JWT 密钥已脱敏")
+
+	var migrations admin.SupportBundleMigrations
+	require.NoError(t, json.Unmarshal(files[admin.SupportBundleMigrationsFile], &migrations))
+	require.NotEmpty(t, migrations.Schemas)
+	assert.Equal(t, migrations.LatestVersion, migrations.Schemas[0].Version, "测试数据库已迁移到最新版本")
+
+	var probes []admin.SupportBundleProbe
+	require.NoError(t, json.Unmarshal(files[admin.SupportBundleHealthFile], &probes))
+	for _, probe := range probes {
+		if probe.Name == "database" {
+			assert.True(t, probe.OK, probe.Error)
+		}
+	}
+
+	var pools []admin.SupportBundlePool
+	require.NoError(t, json.Unmarshal(files[admin.SupportBundlePoolsFile], &pools))
+	require.NotEmpty(t, pools)
+	assert.Equal(t, "default", pools[0].Name)
+}
+
+// TestReadiness 测试就绪检查
+// 主库与分析库分别报告，测试环境未配置分析库时该项正常
+func TestReadiness(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"GET /v1/admin/readiness"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/admin/readiness", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Code int                `json:"code"`
+		Data admin.ReadinessRes `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, resp.Data.Ready)
+	checks := map[string]admin.SupportBundleProbe{}
+	for _, check := range resp.Data.Checks {
+		checks[check.Name] = check
+	}
+	require.Contains(t, checks, "database")
+	require.Contains(t, checks, "reporting_database")
+	assert.True(t, checks["database"].OK, checks["database"].Error)
+	assert.True(t, checks["reporting_database"].OK, checks["reporting_database"].Error)
+}
@@ -0,0 +1,108 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/audit"
+	"go-pg-demo/pkgs"
+)
+
+// listAudit 查询审计日志列表
+func listAudit(t *testing.T, token, query string) audit.ListRes {
+	t.Helper()
+	resp := parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/list?"+query, token, nil))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	raw, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	var res audit.ListRes
+	require.NoError(t, json.Unmarshal(raw, &res))
+	return res
+}
+
+// auditDetail 解析审计记录的详情
+func auditDetail(t *testing.T, item audit.AuditItem) map[string]any {
+	t.Helper()
+	var detail map[string]any
+	require.NoError(t, json.Unmarshal(item.Detail, &detail))
+	return detail
+}
+
+// TestAuditList 测试审计日志列表
+// 包含四个子测试：修改用户记录变化的字段与请求路由、删除角色记录删除前的状态、游标分页、参数校验
+func TestAuditList(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	actor, token := tu.SetupUserWithPermissions([]string{"GET /v1/audit/list", "PATCH /v1/user/:id", "DELETE /v1/role/:id"})
+	target := tu.SetupTestUser()
+	role := tu.SetupTestRole()
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM audit_log WHERE entity_id = ANY($1)`, pkgs.PGArray([]string{target.ID, role.ID}))
+		assert.NoError(t, err, "清理审计日志失败")
+	})
+
+	t.Run("修改用户记录变化的字段与请求路由", func(t *testing.T) {
+		username := target.Username + "_x"
+		resp := parseResponse(t, doRequest(t, http.MethodPatch, "/v1/user/"+target.ID, token, map[string]any{"username": username, "password": "anotherpassword"}))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		res := listAudit(t, token, "entity=user&entityId="+target.ID)
+		require.Len(t, res.List, 1)
+		assert.Equal(t, int64(1), res.Total)
+		item := res.List[0]
+		assert.Equal(t, "patch", item.Action)
+		assert.Equal(t, actor.ID, *item.ActorID)
+		assert.Equal(t, actor.Username, *item.ActorUsername)
+		assert.Equal(t, http.MethodPatch, *item.Method)
+		assert.Equal(t, "/v1/user/:id", *item.Path)
+
+		changes, ok := auditDetail(t, item)["changes"].(map[string]any)
+		require.True(t, ok, "修改记录操作前后的变化")
+		assert.Equal(t, map[string]any{"before": target.Username, "after": username}, changes["username"])
+		assert.NotContains(t, changes, "password", "不记录密码")
+		assert.NotContains(t, changes, "updated_at", "不记录更新时间")
+
+		res = listAudit(t, token, "entity=user&entityId="+target.ID+"&method=DELETE")
+		assert.Empty(t, res.List, "按请求方法筛选")
+	})
+
+	t.Run("删除角色记录删除前的状态", func(t *testing.T) {
+		resp := parseResponse(t, doRequest(t, http.MethodDelete, "/v1/role/"+role.ID, token, nil))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		res := listAudit(t, token, "entity=role&entityId="+role.ID+"&action=delete")
+		require.Len(t, res.List, 1)
+		detail := auditDetail(t, res.List[0])
+		before, ok := detail["before"].(map[string]any)
+		require.True(t, ok, "删除记录删除前的状态")
+		assert.Equal(t, role.Name, before["name"])
+		assert.NotContains(t, detail, "changes")
+	})
+
+	t.Run("游标分页", func(t *testing.T) {
+		first := listAudit(t, token, "actorId="+actor.ID+"&limit=1&order=asc")
+		require.Len(t, first.List, 1)
+		assert.Zero(t, first.Total, "游标分页不统计总数")
+		require.NotEmpty(t, first.NextCursor)
+		assert.Equal(t, "patch", first.List[0].Action)
+
+		second := listAudit(t, token, "actorId="+actor.ID+"&limit=1&order=asc&cursor="+first.NextCursor)
+		require.Len(t, second.List, 1)
+		assert.Equal(t, "delete", second.List[0].Action)
+		assert.Empty(t, second.NextCursor, "没有下一页")
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		resp := parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/list?method=GET", token, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "只记录修改类请求")
+
+		resp = parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/list?createdFrom=2025-02-01&createdTo=2025-01-01", token, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "起始晚于截止")
+
+		resp = parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/list?cursor=invalid", token, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "游标无效")
+	})
+}
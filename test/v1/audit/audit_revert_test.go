@@ -0,0 +1,106 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/audit"
+	"go-pg-demo/pkgs"
+)
+
+// revertAudit 撤销审计记录，返回响应
+func revertAudit(t *testing.T, token, id string) pkgs.Response {
+	t.Helper()
+	return parseResponse(t, doRequest(t, http.MethodPost, "/v1/audit/"+id+"/revert", token, nil))
+}
+
+// latestAudit 返回实体最近一条指定操作的审计记录ID
+func latestAudit(t *testing.T, token, entity, entityID, action string) string {
+	t.Helper()
+	res := listAudit(t, token, "entity="+entity+"&entityId="+entityID+"&action="+action+"&limit=1")
+	require.Len(t, res.List, 1)
+	return res.List[0].ID
+}
+
+// TestAuditRevert 测试撤销审计记录对应的操作
+// 包含四个子测试：撤销角色权限同步、重复撤销、之后被修改的用户角色分配不能撤销、参数校验
+func TestAuditRevert(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{
+		"POST /v1/audit/:id/revert", "GET /v1/audit/list", "PUT /v1/role/:id/permission/sync", "POST /v1/user/:id/role",
+	})
+	role := tu.SetupTestRole()
+	target := tu.SetupTestUser()
+	kept := tu.SetupTestPermission("GET /v1/audit/revert-kept")
+	synced := tu.SetupTestPermission("GET /v1/audit/revert-synced")
+	tu.AssignPermissionToRole(role.ID, kept.ID)
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM audit_log WHERE entity_id = ANY($1)`, pkgs.PGArray([]string{role.ID, target.ID}))
+		assert.NoError(t, err, "清理审计日志失败")
+	})
+
+	var syncID string
+	t.Run("撤销角色权限同步", func(t *testing.T) {
+		resp := parseResponse(t, doRequest(t, http.MethodPut, "/v1/role/"+role.ID+"/permission/sync", token, map[string]any{"permission_ids": []string{synced.ID}}))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		syncID = latestAudit(t, token, pkgs.AuditEntityRole, role.ID, "sync_permissions")
+
+		resp = revertAudit(t, token, syncID)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		raw, err := json.Marshal(resp.Data)
+		require.NoError(t, err)
+		var res audit.RevertRes
+		require.NoError(t, json.Unmarshal(raw, &res))
+		assert.Equal(t, syncID, res.RevertedID)
+		assert.Equal(t, role.ID, res.EntityID)
+		assert.Contains(t, res.Changes, "permissions")
+
+		var permissionIDs []string
+		require.NoError(t, testDB.Select(&permissionIDs, `SELECT permission_id FROM iacc_role_permission WHERE role_id = $1`, role.ID))
+		assert.Equal(t, []string{kept.ID}, permissionIDs, "恢复为同步前的权限")
+
+		list := listAudit(t, token, "entity=role&entityId="+role.ID+"&action="+audit.ActionRevert)
+		require.Len(t, list.List, 1)
+		assert.Equal(t, res.AuditID, list.List[0].ID)
+		assert.Equal(t, syncID, auditDetail(t, list.List[0])["reverted_id"])
+	})
+
+	t.Run("重复撤销", func(t *testing.T) {
+		require.NotEmpty(t, syncID)
+		resp := revertAudit(t, token, syncID)
+		assert.Equal(t, http.StatusConflict, resp.Code, "每条记录只能撤销一次")
+	})
+
+	t.Run("之后被修改的用户角色分配不能撤销", func(t *testing.T) {
+		first, second := tu.SetupTestRole(), tu.SetupTestRole()
+		resp := parseResponse(t, doRequest(t, http.MethodPost, "/v1/user/"+target.ID+"/role", token, map[string]any{"role_ids": []string{first.ID}}))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		firstID := latestAudit(t, token, pkgs.AuditEntityUser, target.ID, "assign_roles")
+		resp = parseResponse(t, doRequest(t, http.MethodPost, "/v1/user/"+target.ID+"/role", token, map[string]any{"role_ids": []string{second.ID}}))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		secondID := latestAudit(t, token, pkgs.AuditEntityUser, target.ID, "assign_roles")
+		require.NotEqual(t, firstID, secondID)
+
+		resp = revertAudit(t, token, firstID)
+		assert.Equal(t, http.StatusConflict, resp.Code, "用户的角色在此之后已被修改")
+
+		resp = revertAudit(t, token, secondID)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		var roleIDs []string
+		require.NoError(t, testDB.Select(&roleIDs, `SELECT role_id FROM iacc_user_role WHERE user_id = $1`, target.ID))
+		assert.Equal(t, []string{first.ID}, roleIDs, "恢复为最近一次分配前的角色")
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		resp := revertAudit(t, token, "invalid")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = revertAudit(t, token, uuid.NewString())
+		assert.Equal(t, http.StatusNotFound, resp.Code, "审计记录不存在")
+	})
+}
@@ -0,0 +1,171 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+)
+
+// 审计导出接口的全部权限，以及产生审计记录、保存预设所需的权限
+var auditPermissions = []string{
+	"GET /v1/audit/export", "GET /v1/audit/export/:id", "GET /v1/audit/export/:id/download",
+	"POST /v1/role/:id/permission", "POST /v1/views",
+}
+
+// TestMain 初始化一次应用，复用数据库和路由
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	os.Exit(m.Run())
+}
+
+// doRequest 发送 JSON 请求，返回原始响应
+func doRequest(t *testing.T, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	return w
+}
+
+// parseResponse 解析标准响应
+func parseResponse(t *testing.T, w *httptest.ResponseRecorder) pkgs.Response {
+	t.Helper()
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return resp
+}
+
+// exportCSV 导出审计日志并解析 CSV，返回除表头外的各行（按列名取值）
+func exportCSV(t *testing.T, token, query string) []map[string]string {
+	t.Helper()
+	w := doRequest(t, http.MethodGet, "/v1/audit/export?"+query, token, nil)
+	require.Contains(t, w.Header().Get("Content-Type"), "text/csv", w.Body.String())
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.NotEmpty(t, records, "至少包含表头")
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for i, column := range records[0] {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// TestAuditExport 测试审计日志的写入与导出
+// 包含四个子测试：角色分配权限写入审计日志并导出、筛选预设、参数校验、异步导出任务只能由发起人查询
+func TestAuditExport(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	actor, token := tu.SetupUserWithPermissions(auditPermissions)
+	role := tu.SetupTestRole()
+	perm := tu.SetupTestPermission("GET /v1/audit-test/" + uuid.NewString()[:8])
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM audit_log WHERE entity_id = $1`, role.ID)
+		assert.NoError(t, err, "清理审计日志失败")
+	})
+
+	resp := parseResponse(t, doRequest(t, http.MethodPost, "/v1/role/"+role.ID+"/permission", token, map[string]any{"permission_ids": []string{perm.ID}}))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+	t.Run("角色分配权限写入审计日志并导出", func(t *testing.T) {
+		rows := exportCSV(t, token, "days=1&entity=role&entityId="+role.ID)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "assign_permissions", rows[0]["action"])
+		assert.Equal(t, actor.ID, rows[0]["actor_id"])
+		assert.Equal(t, actor.Username, rows[0]["actor_username"])
+
+		rows = exportCSV(t, token, "days=1&entity=role&entityId="+role.ID+"&action=delete")
+		assert.Empty(t, rows, "按操作筛选")
+	})
+
+	t.Run("筛选预设", func(t *testing.T) {
+		resp := parseResponse(t, doRequest(t, http.MethodPost, "/v1/views", token, map[string]any{
+			"entity": "audit",
+			"name":   "role-" + role.ID[:8],
+			"config": map[string]any{"filters": map[string]string{"entity": "role", "entityId": role.ID, "days": "1"}},
+		}))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		viewID := resp.Data.(string)
+
+		rows := exportCSV(t, token, "viewId="+viewID)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "assign_permissions", rows[0]["action"])
+
+		// 请求参数优先于预设
+		rows = exportCSV(t, token, "viewId="+viewID+"&action=delete")
+		assert.Empty(t, rows)
+
+		// 其他用户看不到未共享的预设
+		_, otherToken := tu.SetupUserWithPermissions([]string{"GET /v1/audit/export"})
+		resp = parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/export?viewId="+viewID, otherToken, nil))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		resp := parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/export?entity=role", token, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "缺少时间范围")
+
+		resp = parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/export?days=7&createdFrom=2025-01-01", token, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "最近天数与时间起止同时使用")
+
+		resp = parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/export?createdFrom=2025-02-01&createdTo=2025-01-01", token, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "起始晚于截止")
+	})
+
+	t.Run("异步导出任务只能由发起人查询", func(t *testing.T) {
+		// 直接写入一个尚未到执行时间的导出任务，避免被后台任务执行
+		jobID := uuid.NewString()
+		_, err := testDB.Exec(`INSERT INTO async_job (id, type, payload, run_at) VALUES ($1, 'audit.export', $2, now() + interval '1 day')`, jobID, `{"requested_by":"`+uuid.NewString()+`"}`)
+		require.NoError(t, err)
+		t.Cleanup(func() { _, _ = testDB.Exec(`DELETE FROM async_job WHERE id = $1`, jobID) })
+
+		resp := parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/export/"+jobID, token, nil))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		resp = parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/export/"+jobID+"/download", token, nil))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		_, err = testDB.Exec(`UPDATE async_job SET payload = $2 WHERE id = $1`, jobID, `{"requested_by":"`+actor.ID+`"}`)
+		require.NoError(t, err)
+		resp = parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/export/"+jobID, token, nil))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		data := resp.Data.(map[string]any)
+		assert.Equal(t, pkgs.JobStatusPending, data["status"])
+		assert.Equal(t, false, data["ready"])
+		resp = parseResponse(t, doRequest(t, http.MethodGet, "/v1/audit/export/"+jobID+"/download", token, nil))
+		assert.Equal(t, http.StatusConflict, resp.Code, "任务尚未完成")
+	})
+}
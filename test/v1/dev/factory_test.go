@@ -0,0 +1,116 @@
+package dev_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+	maxCount   int
+)
+
+// TestMain 初始化一次应用，复用数据库和路由
+// 配置文件中默认关闭开发与测试接口，这里开启后重新注册其路由
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	maxCount = a.Conf.DevFactory.MaxCount
+	a.Conf.DevFactory.Enabled = true
+	a.Conf.DevToken.Enabled = true
+	a.V1Router.RegisterDev()
+	os.Exit(m.Run())
+}
+
+// doRequest 发送 JSON 请求并解析标准响应
+func doRequest(t *testing.T, method, path, token string, body any) pkgs.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// TestFactory 测试批量生成测试数据
+// 包含两个子测试：生成用户角色模板、数量校验
+func TestFactory(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"POST /v1/dev/factory"})
+
+	t.Run("生成用户角色模板", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/dev/factory", token, map[string]any{"users": 5, "roles": 2, "templates": 3})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		var data struct {
+			Batch     string   `json:"batch"`
+			Password  string   `json:"password"`
+			Users     []string `json:"users"`
+			Roles     []string `json:"roles"`
+			Templates []string `json:"templates"`
+		}
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &data))
+		t.Cleanup(func() {
+			_, err := testDB.Exec(`DELETE FROM template WHERE id = ANY($1::uuid[])`, pq.Array(data.Templates))
+			assert.NoError(t, err, "清理测试模板失败")
+			_, err = testDB.Exec(`DELETE FROM iacc_user WHERE id = ANY($1::uuid[])`, pq.Array(data.Users))
+			assert.NoError(t, err, "清理测试用户失败")
+			_, err = testDB.Exec(`DELETE FROM iacc_role WHERE id = ANY($1::uuid[])`, pq.Array(data.Roles))
+			assert.NoError(t, err, "清理测试角色失败")
+		})
+
+		assert.NotEmpty(t, data.Batch)
+		assert.Equal(t, "Passw0rd!", data.Password)
+		assert.Len(t, data.Users, 5)
+		assert.Len(t, data.Roles, 2)
+		assert.Len(t, data.Templates, 3)
+
+		// 每个用户分配了一个本批次的角色
+		var assigned int
+		require.NoError(t, testDB.Get(&assigned, `SELECT COUNT(*) FROM iacc_user_role WHERE user_id = ANY($1::uuid[]) AND role_id = ANY($2::uuid[])`, pq.Array(data.Users), pq.Array(data.Roles)))
+		assert.Equal(t, 5, assigned)
+
+		// 生成的用户可以使用返回的密码登录
+		var username string
+		require.NoError(t, testDB.Get(&username, `SELECT username FROM iacc_user WHERE id = $1`, data.Users[0]))
+		resp = doRequest(t, http.MethodPost, "/v1/auth/login", "", map[string]any{"username": username, "password": data.Password})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("数量校验", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/dev/factory", token, map[string]any{})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = doRequest(t, http.MethodPost, "/v1/dev/factory", token, map[string]any{"users": maxCount + 1})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,90 @@
+package dev_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// mintToken 签发测试令牌并解析响应
+func mintToken(t *testing.T, token string, body map[string]any) (pkgs.Response, map[string]any) {
+	t.Helper()
+	resp := doRequest(t, http.MethodPost, "/v1/dev/token", token, body)
+	data := map[string]any{}
+	if resp.Data != nil {
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &data))
+	}
+	return resp, data
+}
+
+// TestMintToken 测试签发测试令牌
+// 包含三个子测试：按用户签发、按权限集合签发并复用测试用户、参数校验
+func TestMintToken(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"POST /v1/dev/token"})
+
+	t.Run("按用户签发", func(t *testing.T) {
+		u := tu.SetupTestUser()
+		resp, data := mintToken(t, token, map[string]any{"username": u.Username, "expires_in": 600})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, u.ID, data["user_id"])
+		assert.EqualValues(t, 600, data["expires_in"])
+
+		// 签发的令牌可以直接访问接口
+		me := doRequest(t, http.MethodGet, "/v1/auth/user-detail", data["access_token"].(string), nil)
+		assert.NotEqual(t, http.StatusUnauthorized, me.Code, me.Msg)
+
+		resp, _ = mintToken(t, token, map[string]any{"user_id": uuid.NewString()})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("按权限集合签发并复用测试用户", func(t *testing.T) {
+		path := "/v1/dev-token-test/" + uuid.NewString()[:8]
+		permissions := []string{"GET " + path, "POST " + path}
+		resp, first := mintToken(t, token, map[string]any{"permissions": permissions})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		t.Cleanup(func() {
+			username := first["username"].(string)
+			_, err := testDB.Exec(`DELETE FROM iacc_user WHERE username = $1`, username)
+			assert.NoError(t, err, "清理测试用户失败")
+			_, err = testDB.Exec(`DELETE FROM iacc_role WHERE name = $1`, username)
+			assert.NoError(t, err, "清理测试角色失败")
+			_, err = testDB.Exec(`DELETE FROM iacc_permission WHERE metadata->>'path' = $1`, path)
+			assert.NoError(t, err, "清理测试权限失败")
+		})
+
+		// 权限顺序不同的同一集合复用同一用户
+		resp, second := mintToken(t, token, map[string]any{"permissions": []string{permissions[1], permissions[0]}})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, first["user_id"], second["user_id"])
+
+		var granted int
+		query := `SELECT COUNT(*) FROM iacc_user_role ur
+			JOIN iacc_role_permission rp ON rp.role_id = ur.role_id
+			JOIN iacc_permission p ON p.id = rp.permission_id
+			WHERE ur.user_id = $1 AND p.metadata->>'path' = $2`
+		require.NoError(t, testDB.Get(&granted, query, first["user_id"], path))
+		assert.Equal(t, 2, granted)
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		resp, _ := mintToken(t, token, map[string]any{})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "必须指定用户或权限集合")
+
+		resp, _ = mintToken(t, token, map[string]any{"username": "someone", "permissions": []string{"GET /v1/user/list"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "不能同时指定用户和权限集合")
+
+		resp, _ = mintToken(t, token, map[string]any{"permissions": []string{"get /v1/user/list"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "请求方法应为大写")
+
+		resp, _ = mintToken(t, token, map[string]any{"username": "someone", "expires_in": 10 * 24 * 3600})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "有效期不能超过上限")
+	})
+}
@@ -0,0 +1,172 @@
+package attribute_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+)
+
+// TestMain 初始化一次应用，复用数据库和路由
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	os.Exit(m.Run())
+}
+
+// doRequest 发送请求并解析标准响应，PATCH 请求使用 Merge Patch 内容类型
+func doRequest(t *testing.T, method, path, token string, body any) pkgs.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if method == http.MethodPatch {
+		req.Header.Set("Content-Type", pkgs.MergePatchContentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// createAttribute 通过接口创建属性定义，测试结束后删除；属性键带随机后缀，避免影响其他测试
+func createAttribute(t *testing.T, token string, body map[string]any) string {
+	t.Helper()
+	resp := doRequest(t, http.MethodPost, "/v1/attribute", token, body)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	id := resp.Data.(string)
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM iacc_attribute WHERE id = $1`, id)
+		assert.NoError(t, err, "清理测试属性失败")
+	})
+	return id
+}
+
+// createRole 通过接口创建角色，测试结束后删除
+func createRole(t *testing.T, token string, attributes map[string]any) pkgs.Response {
+	t.Helper()
+	resp := doRequest(t, http.MethodPost, "/v1/role", token, map[string]any{"name": "attr_role_" + uuid.NewString()[:8], "attributes": attributes})
+	if id, ok := resp.Data.(string); ok {
+		t.Cleanup(func() {
+			_, err := testDB.Exec(`DELETE FROM iacc_role WHERE id = $1`, id)
+			assert.NoError(t, err, "清理测试角色失败")
+		})
+	}
+	return resp
+}
+
+// TestRoleAttributes 测试角色自定义属性的定义、校验、筛选与维护
+// 包含四个子测试：按定义校验属性值、按属性筛选列表、合并更新与修改定义、删除定义与必填属性
+func TestRoleAttributes(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{
+		"POST /v1/attribute", "PUT /v1/attribute/:id", "DELETE /v1/attribute/:id", "GET /v1/attribute/list",
+		"POST /v1/role", "GET /v1/role/:id", "PATCH /v1/role/:id", "GET /v1/role/list",
+	})
+	team := "team_" + uuid.NewString()[:8]
+	cost := "cost_" + uuid.NewString()[:8]
+	teamID := createAttribute(t, token, map[string]any{"entity": "role", "key": team, "type": "enum", "options": []string{"infra", "sales"}})
+	createAttribute(t, token, map[string]any{"entity": "role", "key": cost, "type": "number"})
+
+	t.Run("按定义校验属性值", func(t *testing.T) {
+		resp := createRole(t, token, map[string]any{team: "infra", cost: 100})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		resp = doRequest(t, http.MethodGet, "/v1/role/"+resp.Data.(string), token, nil)
+		assert.Equal(t, map[string]any{team: "infra", cost: float64(100)}, resp.Data.(map[string]any)["attributes"])
+
+		for _, attributes := range []map[string]any{
+			{team: "finance"},
+			{cost: "100"},
+			{"undefined_key": "x"},
+		} {
+			resp = createRole(t, token, attributes)
+			assert.Equal(t, http.StatusBadRequest, resp.Code, "%v", attributes)
+			assert.NotEmpty(t, resp.Data, "违规明细放在 data 中")
+		}
+
+		resp = doRequest(t, http.MethodPost, "/v1/attribute", token, map[string]any{"entity": "role", "key": "Bad-Key", "type": "enum"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "属性键格式错误且 enum 缺少可选值")
+	})
+
+	t.Run("按属性筛选列表", func(t *testing.T) {
+		resp := createRole(t, token, map[string]any{team: "sales", cost: 7})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		id := resp.Data.(string)
+		createRole(t, token, map[string]any{team: "infra"})
+
+		query := url.Values{"attr": {team + ":sales", cost + ":7"}, "pageSize": {"100"}}
+		resp = doRequest(t, http.MethodGet, "/v1/role/list?"+query.Encode(), token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		list := resp.Data.(map[string]any)["list"].([]any)
+		require.Len(t, list, 1)
+		assert.Equal(t, id, list[0].(map[string]any)["id"])
+
+		resp = doRequest(t, http.MethodGet, "/v1/role/list?attr=missing_colon", token, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("合并更新与修改定义", func(t *testing.T) {
+		resp := createRole(t, token, map[string]any{team: "infra", cost: 1})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		id := resp.Data.(string)
+
+		resp = doRequest(t, http.MethodPatch, "/v1/role/"+id, token, map[string]any{"attributes": map[string]any{cost: nil}})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		resp = doRequest(t, http.MethodGet, "/v1/role/"+id, token, nil)
+		assert.Equal(t, map[string]any{team: "infra"}, resp.Data.(map[string]any)["attributes"], "null 删除属性，其他属性保留")
+
+		resp = doRequest(t, http.MethodPut, "/v1/attribute/"+teamID, token, map[string]any{"options": []string{"sales"}})
+		assert.Equal(t, http.StatusConflict, resp.Code, "已有角色使用 infra，不能移除")
+		resp = doRequest(t, http.MethodPut, "/v1/attribute/"+teamID, token, map[string]any{"options": []string{"infra", "sales", "ops"}})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("删除定义与必填属性", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/attribute", token, map[string]any{"entity": "role", "key": "req_" + uuid.NewString()[:8], "type": "string", "required": true})
+		assert.Equal(t, http.StatusConflict, resp.Code, "已有角色时不能直接创建必填属性")
+
+		key := "tmp_" + uuid.NewString()[:8]
+		id := createAttribute(t, token, map[string]any{"entity": "role", "key": key, "type": "boolean"})
+		resp = createRole(t, token, map[string]any{key: true})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		roleID := resp.Data.(string)
+
+		resp = doRequest(t, http.MethodPut, "/v1/attribute/"+id, token, map[string]any{"required": true})
+		assert.Equal(t, http.StatusConflict, resp.Code, "其他角色没有设置该属性，不能设为必填")
+
+		resp = doRequest(t, http.MethodDelete, "/v1/attribute/"+id, token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var exists bool
+		require.NoError(t, testDB.Get(&exists, `SELECT jsonb_exists(attributes, $2) FROM iacc_role WHERE id = $1`, roleID, key))
+		assert.False(t, exists, "删除定义时移除角色上的属性值")
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 
 	"go-pg-demo/internal/app"
 	"go-pg-demo/pkgs"
@@ -23,6 +24,7 @@ import (
 var (
 	testDB     *sqlx.DB
 	testLogger *zap.Logger
+	testConf   *pkgs.Config
 	testRouter *gin.Engine
 )
 
@@ -35,6 +37,7 @@ func TestMain(m *testing.M) {
 	}
 	testDB = a.DB
 	testLogger = a.Logger
+	testConf = a.Conf
 	testRouter = a.Server
 	code := m.Run()
 	os.Exit(code)
@@ -120,6 +123,66 @@ func TestAuthLogin(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, resp.Code, "应返回401业务码")
 		assert.Equal(t, "用户名或密码错误", resp.Msg)
 	})
+
+	t.Run("旧参数的密码哈希在登录后升级", func(t *testing.T) {
+		cost := testConf.PasswordHash.BcryptCost
+		if testConf.PasswordHash.Algorithm != pkgs.PasswordAlgorithmBcrypt || cost == bcrypt.MinCost {
+			t.Skip("当前配置无法构造旧参数的哈希")
+		}
+		util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		u := util.SetupTestUser()
+		oldHash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.MinCost)
+		assert.NoError(t, err)
+		_, err = testDB.Exec(`UPDATE "iacc_user" SET password = $1 WHERE id = $2`, string(oldHash), u.ID)
+		assert.NoError(t, err)
+
+		bodyBytes, _ := json.Marshal(map[string]any{"username": u.Username, "password": u.Password})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/auth/login", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		var stored string
+		assert.NoError(t, testDB.Get(&stored, `SELECT password FROM "iacc_user" WHERE id = $1`, u.ID))
+		storedCost, err := bcrypt.Cost([]byte(stored))
+		assert.NoError(t, err)
+		assert.Equal(t, cost, storedCost, "应按当前配置重新哈希")
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored), []byte(u.Password)))
+	})
+
+	t.Run("改用哈希前保存的明文密码在迁移后可以登录", func(t *testing.T) {
+		assert.Contains(t, testConf.PasswordHash.LegacyFormats, pkgs.PasswordLegacyPlain, "默认配置应允许 plain 格式")
+		util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		u := util.SetupTestUser()
+		// 模拟改用哈希前保存的明文密码，再执行为明文密码加前缀的迁移
+		_, err := testDB.Exec(`UPDATE "iacc_user" SET password = $1 WHERE id = $2`, u.Password, u.ID)
+		assert.NoError(t, err)
+		migration, err := os.ReadFile("../../../../migration/db/20251129120000_user_password_plain_prefix.up.sql")
+		assert.NoError(t, err)
+		_, err = testDB.Exec(string(migration))
+		assert.NoError(t, err)
+		var stored string
+		assert.NoError(t, testDB.Get(&stored, `SELECT password FROM "iacc_user" WHERE id = $1`, u.ID))
+		assert.Equal(t, "$plain$"+u.Password, stored, "迁移应为明文密码加上前缀")
+
+		bodyBytes, _ := json.Marshal(map[string]any{"username": u.Username, "password": u.Password})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/auth/login", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		assert.NoError(t, testDB.Get(&stored, `SELECT password FROM "iacc_user" WHERE id = $1`, u.ID))
+		assert.NotContains(t, stored, "$plain$", "登录后应按当前配置重新哈希")
+		hasher, err := pkgs.NewPasswordHasher(testConf)
+		assert.NoError(t, err)
+		assert.True(t, hasher.Verify(stored, u.Password), "重新哈希后的密码应能校验")
+	})
 }
 
 // --- 刷新令牌测试 ---
@@ -307,7 +370,7 @@ func TestAuthUserDetail(t *testing.T) {
 
 	t.Run("用户不存在", func(t *testing.T) {
 		// 构造合法签名但数据库中无此用户
-		claims := jwt.MapClaims{"user_id": "00000000-0000-0000-0000-000000000000", "exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix()}
+		claims := jwt.MapClaims{"user_id": "00000000-0000-0000-0000-000000000000", pkgs.TokenTypeClaim: pkgs.TokenTypeAccess, "exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix()}
 		tk := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 		// secret 与配置一致
 		good, _ := tk.SignedString([]byte("my-secret-key"))
@@ -322,3 +385,57 @@ func TestAuthUserDetail(t *testing.T) {
 		assert.Equal(t, "用户不存在", resp.Msg)
 	})
 }
+
+// TestAuthTokenType 测试访问令牌与刷新令牌不能互换使用
+// 包含三个子测试：刷新令牌不能访问接口、访问令牌不能换取新令牌、缺少类型的令牌不能访问接口
+func TestAuthTokenType(t *testing.T) {
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	u := util.SetupTestUser()
+	loginBodyBytes, _ := json.Marshal(map[string]any{"username": u.Username, "password": u.Password})
+	loginReq, _ := http.NewRequest(http.MethodPost, "/v1/auth/login", bytes.NewBuffer(loginBodyBytes))
+	loginReq.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, loginReq)
+	var loginResp pkgs.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &loginResp)
+	loginData := loginResp.Data.(map[string]any)
+	accessToken := loginData["access_token"].(string)
+	refreshToken := loginData["refresh_token"].(string)
+
+	userDetail := func(token string) pkgs.Response {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/auth/user-detail", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	t.Run("刷新令牌不能访问接口", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, userDetail(accessToken).Code, "访问令牌应能访问接口")
+		resp := userDetail(refreshToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "无效的令牌", resp.Msg)
+	})
+
+	t.Run("访问令牌不能换取新令牌", func(t *testing.T) {
+		bodyBytes, _ := json.Marshal(map[string]any{"refresh_token": accessToken})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/auth/refresh-token", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "刷新令牌无效", resp.Msg)
+	})
+
+	t.Run("缺少类型的令牌不能访问接口", func(t *testing.T) {
+		claims := jwt.MapClaims{"user_id": u.ID, "exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix()}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("my-secret-key"))
+		resp := userDetail(token)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "无效的令牌", resp.Msg)
+	})
+}
@@ -0,0 +1,110 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// cookieRequest 只携带 cookie（不带 Authorization 请求头）发送 JSON 请求，csrf 为空时不携带 X-CSRF-Token 请求头
+func cookieRequest(t *testing.T, method, path string, cookies []*http.Cookie, csrf string, body any) (pkgs.Response, []*http.Cookie) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	if csrf != "" {
+		req.Header.Set(pkgs.CSRFHeader, csrf)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp, w.Result().Cookies()
+}
+
+// cookieValue 返回指定名称的 cookie 值
+func cookieValue(cookies []*http.Cookie, name string) string {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// TestAuthCookie 测试 Cookie 鉴权与 CSRF 校验
+// 包含四个子测试：登录写入 cookie 并通过 cookie 鉴权、修改类请求校验 CSRF 令牌、通过 cookie 刷新令牌、退出登录删除 cookie
+func TestAuthCookie(t *testing.T) {
+	testConf.JWT.Cookie.Enabled = true
+	t.Cleanup(func() { testConf.JWT.Cookie.Enabled = false })
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+
+	login := func(t *testing.T) []*http.Cookie {
+		u := util.SetupTestUser()
+		resp, cookies := cookieRequest(t, http.MethodPost, "/v1/auth/login", nil, "", map[string]any{"username": u.Username, "password": u.Password})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		require.NotEmpty(t, cookieValue(cookies, pkgs.AccessTokenCookie))
+		require.NotEmpty(t, cookieValue(cookies, pkgs.RefreshTokenCookie))
+		require.NotEmpty(t, cookieValue(cookies, pkgs.CSRFTokenCookie))
+		return cookies
+	}
+
+	t.Run("登录写入 cookie 并通过 cookie 鉴权", func(t *testing.T) {
+		cookies := login(t)
+		resp, _ := cookieRequest(t, http.MethodGet, "/v1/auth/user-detail", cookies, "", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("修改类请求校验 CSRF 令牌", func(t *testing.T) {
+		cookies := login(t)
+		resp, _ := cookieRequest(t, http.MethodPost, "/v1/auth/logout", cookies, "", map[string]any{})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Equal(t, "CSRF 令牌校验失败", resp.Msg)
+
+		resp, _ = cookieRequest(t, http.MethodPost, "/v1/auth/logout", cookies, "forged", map[string]any{})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		// 被拒绝的请求没有撤销令牌
+		resp, _ = cookieRequest(t, http.MethodGet, "/v1/auth/user-detail", cookies, "", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("通过 cookie 刷新令牌", func(t *testing.T) {
+		cookies := login(t)
+		resp, _ := cookieRequest(t, http.MethodPost, "/v1/auth/refresh-token", cookies, "", map[string]any{})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp, refreshed := cookieRequest(t, http.MethodPost, "/v1/auth/refresh-token", cookies, cookieValue(cookies, pkgs.CSRFTokenCookie), map[string]any{})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.NotEmpty(t, cookieValue(refreshed, pkgs.AccessTokenCookie))
+		assert.NotEqual(t, cookieValue(cookies, pkgs.CSRFTokenCookie), cookieValue(refreshed, pkgs.CSRFTokenCookie), "刷新后下发新的 CSRF 令牌")
+	})
+
+	t.Run("退出登录删除 cookie", func(t *testing.T) {
+		cookies := login(t)
+		resp, cleared := cookieRequest(t, http.MethodPost, "/v1/auth/logout", cookies, cookieValue(cookies, pkgs.CSRFTokenCookie), map[string]any{})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 2, resp.Data, "同时撤销 cookie 中的刷新令牌")
+		for _, cookie := range cleared {
+			assert.Negative(t, cookie.MaxAge)
+		}
+
+		resp, _ = cookieRequest(t, http.MethodGet, "/v1/auth/user-detail", cookies, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		resp, _ = cookieRequest(t, http.MethodPost, "/v1/auth/refresh-token", cookies, cookieValue(cookies, pkgs.CSRFTokenCookie), map[string]any{})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
@@ -0,0 +1,109 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// deviceRequest 发送 JSON 请求，token 与 deviceID 为空时不携带对应请求头
+func deviceRequest(t *testing.T, method, path, token, deviceID string, body any) pkgs.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if deviceID != "" {
+		req.Header.Set("X-Device-ID", deviceID)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// loginWithDevice 登录并返回访问令牌与刷新令牌
+func loginWithDevice(t *testing.T, username, password, deviceID string) (string, string) {
+	t.Helper()
+	resp := deviceRequest(t, http.MethodPost, "/v1/auth/login", "", deviceID, map[string]any{"username": username, "password": password})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	data := resp.Data.(map[string]any)
+	return data["access_token"].(string), data["refresh_token"].(string)
+}
+
+// TestAuthDevices 测试设备登记与刷新令牌的设备绑定
+// 包含三个子测试：登录登记设备、删除设备后刷新令牌失效、严格设备模式
+func TestAuthDevices(t *testing.T) {
+	t.Run("登录登记设备", func(t *testing.T) {
+		util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		u := util.SetupTestUser()
+		deviceID := uuid.NewString()
+		access, _ := loginWithDevice(t, u.Username, u.Password, deviceID)
+		// 同一设备再次登录不重复登记
+		loginWithDevice(t, u.Username, u.Password, deviceID)
+
+		resp := deviceRequest(t, http.MethodGet, "/v1/auth/devices", access, deviceID, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		data := resp.Data.(map[string]any)
+		assert.Equal(t, float64(1), data["total"])
+		assert.Equal(t, false, data["strict_device"])
+		device := data["list"].([]any)[0].(map[string]any)
+		assert.Equal(t, deviceID, device["device_id"])
+		assert.Equal(t, true, device["current"])
+	})
+
+	t.Run("删除设备后刷新令牌失效", func(t *testing.T) {
+		util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		u := util.SetupTestUser()
+		deviceID := uuid.NewString()
+		access, refresh := loginWithDevice(t, u.Username, u.Password, deviceID)
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "other-device", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "其他设备不能使用绑定设备的刷新令牌")
+		assert.Equal(t, "刷新令牌与当前设备不匹配", resp.Msg)
+
+		list := deviceRequest(t, http.MethodGet, "/v1/auth/devices", access, "", nil).Data.(map[string]any)
+		id := list["list"].([]any)[0].(map[string]any)["id"].(string)
+		resp = deviceRequest(t, http.MethodDelete, "/v1/auth/devices/"+id, access, "", nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", deviceID, map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "设备已移除，请重新登录", resp.Msg)
+
+		resp = deviceRequest(t, http.MethodDelete, "/v1/auth/devices/"+id, access, "", nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "重复删除应返回404")
+	})
+
+	t.Run("严格设备模式", func(t *testing.T) {
+		util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		u := util.SetupTestUser()
+		deviceID := uuid.NewString()
+		access, boundRefresh := loginWithDevice(t, u.Username, u.Password, deviceID)
+		_, unboundRefresh := loginWithDevice(t, u.Username, u.Password, "")
+
+		resp := deviceRequest(t, http.MethodPut, "/v1/auth/devices/strict-mode", access, "", map[string]any{"enabled": true})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": unboundRefresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "严格模式下未绑定设备的刷新令牌应被拒绝")
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": boundRefresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "严格模式下刷新必须携带设备标识")
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", deviceID, map[string]any{"refresh_token": boundRefresh})
+		assert.Equal(t, http.StatusOK, resp.Code, "已登记设备可以刷新")
+	})
+}
@@ -0,0 +1,72 @@
+package auth_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestAuthLogout 测试退出登录
+// 包含三个子测试：撤销访问令牌与刷新令牌、只撤销访问令牌、不能撤销其他用户的刷新令牌
+func TestAuthLogout(t *testing.T) {
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+
+	t.Run("撤销访问令牌与刷新令牌", func(t *testing.T) {
+		u := util.SetupTestUser()
+		access, refresh := loginWithDevice(t, u.Username, u.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/auth/logout", access, "", map[string]any{"refresh_token": refresh})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 2, resp.Data)
+
+		resp = deviceRequest(t, http.MethodGet, "/v1/auth/user-detail", access, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "令牌已撤销", resp.Msg)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "刷新令牌已撤销，请重新登录", resp.Msg)
+
+		// 重复退出使用已撤销的访问令牌，被鉴权中间件拒绝
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/logout", access, "", map[string]any{})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		// 重新登录获得的新令牌不受影响
+		access, _ = loginWithDevice(t, u.Username, u.Password, "")
+		resp = deviceRequest(t, http.MethodGet, "/v1/auth/user-detail", access, "", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("只撤销访问令牌", func(t *testing.T) {
+		u := util.SetupTestUser()
+		access, refresh := loginWithDevice(t, u.Username, u.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/auth/logout", access, "", map[string]any{})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 1, resp.Data)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("不能撤销其他用户的刷新令牌", func(t *testing.T) {
+		u := util.SetupTestUser()
+		other := util.SetupTestUser()
+		access, _ := loginWithDevice(t, u.Username, u.Password, "")
+		_, otherRefresh := loginWithDevice(t, other.Username, other.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/auth/logout", access, "", map[string]any{"refresh_token": otherRefresh})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "刷新令牌无效", resp.Msg)
+
+		// 校验失败时不撤销任何令牌
+		resp = deviceRequest(t, http.MethodGet, "/v1/auth/user-detail", access, "", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": otherRefresh})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+}
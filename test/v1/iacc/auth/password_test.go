@@ -0,0 +1,89 @@
+package auth_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestAuthChangePassword 测试修改密码
+// 包含三个子测试：修改成功后刷新令牌失效、当前密码错误、新密码与当前密码相同
+func TestAuthChangePassword(t *testing.T) {
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+
+	t.Run("修改成功后刷新令牌失效", func(t *testing.T) {
+		u := util.SetupTestUser()
+		access, refresh := loginWithDevice(t, u.Username, u.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/auth/change-password", access, "", map[string]any{"current_password": u.Password, "new_password": "new-password"})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		// 当前访问令牌在过期前仍可使用
+		resp = deviceRequest(t, http.MethodGet, "/v1/auth/user-detail", access, "", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/login", "", "", map[string]any{"username": u.Username, "password": u.Password})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		loginWithDevice(t, u.Username, "new-password", "")
+	})
+
+	t.Run("当前密码错误", func(t *testing.T) {
+		u := util.SetupTestUser()
+		access, refresh := loginWithDevice(t, u.Username, u.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/auth/change-password", access, "", map[string]any{"current_password": "wrong", "new_password": "new-password"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "当前密码错误", resp.Msg)
+
+		// 修改失败时刷新令牌不受影响
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("新密码与当前密码相同", func(t *testing.T) {
+		u := util.SetupTestUser()
+		access, _ := loginWithDevice(t, u.Username, u.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/auth/change-password", access, "", map[string]any{"current_password": u.Password, "new_password": u.Password})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+// TestUserResetPassword 测试管理员重置用户密码
+// 包含三个子测试：重置成功后刷新令牌失效、无权限、用户不存在
+func TestUserResetPassword(t *testing.T) {
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	adminToken := util.GetAccessUserToken([]string{"POST /v1/user/:id/reset-password"})
+
+	t.Run("重置成功后刷新令牌失效", func(t *testing.T) {
+		u := util.SetupTestUser()
+		_, refresh := loginWithDevice(t, u.Username, u.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/user/"+u.ID+"/reset-password", adminToken, "", map[string]any{"password": "reset-password"})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 1, resp.Data)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		loginWithDevice(t, u.Username, "reset-password", "")
+	})
+
+	t.Run("无权限", func(t *testing.T) {
+		u := util.SetupTestUser()
+		token := util.GetAccessUserToken([]string{})
+		resp := deviceRequest(t, http.MethodPost, "/v1/user/"+u.ID+"/reset-password", token, "", map[string]any{"password": "reset-password"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("用户不存在", func(t *testing.T) {
+		resp := deviceRequest(t, http.MethodPost, "/v1/user/00000000-0000-7000-8000-000000000000/reset-password", adminToken, "", map[string]any{"password": "reset-password"})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,46 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/modules/iacc/auth"
+	"go-pg-demo/pkgs"
+)
+
+// TestRevokeSessions 测试运维命令使用的撤销会话：删除全部设备，此前签发的刷新令牌（包括未绑定设备的）全部失效
+func TestRevokeSessions(t *testing.T) {
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	u := util.SetupTestUser()
+	_, boundRefresh := loginWithDevice(t, u.Username, u.Password, uuid.NewString())
+	_, unboundRefresh := loginWithDevice(t, u.Username, u.Password, "")
+
+	pool, closePool := pkgs.NewTenantPool(testConf, testDB, &pkgs.BatchDB{DB: testDB}, testLogger)
+	defer closePool()
+	tables := pkgs.NewTableNames(testConf)
+	repo := auth.NewRepository(testDB, testLogger, testConf, tables, pool, pkgs.NewIDGenerator(testConf), nil, pkgs.NewTokenBlacklist(pool, tables, testLogger), nil, nil)
+	c := pkgs.NewCommandContext(context.Background(), "")
+
+	devices, err := repo.RevokeSessions(c)(&auth.RevokeSessionsReq{Username: u.Username}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), devices)
+
+	for _, refresh := range []string{boundRefresh, unboundRefresh} {
+		resp := deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "会话已撤销，请重新登录", resp.Msg)
+	}
+
+	// 撤销后可以重新登录
+	loginWithDevice(t, u.Username, u.Password, "")
+
+	_, err = repo.RevokeSessions(c)(&auth.RevokeSessionsReq{Username: "missing-" + uuid.NewString()}).Get()
+	var apiErr *pkgs.ApiError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.Code)
+}
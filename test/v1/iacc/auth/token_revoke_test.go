@@ -0,0 +1,90 @@
+package auth_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/pkgs"
+)
+
+// TestRevokeTokens 测试按条件批量撤销令牌
+// 包含四个子测试：撤销指定用户的令牌、未命中客户端类型的刷新令牌仍可使用、签发时间之后的令牌不受影响、参数校验
+func TestRevokeTokens(t *testing.T) {
+	util := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, adminToken := util.SetupUserWithPermissions([]string{"POST /v1/admin/tokens/revoke"})
+
+	t.Run("撤销指定用户的令牌", func(t *testing.T) {
+		u := util.SetupTestUser()
+		other := util.SetupTestUser()
+		access, refresh := loginWithDevice(t, u.Username, u.Password, "")
+		otherAccess, otherRefresh := loginWithDevice(t, other.Username, other.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/admin/tokens/revoke", adminToken, "", map[string]any{"user_ids": []string{u.ID}})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 1, resp.Data.(map[string]any)["tokens"])
+		assert.EqualValues(t, 1, resp.Data.(map[string]any)["users"])
+
+		resp = deviceRequest(t, http.MethodGet, "/v1/auth/user-detail", access, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "令牌已撤销", resp.Msg)
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "刷新令牌已撤销，请重新登录", resp.Msg)
+
+		// 其他用户不受影响
+		resp = deviceRequest(t, http.MethodGet, "/v1/auth/user-detail", otherAccess, "", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": otherRefresh})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("未命中客户端类型的刷新令牌仍可使用", func(t *testing.T) {
+		u := util.SetupTestUser()
+		access, refresh := loginWithDevice(t, u.Username, u.Password, "")
+
+		// 未指定客户端登录的令牌不属于任何客户端类型
+		resp := deviceRequest(t, http.MethodPost, "/v1/admin/tokens/revoke", adminToken, "", map[string]any{"user_ids": []string{u.ID}, "client_type": "mobile"})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 0, resp.Data.(map[string]any)["tokens"])
+		assert.EqualValues(t, 0, resp.Data.(map[string]any)["users"])
+
+		// 没有命中的令牌时不提升令牌版本，访问令牌与刷新令牌都可以继续使用
+		resp = deviceRequest(t, http.MethodGet, "/v1/auth/user-detail", access, "", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("签发时间之后的令牌不受影响", func(t *testing.T) {
+		u := util.SetupTestUser()
+		_, refresh := loginWithDevice(t, u.Username, u.Password, "")
+
+		resp := deviceRequest(t, http.MethodPost, "/v1/admin/tokens/revoke", adminToken, "", map[string]any{
+			"user_ids":      []string{u.ID},
+			"issued_before": time.Now().Add(-time.Hour).Format(time.RFC3339),
+		})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 0, resp.Data.(map[string]any)["users"])
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", "", map[string]any{"refresh_token": refresh})
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		resp := deviceRequest(t, http.MethodPost, "/v1/admin/tokens/revoke", adminToken, "", map[string]any{})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/admin/tokens/revoke", adminToken, "", map[string]any{"issued_before": time.Now().Add(time.Hour).Format(time.RFC3339)})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/admin/tokens/revoke", adminToken, "", map[string]any{"client_type": "desktop"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = deviceRequest(t, http.MethodPost, "/v1/admin/tokens/revoke", adminToken, "", map[string]any{"user_ids": []string{"not-a-uuid"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,156 @@
+package client_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+)
+
+// TestMain 初始化一次应用，复用数据库和路由
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	os.Exit(m.Run())
+}
+
+// doRequest 发送 JSON 请求并解析标准响应
+func doRequest(t *testing.T, method, path, token string, body any) pkgs.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// createClient 通过接口登记客户端，测试结束后删除
+func createClient(t *testing.T, token string, body map[string]any) string {
+	t.Helper()
+	resp := doRequest(t, http.MethodPost, "/v1/client", token, body)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	id := resp.Data.(string)
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM iacc_client WHERE id = $1`, id)
+		assert.NoError(t, err, "清理测试客户端失败")
+	})
+	return id
+}
+
+// TestClientCRUD 测试客户端登记、查询、更新与删除
+// 包含三个子测试：登记与查询、标识重复与有效期校验、更新与删除
+func TestClientCRUD(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{
+		"POST /v1/client", "GET /v1/client/:id", "PUT /v1/client/:id", "DELETE /v1/client/:id", "GET /v1/client/list",
+	})
+
+	t.Run("登记与查询", func(t *testing.T) {
+		clientID := "web-" + uuid.NewString()[:8]
+		id := createClient(t, token, map[string]any{
+			"client_id": clientID, "name": "管理后台", "client_type": "web", "access_token_ttl": 600, "refresh_token_ttl": 3600,
+		})
+		resp := doRequest(t, http.MethodGet, "/v1/client/"+id, token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		data := resp.Data.(map[string]any)
+		assert.Equal(t, clientID, data["client_id"])
+		assert.Equal(t, float64(600), data["access_token_ttl"])
+		assert.Equal(t, true, data["enabled"])
+
+		resp = doRequest(t, http.MethodGet, "/v1/client/list?client_type=web", token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.GreaterOrEqual(t, resp.Data.(map[string]any)["total"], float64(1))
+	})
+
+	t.Run("标识重复与有效期校验", func(t *testing.T) {
+		clientID := "app-" + uuid.NewString()[:8]
+		body := map[string]any{"client_id": clientID, "name": "App", "client_type": "mobile", "access_token_ttl": 600, "refresh_token_ttl": 86400}
+		createClient(t, token, body)
+		resp := doRequest(t, http.MethodPost, "/v1/client", token, body)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		body["client_id"] = "bad id"
+		resp = doRequest(t, http.MethodPost, "/v1/client", token, body)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "客户端标识不能包含空格")
+
+		body["client_id"], body["refresh_token_ttl"] = "app-"+uuid.NewString()[:8], 60
+		resp = doRequest(t, http.MethodPost, "/v1/client", token, body)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "刷新令牌有效期不能短于访问令牌")
+	})
+
+	t.Run("更新与删除", func(t *testing.T) {
+		id := createClient(t, token, map[string]any{
+			"client_id": "svc-" + uuid.NewString()[:8], "name": "同步服务", "client_type": "service", "access_token_ttl": 600, "refresh_token_ttl": 600,
+		})
+		resp := doRequest(t, http.MethodPut, "/v1/client/"+id, token, map[string]any{"access_token_ttl": 1200})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "只修改访问令牌有效期时不能超过现有刷新令牌有效期")
+		resp = doRequest(t, http.MethodPut, "/v1/client/"+id, token, map[string]any{"access_token_ttl": 1200, "refresh_token_ttl": 7200, "enabled": false})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, float64(1), resp.Data)
+
+		resp = doRequest(t, http.MethodDelete, "/v1/client/"+id, token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		resp = doRequest(t, http.MethodGet, "/v1/client/"+id, token, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+// TestClientTokenLifetimes 测试登录与刷新按客户端的有效期签发令牌
+func TestClientTokenLifetimes(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"POST /v1/client", "PUT /v1/client/:id"})
+	u := tu.SetupTestUser()
+	clientID := "mobile-" + uuid.NewString()[:8]
+	id := createClient(t, token, map[string]any{
+		"client_id": clientID, "name": "App", "client_type": "mobile", "access_token_ttl": 1800, "refresh_token_ttl": 2592000,
+	})
+
+	resp := doRequest(t, http.MethodPost, "/v1/auth/login", "", map[string]any{"username": u.Username, "password": u.Password, "client_id": clientID})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	data := resp.Data.(map[string]any)
+	assert.Equal(t, float64(1800), data["expires_in"], "应使用客户端的访问令牌有效期")
+
+	resp = doRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", map[string]any{"refresh_token": data["refresh_token"]})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(1800), resp.Data.(map[string]any)["expires_in"], "刷新时沿用客户端的有效期")
+
+	resp = doRequest(t, http.MethodPost, "/v1/auth/login", "", map[string]any{"username": u.Username, "password": u.Password, "client_id": "unknown-client"})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "客户端不存在或已停用", resp.Msg)
+
+	// 停用后绑定该客户端的刷新令牌不能再刷新
+	resp = doRequest(t, http.MethodPut, "/v1/client/"+id, token, map[string]any{"enabled": false})
+	require.Equal(t, http.StatusOK, resp.Code)
+	resp = doRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", map[string]any{"refresh_token": data["refresh_token"]})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
@@ -4,9 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"os"
-	"strings"
 	"testing"
-	"time"
 
 	"go-pg-demo/internal/app"
 	"go-pg-demo/pkgs"
@@ -161,26 +159,3 @@ func getAuthToken(t *testing.T, permissions []string) string {
 func stringPtr(s string) *string {
 	return &s
 }
-
-// compareTimestamps 比较两个时间戳字符串，只比较到秒级精度
-func compareTimestamps(t1, t2 string) bool {
-	// 解析时间戳
-	parsedT1, err1 := time.Parse(time.RFC3339Nano, t1)
-	parsedT2, err2 := time.Parse(time.RFC3339Nano, t2)
-
-	if err1 != nil || err2 != nil {
-		// 如果解析失败，尝试其他格式
-		parsedT1, err1 = time.Parse("2006-01-02 15:04:05.999999", t1)
-		parsedT2, err2 = time.Parse("2006-01-02 15:04:05.999999", t2)
-
-		if err1 != nil || err2 != nil {
-			// 如果还是失败，直接比较字符串（去掉微秒部分）
-			t1Sec := strings.Split(t1, ".")[0]
-			t2Sec := strings.Split(t2, ".")[0]
-			return t1Sec == t2Sec
-		}
-	}
-
-	// 比较到秒级精度
-	return parsedT1.Truncate(time.Second).Equal(parsedT2.Truncate(time.Second))
-}
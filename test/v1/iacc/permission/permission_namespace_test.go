@@ -0,0 +1,92 @@
+package permission_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPermissionNamespace 测试权限命名空间
+// 包含四个子测试：按编码推断命名空间、显式指定命名空间、按命名空间筛选包含子命名空间、移出命名空间
+func TestPermissionNamespace(t *testing.T) {
+	token := getAuthToken(t, []string{})
+	root := "nstest_" + strings.ReplaceAll(uuid.NewString()[:8], "-", "")
+
+	do := func(t *testing.T, method, url string, body any) pkgs.Response {
+		t.Helper()
+		var reader *bytes.Buffer
+		if body != nil {
+			bodyBytes, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(bodyBytes)
+		} else {
+			reader = &bytes.Buffer{}
+		}
+		req, _ := http.NewRequest(method, url, reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+	create := func(t *testing.T, body map[string]any) map[string]any {
+		t.Helper()
+		resp := do(t, http.MethodPost, "/v1/permission?return=entity", body)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		created := resp.Data.(map[string]any)
+		t.Cleanup(func() {
+			_, err := testDB.Exec(`DELETE FROM iacc_permission WHERE id = $1`, created["id"])
+			assert.NoError(t, err, "清理权限失败")
+		})
+		return created
+	}
+
+	var exportID string
+	t.Run("按编码推断命名空间", func(t *testing.T) {
+		created := create(t, map[string]any{"name": root + "_csv", "type": "data", "metadata": map[string]any{"code": root + ":report:export:csv"}})
+		exportID = created["id"].(string)
+		assert.Equal(t, root+":report:export", created["namespace"])
+	})
+
+	t.Run("显式指定命名空间", func(t *testing.T) {
+		created := create(t, map[string]any{"name": root + "_view", "type": "data", "namespace": root + ":report", "metadata": map[string]any{"code": "report_view"}})
+		assert.Equal(t, root+":report", created["namespace"])
+
+		resp := do(t, http.MethodPost, "/v1/permission", map[string]any{"name": root + "_bad", "type": "data", "namespace": "report export"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "命名空间格式错误")
+	})
+
+	t.Run("按命名空间筛选包含子命名空间", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/v1/permission/list?namespace="+root+":report", nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, float64(2), resp.Data.(map[string]any)["total"])
+
+		resp = do(t, http.MethodGet, "/v1/permission/list?namespace="+root+":report:export", nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, float64(1), resp.Data.(map[string]any)["total"])
+
+		resp = do(t, http.MethodGet, "/v1/permission/list?namespace="+root+":rep", nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, float64(0), resp.Data.(map[string]any)["total"], "只匹配完整的段")
+	})
+
+	t.Run("移出命名空间", func(t *testing.T) {
+		require.NotEmpty(t, exportID)
+		resp := do(t, http.MethodPatch, "/v1/permission/"+exportID, map[string]any{"namespace": nil})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		var namespace *string
+		require.NoError(t, testDB.Get(&namespace, `SELECT namespace FROM iacc_permission WHERE id = $1`, exportID))
+		assert.Nil(t, namespace)
+	})
+}
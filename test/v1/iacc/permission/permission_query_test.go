@@ -42,3 +42,44 @@ func TestQueryPermissionList(t *testing.T) {
 		assert.GreaterOrEqual(t, int64(total.(float64)), int64(2), "总权限数应至少为 2")
 	})
 }
+
+// TestPermissionValues 测试权限字段取值查询功能
+func TestPermissionValues(t *testing.T) {
+	t.Run("按类型统计", func(t *testing.T) {
+		// 准备 - 创建同一类型的两个权限
+		createSortTestPermission(t, "测试权限-Values1", "values_test_type")
+		createSortTestPermission(t, "测试权限-Values2", "values_test_type")
+
+		// 执行
+		req, _ := http.NewRequest(http.MethodGet, "/v1/permission/values?field=type&type=values_test_type", nil)
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		values, ok := resp.Data.([]any)
+		if assert.True(t, ok, "响应数据应该是一个数组") && assert.Len(t, values, 1) {
+			assert.Equal(t, map[string]any{"value": "values_test_type", "count": float64(2)}, values[0])
+		}
+	})
+
+	t.Run("不支持的字段", func(t *testing.T) {
+		// 执行
+		req, _ := http.NewRequest(http.MethodGet, "/v1/permission/values?field=name", nil)
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "响应码应该是 400")
+	})
+}
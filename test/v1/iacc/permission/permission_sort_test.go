@@ -222,10 +222,10 @@ func TestQueryListPermissions_Sort(t *testing.T) {
 
 		assert.Equal(t, 3, len(list))
 		if len(list) >= 3 {
-			// 验证排序顺序 - 使用时间戳比较函数
-			assert.True(t, compareTimestamps(id1["created_at"].(string), list[0].(map[string]any)["created_at"].(string)))
-			assert.True(t, compareTimestamps(id2["created_at"].(string), list[1].(map[string]any)["created_at"].(string)))
-			assert.True(t, compareTimestamps(id3["created_at"].(string), list[2].(map[string]any)["created_at"].(string)))
+			// 验证排序顺序 - 时间相同时按 seq 决胜，顺序与创建顺序一致
+			assert.Equal(t, id1["id"], list[0].(map[string]any)["id"])
+			assert.Equal(t, id2["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, id3["id"], list[2].(map[string]any)["id"])
 		}
 	})
 
@@ -252,10 +252,10 @@ func TestQueryListPermissions_Sort(t *testing.T) {
 
 		assert.Equal(t, 3, len(list))
 		if len(list) >= 3 {
-			// 验证排序顺序 - 使用时间戳比较函数
-			assert.True(t, compareTimestamps(id3["created_at"].(string), list[0].(map[string]any)["created_at"].(string)))
-			assert.True(t, compareTimestamps(id2["created_at"].(string), list[1].(map[string]any)["created_at"].(string)))
-			assert.True(t, compareTimestamps(id1["created_at"].(string), list[2].(map[string]any)["created_at"].(string)))
+			// 验证排序顺序 - 时间相同时按 seq 决胜，顺序与创建顺序一致
+			assert.Equal(t, id3["id"], list[0].(map[string]any)["id"])
+			assert.Equal(t, id2["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, id1["id"], list[2].(map[string]any)["id"])
 		}
 	})
 
@@ -282,10 +282,10 @@ func TestQueryListPermissions_Sort(t *testing.T) {
 
 		assert.Equal(t, 3, len(list))
 		if len(list) >= 3 {
-			// 验证排序顺序 - 使用时间戳比较函数
-			assert.True(t, compareTimestamps(id1["updated_at"].(string), list[0].(map[string]any)["updated_at"].(string)))
-			assert.True(t, compareTimestamps(id2["updated_at"].(string), list[1].(map[string]any)["updated_at"].(string)))
-			assert.True(t, compareTimestamps(id3["updated_at"].(string), list[2].(map[string]any)["updated_at"].(string)))
+			// 验证排序顺序 - 时间相同时按 seq 决胜，顺序与创建顺序一致
+			assert.Equal(t, id1["id"], list[0].(map[string]any)["id"])
+			assert.Equal(t, id2["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, id3["id"], list[2].(map[string]any)["id"])
 		}
 	})
 
@@ -312,10 +312,10 @@ func TestQueryListPermissions_Sort(t *testing.T) {
 
 		assert.Equal(t, 3, len(list))
 		if len(list) >= 3 {
-			// 验证排序顺序 - 使用时间戳比较函数
-			assert.True(t, compareTimestamps(id3["updated_at"].(string), list[0].(map[string]any)["updated_at"].(string)))
-			assert.True(t, compareTimestamps(id2["updated_at"].(string), list[1].(map[string]any)["updated_at"].(string)))
-			assert.True(t, compareTimestamps(id1["updated_at"].(string), list[2].(map[string]any)["updated_at"].(string)))
+			// 验证排序顺序 - 时间相同时按 seq 决胜，顺序与创建顺序一致
+			assert.Equal(t, id3["id"], list[0].(map[string]any)["id"])
+			assert.Equal(t, id2["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, id1["id"], list[2].(map[string]any)["id"])
 		}
 	})
 
@@ -0,0 +1,132 @@
+package permission_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-pg-demo/internal/modules/iacc/permission"
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPermissionTree 测试权限树与移动节点
+// 包含五个子测试：按菜单结构返回、移动节点、父节点必须是菜单、不能移到自身或子孙节点之下、有子节点的菜单不能改为其它类型
+func TestPermissionTree(t *testing.T) {
+	token := getAuthToken(t, []string{})
+	root := "treetest_" + strings.ReplaceAll(uuid.NewString()[:8], "-", "")
+
+	do := func(t *testing.T, method, url string, body any) pkgs.Response {
+		t.Helper()
+		bodyBytes, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+	create := func(t *testing.T, name, pType string, parentID *string) string {
+		t.Helper()
+		resp := do(t, http.MethodPost, "/v1/permission", map[string]any{"name": root + "_" + name, "type": pType, "parent_id": parentID})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		id := resp.Data.(string)
+		t.Cleanup(func() {
+			_, err := testDB.Exec(`DELETE FROM iacc_permission WHERE id = $1`, id)
+			assert.NoError(t, err, "清理权限失败")
+		})
+		return id
+	}
+	tree := func(t *testing.T) permission.TreeRes {
+		t.Helper()
+		resp := do(t, http.MethodGet, "/v1/permission/tree", nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		raw, err := json.Marshal(resp.Data)
+		require.NoError(t, err)
+		var res permission.TreeRes
+		require.NoError(t, json.Unmarshal(raw, &res))
+		return res
+	}
+	find := func(nodes []permission.TreeNode, id string) *permission.TreeNode {
+		for i := range nodes {
+			if nodes[i].ID == id {
+				return &nodes[i]
+			}
+		}
+		return nil
+	}
+
+	// system 菜单下挂 users 子菜单与一个按钮，users 下挂一个接口权限
+	system := create(t, "system", permission.TypeMenu, nil)
+	users := create(t, "users", permission.TypeMenu, &system)
+	button := create(t, "export_button", permission.TypeButton, &system)
+	api := create(t, "list_api", permission.TypeAPI, &users)
+	loose := create(t, "loose_api", permission.TypeAPI, nil)
+
+	t.Run("按菜单结构返回", func(t *testing.T) {
+		res := tree(t)
+		node := find(res.Menus, system)
+		require.NotNil(t, node, "根级菜单在 menus 中")
+		require.Len(t, node.Children, 2)
+		assert.Equal(t, users, node.Children[0].ID, "子菜单排在按钮之前")
+		assert.Equal(t, button, node.Children[1].ID)
+		require.Len(t, node.Children[0].Children, 1)
+		assert.Equal(t, api, node.Children[0].Children[0].ID)
+		assert.NotNil(t, find(res.Ungrouped[permission.TypeAPI], loose), "不属于菜单的权限按类型分组")
+		assert.Nil(t, find(res.Ungrouped[permission.TypeAPI], api), "菜单下的权限不在 ungrouped 中")
+	})
+
+	t.Run("移动节点", func(t *testing.T) {
+		resp := do(t, http.MethodPut, "/v1/permission/"+loose+"/move", map[string]any{"parent_id": users})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		node := find(find(tree(t).Menus, system).Children, users)
+		require.NotNil(t, node)
+		assert.Len(t, node.Children, 2)
+
+		resp = do(t, http.MethodPut, "/v1/permission/"+users+"/move", map[string]any{"parent_id": nil})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		res := tree(t)
+		require.NotNil(t, find(res.Menus, users), "移到根级，子孙节点随之移动")
+		assert.Len(t, find(res.Menus, users).Children, 2)
+		assert.Len(t, find(res.Menus, system).Children, 1)
+
+		resp = do(t, http.MethodPut, "/v1/permission/"+uuid.NewString()+"/move", map[string]any{"parent_id": nil})
+		assert.Equal(t, http.StatusNotFound, resp.Code, "权限不存在")
+	})
+
+	t.Run("父节点必须是菜单", func(t *testing.T) {
+		resp := do(t, http.MethodPut, "/v1/permission/"+loose+"/move", map[string]any{"parent_id": button})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = do(t, http.MethodPost, "/v1/permission", map[string]any{"name": root + "_orphan", "type": permission.TypeAPI, "parent_id": uuid.NewString()})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "父节点不存在")
+	})
+
+	t.Run("不能移到自身或子孙节点之下", func(t *testing.T) {
+		resp := do(t, http.MethodPut, "/v1/permission/"+system+"/move", map[string]any{"parent_id": system})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = do(t, http.MethodPut, "/v1/permission/"+users+"/move", map[string]any{"parent_id": system})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		resp = do(t, http.MethodPut, "/v1/permission/"+system+"/move", map[string]any{"parent_id": users})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "users 是 system 的子节点")
+	})
+	t.Run("有子节点的菜单不能改为其它类型", func(t *testing.T) {
+		resp := do(t, http.MethodPatch, "/v1/permission/"+system, map[string]any{"type": permission.TypeButton})
+		assert.Equal(t, http.StatusConflict, resp.Code, "部分更新")
+		resp = do(t, http.MethodPut, "/v1/permission/"+users, map[string]any{"type": permission.TypeAPI})
+		assert.Equal(t, http.StatusConflict, resp.Code, "更新")
+
+		leaf := create(t, "leaf_menu", permission.TypeMenu, &system)
+		resp = do(t, http.MethodPatch, "/v1/permission/"+leaf, map[string]any{"type": permission.TypeButton})
+		assert.Equal(t, http.StatusOK, resp.Code, "没有子节点的菜单可以改类型")
+	})
+}
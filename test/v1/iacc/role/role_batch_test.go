@@ -0,0 +1,77 @@
+package role_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBatchRoleDuplicates 测试批量接口的批次内重复检测
+// 包含两个子测试：批量创建角色名称重复、批量删除ID重复
+func TestBatchRoleDuplicates(t *testing.T) {
+	t.Run("批量创建 - 角色名称重复", func(t *testing.T) {
+		// 准备：第 0 项与第 2 项名称相同
+		name := "role_" + uuid.NewString()[:8]
+		batchReq := map[string]any{
+			"roles": []map[string]any{
+				{"name": name},
+				{"name": "role_" + uuid.NewString()[:8]},
+				{"name": name},
+			},
+		}
+		bodyBytes, _ := json.Marshal(batchReq)
+		req, _ := http.NewRequest(http.MethodPost, "/v1/role/batch-create", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// 执行
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "名称重复时应返回400而不是数据库错误")
+		assert.Contains(t, resp.Msg, "角色名称存在重复值")
+		violations := resp.Data.([]any)
+		assert.Equal(t, []any{float64(0), float64(2)}, violations[0].(map[string]any)["indexes"], "应返回重复项的下标")
+
+		var count int
+		err = testDB.GetContext(context.Background(), &count, `SELECT count(*) FROM iacc_role WHERE name = $1`, name)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count, "校验失败时不应创建任何角色")
+	})
+
+	t.Run("批量删除 - ID重复", func(t *testing.T) {
+		// 准备
+		entity := createTestRole(t, "", nil)
+		id := entity["id"].(string)
+		deleteReq := map[string]any{"ids": []string{id, id}}
+		bodyBytes, _ := json.Marshal(deleteReq)
+		req, _ := http.NewRequest(http.MethodPost, "/v1/role/batch-delete", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// 执行
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Msg, "角色ID存在重复值")
+	})
+}
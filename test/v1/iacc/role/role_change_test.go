@@ -0,0 +1,115 @@
+package role_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCriticalRoleChange 测试关键角色变更的双人审批
+// 包含四个子测试：变更暂存待审批、提交人不能审批自己的变更、其他用户审批后执行变更、驳回与重复审批
+func TestCriticalRoleChange(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	permissions := []string{"PUT /v1/role/:id", "POST /v1/role/change/:id/approve", "POST /v1/role/change/:id/reject", "GET /v1/role/change/list"}
+	_, requester := tu.SetupUserWithPermissions(permissions)
+	_, approver := tu.SetupUserWithPermissions(permissions)
+
+	do := func(t *testing.T, method, path, token string, body any) pkgs.Response {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), "解析响应体不应出错")
+		return resp
+	}
+	// createCriticalRole 创建关键角色，并在测试结束后删除其变更记录
+	createCriticalRole := func(t *testing.T) string {
+		role := createTestRole(t, "critical_role_"+uuid.NewString()[:8], nil)
+		id := role["id"].(string)
+		_, err := testDB.ExecContext(context.Background(), `UPDATE iacc_role SET critical = TRUE WHERE id = $1`, id)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, err := testDB.ExecContext(context.Background(), `DELETE FROM iacc_role_change WHERE role_id = $1`, id)
+			assert.NoError(t, err, "清理角色变更失败")
+		})
+		return id
+	}
+	// stage 修改关键角色名称，返回暂存的变更ID
+	stage := func(t *testing.T, roleID, name string) string {
+		resp := do(t, http.MethodPut, "/v1/role/"+roleID, requester, map[string]any{"name": name})
+		require.Equal(t, http.StatusAccepted, resp.Code, resp.Msg)
+		data, ok := resp.Data.(map[string]any)
+		require.True(t, ok, "响应应包含变更ID")
+		return data["change_id"].(string)
+	}
+	roleName := func(t *testing.T, roleID string) string {
+		var name string
+		require.NoError(t, testDB.GetContext(context.Background(), &name, `SELECT name FROM iacc_role WHERE id = $1`, roleID))
+		return name
+	}
+
+	t.Run("变更暂存待审批", func(t *testing.T) {
+		roleID := createCriticalRole(t)
+		original := roleName(t, roleID)
+		stage(t, roleID, "critical_role_"+uuid.NewString()[:8])
+
+		assert.Equal(t, original, roleName(t, roleID), "审批前不应执行变更")
+		resp := do(t, http.MethodPut, "/v1/role/"+roleID, requester, map[string]any{"name": "critical_role_" + uuid.NewString()[:8]})
+		assert.Equal(t, http.StatusConflict, resp.Code, "同一角色只能有一个待审批的变更")
+
+		resp = do(t, http.MethodGet, "/v1/role/change/list?status=pending&role_id="+roleID, approver, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 1, resp.Data.(map[string]any)["total"])
+	})
+
+	t.Run("提交人不能审批自己的变更", func(t *testing.T) {
+		roleID := createCriticalRole(t)
+		changeID := stage(t, roleID, "critical_role_"+uuid.NewString()[:8])
+
+		resp := do(t, http.MethodPost, "/v1/role/change/"+changeID+"/approve", requester, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("其他用户审批后执行变更", func(t *testing.T) {
+		roleID := createCriticalRole(t)
+		newName := "critical_role_" + uuid.NewString()[:8]
+		changeID := stage(t, roleID, newName)
+
+		resp := do(t, http.MethodPost, "/v1/role/change/"+changeID+"/approve", approver, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, newName, roleName(t, roleID), "审批后应执行变更")
+
+		var status string
+		require.NoError(t, testDB.GetContext(context.Background(), &status, `SELECT status FROM iacc_role_change WHERE id = $1`, changeID))
+		assert.Equal(t, "approved", status)
+	})
+
+	t.Run("驳回与重复审批", func(t *testing.T) {
+		roleID := createCriticalRole(t)
+		original := roleName(t, roleID)
+		changeID := stage(t, roleID, "critical_role_"+uuid.NewString()[:8])
+
+		resp := do(t, http.MethodPost, "/v1/role/change/"+changeID+"/reject", approver, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, original, roleName(t, roleID), "驳回后不应执行变更")
+
+		resp = do(t, http.MethodPost, "/v1/role/change/"+changeID+"/approve", approver, nil)
+		assert.Equal(t, http.StatusConflict, resp.Code, "已审批的变更不能再次审批")
+	})
+}
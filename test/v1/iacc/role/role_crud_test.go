@@ -10,6 +10,7 @@ import (
 
 	"go-pg-demo/pkgs"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -86,7 +87,7 @@ func TestCreateRole(t *testing.T) {
 }
 
 // TestGetRole 测试获取角色功能
-// 包含三个子测试：成功获取角色、角色不存在、无效ID
+// 包含五个子测试：成功获取角色、扩展权限与用户数、不支持的扩展内容、角色不存在、无效ID
 func TestGetRole(t *testing.T) {
 	t.Run("成功获取角色", func(t *testing.T) {
 		// 准备
@@ -117,6 +118,67 @@ func TestGetRole(t *testing.T) {
 		assert.Equal(t, entity["name"], roleData["name"], "返回的角色名称应该匹配")
 	})
 
+	t.Run("扩展权限与用户数", func(t *testing.T) {
+		// 准备
+		tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		entity := createTestRole(t, "扩展测试角色_"+uuid.NewString()[:8], nil)
+		roleID := entity["id"].(string)
+		permission := createTestPermission(t, "扩展测试权限_"+uuid.NewString()[:8])
+		tu.AssignPermissionToRole(roleID, permission["id"].(string))
+		for range 2 {
+			u := tu.SetupTestUser()
+			tu.AssignRoleToUser(u.ID, roleID)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/role/"+roleID+"?include=permissions,userCount", nil)
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// 执行
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "响应体应该能正确解析为Response结构体")
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		roleData, ok := resp.Data.(map[string]any)
+		assert.True(t, ok, "数据应该是对象类型")
+		assert.EqualValues(t, 2, roleData["user_count"], "应返回拥有该角色的用户数")
+		permissions, ok := roleData["permissions"].([]any)
+		if assert.True(t, ok, "应返回权限列表") && assert.Len(t, permissions, 1) {
+			assert.Equal(t, permission["id"], permissions[0].(map[string]any)["id"])
+			assert.Equal(t, "allow", permissions[0].(map[string]any)["effect"])
+		}
+
+		// 未指定 include 时不返回扩展内容
+		req, _ = http.NewRequest(http.MethodGet, "/v1/role/"+roleID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w = httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		resp = pkgs.Response{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		roleData, _ = resp.Data.(map[string]any)
+		assert.NotContains(t, roleData, "permissions")
+		assert.NotContains(t, roleData, "user_count")
+	})
+
+	t.Run("不支持的扩展内容", func(t *testing.T) {
+		entity := createTestRole(t, "扩展测试角色_"+uuid.NewString()[:8], nil)
+		req, _ := http.NewRequest(http.MethodGet, "/v1/role/"+entity["id"].(string)+"?include=users", nil)
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
 	t.Run("角色不存在", func(t *testing.T) {
 		// 准备
 		fakeID := "123e4567-e89b-12d3-a456-426614174000"
@@ -154,16 +216,21 @@ func TestGetRole(t *testing.T) {
 
 		// 断言
 		assert.Equal(t, http.StatusOK, w.Code, "状态码应该是200")
-		// 根据handler实现，无效ID会返回空数据而不是404错误
+		// 无效ID统一返回 400，并指出无效的路径参数
 		var resp pkgs.Response
 		err := json.Unmarshal(w.Body.Bytes(), &resp)
 		assert.NoError(t, err, "响应体应该能正确解析为Response结构体")
-		assert.Nil(t, resp.Data, "返回数据应该为空")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "响应码应该是 400")
+		assert.Equal(t, "角色ID必须是一个有效的UUID", resp.Msg)
+		violations, _ := resp.Data.([]any)
+		if assert.Len(t, violations, 1) {
+			assert.Equal(t, "id", violations[0].(map[string]any)["field"])
+		}
 	})
 }
 
 // TestUpdateRole 测试更新角色功能
-// 包含两个子测试：成功更新角色、更新不存在的角色
+// 包含三个子测试：成功更新角色、传 null 清空描述、更新不存在的角色
 func TestUpdateRole(t *testing.T) {
 	t.Run("成功更新角色", func(t *testing.T) {
 		// 准备
@@ -201,6 +268,40 @@ func TestUpdateRole(t *testing.T) {
 		assert.Equal(t, newDescription, *updatedRole.Description, "描述应该已被更新")
 	})
 
+	t.Run("传 null 清空描述", func(t *testing.T) {
+		// 准备
+		description := "待清空的描述"
+		entity := createTestRole(t, "清空描述角色_"+uuid.NewString()[:8], &description)
+		token := getAuthToken(t, []string{})
+
+		// 缺省字段不修改
+		req, _ := http.NewRequest(http.MethodPut, "/v1/role/"+entity["id"].(string), bytes.NewBufferString(`{"name":"`+entity["name"].(string)+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var stored *string
+		err := testDB.GetContext(context.Background(), &stored, `SELECT description FROM iacc_role WHERE id = $1`, entity["id"])
+		assert.NoError(t, err)
+		if assert.NotNil(t, stored, "未传的字段不应被修改") {
+			assert.Equal(t, description, *stored)
+		}
+
+		// 显式 null 清空
+		req, _ = http.NewRequest(http.MethodPut, "/v1/role/"+entity["id"].(string), bytes.NewBufferString(`{"description":null}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w = httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		err = testDB.GetContext(context.Background(), &stored, `SELECT description FROM iacc_role WHERE id = $1`, entity["id"])
+		assert.NoError(t, err)
+		assert.Nil(t, stored, "描述应被清空")
+	})
+
 	t.Run("更新不存在的角色", func(t *testing.T) {
 		// 准备
 		fakeID := "123e4567-e89b-12d3-a456-426614174000"
@@ -0,0 +1,134 @@
+package role_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// patchRole 部分更新角色，返回解析后的响应
+func patchRole(t *testing.T, token, id string, body map[string]any) pkgs.Response {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPatch, "/v1/role/"+id, bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// TestRoleInheritance 测试角色继承
+// 包含五个子测试：子角色继承祖先角色的权限、角色详情展开继承的权限、不能形成环、不能直接继承关键角色、取消继承
+func TestRoleInheritance(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := getAuthToken(t, []string{"PATCH /v1/role/:id", "GET /v1/role/:id", "POST /v1/role"})
+
+	// grandparent <- parent <- child，祖先角色授予接口权限，父角色拒绝一个编码权限
+	grandparent, parent, child := testUtil.SetupTestRole(), testUtil.SetupTestRole(), testUtil.SetupTestRole()
+	listPerm := testUtil.SetupTestPermission("GET /v1/template/list")
+	testUtil.AssignPermissionToRole(grandparent.ID, listPerm.ID)
+	codePerm := testUtil.SetupTestCodePermission("template:inherit_test")
+	testUtil.AssignPermissionToRole(grandparent.ID, codePerm.ID)
+	testUtil.DenyPermissionForRole(parent.ID, codePerm.ID)
+
+	resp := patchRole(t, token, parent.ID, map[string]any{"parent_role_id": grandparent.ID})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	resp = patchRole(t, token, child.ID, map[string]any{"parent_role_id": parent.ID})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+	t.Run("子角色继承祖先角色的权限", func(t *testing.T) {
+		u := testUtil.SetupTestUser()
+		testUtil.AssignRoleToUser(u.ID, child.ID)
+		userToken := testUtil.GetAccessTokenByUser(u)
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/template/list", nil)
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var listResp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResp))
+		assert.Equal(t, http.StatusOK, listResp.Code, "通过两级继承获得接口权限")
+	})
+
+	t.Run("角色详情展开继承的权限", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/role/"+child.ID+"?include=permissions", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var detail pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+		require.Equal(t, http.StatusOK, detail.Code, detail.Msg)
+
+		data := detail.Data.(map[string]any)
+		assert.Equal(t, parent.ID, data["parent_role_id"])
+		permissions := map[string]map[string]any{}
+		for _, item := range data["permissions"].([]any) {
+			perm := item.(map[string]any)
+			permissions[perm["id"].(string)] = perm
+		}
+		require.Len(t, permissions, 2)
+		assert.Equal(t, "allow", permissions[listPerm.ID]["effect"])
+		assert.Equal(t, grandparent.ID, permissions[listPerm.ID]["inherited_from"])
+		assert.Equal(t, "deny", permissions[codePerm.ID]["effect"], "拒绝优先")
+		assert.Equal(t, parent.ID, permissions[codePerm.ID]["inherited_from"], "来源为拒绝的角色")
+	})
+
+	t.Run("不能形成环", func(t *testing.T) {
+		resp := patchRole(t, token, grandparent.ID, map[string]any{"parent_role_id": child.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "不能继承子孙角色")
+
+		resp = patchRole(t, token, child.ID, map[string]any{"parent_role_id": child.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "不能继承自身")
+
+		resp = patchRole(t, token, child.ID, map[string]any{"parent_role_id": "00000000-0000-0000-0000-000000000000"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "父角色不存在")
+	})
+
+	t.Run("不能直接继承关键角色", func(t *testing.T) {
+		// 关键角色作为祖先角色时，继承会绕过关键角色的变更审批
+		critical := testUtil.SetupTestRole()
+		_, err := testDB.Exec(`UPDATE iacc_role SET critical = true WHERE id = $1`, critical.ID)
+		require.NoError(t, err)
+		defer testDB.Exec(`UPDATE iacc_role SET critical = false WHERE id = $1`, critical.ID)
+		resp := patchRole(t, token, grandparent.ID, map[string]any{"parent_role_id": critical.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "父角色是关键角色")
+
+		_, err = testDB.Exec(`UPDATE iacc_role SET parent_role_id = $1 WHERE id = $2`, critical.ID, grandparent.ID)
+		require.NoError(t, err)
+		defer testDB.Exec(`UPDATE iacc_role SET parent_role_id = NULL WHERE id = $1`, grandparent.ID)
+		other := testUtil.SetupTestRole()
+		resp = patchRole(t, token, other.ID, map[string]any{"parent_role_id": child.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "祖先角色是关键角色")
+
+		bodyBytes, _ := json.Marshal(map[string]any{"name": "inherit-critical-" + other.ID, "parent_role_id": critical.ID})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/role", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var createResp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createResp))
+		assert.Equal(t, http.StatusBadRequest, createResp.Code, "新建角色不能继承关键角色")
+	})
+
+	t.Run("取消继承", func(t *testing.T) {
+		resp := patchRole(t, token, child.ID, map[string]any{"parent_role_id": nil})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		var parentID *string
+		require.NoError(t, testDB.Get(&parentID, `SELECT parent_role_id FROM iacc_role WHERE id = $1`, child.ID))
+		assert.Nil(t, parentID)
+	})
+}
@@ -0,0 +1,113 @@
+package role_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoleNamespaceGrant 测试按命名空间给角色授予权限
+// 包含五个子测试：授予上级命名空间包含子命名空间的权限、不传命名空间时保持不变、拒绝命名空间优先于逐个授予、查询命名空间授予、参数校验
+func TestRoleNamespaceGrant(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := getAuthToken(t, []string{})
+
+	// 用随机的顶级命名空间隔离其他测试创建的权限
+	root := "nstest_" + strings.ReplaceAll(uuid.NewString()[:8], "-", "")
+	listPerm := testUtil.SetupTestPermission("GET /v1/template/list")
+	_, err := testDB.Exec(`UPDATE iacc_permission SET namespace = $2 WHERE id = $1`, listPerm.ID, root+":template")
+	require.NoError(t, err)
+
+	role := testUtil.SetupTestRole()
+	u := testUtil.SetupTestUser()
+	testUtil.AssignRoleToUser(u.ID, role.ID)
+	userToken := testUtil.GetAccessTokenByUser(u)
+
+	sync := func(t *testing.T, body map[string]any) pkgs.Response {
+		t.Helper()
+		bodyBytes, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPut, "/v1/role/"+role.ID+"/permission/sync", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+	listCode := func(t *testing.T) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "/v1/template/list", nil)
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Code
+	}
+
+	t.Run("授予上级命名空间包含子命名空间的权限", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, listCode(t), "授予前无权限")
+
+		resp := sync(t, map[string]any{"permission_ids": []string{}, "namespaces": []string{root}})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, float64(1), resp.Data.(map[string]any)["added"])
+		assert.Equal(t, http.StatusOK, listCode(t))
+
+		var count int
+		require.NoError(t, testDB.Get(&count, `SELECT COUNT(*) FROM iacc_role_permission WHERE role_id = $1`, role.ID))
+		assert.Zero(t, count, "不逐个写入权限关联")
+	})
+
+	t.Run("不传命名空间时保持不变", func(t *testing.T) {
+		resp := sync(t, map[string]any{"permission_ids": []string{}})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, float64(0), resp.Data.(map[string]any)["removed"])
+		assert.Equal(t, http.StatusOK, listCode(t))
+	})
+
+	t.Run("拒绝命名空间优先于逐个授予", func(t *testing.T) {
+		resp := sync(t, map[string]any{"permission_ids": []string{listPerm.ID}, "namespaces": []string{}, "deny_namespaces": []string{root + ":template"}})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, float64(2), resp.Data.(map[string]any)["added"], "新增一条逐个授予与一条命名空间拒绝")
+		assert.Equal(t, float64(1), resp.Data.(map[string]any)["removed"], "移除原命名空间授予")
+		assert.Equal(t, http.StatusForbidden, listCode(t))
+	})
+
+	t.Run("查询命名空间授予", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/role/"+role.ID+"/permission", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		namespaces := resp.Data.(map[string]any)["namespaces"].([]any)
+		require.Len(t, namespaces, 1)
+		grant := namespaces[0].(map[string]any)
+		assert.Equal(t, root+":template", grant["namespace"])
+		assert.Equal(t, pkgs.RolePermissionDeny, grant["effect"])
+		assert.Equal(t, float64(1), grant["permission_count"])
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		resp := sync(t, map[string]any{"permission_ids": []string{}, "namespaces": []string{"report export"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "命名空间格式错误")
+
+		resp = sync(t, map[string]any{"permission_ids": []string{}, "namespaces": []string{"report:"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "命名空间不能有空段")
+
+		resp = sync(t, map[string]any{"permission_ids": []string{}, "namespaces": []string{"report"}, "deny_namespaces": []string{"report"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "同一命名空间不能同时授予和拒绝")
+	})
+}
@@ -0,0 +1,78 @@
+package role_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPatchRole 测试 JSON Merge Patch 部分更新角色
+// 包含三个子测试：null 清空描述、未出现的字段保持不变、不可为空字段传 null
+func TestPatchRole(t *testing.T) {
+	patch := func(t *testing.T, id string, body string) pkgs.Response {
+		req, _ := http.NewRequest(http.MethodPatch, "/v1/role/"+id, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", pkgs.MergePatchContentType)
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+	load := func(t *testing.T, id string) (string, *string) {
+		var entity struct {
+			Name        string  `db:"name"`
+			Description *string `db:"description"`
+		}
+		err := testDB.GetContext(context.Background(), &entity, `SELECT name, description FROM iacc_role WHERE id = $1`, id)
+		assert.NoError(t, err, "应该能在数据库中找到角色")
+		return entity.Name, entity.Description
+	}
+
+	t.Run("null 清空描述", func(t *testing.T) {
+		description := "待清空的描述"
+		role := createTestRole(t, "patch_role_"+uuid.NewString()[:8], &description)
+
+		resp := patch(t, role["id"].(string), `{"description": null}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		name, desc := load(t, role["id"].(string))
+		assert.Nil(t, desc, "描述应被清空为 NULL")
+		assert.Equal(t, role["name"], name, "未出现的名称应保持不变")
+	})
+
+	t.Run("未出现的字段保持不变", func(t *testing.T) {
+		description := "保留的描述"
+		role := createTestRole(t, "patch_role_"+uuid.NewString()[:8], &description)
+		newName := "patch_role_" + uuid.NewString()[:8]
+
+		resp := patch(t, role["id"].(string), `{"name": "`+newName+`"}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		name, desc := load(t, role["id"].(string))
+		assert.Equal(t, newName, name, "名称应被更新")
+		if assert.NotNil(t, desc, "描述不应被清空") {
+			assert.Equal(t, description, *desc, "描述应保持不变")
+		}
+	})
+
+	t.Run("不可为空字段传 null", func(t *testing.T) {
+		role := createTestRole(t, "patch_role_"+uuid.NewString()[:8], nil)
+
+		resp := patch(t, role["id"].(string), `{"name": null}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "响应码应该是 400")
+		assert.Contains(t, resp.Msg, "角色名称不能为空", "应提示名称不能为空")
+	})
+}
@@ -218,3 +218,80 @@ func TestRoleHandler_GetPermissions(t *testing.T) {
 		assert.Equal(t, float64(0), total, "总数应该是0")
 	})
 }
+
+// TestSyncPermission 测试同步角色权限
+// 包含三个子测试：只应用差异并保留未变化关联、空集合清空权限、权限不存在
+func TestSyncPermission(t *testing.T) {
+	syncPermissions := func(t *testing.T, roleID string, permissionIDs []string) pkgs.Response {
+		bodyBytes, _ := json.Marshal(map[string]any{"permission_ids": permissionIDs})
+		req, _ := http.NewRequest(http.MethodPut, "/v1/role/"+roleID+"/permission/sync", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+
+	t.Run("只应用差异并保留未变化关联", func(t *testing.T) {
+		entity := createTestRole(t, "同步权限测试角色", nil)
+		kept := createTestPermission(t, "同步保留权限")
+		removed := createTestPermission(t, "同步移除权限")
+		added := createTestPermission(t, "同步新增权限")
+
+		// 预置两条关联，保留的关联使用较早的时间以便验证 created_at 不变
+		_, err := testDB.ExecContext(context.Background(),
+			"INSERT INTO iacc_role_permission (role_id, permission_id, created_at) VALUES ($1, $2, '2020-01-01T00:00:00Z'), ($1, $3, DEFAULT)",
+			entity["id"], kept["id"], removed["id"])
+		assert.NoError(t, err, "应该能成功插入权限关联")
+
+		resp := syncPermissions(t, entity["id"].(string), []string{kept["id"].(string), added["id"].(string)})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		data := resp.Data.(map[string]any)
+		assert.Equal(t, float64(1), data["added"], "应新增 1 条关联")
+		assert.Equal(t, float64(1), data["removed"], "应移除 1 条关联")
+
+		var links []struct {
+			PermissionID string `db:"permission_id"`
+			CreatedAt    string `db:"created_at"`
+		}
+		err = testDB.SelectContext(context.Background(), &links,
+			"SELECT permission_id, to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS created_at FROM iacc_role_permission WHERE role_id = $1", entity["id"])
+		assert.NoError(t, err, "应该能查询权限关联")
+		assert.Len(t, links, 2, "同步后应有两条关联")
+		for _, link := range links {
+			assert.NotEqual(t, removed["id"], link.PermissionID, "多余的关联应被删除")
+			if link.PermissionID == kept["id"] {
+				assert.Equal(t, "2020-01-01", link.CreatedAt, "未变化的关联应保留 created_at")
+			}
+		}
+	})
+
+	t.Run("空集合清空权限", func(t *testing.T) {
+		entity := createTestRole(t, "同步清空测试角色", nil)
+		perm := createTestPermission(t, "同步清空权限")
+		_, err := testDB.ExecContext(context.Background(),
+			"INSERT INTO iacc_role_permission (role_id, permission_id) VALUES ($1, $2)", entity["id"], perm["id"])
+		assert.NoError(t, err, "应该能成功插入权限关联")
+
+		resp := syncPermissions(t, entity["id"].(string), []string{})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		var count int64
+		err = testDB.GetContext(context.Background(), &count, "SELECT COUNT(*) FROM iacc_role_permission WHERE role_id = $1", entity["id"])
+		assert.NoError(t, err, "应该能够执行计数查询")
+		assert.Equal(t, int64(0), count, "角色权限应被清空")
+	})
+
+	t.Run("权限不存在", func(t *testing.T) {
+		entity := createTestRole(t, "同步无效权限测试角色", nil)
+
+		resp := syncPermissions(t, entity["id"].(string), []string{"00000000-0000-0000-0000-000000000000"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "响应码应该是 400")
+	})
+}
@@ -1,9 +1,11 @@
 package role_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"go-pg-demo/pkgs"
@@ -74,3 +76,88 @@ func TestQueryRoleList(t *testing.T) {
 		assert.Equal(t, 1, len(list), "应该只返回一条匹配的记录")
 	})
 }
+
+// TestRoleExists 测试角色名称存在性检查功能
+// 包含两个子测试：名称已存在、名称不存在
+func TestRoleExists(t *testing.T) {
+	description := "存在性测试描述"
+	createTestRole(t, "存在性测试角色", &description)
+
+	testCases := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{name: "名称已存在", query: "存在性测试角色", want: true},
+		{name: "名称不存在", query: "不存在的角色名称", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/v1/role/exists?name="+url.QueryEscape(tc.query), nil)
+			token := getAuthToken(t, []string{})
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			// 执行
+			w := httptest.NewRecorder()
+			testRouter.ServeHTTP(w, req)
+
+			// 断言
+			var resp pkgs.Response
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tc.want, resp.Data)
+		})
+	}
+}
+
+// TestRoleListConditional 测试角色列表的条件请求
+// 携带上次响应的 ETag 时返回 304，角色变化后重新返回列表
+func TestRoleListConditional(t *testing.T) {
+	token := getAuthToken(t, []string{})
+	list := func(etag string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/role/list", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	createTestRole(t, "条件请求角色", nil)
+	w := list("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+
+	w = list(etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+
+	createTestRole(t, "条件请求角色2", nil)
+	w = list(etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+// TestRoleListCanceled 测试客户端断开后的列表查询：不返回成功，也不返回部分结果
+func TestRoleListCanceled(t *testing.T) {
+	token := getAuthToken(t, []string{})
+	createTestRole(t, "取消请求角色", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/v1/role/list", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	var resp pkgs.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), "响应体应该能正确解析为Response结构体")
+	assert.NotEqual(t, http.StatusOK, resp.Code, "已取消的请求不应返回成功")
+	assert.Nil(t, resp.Data, "已取消的请求不应返回数据")
+}
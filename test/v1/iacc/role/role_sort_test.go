@@ -4,9 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"strings"
 	"testing"
-	"time"
 
 	"go-pg-demo/pkgs"
 
@@ -228,10 +226,10 @@ func TestQueryListRoles_Sort(t *testing.T) {
 
 		assert.Equal(t, 3, len(list))
 		if len(list) >= 3 {
-			// 验证排序顺序 - 使用时间戳比较函数
-			assert.True(t, compareTimestamps(id1["created_at"].(string), list[0].(map[string]any)["created_at"].(string)))
-			assert.True(t, compareTimestamps(id2["created_at"].(string), list[1].(map[string]any)["created_at"].(string)))
-			assert.True(t, compareTimestamps(id3["created_at"].(string), list[2].(map[string]any)["created_at"].(string)))
+			// 验证排序顺序 - 时间相同时按 seq 决胜，顺序与创建顺序一致
+			assert.Equal(t, id1["id"], list[0].(map[string]any)["id"])
+			assert.Equal(t, id2["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, id3["id"], list[2].(map[string]any)["id"])
 		}
 	})
 
@@ -258,10 +256,10 @@ func TestQueryListRoles_Sort(t *testing.T) {
 
 		assert.Equal(t, 3, len(list))
 		if len(list) >= 3 {
-			// 验证排序顺序 - 使用时间戳比较函数
-			assert.True(t, compareTimestamps(id3["created_at"].(string), list[0].(map[string]any)["created_at"].(string)))
-			assert.True(t, compareTimestamps(id2["created_at"].(string), list[1].(map[string]any)["created_at"].(string)))
-			assert.True(t, compareTimestamps(id1["created_at"].(string), list[2].(map[string]any)["created_at"].(string)))
+			// 验证排序顺序 - 时间相同时按 seq 决胜，顺序与创建顺序一致
+			assert.Equal(t, id3["id"], list[0].(map[string]any)["id"])
+			assert.Equal(t, id2["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, id1["id"], list[2].(map[string]any)["id"])
 		}
 	})
 
@@ -288,10 +286,10 @@ func TestQueryListRoles_Sort(t *testing.T) {
 
 		assert.Equal(t, 3, len(list))
 		if len(list) >= 3 {
-			// 验证排序顺序 - 使用时间戳比较函数
-			assert.True(t, compareTimestamps(id1["updated_at"].(string), list[0].(map[string]any)["updated_at"].(string)))
-			assert.True(t, compareTimestamps(id2["updated_at"].(string), list[1].(map[string]any)["updated_at"].(string)))
-			assert.True(t, compareTimestamps(id3["updated_at"].(string), list[2].(map[string]any)["updated_at"].(string)))
+			// 验证排序顺序 - 时间相同时按 seq 决胜，顺序与创建顺序一致
+			assert.Equal(t, id1["id"], list[0].(map[string]any)["id"])
+			assert.Equal(t, id2["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, id3["id"], list[2].(map[string]any)["id"])
 		}
 	})
 
@@ -318,10 +316,10 @@ func TestQueryListRoles_Sort(t *testing.T) {
 
 		assert.Equal(t, 3, len(list))
 		if len(list) >= 3 {
-			// 验证排序顺序 - 使用时间戳比较函数
-			assert.True(t, compareTimestamps(id3["updated_at"].(string), list[0].(map[string]any)["updated_at"].(string)))
-			assert.True(t, compareTimestamps(id2["updated_at"].(string), list[1].(map[string]any)["updated_at"].(string)))
-			assert.True(t, compareTimestamps(id1["updated_at"].(string), list[2].(map[string]any)["updated_at"].(string)))
+			// 验证排序顺序 - 时间相同时按 seq 决胜，顺序与创建顺序一致
+			assert.Equal(t, id3["id"], list[0].(map[string]any)["id"])
+			assert.Equal(t, id2["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, id1["id"], list[2].(map[string]any)["id"])
 		}
 	})
 
@@ -361,26 +359,3 @@ func TestQueryListRoles_Sort(t *testing.T) {
 		assert.Equal(t, "排序顺序参数错误", resp.Msg)
 	})
 }
-
-// compareTimestamps 比较两个时间戳字符串，只比较到秒级精度
-func compareTimestamps(t1, t2 string) bool {
-	// 解析时间戳
-	parsedT1, err1 := time.Parse(time.RFC3339Nano, t1)
-	parsedT2, err2 := time.Parse(time.RFC3339Nano, t2)
-
-	if err1 != nil || err2 != nil {
-		// 如果解析失败，尝试其他格式
-		parsedT1, err1 = time.Parse("2006-01-02 15:04:05.999999", t1)
-		parsedT2, err2 = time.Parse("2006-01-02 15:04:05.999999", t2)
-
-		if err1 != nil || err2 != nil {
-			// 如果还是失败，直接比较字符串（去掉微秒部分）
-			t1Sec := strings.Split(t1, ".")[0]
-			t2Sec := strings.Split(t2, ".")[0]
-			return t1Sec == t2Sec
-		}
-	}
-
-	// 比较到秒级精度
-	return parsedT1.Truncate(time.Second).Equal(parsedT2.Truncate(time.Second))
-}
@@ -0,0 +1,73 @@
+package role_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRoleTranslation 测试角色显示名称的多语言翻译
+// 依次设置翻译、按 Accept-Language 获取详情、查询全部翻译、删除翻译，以及非法语言标签
+func TestRoleTranslation(t *testing.T) {
+	description := "原始描述"
+	role := createTestRole(t, "翻译测试角色", &description)
+	id := role["id"].(string)
+	token := getAuthToken(t, []string{})
+
+	do := func(method, path, acceptLanguage string, body any) pkgs.Response {
+		var reader *bytes.Reader
+		if body != nil {
+			bodyBytes, _ := json.Marshal(body)
+			reader = bytes.NewReader(bodyBytes)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req, _ := http.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := do(http.MethodPut, "/v1/role/"+id+"/translation/en-us", "", map[string]any{"name": "Translated role", "description": "English description"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// 按 Accept-Language 返回翻译，没有匹配的语言时返回原始名称
+	resp = do(http.MethodGet, "/v1/role/"+id, "en-US,en;q=0.9", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	data := resp.Data.(map[string]any)
+	assert.Equal(t, "Translated role", data["name"])
+	assert.Equal(t, "English description", data["description"])
+
+	resp = do(http.MethodGet, "/v1/role/"+id, "ja", nil)
+	data = resp.Data.(map[string]any)
+	assert.Equal(t, "翻译测试角色", data["name"])
+	assert.Equal(t, description, data["description"])
+
+	// 语言标签保存时规范化
+	resp = do(http.MethodGet, "/v1/role/"+id+"/translation", "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Data.(map[string]any), "en-US")
+
+	resp = do(http.MethodDelete, "/v1/role/"+id+"/translation/en-US", "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, float64(1), resp.Data)
+
+	resp = do(http.MethodGet, "/v1/role/"+id, "en-US", nil)
+	assert.Equal(t, "翻译测试角色", resp.Data.(map[string]any)["name"])
+
+	resp = do(http.MethodPut, "/v1/role/"+id+"/translation/en_US", "", map[string]any{"name": "x"})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,94 @@
+package user_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkAvailability 批量检查用户名、手机号是否可用
+func checkAvailability(t *testing.T, token string, body map[string]any) pkgs.Response {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, "/v1/user/check-availability", bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// availabilityItems 解析响应中某一组候选项的可用性
+func availabilityItems(t *testing.T, resp pkgs.Response, key string) []map[string]any {
+	t.Helper()
+	data, ok := resp.Data.(map[string]any)
+	require.True(t, ok, "响应数据应为可用性结果")
+	var items []map[string]any
+	for _, item := range data[key].([]any) {
+		items = append(items, item.(map[string]any))
+	}
+	return items
+}
+
+// TestCheckAvailability 测试批量检查用户名、手机号是否可用
+// 包含三个子测试：已被使用与可用的项、请求中重复的项、未传任何候选项
+func TestCheckAvailability(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := testUtil.GetAccessUserToken([]string{"POST /v1/user/check-availability"})
+	existing := setupTestUser(t)
+
+	t.Run("已被使用与可用的项", func(t *testing.T) {
+		resp := checkAvailability(t, token, map[string]any{
+			"usernames": []string{"avail_free_user", existing["username"].(string)},
+			"phones":    []string{existing["phone"].(string), "19900000000"},
+		})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		usernames := availabilityItems(t, resp, "usernames")
+		require.Len(t, usernames, 2)
+		assert.Equal(t, "avail_free_user", usernames[0]["value"], "按请求顺序返回")
+		assert.Equal(t, true, usernames[0]["available"])
+		assert.Equal(t, false, usernames[1]["available"])
+		assert.Equal(t, "taken", usernames[1]["reason"])
+
+		phones := availabilityItems(t, resp, "phones")
+		require.Len(t, phones, 2)
+		assert.Equal(t, false, phones[0]["available"])
+		assert.Equal(t, "taken", phones[0]["reason"])
+		assert.Equal(t, true, phones[1]["available"])
+		assert.NotContains(t, phones[1], "reason")
+	})
+
+	t.Run("请求中重复的项", func(t *testing.T) {
+		resp := checkAvailability(t, token, map[string]any{
+			"usernames": []string{"avail_dup_user", "avail_dup_user"},
+		})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		usernames := availabilityItems(t, resp, "usernames")
+		require.Len(t, usernames, 2)
+		assert.Equal(t, true, usernames[0]["available"], "第一次出现的项可用")
+		assert.Equal(t, false, usernames[1]["available"])
+		assert.Equal(t, "duplicate", usernames[1]["reason"])
+		assert.Empty(t, availabilityItems(t, resp, "phones"))
+	})
+
+	t.Run("未传任何候选项", func(t *testing.T) {
+		resp := checkAvailability(t, token, map[string]any{})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = checkAvailability(t, token, map[string]any{"phones": []string{"123"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "手机号长度错误")
+	})
+}
@@ -19,9 +19,12 @@ import (
 
 // 全局测试变量
 var (
-	testDB     *sqlx.DB    // 测试数据库连接
-	testLogger *zap.Logger // 测试日志记录器
-	testRouter *gin.Engine // 测试路由器
+	testDB     *sqlx.DB             // 测试数据库连接
+	testLogger *zap.Logger          // 测试日志记录器
+	testRouter *gin.Engine          // 测试路由器
+	testHasher *pkgs.PasswordHasher // 校验数据库中保存的密码哈希
+	testJobs   *pkgs.JobQueue       // 执行异步导入等任务
+	testConfig *pkgs.Config         // 应用配置
 )
 
 // TestMain 初始化测试环境
@@ -40,6 +43,12 @@ func TestMain(m *testing.M) {
 	testDB = testApp.DB
 	testLogger = testApp.Logger
 	testRouter = testApp.Server
+	testJobs = testApp.Scheduler.Jobs
+	testConfig = testApp.Conf
+	testHasher, err = pkgs.NewPasswordHasher(testApp.Conf)
+	if err != nil {
+		os.Exit(1)
+	}
 
 	// 运行测试
 	exitCode := m.Run()
@@ -137,26 +146,3 @@ func createSortTestUser(t *testing.T, username string, phone string) map[string]
 	}
 	return createTestUser(t, username, phone, "password123")
 }
-
-// compareTimestamps 比较两个时间戳字符串，只比较到秒级精度
-func compareTimestamps(t1, t2 string) bool {
-	// 解析时间戳
-	parsedT1, err1 := time.Parse(time.RFC3339Nano, t1)
-	parsedT2, err2 := time.Parse(time.RFC3339Nano, t2)
-
-	if err1 != nil || err2 != nil {
-		// 如果解析失败，尝试其他格式
-		parsedT1, err1 = time.Parse("2006-01-02 15:04:05.999999", t1)
-		parsedT2, err2 = time.Parse("2006-01-02 15:04:05.999999", t2)
-
-		if err1 != nil || err2 != nil {
-			// 如果还是失败，直接比较字符串（去掉微秒部分）
-			t1Sec := t1[:19] // 取前19个字符，即 "2006-01-02 15:04:05"
-			t2Sec := t2[:19]
-			return t1Sec == t2Sec
-		}
-	}
-
-	// 比较到秒级精度
-	return parsedT1.Truncate(time.Second).Equal(parsedT2.Truncate(time.Second))
-}
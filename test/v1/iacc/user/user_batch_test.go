@@ -16,7 +16,7 @@ import (
 )
 
 // TestBatchCreateUsers 测试批量创建用户功能
-// 包含两个子测试：成功批量创建、无效输入-空列表
+// 包含三个子测试：成功批量创建、无效输入-空列表、无效输入-批次内手机号重复
 func TestBatchCreateUsers(t *testing.T) {
 	t.Run("成功", func(t *testing.T) {
 		// 准备
@@ -87,10 +87,54 @@ func TestBatchCreateUsers(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, errResp.Code)
 		assert.Contains(t, errResp.Msg, "用户列表必须至少包含1项")
 	})
+
+	t.Run("无效输入 - 批次内手机号重复", func(t *testing.T) {
+		// 准备：第 0 项与第 2 项手机号相同，第 1 项同时缺少密码，所有问题应一次性返回
+		phone := "138" + uuid.NewString()[:8]
+		batchCreateReq := map[string]any{
+			"users": []map[string]any{
+				{"username": "批量用户1_" + uuid.NewString()[:8], "phone": phone, "password": "password123"},
+				{"username": "批量用户2_" + uuid.NewString()[:8], "phone": "138" + uuid.NewString()[:8]},
+				{"username": "批量用户3_" + uuid.NewString()[:8], "phone": phone, "password": "password123"},
+			},
+		}
+		bodyBytes, _ := json.Marshal(batchCreateReq)
+		req, _ := http.NewRequest(http.MethodPost, "/v1/user/batch-create", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// 执行
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var errResp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &errResp)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		assert.Contains(t, errResp.Msg, "密码为必填字段", "应包含字段校验错误")
+		assert.Contains(t, errResp.Msg, "手机号存在重复值", "应包含重复校验错误")
+
+		violations, ok := errResp.Data.([]any)
+		if assert.True(t, ok, "响应数据应为违规明细列表") && assert.Len(t, violations, 2) {
+			dup := violations[1].(map[string]any)
+			assert.Equal(t, "users.phone", dup["field"])
+			assert.Equal(t, []any{float64(0), float64(2)}, dup["indexes"], "应返回重复项的下标")
+		}
+
+		// 整批不应写入
+		var count int
+		err = testDB.GetContext(context.Background(), &count, `SELECT count(*) FROM "iacc_user" WHERE phone = $1`, phone)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count, "校验失败时不应创建任何用户")
+	})
 }
 
 // TestBatchDeleteUsers 测试批量删除用户功能
-// 包含两个子测试：成功批量删除、无效输入-空ID列表
+// 包含三个子测试：成功批量删除、无效输入-ID重复、无效输入-空ID列表
 func TestBatchDeleteUsers(t *testing.T) {
 	t.Run("成功", func(t *testing.T) {
 		// 准备
@@ -130,6 +174,31 @@ func TestBatchDeleteUsers(t *testing.T) {
 		assert.Equal(t, 0, count, "用户应已被删除")
 	})
 
+	t.Run("无效输入 - ID重复", func(t *testing.T) {
+		// 准备
+		entity := setupTestUser(t)
+		id := entity["id"].(string)
+		deleteReq := map[string]any{"ids": []string{id, id}}
+		bodyBytes, _ := json.Marshal(deleteReq)
+		req, _ := http.NewRequest(http.MethodPost, "/v1/user/batch-delete", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// 执行
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var errResp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &errResp)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		assert.Contains(t, errResp.Msg, "用户ID存在重复值")
+	})
+
 	t.Run("无效输入 - 空ID列表", func(t *testing.T) {
 		// 准备
 		deleteReq := map[string]any{
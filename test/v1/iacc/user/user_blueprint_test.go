@@ -0,0 +1,76 @@
+package user_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateUserFromBlueprint 测试按蓝图创建用户
+// 包含子测试：成功（分配角色、使用默认邮箱）、蓝图不存在
+func TestCreateUserFromBlueprint(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := testUtil.GetAccessUserToken([]string{})
+	role := testUtil.SetupTestRole()
+
+	// 准备蓝图：一个存在的角色和一个已删除的角色
+	blueprintID := uuid.NewString()
+	_, err := testDB.ExecContext(context.Background(),
+		`INSERT INTO "iacc_blueprint" (id, name, role_ids, profile) VALUES ($1, $2, $3, $4)`,
+		blueprintID, "蓝图-"+blueprintID[:8], pq.Array([]string{role.ID, uuid.NewString()}), `{"email":"onboard@example.com"}`)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = testDB.ExecContext(context.Background(), `DELETE FROM "iacc_blueprint" WHERE id = $1`, blueprintID)
+	})
+
+	post := func(id string, body map[string]any) pkgs.Response {
+		bodyBytes, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, "/v1/user/from-blueprint/"+id, bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		var resp pkgs.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	t.Run("成功", func(t *testing.T) {
+		username := "bp_" + uuid.NewString()[:8]
+		resp := post(blueprintID, map[string]any{"username": username, "phone": "13" + uuid.NewString()[:9], "password": "password123"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		data := resp.Data.(map[string]any)
+		userID := data["id"].(string)
+		t.Cleanup(func() {
+			_, _ = testDB.ExecContext(context.Background(), `DELETE FROM "iacc_user_role" WHERE user_id = $1`, userID)
+			_, _ = testDB.ExecContext(context.Background(), `DELETE FROM "iacc_user" WHERE id = $1`, userID)
+		})
+
+		// 已删除的角色被跳过
+		assert.Equal(t, []any{role.ID}, data["role_ids"])
+
+		var count int
+		assert.NoError(t, testDB.GetContext(context.Background(), &count,
+			`SELECT count(*) FROM "iacc_user_role" WHERE user_id = $1 AND role_id = $2`, userID, role.ID))
+		assert.Equal(t, 1, count)
+
+		var email string
+		assert.NoError(t, testDB.GetContext(context.Background(), &email,
+			`SELECT profile->>'email' FROM "iacc_user" WHERE id = $1`, userID))
+		assert.Equal(t, "onboard@example.com", email)
+	})
+
+	t.Run("蓝图不存在", func(t *testing.T) {
+		resp := post(uuid.NewString(), map[string]any{"username": "bp_" + uuid.NewString()[:8], "phone": "13800000000", "password": "password123"})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -55,14 +55,17 @@ func TestCreateUser(t *testing.T) {
 		type UserEntity struct {
 			ID        string `db:"id"`
 			Phone     string `db:"phone"`
+			Password  string `db:"password"`
 			CreatedAt string `db:"created_at"`
 			UpdatedAt string `db:"updated_at"`
 		}
 		var entity UserEntity
-		query := `SELECT id, phone, created_at, updated_at FROM "iacc_user" WHERE id = $1`
+		query := `SELECT id, phone, password, created_at, updated_at FROM "iacc_user" WHERE id = $1`
 		err = testDB.GetContext(context.Background(), &entity, query, createdID)
 		assert.NoError(t, err, "应该能在数据库中找到创建的用户")
 		assert.Equal(t, phone, entity.Phone, "用户手机号应该匹配")
+		assert.NotEqual(t, password, entity.Password, "不应保存明文密码")
+		assert.True(t, testHasher.Verify(entity.Password, password), "密码哈希应与密码匹配")
 
 		// 清理
 		t.Cleanup(func() {
@@ -196,7 +199,7 @@ func TestCreateUser(t *testing.T) {
 }
 
 // TestGetUserByID 测试根据ID获取用户功能
-// 包含四个子测试：成功获取、成功获取带Profile数据、无效ID、用户不存在
+// 包含六个子测试：成功获取、成功获取带Profile数据、无效ID、用户不存在、返回扩展内容、不支持的扩展内容
 func TestGetUserByID(t *testing.T) {
 	t.Run("成功", func(t *testing.T) {
 		// 准备
@@ -205,7 +208,7 @@ func TestGetUserByID(t *testing.T) {
 		// 创建 TestUtil 实例
 		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
 		// 获取token
-		token := testUtil.GetAccessUserToken([]string{})
+		token := testUtil.GetAccessUserTokenWithCodes([]string{pkgs.PermissionCodeViewPII})
 
 		// 执行
 		req, _ := http.NewRequest(http.MethodGet, "/v1/user/"+entity["id"].(string), nil)
@@ -270,6 +273,85 @@ func TestGetUserByID(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, resp.Code, "响应码应该是 404")
 	})
 
+	t.Run("返回扩展内容", func(t *testing.T) {
+		// 准备：两个角色，其中一个角色拒绝另一个角色允许的权限；一条设备登录记录
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		entity := setupTestUser(t)
+		userID := entity["id"].(string)
+		allowRole := testUtil.SetupTestRole()
+		denyRole := testUtil.SetupTestRole()
+		allowed := testUtil.SetupTestPermission("GET /v1/include-test/allowed")
+		denied := testUtil.SetupTestPermission("GET /v1/include-test/denied")
+		testUtil.AssignRoleToUser(userID, allowRole.ID)
+		testUtil.AssignRoleToUser(userID, denyRole.ID)
+		testUtil.AssignPermissionToRole(allowRole.ID, allowed.ID)
+		testUtil.AssignPermissionToRole(allowRole.ID, denied.ID)
+		testUtil.DenyPermissionForRole(denyRole.ID, denied.ID)
+		_, err := testDB.ExecContext(context.Background(),
+			`INSERT INTO iacc_user_device (user_id, device_id, user_agent, last_ip) VALUES ($1, 'include-test-device', 'Mozilla/5.0', '10.0.0.1')`, userID)
+		assert.NoError(t, err, "创建设备记录不应出错")
+
+		token := testUtil.GetAccessUserToken([]string{})
+
+		// 执行
+		req, _ := http.NewRequest(http.MethodGet, "/v1/user/"+userID+"?include=roles,permissions,loginHistory", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), "解析响应体不应出错")
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		data, ok := resp.Data.(map[string]any)
+		assert.True(t, ok, "响应数据应该是一个 map")
+
+		roles, _ := data["roles"].([]any)
+		assert.Len(t, roles, 2, "应返回用户的两个角色")
+
+		effects := map[string]any{}
+		permissions, _ := data["permissions"].([]any)
+		for _, p := range permissions {
+			item := p.(map[string]any)
+			effects[item["id"].(string)] = item["effect"]
+		}
+		assert.Equal(t, pkgs.RolePermissionAllow, effects[allowed.ID])
+		assert.Equal(t, pkgs.RolePermissionDeny, effects[denied.ID], "任一角色拒绝时效果为 deny")
+
+		history, _ := data["login_history"].([]any)
+		if assert.Len(t, history, 1) {
+			assert.Equal(t, "include-test-device", history[0].(map[string]any)["device_id"])
+			assert.Equal(t, "10.0.0.1", history[0].(map[string]any)["last_ip"])
+		}
+
+		// 未指定 include 时不返回扩展内容
+		req, _ = http.NewRequest(http.MethodGet, "/v1/user/"+userID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w = httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		resp = pkgs.Response{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		data, _ = resp.Data.(map[string]any)
+		assert.NotContains(t, data, "roles")
+		assert.NotContains(t, data, "permissions")
+		assert.NotContains(t, data, "login_history")
+	})
+
+	t.Run("不支持的扩展内容", func(t *testing.T) {
+		entity := setupTestUser(t)
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/user/"+entity["id"].(string)+"?include=devices", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
 	t.Run("成功获取带Profile数据", func(t *testing.T) {
 		// 准备 - 创建一个带有Profile数据的用户
 		username := "testuser5_" + uuid.NewString()[:8]
@@ -294,7 +376,7 @@ func TestGetUserByID(t *testing.T) {
 		// 创建 TestUtil 实例
 		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
 		// 获取token
-		token := testUtil.GetAccessUserToken([]string{})
+		token := testUtil.GetAccessUserTokenWithCodes([]string{pkgs.PermissionCodeViewPII})
 
 		// 执行
 		req, _ := http.NewRequest(http.MethodGet, "/v1/user/"+userID, nil)
@@ -354,7 +436,7 @@ func TestUpdateUser(t *testing.T) {
 		assert.NoError(t, err, "解析响应体不应出错")
 		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
 
-		// 验证用户确实被更新
+		// 验证用户确实被更新，数据库中只保存密码哈希
 		type UpdatedUser struct {
 			Password string `db:"password"`
 		}
@@ -362,7 +444,8 @@ func TestUpdateUser(t *testing.T) {
 		query := `SELECT password FROM "iacc_user" WHERE id = $1`
 		err = testDB.GetContext(context.Background(), &updatedUser, query, entity["id"])
 		assert.NoError(t, err, "应该能在数据库中找到更新的用户")
-		assert.Equal(t, newPassword, updatedUser.Password, "用户密码应该已被更新")
+		assert.NotEqual(t, newPassword, updatedUser.Password, "不应保存明文密码")
+		assert.True(t, testHasher.Verify(updatedUser.Password, newPassword), "用户密码应该已被更新")
 	})
 
 	t.Run("无效ID", func(t *testing.T) {
@@ -0,0 +1,144 @@
+package user_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go-pg-demo/internal/modules/iacc/user"
+	"go-pg-demo/pkgs"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryList 请求用户列表，返回响应码与列表响应
+func queryList(t *testing.T, token string, query url.Values) (int, user.QueryListRes) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/user/list?"+query.Encode(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	var res user.QueryListRes
+	if resp.Code == http.StatusOK {
+		raw, _ := json.Marshal(resp.Data)
+		require.NoError(t, json.Unmarshal(raw, &res))
+	}
+	return resp.Code, res
+}
+
+// TestQueryUserListByCursor 测试用户列表的游标分页
+// 包含三个子测试：逐页遍历不重复不遗漏、升序遍历、无效参数
+func TestQueryUserListByCursor(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := testUtil.GetAccessUserToken([]string{})
+
+	// 只遍历本测试创建的用户
+	since := time.Now().Add(-time.Second).UTC().Format(time.RFC3339Nano)
+	created := map[string]bool{}
+	for range 5 {
+		created[setupTestUser(t)["id"].(string)] = true
+	}
+
+	traverse := func(t *testing.T, order string) []string {
+		var ids []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 10, "翻页次数超出预期")
+			query := url.Values{"limit": {"2"}, "order": {order}, "createdFrom": {since}}
+			if cursor != "" {
+				query.Set("cursor", cursor)
+			}
+			code, res := queryList(t, token, query)
+			require.Equal(t, http.StatusOK, code)
+			assert.LessOrEqual(t, len(res.List), 2)
+			assert.Zero(t, res.Total, "游标分页不统计总数")
+			for _, item := range res.List {
+				ids = append(ids, item.ID)
+			}
+			if res.NextCursor == "" {
+				return ids
+			}
+			cursor = res.NextCursor
+		}
+	}
+
+	t.Run("逐页遍历不重复不遗漏", func(t *testing.T) {
+		ids := traverse(t, "desc")
+		seen := map[string]bool{}
+		for _, id := range ids {
+			assert.False(t, seen[id], "用户重复返回")
+			seen[id] = true
+		}
+		for id := range created {
+			assert.True(t, seen[id], "用户未返回")
+		}
+	})
+
+	t.Run("升序遍历", func(t *testing.T) {
+		desc := traverse(t, "desc")
+		asc := traverse(t, "asc")
+		require.Len(t, asc, len(desc))
+		for i := range asc {
+			assert.Equal(t, desc[len(desc)-1-i], asc[i])
+		}
+	})
+
+	t.Run("无效参数", func(t *testing.T) {
+		code, _ := queryList(t, token, url.Values{"cursor": {"invalid"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = queryList(t, token, url.Values{"limit": {"10"}, "orderBy": {"username"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = queryList(t, token, url.Values{"limit": {"101"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+// TestCursorKeysetUsesIndex 测试游标分页的查询条件与排序可以直接使用各表的 (created_at, seq) 索引，不需要额外排序
+// 测试库数据量小，规划器倾向于全表扫描，因此在事务内关闭顺序扫描后检查执行计划
+func TestCursorKeysetUsesIndex(t *testing.T) {
+	_, cursor := pkgs.CursorPage(pkgs.CursorPagination{Limit: 1}, []int64{1, 2}, func(seq int64) (time.Time, int64) { return time.Now(), seq })
+	require.NotEmpty(t, cursor)
+
+	cases := []struct {
+		table string
+		index string
+	}{
+		{"iacc_user", "idx_iacc_user_created_at_seq"},
+		{"iacc_user_search", "idx_iacc_user_search_created_at_seq"},
+		{"iacc_role", "idx_iacc_role_created_at_seq"},
+		{"iacc_permission", "idx_iacc_permission_created_at_seq"},
+		{"template", "idx_template_created_at_seq"},
+	}
+	for _, tc := range cases {
+		for _, order := range []string{"ASC", "DESC"} {
+			t.Run(tc.table+" "+order, func(t *testing.T) {
+				params := map[string]any{}
+				where, orderClause := pkgs.CursorPagination{Cursor: cursor, Limit: 10}.Keyset("", order, params)
+				query, args, err := sqlx.Named(`EXPLAIN (FORMAT JSON) SELECT id FROM "`+tc.table+`"`+where+orderClause, params)
+				require.NoError(t, err)
+
+				tx, err := testDB.Beginx()
+				require.NoError(t, err)
+				defer tx.Rollback()
+				_, err = tx.Exec(`SET LOCAL enable_seqscan = off`)
+				require.NoError(t, err)
+				var plan string
+				require.NoError(t, tx.Get(&plan, tx.Rebind(query), args...))
+
+				assert.Contains(t, plan, tc.index, "应使用 (created_at, seq) 索引")
+				assert.NotContains(t, plan, `Sort"`, "索引顺序即游标顺序，不应再排序")
+			})
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package user_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSensitiveFields 测试敏感字段的影子列及按邮箱查询
+// 包含两个子测试：创建用户写入影子列、按邮箱精确查询（不区分大小写）
+func TestSensitiveFields(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := testUtil.GetAccessUserTokenWithCodes([]string{pkgs.PermissionCodeViewPII})
+
+	// 通过接口创建带邮箱的用户
+	phone := "138" + uuid.NewString()[:8]
+	email := "Sensitive_" + uuid.NewString()[:8] + "@example.com"
+	createReqBody := map[string]any{
+		"username": "sensitive_" + uuid.NewString()[:8],
+		"phone":    phone,
+		"password": "password123",
+		"profile":  map[string]any{"email": email},
+	}
+	bodyBytes, _ := json.Marshal(createReqBody)
+	req, _ := http.NewRequest(http.MethodPost, "/v1/user", bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	var createResp pkgs.Response
+	err := json.Unmarshal(w.Body.Bytes(), &createResp)
+	assert.NoError(t, err, "解析响应体不应出错")
+	assert.Equal(t, http.StatusOK, createResp.Code, "响应码应该是 200")
+	createdID, _ := createResp.Data.(string)
+
+	t.Cleanup(func() {
+		_, err := testDB.ExecContext(context.Background(), `DELETE FROM "iacc_user" WHERE id = $1`, createdID)
+		assert.NoError(t, err, "清理创建的用户不应出错")
+	})
+
+	t.Run("创建用户写入影子列", func(t *testing.T) {
+		var entity struct {
+			PhoneHash *string `db:"phone_hash"`
+			EmailHash *string `db:"email_hash"`
+		}
+		query := `SELECT phone_hash, email_hash FROM "iacc_user" WHERE id = $1`
+		err := testDB.GetContext(context.Background(), &entity, query, createdID)
+		assert.NoError(t, err, "应该能在数据库中找到创建的用户")
+		if assert.NotNil(t, entity.PhoneHash, "手机号影子列不应为空") {
+			assert.Equal(t, pkgs.BlindIndex(phone), *entity.PhoneHash, "手机号影子列应与明文 HMAC 一致")
+		}
+		if assert.NotNil(t, entity.EmailHash, "邮箱影子列不应为空") {
+			assert.Equal(t, pkgs.EmailBlindIndex(email), *entity.EmailHash, "邮箱影子列应与明文 HMAC 一致")
+		}
+	})
+
+	t.Run("按邮箱精确查询", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/user/list?email="+strings.ToUpper(email), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		data := resp.Data.(map[string]any)
+		assert.Equal(t, 1, int(data["total"].(float64)), "应该只匹配到一个用户")
+		list := data["list"].([]any)
+		if assert.Len(t, list, 1, "列表长度应该为1") {
+			item := list[0].(map[string]any)
+			assert.Equal(t, createdID, item["id"], "匹配到的用户 ID 应一致")
+			assert.Equal(t, phone, item["phone"], "返回的手机号应为明文")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package user_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportUsers 请求导出用户
+func exportUsers(t *testing.T, token string, query url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/user/export?"+query.Encode(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	return w
+}
+
+// TestExportUsers 测试导出用户
+// 包含四个子测试：CSV 按筛选条件导出、按列表的排序导出、XLSX 导出、无效的格式
+func TestExportUsers(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := testUtil.GetAccessUserToken([]string{"GET /v1/user/export"})
+
+	first := testUtil.SetupTestUser()
+	second := testUtil.SetupTestUser()
+	role := testUtil.SetupTestRole()
+	testUtil.AssignRoleToUser(first.ID, role.ID)
+	filter := url.Values{"filter": {"id:in:" + first.ID + "|" + second.ID}}
+
+	t.Run("CSV 按筛选条件导出", func(t *testing.T) {
+		w := exportUsers(t, token, filter)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3, "表头与两个用户")
+		assert.Equal(t, []string{"id", "username", "phone", "email", "role_names", "disabled_at", "created_at", "updated_at"}, records[0])
+
+		byID := map[string][]string{}
+		for _, record := range records[1:] {
+			byID[record[0]] = record
+		}
+		require.Contains(t, byID, first.ID)
+		assert.Equal(t, first.Username, byID[first.ID][1])
+		assert.Equal(t, role.Name, byID[first.ID][4])
+		assert.Empty(t, byID[second.ID][4])
+		assert.NotEqual(t, first.Phone, byID[first.ID][2], "没有 user:view_pii 权限时手机号脱敏")
+	})
+
+	t.Run("按列表的排序导出", func(t *testing.T) {
+		query := url.Values{"filter": filter["filter"], "orderBy": {"created_at"}, "order": {"asc"}}
+		records, err := csv.NewReader(exportUsers(t, token, query).Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, first.ID, records[1][0])
+		assert.Equal(t, second.ID, records[2][0])
+	})
+
+	t.Run("XLSX 导出", func(t *testing.T) {
+		query := url.Values{"filter": filter["filter"], "format": {"xlsx"}}
+		w := exportUsers(t, token, query)
+		assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".xlsx")
+
+		data := w.Body.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		var sheet string
+		for _, f := range zr.File {
+			if f.Name == "xl/worksheets/sheet1.xml" {
+				rc, err := f.Open()
+				require.NoError(t, err)
+				var buf bytes.Buffer
+				_, err = buf.ReadFrom(rc)
+				rc.Close()
+				require.NoError(t, err)
+				sheet = buf.String()
+			}
+		}
+		assert.Equal(t, 3, strings.Count(sheet, "<row "))
+		assert.Contains(t, sheet, first.Username)
+	})
+
+	t.Run("无效的格式", func(t *testing.T) {
+		w := exportUsers(t, token, url.Values{"format": {"pdf"}})
+		var resp pkgs.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
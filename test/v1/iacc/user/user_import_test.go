@@ -0,0 +1,209 @@
+package user_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importUsers 上传 CSV 文件导入用户
+func importUsers(t *testing.T, token, mode, content string) pkgs.Response {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "users.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	if mode != "" {
+		require.NoError(t, form.WriteField("mode", mode))
+	}
+	require.NoError(t, form.Close())
+
+	req, _ := http.NewRequest(http.MethodPost, "/v1/user/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// importReport 解析导入报告
+func importReport(t *testing.T, resp pkgs.Response) (accepted []map[string]any, rejected []map[string]any) {
+	t.Helper()
+	data, ok := resp.Data.(map[string]any)
+	require.True(t, ok, "响应数据应为导入报告")
+	for _, item := range data["accepted"].([]any) {
+		accepted = append(accepted, item.(map[string]any))
+	}
+	for _, item := range data["rejected"].([]any) {
+		rejected = append(rejected, item.(map[string]any))
+	}
+	return accepted, rejected
+}
+
+// TestImportUsers 测试导入用户
+// 包含六个子测试：导入全部通过的文件、atomic 方式存在被拒绝的行时不导入、row 方式跳过被拒绝的行、
+// 与已有用户冲突的行被拒绝、行数较多时转为异步导入、文件格式错误
+func TestImportUsers(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := testUtil.GetAccessUserToken([]string{"POST /v1/user/import", "GET /v1/user/import/:id"})
+
+	seq := 0
+	// 生成唯一的用户名与 11 位手机号
+	newUser := func() (string, string) {
+		seq++
+		n := time.Now().UnixNano()%1_000_000_000 + int64(seq)
+		return fmt.Sprintf("import_%d", n), fmt.Sprintf("13%09d", n)
+	}
+	exists := func(t *testing.T, username string) bool {
+		var count int
+		require.NoError(t, testDB.GetContext(context.Background(), &count, "SELECT COUNT(*) FROM iacc_user WHERE username = $1", username))
+		return count > 0
+	}
+	cleanup := func(usernames ...string) {
+		t.Cleanup(func() {
+			for _, username := range usernames {
+				_, _ = testDB.ExecContext(context.Background(), "DELETE FROM iacc_user WHERE username = $1", username)
+			}
+		})
+	}
+
+	t.Run("导入全部通过的文件", func(t *testing.T) {
+		first, firstPhone := newUser()
+		second, secondPhone := newUser()
+		cleanup(first, second)
+		// 列的顺序不限，email 可选
+		content := "\ufeffphone,username,password,email\n" +
+			firstPhone + "," + first + ",Passw0rd!,first@example.com\n" +
+			secondPhone + "," + second + ",Passw0rd!,\n"
+
+		resp := importUsers(t, token, "", content)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		accepted, rejected := importReport(t, resp)
+		assert.Len(t, accepted, 2)
+		assert.Empty(t, rejected)
+		assert.Equal(t, float64(2), accepted[0]["line"], "第一个数据行为第 2 行")
+		assert.True(t, exists(t, first))
+		assert.True(t, exists(t, second))
+	})
+
+	t.Run("atomic 方式存在被拒绝的行时不导入", func(t *testing.T) {
+		valid, validPhone := newUser()
+		invalid, _ := newUser()
+		cleanup(valid, invalid)
+		content := "username,phone,password\n" +
+			valid + "," + validPhone + ",Passw0rd!\n" +
+			invalid + ",123,Passw0rd!\n"
+
+		resp := importUsers(t, token, "atomic", content)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		accepted, rejected := importReport(t, resp)
+		assert.Empty(t, accepted)
+		require.Len(t, rejected, 1)
+		assert.Equal(t, float64(3), rejected[0]["line"])
+		assert.NotEmpty(t, rejected[0]["reason"])
+		assert.False(t, exists(t, valid), "atomic 方式不应导入任何用户")
+	})
+
+	t.Run("row 方式跳过被拒绝的行", func(t *testing.T) {
+		valid, validPhone := newUser()
+		duplicate, _ := newUser()
+		cleanup(valid, duplicate)
+		content := "username,phone,password\n" +
+			valid + "," + validPhone + ",Passw0rd!\n" +
+			duplicate + "," + validPhone + ",Passw0rd!\n"
+
+		resp := importUsers(t, token, "row", content)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		accepted, rejected := importReport(t, resp)
+		require.Len(t, accepted, 1)
+		assert.Equal(t, float64(2), accepted[0]["line"])
+		require.Len(t, rejected, 1)
+		assert.Equal(t, float64(3), rejected[0]["line"], "手机号与前面的行重复")
+		assert.True(t, exists(t, valid))
+		assert.False(t, exists(t, duplicate))
+	})
+
+	t.Run("与已有用户冲突的行被拒绝", func(t *testing.T) {
+		existing := testUtil.SetupTestUser()
+		valid, validPhone := newUser()
+		_, otherPhone := newUser()
+		cleanup(valid)
+		content := "username,phone,password\n" +
+			existing.Username + "," + otherPhone + ",Passw0rd!\n" +
+			valid + "," + validPhone + ",Passw0rd!\n"
+
+		resp := importUsers(t, token, "row", content)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		accepted, rejected := importReport(t, resp)
+		require.Len(t, accepted, 1)
+		assert.Equal(t, float64(3), accepted[0]["line"], "冲突的行回滚到保存点后继续导入")
+		require.Len(t, rejected, 1)
+		assert.Equal(t, existing.Username, rejected[0]["username"])
+		assert.True(t, exists(t, valid))
+	})
+
+	t.Run("行数较多时转为异步导入", func(t *testing.T) {
+		content := "username,phone,password\n"
+		usernames := make([]string, testConfig.UserImport.SyncRows+1)
+		for i := range usernames {
+			username, phone := newUser()
+			usernames[i] = username
+			content += username + "," + phone + ",Passw0rd!\n"
+		}
+		cleanup(usernames...)
+
+		resp := importUsers(t, token, "row", content)
+		require.Equal(t, http.StatusAccepted, resp.Code, resp.Msg)
+		jobID, _ := resp.Data.(map[string]any)["job_id"].(string)
+		require.NotEmpty(t, jobID)
+		assert.False(t, exists(t, usernames[0]), "任务执行前不应导入")
+
+		_, err := testJobs.RunPending(context.Background(), "", testDB)
+		require.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/user/import/"+jobID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		data := resp.Data.(map[string]any)
+		assert.Equal(t, pkgs.JobStatusSucceeded, data["status"])
+		accepted, rejected := importReport(t, pkgs.Response{Data: data["report"]})
+		assert.Len(t, accepted, len(usernames))
+		assert.Empty(t, rejected)
+		assert.True(t, exists(t, usernames[len(usernames)-1]))
+
+		var payload string
+		require.NoError(t, testDB.GetContext(context.Background(), &payload, "SELECT payload::text FROM async_job WHERE id = $1", jobID))
+		assert.NotContains(t, payload, "Passw0rd!", "任务完成后不应保存密码")
+	})
+
+	t.Run("文件格式错误", func(t *testing.T) {
+		resp := importUsers(t, token, "", "username,phone\nfoo,13800000000\n")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "缺少 password 列")
+		resp = importUsers(t, token, "", "username,phone,password,nickname\n")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "不支持的列")
+		resp = importUsers(t, token, "", "username,phone,password\n")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "没有数据行")
+		resp = importUsers(t, token, "bulk", "username,phone,password\nfoo,13800000000,Passw0rd!\n")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "无效的导入方式")
+	})
+}
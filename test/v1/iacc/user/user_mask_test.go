@@ -0,0 +1,111 @@
+package user_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUserPIIMasking 测试用户详情/列表的敏感信息脱敏
+// 包含四个子测试：无权限详情脱敏、无权限列表脱敏、无权限创建返回实体脱敏、有 user:view_pii 权限返回明文
+func TestUserPIIMasking(t *testing.T) {
+	getUser := func(t *testing.T, token, id string) map[string]any {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/user/"+id, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		data, _ := resp.Data.(map[string]any)
+		return data
+	}
+
+	t.Run("无权限 - 详情脱敏", func(t *testing.T) {
+		entity := setupTestUser(t)
+		phone := entity["phone"].(string)
+
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+
+		data := getUser(t, token, entity["id"].(string))
+		assert.Equal(t, pkgs.MaskPhone(phone), data["phone"], "手机号应该被脱敏")
+		assert.NotEqual(t, phone, data["phone"], "不应返回手机号明文")
+	})
+
+	t.Run("无权限 - 列表脱敏", func(t *testing.T) {
+		entity := setupTestUser(t)
+		phone := entity["phone"].(string)
+
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/user/list?username="+entity["username"].(string), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		list := resp.Data.(map[string]any)["list"].([]any)
+		if assert.Len(t, list, 1, "列表长度应该为1") {
+			assert.Equal(t, pkgs.MaskPhone(phone), list[0].(map[string]any)["phone"], "手机号应该被脱敏")
+		}
+	})
+
+	t.Run("无权限 - 创建返回实体脱敏", func(t *testing.T) {
+		phone := "139" + uuid.NewString()[:8]
+		bodyBytes, _ := json.Marshal(map[string]any{
+			"username": "mask_" + uuid.NewString()[:8],
+			"phone":    phone,
+			"password": "password123",
+		})
+
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+
+		req, _ := http.NewRequest(http.MethodPost, "/v1/user?return=entity", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		data, ok := resp.Data.(map[string]any)
+		if !assert.True(t, ok, "响应数据应该是用户对象") {
+			return
+		}
+		t.Cleanup(func() {
+			_, err := testDB.ExecContext(context.Background(), `DELETE FROM "iacc_user" WHERE id = $1`, data["id"])
+			assert.NoError(t, err, "清理创建的用户不应出错")
+		})
+		assert.NotEmpty(t, data["id"], "应返回服务端生成的 ID")
+		assert.NotEmpty(t, data["created_at"], "应返回创建时间")
+		assert.Equal(t, pkgs.MaskPhone(phone), data["phone"], "手机号应该被脱敏")
+	})
+
+	t.Run("有权限 - 返回明文", func(t *testing.T) {
+		entity := setupTestUser(t)
+
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserTokenWithCodes([]string{pkgs.PermissionCodeViewPII})
+
+		data := getUser(t, token, entity["id"].(string))
+		assert.Equal(t, entity["phone"], data["phone"], "有权限时应返回手机号明文")
+	})
+}
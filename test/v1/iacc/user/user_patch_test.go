@@ -0,0 +1,75 @@
+package user_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPatchUser 测试 JSON Merge Patch 部分更新用户
+// 包含三个子测试：null 清空手机号、profile 递归合并删除邮箱、请求体不是 JSON 对象
+func TestPatchUser(t *testing.T) {
+	patch := func(t *testing.T, id string, body string) pkgs.Response {
+		req, _ := http.NewRequest(http.MethodPatch, "/v1/user/"+id, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", pkgs.MergePatchContentType)
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		req.Header.Set("Authorization", "Bearer "+testUtil.GetAccessUserToken([]string{}))
+
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+
+	t.Run("null 清空手机号", func(t *testing.T) {
+		entity := setupTestUser(t)
+
+		resp := patch(t, entity["id"].(string), `{"phone": null}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		var row struct {
+			Phone     *string `db:"phone"`
+			PhoneHash *string `db:"phone_hash"`
+		}
+		err := testDB.GetContext(context.Background(), &row, `SELECT phone, phone_hash FROM "iacc_user" WHERE id = $1`, entity["id"])
+		assert.NoError(t, err, "应该能在数据库中找到用户")
+		assert.Nil(t, row.Phone, "手机号应被清空")
+		assert.Nil(t, row.PhoneHash, "手机号影子列应同时清空")
+	})
+
+	t.Run("profile 递归合并删除邮箱", func(t *testing.T) {
+		entity := setupTestUser(t)
+		id := entity["id"].(string)
+
+		resp := patch(t, id, `{"profile": {"email": "patch@example.com"}}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		resp = patch(t, id, `{"profile": {"email": null}}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		var row struct {
+			Email     *string `db:"email"`
+			EmailHash *string `db:"email_hash"`
+		}
+		err := testDB.GetContext(context.Background(), &row, `SELECT profile->>'email' AS email, email_hash FROM "iacc_user" WHERE id = $1`, id)
+		assert.NoError(t, err, "应该能在数据库中找到用户")
+		assert.Nil(t, row.Email, "邮箱应从 profile 中删除")
+		assert.Nil(t, row.EmailHash, "邮箱影子列应同时清空")
+	})
+
+	t.Run("请求体不是 JSON 对象", func(t *testing.T) {
+		entity := setupTestUser(t)
+
+		resp := patch(t, entity["id"].(string), `["phone"]`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "响应码应该是 400")
+	})
+}
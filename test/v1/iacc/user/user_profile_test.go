@@ -314,7 +314,7 @@ func TestProfileInAPI(t *testing.T) {
 
 		// 创建 TestUtil 实例
 		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
-		token := testUtil.GetAccessUserToken([]string{})
+		token := testUtil.GetAccessUserTokenWithCodes([]string{pkgs.PermissionCodeViewPII})
 
 		// 执行获取请求
 		req, _ := http.NewRequest(http.MethodGet, "/v1/user/"+userID, nil)
@@ -391,3 +391,82 @@ func TestProfileEdgeCases(t *testing.T) {
 		assert.Nil(t, profile.Email, "Email字段应为nil")
 	})
 }
+
+// TestPatchProfile 测试合并更新个人信息
+// 包含四个子测试：合并保留其它字段、删除字段、同一字段同时设置和删除、用户不存在
+func TestPatchProfile(t *testing.T) {
+	patchProfile := func(t *testing.T, id string, body map[string]any) pkgs.Response {
+		bodyBytes, _ := json.Marshal(body)
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+
+		req, _ := http.NewRequest(http.MethodPatch, "/v1/user/"+id+"/profile", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+
+	t.Run("合并保留其它字段", func(t *testing.T) {
+		entity := setupTestUser(t)
+		id := entity["id"].(string)
+		// 直接写入一个 Profile 结构体之外的字段，验证合并发生在数据库内而不是整体覆盖
+		_, err := testDB.ExecContext(context.Background(), `UPDATE "iacc_user" SET profile = '{"nickname":"小明"}' WHERE id = $1`, id)
+		assert.NoError(t, err, "准备 profile 不应出错")
+
+		email := "merge_" + uuid.NewString()[:8] + "@example.com"
+		resp := patchProfile(t, id, map[string]any{"set": map[string]any{"email": email}})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		var row struct {
+			Nickname  *string `db:"nickname"`
+			EmailHash *string `db:"email_hash"`
+		}
+		err = testDB.GetContext(context.Background(), &row, `SELECT profile->>'nickname' AS nickname, email_hash FROM "iacc_user" WHERE id = $1`, id)
+		assert.NoError(t, err, "应该能在数据库中找到用户")
+		if assert.NotNil(t, row.Nickname, "未提及的字段应保留") {
+			assert.Equal(t, "小明", *row.Nickname)
+		}
+		if assert.NotNil(t, row.EmailHash, "邮箱影子列应同步") {
+			assert.Equal(t, pkgs.EmailBlindIndex(email), *row.EmailHash)
+		}
+	})
+
+	t.Run("删除字段", func(t *testing.T) {
+		entity := setupTestUser(t)
+		id := entity["id"].(string)
+		resp := patchProfile(t, id, map[string]any{"set": map[string]any{"email": "delete@example.com"}})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		resp = patchProfile(t, id, map[string]any{"delete": []string{"email"}})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		var row struct {
+			HasEmail  bool    `db:"has_email"`
+			EmailHash *string `db:"email_hash"`
+		}
+		err := testDB.GetContext(context.Background(), &row, `SELECT profile ? 'email' AS has_email, email_hash FROM "iacc_user" WHERE id = $1`, id)
+		assert.NoError(t, err, "应该能在数据库中找到用户")
+		assert.False(t, row.HasEmail, "邮箱应从 profile 中删除")
+		assert.Nil(t, row.EmailHash, "邮箱影子列应清空")
+	})
+
+	t.Run("同一字段同时设置和删除", func(t *testing.T) {
+		entity := setupTestUser(t)
+		resp := patchProfile(t, entity["id"].(string), map[string]any{
+			"set":    map[string]any{"email": "conflict@example.com"},
+			"delete": []string{"email"},
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "响应码应该是 400")
+	})
+
+	t.Run("用户不存在", func(t *testing.T) {
+		resp := patchProfile(t, uuid.NewString(), map[string]any{"delete": []string{"email"}})
+		assert.Equal(t, http.StatusNotFound, resp.Code, "响应码应该是 404")
+	})
+}
@@ -110,3 +110,101 @@ func TestQueryUserList(t *testing.T) {
 		assert.Equal(t, entity["id"], firstItem["id"])
 	})
 }
+
+// TestCountUser 测试用户数量统计功能
+// 包含两个子测试：按手机号统计、无匹配时为0
+func TestCountUser(t *testing.T) {
+	t.Run("按手机号统计", func(t *testing.T) {
+		// 准备数据
+		entity := setupTestUser(t)
+
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+
+		// 执行
+		req, _ := http.NewRequest(http.MethodGet, "/v1/user/count?phone="+entity["phone"].(string), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, float64(1), resp.Data, "数量应该为1")
+	})
+
+	t.Run("无匹配时为0", func(t *testing.T) {
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+
+		// 执行
+		req, _ := http.NewRequest(http.MethodGet, "/v1/user/count?phone=nonexistent123456789", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, float64(0), resp.Data, "数量应该为0")
+	})
+}
+
+// TestUserExists 测试用户存在性检查功能
+// 包含三个子测试：手机号已存在、手机号不存在、缺少参数
+func TestUserExists(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    func(entity map[string]any) string
+		wantCode int
+		want     any
+	}{
+		{
+			name:     "手机号已存在",
+			query:    func(entity map[string]any) string { return "phone=" + entity["phone"].(string) },
+			wantCode: http.StatusOK,
+			want:     true,
+		},
+		{
+			name:     "手机号不存在",
+			query:    func(map[string]any) string { return "phone=nonexistent123456789" },
+			wantCode: http.StatusOK,
+			want:     false,
+		},
+		{
+			name:     "缺少参数",
+			query:    func(map[string]any) string { return "" },
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// 准备数据
+			entity := setupTestUser(t)
+
+			testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+			token := testUtil.GetAccessUserToken([]string{})
+
+			// 执行
+			req, _ := http.NewRequest(http.MethodGet, "/v1/user/exists?"+tc.query(entity), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			testRouter.ServeHTTP(w, req)
+
+			// 断言
+			var resp pkgs.Response
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.wantCode == http.StatusOK {
+				assert.Equal(t, tc.want, resp.Data)
+			}
+		})
+	}
+}
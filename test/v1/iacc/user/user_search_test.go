@@ -0,0 +1,62 @@
+package user_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserSearchRoleNames 测试用户列表返回的角色名称与物化视图
+// 包含三个子测试：列表返回排序后的角色名称、没有角色时为空数组、物化视图刷新后包含新用户与角色名称
+func TestUserSearchRoleNames(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := testUtil.GetAccessUserToken([]string{})
+
+	withRoles := testUtil.SetupTestUser()
+	role1 := testUtil.SetupTestRole()
+	role2 := testUtil.SetupTestRole()
+	testUtil.AssignRoleToUser(withRoles.ID, role1.ID)
+	testUtil.AssignRoleToUser(withRoles.ID, role2.ID)
+	expected := []string{role1.Name, role2.Name}
+	slices.Sort(expected)
+
+	t.Run("列表返回排序后的角色名称", func(t *testing.T) {
+		code, res := queryList(t, token, url.Values{"filter": {"id:eq:" + withRoles.ID}})
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.List, 1)
+		assert.Equal(t, expected, res.List[0].RoleNames)
+		assert.Empty(t, res.RefreshedAt, "未开启物化视图时实时查询，不返回刷新时间")
+	})
+
+	t.Run("没有角色时为空数组", func(t *testing.T) {
+		noRoles := testUtil.SetupTestUser()
+		code, res := queryList(t, token, url.Values{"filter": {"id:eq:" + noRoles.ID}})
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.List, 1)
+		assert.NotNil(t, res.List[0].RoleNames)
+		assert.Empty(t, res.List[0].RoleNames)
+	})
+
+	t.Run("物化视图刷新后包含新用户与角色名称", func(t *testing.T) {
+		before := time.Now()
+		_, err := testDB.ExecContext(context.Background(), `REFRESH MATERIALIZED VIEW CONCURRENTLY "iacc_user_search"`)
+		require.NoError(t, err)
+
+		var row struct {
+			RoleNames   pq.StringArray `db:"role_names"`
+			RefreshedAt time.Time      `db:"refreshed_at"`
+		}
+		require.NoError(t, testDB.Get(&row, `SELECT role_names, refreshed_at FROM "iacc_user_search" WHERE id = $1`, withRoles.ID))
+		assert.Equal(t, expected, []string(row.RoleNames))
+		assert.False(t, row.RefreshedAt.Before(before.Add(-time.Second)), "刷新时间为本次刷新")
+	})
+}
@@ -0,0 +1,122 @@
+package sandbox_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+)
+
+// TestMain 初始化一次应用，复用数据库和路由
+// 配置文件中默认关闭沙箱模式，这里开启后重新注册其路由
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	a.Conf.Sandbox.Enabled = true
+	a.V1Router.RegisterSandbox()
+	os.Exit(m.Run())
+}
+
+// doRequest 发送 JSON 请求并解析标准响应
+func doRequest(t *testing.T, method, path, token string, body any) (pkgs.Response, http.Header) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp, w.Header()
+}
+
+// issue 签发沙箱令牌，返回令牌
+func issue(t *testing.T, token string, apis []string) string {
+	t.Helper()
+	resp, _ := doRequest(t, http.MethodPost, "/v1/sandbox/token", token, map[string]any{"apis": apis, "expires_in": 300})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	data, _ := resp.Data.(map[string]any)
+	accessToken, _ := data["access_token"].(string)
+	require.NotEmpty(t, accessToken)
+	return accessToken
+}
+
+// TestSandbox 测试沙箱令牌
+// 包含四个子测试：写操作回滚、范围外接口、签发校验、不能刷新
+func TestSandbox(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions([]string{"POST /v1/sandbox/token"})
+
+	t.Run("写操作回滚", func(t *testing.T) {
+		sandboxToken := issue(t, token, []string{"POST /v1/template"})
+		name := "sandbox_" + uuid.NewString()[:8]
+
+		resp, header := doRequest(t, http.MethodPost, "/v1/template", sandboxToken, map[string]any{"name": name})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.NotEmpty(t, resp.Data)
+		assert.Equal(t, "rolled-back", header.Get(pkgs.SandboxHeader))
+
+		var count int
+		require.NoError(t, testDB.Get(&count, `SELECT COUNT(*) FROM template WHERE name = $1`, name))
+		assert.Zero(t, count, "沙箱中创建的模板应已回滚")
+	})
+
+	t.Run("范围外接口", func(t *testing.T) {
+		sandboxToken := issue(t, token, []string{"POST /v1/template"})
+		resp, _ := doRequest(t, http.MethodGet, "/v1/template/list", sandboxToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("签发校验", func(t *testing.T) {
+		cases := []map[string]any{
+			{"apis": []string{}},
+			{"apis": []string{"POST /v1/template", "POST /v1/template"}},
+			{"apis": []string{"POST /v1/not-found"}},
+			{"apis": []string{"POST /v1/sandbox/token"}},
+			{"apis": []string{"POST /v1/template/:id/restore"}},
+			{"apis": []string{"POST /v1/template"}, "expires_in": 86400},
+		}
+		for _, body := range cases {
+			resp, _ := doRequest(t, http.MethodPost, "/v1/sandbox/token", token, body)
+			assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+		}
+
+		// 纳入权限体系的接口，当前用户没有权限时不能签发
+		tu.SetupTestPermission("POST /v1/role")
+		resp, _ := doRequest(t, http.MethodPost, "/v1/sandbox/token", token, map[string]any{"apis": []string{"POST /v1/role"}})
+		assert.Equal(t, http.StatusForbidden, resp.Code, resp.Msg)
+	})
+
+	t.Run("不能刷新", func(t *testing.T) {
+		sandboxToken := issue(t, token, []string{"POST /v1/template"})
+		resp, _ := doRequest(t, http.MethodPost, "/v1/auth/refresh-token", "", map[string]any{"refresh_token": sandboxToken})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
@@ -0,0 +1,104 @@
+package template_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTemplateArchive 测试模板归档与恢复
+// 包含五个子测试：非所有者不能归档、仍被引用的模板不能归档、归档后不出现在列表中、恢复归档的模板、不存在的模板
+func TestTemplateArchive(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+
+	do := func(t *testing.T, method, url, token string) pkgs.Response {
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(nil))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+
+	ownerToken := testUtil.GetAccessUserToken([]string{})
+	name := "ArchiveTest_" + uuid.NewString()[:8]
+	createReq, _ := http.NewRequest(http.MethodPost, "/v1/template", bytes.NewBufferString(`{"name":"`+name+`","num":1}`))
+	createReq.Header.Set("Content-Type", "application/json")
+	createReq.Header.Set("Authorization", "Bearer "+ownerToken)
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, createReq)
+	var created pkgs.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created), "解析响应体不应出错")
+	id, _ := created.Data.(string)
+	t.Cleanup(func() {
+		_, err := testDB.ExecContext(context.Background(), "DELETE FROM template WHERE id = $1", id)
+		assert.NoError(t, err, "清理创建的模板不应出错")
+	})
+
+	t.Run("非所有者不能归档", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/v1/template/"+id+"/archive", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "匿名请求应返回 401")
+
+		otherToken := testUtil.GetAccessUserToken([]string{})
+		resp = do(t, http.MethodPost, "/v1/template/"+id+"/archive", otherToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code, "非所有者应返回 403")
+	})
+
+	t.Run("仍被引用的模板不能归档", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/v1/template/"+id+"/use", ownerToken)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+
+		resp = do(t, http.MethodPost, "/v1/template/"+id+"/archive", ownerToken)
+		assert.Equal(t, http.StatusConflict, resp.Code, "最近使用过的模板归档应返回 409")
+		assert.Contains(t, resp.Msg, "template_usage", "应返回引用方")
+
+		_, err := testDB.ExecContext(context.Background(), "DELETE FROM template_usage WHERE template_id = $1", id)
+		assert.NoError(t, err, "清理使用记录不应出错")
+	})
+
+	t.Run("归档后不出现在列表中", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/v1/template/"+id+"/archive", ownerToken)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.NotEmpty(t, resp.Data.(map[string]any)["archived_at"], "应返回归档时间")
+
+		resp = do(t, http.MethodPost, "/v1/template/"+id+"/archive", ownerToken)
+		assert.Equal(t, http.StatusConflict, resp.Code, "重复归档应返回 409")
+
+		resp = do(t, http.MethodGet, "/v1/template/list?name="+name, ownerToken)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		assert.Equal(t, float64(0), resp.Data.(map[string]any)["total"], "列表不应包含归档的模板")
+
+		resp = do(t, http.MethodGet, "/v1/template/"+id, ownerToken)
+		assert.Equal(t, http.StatusOK, resp.Code, "详情接口仍可查看归档的模板")
+	})
+
+	t.Run("恢复归档的模板", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/v1/template/"+id+"/restore", ownerToken)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Nil(t, resp.Data.(map[string]any)["archived_at"], "恢复后不应有归档时间")
+
+		resp = do(t, http.MethodPost, "/v1/template/"+id+"/restore", ownerToken)
+		assert.Equal(t, http.StatusConflict, resp.Code, "未归档的模板恢复应返回 409")
+
+		resp = do(t, http.MethodGet, "/v1/template/list?name="+name, ownerToken)
+		assert.Equal(t, float64(1), resp.Data.(map[string]any)["total"], "恢复后应重新出现在列表中")
+	})
+
+	t.Run("不存在的模板", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/v1/template/"+uuid.NewString()+"/restore", ownerToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "数据库与对象存储中都不存在时应返回 404")
+	})
+}
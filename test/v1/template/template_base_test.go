@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"go-pg-demo/internal/app"
+	"go-pg-demo/internal/modules/template"
 	"go-pg-demo/pkgs"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +22,8 @@ var (
 	testDB     *sqlx.DB    // 测试数据库连接
 	testLogger *zap.Logger // 测试日志记录器
 	testRouter *gin.Engine // 测试路由器
+	// 模板处理器，用于注册测试用的钩子与引用检查器
+	testHandler *template.Handler
 )
 
 // TestMain 初始化测试环境
@@ -39,6 +42,7 @@ func TestMain(m *testing.M) {
 	testDB = testApp.DB
 	testLogger = testApp.Logger
 	testRouter = testApp.Server
+	testHandler, _ = testApp.V1Router.TemplateHandler.(*template.Handler)
 
 	// 运行测试
 	exitCode := m.Run()
@@ -0,0 +1,165 @@
+package template_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// 名称带有该前缀的模板被测试用的引用检查器视为仍被引用
+const referencedTemplatePrefix = "BatchArchiveReferenced_"
+
+// TestTemplateBatchArchive 测试批量归档模板
+// 包含五个子测试：未登录时拒绝、归档自己的模板、跳过不满足条件的模板、跳过被关注与最近使用的模板、重复的模板ID
+func TestTemplateBatchArchive(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	testHandler.RegisterReferenceChecker(template.ReferenceChecker{
+		Name: "test",
+		Referenced: func(ctx context.Context, tx *sqlx.Tx, ids []string) ([]string, error) {
+			var referenced []string
+			err := tx.SelectContext(ctx, &referenced, "SELECT id FROM template WHERE id = ANY($1) AND name LIKE $2", pkgs.PGArray(ids), referencedTemplatePrefix+"%")
+			return referenced, err
+		},
+	})
+
+	do := func(t *testing.T, token string, ids []any) pkgs.Response {
+		bodyBytes, _ := json.Marshal(map[string]any{"ids": ids})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/template/batch-archive", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+	create := func(t *testing.T, token, name string) string {
+		bodyBytes, _ := json.Marshal(map[string]any{"name": name, "num": 1})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/template", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), "解析响应体不应出错")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		id, _ := resp.Data.(string)
+		t.Cleanup(func() {
+			_, err := testDB.ExecContext(context.Background(), "DELETE FROM template WHERE id = $1", id)
+			assert.NoError(t, err, "清理创建的模板不应出错")
+		})
+		return id
+	}
+	archivedAt := func(t *testing.T, id string) *string {
+		var archived *string
+		err := testDB.GetContext(context.Background(), &archived, "SELECT archived_at::text FROM template WHERE id = $1", id)
+		assert.NoError(t, err, "查询归档时间不应出错")
+		return archived
+	}
+
+	ownerToken := testUtil.GetAccessUserToken([]string{})
+	suffix := uuid.NewString()[:8]
+
+	t.Run("未登录时拒绝", func(t *testing.T) {
+		resp := do(t, "", []any{uuid.NewString()})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "未登录应返回 401")
+	})
+
+	t.Run("归档自己的模板", func(t *testing.T) {
+		first := create(t, ownerToken, "BatchArchive_"+suffix+"_1")
+		second := create(t, ownerToken, "BatchArchive_"+suffix+"_2")
+
+		resp := do(t, ownerToken, []any{first, second})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		data := resp.Data.(map[string]any)
+		assert.ElementsMatch(t, []any{first, second}, data["archived"], "两个模板都应归档")
+		assert.Empty(t, data["skipped"], "不应跳过模板")
+		assert.NotNil(t, archivedAt(t, first), "模板应已归档")
+		assert.NotNil(t, archivedAt(t, second), "模板应已归档")
+	})
+
+	t.Run("跳过不满足条件的模板", func(t *testing.T) {
+		eligible := create(t, ownerToken, "BatchArchive_"+suffix+"_eligible")
+		archived := create(t, ownerToken, "BatchArchive_"+suffix+"_archived")
+		_, err := testDB.ExecContext(context.Background(), "UPDATE template SET archived_at = CURRENT_TIMESTAMP WHERE id = $1", archived)
+		assert.NoError(t, err, "归档模板不应出错")
+		referenced := create(t, ownerToken, referencedTemplatePrefix+suffix)
+		others := create(t, testUtil.GetAccessUserToken([]string{}), "BatchArchive_"+suffix+"_others")
+		missing := uuid.NewString()
+
+		resp := do(t, ownerToken, []any{eligible, archived, referenced, others, missing})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		data := resp.Data.(map[string]any)
+		assert.Equal(t, []any{eligible}, data["archived"], "只应归档满足条件的模板")
+
+		reasons := map[string]string{}
+		for _, item := range data["skipped"].([]any) {
+			skipped := item.(map[string]any)
+			reasons[skipped["id"].(string)] = skipped["reason"].(string)
+			if skipped["id"] == referenced {
+				assert.Equal(t, []any{"test"}, skipped["referenced_by"], "应返回引用方")
+			}
+		}
+		assert.Equal(t, map[string]string{
+			archived:   template.ArchiveSkipArchived,
+			referenced: template.ArchiveSkipReferenced,
+			others:     template.ArchiveSkipForbidden,
+			missing:    template.ArchiveSkipNotFound,
+		}, reasons, "应返回跳过的原因")
+		assert.Nil(t, archivedAt(t, referenced), "仍被引用的模板不应归档")
+		assert.Nil(t, archivedAt(t, others), "其他用户的模板不应归档")
+	})
+
+	t.Run("跳过被关注与最近使用的模板", func(t *testing.T) {
+		watched := create(t, ownerToken, "BatchArchive_"+suffix+"_watched")
+		used := create(t, ownerToken, "BatchArchive_"+suffix+"_used")
+		watcher := testUtil.SetupTestUser()
+		_, err := testDB.ExecContext(context.Background(), "INSERT INTO watch (user_id, entity, entity_id) VALUES ($1, $2, $3)", watcher.ID, pkgs.WatchEntityTemplate, watched)
+		assert.NoError(t, err, "关注模板不应出错")
+		t.Cleanup(func() {
+			_, err := testDB.ExecContext(context.Background(), "DELETE FROM watch WHERE entity_id = $1", watched)
+			assert.NoError(t, err, "清理关注不应出错")
+		})
+		_, err = testDB.ExecContext(context.Background(), "INSERT INTO template_usage (template_id, usage_count) VALUES ($1, 1)", used)
+		assert.NoError(t, err, "记录模板使用不应出错")
+
+		resp := do(t, ownerToken, []any{watched, used})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		data := resp.Data.(map[string]any)
+		assert.Empty(t, data["archived"], "不应归档仍被引用的模板")
+
+		referencedBy := map[string]any{}
+		for _, item := range data["skipped"].([]any) {
+			skipped := item.(map[string]any)
+			assert.Equal(t, template.ArchiveSkipReferenced, skipped["reason"], "跳过原因应为仍被引用")
+			referencedBy[skipped["id"].(string)] = skipped["referenced_by"]
+		}
+		assert.Equal(t, map[string]any{
+			watched: []any{"watch"},
+			used:    []any{"template_usage"},
+		}, referencedBy, "应返回引用方")
+		assert.Nil(t, archivedAt(t, watched), "被关注的模板不应归档")
+		assert.Nil(t, archivedAt(t, used), "最近使用过的模板不应归档")
+	})
+
+	t.Run("重复的模板ID", func(t *testing.T) {
+		id := uuid.NewString()
+		resp := do(t, ownerToken, []any{id, id})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "重复的模板ID应返回 400")
+	})
+}
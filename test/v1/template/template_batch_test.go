@@ -148,6 +148,34 @@ func TestBatchDeleteTemplates(t *testing.T) {
 		assert.Equal(t, 0, count, "模板应已被删除")
 	})
 
+	t.Run("ID重复", func(t *testing.T) {
+		// 准备
+		entity := createTestTemplate(t, "", nil)
+		id := entity["id"].(string)
+		deleteReq := map[string]any{
+			"ids": []string{id, id},
+		}
+		bodyBytes, _ := json.Marshal(deleteReq)
+		req, _ := http.NewRequest(http.MethodPost, "/v1/template/batch-delete", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		// 获取token
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// 执行
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "ID重复时应返回400错误")
+		assert.Contains(t, resp.Msg, "模板ID存在重复值")
+		violations := resp.Data.([]any)
+		assert.Equal(t, []any{float64(0), float64(1)}, violations[0].(map[string]any)["indexes"], "应返回重复项的下标")
+	})
+
 	t.Run("部分ID不存在", func(t *testing.T) {
 		// 准备
 		entity := createTestTemplate(t, "", nil)
@@ -0,0 +1,173 @@
+package template_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/pkgs"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTemplateOwner 测试模板所有者、列表查询范围和所有权转移
+// 包含七个子测试：创建时写入所有者、默认只查自己的模板、无权限查询全部、有权限查询全部、转移所有权、
+// 非所有者不能读写模板、有权限时可以读写全部模板
+func TestTemplateOwner(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+
+	do := func(t *testing.T, method, url, token string, body any) pkgs.Response {
+		var reader *bytes.Buffer
+		if body != nil {
+			bodyBytes, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(bodyBytes)
+		} else {
+			reader = bytes.NewBuffer(nil)
+		}
+		req, _ := http.NewRequest(method, url, reader)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+	// 从令牌中解析用户 ID
+	userIDOf := func(token string) string {
+		claims := jwt.MapClaims{}
+		_, _, err := jwt.NewParser().ParseUnverified(token, claims)
+		assert.NoError(t, err, "解析令牌不应出错")
+		uid, _ := claims["user_id"].(string)
+		return uid
+	}
+	create := func(t *testing.T, token, name string) map[string]any {
+		resp := do(t, http.MethodPost, "/v1/template?return=entity", token, map[string]any{"name": name, "num": 1})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		data, _ := resp.Data.(map[string]any)
+		t.Cleanup(func() {
+			_, err := testDB.ExecContext(context.Background(), "DELETE FROM template WHERE id = $1", data["id"])
+			assert.NoError(t, err, "清理创建的模板不应出错")
+		})
+		return data
+	}
+
+	ownerToken := testUtil.GetAccessUserToken([]string{})
+	ownerID := userIDOf(ownerToken)
+	name := "OwnerTest_" + uuid.NewString()[:8]
+
+	t.Run("创建时写入所有者", func(t *testing.T) {
+		data := create(t, ownerToken, name+"_create")
+		assert.Equal(t, ownerID, data["owner_id"], "所有者应为当前用户")
+	})
+
+	t.Run("默认只查自己的模板", func(t *testing.T) {
+		mine := create(t, ownerToken, name+"_mine")
+		createTestTemplate(t, name+"_anonymous", nil)
+
+		resp := do(t, http.MethodGet, "/v1/template/list?name="+name+"_", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		list := resp.Data.(map[string]any)["list"].([]any)
+		for _, item := range list {
+			assert.Equal(t, ownerID, item.(map[string]any)["owner_id"], "只应返回自己的模板")
+		}
+		ids := make([]any, 0, len(list))
+		for _, item := range list {
+			ids = append(ids, item.(map[string]any)["id"])
+		}
+		assert.Contains(t, ids, mine["id"], "应包含自己创建的模板")
+	})
+
+	t.Run("无权限查询全部", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/v1/template/list?scope=all", ownerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code, "没有 template:manage_all 权限时应返回 403")
+	})
+
+	t.Run("有权限查询全部", func(t *testing.T) {
+		create(t, ownerToken, name+"_all")
+		createTestTemplate(t, name+"_all_anonymous", nil)
+
+		token := testUtil.GetAccessUserTokenWithCodes([]string{template.PermissionCodeManageAll})
+		resp := do(t, http.MethodGet, "/v1/template/list?scope=all&name="+name+"_all", token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		list := resp.Data.(map[string]any)["list"].([]any)
+		assert.Len(t, list, 2, "应返回所有用户的模板")
+	})
+
+	t.Run("转移所有权", func(t *testing.T) {
+		data := create(t, ownerToken, name+"_transfer")
+		otherToken := testUtil.GetAccessUserToken([]string{})
+		otherID := userIDOf(otherToken)
+
+		// 匿名请求不能转移
+		resp := do(t, http.MethodPost, "/v1/template/"+data["id"].(string)+"/transfer", "", map[string]any{"owner_id": otherID})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "匿名请求应返回 401")
+
+		// 非所有者不能转移
+		resp = do(t, http.MethodPost, "/v1/template/"+data["id"].(string)+"/transfer", otherToken, map[string]any{"owner_id": otherID})
+		assert.Equal(t, http.StatusForbidden, resp.Code, "非所有者应返回 403")
+
+		// 所有者转移给其他用户
+		resp = do(t, http.MethodPost, "/v1/template/"+data["id"].(string)+"/transfer", ownerToken, map[string]any{"owner_id": otherID})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+
+		var owner *string
+		err := testDB.GetContext(context.Background(), &owner, "SELECT owner_id FROM template WHERE id = $1", data["id"])
+		assert.NoError(t, err, "查询模板所有者不应出错")
+		if assert.NotNil(t, owner, "所有者不应为空") {
+			assert.Equal(t, otherID, *owner, "所有者应为新用户")
+		}
+	})
+
+	t.Run("非所有者不能读写模板", func(t *testing.T) {
+		data := create(t, ownerToken, name+"_private")
+		id := data["id"].(string)
+		otherToken := testUtil.GetAccessUserToken([]string{})
+
+		resp := do(t, http.MethodGet, "/v1/template/"+id, otherToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "非所有者获取应返回 404")
+		resp = do(t, http.MethodGet, "/v1/template/"+id, "", nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "匿名请求获取有所有者的模板应返回 404")
+
+		resp = do(t, http.MethodPut, "/v1/template/"+id, otherToken, map[string]any{"name": name + "_hijacked"})
+		assert.Equal(t, http.StatusForbidden, resp.Code, "非所有者更新应返回 403")
+		resp = do(t, http.MethodPatch, "/v1/template/"+id, otherToken, map[string]any{"num": 2})
+		assert.Equal(t, http.StatusForbidden, resp.Code, "非所有者部分更新应返回 403")
+		resp = do(t, http.MethodDelete, "/v1/template/"+id, otherToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code, "非所有者删除应返回 403")
+
+		var stored struct {
+			Name string `db:"name"`
+			Num  int    `db:"num"`
+		}
+		err := testDB.GetContext(context.Background(), &stored, "SELECT name, num FROM template WHERE id = $1", id)
+		assert.NoError(t, err, "模板不应被删除")
+		assert.Equal(t, name+"_private", stored.Name, "模板名称不应被修改")
+		assert.Equal(t, 1, stored.Num, "模板数量不应被修改")
+
+		resp = do(t, http.MethodGet, "/v1/template/"+id, ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "所有者可以获取")
+	})
+
+	t.Run("有权限时可以读写全部模板", func(t *testing.T) {
+		data := create(t, ownerToken, name+"_managed")
+		id := data["id"].(string)
+		token := testUtil.GetAccessUserTokenWithCodes([]string{template.PermissionCodeManageAll})
+
+		resp := do(t, http.MethodGet, "/v1/template/"+id, token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "有 template:manage_all 权限时可以获取")
+		resp = do(t, http.MethodPut, "/v1/template/"+id, token, map[string]any{"num": 3})
+		assert.Equal(t, http.StatusOK, resp.Code, "有 template:manage_all 权限时可以更新")
+		assert.Equal(t, float64(1), resp.Data, "影响行数应为 1")
+	})
+}
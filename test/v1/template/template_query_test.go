@@ -1,18 +1,23 @@
 package template_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"go-pg-demo/pkgs"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 // TestQueryListTemplates 测试模板列表查询功能
-// 包含基本查询、分页查询、搜索查询和无效参数测试
+// 包含基本查询、分页查询、搜索查询、按数量筛选、按创建时间筛选和无效参数测试
 func TestQueryListTemplates(t *testing.T) {
 	t.Run("成功查询", func(t *testing.T) {
 		// 准备
@@ -93,6 +98,76 @@ func TestQueryListTemplates(t *testing.T) {
 		assert.Equal(t, entity["id"], list[0].(map[string]any)["id"])
 	})
 
+	t.Run("按数量筛选", func(t *testing.T) {
+		// 准备：名称前缀相同、数量不同的三个模板
+		prefix := "数量筛选_" + uuid.NewString()[:8]
+		ids := map[int]any{}
+		for _, num := range []int{3, 5, 7} {
+			ids[num] = createTestTemplate(t, prefix+"_"+uuid.NewString()[:4], &num)["id"]
+		}
+
+		query := func(filter string) []any {
+			req, _ := http.NewRequest(http.MethodGet, "/v1/template/list?orderBy=num&order=asc&name="+prefix+"&"+filter, nil)
+			w := httptest.NewRecorder()
+			testRouter.ServeHTTP(w, req)
+			var resp pkgs.Response
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+			data, _ := resp.Data.(map[string]any)
+			list, _ := data["list"].([]any)
+			return list
+		}
+		idsOf := func(list []any) []any {
+			var result []any
+			for _, item := range list {
+				result = append(result, item.(map[string]any)["id"])
+			}
+			return result
+		}
+
+		assert.Equal(t, []any{ids[5]}, idsOf(query("num=5")), "精确匹配")
+		assert.Equal(t, []any{ids[5], ids[7]}, idsOf(query("numMin=4")), "只传下限")
+		assert.Equal(t, []any{ids[3], ids[5]}, idsOf(query("numMax=5")), "只传上限，区间包含端点")
+		assert.Equal(t, []any{ids[5]}, idsOf(query("numMin=4&numMax=6")), "闭区间")
+		assert.Empty(t, query("numMin=8"))
+	})
+
+	t.Run("按创建时间筛选", func(t *testing.T) {
+		// 准备
+		entity := createTestTemplate(t, "", nil)
+		created, err := time.Parse(time.RFC3339, entity["created_at"].(string))
+		assert.NoError(t, err)
+
+		count := func(filter string) float64 {
+			req, _ := http.NewRequest(http.MethodGet, "/v1/template/list?name="+entity["name"].(string)+"&"+filter, nil)
+			w := httptest.NewRecorder()
+			testRouter.ServeHTTP(w, req)
+			var resp pkgs.Response
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+			data, _ := resp.Data.(map[string]any)
+			total, _ := data["total"].(float64)
+			return total
+		}
+
+		hourBefore := url.QueryEscape(created.Add(-time.Hour).Format(time.RFC3339))
+		hourAfter := url.QueryEscape(created.Add(time.Hour).Format(time.RFC3339))
+		assert.Equal(t, float64(1), count("createdFrom="+hourBefore+"&createdTo="+hourAfter))
+		assert.Equal(t, float64(0), count("createdFrom="+hourAfter))
+		assert.Equal(t, float64(0), count("updatedTo="+hourBefore))
+	})
+
+	t.Run("最小数量大于最大数量", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/template/list?numMin=10&numMax=1", nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var errResp pkgs.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		assert.Contains(t, errResp.Msg, "最小数量不能大于最大数量")
+	})
+
 	t.Run("无效参数", func(t *testing.T) {
 		// 执行
 		req, _ := http.NewRequest(http.MethodGet, "/v1/template/list?pageSize=200", nil)
@@ -208,4 +283,49 @@ func TestQueryListTemplates_Sort(t *testing.T) {
 			assert.Equal(t, id1, list[1].(map[string]any)["id"])
 		}
 	})
+
+	t.Run("批量创建的创建时间相同时按写入顺序", func(t *testing.T) {
+		// 批量创建在同一事务中写入，created_at 完全相同，依赖 seq 决胜
+		prefix := "SeqTest_" + uuid.NewString()[:8]
+		bodyBytes, _ := json.Marshal(map[string]any{
+			"templates": []map[string]any{{"name": prefix + "_c"}, {"name": prefix + "_a"}, {"name": prefix + "_b"}},
+		})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/template/batch-create", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var createResp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &createResp)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, createResp.Code)
+		ids, _ := createResp.Data.([]any)
+		t.Cleanup(func() {
+			for _, id := range ids {
+				_, err := testDB.ExecContext(context.Background(), "DELETE FROM template WHERE id = $1", id)
+				assert.NoError(t, err, "清理创建的模板不应出错")
+			}
+		})
+
+		for _, order := range []string{"asc", "desc"} {
+			req, _ := http.NewRequest(http.MethodGet, "/v1/template/list?name="+prefix+"&orderBy=created_at&order="+order, nil)
+			w := httptest.NewRecorder()
+			testRouter.ServeHTTP(w, req)
+
+			var resp pkgs.Response
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			list := resp.Data.(map[string]any)["list"].([]any)
+			if assert.Len(t, list, 3) {
+				for i := range list {
+					expected := ids[i]
+					if order == "desc" {
+						expected = ids[len(ids)-1-i]
+					}
+					assert.Equal(t, expected, list[i].(map[string]any)["id"], "排序应与写入顺序一致（%s）", order)
+				}
+			}
+		}
+	})
 }
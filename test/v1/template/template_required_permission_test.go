@@ -0,0 +1,140 @@
+package template_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTemplateRequiredPermission 测试模板要求的权限
+// 包含五个子测试：只能要求自己拥有的权限、拥有权限时可见、缺少权限时不可见、缺少权限时不能修改、有管理权限时不受限制
+func TestTemplateRequiredPermission(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+
+	do := func(t *testing.T, method, url, token string, body any) pkgs.Response {
+		var reader *bytes.Buffer
+		if body != nil {
+			bodyBytes, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(bodyBytes)
+		} else {
+			reader = bytes.NewBuffer(nil)
+		}
+		req, _ := http.NewRequest(method, url, reader)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+	create := func(t *testing.T, token, name, code string) map[string]any {
+		resp := do(t, http.MethodPost, "/v1/template?return=entity", token, map[string]any{"name": name, "num": 1, "required_permission": code})
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		data, _ := resp.Data.(map[string]any)
+		t.Cleanup(func() {
+			_, err := testDB.ExecContext(context.Background(), "DELETE FROM template WHERE id = $1", data["id"])
+			assert.NoError(t, err, "清理创建的模板不应出错")
+		})
+		return data
+	}
+	listIDs := func(t *testing.T, url, token string) []any {
+		resp := do(t, http.MethodGet, url, token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		list := resp.Data.(map[string]any)["list"].([]any)
+		ids := make([]any, 0, len(list))
+		for _, item := range list {
+			ids = append(ids, item.(map[string]any)["id"])
+		}
+		return ids
+	}
+
+	suffix := uuid.NewString()[:8]
+	code := "report_" + suffix + ":view"
+	otherCode := "report_" + suffix + ":edit"
+	name := "RequiredPermTest_" + suffix
+	token := testUtil.GetAccessUserTokenWithCodes([]string{code})
+
+	t.Run("只能要求自己拥有的权限", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/v1/template", token, map[string]any{"name": name + "_denied", "num": 1, "required_permission": otherCode})
+		assert.Equal(t, http.StatusForbidden, resp.Code, "要求自己没有的权限应返回 403")
+	})
+
+	t.Run("拥有权限时可见", func(t *testing.T) {
+		data := create(t, token, name+"_visible", code)
+		assert.Equal(t, code, data["required_permission"], "应返回模板要求的权限")
+
+		resp := do(t, http.MethodGet, "/v1/template/"+data["id"].(string), token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "拥有权限时可以获取")
+		assert.Contains(t, listIDs(t, "/v1/template/list?name="+name+"_visible", token), data["id"], "列表应包含该模板")
+	})
+
+	t.Run("缺少权限时不可见", func(t *testing.T) {
+		data := create(t, token, name+"_hidden", code)
+		id := data["id"].(string)
+		// 模板改为要求当前用户没有的权限，模拟权限被收回
+		_, err := testDB.ExecContext(context.Background(), "UPDATE template SET required_permission = $1 WHERE id = $2", otherCode, id)
+		assert.NoError(t, err, "修改模板要求的权限不应出错")
+
+		resp := do(t, http.MethodGet, "/v1/template/"+id, token, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "缺少权限时获取应返回 404")
+		assert.NotContains(t, listIDs(t, "/v1/template/list?name="+name+"_hidden", token), id, "列表不应包含该模板")
+
+		resp = do(t, http.MethodGet, "/v1/template/count?name="+name+"_hidden", token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		assert.Equal(t, float64(0), resp.Data, "计数不应包含该模板")
+	})
+
+	t.Run("缺少权限时不能修改", func(t *testing.T) {
+		data := create(t, token, name+"_locked", code)
+		id := data["id"].(string)
+		_, err := testDB.ExecContext(context.Background(), "UPDATE template SET required_permission = $1 WHERE id = $2", otherCode, id)
+		assert.NoError(t, err, "修改模板要求的权限不应出错")
+
+		resp := do(t, http.MethodPut, "/v1/template/"+id, token, map[string]any{"name": name + "_renamed"})
+		assert.Equal(t, http.StatusNotFound, resp.Code, "缺少权限时更新应返回 404")
+		resp = do(t, http.MethodPatch, "/v1/template/"+id, token, map[string]any{"num": 2})
+		assert.Equal(t, http.StatusNotFound, resp.Code, "缺少权限时部分更新应返回 404")
+		resp = do(t, http.MethodPost, "/v1/template/"+id+"/archive", token, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "缺少权限时归档应返回 404")
+		resp = do(t, http.MethodDelete, "/v1/template/"+id, token, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "缺少权限时删除应返回 404")
+
+		var row struct {
+			Name       string     `db:"name"`
+			Num        *int       `db:"num"`
+			ArchivedAt *time.Time `db:"archived_at"`
+		}
+		err = testDB.GetContext(context.Background(), &row, "SELECT name, num, archived_at FROM template WHERE id = $1", id)
+		assert.NoError(t, err, "模板应仍然存在")
+		assert.Equal(t, name+"_locked", row.Name, "名称不应被修改")
+		assert.Equal(t, 1, *row.Num, "数量不应被修改")
+		assert.Nil(t, row.ArchivedAt, "模板不应被归档")
+	})
+
+	t.Run("有管理权限时不受限制", func(t *testing.T) {
+		data := create(t, token, name+"_managed", code)
+		id := data["id"].(string)
+		_, err := testDB.ExecContext(context.Background(), "UPDATE template SET required_permission = $1 WHERE id = $2", otherCode, id)
+		assert.NoError(t, err, "修改模板要求的权限不应出错")
+
+		manager := testUtil.GetAccessUserTokenWithCodes([]string{template.PermissionCodeManageAll})
+		resp := do(t, http.MethodGet, "/v1/template/"+id, manager, nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "有 template:manage_all 权限时可以获取")
+		assert.Contains(t, listIDs(t, "/v1/template/list?scope=all&name="+name+"_managed", manager), id, "列表应包含该模板")
+	})
+}
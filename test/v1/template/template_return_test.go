@@ -0,0 +1,78 @@
+package template_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateReturnEntity 测试创建接口 return=entity 返回完整实体
+// 包含两个子测试：单个创建返回实体、批量创建返回实体列表
+func TestCreateReturnEntity(t *testing.T) {
+	post := func(t *testing.T, url string, body any) pkgs.Response {
+		bodyBytes, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		token := getAuthToken(t, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		return resp
+	}
+	cleanup := func(t *testing.T, id string) {
+		t.Cleanup(func() {
+			_, err := testDB.ExecContext(context.Background(), "DELETE FROM template WHERE id = $1", id)
+			assert.NoError(t, err, "清理创建的模板不应出错")
+		})
+	}
+
+	t.Run("单个创建返回实体", func(t *testing.T) {
+		num := 42
+		resp := post(t, "/v1/template?return=entity", map[string]any{"name": "返回实体模板", "num": num})
+
+		data, ok := resp.Data.(map[string]any)
+		if !assert.True(t, ok, "响应数据应该是模板对象") {
+			return
+		}
+		cleanup(t, data["id"].(string))
+		assert.NotEmpty(t, data["id"], "应返回服务端生成的 ID")
+		assert.Equal(t, "返回实体模板", data["name"], "名称应一致")
+		assert.Equal(t, float64(num), data["num"], "数量应一致")
+		assert.NotEmpty(t, data["created_at"], "应返回创建时间")
+		assert.NotEmpty(t, data["updated_at"], "应返回更新时间")
+	})
+
+	t.Run("批量创建返回实体列表", func(t *testing.T) {
+		resp := post(t, "/v1/template/batch-create?return=entity", map[string]any{
+			"templates": []map[string]any{
+				{"name": "返回实体批量模板 1"},
+				{"name": "返回实体批量模板 2"},
+			},
+		})
+
+		list, ok := resp.Data.([]any)
+		if !assert.True(t, ok, "响应数据应该是模板数组") || !assert.Len(t, list, 2, "应创建 2 个模板") {
+			return
+		}
+		for i, item := range list {
+			entity := item.(map[string]any)
+			cleanup(t, entity["id"].(string))
+			assert.NotEmpty(t, entity["id"], "应返回服务端生成的 ID")
+			assert.NotEmpty(t, entity["created_at"], "应返回创建时间")
+			assert.Equal(t, []string{"返回实体批量模板 1", "返回实体批量模板 2"}[i], entity["name"], "返回顺序应与请求一致")
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package template_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTemplateSync 测试模板同步协议
+// 包含五个子测试：推送新建、全量拉取、版本冲突、删除后拉取墓碑、无效检查点
+func TestTemplateSync(t *testing.T) {
+	testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	token := testUtil.GetAccessUserToken([]string{"GET /v1/sync/template", "POST /v1/sync/template"})
+
+	do := func(t *testing.T, method, url string, body any, data any) pkgs.Response {
+		bodyBytes, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		resp.Data = data
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+	push := func(t *testing.T, changes ...template.SyncPushChange) template.SyncPushRes {
+		var res template.SyncPushRes
+		resp := do(t, http.MethodPost, "/v1/sync/template", template.SyncPushReq{Changes: changes}, &res)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		return res
+	}
+	// pull 从检查点拉取全部变更，返回按ID索引的最后一次变更
+	pull := func(t *testing.T, checkpoint string) map[string]template.SyncChange {
+		changes := map[string]template.SyncChange{}
+		for {
+			var res template.SyncPullRes
+			resp := do(t, http.MethodGet, "/v1/sync/template?limit=500&checkpoint="+url.QueryEscape(checkpoint), nil, &res)
+			assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+			for _, change := range res.Changes {
+				changes[change.ID] = change
+			}
+			checkpoint = res.Checkpoint
+			if !res.HasMore {
+				return changes
+			}
+		}
+	}
+
+	id := uuid.NewString()
+	t.Cleanup(func() {
+		_, err := testDB.ExecContext(context.Background(), "DELETE FROM template WHERE id = $1", id)
+		assert.NoError(t, err, "清理模板不应出错")
+		_, err = testDB.ExecContext(context.Background(), "DELETE FROM template_tombstone WHERE id = $1", id)
+		assert.NoError(t, err, "清理墓碑不应出错")
+	})
+	num := 1
+	var version int64
+
+	t.Run("推送新建", func(t *testing.T) {
+		res := push(t, template.SyncPushChange{ID: id, Op: template.SyncOpUpsert, Name: "SyncTest_" + id[:8], Num: &num})
+		assert.Len(t, res.Applied, 1, "新建应被应用")
+		assert.Empty(t, res.Conflicts, "不应有冲突")
+		version = res.Applied[0].Version
+
+		// 同一ID再次新建返回冲突
+		res = push(t, template.SyncPushChange{ID: id, Op: template.SyncOpUpsert, Name: "SyncTest_" + id[:8]})
+		assert.Len(t, res.Conflicts, 1, "重复新建应返回冲突")
+		assert.Equal(t, template.SyncConflictExists, res.Conflicts[0].Reason)
+	})
+
+	t.Run("全量拉取", func(t *testing.T) {
+		changes := pull(t, "")
+		change, ok := changes[id]
+		assert.True(t, ok, "全量拉取应包含新建的模板")
+		assert.Equal(t, template.SyncOpUpsert, change.Op)
+		assert.Equal(t, version, change.Version, "版本应与推送返回的一致")
+	})
+
+	t.Run("版本冲突", func(t *testing.T) {
+		res := push(t, template.SyncPushChange{ID: id, Op: template.SyncOpUpsert, BaseVersion: version - 1, Name: "SyncTest_stale"})
+		assert.Len(t, res.Conflicts, 1, "过期的基准版本应返回冲突")
+		assert.Equal(t, template.SyncConflictVersion, res.Conflicts[0].Reason)
+		if assert.NotNil(t, res.Conflicts[0].Server, "冲突应返回服务端数据") {
+			assert.Equal(t, version, res.Conflicts[0].Server.Version)
+		}
+
+		res = push(t, template.SyncPushChange{ID: id, Op: template.SyncOpUpsert, BaseVersion: version, Name: "SyncTest_updated"})
+		assert.Len(t, res.Applied, 1, "基准版本一致时应被应用")
+		version = res.Applied[0].Version
+	})
+
+	t.Run("删除后拉取墓碑", func(t *testing.T) {
+		var before template.SyncPullRes
+		do(t, http.MethodGet, "/v1/sync/template?limit=1", nil, &before)
+
+		res := push(t, template.SyncPushChange{ID: id, Op: template.SyncOpDelete, BaseVersion: version})
+		assert.Len(t, res.Applied, 1, "删除应被应用")
+
+		// 重复删除视为成功
+		res = push(t, template.SyncPushChange{ID: id, Op: template.SyncOpDelete, BaseVersion: version})
+		assert.Len(t, res.Applied, 1, "重复删除应视为成功")
+
+		changes := pull(t, before.Checkpoint)
+		change, ok := changes[id]
+		assert.True(t, ok, "增量拉取应包含删除")
+		assert.Equal(t, template.SyncOpDelete, change.Op)
+
+		// 已删除的模板不能修改
+		push1 := push(t, template.SyncPushChange{ID: id, Op: template.SyncOpUpsert, BaseVersion: version, Name: "SyncTest_deleted"})
+		assert.Len(t, push1.Conflicts, 1, "修改已删除的模板应返回冲突")
+		assert.Equal(t, template.SyncConflictDeleted, push1.Conflicts[0].Reason)
+	})
+
+	t.Run("无效检查点", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/v1/sync/template?checkpoint=invalid", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "无效检查点应返回 400")
+	})
+}
@@ -0,0 +1,51 @@
+package template_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTimezone 测试返回时间按请求时区序列化
+// 包含三个子测试：?tz= 指定时区、Accept-Language 映射时区、非法时区返回 400
+func TestTimezone(t *testing.T) {
+	entity := createTestTemplate(t, "", nil)
+	get := func(t *testing.T, query, acceptLanguage string) pkgs.Response {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/template/"+entity["id"].(string)+query, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+
+	t.Run("tz 参数指定时区", func(t *testing.T) {
+		resp := get(t, "?tz=Asia/Shanghai", "")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		createdAt := resp.Data.(map[string]any)["created_at"].(string)
+		assert.True(t, strings.HasSuffix(createdAt, "+08:00"), "时间应使用 Asia/Shanghai 时区: %s", createdAt)
+	})
+
+	t.Run("Accept-Language 映射时区", func(t *testing.T) {
+		resp := get(t, "", "zh-CN,zh;q=0.9")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		createdAt := resp.Data.(map[string]any)["created_at"].(string)
+		assert.True(t, strings.HasSuffix(createdAt, "+08:00"), "zh-CN 应使用 Asia/Shanghai 时区: %s", createdAt)
+	})
+
+	t.Run("非法时区", func(t *testing.T) {
+		resp := get(t, "?tz=Not/AZone", "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "非法时区应返回 400")
+	})
+}
@@ -0,0 +1,70 @@
+package template_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-pg-demo/pkgs"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTemplateUsage 测试模板使用次数统计
+// 包含三个子测试：记录使用累加次数、模板不存在返回 404、按使用次数排序
+func TestTemplateUsage(t *testing.T) {
+	do := func(t *testing.T, method, url string) pkgs.Response {
+		req, _ := http.NewRequest(method, url, nil)
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "解析响应体不应出错")
+		return resp
+	}
+
+	t.Run("记录使用累加次数", func(t *testing.T) {
+		entity := createTestTemplate(t, "", nil)
+		id := entity["id"].(string)
+
+		for i := 1; i <= 3; i++ {
+			resp := do(t, http.MethodPost, "/v1/template/"+id+"/use")
+			assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+			assert.Equal(t, float64(i), resp.Data, "应返回累计使用次数")
+		}
+
+		resp := do(t, http.MethodGet, "/v1/template/"+id)
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		assert.Equal(t, float64(3), resp.Data.(map[string]any)["usage_count"], "详情应返回使用次数")
+	})
+
+	t.Run("模板不存在", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/v1/template/"+uuid.NewString()+"/use")
+		assert.Equal(t, http.StatusNotFound, resp.Code, "模板不存在时应返回 404")
+	})
+
+	t.Run("按使用次数排序", func(t *testing.T) {
+		prefix := "UsageTest_" + uuid.NewString()[:8]
+		unused := createTestTemplate(t, prefix+"_unused", nil)
+		popular := createTestTemplate(t, prefix+"_popular", nil)
+		used := createTestTemplate(t, prefix+"_used", nil)
+		for i := 0; i < 2; i++ {
+			do(t, http.MethodPost, "/v1/template/"+popular["id"].(string)+"/use")
+		}
+		do(t, http.MethodPost, "/v1/template/"+used["id"].(string)+"/use")
+
+		resp := do(t, http.MethodGet, "/v1/template/list?name="+prefix+"&orderBy=usage_count&order=desc")
+		assert.Equal(t, http.StatusOK, resp.Code, "响应码应该是 200")
+		list := resp.Data.(map[string]any)["list"].([]any)
+		if assert.Len(t, list, 3, "列表长度应该为3") {
+			assert.Equal(t, popular["id"], list[0].(map[string]any)["id"], "使用最多的模板应排在最前")
+			assert.Equal(t, float64(2), list[0].(map[string]any)["usage_count"], "列表应返回使用次数")
+			assert.Equal(t, used["id"], list[1].(map[string]any)["id"])
+			assert.Equal(t, unused["id"], list[2].(map[string]any)["id"])
+			assert.Equal(t, float64(0), list[2].(map[string]any)["usage_count"], "未使用的模板次数应为 0")
+		}
+	})
+}
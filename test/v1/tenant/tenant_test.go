@@ -0,0 +1,101 @@
+package tenant_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// 全局测试变量
+var (
+	testDB     *sqlx.DB    // 测试数据库连接
+	testRouter *gin.Engine // 测试路由器
+)
+
+// TestMain 初始化测试环境
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	testApp, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+
+	testDB = testApp.DB
+	testRouter = testApp.Server
+
+	os.Exit(m.Run())
+}
+
+// TestCreateTenant 测试创建租户
+// 默认配置为单租户模式，创建租户应返回 400
+func TestCreateTenant(t *testing.T) {
+	t.Run("未启用多租户模式", func(t *testing.T) {
+		// 准备
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+		bodyBytes, _ := json.Marshal(map[string]any{"tenant": "acme"})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/tenant", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// 执行
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		assert.Equal(t, http.StatusOK, w.Code, "状态码应该是200（统一错误响应格式）")
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "响应体应该能正确解析")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "未启用多租户模式时业务码应为400")
+		assert.Equal(t, "未启用多租户模式", resp.Msg, "错误信息应提示未启用多租户模式")
+	})
+
+	t.Run("缺少租户标识", func(t *testing.T) {
+		// 准备
+		testUtil := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+		token := testUtil.GetAccessUserToken([]string{})
+		bodyBytes, _ := json.Marshal(map[string]any{})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/tenant", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// 执行
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		// 断言
+		var resp pkgs.Response
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err, "响应体应该能正确解析")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "缺少租户标识时业务码应为400")
+	})
+}
+
+// TestTenantHeaderIgnoredInSingleTenantMode 单租户模式下携带租户请求头不影响正常访问
+func TestTenantHeaderIgnoredInSingleTenantMode(t *testing.T) {
+	// 准备
+	req, _ := http.NewRequest(http.MethodGet, "/v1/template/list", nil)
+	req.Header.Set("X-Tenant-ID", "not_exists")
+
+	// 执行
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	// 断言
+	var resp pkgs.Response
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err, "响应体应该能正确解析")
+	assert.Equal(t, http.StatusOK, resp.Code, "单租户模式下应忽略租户请求头")
+}
@@ -0,0 +1,159 @@
+package view_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+)
+
+// 视图接口的全部权限
+var viewPermissions = []string{"POST /v1/views", "GET /v1/views", "PUT /v1/views/:id", "DELETE /v1/views/:id"}
+
+// TestMain 初始化一次应用，复用数据库和路由
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	os.Exit(m.Run())
+}
+
+// doRequest 发送 JSON 请求并解析标准响应
+func doRequest(t *testing.T, method, path, token string, body any) pkgs.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// viewList 查询实体上可用的视图
+func viewList(t *testing.T, token, entity string) []map[string]any {
+	t.Helper()
+	resp := doRequest(t, http.MethodGet, "/v1/views?entity="+entity, token, nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	var data struct {
+		List []map[string]any `json:"list"`
+	}
+	raw, _ := json.Marshal(resp.Data)
+	require.NoError(t, json.Unmarshal(raw, &data))
+	return data.List
+}
+
+// TestViewCRUD 测试视图的保存、查询、更新与删除
+// 包含三个子测试：保存与查询、名称重复与参数校验、更新与删除
+func TestViewCRUD(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions(viewPermissions)
+
+	t.Run("保存与查询", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/views", token, map[string]any{
+			"entity": "user",
+			"name":   "最近注册",
+			"config": map[string]any{"filters": map[string]string{"username": "ali"}, "order_by": "created_at", "order": "desc", "columns": []string{"username", "phone"}},
+		})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		id := resp.Data.(string)
+
+		list := viewList(t, token, "user")
+		require.Len(t, list, 1)
+		assert.Equal(t, id, list[0]["id"])
+		assert.Equal(t, true, list[0]["owned"])
+		config := list[0]["config"].(map[string]any)
+		assert.Equal(t, "created_at", config["order_by"])
+		assert.Equal(t, []any{"username", "phone"}, config["columns"])
+
+		// 其他实体上没有该视图
+		assert.Empty(t, viewList(t, token, "role"))
+	})
+
+	t.Run("名称重复与参数校验", func(t *testing.T) {
+		body := map[string]any{"entity": "role", "name": "全部角色"}
+		resp := doRequest(t, http.MethodPost, "/v1/views", token, body)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		resp = doRequest(t, http.MethodPost, "/v1/views", token, body)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = doRequest(t, http.MethodPost, "/v1/views", token, map[string]any{"entity": "unknown", "name": "x"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doRequest(t, http.MethodPost, "/v1/views", token, map[string]any{"entity": "role", "name": "x", "config": map[string]any{"order": "up"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doRequest(t, http.MethodGet, "/v1/views", token, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("更新与删除", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/views", token, map[string]any{"entity": "template", "name": "旧名称"})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		id := resp.Data.(string)
+
+		resp = doRequest(t, http.MethodPut, "/v1/views/"+id, token, map[string]any{"name": "新名称"})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		list := viewList(t, token, "template")
+		require.Len(t, list, 1)
+		assert.Equal(t, "新名称", list[0]["name"])
+
+		resp = doRequest(t, http.MethodDelete, "/v1/views/"+id, token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Empty(t, viewList(t, token, "template"))
+	})
+}
+
+// TestViewSharing 测试共享给角色的视图
+// 包含两个子测试：角色成员可见、不能修改他人的视图
+func TestViewSharing(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, ownerToken := tu.SetupUserWithPermissions(viewPermissions)
+	member, memberToken := tu.SetupUserWithPermissions(viewPermissions)
+	team := tu.SetupTestRole()
+	tu.AssignRoleToUser(member.ID, team.ID)
+
+	resp := doRequest(t, http.MethodPost, "/v1/views", ownerToken, map[string]any{"entity": "client", "name": "团队视图", "shared_role_ids": []string{team.ID}})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	id := resp.Data.(string)
+
+	t.Run("角色成员可见", func(t *testing.T) {
+		list := viewList(t, memberToken, "client")
+		require.Len(t, list, 1)
+		assert.Equal(t, id, list[0]["id"])
+		assert.Equal(t, false, list[0]["owned"])
+	})
+
+	t.Run("不能修改他人的视图", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPut, "/v1/views/"+id, memberToken, map[string]any{"name": "改名"})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		resp = doRequest(t, http.MethodDelete, "/v1/views/"+id, memberToken, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 0, resp.Data)
+		assert.Len(t, viewList(t, ownerToken, "client"), 1)
+	})
+}
@@ -0,0 +1,191 @@
+package watch_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go-pg-demo/internal/app"
+	"go-pg-demo/internal/modules/template"
+	"go-pg-demo/pkgs"
+)
+
+var (
+	testDB     *sqlx.DB
+	testRouter *gin.Engine
+)
+
+// 关注接口的全部权限
+var watchPermissions = []string{"POST /v1/watch", "GET /v1/watch/list", "DELETE /v1/watch/:id", "POST /v1/watch/unwatch"}
+
+// TestMain 初始化一次应用，复用数据库和路由
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	a, _, err := app.InitializeApp()
+	if err != nil {
+		os.Exit(1)
+	}
+	testDB = a.DB
+	testRouter = a.Server
+	os.Exit(m.Run())
+}
+
+// doRequest 发送 JSON 请求并解析标准响应
+func doRequest(t *testing.T, method, path, token string, body any) pkgs.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	var resp pkgs.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// notifications 返回发给用户的关注通知类型，按写入顺序排列
+func notifications(t *testing.T, userID, entityID string) []string {
+	t.Helper()
+	var types []string
+	err := testDB.Select(&types, `SELECT payload->>'type' FROM async_job
+		WHERE type = $1 AND payload->'recipients' ? $2 AND payload->'data'->>'entity_id' = $3
+		ORDER BY seq`, pkgs.JobTypeNotification, userID, entityID)
+	require.NoError(t, err)
+	return types
+}
+
+// TestWatch 测试关注模板与变更通知
+// 包含五个子测试：关注与重复关注、只能关注可见的实体、变更时通知关注者、不再可见时不通知、删除后通知并取消关注
+func TestWatch(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	watcher, token := tu.SetupUserWithPermissions(watchPermissions)
+	managerToken := tu.GetAccessUserTokenWithCodes([]string{template.PermissionCodeManageAll})
+
+	// 关注者创建的模板，由拥有 template:manage_all 权限的用户修改
+	resp := doRequest(t, http.MethodPost, "/v1/template", token, map[string]any{"name": "watch_" + uuid.NewString()[:8], "num": 1})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+	templateID := resp.Data.(string)
+	t.Cleanup(func() {
+		_, err := testDB.Exec(`DELETE FROM template WHERE id = $1`, templateID)
+		assert.NoError(t, err, "清理模板失败")
+		_, err = testDB.Exec(`DELETE FROM async_job WHERE type = $1 AND payload->'data'->>'entity_id' = $2`, pkgs.JobTypeNotification, templateID)
+		assert.NoError(t, err, "清理通知任务失败")
+	})
+
+	t.Run("关注与重复关注", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, "/v1/watch", token, map[string]any{"entity": "template", "entity_id": templateID})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		watchID := resp.Data.(string)
+
+		resp = doRequest(t, http.MethodPost, "/v1/watch", token, map[string]any{"entity": "template", "entity_id": templateID})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, watchID, resp.Data, "重复关注返回原有的关注ID")
+
+		resp = doRequest(t, http.MethodGet, "/v1/watch/list?entity=template&entity_id="+templateID, token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		data := resp.Data.(map[string]any)
+		assert.EqualValues(t, 1, data["total"])
+
+		resp = doRequest(t, http.MethodPost, "/v1/watch", token, map[string]any{"entity": "role", "entity_id": templateID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "不支持关注的实体")
+	})
+
+	t.Run("只能关注可见的实体", func(t *testing.T) {
+		_, otherToken := tu.SetupUserWithPermissions(watchPermissions)
+		resp := doRequest(t, http.MethodPost, "/v1/watch", otherToken, map[string]any{"entity": "template", "entity_id": templateID})
+		assert.Equal(t, http.StatusNotFound, resp.Code, "其他用户的模板按不存在处理")
+
+		target := tu.SetupTestUser()
+		resp = doRequest(t, http.MethodPost, "/v1/watch", otherToken, map[string]any{"entity": "user", "entity_id": target.ID})
+		assert.Equal(t, http.StatusForbidden, resp.Code, "没有查看用户的接口权限")
+
+		_, viewerToken := tu.SetupUserWithPermissions(append([]string{"GET /v1/user/:id"}, watchPermissions...))
+		resp = doRequest(t, http.MethodPost, "/v1/watch", viewerToken, map[string]any{"entity": "user", "entity_id": uuid.NewString()})
+		assert.Equal(t, http.StatusNotFound, resp.Code, "用户不存在")
+	})
+
+	t.Run("变更时通知关注者", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPatch, "/v1/template/"+templateID, token, map[string]any{"num": 2})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Empty(t, notifications(t, watcher.ID, templateID), "自己的修改不通知自己")
+
+		resp = doRequest(t, http.MethodPatch, "/v1/template/"+templateID, managerToken, map[string]any{"num": 3})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, []string{"watch.template.patch"}, notifications(t, watcher.ID, templateID))
+	})
+
+	t.Run("不再可见时不通知", func(t *testing.T) {
+		// 关注之后模板改为要求关注者没有的权限
+		_, err := testDB.Exec(`UPDATE template SET required_permission = 'template:watch_hidden' WHERE id = $1`, templateID)
+		require.NoError(t, err)
+		resp := doRequest(t, http.MethodPatch, "/v1/template/"+templateID, managerToken, map[string]any{"num": 4})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, []string{"watch.template.patch"}, notifications(t, watcher.ID, templateID), "没有新的通知")
+
+		_, err = testDB.Exec(`UPDATE template SET required_permission = NULL WHERE id = $1`, templateID)
+		require.NoError(t, err)
+	})
+
+	t.Run("删除后通知并取消关注", func(t *testing.T) {
+		resp := doRequest(t, http.MethodDelete, "/v1/template/"+templateID, managerToken, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.Equal(t, []string{"watch.template.patch", "watch.template.delete"}, notifications(t, watcher.ID, templateID))
+
+		resp = doRequest(t, http.MethodGet, "/v1/watch/list?entity=template&entity_id="+templateID, token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 0, resp.Data.(map[string]any)["total"], "实体删除后关注自动取消")
+	})
+}
+
+// TestUnwatch 测试取消关注
+// 包含两个子测试：按实体取消、按ID取消只能取消自己的关注
+func TestUnwatch(t *testing.T) {
+	tu := &pkgs.TestUtil{Engine: testRouter, DB: testDB, T: t}
+	_, token := tu.SetupUserWithPermissions(append([]string{"GET /v1/user/:id"}, watchPermissions...))
+	target := tu.SetupTestUser()
+
+	watch := func(t *testing.T) string {
+		t.Helper()
+		resp := doRequest(t, http.MethodPost, "/v1/watch", token, map[string]any{"entity": "user", "entity_id": target.ID})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		return resp.Data.(string)
+	}
+
+	t.Run("按实体取消", func(t *testing.T) {
+		watch(t)
+		resp := doRequest(t, http.MethodPost, "/v1/watch/unwatch", token, map[string]any{"entity": "user", "entity_id": target.ID})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 1, resp.Data)
+
+		resp = doRequest(t, http.MethodPost, "/v1/watch/unwatch", token, map[string]any{"entity": "user", "entity_id": target.ID})
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 0, resp.Data, "没有关注时影响行数为 0")
+	})
+
+	t.Run("按ID取消只能取消自己的关注", func(t *testing.T) {
+		watchID := watch(t)
+		_, otherToken := tu.SetupUserWithPermissions(watchPermissions)
+		resp := doRequest(t, http.MethodDelete, "/v1/watch/"+watchID, otherToken, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 0, resp.Data, "其他用户的关注不受影响")
+
+		resp = doRequest(t, http.MethodDelete, "/v1/watch/"+watchID, token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Msg)
+		assert.EqualValues(t, 1, resp.Data)
+	})
+}